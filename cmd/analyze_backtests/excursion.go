@@ -0,0 +1,48 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/strategy/analytics"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+// analyzeExcursions prints the MAE/MFE distributions of each file's trades, to help tune stop
+// and target distances
+func analyzeExcursions(files []string) {
+	for _, file := range files {
+		trades, err := utils.ReadTradesFromCSV(file)
+		if err != nil {
+			log.Printf("Error reading trades from %s: %v", file, err)
+			continue
+		}
+
+		stats := analytics.AnalyzeExcursions(trades)
+		fmt.Printf("\nFile: %s\n", filepath.Base(file))
+		if stats.MAE.Max == 0 && stats.MFE.Max == 0 {
+			fmt.Println("No excursions recorded (file written before MAE/MFE tracking)")
+			continue
+		}
+
+		fmt.Println("Excursion %\tCount\tMean\tMedian\tP75\tP90\tP95\tMax")
+		rows := []struct {
+			name string
+			dist analytics.Distribution
+		}{
+			{"MAE (all)", stats.MAE},
+			{"MFE (all)", stats.MFE},
+			{"MAE (winners)", stats.WinnersMAE},
+			{"MFE (winners)", stats.WinnersMFE},
+			{"MFE (losers)", stats.LosersMFE},
+		}
+		for _, row := range rows {
+			d := row.dist
+			fmt.Printf("%s\t%d\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\n", row.name, d.Count,
+				d.Mean*100, d.Median*100, d.P75*100, d.P90*100, d.P95*100, d.Max*100)
+		}
+		if stats.WinnersMAE.Count > 0 {
+			fmt.Printf("A stop beyond %.2f%% would have kept 90%% of the winners\n", stats.WinnersMAE.P90*100)
+		}
+	}
+}
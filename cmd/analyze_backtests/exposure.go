@@ -0,0 +1,28 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/strategy/analytics"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// analyzeExposure prints how long each file's trades kept a position open and how often they
+// traded. The period spans the trades, since the files don't record the backtest range
+func analyzeExposure(files []string, barInterval time.Duration) {
+	fmt.Println("File\tTimeInMarket%\tAvgBarsHeld\tTrades/Day (mean)\tMedian\tP90\tMax")
+	for _, file := range files {
+		trades, err := utils.ReadTradesFromCSV(file)
+		if err != nil {
+			log.Printf("Error reading trades from %s: %v", file, err)
+			continue
+		}
+
+		stats := analytics.AnalyzeExposure(trades, time.Time{}, time.Time{}, barInterval)
+		d := stats.TradesPerDay
+		fmt.Printf("%s\t%.1f\t%.1f\t%.2f\t%.1f\t%.1f\t%.0f\n", filepath.Base(file),
+			stats.TimeInMarket*100, stats.AverageBarsHeld, d.Mean, d.Median, d.P90, d.Max)
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
 	"cryptoMegaBot/internal/utils"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -10,11 +13,31 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
+)
+
+var (
+	dataDir   = flag.String("dir", "data", "directory containing backtest trade and kline CSV files")
+	interval  = flag.String("interval", "15m", "kline interval used to measure symbol volatility (ATR)")
+	atrPeriod = flag.Int("atr-period", 14, "ATR period used for volatility normalization")
+	barPeriod = flag.Duration("bar", 15*time.Minute, "bar interval of the backtests, used to express holding times in bars")
+	dbPath    = flag.String("db", "", "SQLite database with backtest runs recorded by the backtest runner (e.g., ./data/trading_bot.db); empty skips the run comparison")
+	strategy  = flag.String("strategy", "", "only compare recorded runs of this strategy")
+	runLimit  = flag.Int("runs", 50, "maximum number of recorded runs to compare, newest first (0 for all)")
 )
 
 func main() {
+	flag.Parse()
+
+	// Compare the runs recorded in the database by their parameters and data range
+	if *dbPath != "" {
+		fmt.Println("## Recorded Backtest Runs")
+		compareRuns(context.Background(), *dbPath, ports.BacktestRunFilter{Strategy: *strategy, Limit: *runLimit})
+		fmt.Println()
+	}
+
 	// Find all backtest trade files
-	files, err := findBacktestFiles("data", "improved_backtest_trades")
+	files, err := findBacktestFiles(*dataDir, "improved_backtest_trades")
 	if err != nil {
 		log.Fatalf("Error finding backtest files: %v", err)
 	}
@@ -59,6 +82,22 @@ func main() {
 	// Print additional analysis
 	fmt.Println("\n## Trend Reversal Analysis")
 	analyzeTrendReversals(files)
+
+	fmt.Println("\n## Entry Type Analysis")
+	analyzeEntryTypes(files)
+
+	fmt.Println("\n## MAE/MFE Analysis")
+	analyzeExcursions(files)
+
+	fmt.Println("\n## Market Regime Analysis")
+	analyzeRegimes(files)
+
+	fmt.Println("\n## Trade Frequency & Exposure")
+	analyzeExposure(files, *barPeriod)
+
+	// Compare symbols on a volatility-adjusted basis
+	fmt.Println("\n## Cross-Symbol Volatility-Normalized Comparison")
+	printSymbolComparison(files, *dataDir, *interval, *atrPeriod)
 }
 
 // TradeStats holds statistics about a set of trades
@@ -134,7 +173,7 @@ func findBacktestFiles(dir, prefix string) ([]string, error) {
 	}
 
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && strings.HasSuffix(entry.Name(), ".csv") {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && isCSVFile(entry.Name()) {
 			files = append(files, filepath.Join(dir, entry.Name()))
 		}
 	}
@@ -149,6 +188,11 @@ func findBacktestFiles(dir, prefix string) ([]string, error) {
 	return files, nil
 }
 
+// isCSVFile reports whether name is a plain or gzip-compressed CSV file
+func isCSVFile(name string) bool {
+	return strings.HasSuffix(name, ".csv") || strings.HasSuffix(name, ".csv.gz")
+}
+
 // extractTPFromFilename extracts the TP value from a filename
 // e.g., improved_backtest_trades_tp1.5.csv -> 1.5
 func extractTPFromFilename(filename string) float64 {
@@ -158,7 +202,7 @@ func extractTPFromFilename(filename string) float64 {
 		return 0
 	}
 
-	tpStr := strings.TrimSuffix(parts[1], ".csv")
+	tpStr := strings.TrimSuffix(strings.TrimSuffix(parts[1], ".gz"), ".csv")
 	var tp float64
 	fmt.Sscanf(tpStr, "%f", &tp)
 	return tp
@@ -237,3 +281,45 @@ func analyzeTrendReversals(files []string) {
 		}
 	}
 }
+
+// analyzeEntryTypes breaks performance down by the signal source that opened each trade
+func analyzeEntryTypes(files []string) {
+	for _, file := range files {
+		trades, err := utils.ReadTradesFromCSV(file)
+		if err != nil {
+			log.Printf("Error reading trades from %s: %v", file, err)
+			continue
+		}
+
+		bySource := make(map[domain.SignalSource][]*domain.Trade)
+		for _, trade := range trades {
+			bySource[trade.SignalSource] = append(bySource[trade.SignalSource], trade)
+		}
+
+		var sources []domain.SignalSource
+		for source := range bySource {
+			sources = append(sources, source)
+		}
+		sort.Slice(sources, func(i, j int) bool {
+			return string(sources[i]) < string(sources[j])
+		})
+
+		fmt.Printf("\nFile: %s\n", filepath.Base(file))
+		fmt.Println("Signal Source\tCount\tWinRate\tTotal PnL\tAvg PnL\tAvg Confirmations")
+		for _, source := range sources {
+			group := bySource[source]
+			stats := calculateTradeStats(group)
+			confirmations := 0
+			for _, trade := range group {
+				confirmations += trade.ConfirmationCount
+			}
+
+			name := string(source)
+			if source == domain.SignalSourceUnknown {
+				name = "untagged"
+			}
+			fmt.Printf("%s\t%d\t%.2f\t%.2f\t%.2f\t%.2f\n", name, stats.TotalTrades, stats.WinRate*100,
+				stats.TotalPnL, stats.TotalPnL/float64(stats.TotalTrades), float64(confirmations)/float64(len(group)))
+		}
+	}
+}
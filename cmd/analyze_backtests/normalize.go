@@ -0,0 +1,228 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/indicators"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// VolatilitySource describes how a symbol's volatility was measured
+type VolatilitySource string
+
+const (
+	// VolatilityFromATR means volatility is the average of each bar's ATR as a percentage of its close
+	VolatilityFromATR VolatilitySource = "ATR"
+	// VolatilityFromReturns means volatility is the standard deviation of trade returns
+	VolatilityFromReturns VolatilitySource = "StdDev"
+)
+
+// SymbolComparison holds volatility-normalized performance for a single symbol
+type SymbolComparison struct {
+	Symbol           string
+	Trades           int
+	TotalPnL         float64
+	AvgReturnPct     float64 // Average per-trade return in percent of entry price
+	VolatilityPct    float64 // Volatility in percent of price
+	Source           VolatilitySource
+	NormalizedReturn float64 // AvgReturnPct / VolatilityPct
+	NormalizedTotal  float64 // Sum of per-trade returns / VolatilityPct
+}
+
+// compareSymbols groups trades by symbol and normalizes their returns by the symbol's volatility.
+// The whole table uses one volatility source so the normalized returns rank alike: ATR from kline
+// CSVs in klineDir when every symbol has them, else the standard deviation of trade returns.
+func compareSymbols(trades []*domain.Trade, klineDir, interval string, atrPeriod int) []SymbolComparison {
+	bySymbol := make(map[string][]*domain.Trade)
+	for _, trade := range trades {
+		bySymbol[trade.Symbol] = append(bySymbol[trade.Symbol], trade)
+	}
+
+	atrPcts := make(map[string]float64, len(bySymbol))
+	source := VolatilityFromATR
+	for symbol := range bySymbol {
+		atrPct, err := symbolATRPercent(klineDir, symbol, interval, atrPeriod)
+		if err != nil || atrPct <= 0 {
+			source = VolatilityFromReturns
+			break
+		}
+		atrPcts[symbol] = atrPct
+	}
+
+	comparisons := make([]SymbolComparison, 0, len(bySymbol))
+	for symbol, symbolTrades := range bySymbol {
+		returns := tradeReturnsPct(symbolTrades)
+
+		cmp := SymbolComparison{
+			Symbol: symbol,
+			Trades: len(symbolTrades),
+			Source: source,
+		}
+		var sumReturns float64
+		for i, trade := range symbolTrades {
+			cmp.TotalPnL += trade.PNL
+			sumReturns += returns[i]
+		}
+		if len(returns) > 0 {
+			cmp.AvgReturnPct = sumReturns / float64(len(returns))
+		}
+
+		if source == VolatilityFromATR {
+			cmp.VolatilityPct = atrPcts[symbol]
+		} else {
+			cmp.VolatilityPct = stdDev(returns)
+		}
+
+		if cmp.VolatilityPct > 0 {
+			cmp.NormalizedReturn = cmp.AvgReturnPct / cmp.VolatilityPct
+			cmp.NormalizedTotal = sumReturns / cmp.VolatilityPct
+		}
+		comparisons = append(comparisons, cmp)
+	}
+
+	// Best volatility-adjusted performers first
+	sort.Slice(comparisons, func(i, j int) bool {
+		return comparisons[i].NormalizedReturn > comparisons[j].NormalizedReturn
+	})
+	return comparisons
+}
+
+// tradeReturnsPct returns the per-trade price return in percent for each trade, positive when the
+// price moved in the trade's favor (down for shorts)
+func tradeReturnsPct(trades []*domain.Trade) []float64 {
+	returns := make([]float64, 0, len(trades))
+	for _, trade := range trades {
+		if trade.EntryPrice == 0 {
+			returns = append(returns, 0)
+			continue
+		}
+		ret := (trade.ExitPrice - trade.EntryPrice) / trade.EntryPrice * 100
+		if trade.Side == domain.PositionSideShort {
+			ret = -ret
+		}
+		returns = append(returns, ret)
+	}
+	return returns
+}
+
+// symbolATRPercent loads the kline CSV for a symbol/interval and returns the average over its bars
+// of the ATR as a percentage of the bar's close, so early, differently priced bars weigh the same
+// as recent ones
+func symbolATRPercent(dir, symbol, interval string, period int) (float64, error) {
+	file, err := findKlineFile(dir, symbol, interval)
+	if err != nil {
+		return 0, err
+	}
+
+	klines, err := utils.ReadKlinesFromCSV(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read klines from %s: %w", file, err)
+	}
+	if len(klines) == 0 {
+		return 0, fmt.Errorf("no klines in %s", file)
+	}
+
+	atr := indicators.NewATRStream(indicators.ATRConfig{
+		IndicatorConfig: indicators.IndicatorConfig{Period: period},
+	})
+	var sumPct float64
+	var bars int
+	for _, k := range klines {
+		value, ready := atr.Update(k)
+		if !ready || k.Close == 0 {
+			continue
+		}
+		sumPct += value / k.Close * 100
+		bars++
+	}
+	if bars == 0 {
+		return 0, fmt.Errorf("not enough klines in %s for a %d period ATR", file, period)
+	}
+	return sumPct / float64(bars), nil
+}
+
+// findKlineFile locates a kline CSV written by fetch_klines (e.g., ETHUSDT_15m_20250207_to_20250507.csv)
+func findKlineFile(dir, symbol, interval string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := fmt.Sprintf("%s_%s_", symbol, interval)
+	var candidates []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && isCSVFile(entry.Name()) {
+			candidates = append(candidates, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no kline file found for %s %s in %s", symbol, interval, dir)
+	}
+
+	// Filenames embed the date range, so the lexically last one is the most recent
+	sort.Strings(candidates)
+	return candidates[len(candidates)-1], nil
+}
+
+// stdDev calculates the sample standard deviation of values
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values) - 1)
+	return math.Sqrt(variance)
+}
+
+// printSymbolComparison prints the cross-symbol comparison table for all backtest files
+func printSymbolComparison(files []string, klineDir, interval string, atrPeriod int) {
+	var allTrades []*domain.Trade
+	for _, file := range files {
+		trades, err := utils.ReadTradesFromCSV(file)
+		if err != nil {
+			fmt.Printf("Error reading trades from %s: %v\n", file, err)
+			continue
+		}
+		allTrades = append(allTrades, trades...)
+	}
+
+	comparisons := compareSymbols(allTrades, klineDir, interval, atrPeriod)
+	if len(comparisons) == 0 {
+		fmt.Println("No trades available for cross-symbol comparison")
+		return
+	}
+	if comparisons[0].Source == VolatilityFromReturns {
+		fmt.Println("Kline data is missing for some symbols; normalizing every symbol by the standard deviation of its trade returns")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.AlignRight|tabwriter.Debug)
+	fmt.Fprintln(w, "Symbol\tTrades\tTotalPnL\tAvgRet%\tVol%\tVolSource\tNormAvg\tNormTotal\t")
+	for _, c := range comparisons {
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%.3f\t%.3f\t%s\t%.3f\t%.3f\t\n",
+			c.Symbol,
+			c.Trades,
+			c.TotalPnL,
+			c.AvgReturnPct,
+			c.VolatilityPct,
+			c.Source,
+			c.NormalizedReturn,
+			c.NormalizedTotal,
+		)
+	}
+	w.Flush()
+}
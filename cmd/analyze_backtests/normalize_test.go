@@ -0,0 +1,114 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/utils"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTradeReturnsPct(t *testing.T) {
+	tests := []struct {
+		name  string
+		trade *domain.Trade
+		want  float64
+	}{
+		{"long gain", &domain.Trade{Side: domain.PositionSideLong, EntryPrice: 100, ExitPrice: 102}, 2},
+		{"long loss", &domain.Trade{Side: domain.PositionSideLong, EntryPrice: 100, ExitPrice: 99}, -1},
+		{"short gain", &domain.Trade{Side: domain.PositionSideShort, EntryPrice: 100, ExitPrice: 97}, 3},
+		{"short loss", &domain.Trade{Side: domain.PositionSideShort, EntryPrice: 100, ExitPrice: 101}, -1},
+		{"no entry price", &domain.Trade{Side: domain.PositionSideLong, ExitPrice: 101}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tradeReturnsPct([]*domain.Trade{tt.trade})
+			if len(got) != 1 || math.Abs(got[0]-tt.want) > 1e-9 {
+				t.Errorf("Expected return %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStdDev(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"empty", nil, 0},
+		{"single value", []float64{5}, 0},
+		{"constant", []float64{2, 2, 2}, 0},
+		{"sample", []float64{2, 4, 4, 4, 5, 5, 7, 9}, math.Sqrt(32.0 / 7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stdDev(tt.values); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// writeKlines writes bars with a constant 2% range around a close of 100 in fetch_klines' naming
+func writeKlines(t *testing.T, dir, symbol string) {
+	t.Helper()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var klines []*domain.Kline
+	for i := 0; i < 20; i++ {
+		open := start.Add(time.Duration(i) * 15 * time.Minute)
+		klines = append(klines, &domain.Kline{
+			OpenTime: open, CloseTime: open.Add(15 * time.Minute), Symbol: symbol, Interval: "15m",
+			Open: 100, High: 101, Low: 99, Close: 100, Volume: 1,
+		})
+	}
+	if err := utils.WriteKlinesToCSV(klines, filepath.Join(dir, symbol+"_15m_20250101_to_20250102.csv")); err != nil {
+		t.Fatalf("Failed to write klines: %v", err)
+	}
+}
+
+func TestCompareSymbols_VolatilitySource(t *testing.T) {
+	trades := []*domain.Trade{
+		{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 100, ExitPrice: 101, PNL: 1},
+		{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 100, ExitPrice: 103, PNL: 3},
+		{Symbol: "BTCUSDT", Side: domain.PositionSideShort, EntryPrice: 100, ExitPrice: 99, PNL: 1},
+		{Symbol: "BTCUSDT", Side: domain.PositionSideShort, EntryPrice: 100, ExitPrice: 101, PNL: -1},
+	}
+	tests := []struct {
+		name       string
+		klines     []string
+		wantSource VolatilitySource
+		wantVol    map[string]float64
+	}{
+		{"kline data for every symbol", []string{"ETHUSDT", "BTCUSDT"}, VolatilityFromATR,
+			map[string]float64{"ETHUSDT": 2, "BTCUSDT": 2}},
+		{"kline data for some symbols", []string{"ETHUSDT"}, VolatilityFromReturns,
+			map[string]float64{"ETHUSDT": math.Sqrt2, "BTCUSDT": math.Sqrt2}},
+		{"no kline data", nil, VolatilityFromReturns,
+			map[string]float64{"ETHUSDT": math.Sqrt2, "BTCUSDT": math.Sqrt2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, symbol := range tt.klines {
+				writeKlines(t, dir, symbol)
+			}
+			comparisons := compareSymbols(trades, dir, "15m", 3)
+			if len(comparisons) != 2 {
+				t.Fatalf("Expected 2 symbols, got %d", len(comparisons))
+			}
+			for _, c := range comparisons {
+				if c.Source != tt.wantSource {
+					t.Errorf("%s: expected volatility source %s, got %s", c.Symbol, tt.wantSource, c.Source)
+				}
+				if math.Abs(c.VolatilityPct-tt.wantVol[c.Symbol]) > 1e-9 {
+					t.Errorf("%s: expected volatility %v, got %v", c.Symbol, tt.wantVol[c.Symbol], c.VolatilityPct)
+				}
+			}
+			if comparisons[0].Symbol != "ETHUSDT" {
+				t.Errorf("Expected ETHUSDT to rank first, got %s", comparisons[0].Symbol)
+			}
+		})
+	}
+}
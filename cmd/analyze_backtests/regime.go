@@ -0,0 +1,47 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/strategy/analytics"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
+	"log"
+	"path/filepath"
+)
+
+// analyzeRegimes prints the win rate and expectancy of each file's trades by the market regime at
+// entry, to show where a strategy earns its PnL
+func analyzeRegimes(files []string) {
+	for _, file := range files {
+		trades, err := utils.ReadTradesFromCSV(file)
+		if err != nil {
+			log.Printf("Error reading trades from %s: %v", file, err)
+			continue
+		}
+
+		breakdown := analytics.AnalyzeRegimes(trades)
+		fmt.Printf("\nFile: %s\n", filepath.Base(file))
+		if len(breakdown.Combined) == 0 {
+			fmt.Println("No regimes recorded (file written before regime tagging)")
+			continue
+		}
+
+		fmt.Println("Regime\tTrades\tWinRate\tTotal PnL\tExpectancy")
+		for _, group := range []struct {
+			name  string
+			stats []analytics.RegimeStats
+		}{
+			{"trend", breakdown.Trend},
+			{"volatility", breakdown.Volatility},
+			{"higher TF", breakdown.HigherTF},
+			{"combined", breakdown.Combined},
+		} {
+			for _, s := range group.stats {
+				label := s.Regime
+				if label == "" {
+					label = "?"
+				}
+				fmt.Printf("%s: %s\t%d\t%.2f\t%.2f\t%.2f\n", group.name, label, s.Trades, s.WinRate*100, s.TotalPNL, s.Expectancy)
+			}
+		}
+	}
+}
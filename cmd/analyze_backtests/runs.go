@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// compareRuns prints the backtest runs recorded in the database, newest first, followed by the
+// average results of every configuration that was run more than once (e.g., on different data)
+func compareRuns(ctx context.Context, path string, filter ports.BacktestRunFilter) {
+	repo, err := sqlite.NewRepository(sqlite.Config{DBPath: path, Logger: logger.NewStdLogger(logger.LevelWarn)})
+	if err != nil {
+		log.Printf("Error opening database %s: %v", path, err)
+		return
+	}
+	defer repo.Close()
+
+	runs, err := repo.FindBacktestRuns(ctx, filter)
+	if err != nil {
+		log.Printf("Error reading backtest runs: %v", err)
+		return
+	}
+	if len(runs) == 0 {
+		fmt.Println("No backtest runs recorded. The backtest runner records them unless run with -record=false.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.AlignRight|tabwriter.Debug)
+	fmt.Fprintln(w, "Run\tRecorded\tStrategy\tSymbol\tData\tConfig\tTP%\tSL%\tTrades\tWinRate\tTotalPnL\tMaxDD\tSharpe\t")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s %s\t%s - %s\t%s\t%.2f\t%.2f\t%d\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			run.ID, run.CreatedAt.Format("2006-01-02 15:04"), run.Strategy, run.Symbol, run.Interval,
+			run.DataStart.Format("2006-01-02"), run.DataEnd.Format("2006-01-02"), run.ConfigHash,
+			run.Params["take_profit"]*100, run.Params["stop_loss"]*100,
+			run.TotalTrades, run.WinRate*100, run.TotalProfit, run.MaxDrawdown, run.SharpeRatio)
+	}
+	w.Flush()
+
+	// Configurations run more than once, by their average results
+	byConfig := make(map[string][]*domain.BacktestRun)
+	for _, run := range runs {
+		byConfig[run.ConfigHash] = append(byConfig[run.ConfigHash], run)
+	}
+	hashes := make([]string, 0, len(byConfig))
+	for hash, group := range byConfig {
+		if len(group) > 1 {
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return
+	}
+	sort.Strings(hashes)
+
+	fmt.Println("\nRepeated configurations (averages across runs)")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.AlignRight|tabwriter.Debug)
+	fmt.Fprintln(w, "Config\tRuns\tTP%\tTrades\tWinRate\tTotalPnL\tMaxDD\t")
+	for _, hash := range hashes {
+		group := byConfig[hash]
+		var trades, winRate, pnl, drawdown float64
+		for _, run := range group {
+			trades += float64(run.TotalTrades)
+			winRate += run.WinRate
+			pnl += run.TotalProfit
+			drawdown += run.MaxDrawdown
+		}
+		n := float64(len(group))
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%.1f\t%.2f\t%.2f\t%.2f\t\n", hash, len(group), group[0].Params["take_profit"]*100,
+			trades/n, winRate/n*100, pnl/n, drawdown/n)
+	}
+	w.Flush()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/risk"
+	"cryptoMegaBot/internal/strategy/backtesting"
+	"fmt"
+	"io"
+	"log"
+	"text/tabwriter"
+)
+
+// executionComparison holds the metrics of an idealized run (no fees, funding or slippage, fills
+// only at the signal bar's close) next to the realistic run of the same configuration
+type executionComparison struct {
+	Ideal     *backtesting.BacktestResult
+	Realistic *backtesting.BacktestResult
+}
+
+// idealConfig returns config without frictions: fees, funding and slippage are zero, exits are
+// only checked at each bar's close and orders fill at the signal bar's close
+func idealConfig(config backtesting.BacktestConfig) backtesting.BacktestConfig {
+	config.Fees = domain.FeeModel{}
+	config.Slippage = 0
+	config.Intrabar = backtesting.IntrabarOff
+	config.Timing = backtesting.ExecutionSignalClose
+	config.Progress = nil
+	config.TradeLog = nil // The log holds the realistic run's trades
+	config.RecordStopPaths = false
+	if config.StreakSizer != nil {
+		config.StreakSizer = risk.NewStreakSizer(config.StreakSizer.Ladder()) // Don't share the streak with the realistic run
+	}
+	return config
+}
+
+// runIdeal runs the idealized variant of config on its own strategy instance from newStrategy
+func runIdeal(ctx context.Context, newStrategy func() (backtestStrategy, error), klines []*domain.Kline, config backtesting.BacktestConfig, appLogger *logger.StdLogger, atrMultiplier float64) *backtesting.BacktestResult {
+	strategy, err := newStrategy()
+	if err != nil {
+		log.Fatalf("Failed to create strategy: %v", err)
+	}
+	result, err := runBacktestWithDynamicPositionSizing(ctx, strategy, klines, idealConfig(config), appLogger, atrMultiplier)
+	if result == nil {
+		appLogger.Error(ctx, err, "Ideal backtest error")
+	}
+	return result
+}
+
+// newExecutionComparison pairs the ideal and realistic results of one configuration
+func newExecutionComparison(ideal, realistic *backtesting.BacktestResult) executionComparison {
+	return executionComparison{Ideal: ideal, Realistic: realistic}
+}
+
+// EdgeRetained returns the share of the ideal profit left after frictions; it is 0 when the
+// ideal run made no profit, since there is no edge to retain
+func (c executionComparison) EdgeRetained() float64 {
+	if c.Ideal.TotalProfit <= 0 {
+		return 0
+	}
+	return c.Realistic.TotalProfit / c.Ideal.TotalProfit
+}
+
+// printExecutionComparison writes the ideal and realistic metrics side by side with their difference
+func printExecutionComparison(out io.Writer, c executionComparison) {
+	if c.Ideal == nil || c.Realistic == nil {
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', tabwriter.AlignRight|tabwriter.Debug)
+	fmt.Fprintln(w, "Metric\tIdeal\tRealistic\tDiff\t")
+	row := func(name, format string, ideal, realistic float64) {
+		fmt.Fprintf(w, "%s\t"+format+"\t"+format+"\t"+format+"\t\n", name, ideal, realistic, realistic-ideal)
+	}
+	row("Trades", "%.0f", float64(c.Ideal.TotalTrades), float64(c.Realistic.TotalTrades))
+	row("WinRate%", "%.2f", c.Ideal.WinRate*100, c.Realistic.WinRate*100)
+	row("TotalPnL", "%.2f", c.Ideal.TotalProfit, c.Realistic.TotalProfit)
+	row("ProfitFactor", "%.2f", c.Ideal.ProfitFactor, c.Realistic.ProfitFactor)
+	row("Sharpe", "%.2f", c.Ideal.SharpeRatio, c.Realistic.SharpeRatio)
+	row("MaxDD", "%.2f", c.Ideal.MaxDrawdown, c.Realistic.MaxDrawdown)
+	row("FinalBalance", "%.2f", c.Ideal.FinalBalance, c.Realistic.FinalBalance)
+	w.Flush()
+	if c.Ideal.TotalProfit > 0 {
+		fmt.Fprintf(out, "Edge retained after frictions: %.1f%%\n", c.EdgeRetained()*100)
+	} else {
+		fmt.Fprintln(out, "Edge retained after frictions: n/a (no ideal profit)")
+	}
+}
@@ -4,14 +4,24 @@ import (
 	"context"
 	"cryptoMegaBot/config"
 	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
 	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/money"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+	"cryptoMegaBot/internal/strategy/analytics"
 	"cryptoMegaBot/internal/strategy/backtesting"
 	"cryptoMegaBot/internal/strategy/strategies"
+	"cryptoMegaBot/internal/strategy/visualization"
 	"cryptoMegaBot/internal/utils"
+	"flag"
 	"fmt"
 	"log"
 	"math"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -22,6 +32,39 @@ type KlineWithTimeframe struct {
 }
 
 func main() {
+	strategyName := flag.String("strategy", improvedMACrossoverStrategy, "Strategy to backtest: improved_ma_crossover, or meta (its META_STRATEGY_CHILDREN voting with META_STRATEGY_QUORUM)")
+	seed := flag.Int64("seed", 0, "Random seed for reproducible backtests (0 picks a fresh seed)")
+	warmup := flag.Int("warmup", 0, "Bars after the strategy's required data points excluded from the results while indicators settle")
+	progress := flag.Bool("progress", false, "Print progress and intermediate equity while the backtest runs")
+	chart := flag.Bool("chart", false, "Write a chart of the klines and trades (JSON and HTML) next to each trades CSV")
+	openInterestFile := flag.String("open-interest", "", "Open interest CSV from fetch_klines for OPEN_INTEREST_CONFIRMATION (empty runs without open interest)")
+	intrabar := flag.String("intrabar", "off", "Check stops and take profits against each bar's high/low: off, pessimistic (stop first when both are reached) or optimistic")
+	record := flag.Bool("record", true, "Store each run's parameters, metrics and trades in the database at DB_PATH (backtest_runs)")
+	slippage := flag.Float64("slippage", 0, "Adverse price move (fraction) on market entries and exits, e.g. 0.0005 for 0.05%")
+	tradeLog := flag.Bool("trade-log", false, "Append each closed trade to data/improved_backtest_trades_tp<TP>.ndjson as it closes, for analysis during long runs")
+	compareExecution := flag.Bool("compare-execution", false, "Run each configuration twice, ideal (no fees, funding or slippage, fills at the signal bar's close) and realistic (fees, slippage, intrabar stops and -timing), and compare the results")
+	timingFlag := flag.String("timing", "next-open", "When market entries and strategy exits fill: next-open (the bar after the signal) or close (the signal bar's close, look-ahead)")
+	flag.Parse()
+
+	intrabarFill, err := backtesting.ParseIntrabarFill(*intrabar)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	timing, err := backtesting.ParseExecutionTiming(*timingFlag)
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+	if *slippage < 0 || *slippage >= 1 {
+		log.Fatalf("FATAL: -slippage must be in [0, 1)")
+	}
+	if *compareExecution && intrabarFill == backtesting.IntrabarOff {
+		intrabarFill = backtesting.IntrabarPessimistic // The realistic run checks stops inside bars
+	}
+
+	// Ctrl-C stops the running backtest and keeps the partial result
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// 1. Load Configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -30,6 +73,18 @@ func main() {
 
 	appLogger := logger.NewStdLogger(cfg.LogLevel)
 
+	// Runs are recorded for analyze_backtests -db; the backtest still runs without the database
+	var runRepo ports.BacktestRunRepository
+	if *record {
+		repo, err := sqlite.NewRepository(sqlite.Config{DBPath: cfg.DBPath, Logger: appLogger})
+		if err != nil {
+			appLogger.Warn(context.Background(), "Backtest runs won't be recorded", map[string]interface{}{"error": err.Error()})
+		} else {
+			defer repo.Close()
+			runRepo = repo
+		}
+	}
+
 	// 2. Load klines from CSV for multiple timeframes
 	timeframes := []string{"5m", "15m", "1h", "4h", "1d"}
 	klinesMap := make(map[string][]*KlineWithTimeframe)
@@ -83,6 +138,19 @@ func main() {
 		}
 	}
 
+	// Verify the timeframes cover the same period, so higher timeframe bars line up with the base
+	aligned := make(map[string][]*domain.Kline, len(klinesMap))
+	for tf, klinesWithTF := range klinesMap {
+		aligned[tf] = make([]*domain.Kline, len(klinesWithTF))
+		for i, k := range klinesWithTF {
+			aligned[tf][i] = k.Kline
+		}
+	}
+	if _, err := utils.AlignTimeframes(aligned); err != nil {
+		appLogger.Warn(context.Background(), "Timeframes are not aligned, multi-timeframe results may be skewed",
+			map[string]interface{}{"error": err.Error()})
+	}
+
 	// Use 1h timeframe as the base for backtesting
 	baseTimeframe := "1h"
 	klines := make([]*domain.Kline, len(klinesMap[baseTimeframe]))
@@ -96,6 +164,16 @@ func main() {
 			"count":         len(klines),
 		})
 
+	// Open interest snapshots for the open interest confirmation
+	var openInterest []*domain.OpenInterest
+	if *openInterestFile != "" {
+		openInterest, err = utils.ReadOpenInterestFromCSV(*openInterestFile)
+		if err != nil {
+			log.Fatalf("FATAL: Failed to load open interest: %v", err)
+		}
+		appLogger.Info(context.Background(), "Loaded open interest", map[string]interface{}{"count": len(openInterest)})
+	}
+
 	// 3. Set up configs with improved parameters
 	tps := []float64{0.015, 0.02, 0.03} // 1.5%, 2.0%, 3.0% take profits
 
@@ -107,6 +185,7 @@ func main() {
 
 	leverage := 3 // Reduced from 4x to 3x for more conservative approach
 	initialFunds := 1000.0
+	maintenanceMarginRate := 0.005 // Binance's lowest ETHUSDT tier, for liquidations
 
 	// 4. Create improved strategy with optimized parameters for day trading
 	strategyConfig := strategies.MACrossoverConfig{
@@ -129,13 +208,14 @@ func main() {
 		ScalpSlowPeriod:   13,   // Slow MA period for scalping
 
 		// Day trading parameters - optimized for more frequent trading
-		MaxDailyLosses:         2,             // Maximum number of losing trades per day
-		MaxConsecutiveLosses:   2,             // Maximum consecutive losses before reducing size
-		MaxHoldingTime:         2 * time.Hour, // Reduced maximum time to hold a position (from 4h to 2h)
-		PartialProfitPct:       0.005,         // Take partial profits at 0.5% (reduced from 1%)
-		TrailingActivePct:      0.002,         // Activate trailing stop at 0.2% (reduced from 0.3%)
-		BreakEvenActivation:    0.002,         // Move to breakeven at 0.2% profit
-		TrailingStopTightening: true,          // Enable progressive tightening of trailing stop
+		MaxDailyLosses:         2,              // Maximum number of losing trades per day
+		MaxConsecutiveLosses:   2,              // Maximum consecutive losses before reducing size
+		MaxHoldingTime:         2 * time.Hour,  // Reduced maximum time to hold a position (from 4h to 2h)
+		PartialProfitPct:       0.005,          // Take partial profits at 0.5% (reduced from 1%)
+		TrailingActivePct:      0.002,          // Activate trailing stop at 0.2% (reduced from 0.3%)
+		BreakEvenActivation:    0.002,          // Move to breakeven at 0.2% profit
+		Fees:                   cfg.FeeModel(), // Breakeven covers fees and funding (TAKER_FEE_RATE / FUNDING_RATE)
+		TrailingStopTightening: true,           // Enable progressive tightening of trailing stop
 
 		// Risk management parameters
 		InitialRiskPerTrade:       0.005, // 0.5% risk per trade
@@ -144,9 +224,29 @@ func main() {
 		// Market hours parameters
 		TradingHoursOnly: false, // Not limiting to specific hours for backtesting
 		MaxLeverageUsed:  4.0,   // Maximum leverage to use
+
+		// Entry confirmation weights and thresholds (ENTRY_CONFIRMATIONS / ENTRY_MIN_CONFIRMATION_SCORE)
+		Confirmation: cfg.EntryConfirmation,
+
+		// Volume profile support/resistance zones (VOLUME_PROFILE_*)
+		UseVolumeProfile:     cfg.VolumeProfilePeriod > 0,
+		VolumeProfilePeriod:  cfg.VolumeProfilePeriod,
+		VolumeProfileBuckets: cfg.VolumeProfileBuckets,
+		VolumeProfileZonePct: cfg.VolumeProfileZonePct,
+
+		// Rising open interest required alongside price (OPEN_INTEREST_*)
+		UseOpenInterest:      cfg.OpenInterestConfirmation,
+		OpenInterestPeriod:   cfg.OpenInterestPeriod,
+		OpenInterestLookback: cfg.OpenInterestLookback,
+
+		// Re-entry rules by close reason (REENTRY_RULES)
+		ReEntry: cfg.ReEntry,
+
+		// Time-based exit after the maximum holding time (TIME_LIMIT_EXIT)
+		DisableTimeLimit: !cfg.TimeLimitExit,
 	}
 
-	strategy, err := strategies.NewImprovedMACrossover(strategyConfig, appLogger)
+	strategy, err := newBacktestStrategy(*strategyName, strategyConfig, cfg, appLogger)
 	if err != nil {
 		appLogger.Error(context.Background(), err, "Failed to create strategy")
 		log.Fatalf("Failed to create strategy: %v", err)
@@ -163,6 +263,36 @@ func main() {
 			TakeProfit:   tp,
 			Symbol:       "ETHUSDT",
 			Leverage:     leverage,
+			Seed:         *seed,
+			WarmupBars:   *warmup,
+			Fees:         cfg.FeeModel(),
+			Slippage:     *slippage,
+			Blackout:     cfg.Blackout,
+			Calendar:     cfg.TradingCalendar,
+			ScaleIn:      cfg.ScaleIn,
+			SessionEnd:   cfg.SessionEndTime, // Zero unless SESSION_END_TIME is set
+			Direction:    cfg.Direction,
+
+			MaintenanceMarginRate: maintenanceMarginRate,
+			RecordStopPaths:       *chart,
+			Intrabar:              intrabarFill,
+			Timing:                timing,
+			OpenInterest:          openInterest,
+		}
+		if len(cfg.StreakLadder) > 0 {
+			config.StreakSizer = risk.NewStreakSizer(cfg.StreakLadder) // Each run starts without a streak
+		}
+		if cfg.MaxExposurePct > 0 {
+			config.RiskManager = risk.NewRiskManager(risk.RiskConfig{MaxExposurePct: cfg.MaxExposurePct})
+		}
+		if *progress {
+			config.Progress = printProgress
+		}
+		if *tradeLog {
+			if config.TradeLog, err = backtesting.CreateTradeLog(fmt.Sprintf("data/improved_backtest_trades_tp%.1f.ndjson", tp*100)); err != nil {
+				log.Fatalf("FATAL: %v", err)
+			}
+			appLogger.Info(context.Background(), "Streaming trades to", map[string]interface{}{"filename": config.TradeLog.Path()})
 		}
 
 		// Use 15m timeframe as the base for day trading backtests
@@ -178,23 +308,44 @@ func main() {
 				"count":         len(klines),
 			})
 
+		// With -compare-execution the same configuration first runs without frictions, on a fresh
+		// strategy so both runs start from the same state
+		var ideal *backtesting.BacktestResult
+		if *compareExecution {
+			ideal = runIdeal(ctx, func() (backtestStrategy, error) {
+				return newBacktestStrategy(*strategyName, strategyConfig, cfg, appLogger)
+			}, klines, config, appLogger, atrMultiplier)
+			if strategy, err = newBacktestStrategy(*strategyName, strategyConfig, cfg, appLogger); err != nil {
+				log.Fatalf("Failed to create strategy: %v", err)
+			}
+		}
+
 		// Modify the backtest to use dynamic position sizing
 		result, err := runBacktestWithDynamicPositionSizing(
-			context.Background(),
+			ctx,
 			strategy,
 			klines,
 			config,
 			appLogger,
 			atrMultiplier,
 		)
+		if err := config.TradeLog.Close(); err != nil {
+			appLogger.Error(context.Background(), err, "Error closing trade log")
+		}
 
-		if err != nil {
+		if result == nil {
 			appLogger.Error(context.Background(), err, "Backtest error")
 			continue
 		}
+		if result.Aborted {
+			appLogger.Warn(context.Background(), "Backtest interrupted, reporting partial result", map[string]interface{}{
+				"BarsProcessed": result.BarsProcessed,
+				"TotalBars":     len(klines) - strategy.RequiredDataPoints(),
+			})
+		}
 
 		appLogger.Info(context.Background(), "Backtest result", map[string]interface{}{
-			"Strategy": "MACrossover",
+			"Strategy": strategy.Name(),
 			"TP":       tp * 100,
 			"Trades":   result.TotalTrades,
 			"WinRate":  result.WinRate * 100,
@@ -203,7 +354,84 @@ func main() {
 			"MaxDD":    result.MaxDrawdown,
 			"AvgWin":   result.AverageWin,
 			"AvgLoss":  result.AverageLoss,
+			"Seed":     result.Seed,
 		})
+		// AnalyzePerformance sorts the trades it is given; the chart export needs them aligned with StopPaths
+		performance := analytics.AnalyzePerformance(append([]*domain.Trade(nil), result.Trades...), config.InitialFunds)
+		appLogger.Info(context.Background(), "Risk-adjusted returns", map[string]interface{}{
+			"Sortino":        performance.SortinoRatio,
+			"Calmar":         performance.CalmarRatio,
+			"AnnualizedVol%": performance.AnnualizedVolatility * 100,
+		})
+		for _, regime := range performance.Regimes.Combined {
+			appLogger.Info(context.Background(), "Results by market regime at entry", map[string]interface{}{
+				"Regime":     regime.Regime,
+				"Trades":     regime.Trades,
+				"WinRate":    regime.WinRate * 100,
+				"PnL":        regime.TotalPNL,
+				"Expectancy": regime.Expectancy,
+			})
+		}
+		exposure := analytics.AnalyzeExposure(result.Trades, klines[0].OpenTime, klines[len(klines)-1].CloseTime, 15*time.Minute)
+		appLogger.Info(context.Background(), "Trade frequency and exposure", map[string]interface{}{
+			"TimeInMarket%": exposure.TimeInMarket * 100,
+			"AvgBarsHeld":   exposure.AverageBarsHeld,
+			"TradesPerDay":  exposure.TradesPerDay.Mean,
+			"MaxTradesDay":  exposure.TradesPerDay.Max,
+			"Days":          exposure.TradesPerDay.Count,
+		})
+		if len(result.Liquidations) > 0 || result.InsufficientMarginSkipped > 0 {
+			appLogger.Warn(context.Background(), "Positions liquidated or skipped for lack of margin", map[string]interface{}{
+				"Liquidations":    len(result.Liquidations),
+				"LiquidationLoss": result.LiquidationLoss,
+				"MarginSkipped":   result.InsufficientMarginSkipped,
+			})
+		}
+		if result.MaxExposureSkipped > 0 {
+			appLogger.Info(context.Background(), "Entries skipped at the maximum exposure", map[string]interface{}{
+				"Skipped":        result.MaxExposureSkipped,
+				"MaxExposurePct": cfg.MaxExposurePct,
+			})
+		}
+		if result.IntrabarExits > 0 {
+			appLogger.Info(context.Background(), "Exits filled inside a bar", map[string]interface{}{
+				"Exits":     result.IntrabarExits,
+				"Ambiguous": result.IntrabarAmbiguous,
+				"Model":     string(config.Intrabar),
+			})
+		}
+		if result.ScaleIns > 0 {
+			appLogger.Info(context.Background(), "Scale-in adds filled", map[string]interface{}{
+				"Adds": result.ScaleIns,
+			})
+		}
+		if result.SessionEndExits > 0 {
+			appLogger.Info(context.Background(), "Positions closed at the session end", map[string]interface{}{
+				"Exits": result.SessionEndExits,
+			})
+		}
+		if result.BlackoutSkipped > 0 {
+			appLogger.Info(context.Background(), "Entries skipped during blackout windows", map[string]interface{}{
+				"Skipped": result.BlackoutSkipped,
+			})
+		}
+		if result.CalendarSkipped > 0 {
+			appLogger.Info(context.Background(), "Entries skipped on days the trading calendar disables", map[string]interface{}{
+				"Skipped": result.CalendarSkipped,
+			})
+		}
+		if result.WarmupBars > 0 {
+			appLogger.Info(context.Background(), "Warm-up excluded from result", map[string]interface{}{
+				"Bars":   result.WarmupBars,
+				"Until":  result.WarmupEnd,
+				"Trades": len(result.WarmupTrades),
+				"PnL":    result.WarmupProfit,
+			})
+		}
+		if ideal != nil {
+			fmt.Printf("\nIdeal vs realistic execution (TP %.1f%%)\n", tp*100)
+			printExecutionComparison(os.Stdout, newExecutionComparison(ideal, result))
+		}
 
 		// Write trades to CSV
 		tradesFile := fmt.Sprintf("data/improved_backtest_trades_tp%.1f.csv", tp*100)
@@ -212,13 +440,52 @@ func main() {
 			appLogger.Error(context.Background(), err, "Error writing trades CSV")
 		}
 		appLogger.Info(context.Background(), "Trades saved to", map[string]interface{}{"filename": tradesFile})
+		if runRepo != nil {
+			run := backtestRun(*strategyName, baseTimeframe, strategyConfig, cfg.MetaStrategyChildren, cfg.MetaStrategyQuorum, config, klines, result)
+			if err := runRepo.SaveBacktestRun(context.Background(), run, result.Trades); err != nil {
+				appLogger.Error(context.Background(), err, "Error recording backtest run")
+			} else {
+				appLogger.Info(context.Background(), "Backtest run recorded", map[string]interface{}{"runID": run.ID, "configHash": run.ConfigHash})
+			}
+		}
+		if *chart {
+			chartFile := fmt.Sprintf("data/backtest_chart_tp%.1f", tp*100)
+			if err := visualization.NewChart(config.Symbol, klines, result).Export(chartFile); err != nil {
+				appLogger.Error(context.Background(), err, "Error writing backtest chart")
+			} else {
+				appLogger.Info(context.Background(), "Chart saved to", map[string]interface{}{"filename": chartFile + ".html"})
+			}
+		}
+
+		if result.Aborted {
+			break
+		}
 	}
 }
 
+// printProgress writes a single updating progress line to stderr
+func printProgress(p backtesting.Progress) {
+	fmt.Fprintf(os.Stderr, "\r%5.1f%% %s  balance %.2f  equity %.2f  trades %d",
+		p.Percent(), p.Time.Format("2006-01-02 15:04"), p.Balance, p.Equity, p.Trades)
+	if p.Bar == p.TotalBars {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// dynamicEntry is an entry signal of runBacktestWithDynamicPositionSizing with its size and ATR
+// taken at the signal bar's close
+type dynamicEntry struct {
+	positionSize float64
+	atr          float64
+	tag          domain.EntryTag
+	regime       domain.MarketRegime
+	warmup       bool
+}
+
 // runBacktestWithDynamicPositionSizing runs a backtest with dynamic position sizing based on volatility
 func runBacktestWithDynamicPositionSizing(
 	ctx context.Context,
-	strategy *strategies.MACrossover,
+	strategy backtestStrategy,
 	klines []*domain.Kline,
 	config backtesting.BacktestConfig,
 	logger *logger.StdLogger,
@@ -227,70 +494,279 @@ func runBacktestWithDynamicPositionSizing(
 	if len(klines) < strategy.RequiredDataPoints() {
 		return nil, fmt.Errorf("not enough data points for strategy")
 	}
+	if config.WarmupBars < 0 {
+		return nil, fmt.Errorf("warm-up bars cannot be negative")
+	}
+	warmupEnd := strategy.RequiredDataPoints() + config.WarmupBars
+	if warmupEnd >= len(klines) && config.WarmupBars > 0 {
+		return nil, fmt.Errorf("not enough data points for a %d bar warm-up", config.WarmupBars)
+	}
 
 	result := &backtesting.BacktestResult{
 		FinalBalance: config.InitialFunds,
+		Seed:         utils.ResolveSeed(config.Seed),
+		WarmupBars:   config.WarmupBars,
+	}
+	if warmupEnd < len(klines) {
+		result.WarmupEnd = klines[warmupEnd].OpenTime
 	}
 
 	var currentPosition *domain.Position
+	var positionInWarmup bool
+	var positionRegime domain.MarketRegime // Market regime at the position's entry
+	var fullSize float64                   // Position size before scaling in, for the size of its adds
+	var stopPath []backtesting.StopLevel
+	var pendingEntry *dynamicEntry     // Market entry filling at the next bar's open
+	var pendingExit domain.CloseReason // Strategy exit filling at the next bar's open
 	var peakBalance = config.InitialFunds
 	var trades []*domain.Trade
+	totalBars := len(klines) - strategy.RequiredDataPoints()
+
+	// enter opens entry's position at price, skipping it when it's invalid or exceeds the balance
+	// or the maximum exposure
+	enter := func(entry dynamicEntry, price float64, at time.Time) {
+		// Use ATR-based stop loss or default stop loss, whichever is wider
+		atrStopLoss := price * (1 - (entry.atr * atrMultiplier / price))
+		defaultStopLoss := price * (1 - config.StopLoss)
+		stopLoss := math.Min(atrStopLoss, defaultStopLoss)
+
+		position := &domain.Position{
+			Symbol:               config.Symbol,
+			EntryPrice:           config.Slipped(price, true), // Market entry
+			Quantity:             config.ScaleIn.InitialQuantity(entry.positionSize),
+			Leverage:             config.Leverage,
+			StopLoss:             stopLoss,
+			TakeProfit:           price * (1 + config.TakeProfit),
+			EntryTime:            at,
+			TrailingStopPrice:    0, // Will be initialized when profit reaches threshold
+			TrailingStopDistance: 0, // Will be set when trailing stop is activated
+			EntryTag:             entry.tag,
+		}
+		if config.ScaleIn.Enabled() {
+			position.ScaleInBasePrice = price
+		}
+		if err := position.Open(); err != nil {
+			logger.Warn(ctx, "Skipping invalid entry", map[string]interface{}{"error": err.Error(), "positionSize": entry.positionSize})
+			return
+		}
+		if position.EntryPrice*position.Quantity > result.FinalBalance {
+			if !entry.warmup {
+				result.InsufficientMarginSkipped++
+			}
+			return
+		}
+		if config.RiskManager != nil && config.RiskManager.CheckExposure(0, position.EntryPrice*position.Quantity, result.FinalBalance) != nil {
+			if !entry.warmup {
+				result.MaxExposureSkipped++
+			}
+			return
+		}
+		currentPosition = position
+		positionRegime = entry.regime
+		fullSize = entry.positionSize
+		stopPath = nil
+		positionInWarmup = entry.warmup
+		if !positionInWarmup {
+			result.TotalTrades++
+		}
+	}
 
 	// Iterate through klines
 	for i := strategy.RequiredDataPoints(); i < len(klines); i++ {
+		if ctx.Err() != nil {
+			result.Aborted = true
+			break
+		}
 		currentKline := klines[i]
 		historicalKlines := klines[:i+1]
+		blackout, _ := config.Blackout.Active(currentKline.OpenTime)
+		sessionEnded := domain.SessionEnded(currentKline.OpenTime, config.SessionEnd)
+		openPrice := currentKline.Open
+		if openPrice <= 0 {
+			openPrice = klines[i-1].Close
+		}
+
+		// Fill the market entry signaled at the previous bar's close, unless the session ended
+		if pendingEntry != nil {
+			if !sessionEnded {
+				enter(*pendingEntry, openPrice, currentKline.OpenTime)
+			}
+			pendingEntry = nil
+		}
 
 		// Check if we should close an existing position
 		if currentPosition != nil {
-			shouldClose, reason := strategy.ShouldClosePosition(ctx, currentPosition, historicalKlines, currentKline.Close)
+			if !sessionEnded {
+				currentPosition.TrackExcursion(currentKline.Low, currentKline.High, currentKline.Close)
+			}
+			if blackout {
+				if stop, ok := config.Blackout.TightenedStop(currentPosition, currentKline.Close); ok {
+					currentPosition.StopLoss = stop
+				}
+			}
+			exitPrice := currentKline.Close
+			marketExit := true // Take profits filled inside a bar rest as limit orders
+			var shouldClose bool
+			var reason domain.CloseReason
+			liquidationPrice := currentPosition.LiquidationPrice(config.MaintenanceMarginRate)
+			liquidated := liquidationPrice > 0 && currentKline.Low > 0 && currentKline.Low <= liquidationPrice
+			if pendingExit != "" {
+				// Signaled at the previous bar's close, filled at this bar's open
+				shouldClose, reason, exitPrice = true, pendingExit, openPrice
+				pendingExit = ""
+			} else if sessionEnded {
+				// Flattened at the bar's open, before it trades towards any stop
+				shouldClose, reason, exitPrice = true, domain.CloseReasonSessionEnd, currentKline.Open
+				if !positionInWarmup {
+					result.SessionEndExits++
+				}
+			} else if stopPrice, stopReason, ambiguous, stopped := config.Intrabar.Exit(currentPosition, currentKline); stopped && (!liquidated || stopPrice > liquidationPrice) {
+				shouldClose, reason, exitPrice = true, stopReason, stopPrice
+				marketExit = stopReason != domain.CloseReasonTakeProfit
+				if !positionInWarmup {
+					result.IntrabarExits++
+					if ambiguous {
+						result.IntrabarAmbiguous++
+					}
+				}
+			} else if liquidated {
+				shouldClose, reason, exitPrice, marketExit = true, domain.CloseReasonLiquidation, liquidationPrice, false
+			} else {
+				shouldClose, reason = strategy.ShouldClosePosition(ctx, currentPosition, historicalKlines, currentKline.Close)
+				if shouldClose && config.Timing == backtesting.ExecutionNextOpen {
+					pendingExit, shouldClose = reason, false
+				}
+			}
+			if config.RecordStopPaths {
+				stopPath = append(stopPath, stopLevel(currentPosition, currentKline.OpenTime))
+			}
 			if shouldClose {
-				// Calculate profit/loss
-				pnl := calculatePNL(currentPosition, currentKline.Close)
-				result.TotalProfit += pnl
-				result.FinalBalance += pnl
-
-				// Update trade statistics
-				if pnl > 0 {
-					result.WinningTrades++
-					result.AverageWin = (result.AverageWin*float64(result.WinningTrades-1) + pnl) / float64(result.WinningTrades)
-				} else {
-					result.LosingTrades++
-					result.AverageLoss = (result.AverageLoss*float64(result.LosingTrades-1) + pnl) / float64(result.LosingTrades)
+				if marketExit {
+					exitPrice = config.Slipped(exitPrice, false)
+				}
+
+				// Calculate profit/loss; a liquidation loses the whole margin and the entry fee
+				pnl := calculatePNL(currentPosition, exitPrice, currentKline.OpenTime, config.Fees)
+				if reason == domain.CloseReasonLiquidation {
+					entryCosts := config.Fees.Fees(currentPosition.EntryPrice, 0, currentPosition.Quantity) +
+						config.Fees.Funding(currentPosition.EntryPrice, currentPosition.Quantity, currentKline.OpenTime.Sub(currentPosition.EntryTime))
+					pnl = -currentPosition.EntryPrice*currentPosition.Quantity - entryCosts*float64(currentPosition.Leverage)
 				}
 
-				// Update max drawdown
-				if result.FinalBalance > peakBalance {
-					peakBalance = result.FinalBalance
+				// Record trade (with fee-adjusted PNL rather than the position's gross PNL)
+				if err := currentPosition.Close(exitPrice, currentKline.OpenTime, reason); err != nil {
+					return nil, fmt.Errorf("failed to close backtest position: %w", err)
 				}
-				drawdown := (peakBalance - result.FinalBalance) / peakBalance
-				if drawdown > result.MaxDrawdown {
-					result.MaxDrawdown = drawdown
+				strategy.PositionClosed(ctx, currentPosition) // Re-entry rules (REENTRY_RULES)
+				trade := currentPosition.Trade()
+				trade.PNL = pnl
+				trade.Regime = positionRegime
+
+				if positionInWarmup {
+					// Warm-up trades leave the balance and statistics untouched
+					result.WarmupProfit += pnl
+					result.WarmupTrades = append(result.WarmupTrades, trade)
+				} else {
+					result.TotalProfit += pnl
+					result.FinalBalance += pnl
+
+					// Update trade statistics
+					if pnl > 0 {
+						result.WinningTrades++
+						result.AverageWin = (result.AverageWin*float64(result.WinningTrades-1) + pnl) / float64(result.WinningTrades)
+					} else {
+						result.LosingTrades++
+						result.AverageLoss = (result.AverageLoss*float64(result.LosingTrades-1) + pnl) / float64(result.LosingTrades)
+					}
+
+					// Update max drawdown
+					if result.FinalBalance > peakBalance {
+						peakBalance = result.FinalBalance
+					}
+					drawdown := (peakBalance - result.FinalBalance) / peakBalance
+					if drawdown > result.MaxDrawdown {
+						result.MaxDrawdown = drawdown
+					}
+					config.StreakSizer.Record(pnl)
+					trades = append(trades, trade)
+					if reason == domain.CloseReasonLiquidation {
+						result.Liquidations = append(result.Liquidations, trade)
+						result.LiquidationLoss += pnl
+					}
+					if config.RecordStopPaths {
+						result.StopPaths = append(result.StopPaths, stopPath)
+					}
 				}
 
-				// Record trade
-				trade := &domain.Trade{
-					PositionID:  currentPosition.ID,
-					Symbol:      config.Symbol,
-					EntryPrice:  currentPosition.EntryPrice,
-					ExitPrice:   currentKline.Close,
-					Quantity:    currentPosition.Quantity,
-					Leverage:    currentPosition.Leverage,
-					PNL:         pnl,
-					EntryTime:   currentPosition.EntryTime,
-					ExitTime:    currentKline.OpenTime,
-					CloseReason: reason,
+				event := backtesting.TradeEvent{Trade: trade, Warmup: positionInWarmup, Balance: result.FinalBalance}
+				if err := config.TradeLog.Write(event); err != nil {
+					return nil, err
+				}
+				if config.TradeEvents != nil {
+					select {
+					case config.TradeEvents <- event:
+					case <-ctx.Done():
+					}
 				}
-				trades = append(trades, trade)
 
 				currentPosition = nil
 			}
 		}
 
+		// Add to the position at the scale-in levels, from the bar after the entry until an exit is signaled
+		if currentPosition != nil && !blackout && pendingExit == "" && currentPosition.EntryTime.Before(currentKline.OpenTime) {
+			if level, ok := config.ScaleIn.NextAddPrice(currentPosition); ok && currentKline.Low < level {
+				fillPrice := level
+				if currentKline.Open > 0 && currentKline.Open < level {
+					fillPrice = currentKline.Open
+				}
+				quantity := config.ScaleIn.AddQuantity(fullSize)
+				if (currentPosition.EntryPrice*currentPosition.Quantity+fillPrice*quantity) <= result.FinalBalance &&
+					currentPosition.ScaleIn(quantity, fillPrice) == nil && !positionInWarmup {
+					result.ScaleIns++
+				}
+			}
+		}
+
+		result.BarsProcessed++
+		if config.Progress != nil && config.ShouldReportProgress(result.BarsProcessed, totalBars) {
+			equity := result.FinalBalance
+			if currentPosition != nil && !positionInWarmup {
+				equity += calculatePNL(currentPosition, currentKline.Close, currentKline.OpenTime, config.Fees)
+			}
+			config.Progress(backtesting.Progress{
+				Bar:       result.BarsProcessed,
+				TotalBars: totalBars,
+				Time:      currentKline.OpenTime,
+				Balance:   result.FinalBalance,
+				Equity:    equity,
+				Trades:    len(trades),
+			})
+		}
+
 		// Check if we should open a new position
-		if currentPosition == nil && strategy.ShouldEnterTrade(ctx, historicalKlines, currentKline.Close) {
-			// Calculate dynamic position size based on volatility
+		if currentPosition == nil && pendingEntry == nil && strategy.ShouldEnterTrade(ctx, historicalKlines, currentKline.Close) {
+			if sessionEnded {
+				continue
+			}
+			if blackout {
+				if i >= warmupEnd {
+					result.BlackoutSkipped++
+				}
+				continue
+			}
+			if closed, _ := config.Calendar.Closed(currentKline.OpenTime); closed {
+				if i >= warmupEnd {
+					result.CalendarSkipped++
+				}
+				continue
+			}
+
+			// Calculate dynamic position size based on volatility, scaled by the win/loss streak and
+			// the day's trading calendar factor
 			positionSize := strategy.GetPositionSize(ctx, historicalKlines, config.InitialFunds)
+			positionSize = config.StreakSizer.Apply(positionSize)
+			positionSize = config.Calendar.Apply(positionSize, currentKline.OpenTime)
 
 			// Calculate dynamic stop loss based on ATR
 			atr, err := strategy.GetATR(ctx, historicalKlines)
@@ -299,24 +775,21 @@ func runBacktestWithDynamicPositionSizing(
 				continue
 			}
 
-			// Use ATR-based stop loss or default stop loss, whichever is wider
-			atrStopLoss := currentKline.Close * (1 - (atr * atrMultiplier / currentKline.Close))
-			defaultStopLoss := currentKline.Close * (1 - config.StopLoss)
-			stopLoss := math.Min(atrStopLoss, defaultStopLoss)
-
-			currentPosition = &domain.Position{
-				Symbol:               config.Symbol,
-				EntryPrice:           currentKline.Close,
-				Quantity:             positionSize,
-				Leverage:             config.Leverage,
-				StopLoss:             stopLoss,
-				TakeProfit:           currentKline.Close * (1 + config.TakeProfit),
-				EntryTime:            currentKline.OpenTime,
-				Status:               domain.StatusOpen,
-				TrailingStopPrice:    0, // Will be initialized when profit reaches threshold
-				TrailingStopDistance: 0, // Will be set when trailing stop is activated
+			entry := dynamicEntry{
+				positionSize: positionSize,
+				atr:          atr,
+				tag:          strategy.LastEntryTag(),
+				regime:       analytics.DetectRegime(historicalKlines, config.Regime),
+				warmup:       i < warmupEnd,
+			}
+			if config.Timing == backtesting.ExecutionNextOpen {
+				pendingEntry = &entry
+				continue
+			}
+			enter(entry, currentKline.Close, currentKline.OpenTime)
+			if currentPosition != nil && config.RecordStopPaths {
+				stopPath = []backtesting.StopLevel{stopLevel(currentPosition, currentKline.OpenTime)}
 			}
-			result.TotalTrades++
 		}
 	}
 
@@ -327,61 +800,32 @@ func runBacktestWithDynamicPositionSizing(
 	}
 	result.ReturnOnInvestment = (result.FinalBalance - config.InitialFunds) / config.InitialFunds
 
-	// Calculate Sharpe Ratio (assuming risk-free rate of 0 for simplicity)
-	if len(trades) > 1 {
-		var returns []float64
-		for i := 1; i < len(trades); i++ {
-			returns = append(returns, trades[i].PNL/trades[i-1].PNL-1)
-		}
-		result.SharpeRatio = calculateSharpeRatio(returns)
-	}
+	// Calculate Sharpe Ratio (assuming risk-free rate of 0) from each trade's return on the balance before it
+	result.SharpeRatio = analytics.SharpeRatio(analytics.TradeReturns(trades, config.InitialFunds))
 
 	result.Trades = trades
 
+	if result.Aborted {
+		return result, ctx.Err()
+	}
 	return result, nil
 }
 
-// calculatePNL calculates the profit/loss for a position including trading fees
-func calculatePNL(position *domain.Position, currentPrice float64) float64 {
-	// Trading fee (0.1% for maker/taker on Binance futures)
-	const tradingFee = 0.001
+// stopLevel snapshots the position's protective levels at t
+func stopLevel(position *domain.Position, t time.Time) backtesting.StopLevel {
+	return backtesting.StopLevel{Time: t, StopLoss: position.StopLoss, TakeProfit: position.TakeProfit, TrailingStop: position.TrailingStopPrice}
+}
 
+// calculatePNL calculates the profit/loss for a position closed at exitTime including trading fees and funding
+func calculatePNL(position *domain.Position, currentPrice float64, exitTime time.Time, fees domain.FeeModel) float64 {
 	// Calculate raw PNL
-	rawPnl := (currentPrice - position.EntryPrice) * position.Quantity * float64(position.Leverage)
+	rawPnl := money.PnL(position.EntryPrice, currentPrice, position.Quantity, false) * float64(position.Leverage)
 
-	// Calculate fees (entry and exit)
-	entryFee := position.EntryPrice * position.Quantity * tradingFee
-	exitFee := currentPrice * position.Quantity * tradingFee
-	totalFees := (entryFee + exitFee) * float64(position.Leverage)
+	// Calculate fees (entry and exit) and funding accrued while holding
+	costs := fees.Fees(position.EntryPrice, currentPrice, position.Quantity) +
+		fees.Funding(position.EntryPrice, position.Quantity, exitTime.Sub(position.EntryTime))
+	totalFees := costs * float64(position.Leverage)
 
 	// Net PNL after fees
 	return rawPnl - totalFees
 }
-
-// calculateSharpeRatio calculates the Sharpe ratio for a series of returns
-func calculateSharpeRatio(returns []float64) float64 {
-	if len(returns) < 2 {
-		return 0
-	}
-
-	// Calculate mean return
-	var sum float64
-	for _, r := range returns {
-		sum += r
-	}
-	mean := sum / float64(len(returns))
-
-	// Calculate standard deviation
-	var variance float64
-	for _, r := range returns {
-		variance += (r - mean) * (r - mean)
-	}
-	variance /= float64(len(returns) - 1)
-	stdDev := math.Sqrt(variance)
-
-	// Calculate Sharpe ratio (assuming risk-free rate of 0)
-	if stdDev == 0 {
-		return 0
-	}
-	return mean / stdDev
-}
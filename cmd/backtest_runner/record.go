@@ -0,0 +1,54 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/backtesting"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"time"
+)
+
+// backtestRun describes a finished run for the backtest_runs table: the strategy and backtest
+// parameters that vary between runs, the data range and the resulting metrics. The meta strategy's
+// runs also record its quorum and the weights of its children
+func backtestRun(strategyName, interval string, strategyConfig strategies.MACrossoverConfig, metaChildren []strategies.MetaChildSpec, metaQuorum float64, config backtesting.BacktestConfig, klines []*domain.Kline, result *backtesting.BacktestResult) *domain.BacktestRun {
+	run := &domain.BacktestRun{
+		Strategy: "MACrossover",
+		Symbol:   config.Symbol,
+		Interval: interval,
+		Params: map[string]float64{
+			"take_profit":    config.TakeProfit,
+			"stop_loss":      config.StopLoss,
+			"leverage":       float64(config.Leverage),
+			"initial_funds":  config.InitialFunds,
+			"warmup_bars":    float64(config.WarmupBars),
+			"fast_ma_period": float64(strategyConfig.FastMAPeriod),
+			"slow_ma_period": float64(strategyConfig.SlowMAPeriod),
+			"signal_period":  float64(strategyConfig.SignalPeriod),
+			"atr_period":     float64(strategyConfig.ATRPeriod),
+			"atr_multiplier": strategyConfig.ATRMultiplier,
+		},
+		DataStart:    klines[0].OpenTime,
+		DataEnd:      klines[len(klines)-1].CloseTime,
+		Seed:         result.Seed,
+		CreatedAt:    time.Now(),
+		TotalTrades:  result.TotalTrades,
+		WinRate:      result.WinRate,
+		TotalProfit:  result.TotalProfit,
+		MaxDrawdown:  result.MaxDrawdown,
+		ProfitFactor: result.ProfitFactor,
+		SharpeRatio:  result.SharpeRatio,
+		FinalBalance: result.FinalBalance,
+	}
+	if strategyName == metaStrategy {
+		run.Strategy = "Meta"
+		run.Params["meta_quorum"] = metaQuorum // 0 requires all children
+		for _, child := range metaChildren {
+			weight := child.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			run.Params["meta_weight_"+child.Name] = weight
+		}
+	}
+	return run
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/strategy"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"fmt"
+)
+
+// Strategies the runner can backtest with -strategy
+const (
+	improvedMACrossoverStrategy = "improved_ma_crossover"
+	metaStrategy                = "meta"
+)
+
+// backtestStrategy is what runBacktestWithDynamicPositionSizing needs of a strategy: signals,
+// sizing and ATR, the tag of the last entry and the re-entry rules' close notification
+type backtestStrategy interface {
+	strategies.Strategy
+	LastEntryTag() domain.EntryTag
+	PositionClosed(ctx context.Context, position *domain.Position)
+}
+
+// newBacktestStrategy creates a fresh instance of the named strategy: the improved MA crossover
+// with strategyConfig, or the meta strategy voting with the META_STRATEGY_* children
+func newBacktestStrategy(name string, strategyConfig strategies.MACrossoverConfig, cfg *config.Config, appLogger *logger.StdLogger) (backtestStrategy, error) {
+	switch name {
+	case improvedMACrossoverStrategy:
+		return strategies.NewImprovedMACrossover(strategyConfig, appLogger)
+	case metaStrategy:
+		return newMetaStrategy(strategyConfig, cfg, appLogger)
+	default:
+		return nil, fmt.Errorf("unknown strategy %q (available: %s, %s)", name, improvedMACrossoverStrategy, metaStrategy)
+	}
+}
+
+// newMetaStrategy builds the meta strategy the way the bot registers it, from META_STRATEGY_CHILDREN,
+// _QUORUM and _EXIT_POLICY. Children are the improved MA crossover with the runner's parameters and
+// the original MA crossover with the configured ones
+func newMetaStrategy(strategyConfig strategies.MACrossoverConfig, cfg *config.Config, appLogger *logger.StdLogger) (*strategies.MetaStrategy, error) {
+	sized := false
+	for _, spec := range cfg.MetaStrategyChildren {
+		sized = sized || spec.Name == improvedMACrossoverStrategy
+	}
+	if !sized {
+		return nil, fmt.Errorf("meta strategy needs %s among its children to size positions", improvedMACrossoverStrategy)
+	}
+
+	metaCfg := strategies.MetaStrategyConfig{
+		Quorum:     cfg.MetaStrategyQuorum,
+		ExitPolicy: cfg.MetaStrategyExitPolicy,
+	}
+	return strategies.NewMetaStrategyFromSpecs(metaCfg, cfg.MetaStrategyChildren, func(name string) (ports.Strategy, error) {
+		switch name {
+		case improvedMACrossoverStrategy:
+			return strategies.NewImprovedMACrossover(strategyConfig, appLogger)
+		case "ma_crossover":
+			return strategy.New(strategy.Config{
+				ShortTermMAPeriod: cfg.StrategyShortMAPeriod,
+				LongTermMAPeriod:  cfg.StrategyLongMAPeriod,
+				EMAPeriod:         cfg.StrategyEMAPeriod,
+				RSIPeriod:         cfg.StrategyRSIPeriod,
+				RSIOverbought:     cfg.StrategyRSIOverbought,
+				RSIOversold:       cfg.StrategyRSIOversold,
+
+				BreakEvenActivation: cfg.BreakEvenActivation,
+				Fees:                cfg.FeeModel(),
+			}, appLogger)
+		default:
+			return nil, fmt.Errorf("can't be backtested (available: ma_crossover, %s)", improvedMACrossoverStrategy)
+		}
+	})
+}
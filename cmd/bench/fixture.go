@@ -0,0 +1,66 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
+	"math"
+	"time"
+)
+
+// fixtureStart is the open time of the first generated kline
+var fixtureStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// volatilityRegimes are the per-bar return volatilities the generated series switches between,
+// so trending, ranging and volatile stretches all show up in the benchmarks
+var volatilityRegimes = []float64{0.0015, 0.003, 0.006}
+
+// generateFixture writes a deterministic random-walk series of 15m ETHUSDT klines to path.
+// The same seed and bar count always produce the same file
+func generateFixture(path string, bars int, seed int64) error {
+	if bars <= 0 {
+		return fmt.Errorf("bar count must be positive")
+	}
+	rng := utils.NewRand(seed)
+	const interval = 15 * time.Minute
+
+	klines := make([]*domain.Kline, 0, bars)
+	price := 2000.0
+	vol := volatilityRegimes[1]
+	drift := 0.0
+	for i := 0; i < bars; i++ {
+		// Switch regime roughly every 500 bars
+		if rng.Intn(500) == 0 {
+			vol = volatilityRegimes[rng.Intn(len(volatilityRegimes))]
+			drift = (rng.Float64() - 0.5) * vol / 5
+		}
+
+		open := price
+		closePrice := open * math.Exp(drift+vol*rng.NormFloat64())
+		high := math.Max(open, closePrice) * (1 + math.Abs(rng.NormFloat64())*vol/2)
+		low := math.Min(open, closePrice) * (1 - math.Abs(rng.NormFloat64())*vol/2)
+		volume := 1000 * (1 + math.Abs(rng.NormFloat64())) * vol / volatilityRegimes[1]
+
+		openTime := fixtureStart.Add(time.Duration(i) * interval)
+		klines = append(klines, &domain.Kline{
+			OpenTime:  openTime,
+			CloseTime: openTime.Add(interval - time.Millisecond),
+			Symbol:    "ETHUSDT",
+			Interval:  "15m",
+			Open:      round(open, 2),
+			High:      round(high, 2),
+			Low:       round(low, 2),
+			Close:     round(closePrice, 2),
+			Volume:    round(volume, 3),
+			IsFinal:   true,
+		})
+		price = round(closePrice, 2)
+	}
+	return utils.WriteKlinesToCSV(klines, path)
+}
+
+// round rounds value to the given number of decimals
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
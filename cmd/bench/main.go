@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// defaultFixture is the bundled kline fixture the benchmarks run against
+const defaultFixture = "internal/strategy/testdata/ETHUSDT_15m_bench.csv.gz"
+
+// benchPackages are the packages with benchmarks
+var benchPackages = []string{
+	"./internal/strategy/indicators/",
+	"./internal/strategy/strategies/",
+	"./internal/strategy/backtesting/",
+}
+
+var (
+	benchRegexp = flag.String("bench", ".", "run only benchmarks matching the regular expression")
+	count       = flag.Int("count", 1, "run each benchmark n times")
+	benchTime   = flag.String("benchtime", "", "run time or iteration count per benchmark (e.g. 3s or 10x; go test default when empty)")
+	klinesPath  = flag.String("klines", defaultFixture, "kline CSV fixture the benchmarks run against")
+	outPath     = flag.String("out", "bench_output.txt", "file receiving the raw go test output, for comparing runs with benchstat (empty disables)")
+	generate    = flag.Bool("generate", false, "regenerate the kline fixture instead of running the benchmarks")
+	bars        = flag.Int("bars", 20000, "klines in a regenerated fixture")
+	seed        = flag.Int64("seed", 42, "random seed of a regenerated fixture")
+)
+
+func main() {
+	flag.Parse()
+
+	if *generate {
+		if err := generateFixture(*klinesPath, *bars, *seed); err != nil {
+			fmt.Printf("Error generating fixture: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d klines to %s\n", *bars, *klinesPath)
+		return
+	}
+
+	// The benchmarks run in their package directories, so they need an absolute path
+	fixture, err := filepath.Abs(*klinesPath)
+	if err != nil {
+		fmt.Printf("Error resolving fixture path: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(fixture); err != nil {
+		fmt.Printf("Kline fixture not found (run from the repository root or pass -klines): %v\n", err)
+		os.Exit(1)
+	}
+
+	args := []string{"test", "-run=^$", "-bench=" + *benchRegexp, "-benchmem", fmt.Sprintf("-count=%d", *count)}
+	if *benchTime != "" {
+		args = append(args, "-benchtime="+*benchTime)
+	}
+	args = append(args, benchPackages...)
+
+	var output bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Env = append(os.Environ(), "BENCH_KLINES="+fixture)
+	cmd.Stdout = io.MultiWriter(&output, os.Stdout)
+	cmd.Stderr = os.Stderr
+
+	fmt.Printf("Running benchmarks against %s with args: %s\n", *klinesPath, strings.Join(args, " "))
+	runErr := cmd.Run()
+
+	if *outPath != "" {
+		if err := os.WriteFile(*outPath, output.Bytes(), 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", *outPath, err)
+		}
+	}
+	printSummary(parseResults(&output))
+
+	if runErr != nil {
+		if exitErr, ok := runErr.(*exec.ExitError); ok {
+			os.Exit(exitErr.ExitCode())
+		}
+		fmt.Printf("Error running benchmarks: %v\n", runErr)
+		os.Exit(1)
+	}
+}
+
+// benchResult is one benchmark line of the go test output
+type benchResult struct {
+	Package string
+	Name    string
+	Metrics map[string]string // Value per unit, e.g. "ns/op" -> "1234"
+}
+
+// parseResults extracts the benchmark lines from go test -bench output
+func parseResults(r io.Reader) []benchResult {
+	var results []benchResult
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = filepath.Base(name)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		result := benchResult{Package: pkg, Name: fields[0], Metrics: make(map[string]string)}
+		// Fields after the iteration count come in value/unit pairs
+		for i := 2; i+1 < len(fields); i += 2 {
+			result.Metrics[fields[i+1]] = fields[i]
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// printSummary prints ns/op and allocations per benchmark
+func printSummary(results []benchResult) {
+	if len(results) == 0 {
+		fmt.Println("\nNo benchmark results")
+		return
+	}
+	fmt.Println("\n=== Benchmark Summary ===")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Package\tBenchmark\tns/op\tns/bar\tB/op\tallocs/op\t")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", r.Package, r.Name,
+			metric(r, "ns/op"), metric(r, "ns/bar"), metric(r, "B/op"), metric(r, "allocs/op"))
+	}
+	w.Flush()
+}
+
+// metric returns the result's value for unit, or "-" when it wasn't reported
+func metric(r benchResult, unit string) string {
+	if value, ok := r.Metrics[unit]; ok {
+		return value
+	}
+	return "-"
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/analytics"
+	"cryptoMegaBot/internal/strategy/backtesting"
+	"cryptoMegaBot/internal/utils"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+)
+
+// runResult is the outcome of one run over the dataset
+type runResult struct {
+	Spec     runSpec
+	Metrics  *analytics.PerformanceMetrics
+	DailyPNL []float64
+}
+
+func main() {
+	var runs runSpecs
+	flag.Var(&runs, "run", "run to compare as [label=]strategy[:param=value,...] (repeatable, at least 2; strategies: "+strings.Join(strategyNames(), ", ")+")")
+	klinesPath := flag.String("klines", "", "kline CSV every run is backtested on (required)")
+	symbol := flag.String("symbol", "ETHUSDT", "symbol of the klines")
+	funds := flag.Float64("funds", 1000, "initial funds of each run")
+	quantity := flag.Float64("quantity", 0.1, "position size in the base asset, or in the quote currency with -quantity-mode quote")
+	quantityMode := flag.String("quantity-mode", "base", "currency of -quantity: base or quote (converted at each entry price)")
+	stopLoss := flag.Float64("stoploss", 0.01, "stop loss as a fraction of the entry price")
+	takeProfit := flag.Float64("takeprofit", 0.02, "take profit as a fraction of the entry price")
+	leverage := flag.Int("leverage", 3, "leverage of each position")
+	seed := flag.Int64("seed", 0, "random seed shared by the backtests and the bootstrap (0 picks a fresh seed)")
+	iterations := flag.Int("bootstrap", 10000, "bootstrap resamples per pairwise significance test")
+	alpha := flag.Float64("alpha", 0.05, "significance level of the pairwise tests")
+	intrabar := flag.String("intrabar", "off", "check stops and take profits against each bar's high/low: off, pessimistic or optimistic")
+	directionFlag := flag.String("direction", "both", "sides entries may be opened on: both, long or short (backtests only open longs, so short skips every entry)")
+	timingFlag := flag.String("timing", "next-open", "when market entries and strategy exits fill: next-open (the bar after the signal) or close (the signal bar's close)")
+	flag.Parse()
+
+	if *klinesPath == "" || len(runs) < 2 {
+		fmt.Println("Usage: compare_strategies -klines <csv> -run <spec> -run <spec> [...]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	intrabarFill, err := backtesting.ParseIntrabarFill(*intrabar)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	timing, err := backtesting.ParseExecutionTiming(*timingFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	direction, err := domain.ParseTradeDirection(*directionFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	mode := domain.QuantityMode(strings.ToLower(*quantityMode))
+	if mode != domain.QuantityModeBase && mode != domain.QuantityModeQuote {
+		fmt.Printf("invalid -quantity-mode %q: must be base or quote\n", *quantityMode)
+		os.Exit(2)
+	}
+
+	// Ctrl-C stops the comparison
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	klines, err := utils.ReadKlinesFromCSV(*klinesPath)
+	if err != nil {
+		fmt.Printf("Error loading klines: %v\n", err)
+		os.Exit(1)
+	}
+	if len(klines) == 0 {
+		fmt.Printf("No klines in %s\n", *klinesPath)
+		os.Exit(1)
+	}
+	resolvedSeed := utils.ResolveSeed(*seed)
+	start, end := klines[0].OpenTime, klines[len(klines)-1].CloseTime
+	fmt.Printf("Comparing %d runs on %d klines from %s to %s (seed %d)\n",
+		len(runs), len(klines), start.Format("2006-01-02"), end.Format("2006-01-02"), resolvedSeed)
+
+	// Every run gets the same klines, backtest settings and seed, so only the strategy differs
+	appLogger := logger.NewStdLogger(logger.LevelWarn)
+	results := make([]runResult, 0, len(runs))
+	for _, spec := range runs {
+		strat, err := factories[spec.Strategy](spec.Params, appLogger)
+		if err != nil {
+			fmt.Printf("Error creating run %s: %v\n", spec.Label, err)
+			os.Exit(1)
+		}
+		result, err := backtesting.Backtest(ctx, strat, klines, backtesting.BacktestConfig{
+			StartTime:    start,
+			EndTime:      end,
+			InitialFunds: *funds,
+			PositionSize: *quantity,
+			QuantityMode: mode,
+			StopLoss:     *stopLoss,
+			TakeProfit:   *takeProfit,
+			Symbol:       *symbol,
+			Leverage:     *leverage,
+			Seed:         resolvedSeed,
+			Intrabar:     intrabarFill,
+			Timing:       timing,
+			Direction:    direction,
+		})
+		if err != nil {
+			fmt.Printf("Error backtesting run %s: %v\n", spec.Label, err)
+			os.Exit(1)
+		}
+		results = append(results, runResult{
+			Spec:     spec,
+			Metrics:  analytics.AnalyzePerformance(append([]*domain.Trade(nil), result.Trades...), *funds),
+			DailyPNL: analytics.DailyPNL(result.Trades, start, end),
+		})
+	}
+
+	printMetrics(results)
+	if err := printSignificance(results, *iterations, resolvedSeed, *alpha); err != nil {
+		fmt.Printf("Error testing significance: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// printMetrics prints the runs' metrics side by side
+func printMetrics(results []runResult) {
+	fmt.Println("\n=== Metrics ===")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Run\tTrades\tWin %\tProfit\tROI %\tMax DD %\tProfit Factor\tExpectancy\tSharpe\tSortino\tCalmar\t")
+	for _, r := range results {
+		m := r.Metrics
+		fmt.Fprintf(w, "%s\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.3f\t%.3f\t%.3f\t\n", r.Spec.Label,
+			m.TotalTrades, m.WinRate*100, m.TotalProfit, m.ReturnOnInvestment*100, m.MaxDrawdown*100,
+			m.ProfitFactor, m.Expectancy, m.SharpeRatio, m.SortinoRatio, m.CalmarRatio)
+	}
+	w.Flush()
+}
+
+// printSignificance runs a paired bootstrap test on the daily PNL of every pair of runs
+func printSignificance(results []runResult, iterations int, seed int64, alpha float64) error {
+	fmt.Printf("\n=== Daily PNL Differences (paired bootstrap, %d resamples) ===\n", iterations)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "A\tB\tMean A-B/day\t95% CI\tp-value\tSignificant\t")
+	pair := 0
+	for i := range results {
+		for j := i + 1; j < len(results); j++ {
+			a, b := results[i], results[j]
+			test, err := analytics.BootstrapMeanDifference(a.DailyPNL, b.DailyPNL, iterations, utils.DeriveSeed(seed, pair))
+			if err != nil {
+				return fmt.Errorf("%s vs %s: %w", a.Spec.Label, b.Spec.Label, err)
+			}
+			pair++
+			significant := "no"
+			if test.Significant(alpha) {
+				significant = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t[%.2f, %.2f]\t%.4f\t%s\t\n", a.Spec.Label, b.Spec.Label,
+				test.MeanDiff, test.CILow, test.CIHigh, test.PValue, significant)
+		}
+	}
+	w.Flush()
+	fmt.Printf("Significant: p < %v. A non-significant difference means the dataset can't tell the runs apart.\n", alpha)
+	return nil
+}
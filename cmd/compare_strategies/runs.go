@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/strategy"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// strategyFactory creates a strategy with the named parameters overriding its defaults
+type strategyFactory func(params map[string]float64, logger ports.Logger) (strategies.Strategy, error)
+
+// factories are the strategies a run can use, under the names and with the parameters of the
+// bot's strategy registry
+var factories = map[string]strategyFactory{
+	"ma_crossover":          newMACrossover,
+	"improved_ma_crossover": newImprovedMACrossover,
+}
+
+// runSpec is one labelled strategy and parameter set to backtest
+type runSpec struct {
+	Label    string
+	Strategy string
+	Params   map[string]float64
+}
+
+// runSpecs collects the repeated -run flag
+type runSpecs []runSpec
+
+func (r *runSpecs) String() string {
+	labels := make([]string, len(*r))
+	for i, spec := range *r {
+		labels[i] = spec.Label
+	}
+	return strings.Join(labels, ", ")
+}
+
+func (r *runSpecs) Set(value string) error {
+	spec, err := parseRunSpec(value)
+	if err != nil {
+		return err
+	}
+	for _, existing := range *r {
+		if existing.Label == spec.Label {
+			return fmt.Errorf("duplicate run label %q", spec.Label)
+		}
+	}
+	*r = append(*r, spec)
+	return nil
+}
+
+// parseRunSpec parses "[label=]strategy[:param=value,...]", e.g.
+// "fast=improved_ma_crossover:fastMAPeriod=5,slowMAPeriod=13". The label defaults to the spec
+func parseRunSpec(value string) (runSpec, error) {
+	spec := runSpec{Label: strings.TrimSpace(value), Params: make(map[string]float64)}
+	body := spec.Label
+	if label, rest, ok := strings.Cut(body, "="); ok && !strings.Contains(label, ":") {
+		spec.Label, body = strings.TrimSpace(label), rest
+	}
+	name, params, _ := strings.Cut(body, ":")
+	spec.Strategy = strings.TrimSpace(name)
+	if _, ok := factories[spec.Strategy]; !ok {
+		return runSpec{}, fmt.Errorf("unknown strategy %q (available: %s)", spec.Strategy, strings.Join(strategyNames(), ", "))
+	}
+	if spec.Label == "" {
+		return runSpec{}, fmt.Errorf("empty run label in %q", value)
+	}
+	if strings.TrimSpace(params) == "" {
+		return spec, nil
+	}
+	for _, param := range strings.Split(params, ",") {
+		key, raw, ok := strings.Cut(param, "=")
+		if !ok {
+			return runSpec{}, fmt.Errorf("parameter %q must be name=value", param)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return runSpec{}, fmt.Errorf("parameter %s: %w", key, err)
+		}
+		spec.Params[strings.TrimSpace(key)] = v
+	}
+	return spec, nil
+}
+
+// strategyNames returns the available strategy names in order
+func strategyNames() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newMACrossover creates the original MA crossover strategy with the bot's default periods
+func newMACrossover(params map[string]float64, logger ports.Logger) (strategies.Strategy, error) {
+	cfg := strategy.Config{
+		ShortTermMAPeriod: 20,
+		LongTermMAPeriod:  50,
+		EMAPeriod:         20,
+		RSIPeriod:         14,
+		RSIOverbought:     70,
+		RSIOversold:       30,
+	}
+	err := applyParams(params, map[string]*int{
+		"shortMAPeriod": &cfg.ShortTermMAPeriod,
+		"longMAPeriod":  &cfg.LongTermMAPeriod,
+		"emaPeriod":     &cfg.EMAPeriod,
+		"rsiPeriod":     &cfg.RSIPeriod,
+	}, map[string]*float64{
+		"rsiOverbought":       &cfg.RSIOverbought,
+		"rsiOversold":         &cfg.RSIOversold,
+		"breakEvenActivation": &cfg.BreakEvenActivation,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s, err := strategy.New(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &backtestableStrategy{Strategy: s}, nil
+}
+
+// newImprovedMACrossover creates the improved MA crossover strategy with the bot's default periods
+func newImprovedMACrossover(params map[string]float64, logger ports.Logger) (strategies.Strategy, error) {
+	cfg := strategies.MACrossoverConfig{
+		FastMAPeriod:  8,
+		SlowMAPeriod:  21,
+		SignalPeriod:  9,
+		ATRPeriod:     14,
+		ATRMultiplier: 2.5,
+	}
+	err := applyParams(params, map[string]*int{
+		"fastMAPeriod": &cfg.FastMAPeriod,
+		"slowMAPeriod": &cfg.SlowMAPeriod,
+		"signalPeriod": &cfg.SignalPeriod,
+		"atrPeriod":    &cfg.ATRPeriod,
+	}, map[string]*float64{
+		"atrMultiplier":       &cfg.ATRMultiplier,
+		"breakEvenActivation": &cfg.BreakEvenActivation,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return strategies.NewImprovedMACrossover(cfg, logger)
+}
+
+// applyParams overrides the configuration fields named in params. Integer fields only accept
+// whole numbers
+func applyParams(params map[string]float64, ints map[string]*int, floats map[string]*float64) error {
+	for name, value := range params {
+		if field, ok := ints[name]; ok {
+			if value != math.Trunc(value) {
+				return fmt.Errorf("parameter %s must be a whole number, got %v", name, value)
+			}
+			*field = int(value)
+			continue
+		}
+		if field, ok := floats[name]; ok {
+			*field = value
+			continue
+		}
+		return fmt.Errorf("unknown parameter %s", name)
+	}
+	return nil
+}
+
+// backtestableStrategy adapts the original strategy to the backtester, which sizes positions
+// itself and doesn't use the ATR
+type backtestableStrategy struct {
+	*strategy.Strategy
+}
+
+func (s *backtestableStrategy) Name() string {
+	return "Moving Average Crossover"
+}
+
+func (s *backtestableStrategy) GetPositionSize(ctx context.Context, klines []*domain.Kline, availableFunds float64) float64 {
+	return availableFunds
+}
+
+func (s *backtestableStrategy) GetATR(ctx context.Context, klines []*domain.Kline) (float64, error) {
+	return 0, fmt.Errorf("%s doesn't compute the ATR", s.Name())
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// issueKind identifies a class of inconsistency in the positions table
+type issueKind string
+
+const (
+	issueOpenWithExitData  issueKind = "open_with_exit_data"       // Open position carrying exit price, time or close reason
+	issueClosedWithoutExit issueKind = "closed_without_exit_price" // Closed position with no exit price (and so no meaningful PNL)
+	issueOrphanedOrderID   issueKind = "orphaned_order_id"         // SL/TP order ID that is malformed, unknown to the exchange or no longer active
+	issueClosedOnExchange  issueKind = "closed_on_exchange"        // Open position whose SL or TP order already filled
+)
+
+// issue is a single inconsistency found in a position record
+type issue struct {
+	position *domain.Position
+	kind     issueKind
+	detail   string
+	fix      func(pos *domain.Position) error // nil when the record needs manual review
+}
+
+// orderLookup fetches an order from the exchange's order history
+type orderLookup func(ctx context.Context, symbol string, orderID int64) (*ports.OrderResponse, error)
+
+// doctor scans position records for inconsistencies. Exchange cross-checks are skipped
+// when lookup is nil (no API keys configured)
+type doctor struct {
+	lookup orderLookup
+}
+
+// examine returns the issues found in one position
+func (d *doctor) examine(ctx context.Context, pos *domain.Position) ([]issue, error) {
+	var issues []issue
+
+	switch pos.Status {
+	case domain.StatusOpen:
+		if pos.ExitPrice != 0 || !pos.ExitTime.IsZero() || pos.CloseReason != "" {
+			issues = append(issues, issue{
+				position: pos,
+				kind:     issueOpenWithExitData,
+				detail:   fmt.Sprintf("exit price %.4f, exit time %s, close reason %q", pos.ExitPrice, formatTime(pos.ExitTime), pos.CloseReason),
+				fix:      clearExitData,
+			})
+		}
+	case domain.StatusClosed:
+		if pos.ExitPrice <= 0 {
+			issues = append(issues, issue{
+				position: pos,
+				kind:     issueClosedWithoutExit,
+				detail:   "no exit price recorded",
+			})
+		}
+	}
+
+	orderIssues, err := d.examineOrders(ctx, pos)
+	if err != nil {
+		return nil, err
+	}
+	for _, oi := range orderIssues {
+		// A filled SL/TP order supplies the exit data a closed position is missing
+		if oi.kind == issueClosedOnExchange && pos.Status == domain.StatusClosed {
+			if len(issues) > 0 && issues[0].kind == issueClosedWithoutExit {
+				issues[0].detail += "; " + oi.detail
+				issues[0].fix = oi.fix
+			}
+			continue
+		}
+		issues = append(issues, oi)
+	}
+	return issues, nil
+}
+
+// examineOrders checks the position's SL and TP order IDs. Open positions and closed ones missing
+// their exit are cross-checked against the exchange when possible; the exchange purges old canceled
+// orders, so the orders of settled positions aren't looked up
+func (d *doctor) examineOrders(ctx context.Context, pos *domain.Position) ([]issue, error) {
+	crossCheck := d.lookup != nil && (pos.IsOpen() || pos.ExitPrice <= 0)
+	orders := []struct {
+		label  string
+		id     *string
+		reason domain.CloseReason
+		clear  func(pos *domain.Position)
+	}{
+		{"SL", pos.StopLossOrderID, domain.CloseReasonStopLoss, func(p *domain.Position) { p.StopLossOrderID = nil }},
+		{"TP", pos.TakeProfitOrderID, domain.CloseReasonTakeProfit, func(p *domain.Position) { p.TakeProfitOrderID = nil }},
+	}
+
+	var issues []issue
+	filled := false
+	for _, o := range orders {
+		if o.id == nil {
+			continue
+		}
+		clearID := o.clear
+		orderID, err := strconv.ParseInt(*o.id, 10, 64)
+		if err != nil || orderID <= 0 {
+			issues = append(issues, issue{
+				position: pos,
+				kind:     issueOrphanedOrderID,
+				detail:   fmt.Sprintf("%s order ID %q is not a valid exchange order ID", o.label, *o.id),
+				fix:      func(p *domain.Position) error { clearID(p); return nil },
+			})
+			continue
+		}
+		if !crossCheck {
+			continue
+		}
+
+		order, err := d.lookup(ctx, pos.Symbol, orderID)
+		if errors.Is(err, ports.ErrOrderNotFound) {
+			if !pos.IsOpen() {
+				continue
+			}
+			issues = append(issues, issue{
+				position: pos,
+				kind:     issueOrphanedOrderID,
+				detail:   fmt.Sprintf("%s order %d not found on the exchange", o.label, orderID),
+				fix:      func(p *domain.Position) error { clearID(p); return nil },
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up %s order %d of position %d: %w", o.label, orderID, pos.ID, err)
+		}
+
+		if pos.IsOpen() && (order.Status == "CANCELED" || order.Status == "EXPIRED") {
+			issues = append(issues, issue{
+				position: pos,
+				kind:     issueOrphanedOrderID,
+				detail:   fmt.Sprintf("%s order %d is %s, the open position has no active %s order", o.label, orderID, order.Status, o.label),
+				fix:      func(p *domain.Position) error { clearID(p); return nil },
+			})
+			continue
+		}
+
+		// Only the first filled order closed the position
+		if order.Status != "FILLED" || filled {
+			continue
+		}
+		filled = true
+		detail := fmt.Sprintf("%s order %d filled at %.4f on %s", o.label, orderID, order.AvgPrice, formatTime(order.Timestamp))
+		var fix func(p *domain.Position) error
+		if order.AvgPrice > 0 {
+			exitPrice, exitTime, reason := order.AvgPrice, order.Timestamp, o.reason
+			fix = func(p *domain.Position) error { return settle(p, exitPrice, exitTime, reason) }
+		}
+		issues = append(issues, issue{position: pos, kind: issueClosedOnExchange, detail: detail, fix: fix})
+	}
+	return issues, nil
+}
+
+// clearExitData removes exit fields from a position that is still open
+func clearExitData(pos *domain.Position) error {
+	pos.ExitPrice = 0
+	pos.ExitTime = time.Time{}
+	pos.CloseReason = ""
+	pos.PNL = 0
+	return nil
+}
+
+// settle records the exit of a position from a filled order, recomputing its PNL. Exit time
+// and close reason already on a closed record are kept
+func settle(pos *domain.Position, exitPrice float64, exitTime time.Time, reason domain.CloseReason) error {
+	if pos.Status == domain.StatusClosed {
+		if !pos.ExitTime.IsZero() {
+			exitTime = pos.ExitTime
+		}
+		if pos.CloseReason != "" {
+			reason = pos.CloseReason
+		}
+	}
+	pos.Status = domain.StatusOpen // Close only settles open positions
+	return pos.Close(exitPrice, exitTime, reason)
+}
+
+// formatTime formats t for the report, or "-" when unset
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/binanceclient"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+var (
+	dbPath = flag.String("db", "", "path to the SQLite database (defaults to DB_PATH or ./data/trading_bot.db)")
+	symbol = flag.String("symbol", "", "only check positions for this symbol")
+	fix    = flag.Bool("fix", false, "repair the records that can be fixed automatically")
+
+	maintain      = flag.Bool("maintain", false, "run database maintenance instead of checking positions")
+	retentionDays = flag.Int("retention-days", 0, "with -maintain, delete cached klines and logged orders older than this many days (0 keeps them)")
+	vacuum        = flag.Bool("vacuum", true, "with -maintain, vacuum the database")
+	integrity     = flag.Bool("integrity", true, "with -maintain, check the database integrity")
+)
+
+// db_doctor scans the positions table for inconsistent records and, with -fix, repairs them.
+// When BINANCE_API_KEY and BINANCE_API_SECRET are set, SL/TP order IDs are cross-checked
+// against the exchange's order history. Exits with status 1 if issues remain.
+// With -maintain it instead runs one database maintenance pass (see runMaintenance)
+func main() {
+	flag.Parse()
+	_ = godotenv.Load() // Optional: the doctor also works with plain environment variables
+	ctx := context.Background()
+	appLogger := logger.NewStdLogger(logger.LevelWarn)
+
+	path := *dbPath
+	if path == "" {
+		path = config.EnvOrDefault("DB_PATH", "./data/trading_bot.db")
+	}
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("Database not found at %s: %v", path, err)
+	}
+	repo, err := sqlite.NewRepository(sqlite.Config{DBPath: path, Logger: appLogger})
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer repo.Close()
+
+	if *maintain {
+		if !runMaintenance(ctx, repo) {
+			repo.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	d := &doctor{}
+	apiKey, secretKey := os.Getenv("BINANCE_API_KEY"), os.Getenv("BINANCE_API_SECRET")
+	if apiKey != "" && secretKey != "" {
+		client, err := binanceclient.New(binanceclient.Config{
+			APIKey:     apiKey,
+			SecretKey:  secretKey,
+			UseTestnet: !strings.EqualFold(os.Getenv("IS_TESTNET"), "false"), // Testnet unless explicitly disabled, like the bot
+			Logger:     appLogger,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize Binance client: %v", err)
+		}
+		if err := client.SetServerTime(ctx); err != nil {
+			log.Fatalf("Failed to synchronize server time: %v", err)
+		}
+		d.lookup = client.GetOrder
+		fmt.Println("Cross-checking order IDs against the exchange order history")
+	} else {
+		fmt.Println("No API keys configured, skipping exchange cross-checks")
+	}
+
+	positions, err := repo.FindAll(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load positions: %v", err)
+	}
+
+	var issues []issue
+	checked := 0
+	for _, pos := range positions {
+		if *symbol != "" && pos.Symbol != *symbol {
+			continue
+		}
+		checked++
+		found, err := d.examine(ctx, pos)
+		if err != nil {
+			log.Fatalf("Failed to check position %d: %v", pos.ID, err)
+		}
+		issues = append(issues, found...)
+	}
+
+	fmt.Printf("Checked %d positions in %s, found %d issues\n", checked, path, len(issues))
+	if len(issues) == 0 {
+		return
+	}
+	printIssues(issues)
+
+	if !*fix {
+		fmt.Println("\nRun with -fix to repair the fixable records")
+		os.Exit(1)
+	}
+
+	remaining := applyFixes(ctx, repo, issues)
+	if remaining > 0 {
+		fmt.Printf("%d issues need manual review\n", remaining)
+		os.Exit(1)
+	}
+}
+
+// runMaintenance checkpoints the write-ahead log, prunes, vacuums and checks the database as
+// selected by the flags and prints the report. Returns false if the integrity check found problems
+func runMaintenance(ctx context.Context, repo *sqlite.Repository) bool {
+	opts := ports.MaintenanceOptions{Vacuum: *vacuum, IntegrityCheck: *integrity}
+	if *retentionDays > 0 {
+		opts.PruneBefore = time.Now().AddDate(0, 0, -*retentionDays)
+	}
+	report, err := repo.Maintain(ctx, opts)
+	if err != nil {
+		log.Fatalf("Database maintenance failed: %v", err)
+	}
+
+	fmt.Printf("Maintenance completed in %s: database %d bytes, WAL %d bytes, %d pages checkpointed\n",
+		report.Duration.Round(time.Millisecond), report.SizeBytes, report.WALSizeBytes, report.CheckpointedPages)
+	if !opts.PruneBefore.IsZero() {
+		fmt.Printf("Pruned %d klines and %d orders before %s\n", report.PrunedKlines, report.PrunedOrders, opts.PruneBefore.Format(time.RFC3339))
+	}
+	if report.WALBusy {
+		fmt.Println("The checkpoint couldn't complete while the database was busy (is the bot running?)")
+	}
+
+	tables := make([]string, 0, len(report.TableRows))
+	for table := range report.TableRows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nTable\tRows")
+	for _, table := range tables {
+		fmt.Fprintf(w, "%s\t%d\n", table, report.TableRows[table])
+	}
+	w.Flush()
+
+	if len(report.IntegrityErrors) > 0 {
+		fmt.Printf("\nIntegrity check found %d problems:\n", len(report.IntegrityErrors))
+		for _, problem := range report.IntegrityErrors {
+			fmt.Println("  " + problem)
+		}
+		return false
+	}
+	return true
+}
+
+// printIssues writes the issues as a table
+func printIssues(issues []issue) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nID\tSymbol\tSide\tStatus\tIssue\tFixable\tDetail")
+	for _, is := range issues {
+		fixable := "no"
+		if is.fix != nil {
+			fixable = "yes"
+		}
+		p := is.position
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", p.ID, p.Symbol, p.PositionSide(), p.Status, is.kind, fixable, is.detail)
+	}
+	w.Flush()
+}
+
+// applyFixes repairs the fixable issues, saving each affected position once, and returns
+// the number of issues left unresolved
+func applyFixes(ctx context.Context, repo *sqlite.Repository, issues []issue) int {
+	remaining := 0
+	var touched []*domain.Position
+	failed := make(map[int64]bool)
+	for _, is := range issues {
+		if is.fix == nil {
+			remaining++
+			continue
+		}
+		if err := is.fix(is.position); err != nil {
+			log.Printf("Failed to fix %s on position %d: %v", is.kind, is.position.ID, err)
+			failed[is.position.ID] = true
+			remaining++
+			continue
+		}
+		if len(touched) == 0 || touched[len(touched)-1] != is.position {
+			touched = append(touched, is.position) // Issues of a position are adjacent
+		}
+	}
+
+	for _, pos := range touched {
+		if failed[pos.ID] {
+			continue // Don't save a partially repaired record
+		}
+		if err := repo.Update(ctx, pos); err != nil {
+			log.Printf("Failed to save position %d: %v", pos.ID, err)
+			remaining++
+			continue
+		}
+		fmt.Printf("Repaired position %d\n", pos.ID)
+	}
+	return remaining
+}
@@ -0,0 +1,97 @@
+// Command external_strategy is an example external strategy process: a plain SMA crossover served
+// over the extstrategy protocol. Build it and point the bot at it to run it without recompiling
+// the bot:
+//
+//	go build -o bin/sma_cross ./cmd/external_strategy
+//	EXTERNAL_STRATEGY_COMMAND=bin/sma_cross
+//
+// Strategies in other languages implement the same newline-delimited JSON protocol on stdin and
+// stdout (see internal/adapters/extstrategy)
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/internal/adapters/extstrategy"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+	"log"
+	"os"
+)
+
+// smaCross enters long when the fast SMA crosses above the slow one, short when it crosses below,
+// and closes when it crosses back against the position
+type smaCross struct {
+	fast, slow int
+}
+
+// newSMACross builds the strategy from the parameters of the describe request
+func newSMACross(params map[string]float64) (ports.Strategy, error) {
+	s := &smaCross{fast: 9, slow: 21}
+	if v, ok := params["fastPeriod"]; ok {
+		s.fast = int(v)
+	}
+	if v, ok := params["slowPeriod"]; ok {
+		s.slow = int(v)
+	}
+	if s.fast <= 0 || s.fast >= s.slow {
+		return nil, fmt.Errorf("fastPeriod must be positive and below slowPeriod, got %d and %d", s.fast, s.slow)
+	}
+	return s, nil
+}
+
+func (s *smaCross) RequiredDataPoints() int {
+	return s.slow + 1
+}
+
+func (s *smaCross) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	return s.cross(klines) > 0
+}
+
+func (s *smaCross) ShouldEnterShort(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	return s.cross(klines) < 0
+}
+
+func (s *smaCross) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	cross := s.cross(klines)
+	if (position.PositionSide() == domain.PositionSideLong && cross < 0) ||
+		(position.PositionSide() == domain.PositionSideShort && cross > 0) {
+		return true, domain.CloseReasonTrendReversal
+	}
+	return false, ""
+}
+
+// cross returns 1 if the fast SMA crossed above the slow one on the last kline, -1 if it crossed
+// below and 0 otherwise
+func (s *smaCross) cross(klines []*domain.Kline) int {
+	if len(klines) < s.slow+1 {
+		return 0
+	}
+	previous := klines[:len(klines)-1]
+	before := sma(previous, s.fast) - sma(previous, s.slow)
+	after := sma(klines, s.fast) - sma(klines, s.slow)
+	switch {
+	case before <= 0 && after > 0:
+		return 1
+	case before >= 0 && after < 0:
+		return -1
+	}
+	return 0
+}
+
+// sma returns the simple moving average of the last period closes
+func sma(klines []*domain.Kline, period int) float64 {
+	var total float64
+	for _, k := range klines[len(klines)-period:] {
+		total += k.Close
+	}
+	return total / float64(period)
+}
+
+func main() {
+	// Logs go to stderr, which the bot logs; stdout carries the protocol
+	log.SetOutput(os.Stderr)
+	if err := extstrategy.Serve(os.Stdin, os.Stdout, "sma_cross", newSMACross); err != nil {
+		log.Fatalf("external strategy failed: %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"cryptoMegaBot/internal/domain"
 	"cryptoMegaBot/internal/ports"
 	"cryptoMegaBot/internal/utils"
+	"flag"
 	"fmt"
 	"log"
 	"sync"
@@ -65,7 +66,80 @@ type fetchResult struct {
 	klines   []*domain.Kline
 }
 
+var (
+	compress     = flag.Bool("gzip", false, "write gzip-compressed CSV files (.csv.gz)")
+	fetchFunding = flag.Bool("funding", true, "also fetch the funding rate history")
+	oiPeriod     = flag.String("oi-period", "1h", "period of the open interest history to fetch (5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d); empty to skip")
+)
+
+// openInterestRetention is how far back Binance serves open interest history
+const openInterestRetention = 30 * 24 * time.Hour
+
+// marketDataFilename names a CSV file of the symbol's data next to its klines
+func marketDataFilename(symbol, kind string, start, end time.Time) string {
+	filename := fmt.Sprintf("data/%s_%s_%s_to_%s.csv", symbol, kind, start.Format("20060102"), end.Format("20060102"))
+	if *compress {
+		filename += ".gz"
+	}
+	return filename
+}
+
+// fetchMarketData fetches the funding rate and open interest history of a symbol and saves them
+// alongside its klines, so strategies can use them as signals
+func fetchMarketData(ctx context.Context, client *binanceclient.Client, symbol string, start, end time.Time, logger ports.Logger) []error {
+	var errs []error
+
+	if *fetchFunding {
+		rates, err := client.GetFundingRateHistory(ctx, symbol, start, end)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error fetching %s funding rates: %w", symbol, err))
+		} else {
+			filename := marketDataFilename(symbol, "funding", start, end)
+			if err := utils.WriteFundingRatesToCSV(rates, filename); err != nil {
+				errs = append(errs, fmt.Errorf("error writing %s: %w", filename, err))
+			} else {
+				logger.Info(ctx, "Saved funding rates to CSV", map[string]interface{}{
+					"symbol":   symbol,
+					"count":    len(rates),
+					"filename": filename,
+				})
+			}
+		}
+	}
+
+	if *oiPeriod != "" {
+		oiStart := start
+		if earliest := end.Add(-openInterestRetention); oiStart.Before(earliest) {
+			logger.Warn(ctx, "Open interest history is only available for the last 30 days", map[string]interface{}{
+				"symbol":    symbol,
+				"requested": start.Format("2006-01-02"),
+				"start":     earliest.Format("2006-01-02"),
+			})
+			oiStart = earliest
+		}
+		snapshots, err := client.GetOpenInterestHistory(ctx, symbol, *oiPeriod, oiStart, end)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error fetching %s open interest: %w", symbol, err))
+		} else {
+			filename := marketDataFilename(symbol, "oi_"+*oiPeriod, oiStart, end)
+			if err := utils.WriteOpenInterestToCSV(snapshots, filename); err != nil {
+				errs = append(errs, fmt.Errorf("error writing %s: %w", filename, err))
+			} else {
+				logger.Info(ctx, "Saved open interest to CSV", map[string]interface{}{
+					"symbol":   symbol,
+					"period":   *oiPeriod,
+					"count":    len(snapshots),
+					"filename": filename,
+				})
+			}
+		}
+	}
+	return errs
+}
+
 func main() {
+	flag.Parse()
+
 	// 1. Load Configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -134,6 +208,9 @@ func main() {
 			start.Format("20060102"),
 			end.Format("20060102"),
 		)
+		if *compress {
+			filename += ".gz"
+		}
 
 		err := utils.WriteKlinesToCSV(result.klines, filename)
 		if err != nil {
@@ -151,7 +228,10 @@ func main() {
 		})
 	}
 
-	// 11. Check if there were any errors
+	// 11. Fetch funding rates and open interest for the same range
+	fetchErrors = append(fetchErrors, fetchMarketData(ctx, binanceClient, symbol, start, end, appLogger)...)
+
+	// 12. Check if there were any errors
 	if len(fetchErrors) > 0 {
 		appLogger.Error(ctx, fetchErrors[0], fmt.Sprintf("Encountered %d errors during fetching", len(fetchErrors)))
 		for i, err := range fetchErrors {
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/binanceclient"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"cryptoMegaBot/internal/app"
+	"cryptoMegaBot/internal/strategy/analytics"
+	"cryptoMegaBot/internal/utils"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+var (
+	dbPath  = flag.String("db", "", "path to the SQLite database (defaults to DB_PATH or ./data/trading_bot.db)")
+	symbol  = flag.String("symbol", "", "symbol to import (defaults to SYMBOL or ETHUSDT)")
+	days    = flag.Int("days", 30, "number of days of history to import")
+	initial = flag.Float64("initial", 0, "starting balance for the analysis (0 uses the current USDT balance minus the analyzed PnL)")
+	csvPath = flag.String("csv", "", "also write the analyzed trades to this CSV file, in the backtest trade format")
+)
+
+// import_history pulls the account's futures fills and funding fees from Binance, rebuilds
+// round-trip trades from them, stores the trades in the imported_trades table and analyzes
+// every imported trade of the period, so real performance can be compared with backtests
+func main() {
+	flag.Parse()
+	_ = godotenv.Load() // Optional: plain environment variables work too
+	ctx := context.Background()
+	appLogger := logger.NewStdLogger(logger.LevelWarn)
+
+	sym := *symbol
+	if sym == "" {
+		sym = config.EnvOrDefault("SYMBOL", "ETHUSDT")
+	}
+	if *days <= 0 {
+		log.Fatalf("-days must be positive")
+	}
+	end := time.Now()
+	start := end.Add(-time.Duration(*days) * 24 * time.Hour)
+
+	apiKey, secretKey := os.Getenv("BINANCE_API_KEY"), os.Getenv("BINANCE_API_SECRET")
+	if apiKey == "" || secretKey == "" {
+		log.Fatalf("BINANCE_API_KEY and BINANCE_API_SECRET are required to read the trade history")
+	}
+	client, err := binanceclient.New(binanceclient.Config{
+		APIKey:     apiKey,
+		SecretKey:  secretKey,
+		UseTestnet: !strings.EqualFold(os.Getenv("IS_TESTNET"), "false"), // Testnet unless explicitly disabled, like the bot
+		Logger:     appLogger,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize Binance client: %v", err)
+	}
+	if err := client.SetServerTime(ctx); err != nil {
+		log.Fatalf("Failed to synchronize server time: %v", err)
+	}
+
+	path := *dbPath
+	if path == "" {
+		path = config.EnvOrDefault("DB_PATH", "./data/trading_bot.db")
+	}
+	repo, err := sqlite.NewRepository(sqlite.Config{DBPath: path, Logger: appLogger})
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer repo.Close()
+
+	// 1. Fetch fills and funding fees
+	fills, err := client.GetAccountTrades(ctx, sym, start, end)
+	if err != nil {
+		log.Fatalf("Failed to fetch account trades: %v", err)
+	}
+	funding, err := client.GetIncomeHistory(ctx, sym, "FUNDING_FEE", start, end)
+	if err != nil {
+		log.Fatalf("Failed to fetch funding fee history: %v", err)
+	}
+	fmt.Printf("Fetched %d fills and %d funding payments for %s since %s\n", len(fills), len(funding), sym, start.Format("2006-01-02 15:04"))
+
+	// 2. Rebuild round-trip trades and store the new ones
+	trades, open := app.ReconstructTrades(fills, funding)
+	inserted, err := repo.SaveImportedTrades(ctx, trades)
+	if err != nil {
+		log.Fatalf("Failed to store imported trades: %v", err)
+	}
+	fmt.Printf("Rebuilt %d closed trades (%d new, %d positions still open are skipped)\n", len(trades), inserted, open)
+
+	// 3. Analyze every imported trade of the period, including earlier imports
+	stored, err := repo.FindImportedTrades(ctx, sym, start, end)
+	if err != nil {
+		log.Fatalf("Failed to load imported trades: %v", err)
+	}
+	if len(stored) == 0 {
+		fmt.Println("No closed trades in the period")
+		return
+	}
+
+	startingBalance := *initial
+	if startingBalance <= 0 {
+		balance, err := client.GetAccountBalance(ctx, "USDT")
+		if err != nil {
+			log.Fatalf("Failed to get account balance: %v", err)
+		}
+		startingBalance = balance
+		for _, t := range stored {
+			startingBalance -= t.PNL
+		}
+		if startingBalance <= 0 {
+			log.Fatalf("Derived starting balance %.2f is not positive, pass it with -initial", startingBalance)
+		}
+	}
+
+	metrics := analytics.AnalyzePerformance(stored, startingBalance)
+	printMetrics(sym, startingBalance, metrics)
+
+	if *csvPath != "" {
+		if err := utils.WriteTradesToCSV(stored, *csvPath); err != nil {
+			log.Fatalf("Failed to write trades CSV: %v", err)
+		}
+		fmt.Printf("Trades written to %s\n", *csvPath)
+	}
+}
+
+// printMetrics writes the performance summary as a table
+func printMetrics(sym string, startingBalance float64, m *analytics.PerformanceMetrics) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\nPerformance of imported %s trades\n", sym)
+	fmt.Fprintf(w, "Trades\t%d (%d wins, %d losses)\n", m.TotalTrades, m.WinningTrades, m.LosingTrades)
+	fmt.Fprintf(w, "Win rate\t%.2f%%\n", m.WinRate*100)
+	fmt.Fprintf(w, "Net PnL\t%.2f USDT\n", m.TotalProfit)
+	fmt.Fprintf(w, "Balance\t%.2f -> %.2f (%.2f%%)\n", startingBalance, m.FinalBalance, m.ReturnOnInvestment*100)
+	fmt.Fprintf(w, "Average win / loss\t%.2f / %.2f\n", m.AverageWin, m.AverageLoss)
+	fmt.Fprintf(w, "Profit factor\t%.2f\n", m.ProfitFactor)
+	fmt.Fprintf(w, "Expectancy\t%.2f\n", m.Expectancy)
+	fmt.Fprintf(w, "Max drawdown\t%.2f%%\n", m.MaxDrawdown*100)
+	fmt.Fprintf(w, "Max consecutive wins / losses\t%d / %d\n", m.MaxConsecutiveWins, m.MaxConsecutiveLosses)
+	fmt.Fprintf(w, "Average trade duration\t%s\n", m.AverageTradeDuration.Round(time.Second))
+	fmt.Fprintf(w, "Time in market\t%.2f%%\n", m.Exposure.TimeInMarket*100)
+	fmt.Fprintf(w, "Trades per day\t%.2f (max %.0f)\n", m.Exposure.TradesPerDay.Mean, m.Exposure.TradesPerDay.Max)
+	w.Flush()
+
+	if returns := m.GetMonthlyReturns(); len(returns) > 0 {
+		fmt.Println("\nMonth\tPnL")
+		for _, r := range returns {
+			fmt.Printf("%s\t%.2f\n", r.Month.Format("2006-01"), r.Return)
+		}
+	}
+}
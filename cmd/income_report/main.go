@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/binanceclient"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+var (
+	dbPath = flag.String("db", "", "path to the SQLite database (defaults to DB_PATH or ./data/trading_bot.db)")
+	symbol = flag.String("symbol", "", "symbol whose income is reported (defaults to SYMBOL or ETHUSDT)")
+	from   = flag.String("from", "", "first UTC day of the report, YYYY-MM-DD (defaults to January 1 of the current year)")
+	to     = flag.String("to", "", "last UTC day of the report, YYYY-MM-DD (defaults to today)")
+	asset  = flag.String("asset", "USDT", "margin asset the bot's stored trades are reconciled in")
+	out    = flag.String("out", "", "file the income statement CSV is written to (defaults to stdout)")
+)
+
+// income_report pulls a symbol's income history (realized PnL, commissions, funding fees and other
+// income) from Binance for a date range, reconciles it month by month against the positions the
+// bot stored as closed, and writes a monthly income statement CSV for tax and accounting. A
+// snapshot of the account (balance and open position) and the months that don't reconcile are
+// printed to stderr
+func main() {
+	flag.Parse()
+	_ = godotenv.Load() // Optional: plain environment variables work too
+	ctx := context.Background()
+	appLogger := logger.NewStdLogger(logger.LevelWarn)
+
+	sym := *symbol
+	if sym == "" {
+		sym = config.EnvOrDefault("SYMBOL", "ETHUSDT")
+	}
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := now
+	var err error
+	if *from != "" {
+		if start, err = time.Parse(time.DateOnly, *from); err != nil {
+			log.Fatalf("Invalid -from: %v", err)
+		}
+	}
+	if *to != "" {
+		day, err := time.Parse(time.DateOnly, *to)
+		if err != nil {
+			log.Fatalf("Invalid -to: %v", err)
+		}
+		end = day.Add(24 * time.Hour) // The whole last day
+	}
+	if !start.Before(end) {
+		log.Fatalf("-from must not be after -to")
+	}
+
+	apiKey, secretKey := os.Getenv("BINANCE_API_KEY"), os.Getenv("BINANCE_API_SECRET")
+	if apiKey == "" || secretKey == "" {
+		log.Fatalf("BINANCE_API_KEY and BINANCE_API_SECRET are required to read the income history")
+	}
+	client, err := binanceclient.New(binanceclient.Config{
+		APIKey:     apiKey,
+		SecretKey:  secretKey,
+		UseTestnet: !strings.EqualFold(os.Getenv("IS_TESTNET"), "false"), // Testnet unless explicitly disabled, like the bot
+		Logger:     appLogger,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize Binance client: %v", err)
+	}
+	if err := client.SetServerTime(ctx); err != nil {
+		log.Fatalf("Failed to synchronize server time: %v", err)
+	}
+
+	path := *dbPath
+	if path == "" {
+		path = config.EnvOrDefault("DB_PATH", "./data/trading_bot.db")
+	}
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("Database not found at %s: %v", path, err)
+	}
+	repo, err := sqlite.NewRepository(sqlite.Config{DBPath: path, Logger: appLogger})
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer repo.Close()
+
+	// 1. Income booked on the exchange and positions the bot closed in the period
+	records, err := client.GetIncomeHistory(ctx, sym, "", start, end)
+	if err != nil {
+		log.Fatalf("Failed to fetch income history: %v", err)
+	}
+	positions, err := repo.FindClosedBetween(ctx, sym, start, end)
+	if err != nil {
+		log.Fatalf("Failed to load closed positions: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Fetched %d income records and %d closed positions for %s from %s to %s\n",
+		len(records), len(positions), sym, start.Format(time.DateOnly), end.Add(-time.Nanosecond).Format(time.DateOnly))
+
+	// 2. Monthly statement
+	statement := buildStatement(records, positions, *asset)
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *out, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeStatementCSV(w, statement); err != nil {
+		log.Fatalf("Failed to write the income statement: %v", err)
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "Income statement written to %s\n", *out)
+	}
+
+	// 3. Reconciliation: income is booked when it happens, positions count in the month they
+	// closed, so a position open across a month end shows up as a difference in both months
+	mismatched := 0
+	for _, r := range statement {
+		if r.Asset != *asset || !r.Mismatched() {
+			continue
+		}
+		realized, commission, funding := r.Differences()
+		fmt.Fprintf(os.Stderr, "%s %s doesn't reconcile: realized PnL %+.4f, commission %+.4f, funding %+.4f (exchange minus bot, %d trades)\n",
+			r.Month, r.Asset, realized, commission, funding, r.Trades)
+		mismatched++
+	}
+	if mismatched == 0 {
+		fmt.Fprintf(os.Stderr, "Every month reconciles with the bot's trades within %.2f %s\n", reconcileTolerance, *asset)
+	}
+
+	// 4. Account snapshot
+	balance, err := client.GetAccountBalance(ctx, *asset)
+	if err != nil {
+		log.Fatalf("Failed to get account balance: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Account snapshot at %s: %.8f %s available\n", time.Now().UTC().Format(time.RFC3339), balance, *asset)
+	risks, err := client.GetPositionRisks(ctx, sym)
+	if err != nil {
+		log.Fatalf("Failed to get the open positions: %v", err)
+	}
+	if len(risks) == 0 {
+		fmt.Fprintf(os.Stderr, "No open %s position\n", sym)
+	}
+	for _, risk := range risks {
+		fmt.Fprintf(os.Stderr, "Open %s %s position: %g at %.4f entry, %.4f unrealized PnL\n", sym, risk.PositionSide, risk.PositionAmt, risk.EntryPrice, risk.UnRealizedProfit)
+	}
+}
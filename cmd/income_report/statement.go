@@ -0,0 +1,165 @@
+package main
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Income types of the exchange's income history with their own column; every other type
+// (transfers, insurance clearance, ...) is summed as other income
+const (
+	incomeRealizedPnL = "REALIZED_PNL"
+	incomeCommission  = "COMMISSION"
+	incomeFundingFee  = "FUNDING_FEE"
+)
+
+// reconcileTolerance is the difference between the exchange's and the bot's figures for a month
+// that is reported as a mismatch
+const reconcileTolerance = 0.01
+
+// statementRow is the income of one month in one asset, with the bot's figures for the positions it
+// closed that month in the margin asset
+type statementRow struct {
+	Month string // UTC month, e.g. 2025-06
+	Asset string
+
+	// Exchange income history
+	RealizedPnL float64
+	Commission  float64 // Negative when paid
+	Funding     float64 // Negative when paid
+	Other       float64
+
+	// Closed positions stored by the bot (margin asset rows only)
+	Trades         int
+	BotRealizedPnL float64 // PNL before fees and funding
+	BotFees        float64 // Positive when paid
+	BotFunding     float64 // Negative when paid
+	BotNetPnL      float64
+}
+
+// NetIncome returns the month's income in the asset across all types.
+func (r *statementRow) NetIncome() float64 {
+	return r.RealizedPnL + r.Commission + r.Funding + r.Other
+}
+
+// Differences returns the exchange's realized PnL, commissions and funding minus the bot's.
+func (r *statementRow) Differences() (realized, commission, funding float64) {
+	return r.RealizedPnL - r.BotRealizedPnL, r.Commission + r.BotFees, r.Funding - r.BotFunding
+}
+
+// Mismatched reports whether a difference exceeds reconcileTolerance.
+func (r *statementRow) Mismatched() bool {
+	realized, commission, funding := r.Differences()
+	return math.Abs(realized) > reconcileTolerance || math.Abs(commission) > reconcileTolerance || math.Abs(funding) > reconcileTolerance
+}
+
+// buildStatement sums the income records by UTC month and asset, and the closed positions by the
+// UTC month of their exit into the rows of marginAsset. Rows are ordered by month, then asset
+func buildStatement(records []ports.IncomeRecord, positions []*domain.Position, marginAsset string) []*statementRow {
+	rows := make(map[string]*statementRow)
+	row := func(t time.Time, asset string) *statementRow {
+		month := t.UTC().Format("2006-01")
+		key := month + "/" + asset
+		if rows[key] == nil {
+			rows[key] = &statementRow{Month: month, Asset: asset}
+		}
+		return rows[key]
+	}
+
+	for _, record := range records {
+		r := row(record.Time, record.Asset)
+		switch record.IncomeType {
+		case incomeRealizedPnL:
+			r.RealizedPnL += record.Income
+		case incomeCommission:
+			r.Commission += record.Income
+		case incomeFundingFee:
+			r.Funding += record.Income
+		default:
+			r.Other += record.Income
+		}
+	}
+	for _, pos := range positions {
+		r := row(pos.ExitTime, marginAsset)
+		r.Trades++
+		r.BotRealizedPnL += pos.PNL + pos.Fees - pos.Funding
+		r.BotFees += pos.Fees
+		r.BotFunding += pos.Funding
+		r.BotNetPnL += pos.PNL
+	}
+
+	statement := make([]*statementRow, 0, len(rows))
+	for _, r := range rows {
+		statement = append(statement, r)
+	}
+	sort.Slice(statement, func(i, j int) bool {
+		if statement[i].Month != statement[j].Month {
+			return statement[i].Month < statement[j].Month
+		}
+		return statement[i].Asset < statement[j].Asset
+	})
+	return statement
+}
+
+// writeStatementCSV writes the statement with a header row and a total row per asset.
+func writeStatementCSV(w io.Writer, statement []*statementRow) error {
+	cw := csv.NewWriter(w)
+	header := []string{"month", "asset", "realized_pnl", "commission", "funding", "other", "net_income",
+		"bot_trades", "bot_realized_pnl", "bot_fees", "bot_funding", "bot_net_pnl",
+		"realized_pnl_diff", "commission_diff", "funding_diff"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	totals := make(map[string]*statementRow)
+	var assets []string
+	for _, r := range statement {
+		if err := cw.Write(statementRecord(r)); err != nil {
+			return err
+		}
+		total := totals[r.Asset]
+		if total == nil {
+			total = &statementRow{Month: "TOTAL", Asset: r.Asset}
+			totals[r.Asset] = total
+			assets = append(assets, r.Asset)
+		}
+		total.RealizedPnL += r.RealizedPnL
+		total.Commission += r.Commission
+		total.Funding += r.Funding
+		total.Other += r.Other
+		total.Trades += r.Trades
+		total.BotRealizedPnL += r.BotRealizedPnL
+		total.BotFees += r.BotFees
+		total.BotFunding += r.BotFunding
+		total.BotNetPnL += r.BotNetPnL
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		if err := cw.Write(statementRecord(totals[asset])); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// statementRecord formats a row as CSV fields.
+func statementRecord(r *statementRow) []string {
+	realized, commission, funding := r.Differences()
+	return []string{r.Month, r.Asset, formatAmount(r.RealizedPnL), formatAmount(r.Commission), formatAmount(r.Funding),
+		formatAmount(r.Other), formatAmount(r.NetIncome()), strconv.Itoa(r.Trades), formatAmount(r.BotRealizedPnL),
+		formatAmount(r.BotFees), formatAmount(r.BotFunding), formatAmount(r.BotNetPnL),
+		formatAmount(realized), formatAmount(commission), formatAmount(funding)}
+}
+
+// formatAmount prints an amount with 8 decimals, the exchange's precision.
+func formatAmount(v float64) string {
+	return fmt.Sprintf("%.8f", v)
+}
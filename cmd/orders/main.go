@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"cryptoMegaBot/internal/ports"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+var (
+	dbPath     = flag.String("db", "", "path to the SQLite database (defaults to DB_PATH or ./data/trading_bot.db)")
+	symbol     = flag.String("symbol", "", "only list orders for this symbol")
+	status     = flag.String("status", "", "only list orders with this status (e.g. NEW, FILLED, REJECTED)")
+	positionID = flag.Int64("position", 0, "only list orders of this position ID")
+	from       = flag.String("from", "", "only list orders sent at or after this time (RFC 3339)")
+	to         = flag.String("to", "", "only list orders sent before this time (RFC 3339)")
+	limit      = flag.Int("limit", 50, "maximum number of orders to list")
+	offset     = flag.Int("offset", 0, "number of orders to skip, for paging")
+	asJSON     = flag.Bool("json", false, "print the orders as JSON instead of a table")
+)
+
+// orders lists the orders the bot sent to the exchange, newest first, from the order log
+func main() {
+	flag.Parse()
+	_ = godotenv.Load() // Optional: the command also works with plain environment variables
+	ctx := context.Background()
+
+	filter, err := buildFilter()
+	if err != nil {
+		log.Fatalf("Invalid filter: %v", err)
+	}
+
+	path := *dbPath
+	if path == "" {
+		path = config.EnvOrDefault("DB_PATH", "./data/trading_bot.db")
+	}
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("Database not found at %s: %v", path, err)
+	}
+	repo, err := sqlite.NewRepository(sqlite.Config{DBPath: path, Logger: logger.NewStdLogger(logger.LevelWarn)})
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer repo.Close()
+
+	orders, total, err := repo.FindOrders(ctx, filter)
+	if err != nil {
+		log.Fatalf("Failed to list orders: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]interface{}{"orders": orders, "total": total}); err != nil {
+			log.Fatalf("Failed to encode orders: %v", err)
+		}
+		return
+	}
+
+	if len(orders) == 0 {
+		fmt.Printf("No orders found (%d match the filter)\n", total)
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSENT\tSYMBOL\tPURPOSE\tSIDE\tPOS SIDE\tTYPE\tQTY\tPRICE\tSTOP\tSTATUS\tORDER ID\tPOSITION\tERROR")
+	for _, o := range orders {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			o.ID, o.CreatedAt.Format(time.RFC3339), o.Symbol, o.Purpose, o.Side, o.PositionSide, o.Type,
+			formatNumber(o.Quantity), formatNumber(o.Price), formatNumber(o.StopPrice), o.Status,
+			formatID(o.OrderID), formatID(o.PositionID), o.Error)
+	}
+	w.Flush()
+	fmt.Printf("\nShowing %d-%d of %d orders\n", filter.Offset+1, filter.Offset+len(orders), total)
+}
+
+// buildFilter reads the order filter from the flags
+func buildFilter() (ports.OrderFilter, error) {
+	filter := ports.OrderFilter{
+		Symbol:     *symbol,
+		Status:     *status,
+		PositionID: *positionID,
+		Limit:      *limit,
+		Offset:     *offset,
+	}
+	if filter.PositionID < 0 {
+		return filter, fmt.Errorf("-position must not be negative")
+	}
+	if filter.Limit <= 0 {
+		return filter, fmt.Errorf("-limit must be positive")
+	}
+	if filter.Offset < 0 {
+		return filter, fmt.Errorf("-offset must not be negative")
+	}
+	var err error
+	if *from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, *from); err != nil {
+			return filter, fmt.Errorf("-from: %w", err)
+		}
+	}
+	if *to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, *to); err != nil {
+			return filter, fmt.Errorf("-to: %w", err)
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("-from must be before -to")
+	}
+	return filter, nil
+}
+
+// formatNumber prints a quantity or price, or "-" when it isn't set
+func formatNumber(v float64) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%g", v)
+}
+
+// formatID prints an ID, or "-" when it isn't set
+func formatID(id int64) string {
+	if id == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d", id)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/binanceclient"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/screener"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+var (
+	symbolsFlag  = flag.String("symbols", "", "comma-separated symbols to screen (defaults to SYMBOL and the symbols of SYMBOL_OVERRIDES_FILE)")
+	top          = flag.Int("top", 3, "number of symbols to select (0 selects every eligible symbol)")
+	lookback     = flag.Int("lookback", 90, "daily ATR readings today's reading is ranked against")
+	atrPeriod    = flag.Int("atr-period", 14, "period of the daily ATR")
+	trendPeriod  = flag.Int("trend-period", 20, "days the trend strength is measured over")
+	volumePeriod = flag.Int("volume-period", 7, "days the quote volume is averaged over")
+	minVolume    = flag.Float64("min-volume", 0, "exclude symbols averaging less daily quote volume")
+	volWeight    = flag.Float64("volatility-weight", 1, "weight of the volatility percentile in the score")
+	trendWeight  = flag.Float64("trend-weight", 1, "weight of the trend strength in the score")
+	volumeWeight = flag.Float64("volume-weight", 1, "weight of the volume rank in the score")
+	outPath      = flag.String("out", "data/screener.json", "file the selection and full ranking are written to (empty to skip)")
+	daily        = flag.Bool("daily", false, "keep running and screen again after each daily close (00:05 UTC) until interrupted")
+	testnet      = flag.Bool("testnet", false, "fetch klines from the testnet instead of production")
+)
+
+// selection is the screener's output file
+type selection struct {
+	Date     string             `json:"date"`    // Day the selection is for (UTC)
+	Symbols  []string           `json:"symbols"` // The top symbols, best first
+	Rankings []screener.Ranking `json:"rankings"`
+	Failed   map[string]string  `json:"failed,omitempty"` // Symbols that couldn't be screened and why
+	Config   screener.Config    `json:"config"`
+}
+
+// screener ranks a list of symbols by their daily volatility percentile, trend strength and quote
+// volume, fetched over REST, and selects the top ones to trade for the day
+func main() {
+	flag.Parse()
+	_ = godotenv.Load() // Optional: only SYMBOL and SYMBOL_OVERRIDES_FILE are read from it
+
+	symbols, err := screenedSymbols()
+	if err != nil {
+		log.Fatalf("Failed to determine the symbols to screen: %v", err)
+	}
+	cfg := screener.Config{
+		ATRPeriod:        *atrPeriod,
+		Lookback:         *lookback,
+		TrendPeriod:      *trendPeriod,
+		VolumePeriod:     *volumePeriod,
+		MinQuoteVolume:   *minVolume,
+		VolatilityWeight: *volWeight,
+		TrendWeight:      *trendWeight,
+		VolumeWeight:     *volumeWeight,
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid screener settings: %v", err)
+	}
+
+	// Ctrl-C stops a daily screener between runs
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := binanceclient.New(binanceclient.Config{UseTestnet: *testnet, Logger: logger.NewStdLogger(logger.LevelError)})
+	if err != nil {
+		log.Fatalf("Failed to initialize Binance client: %v", err)
+	}
+
+	for {
+		if err := screen(ctx, client, symbols, cfg, time.Now().UTC()); err != nil {
+			if !*daily {
+				log.Fatalf("Screening failed: %v", err)
+			}
+			log.Printf("Screening failed: %v", err)
+		}
+		if !*daily {
+			return
+		}
+		next := nextRun(time.Now().UTC())
+		fmt.Printf("\nNext screening at %s\n", next.Format(time.RFC3339))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+	}
+}
+
+// screenedSymbols returns the -symbols list, or SYMBOL and the symbols with per-symbol settings
+func screenedSymbols() ([]string, error) {
+	seen := make(map[string]bool)
+	var symbols []string
+	add := func(symbol string) {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	if *symbolsFlag != "" {
+		for _, symbol := range strings.Split(*symbolsFlag, ",") {
+			add(symbol)
+		}
+		return symbols, nil
+	}
+	add(os.Getenv("SYMBOL"))
+	if path := os.Getenv("SYMBOL_OVERRIDES_FILE"); path != "" {
+		overrides, err := config.LoadSymbolOverrides(path)
+		if err != nil {
+			return nil, err
+		}
+		var configured []string
+		for symbol := range overrides {
+			configured = append(configured, symbol)
+		}
+		sort.Strings(configured)
+		for _, symbol := range configured {
+			add(symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols: pass -symbols or set SYMBOL")
+	}
+	return symbols, nil
+}
+
+// screen fetches the closed daily klines of every symbol, ranks them and prints and saves the
+// selection. Symbols that can't be fetched or have too short a history are reported and skipped
+func screen(ctx context.Context, client *binanceclient.Client, symbols []string, cfg screener.Config, now time.Time) error {
+	today := now.Truncate(24 * time.Hour)
+	limit := cfg.RequiredKlines() + 1 // One more for today's still open kline
+	var metrics []screener.Metrics
+	failed := make(map[string]string)
+	for _, symbol := range symbols {
+		klines, err := client.GetKlines(ctx, symbol, "1d", limit)
+		if err != nil {
+			failed[symbol] = err.Error()
+			continue
+		}
+		m, err := screener.Evaluate(symbol, closedBefore(klines, today), cfg)
+		if err != nil {
+			failed[symbol] = err.Error()
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	if len(metrics) == 0 {
+		return fmt.Errorf("none of the %d symbols could be screened", len(symbols))
+	}
+
+	rankings := screener.Rank(metrics, cfg)
+	result := selection{
+		Date:     today.Format("2006-01-02"),
+		Symbols:  screener.Top(rankings, *top),
+		Rankings: rankings,
+		Failed:   failed,
+		Config:   cfg,
+	}
+	printRankings(result)
+
+	if *outPath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode selection: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(*outPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(*outPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write selection: %w", err)
+	}
+	fmt.Printf("Selection written to %s\n", *outPath)
+	return nil
+}
+
+// closedBefore drops the klines that haven't closed by day (today's open kline)
+func closedBefore(klines []*domain.Kline, day time.Time) []*domain.Kline {
+	for len(klines) > 0 && !klines[len(klines)-1].OpenTime.Before(day) {
+		klines = klines[:len(klines)-1]
+	}
+	return klines
+}
+
+// printRankings writes the ranking as a table, followed by the selection
+func printRankings(result selection) {
+	fmt.Printf("\nScreen for %s\n", result.Date)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Rank\tSymbol\tScore\tATR %\tVol Pctl\tTrend\tChange %\tQuote Volume\tNote")
+	for _, r := range result.Rankings {
+		rank := "-"
+		if r.Rank > 0 {
+			rank = fmt.Sprintf("%d", r.Rank)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.3f\t%.2f\t%.0f\t%.2f\t%+.1f\t%.0f\t%s\n",
+			rank, r.Symbol, r.Score, r.ATRPct, r.VolatilityPercentile, r.TrendStrength, r.TrendChangePct, r.QuoteVolume, r.Excluded)
+	}
+	w.Flush()
+
+	failed := make([]string, 0, len(result.Failed))
+	for symbol := range result.Failed {
+		failed = append(failed, symbol)
+	}
+	sort.Strings(failed)
+	for _, symbol := range failed {
+		fmt.Printf("Skipped %s: %s\n", symbol, result.Failed[symbol])
+	}
+	fmt.Printf("Selected: %s\n", strings.Join(result.Symbols, ", "))
+}
+
+// nextRun returns the time of the next daily screening, shortly after the next daily close
+func nextRun(now time.Time) time.Time {
+	next := now.Truncate(24 * time.Hour).Add(5 * time.Minute)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
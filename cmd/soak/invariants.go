@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/testharness"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// invariantChecker compares the trading service's persisted positions with the fake exchange
+// after every delivered kline, when no kline is in flight. An invariant that stays broken over
+// several klines counts as one violation
+type invariantChecker struct {
+	symbol      string
+	repo        ports.PositionRepository
+	exchange    *testharness.FakeExchange
+	maxReported int
+
+	counts   map[string]int
+	examples []string
+	broken   map[string]bool // Invariants broken at the previous check
+	current  map[string]bool // Invariants broken at this check
+}
+
+// violation records a broken invariant unless it was already broken at the previous check,
+// keeping the first maxReported in full
+func (c *invariantChecker) violation(kline *domain.Kline, kind, format string, args ...interface{}) {
+	c.current[kind] = true
+	if c.broken[kind] {
+		return
+	}
+	c.counts[kind]++
+	if len(c.examples) < c.maxReported {
+		c.examples = append(c.examples, fmt.Sprintf("%s %s: %s", kline.OpenTime.UTC().Format(time.RFC3339), kind, fmt.Sprintf(format, args...)))
+	}
+}
+
+// check verifies the invariants after a kline
+func (c *invariantChecker) check(kline *domain.Kline) {
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.current = make(map[string]bool)
+	c.checkPositions(kline)
+	c.broken = c.current
+}
+
+// checkPositions verifies that the exchange holds exactly the exposure of the service's open
+// positions and that each open position is protected by its SL and TP orders
+func (c *invariantChecker) checkPositions(kline *domain.Kline) {
+	positions, err := c.repo.FindAll(context.Background())
+	if err != nil {
+		c.violation(kline, "repository error", "%v", err)
+		return
+	}
+	open := make(map[domain.PositionSide][]*domain.Position)
+	for _, pos := range positions {
+		if pos.Symbol == c.symbol && pos.IsOpen() {
+			open[pos.PositionSide()] = append(open[pos.PositionSide()], pos)
+		}
+	}
+
+	expected := 0.0
+	for side, list := range open {
+		if len(list) > 1 {
+			c.violation(kline, "duplicate position", "%d open %s positions", len(list), side)
+		}
+		for _, pos := range list {
+			if side == domain.PositionSideShort {
+				expected -= pos.Quantity
+			} else {
+				expected += pos.Quantity
+			}
+		}
+	}
+	actual := c.exchange.Position(domain.PositionSideBoth).Amount
+	if math.Abs(actual-expected) > 1e-9 {
+		c.violation(kline, "exposure mismatch", "exchange holds %.6f, open positions add up to %.6f", actual, expected)
+		return // Orders of a position the exchange already closed are expected to be gone
+	}
+
+	orders := c.exchange.OpenOrders()
+	for _, list := range open {
+		for _, pos := range list {
+			for name, id := range map[string]*string{"SL": pos.StopLossOrderID, "TP": pos.TakeProfitOrderID} {
+				if !orderOpen(orders, id) {
+					c.violation(kline, "unprotected position", "position %d has no open %s order", pos.ID, name)
+				}
+			}
+		}
+	}
+}
+
+// orderOpen reports whether the order with the stored ID is still open on the exchange
+func orderOpen(orders map[int64]ports.OrderResponse, id *string) bool {
+	if id == nil {
+		return false
+	}
+	orderID, err := strconv.ParseInt(*id, 10, 64)
+	if err != nil {
+		return false
+	}
+	_, ok := orders[orderID]
+	return ok
+}
+
+// total returns the number of violations
+func (c *invariantChecker) total() int {
+	total := 0
+	for _, count := range c.counts {
+		total += count
+	}
+	return total
+}
+
+// print prints the violation counts by kind and the first violations
+func (c *invariantChecker) print() {
+	if c.total() == 0 {
+		fmt.Println("Invariants:         all held")
+		return
+	}
+	kinds := make([]string, 0, len(c.counts))
+	for kind := range c.counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	fmt.Printf("Invariants:         %d violations\n", c.total())
+	for _, kind := range kinds {
+		fmt.Printf("  %-22s %d\n", kind+":", c.counts[kind])
+	}
+	fmt.Println("First violations:")
+	for _, example := range c.examples {
+		fmt.Println("  " + example)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"cryptoMegaBot/internal/app"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/strategy"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"cryptoMegaBot/internal/testharness"
+	"cryptoMegaBot/internal/utils"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	klinesPath := flag.String("klines", "", "recorded 1m kline CSV to replay (required)")
+	symbol := flag.String("symbol", "ETHUSDT", "symbol of the klines")
+	strategyName := flag.String("strategy", "improved_ma_crossover", "strategy to run: ma_crossover or improved_ma_crossover")
+	duration := flag.Duration("duration", time.Hour, "how long to soak (0 runs until the replay ends)")
+	loops := flag.Int("loops", -1, "passes over the recording; odd passes replay it backwards (negative repeats until -duration)")
+	speed := flag.Duration("speed", time.Millisecond, "wall time between replayed klines")
+	seed := flag.Int64("seed", 0, "random seed of the injected faults (0 picks a fresh seed)")
+	disconnectRate := flag.Float64("disconnect-rate", 0.002, "chance per kline that the stream disconnects")
+	disconnectKlines := flag.Int("disconnect-klines", 3, "klines lost per disconnect")
+	rejectRate := flag.Float64("reject-rate", 0.01, "chance an order is rejected")
+	partialRate := flag.Float64("partial-rate", 0.01, "chance a market order fills only partially")
+	quantity := flag.Float64("quantity", 0.1, "position size in the base asset")
+	stopLoss := flag.Float64("stoploss", 0.01, "stop loss as a fraction of the entry price")
+	takeProfit := flag.Float64("takeprofit", 0.02, "take profit as a fraction of the entry price")
+	leverage := flag.Int("leverage", 3, "leverage of each position")
+	dbPath := flag.String("db", "", "SQLite database of the run (default: a temporary file removed afterwards)")
+	logLevel := flag.String("log-level", "warn", "log level of the trading service")
+	maxReported := flag.Int("max-reported", 20, "invariant violations printed in full")
+	flag.Parse()
+
+	if *klinesPath == "" {
+		fmt.Println("Usage: soak -klines <1m kline csv> [-duration 4h] [-strategy name] [fault rates]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	klines, err := utils.ReadKlinesFromCSV(*klinesPath)
+	if err != nil {
+		fmt.Printf("Error loading klines: %v\n", err)
+		os.Exit(1)
+	}
+	appLogger := logger.NewStdLogger(logger.ParseLevel(*logLevel))
+
+	// A fresh database per run, so every soak starts flat
+	path := *dbPath
+	if path == "" {
+		dir, err := os.MkdirTemp("", "soak")
+		if err != nil {
+			fmt.Printf("Error creating database directory: %v\n", err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(dir)
+		path = filepath.Join(dir, "soak.db")
+	}
+	repo, err := sqlite.NewRepository(sqlite.Config{DBPath: path, Logger: appLogger})
+	if err != nil {
+		fmt.Printf("Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer repo.Close()
+
+	cfg := &config.Config{
+		Symbol:    *symbol,
+		Quantity:  *quantity,
+		StopLoss:  *stopLoss,
+		MaxProfit: *takeProfit,
+		Leverage:  *leverage,
+		MaxOrders: math.MaxInt32, // Accelerated days hold far more trades than the daily limit
+	}
+	strat, err := newStrategy(*strategyName, appLogger)
+	if err != nil {
+		fmt.Printf("Error creating strategy: %v\n", err)
+		os.Exit(1)
+	}
+	history := strat.RequiredDataPoints() + 1
+	if history < 500 {
+		history = 500
+	}
+
+	resolvedSeed := utils.ResolveSeed(*seed)
+	checker := &invariantChecker{symbol: *symbol, repo: repo, maxReported: *maxReported}
+	exchange, err := testharness.New(testharness.Config{
+		Symbol:  *symbol,
+		Klines:  klines,
+		History: history,
+		Loops:   *loops,
+		Speed:   *speed,
+		Seed:    resolvedSeed,
+		Faults: testharness.Faults{
+			DisconnectRate:   *disconnectRate,
+			DisconnectKlines: *disconnectKlines,
+			RejectRate:       *rejectRate,
+			PartialFillRate:  *partialRate,
+		},
+		AfterKline: func(kline *domain.Kline) { checker.check(kline) },
+	})
+	if err != nil {
+		fmt.Printf("Error creating fake exchange: %v\n", err)
+		os.Exit(1)
+	}
+	checker.exchange = exchange
+
+	service, err := app.NewTradingService(cfg, appLogger, exchange, repo, repo, strat,
+		app.WithStateRepository(repo),
+		app.WithEntryIntentRepository(repo),
+		app.WithStreamWatchdog(false),
+	)
+	if err != nil {
+		fmt.Printf("Error creating trading service: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The soak ends after -duration or when the replay runs out of klines
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+	go func() {
+		select {
+		case <-exchange.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	fmt.Printf("Soaking %s on %d recorded klines (seed %d, strategy %s)\n", *symbol, len(klines), resolvedSeed, *strategyName)
+	started := time.Now()
+	runErr := service.Start(ctx)
+	elapsed := time.Since(started)
+
+	trades, err := repo.FindAll(context.Background())
+	if err != nil {
+		fmt.Printf("Error loading trades: %v\n", err)
+		os.Exit(1)
+	}
+	printSummary(exchange.Stats(), trades, checker, elapsed)
+	if runErr != nil {
+		fmt.Printf("Trading service stopped with an error: %v\n", runErr)
+		os.Exit(1)
+	}
+	if checker.total() > 0 {
+		os.Exit(1)
+	}
+}
+
+// newStrategy creates the named strategy with the bot's default parameters
+func newStrategy(name string, appLogger ports.Logger) (ports.Strategy, error) {
+	switch name {
+	case "ma_crossover":
+		return strategy.New(strategy.Config{
+			ShortTermMAPeriod: 20,
+			LongTermMAPeriod:  50,
+			EMAPeriod:         20,
+			RSIPeriod:         14,
+			RSIOverbought:     70,
+			RSIOversold:       30,
+		}, appLogger)
+	case "improved_ma_crossover":
+		return strategies.NewImprovedMACrossover(strategies.MACrossoverConfig{
+			FastMAPeriod:  8,
+			SlowMAPeriod:  21,
+			SignalPeriod:  9,
+			ATRPeriod:     14,
+			ATRMultiplier: 2.5,
+		}, appLogger)
+	default:
+		return nil, fmt.Errorf("unknown strategy %q (ma_crossover or improved_ma_crossover)", name)
+	}
+}
+
+// printSummary prints what the fake exchange saw, the trades and the invariant violations
+func printSummary(stats testharness.Stats, positions []*domain.Position, checker *invariantChecker, elapsed time.Duration) {
+	closed, pnl := 0, 0.0
+	for _, pos := range positions {
+		if pos.Status == domain.StatusClosed {
+			closed++
+			pnl += pos.PNL
+		}
+	}
+	fmt.Printf("\n=== Soak Summary (%s) ===\n", elapsed.Round(time.Second))
+	fmt.Printf("Klines replayed:    %d (%d delivered, %d disconnects)\n", stats.KlinesReplayed, stats.KlinesDelivered, stats.Disconnects)
+	fmt.Printf("Orders:             %d placed, %d rejected, %d partial fills\n", stats.OrdersPlaced, stats.OrdersRejected, stats.PartialFills)
+	fmt.Printf("Exchange triggers:  %d SL/TP fills, %d expired\n", stats.StopsTriggered, stats.OrdersExpired)
+	fmt.Printf("Positions:          %d opened, %d closed (PnL %.2f)\n", len(positions), closed, pnl)
+	checker.print()
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bundleVersion is the version of the bundle format written by this tool
+const bundleVersion = 1
+
+// dateLayout is the format of dailyVolume.Date
+const dateLayout = "2006-01-02"
+
+// bundle is the exported state of the bot for one symbol
+type bundle struct {
+	Version    int       `json:"version"`
+	Symbol     string    `json:"symbol"`
+	ExportedAt time.Time `json:"exportedAt"`
+
+	OpenPositions   []*domain.Position `json:"openPositions"`
+	ClosedPositions []*domain.Position `json:"closedPositions"` // Oldest first
+	// Closed positions counted against MAX_ORDERS on the export day; the bot derives the count
+	// from ClosedPositions, it's recorded to verify the import
+	TradesToday int `json:"tradesToday"`
+
+	StrategyStates map[string]json.RawMessage `json:"strategyStates"` // By strategy name, including the active strategy
+	DailyVolume    dailyVolume                `json:"dailyVolume"`
+	EntryIntents   []*domain.EntryIntent      `json:"entryIntents"`       // Pending entries
+	SafeMode       *domain.SafeModeEvent      `json:"safeMode,omitempty"` // Active safe mode event
+	OrderIDs       []string                   `json:"orderIDs"`           // SL/TP orders of the open positions expected on the exchange
+
+	// Configuration of the exporting host with its secrets removed; informational, the importing
+	// host keeps its own and the differences are reported
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// dailyVolume is the entry volume of the symbol on a UTC day
+type dailyVolume struct {
+	Date     string  `json:"date"`
+	Notional float64 `json:"notional"`
+	Volume   float64 `json:"volume"`
+}
+
+// exportBundle collects the state of symbol, with the recent most recently closed positions
+// besides the ones closed in the last two days (which cover today in any time zone)
+func exportBundle(ctx context.Context, repo *sqlite.Repository, symbol string, recent int) (*bundle, error) {
+	now := time.Now()
+	b := &bundle{Version: bundleVersion, Symbol: symbol, ExportedAt: now.UTC(), StrategyStates: make(map[string]json.RawMessage)}
+
+	for _, side := range []domain.PositionSide{domain.PositionSideLong, domain.PositionSideShort} {
+		pos, err := repo.FindOpenBySymbolAndSide(ctx, symbol, side)
+		if err != nil {
+			return nil, err
+		}
+		if pos == nil {
+			continue
+		}
+		b.OpenPositions = append(b.OpenPositions, pos)
+		for _, id := range []*string{pos.StopLossOrderID, pos.TakeProfitOrderID} {
+			if id != nil && *id != "" {
+				b.OrderIDs = append(b.OrderIDs, *id)
+			}
+		}
+	}
+
+	closed, err := repo.FindClosedBetween(ctx, symbol, now.Add(-48*time.Hour), now.Add(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool, len(closed))
+	for _, pos := range closed {
+		seen[pos.ID] = true
+	}
+	if recent > 0 {
+		older, err := repo.FindClosedBySymbol(ctx, symbol, recent)
+		if err != nil {
+			return nil, err
+		}
+		for _, pos := range older {
+			if !seen[pos.ID] {
+				closed = append(closed, pos)
+			}
+		}
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].ExitTime.Before(closed[j].ExitTime) })
+	b.ClosedPositions = closed
+	if b.TradesToday, err = repo.CountTodayBySymbol(ctx, symbol); err != nil {
+		return nil, err
+	}
+
+	states, err := repo.FindStrategyStates(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	for name, state := range states {
+		if !json.Valid(state) {
+			return nil, fmt.Errorf("strategy state of %s is not valid JSON", name)
+		}
+		b.StrategyStates[name] = state
+	}
+
+	b.DailyVolume.Date = now.UTC().Format(dateLayout)
+	if b.DailyVolume.Notional, b.DailyVolume.Volume, err = repo.FindDailyVolume(ctx, symbol, now); err != nil {
+		return nil, err
+	}
+	if b.EntryIntents, err = repo.FindPendingEntryIntents(ctx, symbol); err != nil {
+		return nil, err
+	}
+	if b.SafeMode, err = repo.FindActiveSafeModeEvent(ctx, symbol); err != nil {
+		return nil, err
+	}
+
+	// The configuration is optional: it fails to load without the API keys
+	if cfg, err := config.LoadConfig(); err == nil {
+		if b.Config, err = sanitizedConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// importBundle restores b into the database and returns a summary of what was imported. It refuses
+// to import over an open position of the symbol; closed positions and entry intents already in the
+// database are skipped, so a bundle can be imported again after a partial failure
+func importBundle(ctx context.Context, repo *sqlite.Repository, b *bundle) (string, error) {
+	if b.Version != bundleVersion {
+		return "", fmt.Errorf("unsupported bundle version %d (expected %d)", b.Version, bundleVersion)
+	}
+	if b.Symbol == "" {
+		return "", fmt.Errorf("bundle has no symbol")
+	}
+	existing, err := repo.FindOpenBySymbol(ctx, b.Symbol)
+	if err != nil {
+		return "", err
+	}
+	if existing != nil {
+		return "", fmt.Errorf("the database already has an open %s position (ID %d), close or remove it first", b.Symbol, existing.ID)
+	}
+
+	var summary strings.Builder
+	for _, pos := range b.OpenPositions {
+		if pos.Symbol != b.Symbol || pos.Status != domain.StatusOpen {
+			return "", fmt.Errorf("bundle has an unexpected %s %s position among the open ones", pos.Status, pos.Symbol)
+		}
+		if _, err := repo.Create(ctx, pos); err != nil {
+			return "", err
+		}
+	}
+	fmt.Fprintf(&summary, "  open positions: %d\n", len(b.OpenPositions))
+
+	imported := 0
+	for _, pos := range b.ClosedPositions {
+		duplicates, err := repo.FindClosedBetween(ctx, b.Symbol, pos.ExitTime, pos.ExitTime.Add(time.Millisecond))
+		if err != nil {
+			return "", err
+		}
+		if containsEntry(duplicates, pos) {
+			continue
+		}
+		// Create only writes the opening fields, Update adds the exit
+		if _, err := repo.Create(ctx, pos); err != nil {
+			return "", err
+		}
+		if err := repo.Update(ctx, pos); err != nil {
+			return "", err
+		}
+		imported++
+	}
+	fmt.Fprintf(&summary, "  closed positions: %d (%d already present)\n", imported, len(b.ClosedPositions)-imported)
+
+	names := make([]string, 0, len(b.StrategyStates))
+	for name, state := range b.StrategyStates {
+		if err := repo.SaveStrategyState(ctx, name, b.Symbol, state); err != nil {
+			return "", err
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&summary, "  strategy states: %s\n", strings.Join(names, ", "))
+
+	if err := importDailyVolume(ctx, repo, b, &summary); err != nil {
+		return "", err
+	}
+
+	imported = 0
+	for _, intent := range b.EntryIntents {
+		if err := repo.SaveEntryIntent(ctx, intent); err != nil {
+			if errors.Is(err, ports.ErrDuplicateEntry) {
+				continue
+			}
+			return "", err
+		}
+		imported++
+	}
+	fmt.Fprintf(&summary, "  pending entry intents: %d\n", imported)
+
+	if b.SafeMode != nil {
+		active, err := repo.FindActiveSafeModeEvent(ctx, b.Symbol)
+		if err != nil {
+			return "", err
+		}
+		if active == nil {
+			if err := repo.SaveSafeModeEvent(ctx, b.SafeMode); err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&summary, "  safe mode: active since %s (%s)\n", b.SafeMode.StartedAt.Format(time.RFC3339), b.SafeMode.Reason)
+		}
+	}
+
+	if len(b.OrderIDs) > 0 {
+		fmt.Fprintf(&summary, "  SL/TP orders expected on the exchange: %s\n", strings.Join(b.OrderIDs, ", "))
+	}
+	if err := verifyTradesToday(ctx, repo, b, &summary); err != nil {
+		return "", err
+	}
+	compareConfig(b, &summary)
+	return summary.String(), nil
+}
+
+// importDailyVolume restores the export day's entry volume unless the database already has
+// volume for that day (adding to it would count it twice)
+func importDailyVolume(ctx context.Context, repo *sqlite.Repository, b *bundle, summary *strings.Builder) error {
+	if b.DailyVolume.Notional == 0 && b.DailyVolume.Volume == 0 {
+		return nil
+	}
+	day, err := time.Parse(dateLayout, b.DailyVolume.Date)
+	if err != nil {
+		return fmt.Errorf("invalid daily volume date %q: %w", b.DailyVolume.Date, err)
+	}
+	notional, volume, err := repo.FindDailyVolume(ctx, b.Symbol, day)
+	if err != nil {
+		return err
+	}
+	if notional != 0 || volume != 0 {
+		fmt.Fprintf(summary, "  daily volume: kept the database's %s volume\n", b.DailyVolume.Date)
+		return nil
+	}
+	if err := repo.AddDailyVolume(ctx, b.Symbol, day, b.DailyVolume.Notional, b.DailyVolume.Volume); err != nil {
+		return err
+	}
+	fmt.Fprintf(summary, "  daily volume: %.2f notional on %s\n", b.DailyVolume.Notional, b.DailyVolume.Date)
+	return nil
+}
+
+// verifyTradesToday checks that the imported positions give the daily trade count of the export,
+// when it was made earlier today
+func verifyTradesToday(ctx context.Context, repo *sqlite.Repository, b *bundle, summary *strings.Builder) error {
+	now := time.Now()
+	if b.ExportedAt.Local().Format(dateLayout) != now.Format(dateLayout) {
+		fmt.Fprintf(summary, "  trades today: the bundle is from %s, the daily count starts over\n", b.ExportedAt.Local().Format(dateLayout))
+		return nil
+	}
+	count, err := repo.CountTodayBySymbol(ctx, b.Symbol)
+	if err != nil {
+		return err
+	}
+	if count != b.TradesToday {
+		fmt.Fprintf(summary, "  trades today: %d, but the export counted %d\n", count, b.TradesToday)
+		return nil
+	}
+	fmt.Fprintf(summary, "  trades today: %d\n", count)
+	return nil
+}
+
+// compareConfig reports the settings that differ between the bundle and the local configuration
+func compareConfig(b *bundle, summary *strings.Builder) {
+	if len(b.Config) == 0 {
+		return
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(summary, "  config: not compared, the local configuration failed to load: %v\n", err)
+		return
+	}
+	local, err := sanitizedConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(summary, "  config: not compared: %v\n", err)
+		return
+	}
+	var exported, current map[string]json.RawMessage
+	if json.Unmarshal(b.Config, &exported) != nil || json.Unmarshal(local, &current) != nil {
+		fmt.Fprintln(summary, "  config: not compared, the exported configuration is invalid")
+		return
+	}
+	var differing []string
+	for key, value := range exported {
+		if !bytes.Equal(value, current[key]) {
+			differing = append(differing, key)
+		}
+	}
+	for key := range current {
+		if _, ok := exported[key]; !ok {
+			differing = append(differing, key)
+		}
+	}
+	if len(differing) == 0 {
+		fmt.Fprintln(summary, "  config: same as the exporting host")
+		return
+	}
+	sort.Strings(differing)
+	fmt.Fprintf(summary, "  config: differs from the exporting host in %s\n", strings.Join(differing, ", "))
+}
+
+// sanitizedConfig encodes cfg without its credentials
+func sanitizedConfig(cfg *config.Config) (json.RawMessage, error) {
+	clean := *cfg
+	clean.APIKey, clean.SecretKey = "", ""
+	clean.TelegramBotToken, clean.SMTPPassword = "", ""
+	clean.ControlAPIToken = ""
+	clean.Blackout = nil // Loaded from BlackoutFile
+	data, err := json.Marshal(clean)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return data, nil
+}
+
+// containsEntry reports whether positions has one entered at the same time as pos
+func containsEntry(positions []*domain.Position, pos *domain.Position) bool {
+	for _, p := range positions {
+		if p.EntryTime.Equal(pos.EntryTime) && p.PositionSide() == pos.PositionSide() {
+			return true
+		}
+	}
+	return false
+}
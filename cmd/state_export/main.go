@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/adapters/sqlite"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+var (
+	dbPath     = flag.String("db", "", "path to the SQLite database (defaults to DB_PATH or ./data/trading_bot.db)")
+	symbol     = flag.String("symbol", "", "symbol whose state is exported (defaults to SYMBOL or ETHUSDT)")
+	out        = flag.String("out", "", "file the bundle is written to (defaults to stdout)")
+	recent     = flag.Int("recent", 50, "closed positions exported besides today's, for the loss streak and cooldowns")
+	importFile = flag.String("import", "", "import the bundle in this file instead of exporting")
+)
+
+// state_export writes the bot's state for a symbol (open positions, recent closed positions behind
+// the daily trade count and loss streak, strategy state, today's volume, pending entries, safe mode
+// and the working SL/TP order IDs) to a JSON bundle. With -import it restores a bundle into the
+// database, e.g. on a new host or in a disaster recovery drill. Stop the bot before importing
+func main() {
+	flag.Parse()
+	_ = godotenv.Load() // Optional: the tool also works with plain environment variables
+	ctx := context.Background()
+	appLogger := logger.NewStdLogger(logger.LevelWarn)
+
+	path := *dbPath
+	if path == "" {
+		path = config.EnvOrDefault("DB_PATH", "./data/trading_bot.db")
+	}
+	if *importFile == "" {
+		if _, err := os.Stat(path); err != nil {
+			log.Fatalf("Database not found at %s: %v", path, err)
+		}
+	}
+	repo, err := sqlite.NewRepository(sqlite.Config{DBPath: path, Logger: appLogger})
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer repo.Close()
+
+	if *importFile != "" {
+		data, err := os.ReadFile(*importFile)
+		if err != nil {
+			log.Fatalf("Failed to read bundle: %v", err)
+		}
+		var b bundle
+		if err := json.Unmarshal(data, &b); err != nil {
+			log.Fatalf("Failed to parse bundle %s: %v", *importFile, err)
+		}
+		summary, err := importBundle(ctx, repo, &b)
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Imported the %s state exported at %s into %s\n%s", b.Symbol, b.ExportedAt.Format("2006-01-02 15:04:05 MST"), path, summary)
+		return
+	}
+
+	sym := *symbol
+	if sym == "" {
+		sym = config.EnvOrDefault("SYMBOL", "ETHUSDT")
+	}
+	b, err := exportBundle(ctx, repo, sym, *recent)
+	if err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode bundle: %v", err)
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		os.Stdout.Write(data)
+	} else if err := os.WriteFile(*out, data, 0o600); err != nil {
+		log.Fatalf("Failed to write bundle: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d open and %d closed positions, %d strategy states and %d active order IDs for %s\n",
+		len(b.OpenPositions), len(b.ClosedPositions), len(b.StrategyStates), len(b.OrderIDs), sym)
+}
@@ -10,6 +10,10 @@ import (
 	"github.com/joho/godotenv"
 
 	"cryptoMegaBot/internal/adapters/logger" // Import the logger package for LogLevel
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/money"
+	"cryptoMegaBot/internal/risk"
+	"cryptoMegaBot/internal/strategy/strategies"
 )
 
 // Config holds all application configuration.
@@ -20,13 +24,44 @@ type Config struct {
 	IsTestnet bool
 
 	// Trading Parameters
-	Symbol    string
-	Leverage  int
-	Quantity  float64 // Default quantity if not using dynamic sizing
-	MaxOrders int     // Max trades per day
-	StopLoss  float64 // Stop loss percentage (e.g., 0.0025 for 0.25%)
-	MinProfit float64 // Minimum profit target percentage (e.g., 0.01 for 1%)
-	MaxProfit float64 // Maximum profit target percentage (e.g., 0.03 for 3%)
+	Symbol     string
+	Leverage   int
+	MarginType domain.MarginType     // ISOLATED or CROSSED
+	HedgeMode  bool                  // Hold separate LONG and SHORT positions on the symbol (dual-side position mode)
+	Direction  domain.TradeDirection // Sides new positions may be opened on: both, long or short
+	Quantity   float64               // Default quantity if not using dynamic sizing, in the currency of QuantityMode
+	Precision  money.Precision       // Price tick and quantity step orders are rounded to (zero value uses money.DefaultPrecision)
+	MaxOrders  int                   // Max trades per day
+	StopLoss   float64               // Stop loss percentage (e.g., 0.0025 for 0.25%)
+	MinProfit  float64               // Minimum profit target percentage (e.g., 0.01 for 1%)
+	MaxProfit  float64               // Maximum profit target percentage (e.g., 0.03 for 3%)
+
+	// Quantity Currency
+	QuantityMode domain.QuantityMode // Whether Quantity is a base asset amount or a quote amount converted at the entry price
+
+	// Contract Type
+	Contract domain.Contract // USDT-margined (linear) or COIN-margined (inverse, Quantity in contracts of Contract.Size USD)
+
+	// Leverage Brackets
+	LeverageBrackets bool // Lower the leverage to the exchange's bracket limit for larger positions
+
+	// Fees and Breakeven
+	TakerFeeRate        float64 // Fee rate charged on each fill (e.g., 0.0004 for 0.04%)
+	FundingRate         float64 // Expected funding rate paid per 8h funding interval (e.g., 0.0001 for 0.01%)
+	BreakEvenActivation float64 // Profit percentage at which the stop moves to the fee-adjusted breakeven (0 disables)
+
+	// Per-Symbol Overrides
+	SymbolOverridesFile string                        // YAML file of per-symbol parameter blocks (empty disables)
+	SymbolOverrides     SymbolOverrides               // Blocks loaded from SymbolOverridesFile, keyed by symbol
+	StrategyParams      map[string]map[string]float64 // Strategy parameters by strategy name, resolved by ForSymbol
+	global              *Config                       // Settings before the overrides were merged
+
+	// Scale-In Entries
+	ScaleIn domain.ScaleInPlan // Initial share of Quantity and price improvements for the adds (no steps disables)
+
+	// Market Data
+	KlineIntervals   []string // Additional kline intervals to stream alongside 1m (e.g., 15m, 1h)
+	KlineAggregation bool     // Build the additional intervals from the 1m stream instead of streaming them
 
 	// Strategy Parameters
 	StrategyShortMAPeriod int     // e.g., 20
@@ -36,16 +71,177 @@ type Config struct {
 	StrategyRSIOverbought float64 // e.g., 70.0
 	StrategyRSIOversold   float64 // e.g., 30.0
 
+	// External Strategy
+	ExternalStrategyCommand []string      // Program and arguments of an external strategy process (empty disables)
+	ExternalStrategyTimeout time.Duration // Bound of each request to the external strategy process
+
+	// Meta Strategy
+	MetaStrategyChildren   []strategies.MetaChildSpec // Registered strategies voting in the "meta" strategy
+	MetaStrategyQuorum     float64                    // Weight of agreeing children needed to enter (0 requires all)
+	MetaStrategyExitPolicy strategies.ExitPolicy      // When the meta strategy closes a position
+
+	// Entry Confirmation Scoring (MACrossover)
+	EntryConfirmation strategies.ConfirmationConfig // Condition weights/thresholds and minimum score
+
+	// Day Trading Session
+	TimeLimitExit     bool          // Whether the strategy closes positions held longer than its maximum holding time
+	SessionEndEnabled bool          // Whether open positions are flattened at the session end
+	SessionEndTime    time.Duration // Time of day (offset from UTC midnight) the session ends
+
+	// Position Holding Time
+	PositionMaxHoldingTime time.Duration // Positions open longer are market-closed by the service, also after restarts (0 disables)
+
+	// Limit Entries (MACrossover and TradingService)
+	LimitEntries         bool          // Whether entries rest a limit order below the signal price instead of buying at market
+	LimitEntryOffset     float64       // Limit price offset below the signal price (e.g., 0.001 for 0.1%)
+	LimitEntryExpiryBars int           // Final klines an unfilled limit entry rests before it's canceled
+	LimitEntryTimeout    time.Duration // Time after which an unfilled limit entry is canceled (0: only the expiry bars)
+	LimitEntryFallback   bool          // Whether an expired limit entry falls back to a market order instead of skipping the signal
+	LimitEntryPostOnly   bool          // Whether limit entries are placed post-only, so they never fill as taker
+
+	// Re-Entry Rules (MACrossover and TradingService)
+	ReEntry domain.ReEntryPolicy // Cooldown / fresh crossover required after each close reason (empty allows immediate re-entry)
+
+	// Volume Profile Zones (MACrossover)
+	VolumeProfilePeriod  int     // Klines the volume profile is built from (0 disables)
+	VolumeProfileBuckets int     // Price buckets in the profile
+	VolumeProfileZonePct float64 // Distance to a high volume node that counts as reaching it
+
+	// Open Interest Confirmation (MACrossover)
+	OpenInterestConfirmation bool   // Whether long entries need open interest rising alongside price
+	OpenInterestPeriod       string // Period of the open interest snapshots (e.g., "5m")
+	OpenInterestLookback     int    // Snapshots the open interest and price change are measured over
+
+	// Kill Switch (equity-curve based)
+	KillSwitchMaxDrawdown   float64       // Drawdown from peak equity that pauses entries (0 disables)
+	KillSwitchMaxLosingDays int           // Consecutive losing days that pause entries (0 disables)
+	KillSwitchCoolDown      time.Duration // How long entries stay paused before resuming
+
+	// Equity Trail (daily profit lock-in)
+	EquityTrailTarget   float64 // Daily profit, as a fraction of the day's starting equity, that arms the trail (0 disables)
+	EquityTrailGiveback float64 // Share of the day's peak profit given back before flattening for the day
+
+	// Order Circuit Breaker
+	BreakerMaxFailures int           // Consecutive order failures within BreakerWindow that stop entries (0 disables)
+	BreakerWindow      time.Duration // Window the consecutive failures must fall in
+	BreakerOpenFor     time.Duration // How long entries stay stopped before a probe order is allowed
+
+	// Daily Volume Caps (entries, reset at UTC midnight)
+	MaxDailyNotional float64 // Quote notional of entries allowed per day (0 disables)
+	MaxDailyVolume   float64 // Base asset quantity of entries allowed per day (0 disables)
+
+	// Drawdown Throttle
+	DrawdownThrottle []risk.ThrottlePoint // Position size multipliers by drawdown from peak equity (empty disables)
+
+	// 24h Volume Cap
+	MaxVolumeShare float64 // Maximum position notional as a fraction of the symbol's 24h quote volume (0 disables)
+
+	// Maximum Exposure
+	MaxExposurePct float64 // Maximum notional of all open positions, a new entry included, as a fraction of equity (0 disables)
+
+	// Win/Loss Streak Sizing
+	StreakLadder risk.StreakLadder // Position size multipliers by the current win or loss streak (empty disables)
+
+	// Trading Calendar
+	TradingCalendar *risk.TradingCalendar // Position size multipliers by UTC weekday or date, 0 disabling entries (nil if disabled)
+
+	// Liquidity Filter (order book based)
+	LiquidityMaxSpreadPct float64 // Maximum bid/ask spread as a fraction of mid price (0 disables)
+	LiquidityMinDepth     float64 // Minimum quote notional per side within LiquidityDepthLevels (0 disables)
+	LiquidityDepthLevels  int     // Number of top order book levels to consider
+
+	// Slippage Guard (market entries)
+	MaxSlippageBps    float64 // Adverse entry fill slippage vs the signal price that triggers an alert, in bps (0 disables)
+	SlippageExit      bool    // Close entries whose slippage exceeds MaxSlippageBps right away
+	MaxEntrySpreadBps float64 // Skip entries while the book ticker spread exceeds this, in bps of the mid price (0 disables)
+
+	// Control API
+	ControlAPIAddr  string // Listen address for the control API (empty disables it)
+	ControlAPIToken string // Bearer token the control APIs' actions require (required with ControlAPIAddr or GRPCAPIAddr)
+	GRPCAPIAddr     string // Listen address for the gRPC control API (empty disables it)
+
+	// Notifications
+	TelegramBotToken string // Telegram bot token (empty disables Telegram notifications)
+	TelegramChatID   string // Telegram chat to post notifications to
+
+	// Email Notifications
+	SMTPHost     string   // SMTP server for email notifications (empty disables email)
+	SMTPPort     int      // SMTP server port (0 uses 587, or 465 with implicit TLS)
+	SMTPUsername string   // Optional SMTP login
+	SMTPPassword string   // Password for SMTPUsername
+	SMTPTLS      string   // starttls, tls (implicit) or none
+	SMTPFrom     string   // Sender address
+	SMTPTo       []string // Recipient addresses
+
+	// Daily Report
+	DailyReportEnabled bool          // Whether the daily summary report is scheduled
+	DailyReportTime    time.Duration // Time of day (offset from UTC midnight) the report is sent
+	ReportFeeRate      float64       // Fee rate per side used to estimate fees in reports
+	ReportCurrency     string        // Currency PnL and balances are also reported in (e.g., EUR; empty disables)
+
 	// Database
 	DBPath string
 
 	// Logging
-	LogLevel logger.LogLevel // Use the LogLevel type from the logger adapter
+	LogLevel      logger.LogLevel // Use the LogLevel type from the logger adapter
+	EventAuditLog bool            // Whether every trading event (signals, orders, positions, risk limits) is logged
 
 	// Connection Settings (Example for Binance client)
 	ReconnectDelay       time.Duration
 	MaxReconnectAttempts int
 
+	// Clock Drift Monitor
+	ClockCheckInterval time.Duration // How often local vs exchange time is compared (0 disables)
+	ClockMaxDrift      time.Duration // Drift that triggers a server time resync
+
+	// Kline Stream Watchdog
+	StreamWatchdog        bool // Detect stalled streams and kline gaps and refill the kline cache
+	StreamGapPauseEntries bool // Pause new entries until kline continuity is restored
+
+	// Kline Anomaly Detection
+	KlineAnomalyAction        string        // What is done with anomalous klines: off, flag, drop or repair
+	KlineAnomalyJumpSigma     float64       // Close-to-close move, in standard deviations of recent moves, that is a price jump
+	KlineAnomalyClusterCount  int           // Anomalies within KlineAnomalyClusterWindow that send an alert
+	KlineAnomalyClusterWindow time.Duration // Rolling window anomalies are counted in
+
+	// WebSocket Reconnect Alerts
+	ReconnectAlertThreshold int           // Reconnects within ReconnectAlertWindow that trigger an alert (0 disables)
+	ReconnectAlertWindow    time.Duration // Rolling window reconnects are counted in
+
+	// Exchange Latency Alerts
+	LatencyAlertP95 time.Duration // p95 latency of order placements, cancellations or kline fetches that triggers an alert (0 disables)
+
+	// Kline Cache Persistence
+	KlineCacheSaveInterval time.Duration // How often the kline cache is saved for warm starts (0 disables)
+
+	// Database Maintenance
+	DBMaintenanceInterval       time.Duration // How often the database is checkpointed, pruned and checked (0 disables)
+	DBRetention                 time.Duration // Age beyond which cached klines and logged orders are deleted (0 keeps them)
+	DBMaintenanceVacuum         bool          // Vacuum the database during maintenance
+	DBMaintenanceIntegrityCheck bool          // Check the database integrity during maintenance
+
+	// Order Fill Recording
+	RecordOrderFills bool // Save order executions and use their average prices and commissions for PNL
+
+	// Funding and Commission Accrual
+	IncomeAccrualInterval time.Duration // How often open positions' funding and commissions are pulled (0 disables)
+
+	// Exchange Maintenance/Outage Safe Mode
+	SafeModeFailures      int                   // Consecutive failed health checks that enter safe mode (0 disables; maintenance enters at once)
+	SafeModeCheckInterval time.Duration         // How often the exchange is pinged
+	SafeModeRecovery      int                   // Consecutive successful pings that end safe mode
+	SafeModeAction        domain.SafeModeAction // What happens to open positions: none, tighten or close
+	SafeModeTightenStop   float64               // Stop distance from the price for the tighten action (e.g., 0.005 for 0.5%)
+
+	// News/Volatility Blackout Windows
+	BlackoutFile string                 // YAML schedule of blackout windows (empty disables)
+	Blackout     *risk.BlackoutSchedule // Schedule loaded from BlackoutFile; nil if disabled
+
+	// Pre-Entry Balance Check
+	BalanceCheck         bool    // Fit each entry to the available balance before placing it
+	BalanceSafetyBuffer  float64 // Share of the balance kept free for fees and price moves (e.g., 0.05 for 5%)
+	BalanceShrinkEntries bool    // Shrink entries that don't fit instead of skipping them
+
 	// Other (Example)
 	MinAvailableBalance float64 // Minimum available balance required for trading
 }
@@ -63,8 +259,8 @@ func LoadConfig() (*Config, error) {
 	var errs []string // Collect validation errors
 
 	// Binance API
-	cfg.APIKey = getEnv("BINANCE_API_KEY", "")
-	cfg.SecretKey = getEnv("BINANCE_API_SECRET", "")
+	cfg.APIKey = EnvOrDefault("BINANCE_API_KEY", "")
+	cfg.SecretKey = EnvOrDefault("BINANCE_API_SECRET", "")
 	cfg.IsTestnet = getEnvAsBool("IS_TESTNET", true) // Default to testnet for safety
 
 	// Basic API Key validation (can be enhanced)
@@ -76,7 +272,7 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Trading Parameters
-	cfg.Symbol = getEnv("SYMBOL", "ETHUSDT")
+	cfg.Symbol = EnvOrDefault("SYMBOL", "ETHUSDT")
 	if cfg.Symbol == "" {
 		errs = append(errs, "SYMBOL must be set")
 	}
@@ -87,6 +283,17 @@ func LoadConfig() (*Config, error) {
 	} else if cfg.Leverage <= 0 {
 		errs = append(errs, "LEVERAGE must be positive")
 	}
+	cfg.LeverageBrackets = getEnvAsBool("LEVERAGE_BRACKETS", true)
+
+	cfg.MarginType = domain.MarginType(strings.ToUpper(EnvOrDefault("MARGIN_TYPE", string(domain.MarginTypeIsolated))))
+	if cfg.MarginType != domain.MarginTypeIsolated && cfg.MarginType != domain.MarginTypeCrossed {
+		errs = append(errs, fmt.Sprintf("invalid MARGIN_TYPE %q: must be ISOLATED or CROSSED", cfg.MarginType))
+	}
+	cfg.HedgeMode = getEnvAsBool("HEDGE_MODE", false)
+	cfg.Direction, err = domain.ParseTradeDirection(EnvOrDefault("TRADE_DIRECTION", string(domain.TradeDirectionBoth)))
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
 
 	cfg.Quantity, err = getEnvAsFloatRequired("QUANTITY", 1.0)
 	if err != nil {
@@ -95,6 +302,29 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, "QUANTITY must be positive")
 	}
 
+	cfg.QuantityMode = domain.QuantityMode(strings.ToLower(EnvOrDefault("QUANTITY_MODE", string(domain.QuantityModeBase))))
+	if cfg.QuantityMode != domain.QuantityModeBase && cfg.QuantityMode != domain.QuantityModeQuote {
+		errs = append(errs, fmt.Sprintf("invalid QUANTITY_MODE %q: must be base or quote", cfg.QuantityMode))
+	}
+
+	cfg.Contract.Type, err = domain.ParseContractType(EnvOrDefault("CONTRACT_TYPE", string(domain.ContractTypeUSDT)))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("CONTRACT_TYPE: %v", err))
+	}
+	cfg.Contract.Size, err = getEnvAsFloatRequired("CONTRACT_SIZE", 0)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("invalid CONTRACT_SIZE: %v", err))
+	} else if err := cfg.Contract.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("CONTRACT_SIZE: %v (e.g., 10 for ETHUSD_PERP)", err))
+	}
+
+	cfg.Precision, err = money.ParsePrecision(EnvOrDefault("PRICE_TICK_SIZE", "0.01"), EnvOrDefault("QUANTITY_STEP_SIZE", "0.001"))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("PRICE_TICK_SIZE / QUANTITY_STEP_SIZE: %v", err))
+	} else if cfg.QuantityMode != domain.QuantityModeQuote && cfg.Quantity > 0 && cfg.Precision.RoundQuantity(cfg.Quantity) <= 0 {
+		errs = append(errs, "QUANTITY must be at least QUANTITY_STEP_SIZE")
+	}
+
 	cfg.MaxOrders, err = getEnvAsIntRequired("MAX_ORDERS", 5)
 	if err != nil {
 		errs = append(errs, fmt.Sprintf("invalid MAX_ORDERS: %v", err))
@@ -128,6 +358,40 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, "MIN_PROFIT must be less than MAX_PROFIT")
 	}
 
+	// Fees and Breakeven
+	cfg.TakerFeeRate = getEnvAsFloat("TAKER_FEE_RATE", 0.0004)
+	if cfg.TakerFeeRate < 0 || cfg.TakerFeeRate >= 0.01 {
+		errs = append(errs, "TAKER_FEE_RATE must be between 0.0 and 0.01")
+	}
+	cfg.FundingRate = getEnvAsFloat("FUNDING_RATE", 0.0001)
+	if cfg.FundingRate < 0 {
+		errs = append(errs, "FUNDING_RATE cannot be negative")
+	}
+	cfg.BreakEvenActivation = getEnvAsFloat("BREAK_EVEN_ACTIVATION", 0)
+	if cfg.BreakEvenActivation < 0 {
+		errs = append(errs, "BREAK_EVEN_ACTIVATION cannot be negative")
+	}
+
+	// Scale-In Entries
+	cfg.ScaleIn.Steps, err = parseFloatList(EnvOrDefault("SCALE_IN_STEPS", ""))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("SCALE_IN_STEPS is invalid: %v", err))
+	}
+	cfg.ScaleIn.InitialFraction = getEnvAsFloat("SCALE_IN_INITIAL_FRACTION", 0.5)
+	if err := cfg.ScaleIn.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("SCALE_IN_INITIAL_FRACTION / SCALE_IN_STEPS: %v", err))
+	} else if last := cfg.ScaleIn.LastStep(); last > 0 && cfg.StopLoss > 0 && last >= cfg.StopLoss {
+		// The stop loss would fill before the price ever reached the add
+		errs = append(errs, fmt.Sprintf("SCALE_IN_STEPS must stay below STOP_LOSS (%v), got a step of %v", cfg.StopLoss, last))
+	}
+
+	// Market Data
+	cfg.KlineIntervals, err = parseKlineIntervals(EnvOrDefault("KLINE_INTERVALS", ""))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("KLINE_INTERVALS is invalid: %v", err))
+	}
+	cfg.KlineAggregation = getEnvAsBool("KLINE_AGGREGATION", false)
+
 	// Strategy Parameters (using defaults if not set)
 	cfg.StrategyShortMAPeriod = getEnvAsInt("STRATEGY_SHORT_MA_PERIOD", 20)
 	cfg.StrategyLongMAPeriod = getEnvAsInt("STRATEGY_LONG_MA_PERIOD", 50)
@@ -147,15 +411,287 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, "invalid RSI thresholds (Overbought must be > Oversold, between 0-100)")
 	}
 
+	// External Strategy
+	cfg.ExternalStrategyCommand = strings.Fields(EnvOrDefault("EXTERNAL_STRATEGY_COMMAND", ""))
+	externalStrategyTimeoutMs := getEnvAsInt("EXTERNAL_STRATEGY_TIMEOUT_MS", 2000)
+	if externalStrategyTimeoutMs <= 0 {
+		errs = append(errs, "EXTERNAL_STRATEGY_TIMEOUT_MS must be positive")
+	}
+	cfg.ExternalStrategyTimeout = time.Duration(externalStrategyTimeoutMs) * time.Millisecond
+
+	// Meta Strategy
+	cfg.MetaStrategyChildren, err = strategies.ParseMetaChildren(EnvOrDefault("META_STRATEGY_CHILDREN", "ma_crossover,improved_ma_crossover"))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("META_STRATEGY_CHILDREN is invalid: %v", err))
+	}
+	for _, child := range cfg.MetaStrategyChildren {
+		if child.Name == "meta" {
+			errs = append(errs, "META_STRATEGY_CHILDREN cannot include meta itself")
+		}
+	}
+	cfg.MetaStrategyQuorum = getEnvAsFloat("META_STRATEGY_QUORUM", 0)
+	if cfg.MetaStrategyQuorum < 0 {
+		errs = append(errs, "META_STRATEGY_QUORUM cannot be negative")
+	}
+	cfg.MetaStrategyExitPolicy = strategies.ExitPolicy(strings.ToUpper(EnvOrDefault("META_STRATEGY_EXIT_POLICY", string(strategies.ExitAny))))
+	switch cfg.MetaStrategyExitPolicy {
+	case strategies.ExitAny, strategies.ExitQuorum, strategies.ExitAll:
+	default:
+		errs = append(errs, "META_STRATEGY_EXIT_POLICY must be ANY, QUORUM or ALL")
+	}
+
+	// Entry Confirmation Scoring
+	cfg.EntryConfirmation, err = strategies.ParseConfirmationRules(EnvOrDefault("ENTRY_CONFIRMATIONS", ""), strategies.DefaultConfirmationConfig())
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("ENTRY_CONFIRMATIONS is invalid: %v", err))
+	}
+	cfg.EntryConfirmation.MinScore = getEnvAsFloat("ENTRY_MIN_CONFIRMATION_SCORE", cfg.EntryConfirmation.MinScore)
+	if cfg.EntryConfirmation.MinScore < 0 {
+		errs = append(errs, "ENTRY_MIN_CONFIRMATION_SCORE cannot be negative")
+	}
+
+	// Day Trading Session
+	cfg.TimeLimitExit = getEnvAsBool("TIME_LIMIT_EXIT", true)
+	if sessionEnd := EnvOrDefault("SESSION_END_TIME", ""); sessionEnd != "" {
+		cfg.SessionEndEnabled = true
+		cfg.SessionEndTime, err = parseTimeOfDay(sessionEnd)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("SESSION_END_TIME is invalid: %v", err))
+		} else if cfg.SessionEndTime == 0 {
+			errs = append(errs, "SESSION_END_TIME must be after 00:00")
+		}
+	}
+
+	// Position Holding Time
+	maxHoldingMinutes := getEnvAsInt("POSITION_MAX_HOLDING_MINUTES", 0)
+	if maxHoldingMinutes < 0 {
+		errs = append(errs, "POSITION_MAX_HOLDING_MINUTES cannot be negative")
+	}
+	cfg.PositionMaxHoldingTime = time.Duration(maxHoldingMinutes) * time.Minute
+
+	// Limit Entries
+	cfg.LimitEntries = getEnvAsBool("LIMIT_ENTRIES", false)
+	cfg.LimitEntryOffset = getEnvAsFloat("LIMIT_ENTRY_OFFSET", 0.001)
+	if cfg.LimitEntryOffset <= 0 || cfg.LimitEntryOffset >= 1 {
+		errs = append(errs, "LIMIT_ENTRY_OFFSET must be between 0 and 1")
+	}
+	cfg.LimitEntryExpiryBars = getEnvAsInt("LIMIT_ENTRY_EXPIRY_BARS", 3)
+	if cfg.LimitEntryExpiryBars <= 0 {
+		errs = append(errs, "LIMIT_ENTRY_EXPIRY_BARS must be positive")
+	}
+	limitEntryTimeoutSeconds := getEnvAsInt("LIMIT_ENTRY_TIMEOUT_SECONDS", 0)
+	if limitEntryTimeoutSeconds < 0 {
+		errs = append(errs, "LIMIT_ENTRY_TIMEOUT_SECONDS cannot be negative")
+	}
+	cfg.LimitEntryTimeout = time.Duration(limitEntryTimeoutSeconds) * time.Second
+	switch fallback := strings.ToLower(EnvOrDefault("LIMIT_ENTRY_FALLBACK", "skip")); fallback {
+	case "skip":
+	case "market":
+		cfg.LimitEntryFallback = true
+	default:
+		errs = append(errs, fmt.Sprintf("LIMIT_ENTRY_FALLBACK must be skip or market, got %q", fallback))
+	}
+	cfg.LimitEntryPostOnly = getEnvAsBool("LIMIT_ENTRY_POST_ONLY", false)
+
+	// Re-Entry Rules
+	cfg.ReEntry, err = domain.ParseReEntryPolicy(EnvOrDefault("REENTRY_RULES", ""))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("REENTRY_RULES is invalid: %v", err))
+	}
+
+	// Volume Profile Zones
+	cfg.VolumeProfilePeriod = getEnvAsInt("VOLUME_PROFILE_PERIOD", 0)
+	if cfg.VolumeProfilePeriod < 0 {
+		errs = append(errs, "VOLUME_PROFILE_PERIOD cannot be negative")
+	}
+	cfg.VolumeProfileBuckets = getEnvAsInt("VOLUME_PROFILE_BUCKETS", 24)
+	if cfg.VolumeProfileBuckets <= 0 {
+		errs = append(errs, "VOLUME_PROFILE_BUCKETS must be positive")
+	}
+	cfg.VolumeProfileZonePct = getEnvAsFloat("VOLUME_PROFILE_ZONE_PCT", 0.002)
+	if cfg.VolumeProfileZonePct < 0 {
+		errs = append(errs, "VOLUME_PROFILE_ZONE_PCT cannot be negative")
+	}
+
+	// Open Interest Confirmation
+	cfg.OpenInterestConfirmation = getEnvAsBool("OPEN_INTEREST_CONFIRMATION", false)
+	cfg.OpenInterestPeriod = EnvOrDefault("OPEN_INTEREST_PERIOD", "5m")
+	switch cfg.OpenInterestPeriod {
+	case "5m", "15m", "30m", "1h", "2h", "4h", "6h", "12h", "1d":
+	default:
+		errs = append(errs, fmt.Sprintf("OPEN_INTEREST_PERIOD %q is not a Binance open interest period (5m, 15m, 30m, 1h, 2h, 4h, 6h, 12h, 1d)", cfg.OpenInterestPeriod))
+	}
+	cfg.OpenInterestLookback = getEnvAsInt("OPEN_INTEREST_LOOKBACK", 3)
+	if cfg.OpenInterestLookback <= 0 {
+		errs = append(errs, "OPEN_INTEREST_LOOKBACK must be positive")
+	}
+
+	// Kill Switch
+	cfg.KillSwitchMaxDrawdown = getEnvAsFloat("KILL_SWITCH_MAX_DRAWDOWN", 0)
+	if cfg.KillSwitchMaxDrawdown < 0 || cfg.KillSwitchMaxDrawdown >= 1.0 {
+		errs = append(errs, "KILL_SWITCH_MAX_DRAWDOWN must be between 0.0 (disabled) and 1.0")
+	}
+	cfg.KillSwitchMaxLosingDays = getEnvAsInt("KILL_SWITCH_MAX_LOSING_DAYS", 0)
+	if cfg.KillSwitchMaxLosingDays < 0 {
+		errs = append(errs, "KILL_SWITCH_MAX_LOSING_DAYS cannot be negative")
+	}
+	coolDownHours := getEnvAsInt("KILL_SWITCH_COOLDOWN_HOURS", 24)
+	if coolDownHours <= 0 {
+		errs = append(errs, "KILL_SWITCH_COOLDOWN_HOURS must be positive")
+	}
+	cfg.KillSwitchCoolDown = time.Duration(coolDownHours) * time.Hour
+
+	// Equity Trail
+	cfg.EquityTrailTarget = getEnvAsFloat("EQUITY_TRAIL_TARGET", 0)
+	if cfg.EquityTrailTarget < 0 {
+		errs = append(errs, "EQUITY_TRAIL_TARGET cannot be negative")
+	}
+	cfg.EquityTrailGiveback = getEnvAsFloat("EQUITY_TRAIL_GIVEBACK", 0.5)
+	if cfg.EquityTrailGiveback < 0 || cfg.EquityTrailGiveback > 1 {
+		errs = append(errs, "EQUITY_TRAIL_GIVEBACK must be between 0 and 1")
+	}
+
+	// Order Circuit Breaker
+	cfg.BreakerMaxFailures = getEnvAsInt("ORDER_BREAKER_MAX_FAILURES", 5)
+	if cfg.BreakerMaxFailures < 0 {
+		errs = append(errs, "ORDER_BREAKER_MAX_FAILURES cannot be negative")
+	}
+	breakerWindowSeconds := getEnvAsInt("ORDER_BREAKER_WINDOW_SECONDS", 300)
+	if breakerWindowSeconds <= 0 {
+		errs = append(errs, "ORDER_BREAKER_WINDOW_SECONDS must be positive")
+	}
+	cfg.BreakerWindow = time.Duration(breakerWindowSeconds) * time.Second
+	breakerOpenSeconds := getEnvAsInt("ORDER_BREAKER_OPEN_SECONDS", 300)
+	if breakerOpenSeconds <= 0 {
+		errs = append(errs, "ORDER_BREAKER_OPEN_SECONDS must be positive")
+	}
+	cfg.BreakerOpenFor = time.Duration(breakerOpenSeconds) * time.Second
+
+	// Daily Volume Caps
+	cfg.MaxDailyNotional = getEnvAsFloat("MAX_DAILY_NOTIONAL", 0)
+	if cfg.MaxDailyNotional < 0 {
+		errs = append(errs, "MAX_DAILY_NOTIONAL cannot be negative")
+	}
+	cfg.MaxDailyVolume = getEnvAsFloat("MAX_DAILY_VOLUME", 0)
+	if cfg.MaxDailyVolume < 0 {
+		errs = append(errs, "MAX_DAILY_VOLUME cannot be negative")
+	}
+
+	// Drawdown Throttle
+	cfg.DrawdownThrottle, err = risk.ParseThrottleCurve(EnvOrDefault("DRAWDOWN_THROTTLE", ""))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("DRAWDOWN_THROTTLE is invalid: %v", err))
+	}
+
+	// 24h Volume Cap
+	cfg.MaxVolumeShare = getEnvAsFloat("MAX_VOLUME_SHARE", 0)
+	if cfg.MaxVolumeShare < 0 || cfg.MaxVolumeShare >= 1 {
+		errs = append(errs, "MAX_VOLUME_SHARE must be at least 0 and below 1")
+	}
+
+	// Maximum Exposure
+	cfg.MaxExposurePct = getEnvAsFloat("MAX_EXPOSURE_PCT", 0)
+	if cfg.MaxExposurePct < 0 {
+		errs = append(errs, "MAX_EXPOSURE_PCT cannot be negative")
+	}
+
+	// Win/Loss Streak Sizing
+	cfg.StreakLadder, err = risk.ParseStreakLadder(EnvOrDefault("STREAK_LADDER", ""))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("STREAK_LADDER is invalid: %v", err))
+	}
+
+	// Trading Calendar
+	cfg.TradingCalendar, err = risk.ParseTradingCalendar(EnvOrDefault("TRADING_CALENDAR", ""))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("TRADING_CALENDAR is invalid: %v", err))
+	}
+
+	// Liquidity Filter
+	cfg.LiquidityMaxSpreadPct = getEnvAsFloat("LIQUIDITY_MAX_SPREAD_PCT", 0)
+	if cfg.LiquidityMaxSpreadPct < 0 {
+		errs = append(errs, "LIQUIDITY_MAX_SPREAD_PCT cannot be negative")
+	}
+	cfg.LiquidityMinDepth = getEnvAsFloat("LIQUIDITY_MIN_DEPTH", 0)
+	if cfg.LiquidityMinDepth < 0 {
+		errs = append(errs, "LIQUIDITY_MIN_DEPTH cannot be negative")
+	}
+	cfg.LiquidityDepthLevels = getEnvAsInt("LIQUIDITY_DEPTH_LEVELS", 5)
+	if cfg.LiquidityDepthLevels <= 0 {
+		errs = append(errs, "LIQUIDITY_DEPTH_LEVELS must be positive")
+	}
+
+	// Slippage Guard
+	cfg.MaxSlippageBps = getEnvAsFloat("MAX_SLIPPAGE_BPS", 0)
+	if cfg.MaxSlippageBps < 0 {
+		errs = append(errs, "MAX_SLIPPAGE_BPS cannot be negative")
+	}
+	cfg.SlippageExit = getEnvAsBool("SLIPPAGE_EXIT", false)
+	if cfg.SlippageExit && cfg.MaxSlippageBps == 0 {
+		errs = append(errs, "SLIPPAGE_EXIT requires MAX_SLIPPAGE_BPS")
+	}
+	cfg.MaxEntrySpreadBps = getEnvAsFloat("MAX_ENTRY_SPREAD_BPS", 0)
+	if cfg.MaxEntrySpreadBps < 0 {
+		errs = append(errs, "MAX_ENTRY_SPREAD_BPS cannot be negative")
+	}
+
+	// Control API
+	cfg.ControlAPIAddr = EnvOrDefault("CONTROL_API_ADDR", "")
+	cfg.GRPCAPIAddr = EnvOrDefault("GRPC_API_ADDR", "")
+	cfg.ControlAPIToken = EnvOrDefault("CONTROL_API_TOKEN", "")
+	if (cfg.ControlAPIAddr != "" || cfg.GRPCAPIAddr != "") && cfg.ControlAPIToken == "" {
+		errs = append(errs, "CONTROL_API_TOKEN is required when CONTROL_API_ADDR or GRPC_API_ADDR is set")
+	}
+
+	// Notifications
+	cfg.TelegramBotToken = EnvOrDefault("TELEGRAM_BOT_TOKEN", "")
+	cfg.TelegramChatID = EnvOrDefault("TELEGRAM_CHAT_ID", "")
+	if cfg.TelegramBotToken != "" && cfg.TelegramChatID == "" {
+		errs = append(errs, "TELEGRAM_CHAT_ID must be set when TELEGRAM_BOT_TOKEN is set")
+	}
+	cfg.SMTPHost = EnvOrDefault("SMTP_HOST", "")
+	cfg.SMTPPort = getEnvAsInt("SMTP_PORT", 0)
+	cfg.SMTPUsername = EnvOrDefault("SMTP_USERNAME", "")
+	cfg.SMTPPassword = EnvOrDefault("SMTP_PASSWORD", "")
+	cfg.SMTPTLS = strings.ToLower(EnvOrDefault("SMTP_TLS", "starttls"))
+	cfg.SMTPFrom = EnvOrDefault("SMTP_FROM", "")
+	cfg.SMTPTo = parseList(EnvOrDefault("SMTP_TO", ""))
+	if cfg.SMTPHost != "" {
+		if cfg.SMTPFrom == "" || len(cfg.SMTPTo) == 0 {
+			errs = append(errs, "SMTP_FROM and SMTP_TO must be set when SMTP_HOST is set")
+		}
+		if cfg.SMTPTLS != "starttls" && cfg.SMTPTLS != "tls" && cfg.SMTPTLS != "none" {
+			errs = append(errs, "SMTP_TLS must be starttls, tls or none")
+		}
+		if cfg.SMTPPort < 0 || cfg.SMTPPort > 65535 {
+			errs = append(errs, "SMTP_PORT must be a valid port number")
+		}
+	}
+
+	// Daily Report
+	if reportTime := EnvOrDefault("DAILY_REPORT_TIME", ""); reportTime != "" {
+		cfg.DailyReportEnabled = true
+		cfg.DailyReportTime, err = parseTimeOfDay(reportTime)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("DAILY_REPORT_TIME is invalid: %v", err))
+		}
+	}
+	cfg.ReportFeeRate = getEnvAsFloat("REPORT_FEE_RATE", cfg.TakerFeeRate)
+	if cfg.ReportFeeRate < 0 {
+		errs = append(errs, "REPORT_FEE_RATE cannot be negative")
+	}
+	cfg.ReportCurrency = strings.ToUpper(strings.TrimSpace(EnvOrDefault("REPORT_CURRENCY", "")))
+
 	// Database
-	cfg.DBPath = getEnv("DB_PATH", "./data/trading_bot.db")
+	cfg.DBPath = EnvOrDefault("DB_PATH", "./data/trading_bot.db")
 	if cfg.DBPath == "" {
 		errs = append(errs, "DB_PATH must be set")
 	}
 
 	// Logging
-	logLevelStr := getEnv("LOG_LEVEL", "INFO")
+	logLevelStr := EnvOrDefault("LOG_LEVEL", "INFO")
 	cfg.LogLevel = logger.ParseLevel(logLevelStr) // Use the parser from the logger package
+	cfg.EventAuditLog = getEnvAsBool("EVENT_AUDIT_LOG", false)
 
 	// Connection Settings
 	reconnectDelaySeconds := getEnvAsInt("RECONNECT_DELAY_SECONDS", 5)
@@ -169,6 +705,132 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, "MAX_RECONNECT_ATTEMPTS cannot be negative")
 	}
 
+	// Clock Drift Monitor
+	clockCheckSeconds := getEnvAsInt("CLOCK_CHECK_INTERVAL_SECONDS", 300)
+	if clockCheckSeconds < 0 {
+		errs = append(errs, "CLOCK_CHECK_INTERVAL_SECONDS cannot be negative")
+	}
+	cfg.ClockCheckInterval = time.Duration(clockCheckSeconds) * time.Second
+	clockMaxDriftMs := getEnvAsInt("CLOCK_MAX_DRIFT_MS", 500)
+	if clockMaxDriftMs <= 0 {
+		errs = append(errs, "CLOCK_MAX_DRIFT_MS must be positive")
+	}
+	cfg.ClockMaxDrift = time.Duration(clockMaxDriftMs) * time.Millisecond
+
+	// Kline Stream Watchdog
+	cfg.StreamWatchdog = getEnvAsBool("STREAM_WATCHDOG", true)
+	cfg.StreamGapPauseEntries = getEnvAsBool("STREAM_GAP_PAUSE_ENTRIES", false)
+
+	// Kline Anomaly Detection
+	cfg.KlineAnomalyAction = strings.ToLower(EnvOrDefault("KLINE_ANOMALY_ACTION", "off"))
+	switch cfg.KlineAnomalyAction {
+	case "off", "flag", "drop", "repair":
+	default:
+		errs = append(errs, "KLINE_ANOMALY_ACTION must be off, flag, drop or repair")
+	}
+	cfg.KlineAnomalyJumpSigma = getEnvAsFloat("KLINE_ANOMALY_JUMP_SIGMA", 8)
+	if cfg.KlineAnomalyJumpSigma <= 0 {
+		errs = append(errs, "KLINE_ANOMALY_JUMP_SIGMA must be positive")
+	}
+	cfg.KlineAnomalyClusterCount = getEnvAsInt("KLINE_ANOMALY_CLUSTER_COUNT", 5)
+	if cfg.KlineAnomalyClusterCount <= 0 {
+		errs = append(errs, "KLINE_ANOMALY_CLUSTER_COUNT must be positive")
+	}
+	anomalyClusterMinutes := getEnvAsInt("KLINE_ANOMALY_CLUSTER_MINUTES", 30)
+	if anomalyClusterMinutes <= 0 {
+		errs = append(errs, "KLINE_ANOMALY_CLUSTER_MINUTES must be positive")
+	}
+	cfg.KlineAnomalyClusterWindow = time.Duration(anomalyClusterMinutes) * time.Minute
+
+	// WebSocket Reconnect Alerts
+	cfg.ReconnectAlertThreshold = getEnvAsInt("WS_RECONNECT_ALERT_THRESHOLD", 5)
+	if cfg.ReconnectAlertThreshold < 0 {
+		errs = append(errs, "WS_RECONNECT_ALERT_THRESHOLD cannot be negative")
+	}
+	reconnectWindowMinutes := getEnvAsInt("WS_RECONNECT_ALERT_WINDOW_MINUTES", 60)
+	if cfg.ReconnectAlertThreshold > 0 && reconnectWindowMinutes <= 0 {
+		errs = append(errs, "WS_RECONNECT_ALERT_WINDOW_MINUTES must be positive")
+	}
+	cfg.ReconnectAlertWindow = time.Duration(reconnectWindowMinutes) * time.Minute
+
+	// Exchange Latency Alerts
+	latencyAlertMs := getEnvAsInt("LATENCY_ALERT_P95_MS", 1500)
+	if latencyAlertMs < 0 {
+		errs = append(errs, "LATENCY_ALERT_P95_MS cannot be negative")
+	}
+	cfg.LatencyAlertP95 = time.Duration(latencyAlertMs) * time.Millisecond
+
+	// Kline Cache Persistence
+	klineCacheSaveSeconds := getEnvAsInt("KLINE_CACHE_SAVE_INTERVAL_SECONDS", 300)
+	if klineCacheSaveSeconds < 0 {
+		errs = append(errs, "KLINE_CACHE_SAVE_INTERVAL_SECONDS cannot be negative")
+	}
+	cfg.KlineCacheSaveInterval = time.Duration(klineCacheSaveSeconds) * time.Second
+
+	// Database Maintenance
+	dbMaintenanceMinutes := getEnvAsInt("DB_MAINTENANCE_INTERVAL_MINUTES", 0)
+	if dbMaintenanceMinutes < 0 {
+		errs = append(errs, "DB_MAINTENANCE_INTERVAL_MINUTES cannot be negative")
+	}
+	cfg.DBMaintenanceInterval = time.Duration(dbMaintenanceMinutes) * time.Minute
+	dbRetentionDays := getEnvAsInt("DB_RETENTION_DAYS", 0)
+	if dbRetentionDays < 0 {
+		errs = append(errs, "DB_RETENTION_DAYS cannot be negative")
+	}
+	cfg.DBRetention = time.Duration(dbRetentionDays) * 24 * time.Hour
+	cfg.DBMaintenanceVacuum = getEnvAsBool("DB_MAINTENANCE_VACUUM", true)
+	cfg.DBMaintenanceIntegrityCheck = getEnvAsBool("DB_MAINTENANCE_INTEGRITY_CHECK", true)
+
+	// Order Fill Recording
+	cfg.RecordOrderFills = getEnvAsBool("RECORD_ORDER_FILLS", true)
+
+	// Funding and Commission Accrual
+	incomeAccrualMinutes := getEnvAsInt("INCOME_ACCRUAL_INTERVAL_MINUTES", 15)
+	if incomeAccrualMinutes < 0 {
+		errs = append(errs, "INCOME_ACCRUAL_INTERVAL_MINUTES cannot be negative")
+	}
+	cfg.IncomeAccrualInterval = time.Duration(incomeAccrualMinutes) * time.Minute
+
+	// Exchange Maintenance/Outage Safe Mode
+	cfg.SafeModeFailures = getEnvAsInt("SAFE_MODE_FAILURES", 0)
+	if cfg.SafeModeFailures < 0 {
+		errs = append(errs, "SAFE_MODE_FAILURES cannot be negative")
+	}
+	safeModeCheckSeconds := getEnvAsInt("SAFE_MODE_CHECK_INTERVAL_SECONDS", 30)
+	if cfg.SafeModeFailures > 0 && safeModeCheckSeconds <= 0 {
+		errs = append(errs, "SAFE_MODE_CHECK_INTERVAL_SECONDS must be positive")
+	}
+	cfg.SafeModeCheckInterval = time.Duration(safeModeCheckSeconds) * time.Second
+	cfg.SafeModeRecovery = getEnvAsInt("SAFE_MODE_RECOVERY_CHECKS", 3)
+	if cfg.SafeModeRecovery <= 0 {
+		errs = append(errs, "SAFE_MODE_RECOVERY_CHECKS must be positive")
+	}
+	cfg.SafeModeAction, err = domain.ParseSafeModeAction(EnvOrDefault("SAFE_MODE_ACTION", "none"))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("invalid SAFE_MODE_ACTION: %v", err))
+	}
+	cfg.SafeModeTightenStop = getEnvAsFloat("SAFE_MODE_TIGHTEN_PCT", 0.005)
+	if cfg.SafeModeAction == domain.SafeModeTighten && (cfg.SafeModeTightenStop <= 0 || cfg.SafeModeTightenStop >= 1) {
+		errs = append(errs, "SAFE_MODE_TIGHTEN_PCT must be between 0 and 1")
+	}
+
+	// News/Volatility Blackout Windows
+	cfg.BlackoutFile = EnvOrDefault("BLACKOUT_FILE", "")
+	if cfg.BlackoutFile != "" {
+		cfg.Blackout, err = risk.LoadBlackoutSchedule(cfg.BlackoutFile)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("BLACKOUT_FILE is invalid: %v", err))
+		}
+	}
+
+	// Pre-Entry Balance Check
+	cfg.BalanceCheck = getEnvAsBool("BALANCE_CHECK", true)
+	cfg.BalanceSafetyBuffer = getEnvAsFloat("BALANCE_SAFETY_BUFFER", 0.05)
+	if cfg.BalanceSafetyBuffer < 0 || cfg.BalanceSafetyBuffer >= 1 {
+		errs = append(errs, "BALANCE_SAFETY_BUFFER must be between 0 and 1")
+	}
+	cfg.BalanceShrinkEntries = getEnvAsBool("BALANCE_SHRINK_ENTRIES", true)
+
 	// Other
 	cfg.MinAvailableBalance, err = getEnvAsFloatRequired("MIN_AVAILABLE_BALANCE", 100.0)
 	if err != nil {
@@ -177,6 +839,19 @@ func LoadConfig() (*Config, error) {
 		errs = append(errs, "MIN_AVAILABLE_BALANCE cannot be negative")
 	}
 
+	// Per-Symbol Overrides, merged over all settings above for SYMBOL
+	cfg.SymbolOverridesFile = EnvOrDefault("SYMBOL_OVERRIDES_FILE", "")
+	if cfg.SymbolOverridesFile != "" && len(errs) == 0 {
+		cfg.SymbolOverrides, err = LoadSymbolOverrides(cfg.SymbolOverridesFile)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("SYMBOL_OVERRIDES_FILE is invalid: %v", err))
+		} else if resolved, err := cfg.ForSymbol(cfg.Symbol); err != nil {
+			errs = append(errs, fmt.Sprintf("SYMBOL_OVERRIDES_FILE: %v", err))
+		} else {
+			cfg = resolved
+		}
+	}
+
 	// Combine validation errors
 	if len(errs) > 0 {
 		return nil, fmt.Errorf("configuration validation failed: %s", strings.Join(errs, "; "))
@@ -185,9 +860,121 @@ func LoadConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// OrderPrecision returns the configured order precision, or the default if none is set.
+func (c *Config) OrderPrecision() money.Precision {
+	if !c.Precision.TickSize.IsPositive() || !c.Precision.StepSize.IsPositive() {
+		return money.DefaultPrecision()
+	}
+	return c.Precision
+}
+
+// BaseQuantity returns amount (Quantity or a share of it) as an order quantity at price: a quote
+// amount is converted at price (or into whole contracts on COIN-margined contracts) and rounded
+// down to the step size, a base amount (contracts on COIN-margined contracts) is returned
+// unchanged.
+func (c *Config) BaseQuantity(amount, price float64) float64 {
+	if c.QuantityMode != domain.QuantityModeQuote {
+		return amount
+	}
+	if c.Contract.IsInverse() {
+		return c.OrderPrecision().RoundQuantity(c.Contract.Quantity(amount, price))
+	}
+	return c.OrderPrecision().QuoteToQuantity(amount, price)
+}
+
+// MarginAsset returns the asset the symbol's positions are margined in and its balance is read
+// from: USDT, or the base coin on COIN-margined contracts.
+func (c *Config) MarginAsset() string {
+	return c.Contract.MarginAsset(c.Symbol)
+}
+
+// ContractTypes returns the contract type of every configured symbol that isn't USDT-margined:
+// SYMBOL and the symbols of the override blocks, for the exchange client to route their requests.
+func (c *Config) ContractTypes() map[string]domain.ContractType {
+	global := c
+	if c.global != nil {
+		global = c.global
+	}
+	types := make(map[string]domain.ContractType)
+	if global.Contract.IsInverse() {
+		types[strings.ToUpper(global.Symbol)] = global.Contract.Type
+	}
+	if c.Contract.IsInverse() {
+		types[strings.ToUpper(c.Symbol)] = c.Contract.Type
+	}
+	for symbol := range global.SymbolOverrides {
+		if resolved, err := global.ForSymbol(symbol); err == nil && resolved.Contract.IsInverse() {
+			types[symbol] = resolved.Contract.Type
+		}
+	}
+	return types
+}
+
+// FeeModel returns the configured trading fees and funding.
+func (c *Config) FeeModel() domain.FeeModel {
+	return domain.FeeModel{TakerRate: c.TakerFeeRate, FundingRate: c.FundingRate}
+}
+
 // --- Env Var Helpers ---
 
-func getEnv(key, defaultValue string) string {
+// klineIntervals lists the kline intervals supported by Binance futures.
+var klineIntervals = map[string]bool{
+	"1m": true, "3m": true, "5m": true, "15m": true, "30m": true,
+	"1h": true, "2h": true, "4h": true, "6h": true, "8h": true, "12h": true,
+	"1d": true, "3d": true, "1w": true, "1M": true,
+}
+
+// parseKlineIntervals parses a comma-separated list of kline intervals.
+func parseKlineIntervals(value string) ([]string, error) {
+	var intervals []string
+	for _, part := range strings.Split(value, ",") {
+		interval := strings.TrimSpace(part)
+		if interval == "" {
+			continue
+		}
+		if !klineIntervals[interval] {
+			return nil, fmt.Errorf("unsupported interval %q", interval)
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, nil
+}
+
+// parseList parses a comma-separated list, dropping empty entries.
+func parseList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if item := strings.TrimSpace(part); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseFloatList parses a comma-separated list of numbers, dropping empty entries.
+func parseFloatList(value string) ([]float64, error) {
+	var numbers []float64
+	for _, item := range parseList(value) {
+		number, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", item)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// parseTimeOfDay parses an "HH:MM" time into an offset from midnight.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// EnvOrDefault returns the environment variable key, or defaultValue when it's unset or empty
+func EnvOrDefault(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
 		return defaultValue
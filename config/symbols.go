@@ -0,0 +1,137 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/money"
+)
+
+// SymbolOverrides are per-symbol parameter blocks loaded from SYMBOL_OVERRIDES_FILE. Each block
+// overrides the global settings it sets for its symbol; everything else keeps the global value.
+type SymbolOverrides map[string]SymbolOverride
+
+// SymbolOverride holds the settings overridden for one symbol. Unset fields keep the global value.
+type SymbolOverride struct {
+	Leverage         *int     `yaml:"leverage"`
+	Quantity         *float64 `yaml:"quantity"`
+	StopLoss         *float64 `yaml:"stop_loss"`
+	MinProfit        *float64 `yaml:"min_profit"`
+	MaxProfit        *float64 `yaml:"max_profit"`
+	PriceTickSize    string   `yaml:"price_tick_size"`
+	QuantityStepSize string   `yaml:"quantity_step_size"`
+	ContractType     string   `yaml:"contract_type"` // USDT_MARGINED or COIN_MARGINED
+	ContractSize     *float64 `yaml:"contract_size"` // USD value of one COIN_MARGINED contract
+
+	// Strategy parameters by strategy name, with the names the control API accepts when switching
+	// strategies (e.g., improved_ma_crossover: {fastMAPeriod: 8, slowMAPeriod: 21})
+	Strategies map[string]map[string]float64 `yaml:"strategies"`
+}
+
+// LoadSymbolOverrides reads per-symbol parameter blocks from a YAML file keyed by symbol.
+func LoadSymbolOverrides(path string) (SymbolOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read symbol overrides: %w", err)
+	}
+	var overrides SymbolOverrides
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse symbol overrides %s: %w", path, err)
+	}
+	normalized := make(SymbolOverrides, len(overrides))
+	for symbol, override := range overrides {
+		normalized[strings.ToUpper(symbol)] = override
+	}
+	return normalized, nil
+}
+
+// ForSymbol returns a copy of the configuration for trading symbol, with the symbol's override
+// block (if any) merged over the global settings. The merged settings are validated like the
+// global ones. It can be called on a configuration already resolved for another symbol.
+func (c *Config) ForSymbol(symbol string) (*Config, error) {
+	global := c
+	if c.global != nil {
+		global = c.global
+	}
+	resolved := *global
+	resolved.global = global
+	resolved.Symbol = symbol
+	resolved.StrategyParams = nil
+	override, ok := c.SymbolOverrides[strings.ToUpper(symbol)]
+	if !ok {
+		return &resolved, nil
+	}
+
+	if override.Leverage != nil {
+		resolved.Leverage = *override.Leverage
+	}
+	if override.Quantity != nil {
+		resolved.Quantity = *override.Quantity
+	}
+	if override.StopLoss != nil {
+		resolved.StopLoss = *override.StopLoss
+	}
+	if override.MinProfit != nil {
+		resolved.MinProfit = *override.MinProfit
+	}
+	if override.MaxProfit != nil {
+		resolved.MaxProfit = *override.MaxProfit
+	}
+	if override.PriceTickSize != "" || override.QuantityStepSize != "" {
+		precision := resolved.OrderPrecision()
+		tick, step := precision.TickSize.String(), precision.StepSize.String()
+		if override.PriceTickSize != "" {
+			tick = override.PriceTickSize
+		}
+		if override.QuantityStepSize != "" {
+			step = override.QuantityStepSize
+		}
+		var err error
+		if resolved.Precision, err = money.ParsePrecision(tick, step); err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+	}
+	if override.ContractType != "" {
+		contractType, err := domain.ParseContractType(override.ContractType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+		resolved.Contract.Type = contractType
+	}
+	if override.ContractSize != nil {
+		resolved.Contract.Size = *override.ContractSize
+	}
+	resolved.StrategyParams = override.Strategies
+
+	var errs []string
+	if resolved.Leverage <= 0 {
+		errs = append(errs, "leverage must be positive")
+	}
+	if resolved.Quantity <= 0 {
+		errs = append(errs, "quantity must be positive")
+	} else if resolved.QuantityMode != domain.QuantityModeQuote && resolved.OrderPrecision().RoundQuantity(resolved.Quantity) <= 0 {
+		errs = append(errs, "quantity must be at least the quantity step size")
+	}
+	if err := resolved.Contract.Validate(); err != nil {
+		errs = append(errs, "contract_size: "+err.Error())
+	}
+	if resolved.StopLoss <= 0 || resolved.StopLoss >= 1.0 {
+		errs = append(errs, "stop_loss must be between 0.0 and 1.0 (exclusive)")
+	} else if last := resolved.ScaleIn.LastStep(); last >= resolved.StopLoss {
+		errs = append(errs, fmt.Sprintf("stop_loss must be beyond the last scale-in step %v", last))
+	}
+	if resolved.MinProfit <= 0 || resolved.MaxProfit <= 0 || resolved.MinProfit >= resolved.MaxProfit {
+		errs = append(errs, "min_profit and max_profit must be positive with min_profit less than max_profit")
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid overrides for %s: %s", symbol, strings.Join(errs, "; "))
+	}
+	return &resolved, nil
+}
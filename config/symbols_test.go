@@ -0,0 +1,132 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// writeOverrides writes a symbol overrides file and returns its path
+func writeOverrides(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "symbols.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write overrides: %v", err)
+	}
+	return path
+}
+
+// globalConfig returns global settings that pass the per-symbol validation
+func globalConfig(t *testing.T, overrides string) *Config {
+	t.Helper()
+	cfg := &Config{Symbol: "ETHUSDT", Leverage: 3, Quantity: 0.1, StopLoss: 0.01, MinProfit: 0.005, MaxProfit: 0.02}
+	if overrides != "" {
+		var err error
+		if cfg.SymbolOverrides, err = LoadSymbolOverrides(writeOverrides(t, overrides)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	return cfg
+}
+
+func TestLoadSymbolOverrides(t *testing.T) {
+	overrides, err := LoadSymbolOverrides(writeOverrides(t, `
+btcusdt:
+  leverage: 5
+  strategies:
+    improved_ma_crossover: {fastMAPeriod: 5}
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	override, ok := overrides["BTCUSDT"]
+	if !ok {
+		t.Fatalf("Expected the symbol to be upper-cased, got %v", overrides)
+	}
+	if override.Leverage == nil || *override.Leverage != 5 || override.Quantity != nil {
+		t.Errorf("Expected only leverage 5 to be set, got %+v", override)
+	}
+	if got := override.Strategies["improved_ma_crossover"]["fastMAPeriod"]; got != 5 {
+		t.Errorf("Expected strategy parameter fastMAPeriod 5, got %v", got)
+	}
+
+	_, err = LoadSymbolOverrides(writeOverrides(t, "BTCUSDT:\n  levrage: 5\n"))
+	if err == nil || !strings.Contains(err.Error(), "levrage") {
+		t.Errorf("Expected the unknown key to be rejected, got %v", err)
+	}
+	if _, err := LoadSymbolOverrides(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestConfigForSymbol(t *testing.T) {
+	cfg := globalConfig(t, `
+BTCUSDT:
+  leverage: 5
+  stop_loss: 0.02
+  strategies:
+    ma_crossover: {shortMAPeriod: 10}
+`)
+
+	btc, err := cfg.ForSymbol("btcusdt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if btc.Symbol != "btcusdt" || btc.Leverage != 5 || btc.StopLoss != 0.02 {
+		t.Errorf("Expected the override to take precedence, got leverage %d, stop loss %v", btc.Leverage, btc.StopLoss)
+	}
+	if btc.Quantity != 0.1 || btc.MaxProfit != 0.02 {
+		t.Errorf("Expected the settings the block doesn't set to stay global, got quantity %v, max profit %v", btc.Quantity, btc.MaxProfit)
+	}
+	if got := btc.StrategyParams["ma_crossover"]["shortMAPeriod"]; got != 10 {
+		t.Errorf("Expected the symbol's strategy parameters, got %v", btc.StrategyParams)
+	}
+
+	eth, err := cfg.ForSymbol("ETHUSDT")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if eth.Leverage != 3 || eth.StopLoss != 0.01 || eth.StrategyParams != nil {
+		t.Errorf("Expected a symbol without a block to keep the global settings, got leverage %d, stop loss %v, params %v",
+			eth.Leverage, eth.StopLoss, eth.StrategyParams)
+	}
+
+	// Resolving again from BTCUSDT's configuration starts from the global settings, not BTCUSDT's
+	again, err := btc.ForSymbol("ETHUSDT")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if again.Leverage != 3 || again.StopLoss != 0.01 || again.StrategyParams != nil {
+		t.Errorf("Expected the global settings, got leverage %d, stop loss %v, params %v", again.Leverage, again.StopLoss, again.StrategyParams)
+	}
+	if cfg.Leverage != 3 || cfg.Symbol != "ETHUSDT" {
+		t.Errorf("Expected the global configuration to be unchanged, got %+v", cfg)
+	}
+}
+
+func TestConfigForSymbol_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides string
+		wantErr   string
+	}{
+		{name: "zero leverage", overrides: "BTCUSDT:\n  leverage: 0\n", wantErr: "leverage must be positive"},
+		{name: "negative leverage", overrides: "BTCUSDT:\n  leverage: -2\n", wantErr: "leverage must be positive"},
+		{name: "min profit equal to max profit", overrides: "BTCUSDT:\n  min_profit: 0.02\n", wantErr: "min_profit less than max_profit"},
+		{name: "min profit above max profit", overrides: "BTCUSDT:\n  min_profit: 0.03\n  max_profit: 0.01\n", wantErr: "min_profit less than max_profit"},
+		{name: "stop loss within the scale-in steps", overrides: "BTCUSDT:\n  stop_loss: 0.005\n", wantErr: "beyond the last scale-in step"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := globalConfig(t, tt.overrides)
+			cfg.ScaleIn = domain.ScaleInPlan{InitialFraction: 0.5, Steps: []float64{0.003, 0.006}}
+			_, err := cfg.ForSymbol("BTCUSDT")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "BTCUSDT") {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -18,7 +18,14 @@ CREATE TABLE IF NOT EXISTS positions (
     pnl REAL DEFAULT NULL,             -- Null if open
     stop_loss_order_id TEXT DEFAULT NULL, -- Store associated SL order ID (nullable)
     take_profit_order_id TEXT DEFAULT NULL, -- Store associated TP order ID (nullable)
-    close_reason TEXT DEFAULT NULL     -- Reason for closing (SL, TP, Market, etc.) (nullable)
+    close_reason TEXT DEFAULT NULL,    -- Reason for closing (SL, TP, Market, etc.) (nullable)
+    entry_reason TEXT DEFAULT NULL,    -- Why the position was entered (nullable)
+    signal_source TEXT DEFAULT NULL,   -- Entry signal type: crossover, pullback, scalp, ... (nullable)
+    confirmation_count INTEGER NOT NULL DEFAULT 0, -- Confirmation conditions met at entry
+    entry_atr REAL NOT NULL DEFAULT 0, -- ATR at entry in price units
+    side TEXT NOT NULL DEFAULT 'LONG', -- Position side: LONG or SHORT
+    scale_in_base_price REAL NOT NULL DEFAULT 0, -- Initial fill price scale-in adds are measured from (0 without scaling in)
+    scale_ins INTEGER NOT NULL DEFAULT 0         -- Scale-in adds filled so far
     -- Removed UNIQUE constraint, trigger handles the 'one open position' rule
 );
 
@@ -27,14 +34,76 @@ CREATE INDEX IF NOT EXISTS idx_positions_symbol_status ON positions(symbol, stat
 CREATE INDEX IF NOT EXISTS idx_positions_entry_time ON positions(entry_time);
 -- Removed indexes for trade_history
 
--- Trigger to enforce only one 'open' position per symbol
-CREATE TRIGGER IF NOT EXISTS enforce_one_open_position
+-- Persisted strategy risk state (one row per strategy/symbol)
+CREATE TABLE IF NOT EXISTS strategy_state (
+    strategy_name TEXT NOT NULL,
+    symbol TEXT NOT NULL,
+    state TEXT NOT NULL,          -- JSON produced by the strategy
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (strategy_name, symbol)
+);
+
+-- Daily summary reports (one row per UTC date/symbol)
+CREATE TABLE IF NOT EXISTS daily_reports (
+    report_date TEXT NOT NULL,    -- UTC date (YYYY-MM-DD)
+    symbol TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    trades INTEGER NOT NULL,
+    wins INTEGER NOT NULL,
+    losses INTEGER NOT NULL,
+    win_rate REAL NOT NULL,
+    gross_pnl REAL NOT NULL,
+    fees REAL NOT NULL,           -- Estimated entry and exit fees
+    net_pnl REAL NOT NULL,
+    balance REAL NOT NULL,        -- Account balance when the report was compiled
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (report_date, symbol)
+);
+
+-- Entry volume traded per UTC date/symbol, checked against the daily caps
+CREATE TABLE IF NOT EXISTS daily_volume (
+    trade_date TEXT NOT NULL,     -- UTC date (YYYY-MM-DD)
+    symbol TEXT NOT NULL,
+    notional REAL NOT NULL,       -- Quote notional of entry fills
+    volume REAL NOT NULL,         -- Base asset quantity of entry fills
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (trade_date, symbol)
+);
+
+-- Kline cache saved for warm starts (one row per symbol/interval/open time)
+CREATE TABLE IF NOT EXISTS kline_cache (
+    symbol TEXT NOT NULL,
+    kline_interval TEXT NOT NULL, -- e.g., 1m
+    open_time TIMESTAMP NOT NULL,
+    close_time TIMESTAMP NOT NULL,
+    open REAL NOT NULL,
+    high REAL NOT NULL,
+    low REAL NOT NULL,
+    close REAL NOT NULL,
+    volume REAL NOT NULL,
+    PRIMARY KEY (symbol, kline_interval, open_time)
+);
+
+-- Periods without new entries during exchange maintenance or outages
+CREATE TABLE IF NOT EXISTS safe_mode_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol TEXT NOT NULL,
+    reason TEXT NOT NULL,         -- What triggered safe mode
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP DEFAULT NULL -- NULL while safe mode is active
+);
+
+CREATE INDEX IF NOT EXISTS idx_safe_mode_events_symbol ON safe_mode_events(symbol, started_at);
+
+-- Trigger to enforce only one 'open' position per symbol and side (LONG and SHORT in hedge mode)
+CREATE TRIGGER IF NOT EXISTS enforce_one_open_position_per_side
 BEFORE INSERT ON positions
 WHEN NEW.status = 'open'
 BEGIN
-    SELECT RAISE(ABORT, 'Only one open position per symbol allowed')
+    SELECT RAISE(ABORT, 'Only one open position per symbol and side allowed')
     WHERE EXISTS (
-        SELECT 1 FROM positions 
-        WHERE symbol = NEW.symbol AND status = 'open'
+        SELECT 1 FROM positions
+        WHERE symbol = NEW.symbol AND side = NEW.side AND status = 'open'
     );
 END;
@@ -4,14 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"cryptoMegaBot/internal/domain"
 	"cryptoMegaBot/internal/ports"
 
 	"github.com/adshao/go-binance/v2/common"
+	"github.com/adshao/go-binance/v2/delivery"
 	"github.com/adshao/go-binance/v2/futures"
 )
 
@@ -22,11 +27,19 @@ const (
 )
 
 // Client implements the ports.ExchangeClient interface using the go-binance library.
+// Symbols configured as COIN-margined have their prices, klines, orders, positions and balances
+// routed to the delivery (COIN-M) endpoints; everything else uses the USDT-M endpoints.
 type Client struct {
 	futuresClient        *futures.Client
+	deliveryClient       *delivery.Client
+	contractTypes        map[string]domain.ContractType // Contract type by symbol; symbols not listed are USDT-margined
 	logger               ports.Logger
 	reconnectDelay       time.Duration
 	maxReconnectAttempts int
+	reconnects           *reconnectTracker                    // Reconnection statistics of the kline streams
+	latency              *latencyTracker                      // Request latency of order placements, cancellations and kline fetches
+	ws                   wsDialer                             // Opens the kline stream connections
+	after                func(time.Duration) <-chan time.Time // Waits out reconnect backoffs (time.After; overridable for tests)
 }
 
 // Config holds configuration specific to the Binance client adapter.
@@ -37,6 +50,9 @@ type Config struct {
 	Logger               ports.Logger
 	ReconnectDelay       time.Duration // Reconnect delay (e.g., 1 * time.Second)
 	MaxReconnectAttempts int           // Max attempts before giving up
+
+	// Contract type by symbol (e.g., COIN_MARGINED for ETHUSD_PERP); symbols not listed are USDT-margined
+	ContractTypes map[string]domain.ContractType
 }
 
 // New creates a new Binance client adapter.
@@ -51,13 +67,16 @@ func New(cfg Config) (*Client, error) {
 	}
 
 	client := futures.NewClient(cfg.APIKey, cfg.SecretKey)
+	deliveryClient := delivery.NewClient(cfg.APIKey, cfg.SecretKey)
 
 	// Set BaseURL directly instead of using global futures.UseTestnet
 	if cfg.UseTestnet {
 		client.BaseURL = baseURLTestnet
+		deliveryClient.BaseURL = deliveryBaseURLTestnet
 		cfg.Logger.Info(context.Background(), "Binance client configured for Testnet", map[string]interface{}{"baseURL": client.BaseURL})
 	} else {
 		client.BaseURL = baseURLProduction
+		deliveryClient.BaseURL = deliveryBaseURLProduction
 		cfg.Logger.Info(context.Background(), "Binance client configured for Production", map[string]interface{}{"baseURL": client.BaseURL})
 	}
 
@@ -71,11 +90,22 @@ func New(cfg Config) (*Client, error) {
 		maxAttempts = 10
 	}
 
+	contractTypes := make(map[string]domain.ContractType, len(cfg.ContractTypes))
+	for symbol, contractType := range cfg.ContractTypes {
+		contractTypes[strings.ToUpper(symbol)] = contractType
+	}
+
 	return &Client{
 		futuresClient:        client,
+		deliveryClient:       deliveryClient,
+		contractTypes:        contractTypes,
 		logger:               cfg.Logger,
 		reconnectDelay:       reconnectDelay,
 		maxReconnectAttempts: maxAttempts,
+		reconnects:           newReconnectTracker(),
+		latency:              newLatencyTracker(),
+		ws:                   binanceWsDialer{},
+		after:                time.After,
 	}, nil
 }
 
@@ -95,12 +125,18 @@ func (c *Client) handleError(ctx context.Context, err error, operation string) e
 		// Map specific Binance error codes to custom errors
 		var mappedErr error
 		switch apiErr.Code {
-		case -1003: // Too many requests
+		case -1001, -1007, -1008: // Internal error, backend timeout, server overloaded
+			mappedErr = ports.ErrExchangeUnavailable
+		case -1003, -1015: // Too many requests, too many new orders
 			mappedErr = ports.ErrRateLimited
+		case -1002: // Unauthorized for this request
+			mappedErr = ports.ErrPermissionDenied
 		case -1021: // Timestamp for this request is outside of the recvWindow
-			mappedErr = ports.ErrTimeout // Or a specific timing error
+			mappedErr = ports.ErrClockSkew
 		case -1022: // Signature for this request is not valid
 			mappedErr = ports.ErrAuthenticationFailed
+		case -1013: // Filter failure (price, lot size, notional)
+			mappedErr = ports.ErrInvalidRequest
 		case -1101, -1102, -1103, -1104, -1105, -1106, -1111, -1115, -1116, -1117, -1120, -1121, -1125, -1127, -1128, -1130: // Parameter/Request format errors
 			mappedErr = ports.ErrInvalidRequest
 		case -2010: // New order rejected
@@ -113,10 +149,14 @@ func (c *Client) handleError(ctx context.Context, err error, operation string) e
 			mappedErr = ports.ErrInvalidAPIKeys
 		case -2015: // Invalid API-key, IP, or permissions for action
 			mappedErr = ports.ErrInvalidAPIKeys // Could also be PermissionDenied
-		case -2019: // Margin is insufficient
+		case -2018, -2019, -2027, -2028: // Balance or margin insufficient, position limit at this leverage
 			mappedErr = ports.ErrInsufficientFunds
+		case -2021: // Order would immediately trigger
+			mappedErr = ports.ErrOrderPlacementFailed
 		case -2022: // ReduceOnly Order is rejected
-			mappedErr = ports.ErrOrderPlacementFailed // Or a more specific error
+			mappedErr = ports.ErrReduceOnlyRejected
+		case -5022: // Post Only order will be rejected (it would execute as taker)
+			mappedErr = ports.ErrPostOnlyRejected
 		case -3005: // Insufficient balance
 			mappedErr = ports.ErrInsufficientFunds
 		case -3041: // Position is not sufficient
@@ -127,14 +167,29 @@ func (c *Client) handleError(ctx context.Context, err error, operation string) e
 			mappedErr = ports.ErrInvalidRequest
 		case -4015: // Leverage is not valid
 			mappedErr = ports.ErrInvalidRequest
+		case -4164: // Order notional below the minimum
+			mappedErr = ports.ErrInvalidRequest
+		case -4046: // No need to change margin type
+			mappedErr = ports.ErrNoChangeNeeded
+		case -4059: // No need to change position side
+			mappedErr = ports.ErrNoChangeNeeded
 		case -4044: // Position not found
 			mappedErr = ports.ErrPositionNotFound
 		case -4047: // Exceeded the maximum allowable position at current leverage.
 			mappedErr = ports.ErrInsufficientFunds // Or a specific position limit error
 		default:
-			// General classification for unmapped API errors
-			mappedErr = ports.ErrUnknown
+			if !apiErr.IsValid() {
+				// 5xx error page without a JSON body (gateway errors, maintenance)
+				mappedErr = ports.ErrExchangeUnavailable
+			} else {
+				// General classification for unmapped API errors
+				mappedErr = ports.ErrUnknown
+			}
+		}
+		if isMaintenance(apiErr) {
+			mappedErr = ports.ErrExchangeMaintenance
 		}
+		fields["category"] = string(ports.Category(mappedErr))
 		finalErr := fmt.Errorf("%s failed: %w: %w", operation, mappedErr, err)
 		c.logger.Error(ctx, err, fmt.Sprintf("%s failed with API error", operation), fields)
 		return finalErr
@@ -142,23 +197,45 @@ func (c *Client) handleError(ctx context.Context, err error, operation string) e
 
 	// Handle non-API errors (network, context cancellation, etc.)
 	var finalErr error
-	if errors.Is(err, context.DeadlineExceeded) {
-		finalErr = fmt.Errorf("%s failed: %w: %w", operation, ports.ErrTimeout, err)
-	} else if errors.Is(err, context.Canceled) {
+	if errors.Is(err, context.Canceled) {
 		finalErr = fmt.Errorf("%s operation canceled: %w: %w", operation, ports.ErrContextCanceled, err)
-	} else if strings.Contains(err.Error(), "use of closed network connection") ||
-		strings.Contains(err.Error(), "connection refused") ||
-		strings.Contains(err.Error(), "connection reset by peer") {
-		finalErr = fmt.Errorf("%s failed: %w: %w", operation, ports.ErrConnectionFailed, err)
 	} else {
-		// Default for other errors (e.g., parsing errors within the adapter)
-		finalErr = fmt.Errorf("%s failed: %w: %w", operation, ports.ErrUnknown, err)
+		mappedErr := mapTransportError(err)
+		fields["category"] = string(ports.Category(mappedErr))
+		finalErr = fmt.Errorf("%s failed: %w: %w", operation, mappedErr, err)
 	}
 
 	c.logger.Error(ctx, err, fmt.Sprintf("%s failed", operation), fields)
 	return finalErr
 }
 
+// mapTransportError classifies an error that didn't come with an API response: timeouts, failed
+// or dropped connections and DNS failures are transient, anything else (e.g., a response the
+// adapter couldn't parse) is unknown.
+func mapTransportError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ports.ErrTimeout
+	case errors.Is(err, net.ErrClosed), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ports.ErrConnectionFailed
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return ports.ErrConnectionFailed
+	}
+	return ports.ErrUnknown
+}
+
+// isMaintenance reports whether an API error announces system maintenance, either in its
+// message or in the body of an error page.
+func isMaintenance(apiErr *common.APIError) bool {
+	text := strings.ToLower(apiErr.Message + " " + string(apiErr.Response))
+	return strings.Contains(text, "maintenance")
+}
+
 // SetServerTime synchronizes the client's time with the server's time.
 func (c *Client) SetServerTime(ctx context.Context) error {
 	op := "SetServerTime"
@@ -173,6 +250,9 @@ func (c *Client) SetServerTime(ctx context.Context) error {
 // GetMarkPrice retrieves the current mark price for a given symbol.
 func (c *Client) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
 	op := "GetMarkPrice"
+	if c.isCoinMargined(symbol) {
+		return c.deliveryPrice(ctx, op, symbol)
+	}
 	tickers, err := c.futuresClient.NewPremiumIndexService().Symbol(symbol).Do(ctx)
 	if err != nil {
 		return 0, c.handleError(ctx, err, op)
@@ -194,6 +274,9 @@ func (c *Client) GetMarkPrice(ctx context.Context, symbol string) (float64, erro
 // GetTickerPrice retrieves the last ticker price for a given symbol.
 func (c *Client) GetTickerPrice(ctx context.Context, symbol string) (float64, error) {
 	op := "GetTickerPrice"
+	if c.isCoinMargined(symbol) {
+		return c.deliveryPrice(ctx, op, symbol)
+	}
 	tickers, err := c.futuresClient.NewListPriceChangeStatsService().Symbol(symbol).Do(ctx)
 	if err != nil {
 		return 0, c.handleError(ctx, err, op)
@@ -211,9 +294,83 @@ func (c *Client) GetTickerPrice(ctx context.Context, symbol string) (float64, er
 	return price, nil
 }
 
+// GetTickerStats retrieves the rolling 24h ticker statistics of a symbol (implements
+// ports.TickerStatsProvider).
+func (c *Client) GetTickerStats(ctx context.Context, symbol string) (*ports.TickerStats, error) {
+	op := "GetTickerStats"
+	tickers, err := c.futuresClient.NewListPriceChangeStatsService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	if len(tickers) == 0 {
+		err := fmt.Errorf("no ticker data returned for symbol %s", symbol)
+		return nil, c.handleError(ctx, err, op)
+	}
+
+	ticker := tickers[0]
+	stats := &ports.TickerStats{Symbol: ticker.Symbol}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"lastPrice", ticker.LastPrice, &stats.LastPrice},
+		{"priceChangePercent", ticker.PriceChangePercent, &stats.PriceChangePercent},
+		{"volume", ticker.Volume, &stats.Volume},
+		{"quoteVolume", ticker.QuoteVolume, &stats.QuoteVolume},
+	} {
+		value, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			parseErr := fmt.Errorf("could not parse %s '%s': %w", field.name, field.value, err)
+			return nil, c.handleError(ctx, parseErr, op)
+		}
+		*field.dest = value
+	}
+	return stats, nil
+}
+
+// GetBookTicker retrieves the best bid and ask of a symbol (implements ports.BookTickerProvider).
+func (c *Client) GetBookTicker(ctx context.Context, symbol string) (*ports.BookTicker, error) {
+	op := "GetBookTicker"
+	tickers, err := c.futuresClient.NewListBookTickersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	if len(tickers) == 0 {
+		err := fmt.Errorf("no book ticker returned for symbol %s", symbol)
+		return nil, c.handleError(ctx, err, op)
+	}
+
+	ticker := tickers[0]
+	book := &ports.BookTicker{Symbol: ticker.Symbol}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"bidPrice", ticker.BidPrice, &book.BidPrice},
+		{"bidQty", ticker.BidQuantity, &book.BidQty},
+		{"askPrice", ticker.AskPrice, &book.AskPrice},
+		{"askQty", ticker.AskQuantity, &book.AskQty},
+	} {
+		value, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			parseErr := fmt.Errorf("could not parse %s '%s': %w", field.name, field.value, err)
+			return nil, c.handleError(ctx, parseErr, op)
+		}
+		*field.dest = value
+	}
+	return book, nil
+}
+
 // GetAccountBalance retrieves the available balance for a specific asset (e.g., "USDT").
+// The margin coins of COIN-margined symbols (e.g., ETH for ETHUSD_PERP) are read from the
+// COIN-margined account.
 func (c *Client) GetAccountBalance(ctx context.Context, asset string) (float64, error) {
 	op := "GetAccountBalance"
+	if c.isCoinMarginAsset(asset) {
+		return c.deliveryBalance(ctx, op, asset)
+	}
 	account, err := c.futuresClient.NewGetAccountService().Do(ctx)
 	if err != nil {
 		return 0, c.handleError(ctx, err, op)
@@ -262,43 +419,203 @@ func (c *Client) GetServerTime(ctx context.Context) (time.Time, error) {
 // SetLeverage sets the leverage for a specific symbol.
 func (c *Client) SetLeverage(ctx context.Context, symbol string, leverage int) error {
 	op := "SetLeverage"
-	_, err := c.futuresClient.NewChangeLeverageService().
+	var err error
+	if c.isCoinMargined(symbol) {
+		_, err = c.deliveryClient.NewChangeLeverageService().Symbol(symbol).Leverage(leverage).Do(ctx)
+	} else {
+		_, err = c.futuresClient.NewChangeLeverageService().
+			Symbol(symbol).
+			Leverage(leverage).
+			Do(ctx)
+	}
+	if err != nil {
+		return c.handleError(ctx, err, op)
+	}
+	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "leverage": leverage})
+	return nil
+}
+
+// GetLeverageBrackets fetches the notional brackets and their maximum leverage for a symbol
+// (implements ports.LeverageBracketProvider).
+func (c *Client) GetLeverageBrackets(ctx context.Context, symbol string) ([]ports.LeverageBracket, error) {
+	op := "GetLeverageBrackets"
+	res, err := c.futuresClient.NewGetLeverageBracketService().
 		Symbol(symbol).
-		Leverage(leverage).
 		Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	var brackets []ports.LeverageBracket
+	for _, r := range res {
+		if r.Symbol != symbol {
+			continue
+		}
+		for _, b := range r.Brackets {
+			brackets = append(brackets, ports.LeverageBracket{
+				Bracket:          b.Bracket,
+				InitialLeverage:  b.InitialLeverage,
+				NotionalFloor:    b.NotionalFloor,
+				NotionalCap:      b.NotionalCap,
+				MaintMarginRatio: b.MaintMarginRatio,
+			})
+		}
+	}
+	if len(brackets) == 0 {
+		return nil, c.handleError(ctx, fmt.Errorf("no leverage brackets returned for %s", symbol), op)
+	}
+	sort.Slice(brackets, func(i, j int) bool { return brackets[i].NotionalCap < brackets[j].NotionalCap })
+	return brackets, nil
+}
+
+// ChangeMarginType switches the margin mode (isolated or cross) for a symbol.
+func (c *Client) ChangeMarginType(ctx context.Context, symbol string, marginType domain.MarginType) error {
+	op := "ChangeMarginType"
+	var err error
+	if c.isCoinMargined(symbol) {
+		err = c.deliveryClient.NewChangeMarginTypeService().Symbol(symbol).MarginType(delivery.MarginType(marginType)).Do(ctx)
+	} else {
+		err = c.futuresClient.NewChangeMarginTypeService().
+			Symbol(symbol).
+			MarginType(futures.MarginType(marginType)). // Direct conversion assuming values match
+			Do(ctx)
+	}
 	if err != nil {
 		return c.handleError(ctx, err, op)
 	}
-	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "leverage": leverage})
+	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "marginType": marginType})
 	return nil
 }
 
-// PlaceMarketOrder places a market order.
-func (c *Client) PlaceMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string) (*ports.OrderResponse, error) {
+// PlaceMarketOrder places a market order, tagged with clientOrderID unless it is empty.
+func (c *Client) PlaceMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, clientOrderID string) (_ *ports.OrderResponse, err error) {
+	defer c.latency.observe(ports.LatencyPlaceOrder, time.Now(), &err)
 	op := "PlaceMarketOrder"
+	if c.isCoinMargined(symbol) {
+		resp, err := c.placeDeliveryOrder(ctx, deliveryOrder{symbol: symbol, side: side, positionSide: positionSide, orderType: delivery.OrderTypeMarket, quantity: quantity, clientOrderID: clientOrderID})
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "contracts": quantity, "orderID": resp.OrderID, "clientOrderID": resp.ClientOrderID, "avgPrice": resp.AvgPrice})
+		return resp, nil
+	}
 	binanceSide := futures.SideType(side) // Direct conversion assuming values match
 
-	order, err := c.futuresClient.NewCreateOrderService().
+	service := withPositionSide(c.futuresClient.NewCreateOrderService(), positionSide).
 		Symbol(symbol).
 		Side(binanceSide).
 		Type(futures.OrderTypeMarket).
+		Quantity(quantity)
+	if clientOrderID != "" {
+		service = service.NewClientOrderID(clientOrderID)
+	}
+	order, err := service.Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+
+	resp := translateOrderResponse(order)
+	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "quantity": quantity, "orderID": resp.OrderID, "clientOrderID": resp.ClientOrderID, "avgPrice": resp.AvgPrice})
+	return resp, nil
+}
+
+// PlaceLimitOrder places a good-till-canceled limit order (implements ports.LimitOrderPlacer).
+func (c *Client) PlaceLimitOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, price string, clientOrderID string) (_ *ports.OrderResponse, err error) {
+	defer c.latency.observe(ports.LatencyPlaceOrder, time.Now(), &err)
+	return c.placeLimitOrder(ctx, "PlaceLimitOrder", symbol, side, positionSide, quantity, price, clientOrderID, false)
+}
+
+// PlacePostOnlyLimitOrder places a good-till-crossing limit order, which Binance rejects instead
+// of filling as taker (implements ports.PostOnlyOrderPlacer).
+func (c *Client) PlacePostOnlyLimitOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, price string, clientOrderID string) (_ *ports.OrderResponse, err error) {
+	defer c.latency.observe(ports.LatencyPlaceOrder, time.Now(), &err)
+	return c.placeLimitOrder(ctx, "PlacePostOnlyLimitOrder", symbol, side, positionSide, quantity, price, clientOrderID, true)
+}
+
+// placeLimitOrder places a limit order, good-till-crossing if postOnly and good-till-canceled otherwise.
+func (c *Client) placeLimitOrder(ctx context.Context, op, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity, price, clientOrderID string, postOnly bool) (*ports.OrderResponse, error) {
+	if c.isCoinMargined(symbol) {
+		resp, err := c.placeDeliveryOrder(ctx, deliveryOrder{symbol: symbol, side: side, positionSide: positionSide, orderType: delivery.OrderTypeLimit, quantity: quantity, price: price, clientOrderID: clientOrderID, postOnly: postOnly})
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "contracts": quantity, "price": price, "orderID": resp.OrderID, "clientOrderID": resp.ClientOrderID})
+		return resp, nil
+	}
+	binanceSide := futures.SideType(side)
+	timeInForce := futures.TimeInForceTypeGTC
+	if postOnly {
+		timeInForce = futures.TimeInForceTypeGTX
+	}
+
+	service := withPositionSide(c.futuresClient.NewCreateOrderService(), positionSide).
+		Symbol(symbol).
+		Side(binanceSide).
+		Type(futures.OrderTypeLimit).
+		TimeInForce(timeInForce).
 		Quantity(quantity).
-		Do(ctx)
+		Price(price)
+	if clientOrderID != "" {
+		service = service.NewClientOrderID(clientOrderID)
+	}
+	order, err := service.Do(ctx)
 	if err != nil {
 		return nil, c.handleError(ctx, err, op)
 	}
 
 	resp := translateOrderResponse(order)
-	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "quantity": quantity, "orderID": resp.OrderID, "avgPrice": resp.AvgPrice})
+	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "quantity": quantity, "price": price, "orderID": resp.OrderID, "clientOrderID": resp.ClientOrderID})
+	return resp, nil
+}
+
+// PlaceReduceOnlyMarketOrder places a market order that can only reduce the position (implements
+// ports.ReduceOnlyOrderPlacer). Hedge mode orders reduce the position side they close, which the
+// exchange doesn't accept the reduce-only flag for.
+func (c *Client) PlaceReduceOnlyMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string) (_ *ports.OrderResponse, err error) {
+	defer c.latency.observe(ports.LatencyPlaceOrder, time.Now(), &err)
+	op := "PlaceReduceOnlyMarketOrder"
+	hedged := positionSide == domain.PositionSideLong || positionSide == domain.PositionSideShort
+	if c.isCoinMargined(symbol) {
+		resp, err := c.placeDeliveryOrder(ctx, deliveryOrder{symbol: symbol, side: side, positionSide: positionSide, orderType: delivery.OrderTypeMarket, quantity: quantity, reduceOnly: !hedged})
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "contracts": quantity, "orderID": resp.OrderID, "avgPrice": resp.AvgPrice})
+		return resp, nil
+	}
+
+	service := withPositionSide(c.futuresClient.NewCreateOrderService(), positionSide).
+		Symbol(symbol).
+		Side(futures.SideType(side)).
+		Type(futures.OrderTypeMarket).
+		Quantity(quantity)
+	if !hedged {
+		service = service.ReduceOnly(true)
+	}
+	order, err := service.Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+
+	resp := translateOrderResponse(order)
+	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "quantity": quantity, "orderID": resp.OrderID, "avgPrice": resp.AvgPrice})
 	return resp, nil
 }
 
 // PlaceStopMarketOrder places a stop-market order.
-func (c *Client) PlaceStopMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
+func (c *Client) PlaceStopMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (_ *ports.OrderResponse, err error) {
+	defer c.latency.observe(ports.LatencyPlaceOrder, time.Now(), &err)
 	op := "PlaceStopMarketOrder"
+	if c.isCoinMargined(symbol) {
+		resp, err := c.placeDeliveryOrder(ctx, deliveryOrder{symbol: symbol, side: side, positionSide: positionSide, orderType: delivery.OrderTypeStopMarket, quantity: quantity, stopPrice: stopPrice, closePosition: true})
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "contracts": quantity, "stopPrice": stopPrice, "orderID": resp.OrderID})
+		return resp, nil
+	}
 	binanceSide := futures.SideType(side)
 
-	order, err := c.futuresClient.NewCreateOrderService().
+	order, err := withPositionSide(c.futuresClient.NewCreateOrderService(), positionSide).
 		Symbol(symbol).
 		Side(binanceSide).
 		Type(futures.OrderTypeStopMarket).
@@ -311,25 +628,35 @@ func (c *Client) PlaceStopMarketOrder(ctx context.Context, symbol string, side d
 	}
 
 	resp := translateOrderResponse(order)
-	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "quantity": quantity, "stopPrice": stopPrice, "orderID": resp.OrderID})
+	c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "positionSide": positionSide, "quantity": quantity, "stopPrice": stopPrice, "orderID": resp.OrderID})
 	return resp, nil
 }
 
 // PlaceTakeProfitMarketOrder places a take-profit-market order.
-func (c *Client) PlaceTakeProfitMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
+func (c *Client) PlaceTakeProfitMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (_ *ports.OrderResponse, err error) {
+	defer c.latency.observe(ports.LatencyPlaceOrder, time.Now(), &err)
 	op := "PlaceTakeProfitMarketOrder"
+	if c.isCoinMargined(symbol) {
+		resp, err := c.placeDeliveryOrder(ctx, deliveryOrder{symbol: symbol, side: side, positionSide: positionSide, orderType: delivery.OrderTypeTakeProfitMarket, quantity: quantity, stopPrice: stopPrice, closePosition: true})
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "side": side, "contracts": quantity, "stopPrice": stopPrice, "orderID": resp.OrderID, "status": resp.Status})
+		return resp, nil
+	}
 	binanceSide := futures.SideType(side)
 
 	// Add detailed logging before order placement
 	c.logger.Info(ctx, op+": Attempting to place take profit order", map[string]interface{}{
-		"symbol":    symbol,
-		"side":      side,
-		"quantity":  quantity,
-		"stopPrice": stopPrice,
-		"type":      "TAKE_PROFIT_MARKET",
+		"symbol":       symbol,
+		"side":         side,
+		"positionSide": positionSide,
+		"quantity":     quantity,
+		"stopPrice":    stopPrice,
+		"type":         "TAKE_PROFIT_MARKET",
 	})
 
-	order, err := c.futuresClient.NewCreateOrderService().
+	order, err := withPositionSide(c.futuresClient.NewCreateOrderService(), positionSide).
 		Symbol(symbol).
 		Side(binanceSide).
 		Type(futures.OrderTypeTakeProfitMarket).
@@ -360,9 +687,35 @@ func (c *Client) PlaceTakeProfitMarketOrder(ctx context.Context, symbol string,
 	return resp, nil
 }
 
-// GetPositionRisk retrieves the risk information for a specific position symbol.
-func (c *Client) GetPositionRisk(ctx context.Context, symbol string) (*ports.PositionRisk, error) {
-	op := "GetPositionRisk"
+// withPositionSide sets the hedge mode position side on an order. One-way mode orders (BOTH or
+// empty) are left without one, which is what the exchange expects in that mode.
+func withPositionSide(svc *futures.CreateOrderService, positionSide domain.PositionSide) *futures.CreateOrderService {
+	if positionSide == domain.PositionSideLong || positionSide == domain.PositionSideShort {
+		svc = svc.PositionSide(futures.PositionSideType(positionSide))
+	}
+	return svc
+}
+
+// SetPositionMode switches the account between hedge mode and one-way mode.
+func (c *Client) SetPositionMode(ctx context.Context, hedgeMode bool) error {
+	op := "SetPositionMode"
+	err := c.futuresClient.NewChangePositionModeService().
+		DualSide(hedgeMode).
+		Do(ctx)
+	if err != nil {
+		return c.handleError(ctx, err, op)
+	}
+	c.logger.Info(ctx, op+" successful", map[string]interface{}{"hedgeMode": hedgeMode})
+	return nil
+}
+
+// GetPositionRisks retrieves the risk information of a symbol's open positions, one per side
+// with a non-zero amount.
+func (c *Client) GetPositionRisks(ctx context.Context, symbol string) ([]*ports.PositionRisk, error) {
+	op := "GetPositionRisks"
+	if c.isCoinMargined(symbol) {
+		return c.deliveryPositionRisks(ctx, op, symbol)
+	}
 	positions, err := c.futuresClient.NewGetPositionRiskService().Symbol(symbol).Do(ctx)
 	if err != nil {
 		return nil, c.handleError(ctx, err, op)
@@ -372,16 +725,18 @@ func (c *Client) GetPositionRisk(ctx context.Context, symbol string) (*ports.Pos
 		return nil, nil // It's valid not to have a position
 	}
 
-	// Assuming only one position per symbol for futures
-	binancePos := positions[0]
-	qty, _ := strconv.ParseFloat(binancePos.PositionAmt, 64) // Ignore error, default to 0
-	if qty == 0 {
+	// One entry per symbol in one-way mode, one per side (LONG, SHORT) in hedge mode
+	var risks []*ports.PositionRisk
+	for _, binancePos := range positions {
+		qty, _ := strconv.ParseFloat(binancePos.PositionAmt, 64) // Ignore error, default to 0
+		if qty != 0 {
+			risks = append(risks, translatePositionRisk(binancePos))
+		}
+	}
+	if len(risks) == 0 {
 		c.logger.Debug(ctx, op+": Position amount is zero for symbol", map[string]interface{}{"symbol": symbol})
-		return nil, nil // Position amount is zero, effectively no position
 	}
-
-	resp := translatePositionRisk(binancePos)
-	return resp, nil
+	return risks, nil
 }
 
 // StreamKlines starts a WebSocket stream for K-line/candlestick data.
@@ -389,9 +744,8 @@ func (c *Client) StreamKlines(ctx context.Context, symbol, interval string, hand
 	op := "StreamKlines"
 	wsCtx, cancelWs := context.WithCancel(ctx) // Create a cancellable context for the WS lifecycle
 
-	// Wrapper for the domain handler to perform translation
-	binanceHandler := func(event *futures.WsKlineEvent) {
-		domainKline, err := translateWsKline(event)
+	// Passes translated klines to the domain handler; translation errors are only logged
+	onKline := func(domainKline *domain.Kline, err error) {
 		if err != nil {
 			c.logger.Error(wsCtx, err, op+": Failed to translate WebSocket kline event")
 			// Decide if we should call the errHandler or just log
@@ -409,11 +763,17 @@ func (c *Client) StreamKlines(ctx context.Context, symbol, interval string, hand
 		errHandler(translatedErr) // Pass the translated error up
 	}
 
+	// COIN-margined symbols stream from the delivery endpoints
+	coinMargined := c.isCoinMargined(symbol)
+
 	// Reconnection loop
+	streamID := c.reconnects.register()
 	go func() {
 		defer cancelWs() // Ensure context is cancelled when this goroutine exits
+		defer c.reconnects.stopped(streamID)
 
 		attempt := 0
+		everConnected := false // Whether a connection succeeded before, so the next one is a reconnect
 		for {
 			select {
 			case <-wsCtx.Done():
@@ -422,10 +782,11 @@ func (c *Client) StreamKlines(ctx context.Context, symbol, interval string, hand
 			default:
 				// Attempt connection
 				c.logger.Info(wsCtx, op+": Attempting WebSocket connection...", map[string]interface{}{"symbol": symbol, "interval": interval, "attempt": attempt + 1})
-				innerDoneCh, innerStopCh, connectErr := futures.WsKlineServe(symbol, interval, binanceHandler, binanceErrHandler)
+				innerDoneCh, innerStopCh, connectErr := c.ws.DialKlines(symbol, interval, coinMargined, onKline, binanceErrHandler)
 
 				if connectErr != nil {
 					c.handleError(wsCtx, connectErr, op+" connection attempt") // Log the connection error
+					c.reconnects.connectFailed(streamID)
 					attempt++
 					if attempt >= c.maxReconnectAttempts {
 						c.logger.Error(wsCtx, connectErr, op+": Max reconnection attempts exceeded, giving up.", map[string]interface{}{"symbol": symbol, "interval": interval, "maxAttempts": c.maxReconnectAttempts})
@@ -434,14 +795,12 @@ func (c *Client) StreamKlines(ctx context.Context, symbol, interval string, hand
 						return
 					}
 
-					// Calculate delay with exponential backoff and jitter
-					delay := c.reconnectDelay * time.Duration(1<<uint(attempt-1))
-					jitter := time.Duration(float64(delay) * 0.1 * float64(time.Millisecond)) // 10% jitter
-					actualDelay := delay + jitter
+					// Exponential backoff
+					actualDelay := c.reconnectBackoff(attempt)
 					c.logger.Info(wsCtx, op+": Connection failed, retrying...", map[string]interface{}{"symbol": symbol, "interval": interval, "attempt": attempt + 1, "delay": actualDelay.String()})
 
 					select {
-					case <-time.After(actualDelay):
+					case <-c.after(actualDelay):
 						continue // Retry connection
 					case <-wsCtx.Done():
 						c.logger.Info(wsCtx, op+": Context cancelled during backoff.", map[string]interface{}{"symbol": symbol, "interval": interval})
@@ -450,12 +809,26 @@ func (c *Client) StreamKlines(ctx context.Context, symbol, interval string, hand
 				}
 
 				// Connection successful
-				c.logger.Info(wsCtx, op+": WebSocket connection established.", map[string]interface{}{"symbol": symbol, "interval": interval})
+				outage := c.reconnects.connected(streamID, everConnected)
+				if everConnected {
+					stats := c.reconnects.snapshot()
+					c.logger.Info(wsCtx, op+": WebSocket reconnected.", map[string]interface{}{
+						"symbol":        symbol,
+						"interval":      interval,
+						"outage":        outage.Round(time.Millisecond).String(),
+						"reconnects":    stats.Reconnects,
+						"totalDowntime": (time.Duration(stats.DowntimeMs) * time.Millisecond).String(),
+					})
+				} else {
+					c.logger.Info(wsCtx, op+": WebSocket connection established.", map[string]interface{}{"symbol": symbol, "interval": interval})
+				}
+				everConnected = true
 				attempt = 0 // Reset attempt count on successful connection
 
 				// Wait for the inner connection to close or the context to be cancelled
 				select {
 				case <-innerDoneCh:
+					c.reconnects.disconnected(streamID)
 					c.logger.Warn(wsCtx, op+": WebSocket connection closed unexpectedly. Reconnecting...", map[string]interface{}{"symbol": symbol, "interval": interval})
 					// Loop will continue and attempt reconnection
 				case <-wsCtx.Done():
@@ -503,8 +876,12 @@ func (c *Client) StreamKlines(ctx context.Context, symbol, interval string, hand
 }
 
 // GetKlines retrieves historical klines/candlestick data for the given symbol.
-func (c *Client) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]*domain.Kline, error) {
+func (c *Client) GetKlines(ctx context.Context, symbol string, interval string, limit int) (_ []*domain.Kline, err error) {
+	defer c.latency.observe(ports.LatencyFetchKlines, time.Now(), &err)
 	op := "GetKlines"
+	if c.isCoinMargined(symbol) {
+		return c.deliveryKlines(ctx, op, symbol, interval, limit)
+	}
 	binanceKlines, err := c.futuresClient.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit).Do(ctx)
 	if err != nil {
 		return nil, c.handleError(ctx, err, op)
@@ -524,6 +901,32 @@ func (c *Client) GetKlines(ctx context.Context, symbol string, interval string,
 	return domainKlines, nil
 }
 
+// GetOrderBookDepth retrieves a snapshot of the order book with up to limit levels per side.
+// Binance only accepts limits of 5, 10, 20, 50, 100, 500 and 1000, so limit is rounded up to the next valid value.
+func (c *Client) GetOrderBookDepth(ctx context.Context, symbol string, limit int) (*ports.OrderBookDepth, error) {
+	op := "GetOrderBookDepth"
+	depth, err := c.futuresClient.NewDepthService().Symbol(symbol).Limit(validDepthLimit(limit)).Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+
+	bids, err := translatePriceLevels(depth.Bids)
+	if err != nil {
+		return nil, c.handleError(ctx, fmt.Errorf("failed to translate bids: %w", err), op)
+	}
+	asks, err := translatePriceLevels(depth.Asks)
+	if err != nil {
+		return nil, c.handleError(ctx, fmt.Errorf("failed to translate asks: %w", err), op)
+	}
+
+	return &ports.OrderBookDepth{
+		Symbol:    symbol,
+		Bids:      bids,
+		Asks:      asks,
+		Timestamp: time.UnixMilli(depth.Time),
+	}, nil
+}
+
 // GetKlinesRange fetches all klines for a symbol/interval between start and end time.
 func (c *Client) GetKlinesRange(ctx context.Context, symbol, interval string, start, end time.Time) ([]*domain.Kline, error) {
 	op := "GetKlinesRange"
@@ -532,6 +935,7 @@ func (c *Client) GetKlinesRange(ctx context.Context, symbol, interval string, st
 	from := start
 
 	for {
+		requested := time.Now()
 		klines, err := c.futuresClient.NewKlinesService().
 			Symbol(symbol).
 			Interval(interval).
@@ -539,6 +943,7 @@ func (c *Client) GetKlinesRange(ctx context.Context, symbol, interval string, st
 			EndTime(end.UnixMilli()).
 			Limit(maxLimit).
 			Do(ctx)
+		c.latency.record(ports.LatencyFetchKlines, time.Since(requested), err != nil) // Each page is a request
 		if err != nil {
 			return nil, c.handleError(ctx, err, op)
 		}
@@ -562,10 +967,287 @@ func (c *Client) GetKlinesRange(ctx context.Context, symbol, interval string, st
 	return allKlines, nil
 }
 
+// GetFundingRateHistory fetches the funding rate settlements of a symbol between start and end,
+// paginating through the exchange's 1000-entry limit.
+func (c *Client) GetFundingRateHistory(ctx context.Context, symbol string, start, end time.Time) ([]*domain.FundingRate, error) {
+	op := "GetFundingRateHistory"
+	const maxLimit = 1000
+	var rates []*domain.FundingRate
+
+	for from := start; from.Before(end); {
+		history, err := c.futuresClient.NewFundingRateService().
+			Symbol(symbol).
+			StartTime(from.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(maxLimit).
+			Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		for _, h := range history {
+			rate, err := strconv.ParseFloat(h.FundingRate, 64)
+			if err != nil {
+				return nil, c.handleError(ctx, fmt.Errorf("failed to parse funding rate %q: %w", h.FundingRate, err), op)
+			}
+			// Older settlements are reported without a mark price
+			markPrice, _ := strconv.ParseFloat(h.MarkPrice, 64)
+			rates = append(rates, &domain.FundingRate{
+				Symbol:      h.Symbol,
+				FundingTime: time.UnixMilli(h.FundingTime),
+				Rate:        rate,
+				MarkPrice:   markPrice,
+			})
+		}
+		if len(history) < maxLimit {
+			break
+		}
+		from = time.UnixMilli(history[len(history)-1].FundingTime + 1)
+	}
+	return rates, nil
+}
+
+// GetOpenInterestHistory fetches open interest snapshots of a symbol at the given period (e.g.,
+// "5m", "1h") between start and end. Binance only serves the last 30 days of open interest.
+func (c *Client) GetOpenInterestHistory(ctx context.Context, symbol, period string, start, end time.Time) ([]*domain.OpenInterest, error) {
+	op := "GetOpenInterestHistory"
+	const maxLimit = 500
+	var snapshots []*domain.OpenInterest
+
+	for from := start; from.Before(end); {
+		history, err := c.futuresClient.NewOpenInterestStatisticsService().
+			Symbol(symbol).
+			Period(period).
+			StartTime(from.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(maxLimit).
+			Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		for _, h := range history {
+			contracts, err := strconv.ParseFloat(h.SumOpenInterest, 64)
+			if err != nil {
+				return nil, c.handleError(ctx, fmt.Errorf("failed to parse open interest %q: %w", h.SumOpenInterest, err), op)
+			}
+			value, err := strconv.ParseFloat(h.SumOpenInterestValue, 64)
+			if err != nil {
+				return nil, c.handleError(ctx, fmt.Errorf("failed to parse open interest value %q: %w", h.SumOpenInterestValue, err), op)
+			}
+			snapshots = append(snapshots, &domain.OpenInterest{
+				Symbol:    h.Symbol,
+				Time:      time.UnixMilli(h.Timestamp),
+				Period:    period,
+				Contracts: contracts,
+				Value:     value,
+			})
+		}
+		if len(history) < maxLimit {
+			break
+		}
+		from = time.UnixMilli(history[len(history)-1].Timestamp + 1)
+	}
+	return snapshots, nil
+}
+
+// GetOrder retrieves an order by ID, including filled, canceled and expired orders.
+// Returns ErrOrderNotFound if the exchange has no such order for the symbol.
+func (c *Client) GetOrder(ctx context.Context, symbol string, orderID int64) (*ports.OrderResponse, error) {
+	op := "GetOrder"
+	if c.isCoinMargined(symbol) {
+		order, err := c.deliveryClient.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		return translateDeliveryOrder(order), nil
+	}
+	order, err := c.futuresClient.NewGetOrderService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	return translateOrder(order), nil
+}
+
+// GetOrderByClientID retrieves an order by its client order ID, including filled, canceled and
+// expired orders. Returns ErrOrderNotFound if the exchange has no such order for the symbol.
+func (c *Client) GetOrderByClientID(ctx context.Context, symbol string, clientOrderID string) (*ports.OrderResponse, error) {
+	op := "GetOrderByClientID"
+	if c.isCoinMargined(symbol) {
+		order, err := c.deliveryClient.NewGetOrderService().Symbol(symbol).OrigClientOrderID(clientOrderID).Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		return translateDeliveryOrder(order), nil
+	}
+	order, err := c.futuresClient.NewGetOrderService().
+		Symbol(symbol).
+		OrigClientOrderID(clientOrderID).
+		Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	return translateOrder(order), nil
+}
+
+// ListOpenOrders retrieves the open orders for a symbol.
+func (c *Client) ListOpenOrders(ctx context.Context, symbol string) ([]*ports.OrderResponse, error) {
+	op := "ListOpenOrders"
+	if c.isCoinMargined(symbol) {
+		orders, err := c.deliveryClient.NewListOpenOrdersService().Symbol(symbol).Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		result := make([]*ports.OrderResponse, 0, len(orders))
+		for _, order := range orders {
+			result = append(result, translateDeliveryOrder(order))
+		}
+		return result, nil
+	}
+	orders, err := c.futuresClient.NewListOpenOrdersService().
+		Symbol(symbol).
+		Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	result := make([]*ports.OrderResponse, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, translateOrder(order))
+	}
+	return result, nil
+}
+
+// GetAccountTrades fetches the account's fills for a symbol between start and end time,
+// ordered by execution time. Binance limits each request to 7 days and 1000 fills, so the
+// range is walked in windows and pages.
+func (c *Client) GetAccountTrades(ctx context.Context, symbol string, start, end time.Time) ([]ports.AccountFill, error) {
+	op := "GetAccountTrades"
+	const (
+		maxLimit  = 1000
+		maxWindow = 7 * 24 * time.Hour
+	)
+	var fills []ports.AccountFill
+
+	for from := start; from.Before(end); {
+		to := from.Add(maxWindow - time.Millisecond)
+		if to.After(end) {
+			to = end
+		}
+		trades, err := c.futuresClient.NewListAccountTradeService().
+			Symbol(symbol).
+			StartTime(from.UnixMilli()).
+			EndTime(to.UnixMilli()).
+			Limit(maxLimit).
+			Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		for _, t := range trades {
+			fill, err := translateAccountTrade(t)
+			if err != nil {
+				return nil, c.handleError(ctx, fmt.Errorf("failed to translate account trade %d: %w", t.ID, err), op)
+			}
+			fills = append(fills, fill)
+		}
+
+		// A full page may leave fills in the window: continue after the last one
+		if len(trades) == maxLimit {
+			from = time.UnixMilli(trades[len(trades)-1].Time + 1)
+		} else {
+			from = to.Add(time.Millisecond)
+		}
+	}
+	return fills, nil
+}
+
+// GetOrderFills fetches the executions of an order (implements ports.OrderFillProvider). Binance
+// doesn't include them in order responses, so they are read from the account's trade history.
+func (c *Client) GetOrderFills(ctx context.Context, symbol string, orderID int64) ([]ports.AccountFill, error) {
+	op := "GetOrderFills"
+	trades, err := c.futuresClient.NewListAccountTradeService().
+		Symbol(symbol).
+		OrderID(orderID).
+		Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	fills := make([]ports.AccountFill, 0, len(trades))
+	for _, t := range trades {
+		fill, err := translateAccountTrade(t)
+		if err != nil {
+			return nil, c.handleError(ctx, fmt.Errorf("failed to translate account trade %d: %w", t.ID, err), op)
+		}
+		fills = append(fills, fill)
+	}
+	return fills, nil
+}
+
+// GetIncomeHistory fetches the account's income history for a symbol between start and end
+// time, ordered by time. An empty incomeType returns every type.
+func (c *Client) GetIncomeHistory(ctx context.Context, symbol, incomeType string, start, end time.Time) ([]ports.IncomeRecord, error) {
+	op := "GetIncomeHistory"
+	const maxLimit = 1000
+	var records []ports.IncomeRecord
+
+	for from := start; from.Before(end); {
+		svc := c.futuresClient.NewGetIncomeHistoryService().
+			Symbol(symbol).
+			StartTime(from.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(maxLimit)
+		if incomeType != "" {
+			svc = svc.IncomeType(incomeType)
+		}
+		history, err := svc.Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		for _, h := range history {
+			income, err := strconv.ParseFloat(h.Income, 64)
+			if err != nil {
+				return nil, c.handleError(ctx, fmt.Errorf("failed to parse income %q: %w", h.Income, err), op)
+			}
+			records = append(records, ports.IncomeRecord{
+				Symbol:     h.Symbol,
+				IncomeType: h.IncomeType,
+				Income:     income,
+				Asset:      h.Asset,
+				Time:       time.UnixMilli(h.Time),
+			})
+		}
+		if len(history) < maxLimit {
+			break
+		}
+		from = time.UnixMilli(history[len(history)-1].Time + 1)
+	}
+	return records, nil
+}
+
 // CancelOrder cancels an open order on Binance.
-func (c *Client) CancelOrder(ctx context.Context, symbol string, orderID int64) (*ports.OrderResponse, error) {
+func (c *Client) CancelOrder(ctx context.Context, symbol string, orderID int64) (_ *ports.OrderResponse, err error) {
+	defer c.latency.observe(ports.LatencyCancelOrder, time.Now(), &err)
 	op := "CancelOrder"
 	c.logger.Debug(ctx, "Attempting to cancel order", map[string]interface{}{"symbol": symbol, "orderID": orderID})
+	if c.isCoinMargined(symbol) {
+		res, err := c.deliveryClient.NewCancelOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+		if err != nil {
+			return nil, c.handleError(ctx, err, op)
+		}
+		resp := translateDeliveryOrderResponse(&delivery.CreateOrderResponse{
+			OrderID:       res.OrderID,
+			Symbol:        res.Symbol,
+			ClientOrderID: res.ClientOrderID,
+			Price:         res.Price,
+			OrigQuantity:  res.OrigQuantity,
+			Status:        res.Status,
+			TimeInForce:   res.TimeInForce,
+			Type:          res.Type,
+			Side:          res.Side,
+		})
+		c.logger.Info(ctx, op+" successful", map[string]interface{}{"symbol": symbol, "orderID": orderID, "status": resp.Status})
+		return resp, nil
+	}
 
 	res, err := c.futuresClient.NewCancelOrderService().
 		Symbol(symbol).
@@ -612,6 +1294,7 @@ func translateOrderResponse(order *futures.CreateOrderResponse) *ports.OrderResp
 	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
 	origQty, _ := strconv.ParseFloat(order.OrigQuantity, 64)
 	execQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	stopPrice, _ := strconv.ParseFloat(order.StopPrice, 64)
 
 	return &ports.OrderResponse{
 		OrderID:       order.OrderID,
@@ -626,9 +1309,61 @@ func translateOrderResponse(order *futures.CreateOrderResponse) *ports.OrderResp
 		Type:          string(order.Type),
 		Side:          string(order.Side),
 		Timestamp:     time.UnixMilli(order.UpdateTime), // Assuming UpdateTime is relevant timestamp
+		StopPrice:     stopPrice,
+		ClosePosition: order.ClosePosition,
 	}
 }
 
+// translateOrder converts a queried order into an OrderResponse.
+func translateOrder(order *futures.Order) *ports.OrderResponse {
+	if order == nil {
+		return nil
+	}
+	return translateOrderResponse(&futures.CreateOrderResponse{
+		OrderID:          order.OrderID,
+		Symbol:           order.Symbol,
+		ClientOrderID:    order.ClientOrderID,
+		Price:            order.Price,
+		AvgPrice:         order.AvgPrice,
+		OrigQuantity:     order.OrigQuantity,
+		ExecutedQuantity: order.ExecutedQuantity,
+		Status:           order.Status,
+		TimeInForce:      order.TimeInForce,
+		Type:             order.Type,
+		Side:             order.Side,
+		UpdateTime:       order.UpdateTime,
+		StopPrice:        order.StopPrice,
+		ClosePosition:    order.ClosePosition,
+	})
+}
+
+// translateAccountTrade converts a fill from the account trade list.
+func translateAccountTrade(t *futures.AccountTrade) (ports.AccountFill, error) {
+	fill := ports.AccountFill{
+		ID:              t.ID,
+		OrderID:         t.OrderID,
+		Symbol:          t.Symbol,
+		Side:            domain.OrderSide(t.Side),
+		PositionSide:    domain.PositionSide(t.PositionSide),
+		CommissionAsset: t.CommissionAsset,
+		Time:            time.UnixMilli(t.Time),
+	}
+	var err error
+	if fill.Price, err = strconv.ParseFloat(t.Price, 64); err != nil {
+		return fill, fmt.Errorf("invalid price %q: %w", t.Price, err)
+	}
+	if fill.Quantity, err = strconv.ParseFloat(t.Quantity, 64); err != nil {
+		return fill, fmt.Errorf("invalid quantity %q: %w", t.Quantity, err)
+	}
+	if fill.RealizedPnL, err = strconv.ParseFloat(t.RealizedPnl, 64); err != nil {
+		return fill, fmt.Errorf("invalid realized PnL %q: %w", t.RealizedPnl, err)
+	}
+	if fill.Commission, err = strconv.ParseFloat(t.Commission, 64); err != nil {
+		return fill, fmt.Errorf("invalid commission %q: %w", t.Commission, err)
+	}
+	return fill, nil
+}
+
 func translatePositionRisk(pos *futures.PositionRisk) *ports.PositionRisk {
 	if pos == nil {
 		return nil
@@ -654,10 +1389,46 @@ func translatePositionRisk(pos *futures.PositionRisk) *ports.PositionRisk {
 		IsolatedMargin:   isoMargin,
 		IsAutoAddMargin:  isAutoAdd,
 		MaxNotionalValue: maxNotional,
+		MarginType:       translateMarginType(pos.MarginType),
+		PositionSide:     domain.PositionSide(pos.PositionSide),
 		// UpdateTime: time.UnixMilli(pos.UpdateTime), // Removed as field doesn't exist in source
 	}
 }
 
+// validDepthLimit rounds limit up to the nearest depth limit accepted by Binance.
+func validDepthLimit(limit int) int {
+	for _, valid := range []int{5, 10, 20, 50, 100, 500, 1000} {
+		if limit <= valid {
+			return valid
+		}
+	}
+	return 1000
+}
+
+func translatePriceLevels(levels []common.PriceLevel) ([]ports.OrderBookLevel, error) {
+	result := make([]ports.OrderBookLevel, 0, len(levels))
+	for _, level := range levels {
+		price, qty, err := level.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("parsing price level %s@%s: %w", level.Quantity, level.Price, err)
+		}
+		result = append(result, ports.OrderBookLevel{Price: price, Quantity: qty})
+	}
+	return result, nil
+}
+
+// translateMarginType normalizes the position risk margin type ("isolated"/"cross") to domain values.
+func translateMarginType(marginType string) domain.MarginType {
+	switch strings.ToLower(marginType) {
+	case "isolated":
+		return domain.MarginTypeIsolated
+	case "cross", "crossed":
+		return domain.MarginTypeCrossed
+	default:
+		return domain.MarginType(strings.ToUpper(marginType))
+	}
+}
+
 func translateWsKline(event *futures.WsKlineEvent) (*domain.Kline, error) {
 	if event == nil {
 		return nil, errors.New("received nil kline event")
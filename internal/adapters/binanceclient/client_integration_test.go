@@ -0,0 +1,228 @@
+//go:build integration
+
+package binanceclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests run against the Binance Futures testnet and need testnet API keys:
+//
+//	BINANCE_TESTNET_API_KEY=... BINANCE_TESTNET_API_SECRET=... make test-integration
+//
+// BINANCE_TESTNET_SYMBOL overrides the traded symbol (default BTCUSDT).
+
+const streamDuration = 30 * time.Second
+
+// newTestnetClient creates a client for the testnet, skipping the test when no keys are configured.
+func newTestnetClient(t *testing.T) (*Client, string) {
+	t.Helper()
+	apiKey := os.Getenv("BINANCE_TESTNET_API_KEY")
+	secretKey := os.Getenv("BINANCE_TESTNET_API_SECRET")
+	if apiKey == "" || secretKey == "" {
+		t.Skip("BINANCE_TESTNET_API_KEY and BINANCE_TESTNET_API_SECRET must be set to run testnet integration tests")
+	}
+	symbol := os.Getenv("BINANCE_TESTNET_SYMBOL")
+	if symbol == "" {
+		symbol = "BTCUSDT"
+	}
+
+	client, err := New(Config{
+		APIKey:     apiKey,
+		SecretKey:  secretKey,
+		UseTestnet: true,
+		Logger:     logger.NewStdLogger(logger.LevelWarn),
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.SetServerTime(context.Background()))
+	return client, symbol
+}
+
+func TestIntegration_MarketData(t *testing.T) {
+	client, symbol := newTestnetClient(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.Ping(ctx))
+
+	serverTime, err := client.GetServerTime(ctx)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), serverTime, time.Minute)
+
+	markPrice, err := client.GetMarkPrice(ctx, symbol)
+	require.NoError(t, err)
+	assert.Greater(t, markPrice, 0.0)
+
+	tickerPrice, err := client.GetTickerPrice(ctx, symbol)
+	require.NoError(t, err)
+	assert.InEpsilon(t, markPrice, tickerPrice, 0.05)
+
+	klines, err := client.GetKlines(ctx, symbol, "1m", 50)
+	require.NoError(t, err)
+	require.Len(t, klines, 50)
+	for i, k := range klines {
+		assert.Equal(t, symbol, k.Symbol)
+		assert.Equal(t, "1m", k.Interval)
+		assert.True(t, k.CloseTime.After(k.OpenTime), "kline %d close time after open time", i)
+		assert.GreaterOrEqual(t, k.High, k.Low, "kline %d high >= low", i)
+		assert.GreaterOrEqual(t, k.High, k.Close, "kline %d high >= close", i)
+		assert.LessOrEqual(t, k.Low, k.Open, "kline %d low <= open", i)
+		if i > 0 {
+			assert.Equal(t, time.Minute, k.OpenTime.Sub(klines[i-1].OpenTime), "kline %d follows the previous one", i)
+		}
+	}
+
+	depth, err := client.GetOrderBookDepth(ctx, symbol, 5)
+	require.NoError(t, err)
+	require.NotEmpty(t, depth.Bids)
+	require.NotEmpty(t, depth.Asks)
+	assert.LessOrEqual(t, depth.Bids[0].Price, depth.Asks[0].Price)
+}
+
+func TestIntegration_AccountSettings(t *testing.T) {
+	client, symbol := newTestnetClient(t)
+	ctx := context.Background()
+
+	balance, err := client.GetAccountBalance(ctx, "USDT")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, balance, 0.0)
+
+	require.NoError(t, client.SetLeverage(ctx, symbol, 5))
+
+	risks, err := client.GetPositionRisks(ctx, symbol)
+	require.NoError(t, err)
+	require.NotEmpty(t, risks)
+	pos := risks[0]
+	assert.Equal(t, symbol, pos.Symbol)
+	assert.Equal(t, 5, pos.Leverage)
+
+	// Switching to the margin type already in effect is reported as ErrNoChangeNeeded
+	err = client.ChangeMarginType(ctx, symbol, pos.MarginType)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ports.ErrNoChangeNeeded)
+}
+
+func TestIntegration_PlaceAndCancelOrder(t *testing.T) {
+	client, symbol := newTestnetClient(t)
+	ctx := context.Background()
+
+	markPrice, err := client.GetMarkPrice(ctx, symbol)
+	require.NoError(t, err)
+
+	// A stop far below the market rests on the book without triggering
+	stopPrice := fmt.Sprintf("%.1f", markPrice*0.5)
+	order, err := client.PlaceStopMarketOrder(ctx, symbol, domain.Sell, domain.PositionSideBoth, "0.001", stopPrice)
+	require.NoError(t, err)
+	require.NotNil(t, order)
+	assert.NotZero(t, order.OrderID)
+	assert.Equal(t, symbol, order.Symbol)
+	assert.Equal(t, string(domain.Sell), order.Side)
+
+	canceled, err := client.CancelOrder(ctx, symbol, order.OrderID)
+	require.NoError(t, err)
+	assert.Equal(t, order.OrderID, canceled.OrderID)
+	assert.Equal(t, "CANCELED", canceled.Status)
+
+	// The canceled order is still part of the order history
+	queried, err := client.GetOrder(ctx, symbol, order.OrderID)
+	require.NoError(t, err)
+	assert.Equal(t, "CANCELED", queried.Status)
+	byClientID, err := client.GetOrderByClientID(ctx, symbol, order.ClientOrderID)
+	require.NoError(t, err)
+	assert.Equal(t, order.OrderID, byClientID.OrderID)
+
+	// Unknown client order IDs are reported as ErrOrderNotFound
+	_, err = client.GetOrderByClientID(ctx, symbol, "cmb-never-placed")
+	assert.ErrorIs(t, err, ports.ErrOrderNotFound)
+
+	// Canceling it again is rejected by the exchange
+	_, err = client.CancelOrder(ctx, symbol, order.OrderID)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ports.ErrOrderNotFound) || errors.Is(err, ports.ErrOrderCancelFailed),
+		"unexpected error mapping: %v", err)
+}
+
+func TestIntegration_ErrorMapping(t *testing.T) {
+	client, _ := newTestnetClient(t)
+	ctx := context.Background()
+
+	_, err := client.GetKlines(ctx, "NOTASYMBOL", "1m", 10)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ports.ErrInvalidRequest)
+
+	_, err = client.PlaceMarketOrder(ctx, "NOTASYMBOL", domain.Buy, domain.PositionSideBoth, "0.001", "")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ports.ErrInvalidRequest)
+
+	unauthorized, err := New(Config{
+		APIKey:     "invalid-key",
+		SecretKey:  "invalid-secret",
+		UseTestnet: true,
+		Logger:     logger.NewStdLogger(logger.LevelError),
+	})
+	require.NoError(t, err)
+	_, err = unauthorized.GetAccountBalance(ctx, "USDT")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ports.ErrInvalidAPIKeys)
+}
+
+func TestIntegration_StreamKlines(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping kline stream test in short mode")
+	}
+	client, symbol := newTestnetClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var klines []*domain.Kline
+	var streamErrs []error
+	doneCh, stopCh, err := client.StreamKlines(ctx, symbol, "1m",
+		func(k *domain.Kline) {
+			mu.Lock()
+			defer mu.Unlock()
+			klines = append(klines, k)
+		},
+		func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			streamErrs = append(streamErrs, err)
+		})
+	require.NoError(t, err)
+
+	time.Sleep(streamDuration)
+	select {
+	case stopCh <- struct{}{}:
+	default:
+	}
+	cancel()
+	select {
+	case <-doneCh:
+	case <-time.After(10 * time.Second):
+		t.Error("stream did not shut down within 10s")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Empty(t, streamErrs)
+	require.NotEmpty(t, klines, "expected kline events within %s", streamDuration)
+	for _, k := range klines {
+		assert.Equal(t, symbol, k.Symbol)
+		assert.Equal(t, "1m", k.Interval)
+		assert.Greater(t, k.Close, 0.0)
+		assert.GreaterOrEqual(t, k.High, k.Low)
+	}
+}
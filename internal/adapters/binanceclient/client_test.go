@@ -0,0 +1,58 @@
+package binanceclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+func TestHandleErrorCategories(t *testing.T) {
+	c := &Client{logger: logger.NewStdLogger(logger.LevelError)}
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"server overloaded", &common.APIError{Code: -1008, Message: "Server is currently overloaded"}, ports.ErrExchangeUnavailable},
+		{"too many new orders", &common.APIError{Code: -1015, Message: "Too many new orders"}, ports.ErrRateLimited},
+		{"filter failure", &common.APIError{Code: -1013, Message: "Filter failure: LOT_SIZE"}, ports.ErrInvalidRequest},
+		{"would trigger", &common.APIError{Code: -2021, Message: "Order would immediately trigger."}, ports.ErrOrderPlacementFailed},
+		{"reduce only rejected", &common.APIError{Code: -2022, Message: "ReduceOnly Order is rejected."}, ports.ErrReduceOnlyRejected},
+		{"post only rejected", &common.APIError{Code: -5022, Message: "Due to the order could not be executed as maker, the Post Only order will be rejected."}, ports.ErrPostOnlyRejected},
+		{"max position", &common.APIError{Code: -2027, Message: "Exceeded the maximum allowable position at current leverage."}, ports.ErrInsufficientFunds},
+		{"unauthorized", &common.APIError{Code: -1002, Message: "You are not authorized to execute this request."}, ports.ErrPermissionDenied},
+		{"maintenance", &common.APIError{Code: -1001, Message: "System is under maintenance"}, ports.ErrExchangeMaintenance},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, ports.ErrConnectionFailed},
+		{"connection reset", fmt.Errorf("read: %w", syscall.ECONNRESET), ports.ErrConnectionFailed},
+		{"closed connection", fmt.Errorf("write: %w", net.ErrClosed), ports.ErrConnectionFailed},
+		{"dropped response", fmt.Errorf("Get https://fapi.binance.com: %w", io.ErrUnexpectedEOF), ports.ErrConnectionFailed},
+		{"dns", &net.DNSError{Err: "no such host", Name: "fapi.binance.com"}, ports.ErrConnectionFailed},
+		{"deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), ports.ErrTimeout},
+		{"canceled", context.Canceled, ports.ErrContextCanceled},
+		{"unparsable", errors.New("invalid character '<' looking for beginning of value"), ports.ErrUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.handleError(ctx, tt.err, "test")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("handleError(%v) = %v, want it to wrap %v", tt.err, err, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected the original error to stay wrapped, got %v", err)
+			}
+		})
+	}
+	if err := c.handleError(ctx, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, "test"); !ports.IsRetryable(err) {
+		t.Errorf("Expected a refused connection to be retryable")
+	}
+}
@@ -0,0 +1,313 @@
+package binanceclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/adshao/go-binance/v2/delivery"
+)
+
+const (
+	// Base URLs of the COIN-margined (delivery) futures API
+	deliveryBaseURLProduction = "https://dapi.binance.com"
+	deliveryBaseURLTestnet    = "https://testnet.binancefuture.com"
+)
+
+// isCoinMargined reports whether symbol is configured as a COIN-margined contract, whose requests
+// go to the delivery endpoints.
+func (c *Client) isCoinMargined(symbol string) bool {
+	return c.contractTypes[strings.ToUpper(symbol)] == domain.ContractTypeCoin
+}
+
+// isCoinMarginAsset reports whether asset is the margin asset of a COIN-margined symbol, whose
+// balance is held in the delivery account.
+func (c *Client) isCoinMarginAsset(asset string) bool {
+	for symbol, contractType := range c.contractTypes {
+		if contract := (domain.Contract{Type: contractType}); contract.IsInverse() && contract.MarginAsset(symbol) == strings.ToUpper(asset) {
+			return true
+		}
+	}
+	return false
+}
+
+// deliveryOrder holds the parameters of an order sent to the delivery endpoints. Empty price,
+// stopPrice and clientOrderID are left out of the request.
+type deliveryOrder struct {
+	symbol        string
+	side          domain.OrderSide
+	positionSide  domain.PositionSide
+	orderType     delivery.OrderType
+	quantity      string // Contracts
+	price         string // Limit price, with good-till-canceled time in force unless postOnly
+	stopPrice     string
+	clientOrderID string
+	closePosition bool
+	reduceOnly    bool
+	postOnly      bool // Good-till-crossing limit order
+}
+
+// placeDeliveryOrder places an order on a COIN-margined symbol.
+func (c *Client) placeDeliveryOrder(ctx context.Context, o deliveryOrder) (*ports.OrderResponse, error) {
+	svc := c.deliveryClient.NewCreateOrderService().
+		Symbol(o.symbol).
+		Side(delivery.SideType(o.side)).
+		Type(o.orderType).
+		Quantity(o.quantity)
+	if o.positionSide == domain.PositionSideLong || o.positionSide == domain.PositionSideShort {
+		svc = svc.PositionSide(delivery.PositionSideType(o.positionSide))
+	}
+	if o.price != "" {
+		timeInForce := delivery.TimeInForceTypeGTC
+		if o.postOnly {
+			timeInForce = delivery.TimeInForceTypeGTX
+		}
+		svc = svc.Price(o.price).TimeInForce(timeInForce)
+	}
+	if o.stopPrice != "" {
+		svc = svc.StopPrice(o.stopPrice)
+	}
+	if o.closePosition {
+		svc = svc.ClosePosition(true)
+	}
+	if o.reduceOnly {
+		svc = svc.ReduceOnly(true)
+	}
+	if o.clientOrderID != "" {
+		svc = svc.NewClientOrderID(o.clientOrderID)
+	}
+	order, err := svc.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return translateDeliveryOrderResponse(order), nil
+}
+
+// deliveryPrice returns the last price of a COIN-margined symbol. The delivery API has no premium
+// index service in the client library, so it also stands in for the mark price.
+func (c *Client) deliveryPrice(ctx context.Context, op, symbol string) (float64, error) {
+	prices, err := c.deliveryClient.NewListPricesService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return 0, c.handleError(ctx, err, op)
+	}
+	for _, p := range prices {
+		if p.Symbol != symbol {
+			continue
+		}
+		price, err := strconv.ParseFloat(p.Price, 64)
+		if err != nil {
+			return 0, c.handleError(ctx, fmt.Errorf("could not parse price '%s': %w", p.Price, err), op)
+		}
+		return price, nil
+	}
+	return 0, c.handleError(ctx, fmt.Errorf("no price data returned for symbol %s", symbol), op)
+}
+
+// deliveryBalance returns the wallet balance of a coin in the COIN-margined account.
+func (c *Client) deliveryBalance(ctx context.Context, op, asset string) (float64, error) {
+	balances, err := c.deliveryClient.NewGetBalanceService().Do(ctx)
+	if err != nil {
+		return 0, c.handleError(ctx, err, op)
+	}
+	for _, bal := range balances {
+		if strings.EqualFold(bal.Asset, asset) {
+			balance, err := strconv.ParseFloat(bal.Balance, 64)
+			if err != nil {
+				return 0, c.handleError(ctx, fmt.Errorf("could not parse balance '%s' for asset %s: %w", bal.Balance, asset, err), op)
+			}
+			return balance, nil
+		}
+	}
+	return 0, c.handleError(ctx, fmt.Errorf("asset %s not found in COIN-margined account balance", asset), op)
+}
+
+// deliveryPositionRisks returns each side of a COIN-margined symbol with a non-zero amount.
+func (c *Client) deliveryPositionRisks(ctx context.Context, op, symbol string) ([]*ports.PositionRisk, error) {
+	positions, err := c.deliveryClient.NewGetPositionRiskService().Pair(deliveryPair(symbol)).Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	var risks []*ports.PositionRisk
+	for _, pos := range positions {
+		if pos.Symbol != symbol {
+			continue
+		}
+		if qty, _ := strconv.ParseFloat(pos.PositionAmt, 64); qty != 0 {
+			risks = append(risks, translateDeliveryPositionRisk(pos))
+		}
+	}
+	if len(risks) == 0 {
+		c.logger.Debug(ctx, op+": No position found for symbol", map[string]interface{}{"symbol": symbol})
+	}
+	return risks, nil
+}
+
+// deliveryKlines returns the latest historical klines of a COIN-margined symbol.
+func (c *Client) deliveryKlines(ctx context.Context, op, symbol, interval string, limit int) ([]*domain.Kline, error) {
+	klines, err := c.deliveryClient.NewKlinesService().Symbol(symbol).Interval(interval).Limit(limit).Do(ctx)
+	if err != nil {
+		return nil, c.handleError(ctx, err, op)
+	}
+	result := make([]*domain.Kline, 0, len(klines))
+	for _, k := range klines {
+		dk, err := translateDeliveryKline(k, symbol, interval)
+		if err != nil {
+			return nil, c.handleError(ctx, fmt.Errorf("failed to translate historical kline: %w", err), op)
+		}
+		result = append(result, dk)
+	}
+	return result, nil
+}
+
+// deliveryPair returns the underlying pair of a COIN-margined symbol (ETHUSD for ETHUSD_PERP and
+// quarterly contracts like ETHUSD_250926).
+func deliveryPair(symbol string) string {
+	if i := strings.Index(symbol, "_"); i > 0 {
+		return symbol[:i]
+	}
+	return symbol
+}
+
+// --- Translation Helpers ---
+
+func translateDeliveryOrderResponse(order *delivery.CreateOrderResponse) *ports.OrderResponse {
+	if order == nil {
+		return nil
+	}
+	price, _ := strconv.ParseFloat(order.Price, 64)
+	avgPrice, _ := strconv.ParseFloat(order.AvgPrice, 64)
+	origQty, _ := strconv.ParseFloat(order.OrigQuantity, 64)
+	execQty, _ := strconv.ParseFloat(order.ExecutedQuantity, 64)
+	stopPrice, _ := strconv.ParseFloat(order.StopPrice, 64)
+
+	return &ports.OrderResponse{
+		OrderID:       order.OrderID,
+		Symbol:        order.Symbol,
+		ClientOrderID: order.ClientOrderID,
+		Price:         price,
+		AvgPrice:      avgPrice,
+		OrigQuantity:  origQty,
+		ExecutedQty:   execQty,
+		Status:        string(order.Status),
+		TimeInForce:   string(order.TimeInForce),
+		Type:          string(order.Type),
+		Side:          string(order.Side),
+		Timestamp:     time.UnixMilli(order.UpdateTime),
+		StopPrice:     stopPrice,
+		ClosePosition: order.ClosePosition,
+	}
+}
+
+// translateDeliveryOrder converts a queried COIN-margined order into an OrderResponse.
+func translateDeliveryOrder(order *delivery.Order) *ports.OrderResponse {
+	if order == nil {
+		return nil
+	}
+	return translateDeliveryOrderResponse(&delivery.CreateOrderResponse{
+		OrderID:          order.OrderID,
+		Symbol:           order.Symbol,
+		ClientOrderID:    order.ClientOrderID,
+		Price:            order.Price,
+		AvgPrice:         order.AvgPrice,
+		OrigQuantity:     order.OrigQuantity,
+		ExecutedQuantity: order.ExecutedQuantity,
+		Status:           order.Status,
+		TimeInForce:      order.TimeInForce,
+		Type:             order.Type,
+		Side:             order.Side,
+		UpdateTime:       order.UpdateTime,
+		StopPrice:        order.StopPrice,
+		ClosePosition:    order.ClosePosition,
+	})
+}
+
+func translateDeliveryPositionRisk(pos *delivery.PositionRisk) *ports.PositionRisk {
+	if pos == nil {
+		return nil
+	}
+	posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
+	entryPrice, _ := strconv.ParseFloat(pos.EntryPrice, 64)
+	markPrice, _ := strconv.ParseFloat(pos.MarkPrice, 64)
+	unProfit, _ := strconv.ParseFloat(pos.UnRealizedProfit, 64)
+	liqPrice, _ := strconv.ParseFloat(pos.LiquidationPrice, 64)
+	leverage, _ := strconv.Atoi(pos.Leverage)
+	isoMargin, _ := strconv.ParseFloat(pos.IsolatedMargin, 64)
+	isAutoAdd, _ := strconv.ParseBool(pos.IsAutoAddMargin)
+
+	return &ports.PositionRisk{
+		Symbol:           pos.Symbol,
+		PositionAmt:      posAmt, // Contracts
+		EntryPrice:       entryPrice,
+		MarkPrice:        markPrice,
+		UnRealizedProfit: unProfit, // In the base coin
+		LiquidationPrice: liqPrice,
+		Leverage:         leverage,
+		IsolatedMargin:   isoMargin,
+		IsAutoAddMargin:  isAutoAdd,
+		MarginType:       translateMarginType(pos.MarginType),
+		PositionSide:     domain.PositionSide(pos.PositionSide),
+	}
+}
+
+func translateDeliveryWsKline(event *delivery.WsKlineEvent) (*domain.Kline, error) {
+	if event == nil {
+		return nil, errors.New("received nil kline event")
+	}
+	k := event.Kline
+	kline := &domain.Kline{
+		OpenTime:  time.UnixMilli(k.StartTime),
+		CloseTime: time.UnixMilli(k.EndTime),
+		Symbol:    k.Symbol,
+		Interval:  k.Interval,
+		IsFinal:   k.IsFinal,
+	}
+	if err := parseKlineValues(kline, k.Open, k.High, k.Low, k.Close, k.Volume); err != nil {
+		return nil, err
+	}
+	return kline, nil
+}
+
+func translateDeliveryKline(k *delivery.Kline, symbol, interval string) (*domain.Kline, error) {
+	if k == nil {
+		return nil, errors.New("received nil historical kline")
+	}
+	kline := &domain.Kline{
+		OpenTime:  time.UnixMilli(k.OpenTime),
+		CloseTime: time.UnixMilli(k.CloseTime),
+		Symbol:    symbol,
+		Interval:  interval,
+		IsFinal:   true,
+	}
+	if err := parseKlineValues(kline, k.Open, k.High, k.Low, k.Close, k.Volume); err != nil {
+		return nil, err
+	}
+	return kline, nil
+}
+
+// parseKlineValues parses a kline's prices and volume (in contracts for COIN-margined symbols).
+func parseKlineValues(kline *domain.Kline, open, high, low, cls, volume string) error {
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *float64
+	}{
+		{"open price", open, &kline.Open},
+		{"high price", high, &kline.High},
+		{"low price", low, &kline.Low},
+		{"close price", cls, &kline.Close},
+		{"volume", volume, &kline.Volume},
+	} {
+		value, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return fmt.Errorf("parsing %s '%s': %w", field.name, field.value, err)
+		}
+		*field.dest = value
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package binanceclient
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// latencyWindow is the number of recent requests per operation the percentiles are calculated from.
+const latencyWindow = 200
+
+// operationSamples holds the latencies of an operation's recent requests in a ring buffer.
+type operationSamples struct {
+	stats   ports.OperationLatency
+	samples []time.Duration
+	next    int // Index the next sample overwrites once the buffer is full
+}
+
+// latencyTracker records the request latency of the client's exchange operations.
+type latencyTracker struct {
+	mu         sync.Mutex
+	window     int
+	operations map[string]*operationSamples
+}
+
+// newLatencyTracker creates an empty tracker.
+func newLatencyTracker() *latencyTracker {
+	return &latencyTracker{window: latencyWindow, operations: make(map[string]*operationSamples)}
+}
+
+// observe records a request of operation that started at start and failed if *err is set. Meant
+// to be deferred with a pointer to the caller's named error result.
+func (t *latencyTracker) observe(operation string, start time.Time, err *error) {
+	t.record(operation, time.Since(start), err != nil && *err != nil)
+}
+
+// record adds a request of operation that took latency.
+func (t *latencyTracker) record(operation string, latency time.Duration, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	op, ok := t.operations[operation]
+	if !ok {
+		op = &operationSamples{samples: make([]time.Duration, 0, t.window)}
+		t.operations[operation] = op
+	}
+	op.stats.Count++
+	if failed {
+		op.stats.Errors++
+	}
+	op.stats.LastMs = latency.Milliseconds()
+	if len(op.samples) < t.window {
+		op.samples = append(op.samples, latency)
+		return
+	}
+	op.samples[op.next] = latency
+	op.next = (op.next + 1) % t.window
+}
+
+// snapshot returns the statistics of every operation requested so far.
+func (t *latencyTracker) snapshot() map[string]ports.OperationLatency {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := make(map[string]ports.OperationLatency, len(t.operations))
+	for operation, op := range t.operations {
+		sorted := append([]time.Duration(nil), op.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		s := op.stats
+		s.Samples = len(sorted)
+		s.P50Ms = percentile(sorted, 0.50).Milliseconds()
+		s.P95Ms = percentile(sorted, 0.95).Milliseconds()
+		s.P99Ms = percentile(sorted, 0.99).Milliseconds()
+		s.MaxMs = sorted[len(sorted)-1].Milliseconds()
+		stats[operation] = s
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank p percentile of sorted, which must not be empty.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+// LatencyStats returns the request latency of the client's order placements, cancellations and
+// kline fetches since it was created (implements ports.LatencyStatsProvider).
+func (c *Client) LatencyStats() map[string]ports.OperationLatency {
+	return c.latency.snapshot()
+}
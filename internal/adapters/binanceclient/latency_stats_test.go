@@ -0,0 +1,61 @@
+package binanceclient
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := newLatencyTracker()
+	assert.Empty(t, tracker.snapshot())
+
+	// 1..100ms, the slowest one failing
+	for ms := 1; ms <= 100; ms++ {
+		tracker.record(ports.LatencyPlaceOrder, time.Duration(ms)*time.Millisecond, ms == 100)
+	}
+	tracker.record(ports.LatencyCancelOrder, 40*time.Millisecond, false)
+
+	stats := tracker.snapshot()
+	assert.Equal(t, ports.OperationLatency{Count: 100, Errors: 1, Samples: 100, P50Ms: 50, P95Ms: 95, P99Ms: 99, MaxMs: 100, LastMs: 100}, stats[ports.LatencyPlaceOrder])
+	assert.Equal(t, ports.OperationLatency{Count: 1, Samples: 1, P50Ms: 40, P95Ms: 40, P99Ms: 40, MaxMs: 40, LastMs: 40}, stats[ports.LatencyCancelOrder])
+	assert.NotContains(t, stats, ports.LatencyFetchKlines)
+}
+
+func TestLatencyTrackerRollingWindow(t *testing.T) {
+	tracker := newLatencyTracker()
+	tracker.window = 10
+
+	// A slow start rolls out of the window of the 10 latest requests
+	for i := 0; i < 10; i++ {
+		tracker.record(ports.LatencyFetchKlines, time.Second, false)
+	}
+	for i := 0; i < 10; i++ {
+		tracker.record(ports.LatencyFetchKlines, 20*time.Millisecond, false)
+	}
+	stats := tracker.snapshot()[ports.LatencyFetchKlines]
+	assert.Equal(t, 20, stats.Count)
+	assert.Equal(t, 10, stats.Samples)
+	assert.Equal(t, int64(20), stats.P95Ms)
+	assert.Equal(t, int64(20), stats.MaxMs)
+}
+
+func TestLatencyTrackerObserve(t *testing.T) {
+	tracker := newLatencyTracker()
+	request := func(fail bool) (err error) {
+		defer tracker.observe(ports.LatencyCancelOrder, time.Now(), &err)
+		if fail {
+			return errors.New("unknown order")
+		}
+		return nil
+	}
+	_ = request(false)
+	_ = request(true)
+	stats := tracker.snapshot()[ports.LatencyCancelOrder]
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, 1, stats.Errors)
+}
@@ -0,0 +1,117 @@
+package binanceclient
+
+import (
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// reconnectTracker counts the reconnections, failed connection attempts and downtime of the
+// client's kline streams. Each StreamKlines call registers its own stream.
+type reconnectTracker struct {
+	mu        sync.Mutex
+	now       func() time.Time // Overridable for tests
+	stats     ports.ReconnectStats
+	downtime  time.Duration     // Downtime of outages that have ended
+	nextID    int               // ID of the next registered stream
+	downSince map[int]time.Time // When each disconnected stream went down
+	failures  map[int]int       // Consecutive failed connection attempts by stream
+}
+
+// newReconnectTracker creates an empty tracker.
+func newReconnectTracker() *reconnectTracker {
+	return &reconnectTracker{
+		now:       time.Now,
+		downSince: make(map[int]time.Time),
+		failures:  make(map[int]int),
+	}
+}
+
+// register adds a stream and returns its ID.
+func (t *reconnectTracker) register() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	return t.nextID
+}
+
+// connectFailed records a failed connection attempt of stream id. A stream failing its first
+// attempt counts as disconnected from then on.
+func (t *reconnectTracker) connectFailed(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Failures++
+	t.failures[id]++
+	if _, down := t.downSince[id]; !down {
+		t.downSince[id] = t.now()
+	}
+}
+
+// connected records that stream id is connected, ending its outage. It returns the length of
+// the outage, which is zero for a first connection that succeeded right away.
+func (t *reconnectTracker) connected(id int, reconnect bool) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	delete(t.failures, id)
+	var outage time.Duration
+	if since, down := t.downSince[id]; down {
+		outage = now.Sub(since)
+		t.downtime += outage
+		delete(t.downSince, id)
+	}
+	if reconnect {
+		t.stats.Reconnects++
+		t.stats.LastReconnectAt = now
+	}
+	return outage
+}
+
+// disconnected records that the connection of stream id dropped.
+func (t *reconnectTracker) disconnected(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.stats.LastDisconnectAt = now
+	if _, down := t.downSince[id]; !down {
+		t.downSince[id] = now
+	}
+}
+
+// stopped removes stream id, counting an outage it ends in towards the downtime.
+func (t *reconnectTracker) stopped(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if since, down := t.downSince[id]; down {
+		t.downtime += t.now().Sub(since)
+		delete(t.downSince, id)
+	}
+	delete(t.failures, id)
+}
+
+// snapshot returns the current statistics, with ongoing outages counted up to now.
+func (t *reconnectTracker) snapshot() ports.ReconnectStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	stats := t.stats
+	downtime := t.downtime
+	for _, since := range t.downSince {
+		downtime += now.Sub(since)
+	}
+	stats.DowntimeMs = downtime.Milliseconds()
+	stats.Disconnected = len(t.downSince)
+	for _, failures := range t.failures {
+		if failures > stats.ConsecutiveFailures {
+			stats.ConsecutiveFailures = failures
+		}
+	}
+	return stats
+}
+
+// ReconnectStats returns the reconnection statistics of the client's kline streams since it was
+// created (implements ports.ReconnectStatsProvider).
+func (c *Client) ReconnectStats() ports.ReconnectStats {
+	return c.reconnects.snapshot()
+}
@@ -0,0 +1,51 @@
+package binanceclient
+
+import (
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconnectTracker(t *testing.T) {
+	now := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	tracker := newReconnectTracker()
+	tracker.now = func() time.Time { return now }
+
+	klines1m, klines15m := tracker.register(), tracker.register()
+	assert.Zero(t, tracker.connected(klines1m, false), "a first connection is not an outage")
+	tracker.connected(klines15m, false)
+	assert.Equal(t, ports.ReconnectStats{}, tracker.snapshot())
+
+	// The 1m stream drops and takes two failed attempts and 30s to come back
+	tracker.disconnected(klines1m)
+	now = now.Add(10 * time.Second)
+	tracker.connectFailed(klines1m)
+	tracker.connectFailed(klines1m)
+	stats := tracker.snapshot()
+	assert.Equal(t, 1, stats.Disconnected)
+	assert.Equal(t, 2, stats.ConsecutiveFailures)
+	assert.Equal(t, int64(10000), stats.DowntimeMs, "an ongoing outage counts up to now")
+
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, 30*time.Second, tracker.connected(klines1m, true))
+	stats = tracker.snapshot()
+	assert.Equal(t, ports.ReconnectStats{
+		Reconnects:       1,
+		Failures:         2,
+		DowntimeMs:       30000,
+		LastDisconnectAt: now.Add(-30 * time.Second),
+		LastReconnectAt:  now,
+	}, stats)
+
+	// A stream stopped during an outage keeps its downtime
+	tracker.disconnected(klines15m)
+	now = now.Add(5 * time.Second)
+	tracker.stopped(klines15m)
+	now = now.Add(time.Minute)
+	stats = tracker.snapshot()
+	assert.Equal(t, int64(35000), stats.DowntimeMs)
+	assert.Zero(t, stats.Disconnected)
+}
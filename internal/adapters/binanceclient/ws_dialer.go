@@ -0,0 +1,38 @@
+package binanceclient
+
+import (
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+
+	"github.com/adshao/go-binance/v2/delivery"
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// wsDialer opens kline WebSocket connections for StreamKlines, which handles reconnecting them.
+// The client dials Binance through go-binance; tests inject fakes to simulate failed connections,
+// disconnects and stream errors.
+type wsDialer interface {
+	// DialKlines connects to the kline stream of symbol and interval (on the delivery endpoints if
+	// coinMargined). onKline receives each translated kline event and onError the errors reported
+	// while connected. doneC is closed when the connection ends and a send on stopC closes it.
+	DialKlines(symbol, interval string, coinMargined bool, onKline func(*domain.Kline, error), onError func(error)) (doneC, stopC chan struct{}, err error)
+}
+
+// binanceWsDialer dials Binance's kline streams.
+type binanceWsDialer struct{}
+
+// DialKlines implements wsDialer.
+func (binanceWsDialer) DialKlines(symbol, interval string, coinMargined bool, onKline func(*domain.Kline, error), onError func(error)) (chan struct{}, chan struct{}, error) {
+	if coinMargined {
+		return delivery.WsKlineServe(symbol, interval, func(event *delivery.WsKlineEvent) { onKline(translateDeliveryWsKline(event)) }, onError)
+	}
+	return futures.WsKlineServe(symbol, interval, func(event *futures.WsKlineEvent) { onKline(translateWsKline(event)) }, onError)
+}
+
+// reconnectBackoff returns the wait before reconnect attempt number attempt (from 1): the reconnect
+// delay doubled for each failed attempt before it, plus 10%.
+func (c *Client) reconnectBackoff(attempt int) time.Duration {
+	delay := c.reconnectDelay * time.Duration(1<<uint(attempt-1))
+	return delay + delay/10
+}
@@ -0,0 +1,149 @@
+package binanceclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is a kline stream connection opened by fakeWsDialer.
+type fakeConn struct {
+	coinMargined bool
+	onKline      func(*domain.Kline, error)
+	onError      func(error)
+	done         chan struct{} // Closed by the test to drop the connection
+	stop         chan struct{} // Receives the client's stop signal
+}
+
+// fakeWsDialer fails the dials listed in results (nil succeeds) and then connects every dial.
+type fakeWsDialer struct {
+	mu      sync.Mutex
+	results []error
+	dials   int
+	conns   chan *fakeConn
+}
+
+func (d *fakeWsDialer) DialKlines(symbol, interval string, coinMargined bool, onKline func(*domain.Kline, error), onError func(error)) (chan struct{}, chan struct{}, error) {
+	d.mu.Lock()
+	d.dials++
+	var err error
+	if len(d.results) > 0 {
+		err, d.results = d.results[0], d.results[1:]
+	}
+	d.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := &fakeConn{coinMargined: coinMargined, onKline: onKline, onError: onError, done: make(chan struct{}), stop: make(chan struct{}, 1)}
+	d.conns <- conn
+	return conn.done, conn.stop, nil
+}
+
+// newStreamClient returns a client dialing through dialer whose reconnect backoffs end right away
+// and are sent to the returned channel.
+func newStreamClient(dialer *fakeWsDialer, maxAttempts int) (*Client, chan time.Duration) {
+	backoffs := make(chan time.Duration, 10)
+	return &Client{
+		contractTypes:        map[string]domain.ContractType{"ETHUSD_PERP": domain.ContractTypeCoin},
+		logger:               logger.NewStdLogger(logger.LevelError),
+		reconnectDelay:       time.Second,
+		maxReconnectAttempts: maxAttempts,
+		reconnects:           newReconnectTracker(),
+		latency:              newLatencyTracker(),
+		ws:                   dialer,
+		after: func(d time.Duration) <-chan time.Time {
+			backoffs <- d
+			fired := make(chan time.Time, 1)
+			fired <- time.Time{}
+			return fired
+		},
+	}, backoffs
+}
+
+// receive returns the next value of ch, failing the test if none arrives in time.
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the stream")
+		var zero T
+		return zero
+	}
+}
+
+func TestStreamKlinesReconnects(t *testing.T) {
+	dialer := &fakeWsDialer{conns: make(chan *fakeConn, 10)}
+	client, backoffs := newStreamClient(dialer, 3)
+	klines := make(chan *domain.Kline, 10)
+	errs := make(chan error, 10)
+
+	doneCh, stopCh, err := client.StreamKlines(context.Background(), "ETHUSDT", "1m", func(k *domain.Kline) { klines <- k }, func(err error) { errs <- err })
+	require.NoError(t, err)
+	conn := receive(t, dialer.conns)
+	assert.False(t, conn.coinMargined)
+
+	// Klines are passed on, translation failures only logged
+	conn.onKline(nil, errors.New("bad event"))
+	conn.onKline(&domain.Kline{Symbol: "ETHUSDT", Close: 2000}, nil)
+	assert.Equal(t, 2000.0, receive(t, klines).Close)
+	assert.Empty(t, klines)
+
+	// Stream errors are translated for the handler
+	conn.onError(fmt.Errorf("read: %w", syscall.ECONNRESET))
+	assert.ErrorIs(t, receive(t, errs), ports.ErrConnectionFailed)
+
+	// A dropped connection is redialed right away
+	close(conn.done)
+	conn = receive(t, dialer.conns)
+	assert.Eventually(t, func() bool { return client.ReconnectStats().Reconnects == 1 }, time.Second, time.Millisecond)
+	assert.Empty(t, backoffs)
+
+	// Stopping closes the connection and the done channel
+	close(stopCh)
+	receive(t, doneCh)
+	receive(t, conn.stop)
+}
+
+func TestStreamKlinesBackoff(t *testing.T) {
+	refused := fmt.Errorf("dial: %w", syscall.ECONNREFUSED)
+	dialer := &fakeWsDialer{results: []error{refused, refused, nil}, conns: make(chan *fakeConn, 10)}
+	client, backoffs := newStreamClient(dialer, 3)
+
+	doneCh, _, err := client.StreamKlines(context.Background(), "ETHUSD_PERP", "1m", func(*domain.Kline) {}, func(error) {})
+	require.NoError(t, err)
+	conn := receive(t, dialer.conns)
+	assert.True(t, conn.coinMargined, "COIN-margined symbols stream from the delivery endpoints")
+	assert.Equal(t, 1100*time.Millisecond, receive(t, backoffs))
+	assert.Equal(t, 2200*time.Millisecond, receive(t, backoffs))
+
+	// A successful connection resets the backoff, and the stream gives up after the maximum
+	// consecutive failed attempts
+	dialer.mu.Lock()
+	dialer.results = []error{refused, refused, refused}
+	dialer.mu.Unlock()
+	close(conn.done)
+	receive(t, doneCh)
+	assert.Equal(t, 1100*time.Millisecond, receive(t, backoffs))
+	assert.Equal(t, 2200*time.Millisecond, receive(t, backoffs))
+	assert.Empty(t, backoffs)
+
+	stats := client.ReconnectStats()
+	assert.Equal(t, 5, stats.Failures)
+	assert.Zero(t, stats.Disconnected, "a stream that gave up is no longer down")
+	dialer.mu.Lock()
+	assert.Equal(t, 6, dialer.dials)
+	dialer.mu.Unlock()
+}
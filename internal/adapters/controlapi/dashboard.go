@@ -0,0 +1,121 @@
+package controlapi
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+const (
+	defaultPushInterval = 2 * time.Second
+	dashboardWriteWait  = 5 * time.Second  // Timeout for sending one update
+	dashboardLogLines   = 50               // Most recent log lines sent with each update
+	dashboardPongWait   = 60 * time.Second // Connections without a pong for this long are closed
+)
+
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// upgrader accepts dashboard websocket connections from the page served by this server.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// handleDashboardPage serves the dashboard web UI.
+func (s *Server) handleDashboardPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboardPage)
+}
+
+// handleDashboardSnapshot returns the current dashboard data as JSON.
+func (s *Server) handleDashboardSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.dashboardSnapshot(r.Context())
+	if err != nil {
+		s.logger.Error(r.Context(), err, "Control API: failed to build dashboard snapshot")
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// handleDashboardStream upgrades to a websocket and pushes a snapshot every push interval
+// until the client disconnects or the server shuts down.
+func (s *Server) handleDashboardStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader already replied with an error
+	}
+	defer conn.Close()
+
+	// Read (and discard) client messages to process pongs and notice disconnects
+	disconnected := make(chan struct{})
+	_ = conn.SetReadDeadline(time.Now().Add(dashboardPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(dashboardPongWait))
+	})
+	go func() {
+		defer close(disconnected)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(s.pushInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.pushDashboard(r.Context(), conn); err != nil {
+			s.logger.Debug(r.Context(), "Dashboard connection closed", map[string]interface{}{"remoteAddr": r.RemoteAddr, "error": err.Error()})
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-disconnected:
+			return
+		case <-s.closing:
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(dashboardWriteWait))
+			return
+		}
+	}
+}
+
+// pushDashboard sends one snapshot followed by a ping. Snapshot errors are sent to the page
+// instead of closing the connection, since they are usually transient (e.g., a busy database).
+func (s *Server) pushDashboard(ctx context.Context, conn *websocket.Conn) error {
+	var msg interface{}
+	snapshot, err := s.dashboardSnapshot(ctx)
+	if err != nil {
+		msg = map[string]string{"error": err.Error()}
+	} else {
+		msg = snapshot
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(dashboardWriteWait))
+	if err := conn.WriteJSON(msg); err != nil {
+		return err
+	}
+	return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(dashboardWriteWait))
+}
+
+// dashboardSnapshot combines the provider's snapshot with the recent log lines.
+func (s *Server) dashboardSnapshot(ctx context.Context) (ports.DashboardSnapshot, error) {
+	snapshot, err := s.dashboard.Dashboard(ctx)
+	if err != nil {
+		return snapshot, err
+	}
+	if s.logs != nil {
+		logs := s.logs.Recent()
+		if len(logs) > dashboardLogLines {
+			logs = logs[len(logs)-dashboardLogLines:]
+		}
+		snapshot.Logs = logs
+	}
+	return snapshot, nil
+}
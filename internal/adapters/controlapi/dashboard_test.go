@@ -0,0 +1,121 @@
+package controlapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// mockDashboard implements ports.DashboardProvider for testing
+type mockDashboard struct {
+	snapshot ports.DashboardSnapshot
+	err      error
+}
+
+func (m *mockDashboard) Dashboard(ctx context.Context) (ports.DashboardSnapshot, error) {
+	return m.snapshot, m.err
+}
+
+// mockLogs implements ports.LogHistory for testing
+type mockLogs []ports.LogLine
+
+func (m mockLogs) Recent() []ports.LogLine {
+	return m
+}
+
+func newDashboardServer(t *testing.T, dashboard *mockDashboard, logs ports.LogHistory) *Server {
+	t.Helper()
+	srv, err := New(Config{
+		Addr:         "127.0.0.1:0",
+		Controller:   &mockController{},
+		Logger:       &mockLogger{},
+		Token:        testToken,
+		Dashboard:    dashboard,
+		Logs:         logs,
+		PushInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	return srv
+}
+
+func TestServer_Dashboard(t *testing.T) {
+	var logs mockLogs
+	for i := 0; i < dashboardLogLines+5; i++ {
+		logs = append(logs, ports.LogLine{Level: "INFO", Message: "line"})
+	}
+	dashboard := &mockDashboard{snapshot: ports.DashboardSnapshot{
+		Symbol:    "ETHUSDT",
+		Price:     2000,
+		Positions: []ports.DashboardPosition{{ID: 1, Side: "LONG", EntryPrice: 1900, UnrealizedPnL: 10}},
+		Equity:    []ports.EquityPoint{{Equity: 1000}, {Equity: 1010}},
+	}}
+	srv := newDashboardServer(t, dashboard, logs)
+
+	t.Run("page", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, rec.Body.String(), "/dashboard/ws")
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard/snapshot", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var got ports.DashboardSnapshot
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+		assert.Equal(t, "ETHUSDT", got.Symbol)
+		assert.Len(t, got.Positions, 1)
+		assert.Len(t, got.Logs, dashboardLogLines)
+	})
+
+	t.Run("websocket pushes updates", func(t *testing.T) {
+		ts := httptest.NewServer(srv.routes())
+		defer ts.Close()
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/dashboard/ws", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		for i := 0; i < 2; i++ {
+			var got ports.DashboardSnapshot
+			require.NoError(t, conn.ReadJSON(&got))
+			assert.Equal(t, 2000.0, got.Price)
+			assert.Len(t, got.Equity, 2)
+		}
+
+		// Shutdown closes open dashboard connections
+		require.NoError(t, srv.Shutdown(context.Background()))
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+				break
+			}
+		}
+	})
+
+	t.Run("snapshot error", func(t *testing.T) {
+		srv := newDashboardServer(t, &mockDashboard{err: assert.AnError}, nil)
+		rec := httptest.NewRecorder()
+		srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard/snapshot", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestServer_DashboardDisabled(t *testing.T) {
+	srv := newTestServer(t, &mockController{})
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,124 @@
+package controlapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+const (
+	defaultOrdersLimit = 50
+	maxOrdersLimit     = 500
+)
+
+// orderJSON is an order of the order log as returned by GET /orders.
+type orderJSON struct {
+	ID            int64     `json:"id"`
+	OrderID       int64     `json:"orderId"`
+	ClientOrderID string    `json:"clientOrderId,omitempty"`
+	PositionID    int64     `json:"positionId,omitempty"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`
+	PositionSide  string    `json:"positionSide"`
+	Type          string    `json:"type"`
+	Purpose       string    `json:"purpose"`
+	Quantity      float64   `json:"quantity"`
+	Price         float64   `json:"price,omitempty"`
+	StopPrice     float64   `json:"stopPrice,omitempty"`
+	Status        string    `json:"status"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// ordersPage is the response of GET /orders.
+type ordersPage struct {
+	Orders []orderJSON `json:"orders"`
+	Total  int         `json:"total"` // Orders matching the filter
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
+
+// handleOrders lists the logged orders, newest first. Query parameters: symbol, status,
+// position (ID), from and to (RFC 3339, sent in [from, to)), limit (default 50, at most 500)
+// and offset.
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	orders, total, err := s.orders.FindOrders(r.Context(), filter)
+	if err != nil {
+		s.logger.Error(r.Context(), err, "Control API: failed to list orders")
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to list orders"})
+		return
+	}
+	page := ordersPage{Orders: make([]orderJSON, 0, len(orders)), Total: total, Limit: filter.Limit, Offset: filter.Offset}
+	for _, o := range orders {
+		page.Orders = append(page.Orders, toOrderJSON(o))
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// parseOrderFilter reads the order filter from the query parameters.
+func parseOrderFilter(query url.Values) (ports.OrderFilter, error) {
+	filter := ports.OrderFilter{
+		Symbol: query.Get("symbol"),
+		Status: query.Get("status"),
+		Limit:  defaultOrdersLimit,
+	}
+	var err error
+	if v := query.Get("position"); v != "" {
+		if filter.PositionID, err = strconv.ParseInt(v, 10, 64); err != nil || filter.PositionID <= 0 {
+			return filter, fmt.Errorf("invalid position %q", v)
+		}
+	}
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := query.Get(name); v != "" {
+			if *dst, err = time.Parse(time.RFC3339, v); err != nil {
+				return filter, fmt.Errorf("invalid %s %q: expected RFC 3339", name, v)
+			}
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+	if v := query.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", v)
+		}
+		filter.Limit = min(filter.Limit, maxOrdersLimit)
+	}
+	if v := query.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return filter, fmt.Errorf("invalid offset %q", v)
+		}
+	}
+	return filter, nil
+}
+
+// toOrderJSON converts a logged order for the response.
+func toOrderJSON(o *domain.Order) orderJSON {
+	return orderJSON{
+		ID:            o.ID,
+		OrderID:       o.OrderID,
+		ClientOrderID: o.ClientOrderID,
+		PositionID:    o.PositionID,
+		Symbol:        o.Symbol,
+		Side:          string(o.Side),
+		PositionSide:  string(o.PositionSide),
+		Type:          o.Type,
+		Purpose:       string(o.Purpose),
+		Quantity:      o.Quantity,
+		Price:         o.Price,
+		StopPrice:     o.StopPrice,
+		Status:        o.Status,
+		Error:         o.Error,
+		CreatedAt:     o.CreatedAt,
+	}
+}
@@ -0,0 +1,79 @@
+package controlapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOrderRepo implements ports.OrderRepository for testing
+type mockOrderRepo struct {
+	orders []*domain.Order
+	filter ports.OrderFilter // Filter of the last FindOrders call
+}
+
+func (m *mockOrderRepo) SaveOrder(ctx context.Context, order *domain.Order) error {
+	m.orders = append(m.orders, order)
+	return nil
+}
+
+func (m *mockOrderRepo) AssignOrders(ctx context.Context, positionID int64, orderIDs []int64) error {
+	return nil
+}
+
+func (m *mockOrderRepo) FindOrders(ctx context.Context, filter ports.OrderFilter) ([]*domain.Order, int, error) {
+	m.filter = filter
+	return m.orders, len(m.orders), nil
+}
+
+func TestServer_Orders(t *testing.T) {
+	at := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	repo := &mockOrderRepo{orders: []*domain.Order{
+		{ID: 2, OrderID: 11, PositionID: 7, Symbol: "ETHUSDT", Side: domain.Sell, PositionSide: domain.PositionSideBoth,
+			Type: "STOP_MARKET", Purpose: domain.OrderPurposeStopLoss, StopPrice: 1980, Status: "NEW", CreatedAt: at},
+	}}
+	srv, err := New(Config{Addr: "127.0.0.1:0", Controller: &mockController{}, Logger: &mockLogger{}, Token: testToken, Orders: repo})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+		"/orders?symbol=ETHUSDT&status=NEW&position=7&from=2025-05-01T00:00:00Z&to=2025-05-02T00:00:00Z&limit=1000&offset=20", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var page ordersPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	assert.Equal(t, 1, page.Total)
+	require.Len(t, page.Orders, 1)
+	assert.Equal(t, "STOP_LOSS", page.Orders[0].Purpose)
+	assert.Equal(t, 1980.0, page.Orders[0].StopPrice)
+	assert.Equal(t, ports.OrderFilter{
+		Symbol:     "ETHUSDT",
+		Status:     "NEW",
+		PositionID: 7,
+		From:       at.Add(-9 * time.Hour),
+		To:         at.Add(15 * time.Hour),
+		Limit:      maxOrdersLimit,
+		Offset:     20,
+	}, repo.filter)
+
+	for _, query := range []string{"position=x", "from=yesterday", "limit=0", "offset=-1",
+		"from=2025-05-02T00:00:00Z&to=2025-05-01T00:00:00Z"} {
+		rec := httptest.NewRecorder()
+		srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+
+	t.Run("not served without an order log", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newTestServer(t, &mockController{}).routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
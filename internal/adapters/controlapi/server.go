@@ -0,0 +1,215 @@
+package controlapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// Server exposes operator controls for the trading service over HTTP.
+type Server struct {
+	httpServer *http.Server
+	controller ports.TradingController
+	logger     ports.Logger
+	orders     ports.OrderRepository // Order log (optional)
+	token      string                // Bearer token the control actions require
+
+	// Web dashboard (optional)
+	dashboard    ports.DashboardProvider
+	logs         ports.LogHistory
+	pushInterval time.Duration
+	closing      chan struct{} // Closed on Shutdown to end dashboard connections
+	closeOnce    sync.Once
+}
+
+// Config holds configuration for the control API server.
+type Config struct {
+	Addr       string // Listen address (e.g., "127.0.0.1:8080")
+	Controller ports.TradingController
+	Logger     ports.Logger
+	Orders     ports.OrderRepository // Optional order log, listed at /orders when set
+
+	// Bearer token the control actions (POST endpoints) require in the Authorization header, so
+	// other local processes and web pages can't trade through the API
+	Token string
+
+	// Optional web dashboard, served at /dashboard when Dashboard is set
+	Dashboard    ports.DashboardProvider
+	Logs         ports.LogHistory // Recent log lines shown on the dashboard
+	PushInterval time.Duration    // How often the dashboard is updated (default 2s)
+}
+
+// New creates a new control API server.
+func New(cfg Config) (*Server, error) {
+	if cfg.Logger == nil {
+		return nil, fmt.Errorf("logger is required for control API")
+	}
+	if cfg.Controller == nil {
+		return nil, fmt.Errorf("controller is required for control API")
+	}
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("listen address is required for control API")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("token is required for control API")
+	}
+
+	s := &Server{
+		controller:   cfg.Controller,
+		logger:       cfg.Logger,
+		orders:       cfg.Orders,
+		token:        cfg.Token,
+		dashboard:    cfg.Dashboard,
+		logs:         cfg.Logs,
+		pushInterval: cfg.PushInterval,
+		closing:      make(chan struct{}),
+	}
+	if s.pushInterval <= 0 {
+		s.pushInterval = defaultPushInterval
+	}
+	s.httpServer = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s, nil
+}
+
+// Start begins listening in the background. It returns once the listener is bound.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+	s.logger.Info(context.Background(), "Control API listening", map[string]interface{}{"addr": ln.Addr().String()})
+
+	go func() {
+		if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error(context.Background(), err, "Control API server stopped unexpectedly")
+		}
+	}()
+	return nil
+}
+
+// Shutdown gracefully stops the server, closing open dashboard connections.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.closing) })
+	return s.httpServer.Shutdown(ctx)
+}
+
+// routes registers the API endpoints.
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("POST /killswitch/resume", s.authorized(s.handleResume))
+	mux.HandleFunc("GET /strategy", s.handleGetStrategy)
+	mux.HandleFunc("POST /strategy", s.authorized(s.handleSwitchStrategy))
+	if s.orders != nil {
+		mux.HandleFunc("GET /orders", s.handleOrders)
+	}
+	if s.dashboard != nil {
+		mux.HandleFunc("GET /dashboard", s.handleDashboardPage)
+		mux.HandleFunc("GET /dashboard/snapshot", s.handleDashboardSnapshot)
+		mux.HandleFunc("GET /dashboard/ws", s.handleDashboardStream)
+	}
+	return mux
+}
+
+// authorized wraps a control action so it only runs for JSON requests carrying the bearer token.
+// Requiring a JSON content type keeps browsers from sending the request cross-origin without a
+// CORS preflight, which the API never approves.
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			s.logger.Warn(r.Context(), "Control API: unauthorized request", map[string]interface{}{
+				"path":       r.URL.Path,
+				"remoteAddr": r.RemoteAddr,
+			})
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "a valid bearer token is required"})
+			return
+		}
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			writeJSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": "Content-Type must be application/json"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleStatus returns the current trading status, including kill switch state.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.controller.Status(r.Context()))
+}
+
+// handleResume clears a tripped kill switch.
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	if err := s.controller.ResumeTrading(r.Context()); err != nil {
+		s.logger.Error(r.Context(), err, "Control API: failed to resume trading")
+		status := http.StatusInternalServerError
+		if errors.Is(err, ports.ErrConfigurationError) {
+			status = http.StatusConflict
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	s.logger.Info(r.Context(), "Control API: trading resumed", map[string]interface{}{"remoteAddr": r.RemoteAddr})
+	writeJSON(w, http.StatusOK, s.controller.Status(r.Context()))
+}
+
+// handleGetStrategy returns the active strategy and the strategies it can be switched to.
+func (s *Server) handleGetStrategy(w http.ResponseWriter, r *http.Request) {
+	status := s.controller.Status(r.Context())
+	if status.Strategy == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "strategy switching is not enabled"})
+		return
+	}
+	writeJSON(w, http.StatusOK, status.Strategy)
+}
+
+// handleSwitchStrategy switches the active strategy or updates its parameters.
+func (s *Server) handleSwitchStrategy(w http.ResponseWriter, r *http.Request) {
+	var req ports.StrategySwitchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	if req.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "strategy name is required"})
+		return
+	}
+
+	if err := s.controller.SwitchStrategy(r.Context(), req); err != nil {
+		s.logger.Error(r.Context(), err, "Control API: failed to switch strategy", map[string]interface{}{"strategy": req.Name})
+		status := http.StatusInternalServerError
+		if errors.Is(err, ports.ErrConfigurationError) {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	s.logger.Info(r.Context(), "Control API: strategy switched", map[string]interface{}{
+		"strategy":       req.Name,
+		"closePositions": req.ClosePositions,
+		"remoteAddr":     r.RemoteAddr,
+	})
+	writeJSON(w, http.StatusOK, s.controller.Status(r.Context()))
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
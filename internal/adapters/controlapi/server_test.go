@@ -0,0 +1,277 @@
+package controlapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLogger implements ports.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(ctx context.Context, msg string, fields ...map[string]interface{}) {}
+func (m *mockLogger) Info(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Warn(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Error(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+}
+
+// mockController implements ports.TradingController for testing
+type mockController struct {
+	status    ports.TradingStatus
+	resumeErr error
+	resumed   bool
+	switchErr error
+	switched  *ports.StrategySwitchRequest
+}
+
+func (m *mockController) Status(ctx context.Context) ports.TradingStatus {
+	return m.status
+}
+
+func (m *mockController) ResumeTrading(ctx context.Context) error {
+	if m.resumeErr != nil {
+		return m.resumeErr
+	}
+	m.resumed = true
+	m.status.KillSwitch.Tripped = false
+	return nil
+}
+
+func (m *mockController) PauseTrading(ctx context.Context, reason string) error {
+	m.status.Paused = reason
+	return nil
+}
+
+func (m *mockController) OpenPositions(ctx context.Context) []domain.Position {
+	return nil
+}
+
+func (m *mockController) ForceClose(ctx context.Context, side domain.PositionSide) ([]domain.Position, error) {
+	return nil, ports.ErrNotFound
+}
+
+func (m *mockController) SwitchStrategy(ctx context.Context, req ports.StrategySwitchRequest) error {
+	if m.switchErr != nil {
+		return m.switchErr
+	}
+	m.switched = &req
+	m.status.Strategy.Name = req.Name
+	m.status.Strategy.Params = req.Params
+	return nil
+}
+
+// testToken is the bearer token of the test servers
+const testToken = "test-token"
+
+func newTestServer(t *testing.T, controller *mockController) *Server {
+	t.Helper()
+	srv, err := New(Config{Addr: "127.0.0.1:0", Controller: controller, Logger: &mockLogger{}, Token: testToken})
+	require.NoError(t, err)
+	return srv
+}
+
+// controlRequest returns a JSON request carrying the test token
+func controlRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestServer_Status(t *testing.T) {
+	controller := &mockController{
+		status: ports.TradingStatus{
+			Symbol:     "ETHUSDT",
+			KillSwitch: &ports.KillSwitchStatus{Tripped: true, Reason: "drawdown"},
+		},
+	}
+	srv := newTestServer(t, controller)
+
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got ports.TradingStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "ETHUSDT", got.Symbol)
+	require.NotNil(t, got.KillSwitch)
+	assert.True(t, got.KillSwitch.Tripped)
+	assert.Equal(t, "drawdown", got.KillSwitch.Reason)
+}
+
+func TestServer_Resume(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		resumeErr      error
+		expectedStatus int
+		expectResumed  bool
+	}{
+		{
+			name:           "resume succeeds",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusOK,
+			expectResumed:  true,
+		},
+		{
+			name:           "kill switch not enabled",
+			method:         http.MethodPost,
+			resumeErr:      ports.ErrConfigurationError,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &mockController{
+				status:    ports.TradingStatus{KillSwitch: &ports.KillSwitchStatus{Tripped: true}},
+				resumeErr: tt.resumeErr,
+			}
+			srv := newTestServer(t, controller)
+
+			rec := httptest.NewRecorder()
+			srv.routes().ServeHTTP(rec, controlRequest(tt.method, "/killswitch/resume", ""))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectResumed, controller.resumed)
+		})
+	}
+}
+
+func TestServer_GetStrategy(t *testing.T) {
+	controller := &mockController{
+		status: ports.TradingStatus{Strategy: &ports.StrategyStatus{Name: "ma_crossover", Available: []string{"improved_ma_crossover", "ma_crossover"}}},
+	}
+	srv := newTestServer(t, controller)
+
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/strategy", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got ports.StrategyStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	assert.Equal(t, "ma_crossover", got.Name)
+	assert.Equal(t, []string{"improved_ma_crossover", "ma_crossover"}, got.Available)
+
+	// Switching disabled
+	srv = newTestServer(t, &mockController{})
+	rec = httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/strategy", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_SwitchStrategy(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		switchErr      error
+		expectedStatus int
+		expectSwitched *ports.StrategySwitchRequest
+	}{
+		{
+			name:           "switch succeeds",
+			body:           `{"name":"improved_ma_crossover","params":{"fastMAPeriod":5},"closePositions":true}`,
+			expectedStatus: http.StatusOK,
+			expectSwitched: &ports.StrategySwitchRequest{Name: "improved_ma_crossover", Params: map[string]float64{"fastMAPeriod": 5}, ClosePositions: true},
+		},
+		{
+			name:           "invalid body",
+			body:           `{"name":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing name",
+			body:           `{"params":{"fastMAPeriod":5}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown strategy",
+			body:           `{"name":"nope"}`,
+			switchErr:      ports.ErrConfigurationError,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "closing positions fails",
+			body:           `{"name":"ma_crossover","closePositions":true}`,
+			switchErr:      assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &mockController{
+				status:    ports.TradingStatus{Strategy: &ports.StrategyStatus{Name: "ma_crossover"}},
+				switchErr: tt.switchErr,
+			}
+			srv := newTestServer(t, controller)
+
+			rec := httptest.NewRecorder()
+			srv.routes().ServeHTTP(rec, controlRequest(http.MethodPost, "/strategy", tt.body))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectSwitched, controller.switched)
+			if tt.expectSwitched != nil {
+				var got ports.TradingStatus
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+				assert.Equal(t, tt.expectSwitched.Name, got.Strategy.Name)
+			}
+		})
+	}
+}
+
+func TestServer_ControlAuthorization(t *testing.T) {
+	_, err := New(Config{Addr: "127.0.0.1:0", Controller: &mockController{}, Logger: &mockLogger{}})
+	assert.Error(t, err, "a token is required")
+
+	tests := []struct {
+		name           string
+		authorization  string
+		contentType    string
+		expectedStatus int
+	}{
+		{"no token", "", "application/json", http.StatusUnauthorized},
+		{"wrong token", "Bearer nope", "application/json", http.StatusUnauthorized},
+		{"not a bearer token", testToken, "application/json", http.StatusUnauthorized},
+		{"form post from a web page", "Bearer " + testToken, "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"no content type", "Bearer " + testToken, "", http.StatusUnsupportedMediaType},
+		{"authorized", "Bearer " + testToken, "application/json; charset=utf-8", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &mockController{
+				status: ports.TradingStatus{KillSwitch: &ports.KillSwitchStatus{Tripped: true}, Strategy: &ports.StrategyStatus{Name: "ma_crossover"}},
+			}
+			srv := newTestServer(t, controller)
+			for _, target := range []string{"/killswitch/resume", "/strategy"} {
+				req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(`{"name":"improved_ma_crossover"}`))
+				if tt.authorization != "" {
+					req.Header.Set("Authorization", tt.authorization)
+				}
+				if tt.contentType != "" {
+					req.Header.Set("Content-Type", tt.contentType)
+				}
+				rec := httptest.NewRecorder()
+				srv.routes().ServeHTTP(rec, req)
+				assert.Equal(t, tt.expectedStatus, rec.Code, target)
+			}
+			authorized := tt.expectedStatus == http.StatusOK
+			assert.Equal(t, authorized, controller.resumed)
+			assert.Equal(t, authorized, controller.switched != nil)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// TLSMode selects how the connection to the SMTP server is secured.
+type TLSMode string
+
+const (
+	TLSModeStartTLS TLSMode = "starttls" // Upgrade a plain connection with STARTTLS (usually port 587)
+	TLSModeImplicit TLSMode = "tls"      // Connect over TLS from the start (usually port 465)
+	TLSModeNone     TLSMode = "none"     // No encryption; only for local relays
+)
+
+// defaultTimeout bounds a whole delivery when the context has no earlier deadline.
+const defaultTimeout = 30 * time.Second
+
+// bodyTemplate wraps every notification in the email body.
+var bodyTemplate = template.Must(template.New("body").Parse(`{{.Message}}
+
+--
+Sent by cryptoMegaBot at {{.Time.Format "2006-01-02 15:04:05 MST"}}
+`))
+
+// Notifier sends notifications as plain-text emails over SMTP (implements ports.Notifier).
+type Notifier struct {
+	cfg    Config
+	logger ports.Logger
+}
+
+// Config holds configuration for the email notifier.
+type Config struct {
+	Host          string        // SMTP server host name
+	Port          int           // SMTP server port (defaults to 587, or 465 with TLSModeImplicit)
+	Username      string        // Optional: authenticates with PLAIN auth when set
+	Password      string        // Password for Username
+	TLSMode       TLSMode       // Connection security (defaults to TLSModeStartTLS)
+	From          string        // Sender address
+	To            []string      // Recipient addresses
+	SubjectPrefix string        // Prepended to every subject (defaults to "[cryptoMegaBot]")
+	Timeout       time.Duration // Optional: defaults to 30s
+	Logger        ports.Logger
+}
+
+// New creates a new email notifier.
+func New(cfg Config) (*Notifier, error) {
+	if cfg.Logger == nil {
+		return nil, fmt.Errorf("logger is required for email notifier")
+	}
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required for email notifier")
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("sender address is required for email notifier")
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required for email notifier")
+	}
+	switch cfg.TLSMode {
+	case "":
+		cfg.TLSMode = TLSModeStartTLS
+	case TLSModeStartTLS, TLSModeImplicit, TLSModeNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", cfg.TLSMode)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.TLSMode == TLSModeImplicit {
+			cfg.Port = 465
+		}
+	}
+	if cfg.SubjectPrefix == "" {
+		cfg.SubjectPrefix = "[cryptoMegaBot]"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	return &Notifier{cfg: cfg, logger: cfg.Logger}, nil
+}
+
+// Notify sends the message as an email with the subject line prefixed by SubjectPrefix.
+func (n *Notifier) Notify(ctx context.Context, subject, message string) error {
+	msg, err := n.buildMessage(subject, message, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	client, err := n.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ports.ErrNotificationFailed, err)
+	}
+	defer client.Close()
+
+	if err := n.send(client, msg); err != nil {
+		return fmt.Errorf("%w: %v", ports.ErrNotificationFailed, err)
+	}
+
+	n.logger.Debug(ctx, "Email notification sent", map[string]interface{}{"subject": subject, "recipients": len(n.cfg.To)})
+	return nil
+}
+
+// dial connects to the SMTP server, securing the connection according to the TLS mode.
+// The whole delivery is bounded by the context deadline or the configured timeout.
+func (n *Notifier) dial(ctx context.Context) (*smtp.Client, error) {
+	deadline := time.Now().Add(n.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: n.cfg.Host}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set SMTP connection deadline: %w", err)
+	}
+	if n.cfg.TLSMode == TLSModeImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP handshake with %s failed: %w", addr, err)
+	}
+	if n.cfg.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server %s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS with %s failed: %w", addr, err)
+		}
+	}
+	return client, nil
+}
+
+// send authenticates if configured and delivers msg to every recipient.
+func (n *Notifier) send(client *smtp.Client, msg []byte) error {
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+	if err := client.Mail(n.cfg.From); err != nil {
+		return fmt.Errorf("SMTP server rejected sender %s: %w", n.cfg.From, err)
+	}
+	for _, to := range n.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA command failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected email: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage renders the headers and templated body of an email.
+func (n *Notifier) buildMessage(subject, message string, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	data := struct {
+		Message string
+		Time    time.Time
+	}{Message: message, Time: now.UTC()}
+	if err := bodyTemplate.Execute(&body, data); err != nil {
+		return nil, err
+	}
+
+	fullSubject := strings.TrimSpace(n.cfg.SubjectPrefix + " " + subject)
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", fullSubject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,179 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLogger implements ports.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(ctx context.Context, msg string, fields ...map[string]interface{}) {}
+func (m *mockLogger) Info(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Warn(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Error(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+}
+
+// smtpSession records what a client sent to the fake SMTP server
+type smtpSession struct {
+	auth       string
+	from       string
+	recipients []string
+	data       string
+}
+
+// fakeSMTPServer accepts a single plain-text SMTP session. rejectRcpt makes it refuse recipients
+func fakeSMTPServer(t *testing.T, rejectRcpt bool) (string, <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var s smtpSession
+		defer func() { sessions <- s }()
+
+		tp.PrintfLine("220 localhost ESMTP ready")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO":
+				tp.PrintfLine("250-localhost")
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				s.auth = line
+				tp.PrintfLine("235 Authentication successful")
+			case "MAIL":
+				s.from = line
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				if rejectRcpt {
+					tp.PrintfLine("550 No such user")
+					continue
+				}
+				s.recipients = append(s.recipients, line)
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				s.data = string(data)
+				tp.PrintfLine("250 OK: queued")
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				return
+			default:
+				tp.PrintfLine("250 OK")
+			}
+		}
+	}()
+	return ln.Addr().String(), sessions
+}
+
+func testConfig(t *testing.T, addr string) Config {
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	p, err := net.LookupPort("tcp", port)
+	require.NoError(t, err)
+	return Config{
+		Host:    host,
+		Port:    p,
+		TLSMode: TLSModeNone,
+		From:    "bot@example.com",
+		To:      []string{"ops@example.com", "me@example.com"},
+		Logger:  &mockLogger{},
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	valid := Config{Host: "smtp.example.com", From: "bot@example.com", To: []string{"ops@example.com"}, Logger: &mockLogger{}}
+	n, err := New(valid)
+	require.NoError(t, err)
+	assert.Equal(t, TLSModeStartTLS, n.cfg.TLSMode)
+	assert.Equal(t, 587, n.cfg.Port)
+
+	implicit := valid
+	implicit.TLSMode = TLSModeImplicit
+	n, err = New(implicit)
+	require.NoError(t, err)
+	assert.Equal(t, 465, n.cfg.Port)
+
+	for name, mutate := range map[string]func(c *Config){
+		"no logger":        func(c *Config) { c.Logger = nil },
+		"no host":          func(c *Config) { c.Host = "" },
+		"no sender":        func(c *Config) { c.From = "" },
+		"no recipients":    func(c *Config) { c.To = nil },
+		"unknown TLS mode": func(c *Config) { c.TLSMode = "ssl3" },
+	} {
+		cfg := valid
+		mutate(&cfg)
+		_, err := New(cfg)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	addr, sessions := fakeSMTPServer(t, false)
+	cfg := testConfig(t, addr)
+	cfg.Username, cfg.Password = "bot", "secret"
+	n, err := New(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(context.Background(), "Daily report ETHUSDT", "Trades: 3\nNet PnL: 12.50 USDT"))
+
+	s := <-sessions
+	assert.True(t, strings.HasPrefix(s.auth, "AUTH PLAIN"))
+	assert.Equal(t, "MAIL FROM:<bot@example.com>", s.from)
+	assert.Equal(t, []string{"RCPT TO:<ops@example.com>", "RCPT TO:<me@example.com>"}, s.recipients)
+
+	headers, err := textproto.NewReader(bufio.NewReader(strings.NewReader(s.data))).ReadMIMEHeader()
+	require.NoError(t, err)
+	assert.Equal(t, "[cryptoMegaBot] Daily report ETHUSDT", headers.Get("Subject"))
+	assert.Equal(t, "ops@example.com, me@example.com", headers.Get("To"))
+	assert.Equal(t, "text/plain; charset=utf-8", headers.Get("Content-Type"))
+	assert.Contains(t, s.data, "Trades: 3\nNet PnL: 12.50 USDT\n\n--\nSent by cryptoMegaBot at ")
+}
+
+func TestNotifier_NotifyRejected(t *testing.T) {
+	addr, _ := fakeSMTPServer(t, true)
+	n, err := New(testConfig(t, addr))
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), "subject", "message")
+	require.ErrorIs(t, err, ports.ErrNotificationFailed)
+	assert.Contains(t, err.Error(), "ops@example.com")
+}
+
+func TestNotifier_StartTLSRequired(t *testing.T) {
+	addr, _ := fakeSMTPServer(t, false)
+	cfg := testConfig(t, addr)
+	cfg.TLSMode = TLSModeStartTLS
+	n, err := New(cfg)
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), "subject", "message")
+	require.ErrorIs(t, err, ports.ErrNotificationFailed)
+	assert.Contains(t, err.Error(), "STARTTLS")
+}
@@ -0,0 +1,149 @@
+// Package extstrategy runs trading strategies as external processes, so they can be written in any
+// language and iterated on without recompiling the bot.
+//
+// The bot starts the strategy's command and exchanges newline-delimited JSON messages over its
+// stdin and stdout: one Request per line, answered by exactly one Response line with the same ID,
+// in order. Anything the process writes to stderr is logged. The process should exit when its stdin
+// is closed.
+//
+// The first request is always "describe", which carries the strategy parameters and is answered
+// with the strategy's name, the klines it needs and whether it trades short. Later requests are
+// "should_enter", "should_enter_short" and "should_close", answered with Result (and Reason for
+// closes). A Response with Error set is logged and treated as "no signal".
+package extstrategy
+
+import (
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// Methods of the protocol.
+const (
+	MethodDescribe         = "describe"
+	MethodShouldEnter      = "should_enter"
+	MethodShouldEnterShort = "should_enter_short"
+	MethodShouldClose      = "should_close"
+)
+
+// Request is a message sent to the strategy process.
+type Request struct {
+	ID       int64              `json:"id"`
+	Method   string             `json:"method"`
+	Params   map[string]float64 `json:"params,omitempty"`   // describe: strategy parameters
+	Klines   []Kline            `json:"klines,omitempty"`   // Oldest first, at most RequiredDataPoints
+	Price    float64            `json:"price,omitempty"`    // Current price
+	Position *Position          `json:"position,omitempty"` // should_close: the open position
+}
+
+// Response is the strategy process's answer to a Request.
+type Response struct {
+	ID                 int64  `json:"id"`
+	Error              string `json:"error,omitempty"`
+	Result             bool   `json:"result,omitempty"`             // Whether to enter or close
+	Reason             string `json:"reason,omitempty"`             // should_close: close reason (defaults to "Market")
+	Name               string `json:"name,omitempty"`               // describe: strategy name
+	RequiredDataPoints int    `json:"requiredDataPoints,omitempty"` // describe: klines needed per evaluation
+	Short              bool   `json:"short,omitempty"`              // describe: whether should_enter_short is supported
+}
+
+// Kline is a closed or forming kline. Times are Unix milliseconds.
+type Kline struct {
+	OpenTime  int64   `json:"openTime"`
+	CloseTime int64   `json:"closeTime"`
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+	Final     bool    `json:"final"`
+}
+
+// Position is an open position. Times are Unix milliseconds.
+type Position struct {
+	Symbol     string  `json:"symbol"`
+	Side       string  `json:"side"` // LONG or SHORT
+	EntryPrice float64 `json:"entryPrice"`
+	Quantity   float64 `json:"quantity"`
+	Leverage   int     `json:"leverage"`
+	StopLoss   float64 `json:"stopLoss"`
+	TakeProfit float64 `json:"takeProfit"`
+	EntryTime  int64   `json:"entryTime"`
+}
+
+// toKlines converts the last limit klines (all if limit <= 0) to protocol klines.
+func toKlines(klines []*domain.Kline, limit int) []Kline {
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	out := make([]Kline, 0, len(klines))
+	for _, k := range klines {
+		if k == nil {
+			continue
+		}
+		out = append(out, Kline{
+			OpenTime:  k.OpenTime.UnixMilli(),
+			CloseTime: k.CloseTime.UnixMilli(),
+			Open:      k.Open,
+			High:      k.High,
+			Low:       k.Low,
+			Close:     k.Close,
+			Volume:    k.Volume,
+			Final:     k.IsFinal,
+		})
+	}
+	return out
+}
+
+// fromKlines converts protocol klines back to domain klines.
+func fromKlines(klines []Kline) []*domain.Kline {
+	out := make([]*domain.Kline, len(klines))
+	for i, k := range klines {
+		out[i] = &domain.Kline{
+			OpenTime:  time.UnixMilli(k.OpenTime),
+			CloseTime: time.UnixMilli(k.CloseTime),
+			Open:      k.Open,
+			High:      k.High,
+			Low:       k.Low,
+			Close:     k.Close,
+			Volume:    k.Volume,
+			IsFinal:   k.Final,
+		}
+	}
+	return out
+}
+
+// toPosition converts a domain position to a protocol position.
+func toPosition(pos *domain.Position) *Position {
+	if pos == nil {
+		return nil
+	}
+	return &Position{
+		Symbol:     pos.Symbol,
+		Side:       string(pos.PositionSide()),
+		EntryPrice: pos.EntryPrice,
+		Quantity:   pos.Quantity,
+		Leverage:   pos.Leverage,
+		StopLoss:   pos.StopLoss,
+		TakeProfit: pos.TakeProfit,
+		EntryTime:  pos.EntryTime.UnixMilli(),
+	}
+}
+
+// fromPosition converts a protocol position back to a domain position.
+func fromPosition(pos *Position) *domain.Position {
+	if pos == nil {
+		return nil
+	}
+	return &domain.Position{
+		Symbol:     pos.Symbol,
+		Side:       domain.PositionSide(pos.Side),
+		EntryPrice: pos.EntryPrice,
+		Quantity:   pos.Quantity,
+		Leverage:   pos.Leverage,
+		StopLoss:   pos.StopLoss,
+		TakeProfit: pos.TakeProfit,
+		EntryTime:  time.UnixMilli(pos.EntryTime),
+		Status:     domain.StatusOpen,
+	}
+}
@@ -0,0 +1,89 @@
+package extstrategy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// Serve answers requests read from in with the strategy built by factory until in is closed, so
+// a Go strategy can run as an external strategy process:
+//
+//	func main() {
+//		if err := extstrategy.Serve(os.Stdin, os.Stdout, "my_strategy", newMyStrategy); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// The strategy is built on the describe request, with its parameters.
+func Serve(in io.Reader, out io.Writer, name string, factory ports.StrategyFactory) error {
+	ctx := context.Background()
+	reader := bufio.NewReader(in)
+	encoder := json.NewEncoder(out)
+	var strat ports.Strategy
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var req Request
+			var resp Response
+			if jsonErr := json.Unmarshal(line, &req); jsonErr != nil {
+				resp.Error = fmt.Sprintf("invalid request: %v", jsonErr)
+			} else {
+				resp = answer(ctx, req, name, factory, &strat)
+			}
+			if err := encoder.Encode(resp); err != nil {
+				return fmt.Errorf("failed to write response: %w", err)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
+
+// answer evaluates one request, building *strat on describe.
+func answer(ctx context.Context, req Request, name string, factory ports.StrategyFactory, strat *ports.Strategy) Response {
+	resp := Response{ID: req.ID}
+	if req.Method == MethodDescribe {
+		built, err := factory(req.Params)
+		if err != nil {
+			resp.Error = err.Error()
+			return resp
+		}
+		*strat = built
+		_, short := built.(ports.ShortStrategy)
+		resp.Name = name
+		resp.RequiredDataPoints = built.RequiredDataPoints()
+		resp.Short = short
+		return resp
+	}
+	if *strat == nil {
+		resp.Error = "strategy not described yet"
+		return resp
+	}
+
+	klines := fromKlines(req.Klines)
+	switch req.Method {
+	case MethodShouldEnter:
+		resp.Result = (*strat).ShouldEnterTrade(ctx, klines, req.Price)
+	case MethodShouldEnterShort:
+		if shortStrat, ok := (*strat).(ports.ShortStrategy); ok {
+			resp.Result = shortStrat.ShouldEnterShort(ctx, klines, req.Price)
+		}
+	case MethodShouldClose:
+		var reason domain.CloseReason
+		resp.Result, reason = (*strat).ShouldClosePosition(ctx, fromPosition(req.Position), klines, req.Price)
+		resp.Reason = string(reason)
+	default:
+		resp.Error = fmt.Sprintf("unknown method %q", req.Method)
+	}
+	return resp
+}
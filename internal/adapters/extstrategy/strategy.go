@@ -0,0 +1,298 @@
+package extstrategy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// defaultTimeout bounds each request when the config has none.
+const defaultTimeout = 2 * time.Second
+
+// errExited is returned for requests after the strategy process has exited.
+var errExited = errors.New("strategy process exited")
+
+// Strategy evaluates signals in an external process (implements ports.Strategy and
+// ports.ShortStrategy). Requests are serialized; a request that fails or times out is logged and
+// treated as "no signal", so a broken strategy process neither enters nor closes positions (the
+// exchange-side stop loss and take profit still protect open ones).
+type Strategy struct {
+	cfg    Config
+	cmd    *exec.Cmd
+	stdin  *os.File
+	logger ports.Logger
+
+	responses chan Response
+	exited    chan struct{} // Closed once the process has exited
+	exitErr   error         // Why the process exited; set before exited is closed
+
+	mu        sync.Mutex // Serializes requests
+	nextID    int64
+	closeOnce sync.Once
+
+	// From the describe response
+	name     string
+	required int
+	short    bool
+}
+
+// Config holds configuration for an external strategy.
+type Config struct {
+	Command string             // Executable of the strategy process
+	Args    []string           // Arguments passed to Command
+	Params  map[string]float64 // Sent with the describe request
+	Timeout time.Duration      // Optional: bound of each request, defaults to 2s
+	Logger  ports.Logger
+}
+
+// New starts the strategy process and describes it. The process keeps running until Close.
+func New(cfg Config) (*Strategy, error) {
+	if cfg.Logger == nil {
+		return nil, fmt.Errorf("logger is required for external strategy")
+	}
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("command is required for external strategy")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	// A pipe of our own (rather than StdinPipe) supports write deadlines, so a process that stops
+	// reading can't block a request past its timeout
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create strategy process stdin: %w", err)
+	}
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Stdin = stdinReader
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return nil, fmt.Errorf("failed to create strategy process stdout: %w", err)
+	}
+	// Not StderrPipe, which Wait closes while the last lines may still be unread
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return nil, fmt.Errorf("failed to create strategy process stderr: %w", err)
+	}
+	cmd.Stderr = stderrWriter
+	if err := cmd.Start(); err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		stderrReader.Close()
+		stderrWriter.Close()
+		return nil, fmt.Errorf("failed to start strategy process %s: %w", cfg.Command, err)
+	}
+	// Owned by the child now
+	stdinReader.Close()
+	stderrWriter.Close()
+
+	s := &Strategy{
+		cfg:       cfg,
+		cmd:       cmd,
+		stdin:     stdinWriter,
+		logger:    cfg.Logger,
+		responses: make(chan Response),
+		exited:    make(chan struct{}),
+		name:      cfg.Command,
+	}
+	go s.logStderr(stderrReader)
+	go s.readResponses(bufio.NewReader(stdout))
+
+	resp, err := s.request(context.Background(), Request{Method: MethodDescribe, Params: cfg.Params})
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to describe external strategy %s: %w", cfg.Command, err)
+	}
+	if resp.RequiredDataPoints <= 0 {
+		s.Close()
+		return nil, fmt.Errorf("external strategy %s requires %d data points, must be positive", cfg.Command, resp.RequiredDataPoints)
+	}
+	if resp.Name != "" {
+		s.name = resp.Name
+	}
+	s.required = resp.RequiredDataPoints
+	s.short = resp.Short
+	s.logger.Info(context.Background(), "External strategy started", map[string]interface{}{
+		"strategy":           s.name,
+		"pid":                cmd.Process.Pid,
+		"requiredDataPoints": s.required,
+		"short":              s.short,
+	})
+	return s, nil
+}
+
+// Name returns the name the strategy process described itself with.
+func (s *Strategy) Name() string {
+	return s.name
+}
+
+// RequiredDataPoints returns the number of klines the strategy process asked for. Requests
+// carry at most that many of the latest klines.
+func (s *Strategy) RequiredDataPoints() int {
+	return s.required
+}
+
+// ShouldEnterTrade asks the strategy process whether to enter a long position.
+func (s *Strategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	resp, err := s.request(ctx, Request{Method: MethodShouldEnter, Klines: toKlines(klines, s.required), Price: currentPrice})
+	if err != nil {
+		s.logger.Error(ctx, err, "External strategy failed to evaluate entry", map[string]interface{}{"strategy": s.name})
+		return false
+	}
+	return resp.Result
+}
+
+// ShouldEnterShort asks the strategy process whether to enter a short position, if it trades
+// short at all.
+func (s *Strategy) ShouldEnterShort(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	if !s.short {
+		return false
+	}
+	resp, err := s.request(ctx, Request{Method: MethodShouldEnterShort, Klines: toKlines(klines, s.required), Price: currentPrice})
+	if err != nil {
+		s.logger.Error(ctx, err, "External strategy failed to evaluate short entry", map[string]interface{}{"strategy": s.name})
+		return false
+	}
+	return resp.Result
+}
+
+// ShouldClosePosition asks the strategy process whether to close the position.
+func (s *Strategy) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	resp, err := s.request(ctx, Request{
+		Method:   MethodShouldClose,
+		Klines:   toKlines(klines, s.required),
+		Price:    currentPrice,
+		Position: toPosition(position),
+	})
+	if err != nil {
+		s.logger.Error(ctx, err, "External strategy failed to evaluate close", map[string]interface{}{"strategy": s.name})
+		return false, ""
+	}
+	if !resp.Result {
+		return false, ""
+	}
+	if resp.Reason == "" {
+		return true, domain.CloseReasonMarket
+	}
+	return true, domain.CloseReason(resp.Reason)
+}
+
+// Close closes the process's stdin and waits for it to exit, killing it if it doesn't within
+// the request timeout.
+func (s *Strategy) Close() error {
+	s.closeOnce.Do(func() {
+		s.stdin.Close()
+		select {
+		case <-s.exited:
+		case <-time.After(s.cfg.Timeout):
+			s.cmd.Process.Kill()
+			<-s.exited
+		}
+	})
+	return nil
+}
+
+// request sends req and waits for its response, the timeout, ctx or the process's exit.
+func (s *Strategy) request(ctx context.Context, req Request) (Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.exited:
+		return Response{}, fmt.Errorf("%w: %v", errExited, s.exitErr)
+	default:
+	}
+
+	s.nextID++
+	req.ID = s.nextID
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to encode %s request: %w", req.Method, err)
+	}
+	deadline := time.Now().Add(s.cfg.Timeout)
+	s.stdin.SetWriteDeadline(deadline)
+	if _, err := s.stdin.Write(append(data, '\n')); err != nil {
+		return Response{}, fmt.Errorf("failed to send %s request: %w", req.Method, err)
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		select {
+		case resp := <-s.responses:
+			if resp.ID < req.ID {
+				continue // Late answer to a request that timed out
+			}
+			if resp.ID != req.ID {
+				return Response{}, fmt.Errorf("got response %d to %s request %d", resp.ID, req.Method, req.ID)
+			}
+			if resp.Error != "" {
+				return Response{}, fmt.Errorf("%s: %s", req.Method, resp.Error)
+			}
+			return resp, nil
+		case <-timer.C:
+			return Response{}, fmt.Errorf("%s request timed out after %s", req.Method, s.cfg.Timeout)
+		case <-ctx.Done():
+			return Response{}, ctx.Err()
+		case <-s.exited:
+			return Response{}, fmt.Errorf("%w: %v", errExited, s.exitErr)
+		}
+	}
+}
+
+// readResponses passes the process's response lines to requests until its stdout closes, then
+// waits for it to exit.
+func (s *Strategy) readResponses(stdout *bufio.Reader) {
+	defer close(s.exited)
+	for {
+		line, err := stdout.ReadBytes('\n')
+		if len(line) > 0 {
+			var resp Response
+			if jsonErr := json.Unmarshal(line, &resp); jsonErr != nil {
+				s.logger.Warn(context.Background(), "External strategy wrote an invalid response line", map[string]interface{}{
+					"command": s.cfg.Command,
+					"error":   jsonErr.Error(),
+				})
+			} else {
+				select {
+				case s.responses <- resp:
+				case <-time.After(s.cfg.Timeout):
+					// Nobody is waiting (e.g., an answer without a request); drop it
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	s.exitErr = s.cmd.Wait()
+	if s.exitErr == nil {
+		s.exitErr = errors.New("exit status 0")
+	}
+	s.logger.Warn(context.Background(), "External strategy process exited", map[string]interface{}{
+		"command": s.cfg.Command,
+		"status":  s.exitErr.Error(),
+	})
+}
+
+// logStderr logs each line the process writes to stderr.
+func (s *Strategy) logStderr(stderr *os.File) {
+	defer stderr.Close()
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		s.logger.Info(context.Background(), "External strategy: "+scanner.Text(), map[string]interface{}{"command": s.cfg.Command})
+	}
+}
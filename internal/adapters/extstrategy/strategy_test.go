@@ -0,0 +1,174 @@
+package extstrategy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperEnv makes the test binary serve thresholdStrategy instead of running the tests, so the
+// tests can start it as a strategy process
+const helperEnv = "EXTSTRATEGY_TEST_HELPER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(helperEnv) == "1" {
+		if err := Serve(os.Stdin, os.Stdout, "threshold", newThresholdStrategy); err != nil {
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// thresholdStrategy enters long above its threshold and short below it, and closes at the take
+// profit. A price of 0 crashes it; a negative price makes it hang
+type thresholdStrategy struct {
+	threshold float64
+}
+
+func newThresholdStrategy(params map[string]float64) (ports.Strategy, error) {
+	s := &thresholdStrategy{threshold: 100}
+	if v, ok := params["threshold"]; ok {
+		s.threshold = v
+	}
+	return s, nil
+}
+
+func (s *thresholdStrategy) RequiredDataPoints() int { return 3 }
+
+func (s *thresholdStrategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, price float64) bool {
+	switch {
+	case price == 0:
+		os.Exit(3)
+	case price < 0:
+		time.Sleep(time.Hour)
+	}
+	return len(klines) == 3 && price > s.threshold
+}
+
+func (s *thresholdStrategy) ShouldEnterShort(ctx context.Context, klines []*domain.Kline, price float64) bool {
+	return price < s.threshold
+}
+
+func (s *thresholdStrategy) ShouldClosePosition(ctx context.Context, pos *domain.Position, klines []*domain.Kline, price float64) (bool, domain.CloseReason) {
+	if pos.PositionSide() == domain.PositionSideLong && price >= pos.TakeProfit {
+		return true, domain.CloseReasonTakeProfit
+	}
+	return false, ""
+}
+
+// mockLogger implements ports.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(ctx context.Context, msg string, fields ...map[string]interface{}) {}
+func (m *mockLogger) Info(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Warn(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Error(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+}
+
+func startHelper(t *testing.T, params map[string]float64) *Strategy {
+	t.Helper()
+	t.Setenv(helperEnv, "1")
+	strat, err := New(Config{
+		Command: os.Args[0],
+		Params:  params,
+		Timeout: 500 * time.Millisecond,
+		Logger:  &mockLogger{},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { strat.Close() })
+	return strat
+}
+
+func testKlines(n int) []*domain.Kline {
+	klines := make([]*domain.Kline, n)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range klines {
+		klines[i] = &domain.Kline{
+			OpenTime:  start.Add(time.Duration(i) * time.Minute),
+			CloseTime: start.Add(time.Duration(i+1)*time.Minute - time.Millisecond),
+			Close:     100,
+			IsFinal:   true,
+		}
+	}
+	return klines
+}
+
+func TestStrategy_Signals(t *testing.T) {
+	ctx := context.Background()
+	strat := startHelper(t, map[string]float64{"threshold": 150})
+	klines := testKlines(10)
+
+	assert.Equal(t, "threshold", strat.Name())
+	assert.Equal(t, 3, strat.RequiredDataPoints())
+
+	// Only the required number of klines is sent
+	assert.True(t, strat.ShouldEnterTrade(ctx, klines, 151))
+	assert.False(t, strat.ShouldEnterTrade(ctx, klines, 149))
+	assert.True(t, strat.ShouldEnterShort(ctx, klines, 149))
+
+	pos := &domain.Position{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 150, TakeProfit: 160, Status: domain.StatusOpen}
+	shouldClose, reason := strat.ShouldClosePosition(ctx, pos, klines, 161)
+	assert.True(t, shouldClose)
+	assert.Equal(t, domain.CloseReasonTakeProfit, reason)
+	shouldClose, _ = strat.ShouldClosePosition(ctx, pos, klines, 155)
+	assert.False(t, shouldClose)
+}
+
+func TestStrategy_Failures(t *testing.T) {
+	ctx := context.Background()
+	klines := testKlines(3)
+
+	t.Run("timed out request yields no signal", func(t *testing.T) {
+		strat := startHelper(t, nil)
+		assert.False(t, strat.ShouldEnterTrade(ctx, klines, -1))
+	})
+
+	t.Run("exited process yields no signal", func(t *testing.T) {
+		strat := startHelper(t, nil)
+		assert.False(t, strat.ShouldEnterTrade(ctx, klines, 0))
+		_, err := strat.request(ctx, Request{Method: MethodShouldEnter, Price: 101})
+		assert.ErrorIs(t, err, errExited)
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		_, err := New(Config{Command: "/nonexistent/strategy", Logger: &mockLogger{}})
+		assert.ErrorContains(t, err, "failed to start strategy process")
+	})
+}
+
+func TestServe(t *testing.T) {
+	in := strings.Join([]string{
+		`{"id":1,"method":"should_enter","price":101}`,
+		`{"id":2,"method":"describe","params":{"threshold":50}}`,
+		`{"id":3,"method":"should_enter","price":60,"klines":[{},{},{}]}`,
+		`{"id":4,"method":"bogus"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	require.NoError(t, Serve(strings.NewReader(in), &out, "threshold", newThresholdStrategy))
+
+	var responses []Response
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var resp Response
+		require.NoError(t, decoder.Decode(&resp))
+		responses = append(responses, resp)
+	}
+	require.Len(t, responses, 5)
+	assert.Equal(t, "strategy not described yet", responses[0].Error)
+	assert.Equal(t, Response{ID: 2, Name: "threshold", RequiredDataPoints: 3, Short: true}, responses[1])
+	assert.Equal(t, Response{ID: 3, Result: true}, responses[2])
+	assert.Contains(t, responses[3].Error, "unknown method")
+	assert.Contains(t, responses[4].Error, "invalid request")
+}
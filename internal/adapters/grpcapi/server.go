@@ -0,0 +1,380 @@
+// Package grpcapi serves the TradingControl gRPC service (pkg/controlpb), giving external risk
+// systems and UIs the operator controls, the trade history and a stream of trading events.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/pkg/controlpb"
+)
+
+// Trade history limits of ListTrades.
+const (
+	defaultTradesLimit = 50
+	maxTradesLimit     = 500
+)
+
+// eventBuffer is the number of events queued per TradeEvents stream. Events published while a
+// slow client's queue is full are dropped, as the event bus must not block trading.
+const eventBuffer = 256
+
+// Server serves the TradingControl gRPC service (implements controlpb.TradingControlServer).
+type Server struct {
+	controlpb.UnimplementedTradingControlServer
+
+	grpcServer *grpc.Server
+	addr       string
+	controller ports.TradingController
+	trades     ports.TradeRepository
+	events     ports.EventBus
+	symbol     string
+	logger     ports.Logger
+	token      string        // Bearer token every call requires
+	closing    chan struct{} // Closed on Shutdown to end event streams
+	closeOnce  sync.Once
+}
+
+// Config holds configuration for the gRPC API server.
+type Config struct {
+	Addr       string // Listen address (e.g., "127.0.0.1:9090")
+	Controller ports.TradingController
+	Trades     ports.TradeRepository // Closed positions listed by ListTrades
+	Events     ports.EventBus        // Events streamed by TradeEvents
+	Symbol     string                // Symbol whose trades are listed
+	Logger     ports.Logger
+
+	// Bearer token every call requires in its "authorization" metadata, shared with the HTTP
+	// control API, so other local processes can't trade through the API
+	Token string
+}
+
+// New creates a new gRPC API server.
+func New(cfg Config) (*Server, error) {
+	if cfg.Logger == nil {
+		return nil, fmt.Errorf("logger is required for gRPC API")
+	}
+	if cfg.Controller == nil {
+		return nil, fmt.Errorf("controller is required for gRPC API")
+	}
+	if cfg.Trades == nil {
+		return nil, fmt.Errorf("trade repository is required for gRPC API")
+	}
+	if cfg.Events == nil {
+		return nil, fmt.Errorf("event bus is required for gRPC API")
+	}
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("listen address is required for gRPC API")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("token is required for gRPC API")
+	}
+
+	s := &Server{
+		addr:       cfg.Addr,
+		controller: cfg.Controller,
+		trades:     cfg.Trades,
+		events:     cfg.Events,
+		symbol:     cfg.Symbol,
+		logger:     cfg.Logger,
+		token:      cfg.Token,
+		closing:    make(chan struct{}),
+	}
+	s.grpcServer = grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := s.authorize(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorize(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	controlpb.RegisterTradingControlServer(s.grpcServer, s)
+	return s, nil
+}
+
+// authorize checks the call's bearer token.
+func (s *Server) authorize(ctx context.Context, method string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	s.logger.Warn(ctx, "gRPC API: unauthenticated call", map[string]interface{}{"method": method})
+	return status.Error(codes.Unauthenticated, "a valid bearer token is required")
+}
+
+// Start begins serving in the background. It returns once the listener is bound.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	s.addr = ln.Addr().String()
+	s.logger.Info(context.Background(), "gRPC API listening", map[string]interface{}{"addr": s.addr})
+
+	go func() {
+		if err := s.grpcServer.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error(context.Background(), err, "gRPC API server stopped unexpectedly")
+		}
+	}()
+	return nil
+}
+
+// Addr returns the listen address, with the actual port once started.
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// Shutdown stops the server gracefully, ending event streams. Calls still running when ctx is
+// done are canceled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.closing) })
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// GetStatus returns a snapshot of the trading state.
+func (s *Server) GetStatus(ctx context.Context, req *controlpb.GetStatusRequest) (*controlpb.Status, error) {
+	return toStatus(s.controller.Status(ctx)), nil
+}
+
+// GetOpenPosition returns the open positions.
+func (s *Server) GetOpenPosition(ctx context.Context, req *controlpb.GetOpenPositionRequest) (*controlpb.GetOpenPositionResponse, error) {
+	resp := &controlpb.GetOpenPositionResponse{}
+	for _, pos := range s.controller.OpenPositions(ctx) {
+		resp.Positions = append(resp.Positions, toPosition(&pos))
+	}
+	return resp, nil
+}
+
+// ListTrades returns the most recent closed positions, or those exited in [from, to).
+func (s *Server) ListTrades(ctx context.Context, req *controlpb.ListTradesRequest) (*controlpb.ListTradesResponse, error) {
+	var positions []*domain.Position
+	var err error
+	if req.From != nil || req.To != nil {
+		if req.From == nil || req.To == nil || !req.From.AsTime().Before(req.To.AsTime()) {
+			return nil, status.Error(codes.InvalidArgument, "from and to must both be set, from before to")
+		}
+		positions, err = s.trades.FindClosedBetween(ctx, s.symbol, req.From.AsTime(), req.To.AsTime())
+		// Most recent first, like FindClosedBySymbol
+		for i, j := 0, len(positions)-1; i < j; i, j = i+1, j-1 {
+			positions[i], positions[j] = positions[j], positions[i]
+		}
+	} else {
+		limit := int(req.Limit)
+		if limit <= 0 {
+			limit = defaultTradesLimit
+		}
+		positions, err = s.trades.FindClosedBySymbol(ctx, s.symbol, min(limit, maxTradesLimit))
+	}
+	if err != nil {
+		s.logger.Error(ctx, err, "gRPC API: failed to list trades")
+		return nil, status.Errorf(codes.Internal, "failed to list trades: %v", err)
+	}
+
+	resp := &controlpb.ListTradesResponse{}
+	for _, pos := range positions {
+		resp.Trades = append(resp.Trades, toPosition(pos))
+	}
+	return resp, nil
+}
+
+// PauseTrading stops new entries until ResumeTrading.
+func (s *Server) PauseTrading(ctx context.Context, req *controlpb.PauseTradingRequest) (*controlpb.Status, error) {
+	if err := s.controller.PauseTrading(ctx, req.Reason); err != nil {
+		s.logger.Error(ctx, err, "gRPC API: failed to pause trading")
+		return nil, toStatusError(err)
+	}
+	s.logger.Info(ctx, "gRPC API: trading paused", map[string]interface{}{"reason": req.Reason})
+	return toStatus(s.controller.Status(ctx)), nil
+}
+
+// ResumeTrading lifts an operator pause and clears a tripped kill switch.
+func (s *Server) ResumeTrading(ctx context.Context, req *controlpb.ResumeTradingRequest) (*controlpb.Status, error) {
+	if err := s.controller.ResumeTrading(ctx); err != nil {
+		s.logger.Error(ctx, err, "gRPC API: failed to resume trading")
+		return nil, toStatusError(err)
+	}
+	s.logger.Info(ctx, "gRPC API: trading resumed")
+	return toStatus(s.controller.Status(ctx)), nil
+}
+
+// ForceClose closes the open positions on the requested side, or all of them, at market.
+func (s *Server) ForceClose(ctx context.Context, req *controlpb.ForceCloseRequest) (*controlpb.ForceCloseResponse, error) {
+	side := domain.PositionSide(req.Side)
+	if side != "" && side != domain.PositionSideLong && side != domain.PositionSideShort {
+		return nil, status.Errorf(codes.InvalidArgument, "side must be LONG, SHORT or empty, got %q", req.Side)
+	}
+	closed, err := s.controller.ForceClose(ctx, side)
+	if err != nil {
+		// Positions closed before the failure are reported by the service's own logs and events
+		s.logger.Error(ctx, err, "gRPC API: failed to force close", map[string]interface{}{
+			"side":   req.Side,
+			"closed": len(closed),
+		})
+		return nil, toStatusError(err)
+	}
+	s.logger.Info(ctx, "gRPC API: positions force closed", map[string]interface{}{"side": req.Side, "closed": len(closed)})
+
+	resp := &controlpb.ForceCloseResponse{}
+	for _, pos := range closed {
+		resp.Closed = append(resp.Closed, toPosition(&pos))
+	}
+	return resp, nil
+}
+
+// TradeEvents streams trading events until the client cancels or the server shuts down. Klines
+// are only streamed when requested explicitly.
+func (s *Server) TradeEvents(req *controlpb.TradeEventsRequest, stream controlpb.TradingControl_TradeEventsServer) error {
+	ctx := stream.Context()
+	types := make([]ports.EventType, len(req.Types))
+	for i, t := range req.Types {
+		types[i] = ports.EventType(t)
+	}
+	allButKlines := len(types) == 0
+
+	queue := make(chan ports.Event, eventBuffer)
+	unsubscribe := s.events.Subscribe(func(ctx context.Context, event ports.Event) {
+		if allButKlines && event.Type == ports.EventKlineReceived {
+			return
+		}
+		select {
+		case queue <- event:
+		default:
+			s.logger.Warn(ctx, "gRPC API: event stream client too slow, event dropped", map[string]interface{}{"event": event.Type})
+		}
+	}, types...)
+	defer unsubscribe()
+	s.logger.Info(ctx, "gRPC API: trade event stream opened", map[string]interface{}{"types": req.Types})
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info(ctx, "gRPC API: trade event stream closed")
+			return nil
+		case <-s.closing:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case event := <-queue:
+			if err := stream.Send(toEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatusError maps service errors to gRPC status codes.
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, ports.ErrConfigurationError):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ports.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// toStatus converts a trading status to its message.
+func toStatus(st ports.TradingStatus) *controlpb.Status {
+	msg := &controlpb.Status{
+		Symbol:          st.Symbol,
+		HasOpenPosition: st.HasOpenPosition,
+		TradesToday:     int32(st.TradesToday),
+		MaxOrders:       int32(st.MaxOrders),
+		Paused:          st.Paused,
+		Blackout:        st.Blackout,
+		Timestamp:       toTimestamp(st.Timestamp),
+	}
+	if st.KillSwitch != nil {
+		msg.KillSwitchTripped = st.KillSwitch.Tripped
+		msg.KillSwitchReason = st.KillSwitch.Reason
+	}
+	if st.Strategy != nil {
+		msg.Strategy = st.Strategy.Name
+	}
+	return msg
+}
+
+// toPosition converts a position to its message.
+func toPosition(pos *domain.Position) *controlpb.Position {
+	return &controlpb.Position{
+		Id:          pos.ID,
+		Symbol:      pos.Symbol,
+		Side:        string(pos.PositionSide()),
+		EntryPrice:  pos.EntryPrice,
+		ExitPrice:   pos.ExitPrice,
+		Quantity:    pos.Quantity,
+		Leverage:    int32(pos.Leverage),
+		StopLoss:    pos.StopLoss,
+		TakeProfit:  pos.TakeProfit,
+		EntryTime:   toTimestamp(pos.EntryTime),
+		ExitTime:    toTimestamp(pos.ExitTime),
+		Status:      string(pos.Status),
+		Pnl:         pos.PNL,
+		CloseReason: string(pos.CloseReason),
+		Fees:        pos.Fees,
+		Funding:     pos.Funding,
+	}
+}
+
+// toEvent converts a trading event to its message.
+func toEvent(event ports.Event) *controlpb.TradeEvent {
+	msg := &controlpb.TradeEvent{
+		Type:     string(event.Type),
+		Symbol:   event.Symbol,
+		Time:     toTimestamp(event.Time),
+		Side:     string(event.Side),
+		Exit:     event.Exit,
+		OrderId:  event.OrderID,
+		Quantity: event.Quantity,
+		Price:    event.Price,
+		Reason:   event.Reason,
+	}
+	if event.Position != nil {
+		msg.Position = toPosition(event.Position)
+	}
+	if event.Kline != nil && msg.Price == 0 {
+		msg.Price = event.Kline.Close
+	}
+	return msg
+}
+
+// toTimestamp converts t, leaving zero times unset.
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,347 @@
+package grpcapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/pkg/controlpb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLogger implements ports.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(ctx context.Context, msg string, fields ...map[string]interface{}) {}
+func (m *mockLogger) Info(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Warn(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Error(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+}
+
+// mockController implements ports.TradingController for testing
+type mockController struct {
+	mu        sync.Mutex
+	status    ports.TradingStatus
+	positions []domain.Position
+	resumeErr error
+}
+
+func (m *mockController) Status(ctx context.Context) ports.TradingStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+func (m *mockController) ResumeTrading(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.resumeErr != nil {
+		return m.resumeErr
+	}
+	m.status.Paused = ""
+	return nil
+}
+
+func (m *mockController) PauseTrading(ctx context.Context, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.Paused = reason
+	return nil
+}
+
+func (m *mockController) OpenPositions(ctx context.Context) []domain.Position {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Position(nil), m.positions...)
+}
+
+func (m *mockController) ForceClose(ctx context.Context, side domain.PositionSide) ([]domain.Position, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var closed, kept []domain.Position
+	for _, pos := range m.positions {
+		if side != "" && pos.PositionSide() != side {
+			kept = append(kept, pos)
+			continue
+		}
+		pos.Status = domain.StatusClosed
+		pos.CloseReason = domain.CloseReasonManual
+		closed = append(closed, pos)
+	}
+	m.positions = kept
+	if len(closed) == 0 {
+		return nil, ports.ErrNotFound
+	}
+	return closed, nil
+}
+
+func (m *mockController) SwitchStrategy(ctx context.Context, req ports.StrategySwitchRequest) error {
+	return nil
+}
+
+// mockTradeRepo implements ports.TradeRepository for testing, trades in exit order
+type mockTradeRepo struct {
+	trades []*domain.Position
+}
+
+func (m *mockTradeRepo) FindClosedBySymbol(ctx context.Context, symbol string, limit int) ([]*domain.Position, error) {
+	var out []*domain.Position
+	for i := len(m.trades) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, m.trades[i])
+	}
+	return out, nil
+}
+
+func (m *mockTradeRepo) CountTodayBySymbol(ctx context.Context, symbol string) (int, error) {
+	return len(m.trades), nil
+}
+
+func (m *mockTradeRepo) FindClosedBetween(ctx context.Context, symbol string, from, to time.Time) ([]*domain.Position, error) {
+	var out []*domain.Position
+	for _, pos := range m.trades {
+		if !pos.ExitTime.Before(from) && pos.ExitTime.Before(to) {
+			out = append(out, pos)
+		}
+	}
+	return out, nil
+}
+
+// mockEventBus implements ports.EventBus for testing
+type mockEventBus struct {
+	mu       sync.Mutex
+	handlers map[int]ports.EventHandler
+	next     int
+}
+
+func (b *mockEventBus) Publish(ctx context.Context, event ports.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, handler := range b.handlers {
+		handler(ctx, event)
+	}
+}
+
+func (b *mockEventBus) Subscribe(handler ports.EventHandler, types ...ports.EventType) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.handlers == nil {
+		b.handlers = make(map[int]ports.EventHandler)
+	}
+	id := b.next
+	b.next++
+	b.handlers[id] = handler
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.handlers, id)
+	}
+}
+
+func (b *mockEventBus) subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.handlers)
+}
+
+func newTestClient(t *testing.T, controller *mockController, trades *mockTradeRepo, bus *mockEventBus) controlpb.TradingControlClient {
+	t.Helper()
+	srv, err := New(Config{
+		Addr:       "127.0.0.1:0",
+		Controller: controller,
+		Trades:     trades,
+		Events:     bus,
+		Symbol:     "ETHUSDT",
+		Logger:     &mockLogger{},
+		Token:      testToken,
+	})
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+	return dial(t, srv.Addr(), testToken)
+}
+
+// testToken is the bearer token of the test servers
+const testToken = "test-token"
+
+// bearerToken sends a bearer token with every call over an insecure test connection
+type bearerToken string
+
+func (b bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(b)}, nil
+}
+
+func (b bearerToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// dial connects a client sending token (none if empty)
+func dial(t *testing.T, addr, token string) controlpb.TradingControlClient {
+	t.Helper()
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken(token)))
+	}
+	conn, err := grpc.NewClient(addr, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return controlpb.NewTradingControlClient(conn)
+}
+
+func TestServer_Authentication(t *testing.T) {
+	ctx := context.Background()
+	_, err := New(Config{Addr: "127.0.0.1:0", Controller: &mockController{}, Trades: &mockTradeRepo{}, Events: &mockEventBus{}, Logger: &mockLogger{}})
+	assert.Error(t, err, "a token is required")
+
+	controller := &mockController{}
+	srv, err := New(Config{Addr: "127.0.0.1:0", Controller: controller, Trades: &mockTradeRepo{}, Events: &mockEventBus{},
+		Symbol: "ETHUSDT", Logger: &mockLogger{}, Token: testToken})
+	require.NoError(t, err)
+	require.NoError(t, srv.Start())
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+
+	for _, token := range []string{"", "wrong"} {
+		client := dial(t, srv.Addr(), token)
+		_, err := client.PauseTrading(ctx, &controlpb.PauseTradingRequest{Reason: "test"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "token %q", token)
+		stream, err := client.TradeEvents(ctx, &controlpb.TradeEventsRequest{})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "token %q", token)
+	}
+	assert.Empty(t, controller.status.Paused)
+
+	_, err = dial(t, srv.Addr(), testToken).PauseTrading(ctx, &controlpb.PauseTradingRequest{Reason: "test"})
+	require.NoError(t, err)
+}
+
+func TestServer_Controls(t *testing.T) {
+	ctx := context.Background()
+	entry := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	controller := &mockController{
+		status: ports.TradingStatus{Symbol: "ETHUSDT", HasOpenPosition: true, MaxOrders: 5, Timestamp: entry},
+		positions: []domain.Position{
+			{ID: 1, Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000, Quantity: 1, EntryTime: entry, Status: domain.StatusOpen},
+			{ID: 2, Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2010, Quantity: 1, EntryTime: entry, Status: domain.StatusOpen},
+		},
+	}
+	client := newTestClient(t, controller, &mockTradeRepo{}, &mockEventBus{})
+
+	st, err := client.GetStatus(ctx, &controlpb.GetStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "ETHUSDT", st.Symbol)
+	assert.Equal(t, int32(5), st.MaxOrders)
+	assert.Equal(t, entry, st.Timestamp.AsTime())
+
+	open, err := client.GetOpenPosition(ctx, &controlpb.GetOpenPositionRequest{})
+	require.NoError(t, err)
+	require.Len(t, open.Positions, 2)
+	assert.Equal(t, "SHORT", open.Positions[1].Side)
+	assert.Nil(t, open.Positions[0].ExitTime)
+
+	st, err = client.PauseTrading(ctx, &controlpb.PauseTradingRequest{Reason: "risk review"})
+	require.NoError(t, err)
+	assert.Equal(t, "risk review", st.Paused)
+	st, err = client.ResumeTrading(ctx, &controlpb.ResumeTradingRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, st.Paused)
+
+	t.Run("force close one side", func(t *testing.T) {
+		resp, err := client.ForceClose(ctx, &controlpb.ForceCloseRequest{Side: "SHORT"})
+		require.NoError(t, err)
+		require.Len(t, resp.Closed, 1)
+		assert.Equal(t, int64(2), resp.Closed[0].Id)
+		assert.Equal(t, "MANUAL", resp.Closed[0].CloseReason)
+
+		_, err = client.ForceClose(ctx, &controlpb.ForceCloseRequest{Side: "SHORT"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("invalid side", func(t *testing.T) {
+		_, err := client.ForceClose(ctx, &controlpb.ForceCloseRequest{Side: "BOTH"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("resume without anything to resume", func(t *testing.T) {
+		controller.mu.Lock()
+		controller.resumeErr = ports.ErrConfigurationError
+		controller.mu.Unlock()
+		_, err := client.ResumeTrading(ctx, &controlpb.ResumeTradingRequest{})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+}
+
+func TestServer_ListTrades(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	trades := &mockTradeRepo{}
+	for i := 0; i < 5; i++ {
+		trades.trades = append(trades.trades, &domain.Position{
+			ID:       int64(i + 1),
+			Symbol:   "ETHUSDT",
+			Status:   domain.StatusClosed,
+			PNL:      float64(i),
+			ExitTime: start.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	client := newTestClient(t, &mockController{}, trades, &mockEventBus{})
+
+	resp, err := client.ListTrades(ctx, &controlpb.ListTradesRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Trades, 2)
+	assert.Equal(t, int64(5), resp.Trades[0].Id)
+
+	resp, err = client.ListTrades(ctx, &controlpb.ListTradesRequest{
+		From: timestamppb.New(start.Add(time.Hour)),
+		To:   timestamppb.New(start.Add(3 * time.Hour)),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Trades, 2)
+	assert.Equal(t, int64(3), resp.Trades[0].Id, "most recent first")
+	assert.Equal(t, int64(2), resp.Trades[1].Id)
+
+	_, err = client.ListTrades(ctx, &controlpb.ListTradesRequest{From: timestamppb.New(start)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_TradeEvents(t *testing.T) {
+	bus := &mockEventBus{}
+	client := newTestClient(t, &mockController{}, &mockTradeRepo{}, bus)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.TradeEvents(ctx, &controlpb.TradeEventsRequest{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return bus.subscribers() == 1 }, time.Second, 10*time.Millisecond)
+
+	now := time.Now().UTC()
+	bus.Publish(context.Background(), ports.Event{Type: ports.EventKlineReceived, Symbol: "ETHUSDT", Time: now, Kline: &domain.Kline{Close: 2000}})
+	bus.Publish(context.Background(), ports.Event{
+		Type:     ports.EventPositionClosed,
+		Symbol:   "ETHUSDT",
+		Time:     now,
+		Side:     domain.PositionSideLong,
+		Position: &domain.Position{ID: 7, Side: domain.PositionSideLong, PNL: 12.5, Status: domain.StatusClosed},
+	})
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "POSITION_CLOSED", event.Type, "klines are only streamed on request")
+	assert.Equal(t, "LONG", event.Side)
+	require.NotNil(t, event.Position)
+	assert.Equal(t, 12.5, event.Position.Pnl)
+
+	cancel()
+	require.Eventually(t, func() bool { return bus.subscribers() == 0 }, time.Second, 10*time.Millisecond)
+}
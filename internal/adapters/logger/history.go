@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// History keeps the most recent log lines in memory, e.g. for the web dashboard.
+// It implements ports.LogHistory and is safe for concurrent use.
+type History struct {
+	mu    sync.Mutex
+	lines []ports.LogLine // Ring buffer
+	next  int             // Index the next line is written to
+	full  bool            // Whether the buffer has wrapped around
+}
+
+// NewHistory creates a log history keeping the last size lines.
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = 1
+	}
+	return &History{lines: make([]ports.LogLine, size)}
+}
+
+// Add stores a log line, dropping the oldest one when the history is full.
+func (h *History) Add(line ports.LogLine) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lines[h.next] = line
+	h.next = (h.next + 1) % len(h.lines)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Recent returns the kept log lines, oldest first.
+func (h *History) Recent() []ports.LogLine {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]ports.LogLine(nil), h.lines[:h.next]...)
+	}
+	recent := make([]ports.LogLine, 0, len(h.lines))
+	recent = append(recent, h.lines[h.next:]...)
+	return append(recent, h.lines[:h.next]...)
+}
+
+// SetHistory also records every logged line (at or above the logger's level) in h.
+// Call it before the logger is shared between goroutines.
+func (l *StdLogger) SetHistory(h *History) {
+	l.history = h
+}
+
+// record adds a formatted line to the history, if one is set.
+func (l *StdLogger) record(level LogLevel, line string) {
+	if l.history != nil {
+		l.history.Add(ports.LogLine{Time: time.Now(), Level: level.String(), Message: line})
+	}
+}
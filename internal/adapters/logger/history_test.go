@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	l := NewStdLogger(LevelInfo)
+	h := NewHistory(3)
+	l.SetHistory(h)
+	assert.Empty(t, h.Recent())
+
+	l.Debug(context.Background(), "below level")
+	l.Info(context.Background(), "first")
+	l.Error(context.Background(), errors.New("boom"), "second")
+	recent := h.Recent()
+	require.Len(t, recent, 2)
+	assert.Equal(t, "INFO", recent[0].Level)
+	assert.Equal(t, "first", recent[0].Message)
+	assert.Equal(t, "ERROR", recent[1].Level)
+	assert.Equal(t, "second | error: boom", recent[1].Message)
+
+	// The oldest lines are dropped once the history is full
+	l.Warn(context.Background(), "third")
+	l.Warn(context.Background(), "fourth")
+	recent = h.Recent()
+	require.Len(t, recent, 3)
+	assert.Equal(t, "second | error: boom", recent[0].Message)
+	assert.Equal(t, "fourth", recent[2].Message)
+}
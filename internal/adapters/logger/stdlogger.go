@@ -10,8 +10,9 @@ import (
 
 // StdLogger implements the ports.Logger interface using the standard log package.
 type StdLogger struct {
-	logger *log.Logger
-	level  LogLevel
+	logger  *log.Logger
+	level   LogLevel
+	history *History // Optional: keeps the most recent lines
 }
 
 // LogLevel defines the logging level.
@@ -71,7 +72,7 @@ func (l *StdLogger) log(ctx context.Context, level LogLevel, msg string, err err
 	}
 
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("[%s] %s", level.String(), msg))
+	sb.WriteString(msg)
 
 	if err != nil {
 		sb.WriteString(fmt.Sprintf(" | error: %v", err))
@@ -85,7 +86,9 @@ func (l *StdLogger) log(ctx context.Context, level LogLevel, msg string, err err
 		}
 	}
 
-	l.logger.Println(sb.String())
+	line := sb.String()
+	l.logger.Printf("[%s] %s", level.String(), line)
+	l.record(level, line)
 }
 
 // Debug logs a message at Debug level.
@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// --- DatabaseMaintainer Implementation ---
+
+// Maintain runs the database upkeep selected by opts: old cached klines and logged orders are
+// pruned, the file is vacuumed, the write-ahead log is checkpointed and truncated (always) and
+// the integrity is checked. It then reports the database and WAL sizes and each table's row count.
+// VACUUM and the checkpoint hold the only connection, so other queries wait until they finish.
+func (r *Repository) Maintain(ctx context.Context, opts ports.MaintenanceOptions) (*ports.MaintenanceReport, error) {
+	start := time.Now()
+	report := &ports.MaintenanceReport{}
+
+	if !opts.PruneBefore.IsZero() {
+		var err error
+		if report.PrunedKlines, err = r.prune(ctx, "kline_cache", "open_time", opts.PruneBefore); err != nil {
+			return nil, err
+		}
+		if report.PrunedOrders, err = r.prune(ctx, "orders", "created_at", opts.PruneBefore); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Vacuum {
+		if _, err := r.db.ExecContext(ctx, "VACUUM"); err != nil {
+			return nil, fmt.Errorf("failed to vacuum database: %w", err)
+		}
+		report.Vacuumed = true
+	}
+
+	var busy, logPages int
+	if err := r.db.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &logPages, &report.CheckpointedPages); err != nil {
+		return nil, fmt.Errorf("failed to checkpoint write-ahead log: %w", err)
+	}
+	report.WALBusy = busy != 0
+
+	if opts.IntegrityCheck {
+		problems, err := r.integrityCheck(ctx)
+		if err != nil {
+			return nil, err
+		}
+		report.IntegrityChecked = true
+		report.IntegrityErrors = problems
+	}
+
+	if err := r.databaseSize(ctx, report); err != nil {
+		return nil, err
+	}
+	rows, err := r.tableRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.TableRows = rows
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// prune deletes the rows of table whose timestamp column is before cutoff. julianday normalizes
+// timestamps stored with different zone offsets before comparing.
+func (r *Repository) prune(ctx context.Context, table, column string, cutoff time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE julianday(%s) < julianday(?)", table, column)
+	res, err := r.db.ExecContext(ctx, query, cutoff.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune %s: %w", table, err)
+	}
+	return res.RowsAffected()
+}
+
+// integrityCheck runs PRAGMA integrity_check and returns the problems it found, if any.
+func (r *Repository) integrityCheck(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check result: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating integrity check results: %w", err)
+	}
+	return problems, nil
+}
+
+// databaseSize sets the size of the database from its page count, and of the WAL file next to it.
+func (r *Repository) databaseSize(ctx context.Context, report *ports.MaintenanceReport) error {
+	var pageCount, pageSize int64
+	if err := r.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return fmt.Errorf("failed to read database page count: %w", err)
+	}
+	if err := r.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return fmt.Errorf("failed to read database page size: %w", err)
+	}
+	report.SizeBytes = pageCount * pageSize
+
+	var seq int
+	var name, file string
+	if err := r.db.QueryRowContext(ctx, "SELECT seq, name, file FROM pragma_database_list WHERE name = 'main'").Scan(&seq, &name, &file); err != nil {
+		return fmt.Errorf("failed to locate database file: %w", err)
+	}
+	if info, err := os.Stat(file + "-wal"); err == nil {
+		report.WALSizeBytes = info.Size()
+	}
+	return nil
+}
+
+// tableRows counts the rows of every table.
+func (r *Repository) tableRows(ctx context.Context) (map[string]int64, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		if err := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, table)).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
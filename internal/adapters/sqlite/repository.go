@@ -3,11 +3,11 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
 	"cryptoMegaBot/internal/domain"
@@ -16,7 +16,11 @@ import (
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
 )
 
-// Repository implements the ports.PositionRepository and ports.TradeRepository interfaces using SQLite.
+// Repository implements the ports.PositionRepository, ports.TradeRepository,
+// ports.StrategyStateRepository, ports.DailyReportRepository, ports.EntryIntentRepository,
+// ports.DailyVolumeRepository, ports.KlineCacheRepository, ports.SafeModeRepository,
+// ports.OrderFillRepository, ports.OrderRepository, ports.BacktestRunRepository and
+// ports.DatabaseMaintainer interfaces using SQLite.
 type Repository struct {
 	db     *sql.DB
 	logger ports.Logger
@@ -103,36 +107,302 @@ func (r *Repository) initializeSchema(ctx context.Context) error {
 		pnl REAL DEFAULT NULL,             -- Null if open
 		stop_loss_order_id TEXT DEFAULT NULL, -- Store associated SL order ID (nullable)
 		take_profit_order_id TEXT DEFAULT NULL, -- Store associated TP order ID (nullable)
-		close_reason TEXT DEFAULT NULL     -- Reason for closing (SL, TP, Market, etc.) (nullable)
+		close_reason TEXT DEFAULT NULL,    -- Reason for closing (SL, TP, Market, etc.) (nullable)
+		entry_reason TEXT DEFAULT NULL,    -- Why the position was entered (nullable)
+		signal_source TEXT DEFAULT NULL,   -- Entry signal type: crossover, pullback, scalp, ... (nullable)
+		confirmation_count INTEGER NOT NULL DEFAULT 0, -- Confirmation conditions met at entry
+		entry_atr REAL NOT NULL DEFAULT 0, -- ATR at entry in price units
+		side TEXT NOT NULL DEFAULT 'LONG', -- Position side: LONG or SHORT
+		scale_in_base_price REAL NOT NULL DEFAULT 0, -- Initial fill price scale-in adds are measured from (0 without scaling in)
+		scale_ins INTEGER NOT NULL DEFAULT 0,        -- Scale-in adds filled so far
+		fees REAL NOT NULL DEFAULT 0,      -- Commissions paid, deducted from pnl
+		funding REAL NOT NULL DEFAULT 0    -- Funding received (negative when paid), included in pnl
 	);
 
 	-- Indexes for positions table
 	CREATE INDEX IF NOT EXISTS idx_positions_symbol_status ON positions(symbol, status);
 	CREATE INDEX IF NOT EXISTS idx_positions_entry_time ON positions(entry_time);
 
-	-- Trigger to enforce only one 'open' position per symbol
-	CREATE TRIGGER IF NOT EXISTS enforce_one_open_position
+	-- Persisted strategy risk state (one row per strategy/symbol)
+	CREATE TABLE IF NOT EXISTS strategy_state (
+		strategy_name TEXT NOT NULL,
+		symbol TEXT NOT NULL,
+		state TEXT NOT NULL,          -- JSON produced by the strategy
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (strategy_name, symbol)
+	);
+
+	-- Daily summary reports (one row per UTC date/symbol)
+	CREATE TABLE IF NOT EXISTS daily_reports (
+		report_date TEXT NOT NULL,    -- UTC date (YYYY-MM-DD)
+		symbol TEXT NOT NULL,
+		period_start TIMESTAMP NOT NULL,
+		period_end TIMESTAMP NOT NULL,
+		trades INTEGER NOT NULL,
+		wins INTEGER NOT NULL,
+		losses INTEGER NOT NULL,
+		win_rate REAL NOT NULL,
+		gross_pnl REAL NOT NULL,
+		fees REAL NOT NULL,           -- Estimated entry and exit fees
+		net_pnl REAL NOT NULL,
+		balance REAL NOT NULL,        -- Account balance when the report was compiled
+		created_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (report_date, symbol)
+	);
+
+	-- Round-trip trades rebuilt from the exchange's trade history (one row per symbol/side/entry)
+	CREATE TABLE IF NOT EXISTS imported_trades (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		symbol TEXT NOT NULL,
+		side TEXT NOT NULL,           -- LONG or SHORT
+		entry_price REAL NOT NULL,    -- Average entry fill price
+		exit_price REAL NOT NULL,     -- Average exit fill price
+		quantity REAL NOT NULL,
+		pnl REAL NOT NULL,            -- Realized PnL net of commissions and funding
+		entry_time TIMESTAMP NOT NULL,
+		exit_time TIMESTAMP NOT NULL,
+		imported_at TIMESTAMP NOT NULL,
+		UNIQUE (symbol, side, entry_time)
+	);
+
+	-- Entry orders saved before placement (one row per client order ID)
+	CREATE TABLE IF NOT EXISTS entry_intents (
+		client_order_id TEXT PRIMARY KEY,
+		symbol TEXT NOT NULL,
+		side TEXT NOT NULL,           -- LONG or SHORT
+		kline_open_time TIMESTAMP NOT NULL,
+		quantity REAL NOT NULL,
+		status TEXT NOT NULL CHECK(status IN ('PENDING', 'OPENED', 'FAILED')),
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_entry_intents_symbol_status ON entry_intents(symbol, status);
+
+	-- Entry volume traded per UTC date/symbol, checked against the daily caps
+	CREATE TABLE IF NOT EXISTS daily_volume (
+		trade_date TEXT NOT NULL,     -- UTC date (YYYY-MM-DD)
+		symbol TEXT NOT NULL,
+		notional REAL NOT NULL,       -- Quote notional of entry fills
+		volume REAL NOT NULL,         -- Base asset quantity of entry fills
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (trade_date, symbol)
+	);
+
+	-- Kline cache saved for warm starts (one row per symbol/interval/open time)
+	CREATE TABLE IF NOT EXISTS kline_cache (
+		symbol TEXT NOT NULL,
+		kline_interval TEXT NOT NULL, -- e.g., 1m
+		open_time TIMESTAMP NOT NULL,
+		close_time TIMESTAMP NOT NULL,
+		open REAL NOT NULL,
+		high REAL NOT NULL,
+		low REAL NOT NULL,
+		close REAL NOT NULL,
+		volume REAL NOT NULL,
+		PRIMARY KEY (symbol, kline_interval, open_time)
+	);
+
+	-- Periods without new entries during exchange maintenance or outages
+	CREATE TABLE IF NOT EXISTS safe_mode_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		symbol TEXT NOT NULL,
+		reason TEXT NOT NULL,         -- What triggered safe mode
+		started_at TIMESTAMP NOT NULL,
+		ended_at TIMESTAMP DEFAULT NULL -- NULL while safe mode is active
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_safe_mode_events_symbol ON safe_mode_events(symbol, started_at);
+
+	-- Executions of the orders placed for positions (one row per order/trade)
+	CREATE TABLE IF NOT EXISTS order_fills (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		position_id INTEGER NOT NULL,
+		order_id INTEGER NOT NULL,    -- Exchange's order ID
+		trade_id INTEGER NOT NULL,    -- Exchange's trade ID of the execution
+		symbol TEXT NOT NULL,
+		side TEXT NOT NULL,           -- BUY or SELL
+		role TEXT NOT NULL CHECK(role IN ('ENTRY', 'SCALE_IN', 'EXIT')),
+		price REAL NOT NULL,
+		quantity REAL NOT NULL,
+		commission REAL NOT NULL,
+		commission_asset TEXT NOT NULL, -- e.g., USDT or BNB
+		fill_time TIMESTAMP NOT NULL,
+		UNIQUE (order_id, trade_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_order_fills_position ON order_fills(position_id);
+
+	-- Orders sent to the exchange, including rejected ones, for auditing
+	CREATE TABLE IF NOT EXISTS orders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		order_id INTEGER NOT NULL,    -- Exchange's order ID; 0 if rejected
+		client_order_id TEXT NOT NULL,
+		position_id INTEGER NOT NULL, -- 0 if no position was opened
+		symbol TEXT NOT NULL,
+		side TEXT NOT NULL,           -- BUY or SELL
+		position_side TEXT NOT NULL,  -- BOTH, LONG or SHORT
+		order_type TEXT NOT NULL,     -- MARKET, LIMIT, STOP_MARKET or TAKE_PROFIT_MARKET
+		purpose TEXT NOT NULL,        -- ENTRY, SCALE_IN, EXIT, STOP_LOSS, TAKE_PROFIT or EMERGENCY_CLOSE
+		quantity REAL NOT NULL,
+		price REAL NOT NULL,
+		stop_price REAL NOT NULL,
+		status TEXT NOT NULL,         -- Exchange status in the order response, or REJECTED
+		error TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_orders_symbol ON orders(symbol, created_at);
+	CREATE INDEX IF NOT EXISTS idx_orders_position ON orders(position_id);
+
+	-- Backtest runs: what ran on which data, and the resulting metrics
+	CREATE TABLE IF NOT EXISTS backtest_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		strategy TEXT NOT NULL,
+		symbol TEXT NOT NULL,
+		kline_interval TEXT NOT NULL, -- Base interval, e.g., 15m
+		params TEXT NOT NULL,         -- JSON object of the strategy and backtest parameters
+		config_hash TEXT NOT NULL,    -- Hash of the strategy and parameters
+		data_start TIMESTAMP NOT NULL,
+		data_end TIMESTAMP NOT NULL,
+		seed INTEGER NOT NULL,
+		total_trades INTEGER NOT NULL,
+		win_rate REAL NOT NULL,
+		total_profit REAL NOT NULL,
+		max_drawdown REAL NOT NULL,
+		profit_factor REAL NOT NULL,
+		sharpe_ratio REAL NOT NULL,
+		final_balance REAL NOT NULL,
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_backtest_runs_config_hash ON backtest_runs(config_hash);
+
+	-- Trades of the backtest runs
+	CREATE TABLE IF NOT EXISTS backtest_trades (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id INTEGER NOT NULL REFERENCES backtest_runs(id) ON DELETE CASCADE,
+		symbol TEXT NOT NULL,
+		side TEXT NOT NULL,           -- LONG or SHORT
+		entry_price REAL NOT NULL,
+		exit_price REAL NOT NULL,
+		quantity REAL NOT NULL,
+		leverage INTEGER NOT NULL,
+		pnl REAL NOT NULL,
+		entry_time TIMESTAMP NOT NULL,
+		exit_time TIMESTAMP NOT NULL,
+		close_reason TEXT NOT NULL,
+		entry_reason TEXT DEFAULT NULL,
+		signal_source TEXT DEFAULT NULL,
+		confirmation_count INTEGER NOT NULL DEFAULT 0,
+		entry_atr REAL NOT NULL DEFAULT 0,
+		mae REAL NOT NULL DEFAULT 0,
+		mfe REAL NOT NULL DEFAULT 0,
+		regime_trend TEXT DEFAULT NULL,
+		regime_volatility TEXT DEFAULT NULL,
+		regime_htf TEXT DEFAULT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_backtest_trades_run ON backtest_trades(run_id);
+	`
+	// Note: This simple ExecContext won't handle schema *changes* well (e.g., adding columns).
+	// It only ensures tables/indexes exist; addMissingColumns handles new columns.
+	_, err := r.db.ExecContext(ctx, schema)
+	if err != nil {
+		return fmt.Errorf("failed to execute schema initialization: %w", err)
+	}
+	if err := r.addMissingColumns(ctx, "positions", positionEntryTagColumns); err != nil {
+		return err
+	}
+	if err := r.addMissingColumns(ctx, "positions", positionScaleInColumns); err != nil {
+		return err
+	}
+	if err := r.addMissingColumns(ctx, "positions", positionCostColumns); err != nil {
+		return err
+	}
+	return r.ensureOpenPositionTrigger(ctx)
+}
+
+// ensureOpenPositionTrigger enforces at most one open position per symbol and side, so a hedge
+// mode account can hold a long and a short at once. The trigger is created after the side column
+// exists; the older per-symbol trigger is dropped since it would block the second side.
+func (r *Repository) ensureOpenPositionTrigger(ctx context.Context) error {
+	const trigger = `
+	DROP TRIGGER IF EXISTS enforce_one_open_position;
+
+	CREATE TRIGGER IF NOT EXISTS enforce_one_open_position_per_side
 	BEFORE INSERT ON positions
 	WHEN NEW.status = 'open'
 	BEGIN
-		SELECT RAISE(ABORT, 'Only one open position per symbol allowed')
+		SELECT RAISE(ABORT, 'Only one open position per symbol and side allowed')
 		WHERE EXISTS (
 			SELECT 1 FROM positions
-			WHERE symbol = NEW.symbol AND status = 'open'
+			WHERE symbol = NEW.symbol AND side = NEW.side AND status = 'open'
 		);
 	END;
 	`
-	// Note: This simple ExecContext won't handle schema *changes* well (e.g., adding columns).
-	// It only ensures tables/indexes/triggers exist.
-	_, err := r.db.ExecContext(ctx, schema)
+	if _, err := r.db.ExecContext(ctx, trigger); err != nil {
+		return fmt.Errorf("failed to create open position trigger: %w", err)
+	}
+	return nil
+}
+
+// columnDef describes a column added to an existing table after its initial release.
+type columnDef struct {
+	name       string
+	definition string
+}
+
+// positionEntryTagColumns are the entry tag columns added to the positions table.
+var positionEntryTagColumns = []columnDef{
+	{name: "entry_reason", definition: "TEXT DEFAULT NULL"},
+	{name: "signal_source", definition: "TEXT DEFAULT NULL"},
+	{name: "confirmation_count", definition: "INTEGER NOT NULL DEFAULT 0"},
+	{name: "entry_atr", definition: "REAL NOT NULL DEFAULT 0"},
+	{name: "side", definition: "TEXT NOT NULL DEFAULT 'LONG'"},
+}
+
+// positionScaleInColumns are the scale-in state columns added to the positions table.
+var positionScaleInColumns = []columnDef{
+	{name: "scale_in_base_price", definition: "REAL NOT NULL DEFAULT 0"},
+	{name: "scale_ins", definition: "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// positionCostColumns are the commission and funding columns added to the positions table.
+var positionCostColumns = []columnDef{
+	{name: "fees", definition: "REAL NOT NULL DEFAULT 0"},
+	{name: "funding", definition: "REAL NOT NULL DEFAULT 0"},
+}
+
+// addMissingColumns adds columns that databases created by older versions don't have yet.
+func (r *Repository) addMissingColumns(ctx context.Context, table string, columns []columnDef) error {
+	rows, err := r.db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
 	if err != nil {
-		// Check if the error is due to the trigger already existing (common if run multiple times)
-		// This is a basic check, a migration tool handles this better.
-		// Using strings.Contains requires importing "strings"
-		if !strings.Contains(err.Error(), "trigger enforce_one_open_position already exists") {
-			return fmt.Errorf("failed to execute schema initialization: %w", err)
+		return fmt.Errorf("failed to inspect columns of %s: %w", table, err)
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect columns of %s: %w", table, err)
+	}
+
+	for _, col := range columns {
+		if existing[col.name] {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, col.name, col.definition)
+		if _, err := r.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %w", table, col.name, err)
 		}
-		r.logger.Debug(ctx, "Trigger enforce_one_open_position already exists, ignoring error.")
+		r.logger.Info(ctx, "Added missing database column", map[string]interface{}{"table": table, "column": col.name})
 	}
 	return nil
 }
@@ -152,8 +422,10 @@ func (r *Repository) Close() error {
 func (r *Repository) Create(ctx context.Context, pos *domain.Position) (int64, error) {
 	const query = `
 	INSERT INTO positions (symbol, entry_price, quantity, leverage, stop_loss, take_profit, entry_time, status,
-	                       stop_loss_order_id, take_profit_order_id)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)` // Added placeholders for new fields
+	                       stop_loss_order_id, take_profit_order_id,
+	                       entry_reason, signal_source, confirmation_count, entry_atr, side,
+	                       scale_in_base_price, scale_ins, fees, funding)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)` // Added placeholders for new fields
 
 	// Use sql.NullString for nullable text fields
 	var slOrderID, tpOrderID sql.NullString
@@ -166,7 +438,9 @@ func (r *Repository) Create(ctx context.Context, pos *domain.Position) (int64, e
 
 	result, err := r.db.ExecContext(ctx, query,
 		pos.Symbol, pos.EntryPrice, pos.Quantity, pos.Leverage, pos.StopLoss, pos.TakeProfit, pos.EntryTime, pos.Status,
-		slOrderID, tpOrderID) // Pass new nullable fields
+		slOrderID, tpOrderID, // Pass new nullable fields
+		nullString(pos.EntryReason), nullString(string(pos.SignalSource)), pos.ConfirmationCount, pos.EntryATR,
+		pos.PositionSide(), pos.ScaleInBasePrice, pos.ScaleIns, pos.Fees, pos.Funding)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert position for symbol %s: %w", pos.Symbol, err)
 	}
@@ -181,13 +455,16 @@ func (r *Repository) Create(ctx context.Context, pos *domain.Position) (int64, e
 	return id, nil
 }
 
-// Update modifies an existing position based on its ID. Typically used when closing a position.
+// Update modifies an existing position based on its ID: when closing it, when its stops move or
+// when a scale-in add changes its size and blended entry price, or when its costs accrue.
 func (r *Repository) Update(ctx context.Context, pos *domain.Position) error {
 	const query = `
 	UPDATE positions
 	SET exit_price = ?, exit_time = ?, status = ?, pnl = ?, close_reason = ?,
-	    stop_loss_order_id = ?, take_profit_order_id = ?
-	WHERE id = ?` // Removed fields that shouldn't change on close (entry_price, quantity, etc.)
+	    stop_loss_order_id = ?, take_profit_order_id = ?,
+	    entry_price = ?, quantity = ?, stop_loss = ?, take_profit = ?, scale_ins = ?,
+	    fees = ?, funding = ?
+	WHERE id = ?`
 
 	// Prepare nullable fields for update
 	var exitPrice sql.NullFloat64
@@ -218,6 +495,8 @@ func (r *Repository) Update(ctx context.Context, pos *domain.Position) error {
 	result, err := r.db.ExecContext(ctx, query,
 		exitPrice, exitTime, pos.Status, pnl, closeReason,
 		slOrderID, tpOrderID, // Update order IDs as well (might be nullified if cancelled)
+		pos.EntryPrice, pos.Quantity, pos.StopLoss, pos.TakeProfit, pos.ScaleIns,
+		pos.Fees, pos.Funding,
 		pos.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update position ID %d: %w", pos.ID, err)
@@ -240,7 +519,9 @@ func (r *Repository) FindOpenBySymbol(ctx context.Context, symbol string) (*doma
 	const query = `
 	SELECT id, symbol, entry_price, exit_price, quantity, leverage,
 	       stop_loss, take_profit, entry_time, exit_time, status, pnl,
-	       stop_loss_order_id, take_profit_order_id, close_reason
+	       stop_loss_order_id, take_profit_order_id, close_reason,
+	       entry_reason, signal_source, confirmation_count, entry_atr, side,
+	       scale_in_base_price, scale_ins, fees, funding
 	FROM positions
 	WHERE symbol = ? AND status = ?`
 
@@ -256,13 +537,38 @@ func (r *Repository) FindOpenBySymbol(ctx context.Context, symbol string) (*doma
 	return pos, nil
 }
 
+// FindOpenBySymbolAndSide retrieves the open position on one side (LONG or SHORT) of a symbol, if any.
+func (r *Repository) FindOpenBySymbolAndSide(ctx context.Context, symbol string, side domain.PositionSide) (*domain.Position, error) {
+	const query = `
+	SELECT id, symbol, entry_price, exit_price, quantity, leverage,
+	       stop_loss, take_profit, entry_time, exit_time, status, pnl,
+	       stop_loss_order_id, take_profit_order_id, close_reason,
+	       entry_reason, signal_source, confirmation_count, entry_atr, side,
+	       scale_in_base_price, scale_ins, fees, funding
+	FROM positions
+	WHERE symbol = ? AND side = ? AND status = ?`
+
+	row := r.db.QueryRowContext(ctx, query, symbol, side, domain.StatusOpen)
+	pos, err := scanPosition(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug(ctx, "No open position found for symbol and side", map[string]interface{}{"symbol": symbol, "side": side})
+			return nil, nil // Not an error, just not found
+		}
+		return nil, fmt.Errorf("failed to query open %s position for symbol %s: %w", side, symbol, err)
+	}
+	return pos, nil
+}
+
 // FindByID retrieves a position by its unique ID.
 func (r *Repository) FindByID(ctx context.Context, id int64) (*domain.Position, error) {
 	// Updated SELECT to include all columns expected by scanPosition
 	const query = `
 	SELECT id, symbol, entry_price, exit_price, quantity, leverage,
 	       stop_loss, take_profit, entry_time, exit_time, status, pnl,
-	       stop_loss_order_id, take_profit_order_id, close_reason
+	       stop_loss_order_id, take_profit_order_id, close_reason,
+	       entry_reason, signal_source, confirmation_count, entry_atr, side,
+	       scale_in_base_price, scale_ins, fees, funding
 	FROM positions
 	WHERE id = ?`
 
@@ -284,7 +590,9 @@ func (r *Repository) FindAll(ctx context.Context) ([]*domain.Position, error) {
 	const query = `
 	SELECT id, symbol, entry_price, exit_price, quantity, leverage,
 	       stop_loss, take_profit, entry_time, exit_time, status, pnl,
-	       stop_loss_order_id, take_profit_order_id, close_reason
+	       stop_loss_order_id, take_profit_order_id, close_reason,
+	       entry_reason, signal_source, confirmation_count, entry_atr, side,
+	       scale_in_base_price, scale_ins, fees, funding
 	FROM positions
 	ORDER BY entry_time DESC`
 
@@ -330,7 +638,9 @@ func (r *Repository) FindClosedBySymbol(ctx context.Context, symbol string, limi
 	const query = `
 	SELECT id, symbol, entry_price, exit_price, quantity, leverage,
 	       stop_loss, take_profit, entry_time, exit_time, status, pnl,
-	       stop_loss_order_id, take_profit_order_id, close_reason
+	       stop_loss_order_id, take_profit_order_id, close_reason,
+	       entry_reason, signal_source, confirmation_count, entry_atr, side,
+	       scale_in_base_price, scale_ins, fees, funding
 	FROM positions
 	WHERE symbol = ? AND status = ? ORDER BY exit_time DESC LIMIT ?`
 
@@ -371,6 +681,738 @@ func (r *Repository) CountTodayBySymbol(ctx context.Context, symbol string) (int
 	return count, nil
 }
 
+// FindClosedBetween retrieves *closed* positions for a symbol whose exit time is in [from, to),
+// ordered by exit time ascending.
+func (r *Repository) FindClosedBetween(ctx context.Context, symbol string, from, to time.Time) ([]*domain.Position, error) {
+	// julianday normalizes timestamps stored with different zone offsets before comparing
+	const query = `
+	SELECT id, symbol, entry_price, exit_price, quantity, leverage,
+	       stop_loss, take_profit, entry_time, exit_time, status, pnl,
+	       stop_loss_order_id, take_profit_order_id, close_reason,
+	       entry_reason, signal_source, confirmation_count, entry_atr, side,
+	       scale_in_base_price, scale_ins, fees, funding
+	FROM positions
+	WHERE symbol = ? AND status = ?
+	  AND julianday(exit_time) >= julianday(?) AND julianday(exit_time) < julianday(?)
+	ORDER BY exit_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, domain.StatusClosed, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closed positions for symbol %s between %s and %s: %w", symbol, from, to, err)
+	}
+	defer rows.Close()
+
+	positions := make([]*domain.Position, 0)
+	for rows.Next() {
+		pos, err := scanPosition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan closed position during FindClosedBetween: %w", err)
+		}
+		positions = append(positions, pos)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating closed position rows: %w", err)
+	}
+	return positions, nil
+}
+
+// --- StrategyStateRepository Implementation ---
+
+// SaveStrategyState stores (or replaces) the serialized state for a strategy and symbol.
+func (r *Repository) SaveStrategyState(ctx context.Context, strategyName, symbol string, state []byte) error {
+	const query = `
+	INSERT INTO strategy_state (strategy_name, symbol, state, updated_at)
+	VALUES (?, ?, ?, ?)
+	ON CONFLICT(strategy_name, symbol) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContext(ctx, query, strategyName, symbol, string(state), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save strategy state for %s/%s: %w", strategyName, symbol, err)
+	}
+	r.logger.Debug(ctx, "Strategy state saved", map[string]interface{}{"strategy": strategyName, "symbol": symbol})
+	return nil
+}
+
+// LoadStrategyState retrieves the serialized state for a strategy and symbol.
+// Returns nil, nil if no state has been saved yet.
+func (r *Repository) LoadStrategyState(ctx context.Context, strategyName, symbol string) ([]byte, error) {
+	const query = `SELECT state FROM strategy_state WHERE strategy_name = ? AND symbol = ?`
+
+	var state string
+	err := r.db.QueryRowContext(ctx, query, strategyName, symbol).Scan(&state)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not an error, nothing saved yet
+		}
+		return nil, fmt.Errorf("failed to load strategy state for %s/%s: %w", strategyName, symbol, err)
+	}
+	return []byte(state), nil
+}
+
+// FindStrategyStates retrieves the serialized states saved for a symbol, keyed by strategy name.
+func (r *Repository) FindStrategyStates(ctx context.Context, symbol string) (map[string][]byte, error) {
+	const query = `SELECT strategy_name, state FROM strategy_state WHERE symbol = ? ORDER BY strategy_name`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query strategy states for symbol %s: %w", symbol, err)
+	}
+	defer rows.Close()
+
+	states := make(map[string][]byte)
+	for rows.Next() {
+		var name, state string
+		if err := rows.Scan(&name, &state); err != nil {
+			return nil, fmt.Errorf("failed to scan strategy state: %w", err)
+		}
+		states[name] = []byte(state)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating strategy state rows: %w", err)
+	}
+	return states, nil
+}
+
+// --- DailyReportRepository Implementation ---
+
+// reportDateLayout is the format of the daily_reports.report_date column.
+const reportDateLayout = "2006-01-02"
+
+// SaveDailyReport stores (or replaces) the report for its date and symbol.
+func (r *Repository) SaveDailyReport(ctx context.Context, report *domain.DailyReport) error {
+	const query = `
+	INSERT INTO daily_reports (report_date, symbol, period_start, period_end, trades, wins, losses,
+	                           win_rate, gross_pnl, fees, net_pnl, balance, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(report_date, symbol) DO UPDATE SET
+		period_start = excluded.period_start, period_end = excluded.period_end,
+		trades = excluded.trades, wins = excluded.wins, losses = excluded.losses,
+		win_rate = excluded.win_rate, gross_pnl = excluded.gross_pnl, fees = excluded.fees,
+		net_pnl = excluded.net_pnl, balance = excluded.balance, created_at = excluded.created_at`
+
+	date := report.Date.UTC().Format(reportDateLayout)
+	_, err := r.db.ExecContext(ctx, query,
+		date, report.Symbol, report.PeriodStart, report.PeriodEnd, report.Trades, report.Wins, report.Losses,
+		report.WinRate, report.GrossPnL, report.Fees, report.NetPnL, report.Balance, report.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save daily report for %s/%s: %w", report.Symbol, date, err)
+	}
+	r.logger.Debug(ctx, "Daily report saved", map[string]interface{}{"symbol": report.Symbol, "date": date})
+	return nil
+}
+
+// FindDailyReport retrieves the report for a symbol and UTC date.
+// Returns nil, nil if no report exists.
+func (r *Repository) FindDailyReport(ctx context.Context, symbol string, date time.Time) (*domain.DailyReport, error) {
+	const query = `
+	SELECT report_date, symbol, period_start, period_end, trades, wins, losses,
+	       win_rate, gross_pnl, fees, net_pnl, balance, created_at
+	FROM daily_reports WHERE symbol = ? AND report_date = ?`
+
+	var report domain.DailyReport
+	var reportDate string
+	err := r.db.QueryRowContext(ctx, query, symbol, date.UTC().Format(reportDateLayout)).Scan(
+		&reportDate, &report.Symbol, &report.PeriodStart, &report.PeriodEnd, &report.Trades, &report.Wins, &report.Losses,
+		&report.WinRate, &report.GrossPnL, &report.Fees, &report.NetPnL, &report.Balance, &report.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not an error, no report for that day
+		}
+		return nil, fmt.Errorf("failed to find daily report for %s/%s: %w", symbol, date.UTC().Format(reportDateLayout), err)
+	}
+	report.Date, err = time.Parse(reportDateLayout, reportDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse daily report date %q: %w", reportDate, err)
+	}
+	return &report, nil
+}
+
+// --- DailyVolumeRepository Implementation ---
+
+// AddDailyVolume adds an entry fill's notional and quantity to the symbol's totals for the UTC day of date.
+func (r *Repository) AddDailyVolume(ctx context.Context, symbol string, date time.Time, notional, volume float64) error {
+	const query = `
+	INSERT INTO daily_volume (trade_date, symbol, notional, volume, updated_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(trade_date, symbol) DO UPDATE SET
+		notional = notional + excluded.notional, volume = volume + excluded.volume,
+		updated_at = excluded.updated_at`
+
+	day := date.UTC().Format(reportDateLayout)
+	_, err := r.db.ExecContext(ctx, query, day, symbol, notional, volume, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to add daily volume for %s/%s: %w", symbol, day, err)
+	}
+	return nil
+}
+
+// FindDailyVolume retrieves the symbol's entry notional and quantity for the UTC day of date.
+// Returns zero totals if nothing was traded that day.
+func (r *Repository) FindDailyVolume(ctx context.Context, symbol string, date time.Time) (notional, volume float64, err error) {
+	const query = `SELECT notional, volume FROM daily_volume WHERE symbol = ? AND trade_date = ?`
+
+	day := date.UTC().Format(reportDateLayout)
+	err = r.db.QueryRowContext(ctx, query, symbol, day).Scan(&notional, &volume)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, nil // Not an error, nothing traded that day
+		}
+		return 0, 0, fmt.Errorf("failed to find daily volume for %s/%s: %w", symbol, day, err)
+	}
+	return notional, volume, nil
+}
+
+// --- KlineCacheRepository Implementation ---
+
+// SaveKlines replaces the saved klines of a symbol and interval.
+func (r *Repository) SaveKlines(ctx context.Context, symbol, interval string, klines []*domain.Kline) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for kline cache: %w", err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM kline_cache WHERE symbol = ? AND kline_interval = ?`, symbol, interval); err != nil {
+		return fmt.Errorf("failed to clear kline cache for %s/%s: %w", symbol, interval, err)
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO kline_cache (symbol, kline_interval, open_time, close_time, open, high, low, close, volume)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(symbol, kline_interval, open_time) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare kline cache insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, k := range klines {
+		_, err := stmt.ExecContext(ctx, symbol, interval, k.OpenTime.UTC(), k.CloseTime.UTC(), k.Open, k.High, k.Low, k.Close, k.Volume)
+		if err != nil {
+			return fmt.Errorf("failed to save kline %s/%s at %s: %w", symbol, interval, k.OpenTime.UTC().Format(time.RFC3339), err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit kline cache for %s/%s: %w", symbol, interval, err)
+	}
+	r.logger.Debug(ctx, "Kline cache saved", map[string]interface{}{"symbol": symbol, "interval": interval, "count": len(klines)})
+	return nil
+}
+
+// LoadKlines retrieves the saved klines of a symbol and interval, ordered by open time ascending.
+// Returns an empty slice if nothing has been saved yet.
+func (r *Repository) LoadKlines(ctx context.Context, symbol, interval string) ([]*domain.Kline, error) {
+	const query = `
+	SELECT open_time, close_time, open, high, low, close, volume
+	FROM kline_cache WHERE symbol = ? AND kline_interval = ?
+	ORDER BY open_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query kline cache for %s/%s: %w", symbol, interval, err)
+	}
+	defer rows.Close()
+
+	klines := make([]*domain.Kline, 0)
+	for rows.Next() {
+		k := &domain.Kline{Symbol: symbol, Interval: interval, IsFinal: true}
+		if err := rows.Scan(&k.OpenTime, &k.CloseTime, &k.Open, &k.High, &k.Low, &k.Close, &k.Volume); err != nil {
+			return nil, fmt.Errorf("failed to scan kline cache row: %w", err)
+		}
+		klines = append(klines, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating kline cache rows: %w", err)
+	}
+	return klines, nil
+}
+
+// --- SafeModeRepository Implementation ---
+
+// SaveSafeModeEvent stores a new safe mode event and sets its ID.
+func (r *Repository) SaveSafeModeEvent(ctx context.Context, event *domain.SafeModeEvent) error {
+	const query = `INSERT INTO safe_mode_events (symbol, reason, started_at, ended_at) VALUES (?, ?, ?, ?)`
+
+	var endedAt sql.NullTime
+	if !event.EndedAt.IsZero() {
+		endedAt = sql.NullTime{Time: event.EndedAt.UTC(), Valid: true}
+	}
+	res, err := r.db.ExecContext(ctx, query, event.Symbol, event.Reason, event.StartedAt.UTC(), endedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save safe mode event for %s: %w", event.Symbol, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get safe mode event ID: %w", err)
+	}
+	event.ID = id
+	return nil
+}
+
+// EndSafeModeEvent sets the end time of the safe mode event with the given ID.
+func (r *Repository) EndSafeModeEvent(ctx context.Context, id int64, endedAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE safe_mode_events SET ended_at = ? WHERE id = ?`, endedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to end safe mode event %d: %w", id, err)
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("failed to end safe mode event %d: %w", id, ports.ErrNotFound)
+	}
+	return nil
+}
+
+// FindActiveSafeModeEvent retrieves the symbol's latest safe mode event without an end time.
+// Returns nil, nil if safe mode is not active.
+func (r *Repository) FindActiveSafeModeEvent(ctx context.Context, symbol string) (*domain.SafeModeEvent, error) {
+	const query = `
+	SELECT id, symbol, reason, started_at FROM safe_mode_events
+	WHERE symbol = ? AND ended_at IS NULL
+	ORDER BY started_at DESC, id DESC LIMIT 1`
+
+	var event domain.SafeModeEvent
+	err := r.db.QueryRowContext(ctx, query, symbol).Scan(&event.ID, &event.Symbol, &event.Reason, &event.StartedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // Not an error, safe mode is not active
+		}
+		return nil, fmt.Errorf("failed to find active safe mode event for %s: %w", symbol, err)
+	}
+	return &event, nil
+}
+
+// --- OrderFillRepository Implementation ---
+
+// SaveOrderFills stores order fills, skipping those already saved (same order and trade ID).
+func (r *Repository) SaveOrderFills(ctx context.Context, fills []*domain.OrderFill) error {
+	const query = `
+	INSERT INTO order_fills (position_id, order_id, trade_id, symbol, side, role, price, quantity,
+	                         commission, commission_asset, fill_time)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(order_id, trade_id) DO NOTHING`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for order fills: %w", err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	for _, f := range fills {
+		res, err := tx.ExecContext(ctx, query, f.PositionID, f.OrderID, f.TradeID, f.Symbol, f.Side, f.Role,
+			f.Price, f.Quantity, f.Commission, f.CommissionAsset, f.Time.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to save fill %d of order %d: %w", f.TradeID, f.OrderID, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 1 {
+			if id, err := res.LastInsertId(); err == nil {
+				f.ID = id
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order fills: %w", err)
+	}
+	r.logger.Debug(ctx, "Order fills saved", map[string]interface{}{"fills": len(fills)})
+	return nil
+}
+
+// FindOrderFills retrieves the fills of a position, ordered by execution time ascending.
+func (r *Repository) FindOrderFills(ctx context.Context, positionID int64) ([]*domain.OrderFill, error) {
+	const query = `
+	SELECT id, position_id, order_id, trade_id, symbol, side, role, price, quantity,
+	       commission, commission_asset, fill_time
+	FROM order_fills
+	WHERE position_id = ?
+	ORDER BY fill_time ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, positionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order fills of position %d: %w", positionID, err)
+	}
+	defer rows.Close()
+
+	fills := make([]*domain.OrderFill, 0)
+	for rows.Next() {
+		f := &domain.OrderFill{}
+		if err := rows.Scan(&f.ID, &f.PositionID, &f.OrderID, &f.TradeID, &f.Symbol, &f.Side, &f.Role, &f.Price, &f.Quantity,
+			&f.Commission, &f.CommissionAsset, &f.Time); err != nil {
+			return nil, fmt.Errorf("failed to scan order fill: %w", err)
+		}
+		fills = append(fills, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating order fill rows: %w", err)
+	}
+	return fills, nil
+}
+
+// --- OrderRepository Implementation ---
+
+// SaveOrder stores an order sent to the exchange and sets its ID.
+func (r *Repository) SaveOrder(ctx context.Context, order *domain.Order) error {
+	const query = `
+	INSERT INTO orders (order_id, client_order_id, position_id, symbol, side, position_side, order_type, purpose,
+	                    quantity, price, stop_price, status, error, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := r.db.ExecContext(ctx, query, order.OrderID, order.ClientOrderID, order.PositionID, order.Symbol, order.Side,
+		order.PositionSide, order.Type, order.Purpose, order.Quantity, order.Price, order.StopPrice, order.Status, order.Error,
+		order.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save order %d: %w", order.OrderID, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get ID of saved order: %w", err)
+	}
+	order.ID = id
+	return nil
+}
+
+// AssignOrders links the orders with the given exchange order IDs to a position. Rejected orders
+// (ID 0) are never linked.
+func (r *Repository) AssignOrders(ctx context.Context, positionID int64, orderIDs []int64) error {
+	const query = `UPDATE orders SET position_id = ? WHERE order_id = ? AND order_id != 0`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for order assignment: %w", err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	for _, orderID := range orderIDs {
+		if _, err := tx.ExecContext(ctx, query, positionID, orderID); err != nil {
+			return fmt.Errorf("failed to assign order %d to position %d: %w", orderID, positionID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit order assignment: %w", err)
+	}
+	return nil
+}
+
+// FindOrders retrieves a page of the orders matching the filter, newest first, and the total
+// number of matching orders.
+func (r *Repository) FindOrders(ctx context.Context, filter ports.OrderFilter) ([]*domain.Order, int, error) {
+	// julianday normalizes timestamps stored with different zone offsets before comparing
+	where := `
+	WHERE (? = '' OR symbol = ?) AND (? = '' OR status = ?) AND (? = 0 OR position_id = ?)`
+	args := []interface{}{filter.Symbol, filter.Symbol, filter.Status, filter.Status, filter.PositionID, filter.PositionID}
+	if !filter.From.IsZero() {
+		where += ` AND julianday(created_at) >= julianday(?)`
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		where += ` AND julianday(created_at) < julianday(?)`
+		args = append(args, filter.To.UTC())
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	query := `
+	SELECT id, order_id, client_order_id, position_id, symbol, side, position_side, order_type, purpose,
+	       quantity, price, stop_price, status, error, created_at
+	FROM orders` + where + `
+	ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	} else if filter.Offset > 0 {
+		query += " LIMIT -1 OFFSET ?"
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]*domain.Order, 0)
+	for rows.Next() {
+		o := &domain.Order{}
+		if err := rows.Scan(&o.ID, &o.OrderID, &o.ClientOrderID, &o.PositionID, &o.Symbol, &o.Side, &o.PositionSide, &o.Type,
+			&o.Purpose, &o.Quantity, &o.Price, &o.StopPrice, &o.Status, &o.Error, &o.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating order rows: %w", err)
+	}
+	return orders, total, nil
+}
+
+// --- ImportedTradeRepository Implementation ---
+
+// SaveImportedTrades stores trades rebuilt from the exchange history, skipping those already
+// imported (same symbol, side and entry time). Returns the number of new trades.
+func (r *Repository) SaveImportedTrades(ctx context.Context, trades []*domain.Trade) (int, error) {
+	const query = `
+	INSERT INTO imported_trades (symbol, side, entry_price, exit_price, quantity, pnl, entry_time, exit_time, imported_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(symbol, side, entry_time) DO NOTHING`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction for imported trades: %w", err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	inserted := 0
+	now := time.Now().UTC()
+	for _, t := range trades {
+		// UTC keeps the stored entry time identical across imports for the uniqueness check
+		res, err := tx.ExecContext(ctx, query, t.Symbol, t.Side, t.EntryPrice, t.ExitPrice, t.Quantity, t.PNL,
+			t.EntryTime.UTC(), t.ExitTime.UTC(), now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save imported %s trade entered at %s: %w", t.Symbol, t.EntryTime, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			inserted += int(n)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit imported trades: %w", err)
+	}
+	r.logger.Debug(ctx, "Imported trades saved", map[string]interface{}{"trades": len(trades), "new": inserted})
+	return inserted, nil
+}
+
+// FindImportedTrades retrieves imported trades for a symbol whose exit time is in [from, to),
+// ordered by entry time ascending.
+func (r *Repository) FindImportedTrades(ctx context.Context, symbol string, from, to time.Time) ([]*domain.Trade, error) {
+	const query = `
+	SELECT id, symbol, side, entry_price, exit_price, quantity, pnl, entry_time, exit_time
+	FROM imported_trades
+	WHERE symbol = ? AND julianday(exit_time) >= julianday(?) AND julianday(exit_time) < julianday(?)
+	ORDER BY entry_time ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query imported trades for symbol %s: %w", symbol, err)
+	}
+	defer rows.Close()
+
+	trades := make([]*domain.Trade, 0)
+	for rows.Next() {
+		t := &domain.Trade{CloseReason: domain.CloseReasonUnknown}
+		if err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.EntryPrice, &t.ExitPrice, &t.Quantity, &t.PNL, &t.EntryTime, &t.ExitTime); err != nil {
+			return nil, fmt.Errorf("failed to scan imported trade: %w", err)
+		}
+		trades = append(trades, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating imported trade rows: %w", err)
+	}
+	return trades, nil
+}
+
+// --- BacktestRunRepository Implementation ---
+
+// SaveBacktestRun stores a run with its trades in one transaction and sets the run's ID.
+func (r *Repository) SaveBacktestRun(ctx context.Context, run *domain.BacktestRun, trades []*domain.Trade) error {
+	const runQuery = `
+	INSERT INTO backtest_runs (strategy, symbol, kline_interval, params, config_hash, data_start, data_end, seed,
+		total_trades, win_rate, total_profit, max_drawdown, profit_factor, sharpe_ratio, final_balance, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	const tradeQuery = `
+	INSERT INTO backtest_trades (run_id, symbol, side, entry_price, exit_price, quantity, leverage, pnl, entry_time,
+		exit_time, close_reason, entry_reason, signal_source, confirmation_count, entry_atr, mae, mfe,
+		regime_trend, regime_volatility, regime_htf)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	params, err := json.Marshal(run.Params)
+	if err != nil {
+		return fmt.Errorf("failed to encode backtest run params: %w", err)
+	}
+	if run.ConfigHash == "" {
+		run.ConfigHash = run.ComputeConfigHash()
+	}
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now().UTC()
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for backtest run: %w", err)
+	}
+	defer tx.Rollback() // No-op after Commit
+
+	res, err := tx.ExecContext(ctx, runQuery, run.Strategy, run.Symbol, run.Interval, string(params), run.ConfigHash,
+		run.DataStart.UTC(), run.DataEnd.UTC(), run.Seed, run.TotalTrades, run.WinRate, run.TotalProfit,
+		run.MaxDrawdown, run.ProfitFactor, run.SharpeRatio, run.FinalBalance, run.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save %s backtest run: %w", run.Strategy, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get backtest run ID: %w", err)
+	}
+
+	for _, t := range trades {
+		_, err := tx.ExecContext(ctx, tradeQuery, id, t.Symbol, t.Side, t.EntryPrice, t.ExitPrice, t.Quantity, t.Leverage,
+			t.PNL, t.EntryTime.UTC(), t.ExitTime.UTC(), t.CloseReason, nullString(t.EntryReason),
+			nullString(string(t.SignalSource)), t.ConfirmationCount, t.EntryATR, t.MAE, t.MFE,
+			nullString(string(t.Regime.Trend)), nullString(string(t.Regime.Volatility)), nullString(string(t.Regime.HigherTF)))
+		if err != nil {
+			return fmt.Errorf("failed to save backtest trade entered at %s: %w", t.EntryTime, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit backtest run: %w", err)
+	}
+	run.ID = id
+	r.logger.Debug(ctx, "Backtest run saved", map[string]interface{}{"runID": id, "configHash": run.ConfigHash, "trades": len(trades)})
+	return nil
+}
+
+// FindBacktestRuns retrieves the runs matching the filter, newest first.
+func (r *Repository) FindBacktestRuns(ctx context.Context, filter ports.BacktestRunFilter) ([]*domain.BacktestRun, error) {
+	query := `
+	SELECT id, strategy, symbol, kline_interval, params, config_hash, data_start, data_end, seed,
+		total_trades, win_rate, total_profit, max_drawdown, profit_factor, sharpe_ratio, final_balance, created_at
+	FROM backtest_runs
+	WHERE (? = '' OR strategy = ?) AND (? = '' OR symbol = ?) AND (? = '' OR config_hash = ?)
+	ORDER BY created_at DESC, id DESC`
+	args := []interface{}{filter.Strategy, filter.Strategy, filter.Symbol, filter.Symbol, filter.ConfigHash, filter.ConfigHash}
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query backtest runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]*domain.BacktestRun, 0)
+	for rows.Next() {
+		run := &domain.BacktestRun{}
+		var params string
+		if err := rows.Scan(&run.ID, &run.Strategy, &run.Symbol, &run.Interval, &params, &run.ConfigHash,
+			&run.DataStart, &run.DataEnd, &run.Seed, &run.TotalTrades, &run.WinRate, &run.TotalProfit,
+			&run.MaxDrawdown, &run.ProfitFactor, &run.SharpeRatio, &run.FinalBalance, &run.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan backtest run: %w", err)
+		}
+		if err := json.Unmarshal([]byte(params), &run.Params); err != nil {
+			return nil, fmt.Errorf("failed to decode params of backtest run %d: %w", run.ID, err)
+		}
+		runs = append(runs, run)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating backtest run rows: %w", err)
+	}
+	return runs, nil
+}
+
+// FindBacktestTrades retrieves the trades of a run, ordered by entry time ascending.
+func (r *Repository) FindBacktestTrades(ctx context.Context, runID int64) ([]*domain.Trade, error) {
+	const query = `
+	SELECT id, symbol, side, entry_price, exit_price, quantity, leverage, pnl, entry_time, exit_time, close_reason,
+		entry_reason, signal_source, confirmation_count, entry_atr, mae, mfe, regime_trend, regime_volatility, regime_htf
+	FROM backtest_trades
+	WHERE run_id = ?
+	ORDER BY entry_time ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades of backtest run %d: %w", runID, err)
+	}
+	defer rows.Close()
+
+	trades := make([]*domain.Trade, 0)
+	for rows.Next() {
+		t := &domain.Trade{}
+		var entryReason, signalSource, trend, volatility, higherTF sql.NullString
+		if err := rows.Scan(&t.ID, &t.Symbol, &t.Side, &t.EntryPrice, &t.ExitPrice, &t.Quantity, &t.Leverage, &t.PNL,
+			&t.EntryTime, &t.ExitTime, &t.CloseReason, &entryReason, &signalSource, &t.ConfirmationCount, &t.EntryATR,
+			&t.MAE, &t.MFE, &trend, &volatility, &higherTF); err != nil {
+			return nil, fmt.Errorf("failed to scan backtest trade: %w", err)
+		}
+		t.EntryReason = entryReason.String
+		t.SignalSource = domain.SignalSource(signalSource.String)
+		t.Regime = domain.MarketRegime{
+			Trend:      domain.TrendRegime(trend.String),
+			Volatility: domain.VolatilityRegime(volatility.String),
+			HigherTF:   domain.TrendDirection(higherTF.String),
+		}
+		trades = append(trades, t)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating backtest trade rows: %w", err)
+	}
+	return trades, nil
+}
+
+// --- EntryIntentRepository Implementation ---
+
+// SaveEntryIntent stores a new entry intent.
+// Returns ports.ErrDuplicateEntry if an intent with the same client order ID already exists.
+func (r *Repository) SaveEntryIntent(ctx context.Context, intent *domain.EntryIntent) error {
+	const query = `
+	INSERT INTO entry_intents (client_order_id, symbol, side, kline_open_time, quantity, status, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(client_order_id) DO NOTHING`
+
+	res, err := r.db.ExecContext(ctx, query, intent.ClientOrderID, intent.Symbol, intent.Side, intent.KlineOpenTime.UTC(),
+		intent.Quantity, intent.Status, intent.CreatedAt.UTC(), intent.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save entry intent %s: %w", intent.ClientOrderID, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("entry intent %s: %w", intent.ClientOrderID, ports.ErrDuplicateEntry)
+	}
+	r.logger.Debug(ctx, "Entry intent saved", map[string]interface{}{"clientOrderID": intent.ClientOrderID, "side": intent.Side})
+	return nil
+}
+
+// UpdateEntryIntentStatus sets the status of the intent with the given client order ID.
+func (r *Repository) UpdateEntryIntentStatus(ctx context.Context, clientOrderID string, status domain.EntryIntentStatus) error {
+	const query = `UPDATE entry_intents SET status = ?, updated_at = ? WHERE client_order_id = ?`
+
+	res, err := r.db.ExecContext(ctx, query, status, time.Now().UTC(), clientOrderID)
+	if err != nil {
+		return fmt.Errorf("failed to update entry intent %s: %w", clientOrderID, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("entry intent %s: %w", clientOrderID, ports.ErrNotFound)
+	}
+	return nil
+}
+
+// FindPendingEntryIntents retrieves the symbol's intents still pending, oldest first.
+func (r *Repository) FindPendingEntryIntents(ctx context.Context, symbol string) ([]*domain.EntryIntent, error) {
+	const query = `
+	SELECT client_order_id, symbol, side, kline_open_time, quantity, status, created_at
+	FROM entry_intents
+	WHERE symbol = ? AND status = ?
+	ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, domain.EntryIntentPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending entry intents for symbol %s: %w", symbol, err)
+	}
+	defer rows.Close()
+
+	intents := make([]*domain.EntryIntent, 0)
+	for rows.Next() {
+		intent := &domain.EntryIntent{}
+		if err := rows.Scan(&intent.ClientOrderID, &intent.Symbol, &intent.Side, &intent.KlineOpenTime,
+			&intent.Quantity, &intent.Status, &intent.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entry intent: %w", err)
+		}
+		intents = append(intents, intent)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entry intent rows: %w", err)
+	}
+	return intents, nil
+}
+
 // --- Helper Scan Functions --- (scanTrade removed)
 
 // scanner defines an interface compatible with *sql.Row and *sql.Rows.
@@ -388,12 +1430,16 @@ func scanPosition(s scanner) (*domain.Position, error) {
 	var tpOrderID sql.NullString
 	var closeReason sql.NullString
 	var exitPrice sql.NullFloat64 // Add NullFloat64 for exit_price
+	var entryReason, signalSource sql.NullString
+	var side string
 
 	// Ensure the Scan call matches the SELECT query columns exactly
 	err := s.Scan(
 		&p.ID, &p.Symbol, &p.EntryPrice, &exitPrice, &p.Quantity, &p.Leverage,
 		&p.StopLoss, &p.TakeProfit, &p.EntryTime, &exitTime, &status, &pnl,
 		&slOrderID, &tpOrderID, &closeReason, // Scan new columns
+		&entryReason, &signalSource, &p.ConfirmationCount, &p.EntryATR, &side,
+		&p.ScaleInBasePrice, &p.ScaleIns, &p.Fees, &p.Funding,
 	)
 	if err != nil {
 		return nil, err // Handle sql.ErrNoRows in the caller
@@ -422,8 +1468,17 @@ func scanPosition(s scanner) (*domain.Position, error) {
 		p.CloseReason = "" // Default to empty string if NULL
 	}
 
+	p.EntryReason = entryReason.String                        // Empty if NULL
+	p.SignalSource = domain.SignalSource(signalSource.String) // SignalSourceUnknown if NULL
+
+	p.Side = domain.PositionSide(side)
 	p.Status = domain.PositionStatus(status) // Convert string to domain type
 	return p, nil
 }
 
+// nullString converts an empty string to SQL NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 // scanTrade function removed.
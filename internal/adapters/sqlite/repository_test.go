@@ -2,12 +2,14 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -419,3 +421,673 @@ func TestRepository_GetTotalProfit(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_StrategyState(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Nothing saved yet
+	state, err := repo.LoadStrategyState(ctx, "ImprovedMACrossover", "ETHUSDT")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// Save and load
+	require.NoError(t, repo.SaveStrategyState(ctx, "ImprovedMACrossover", "ETHUSDT", []byte(`{"dailyLossCount":1}`)))
+	state, err = repo.LoadStrategyState(ctx, "ImprovedMACrossover", "ETHUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, `{"dailyLossCount":1}`, string(state))
+
+	// Saving again replaces the previous state
+	require.NoError(t, repo.SaveStrategyState(ctx, "ImprovedMACrossover", "ETHUSDT", []byte(`{"dailyLossCount":2}`)))
+	state, err = repo.LoadStrategyState(ctx, "ImprovedMACrossover", "ETHUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, `{"dailyLossCount":2}`, string(state))
+
+	// State is scoped per symbol
+	state, err = repo.LoadStrategyState(ctx, "ImprovedMACrossover", "BTCUSDT")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// All states of a symbol
+	require.NoError(t, repo.SaveStrategyState(ctx, "active_strategy", "ETHUSDT", []byte(`{"name":"RSI"}`)))
+	require.NoError(t, repo.SaveStrategyState(ctx, "RSI", "BTCUSDT", []byte(`{}`)))
+	states, err := repo.FindStrategyStates(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{
+		"ImprovedMACrossover": []byte(`{"dailyLossCount":2}`),
+		"active_strategy":     []byte(`{"name":"RSI"}`),
+	}, states)
+}
+
+func TestRepository_EntryTag(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	pos := &domain.Position{
+		Symbol:     "BTCUSDT",
+		EntryPrice: 50000.0,
+		Quantity:   0.1,
+		Leverage:   10,
+		StopLoss:   49000.0,
+		TakeProfit: 51000.0,
+		EntryTime:  time.Now().UTC(),
+		Status:     domain.StatusOpen,
+		EntryTag: domain.EntryTag{
+			EntryReason:       "pullback in established uptrend",
+			SignalSource:      domain.SignalSourcePullback,
+			ConfirmationCount: 3,
+			EntryATR:          125.5,
+		},
+	}
+	id, err := repo.Create(ctx, pos)
+	require.NoError(t, err)
+
+	found, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, pos.EntryTag, found.EntryTag)
+}
+
+func TestRepository_ScaleIn(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	pos := &domain.Position{
+		Symbol:           "ETHUSDT",
+		EntryPrice:       2000.0,
+		Quantity:         0.5,
+		Leverage:         4,
+		StopLoss:         1960.0,
+		TakeProfit:       2100.0,
+		EntryTime:        time.Now().UTC(),
+		Status:           domain.StatusOpen,
+		ScaleInBasePrice: 2000.0,
+	}
+	id, err := repo.Create(ctx, pos)
+	require.NoError(t, err)
+
+	// An add changes the size and blended entry price, which Update persists
+	require.NoError(t, pos.ScaleIn(0.25, 1994))
+	pos.StopLoss = 1965.0
+	require.NoError(t, repo.Update(ctx, pos))
+
+	found, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, 0.75, found.Quantity)
+	assert.InDelta(t, 1998.0, found.EntryPrice, 1e-9)
+	assert.Equal(t, 1965.0, found.StopLoss)
+	assert.Equal(t, 2000.0, found.ScaleInBasePrice)
+	assert.Equal(t, 1, found.ScaleIns)
+}
+
+func TestRepository_PositionCosts(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	pos := &domain.Position{Symbol: "ETHUSDT", EntryPrice: 2000.0, Quantity: 0.5, Leverage: 4, StopLoss: 1960.0,
+		TakeProfit: 2100.0, EntryTime: time.Now().UTC(), Status: domain.StatusOpen, Fees: 0.4}
+	id, err := repo.Create(ctx, pos)
+	require.NoError(t, err)
+
+	// Accrued funding and commissions are persisted while the position is open
+	pos.Fees, pos.Funding = 0.8, -1.2
+	require.NoError(t, repo.Update(ctx, pos))
+
+	found, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, 0.8, found.Fees)
+	assert.Equal(t, -1.2, found.Funding)
+}
+
+func TestRepository_HedgeModePositions(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	newPosition := func(side domain.PositionSide) *domain.Position {
+		return &domain.Position{
+			Symbol:     "ETHUSDT",
+			Side:       side,
+			EntryPrice: 2000.0,
+			Quantity:   1.0,
+			Leverage:   4,
+			StopLoss:   1900.0,
+			TakeProfit: 2200.0,
+			EntryTime:  time.Now().UTC(),
+			Status:     domain.StatusOpen,
+		}
+	}
+
+	// A long and a short can be open on the same symbol at once; no side is stored as LONG
+	_, err := repo.Create(ctx, newPosition(""))
+	require.NoError(t, err)
+	shortID, err := repo.Create(ctx, newPosition(domain.PositionSideShort))
+	require.NoError(t, err)
+
+	// But still only one per side
+	_, err = repo.Create(ctx, newPosition(domain.PositionSideShort))
+	assert.Error(t, err)
+
+	short, err := repo.FindOpenBySymbolAndSide(ctx, "ETHUSDT", domain.PositionSideShort)
+	require.NoError(t, err)
+	require.NotNil(t, short)
+	assert.Equal(t, shortID, short.ID)
+	assert.True(t, short.IsShort())
+
+	long, err := repo.FindOpenBySymbolAndSide(ctx, "ETHUSDT", domain.PositionSideLong)
+	require.NoError(t, err)
+	require.NotNil(t, long)
+	assert.Equal(t, domain.PositionSideLong, long.Side)
+
+	none, err := repo.FindOpenBySymbolAndSide(ctx, "BTCUSDT", domain.PositionSideLong)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+}
+
+func TestRepository_MigratesLegacyPositionsTable(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Simulate a database created before entry tags existed
+	legacy, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = legacy.ExecContext(ctx, `
+	CREATE TABLE positions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		symbol TEXT NOT NULL,
+		entry_price REAL NOT NULL,
+		exit_price REAL DEFAULT NULL,
+		quantity REAL NOT NULL,
+		leverage INTEGER NOT NULL,
+		stop_loss REAL NOT NULL,
+		take_profit REAL NOT NULL,
+		entry_time TIMESTAMP NOT NULL,
+		exit_time TIMESTAMP DEFAULT NULL,
+		status TEXT NOT NULL CHECK(status IN ('open', 'closed')),
+		pnl REAL DEFAULT NULL,
+		stop_loss_order_id TEXT DEFAULT NULL,
+		take_profit_order_id TEXT DEFAULT NULL,
+		close_reason TEXT DEFAULT NULL
+	);
+	INSERT INTO positions (symbol, entry_price, quantity, leverage, stop_loss, take_profit, entry_time, status)
+	VALUES ('ETHUSDT', 2000, 1, 3, 1900, 2100, '2025-05-01 10:00:00', 'open');`)
+	require.NoError(t, err)
+	require.NoError(t, legacy.Close())
+
+	repo, err := NewRepository(Config{DBPath: dbPath, Logger: &mockLogger{}})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	found, err := repo.FindOpenBySymbol(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, domain.SignalSourceUnknown, found.SignalSource)
+	assert.Zero(t, found.ConfirmationCount)
+	assert.Equal(t, domain.PositionSideLong, found.Side)
+
+	// The legacy per-symbol trigger is replaced, so a short can be opened next to the long
+	_, err = repo.Create(ctx, &domain.Position{Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2000,
+		Quantity: 1, Leverage: 3, StopLoss: 2100, TakeProfit: 1900, EntryTime: time.Now().UTC(), Status: domain.StatusOpen})
+	require.NoError(t, err)
+}
+
+func TestRepository_FindClosedBetween(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	berlin := time.FixedZone("UTC+2", 2*60*60)
+	exits := []time.Time{
+		day.Add(-time.Minute),              // Previous day
+		day.Add(2 * time.Hour),             // Inside
+		day.Add(23 * time.Hour).In(berlin), // Inside, stored with a different offset
+		day.Add(24 * time.Hour),            // Next day (end is exclusive)
+		day.Add(30 * time.Minute),          // Inside
+	}
+	for _, exit := range exits {
+		pos := &domain.Position{
+			Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.1, Leverage: 1, StopLoss: 1900, TakeProfit: 2100,
+			EntryTime: exit.Add(-time.Hour), Status: domain.StatusOpen,
+		}
+		id, err := repo.Create(ctx, pos)
+		require.NoError(t, err)
+		pos.ID = id
+		pos.Status = domain.StatusClosed
+		pos.ExitPrice = 2050
+		pos.ExitTime = exit
+		pos.PNL = 5
+		pos.CloseReason = domain.CloseReasonTakeProfit
+		require.NoError(t, repo.Update(ctx, pos))
+	}
+
+	positions, err := repo.FindClosedBetween(ctx, "ETHUSDT", day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, positions, 3)
+	assert.True(t, positions[0].ExitTime.Equal(exits[4]))
+	assert.True(t, positions[1].ExitTime.Equal(exits[1]))
+	assert.True(t, positions[2].ExitTime.Equal(exits[2]))
+
+	positions, err = repo.FindClosedBetween(ctx, "BTCUSDT", day, day.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, positions)
+}
+
+func TestRepository_DailyReport(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	found, err := repo.FindDailyReport(ctx, "ETHUSDT", day)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	report := &domain.DailyReport{
+		Date: day, Symbol: "ETHUSDT", PeriodStart: day, PeriodEnd: day.Add(24 * time.Hour),
+		Trades: 4, Wins: 3, Losses: 1, WinRate: 0.75, GrossPnL: 12.5, Fees: 1.5, NetPnL: 11, Balance: 1011,
+		CreatedAt: day.Add(24 * time.Hour),
+	}
+	require.NoError(t, repo.SaveDailyReport(ctx, report))
+
+	found, err = repo.FindDailyReport(ctx, "ETHUSDT", day.Add(12*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.True(t, found.Date.Equal(day))
+	assert.True(t, found.PeriodEnd.Equal(report.PeriodEnd))
+	assert.Equal(t, 4, found.Trades)
+	assert.Equal(t, 0.75, found.WinRate)
+	assert.Equal(t, 11.0, found.NetPnL)
+
+	// Saving again for the same day replaces the report
+	report.Trades = 5
+	report.NetPnL = 9
+	require.NoError(t, repo.SaveDailyReport(ctx, report))
+	found, err = repo.FindDailyReport(ctx, "ETHUSDT", day)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, 5, found.Trades)
+	assert.Equal(t, 9.0, found.NetPnL)
+}
+
+func TestRepository_DailyVolume(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	day := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	notional, volume, err := repo.FindDailyVolume(ctx, "ETHUSDT", day)
+	require.NoError(t, err)
+	assert.Zero(t, notional)
+	assert.Zero(t, volume)
+
+	// Fills of the same UTC day accumulate
+	require.NoError(t, repo.AddDailyVolume(ctx, "ETHUSDT", day, 2000, 1))
+	require.NoError(t, repo.AddDailyVolume(ctx, "ETHUSDT", day.Add(10*time.Hour), 1050, 0.5))
+	require.NoError(t, repo.AddDailyVolume(ctx, "ETHUSDT", day.Add(24*time.Hour), 500, 0.25))
+	require.NoError(t, repo.AddDailyVolume(ctx, "BTCUSDT", day, 30000, 0.5))
+
+	notional, volume, err = repo.FindDailyVolume(ctx, "ETHUSDT", day.Add(14*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 3050.0, notional)
+	assert.Equal(t, 1.5, volume)
+
+	notional, volume, err = repo.FindDailyVolume(ctx, "ETHUSDT", day.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 500.0, notional)
+	assert.Equal(t, 0.25, volume)
+}
+
+func TestRepository_KlineCache(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	klines, err := repo.LoadKlines(ctx, "ETHUSDT", "1m")
+	require.NoError(t, err)
+	assert.Empty(t, klines)
+
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	saved := make([]*domain.Kline, 3)
+	for i := range saved {
+		open := start.Add(time.Duration(i) * time.Minute)
+		saved[i] = &domain.Kline{OpenTime: open, CloseTime: open.Add(time.Minute - time.Millisecond), Open: 100, High: 102, Low: 99, Close: 101 + float64(i), Volume: 5}
+	}
+	require.NoError(t, repo.SaveKlines(ctx, "ETHUSDT", "1m", saved))
+	// Saving again replaces the previous set
+	require.NoError(t, repo.SaveKlines(ctx, "ETHUSDT", "1m", saved[1:]))
+
+	klines, err = repo.LoadKlines(ctx, "ETHUSDT", "1m")
+	require.NoError(t, err)
+	require.Len(t, klines, 2)
+	assert.True(t, klines[0].OpenTime.Equal(saved[1].OpenTime))
+	assert.True(t, klines[1].CloseTime.Equal(saved[2].CloseTime))
+	assert.Equal(t, 103.0, klines[1].Close)
+	assert.Equal(t, "1m", klines[1].Interval)
+	assert.True(t, klines[1].IsFinal)
+
+	klines, err = repo.LoadKlines(ctx, "ETHUSDT", "5m")
+	require.NoError(t, err)
+	assert.Empty(t, klines)
+}
+
+func TestRepository_ImportedTrades(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	trades := []*domain.Trade{
+		{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000, ExitPrice: 2050, Quantity: 0.5, PNL: 24.5,
+			EntryTime: start, ExitTime: start.Add(time.Hour)},
+		{Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2060, ExitPrice: 2070, Quantity: 0.5, PNL: -5.5,
+			EntryTime: start.Add(2 * time.Hour), ExitTime: start.Add(3 * time.Hour)},
+	}
+	inserted, err := repo.SaveImportedTrades(ctx, trades)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inserted)
+
+	// Importing an overlapping range again only adds the new trade; entry times in another
+	// zone still match the stored ones
+	again := append([]*domain.Trade{{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000, ExitPrice: 2050,
+		Quantity: 0.5, PNL: 24.5, EntryTime: start.In(time.FixedZone("UTC+3", 3*3600)), ExitTime: start.Add(time.Hour)}},
+		&domain.Trade{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2080, ExitPrice: 2090, Quantity: 1, PNL: 9,
+			EntryTime: start.Add(24 * time.Hour), ExitTime: start.Add(25 * time.Hour)})
+	inserted, err = repo.SaveImportedTrades(ctx, again)
+	require.NoError(t, err)
+	assert.Equal(t, 1, inserted)
+
+	found, err := repo.FindImportedTrades(ctx, "ETHUSDT", start, start.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, domain.PositionSideLong, found[0].Side)
+	assert.Equal(t, 24.5, found[0].PNL)
+	assert.True(t, found[0].EntryTime.Equal(start))
+	assert.Equal(t, domain.PositionSideShort, found[1].Side)
+	assert.Equal(t, 2070.0, found[1].ExitPrice)
+
+	found, err = repo.FindImportedTrades(ctx, "BTCUSDT", start, start.Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestRepository_EntryIntents(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	openTime := time.Date(2025, 6, 11, 12, 30, 0, 0, time.UTC)
+	first := &domain.EntryIntent{
+		ClientOrderID: domain.EntryClientOrderID("ETHUSDT", domain.PositionSideLong, openTime),
+		Symbol:        "ETHUSDT",
+		Side:          domain.PositionSideLong,
+		KlineOpenTime: openTime,
+		Quantity:      0.5,
+		Status:        domain.EntryIntentPending,
+		CreatedAt:     openTime.Add(time.Minute),
+	}
+	second := *first
+	second.ClientOrderID = domain.EntryClientOrderID("ETHUSDT", domain.PositionSideShort, openTime)
+	second.Side = domain.PositionSideShort
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	require.NoError(t, repo.SaveEntryIntent(ctx, first))
+	require.NoError(t, repo.SaveEntryIntent(ctx, &second))
+
+	// The same signal can't be saved twice
+	err := repo.SaveEntryIntent(ctx, first)
+	assert.ErrorIs(t, err, ports.ErrDuplicateEntry)
+
+	pending, err := repo.FindPendingEntryIntents(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, first.ClientOrderID, pending[0].ClientOrderID)
+	assert.Equal(t, domain.PositionSideLong, pending[0].Side)
+	assert.True(t, pending[0].KlineOpenTime.Equal(openTime))
+	assert.Equal(t, 0.5, pending[0].Quantity)
+	assert.Equal(t, second.ClientOrderID, pending[1].ClientOrderID)
+
+	require.NoError(t, repo.UpdateEntryIntentStatus(ctx, first.ClientOrderID, domain.EntryIntentOpened))
+	require.NoError(t, repo.UpdateEntryIntentStatus(ctx, second.ClientOrderID, domain.EntryIntentFailed))
+	pending, err = repo.FindPendingEntryIntents(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	err = repo.UpdateEntryIntentStatus(ctx, "cmb-unknown", domain.EntryIntentFailed)
+	assert.ErrorIs(t, err, ports.ErrNotFound)
+}
+
+func TestRepository_SafeModeEvents(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	active, err := repo.FindActiveSafeModeEvent(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	start := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	event := &domain.SafeModeEvent{Symbol: "ETHUSDT", Reason: "exchange is under maintenance", StartedAt: start}
+	require.NoError(t, repo.SaveSafeModeEvent(ctx, event))
+	assert.NotZero(t, event.ID)
+	require.NoError(t, repo.SaveSafeModeEvent(ctx, &domain.SafeModeEvent{Symbol: "BTCUSDT", Reason: "other symbol", StartedAt: start}))
+
+	active, err = repo.FindActiveSafeModeEvent(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	assert.Equal(t, event.ID, active.ID)
+	assert.Equal(t, "exchange is under maintenance", active.Reason)
+	assert.True(t, active.StartedAt.Equal(start))
+	assert.True(t, active.Active())
+
+	require.NoError(t, repo.EndSafeModeEvent(ctx, event.ID, start.Add(30*time.Minute)))
+	active, err = repo.FindActiveSafeModeEvent(ctx, "ETHUSDT")
+	require.NoError(t, err)
+	assert.Nil(t, active, "ended events are not active")
+
+	err = repo.EndSafeModeEvent(ctx, 9999, start)
+	assert.ErrorIs(t, err, ports.ErrNotFound)
+}
+
+func TestRepository_OrderFills(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	fills, err := repo.FindOrderFills(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, fills)
+
+	at := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	entry := []*domain.OrderFill{
+		{PositionID: 1, OrderID: 10, TradeID: 101, Symbol: "ETHUSDT", Side: domain.Buy, Role: domain.FillRoleEntry,
+			Price: 2000, Quantity: 0.3, Commission: 0.24, CommissionAsset: "USDT", Time: at},
+		{PositionID: 1, OrderID: 10, TradeID: 102, Symbol: "ETHUSDT", Side: domain.Buy, Role: domain.FillRoleEntry,
+			Price: 2001, Quantity: 0.7, Commission: 0.56, CommissionAsset: "USDT", Time: at.Add(time.Millisecond)},
+	}
+	require.NoError(t, repo.SaveOrderFills(ctx, entry))
+	assert.NotZero(t, entry[0].ID)
+	// Saving the same executions again doesn't duplicate them
+	require.NoError(t, repo.SaveOrderFills(ctx, entry[:1]))
+	require.NoError(t, repo.SaveOrderFills(ctx, []*domain.OrderFill{
+		{PositionID: 1, OrderID: 11, TradeID: 103, Symbol: "ETHUSDT", Side: domain.Sell, Role: domain.FillRoleExit,
+			Price: 2050, Quantity: 1, Commission: 0.82, CommissionAsset: "USDT", Time: at.Add(time.Hour)},
+		{PositionID: 2, OrderID: 12, TradeID: 104, Symbol: "ETHUSDT", Side: domain.Buy, Role: domain.FillRoleEntry,
+			Price: 2100, Quantity: 1, Commission: 0.84, CommissionAsset: "USDT", Time: at.Add(2 * time.Hour)},
+	}))
+
+	fills, err = repo.FindOrderFills(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, fills, 3)
+	assert.Equal(t, int64(102), fills[1].TradeID)
+	assert.Equal(t, domain.FillRoleEntry, fills[1].Role)
+	assert.Equal(t, 0.7, fills[1].Quantity)
+	assert.True(t, fills[1].Time.Equal(at.Add(time.Millisecond)))
+	assert.Equal(t, domain.FillRoleExit, fills[2].Role)
+	assert.Equal(t, domain.Sell, fills[2].Side)
+}
+
+func TestRepository_Orders(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	at := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	orders := []*domain.Order{
+		{OrderID: 10, ClientOrderID: "entry-1", Symbol: "ETHUSDT", Side: domain.Buy, PositionSide: domain.PositionSideBoth,
+			Type: "MARKET", Purpose: domain.OrderPurposeEntry, Quantity: 1, Status: "FILLED", CreatedAt: at},
+		{OrderID: 11, Symbol: "ETHUSDT", Side: domain.Sell, PositionSide: domain.PositionSideBoth, Type: "STOP_MARKET",
+			Purpose: domain.OrderPurposeStopLoss, StopPrice: 1980, Status: "NEW", CreatedAt: at.Add(time.Second)},
+		{Symbol: "ETHUSDT", Side: domain.Buy, PositionSide: domain.PositionSideBoth, Type: "MARKET", Purpose: domain.OrderPurposeEntry,
+			Quantity: 1, Status: domain.OrderStatusRejected, Error: "API rate limit exceeded", CreatedAt: at.Add(time.Hour)},
+		{OrderID: 20, Symbol: "BTCUSDT", Side: domain.Buy, PositionSide: domain.PositionSideBoth, Type: "MARKET",
+			Purpose: domain.OrderPurposeEntry, Quantity: 0.1, Status: "FILLED", CreatedAt: at.Add(2 * time.Hour)},
+	}
+	for _, o := range orders {
+		require.NoError(t, repo.SaveOrder(ctx, o))
+		assert.NotZero(t, o.ID)
+	}
+	require.NoError(t, repo.AssignOrders(ctx, 7, []int64{10, 11, 0}))
+
+	all, total, err := repo.FindOrders(ctx, ports.OrderFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, all, 4)
+	assert.Equal(t, int64(20), all[0].OrderID, "newest first")
+
+	page, total, err := repo.FindOrders(ctx, ports.OrderFilter{Symbol: "ETHUSDT", Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, int64(11), page[0].OrderID)
+	assert.Equal(t, domain.OrderPurposeStopLoss, page[0].Purpose)
+	assert.Equal(t, 1980.0, page[0].StopPrice)
+	assert.Equal(t, int64(7), page[0].PositionID)
+
+	rejected, _, err := repo.FindOrders(ctx, ports.OrderFilter{Status: domain.OrderStatusRejected})
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Zero(t, rejected[0].PositionID, "rejected orders are never linked")
+	assert.Equal(t, "API rate limit exceeded", rejected[0].Error)
+
+	ofPosition, total, err := repo.FindOrders(ctx, ports.OrderFilter{PositionID: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "entry-1", ofPosition[1].ClientOrderID)
+
+	inRange, _, err := repo.FindOrders(ctx, ports.OrderFilter{From: at.Add(time.Second), To: at.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, inRange, 2)
+	assert.True(t, inRange[1].CreatedAt.Equal(at.Add(time.Second)))
+}
+
+func TestRepository_BacktestRuns(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	start := time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC)
+	newRun := func(tp float64, created time.Time) *domain.BacktestRun {
+		return &domain.BacktestRun{Strategy: "MACrossover", Symbol: "ETHUSDT", Interval: "15m",
+			Params: map[string]float64{"take_profit": tp, "stop_loss": 0.01}, DataStart: start, DataEnd: start.Add(90 * 24 * time.Hour),
+			Seed: 42, TotalTrades: 2, WinRate: 0.5, TotalProfit: 12.5, MaxDrawdown: 0.03, CreatedAt: created}
+	}
+	trades := []*domain.Trade{
+		{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000, ExitPrice: 2040, Quantity: 0.5, Leverage: 3, PNL: 20,
+			EntryTime: start.Add(time.Hour), ExitTime: start.Add(2 * time.Hour), CloseReason: domain.CloseReasonTakeProfit, MFE: 0.02,
+			Regime:   domain.MarketRegime{Trend: domain.TrendRegimeTrending, Volatility: domain.VolatilityRegimeNormal, HigherTF: domain.TrendDirectionUp},
+			EntryTag: domain.EntryTag{EntryReason: "crossover", SignalSource: domain.SignalSourceCrossover, ConfirmationCount: 3}},
+		{Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2050, ExitPrice: 2065, Quantity: 0.5, Leverage: 3, PNL: -7.5,
+			EntryTime: start.Add(5 * time.Hour), ExitTime: start.Add(6 * time.Hour), CloseReason: domain.CloseReasonStopLoss},
+	}
+
+	first := newRun(0.015, start.Add(100*24*time.Hour))
+	require.NoError(t, repo.SaveBacktestRun(ctx, first, trades))
+	assert.NotZero(t, first.ID)
+	assert.Len(t, first.ConfigHash, 12)
+	second := newRun(0.02, first.CreatedAt.Add(time.Minute))
+	require.NoError(t, repo.SaveBacktestRun(ctx, second, nil))
+	rerun := newRun(0.015, second.CreatedAt.Add(time.Minute))
+	require.NoError(t, repo.SaveBacktestRun(ctx, rerun, nil))
+	assert.Equal(t, first.ConfigHash, rerun.ConfigHash, "the same configuration hashes the same")
+	assert.NotEqual(t, first.ConfigHash, second.ConfigHash)
+
+	runs, err := repo.FindBacktestRuns(ctx, ports.BacktestRunFilter{})
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, []int64{rerun.ID, second.ID, first.ID}, []int64{runs[0].ID, runs[1].ID, runs[2].ID}, "newest first")
+	assert.Equal(t, 0.02, runs[1].Params["take_profit"])
+	assert.True(t, runs[2].DataStart.Equal(start))
+	assert.Equal(t, int64(42), runs[2].Seed)
+
+	runs, err = repo.FindBacktestRuns(ctx, ports.BacktestRunFilter{ConfigHash: first.ConfigHash, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, rerun.ID, runs[0].ID)
+	runs, err = repo.FindBacktestRuns(ctx, ports.BacktestRunFilter{Strategy: "Other"})
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	found, err := repo.FindBacktestTrades(ctx, first.ID)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, trades[0].Regime, found[0].Regime)
+	assert.Equal(t, trades[0].EntryTag, found[0].EntryTag)
+	assert.Equal(t, domain.CloseReasonTakeProfit, found[0].CloseReason)
+	assert.Equal(t, 3, found[0].Leverage)
+	assert.True(t, found[1].ExitTime.Equal(start.Add(6*time.Hour)))
+	assert.True(t, found[1].Regime.IsZero())
+
+	found, err = repo.FindBacktestTrades(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestRepository_Maintain(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	cutoff := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	klines := []*domain.Kline{
+		{OpenTime: cutoff.Add(-2 * time.Minute), CloseTime: cutoff.Add(-time.Minute - time.Millisecond), Open: 1, High: 1, Low: 1, Close: 1},
+		{OpenTime: cutoff, CloseTime: cutoff.Add(time.Minute - time.Millisecond), Open: 2, High: 2, Low: 2, Close: 2},
+	}
+	require.NoError(t, repo.SaveKlines(ctx, "ETHUSDT", "1m", klines))
+	for _, created := range []time.Time{cutoff.Add(-time.Hour), cutoff.Add(time.Hour)} {
+		require.NoError(t, repo.SaveOrder(ctx, &domain.Order{OrderID: created.Unix(), Symbol: "ETHUSDT", Side: domain.Buy,
+			PositionSide: domain.PositionSideBoth, Type: "MARKET", Purpose: domain.OrderPurposeEntry, Status: "FILLED", CreatedAt: created}))
+	}
+
+	report, err := repo.Maintain(ctx, ports.MaintenanceOptions{PruneBefore: cutoff, Vacuum: true, IntegrityCheck: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), report.PrunedKlines)
+	assert.Equal(t, int64(1), report.PrunedOrders)
+	assert.True(t, report.Vacuumed)
+	assert.True(t, report.IntegrityChecked)
+	assert.Empty(t, report.IntegrityErrors)
+	assert.False(t, report.WALBusy)
+	assert.Positive(t, report.SizeBytes)
+	assert.Zero(t, report.WALSizeBytes, "the checkpoint truncates the WAL")
+	assert.Equal(t, int64(1), report.TableRows["kline_cache"])
+	assert.Equal(t, int64(1), report.TableRows["orders"])
+	assert.Contains(t, report.TableRows, "positions")
+
+	remaining, err := repo.LoadKlines(ctx, "ETHUSDT", "1m")
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.True(t, remaining[0].OpenTime.Equal(cutoff))
+
+	// Without options only the checkpoint and the statistics run
+	report, err = repo.Maintain(ctx, ports.MaintenanceOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, report.PrunedKlines+report.PrunedOrders)
+	assert.False(t, report.Vacuumed || report.IntegrityChecked)
+	assert.Equal(t, int64(1), report.TableRows["orders"])
+}
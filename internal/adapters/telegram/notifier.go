@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// defaultBaseURL is the Telegram Bot API endpoint.
+const defaultBaseURL = "https://api.telegram.org"
+
+// Notifier sends messages to a Telegram chat through the Bot API (implements ports.Notifier).
+type Notifier struct {
+	botToken   string
+	chatID     string
+	baseURL    string
+	httpClient *http.Client
+	logger     ports.Logger
+}
+
+// Config holds configuration for the Telegram notifier.
+type Config struct {
+	BotToken   string       // Token issued by @BotFather
+	ChatID     string       // Chat, group or channel to post to
+	BaseURL    string       // Bot API endpoint (defaults to https://api.telegram.org)
+	HTTPClient *http.Client // Optional: defaults to a client with a 10s timeout
+	Logger     ports.Logger
+}
+
+// New creates a new Telegram notifier.
+func New(cfg Config) (*Notifier, error) {
+	if cfg.Logger == nil {
+		return nil, fmt.Errorf("logger is required for Telegram notifier")
+	}
+	if cfg.BotToken == "" {
+		return nil, fmt.Errorf("bot token is required for Telegram notifier")
+	}
+	if cfg.ChatID == "" {
+		return nil, fmt.Errorf("chat ID is required for Telegram notifier")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Notifier{
+		botToken:   cfg.BotToken,
+		chatID:     cfg.ChatID,
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		httpClient: cfg.HTTPClient,
+		logger:     cfg.Logger,
+	}, nil
+}
+
+// sendMessageRequest is the body of the Bot API sendMessage call.
+type sendMessageRequest struct {
+	ChatID string `json:"chat_id"`
+	Text   string `json:"text"`
+}
+
+// apiResponse is the common envelope of Bot API responses.
+type apiResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+// Notify sends the subject and message as a single plain-text chat message.
+func (n *Notifier) Notify(ctx context.Context, subject, message string) error {
+	text := message
+	if subject != "" {
+		text = subject + "\n\n" + message
+	}
+	body, err := json.Marshal(sendMessageRequest{ChatID: n.chatID, Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to encode Telegram message: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", n.baseURL, n.botToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Telegram request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		// The *url.Error message contains the request URL, which includes the bot token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("%w: Telegram request failed: %v", ports.ErrNotificationFailed, err)
+	}
+	defer resp.Body.Close()
+
+	var result apiResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode Telegram response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || !result.OK {
+		return fmt.Errorf("%w: Telegram rejected message (status %d): %s", ports.ErrNotificationFailed, resp.StatusCode, result.Description)
+	}
+
+	n.logger.Debug(ctx, "Telegram message sent", map[string]interface{}{"subject": subject})
+	return nil
+}
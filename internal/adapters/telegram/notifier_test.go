@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLogger implements ports.Logger for testing
+type mockLogger struct{}
+
+func (m *mockLogger) Debug(ctx context.Context, msg string, fields ...map[string]interface{}) {}
+func (m *mockLogger) Info(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Warn(ctx context.Context, msg string, fields ...map[string]interface{})  {}
+func (m *mockLogger) Error(ctx context.Context, err error, msg string, fields ...map[string]interface{}) {
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(Config{ChatID: "42", Logger: &mockLogger{}})
+	assert.Error(t, err)
+	_, err = New(Config{BotToken: "token", Logger: &mockLogger{}})
+	assert.Error(t, err)
+	_, err = New(Config{BotToken: "token", ChatID: "42"})
+	assert.Error(t, err)
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	var gotPath string
+	var gotBody sendMessageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer server.Close()
+
+	n, err := New(Config{BotToken: "123:abc", ChatID: "-10042", BaseURL: server.URL, Logger: &mockLogger{}})
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(context.Background(), "Daily report", "Trades: 3"))
+	assert.Equal(t, "/bot123:abc/sendMessage", gotPath)
+	assert.Equal(t, "-10042", gotBody.ChatID)
+	assert.Equal(t, "Daily report\n\nTrades: 3", gotBody.Text)
+}
+
+func TestNotifier_NotifyRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	n, err := New(Config{BotToken: "123:abc", ChatID: "1", BaseURL: server.URL, Logger: &mockLogger{}})
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), "subject", "message")
+	require.ErrorIs(t, err, ports.ErrNotificationFailed)
+	assert.Contains(t, err.Error(), "chat not found")
+}
+
+func TestNotifier_NotifyHidesToken(t *testing.T) {
+	n, err := New(Config{BotToken: "secret-token", ChatID: "1", BaseURL: "http://127.0.0.1:1", Logger: &mockLogger{}})
+	require.NoError(t, err)
+
+	err = n.Notify(context.Background(), "subject", "message")
+	require.ErrorIs(t, err, ports.ErrNotificationFailed)
+	assert.NotContains(t, err.Error(), "secret-token")
+}
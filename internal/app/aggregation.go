@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/utils"
+)
+
+// WithKlineAggregation builds the additional kline intervals from the primary 1m stream instead of
+// subscribing to a WebSocket stream per interval. Their initial klines are still loaded on Start;
+// closed 1m klines are then resampled into each interval and completed bars appended to its
+// cache. A bar whose first 1m klines are older than the initial kline cache is skipped, so the
+// first aggregated bar may arrive one period late.
+func WithKlineAggregation() Option {
+	return func(s *TradingService) {
+		s.aggregators = make(map[string]*utils.KlineAggregator)
+	}
+}
+
+// startAggregation creates an aggregator per additional interval and seeds it with the closed
+// klines of the primary cache, so the bar in progress is complete when it closes. Cached klines
+// of the intervals that haven't closed yet are dropped, as aggregation replaces them.
+func (s *TradingService) startAggregation(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, interval := range s.intervals {
+		aggregator, err := utils.NewKlineAggregator(primaryInterval, interval)
+		if err != nil {
+			return fmt.Errorf("failed to aggregate %s klines: %w", interval, err)
+		}
+		for _, k := range s.signals.Klines() {
+			if k.CloseTime.Before(now) {
+				aggregator.Add(k)
+			}
+		}
+		s.aggregators[interval] = aggregator
+		s.signals.SetTimeframe(interval, closedKlines(s.signals.Timeframe(interval), now))
+		s.logger.Info(ctx, "Aggregating timeframe klines from the primary stream", map[string]interface{}{"interval": interval})
+	}
+	return nil
+}
+
+// closedKlines drops the trailing klines that are still open at now.
+func closedKlines(klines []*domain.Kline, now time.Time) []*domain.Kline {
+	for len(klines) > 0 && !klines[len(klines)-1].CloseTime.Before(now) {
+		klines = klines[:len(klines)-1]
+	}
+	return klines
+}
+
+// aggregateKline adds a final primary kline to every aggregator and caches the bars it completes.
+// Assumes the caller holds the lock.
+func (s *TradingService) aggregateKline(kline *domain.Kline) {
+	for interval, aggregator := range s.aggregators {
+		bar := aggregator.Add(kline)
+		if bar == nil {
+			continue
+		}
+		cache := s.signals.Timeframe(interval)
+		if n := len(cache); n > 0 && !bar.OpenTime.After(cache[n-1].OpenTime) {
+			continue // Already loaded with the initial klines
+		}
+		s.signals.AddTimeframeKline(interval, bar)
+	}
+}
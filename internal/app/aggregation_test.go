@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+)
+
+func TestTradingService_KlineAggregation(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	strat := &mockMultiTimeframeStrategy{timeframes: []string{"15m"}}
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, strat,
+		WithKlineAggregation(), WithClock(clock.NewFake(start.Add(10*time.Minute+30*time.Second))))
+	require.NoError(t, err)
+
+	// Started mid-period: the primary cache holds the period's first minutes and the REST load
+	// returned the forming 15m bar
+	service.signals.klines = minuteKlines(start, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	previous := start.Add(-15 * time.Minute)
+	service.signals.timeframes["15m"] = []*domain.Kline{
+		{OpenTime: previous, CloseTime: start.Add(-time.Millisecond), Close: 1990, IsFinal: true},
+		{OpenTime: start, CloseTime: start.Add(15*time.Minute - time.Millisecond), Close: 1995, IsFinal: true},
+	}
+	require.NoError(t, service.startAggregation(context.Background()))
+	require.Len(t, service.signals.timeframes["15m"], 1, "the forming bar is dropped")
+
+	for _, k := range minuteKlines(start, 10, 11, 12, 13) {
+		service.handleKlineEvent(k)
+	}
+	assert.Len(t, service.signals.timeframes["15m"], 1, "no bar before the period closes")
+
+	last := minuteKlines(start, 14)[0]
+	last.Close = 2010
+	service.handleKlineEvent(last)
+	require.Len(t, service.signals.timeframes["15m"], 2)
+	bar := service.signals.timeframes["15m"][1]
+	assert.Equal(t, start, bar.OpenTime)
+	assert.Equal(t, "15m", bar.Interval)
+	assert.Equal(t, 2010.0, bar.Close)
+	assert.True(t, bar.IsFinal)
+	assert.Same(t, bar, strat.data["15m"][1], "the strategy sees the aggregated bar")
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/dataquality"
+	"cryptoMegaBot/internal/domain"
+)
+
+// WithKlineAnomalyDetection screens final primary klines with detector before they reach the
+// kline cache and the strategy. Anomalies are logged; depending on the detector's action the
+// kline is passed on, dropped or repaired. A warning notification is sent when anomalies cluster.
+// The detector is seeded with the initial klines on Start.
+func WithKlineAnomalyDetection(detector *dataquality.Detector) Option {
+	return func(s *TradingService) {
+		s.anomalyDetector = detector
+	}
+}
+
+// screenKline returns the kline to process in place of kline, or nil if it was dropped.
+// Assumes the caller holds the lock.
+func (s *TradingService) screenKline(ctx context.Context, kline *domain.Kline, now time.Time) *domain.Kline {
+	if s.anomalyDetector == nil {
+		return kline
+	}
+	result := s.anomalyDetector.Check(kline, now)
+	if len(result.Anomalies) == 0 {
+		return result.Kline
+	}
+
+	details := make([]string, len(result.Anomalies))
+	for i, a := range result.Anomalies {
+		details[i] = a.String()
+	}
+	action := s.anomalyDetector.Config().Action
+	if action == dataquality.ActionRepair && result.Kline == nil {
+		action = dataquality.ActionDrop // Couldn't be repaired
+	}
+	s.logger.Warn(ctx, "Anomalous kline", map[string]interface{}{
+		"symbol":    s.cfg.Symbol,
+		"openTime":  kline.OpenTime,
+		"close":     kline.Close,
+		"volume":    kline.Volume,
+		"anomalies": details,
+		"action":    string(action),
+	})
+
+	if result.Clustered {
+		cfg := s.anomalyDetector.Config()
+		body := fmt.Sprintf("Symbol: %s\n%d anomalous klines within %s\nLatest: %s\nAction: %s",
+			s.cfg.Symbol, s.anomalyDetector.RecentAnomalies(now), cfg.ClusterWindow, strings.Join(details, "; "), action)
+		s.notify(ctx, fmt.Sprintf("%s kline anomalies clustering", s.cfg.Symbol), body, nil)
+	}
+	return result.Kline
+}
+
+// seedAnomalyDetector feeds the initial klines to the detector, before the stream starts.
+func (s *TradingService) seedAnomalyDetector() {
+	if s.anomalyDetector != nil {
+		s.anomalyDetector.Seed(s.signals.Klines())
+	}
+}
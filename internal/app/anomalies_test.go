@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/dataquality"
+)
+
+func TestTradingService_KlineAnomalyDetection(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	start := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	detector, err := dataquality.NewDetector(dataquality.Config{Action: dataquality.ActionDrop, ClusterCount: 2})
+	require.NoError(t, err)
+	notifier := &mockNotifier{}
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+		WithKlineAnomalyDetection(detector), WithNotifier(notifier))
+	require.NoError(t, err)
+	service.signals.klines = minuteKlines(start, 0, 1, 2)
+	for _, k := range service.signals.klines {
+		k.Open, k.High, k.Low, k.Volume = k.Close, k.Close, k.Close, 1
+	}
+	service.seedAnomalyDetector()
+
+	zeroVolume := minuteKlines(start, 3)[0]
+	zeroVolume.Open, zeroVolume.High, zeroVolume.Low = 2000, 2000, 2000
+	service.handleKlineEvent(zeroVolume)
+	assert.Len(t, service.signals.klines, 3, "zero volume kline dropped")
+
+	duplicate := *service.signals.klines[2]
+	service.handleKlineEvent(&duplicate)
+	assert.Len(t, service.signals.klines, 3, "duplicate dropped")
+	assert.NotSame(t, &duplicate, service.signals.klines[2])
+
+	service.notifications.Wait()
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	require.Len(t, notifier.subjects, 1)
+	assert.Equal(t, "ETHUSDT kline anomalies clustering", notifier.subjects[0])
+	assert.Contains(t, notifier.messages[0], "DUPLICATE")
+}
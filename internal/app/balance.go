@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errInsufficientBalance is returned (wrapped) for entries skipped because the account can't
+// afford them.
+var errInsufficientBalance = errors.New("insufficient balance")
+
+// BalanceCheckConfig holds configuration for the pre-entry balance check.
+type BalanceCheckConfig struct {
+	Asset        string  // Margin asset whose available balance is checked (defaults to USDT)
+	SafetyBuffer float64 // Share of the balance kept free for fees and price moves (e.g., 0.05 for 5%)
+	MinBalance   float64 // Entries are skipped while the available balance is below this
+	Shrink       bool    // Shrink orders that don't fit to the affordable quantity instead of skipping them
+}
+
+// WithBalanceCheck fetches the available balance before each entry and computes the largest
+// quantity its margin covers at the configured leverage, keeping SafetyBuffer of it free. Orders
+// above that are shrunk to fit (with Shrink) or skipped. Entries also fail closed when the
+// balance can't be fetched.
+func WithBalanceCheck(cfg BalanceCheckConfig) Option {
+	return func(s *TradingService) {
+		if cfg.Asset == "" {
+			cfg.Asset = "USDT"
+		}
+		s.sizer.balanceCheck = &cfg
+	}
+}
+
+// affordableQuantity returns quantity, or the largest quantity the available balance affords at
+// price if that is smaller and shrinking is enabled. Returns an error wrapping
+// errInsufficientBalance if the entry should be skipped.
+func (z *EntrySizer) affordableQuantity(ctx context.Context, quantity, price float64) (float64, error) {
+	if z.balanceCheck == nil {
+		return quantity, nil
+	}
+	balance, err := z.exchange.GetAccountBalance(ctx, z.balanceCheck.Asset)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s balance before entry: %w", z.balanceCheck.Asset, err)
+	}
+	if balance < z.balanceCheck.MinBalance || balance <= 0 {
+		return 0, fmt.Errorf("%w: available %.2f %s is below the minimum of %.2f", errInsufficientBalance, balance, z.balanceCheck.Asset, z.balanceCheck.MinBalance)
+	}
+
+	leverage := z.Leverage()
+	if leverage < 1 {
+		leverage = 1
+	}
+	usable := balance * (1 - z.balanceCheck.SafetyBuffer)
+	// Margin is the notional in the margin asset over the leverage: USDT, or coin for COIN-margined contracts
+	affordable := z.cfg.OrderPrecision().RoundQuantity(usable * float64(leverage) / z.cfg.Contract.Notional(price, 1))
+	if quantity <= affordable {
+		return quantity, nil
+	}
+	required := z.cfg.Contract.Notional(price, quantity) / float64(leverage)
+	if !z.balanceCheck.Shrink || affordable <= 0 {
+		return 0, fmt.Errorf("%w: entry of %g at %.2f needs %.2f %s margin, %.2f usable of %.2f available",
+			errInsufficientBalance, quantity, price, required, z.balanceCheck.Asset, usable, balance)
+	}
+	z.logger.Warn(ctx, "Shrinking entry to the affordable quantity", map[string]interface{}{
+		"quantity":       quantity,
+		"affordable":     affordable,
+		"price":          price,
+		"leverage":       leverage,
+		"requiredMargin": required,
+		"balance":        balance,
+		"safetyBuffer":   z.balanceCheck.SafetyBuffer,
+	})
+	return affordable, nil
+}
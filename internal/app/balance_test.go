@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_BalanceCheck(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 5}
+	newService := func(t *testing.T, balance float64, check BalanceCheckConfig) (*TradingService, *mockExchange) {
+		exchange := &mockExchange{
+			balance: balance,
+			orderResponses: map[string]*ports.OrderResponse{
+				"market_BUY": {OrderID: 1, AvgPrice: 2000},
+				"stop_SELL":  {OrderID: 2},
+				"tp_SELL":    {OrderID: 3},
+			},
+		}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithBalanceCheck(check))
+		require.NoError(t, err)
+		return service, exchange
+	}
+	ctx := context.Background()
+
+	t.Run("affordable entry keeps its quantity", func(t *testing.T) {
+		service, exchange := newService(t, 1000, BalanceCheckConfig{SafetyBuffer: 0.05, Shrink: true})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "1.000", exchange.marketOrderQty)
+		assert.Equal(t, 1.0, service.positions.long.Quantity)
+	})
+
+	t.Run("entry is shrunk to the affordable quantity", func(t *testing.T) {
+		// 200 USDT less 5% at 5x leverage buys 0.475 ETH at 2000
+		service, exchange := newService(t, 200, BalanceCheckConfig{SafetyBuffer: 0.05, Shrink: true})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "0.475", exchange.marketOrderQty)
+		assert.Equal(t, 0.475, service.positions.long.Quantity)
+	})
+
+	t.Run("entry is skipped without shrinking", func(t *testing.T) {
+		service, exchange := newService(t, 200, BalanceCheckConfig{SafetyBuffer: 0.05})
+		err := service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now())
+		assert.ErrorIs(t, err, errInsufficientBalance)
+		assert.Empty(t, exchange.marketOrderQty)
+		assert.Nil(t, service.positions.long)
+	})
+
+	t.Run("entry is skipped below the minimum balance", func(t *testing.T) {
+		service, exchange := newService(t, 50, BalanceCheckConfig{MinBalance: 100, Shrink: true})
+		err := service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now())
+		assert.ErrorIs(t, err, errInsufficientBalance)
+		assert.Empty(t, exchange.marketOrderQty)
+	})
+
+	t.Run("entry fails closed when the balance is unavailable", func(t *testing.T) {
+		service, exchange := newService(t, 0, BalanceCheckConfig{Shrink: true})
+		exchange.balanceErr = ports.ErrExchangeUnavailable
+		err := service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now())
+		assert.ErrorIs(t, err, ports.ErrExchangeUnavailable)
+		assert.Empty(t, exchange.marketOrderQty)
+	})
+}
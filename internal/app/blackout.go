@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"cryptoMegaBot/internal/risk"
+)
+
+// WithBlackoutSchedule refuses new entries during the schedule's blackout windows (e.g., around
+// CPI or FOMC releases) and, if the schedule sets tighten_stop, pulls the stops of open positions
+// closer to the price while a window is active. The schedule must be validated.
+func WithBlackoutSchedule(schedule *risk.BlackoutSchedule) Option {
+	return func(s *TradingService) {
+		s.gate.blackout = schedule
+	}
+}
+
+// tightenStopsForBlackout moves the in-memory stop of each open position closer to price while
+// a blackout is active, so the strategy's exit check closes it sooner. The stop order already
+// placed on the exchange is left as the hard backstop.
+// Assumes the caller holds the lock.
+func (s *TradingService) tightenStopsForBlackout(ctx context.Context, price float64, now time.Time) {
+	active, name := s.gate.blackout.Active(now)
+	if !active {
+		return
+	}
+	for _, pos := range s.positions.OpenPositions() {
+		stop, ok := s.gate.blackout.TightenedStop(pos, price)
+		if !ok {
+			continue
+		}
+		s.logger.Info(ctx, "Tightening stop loss during blackout", map[string]interface{}{
+			"positionID": pos.ID,
+			"side":       pos.PositionSide(),
+			"blackout":   name,
+			"oldStop":    pos.StopLoss,
+			"newStop":    stop,
+		})
+		pos.StopLoss = stop
+		if err := s.positions.Save(ctx, pos); err != nil {
+			s.logger.Error(ctx, err, "Failed to save tightened stop loss", map[string]interface{}{"positionID": pos.ID})
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_Blackout(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	now := time.Now()
+	newService := func(t *testing.T, schedule *risk.BlackoutSchedule) (*TradingService, *mockPositionRepo) {
+		require.NoError(t, schedule.Validate())
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, posRepo, &mockTradeRepo{}, &mockStrategy{},
+			WithBlackoutSchedule(schedule))
+		require.NoError(t, err)
+		return service, posRepo
+	}
+
+	t.Run("active window blocks entries and tightens stops", func(t *testing.T) {
+		service, posRepo := newService(t, &risk.BlackoutSchedule{
+			TightenStop: 0.01,
+			Events:      []risk.BlackoutEvent{{Name: "FOMC", At: now.Add(10 * time.Minute), Before: time.Hour}},
+		})
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "blackout: FOMC", reason)
+		assert.Equal(t, "FOMC", service.Status(context.Background()).Blackout)
+
+		pos := &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 2000, StopLoss: 1900, Status: domain.StatusOpen}
+		service.positions.long = pos
+		service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Close: 2100, CloseTime: now, IsFinal: true})
+		assert.InDelta(t, 2079, pos.StopLoss, 1e-9)
+		assert.Same(t, pos, posRepo.positions["ETHUSDT"])
+	})
+
+	t.Run("outside the window trading is allowed", func(t *testing.T) {
+		service, _ := newService(t, &risk.BlackoutSchedule{
+			TightenStop: 0.01,
+			Events:      []risk.BlackoutEvent{{Name: "CPI", At: now.Add(2 * time.Hour), Before: 30 * time.Minute}},
+		})
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		assert.Empty(t, service.Status(context.Background()).Blackout)
+
+		pos := &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 2000, StopLoss: 1900, Status: domain.StatusOpen}
+		service.positions.long = pos
+		service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Close: 2100, CloseTime: now, IsFinal: true})
+		assert.Equal(t, 1900.0, pos.StopLoss)
+	})
+}
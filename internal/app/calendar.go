@@ -0,0 +1,15 @@
+package app
+
+import (
+	"cryptoMegaBot/internal/risk"
+)
+
+// WithTradingCalendar scales the quantity of new entries and scale-in adds by the calendar's factor
+// for the current UTC weekday or date, e.g. half size on weekends, and refuses new entries on the
+// days it disables (factor 0). Open positions are managed as usual on those days.
+func WithTradingCalendar(calendar *risk.TradingCalendar) Option {
+	return func(s *TradingService) {
+		s.gate.calendar = calendar
+		s.sizer.calendar = calendar
+	}
+}
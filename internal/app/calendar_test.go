@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_TradingCalendar(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	calendar, err := risk.ParseTradingCalendar("SAT:0.5,SUN:0,2025-12-25:0")
+	require.NoError(t, err)
+	ctx := context.Background()
+	newService := func(t *testing.T, now time.Time) (*TradingService, *mockExchange) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY": {OrderID: 1, AvgPrice: 2000},
+			"stop_SELL":  {OrderID: 2},
+			"tp_SELL":    {OrderID: 3},
+		}}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithTradingCalendar(calendar), WithClock(clock.NewFake(now)))
+		require.NoError(t, err)
+		return service, exchange
+	}
+
+	t.Run("weekday at full size", func(t *testing.T) {
+		service, exchange := newService(t, time.Date(2025, 12, 24, 12, 0, 0, 0, time.UTC))
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "1.000", exchange.marketOrderQty)
+	})
+
+	t.Run("reduced size on Saturday", func(t *testing.T) {
+		service, exchange := newService(t, time.Date(2025, 12, 27, 12, 0, 0, 0, time.UTC))
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "0.500", exchange.marketOrderQty)
+	})
+
+	t.Run("no entries on a disabled weekday or holiday", func(t *testing.T) {
+		service, _ := newService(t, time.Date(2025, 12, 21, 12, 0, 0, 0, time.UTC))
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "trading calendar: no entries on Sunday", reason)
+
+		service, _ = newService(t, time.Date(2025, 12, 25, 12, 0, 0, 0, time.UTC))
+		ok, reason = service.gate.Allow(domain.PositionSideShort)
+		assert.False(t, ok)
+		assert.Equal(t, "trading calendar: no entries on 2025-12-25", reason)
+	})
+}
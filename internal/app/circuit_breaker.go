@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// WithCircuitBreaker stops new entries after repeated order failures or rate-limit errors. While
+// the breaker is open, exits and protective orders of open positions are still placed; once its
+// open period ends, the next order is a probe that closes the breaker if it succeeds.
+func WithCircuitBreaker(breaker *risk.CircuitBreaker) Option {
+	return func(s *TradingService) {
+		s.gate.breaker = breaker
+	}
+}
+
+// recordOrderResult feeds the result of an order placement to the circuit breaker, and logs and
+// notifies when that opens or closes it.
+func (s *TradingService) recordOrderResult(ctx context.Context, err error) {
+	if s.gate.breaker == nil {
+		return
+	}
+	now := s.now()
+	from, to := s.gate.breaker.Record(err, now)
+	if from == to {
+		return
+	}
+	status := s.gate.breaker.Status(now)
+	switch to {
+	case risk.BreakerOpen:
+		s.logger.Warn(ctx, "Order circuit breaker opened, new entries stopped", map[string]interface{}{
+			"symbol":    s.cfg.Symbol,
+			"from":      string(from),
+			"lastError": status.LastError,
+			"probeAt":   status.ProbeAt,
+			"trips":     status.Trips,
+		})
+		s.publish(ctx, ports.Event{Type: ports.EventRiskLimitBreached, Reason: "circuit breaker open: " + status.LastError})
+		body := fmt.Sprintf("Symbol: %s\nLast error: %s\nNew entries are stopped until a probe order succeeds after %s UTC. Exits are still managed.",
+			s.cfg.Symbol, status.LastError, status.ProbeAt.UTC().Format("15:04:05"))
+		s.notify(ctx, fmt.Sprintf("%s order circuit breaker open", s.cfg.Symbol), body, nil)
+	case risk.BreakerClosed:
+		s.logger.Info(ctx, "Order circuit breaker closed, new entries allowed", map[string]interface{}{
+			"symbol": s.cfg.Symbol,
+			"from":   string(from),
+		})
+		s.notify(ctx, fmt.Sprintf("%s order circuit breaker closed", s.cfg.Symbol),
+			fmt.Sprintf("Symbol: %s\nAn order succeeded, new entries are allowed again", s.cfg.Symbol), nil)
+	}
+}
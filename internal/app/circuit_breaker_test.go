@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_CircuitBreaker(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 1}
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	breaker, err := risk.NewCircuitBreaker(risk.CircuitBreakerConfig{MaxFailures: 2, Window: time.Minute, OpenFor: 5 * time.Minute})
+	require.NoError(t, err)
+	exchange := &mockExchange{
+		markPrice:   2000,
+		orderErrors: map[string]error{"market_BUY": fmt.Errorf("place order: %w", ports.ErrExchangeUnavailable)},
+	}
+	notifier := &mockNotifier{}
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+		&mockTradeRepo{}, &mockStrategy{}, WithCircuitBreaker(breaker), WithClock(fake), WithNotifier(notifier))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		require.Error(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, now))
+	}
+	ok, reason := service.gate.Allow(domain.PositionSideLong)
+	assert.False(t, ok)
+	assert.Contains(t, reason, "circuit breaker open")
+	status := service.Status(ctx).CircuitBreaker
+	require.NotNil(t, status)
+	assert.Equal(t, "OPEN", status.State)
+
+	// Exits are still placed while the breaker is open
+	exchange.orderResponses = map[string]*ports.OrderResponse{"market_SELL": {OrderID: 9, AvgPrice: 2001}}
+	service.positions.long = &domain.Position{ID: 1, Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 1990, Quantity: 0.1, Status: domain.StatusOpen}
+	closed, err := service.ForceClose(ctx, domain.PositionSideLong)
+	require.NoError(t, err)
+	require.Len(t, closed, 1)
+	assert.Equal(t, "OPEN", service.Status(ctx).CircuitBreaker.State, "only a probe closes the breaker")
+
+	// Once the open period ends, a successful order closes it
+	fake.Advance(6 * time.Minute)
+	ok, _ = service.gate.Allow(domain.PositionSideLong)
+	assert.True(t, ok)
+	service.positions.short = &domain.Position{ID: 2, Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2010, Quantity: 0.1, Status: domain.StatusOpen}
+	exchange.orderErrors = nil
+	exchange.orderResponses["market_BUY"] = &ports.OrderResponse{OrderID: 10, AvgPrice: 2000}
+	_, err = service.ForceClose(ctx, domain.PositionSideShort)
+	require.NoError(t, err)
+	assert.Equal(t, "CLOSED", service.Status(ctx).CircuitBreaker.State)
+
+	service.notifications.Wait()
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	assert.Contains(t, notifier.subjects, "ETHUSDT order circuit breaker open")
+	assert.Contains(t, notifier.subjects, "ETHUSDT order circuit breaker closed")
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// ClockMonitorConfig holds configuration for the clock drift monitor.
+type ClockMonitorConfig struct {
+	CheckInterval time.Duration // How often local and exchange time are compared
+	MaxDrift      time.Duration // Drift since the last sync that triggers a resync
+}
+
+// ClockMonitor periodically compares local and exchange time and resynchronizes the
+// exchange client when the clocks drift apart, preventing timestamp (-1021) rejections.
+type ClockMonitor struct {
+	cfg      ClockMonitorConfig
+	logger   ports.Logger
+	exchange ports.ExchangeClient
+	now      func() time.Time // Overridable for tests
+	checkCh  chan struct{}    // Requests an immediate check
+
+	mu     sync.Mutex
+	offset time.Duration // Exchange minus local time when the client was last synchronized
+	synced bool          // Whether offset has been measured
+	status ports.ClockStatus
+}
+
+// NewClockMonitor creates a new clock drift monitor.
+func NewClockMonitor(cfg ClockMonitorConfig, logger ports.Logger, exchange ports.ExchangeClient) (*ClockMonitor, error) {
+	if logger == nil || exchange == nil {
+		return nil, fmt.Errorf("missing required dependencies for ClockMonitor")
+	}
+	if cfg.CheckInterval <= 0 {
+		return nil, fmt.Errorf("clock check interval must be positive")
+	}
+	if cfg.MaxDrift <= 0 {
+		return nil, fmt.Errorf("maximum clock drift must be positive")
+	}
+
+	return &ClockMonitor{
+		cfg:      cfg,
+		logger:   logger,
+		exchange: exchange,
+		now:      time.Now,
+		checkCh:  make(chan struct{}, 1),
+		status:   ports.ClockStatus{MaxDriftMs: cfg.MaxDrift.Milliseconds()},
+	}, nil
+}
+
+// Run checks drift every CheckInterval (and whenever RequestCheck is called) until ctx is canceled.
+// The exchange client is expected to have been synchronized before Run starts.
+func (c *ClockMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	c.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-c.checkCh:
+		}
+		c.check(ctx)
+	}
+}
+
+// RequestCheck asks Run to measure drift right away, e.g. after the exchange rejected a
+// request because of its timestamp. It never blocks.
+func (c *ClockMonitor) RequestCheck() {
+	select {
+	case c.checkCh <- struct{}{}:
+	default: // A check is already pending
+	}
+}
+
+// Status returns a snapshot of the latest drift measurement.
+func (c *ClockMonitor) Status() ports.ClockStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// check logs failures of Check; Run keeps going so a transient error doesn't stop monitoring.
+func (c *ClockMonitor) check(ctx context.Context) {
+	if err := c.Check(ctx); err != nil && ctx.Err() == nil {
+		c.logger.Error(ctx, err, "Clock drift check failed")
+	}
+}
+
+// Check measures the drift since the last sync and resynchronizes the exchange client
+// when it exceeds MaxDrift. The first measurement only records the baseline offset.
+func (c *ClockMonitor) Check(ctx context.Context) error {
+	offset, roundTrip, err := c.measureOffset(ctx)
+	if err != nil {
+		c.recordError(err)
+		return fmt.Errorf("failed to measure clock drift: %w", err)
+	}
+
+	c.mu.Lock()
+	if !c.synced {
+		c.offset = offset
+		c.synced = true
+	}
+	drift := offset - c.offset
+	c.status.DriftMs = drift.Milliseconds()
+	c.status.RoundTripMs = roundTrip.Milliseconds()
+	c.status.LastCheck = c.now()
+	c.status.LastError = ""
+	c.mu.Unlock()
+
+	c.logger.Debug(ctx, "Clock drift measured", map[string]interface{}{
+		"driftMs":     drift.Milliseconds(),
+		"roundTripMs": roundTrip.Milliseconds(),
+	})
+	if drift <= c.cfg.MaxDrift && drift >= -c.cfg.MaxDrift {
+		return nil
+	}
+
+	c.logger.Warn(ctx, "Clock drift exceeds tolerance, resynchronizing with exchange", map[string]interface{}{
+		"driftMs":    drift.Milliseconds(),
+		"maxDriftMs": c.cfg.MaxDrift.Milliseconds(),
+	})
+	if err := c.exchange.SetServerTime(ctx); err != nil {
+		c.recordError(err)
+		return fmt.Errorf("failed to resynchronize server time: %w", err)
+	}
+
+	c.mu.Lock()
+	c.offset = offset
+	c.status.DriftMs = 0
+	c.status.LastResync = c.now()
+	c.status.Resyncs++
+	c.mu.Unlock()
+
+	c.logger.Info(ctx, "Server time resynchronized", map[string]interface{}{"offsetMs": offset.Milliseconds()})
+	return nil
+}
+
+// measureOffset returns exchange minus local time, assuming the server timestamp was taken
+// halfway through the request, along with the request round trip.
+func (c *ClockMonitor) measureOffset(ctx context.Context) (time.Duration, time.Duration, error) {
+	sent := c.now()
+	serverTime, err := c.exchange.GetServerTime(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	received := c.now()
+
+	roundTrip := received.Sub(sent)
+	return serverTime.Sub(sent.Add(roundTrip / 2)), roundTrip, nil
+}
+
+// recordError stores the error of a failed check for the status snapshot.
+func (c *ClockMonitor) recordError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status.LastCheck = c.now()
+	c.status.LastError = err.Error()
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/ports"
+)
+
+// driftingExchange reports a server time that runs ahead of the local clock by drift
+type driftingExchange struct {
+	mockExchange
+	local     time.Time
+	drift     time.Duration
+	syncCalls int
+	syncErr   error
+	timeErr   error
+}
+
+func (m *driftingExchange) GetServerTime(ctx context.Context) (time.Time, error) {
+	return m.local.Add(m.drift), m.timeErr
+}
+
+func (m *driftingExchange) SetServerTime(ctx context.Context) error {
+	m.syncCalls++
+	return m.syncErr
+}
+
+func TestNewClockMonitor(t *testing.T) {
+	_, err := NewClockMonitor(ClockMonitorConfig{CheckInterval: time.Minute}, &mockLogger{}, &mockExchange{})
+	assert.Error(t, err)
+	_, err = NewClockMonitor(ClockMonitorConfig{MaxDrift: time.Second}, &mockLogger{}, &mockExchange{})
+	assert.Error(t, err)
+	_, err = NewClockMonitor(ClockMonitorConfig{CheckInterval: time.Minute, MaxDrift: time.Second}, nil, &mockExchange{})
+	assert.Error(t, err)
+}
+
+func TestClockMonitor_Check(t *testing.T) {
+	local := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	exchange := &driftingExchange{local: local, drift: 2 * time.Second}
+	monitor, err := NewClockMonitor(ClockMonitorConfig{CheckInterval: time.Minute, MaxDrift: 500 * time.Millisecond}, &mockLogger{}, exchange)
+	require.NoError(t, err)
+	monitor.now = func() time.Time { return exchange.local }
+
+	// The first check records the offset at startup as the baseline
+	require.NoError(t, monitor.Check(context.Background()))
+	assert.Equal(t, int64(0), monitor.Status().DriftMs)
+	assert.Equal(t, 0, exchange.syncCalls)
+
+	// Drift within tolerance is reported without resyncing
+	exchange.drift += 300 * time.Millisecond
+	require.NoError(t, monitor.Check(context.Background()))
+	assert.Equal(t, int64(300), monitor.Status().DriftMs)
+	assert.Equal(t, 0, exchange.syncCalls)
+
+	// Drift beyond tolerance in either direction triggers a resync
+	exchange.drift -= 900 * time.Millisecond
+	require.NoError(t, monitor.Check(context.Background()))
+	status := monitor.Status()
+	assert.Equal(t, 1, exchange.syncCalls)
+	assert.Equal(t, 1, status.Resyncs)
+	assert.Equal(t, int64(0), status.DriftMs)
+	assert.Equal(t, local, status.LastResync)
+
+	// The resync becomes the new baseline
+	require.NoError(t, monitor.Check(context.Background()))
+	assert.Equal(t, 1, exchange.syncCalls)
+}
+
+func TestClockMonitor_CheckErrors(t *testing.T) {
+	exchange := &driftingExchange{local: time.Now(), timeErr: ports.ErrExchangeUnavailable}
+	monitor, err := NewClockMonitor(ClockMonitorConfig{CheckInterval: time.Minute, MaxDrift: time.Second}, &mockLogger{}, exchange)
+	require.NoError(t, err)
+
+	err = monitor.Check(context.Background())
+	require.ErrorIs(t, err, ports.ErrExchangeUnavailable)
+	assert.Contains(t, monitor.Status().LastError, "unavailable")
+
+	// A successful check clears the error
+	exchange.timeErr = nil
+	require.NoError(t, monitor.Check(context.Background()))
+	assert.Empty(t, monitor.Status().LastError)
+
+	// Failed resyncs are reported and retried on the next check
+	exchange.drift = 5 * time.Second
+	exchange.syncErr = ports.ErrExchangeUnavailable
+	require.Error(t, monitor.Check(context.Background()))
+	require.Error(t, monitor.Check(context.Background()))
+	assert.Equal(t, 2, exchange.syncCalls)
+	assert.Equal(t, 0, monitor.Status().Resyncs)
+}
+
+func TestTradingService_ClockSkewTriggersCheck(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	monitor, err := NewClockMonitor(ClockMonitorConfig{CheckInterval: time.Hour, MaxDrift: time.Second}, &mockLogger{}, &mockExchange{})
+	require.NoError(t, err)
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+		WithClockMonitor(monitor))
+	require.NoError(t, err)
+
+	service.resyncOnClockSkew(fmt.Errorf("failed to place entry order: %w", ports.ErrOrderPlacementFailed))
+	assert.Len(t, monitor.checkCh, 0)
+
+	service.resyncOnClockSkew(fmt.Errorf("failed to place entry order: %w", ports.ErrClockSkew))
+	service.resyncOnClockSkew(fmt.Errorf("failed to place entry order: %w", ports.ErrClockSkew))
+	assert.Len(t, monitor.checkCh, 1, "pending checks are coalesced")
+
+	assert.NotNil(t, service.Status(context.Background()).Clock)
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// Status returns a snapshot of the current trading state (implements ports.TradingController).
+func (s *TradingService) Status(ctx context.Context) ports.TradingStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	status := ports.TradingStatus{
+		Symbol:          s.cfg.Symbol,
+		HasOpenPosition: len(s.positions.OpenPositions()) > 0,
+		MaxOrders:       s.cfg.MaxOrders,
+		Timestamp:       now,
+	}
+	s.gate.addStatus(&status, now)
+	if s.clock != nil {
+		clock := s.clock.Status()
+		status.Clock = &clock
+	}
+	status.Strategy = s.strategyStatus()
+	status.Stream = s.streamStatus()
+	status.Reconnects = s.reconnectStatus()
+	status.Latency = s.latencyStatus()
+	return status
+}
+
+// ResumeTrading lifts an operator pause, clears a tripped kill switch and unlocks a locked-in equity
+// trail so new entries are allowed again (implements ports.TradingController).
+func (s *TradingService) ResumeTrading(ctx context.Context) error {
+	s.mu.Lock()
+	paused, err := s.gate.Resume()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	s.logger.Warn(ctx, "Trading manually resumed, new entries allowed", map[string]interface{}{
+		"symbol":         s.cfg.Symbol,
+		"operatorPaused": paused,
+	})
+	return nil
+}
+
+// PauseTrading stops new entries until ResumeTrading (implements ports.TradingController). Open
+// positions are still managed and closed by the strategy and their protective orders.
+func (s *TradingService) PauseTrading(ctx context.Context, reason string) error {
+	if reason == "" {
+		reason = "no reason given"
+	}
+	s.mu.Lock()
+	s.gate.Pause(reason)
+	s.mu.Unlock()
+	s.logger.Warn(ctx, "Trading paused by operator, new entries refused", map[string]interface{}{
+		"symbol": s.cfg.Symbol,
+		"reason": reason,
+	})
+	s.notify(ctx, "Trading paused", fmt.Sprintf("Symbol: %s\nReason: %s", s.cfg.Symbol, reason), nil)
+	return nil
+}
+
+// OpenPositions returns copies of the open positions (implements ports.TradingController).
+func (s *TradingService) OpenPositions(ctx context.Context) []domain.Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var positions []domain.Position
+	for _, pos := range s.positions.OpenPositions() {
+		positions = append(positions, *pos)
+	}
+	return positions
+}
+
+// ForceClose closes the open positions on side, or every open position if side is empty, at
+// market with reason MANUAL (implements ports.TradingController). Returns ports.ErrNotFound if
+// there is no such position.
+func (s *TradingService) ForceClose(ctx context.Context, side domain.PositionSide) ([]domain.Position, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var closed []domain.Position
+	for _, pos := range s.positions.OpenPositions() {
+		if side != "" && pos.PositionSide() != side {
+			continue
+		}
+		price, err := s.exchange.GetMarkPrice(ctx, s.cfg.Symbol)
+		if err != nil {
+			return closed, fmt.Errorf("failed to get price to close %s position: %w", pos.PositionSide(), err)
+		}
+		s.logger.Warn(ctx, "Force closing position on operator request", map[string]interface{}{
+			"positionID": pos.ID,
+			"side":       pos.PositionSide(),
+			"price":      price,
+		})
+		if err := s.closePosition(ctx, pos, price, domain.CloseReasonManual); err != nil {
+			return closed, fmt.Errorf("failed to close %s position: %w", pos.PositionSide(), err)
+		}
+		closed = append(closed, *pos)
+	}
+	if len(closed) == 0 {
+		return nil, fmt.Errorf("no open position to close: %w", ports.ErrNotFound)
+	}
+	return closed, nil
+}
+
+// updateEquity feeds the current realized+unrealized equity into the entry gate's kill switch and
+// the sizer's drawdown throttle, and logs when the kill switch trips. Assumes the mutex `s.mu` is
+// already locked by the caller.
+func (s *TradingService) updateEquity(ctx context.Context, currentPrice float64) {
+	equity := s.currentEquity(currentPrice)
+	s.sizer.UpdateEquity(ctx, equity)
+	if tripped, ks := s.gate.UpdateEquity(equity); tripped {
+		s.logger.Warn(ctx, "Kill switch tripped, pausing new entries", map[string]interface{}{
+			"symbol":     s.cfg.Symbol,
+			"reason":     ks.Reason,
+			"equity":     ks.CurrentEquity,
+			"peakEquity": ks.PeakEquity,
+			"drawdown":   ks.Drawdown,
+			"losingDays": ks.LosingDays,
+			"resumeAt":   ks.ResumeAt,
+		})
+		s.publish(ctx, ports.Event{Type: ports.EventRiskLimitBreached, Reason: "kill switch: " + ks.Reason})
+	}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_OperatorControls(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	newService := func(t *testing.T, exchange *mockExchange) *TradingService {
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{})
+		require.NoError(t, err)
+		return service
+	}
+
+	t.Run("pause refuses entries until resumed", func(t *testing.T) {
+		service := newService(t, &mockExchange{})
+		require.NoError(t, service.PauseTrading(ctx, "risk review"))
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "paused by operator: risk review", reason)
+		assert.Equal(t, "risk review", service.Status(ctx).Paused)
+
+		require.NoError(t, service.ResumeTrading(ctx))
+		ok, _ = service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		assert.ErrorIs(t, service.ResumeTrading(ctx), ports.ErrConfigurationError, "nothing left to resume")
+	})
+
+	t.Run("force close one side", func(t *testing.T) {
+		exchange := &mockExchange{markPrice: 2000, orderResponses: map[string]*ports.OrderResponse{"market_BUY": {OrderID: 4, AvgPrice: 2005}}}
+		service := newService(t, exchange)
+		service.positions.long = &domain.Position{ID: 1, Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 1950, Quantity: 0.1, Status: domain.StatusOpen}
+		service.positions.short = &domain.Position{ID: 2, Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2100, Quantity: 0.1, Status: domain.StatusOpen}
+		require.Len(t, service.OpenPositions(ctx), 2)
+
+		closed, err := service.ForceClose(ctx, domain.PositionSideShort)
+		require.NoError(t, err)
+		require.Len(t, closed, 1)
+		assert.Equal(t, int64(2), closed[0].ID)
+		assert.Equal(t, domain.StatusClosed, closed[0].Status)
+		assert.Equal(t, domain.CloseReasonManual, closed[0].CloseReason)
+		assert.Nil(t, service.positions.short)
+		assert.NotNil(t, service.positions.long)
+
+		_, err = service.ForceClose(ctx, domain.PositionSideShort)
+		assert.ErrorIs(t, err, ports.ErrNotFound)
+	})
+}
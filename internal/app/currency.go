@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// currencyRateTTL is how long a conversion rate is reused before the tickers are fetched again.
+const currencyRateTTL = time.Minute
+
+// currencyBridges are the assets a conversion goes through when the exchange lists no pair of
+// the two currencies, e.g. USDT→BTC→EUR.
+var currencyBridges = []string{"BTC", "USDT", "ETH"}
+
+// conversionLeg is one ticker of a conversion path: the rate is the ticker price, or its inverse
+// if the pair is quoted the other way round.
+type conversionLeg struct {
+	symbol  string
+	inverse bool
+}
+
+// CurrencyConverter converts amounts from the account's quote asset (e.g., USDT) to a reporting
+// currency (e.g., EUR) at exchange ticker rates, for users who account in another currency. It
+// uses a pair of the two currencies in either direction, or a bridge through BTC, USDT or ETH,
+// and reuses a rate for a minute. It is safe for concurrent use.
+type CurrencyConverter struct {
+	prices ports.TickerPriceSource
+	from   string
+	to     string
+	now    func() time.Time // Overridable for tests
+
+	mu     sync.Mutex
+	path   []conversionLeg // Tickers of the last working conversion; nil until found
+	rate   float64
+	rateAt time.Time
+}
+
+// NewCurrencyConverter creates a converter from the from asset to the to currency.
+func NewCurrencyConverter(prices ports.TickerPriceSource, from, to string) (*CurrencyConverter, error) {
+	from, to = strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to))
+	if prices == nil {
+		return nil, fmt.Errorf("currency converter needs a ticker price source")
+	}
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("currency converter needs both currencies, got %q and %q", from, to)
+	}
+	return &CurrencyConverter{prices: prices, from: from, to: to, now: time.Now}, nil
+}
+
+// From returns the asset amounts are converted from.
+func (c *CurrencyConverter) From() string {
+	return c.from
+}
+
+// Currency returns the reporting currency amounts are converted to.
+func (c *CurrencyConverter) Currency() string {
+	return c.to
+}
+
+// Rate returns the amount of the reporting currency one unit of the quote asset is worth.
+func (c *CurrencyConverter) Rate(ctx context.Context) (float64, error) {
+	if c.from == c.to {
+		return 1, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if c.rate > 0 && now.Sub(c.rateAt) < currencyRateTTL {
+		return c.rate, nil
+	}
+	if c.path != nil {
+		if rate, err := c.pathRate(ctx, c.path); err == nil {
+			c.rate, c.rateAt = rate, now
+			return rate, nil
+		}
+		c.path = nil // The pair may have been delisted; look for another one
+	}
+
+	var errs []error
+	for _, path := range c.candidatePaths() {
+		rate, err := c.pathRate(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.path, c.rate, c.rateAt = path, rate, now
+		return rate, nil
+	}
+	return 0, fmt.Errorf("no ticker converts %s to %s: %w", c.from, c.to, errors.Join(errs...))
+}
+
+// Convert converts an amount of the quote asset to the reporting currency.
+func (c *CurrencyConverter) Convert(ctx context.Context, amount float64) (float64, error) {
+	rate, err := c.Rate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
+
+// candidatePaths lists the ticker paths from the quote asset to the reporting currency, direct
+// pairs first.
+func (c *CurrencyConverter) candidatePaths() [][]conversionLeg {
+	paths := [][]conversionLeg{
+		{{symbol: c.from + c.to}},
+		{{symbol: c.to + c.from, inverse: true}},
+	}
+	for _, bridge := range currencyBridges {
+		if bridge == c.from || bridge == c.to {
+			continue
+		}
+		for _, first := range []conversionLeg{{symbol: c.from + bridge}, {symbol: bridge + c.from, inverse: true}} {
+			for _, second := range []conversionLeg{{symbol: bridge + c.to}, {symbol: c.to + bridge, inverse: true}} {
+				paths = append(paths, []conversionLeg{first, second})
+			}
+		}
+	}
+	return paths
+}
+
+// pathRate multiplies the rates of the path's tickers.
+func (c *CurrencyConverter) pathRate(ctx context.Context, path []conversionLeg) (float64, error) {
+	rate := 1.0
+	for _, leg := range path {
+		price, err := c.prices.GetTickerPrice(ctx, leg.symbol)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", leg.symbol, err)
+		}
+		if price <= 0 {
+			return 0, fmt.Errorf("%s: invalid price %v", leg.symbol, price)
+		}
+		if leg.inverse {
+			price = 1 / price
+		}
+		rate *= price
+	}
+	return rate, nil
+}
+
+// WithCurrencyConverter reports the dashboard's amounts also in the converter's currency.
+func WithCurrencyConverter(converter *CurrencyConverter) Option {
+	return func(s *TradingService) {
+		s.converter = converter
+	}
+}
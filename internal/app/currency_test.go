@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// mockTickerPrices serves ticker prices by symbol and counts the lookups
+type mockTickerPrices struct {
+	prices  map[string]float64
+	lookups int
+}
+
+func (m *mockTickerPrices) GetTickerPrice(ctx context.Context, symbol string) (float64, error) {
+	m.lookups++
+	if price, ok := m.prices[symbol]; ok {
+		return price, nil
+	}
+	return 0, ports.ErrInvalidRequest
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		prices map[string]float64
+		rate   float64
+	}{
+		{name: "direct pair", prices: map[string]float64{"USDTEUR": 0.92}, rate: 0.92},
+		{name: "inverse pair", prices: map[string]float64{"EURUSDT": 1.25}, rate: 0.8},
+		{name: "bridge through BTC", prices: map[string]float64{"BTCUSDT": 50000, "BTCEUR": 45000}, rate: 0.9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := NewCurrencyConverter(&mockTickerPrices{prices: tt.prices}, "usdt", "eur")
+			require.NoError(t, err)
+			assert.Equal(t, "EUR", converter.Currency())
+			amount, err := converter.Convert(ctx, 100)
+			require.NoError(t, err)
+			assert.InDelta(t, 100*tt.rate, amount, 1e-9)
+		})
+	}
+
+	t.Run("rate is reused for a minute", func(t *testing.T) {
+		prices := &mockTickerPrices{prices: map[string]float64{"EURUSDT": 1.25}}
+		converter, err := NewCurrencyConverter(prices, "USDT", "EUR")
+		require.NoError(t, err)
+		now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+		converter.now = func() time.Time { return now }
+
+		_, err = converter.Rate(ctx)
+		require.NoError(t, err)
+		lookups := prices.lookups
+		prices.prices["EURUSDT"] = 1.0
+		rate, err := converter.Rate(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0.8, rate)
+		assert.Equal(t, lookups, prices.lookups)
+
+		// Once it's stale only the pair that worked is fetched again
+		now = now.Add(2 * time.Minute)
+		rate, err = converter.Rate(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, rate)
+		assert.Equal(t, lookups+1, prices.lookups)
+	})
+
+	t.Run("same currency and missing pairs", func(t *testing.T) {
+		converter, err := NewCurrencyConverter(&mockTickerPrices{}, "USDT", "USDT")
+		require.NoError(t, err)
+		rate, err := converter.Rate(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, rate)
+
+		converter, err = NewCurrencyConverter(&mockTickerPrices{}, "USDT", "CHF")
+		require.NoError(t, err)
+		_, err = converter.Rate(ctx)
+		assert.ErrorContains(t, err, "no ticker converts USDT to CHF")
+
+		_, err = NewCurrencyConverter(&mockTickerPrices{}, "USDT", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestDailyReporter_SendConverted(t *testing.T) {
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	tradeRepo := &mockTradeRepo{trades: []*domain.Position{
+		{Symbol: "ETHUSDT", EntryPrice: 2000, ExitPrice: 2100, Quantity: 0.1, PNL: 10, ExitTime: day.Add(2 * time.Hour)},
+	}}
+	notifier := &mockNotifier{}
+	converter, err := NewCurrencyConverter(&mockTickerPrices{prices: map[string]float64{"EURUSDT": 1.25}}, "USDT", "EUR")
+	require.NoError(t, err)
+
+	_, err = NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT", BalanceAsset: "USDC", Converter: converter},
+		&mockLogger{}, &mockExchange{}, tradeRepo, &mockReportRepo{}, notifier)
+	assert.Error(t, err, "Expected a converter from another asset to be rejected")
+
+	r, err := NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT", Converter: converter},
+		&mockLogger{}, &mockExchange{balance: 1000}, tradeRepo, &mockReportRepo{}, notifier)
+	require.NoError(t, err)
+	_, err = r.Send(context.Background(), day.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	require.Len(t, notifier.messages, 1)
+	assert.Contains(t, notifier.messages[0], "Net PnL: 10.00 USDT")
+	assert.Contains(t, notifier.messages[0], "In EUR (1 USDT = 0.8000 EUR):")
+	assert.Contains(t, notifier.messages[0], "Net PnL: 8.00 EUR")
+	assert.Contains(t, notifier.messages[0], "Balance: 800.00 EUR")
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// errDailyVolumeCap is returned (wrapped) for entries refused by the daily volume caps.
+var errDailyVolumeCap = errors.New("daily volume cap")
+
+// WithDailyVolumeCap refuses new entries and scale-in adds once the entry notional or quantity
+// traded during the current UTC day would exceed the cap's limits. Each entry fill is added to the
+// day's totals in repo, which are restored on startup so a restart doesn't reset the caps.
+func WithDailyVolumeCap(limit *risk.DailyVolumeCap, repo ports.DailyVolumeRepository) Option {
+	return func(s *TradingService) {
+		s.gate.dailyVolume = limit
+		s.sizer.dailyVolume = limit
+		s.sizer.volumeRepo = repo
+	}
+}
+
+// RestoreDailyVolume loads today's entry totals saved before a restart.
+func (z *EntrySizer) RestoreDailyVolume(ctx context.Context) error {
+	if z.dailyVolume == nil {
+		return nil
+	}
+	now := z.clock.Now()
+	notional, volume, err := z.volumeRepo.FindDailyVolume(ctx, z.cfg.Symbol, now)
+	if err != nil {
+		return err
+	}
+	z.dailyVolume.Restore(now, notional, volume)
+	z.logger.Info(ctx, "Daily traded volume restored", map[string]interface{}{"notional": notional, "volume": volume})
+	return nil
+}
+
+// checkDailyVolume returns an error if an entry of quantity at price would exceed the daily caps.
+func (z *EntrySizer) checkDailyVolume(quantity, price float64) error {
+	if z.dailyVolume == nil {
+		return nil
+	}
+	if ok, reason := z.dailyVolume.Allow(z.clock.Now(), quantity*price, quantity); !ok {
+		return fmt.Errorf("%w: entry of %g at %.2f refused: %s", errDailyVolumeCap, quantity, price, reason)
+	}
+	return nil
+}
+
+// RecordVolume adds an entry fill to today's totals. A failure to persist them is only
+// logged: the in-memory totals still enforce the caps until the next restart.
+func (z *EntrySizer) RecordVolume(ctx context.Context, quantity, price float64) {
+	if z.dailyVolume == nil {
+		return
+	}
+	now := z.clock.Now()
+	z.dailyVolume.Record(now, quantity*price, quantity)
+	if err := z.volumeRepo.AddDailyVolume(ctx, z.cfg.Symbol, now, quantity*price, quantity); err != nil {
+		z.logger.Error(ctx, err, "Failed to save daily traded volume")
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// mockVolumeRepo implements ports.DailyVolumeRepository in memory (one symbol, one day)
+type mockVolumeRepo struct {
+	notional, volume float64
+}
+
+func (m *mockVolumeRepo) AddDailyVolume(ctx context.Context, symbol string, date time.Time, notional, volume float64) error {
+	m.notional += notional
+	m.volume += volume
+	return nil
+}
+
+func (m *mockVolumeRepo) FindDailyVolume(ctx context.Context, symbol string, date time.Time) (float64, float64, error) {
+	return m.notional, m.volume, nil
+}
+
+func TestTradingService_DailyVolumeCap(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  1,
+		StopLoss:  0.02,
+		MaxProfit: 0.05,
+		MaxOrders: 5,
+		Leverage:  10,
+	}
+	newService := func(t *testing.T, repo *mockVolumeRepo) (*TradingService, *mockExchange) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY": {OrderID: 1, ExecutedQty: 1, AvgPrice: 2000, Status: "FILLED"},
+			"stop_SELL":  {OrderID: 2, Status: "NEW"},
+			"tp_SELL":    {OrderID: 3, Status: "NEW"},
+		}}
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{},
+			WithDailyVolumeCap(risk.NewDailyVolumeCap(risk.DailyVolumeConfig{MaxNotional: 5000}), repo))
+		require.NoError(t, err)
+		return service, exchange
+	}
+
+	t.Run("entry fills are recorded and persisted", func(t *testing.T) {
+		repo := &mockVolumeRepo{}
+		service, _ := newService(t, repo)
+		require.NoError(t, service.enterPosition(context.Background(), domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, 2000.0, repo.notional)
+		assert.Equal(t, 1.0, repo.volume)
+		notional, _ := service.gate.dailyVolume.Usage(time.Now())
+		assert.Equal(t, 2000.0, notional)
+	})
+
+	t.Run("entry that would exceed the cap is refused", func(t *testing.T) {
+		repo := &mockVolumeRepo{notional: 3500, volume: 1.75}
+		service, exchange := newService(t, repo)
+		require.NoError(t, service.sizer.RestoreDailyVolume(context.Background()))
+
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok, "cap not used up yet")
+		err := service.enterPosition(context.Background(), domain.PositionSideLong, 2000, time.Now())
+		require.ErrorIs(t, err, errDailyVolumeCap)
+		assert.Empty(t, exchange.marketOrderQty)
+		assert.Equal(t, 3500.0, repo.notional)
+	})
+
+	t.Run("used up cap blocks entries", func(t *testing.T) {
+		repo := &mockVolumeRepo{notional: 5000, volume: 2.5}
+		service, _ := newService(t, repo)
+		require.NoError(t, service.sizer.RestoreDailyVolume(context.Background()))
+
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Contains(t, reason, "daily notional cap reached")
+	})
+}
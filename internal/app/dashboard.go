@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// maxEquityHistory limits the equity curve to one day of primary interval klines.
+const maxEquityHistory = 1440
+
+// WithEquityHistory records the account equity (starting balance plus realized and unrealized
+// PnL) on every closed primary kline, for the dashboard's equity curve.
+func WithEquityHistory() Option {
+	return func(s *TradingService) {
+		s.recordEquity = true
+	}
+}
+
+// recordEquityPoint adds the equity at the kline's close to the equity history.
+// Assumes the caller holds the lock.
+func (s *TradingService) recordEquityPoint(kline *domain.Kline) {
+	if !s.recordEquity {
+		return
+	}
+	equity := s.startingEquity + s.realizedPnL
+	for _, pos := range s.positions.OpenPositions() {
+		equity += pos.UnrealizedPnL(kline.Close)
+	}
+	s.equityHistory = append(s.equityHistory, ports.EquityPoint{Time: kline.CloseTime, Equity: equity})
+	if len(s.equityHistory) > maxEquityHistory {
+		s.equityHistory = s.equityHistory[len(s.equityHistory)-maxEquityHistory:]
+	}
+}
+
+// Dashboard returns a snapshot of the live trading data (implements ports.DashboardProvider).
+// Today's trades are those closed since local midnight, like the daily trade limit.
+func (s *TradingService) Dashboard(ctx context.Context) (ports.DashboardSnapshot, error) {
+	now := s.now()
+	snapshot := ports.DashboardSnapshot{Symbol: s.cfg.Symbol, Timestamp: now}
+
+	s.mu.Lock()
+	if klines := s.signals.Klines(); len(klines) > 0 {
+		snapshot.Price = klines[len(klines)-1].Close
+		snapshot.PriceTime = klines[len(klines)-1].CloseTime
+	}
+	for _, pos := range s.positions.OpenPositions() {
+		snapshot.Positions = append(snapshot.Positions, ports.DashboardPosition{
+			ID:            pos.ID,
+			Side:          string(pos.PositionSide()),
+			EntryPrice:    pos.EntryPrice,
+			Quantity:      pos.Quantity,
+			StopLoss:      pos.StopLoss,
+			TakeProfit:    pos.TakeProfit,
+			EntryTime:     pos.EntryTime,
+			UnrealizedPnL: pos.UnrealizedPnL(snapshot.Price),
+		})
+	}
+	snapshot.Equity = append([]ports.EquityPoint(nil), s.equityHistory...)
+	s.mu.Unlock()
+
+	year, month, day := now.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+	closed, err := s.tradeRepo.FindClosedBetween(ctx, s.cfg.Symbol, midnight, now.Add(time.Second))
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to load today's trades: %w", err)
+	}
+	for _, pos := range closed {
+		trade := pos.Trade()
+		snapshot.TradesToday = append(snapshot.TradesToday, ports.DashboardTrade{
+			Side:        string(trade.Side),
+			EntryPrice:  trade.EntryPrice,
+			ExitPrice:   trade.ExitPrice,
+			Quantity:    trade.Quantity,
+			PNL:         trade.PNL,
+			EntryTime:   trade.EntryTime,
+			ExitTime:    trade.ExitTime,
+			CloseReason: string(trade.CloseReason),
+		})
+		snapshot.RealizedToday += trade.PNL
+	}
+
+	// A missing rate shouldn't take the dashboard down; amounts stay in the quote asset
+	if s.converter != nil {
+		if rate, err := s.converter.Rate(ctx); err != nil {
+			s.logger.Warn(ctx, "Failed to get currency conversion rate for dashboard", map[string]interface{}{
+				"currency": s.converter.Currency(),
+				"error":    err.Error(),
+			})
+		} else {
+			snapshot.Currency, snapshot.ConversionRate = s.converter.Currency(), rate
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+)
+
+func TestTradingService_Dashboard(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	now := time.Now()
+	tradeRepo := &mockTradeRepo{trades: []*domain.Position{
+		{Symbol: "ETHUSDT", EntryPrice: 1900, ExitPrice: 1950, Quantity: 0.1, PNL: 5, Status: domain.StatusClosed,
+			EntryTime: now.Add(-time.Minute), ExitTime: now, CloseReason: domain.CloseReasonTakeProfit},
+		{Symbol: "ETHUSDT", PNL: 100, Status: domain.StatusClosed, ExitTime: now.Add(-48 * time.Hour)}, // Not today
+	}}
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, tradeRepo, &mockStrategy{}, WithEquityHistory())
+	require.NoError(t, err)
+	service.startingEquity = 1000
+	service.realizedPnL = 5
+	service.positions.long = &domain.Position{ID: 7, Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.5, Status: domain.StatusOpen, EntryTime: now}
+
+	service.handleKlineEvent(&domain.Kline{OpenTime: now.Add(-time.Minute), CloseTime: now, Close: 2010, IsFinal: true})
+	service.handleKlineEvent(&domain.Kline{OpenTime: now, CloseTime: now.Add(time.Minute), Close: 1990, IsFinal: false}) // Not recorded
+
+	snapshot, err := service.Dashboard(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ETHUSDT", snapshot.Symbol)
+	assert.Equal(t, 2010.0, snapshot.Price)
+	require.Len(t, snapshot.Positions, 1)
+	assert.Equal(t, int64(7), snapshot.Positions[0].ID)
+	assert.InDelta(t, 5.0, snapshot.Positions[0].UnrealizedPnL, 1e-9)
+	require.Len(t, snapshot.TradesToday, 1)
+	assert.Equal(t, "TP", snapshot.TradesToday[0].CloseReason)
+	assert.Equal(t, 5.0, snapshot.RealizedToday)
+	require.Len(t, snapshot.Equity, 1)
+	assert.InDelta(t, 1010.0, snapshot.Equity[0].Equity, 1e-9) // 1000 + 5 realized + 5 unrealized
+
+	tradeRepo.findClosedErr = assert.AnError
+	_, err = service.Dashboard(context.Background())
+	assert.Error(t, err)
+}
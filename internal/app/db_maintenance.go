@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// DBMaintenanceConfig configures the periodic upkeep of the database.
+type DBMaintenanceConfig struct {
+	Interval       time.Duration // How often maintenance runs
+	Retention      time.Duration // Cached klines and logged orders older than this are deleted (0 keeps them)
+	Vacuum         bool          // Rebuild the database file to return the space of deleted rows
+	IntegrityCheck bool          // Verify the database structure, sending a critical notification on problems
+}
+
+// WithDBMaintenance runs database maintenance every cfg.Interval: the write-ahead log is
+// checkpointed, old rows are pruned, the file is vacuumed and its integrity checked as configured,
+// and the database size and row counts are logged.
+func WithDBMaintenance(db ports.DatabaseMaintainer, cfg DBMaintenanceConfig) Option {
+	return func(s *TradingService) {
+		s.dbMaintainer = db
+		s.dbMaintenance = cfg
+	}
+}
+
+// runDBMaintenance maintains the database every interval until ctx is canceled.
+func (s *TradingService) runDBMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(s.dbMaintenance.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.maintainDB(ctx, s.now())
+		}
+	}
+}
+
+// maintainDB runs one maintenance pass and logs its report. Failures are logged and retried at
+// the next interval; integrity problems are also sent as a critical notification.
+func (s *TradingService) maintainDB(ctx context.Context, now time.Time) {
+	opts := ports.MaintenanceOptions{Vacuum: s.dbMaintenance.Vacuum, IntegrityCheck: s.dbMaintenance.IntegrityCheck}
+	if s.dbMaintenance.Retention > 0 {
+		opts.PruneBefore = now.Add(-s.dbMaintenance.Retention)
+	}
+	report, err := s.dbMaintainer.Maintain(ctx, opts)
+	if err != nil {
+		s.logger.Error(ctx, err, "Database maintenance failed")
+		return
+	}
+
+	fields := map[string]interface{}{
+		"sizeBytes":         report.SizeBytes,
+		"walSizeBytes":      report.WALSizeBytes,
+		"checkpointedPages": report.CheckpointedPages,
+		"prunedKlines":      report.PrunedKlines,
+		"prunedOrders":      report.PrunedOrders,
+		"vacuumed":          report.Vacuumed,
+		"duration":          report.Duration.String(),
+	}
+	for table, rows := range report.TableRows {
+		fields["rows."+table] = rows
+	}
+	s.logger.Info(ctx, "Database maintenance completed", fields)
+	if report.WALBusy {
+		s.logger.Warn(ctx, "Database checkpoint couldn't complete while the database was busy")
+	}
+	if len(report.IntegrityErrors) > 0 {
+		cause := errors.New(strings.Join(report.IntegrityErrors, "; "))
+		s.logger.Error(ctx, cause, "Database integrity check failed", map[string]interface{}{"problems": len(report.IntegrityErrors)})
+		s.notifyCritical(ctx, "Database integrity check failed", cause)
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+type mockDatabaseMaintainer struct {
+	opts   []ports.MaintenanceOptions
+	report *ports.MaintenanceReport
+}
+
+func (m *mockDatabaseMaintainer) Maintain(ctx context.Context, opts ports.MaintenanceOptions) (*ports.MaintenanceReport, error) {
+	m.opts = append(m.opts, opts)
+	return m.report, nil
+}
+
+func TestTradingService_MaintainDB(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 1}
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	db := &mockDatabaseMaintainer{report: &ports.MaintenanceReport{SizeBytes: 4096, TableRows: map[string]int64{"trades": 3}}}
+	notifier := &mockNotifier{}
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+		&mockTradeRepo{}, &mockStrategy{}, WithNotifier(notifier),
+		WithDBMaintenance(db, DBMaintenanceConfig{Interval: time.Hour, Retention: 30 * 24 * time.Hour, Vacuum: true}))
+	require.NoError(t, err)
+
+	service.maintainDB(ctx, now)
+	require.Len(t, db.opts, 1)
+	assert.True(t, db.opts[0].PruneBefore.Equal(now.AddDate(0, 0, -30)))
+	assert.True(t, db.opts[0].Vacuum)
+	assert.False(t, db.opts[0].IntegrityCheck)
+
+	// Without a retention nothing is pruned; integrity problems are escalated
+	service.dbMaintenance = DBMaintenanceConfig{Interval: time.Hour, IntegrityCheck: true}
+	db.report = &ports.MaintenanceReport{IntegrityChecked: true, IntegrityErrors: []string{"row 7 missing from index idx_trades_symbol"}}
+	service.maintainDB(ctx, now)
+	require.Len(t, db.opts, 2)
+	assert.True(t, db.opts[1].PruneBefore.IsZero())
+	assert.True(t, db.opts[1].IntegrityCheck)
+
+	service.notifications.Wait()
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	require.Len(t, notifier.messages, 1)
+	assert.Contains(t, notifier.messages[0], "idx_trades_symbol")
+}
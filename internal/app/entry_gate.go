@@ -0,0 +1,241 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// positionBook is the view of the open positions the entry gate and sizer need. PositionManager
+// provides it.
+type positionBook interface {
+	Position(side domain.PositionSide) *domain.Position
+	OpenPositions() []*domain.Position
+}
+
+// limitEntryBook is the view of the limit entries resting on the exchange the entry gate and
+// sizer need. TradingService provides it.
+type limitEntryBook interface {
+	pendingLimitEntry(side domain.PositionSide) *pendingLimitEntry
+	pendingLimitEntries() []*pendingLimitEntry
+}
+
+// entryBlocker reports whether a condition tracked outside the gate, such as the kline stream's
+// continuity or exchange safe mode, blocks entries, and why.
+type entryBlocker func() (bool, string)
+
+// EntryGate decides whether the bot may add exposure: it enforces the trade direction, one
+// position (or resting limit entry) per side, the daily trade limit, the re-entry cooldown and the
+// daily volume caps, and pauses entries for an operator, the kill switch, a locked-in equity trail,
+// the order circuit breaker, blackout windows, the trading calendar and the session end. It sends
+// no orders; TradingService asks it before each entry and scale-in add. It isn't safe for
+// concurrent use: the service calls it with its lock held.
+type EntryGate struct {
+	cfg       *config.Config
+	clock     ports.Clock
+	positions positionBook
+	limits    limitEntryBook
+	blockers  []entryBlocker
+
+	killSwitch  *risk.KillSwitch       // Optional: pauses entries on equity drawdown / losing streaks
+	equityTrail *risk.EquityTrail      // Optional: stops entries for the rest of a locked-in day
+	breaker     *risk.CircuitBreaker   // Optional: stops entries after repeated order failures
+	blackout    *risk.BlackoutSchedule // Optional: refuses entries during news/volatility windows
+	calendar    *risk.TradingCalendar  // Optional: refuses entries on the days it disables
+	dailyVolume *risk.DailyVolumeCap   // Optional: refuses entries once the day's caps are reached
+	sessionEnd  time.Duration          // Optional: day trading session end, offset from UTC midnight (0 disables)
+	reEntry     domain.ReEntryPolicy   // Optional: cooldowns after exits, by close reason
+
+	tradesToday    int
+	pauseReason    string // Why an operator paused new entries; empty unless paused
+	lastExitReason domain.CloseReason
+	lastExitTime   time.Time // Zero until a position was closed
+}
+
+// NewEntryGate creates an entry gate without risk components; the service's options add them.
+func NewEntryGate(cfg *config.Config) *EntryGate {
+	return &EntryGate{cfg: cfg, clock: clock.Real{}}
+}
+
+// attach connects the gate to the service's clock, the positions and resting limit entries that
+// block entries on a side, and the service's own entry blockers.
+func (g *EntryGate) attach(clock ports.Clock, positions positionBook, limits limitEntryBook, blockers ...entryBlocker) {
+	g.clock = clock
+	g.positions = positions
+	g.limits = limits
+	g.blockers = blockers
+}
+
+// Allow reports whether a new position may be opened on side, and why not.
+// In hedge mode a long and a short can be open at once; in one-way mode any open position blocks entries.
+// The available balance is checked when sizing the entry, where its price is known.
+func (g *EntryGate) Allow(side domain.PositionSide) (bool, string) {
+	// 0. Check the configured trade direction allows the side
+	if !g.cfg.Direction.Allows(side) {
+		return false, fmt.Sprintf("%s entries disabled by TRADE_DIRECTION=%s", side, g.cfg.Direction)
+	}
+
+	// 1. Check if a position is already open on this side (or on either side in one-way mode)
+	if pos := g.positions.Position(side); pos != nil {
+		return false, fmt.Sprintf("position %d already open", pos.ID)
+	}
+	if !g.cfg.HedgeMode {
+		if open := g.positions.OpenPositions(); len(open) > 0 {
+			return false, fmt.Sprintf("position %d already open (one-way mode)", open[0].ID)
+		}
+	}
+	// 1.1 Check for a limit entry still resting on this side (or on either side in one-way mode)
+	if pending := g.limits.pendingLimitEntry(side); pending != nil {
+		return false, fmt.Sprintf("limit entry %s pending", pending.clientOrderID)
+	}
+
+	// 2. Check daily trade limit
+	// The count is restored from the trade history on startup and kept in memory since.
+	// TODO: Consider refreshing tradesToday from DB periodically or on error?
+	if g.tradesToday >= g.cfg.MaxOrders {
+		return false, fmt.Sprintf("daily trade limit reached (%d/%d)", g.tradesToday, g.cfg.MaxOrders)
+	}
+
+	// 2.1 Check the kill switch, kline stream continuity, blackout windows and the session end
+	if paused, reason := g.Paused(); paused {
+		return false, reason
+	}
+
+	// 2.2 Check the re-entry cooldown of the last exit
+	now := g.clock.Now()
+	if blocked, reason := g.reEntryCooldown(now); blocked {
+		return false, reason
+	}
+
+	// 2.3 Check the daily notional/volume caps
+	if g.dailyVolume != nil {
+		if reached, reason := g.dailyVolume.Reached(now); reached {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+// Paused reports whether adding exposure is paused by an operator, the equity kill switch, a
+// locked-in equity trail, the order circuit breaker, one of the service's blockers (a
+// discontinuous kline stream, exchange safe mode), a news/volatility blackout window, a day the
+// trading calendar disables or the end of the trading session.
+func (g *EntryGate) Paused() (bool, string) {
+	now := g.clock.Now()
+	if g.pauseReason != "" {
+		return true, "paused by operator: " + g.pauseReason
+	}
+	if g.killSwitch != nil {
+		if tripped, reason := g.killSwitch.IsTripped(now); tripped {
+			return true, "kill switch active: " + reason
+		}
+	}
+	if g.equityTrail != nil {
+		if locked, reason := g.equityTrail.Locked(now); locked {
+			return true, "equity trail locked in: " + reason
+		}
+	}
+	if g.breaker != nil {
+		if ok, reason := g.breaker.Allow(now); !ok {
+			return true, "circuit breaker open: " + reason
+		}
+	}
+	for _, blocked := range g.blockers {
+		if paused, reason := blocked(); paused {
+			return true, reason
+		}
+	}
+	if active, name := g.blackout.Active(now); active {
+		return true, "blackout: " + name
+	}
+	if closed, reason := g.calendar.Closed(now); closed {
+		return true, "trading calendar: " + reason
+	}
+	if g.SessionEnded(now) {
+		return true, "session ended"
+	}
+	return false, ""
+}
+
+// RestoreTradesToday sets the number of positions opened today, counted before a restart.
+func (g *EntryGate) RestoreTradesToday(count int) {
+	g.tradesToday = count
+}
+
+// TradesToday returns the number of positions opened today.
+func (g *EntryGate) TradesToday() int {
+	return g.tradesToday
+}
+
+// RecordEntry counts an opened position as one of the day's trades and returns the day's count.
+func (g *EntryGate) RecordEntry() int {
+	g.tradesToday++
+	return g.tradesToday
+}
+
+// RecordExit remembers pos as the last exit, so its re-entry cooldown applies.
+func (g *EntryGate) RecordExit(pos *domain.Position) {
+	g.lastExitReason = pos.CloseReason
+	g.lastExitTime = pos.ExitTime
+}
+
+// Pause refuses new entries for reason until Resume.
+func (g *EntryGate) Pause(reason string) {
+	g.pauseReason = reason
+}
+
+// Resume lifts an operator pause, clears a tripped kill switch and unlocks a locked-in equity
+// trail. Reports whether an operator had paused entries; fails if there was nothing to resume.
+func (g *EntryGate) Resume() (bool, error) {
+	paused := g.pauseReason != ""
+	g.pauseReason = ""
+	if g.killSwitch == nil && g.equityTrail == nil && !paused {
+		return false, fmt.Errorf("trading is not paused and neither the kill switch nor the equity trail is enabled: %w", ports.ErrConfigurationError)
+	}
+	if g.killSwitch != nil {
+		g.killSwitch.Resume()
+	}
+	if g.equityTrail != nil {
+		g.equityTrail.Resume()
+	}
+	return paused, nil
+}
+
+// UpdateEquity feeds the current equity into the kill switch. Reports whether that tripped it,
+// with its status.
+func (g *EntryGate) UpdateEquity(equity float64) (bool, ports.KillSwitchStatus) {
+	if g.killSwitch == nil {
+		return false, ports.KillSwitchStatus{}
+	}
+	now := g.clock.Now()
+	if !g.killSwitch.Update(equity, now) {
+		return false, ports.KillSwitchStatus{}
+	}
+	return true, g.killSwitch.Status(now)
+}
+
+// addStatus adds the gate's state to a status snapshot taken at now.
+func (g *EntryGate) addStatus(status *ports.TradingStatus, now time.Time) {
+	status.TradesToday = g.tradesToday
+	status.Paused = g.pauseReason
+	if g.killSwitch != nil {
+		ks := g.killSwitch.Status(now)
+		status.KillSwitch = &ks
+	}
+	if g.equityTrail != nil {
+		trail := g.equityTrail.Status(now)
+		status.EquityTrail = &trail
+	}
+	if g.breaker != nil {
+		breaker := g.breaker.Status(now)
+		status.CircuitBreaker = &breaker
+	}
+	if active, name := g.blackout.Active(now); active {
+		status.Blackout = name
+	}
+}
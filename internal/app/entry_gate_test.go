@@ -0,0 +1,147 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// entryBook is a fixed set of open positions and resting limit entries
+type entryBook struct {
+	long, short *domain.Position
+	pending     []*pendingLimitEntry
+}
+
+func (b *entryBook) Position(side domain.PositionSide) *domain.Position {
+	if side == domain.PositionSideShort {
+		return b.short
+	}
+	return b.long
+}
+
+func (b *entryBook) OpenPositions() []*domain.Position {
+	var open []*domain.Position
+	for _, pos := range []*domain.Position{b.long, b.short} {
+		if pos != nil {
+			open = append(open, pos)
+		}
+	}
+	return open
+}
+
+func (b *entryBook) pendingLimitEntry(side domain.PositionSide) *pendingLimitEntry {
+	for _, pending := range b.pending {
+		if pending.side == side {
+			return pending
+		}
+	}
+	return nil
+}
+
+func (b *entryBook) pendingLimitEntries() []*pendingLimitEntry {
+	return b.pending
+}
+
+func TestEntryGate_Checks(t *testing.T) {
+	now := time.Date(2025, 6, 4, 12, 0, 0, 0, time.UTC)
+	newGate := func(book *entryBook, blockers ...entryBlocker) *EntryGate {
+		gate := NewEntryGate(&config.Config{Symbol: "ETHUSDT", HedgeMode: true, MaxOrders: 2})
+		gate.attach(clock.NewFake(now), book, book, blockers...)
+		return gate
+	}
+
+	t.Run("positions and limit entries block their side", func(t *testing.T) {
+		book := &entryBook{long: &domain.Position{ID: 7}, pending: []*pendingLimitEntry{{side: domain.PositionSideShort, clientOrderID: "x1"}}}
+		gate := newGate(book)
+		ok, reason := gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "position 7 already open", reason)
+		ok, reason = gate.Allow(domain.PositionSideShort)
+		assert.False(t, ok)
+		assert.Equal(t, "limit entry x1 pending", reason)
+
+		book.pending = nil
+		ok, _ = gate.Allow(domain.PositionSideShort)
+		assert.True(t, ok, "hedge mode allows a short next to the long")
+	})
+
+	t.Run("daily trade limit", func(t *testing.T) {
+		gate := newGate(&entryBook{})
+		gate.RestoreTradesToday(1)
+		ok, _ := gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		assert.Equal(t, 2, gate.RecordEntry())
+		ok, reason := gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "daily trade limit reached (2/2)", reason)
+	})
+
+	t.Run("service blockers pause entries", func(t *testing.T) {
+		streamIssue := "gap of 3 klines"
+		gate := newGate(&entryBook{}, func() (bool, string) {
+			return streamIssue != "", "kline stream discontinuous: " + streamIssue
+		})
+		paused, reason := gate.Paused()
+		assert.True(t, paused)
+		assert.Equal(t, "kline stream discontinuous: gap of 3 klines", reason)
+
+		gate.Pause("maintenance")
+		_, reason = gate.Paused()
+		assert.Equal(t, "paused by operator: maintenance", reason, "an operator pause is reported first")
+
+		streamIssue = ""
+		resumed, err := gate.Resume()
+		require.NoError(t, err)
+		assert.True(t, resumed)
+		paused, _ = gate.Paused()
+		assert.False(t, paused)
+	})
+
+	t.Run("nothing to resume", func(t *testing.T) {
+		gate := newGate(&entryBook{})
+		_, err := gate.Resume()
+		assert.ErrorIs(t, err, ports.ErrConfigurationError)
+	})
+
+	t.Run("re-entry cooldown of the last exit", func(t *testing.T) {
+		policy, err := domain.ParseReEntryPolicy("SL:30m")
+		require.NoError(t, err)
+		gate := newGate(&entryBook{})
+		gate.reEntry = policy
+		gate.RecordExit(&domain.Position{CloseReason: domain.CloseReasonStopLoss, ExitTime: now.Add(-10 * time.Minute)})
+		ok, reason := gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "re-entry cooldown after SL exit (20m0s left)", reason)
+
+		gate.RecordExit(&domain.Position{CloseReason: domain.CloseReasonStopLoss, ExitTime: now.Add(-time.Hour)})
+		ok, _ = gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+	})
+
+	t.Run("kill switch trips on equity", func(t *testing.T) {
+		gate := newGate(&entryBook{})
+		gate.killSwitch = risk.NewKillSwitch(risk.KillSwitchConfig{MaxDrawdown: 0.1, CoolDown: time.Hour})
+		tripped, _ := gate.UpdateEquity(1000)
+		assert.False(t, tripped)
+		tripped, status := gate.UpdateEquity(850)
+		assert.True(t, tripped)
+		assert.True(t, status.Tripped)
+		paused, reason := gate.Paused()
+		assert.True(t, paused)
+		assert.Contains(t, reason, "kill switch active")
+
+		var snapshot ports.TradingStatus
+		gate.addStatus(&snapshot, now)
+		require.NotNil(t, snapshot.KillSwitch)
+		assert.True(t, snapshot.KillSwitch.Tripped)
+		assert.Nil(t, snapshot.EquityTrail)
+	})
+}
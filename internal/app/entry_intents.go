@@ -0,0 +1,172 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// WithEntryIntentRepository records every entry order before it is placed. On startup, entries
+// still pending (the bot stopped between placing the order and saving its position) are looked
+// up on the exchange by their client order ID: fills whose position is still open are adopted
+// with fresh SL/TP orders instead of being entered again.
+func WithEntryIntentRepository(repo ports.EntryIntentRepository) Option {
+	return func(s *TradingService) {
+		s.intents = repo
+	}
+}
+
+// saveEntryIntent records an entry about to be placed. It returns nil without an intent
+// repository, and fails if the same signal was already recorded, e.g. when a restart processes
+// the kline again.
+func (s *TradingService) saveEntryIntent(ctx context.Context, clientOrderID string, side domain.PositionSide, klineOpenTime time.Time, quantity float64) (*domain.EntryIntent, error) {
+	if s.intents == nil {
+		return nil, nil
+	}
+	intent := &domain.EntryIntent{
+		ClientOrderID: clientOrderID,
+		Symbol:        s.cfg.Symbol,
+		Side:          side,
+		KlineOpenTime: klineOpenTime,
+		Quantity:      quantity,
+		Status:        domain.EntryIntentPending,
+		CreatedAt:     s.now().UTC(),
+	}
+	if err := s.intents.SaveEntryIntent(ctx, intent); err != nil {
+		if errors.Is(err, ports.ErrDuplicateEntry) {
+			return nil, fmt.Errorf("entry for the kline opening at %s was already placed (client order ID %s): %w",
+				klineOpenTime.UTC().Format(time.RFC3339), clientOrderID, err)
+		}
+		return nil, fmt.Errorf("failed to record entry intent, not placing the order: %w", err)
+	}
+	return intent, nil
+}
+
+// finishEntryIntent marks the intent as opened or failed. A nil intent is ignored.
+func (s *TradingService) finishEntryIntent(ctx context.Context, intent *domain.EntryIntent, opened bool) {
+	if intent == nil {
+		return
+	}
+	status := domain.EntryIntentFailed
+	if opened {
+		status = domain.EntryIntentOpened
+	}
+	if err := s.intents.UpdateEntryIntentStatus(ctx, intent.ClientOrderID, status); err != nil {
+		s.logger.Error(ctx, err, "Failed to update entry intent", map[string]interface{}{"clientOrderID": intent.ClientOrderID, "status": status})
+		return
+	}
+	intent.Status = status
+}
+
+// reconcileEntryIntents resolves the entries left pending by the previous run. It must run after
+// the open positions have been loaded. Fails if an entry's outcome can't be determined, since
+// trading on could enter a second position.
+func (s *TradingService) reconcileEntryIntents(ctx context.Context) error {
+	if s.intents == nil {
+		return nil
+	}
+	pending, err := s.intents.FindPendingEntryIntents(ctx, s.cfg.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to load pending entry intents: %w", err)
+	}
+	for _, intent := range pending {
+		if err := s.reconcileEntryIntent(ctx, intent); err != nil {
+			return err
+		}
+	}
+	if len(pending) > 0 {
+		s.logger.Info(ctx, "Pending entries reconciled with the exchange", map[string]interface{}{"count": len(pending)})
+	}
+	return nil
+}
+
+// reconcileEntryIntent looks up a pending entry's order on the exchange and settles the intent:
+// orders that never arrived or didn't fill have failed, fills whose position was saved are opened,
+// and fills the exchange still holds without a saved position are adopted.
+func (s *TradingService) reconcileEntryIntent(ctx context.Context, intent *domain.EntryIntent) error {
+	op := "reconcileEntryIntent"
+	fields := map[string]interface{}{"clientOrderID": intent.ClientOrderID, "side": intent.Side}
+
+	order, err := s.exchange.GetOrderByClientID(ctx, intent.Symbol, intent.ClientOrderID)
+	if errors.Is(err, ports.ErrOrderNotFound) {
+		s.logger.Info(ctx, op+": Pending entry never reached the exchange", fields)
+		s.finishEntryIntent(ctx, intent, false)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up entry order %s: %w", intent.ClientOrderID, err)
+	}
+	fields["orderID"] = order.OrderID
+	fields["status"] = order.Status
+	if order.Type == "LIMIT" && orderOpen(order) {
+		// A limit entry still resting: nobody tracks it any more, so take what filled and cancel the rest
+		canceled, err := s.exchange.CancelOrder(ctx, intent.Symbol, order.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to cancel resting entry order %s: %w", intent.ClientOrderID, err)
+		}
+		s.logger.Info(ctx, op+": Canceled limit entry left resting", fields)
+		if canceled != nil {
+			order = canceled
+		}
+	}
+	if order.ExecutedQty <= 0 {
+		s.logger.Info(ctx, op+": Pending entry did not fill", fields)
+		s.finishEntryIntent(ctx, intent, false)
+		return nil
+	}
+	if s.positions.Position(intent.Side) != nil {
+		// The position was saved; only the intent's update was lost
+		s.finishEntryIntent(ctx, intent, true)
+		return nil
+	}
+
+	// Filled, but the position was never saved: adopt it if the exchange still holds it
+	risks, err := s.exchange.GetPositionRisks(ctx, intent.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to check exchange position for entry %s: %w", intent.ClientOrderID, err)
+	}
+	risk := ports.PositionRiskFor(risks, intent.Side)
+	if risk == nil {
+		s.logger.Warn(ctx, op+": Pending entry filled, but the position is no longer open on the exchange", fields)
+		s.finishEntryIntent(ctx, intent, false)
+		return nil
+	}
+
+	entryPrice := order.AvgPrice
+	if entryPrice == 0 {
+		entryPrice = risk.EntryPrice
+	}
+	entryTime := order.Timestamp.UTC()
+	if order.Timestamp.UnixMilli() <= 0 {
+		entryTime = s.now().UTC()
+	}
+	slPrice, tpPrice := s.exitPrices(intent.Side, entryPrice)
+	fields["entryPrice"] = entryPrice
+	fields["quantity"] = order.ExecutedQty
+	s.logger.Warn(ctx, op+": Adopting entry that filled before its position was saved", fields)
+
+	adopted := &domain.Position{
+		Symbol:     intent.Symbol,
+		Side:       intent.Side,
+		EntryPrice: entryPrice,
+		Quantity:   order.ExecutedQty,
+		Leverage:   s.sizer.Leverage(),
+		StopLoss:   slPrice,
+		TakeProfit: tpPrice,
+		EntryTime:  entryTime,
+	}
+	if s.sizer.ScaleIn().Enabled() {
+		adopted.ScaleInBasePrice = entryPrice
+	}
+	err = s.protectPosition(ctx, op, adopted, order.OrderID)
+	s.finishEntryIntent(ctx, intent, err == nil)
+	if err != nil {
+		// protectPosition already closed the entry again (or raised a critical notification)
+		s.logger.Error(ctx, err, op+": Failed to adopt filled entry", fields)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// mockIntentRepo implements ports.EntryIntentRepository in memory
+type mockIntentRepo struct {
+	intents map[string]*domain.EntryIntent
+	saveErr error
+	saved   []string // Client order IDs in the order they were saved
+}
+
+func (m *mockIntentRepo) SaveEntryIntent(ctx context.Context, intent *domain.EntryIntent) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if _, ok := m.intents[intent.ClientOrderID]; ok {
+		return ports.ErrDuplicateEntry
+	}
+	stored := *intent
+	m.intents[intent.ClientOrderID] = &stored
+	m.saved = append(m.saved, intent.ClientOrderID)
+	return nil
+}
+
+func (m *mockIntentRepo) UpdateEntryIntentStatus(ctx context.Context, clientOrderID string, status domain.EntryIntentStatus) error {
+	intent, ok := m.intents[clientOrderID]
+	if !ok {
+		return ports.ErrNotFound
+	}
+	intent.Status = status
+	return nil
+}
+
+func (m *mockIntentRepo) FindPendingEntryIntents(ctx context.Context, symbol string) ([]*domain.EntryIntent, error) {
+	var pending []*domain.EntryIntent
+	for _, id := range m.saved {
+		if intent := m.intents[id]; intent.Symbol == symbol && intent.Status == domain.EntryIntentPending {
+			pending = append(pending, intent)
+		}
+	}
+	return pending, nil
+}
+
+func (m *mockIntentRepo) status(clientOrderID string) domain.EntryIntentStatus {
+	if intent, ok := m.intents[clientOrderID]; ok {
+		return intent.Status
+	}
+	return ""
+}
+
+func TestTradingService_EntryIntents(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.02,
+		MaxProfit: 0.05,
+		MaxOrders: 5,
+		Leverage:  10,
+	}
+	klineOpen := time.Date(2025, 6, 11, 12, 30, 0, 0, time.UTC)
+	clientOrderID := domain.EntryClientOrderID("ETHUSDT", domain.PositionSideLong, klineOpen)
+	filled := &ports.OrderResponse{OrderID: 1, Symbol: "ETHUSDT", ClientOrderID: clientOrderID, ExecutedQty: 0.1, AvgPrice: 2000, Status: "FILLED", Timestamp: klineOpen.Add(time.Minute)}
+	protectiveOrders := map[string]*ports.OrderResponse{
+		"stop_SELL": {OrderID: 2, Status: "NEW"},
+		"tp_SELL":   {OrderID: 3, Status: "NEW"},
+	}
+	newService := func(t *testing.T) (*TradingService, *mockExchange, *mockPositionRepo, *mockIntentRepo) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{"market_BUY": filled}}
+		for key, order := range protectiveOrders {
+			exchange.orderResponses[key] = order
+		}
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		intents := &mockIntentRepo{intents: make(map[string]*domain.EntryIntent)}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{},
+			WithEntryIntentRepository(intents))
+		require.NoError(t, err)
+		return service, exchange, posRepo, intents
+	}
+	pendingIntent := func(intents *mockIntentRepo) {
+		require.NoError(t, intents.SaveEntryIntent(context.Background(), &domain.EntryIntent{
+			ClientOrderID: clientOrderID, Symbol: "ETHUSDT", Side: domain.PositionSideLong,
+			KlineOpenTime: klineOpen, Quantity: 0.1, Status: domain.EntryIntentPending,
+		}))
+	}
+
+	t.Run("entry is recorded before placement and tagged with its client order ID", func(t *testing.T) {
+		service, exchange, _, intents := newService(t)
+		require.NoError(t, service.enterPosition(context.Background(), domain.PositionSideLong, 2000, klineOpen))
+		assert.Equal(t, []string{clientOrderID}, exchange.clientOrderIDs)
+		assert.Equal(t, domain.EntryIntentOpened, intents.status(clientOrderID))
+	})
+
+	t.Run("the same kline cannot enter twice", func(t *testing.T) {
+		service, exchange, _, _ := newService(t)
+		require.NoError(t, service.enterPosition(context.Background(), domain.PositionSideLong, 2000, klineOpen))
+		service.positions.Untrack(domain.PositionSideLong) // E.g. closed by its stop in the meantime
+
+		err := service.enterPosition(context.Background(), domain.PositionSideLong, 2000, klineOpen)
+		assert.ErrorIs(t, err, ports.ErrDuplicateEntry)
+		assert.Len(t, exchange.clientOrderIDs, 1)
+	})
+
+	t.Run("no order is placed when the intent cannot be recorded", func(t *testing.T) {
+		service, exchange, _, intents := newService(t)
+		intents.saveErr = ports.ErrDBConnection
+		err := service.enterPosition(context.Background(), domain.PositionSideLong, 2000, klineOpen)
+		assert.ErrorIs(t, err, ports.ErrDBConnection)
+		assert.Empty(t, exchange.clientOrderIDs)
+	})
+
+	t.Run("failed placement that filled anyway is adopted", func(t *testing.T) {
+		service, exchange, posRepo, intents := newService(t)
+		exchange.orderErrors = map[string]error{"market_BUY": ports.ErrTimeout}
+		exchange.ordersByClient = map[string]*ports.OrderResponse{clientOrderID: filled}
+		exchange.positionRisk = &ports.PositionRisk{Symbol: "ETHUSDT", PositionAmt: 0.1, EntryPrice: 2000}
+
+		require.NoError(t, service.enterPosition(context.Background(), domain.PositionSideLong, 2000, klineOpen))
+		require.NotNil(t, service.positions.long)
+		assert.Same(t, service.positions.long, posRepo.positions["ETHUSDT"])
+		assert.Equal(t, domain.EntryIntentOpened, intents.status(clientOrderID))
+	})
+
+	t.Run("startup marks entries that never reached the exchange as failed", func(t *testing.T) {
+		service, _, _, intents := newService(t)
+		pendingIntent(intents)
+		require.NoError(t, service.reconcileEntryIntents(context.Background()))
+		assert.Equal(t, domain.EntryIntentFailed, intents.status(clientOrderID))
+		assert.Nil(t, service.positions.long)
+	})
+
+	t.Run("startup settles entries whose position was saved", func(t *testing.T) {
+		service, exchange, _, intents := newService(t)
+		pendingIntent(intents)
+		exchange.ordersByClient = map[string]*ports.OrderResponse{clientOrderID: filled}
+		saved := &domain.Position{ID: 7, Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.1, Status: domain.StatusOpen}
+		service.positions.Track(saved)
+
+		require.NoError(t, service.reconcileEntryIntents(context.Background()))
+		assert.Equal(t, domain.EntryIntentOpened, intents.status(clientOrderID))
+		assert.Same(t, saved, service.positions.long)
+	})
+
+	t.Run("startup adopts a fill the exchange still holds", func(t *testing.T) {
+		service, exchange, posRepo, intents := newService(t)
+		pendingIntent(intents)
+		exchange.ordersByClient = map[string]*ports.OrderResponse{clientOrderID: filled}
+		exchange.positionRisk = &ports.PositionRisk{Symbol: "ETHUSDT", PositionAmt: 0.1, EntryPrice: 2000}
+
+		require.NoError(t, service.reconcileEntryIntents(context.Background()))
+		require.NotNil(t, service.positions.long)
+		pos := posRepo.positions["ETHUSDT"]
+		require.NotNil(t, pos)
+		assert.Equal(t, 0.1, pos.Quantity)
+		assert.InDelta(t, 1960, pos.StopLoss, 1e-9)
+		assert.InDelta(t, 2100, pos.TakeProfit, 1e-9)
+		assert.Equal(t, "2", *pos.StopLossOrderID)
+		assert.True(t, pos.EntryTime.Equal(filled.Timestamp))
+		assert.Equal(t, domain.EntryIntentOpened, intents.status(clientOrderID))
+		assert.Empty(t, exchange.clientOrderIDs, "no new entry order expected")
+	})
+
+	t.Run("startup adopts a hedge mode short next to an open long", func(t *testing.T) {
+		service, exchange, _, intents := newService(t)
+		service.cfg.HedgeMode = true
+		t.Cleanup(func() { service.cfg.HedgeMode = false }) // cfg is shared by the subtests
+		shortID := domain.EntryClientOrderID("ETHUSDT", domain.PositionSideShort, klineOpen)
+		require.NoError(t, intents.SaveEntryIntent(context.Background(), &domain.EntryIntent{
+			ClientOrderID: shortID, Symbol: "ETHUSDT", Side: domain.PositionSideShort,
+			KlineOpenTime: klineOpen, Quantity: 0.1, Status: domain.EntryIntentPending,
+		}))
+		exchange.ordersByClient = map[string]*ports.OrderResponse{shortID: {OrderID: 4, Symbol: "ETHUSDT", ClientOrderID: shortID,
+			ExecutedQty: 0.1, AvgPrice: 2000, Status: "FILLED", Timestamp: klineOpen.Add(time.Minute)}}
+		exchange.orderResponses["stop_BUY"] = &ports.OrderResponse{OrderID: 5, Status: "NEW"}
+		exchange.orderResponses["tp_BUY"] = &ports.OrderResponse{OrderID: 6, Status: "NEW"}
+		// The exchange reports the long side first
+		exchange.positionRisk = &ports.PositionRisk{Symbol: "ETHUSDT", PositionAmt: 0.2, EntryPrice: 1900, PositionSide: domain.PositionSideLong}
+		exchange.otherSideRisk = &ports.PositionRisk{Symbol: "ETHUSDT", PositionAmt: -0.1, EntryPrice: 2000, PositionSide: domain.PositionSideShort}
+
+		require.NoError(t, service.reconcileEntryIntents(context.Background()))
+		require.NotNil(t, service.positions.short)
+		assert.Equal(t, 0.1, service.positions.short.Quantity)
+		assert.Equal(t, "5", *service.positions.short.StopLossOrderID)
+		assert.Equal(t, domain.EntryIntentOpened, intents.status(shortID))
+	})
+
+	t.Run("startup skips fills that were closed in the meantime", func(t *testing.T) {
+		service, exchange, _, intents := newService(t)
+		pendingIntent(intents)
+		exchange.ordersByClient = map[string]*ports.OrderResponse{clientOrderID: filled}
+
+		require.NoError(t, service.reconcileEntryIntents(context.Background()))
+		assert.Nil(t, service.positions.long)
+		assert.Equal(t, domain.EntryIntentFailed, intents.status(clientOrderID))
+	})
+
+	t.Run("startup fails when the outcome is unknown", func(t *testing.T) {
+		service, exchange, _, intents := newService(t)
+		pendingIntent(intents)
+		exchange.getOrderErr = ports.ErrExchangeUnavailable
+
+		err := service.reconcileEntryIntents(context.Background())
+		assert.ErrorIs(t, err, ports.ErrExchangeUnavailable)
+		assert.Equal(t, domain.EntryIntentPending, intents.status(clientOrderID))
+	})
+}
@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// EntrySizer sizes entries and scale-in adds: it scales the configured quantity by the drawdown
+// throttle, the win/loss streak and the trading calendar, caps it to the 24h volume, fits the
+// leverage to the symbol's brackets and the quantity to the available balance, and refuses sizes
+// beyond the daily volume caps or the maximum exposure. Apart from leverage changes it sends no
+// orders; TradingService places the entries it sizes. It isn't safe for concurrent use: the
+// service calls it with its lock held.
+type EntrySizer struct {
+	cfg       *config.Config
+	logger    ports.Logger
+	exchange  ports.ExchangeClient
+	clock     ports.Clock
+	positions positionBook
+	limits    limitEntryBook
+
+	riskMgr      *risk.RiskManager           // Optional: throttles size during drawdowns, caps it to the 24h volume and exposure
+	streakSizer  *risk.StreakSizer           // Optional: scales size by the win/loss streak
+	calendar     *risk.TradingCalendar       // Optional: scales size by weekday/date
+	scaleIn      domain.ScaleInPlan          // Optional: the zero plan enters the full quantity at once
+	balanceCheck *BalanceCheckConfig         // Optional: fits entries to the available balance
+	dailyVolume  *risk.DailyVolumeCap        // Optional: refuses sizes beyond the day's caps
+	volumeRepo   ports.DailyVolumeRepository // Persists the day's totals of the volume caps
+
+	// Leverage bracket awareness (optional)
+	bracketAware bool
+	brackets     []ports.LeverageBracket // Symbol's brackets fetched on Start; nil if unavailable
+	leverage     int                     // Leverage set on the exchange; 0 until adjusted to a bracket
+
+	quoteVolumeAt time.Time // When the 24h quote volume of the volume cap was last fetched
+}
+
+// NewEntrySizer creates an entry sizer of the configured quantity without risk components; the
+// service's options add them.
+func NewEntrySizer(cfg *config.Config, logger ports.Logger, exchange ports.ExchangeClient) *EntrySizer {
+	return &EntrySizer{cfg: cfg, logger: logger, exchange: exchange, clock: clock.Real{}}
+}
+
+// attach connects the sizer to the service's clock and to the positions and resting limit
+// entries whose exposure it limits.
+func (z *EntrySizer) attach(clock ports.Clock, positions positionBook, limits limitEntryBook) {
+	z.clock = clock
+	z.positions = positions
+	z.limits = limits
+}
+
+// EntrySize returns the quantity and leverage of an entry at entryPrice, fitted to the leverage
+// brackets, the available balance and the daily volume caps. op prefixes its logs and errors.
+func (z *EntrySizer) EntrySize(ctx context.Context, op string, entryPrice float64) (float64, int, error) {
+	// 1. Quantity (Fixed from config, scaled down during drawdowns if a risk manager is set, scaled
+	// by the win/loss streak if streak sizing is set and by the day's trading calendar factor, and
+	// converted at the entry price if it's given in the quote currency)
+	quantity := z.cfg.Quantity
+	if z.riskMgr != nil {
+		quantity = z.riskMgr.ApplyThrottle(quantity)
+		if factor := z.riskMgr.ThrottleFactor(); factor < 1.0 {
+			z.logger.Info(ctx, op+": Position size throttled by drawdown", map[string]interface{}{
+				"drawdown":     z.riskMgr.GetStats().CurrentDrawdown,
+				"factor":       factor,
+				"baseQuantity": z.cfg.Quantity,
+				"quantity":     quantity,
+			})
+		}
+		if quantity <= 0 {
+			return 0, 0, fmt.Errorf("%s: throttled quantity is zero at current drawdown", op)
+		}
+	}
+	if z.streakSizer != nil {
+		quantity = z.streakSizer.Apply(quantity)
+		if factor := z.streakSizer.Factor(); factor != 1.0 {
+			z.logger.Info(ctx, op+": Position size scaled by win/loss streak", map[string]interface{}{
+				"streak":   z.streakSizer.Streak(),
+				"factor":   factor,
+				"quantity": quantity,
+			})
+		}
+	}
+	now := z.clock.Now()
+	if factor := z.calendar.Factor(now); factor != 1.0 {
+		quantity *= factor
+		z.logger.Info(ctx, op+": Position size scaled by the trading calendar", map[string]interface{}{
+			"day":      now.UTC().Weekday().String(),
+			"factor":   factor,
+			"quantity": quantity,
+		})
+	}
+	// With scale-in entries only the initial share is entered on the signal
+	quantity = z.scaleIn.InitialQuantity(quantity)
+	quantity = z.cfg.BaseQuantity(quantity, entryPrice)
+	// Keep the notional within the risk manager's share of the 24h volume
+	quantity, err := z.capToVolume(ctx, op, 0, quantity, entryPrice)
+	if err != nil {
+		return 0, 0, err
+	}
+	quantity = z.cfg.OrderPrecision().RoundQuantity(quantity) // Record the size actually ordered
+	if quantity <= 0 {
+		return 0, 0, fmt.Errorf("%s: quantity is below the step size", op)
+	}
+	// 1.1 Keep the leverage within the bracket of the entry's notional
+	leverage, err := z.fitLeverage(ctx, quantity*entryPrice)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	// 1.2 Fit the order to the available balance
+	quantity, err = z.affordableQuantity(ctx, quantity, entryPrice)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := z.checkDailyVolume(quantity, entryPrice); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	// 1.3 Keep the open notional within the maximum exposure
+	if err := z.checkExposure(ctx, quantity, entryPrice); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return quantity, leverage, nil
+}
+
+// AddSize returns the quantity of the next scale-in add to pos at price and the leverage of the
+// position after it, scaled and capped like an entry. op prefixes its logs and errors.
+func (z *EntrySizer) AddSize(ctx context.Context, op string, pos *domain.Position, price float64) (float64, int, error) {
+	quantity := z.scaleIn.AddQuantity(z.cfg.Quantity)
+	if z.riskMgr != nil {
+		quantity = z.riskMgr.ApplyThrottle(quantity)
+	}
+	quantity = z.streakSizer.Apply(quantity)
+	quantity = z.calendar.Apply(quantity, z.clock.Now())
+	quantity = z.cfg.BaseQuantity(quantity, price)
+	quantity, err := z.capToVolume(ctx, op, pos.Quantity, quantity, price)
+	if err != nil {
+		return 0, 0, err
+	}
+	quantity = z.cfg.OrderPrecision().RoundQuantity(quantity) // Record the size actually ordered
+	if quantity <= 0 {
+		return 0, 0, fmt.Errorf("%s: add quantity is below the step size", op)
+	}
+	if err := z.checkDailyVolume(quantity, price); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := z.checkExposure(ctx, quantity, price); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	leverage, err := z.fitLeverage(ctx, (pos.Quantity+quantity)*price)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return quantity, leverage, nil
+}
+
+// ScaleIn returns the scale-in plan entries are sized by.
+func (z *EntrySizer) ScaleIn() domain.ScaleInPlan {
+	return z.scaleIn
+}
+
+// UpdateEquity feeds the current equity into the risk manager's drawdown throttle.
+func (z *EntrySizer) UpdateEquity(ctx context.Context, equity float64) {
+	if z.riskMgr != nil {
+		z.riskMgr.UpdateEquity(ctx, equity)
+	}
+}
+
+// RecordResult feeds a closed position's PnL into the win/loss streak.
+func (z *EntrySizer) RecordResult(pnl float64) {
+	z.streakSizer.Record(pnl)
+}
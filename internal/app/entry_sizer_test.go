@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestEntrySizer_EntrySize(t *testing.T) {
+	ctx := context.Background()
+	saturday := time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC)
+	newSizer := func(quantity float64, book *entryBook, exchange *mockExchange) *EntrySizer {
+		cfg := &config.Config{Symbol: "ETHUSDT", Quantity: quantity, Leverage: 10}
+		sizer := NewEntrySizer(cfg, &mockLogger{}, exchange)
+		sizer.attach(clock.NewFake(saturday), book, book)
+		return sizer
+	}
+
+	t.Run("configured quantity at the configured leverage", func(t *testing.T) {
+		quantity, leverage, err := newSizer(1, &entryBook{}, &mockExchange{}).EntrySize(ctx, "test", 2000)
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, quantity)
+		assert.Equal(t, 10, leverage)
+	})
+
+	t.Run("calendar factor and scale-in share", func(t *testing.T) {
+		calendar, err := risk.ParseTradingCalendar("SAT:0.5")
+		require.NoError(t, err)
+		sizer := newSizer(1, &entryBook{}, &mockExchange{})
+		sizer.calendar = calendar
+		sizer.scaleIn = domain.ScaleInPlan{InitialFraction: 0.5, Steps: []float64{0.003, 0.006}}
+		quantity, _, err := sizer.EntrySize(ctx, "test", 2000)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.25, quantity, 1e-9)
+	})
+
+	t.Run("quantity below the step size", func(t *testing.T) {
+		_, _, err := newSizer(0.0004, &entryBook{}, &mockExchange{}).EntrySize(ctx, "test", 2000)
+		assert.ErrorContains(t, err, "test: quantity is below the step size")
+	})
+
+	t.Run("resting limit entries count towards the exposure", func(t *testing.T) {
+		book := &entryBook{}
+		sizer := newSizer(1, book, &mockExchange{balance: 1000})
+		sizer.riskMgr = risk.NewRiskManager(risk.RiskConfig{MaxExposurePct: 2})
+		_, _, err := sizer.EntrySize(ctx, "test", 2000)
+		require.NoError(t, err)
+
+		// 2000 notional at 4x holds 500 margin: 4000 of 1500 equity with the new entry
+		book.pending = []*pendingLimitEntry{{side: domain.PositionSideShort, price: 2000, quantity: 1, leverage: 4}}
+		_, _, err = sizer.EntrySize(ctx, "test", 2000)
+		assert.ErrorIs(t, err, risk.ErrMaxExposure)
+	})
+}
+
+func TestEntrySizer_AddSize(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, Leverage: 10}
+	pos := &domain.Position{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000, Quantity: 0.5}
+	newSizer := func(limit *risk.DailyVolumeCap) *EntrySizer {
+		sizer := NewEntrySizer(cfg, &mockLogger{}, &mockExchange{})
+		sizer.attach(clock.NewFake(time.Now()), &entryBook{long: pos}, &entryBook{})
+		sizer.scaleIn = domain.ScaleInPlan{InitialFraction: 0.5, Steps: []float64{0.003, 0.006}}
+		sizer.dailyVolume = limit
+		return sizer
+	}
+
+	quantity, leverage, err := newSizer(nil).AddSize(ctx, "add", pos, 2000)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.25, quantity, 1e-9, "each of the 2 adds is a quarter of the full size")
+	assert.Equal(t, 10, leverage)
+
+	_, _, err = newSizer(risk.NewDailyVolumeCap(risk.DailyVolumeConfig{MaxNotional: 400})).AddSize(ctx, "add", pos, 2000)
+	assert.ErrorIs(t, err, errDailyVolumeCap)
+}
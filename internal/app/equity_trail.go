@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// WithEquityTrail locks in the day's profit: once the account's realized+unrealized profit for
+// the UTC day reaches the trail's target, a floor trails the peak, and when equity falls back to
+// it the open positions are market-closed and no new entries are made until UTC midnight.
+func WithEquityTrail(trail *risk.EquityTrail) Option {
+	return func(s *TradingService) {
+		s.gate.equityTrail = trail
+	}
+}
+
+// currentEquity returns the starting balance plus the realized and unrealized PnL at price.
+// Assumes the caller holds the lock.
+func (s *TradingService) currentEquity(price float64) float64 {
+	equity := s.startingEquity + s.realizedPnL
+	for _, pos := range s.positions.OpenPositions() {
+		equity += pos.UnrealizedPnL(price)
+	}
+	return equity
+}
+
+// restoreEquityTrail starts the trail from the equity the day started with, balance less the PnL
+// of the positions closed today, and replays those closes so a restart keeps the day's floor and
+// a locked-in day stays locked. Profit peaks reached only unrealized are not recovered. Failures
+// are logged and the trail starts from balance.
+func (s *TradingService) restoreEquityTrail(ctx context.Context, balance float64) {
+	now := s.now()
+	dayStart := now.UTC().Truncate(24 * time.Hour)
+	closed, err := s.tradeRepo.FindClosedBetween(ctx, s.cfg.Symbol, dayStart, now)
+	if err != nil {
+		s.logger.Error(ctx, err, "Failed to load today's trades for the equity trail, starting it from the current balance")
+		s.gate.equityTrail.Update(balance, now)
+		return
+	}
+
+	equity := balance
+	for _, pos := range closed {
+		equity -= pos.PNL
+	}
+	s.gate.equityTrail.Update(equity, dayStart)
+	for _, pos := range closed {
+		equity += pos.PNL
+		s.gate.equityTrail.Update(equity, pos.ExitTime)
+	}
+	status := s.gate.equityTrail.Status(now)
+	s.logger.Info(ctx, "Equity trail enabled", map[string]interface{}{
+		"dayStartEquity": status.DayStartEquity,
+		"dailyProfit":    status.DailyProfit,
+		"armed":          status.Armed,
+		"locked":         status.Locked,
+	})
+}
+
+// checkEquityTrail feeds the current equity into the equity trail, logging and notifying when it
+// locks in the day, and market-closes the open positions while the day is locked. Reports whether
+// any close was attempted; positions it fails to close are retried on the next kline. Assumes the
+// caller holds the lock.
+func (s *TradingService) checkEquityTrail(ctx context.Context, price float64, now time.Time) bool {
+	if s.gate.equityTrail == nil {
+		return false
+	}
+	if s.gate.equityTrail.Update(s.currentEquity(price), now) {
+		status := s.gate.equityTrail.Status(now)
+		s.logger.Warn(ctx, "Equity trail locked in the day, flattening and pausing entries until midnight UTC", map[string]interface{}{
+			"symbol":         s.cfg.Symbol,
+			"equity":         status.CurrentEquity,
+			"floor":          status.Floor,
+			"dayStartEquity": status.DayStartEquity,
+			"peakProfit":     status.PeakProfit,
+		})
+		s.publish(ctx, ports.Event{Type: ports.EventRiskLimitBreached, Reason: "equity trail: " + status.Reason})
+		s.notify(ctx, fmt.Sprintf("%s equity trail locked in", s.cfg.Symbol),
+			fmt.Sprintf("Daily profit peaked at %.2f and fell back to %.2f (equity %.2f). Open positions are closed and trading stops until midnight UTC.",
+				status.PeakProfit, status.DailyProfit, status.CurrentEquity), nil)
+	}
+	if locked, _ := s.gate.equityTrail.Locked(now); !locked {
+		return false
+	}
+
+	open := s.positions.OpenPositions()
+	for _, pos := range open {
+		s.logger.Info(ctx, "Equity trail locked in, closing position", map[string]interface{}{"positionID": pos.ID, "side": pos.PositionSide()})
+		if err := s.closePosition(ctx, pos, price, domain.CloseReasonEquityTrail); err != nil {
+			s.logger.Error(ctx, err, "Failed to close position after the equity trail locked in, will retry", map[string]interface{}{"positionID": pos.ID})
+			s.resyncOnClockSkew(err)
+			s.observeExchangeError(ctx, err)
+		}
+	}
+	return len(open) > 0
+}
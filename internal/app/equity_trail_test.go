@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_EquityTrail(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 1}
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	newService := func(t *testing.T, exchange *mockExchange, tradeRepo *mockTradeRepo) (*TradingService, *mockNotifier) {
+		trail, err := risk.NewEquityTrail(risk.EquityTrailConfig{Target: 0.02, Giveback: 0.5})
+		require.NoError(t, err)
+		notifier := &mockNotifier{}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			tradeRepo, &mockStrategy{}, WithEquityTrail(trail), WithClock(clock.NewFake(now)), WithNotifier(notifier))
+		require.NoError(t, err)
+		return service, notifier
+	}
+
+	t.Run("giving back the day's profit flattens and stops entries", func(t *testing.T) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{"market_SELL": {OrderID: 7, AvgPrice: 2015}}}
+		service, notifier := newService(t, exchange, &mockTradeRepo{})
+		service.startingEquity = 1000
+		service.gate.equityTrail.Update(1000, now)
+		service.positions.long = &domain.Position{ID: 1, Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000, Quantity: 1, Status: domain.StatusOpen, EntryTime: now}
+
+		assert.False(t, service.checkEquityTrail(ctx, 2030, now), "armed at +30, floor at 1015")
+		assert.NotNil(t, service.positions.long)
+		assert.True(t, service.checkEquityTrail(ctx, 2015, now))
+		assert.Nil(t, service.positions.long)
+		assert.Equal(t, domain.CloseReasonEquityTrail, service.gate.lastExitReason)
+
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Contains(t, reason, "equity trail locked in")
+		status := service.Status(ctx).EquityTrail
+		require.NotNil(t, status)
+		assert.True(t, status.Locked)
+
+		service.notifications.Wait()
+		notifier.mu.Lock()
+		assert.Contains(t, notifier.subjects, "ETHUSDT equity trail locked in")
+		notifier.mu.Unlock()
+
+		require.NoError(t, service.ResumeTrading(ctx))
+		ok, _ = service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+	})
+
+	t.Run("a restart rebuilds the day from its closed trades", func(t *testing.T) {
+		tradeRepo := &mockTradeRepo{trades: []*domain.Position{
+			{ID: 1, Symbol: "ETHUSDT", PNL: 40, ExitTime: now.Add(-3 * time.Hour)},
+			{ID: 2, Symbol: "ETHUSDT", PNL: -25, ExitTime: now.Add(-2 * time.Hour)},
+			{ID: 3, Symbol: "ETHUSDT", PNL: 90, ExitTime: now.Add(-30 * time.Hour)}, // Yesterday
+		}}
+		service, _ := newService(t, &mockExchange{}, tradeRepo)
+		service.restoreEquityTrail(ctx, 1015)
+
+		status := service.gate.equityTrail.Status(now)
+		assert.Equal(t, 1000.0, status.DayStartEquity)
+		assert.True(t, status.Locked, "the day peaked at +40 and fell back to +15, below the floor at +20")
+	})
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// EventBus is an in-process ports.EventBus. Handlers run synchronously in the publisher's
+// goroutine, in subscription order. The trading service publishes while holding its lock, so
+// handlers must be quick and must not call back into the service; slow work such as sending a
+// notification belongs in a goroutine. A handler that panics is logged and skipped.
+type EventBus struct {
+	logger ports.Logger
+
+	mu            sync.RWMutex
+	subscriptions []*subscription
+}
+
+// subscription is a handler and the event types it receives (every type if types is empty).
+type subscription struct {
+	handler ports.EventHandler
+	types   map[ports.EventType]bool
+}
+
+// NewEventBus creates an event bus without subscribers.
+func NewEventBus(logger ports.Logger) *EventBus {
+	return &EventBus{logger: logger}
+}
+
+// Publish delivers event to the handlers subscribed to its type (implements ports.EventBus).
+func (b *EventBus) Publish(ctx context.Context, event ports.Event) {
+	b.mu.RLock()
+	subscriptions := b.subscriptions
+	b.mu.RUnlock()
+
+	for _, sub := range subscriptions {
+		if len(sub.types) > 0 && !sub.types[event.Type] {
+			continue
+		}
+		b.deliver(ctx, sub.handler, event)
+	}
+}
+
+// deliver runs handler, recovering from a panic so one subscriber can't break the others.
+func (b *EventBus) deliver(ctx context.Context, handler ports.EventHandler, event ports.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error(ctx, fmt.Errorf("panic: %v", r), "Event handler failed", map[string]interface{}{"event": event.Type})
+		}
+	}()
+	handler(ctx, event)
+}
+
+// Subscribe registers handler for events of the given types, or every event if none are given
+// (implements ports.EventBus).
+func (b *EventBus) Subscribe(handler ports.EventHandler, types ...ports.EventType) func() {
+	sub := &subscription{handler: handler, types: make(map[ports.EventType]bool, len(types))}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Copy on write, so Publish can iterate without holding the lock
+	b.subscriptions = append(append([]*subscription(nil), b.subscriptions...), sub)
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subscriptions {
+			if s == sub {
+				b.subscriptions = append(append([]*subscription(nil), b.subscriptions[:i]...), b.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// WithEventBus publishes the service's events on bus instead of a private one, e.g. to share
+// it with other components. Subscribers can also be attached through Events.
+func WithEventBus(bus ports.EventBus) Option {
+	return func(s *TradingService) {
+		s.events = bus
+	}
+}
+
+// Events returns the bus the service publishes its trading events on.
+func (s *TradingService) Events() ports.EventBus {
+	return s.events
+}
+
+// subscribeInternal attaches the service's own subsystems that react to trading events.
+func (s *TradingService) subscribeInternal() {
+	s.events.Subscribe(s.onKlineReceived, ports.EventKlineReceived)
+	s.events.Subscribe(s.onPositionClosed, ports.EventPositionClosed)
+	if s.notifier != nil {
+		s.events.Subscribe(s.notifyPositionEvent, ports.EventPositionOpened, ports.EventPositionClosed)
+	}
+}
+
+// publish stamps event with the symbol and current time (unless set) and publishes it.
+func (s *TradingService) publish(ctx context.Context, event ports.Event) {
+	if event.Symbol == "" {
+		event.Symbol = s.cfg.Symbol
+	}
+	if event.Time.IsZero() {
+		event.Time = s.now().UTC()
+	}
+	s.events.Publish(ctx, event)
+}
+
+// publishPosition publishes a position event with a snapshot of pos.
+func (s *TradingService) publishPosition(ctx context.Context, eventType ports.EventType, pos *domain.Position) {
+	snapshot := *pos
+	s.publish(ctx, ports.Event{Type: eventType, Side: pos.PositionSide(), Position: &snapshot})
+}
+
+// onKlineReceived records the equity point of a new kline for the dashboard.
+// Runs with the service's lock held by the publisher.
+func (s *TradingService) onKlineReceived(ctx context.Context, event ports.Event) {
+	s.recordEquityPoint(event.Kline)
+}
+
+// onPositionClosed adds a closed position's result to the win/loss streak.
+// Runs with the service's lock held by the publisher.
+func (s *TradingService) onPositionClosed(ctx context.Context, event ports.Event) {
+	s.sizer.RecordResult(event.Position.PNL)
+}
+
+// notifyPositionEvent sends the entry or exit notification of a position event.
+func (s *TradingService) notifyPositionEvent(ctx context.Context, event ports.Event) {
+	var subject, message string
+	var err error
+	if event.Type == ports.EventPositionOpened {
+		subject, message, err = FormatEntryNotification(event.Position)
+	} else {
+		subject, message, err = FormatExitNotification(event.Position)
+	}
+	s.notify(ctx, subject, message, err)
+}
+
+// LogEvents returns an event handler writing every event to logger, e.g. as an audit trail.
+// Klines are logged at debug level, everything else at info.
+func LogEvents(logger ports.Logger) ports.EventHandler {
+	return func(ctx context.Context, event ports.Event) {
+		fields := map[string]interface{}{"event": event.Type, "symbol": event.Symbol, "time": event.Time}
+		switch event.Type {
+		case ports.EventKlineReceived:
+			fields["close"] = event.Kline.Close
+			fields["openTime"] = event.Kline.OpenTime
+			logger.Debug(ctx, "Trading event", fields)
+			return
+		case ports.EventSignalGenerated:
+			fields["side"], fields["exit"], fields["price"] = event.Side, event.Exit, event.Price
+			if event.Reason != "" {
+				fields["reason"] = event.Reason
+			}
+		case ports.EventOrderPlaced, ports.EventOrderFilled:
+			fields["side"], fields["exit"], fields["orderID"], fields["quantity"] = event.Side, event.Exit, event.OrderID, event.Quantity
+			if event.Type == ports.EventOrderFilled {
+				fields["price"] = event.Price
+			}
+		case ports.EventPositionOpened:
+			fields["side"], fields["positionID"], fields["entryPrice"], fields["quantity"] = event.Side, event.Position.ID, event.Position.EntryPrice, event.Position.Quantity
+		case ports.EventPositionClosed:
+			fields["side"], fields["positionID"], fields["exitPrice"], fields["pnl"], fields["reason"] = event.Side, event.Position.ID, event.Position.ExitPrice, event.Position.PNL, event.Position.CloseReason
+		case ports.EventRiskLimitBreached:
+			fields["reason"] = event.Reason
+		}
+		logger.Info(ctx, "Trading event", fields)
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestEventBus(t *testing.T) {
+	ctx := context.Background()
+	bus := NewEventBus(&mockLogger{})
+
+	var all, closed []ports.EventType
+	bus.Subscribe(func(ctx context.Context, event ports.Event) { panic("broken subscriber") }, ports.EventPositionOpened)
+	unsubscribe := bus.Subscribe(func(ctx context.Context, event ports.Event) { all = append(all, event.Type) })
+	bus.Subscribe(func(ctx context.Context, event ports.Event) { closed = append(closed, event.Type) }, ports.EventPositionClosed)
+
+	bus.Publish(ctx, ports.Event{Type: ports.EventPositionOpened})
+	bus.Publish(ctx, ports.Event{Type: ports.EventPositionClosed})
+	unsubscribe()
+	bus.Publish(ctx, ports.Event{Type: ports.EventPositionClosed})
+
+	// The panicking subscriber doesn't stop delivery to the others
+	assert.Equal(t, []ports.EventType{ports.EventPositionOpened, ports.EventPositionClosed}, all)
+	assert.Equal(t, []ports.EventType{ports.EventPositionClosed, ports.EventPositionClosed}, closed)
+}
+
+func TestTradingService_PublishesTradingEvents(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 1}
+	exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+		"market_BUY":  {OrderID: 1, AvgPrice: 2000, ExecutedQty: 1},
+		"stop_SELL":   {OrderID: 2},
+		"tp_SELL":     {OrderID: 3},
+		"market_SELL": {OrderID: 4, AvgPrice: 2100, ExecutedQty: 1},
+	}}
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+		&mockTradeRepo{}, &mockStrategy{})
+	require.NoError(t, err)
+
+	var events []ports.Event
+	service.Events().Subscribe(func(ctx context.Context, event ports.Event) { events = append(events, event) })
+
+	ctx := context.Background()
+	require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+	require.NoError(t, service.closePosition(ctx, service.positions.long, 2100, domain.CloseReasonTakeProfit))
+
+	var types []ports.EventType
+	for _, event := range events {
+		assert.Equal(t, "ETHUSDT", event.Symbol)
+		assert.False(t, event.Time.IsZero())
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []ports.EventType{
+		ports.EventOrderPlaced, ports.EventOrderFilled, ports.EventPositionOpened, ports.EventRiskLimitBreached,
+		ports.EventOrderPlaced, ports.EventOrderFilled, ports.EventPositionClosed,
+	}, types)
+
+	assert.False(t, events[0].Exit)
+	assert.Equal(t, int64(1), events[0].OrderID)
+	assert.Equal(t, 2000.0, events[1].Price)
+	assert.True(t, events[4].Exit)
+	assert.Equal(t, domain.CloseReasonTakeProfit, events[6].Position.CloseReason)
+	assert.Equal(t, 2100.0, events[6].Position.ExitPrice)
+}
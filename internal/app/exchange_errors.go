@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// userConfigAlertInterval is how often a recurring configuration error is alerted.
+const userConfigAlertInterval = time.Hour
+
+// observeExchangeError acts on an order or data request that failed, by the error's category:
+// transient outages count towards entering safe mode, and configuration errors (invalid API keys,
+// missing permissions), which no retry fixes, are sent as a critical notification at most once
+// per userConfigAlertInterval. Exchange rejections and permanent errors only concern the request
+// and are left to the caller. Assumes the caller holds the lock.
+func (s *TradingService) observeExchangeError(ctx context.Context, err error) {
+	switch ports.Category(err) {
+	case ports.CategoryTransient:
+		if s.safeMode != nil && isExchangeOutage(err) {
+			s.recordHealthCheck(ctx, err, s.now())
+		}
+	case ports.CategoryUserConfig:
+		now := s.now()
+		if !s.lastUserConfigAlert.IsZero() && now.Sub(s.lastUserConfigAlert) < userConfigAlertInterval {
+			return
+		}
+		s.lastUserConfigAlert = now
+		s.logger.Error(ctx, err, "Exchange request failed on the bot's configuration, operator action needed", map[string]interface{}{"symbol": s.cfg.Symbol})
+		s.notifyCritical(ctx, "Exchange request failed on the bot's configuration (check the API keys and their permissions)", err)
+	}
+}
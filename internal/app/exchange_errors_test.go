@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_ObserveExchangeError(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 1}
+	ctx := context.Background()
+	fake := clock.NewFake(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	notifier := &mockNotifier{}
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+		&mockTradeRepo{}, &mockStrategy{}, WithClock(fake), WithNotifier(notifier),
+		WithSafeMode(SafeModeConfig{CheckInterval: time.Minute, FailureThreshold: 2}, nil))
+	require.NoError(t, err)
+
+	// Rejections and permanent errors concern only their request
+	service.observeExchangeError(ctx, fmt.Errorf("place order: %w", ports.ErrInsufficientFunds))
+	service.observeExchangeError(ctx, ports.ErrOrderNotFound)
+	assert.Zero(t, service.exchangeFailures)
+
+	// Configuration errors are alerted, at most once an hour
+	keys := fmt.Errorf("place order: %w", ports.ErrInvalidAPIKeys)
+	service.observeExchangeError(ctx, keys)
+	fake.Advance(30 * time.Minute)
+	service.observeExchangeError(ctx, keys)
+	fake.Advance(31 * time.Minute)
+	service.observeExchangeError(ctx, fmt.Errorf("get balance: %w", ports.ErrPermissionDenied))
+	assert.Zero(t, service.exchangeFailures, "configuration errors aren't outages")
+
+	// Outages count towards safe mode
+	service.observeExchangeError(ctx, ports.ErrTimeout)
+	service.observeExchangeError(ctx, ports.ErrExchangeUnavailable)
+	assert.NotNil(t, service.safeModeEvent)
+
+	service.notifications.Wait()
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	var configAlerts int
+	for _, subject := range notifier.subjects {
+		if subject == "CRITICAL ETHUSDT: Exchange request failed on the bot's configuration (check the API keys and their permissions)" {
+			configAlerts++
+		}
+	}
+	assert.Equal(t, 2, configAlerts)
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"fmt"
+)
+
+// checkExposure returns an error wrapping risk.ErrMaxExposure if an entry of quantity at price
+// would take the notional of the open positions and resting limit entries above the risk
+// manager's maximum exposure. Equity is the live balance: the available balance plus the margin
+// already held by those positions and orders.
+func (z *EntrySizer) checkExposure(ctx context.Context, quantity, price float64) error {
+	if z.riskMgr == nil || !z.riskMgr.ExposureLimitEnabled() {
+		return nil
+	}
+	asset := z.cfg.MarginAsset()
+	if z.balanceCheck != nil {
+		asset = z.balanceCheck.Asset
+	}
+	balance, err := z.exchange.GetAccountBalance(ctx, asset)
+	if err != nil {
+		return fmt.Errorf("failed to get %s balance for the exposure check: %w", asset, err)
+	}
+	notional, margin := z.openExposure()
+	return z.riskMgr.CheckExposure(notional, z.cfg.Contract.Notional(price, quantity), balance+margin)
+}
+
+// openExposure returns the notional of the open positions and resting limit entries and the
+// margin they hold.
+func (z *EntrySizer) openExposure() (notional, margin float64) {
+	add := func(value float64, leverage int) {
+		if leverage < 1 {
+			leverage = 1
+		}
+		notional += value
+		margin += value / float64(leverage)
+	}
+	for _, pos := range z.positions.OpenPositions() {
+		add(pos.Contract.Notional(pos.EntryPrice, pos.Quantity), pos.Leverage)
+	}
+	for _, pending := range z.limits.pendingLimitEntries() {
+		add(z.cfg.Contract.Notional(pending.price, pending.quantity), pending.leverage)
+	}
+	return notional, margin
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_MaxExposure(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	newService := func(t *testing.T, exchange *mockExchange) *TradingService {
+		exchange.orderResponses = map[string]*ports.OrderResponse{
+			"market_BUY": {OrderID: 1, AvgPrice: 2000},
+			"stop_SELL":  {OrderID: 2},
+			"tp_SELL":    {OrderID: 3},
+		}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithRiskManager(risk.NewRiskManager(risk.RiskConfig{MaxExposurePct: 2})))
+		require.NoError(t, err)
+		return service
+	}
+
+	t.Run("entry within the limit", func(t *testing.T) {
+		exchange := &mockExchange{balance: 1000}
+		service := newService(t, exchange)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "1.000", exchange.marketOrderQty)
+	})
+
+	t.Run("open positions count towards the limit", func(t *testing.T) {
+		exchange := &mockExchange{balance: 1000}
+		service := newService(t, exchange)
+		// 2000 notional at 4x holds 500 margin: 4000 of 1500 equity with the new entry
+		service.positions.short = &domain.Position{Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2000, Quantity: 1, Leverage: 4}
+		err := service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now())
+		assert.ErrorIs(t, err, risk.ErrMaxExposure)
+		assert.Empty(t, exchange.marketOrderQty)
+	})
+
+	t.Run("entry fails closed without a balance", func(t *testing.T) {
+		exchange := &mockExchange{balanceErr: errors.New("timeout")}
+		service := newService(t, exchange)
+		err := service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now())
+		assert.ErrorContains(t, err, "balance for the exposure check")
+		assert.Empty(t, exchange.marketOrderQty)
+	})
+}
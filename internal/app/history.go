@@ -0,0 +1,142 @@
+package app
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// quantityEpsilon treats leftover quantities from float rounding as a flat position.
+const quantityEpsilon = 1e-9
+
+// incomeTypeFunding is the income history type of funding fee payments.
+const incomeTypeFunding = "FUNDING_FEE"
+
+// tradeBuilder accumulates the fills of one round trip, from a flat position back to flat.
+type tradeBuilder struct {
+	side          domain.PositionSide
+	entryQty      float64
+	entryNotional float64
+	exitQty       float64
+	exitNotional  float64
+	pnl           float64
+	trade         domain.Trade
+}
+
+// ReconstructTrades rebuilds closed round-trip trades from the account's fills. A trade opens
+// when a position (per symbol and hedge mode side) leaves flat and closes when it returns to flat;
+// in one-way mode a fill that flips the position closes one trade and opens the next. PNL is the
+// realized PnL minus commissions charged in the symbol's quote asset, plus the funding fees in
+// income booked while the trade was open. Fills closing positions opened before the first fill
+// are skipped. Positions still open at the end are not returned; their number is reported as open.
+func ReconstructTrades(fills []ports.AccountFill, income []ports.IncomeRecord) (trades []*domain.Trade, open int) {
+	sorted := append([]ports.AccountFill(nil), fills...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Time.Equal(sorted[j].Time) {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	building := make(map[string]*tradeBuilder) // Keyed by symbol and position side
+	for _, fill := range sorted {
+		if fill.Quantity <= 0 {
+			continue
+		}
+		key := fill.Symbol + "/" + string(fill.PositionSide)
+		if building[key] == nil && closesEarlierPosition(fill) {
+			continue
+		}
+		remaining := fill.Quantity
+		for remaining > quantityEpsilon {
+			b := building[key]
+			if b == nil {
+				b = &tradeBuilder{side: openingSide(fill), trade: domain.Trade{Symbol: fill.Symbol, EntryTime: fill.Time}}
+				building[key] = b
+			}
+			// The commission is split by the quantity attributed to each trade; realized PnL only
+			// comes from the closing part of a fill
+			if fill.Side == b.side.EntrySide() {
+				b.entryQty += remaining
+				b.entryNotional += remaining * fill.Price
+				b.pnl -= quoteCommission(fill) * remaining / fill.Quantity
+				remaining = 0
+				continue
+			}
+
+			closing := math.Min(remaining, b.entryQty-b.exitQty)
+			b.exitQty += closing
+			b.exitNotional += closing * fill.Price
+			b.pnl += fill.RealizedPnL - quoteCommission(fill)*closing/fill.Quantity
+			remaining -= closing
+			if b.entryQty-b.exitQty <= quantityEpsilon {
+				b.trade.ExitTime = fill.Time
+				trades = append(trades, b.finish())
+				delete(building, key)
+			}
+		}
+	}
+
+	addFunding(trades, income)
+	return trades, len(building)
+}
+
+// openingSide returns the side of a position opened by fill: the hedge mode side, or in one-way
+// mode the direction of the order.
+func openingSide(fill ports.AccountFill) domain.PositionSide {
+	if fill.PositionSide == domain.PositionSideLong || fill.PositionSide == domain.PositionSideShort {
+		return fill.PositionSide
+	}
+	if fill.Side == domain.Sell {
+		return domain.PositionSideShort
+	}
+	return domain.PositionSideLong
+}
+
+// closesEarlierPosition reports whether a fill with no position being tracked closes one opened
+// before the history starts: a hedge mode fill on the exit side, or a fill that realized PnL.
+func closesEarlierPosition(fill ports.AccountFill) bool {
+	if fill.PositionSide == domain.PositionSideLong || fill.PositionSide == domain.PositionSideShort {
+		return fill.Side == fill.PositionSide.ExitSide()
+	}
+	return fill.RealizedPnL != 0
+}
+
+// quoteCommission returns the fill's commission if it was charged in the symbol's quote asset.
+// Commissions paid in other assets (e.g., BNB) can't be converted and are left out.
+func quoteCommission(fill ports.AccountFill) float64 {
+	if fill.CommissionAsset != "" && strings.HasSuffix(fill.Symbol, fill.CommissionAsset) {
+		return fill.Commission
+	}
+	return 0
+}
+
+// finish builds the trade from the accumulated fills.
+func (b *tradeBuilder) finish() *domain.Trade {
+	trade := b.trade
+	trade.Side = b.side
+	trade.Quantity = b.entryQty
+	trade.EntryPrice = b.entryNotional / b.entryQty
+	trade.ExitPrice = b.exitNotional / b.exitQty
+	trade.PNL = b.pnl
+	trade.CloseReason = domain.CloseReasonUnknown
+	return &trade
+}
+
+// addFunding adds the funding fees booked while each trade was open to its PNL.
+func addFunding(trades []*domain.Trade, income []ports.IncomeRecord) {
+	for _, record := range income {
+		if record.IncomeType != incomeTypeFunding {
+			continue
+		}
+		for _, trade := range trades {
+			if trade.Symbol == record.Symbol && !record.Time.Before(trade.EntryTime) && !record.Time.After(trade.ExitTime) {
+				trade.PNL += record.Income
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconstructTrades(t *testing.T) {
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	fill := func(id int64, minutes int, side domain.OrderSide, positionSide domain.PositionSide, price, qty, pnl, commission float64) ports.AccountFill {
+		return ports.AccountFill{ID: id, Symbol: "ETHUSDT", Side: side, PositionSide: positionSide, Price: price, Quantity: qty,
+			RealizedPnL: pnl, Commission: commission, CommissionAsset: "USDT", Time: at(minutes)}
+	}
+
+	fills := []ports.AccountFill{
+		// Closes positions opened before the history starts
+		fill(8, -10, domain.Sell, domain.PositionSideLong, 1990, 0.3, 3, 0.1),
+		fill(9, -5, domain.Buy, domain.PositionSideBoth, 1995, 0.2, -2, 0.1),
+		// One-way long scaled in twice, closed in one fill that flips short
+		fill(1, 0, domain.Buy, domain.PositionSideBoth, 2000, 0.5, 0, 0.4),
+		fill(2, 5, domain.Buy, domain.PositionSideBoth, 2020, 0.5, 0, 0.4),
+		fill(3, 30, domain.Sell, domain.PositionSideBoth, 2050, 1.5, 40, 1.2),
+		// Short closed later
+		fill(4, 60, domain.Buy, domain.PositionSideBoth, 2040, 0.5, 5, 0.4),
+		// Hedge mode long still open at the end
+		fill(5, 70, domain.Buy, domain.PositionSideLong, 2045, 0.2, 0, 0.1),
+		// Commission in BNB isn't deducted
+		ports.AccountFill{ID: 6, Symbol: "BTCUSDT", Side: domain.Sell, PositionSide: domain.PositionSideShort, Price: 60000, Quantity: 0.01,
+			Commission: 0.001, CommissionAsset: "BNB", Time: at(80)},
+		ports.AccountFill{ID: 7, Symbol: "BTCUSDT", Side: domain.Buy, PositionSide: domain.PositionSideShort, Price: 59000, Quantity: 0.01,
+			RealizedPnL: 10, Commission: 0.001, CommissionAsset: "BNB", Time: at(90)},
+	}
+	income := []ports.IncomeRecord{
+		{Symbol: "ETHUSDT", IncomeType: "FUNDING_FEE", Income: -1.5, Time: at(20)},
+		{Symbol: "ETHUSDT", IncomeType: "FUNDING_FEE", Income: 0.5, Time: at(45)},
+		{Symbol: "ETHUSDT", IncomeType: "REALIZED_PNL", Income: 40, Time: at(30)}, // Already in the fills
+	}
+
+	// Fills arrive out of order
+	fills[2], fills[4] = fills[4], fills[2]
+	trades, open := ReconstructTrades(fills, income)
+	require.Len(t, trades, 3)
+	assert.Equal(t, 1, open)
+
+	long := trades[0]
+	assert.Equal(t, domain.PositionSideLong, long.Side)
+	assert.Equal(t, 1.0, long.Quantity)
+	assert.InDelta(t, 2010, long.EntryPrice, 1e-9)
+	assert.InDelta(t, 2050, long.ExitPrice, 1e-9)
+	assert.True(t, long.EntryTime.Equal(at(0)) && long.ExitTime.Equal(at(30)))
+	// The flipping fill's PnL and two thirds of its commission, entry commissions and the funding fee
+	assert.InDelta(t, 40-1.2*2/3.0-0.8-1.5, long.PNL, 1e-9)
+
+	short := trades[1]
+	assert.Equal(t, domain.PositionSideShort, short.Side)
+	assert.Equal(t, 0.5, short.Quantity)
+	assert.InDelta(t, 2050, short.EntryPrice, 1e-9)
+	assert.InDelta(t, 2040, short.ExitPrice, 1e-9)
+	assert.True(t, short.EntryTime.Equal(at(30)) && short.ExitTime.Equal(at(60)))
+	assert.InDelta(t, -1.2/3.0+5-0.4+0.5, short.PNL, 1e-9)
+
+	btc := trades[2]
+	assert.Equal(t, "BTCUSDT", btc.Symbol)
+	assert.Equal(t, domain.PositionSideShort, btc.Side)
+	assert.Equal(t, 10.0, btc.PNL)
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// WithMaxHoldingTime market-closes positions open for longer than max (which must be positive),
+// independently of the strategy's own time limit. Positions are checked on startup, so positions
+// that expired while the bot was down are closed right away, and on every kline.
+func WithMaxHoldingTime(max time.Duration) Option {
+	return func(s *TradingService) {
+		s.maxHolding = max
+	}
+}
+
+// holdingExpired reports whether pos has been open for longer than the maximum holding time at now.
+func (s *TradingService) holdingExpired(pos *domain.Position, now time.Time) bool {
+	return s.maxHolding > 0 && !pos.EntryTime.IsZero() && now.Sub(pos.EntryTime) > s.maxHolding
+}
+
+// closeExpiredPositions market-closes the open positions held past the maximum holding time with
+// reason TIME_LIMIT and reports whether any close was attempted. Positions it fails to close are
+// retried on the next kline. Assumes the caller holds the lock.
+func (s *TradingService) closeExpiredPositions(ctx context.Context, price float64, now time.Time) bool {
+	attempted := false
+	for _, pos := range s.positions.OpenPositions() {
+		if !s.holdingExpired(pos, now) {
+			continue
+		}
+		attempted = true
+		s.logger.Info(ctx, "Maximum holding time exceeded, closing position", map[string]interface{}{
+			"positionID": pos.ID,
+			"side":       pos.PositionSide(),
+			"entryTime":  pos.EntryTime,
+			"held":       now.Sub(pos.EntryTime).Round(time.Second).String(),
+			"maxHolding": s.maxHolding.String(),
+		})
+		if err := s.closePosition(ctx, pos, price, domain.CloseReasonTimeLimit); err != nil {
+			s.logger.Error(ctx, err, "Failed to close position past the maximum holding time, will retry", map[string]interface{}{"positionID": pos.ID})
+			s.resyncOnClockSkew(err)
+			s.observeExchangeError(ctx, err)
+		}
+	}
+	return attempted
+}
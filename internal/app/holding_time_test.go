@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_MaxHoldingTime(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	now := time.Now()
+	newService := func(t *testing.T, exchange *mockExchange) *TradingService {
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{},
+			WithMaxHoldingTime(time.Hour))
+		require.NoError(t, err)
+		return service
+	}
+
+	t.Run("positions held too long are closed regardless of the strategy", func(t *testing.T) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{"market_SELL": {OrderID: 4, AvgPrice: 2010}}}
+		service := newService(t, exchange)
+		service.positions.long = &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.1, StopLoss: 1980, Status: domain.StatusOpen, EntryTime: now.Add(-2 * time.Hour)}
+
+		service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Close: 2010, CloseTime: now, IsFinal: true})
+		assert.Nil(t, service.positions.long)
+		assert.Equal(t, domain.CloseReasonTimeLimit, service.gate.lastExitReason)
+	})
+
+	t.Run("positions within the holding time stay open", func(t *testing.T) {
+		service := newService(t, &mockExchange{})
+		pos := &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.1, StopLoss: 1980, Status: domain.StatusOpen, EntryTime: now.Add(-30 * time.Minute)}
+		service.positions.long = pos
+
+		assert.False(t, service.closeExpiredPositions(context.Background(), 2010, now))
+		assert.Same(t, pos, service.positions.long)
+		assert.True(t, service.holdingExpired(pos, now.Add(31*time.Minute)))
+	})
+}
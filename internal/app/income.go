@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// Income types of the exchange's income history that are accrued onto positions.
+const (
+	incomeFundingFee = "FUNDING_FEE"
+	incomeCommission = "COMMISSION"
+)
+
+// incomeEntryMargin is how long before a position's entry time its income is looked up from, so
+// the entry order's commission (booked when it filled, just before the position was recorded) counts.
+const incomeEntryMargin = 5 * time.Second
+
+// IncomeAccrualConfig configures the accrual of funding fees and commissions onto open positions.
+type IncomeAccrualConfig struct {
+	Interval time.Duration // How often the income history of open positions is pulled
+}
+
+// WithIncomeAccrual pulls the income history of open positions from the exchange (if the client
+// implements ports.IncomeHistoryProvider) every cfg.Interval and once more when they close, so
+// their stored PNL is net of the funding fees and commissions actually booked. Funding is added
+// to the position's Funding; commissions set its Fees unless order fills are recorded
+// (WithOrderFills), which attribute commissions per order. While both sides of a hedge mode
+// account are open, the symbol's income can't be told apart and is split by notional.
+func WithIncomeAccrual(cfg IncomeAccrualConfig) Option {
+	return func(s *TradingService) {
+		s.incomeAccrual = &cfg
+	}
+}
+
+// startIncomeAccrual starts pulling the income of open positions until ctx is canceled.
+func (s *TradingService) startIncomeAccrual(ctx context.Context) {
+	if s.incomeAccrual == nil {
+		return
+	}
+	if _, ok := s.exchange.(ports.IncomeHistoryProvider); !ok {
+		s.logger.Warn(ctx, "Exchange client doesn't provide the income history, funding and commissions won't be accrued")
+		return
+	}
+	go s.runIncomeAccrual(ctx)
+	s.logger.Info(ctx, "Funding and commission accrual started", map[string]interface{}{"interval": s.incomeAccrual.Interval.String()})
+}
+
+// runIncomeAccrual accrues the income of the open positions every interval until ctx is canceled.
+func (s *TradingService) runIncomeAccrual(ctx context.Context) {
+	ticker := time.NewTicker(s.incomeAccrual.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		for _, pos := range s.positions.OpenPositions() {
+			if s.accrueIncome(ctx, pos) {
+				if err := s.positions.Save(ctx, pos); err != nil {
+					s.logger.Error(ctx, err, "Failed to save accrued funding and commissions", map[string]interface{}{"positionID": pos.ID})
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// accrueIncome sets the position's funding, and its commissions unless order fills are recorded,
+// from the income booked since its entry. The whole period is summed again on every pull, so
+// restarts and overlapping pulls don't count income twice. Returns whether they changed; failures
+// are logged and keep the previous values. Assumes the caller holds the lock.
+func (s *TradingService) accrueIncome(ctx context.Context, pos *domain.Position) bool {
+	provider, ok := s.exchange.(ports.IncomeHistoryProvider)
+	if s.incomeAccrual == nil || !ok {
+		return false
+	}
+	records, err := provider.GetIncomeHistory(ctx, pos.Symbol, "", pos.EntryTime.Add(-incomeEntryMargin), s.now())
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to fetch income history of position", map[string]interface{}{
+			"positionID": pos.ID,
+			"error":      err.Error(),
+		})
+		return false
+	}
+
+	var funding, commission float64
+	for _, r := range records {
+		if !strings.HasSuffix(pos.Symbol, r.Asset) {
+			continue // Paid in another asset (e.g., BNB), can't be converted
+		}
+		switch r.IncomeType {
+		case incomeFundingFee:
+			funding += r.Income
+		case incomeCommission:
+			commission -= r.Income // Booked as negative income
+		}
+	}
+	share := s.incomeShare(pos)
+	funding *= share
+	commission *= share
+
+	changed := funding != pos.Funding
+	pos.Funding = funding
+	if s.fillRepo == nil && commission != pos.Fees {
+		pos.Fees = commission
+		changed = true
+	}
+	if changed {
+		s.logger.Debug(ctx, "Accrued funding and commissions of position", map[string]interface{}{
+			"positionID": pos.ID,
+			"funding":    pos.Funding,
+			"fees":       pos.Fees,
+		})
+	}
+	return changed
+}
+
+// incomeShare returns the share of the symbol's income that belongs to pos: all of it unless the
+// other side is open too, in which case it's split by notional. Assumes the caller holds the lock.
+func (s *TradingService) incomeShare(pos *domain.Position) float64 {
+	var total float64
+	for _, open := range s.positions.OpenPositions() {
+		total += open.EntryPrice * open.Quantity
+	}
+	if total <= 0 || len(s.positions.OpenPositions()) < 2 {
+		return 1
+	}
+	return pos.EntryPrice * pos.Quantity / total
+}
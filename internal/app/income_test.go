@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_IncomeAccrual(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	entry := time.Date(2025, 5, 1, 7, 30, 0, 0, time.UTC)
+	income := []ports.IncomeRecord{
+		{Symbol: "ETHUSDT", IncomeType: "COMMISSION", Income: -0.5, Asset: "USDT", Time: entry.Add(-time.Hour)}, // Previous position
+		{Symbol: "ETHUSDT", IncomeType: "COMMISSION", Income: -0.8, Asset: "USDT", Time: entry.Add(-time.Second)},
+		{Symbol: "ETHUSDT", IncomeType: "COMMISSION", Income: -0.001, Asset: "BNB", Time: entry.Add(-time.Second)},
+		{Symbol: "ETHUSDT", IncomeType: "FUNDING_FEE", Income: -1.5, Asset: "USDT", Time: entry.Add(30 * time.Minute)},
+		{Symbol: "ETHUSDT", IncomeType: "REALIZED_PNL", Income: 7, Asset: "USDT", Time: entry.Add(30 * time.Minute)},
+	}
+	newService := func(t *testing.T, opts ...Option) (*TradingService, *mockExchange, *mockPositionRepo) {
+		exchange := &mockExchange{income: income, orderResponses: map[string]*ports.OrderResponse{"market_SELL": {OrderID: 4, AvgPrice: 2010}}}
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		opts = append(opts, WithIncomeAccrual(IncomeAccrualConfig{Interval: time.Minute}), WithClock(clock.NewFake(entry.Add(time.Hour))))
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{}, opts...)
+		require.NoError(t, err)
+		service.positions.long = &domain.Position{ID: 1, Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000,
+			Quantity: 1, Leverage: 1, EntryTime: entry, Status: domain.StatusOpen}
+		return service, exchange, posRepo
+	}
+
+	t.Run("funding and commissions accrue onto the position", func(t *testing.T) {
+		service, _, posRepo := newService(t)
+		pos := service.positions.long
+		require.True(t, service.accrueIncome(ctx, pos))
+		assert.InDelta(t, -1.5, pos.Funding, 1e-9)
+		assert.InDelta(t, 0.8, pos.Fees, 1e-9)
+		assert.False(t, service.accrueIncome(ctx, pos), "pulling the same period again changes nothing")
+
+		require.NoError(t, service.closePosition(ctx, pos, 2010, domain.CloseReasonTakeProfit))
+		stored := posRepo.positions["ETHUSDT"]
+		require.NotNil(t, stored)
+		assert.InDelta(t, 7.7, stored.PNL, 1e-9) // 10 - 0.8 commission - 1.5 funding
+	})
+
+	t.Run("recorded fills keep their commissions", func(t *testing.T) {
+		service, _, _ := newService(t, WithOrderFills(&mockFillRepo{}))
+		pos := service.positions.long
+		pos.Fees = 0.9
+		require.True(t, service.accrueIncome(ctx, pos))
+		assert.InDelta(t, -1.5, pos.Funding, 1e-9)
+		assert.InDelta(t, 0.9, pos.Fees, 1e-9)
+	})
+
+	t.Run("hedge mode splits the income by notional", func(t *testing.T) {
+		service, _, _ := newService(t)
+		service.positions.short = &domain.Position{ID: 2, Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2000,
+			Quantity: 3, Leverage: 1, EntryTime: entry, Status: domain.StatusOpen}
+		require.True(t, service.accrueIncome(ctx, service.positions.long))
+		assert.InDelta(t, -0.375, service.positions.long.Funding, 1e-9)
+		assert.InDelta(t, 0.2, service.positions.long.Fees, 1e-9)
+	})
+
+	t.Run("failed pull keeps the previous values", func(t *testing.T) {
+		service, exchange, _ := newService(t)
+		exchange.incomeErr = assert.AnError
+		pos := service.positions.long
+		pos.Funding = -1
+		assert.False(t, service.accrueIncome(ctx, pos))
+		assert.Equal(t, -1.0, pos.Funding)
+	})
+}
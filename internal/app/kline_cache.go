@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// WithKlineCachePersistence saves the primary kline cache to repo every saveInterval and on
+// shutdown. On startup the service warm-starts from the saved klines and fetches only the
+// candles opened since the last save, instead of the full history, from the REST API.
+func WithKlineCachePersistence(repo ports.KlineCacheRepository, saveInterval time.Duration) Option {
+	return func(s *TradingService) {
+		s.klineStore = repo
+		s.klineSaveInterval = saveInterval
+	}
+}
+
+// loadInitialKlines returns the primary klines the strategy starts from: the saved cache topped
+// up with the missing recent klines if possible, otherwise the latest required klines from REST.
+func (s *TradingService) loadInitialKlines(ctx context.Context, required int) ([]*domain.Kline, error) {
+	if s.klineStore != nil {
+		if klines, ok := s.warmStartKlines(ctx, required, s.now()); ok {
+			return klines, nil
+		}
+	}
+	return s.exchange.GetKlines(ctx, s.cfg.Symbol, primaryInterval, required)
+}
+
+// warmStartKlines loads the saved kline cache and fetches the klines opened after its last
+// kline. Reports false, so the caller falls back to a full fetch, if nothing usable was saved,
+// the saved klines are older than a full cache or the top-up fails.
+func (s *TradingService) warmStartKlines(ctx context.Context, required int, now time.Time) ([]*domain.Kline, bool) {
+	saved, err := s.klineStore.LoadKlines(ctx, s.cfg.Symbol, primaryInterval)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to load saved kline cache, fetching full history", map[string]interface{}{"error": err.Error()})
+		return nil, false
+	}
+	if len(saved) == 0 {
+		return nil, false
+	}
+
+	// Klines opened since the last saved one, including the one forming at now
+	last := saved[len(saved)-1]
+	missing := int(now.Sub(last.OpenTime) / primaryIntervalDuration)
+	if missing < 0 || missing >= maxKlineCacheSize {
+		s.logger.Info(ctx, "Saved kline cache is too old for a warm start", map[string]interface{}{"lastOpenTime": last.OpenTime})
+		return nil, false
+	}
+
+	// One extra kline overlaps the last saved one, so a gap in the response shows up
+	recent, err := s.exchange.GetKlines(ctx, s.cfg.Symbol, primaryInterval, missing+1)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to top up saved kline cache, fetching full history", map[string]interface{}{"error": err.Error()})
+		return nil, false
+	}
+	if len(recent) == 0 || recent[0].OpenTime.After(last.OpenTime) {
+		s.logger.Warn(ctx, "Kline top-up doesn't continue the saved cache, fetching full history", map[string]interface{}{"lastOpenTime": last.OpenTime})
+		return nil, false
+	}
+
+	klines := mergeKlines(saved, recent)
+	if len(klines) < required {
+		return nil, false
+	}
+	if len(klines) > maxKlineCacheSize {
+		klines = klines[len(klines)-maxKlineCacheSize:]
+	}
+	s.logger.Info(ctx, "Warm-started kline cache", map[string]interface{}{"saved": len(saved), "fetched": len(recent), "count": len(klines)})
+	return klines, true
+}
+
+// mergeKlines appends recent to saved, with recent replacing the saved klines it overlaps.
+func mergeKlines(saved, recent []*domain.Kline) []*domain.Kline {
+	keep := len(saved)
+	for keep > 0 && !saved[keep-1].OpenTime.Before(recent[0].OpenTime) {
+		keep--
+	}
+	merged := make([]*domain.Kline, 0, keep+len(recent))
+	merged = append(merged, saved[:keep]...)
+	return append(merged, recent...)
+}
+
+// runKlineCacheSaver saves the kline cache every klineSaveInterval until ctx is canceled.
+func (s *TradingService) runKlineCacheSaver(ctx context.Context) {
+	ticker := time.NewTicker(s.klineSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.saveKlineCache(ctx)
+		}
+	}
+}
+
+// saveKlineCache saves a snapshot of the primary kline cache. Failures are only logged: the
+// next start falls back to a full fetch.
+func (s *TradingService) saveKlineCache(ctx context.Context) {
+	if s.klineStore == nil {
+		return
+	}
+	s.mu.Lock()
+	klines := append([]*domain.Kline(nil), s.signals.Klines()...)
+	s.mu.Unlock()
+	if len(klines) == 0 {
+		return
+	}
+	if err := s.klineStore.SaveKlines(ctx, s.cfg.Symbol, primaryInterval, klines); err != nil {
+		s.logger.Error(ctx, err, "Failed to save kline cache")
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+)
+
+// mockKlineStore implements ports.KlineCacheRepository in memory
+type mockKlineStore struct {
+	klines  []*domain.Kline
+	loadErr error
+	saves   int
+}
+
+func (m *mockKlineStore) SaveKlines(ctx context.Context, symbol, interval string, klines []*domain.Kline) error {
+	m.klines = append([]*domain.Kline(nil), klines...)
+	m.saves++
+	return nil
+}
+
+func (m *mockKlineStore) LoadKlines(ctx context.Context, symbol, interval string) ([]*domain.Kline, error) {
+	return m.klines, m.loadErr
+}
+
+// klineRun returns count consecutive 1m klines, the first opening at start
+func klineRun(start time.Time, count int, close float64) []*domain.Kline {
+	klines := make([]*domain.Kline, count)
+	for i := range klines {
+		open := start.Add(time.Duration(i) * time.Minute)
+		klines[i] = &domain.Kline{OpenTime: open, CloseTime: open.Add(time.Minute - time.Millisecond), Close: close, IsFinal: true}
+	}
+	return klines
+}
+
+func TestTradingService_KlineCacheWarmStart(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	now := time.Now().Truncate(time.Minute).Add(30 * time.Second)
+	newService := func(t *testing.T, store *mockKlineStore, exchange *mockExchange) *TradingService {
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithKlineCachePersistence(store, time.Minute))
+		require.NoError(t, err)
+		return service
+	}
+
+	t.Run("tops up the saved cache with the missing klines only", func(t *testing.T) {
+		// Saved up to 5 minutes ago; the top-up overlaps the last saved kline
+		store := &mockKlineStore{klines: klineRun(now.Truncate(time.Minute).Add(-24*time.Minute), 20, 100)}
+		exchange := &mockExchange{klines: klineRun(now.Truncate(time.Minute).Add(-5*time.Minute), 6, 200)}
+		service := newService(t, store, exchange)
+
+		klines, err := service.loadInitialKlines(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{6}, exchange.klineLimits)
+		require.Len(t, klines, 25)
+		assert.Equal(t, 100.0, klines[18].Close)
+		assert.Equal(t, 200.0, klines[19].Close, "overlapping kline replaced by the fetched one")
+		assert.True(t, klines[24].OpenTime.Equal(now.Truncate(time.Minute)))
+	})
+
+	t.Run("falls back to a full fetch without a usable saved cache", func(t *testing.T) {
+		full := klineRun(now.Truncate(time.Minute).Add(-9*time.Minute), 10, 300)
+		for name, store := range map[string]*mockKlineStore{
+			"nothing saved": {},
+			"load error":    {loadErr: errors.New("db locked")},
+			"too old":       {klines: klineRun(now.Add(-48*time.Hour), 20, 100)},
+		} {
+			exchange := &mockExchange{klines: full}
+			service := newService(t, store, exchange)
+			klines, err := service.loadInitialKlines(context.Background(), 10)
+			require.NoError(t, err, name)
+			assert.Equal(t, 300.0, klines[0].Close, name)
+			assert.Equal(t, 10, exchange.klineLimits[len(exchange.klineLimits)-1], name)
+		}
+	})
+
+	t.Run("saves a snapshot of the cache", func(t *testing.T) {
+		store := &mockKlineStore{}
+		service := newService(t, store, &mockExchange{})
+		service.saveKlineCache(context.Background())
+		assert.Zero(t, store.saves, "empty cache is not saved")
+
+		service.signals.klines = klineRun(now.Add(-time.Hour), 3, 100)
+		service.saveKlineCache(context.Background())
+		assert.Equal(t, 1, store.saves)
+		assert.Len(t, store.klines, 3)
+	})
+}
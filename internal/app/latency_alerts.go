@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+const (
+	// defaultLatencyCheckInterval is how often the latency statistics are sampled when
+	// LatencyAlertConfig doesn't set an interval.
+	defaultLatencyCheckInterval = 30 * time.Second
+	// defaultLatencyMinSamples is the number of recent requests an operation needs before its p95
+	// latency can raise an alert, so a handful of slow requests after startup don't.
+	defaultLatencyMinSamples = 20
+)
+
+// LatencyAlertConfig holds configuration for the exchange request latency alerts.
+type LatencyAlertConfig struct {
+	P95Threshold  time.Duration // p95 latency of an operation that triggers an alert
+	MinSamples    int           // Recent requests an operation needs before it is checked (0 uses 20)
+	CheckInterval time.Duration // How often the exchange client's statistics are sampled (0 uses 30s)
+}
+
+// WithLatencyAlerts samples the exchange client's request latency statistics (if it implements
+// ports.LatencyStatsProvider), reports them in the status and notifies the operator when the p95
+// latency of order placements, cancellations or kline fetches rises above P95Threshold, since
+// slower fills than the strategy was tuned for change which parameters are viable. The alert is
+// sent once per breach of each operation and the all-clear once it is back below the threshold.
+func WithLatencyAlerts(cfg LatencyAlertConfig) Option {
+	return func(s *TradingService) {
+		if cfg.MinSamples <= 0 {
+			cfg.MinSamples = defaultLatencyMinSamples
+		}
+		if cfg.CheckInterval <= 0 {
+			cfg.CheckInterval = defaultLatencyCheckInterval
+		}
+		s.latencyAlerts = &cfg
+		s.latencyAlerting = make(map[string]bool)
+	}
+}
+
+// runLatencyMonitor samples the latency statistics every CheckInterval until ctx is canceled.
+func (s *TradingService) runLatencyMonitor(ctx context.Context, provider ports.LatencyStatsProvider) {
+	ticker := time.NewTicker(s.latencyAlerts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		s.mu.Lock()
+		s.recordLatencyStats(ctx, provider.LatencyStats())
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordLatencyStats stores a sample of the latency statistics and raises or clears the alert of
+// each operation. Assumes the caller holds the lock.
+func (s *TradingService) recordLatencyStats(ctx context.Context, stats map[string]ports.OperationLatency) {
+	s.latencyStats = stats
+	threshold := s.latencyAlerts.P95Threshold
+
+	operations := make([]string, 0, len(stats))
+	for operation := range stats {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		latency := stats[operation]
+		p95 := time.Duration(latency.P95Ms) * time.Millisecond
+		switch {
+		case !s.latencyAlerting[operation] && latency.Samples >= s.latencyAlerts.MinSamples && p95 > threshold:
+			s.latencyAlerting[operation] = true
+			s.logger.Warn(ctx, "Exchange latency above the threshold", map[string]interface{}{
+				"symbol":    s.cfg.Symbol,
+				"operation": operation,
+				"p95":       p95.String(),
+				"p50":       (time.Duration(latency.P50Ms) * time.Millisecond).String(),
+				"max":       (time.Duration(latency.MaxMs) * time.Millisecond).String(),
+				"threshold": threshold.String(),
+				"samples":   latency.Samples,
+			})
+			s.notify(ctx, fmt.Sprintf("%s exchange latency high: %s p95 %s", s.cfg.Symbol, operation, p95),
+				fmt.Sprintf("Symbol: %s\nOperation: %s\np95 latency: %s (threshold %s)\np50: %s, p99: %s, max: %s over the last %d requests\nFailed requests: %d of %d\nFills may be slower than the strategy's parameters assume.",
+					s.cfg.Symbol, operation, p95, threshold,
+					time.Duration(latency.P50Ms)*time.Millisecond, time.Duration(latency.P99Ms)*time.Millisecond,
+					time.Duration(latency.MaxMs)*time.Millisecond, latency.Samples, latency.Errors, latency.Count), nil)
+		case s.latencyAlerting[operation] && p95 <= threshold:
+			s.latencyAlerting[operation] = false
+			s.logger.Info(ctx, "Exchange latency back below the threshold", map[string]interface{}{
+				"symbol":    s.cfg.Symbol,
+				"operation": operation,
+				"p95":       p95.String(),
+				"threshold": threshold.String(),
+			})
+			s.notify(ctx, fmt.Sprintf("%s exchange latency normal: %s", s.cfg.Symbol, operation),
+				fmt.Sprintf("Symbol: %s\nOperation: %s\np95 latency: %s (threshold %s)", s.cfg.Symbol, operation, p95, threshold), nil)
+		}
+	}
+}
+
+// latencyStatus returns the latency statistics for the status, or nil if latency alerts are
+// disabled. Assumes the caller holds the lock.
+func (s *TradingService) latencyStatus() *ports.LatencyStatus {
+	if s.latencyAlerts == nil {
+		return nil
+	}
+	status := &ports.LatencyStatus{
+		Operations:     make(map[string]ports.OperationLatency, len(s.latencyStats)),
+		P95ThresholdMs: s.latencyAlerts.P95Threshold.Milliseconds(),
+	}
+	for operation, latency := range s.latencyStats {
+		status.Operations[operation] = latency
+		if s.latencyAlerting[operation] {
+			status.Alerting = append(status.Alerting, operation)
+		}
+	}
+	sort.Strings(status.Alerting)
+	return status
+}
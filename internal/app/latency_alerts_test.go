@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_LatencyAlerts(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	ctx := context.Background()
+
+	t.Run("alerts once per breach of each operation and sends the all-clear", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+			WithLatencyAlerts(LatencyAlertConfig{P95Threshold: 500 * time.Millisecond}), WithNotifier(notifier))
+		require.NoError(t, err)
+		assert.Equal(t, defaultLatencyCheckInterval, service.latencyAlerts.CheckInterval)
+		assert.Equal(t, defaultLatencyMinSamples, service.latencyAlerts.MinSamples)
+
+		// Too few requests to judge
+		service.recordLatencyStats(ctx, map[string]ports.OperationLatency{
+			ports.LatencyPlaceOrder: {Count: 5, Samples: 5, P95Ms: 900},
+		})
+		assert.Empty(t, service.latencyAlerting[ports.LatencyPlaceOrder])
+
+		service.recordLatencyStats(ctx, map[string]ports.OperationLatency{
+			ports.LatencyPlaceOrder:  {Count: 40, Errors: 2, Samples: 40, P50Ms: 200, P95Ms: 800, P99Ms: 1200, MaxMs: 1500},
+			ports.LatencyFetchKlines: {Count: 30, Samples: 30, P95Ms: 150},
+		})
+		service.recordLatencyStats(ctx, map[string]ports.OperationLatency{
+			ports.LatencyPlaceOrder:  {Count: 41, Samples: 41, P95Ms: 700},
+			ports.LatencyFetchKlines: {Count: 31, Samples: 31, P95Ms: 150},
+		})
+
+		status := service.Status(ctx).Latency
+		require.NotNil(t, status)
+		assert.Equal(t, int64(500), status.P95ThresholdMs)
+		assert.Equal(t, []string{ports.LatencyPlaceOrder}, status.Alerting)
+		assert.Equal(t, int64(700), status.Operations[ports.LatencyPlaceOrder].P95Ms)
+		assert.Len(t, status.Operations, 2)
+
+		service.recordLatencyStats(ctx, map[string]ports.OperationLatency{
+			ports.LatencyPlaceOrder: {Count: 60, Samples: 60, P95Ms: 400},
+		})
+		assert.Empty(t, service.Status(ctx).Latency.Alerting)
+
+		service.notifications.Wait()
+		assert.ElementsMatch(t, []string{"ETHUSDT exchange latency high: place_order p95 800ms", "ETHUSDT exchange latency normal: place_order"}, notifier.subjects)
+		assert.Contains(t, strings.Join(notifier.messages, "\n"), "p50: 200ms, p99: 1.2s, max: 1.5s over the last 40 requests")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{})
+		require.NoError(t, err)
+		assert.Nil(t, service.Status(ctx).Latency)
+	})
+}
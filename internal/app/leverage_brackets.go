@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+// WithLeverageBrackets keeps entries within the symbol's leverage brackets (if the exchange client
+// implements ports.LeverageBracketProvider). The brackets are fetched on Start; before each entry
+// and scale-in add the exchange leverage is lowered to the maximum of the bracket the position's
+// notional falls in, and raised back to the configured leverage once the notional allows it.
+// Entries whose notional exceeds every bracket are skipped.
+func WithLeverageBrackets() Option {
+	return func(s *TradingService) {
+		s.sizer.bracketAware = true
+	}
+}
+
+// LoadLeverageBrackets fetches the symbol's leverage brackets. Failures are logged and leave
+// entries at the configured leverage.
+func (z *EntrySizer) LoadLeverageBrackets(ctx context.Context) {
+	if !z.bracketAware {
+		return
+	}
+	provider, ok := z.exchange.(ports.LeverageBracketProvider)
+	if !ok {
+		z.logger.Warn(ctx, "Exchange client doesn't provide leverage brackets, using the configured leverage", map[string]interface{}{
+			"symbol": z.cfg.Symbol,
+		})
+		return
+	}
+	brackets, err := provider.GetLeverageBrackets(ctx, z.cfg.Symbol)
+	if err != nil {
+		z.logger.Warn(ctx, "Failed to fetch leverage brackets, using the configured leverage", map[string]interface{}{
+			"symbol": z.cfg.Symbol,
+			"error":  err.Error(),
+		})
+		return
+	}
+	z.brackets = brackets
+
+	fields := map[string]interface{}{
+		"symbol":      z.cfg.Symbol,
+		"brackets":    len(brackets),
+		"maxLeverage": brackets[0].InitialLeverage,
+		"maxNotional": brackets[len(brackets)-1].NotionalCap,
+	}
+	if z.cfg.Leverage > brackets[0].InitialLeverage {
+		fields["leverage"] = z.cfg.Leverage
+		z.logger.Warn(ctx, "Configured leverage exceeds the highest bracket leverage, entries use the bracket maximum", fields)
+		return
+	}
+	z.logger.Info(ctx, "Leverage brackets loaded", fields)
+}
+
+// Leverage returns the leverage set on the exchange for the symbol.
+func (z *EntrySizer) Leverage() int {
+	if z.leverage > 0 {
+		return z.leverage
+	}
+	return z.cfg.Leverage
+}
+
+// fitLeverage sets the exchange leverage to the configured leverage, lowered to the maximum of
+// the bracket a position with the given notional value falls in, and returns it. Without
+// brackets the current leverage is returned unchanged.
+func (z *EntrySizer) fitLeverage(ctx context.Context, notional float64) (int, error) {
+	if z.brackets == nil {
+		return z.Leverage(), nil
+	}
+	leverage, bracket, err := risk.FitLeverage(z.brackets, z.cfg.Leverage, notional)
+	if err != nil {
+		return 0, err
+	}
+	if leverage == z.Leverage() {
+		return leverage, nil
+	}
+	if err := z.exchange.SetLeverage(ctx, z.cfg.Symbol, leverage); err != nil {
+		return 0, fmt.Errorf("failed to set the leverage of bracket %d to %dx: %w", bracket.Bracket, leverage, err)
+	}
+	z.logger.Info(ctx, "Leverage adjusted to the leverage bracket", map[string]interface{}{
+		"symbol":             z.cfg.Symbol,
+		"previousLeverage":   z.Leverage(),
+		"leverage":           leverage,
+		"configuredLeverage": z.cfg.Leverage,
+		"notional":           notional,
+		"bracket":            bracket.Bracket,
+		"notionalCap":        bracket.NotionalCap,
+		"maxLeverage":        bracket.InitialLeverage,
+	})
+	z.leverage = leverage
+	return leverage, nil
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// bracketExchange is a mockExchange that provides leverage brackets and records leverage changes.
+type bracketExchange struct {
+	*mockExchange
+	brackets    []ports.LeverageBracket
+	bracketsErr error
+	leverages   []int // Leverage passed to each SetLeverage call
+}
+
+func (b *bracketExchange) GetLeverageBrackets(ctx context.Context, symbol string) ([]ports.LeverageBracket, error) {
+	return b.brackets, b.bracketsErr
+}
+
+func (b *bracketExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	b.leverages = append(b.leverages, leverage)
+	return b.leverageErr
+}
+
+func TestTradingService_LeverageBrackets(t *testing.T) {
+	brackets := []ports.LeverageBracket{
+		{Bracket: 1, InitialLeverage: 20, NotionalFloor: 0, NotionalCap: 5000},
+		{Bracket: 2, InitialLeverage: 10, NotionalFloor: 5000, NotionalCap: 25000},
+		{Bracket: 3, InitialLeverage: 5, NotionalFloor: 25000, NotionalCap: 50000},
+	}
+	newService := func(t *testing.T, quantity float64, exchange ports.ExchangeClient) *TradingService {
+		cfg := &config.Config{Symbol: "ETHUSDT", Quantity: quantity, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 20}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithLeverageBrackets())
+		require.NoError(t, err)
+		service.sizer.LoadLeverageBrackets(context.Background())
+		return service
+	}
+	newExchange := func() *bracketExchange {
+		return &bracketExchange{
+			mockExchange: &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+				"market_BUY": {OrderID: 1, AvgPrice: 2000},
+				"stop_SELL":  {OrderID: 2},
+				"tp_SELL":    {OrderID: 3},
+			}},
+			brackets: brackets,
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("small entry keeps the configured leverage", func(t *testing.T) {
+		exchange := newExchange()
+		service := newService(t, 1, exchange)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Empty(t, exchange.leverages)
+		assert.Equal(t, 20, service.positions.long.Leverage)
+	})
+
+	t.Run("larger entry lowers the leverage to its bracket", func(t *testing.T) {
+		exchange := newExchange()
+		service := newService(t, 5, exchange)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, []int{10}, exchange.leverages)
+		assert.Equal(t, 10, service.positions.long.Leverage)
+		assert.Equal(t, 10, service.sizer.Leverage())
+	})
+
+	t.Run("leverage is raised back once the notional allows", func(t *testing.T) {
+		exchange := newExchange()
+		service := newService(t, 1, exchange)
+		service.sizer.leverage = 5
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, []int{20}, exchange.leverages)
+		assert.Equal(t, 20, service.positions.long.Leverage)
+	})
+
+	t.Run("entry above the last bracket is skipped", func(t *testing.T) {
+		exchange := newExchange()
+		service := newService(t, 30, exchange)
+		err := service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeds the maximum")
+		assert.Empty(t, exchange.marketOrderQty)
+	})
+
+	t.Run("failed leverage change skips the entry", func(t *testing.T) {
+		exchange := newExchange()
+		exchange.leverageErr = assert.AnError
+		service := newService(t, 5, exchange)
+		require.Error(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Empty(t, exchange.marketOrderQty)
+		assert.Equal(t, 20, service.sizer.Leverage())
+	})
+
+	t.Run("unavailable brackets use the configured leverage", func(t *testing.T) {
+		exchange := newExchange()
+		exchange.bracketsErr = ports.ErrExchangeUnavailable
+		service := newService(t, 30, exchange)
+		assert.Nil(t, service.sizer.brackets)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Empty(t, exchange.leverages)
+
+		service = newService(t, 30, &mockExchange{orderResponses: exchange.orderResponses})
+		assert.Nil(t, service.sizer.brackets)
+	})
+}
@@ -0,0 +1,464 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/strategy/strategies"
+)
+
+// defaultLimitEntryExpiryBars is used when neither the strategy nor the config set an expiry,
+// matching the backtester's default.
+const defaultLimitEntryExpiryBars = 3
+
+// defaultLimitEntryPollInterval is how often resting limit entries are checked for fills.
+const defaultLimitEntryPollInterval = 5 * time.Second
+
+// LimitEntryConfig holds configuration for executing the limit entries strategies ask for.
+type LimitEntryConfig struct {
+	Timeout          time.Duration // Unfilled limit entries are canceled after this long (0: only after their expiry bars)
+	ExpiryBars       int           // Final klines a limit entry rests for when the strategy doesn't set an expiry (default 3)
+	FallbackToMarket bool          // Enter with a market order when a limit entry expires unfilled, instead of skipping the signal
+	PollInterval     time.Duration // How often resting limit entries are checked for fills (default 5s)
+	PostOnly         bool          // Place limit entries post-only if the exchange client can (implements ports.PostOnlyOrderPlacer)
+}
+
+// pendingLimitEntry is a limit entry order resting on the exchange.
+type pendingLimitEntry struct {
+	side          domain.PositionSide
+	clientOrderID string
+	orderID       int64
+	price         float64   // Limit price
+	quantity      float64   // Quantity ordered
+	leverage      int       // Leverage fitted to the entry's notional
+	klineOpenTime time.Time // Open time of the signal's kline
+	tag           domain.EntryTag
+	intent        *domain.EntryIntent
+	barsLeft      int       // Final klines the order still rests for
+	expiresAt     time.Time // Zero without a timeout
+
+	position *domain.Position // Position opened for the fills so far, nil before the first fill
+	filled   float64          // Quantity filled so far
+	avgPrice float64          // Average price of those fills
+	fillIDs  map[int64]bool   // Trades of the order already recorded
+}
+
+// WithLimitEntries executes the limit entries of strategies that ask for them (implementing
+// strategies.EntryOrderProvider) with limit orders, if the exchange client can place them
+// (implements ports.LimitOrderPlacer). A limit entry rests until it fills, until the strategy's
+// expiry bars (or cfg.ExpiryBars) have closed or until cfg.Timeout has passed; it is then
+// canceled, and the signal is either skipped or entered with a market order. The first fill opens
+// a protected position of the filled quantity, even while the rest of the order still rests; later
+// fills grow it and resize its SL/TP orders. Without this option all entries use market orders.
+func WithLimitEntries(cfg LimitEntryConfig) Option {
+	return func(s *TradingService) {
+		if cfg.ExpiryBars <= 0 {
+			cfg.ExpiryBars = defaultLimitEntryExpiryBars
+		}
+		if cfg.PollInterval <= 0 {
+			cfg.PollInterval = defaultLimitEntryPollInterval
+		}
+		s.limitEntries = &cfg
+		s.pendingLimit = make(map[domain.PositionSide]*pendingLimitEntry)
+	}
+}
+
+// limitEntryOrder returns the limit entry the strategy asks for on an entry signal at price.
+// Only limits that improve on the signal price (below it for a long, above it for a short) are
+// used; anything else would fill right away, so the entry uses a market order.
+// Assumes the caller holds the lock.
+func (s *TradingService) limitEntryOrder(ctx context.Context, side domain.PositionSide, price float64) (strategies.EntryOrder, bool) {
+	if s.limitEntries == nil {
+		return strategies.EntryOrder{}, false
+	}
+	provider, ok := s.signals.Strategy().(strategies.EntryOrderProvider)
+	if !ok {
+		return strategies.EntryOrder{}, false
+	}
+	order := provider.GetEntryOrder(ctx, s.signals.Klines(), price)
+	if order.Type != strategies.EntryOrderLimit || order.LimitPrice <= 0 {
+		return order, false
+	}
+	if (side == domain.PositionSideShort && order.LimitPrice <= price) || (side != domain.PositionSideShort && order.LimitPrice >= price) {
+		return order, false
+	}
+	if _, ok := s.exchange.(ports.LimitOrderPlacer); !ok {
+		s.logger.Warn(ctx, "Exchange client can't place limit orders, entering with a market order", map[string]interface{}{"side": side})
+		return order, false
+	}
+	return order, true
+}
+
+// placeLimitEntry places the limit entry order of a signal on side and tracks it until it fills
+// or expires.
+// Assumes the caller holds the lock.
+func (s *TradingService) placeLimitEntry(ctx context.Context, side domain.PositionSide, order strategies.EntryOrder, klineOpenTime time.Time) error {
+	op := "placeLimitEntry"
+	price := s.cfg.OrderPrecision().RoundPrice(order.LimitPrice)
+	s.logger.Info(ctx, op+": Attempting limit entry", map[string]interface{}{"side": side, "limitPrice": price})
+
+	quantity, leverage, err := s.sizer.EntrySize(ctx, op, price)
+	if err != nil {
+		return err
+	}
+	quantityStr := s.formatQuantity(quantity)
+
+	// Record the entry before placing it, so a restart can cancel or adopt it
+	clientOrderID := domain.LimitEntryClientOrderID(s.cfg.Symbol, side, klineOpenTime)
+	intent, err := s.saveEntryIntent(ctx, clientOrderID, side, klineOpenTime, quantity)
+	if err != nil {
+		return err
+	}
+
+	placed, err := s.positions.PlaceLimitEntry(ctx, side, quantity, price, clientOrderID, s.limitEntries.PostOnly)
+	if errors.Is(err, ports.ErrPostOnlyRejected) {
+		// Price already moved through the limit; the order never rested, so it's handled like an unfilled entry
+		s.finishEntryIntent(ctx, intent, false)
+		fields := map[string]interface{}{"clientOrderID": clientOrderID, "limitPrice": price}
+		if !s.limitEntries.FallbackToMarket {
+			s.logger.Info(ctx, op+": Post-only limit entry rejected, skipping the signal", fields)
+			return nil
+		}
+		s.logger.Info(ctx, op+": Post-only limit entry rejected, entering with a market order", fields)
+		return s.enterWithMarketOrder(ctx, side, price, klineOpenTime)
+	}
+	if err != nil {
+		s.logger.Error(ctx, err, op+": Failed to place limit entry order")
+		// The order may still have reached the exchange (e.g., on a timeout); don't leave it resting
+		if intent != nil {
+			if recErr := s.reconcileEntryIntent(ctx, intent); recErr != nil {
+				s.logger.Warn(ctx, op+": Limit entry outcome unknown, it is checked again on restart", map[string]interface{}{
+					"clientOrderID": clientOrderID,
+					"error":         recErr.Error(),
+				})
+			}
+		}
+		return fmt.Errorf("limit entry order failed: %w", err)
+	}
+
+	pending := &pendingLimitEntry{
+		side:          side,
+		clientOrderID: clientOrderID,
+		orderID:       placed.OrderID,
+		price:         price,
+		quantity:      quantity,
+		leverage:      leverage,
+		klineOpenTime: klineOpenTime,
+		intent:        intent,
+		barsLeft:      order.ExpiryBars,
+	}
+	if pending.barsLeft <= 0 {
+		pending.barsLeft = s.limitEntries.ExpiryBars
+	}
+	if s.limitEntries.Timeout > 0 {
+		pending.expiresAt = s.now().Add(s.limitEntries.Timeout)
+	}
+	if tagger, ok := s.signals.Strategy().(ports.EntryTagger); ok {
+		pending.tag = tagger.LastEntryTag() // The signal's tag; the strategy moves on while the order rests
+	}
+	s.pendingLimit[side] = pending
+	s.logger.Info(ctx, op+": Limit entry order resting", map[string]interface{}{
+		"orderID":       placed.OrderID,
+		"clientOrderID": clientOrderID,
+		"limitPrice":    price,
+		"quantity":      quantityStr,
+		"expiryBars":    pending.barsLeft,
+		"expiresAt":     pending.expiresAt,
+	})
+
+	// It may have filled on placement already
+	return s.settleLimitEntry(ctx, pending, placed, price, false, false)
+}
+
+// pendingLimitEntry returns the limit entry resting on side, or on either side in one-way mode.
+// Assumes the caller holds the lock.
+func (s *TradingService) pendingLimitEntry(side domain.PositionSide) *pendingLimitEntry {
+	if pending := s.pendingLimit[side]; pending != nil || s.cfg.HedgeMode {
+		return pending
+	}
+	for _, pending := range s.pendingLimit {
+		return pending
+	}
+	return nil
+}
+
+// pendingLimitEntries returns the limit entries resting on the exchange.
+// Assumes the caller holds the lock.
+func (s *TradingService) pendingLimitEntries() []*pendingLimitEntry {
+	pending := make([]*pendingLimitEntry, 0, len(s.pendingLimit))
+	for _, entry := range s.pendingLimit {
+		pending = append(pending, entry)
+	}
+	return pending
+}
+
+// checkLimitEntries looks up the resting limit entries on the exchange: fills are opened (or added
+// to the position of earlier fills), and those past their expiry bars or timeout, or resting while entries are paused, are
+// canceled. bar tells whether a final kline closed since the last check. price is the current
+// price, used for market fallbacks.
+// Assumes the caller holds the lock.
+func (s *TradingService) checkLimitEntries(ctx context.Context, price float64, bar bool, now time.Time) {
+	paused, _ := s.gate.Paused()
+	for _, side := range []domain.PositionSide{domain.PositionSideLong, domain.PositionSideShort, domain.PositionSideBoth} {
+		pending := s.pendingLimit[side]
+		if pending == nil {
+			continue
+		}
+		if bar {
+			pending.barsLeft--
+		}
+		expired := paused || pending.barsLeft <= 0 || (!pending.expiresAt.IsZero() && !now.Before(pending.expiresAt))
+
+		order, err := s.exchange.GetOrderByClientID(ctx, s.cfg.Symbol, pending.clientOrderID)
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to check limit entry order, retrying on the next check", map[string]interface{}{
+				"clientOrderID": pending.clientOrderID,
+				"error":         err.Error(),
+			})
+			s.observeExchangeError(ctx, err)
+			continue
+		}
+		if err := s.settleLimitEntry(ctx, pending, order, price, expired, !paused); err != nil {
+			s.logger.Error(ctx, err, "Failed to settle limit entry", map[string]interface{}{"clientOrderID": pending.clientOrderID})
+			s.resyncOnClockSkew(err)
+			s.observeExchangeError(ctx, err)
+		}
+	}
+}
+
+// cancelLimitEntries cancels all resting limit entries without falling back to market orders,
+// e.g. on shutdown. Partial fills are still opened and protected.
+// Assumes the caller holds the lock.
+func (s *TradingService) cancelLimitEntries(ctx context.Context) {
+	for _, pending := range s.pendingLimit {
+		s.cancelLimitEntry(ctx, pending)
+	}
+}
+
+// cancelLimitEntry cancels a resting limit entry without a market fallback, opening (or adding to
+// its position) what filled until then. Failures are logged only.
+// Assumes the caller holds the lock.
+func (s *TradingService) cancelLimitEntry(ctx context.Context, pending *pendingLimitEntry) {
+	order := &ports.OrderResponse{OrderID: pending.orderID, Status: "NEW"}
+	if err := s.settleLimitEntry(ctx, pending, order, pending.price, true, false); err != nil {
+		s.logger.Error(ctx, err, "Failed to cancel limit entry", map[string]interface{}{"clientOrderID": pending.clientOrderID})
+	}
+}
+
+// settleLimitEntry acts on the latest state of a limit entry's order: fills open the position, or
+// are added to the one opened for earlier fills, even while the order still rests; an order that is
+// done without a fill (e.g., canceled by hand) is dropped; an expired order is canceled, opening
+// what filled until then or, without a fill, entering with a market order at price if fallback is
+// set and configured. If the fills of a resting order can't be protected, the rest is canceled.
+// Assumes the caller holds the lock.
+func (s *TradingService) settleLimitEntry(ctx context.Context, pending *pendingLimitEntry, order *ports.OrderResponse, price float64, expired, fallback bool) error {
+	fields := map[string]interface{}{"clientOrderID": pending.clientOrderID, "orderID": pending.orderID, "status": order.Status}
+	if !orderOpen(order) {
+		delete(s.pendingLimit, pending.side)
+		if order.Status == "FILLED" || order.ExecutedQty > 0 {
+			return s.finishLimitEntry(ctx, pending, s.openLimitEntry(ctx, pending, order))
+		}
+		s.logger.Info(ctx, "Limit entry order is done without a fill", fields)
+		s.finishEntryIntent(ctx, pending.intent, pending.position != nil)
+		return nil
+	}
+	if order.ExecutedQty > pending.filled {
+		if err := s.openLimitEntry(ctx, pending, order); err != nil {
+			// Don't let the rest fill unprotected; a failed cancel is retried on the next check
+			if _, cancelErr := s.exchange.CancelOrder(ctx, s.cfg.Symbol, pending.orderID); cancelErr != nil {
+				return fmt.Errorf("%w (canceling the rest of limit entry %s failed: %v)", err, pending.clientOrderID, cancelErr)
+			}
+			delete(s.pendingLimit, pending.side)
+			return s.finishLimitEntry(ctx, pending, err)
+		}
+	}
+	if !expired {
+		return nil
+	}
+
+	// Cancel the rest; if that fails the entry stays pending and the next check retries
+	canceled, err := s.exchange.CancelOrder(ctx, s.cfg.Symbol, pending.orderID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel expired limit entry %s: %w", pending.clientOrderID, err)
+	}
+	if canceled == nil {
+		canceled = order
+	}
+	delete(s.pendingLimit, pending.side)
+	if canceled.ExecutedQty > 0 {
+		s.logger.Info(ctx, "Limit entry expired partially filled, keeping the filled quantity", fields)
+		return s.finishLimitEntry(ctx, pending, s.openLimitEntry(ctx, pending, canceled))
+	}
+	if pending.position != nil {
+		// The canceled order's response didn't carry its fills; those already opened stand
+		return s.finishLimitEntry(ctx, pending, nil)
+	}
+	s.finishEntryIntent(ctx, pending.intent, false)
+	if !fallback || !s.limitEntries.FallbackToMarket {
+		s.logger.Info(ctx, "Limit entry expired unfilled, skipping the signal", fields)
+		return nil
+	}
+	if ok, reason := s.gate.Allow(pending.side); !ok {
+		fields["reason"] = reason
+		s.logger.Info(ctx, "Limit entry expired unfilled, market fallback not allowed", fields)
+		return nil
+	}
+	if price <= 0 {
+		price = pending.price // No kline received yet
+	}
+	s.logger.Info(ctx, "Limit entry expired unfilled, entering with a market order", fields)
+	return s.enterWithMarketOrder(ctx, pending.side, price, pending.klineOpenTime)
+}
+
+// finishLimitEntry settles the intent of a limit entry whose order is done: it opened if any of its
+// fills were protected. Returns err, the outcome of the last fills.
+// Assumes the caller holds the lock.
+func (s *TradingService) finishLimitEntry(ctx context.Context, pending *pendingLimitEntry, err error) error {
+	s.finishEntryIntent(ctx, pending.intent, pending.position != nil)
+	return err
+}
+
+// openLimitEntry protects the fills of a limit entry order not opened yet. The first fills open a
+// position whose SL/TP prices are measured from their fill price; later ones are added to it and
+// its SL/TP orders resized to the new quantity at the same prices. If that position was closed in
+// the meantime (e.g., its stop loss filled), they open a new one.
+// Assumes the caller holds the lock.
+func (s *TradingService) openLimitEntry(ctx context.Context, pending *pendingLimitEntry, order *ports.OrderResponse) error {
+	op := "openLimitEntry"
+	filled := order.ExecutedQty
+	if filled <= 0 {
+		filled = pending.quantity // A filled order that doesn't report its quantity
+	}
+	quantity := s.cfg.OrderPrecision().RoundQuantity(filled - pending.filled)
+	if quantity <= 0 {
+		return nil // Nothing filled since the last check
+	}
+
+	var fills []*domain.OrderFill
+	for _, fill := range s.orderFills(ctx, order, domain.FillRoleEntry) {
+		if !pending.fillIDs[fill.TradeID] {
+			fills = append(fills, fill)
+		}
+	}
+	avgPrice := order.AvgPrice
+	if avgPrice <= 0 {
+		avgPrice = pending.price // Limit orders fill at their price or better
+	}
+	entryPrice := (avgPrice*filled - pending.avgPrice*pending.filled) / quantity // Price of the new fills
+	if entryPrice <= 0 || pending.filled == 0 {
+		entryPrice = avgPrice
+	}
+	entryPrice = averageFillPrice(fills, entryPrice)
+	pending.filled, pending.avgPrice = filled, avgPrice
+	if pending.fillIDs == nil {
+		pending.fillIDs = make(map[int64]bool)
+	}
+	for _, fill := range fills {
+		pending.fillIDs[fill.TradeID] = true
+	}
+
+	s.publish(ctx, ports.Event{Type: ports.EventOrderFilled, Side: pending.side, OrderID: pending.orderID, Quantity: quantity, Price: entryPrice})
+	s.logger.Info(ctx, op+": Limit entry order filled", map[string]interface{}{
+		"orderID":  pending.orderID,
+		"avgPrice": entryPrice,
+		"quantity": quantity,
+		"filled":   filled,
+		"status":   order.Status,
+	})
+	s.sizer.RecordVolume(ctx, quantity, entryPrice) // The fill counts even if protecting it fails
+
+	if pos := pending.position; pos != nil && s.positions.Position(pending.side) == pos {
+		return s.addLimitEntryFill(ctx, pos, quantity, entryPrice, fills)
+	}
+
+	slPrice, tpPrice := s.exitPrices(pending.side, entryPrice)
+	newPosition := &domain.Position{
+		Symbol:     s.cfg.Symbol,
+		Side:       pending.side,
+		EntryPrice: entryPrice,
+		Quantity:   quantity,
+		Leverage:   pending.leverage,
+		StopLoss:   slPrice,
+		TakeProfit: tpPrice,
+		EntryTime:  s.now().UTC(),
+		EntryTag:   pending.tag,
+		Fees:       domain.SummarizeFills(fills).Commission,
+	}
+	if s.sizer.ScaleIn().Enabled() {
+		newPosition.ScaleInBasePrice = entryPrice
+	}
+	if err := s.protectPosition(ctx, op, newPosition, pending.orderID); err != nil {
+		return err
+	}
+	pending.position = newPosition
+	s.saveOrderFills(ctx, newPosition.ID, fills)
+	return nil
+}
+
+// addLimitEntryFill adds later fills of a limit entry to the position opened for its earlier fills
+// and resizes its SL/TP orders. If the resized stop loss can't be placed the added quantity is
+// closed again, since it would be unprotected.
+// Assumes the caller holds the lock.
+func (s *TradingService) addLimitEntryFill(ctx context.Context, pos *domain.Position, quantity, price float64, fills []*domain.OrderFill) error {
+	op := "addLimitEntryFill"
+	entryPrice, previousQuantity := pos.EntryPrice, pos.Quantity
+	err := pos.ApplyPartialFill(quantity, price)
+	if err == nil {
+		err = s.positions.ResizeProtectiveOrders(ctx, op, pos)
+	}
+	if err != nil {
+		positionSide := pos.PositionSide()
+		quantityStr := s.formatQuantity(quantity)
+		s.logger.Warn(ctx, op+": Closing the unprotected fill again...", map[string]interface{}{"positionID": pos.ID})
+		if closeErr := s.positions.EmergencyClose(ctx, price, quantityStr, positionSide.EntrySide(), s.positions.ExchangeSide(positionSide)); closeErr != nil {
+			s.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED after limit entry fill")
+			s.notifyCritical(ctx, "Emergency close of a limit entry fill failed", closeErr)
+		}
+		pos.EntryPrice, pos.Quantity = entryPrice, previousQuantity
+		s.restoreStopLoss(ctx, op, pos)
+		return fmt.Errorf("failed to protect limit entry fill: %w (emergency close attempted)", err)
+	}
+
+	pos.Fees += domain.SummarizeFills(fills).Commission
+	s.saveOrderFills(ctx, pos.ID, fills)
+	if err := s.positions.Save(ctx, pos); err != nil {
+		// The exchange orders already match the new size; only the saved record lags behind
+		s.logger.Error(ctx, err, op+": Failed to save grown position", map[string]interface{}{"positionID": pos.ID})
+	}
+	s.logger.Info(ctx, op+": Added limit entry fill to position", map[string]interface{}{
+		"positionID": pos.ID,
+		"fillPrice":  price,
+		"entryPrice": pos.EntryPrice,
+		"quantity":   pos.Quantity,
+	})
+	return nil
+}
+
+// runLimitEntries checks the resting limit entries for fills and timeouts until ctx is canceled.
+func (s *TradingService) runLimitEntries(ctx context.Context) {
+	ticker := time.NewTicker(s.limitEntries.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		if len(s.pendingLimit) > 0 {
+			price, _ := s.signals.LastPrice()
+			s.checkLimitEntries(ctx, price, false, s.now())
+		}
+		s.mu.Unlock()
+	}
+}
+
+// orderOpen reports whether an order still rests on the exchange (new or partially filled).
+func orderOpen(order *ports.OrderResponse) bool {
+	return order.Status == "NEW" || order.Status == "PARTIALLY_FILLED"
+}
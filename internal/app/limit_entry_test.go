@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"cryptoMegaBot/internal/testharness"
+)
+
+// mockLimitStrategy extends mockStrategy with a fixed entry order
+type mockLimitStrategy struct {
+	mockStrategy
+	order strategies.EntryOrder
+}
+
+func (m *mockLimitStrategy) GetEntryOrder(ctx context.Context, klines []*domain.Kline, currentPrice float64) strategies.EntryOrder {
+	return m.order
+}
+
+func TestTradingService_LimitEntries(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	signalTime := time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC)
+	clientOrderID := domain.LimitEntryClientOrderID("ETHUSDT", domain.PositionSideLong, signalTime)
+	limit := strategies.EntryOrder{Type: strategies.EntryOrderLimit, LimitPrice: 1990, ExpiryBars: 2}
+
+	newService := func(t *testing.T, limitCfg LimitEntryConfig) (*TradingService, *mockExchange) {
+		exchange := &mockExchange{
+			orderResponses: map[string]*ports.OrderResponse{
+				"limit_BUY":   {OrderID: 7, Status: "NEW"},
+				"market_BUY":  {OrderID: 1, AvgPrice: 2000, ExecutedQty: 1},
+				"stop_SELL":   {OrderID: 2},
+				"tp_SELL":     {OrderID: 3},
+				"market_SELL": {OrderID: 4, AvgPrice: 2000},
+			},
+			ordersByClient: map[string]*ports.OrderResponse{clientOrderID: {OrderID: 7, Status: "NEW"}},
+		}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockLimitStrategy{order: limit}, WithLimitEntries(limitCfg))
+		require.NoError(t, err)
+		return service, exchange
+	}
+
+	t.Run("resting entry opens the position once filled", func(t *testing.T) {
+		service, exchange := newService(t, LimitEntryConfig{})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		assert.Equal(t, []string{"1990.00"}, exchange.limitPrices)
+		assert.Empty(t, exchange.clientOrderIDs, "Expected no market order")
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "limit entry "+clientOrderID+" pending", reason)
+
+		service.checkLimitEntries(ctx, 2000, true, time.Now())
+		assert.Nil(t, service.positions.Position(domain.PositionSideLong), "Expected the unfilled entry to keep resting")
+
+		exchange.ordersByClient[clientOrderID] = &ports.OrderResponse{OrderID: 7, Status: "FILLED", AvgPrice: 1990, ExecutedQty: 1}
+		service.checkLimitEntries(ctx, 1995, false, time.Now())
+		pos := service.positions.Position(domain.PositionSideLong)
+		require.NotNil(t, pos)
+		assert.Equal(t, 1990.0, pos.EntryPrice)
+		assert.InDelta(t, 1970.1, pos.StopLoss, 0.01, "Expected the stop measured from the fill")
+		assert.Empty(t, service.pendingLimit)
+		assert.Empty(t, exchange.canceledOrders)
+	})
+
+	t.Run("expired entry falls back to a market order", func(t *testing.T) {
+		service, exchange := newService(t, LimitEntryConfig{FallbackToMarket: true})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		service.checkLimitEntries(ctx, 2005, true, time.Now())
+		service.checkLimitEntries(ctx, 2005, true, time.Now())
+
+		assert.Equal(t, []int64{7}, exchange.canceledOrders)
+		assert.Equal(t, []string{domain.EntryClientOrderID("ETHUSDT", domain.PositionSideLong, signalTime)}, exchange.clientOrderIDs)
+		pos := service.positions.Position(domain.PositionSideLong)
+		require.NotNil(t, pos)
+		assert.Equal(t, 2000.0, pos.EntryPrice)
+	})
+
+	t.Run("entry timing out unfilled is skipped", func(t *testing.T) {
+		service, exchange := newService(t, LimitEntryConfig{Timeout: time.Minute, ExpiryBars: 10})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		service.checkLimitEntries(ctx, 2005, false, time.Now())
+		assert.Empty(t, exchange.canceledOrders)
+
+		service.checkLimitEntries(ctx, 2005, false, time.Now().Add(2*time.Minute))
+		assert.Equal(t, []int64{7}, exchange.canceledOrders)
+		assert.Empty(t, exchange.clientOrderIDs, "Expected no market order")
+		assert.Nil(t, service.positions.Position(domain.PositionSideLong))
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+	})
+
+	t.Run("partial fill at expiry opens the filled quantity", func(t *testing.T) {
+		service, exchange := newService(t, LimitEntryConfig{FallbackToMarket: true})
+		exchange.orderResponses["cancel_7"] = &ports.OrderResponse{OrderID: 7, Status: "CANCELED", AvgPrice: 1990, ExecutedQty: 0.4}
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		service.checkLimitEntries(ctx, 2005, true, time.Now())
+		service.checkLimitEntries(ctx, 2005, true, time.Now())
+
+		pos := service.positions.Position(domain.PositionSideLong)
+		require.NotNil(t, pos)
+		assert.Equal(t, 0.4, pos.Quantity)
+		assert.Empty(t, exchange.clientOrderIDs, "Expected no market order for the rest")
+	})
+
+	t.Run("partial fills are protected while the rest rests", func(t *testing.T) {
+		service, exchange := newService(t, LimitEntryConfig{})
+		exchange.orderResponses["cancel_7"] = &ports.OrderResponse{OrderID: 7, Status: "CANCELED", AvgPrice: 1990, ExecutedQty: 0.8}
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+
+		exchange.ordersByClient[clientOrderID] = &ports.OrderResponse{OrderID: 7, Status: "PARTIALLY_FILLED", AvgPrice: 1990, ExecutedQty: 0.4}
+		service.checkLimitEntries(ctx, 1995, false, time.Now())
+		pos := service.positions.Position(domain.PositionSideLong)
+		require.NotNil(t, pos, "Expected the partial fill to be opened right away")
+		assert.Equal(t, 0.4, pos.Quantity)
+		assert.Equal(t, []string{"0.400"}, exchange.stopQty)
+		assert.NotEmpty(t, service.pendingLimit, "Expected the rest to keep resting")
+
+		exchange.ordersByClient[clientOrderID] = &ports.OrderResponse{OrderID: 7, Status: "PARTIALLY_FILLED", AvgPrice: 1985, ExecutedQty: 0.8}
+		service.checkLimitEntries(ctx, 1995, false, time.Now())
+		assert.Same(t, pos, service.positions.Position(domain.PositionSideLong))
+		assert.InDelta(t, 0.8, pos.Quantity, 1e-9)
+		assert.InDelta(t, 1985, pos.EntryPrice, 1e-9)
+		assert.Equal(t, []string{"0.400", "0.800"}, exchange.stopQty, "Expected the stop resized to the fills")
+
+		// Closing the position cancels the rest of the order first
+		require.NoError(t, service.closePosition(ctx, pos, 1995, domain.CloseReasonMarket))
+		assert.Contains(t, exchange.canceledOrders, int64(7))
+		assert.Empty(t, service.pendingLimit)
+		assert.Equal(t, "0.800", exchange.marketOrderQty, "Expected the close to cover every fill")
+	})
+
+	t.Run("limit above the signal price enters at market", func(t *testing.T) {
+		service, exchange := newService(t, LimitEntryConfig{})
+		service.signals.strategy = &mockLimitStrategy{order: strategies.EntryOrder{Type: strategies.EntryOrderLimit, LimitPrice: 2010}}
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		assert.Empty(t, exchange.limitPrices)
+		assert.NotNil(t, service.positions.Position(domain.PositionSideLong))
+	})
+}
+
+// flatRecording returns n 1m klines closing at price, for replays on the fake exchange
+func flatRecording(n int, price float64) []*domain.Kline {
+	start := time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, n)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Minute), Open: price, High: price + 1, Low: price - 1, Close: price}
+	}
+	return klines
+}
+
+func TestTradingService_PostOnlyLimitEntries(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	signalTime := time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC)
+	limit := strategies.EntryOrder{Type: strategies.EntryOrderLimit, LimitPrice: 1990, ExpiryBars: 2}
+
+	// The signal came at 2000; by the time the order is placed the fake exchange trades at lastPrice
+	newService := func(t *testing.T, lastPrice float64, limitCfg LimitEntryConfig) (*TradingService, *testharness.FakeExchange) {
+		exchange, err := testharness.New(testharness.Config{Symbol: "ETHUSDT", Klines: flatRecording(10, lastPrice), History: 4})
+		require.NoError(t, err)
+		limitCfg.PostOnly = true
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockLimitStrategy{order: limit}, WithLimitEntries(limitCfg))
+		require.NoError(t, err)
+		return service, exchange
+	}
+
+	t.Run("entry below the book rests", func(t *testing.T) {
+		service, exchange := newService(t, 2000, LimitEntryConfig{})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		require.Len(t, exchange.OpenOrders(), 1)
+		assert.NotEmpty(t, service.pendingLimit)
+		assert.Zero(t, exchange.Stats().OrdersRejected)
+	})
+
+	t.Run("entry the price already moved through is skipped", func(t *testing.T) {
+		service, exchange := newService(t, 1985, LimitEntryConfig{})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		assert.Equal(t, 1, exchange.Stats().OrdersRejected)
+		assert.Empty(t, exchange.OpenOrders())
+		assert.Empty(t, service.pendingLimit)
+		assert.Nil(t, service.positions.Position(domain.PositionSideLong))
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok, "Expected the rejected entry not to block the next signal")
+	})
+
+	t.Run("entry the price already moved through falls back to a market order", func(t *testing.T) {
+		service, exchange := newService(t, 1985, LimitEntryConfig{FallbackToMarket: true})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, signalTime))
+		assert.Equal(t, 1, exchange.Stats().OrdersRejected)
+		assert.Empty(t, service.pendingLimit)
+		pos := service.positions.Position(domain.PositionSideLong)
+		require.NotNil(t, pos)
+		assert.Equal(t, 1985.0, pos.EntryPrice)
+		assert.Equal(t, 1.0, exchange.Position(domain.PositionSideBoth).Amount)
+		assert.Len(t, exchange.OpenOrders(), 2, "Expected the SL/TP orders of the market entry")
+	})
+}
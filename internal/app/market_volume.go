@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// quoteVolumeMaxAge is how long a fetched 24h quote volume is used before it's fetched again.
+const quoteVolumeMaxAge = 5 * time.Minute
+
+// refreshQuoteVolume hands the symbol's rolling 24h quote volume to the risk manager's volume
+// cap, fetching it at most every quoteVolumeMaxAge. If fetching fails the last volume is kept;
+// without one it fails, so entries don't go out uncapped.
+func (z *EntrySizer) refreshQuoteVolume(ctx context.Context) error {
+	if z.clock.Now().Sub(z.quoteVolumeAt) < quoteVolumeMaxAge {
+		return nil
+	}
+	provider, ok := z.exchange.(ports.TickerStatsProvider)
+	if !ok {
+		return fmt.Errorf("exchange client doesn't report 24h ticker statistics for the volume cap")
+	}
+	stats, err := provider.GetTickerStats(ctx, z.cfg.Symbol)
+	if err != nil {
+		if z.riskMgr.GetStats().QuoteVolume24h > 0 {
+			z.logger.Warn(ctx, "Failed to refresh 24h quote volume, using the last one", map[string]interface{}{
+				"quoteVolume": z.riskMgr.GetStats().QuoteVolume24h,
+				"error":       err.Error(),
+			})
+			return nil
+		}
+		return fmt.Errorf("failed to fetch 24h quote volume for the volume cap: %w", err)
+	}
+	z.riskMgr.UpdateQuoteVolume(stats.QuoteVolume)
+	z.quoteVolumeAt = z.clock.Now()
+	z.logger.Debug(ctx, "24h quote volume updated", map[string]interface{}{
+		"quoteVolume": stats.QuoteVolume,
+		"maxNotional": z.riskMgr.MaxNotional(),
+	})
+	return nil
+}
+
+// capToVolume limits an order of quantity at price, on top of held (the quantity already in the
+// position), so the position's notional stays within the risk manager's share of the 24h quote
+// volume. Returns quantity unchanged without a volume cap.
+func (z *EntrySizer) capToVolume(ctx context.Context, op string, held, quantity, price float64) (float64, error) {
+	if z.riskMgr == nil || !z.riskMgr.VolumeCapEnabled() {
+		return quantity, nil
+	}
+	if err := z.refreshQuoteVolume(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	capped := z.riskMgr.CapToVolume(held+quantity, price) - held
+	if capped < quantity {
+		z.logger.Info(ctx, op+": Position size capped by 24h volume", map[string]interface{}{
+			"quoteVolume": z.riskMgr.GetStats().QuoteVolume24h,
+			"maxNotional": z.riskMgr.MaxNotional(),
+			"quantity":    quantity,
+			"capped":      capped,
+		})
+		return max(capped, 0), nil
+	}
+	return quantity, nil
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_VolumeCap(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	newService := func(t *testing.T, exchange *mockExchange) *TradingService {
+		exchange.orderResponses = map[string]*ports.OrderResponse{
+			"market_BUY": {OrderID: 1, AvgPrice: 2000},
+			"stop_SELL":  {OrderID: 2},
+			"tp_SELL":    {OrderID: 3},
+		}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithRiskManager(risk.NewRiskManager(risk.RiskConfig{MaxVolumeShare: 0.001})))
+		require.NoError(t, err)
+		return service
+	}
+
+	t.Run("entry is capped at the share of the 24h volume", func(t *testing.T) {
+		// 0.1% of 1M is 1000 notional, half the configured quantity at 2000
+		exchange := &mockExchange{tickerStats: &ports.TickerStats{Symbol: "ETHUSDT", QuoteVolume: 1_000_000}}
+		service := newService(t, exchange)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "0.500", exchange.marketOrderQty)
+		assert.Equal(t, 0.5, service.positions.Position(domain.PositionSideLong).Quantity)
+	})
+
+	t.Run("entry fails closed without a volume", func(t *testing.T) {
+		exchange := &mockExchange{tickerStatsErr: errors.New("ticker unavailable")}
+		service := newService(t, exchange)
+		err := service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now())
+		assert.ErrorContains(t, err, "failed to fetch 24h quote volume")
+		assert.Empty(t, exchange.marketOrderQty)
+	})
+
+	t.Run("last volume is kept when a refresh fails", func(t *testing.T) {
+		exchange := &mockExchange{tickerStatsErr: errors.New("ticker unavailable")}
+		service := newService(t, exchange)
+		service.sizer.riskMgr.UpdateQuoteVolume(1_000_000)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "0.500", exchange.marketOrderQty)
+	})
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// notificationTimeout bounds the delivery of a single trade or error notification.
+const notificationTimeout = 30 * time.Second
+
+// Message templates for the notifications sent by the trading service. The daily summary is
+// rendered by FormatDailyReport.
+var (
+	entryTemplate = template.Must(template.New("entry").Parse(
+		`Opened {{.Side}} {{.Symbol}} at {{printf "%.4f" .EntryPrice}}
+Quantity: {{.Quantity}} ({{.Leverage}}x)
+Stop loss: {{printf "%.4f" .StopLoss}}
+Take profit: {{printf "%.4f" .TakeProfit}}
+{{- if .EntryReason}}
+Reason: {{.EntryReason}}{{end}}
+Time: {{.EntryTime.Format "2006-01-02 15:04:05"}} UTC`))
+
+	exitTemplate = template.Must(template.New("exit").Parse(
+		`Closed {{.Side}} {{.Symbol}} at {{printf "%.4f" .ExitPrice}} ({{.CloseReason}})
+Entry: {{printf "%.4f" .EntryPrice}}
+Quantity: {{.Quantity}} ({{.Leverage}}x)
+PnL: {{printf "%.2f" .PNL}}
+Held: {{.Held}}
+Time: {{.ExitTime.Format "2006-01-02 15:04:05"}} UTC`))
+
+	criticalTemplate = template.Must(template.New("critical").Parse(
+		`{{.Message}}
+Error: {{.Err}}
+Symbol: {{.Symbol}}
+Time: {{.Time.Format "2006-01-02 15:04:05"}} UTC
+
+Manual intervention may be required.`))
+)
+
+// FormatEntryNotification renders the subject and message announcing an opened position.
+func FormatEntryNotification(pos *domain.Position) (string, string, error) {
+	data := struct {
+		*domain.Position
+		Side domain.PositionSide
+	}{Position: pos, Side: pos.PositionSide()}
+	message, err := render(entryTemplate, data)
+	return fmt.Sprintf("Opened %s %s", pos.PositionSide(), pos.Symbol), message, err
+}
+
+// FormatExitNotification renders the subject and message announcing a closed position.
+func FormatExitNotification(pos *domain.Position) (string, string, error) {
+	data := struct {
+		*domain.Position
+		Side domain.PositionSide
+		Held time.Duration
+	}{Position: pos, Side: pos.PositionSide(), Held: pos.ExitTime.Sub(pos.EntryTime).Round(time.Second)}
+	message, err := render(exitTemplate, data)
+	return fmt.Sprintf("Closed %s %s: PnL %.2f", pos.PositionSide(), pos.Symbol, pos.PNL), message, err
+}
+
+// FormatCriticalNotification renders the subject and message of an error that needs attention.
+func FormatCriticalNotification(symbol, message string, cause error, at time.Time) (string, string, error) {
+	data := struct {
+		Symbol  string
+		Message string
+		Err     error
+		Time    time.Time
+	}{Symbol: symbol, Message: message, Err: cause, Time: at.UTC()}
+	body, err := render(criticalTemplate, data)
+	return fmt.Sprintf("CRITICAL %s: %s", symbol, message), body, err
+}
+
+// render executes a message template.
+func render(tmpl *template.Template, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s notification: %w", tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// notify sends a rendered notification in the background so slow deliveries don't hold up
+// trading. Rendering and delivery failures are logged.
+func (s *TradingService) notify(ctx context.Context, subject, message string, err error) {
+	if s.notifier == nil {
+		return
+	}
+	if err != nil {
+		s.logger.Error(ctx, err, "Failed to render notification", map[string]interface{}{"subject": subject})
+		return
+	}
+	s.notifications.Add(1)
+	go func() {
+		defer s.notifications.Done()
+		sendCtx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+		defer cancel()
+		if err := s.notifier.Notify(sendCtx, subject, message); err != nil {
+			s.logger.Error(sendCtx, err, "Failed to send notification", map[string]interface{}{"subject": subject})
+		}
+	}()
+}
+
+// notifyCritical sends a critical error notification.
+func (s *TradingService) notifyCritical(ctx context.Context, message string, cause error) {
+	subject, body, err := FormatCriticalNotification(s.cfg.Symbol, message, cause, s.now())
+	s.notify(ctx, subject, body, err)
+}
+
+// MultiNotifier delivers every notification through several notifiers (e.g., Telegram and email).
+type MultiNotifier []ports.Notifier
+
+// Notify sends through every notifier, even if some fail; their errors are joined.
+func (m MultiNotifier) Notify(ctx context.Context, subject, message string) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, subject, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
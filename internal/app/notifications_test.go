@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestFormatNotifications(t *testing.T) {
+	entry := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	pos := &domain.Position{
+		Symbol:     "ETHUSDT",
+		Side:       domain.PositionSideShort,
+		EntryPrice: 2000,
+		Quantity:   0.1,
+		Leverage:   3,
+		StopLoss:   2020,
+		TakeProfit: 1960,
+		EntryTime:  entry,
+		EntryTag:   domain.EntryTag{EntryReason: "MA crossover"},
+	}
+
+	subject, message, err := FormatEntryNotification(pos)
+	require.NoError(t, err)
+	assert.Equal(t, "Opened SHORT ETHUSDT", subject)
+	assert.Equal(t, "Opened SHORT ETHUSDT at 2000.0000\nQuantity: 0.1 (3x)\nStop loss: 2020.0000\nTake profit: 1960.0000\n"+
+		"Reason: MA crossover\nTime: 2025-05-01 12:00:00 UTC", message)
+
+	require.NoError(t, pos.Open())
+	require.NoError(t, pos.Close(1950, entry.Add(90*time.Minute), domain.CloseReasonTakeProfit))
+	subject, message, err = FormatExitNotification(pos)
+	require.NoError(t, err)
+	assert.Equal(t, "Closed SHORT ETHUSDT: PnL 5.00", subject)
+	assert.Contains(t, message, "Closed SHORT ETHUSDT at 1950.0000 (TP)")
+	assert.Contains(t, message, "Held: 1h30m0s")
+
+	subject, message, err = FormatCriticalNotification("ETHUSDT", "Emergency close failed", errors.New("timeout"), entry)
+	require.NoError(t, err)
+	assert.Equal(t, "CRITICAL ETHUSDT: Emergency close failed", subject)
+	assert.Contains(t, message, "Error: timeout")
+}
+
+func TestTradingService_Notifications(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	exchange := &mockExchange{
+		orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY":  {OrderID: 1, AvgPrice: 2000},
+			"stop_SELL":   {OrderID: 2},
+			"tp_SELL":     {OrderID: 3},
+			"market_SELL": {OrderID: 4, AvgPrice: 2040},
+		},
+	}
+	posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+	notifier := &mockNotifier{}
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{}, WithNotifier(notifier))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+	require.NoError(t, service.closePosition(ctx, service.positions.long, 2040, domain.CloseReasonTakeProfit))
+	service.notifications.Wait()
+	notifier.mu.Lock()
+	assert.ElementsMatch(t, []string{"Opened LONG ETHUSDT", "Closed LONG ETHUSDT: PnL 4.00"}, notifier.subjects)
+	notifier.mu.Unlock()
+
+	// A failed emergency close after a failed stop loss raises a critical notification
+	exchange.orderErrors = map[string]error{
+		"stop_SELL":   ports.ErrOrderPlacementFailed,
+		"market_SELL": ports.ErrOrderPlacementFailed,
+	}
+	require.Error(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+	service.notifications.Wait()
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	require.Len(t, notifier.subjects, 3)
+	assert.Equal(t, "CRITICAL ETHUSDT: Emergency close failed after stop loss placement failure", notifier.subjects[2])
+}
+
+func TestMultiNotifier(t *testing.T) {
+	ok := &mockNotifier{}
+	failing := &mockNotifier{notifyErr: ports.ErrNotificationFailed}
+	err := MultiNotifier{failing, ok}.Notify(context.Background(), "subject", "message")
+	require.ErrorIs(t, err, ports.ErrNotificationFailed)
+	assert.Equal(t, []string{"subject"}, ok.subjects, "a failing notifier doesn't stop the others")
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/utils"
+)
+
+// openInterestSnapshots is how many open interest snapshots are fetched for strategies.
+const openInterestSnapshots = 30
+
+// provideOpenInterest hands the latest open interest snapshots to strategies that implement
+// ports.OpenInterestStrategy, fetching them at most once per snapshot period. If fetching fails
+// the last snapshots are kept; the strategy decides when they are too old to use.
+// Assumes the caller holds the lock.
+func (s *TradingService) provideOpenInterest(ctx context.Context) {
+	strat, ok := s.signals.Strategy().(ports.OpenInterestStrategy)
+	if !ok || strat.OpenInterestPeriod() == "" {
+		return
+	}
+	provider, ok := s.exchange.(ports.OpenInterestProvider)
+	if !ok {
+		return
+	}
+	period := strat.OpenInterestPeriod()
+	interval, err := utils.ParseInterval(period)
+	if err != nil {
+		s.logger.Error(ctx, err, "Invalid open interest period", map[string]interface{}{"period": period})
+		return
+	}
+
+	now := s.now()
+	if len(s.openInterest) == 0 || s.openInterest[0].Period != period || now.Sub(s.openInterestAt) >= interval {
+		snapshots, err := provider.GetOpenInterestHistory(ctx, s.cfg.Symbol, period, now.Add(-openInterestSnapshots*interval), now)
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to fetch open interest, using the last snapshots", map[string]interface{}{
+				"period":    period,
+				"snapshots": len(s.openInterest),
+				"error":     err.Error(),
+			})
+		} else {
+			s.openInterest = snapshots
+			s.openInterestAt = now
+		}
+	}
+	if len(s.openInterest) > 0 && s.openInterest[0].Period == period {
+		strat.SetOpenInterest(s.openInterest)
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+)
+
+// mockOpenInterestStrategy extends mockStrategy with the open interest it was given
+type mockOpenInterestStrategy struct {
+	mockStrategy
+	period       string
+	openInterest []*domain.OpenInterest
+}
+
+func (m *mockOpenInterestStrategy) OpenInterestPeriod() string {
+	return m.period
+}
+
+func (m *mockOpenInterestStrategy) SetOpenInterest(snapshots []*domain.OpenInterest) {
+	m.openInterest = snapshots
+}
+
+func TestTradingService_ProvideOpenInterest(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	snapshots := []*domain.OpenInterest{
+		{Symbol: "ETHUSDT", Time: time.Now().Add(-5 * time.Minute), Period: "5m", Contracts: 1000},
+		{Symbol: "ETHUSDT", Time: time.Now(), Period: "5m", Contracts: 1010},
+	}
+	exchange := &mockExchange{openInterest: snapshots}
+	strategy := &mockOpenInterestStrategy{period: "5m"}
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+		&mockTradeRepo{}, strategy)
+	require.NoError(t, err)
+
+	service.provideOpenInterest(ctx)
+	assert.Equal(t, snapshots, strategy.openInterest)
+	assert.Equal(t, 1, exchange.oiFetches)
+
+	// Fetched once per period; a failed refresh keeps the last snapshots
+	service.provideOpenInterest(ctx)
+	assert.Equal(t, 1, exchange.oiFetches)
+	service.openInterestAt = time.Now().Add(-10 * time.Minute)
+	exchange.openInterestErr = errors.New("unavailable")
+	strategy.openInterest = nil
+	service.provideOpenInterest(ctx)
+	assert.Equal(t, 2, exchange.oiFetches)
+	assert.Equal(t, snapshots, strategy.openInterest)
+
+	// Nothing is fetched while the strategy doesn't use open interest
+	strategy.period = ""
+	service.openInterestAt = time.Time{}
+	service.provideOpenInterest(ctx)
+	assert.Equal(t, 2, exchange.oiFetches)
+}
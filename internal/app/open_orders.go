@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// cancelOrphanedOrders cancels the symbol's open stop-loss and take-profit orders that don't
+// belong to a stored open position, e.g. those of a position that closed while the bot was down
+// or orders placed just before a crash. Left in place, they could trigger later and open an
+// unintended position. Only close-position orders (the kind the bot places for SL/TP) are
+// considered, so orders placed by hand are left alone.
+func (s *TradingService) cancelOrphanedOrders(ctx context.Context) error {
+	orders, err := s.exchange.ListOpenOrders(ctx, s.cfg.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to list open orders: %w", err)
+	}
+
+	// Protective orders of the stored positions
+	known := make(map[string]bool)
+	for _, pos := range s.positions.OpenPositions() {
+		for _, id := range []*string{pos.StopLossOrderID, pos.TakeProfitOrderID} {
+			if id != nil && *id != "" {
+				known[*id] = true
+			}
+		}
+	}
+
+	open := make(map[string]bool, len(orders))
+	canceled := 0
+	for _, order := range orders {
+		id := strconv.FormatInt(order.OrderID, 10)
+		open[id] = true
+		if known[id] || !order.ClosePosition {
+			continue
+		}
+		s.logger.Warn(ctx, "Canceling orphaned order", map[string]interface{}{
+			"orderID": order.OrderID, "type": order.Type, "side": order.Side, "stopPrice": order.StopPrice,
+		})
+		if err := s.positions.CancelOrder(ctx, order.OrderID, order.Type); err == nil {
+			canceled++
+		}
+	}
+
+	// The stored IDs may also point to orders that triggered or were canceled by hand
+	for id := range known {
+		if !open[id] {
+			s.logger.Warn(ctx, "Stored protective order is not open on the exchange", map[string]interface{}{"orderID": id})
+		}
+	}
+	s.logger.Info(ctx, "Open orders checked", map[string]interface{}{"open": len(orders), "orphansCanceled": canceled})
+	return nil
+}
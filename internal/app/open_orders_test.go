@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_CancelOrphanedOrders(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, Leverage: 10, StopLoss: 0.02, MaxProfit: 0.05, MaxOrders: 5}
+	newService := func(t *testing.T, exchange *mockExchange) (*TradingService, *mockLogger) {
+		log := &mockLogger{}
+		service, err := NewTradingService(cfg, log, exchange, &mockPositionRepo{positions: map[string]*domain.Position{}}, &mockTradeRepo{}, &mockStrategy{})
+		require.NoError(t, err)
+		return service, log
+	}
+
+	t.Run("cancels protective orders without a position", func(t *testing.T) {
+		exchange := &mockExchange{openOrders: []*ports.OrderResponse{
+			{OrderID: 11, Type: "STOP_MARKET", ClosePosition: true},
+			{OrderID: 12, Type: "TAKE_PROFIT_MARKET", ClosePosition: true},
+			{OrderID: 13, Type: "STOP_MARKET", ClosePosition: true}, // Left by a crash
+			{OrderID: 14, Type: "LIMIT"},                            // Placed by hand
+		}}
+		service, log := newService(t, exchange)
+		service.positions.Track(&domain.Position{
+			Symbol: "ETHUSDT", Status: domain.StatusOpen, Quantity: 1, EntryPrice: 2000,
+			StopLossOrderID: ptrToString("11"), TakeProfitOrderID: ptrToString("12"),
+		})
+
+		require.NoError(t, service.cancelOrphanedOrders(context.Background()))
+		assert.Equal(t, []int64{13}, exchange.canceledOrders)
+		assert.Contains(t, log.warnMsgs, "Canceling orphaned order")
+		assert.NotContains(t, log.warnMsgs, "Stored protective order is not open on the exchange")
+	})
+
+	t.Run("cancels everything without positions and reports missing stored orders", func(t *testing.T) {
+		exchange := &mockExchange{openOrders: []*ports.OrderResponse{
+			{OrderID: 21, Type: "STOP_MARKET", ClosePosition: true},
+			{OrderID: 22, Type: "TAKE_PROFIT_MARKET", ClosePosition: true},
+		}}
+		service, log := newService(t, exchange)
+		require.NoError(t, service.cancelOrphanedOrders(context.Background()))
+		assert.Equal(t, []int64{21, 22}, exchange.canceledOrders)
+
+		exchange = &mockExchange{}
+		service, log = newService(t, exchange)
+		service.positions.Track(&domain.Position{
+			Symbol: "ETHUSDT", Status: domain.StatusOpen, Quantity: 1, EntryPrice: 2000, StopLossOrderID: ptrToString("31"),
+		})
+		require.NoError(t, service.cancelOrphanedOrders(context.Background()))
+		assert.Empty(t, exchange.canceledOrders)
+		assert.Contains(t, log.warnMsgs, "Stored protective order is not open on the exchange")
+	})
+
+	t.Run("fails when the orders can't be listed", func(t *testing.T) {
+		service, _ := newService(t, &mockExchange{openOrdersErr: ports.ErrExchangeUnavailable})
+		err := service.cancelOrphanedOrders(context.Background())
+		assert.ErrorIs(t, err, ports.ErrExchangeUnavailable)
+	})
+}
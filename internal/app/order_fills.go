@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// WithOrderFills records the executions of the bot's entry, scale-in and closing orders in repo.
+// Fills come from the order response or, if it has none, from the exchange client (if it
+// implements ports.OrderFillProvider). Positions then use the volume-weighted fill prices instead
+// of the order's AvgPrice, and their PNL is net of the commissions paid in the quote asset.
+func WithOrderFills(repo ports.OrderFillRepository) Option {
+	return func(s *TradingService) {
+		s.fillRepo = repo
+	}
+}
+
+// orderFills returns the executions of order, tagged with role. Returns nil if fills aren't
+// recorded or the exchange doesn't report them.
+func (s *TradingService) orderFills(ctx context.Context, order *ports.OrderResponse, role domain.FillRole) []*domain.OrderFill {
+	if s.fillRepo == nil {
+		return nil
+	}
+	executions := order.Fills
+	if len(executions) == 0 {
+		provider, ok := s.exchange.(ports.OrderFillProvider)
+		if !ok {
+			return nil
+		}
+		var err error
+		executions, err = provider.GetOrderFills(ctx, s.cfg.Symbol, order.OrderID)
+		if err != nil {
+			s.logger.Warn(ctx, "Failed to fetch order fills, using the order's average price", map[string]interface{}{
+				"orderID": order.OrderID,
+				"role":    role,
+				"error":   err.Error(),
+			})
+			return nil
+		}
+	}
+
+	fills := make([]*domain.OrderFill, 0, len(executions))
+	for _, e := range executions {
+		fills = append(fills, &domain.OrderFill{
+			OrderID:         order.OrderID,
+			TradeID:         e.ID,
+			Symbol:          s.cfg.Symbol,
+			Side:            e.Side,
+			Role:            role,
+			Price:           e.Price,
+			Quantity:        e.Quantity,
+			Commission:      e.Commission,
+			CommissionAsset: e.CommissionAsset,
+			Time:            e.Time,
+		})
+	}
+	return fills
+}
+
+// averageFillPrice returns the average price of fills, or avgPrice if there are none.
+func averageFillPrice(fills []*domain.OrderFill, avgPrice float64) float64 {
+	if summary := domain.SummarizeFills(fills); summary.Quantity > 0 {
+		return summary.AvgPrice
+	}
+	return avgPrice
+}
+
+// saveOrderFills stores the fills of an order placed for the position with the given ID.
+// Failures are logged; the position itself is unaffected.
+func (s *TradingService) saveOrderFills(ctx context.Context, positionID int64, fills []*domain.OrderFill) {
+	if s.fillRepo == nil || len(fills) == 0 {
+		return
+	}
+	for _, f := range fills {
+		f.PositionID = positionID
+	}
+	if err := s.fillRepo.SaveOrderFills(ctx, fills); err != nil {
+		s.logger.Error(ctx, err, "Failed to save order fills", map[string]interface{}{
+			"positionID": positionID,
+			"orderID":    fills[0].OrderID,
+			"role":       fills[0].Role,
+		})
+	}
+}
+
+// positionFees returns the commissions of the position's saved entry and scale-in fills, or its
+// in-memory Fees if they can't be loaded (e.g., the fills weren't saved).
+func (s *TradingService) positionFees(ctx context.Context, pos *domain.Position) float64 {
+	if s.fillRepo == nil {
+		return pos.Fees
+	}
+	fills, err := s.fillRepo.FindOrderFills(ctx, pos.ID)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to load order fills of position", map[string]interface{}{
+			"positionID": pos.ID,
+			"error":      err.Error(),
+		})
+		return pos.Fees
+	}
+	opening := make([]*domain.OrderFill, 0, len(fills))
+	for _, f := range fills {
+		if f.Role != domain.FillRoleExit {
+			opening = append(opening, f)
+		}
+	}
+	if len(opening) == 0 {
+		return pos.Fees
+	}
+	return domain.SummarizeFills(opening).Commission
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+type mockFillRepo struct {
+	fills   []*domain.OrderFill
+	saveErr error
+}
+
+func (m *mockFillRepo) SaveOrderFills(ctx context.Context, fills []*domain.OrderFill) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.fills = append(m.fills, fills...)
+	return nil
+}
+
+func (m *mockFillRepo) FindOrderFills(ctx context.Context, positionID int64) ([]*domain.OrderFill, error) {
+	var fills []*domain.OrderFill
+	for _, f := range m.fills {
+		if f.PositionID == positionID {
+			fills = append(fills, f)
+		}
+	}
+	return fills, nil
+}
+
+// fillExchange is a mockExchange that looks up order fills by order ID.
+type fillExchange struct {
+	*mockExchange
+	fills    map[int64][]ports.AccountFill
+	fillsErr error
+}
+
+func (f *fillExchange) GetOrderFills(ctx context.Context, symbol string, orderID int64) ([]ports.AccountFill, error) {
+	return f.fills[orderID], f.fillsErr
+}
+
+func TestTradingService_OrderFills(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 5}
+	at := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	newService := func(t *testing.T) (*TradingService, *fillExchange, *mockFillRepo) {
+		exchange := &fillExchange{
+			mockExchange: &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+				"market_BUY": {OrderID: 1, AvgPrice: 2005, Fills: []ports.AccountFill{
+					{ID: 11, OrderID: 1, Symbol: "ETHUSDT", Side: domain.Buy, Price: 2000, Quantity: 0.3, Commission: 0.24, CommissionAsset: "USDT", Time: at},
+					{ID: 12, OrderID: 1, Symbol: "ETHUSDT", Side: domain.Buy, Price: 2010, Quantity: 0.7, Commission: 0.5628, CommissionAsset: "USDT", Time: at},
+				}},
+				"stop_SELL":   {OrderID: 2},
+				"tp_SELL":     {OrderID: 3},
+				"market_SELL": {OrderID: 4, AvgPrice: 2049},
+			}},
+			fills: map[int64][]ports.AccountFill{
+				4: {{ID: 41, OrderID: 4, Symbol: "ETHUSDT", Side: domain.Sell, Price: 2050, Quantity: 1, Commission: 0.82, CommissionAsset: "USDT", Time: at.Add(time.Hour)}},
+			},
+		}
+		fillRepo := &mockFillRepo{}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithOrderFills(fillRepo))
+		require.NoError(t, err)
+		return service, exchange, fillRepo
+	}
+	ctx := context.Background()
+
+	t.Run("prices and PNL come from the fills", func(t *testing.T) {
+		service, _, fillRepo := newService(t)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		pos := service.positions.long
+		assert.InDelta(t, 2007, pos.EntryPrice, 1e-9, "volume-weighted entry fill price")
+		assert.InDelta(t, 0.8028, pos.Fees, 1e-9)
+		require.Len(t, fillRepo.fills, 2)
+		assert.Equal(t, pos.ID, fillRepo.fills[0].PositionID)
+		assert.Equal(t, domain.FillRoleEntry, fillRepo.fills[0].Role)
+
+		pos.Fees = 0 // As after a restart: the entry commissions are loaded from the saved fills
+		require.NoError(t, service.closePosition(ctx, pos, 2040, domain.CloseReasonTakeProfit))
+		assert.Equal(t, 2050.0, pos.ExitPrice, "exit fill fetched from the exchange")
+		assert.InDelta(t, 1.6228, pos.Fees, 1e-9)
+		assert.InDelta(t, 43-1.6228, pos.PNL, 1e-9)
+		require.Len(t, fillRepo.fills, 3)
+		assert.Equal(t, domain.FillRoleExit, fillRepo.fills[2].Role)
+		assert.Equal(t, int64(4), fillRepo.fills[2].OrderID)
+	})
+
+	t.Run("unavailable fills fall back to the average price", func(t *testing.T) {
+		service, exchange, fillRepo := newService(t)
+		exchange.orderResponses["market_BUY"].Fills = nil
+		exchange.fillsErr = ports.ErrExchangeUnavailable
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		pos := service.positions.long
+		assert.Equal(t, 2005.0, pos.EntryPrice)
+		assert.Zero(t, pos.Fees)
+
+		require.NoError(t, service.closePosition(ctx, pos, 2040, domain.CloseReasonTakeProfit))
+		assert.Equal(t, 2049.0, pos.ExitPrice)
+		assert.InDelta(t, 44, pos.PNL, 1e-9)
+		assert.Empty(t, fillRepo.fills)
+	})
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// WithOrderLog records every order the bot sends to the exchange in repo, including those the
+// exchange rejected, so operators can audit them. Orders placed before their position was saved
+// (entries and their SL/TP) are linked to it once it is.
+func WithOrderLog(repo ports.OrderRepository) Option {
+	return func(s *TradingService) {
+		s.orderLog = repo
+	}
+}
+
+// orderSent handles the outcome of sending sent to the exchange: the OnOrderResult hook is told
+// about it and the order log stores it, completed from the response (or err if it was rejected).
+func (m *PositionManager) orderSent(ctx context.Context, sent domain.Order, order *ports.OrderResponse, err error) {
+	if m.onOrderResult != nil {
+		m.onOrderResult(ctx, err)
+	}
+	if m.orderLog == nil {
+		return
+	}
+	sent.Symbol = m.cfg.Symbol
+	sent.CreatedAt = m.clock.Now().UTC()
+	switch {
+	case err != nil:
+		sent.Status, sent.Error = domain.OrderStatusRejected, err.Error()
+	case order != nil:
+		sent.OrderID, sent.Status = order.OrderID, order.Status
+		if order.ClientOrderID != "" {
+			sent.ClientOrderID = order.ClientOrderID
+		}
+	}
+	if sent.Status == "" {
+		sent.Status = "NEW" // The exchange accepted it without reporting a status
+	}
+	if saveErr := m.orderLog.SaveOrder(ctx, &sent); saveErr != nil {
+		m.logger.Error(ctx, saveErr, "Failed to save order to the order log", map[string]interface{}{
+			"orderID": sent.OrderID,
+			"purpose": sent.Purpose,
+		})
+	}
+}
+
+// assignOrders links orders sent before their position was saved to it. Failures are logged only.
+func (m *PositionManager) assignOrders(ctx context.Context, positionID int64, orderIDs ...int64) {
+	if m.orderLog == nil {
+		return
+	}
+	if err := m.orderLog.AssignOrders(ctx, positionID, orderIDs); err != nil {
+		m.logger.Error(ctx, err, "Failed to link orders to their position", map[string]interface{}{
+			"positionID": positionID,
+			"orderIDs":   orderIDs,
+		})
+	}
+}
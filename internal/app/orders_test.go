@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// mockOrderLog implements ports.OrderRepository for testing
+type mockOrderLog struct {
+	orders []*domain.Order
+}
+
+func (m *mockOrderLog) SaveOrder(ctx context.Context, order *domain.Order) error {
+	order.ID = int64(len(m.orders) + 1)
+	m.orders = append(m.orders, order)
+	return nil
+}
+
+func (m *mockOrderLog) AssignOrders(ctx context.Context, positionID int64, orderIDs []int64) error {
+	for _, o := range m.orders {
+		for _, id := range orderIDs {
+			if id != 0 && o.OrderID == id {
+				o.PositionID = positionID
+			}
+		}
+	}
+	return nil
+}
+
+func (m *mockOrderLog) FindOrders(ctx context.Context, filter ports.OrderFilter) ([]*domain.Order, int, error) {
+	return m.orders, len(m.orders), nil
+}
+
+func TestTradingService_OrderLog(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5, Leverage: 1}
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	orderLog := &mockOrderLog{}
+	exchange := &mockExchange{
+		markPrice: 2000,
+		orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY": {OrderID: 1, ExecutedQty: 0.1, AvgPrice: 2000, Status: "FILLED"},
+			"stop_SELL":  {OrderID: 2, Status: "NEW"},
+			"tp_SELL":    {OrderID: 3, Status: "NEW"},
+		},
+	}
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+		&mockTradeRepo{}, &mockStrategy{}, WithOrderLog(orderLog), WithClock(clock.NewFake(now)))
+	require.NoError(t, err)
+
+	require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, now))
+	require.Len(t, orderLog.orders, 3)
+	entry, sl, tp := orderLog.orders[0], orderLog.orders[1], orderLog.orders[2]
+	assert.Equal(t, domain.OrderPurposeEntry, entry.Purpose)
+	assert.Equal(t, "MARKET", entry.Type)
+	assert.Equal(t, domain.Buy, entry.Side)
+	assert.Equal(t, 0.1, entry.Quantity)
+	assert.Equal(t, "FILLED", entry.Status)
+	assert.Equal(t, now, entry.CreatedAt)
+	assert.Equal(t, domain.OrderPurposeStopLoss, sl.Purpose)
+	assert.Equal(t, "STOP_MARKET", sl.Type)
+	assert.Greater(t, sl.StopPrice, 0.0)
+	assert.Equal(t, domain.OrderPurposeTakeProfit, tp.Purpose)
+	for _, o := range orderLog.orders {
+		assert.Equal(t, "ETHUSDT", o.Symbol)
+		assert.Equal(t, int64(1), o.PositionID, "orders are linked to the saved position")
+	}
+
+	// Rejected orders are logged with their error
+	exchange.orderErrors = map[string]error{"market_SELL": fmt.Errorf("place order: %w", ports.ErrExchangeUnavailable)}
+	_, err = service.ForceClose(ctx, domain.PositionSideLong)
+	require.Error(t, err)
+	require.Len(t, orderLog.orders, 4)
+	exit := orderLog.orders[3]
+	assert.Equal(t, domain.OrderPurposeExit, exit.Purpose)
+	assert.Equal(t, domain.OrderStatusRejected, exit.Status)
+	assert.Equal(t, int64(0), exit.OrderID)
+	assert.Equal(t, int64(1), exit.PositionID)
+	assert.Contains(t, exit.Error, "place order")
+}
@@ -0,0 +1,581 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// PositionManagerConfig holds the dependencies of a PositionManager.
+type PositionManagerConfig struct {
+	Config   *config.Config
+	Logger   ports.Logger
+	Exchange ports.ExchangeClient
+	Repo     ports.PositionRepository
+	OrderLog ports.OrderRepository // Optional: records every order sent, for auditing
+	Clock    ports.Clock           // Optional: defaults to clock.Real
+
+	// Hooks (optional) reporting what the manager does to the orchestrator
+	OnOrderResult func(ctx context.Context, err error)                   // Outcome of every order sent (nil err if accepted)
+	OnEvent       func(ctx context.Context, event ports.Event)           // Orders placed and filled
+	OnCritical    func(ctx context.Context, message string, cause error) // Failures needing an operator
+	OnNotice      func(ctx context.Context, subject, message string)     // Outcomes the operator should know about
+}
+
+// PositionManager owns the bot's positions on the exchange: it tracks the open position of each
+// side, sends entry, exit and protective (SL/TP) orders, unwinds entries it can't protect or
+// save, persists positions and logs the orders it sends. Deciding when to trade, sizing and
+// accounting are left to the caller. It isn't safe for concurrent use: TradingService calls it
+// with its lock held.
+type PositionManager struct {
+	cfg      *config.Config
+	logger   ports.Logger
+	exchange ports.ExchangeClient
+	repo     ports.PositionRepository
+	orderLog ports.OrderRepository
+	clock    ports.Clock
+
+	onOrderResult func(ctx context.Context, err error)
+	onEvent       func(ctx context.Context, event ports.Event)
+	onCritical    func(ctx context.Context, message string, cause error)
+	onNotice      func(ctx context.Context, subject, message string)
+
+	verifyDelay time.Duration // Wait between the checks of the exchange position after an emergency close
+
+	long  *domain.Position // Open long position
+	short *domain.Position // Open short position (only opened for a ports.ShortStrategy)
+}
+
+// NewPositionManager creates a position manager without open positions.
+func NewPositionManager(cfg PositionManagerConfig) *PositionManager {
+	m := &PositionManager{
+		cfg:           cfg.Config,
+		logger:        cfg.Logger,
+		exchange:      cfg.Exchange,
+		repo:          cfg.Repo,
+		orderLog:      cfg.OrderLog,
+		clock:         cfg.Clock,
+		onOrderResult: cfg.OnOrderResult,
+		onEvent:       cfg.OnEvent,
+		onCritical:    cfg.OnCritical,
+		onNotice:      cfg.OnNotice,
+		verifyDelay:   defaultEmergencyVerifyDelay,
+	}
+	if m.clock == nil {
+		m.clock = clock.Real{}
+	}
+	return m
+}
+
+// Position returns the open position on side, or nil.
+func (m *PositionManager) Position(side domain.PositionSide) *domain.Position {
+	if side == domain.PositionSideShort {
+		return m.short
+	}
+	return m.long
+}
+
+// OpenPositions returns the open positions, long first.
+func (m *PositionManager) OpenPositions() []*domain.Position {
+	var open []*domain.Position
+	for _, pos := range []*domain.Position{m.long, m.short} {
+		if pos != nil {
+			open = append(open, pos)
+		}
+	}
+	return open
+}
+
+// Track records pos as the open position of its side.
+func (m *PositionManager) Track(pos *domain.Position) {
+	pos.Contract = m.cfg.Contract // Not persisted; PnL and margin math follow the symbol's contract
+	if pos.PositionSide() == domain.PositionSideShort {
+		m.short = pos
+		return
+	}
+	m.long = pos
+}
+
+// Untrack clears the open position of side.
+func (m *PositionManager) Untrack(side domain.PositionSide) {
+	if side == domain.PositionSideShort {
+		m.short = nil
+		return
+	}
+	m.long = nil
+}
+
+// LoadOpen loads the open position of each side from the repository and tracks it.
+func (m *PositionManager) LoadOpen(ctx context.Context) error {
+	for _, side := range []domain.PositionSide{domain.PositionSideLong, domain.PositionSideShort} {
+		openPos, err := m.repo.FindOpenBySymbolAndSide(ctx, m.cfg.Symbol, side)
+		if err != nil {
+			// State is critical, so failing to load it is fatal
+			m.logger.Error(ctx, err, "Failed to check for existing open position", map[string]interface{}{"side": side})
+			m.logger.Info(ctx, "No existing open position found")
+			return fmt.Errorf("failed to query open position (%s): %w", side, err)
+		}
+		if openPos == nil {
+			m.logger.Info(ctx, "No existing open position found", map[string]interface{}{"side": side})
+			continue
+		}
+		m.Track(openPos)
+		m.logger.Info(ctx, "Found existing open position", map[string]interface{}{"positionID": openPos.ID, "side": side, "entryPrice": openPos.EntryPrice, "takeProfit": openPos.TakeProfit, "stopLoss": openPos.StopLoss})
+	}
+	return nil
+}
+
+// Save persists changes to a stored position.
+func (m *PositionManager) Save(ctx context.Context, pos *domain.Position) error {
+	return m.repo.Update(ctx, pos)
+}
+
+// ExchangeSide returns the position side sent with orders for a position on side: the side
+// itself in hedge mode, BOTH in one-way mode.
+func (m *PositionManager) ExchangeSide(side domain.PositionSide) domain.PositionSide {
+	if m.cfg.HedgeMode {
+		return side
+	}
+	return domain.PositionSideBoth
+}
+
+// PlaceMarketOrder places a market order for purpose (an entry, scale-in add or exit) and
+// publishes it as placed and, if the response reports an execution, as filled. positionID is the
+// position it's placed for, 0 for entries.
+func (m *PositionManager) PlaceMarketOrder(ctx context.Context, positionSide domain.PositionSide, purpose domain.OrderPurpose, positionID int64, quantity, clientOrderID string) (*ports.OrderResponse, error) {
+	exit := purpose == domain.OrderPurposeExit
+	side := positionSide.EntrySide()
+	if exit {
+		side = positionSide.ExitSide()
+	}
+	exchangeSide := m.ExchangeSide(positionSide)
+	order, err := m.exchange.PlaceMarketOrder(ctx, m.cfg.Symbol, side, exchangeSide, quantity, clientOrderID)
+	orderedQty, _ := strconv.ParseFloat(quantity, 64)
+	m.orderSent(ctx, domain.Order{ClientOrderID: clientOrderID, PositionID: positionID, Side: side, PositionSide: exchangeSide,
+		Type: "MARKET", Purpose: purpose, Quantity: orderedQty}, order, err)
+	if err != nil {
+		return nil, err
+	}
+	event := ports.Event{Type: ports.EventOrderPlaced, Side: positionSide, Exit: exit, OrderID: order.OrderID, Quantity: order.OrigQuantity}
+	m.event(ctx, event)
+	if order.AvgPrice > 0 || order.ExecutedQty > 0 {
+		event.Type, event.Quantity, event.Price = ports.EventOrderFilled, order.ExecutedQty, order.AvgPrice
+		m.event(ctx, event)
+	}
+	return order, nil
+}
+
+// PlaceLimitEntry rests a limit order entering a position on side at price. With postOnly the
+// order is placed post-only if the exchange client can (implements ports.PostOnlyOrderPlacer), and
+// fails with ports.ErrPostOnlyRejected instead of filling right away.
+func (m *PositionManager) PlaceLimitEntry(ctx context.Context, side domain.PositionSide, quantity, price float64, clientOrderID string, postOnly bool) (*ports.OrderResponse, error) {
+	placer, ok := m.exchange.(ports.LimitOrderPlacer)
+	if !ok {
+		return nil, fmt.Errorf("exchange client can't place limit orders: %w", ports.ErrConfigurationError)
+	}
+	precision := m.cfg.OrderPrecision()
+	exchangeSide := m.ExchangeSide(side)
+	quantityStr, priceStr := precision.FormatQuantity(quantity), precision.FormatPrice(price)
+	var placed *ports.OrderResponse
+	var err error
+	if postOnlyPlacer, ok := m.exchange.(ports.PostOnlyOrderPlacer); ok && postOnly {
+		placed, err = postOnlyPlacer.PlacePostOnlyLimitOrder(ctx, m.cfg.Symbol, side.EntrySide(), exchangeSide, quantityStr, priceStr, clientOrderID)
+	} else {
+		placed, err = placer.PlaceLimitOrder(ctx, m.cfg.Symbol, side.EntrySide(), exchangeSide, quantityStr, priceStr, clientOrderID)
+	}
+	m.orderSent(ctx, domain.Order{ClientOrderID: clientOrderID, Side: side.EntrySide(), PositionSide: exchangeSide, Type: "LIMIT",
+		Purpose: domain.OrderPurposeEntry, Quantity: quantity, Price: price}, placed, err)
+	if err != nil {
+		return nil, err
+	}
+	m.event(ctx, ports.Event{Type: ports.EventOrderPlaced, Side: side, OrderID: placed.OrderID, Quantity: quantity})
+	return placed, nil
+}
+
+// Protect places the SL/TP orders of a filled entry, saves the position and tracks it as the open
+// position of its side. If any step fails, the orders placed so far are canceled and the entry is
+// closed again. entryOrderID is the filled entry order, linked to the position in the order log.
+func (m *PositionManager) Protect(ctx context.Context, op string, newPosition *domain.Position, entryOrderID int64) error {
+	positionSide := newPosition.PositionSide()
+	side := positionSide.EntrySide()
+	exitSide := positionSide.ExitSide()
+	exchangeSide := m.ExchangeSide(positionSide)
+	precision := m.cfg.OrderPrecision()
+	quantityStr := precision.FormatQuantity(newPosition.Quantity)
+	actualEntryPrice := newPosition.EntryPrice
+	slPriceStr := precision.FormatPrice(newPosition.StopLoss)
+	tpPriceStr := precision.FormatPrice(newPosition.TakeProfit)
+
+	// 4. Place SL order (opposite side)
+	m.logger.Info(ctx, op+": Placing stop loss market order...")
+	slOrder, err := m.exchange.PlaceStopMarketOrder(ctx, m.cfg.Symbol, exitSide, exchangeSide, quantityStr, slPriceStr)
+	m.orderSent(ctx, domain.Order{Side: exitSide, PositionSide: exchangeSide, Type: "STOP_MARKET", Purpose: domain.OrderPurposeStopLoss,
+		StopPrice: newPosition.StopLoss}, slOrder, err)
+	if err != nil {
+		m.logger.Error(ctx, err, op+": Failed to place stop loss order")
+		// Critical failure: We have an open position without a stop loss.
+		// Attempt to close the position immediately as a safety measure.
+		m.logger.Warn(ctx, op+": Attempting emergency close due to SL placement failure...")
+		closeErr := m.EmergencyClose(ctx, actualEntryPrice, quantityStr, side, exchangeSide)
+		if closeErr != nil {
+			m.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED")
+			// This is a very bad state. Manual intervention likely required.
+			m.critical(ctx, "Emergency close failed after stop loss placement failure", closeErr)
+		}
+		return fmt.Errorf("stop loss order failed after entry: %w (emergency close attempted)", err)
+	}
+	m.logger.Info(ctx, op+": Stop loss order placed", map[string]interface{}{"orderID": slOrder.OrderID, "stopPrice": slPriceStr})
+
+	// 5. Place TP order (opposite side)
+	m.logger.Info(ctx, op+": Placing take profit market order...")
+	tpOrder, err := m.exchange.PlaceTakeProfitMarketOrder(ctx, m.cfg.Symbol, exitSide, exchangeSide, quantityStr, tpPriceStr)
+	m.orderSent(ctx, domain.Order{Side: exitSide, PositionSide: exchangeSide, Type: "TAKE_PROFIT_MARKET", Purpose: domain.OrderPurposeTakeProfit,
+		StopPrice: newPosition.TakeProfit}, tpOrder, err)
+	if err != nil {
+		m.logger.Error(ctx, err, op+": Failed to place take profit order")
+		// Less critical than SL failure, but still problematic.
+		// Cancel the SL order and close the position.
+		m.logger.Warn(ctx, op+": Attempting emergency close due to TP placement failure...")
+		cancelErr := m.CancelOrder(ctx, slOrder.OrderID, "SL")
+		if cancelErr != nil {
+			// Log but proceed with close attempt
+			m.logger.Error(ctx, cancelErr, op+": Failed to cancel SL order during TP failure cleanup")
+		}
+		closeErr := m.EmergencyClose(ctx, actualEntryPrice, quantityStr, side, exchangeSide)
+		if closeErr != nil {
+			m.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED after TP failure")
+			m.critical(ctx, "Emergency close failed after take profit placement failure", closeErr)
+		}
+		return fmt.Errorf("take profit order failed after entry: %w (emergency close attempted)", err)
+	}
+	m.logger.Info(ctx, op+": Take profit order placed", map[string]interface{}{"orderID": tpOrder.OrderID, "stopPrice": tpPriceStr})
+
+	// --- Persistence and State Update ---
+	// 6. Complete the domain.Position with its order IDs
+	newPosition.StopLossOrderID = ptrToString(strconv.FormatInt(slOrder.OrderID, 10)) // Store order IDs
+	newPosition.TakeProfitOrderID = ptrToString(strconv.FormatInt(tpOrder.OrderID, 10))
+	if err := newPosition.Open(); err != nil {
+		// Orders are live but the fill can't be represented (e.g. zero fill price); unwind like a DB failure
+		m.logger.Error(ctx, err, op+": Invalid position after entry fill", map[string]interface{}{"entryPrice": actualEntryPrice, "quantity": newPosition.Quantity})
+		_ = m.CancelOrder(ctx, slOrder.OrderID, "SL")
+		_ = m.CancelOrder(ctx, tpOrder.OrderID, "TP")
+		if closeErr := m.EmergencyClose(ctx, actualEntryPrice, quantityStr, side, exchangeSide); closeErr != nil {
+			m.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED after invalid position")
+			m.critical(ctx, "Emergency close failed after invalid entry fill", closeErr)
+		}
+		return fmt.Errorf("invalid position after entry: %w (emergency close attempted)", err)
+	}
+
+	// 7. Save position via repo.Create
+	posID, err := m.repo.Create(ctx, newPosition)
+	if err != nil {
+		m.logger.Error(ctx, err, op+": Failed to save new position to repository")
+		// This is also problematic. We have orders placed but no DB record.
+		// Attempt to cancel orders and close position.
+		m.logger.Warn(ctx, op+": Attempting emergency close due to DB save failure...")
+		cancelSlErr := m.CancelOrder(ctx, slOrder.OrderID, "SL")
+		cancelTpErr := m.CancelOrder(ctx, tpOrder.OrderID, "TP")
+		closeErr := m.EmergencyClose(ctx, actualEntryPrice, quantityStr, side, exchangeSide)
+		// Log all errors
+		if cancelSlErr != nil {
+			m.logger.Error(ctx, cancelSlErr, op+": Failed to cancel SL order during DB failure cleanup")
+		}
+		if cancelTpErr != nil {
+			m.logger.Error(ctx, cancelTpErr, op+": Failed to cancel TP order during DB failure cleanup")
+		}
+		if closeErr != nil {
+			m.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED after DB failure")
+			m.critical(ctx, "Emergency close failed after database failure", closeErr)
+		}
+		return fmt.Errorf("failed to save position to DB after placing orders: %w (emergency close attempted)", err)
+	}
+	newPosition.ID = posID // Set the ID returned by the database
+	m.logger.Info(ctx, op+": New position saved to DB", map[string]interface{}{"positionID": newPosition.ID})
+	m.assignOrders(ctx, newPosition.ID, entryOrderID, slOrder.OrderID, tpOrder.OrderID)
+
+	// 8. Track the position
+	m.Track(newPosition)
+	return nil
+}
+
+// ResizeProtectiveOrders replaces the position's SL/TP orders with orders for its current quantity
+// at the same prices. The old stop loss is canceled before the new one is placed, so two stops
+// never rest at once and together close more than the position; if it can't be canceled, nothing
+// is replaced. Fails if the stop loss can't be replaced: StopLossOrderID is then nil if the old one
+// was already canceled, and RestoreStopLoss must put one back. A take profit that can't be
+// replaced keeps covering the previous quantity.
+func (m *PositionManager) ResizeProtectiveOrders(ctx context.Context, op string, pos *domain.Position) error {
+	if pos.StopLossOrderID != nil {
+		oldID, _ := strconv.ParseInt(*pos.StopLossOrderID, 10, 64)
+		if err := m.CancelOrder(ctx, oldID, "SL"); err != nil {
+			return fmt.Errorf("failed to cancel stop loss order before resizing it: %w", err)
+		}
+		pos.StopLossOrderID = nil
+	}
+	if err := m.RestoreStopLoss(ctx, pos); err != nil {
+		return fmt.Errorf("failed to place resized stop loss order: %w", err)
+	}
+
+	exitSide := pos.PositionSide().ExitSide()
+	exchangeSide := m.ExchangeSide(pos.PositionSide())
+	precision := m.cfg.OrderPrecision()
+	quantityStr := precision.FormatQuantity(pos.Quantity)
+
+	tpOrder, err := m.exchange.PlaceTakeProfitMarketOrder(ctx, m.cfg.Symbol, exitSide, exchangeSide, quantityStr, precision.FormatPrice(pos.TakeProfit))
+	m.orderSent(ctx, domain.Order{PositionID: pos.ID, Side: exitSide, PositionSide: exchangeSide, Type: "TAKE_PROFIT_MARKET",
+		Purpose: domain.OrderPurposeTakeProfit, StopPrice: pos.TakeProfit}, tpOrder, err)
+	if err != nil {
+		m.logger.Error(ctx, err, op+": Failed to place resized take profit order, keeping the previous one", map[string]interface{}{"positionID": pos.ID})
+		return nil
+	}
+	if pos.TakeProfitOrderID != nil {
+		oldID, _ := strconv.ParseInt(*pos.TakeProfitOrderID, 10, 64)
+		_ = m.CancelOrder(ctx, oldID, "TP")
+	}
+	pos.TakeProfitOrderID = ptrToString(strconv.FormatInt(tpOrder.OrderID, 10))
+	return nil
+}
+
+// RestoreStopLoss places a stop loss order for the position's current quantity if it has none,
+// e.g. after ResizeProtectiveOrders canceled the old one but couldn't place its replacement.
+func (m *PositionManager) RestoreStopLoss(ctx context.Context, pos *domain.Position) error {
+	if pos.StopLossOrderID != nil {
+		return nil
+	}
+	exitSide := pos.PositionSide().ExitSide()
+	exchangeSide := m.ExchangeSide(pos.PositionSide())
+	precision := m.cfg.OrderPrecision()
+	slOrder, err := m.exchange.PlaceStopMarketOrder(ctx, m.cfg.Symbol, exitSide, exchangeSide, precision.FormatQuantity(pos.Quantity), precision.FormatPrice(pos.StopLoss))
+	m.orderSent(ctx, domain.Order{PositionID: pos.ID, Side: exitSide, PositionSide: exchangeSide, Type: "STOP_MARKET",
+		Purpose: domain.OrderPurposeStopLoss, StopPrice: pos.StopLoss}, slOrder, err)
+	if err != nil {
+		return err
+	}
+	pos.StopLossOrderID = ptrToString(strconv.FormatInt(slOrder.OrderID, 10))
+	return nil
+}
+
+// CancelProtectiveOrders cancels the SL/TP orders of a closed position. Failures are logged only.
+func (m *PositionManager) CancelProtectiveOrders(ctx context.Context, pos *domain.Position) {
+	if pos.StopLossOrderID != nil {
+		slOrderID, _ := strconv.ParseInt(*pos.StopLossOrderID, 10, 64)
+		_ = m.CancelOrder(ctx, slOrderID, "SL")
+	}
+	if pos.TakeProfitOrderID != nil {
+		tpOrderID, _ := strconv.ParseInt(*pos.TakeProfitOrderID, 10, 64)
+		_ = m.CancelOrder(ctx, tpOrderID, "TP")
+	}
+}
+
+const (
+	// emergencyCloseAttempts is how many times an emergency close is sent while it fails with
+	// retryable errors.
+	emergencyCloseAttempts = 3
+	// emergencyVerifyAttempts is how many times the exchange position is checked after an
+	// emergency close before the residual is closed again.
+	emergencyVerifyAttempts = 5
+	// defaultEmergencyVerifyDelay is the wait between those checks, for the fill to show in the
+	// exchange position.
+	defaultEmergencyVerifyDelay = 500 * time.Millisecond
+	// quantityTolerance absorbs float noise when comparing position quantities.
+	quantityTolerance = 1e-9
+)
+
+// EmergencyClose places a market order to close the current exposure, retrying it right away
+// (up to emergencyCloseAttempts times) while it fails with a retryable error. It then checks the
+// exchange position until the closed quantity is gone from it; a residual still there (e.g., after
+// a partial fill) is closed with a reduce-only order, and the final state is reported through the
+// OnNotice hook. Returns an error if the order failed or a residual remains.
+// Assumes entrySide was the side used to open the position, on positionSide in hedge mode.
+// Used when SL/TP placement fails after entry.
+func (m *PositionManager) EmergencyClose(ctx context.Context, entryPrice float64, quantityStr string, entrySide domain.OrderSide, positionSide domain.PositionSide) error {
+	op := "emergencyClose"
+	closeSide := domain.Sell
+	if entrySide == domain.Sell {
+		closeSide = domain.Buy
+	}
+	// The exposure before the close tells what it should leave: closing a scale-in add keeps the
+	// initial position open
+	before, baselineErr := m.exposure(ctx, entrySide)
+
+	m.logger.Warn(ctx, op+": Placing emergency closing order", map[string]interface{}{"side": closeSide, "quantity": quantityStr})
+	quantity, _ := strconv.ParseFloat(quantityStr, 64)
+	var order *ports.OrderResponse
+	var err error
+	for attempt := 1; attempt <= emergencyCloseAttempts; attempt++ {
+		order, err = m.exchange.PlaceMarketOrder(ctx, m.cfg.Symbol, closeSide, positionSide, quantityStr, "")
+		m.orderSent(ctx, domain.Order{Side: closeSide, PositionSide: positionSide, Type: "MARKET", Purpose: domain.OrderPurposeEmergencyClose,
+			Quantity: quantity}, order, err)
+		if err == nil || !ports.IsRetryable(err) || attempt == emergencyCloseAttempts {
+			break
+		}
+		m.logger.Warn(ctx, op+": Emergency close failed with a retryable error, retrying", map[string]interface{}{"attempt": attempt, "error": err.Error()})
+	}
+	if err != nil {
+		m.logger.Error(ctx, err, op+": FAILED TO PLACE EMERGENCY CLOSE ORDER")
+		return fmt.Errorf("emergency close order placement failed: %w", err)
+	}
+	if order != nil {
+		closed := domain.PositionSideLong
+		if entrySide == domain.Sell {
+			closed = domain.PositionSideShort
+		}
+		m.event(ctx, ports.Event{Type: ports.EventOrderPlaced, Side: closed, Exit: true, OrderID: order.OrderID, Quantity: order.OrigQuantity})
+	}
+	m.logger.Info(ctx, op+": Emergency close order placed successfully")
+	// Note: This does not update DB state, as the position might not have been saved yet.
+	// It's purely a safety mechanism on the exchange side.
+
+	if baselineErr != nil {
+		m.logger.Warn(ctx, op+": Can't verify the emergency close without the position before it", map[string]interface{}{"error": baselineErr.Error()})
+		m.critical(ctx, "Emergency close placed but not verified, check the exchange position", baselineErr)
+		return nil
+	}
+	target := math.Max(0, before-quantity)
+	residual, err := m.emergencyResidual(ctx, entrySide, target)
+	if err == nil && residual > 0 {
+		residualStr := m.cfg.OrderPrecision().FormatQuantity(residual)
+		m.logger.Warn(ctx, op+": Position not flat after the emergency close, closing the residual reduce-only", map[string]interface{}{
+			"residual": residualStr,
+			"expected": target,
+		})
+		err = m.placeReduceOnly(ctx, closeSide, positionSide, residualStr)
+		if errors.Is(err, ports.ErrReduceOnlyRejected) {
+			// Nothing is left to reduce, e.g. the stop loss filled meanwhile; the check below tells
+			m.logger.Warn(ctx, op+": Reduce-only close of the residual rejected, checking the position again", map[string]interface{}{"error": err.Error()})
+		}
+		if err == nil || errors.Is(err, ports.ErrReduceOnlyRejected) {
+			residual, err = m.emergencyResidual(ctx, entrySide, target)
+		}
+	}
+	switch {
+	case err != nil:
+		m.logger.Error(ctx, err, op+": Failed to verify the emergency close")
+		m.critical(ctx, "Emergency close placed but not verified, check the exchange position", err)
+		return nil
+	case residual > 0:
+		residualStr := m.cfg.OrderPrecision().FormatQuantity(residual)
+		m.logger.Error(ctx, nil, op+": POSITION NOT FLAT AFTER EMERGENCY CLOSE", map[string]interface{}{"residual": residualStr})
+		return fmt.Errorf("emergency close left %s open on the exchange", residualStr)
+	}
+	m.logger.Info(ctx, op+": Emergency close verified", map[string]interface{}{"exposure": target})
+	m.notice(ctx, fmt.Sprintf("%s emergency close verified", m.cfg.Symbol),
+		fmt.Sprintf("Symbol: %s\nClosed: %s at market (entry price %s)\nRemaining exposure on the exchange: %s",
+			m.cfg.Symbol, quantityStr, strconv.FormatFloat(entryPrice, 'f', -1, 64), m.cfg.OrderPrecision().FormatQuantity(target)))
+	return nil
+}
+
+// emergencyResidual checks the exchange position up to emergencyVerifyAttempts times, waiting
+// verifyDelay between the checks, and returns how much of the exposure is left above target (0
+// once it's down to it). Returns the last error if no check succeeded after the last wait.
+func (m *PositionManager) emergencyResidual(ctx context.Context, entrySide domain.OrderSide, target float64) (float64, error) {
+	var residual float64
+	var err error
+	for attempt := 1; attempt <= emergencyVerifyAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return residual, ctx.Err()
+			case <-time.After(m.verifyDelay):
+			}
+		}
+		var exposure float64
+		if exposure, err = m.exposure(ctx, entrySide); err != nil {
+			continue
+		}
+		if residual = exposure - target; residual <= quantityTolerance {
+			return 0, nil
+		}
+	}
+	return residual, err
+}
+
+// exposure returns the size of the exchange position in the direction of entrySide (0 if flat or
+// on the other side). In hedge mode the other side's position is ignored.
+func (m *PositionManager) exposure(ctx context.Context, entrySide domain.OrderSide) (float64, error) {
+	risks, err := m.exchange.GetPositionRisks(ctx, m.cfg.Symbol)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the exchange position: %w", err)
+	}
+	side := domain.PositionSideLong
+	if entrySide == domain.Sell {
+		side = domain.PositionSideShort
+	}
+	risk := ports.PositionRiskFor(risks, side)
+	if risk == nil {
+		return 0, nil
+	}
+	return math.Abs(risk.PositionAmt), nil
+}
+
+// placeReduceOnly sends a reduce-only market order, or a plain one if the exchange client can't
+// place reduce-only orders.
+func (m *PositionManager) placeReduceOnly(ctx context.Context, side domain.OrderSide, positionSide domain.PositionSide, quantityStr string) error {
+	var order *ports.OrderResponse
+	var err error
+	if placer, ok := m.exchange.(ports.ReduceOnlyOrderPlacer); ok {
+		order, err = placer.PlaceReduceOnlyMarketOrder(ctx, m.cfg.Symbol, side, positionSide, quantityStr)
+	} else {
+		order, err = m.exchange.PlaceMarketOrder(ctx, m.cfg.Symbol, side, positionSide, quantityStr, "")
+	}
+	quantity, _ := strconv.ParseFloat(quantityStr, 64)
+	m.orderSent(ctx, domain.Order{Side: side, PositionSide: positionSide, Type: "MARKET", Purpose: domain.OrderPurposeEmergencyClose,
+		Quantity: quantity}, order, err)
+	if err != nil {
+		return fmt.Errorf("reduce-only close of the residual failed: %w", err)
+	}
+	return nil
+}
+
+// CancelOrder attempts to cancel an order and logs a warning on failure. An order that no longer
+// exists (already filled or canceled) is not an error.
+func (m *PositionManager) CancelOrder(ctx context.Context, orderID int64, orderType string) error {
+	op := "cancelOrder"
+	m.logger.Info(ctx, op+": Attempting to cancel order", map[string]interface{}{"symbol": m.cfg.Symbol, "orderID": orderID, "type": orderType})
+	_, err := m.exchange.CancelOrder(ctx, m.cfg.Symbol, orderID)
+	if err != nil {
+		// Ignore "Order does not exist" errors, as it might have already been filled or cancelled.
+		if errors.Is(err, ports.ErrOrderNotFound) {
+			m.logger.Warn(ctx, op+": Order not found, likely already filled or cancelled", map[string]interface{}{"orderID": orderID, "type": orderType})
+			return nil // Not an error in this context
+		}
+		m.logger.Error(ctx, err, op+": Failed to cancel order", map[string]interface{}{"orderID": orderID, "type": orderType})
+		return err // Return other errors
+	}
+	m.logger.Info(ctx, op+": Order cancelled successfully", map[string]interface{}{"orderID": orderID, "type": orderType})
+	return nil
+}
+
+// event passes event to the OnEvent hook, if set.
+func (m *PositionManager) event(ctx context.Context, event ports.Event) {
+	if m.onEvent != nil {
+		m.onEvent(ctx, event)
+	}
+}
+
+// critical passes a failure needing an operator to the OnCritical hook, if set.
+func (m *PositionManager) critical(ctx context.Context, message string, cause error) {
+	if m.onCritical != nil {
+		m.onCritical(ctx, message, cause)
+	}
+}
+
+// notice passes an outcome for the operator to the OnNotice hook, if set.
+func (m *PositionManager) notice(ctx context.Context, subject, message string) {
+	if m.onNotice != nil {
+		m.onNotice(ctx, subject, message)
+	}
+}
+
+// ptrToString converts a string to a pointer to a string.
+func ptrToString(s string) *string {
+	return &s
+}
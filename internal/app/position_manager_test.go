@@ -0,0 +1,230 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/testharness"
+)
+
+func TestPositionManager(t *testing.T) {
+	ctx := context.Background()
+	type recorded struct {
+		results   []error
+		events    []ports.EventType
+		criticals []string
+		notices   []string
+	}
+	newManager := func(exchange *mockExchange, repo *mockPositionRepo, orderLog ports.OrderRepository) (*PositionManager, *recorded) {
+		rec := &recorded{}
+		return NewPositionManager(PositionManagerConfig{
+			Config:        &config.Config{Symbol: "ETHUSDT"},
+			Logger:        &mockLogger{},
+			Exchange:      exchange,
+			Repo:          repo,
+			OrderLog:      orderLog,
+			OnOrderResult: func(ctx context.Context, err error) { rec.results = append(rec.results, err) },
+			OnEvent:       func(ctx context.Context, event ports.Event) { rec.events = append(rec.events, event.Type) },
+			OnCritical:    func(ctx context.Context, message string, cause error) { rec.criticals = append(rec.criticals, message) },
+			OnNotice:      func(ctx context.Context, subject, message string) { rec.notices = append(rec.notices, subject) },
+		}), rec
+	}
+	entry := func() *domain.Position {
+		return &domain.Position{Symbol: "ETHUSDT", Side: domain.PositionSideLong, EntryPrice: 2000, Quantity: 0.1, StopLoss: 1980, TakeProfit: 2040}
+	}
+
+	t.Run("protects, saves and tracks an entry", func(t *testing.T) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY": {OrderID: 1, ExecutedQty: 0.1, AvgPrice: 2000, Status: "FILLED"},
+			"stop_SELL":  {OrderID: 2},
+			"tp_SELL":    {OrderID: 3},
+		}}
+		repo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		orderLog := &mockOrderLog{}
+		manager, rec := newManager(exchange, repo, orderLog)
+
+		order, err := manager.PlaceMarketOrder(ctx, domain.PositionSideLong, domain.OrderPurposeEntry, 0, "0.1", "entry-1")
+		require.NoError(t, err)
+		assert.Equal(t, []ports.EventType{ports.EventOrderPlaced, ports.EventOrderFilled}, rec.events)
+
+		pos := entry()
+		require.NoError(t, manager.Protect(ctx, "test", pos, order.OrderID))
+		assert.Same(t, pos, manager.Position(domain.PositionSideLong))
+		assert.Nil(t, manager.Position(domain.PositionSideShort))
+		assert.Same(t, pos, repo.positions["ETHUSDT"])
+		assert.Equal(t, domain.StatusOpen, pos.Status)
+		assert.Equal(t, "2", *pos.StopLossOrderID)
+		assert.Equal(t, "3", *pos.TakeProfitOrderID)
+		assert.Equal(t, []error{nil, nil, nil}, rec.results)
+		require.Len(t, orderLog.orders, 3)
+		for _, o := range orderLog.orders {
+			assert.Equal(t, pos.ID, o.PositionID)
+		}
+
+		manager.CancelProtectiveOrders(ctx, pos)
+		assert.Equal(t, []int64{2, 3}, exchange.canceledOrders)
+		manager.Untrack(domain.PositionSideLong)
+		assert.Empty(t, manager.OpenPositions())
+	})
+
+	t.Run("closes an entry it can't protect", func(t *testing.T) {
+		exchange := &mockExchange{
+			orderResponses: map[string]*ports.OrderResponse{"market_SELL": {OrderID: 9}},
+			orderErrors:    map[string]error{"stop_SELL": fmt.Errorf("place order: %w", ports.ErrExchangeUnavailable)},
+		}
+		repo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		manager, rec := newManager(exchange, repo, nil)
+
+		err := manager.Protect(ctx, "test", entry(), 1)
+		require.Error(t, err)
+		assert.Empty(t, manager.OpenPositions())
+		assert.Empty(t, repo.positions)
+		assert.Equal(t, "0.100", exchange.marketOrderQty, "the entry is closed again")
+		require.Len(t, rec.results, 2)
+		assert.ErrorIs(t, rec.results[0], ports.ErrExchangeUnavailable)
+		assert.NoError(t, rec.results[1])
+		assert.Empty(t, rec.criticals)
+
+		exchange.orderErrors["market_SELL"] = errors.New("rejected")
+		require.Error(t, manager.Protect(ctx, "test", entry(), 1))
+		assert.Equal(t, []string{"Emergency close failed after stop loss placement failure"}, rec.criticals)
+		assert.Len(t, rec.results, 4, "an unclassified failure isn't retried")
+
+		// Retryable failures are retried before giving up
+		exchange.orderErrors["market_SELL"] = fmt.Errorf("place order: %w", ports.ErrTimeout)
+		require.Error(t, manager.Protect(ctx, "test", entry(), 1))
+		assert.Len(t, rec.results, 4+1+emergencyCloseAttempts)
+	})
+
+	t.Run("verifies an emergency close and closes the residual reduce-only", func(t *testing.T) {
+		long := func(amount float64) *ports.PositionRisk {
+			return &ports.PositionRisk{Symbol: "ETHUSDT", PositionAmt: amount, PositionSide: domain.PositionSideBoth}
+		}
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{"market_SELL": {OrderID: 9}, "reduce_SELL": {OrderID: 10}}}
+		manager, rec := newManager(exchange, &mockPositionRepo{}, nil)
+		manager.verifyDelay = 0
+
+		// Flat after the close
+		exchange.positionRisks = []*ports.PositionRisk{long(0.1)}
+		exchange.positionRisk = long(0)
+		require.NoError(t, manager.EmergencyClose(ctx, 2000, "0.1", domain.Buy, domain.PositionSideBoth))
+		assert.Empty(t, exchange.reduceOnlyQty)
+		assert.Equal(t, []string{"ETHUSDT emergency close verified"}, rec.notices)
+
+		// A partial fill leaves 0.04, which the reduce-only order closes
+		exchange.positionRisks = []*ports.PositionRisk{long(0.1), long(0.04), long(0.04), long(0.04), long(0.04), long(0.04)}
+		require.NoError(t, manager.EmergencyClose(ctx, 2000, "0.1", domain.Buy, domain.PositionSideBoth))
+		assert.Equal(t, []string{"0.040"}, exchange.reduceOnlyQty)
+		assert.Len(t, rec.notices, 2)
+
+		// Closing a scale-in add leaves the initial position open
+		exchange.reduceOnlyQty = nil
+		exchange.positionRisks = []*ports.PositionRisk{long(0.3)}
+		exchange.positionRisk = long(0.2)
+		require.NoError(t, manager.EmergencyClose(ctx, 2000, "0.1", domain.Buy, domain.PositionSideBoth))
+		assert.Empty(t, exchange.reduceOnlyQty)
+
+		// A residual the reduce-only order doesn't close is an error
+		exchange.positionRisks = []*ports.PositionRisk{long(0.1)}
+		exchange.positionRisk = long(0.05)
+		err := manager.EmergencyClose(ctx, 2000, "0.1", domain.Buy, domain.PositionSideBoth)
+		assert.EqualError(t, err, "emergency close left 0.050 open on the exchange")
+		assert.Equal(t, []string{"0.050"}, exchange.reduceOnlyQty)
+		assert.Len(t, rec.notices, 3)
+		assert.Empty(t, rec.criticals)
+
+		// In hedge mode the open short doesn't hide the closed long
+		exchange.reduceOnlyQty = nil
+		exchange.otherSideRisk = &ports.PositionRisk{Symbol: "ETHUSDT", PositionAmt: -0.5, PositionSide: domain.PositionSideShort}
+		exchange.positionRisks = []*ports.PositionRisk{{Symbol: "ETHUSDT", PositionAmt: 0.1, PositionSide: domain.PositionSideLong}}
+		exchange.positionRisk = nil
+		require.NoError(t, manager.EmergencyClose(ctx, 2000, "0.1", domain.Buy, domain.PositionSideLong))
+		assert.Empty(t, exchange.reduceOnlyQty)
+		assert.Len(t, rec.notices, 4)
+		assert.Empty(t, rec.criticals)
+		exchange.otherSideRisk = nil
+
+		// Without the exchange position the close can't be verified
+		exchange.positionRiskErr = errors.New("timeout")
+		require.NoError(t, manager.EmergencyClose(ctx, 2000, "0.1", domain.Buy, domain.PositionSideBoth))
+		assert.Equal(t, []string{"Emergency close placed but not verified, check the exchange position"}, rec.criticals)
+	})
+
+	t.Run("sends orders for the hedge mode side", func(t *testing.T) {
+		manager, _ := newManager(&mockExchange{}, &mockPositionRepo{}, nil)
+		assert.Equal(t, domain.PositionSideBoth, manager.ExchangeSide(domain.PositionSideShort))
+		manager.cfg.HedgeMode = true
+		assert.Equal(t, domain.PositionSideShort, manager.ExchangeSide(domain.PositionSideShort))
+	})
+
+	t.Run("orders already gone are canceled", func(t *testing.T) {
+		exchange := &mockExchange{orderErrors: map[string]error{
+			"cancel_5": ports.ErrOrderNotFound,
+			"cancel_6": errors.New("timeout"),
+		}}
+		manager, _ := newManager(exchange, &mockPositionRepo{}, nil)
+		assert.NoError(t, manager.CancelOrder(ctx, 5, "SL"))
+		assert.Error(t, manager.CancelOrder(ctx, 6, "TP"))
+	})
+
+	t.Run("loads the open positions", func(t *testing.T) {
+		long := &domain.Position{ID: 1, Symbol: "ETHUSDT", Side: domain.PositionSideLong, Status: domain.StatusOpen}
+		short := &domain.Position{ID: 2, Symbol: "ETHUSDT", Side: domain.PositionSideShort, Status: domain.StatusOpen}
+		repo := &mockPositionRepo{positions: map[string]*domain.Position{"ETHUSDT": long, "ETHUSDT/SHORT": short}}
+		manager, _ := newManager(&mockExchange{}, repo, nil)
+		require.NoError(t, manager.LoadOpen(ctx))
+		assert.Equal(t, []*domain.Position{long, short}, manager.OpenPositions())
+
+		repo.findOpenErr = errors.New("db down")
+		assert.Error(t, manager.LoadOpen(ctx))
+	})
+}
+
+// laggingExchange reports the position the fake exchange held before the close for the next stale
+// GetPositionRisks calls, like an exchange whose position endpoint lags behind its fills
+type laggingExchange struct {
+	*testharness.FakeExchange
+	snapshot []*ports.PositionRisk
+	stale    int
+}
+
+func (e *laggingExchange) GetPositionRisks(ctx context.Context, symbol string) ([]*ports.PositionRisk, error) {
+	if e.stale > 0 {
+		e.stale--
+		return e.snapshot, nil
+	}
+	return e.FakeExchange.GetPositionRisks(ctx, symbol)
+}
+
+func TestTradingService_ReduceOnlyRejected(t *testing.T) {
+	ctx := context.Background()
+	fake, err := testharness.New(testharness.Config{Symbol: "ETHUSDT", Klines: flatRecording(10, 2000), History: 4})
+	require.NoError(t, err)
+	_, err = fake.PlaceMarketOrder(ctx, "ETHUSDT", domain.Buy, domain.PositionSideBoth, "0.1", "")
+	require.NoError(t, err)
+	snapshot, err := fake.GetPositionRisks(ctx, "ETHUSDT")
+	require.NoError(t, err)
+
+	// The close fills in full, but the position still shows through every check, so the residual
+	// the service closes reduce-only is already gone when the order arrives
+	exchange := &laggingExchange{FakeExchange: fake, snapshot: snapshot, stale: 1 + emergencyVerifyAttempts}
+	logger := &mockLogger{}
+	service, err := NewTradingService(&config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5},
+		logger, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)}, &mockTradeRepo{}, &mockStrategy{})
+	require.NoError(t, err)
+	service.positions.verifyDelay = 0
+
+	require.NoError(t, service.positions.EmergencyClose(ctx, 2000, "0.1", domain.Buy, domain.PositionSideBoth))
+	assert.Equal(t, 1, fake.Stats().OrdersRejected, "Expected the reduce-only close of the stale residual to be rejected")
+	assert.Equal(t, testharness.Position{}, fake.Position(domain.PositionSideBoth))
+	assert.Contains(t, logger.warnMsgs, "emergencyClose: Reduce-only close of the residual rejected, checking the position again")
+	assert.Empty(t, logger.errorMsgs)
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+// defaultReconnectCheckInterval is how often the reconnect statistics are sampled when
+// ReconnectAlertConfig doesn't set an interval.
+const defaultReconnectCheckInterval = 15 * time.Second
+
+// ReconnectAlertConfig holds configuration for the WebSocket reconnect alerts.
+type ReconnectAlertConfig struct {
+	Threshold     int           // Reconnects within Window that trigger an alert
+	Window        time.Duration // Rolling window reconnects are counted in
+	CheckInterval time.Duration // How often the exchange client's statistics are sampled (0 uses 15s)
+}
+
+// reconnectSample is the exchange client's reconnect count at a point in time.
+type reconnectSample struct {
+	at         time.Time
+	reconnects int
+}
+
+// WithReconnectAlerts samples the exchange client's kline stream reconnect statistics (if it
+// implements ports.ReconnectStatsProvider), logs new reconnects, reports them in the status and
+// notifies the operator when Threshold or more reconnects happen within Window. The alert is
+// sent once per breach and the all-clear once the rate drops below the threshold again.
+func WithReconnectAlerts(cfg ReconnectAlertConfig) Option {
+	return func(s *TradingService) {
+		if cfg.CheckInterval <= 0 {
+			cfg.CheckInterval = defaultReconnectCheckInterval
+		}
+		s.reconnectAlerts = &cfg
+	}
+}
+
+// runReconnectMonitor samples the reconnect statistics every CheckInterval until ctx is canceled.
+func (s *TradingService) runReconnectMonitor(ctx context.Context, provider ports.ReconnectStatsProvider) {
+	ticker := time.NewTicker(s.reconnectAlerts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		s.mu.Lock()
+		s.recordReconnectStats(ctx, provider.ReconnectStats(), s.now())
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordReconnectStats adds a sample of the reconnect statistics, logs reconnects since the
+// previous sample and raises or clears the alert. Assumes the caller holds the lock.
+func (s *TradingService) recordReconnectStats(ctx context.Context, stats ports.ReconnectStats, now time.Time) {
+	previous := s.reconnectStats
+	s.reconnectStats = stats
+	s.reconnectSamples = append(s.reconnectSamples, reconnectSample{at: now, reconnects: stats.Reconnects})
+	// Keep the newest sample at or before the start of the window as the baseline
+	windowStart := now.Add(-s.reconnectAlerts.Window)
+	for len(s.reconnectSamples) > 1 && !s.reconnectSamples[1].at.After(windowStart) {
+		s.reconnectSamples = s.reconnectSamples[1:]
+	}
+	recent := s.recentReconnects()
+
+	if stats.Reconnects > previous.Reconnects {
+		s.logger.Info(ctx, "Kline stream reconnected", map[string]interface{}{
+			"symbol":              s.cfg.Symbol,
+			"new":                 stats.Reconnects - previous.Reconnects,
+			"reconnects":          stats.Reconnects,
+			"recentReconnects":    recent,
+			"window":              s.reconnectAlerts.Window.String(),
+			"downtime":            (time.Duration(stats.DowntimeMs) * time.Millisecond).String(),
+			"consecutiveFailures": stats.ConsecutiveFailures,
+		})
+	}
+
+	switch {
+	case !s.reconnectAlerting && recent >= s.reconnectAlerts.Threshold:
+		s.reconnectAlerting = true
+		s.logger.Warn(ctx, "Kline stream reconnecting too often", map[string]interface{}{
+			"symbol":           s.cfg.Symbol,
+			"recentReconnects": recent,
+			"threshold":        s.reconnectAlerts.Threshold,
+			"window":           s.reconnectAlerts.Window.String(),
+		})
+		s.notify(ctx, fmt.Sprintf("%s stream unstable: %d reconnects", s.cfg.Symbol, recent),
+			fmt.Sprintf("Symbol: %s\nReconnects in the last %s: %d (threshold %d)\nTotal reconnects: %d\nDowntime: %s\nConsecutive failed attempts: %d\nStreams disconnected: %d",
+				s.cfg.Symbol, s.reconnectAlerts.Window, recent, s.reconnectAlerts.Threshold, stats.Reconnects,
+				time.Duration(stats.DowntimeMs)*time.Millisecond, stats.ConsecutiveFailures, stats.Disconnected), nil)
+	case s.reconnectAlerting && recent < s.reconnectAlerts.Threshold:
+		s.reconnectAlerting = false
+		s.logger.Info(ctx, "Kline stream reconnect rate back below the threshold", map[string]interface{}{
+			"symbol":           s.cfg.Symbol,
+			"recentReconnects": recent,
+			"threshold":        s.reconnectAlerts.Threshold,
+		})
+		s.notify(ctx, fmt.Sprintf("%s stream stable again", s.cfg.Symbol),
+			fmt.Sprintf("Symbol: %s\nReconnects in the last %s: %d (threshold %d)",
+				s.cfg.Symbol, s.reconnectAlerts.Window, recent, s.reconnectAlerts.Threshold), nil)
+	}
+}
+
+// recentReconnects returns the reconnects since the baseline sample. Assumes the caller holds
+// the lock.
+func (s *TradingService) recentReconnects() int {
+	if len(s.reconnectSamples) == 0 {
+		return 0
+	}
+	return s.reconnectStats.Reconnects - s.reconnectSamples[0].reconnects
+}
+
+// reconnectStatus returns the reconnect statistics for the status, or nil if reconnect alerts
+// are disabled. Assumes the caller holds the lock.
+func (s *TradingService) reconnectStatus() *ports.ReconnectStatus {
+	if s.reconnectAlerts == nil {
+		return nil
+	}
+	return &ports.ReconnectStatus{
+		ReconnectStats:   s.reconnectStats,
+		RecentReconnects: s.recentReconnects(),
+		WindowMs:         s.reconnectAlerts.Window.Milliseconds(),
+		Threshold:        s.reconnectAlerts.Threshold,
+		Alerting:         s.reconnectAlerting,
+	}
+}
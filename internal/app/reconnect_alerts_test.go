@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_ReconnectAlerts(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	ctx := context.Background()
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+
+	t.Run("alerts once per breach and sends the all-clear", func(t *testing.T) {
+		notifier := &mockNotifier{}
+		service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+			WithReconnectAlerts(ReconnectAlertConfig{Threshold: 3, Window: 10 * time.Minute}), WithNotifier(notifier))
+		require.NoError(t, err)
+		assert.Equal(t, defaultReconnectCheckInterval, service.reconnectAlerts.CheckInterval)
+
+		// Reconnects before the first sample don't count towards the window
+		service.recordReconnectStats(ctx, ports.ReconnectStats{Reconnects: 4}, start)
+		assert.Equal(t, 0, service.recentReconnects())
+		assert.False(t, service.reconnectAlerting)
+
+		service.recordReconnectStats(ctx, ports.ReconnectStats{Reconnects: 6}, start.Add(2*time.Minute))
+		assert.False(t, service.reconnectAlerting)
+		service.recordReconnectStats(ctx, ports.ReconnectStats{Reconnects: 7, DowntimeMs: 4500}, start.Add(4*time.Minute))
+		assert.Equal(t, 3, service.recentReconnects())
+		assert.True(t, service.reconnectAlerting)
+		service.recordReconnectStats(ctx, ports.ReconnectStats{Reconnects: 8}, start.Add(6*time.Minute))
+
+		status := service.Status(ctx).Reconnects
+		require.NotNil(t, status)
+		assert.Equal(t, 8, status.Reconnects)
+		assert.Equal(t, 4, status.RecentReconnects)
+		assert.Equal(t, int64(600000), status.WindowMs)
+		assert.True(t, status.Alerting)
+
+		// The window has moved past the first three reconnects
+		service.recordReconnectStats(ctx, ports.ReconnectStats{Reconnects: 8}, start.Add(15*time.Minute))
+		assert.Equal(t, 1, service.recentReconnects())
+		assert.False(t, service.reconnectAlerting)
+
+		service.notifications.Wait()
+		assert.ElementsMatch(t, []string{"ETHUSDT stream unstable: 3 reconnects", "ETHUSDT stream stable again"}, notifier.subjects)
+		assert.Contains(t, strings.Join(notifier.messages, "\n"), "Downtime: 4.5s")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{})
+		require.NoError(t, err)
+		assert.Nil(t, service.Status(ctx).Reconnects)
+	})
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// WithReEntryPolicy refuses new entries until the cooldown of the last exit's close reason has
+// passed (e.g., 30 minutes after a stop loss). Rules requiring a fresh crossover are enforced by
+// strategies implementing ports.ExitAwareStrategy, which are told about every closed position.
+func WithReEntryPolicy(policy domain.ReEntryPolicy) Option {
+	return func(s *TradingService) {
+		s.gate.reEntry = policy
+	}
+}
+
+// restoreLastExit loads the most recently closed position, so the re-entry rules of an exit
+// before a restart still apply, and hands it to an exit-aware strategy. Failures are logged only.
+func (s *TradingService) restoreLastExit(ctx context.Context) {
+	closed, err := s.tradeRepo.FindClosedBySymbol(ctx, s.cfg.Symbol, 1)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to load the last closed position for re-entry rules", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if len(closed) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordExit(ctx, closed[0])
+}
+
+// recordExit hands pos to the entry gate as the last exit and tells an exit-aware strategy about it.
+// Assumes the caller holds the lock.
+func (s *TradingService) recordExit(ctx context.Context, pos *domain.Position) {
+	s.gate.RecordExit(pos)
+	if aware, ok := s.signals.Strategy().(ports.ExitAwareStrategy); ok {
+		aware.PositionClosed(ctx, pos)
+	}
+}
+
+// reEntryCooldown reports whether the last exit's re-entry cooldown still blocks entries at now.
+func (g *EntryGate) reEntryCooldown(now time.Time) (bool, string) {
+	if g.lastExitTime.IsZero() {
+		return false, ""
+	}
+	remaining := g.reEntry.CooldownRemaining(g.lastExitReason, g.lastExitTime, now)
+	if remaining <= 0 {
+		return false, ""
+	}
+	return true, fmt.Sprintf("re-entry cooldown after %s exit (%s left)", g.lastExitReason, remaining.Round(time.Second))
+}
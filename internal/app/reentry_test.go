@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+)
+
+// exitAwareStrategy records the positions it is told about
+type exitAwareStrategy struct {
+	mockStrategy
+	closed []*domain.Position
+}
+
+func (m *exitAwareStrategy) PositionClosed(ctx context.Context, position *domain.Position) {
+	m.closed = append(m.closed, position)
+}
+
+func TestTradingService_ReEntryCooldown(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  1,
+		StopLoss:  0.02,
+		MaxProfit: 0.05,
+		MaxOrders: 5,
+	}
+	policy, err := domain.ParseReEntryPolicy("TP:0,SL:30m,TREND_REVERSAL:0:crossover")
+	require.NoError(t, err)
+
+	newService := func(t *testing.T, lastExit *domain.Position) (*TradingService, *exitAwareStrategy) {
+		tradeRepo := &mockTradeRepo{}
+		if lastExit != nil {
+			tradeRepo.trades = []*domain.Position{lastExit}
+		}
+		strategy := &exitAwareStrategy{}
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, posRepo, tradeRepo, strategy,
+			WithReEntryPolicy(policy))
+		require.NoError(t, err)
+		service.restoreLastExit(context.Background())
+		return service, strategy
+	}
+
+	t.Run("recent stop loss blocks entries", func(t *testing.T) {
+		exit := &domain.Position{ID: 1, CloseReason: domain.CloseReasonStopLoss, ExitTime: time.Now().Add(-10 * time.Minute)}
+		service, strategy := newService(t, exit)
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Contains(t, reason, "re-entry cooldown after SL exit")
+		require.Len(t, strategy.closed, 1, "restored exit is handed to the strategy")
+		assert.Equal(t, int64(1), strategy.closed[0].ID)
+	})
+
+	t.Run("stop loss cooldown expires", func(t *testing.T) {
+		exit := &domain.Position{ID: 1, CloseReason: domain.CloseReasonStopLoss, ExitTime: time.Now().Add(-31 * time.Minute)}
+		service, _ := newService(t, exit)
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+	})
+
+	t.Run("take profit allows immediate re-entry", func(t *testing.T) {
+		exit := &domain.Position{ID: 1, CloseReason: domain.CloseReasonTakeProfit, ExitTime: time.Now()}
+		service, _ := newService(t, exit)
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+	})
+
+	t.Run("recorded exit replaces the restored one", func(t *testing.T) {
+		service, strategy := newService(t, nil)
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok, "no exit yet")
+
+		service.mu.Lock()
+		service.recordExit(context.Background(), &domain.Position{ID: 2, CloseReason: domain.CloseReasonStopLoss, ExitTime: time.Now()})
+		service.mu.Unlock()
+		ok, _ = service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Len(t, strategy.closed, 1)
+	})
+}
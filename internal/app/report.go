@@ -0,0 +1,230 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// DailyReportConfig holds configuration for the daily summary report.
+type DailyReportConfig struct {
+	Symbol       string        // Symbol whose trades are summarized
+	BalanceAsset string        // Asset whose balance is reported (defaults to USDT)
+	At           time.Duration // Time of day (offset from UTC midnight) at which the report is sent
+	FeeRate      float64       // Fee rate per side used to estimate the fees of positions without recorded fees (e.g., 0.0004 for 0.04%)
+
+	// Converter (optional) also reports PnL and balance in another currency. It must convert
+	// from BalanceAsset.
+	Converter *CurrencyConverter
+}
+
+// DailyReporter compiles a summary of the last 24 hours of trading once a day,
+// stores it and sends it through the notifier.
+type DailyReporter struct {
+	cfg        DailyReportConfig
+	logger     ports.Logger
+	exchange   ports.ExchangeClient
+	tradeRepo  ports.TradeRepository
+	reportRepo ports.DailyReportRepository
+	notifier   ports.Notifier   // Optional: reports are only stored when nil
+	now        func() time.Time // Overridable for tests
+}
+
+// NewDailyReporter creates a new daily report scheduler.
+func NewDailyReporter(
+	cfg DailyReportConfig,
+	logger ports.Logger,
+	exchange ports.ExchangeClient,
+	tradeRepo ports.TradeRepository,
+	reportRepo ports.DailyReportRepository,
+	notifier ports.Notifier,
+) (*DailyReporter, error) {
+	if logger == nil || exchange == nil || tradeRepo == nil || reportRepo == nil {
+		return nil, fmt.Errorf("missing required dependencies for DailyReporter")
+	}
+	if cfg.Symbol == "" {
+		return nil, fmt.Errorf("daily report symbol must be set")
+	}
+	if cfg.At < 0 || cfg.At >= 24*time.Hour {
+		return nil, fmt.Errorf("daily report time must be within a day, got %s", cfg.At)
+	}
+	if cfg.FeeRate < 0 {
+		return nil, fmt.Errorf("daily report fee rate cannot be negative")
+	}
+	if cfg.BalanceAsset == "" {
+		cfg.BalanceAsset = "USDT"
+	}
+	if cfg.Converter != nil && cfg.Converter.From() != cfg.BalanceAsset {
+		return nil, fmt.Errorf("daily report converter must convert from %s, got %s", cfg.BalanceAsset, cfg.Converter.From())
+	}
+
+	return &DailyReporter{
+		cfg:        cfg,
+		logger:     logger,
+		exchange:   exchange,
+		tradeRepo:  tradeRepo,
+		reportRepo: reportRepo,
+		notifier:   notifier,
+		now:        time.Now,
+	}, nil
+}
+
+// Run sends a report at the configured time every day until ctx is canceled.
+func (r *DailyReporter) Run(ctx context.Context) {
+	for {
+		next := r.nextRun(r.now())
+		r.logger.Debug(ctx, "Next daily report scheduled", map[string]interface{}{"at": next})
+
+		timer := time.NewTimer(next.Sub(r.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := r.Send(ctx, next); err != nil {
+			r.logger.Error(ctx, err, "Failed to send daily report", map[string]interface{}{"symbol": r.cfg.Symbol})
+		}
+	}
+}
+
+// nextRun returns the first scheduled report time strictly after now.
+func (r *DailyReporter) nextRun(now time.Time) time.Time {
+	now = now.UTC()
+	next := now.Truncate(24 * time.Hour).Add(r.cfg.At)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
+
+// Send compiles the report for the 24 hours ending at end, stores it and sends it through
+// the notifier. Storing and notifying are attempted independently; their errors are joined.
+func (r *DailyReporter) Send(ctx context.Context, end time.Time) (*domain.DailyReport, error) {
+	report, err := r.Compile(ctx, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	if err := r.reportRepo.SaveDailyReport(ctx, report); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save daily report: %w", err))
+	}
+	if r.notifier != nil {
+		if err := r.notifier.Notify(ctx, dailyReportSubject(report), r.format(ctx, report)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send daily report notification: %w", err))
+		}
+	}
+
+	r.logger.Info(ctx, "Daily report compiled", map[string]interface{}{
+		"symbol":  report.Symbol,
+		"date":    report.Date.Format("2006-01-02"),
+		"trades":  report.Trades,
+		"netPnL":  report.NetPnL,
+		"balance": report.Balance,
+	})
+	return report, errors.Join(errs...)
+}
+
+// Compile summarizes the positions closed in the 24 hours ending at end. The report is dated
+// with the UTC day of its last instant, so a report sent at 00:00 covers the previous day.
+func (r *DailyReporter) Compile(ctx context.Context, end time.Time) (*domain.DailyReport, error) {
+	end = end.UTC()
+	start := end.Add(-24 * time.Hour)
+
+	positions, err := r.tradeRepo.FindClosedBetween(ctx, r.cfg.Symbol, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load closed positions for daily report: %w", err)
+	}
+
+	report := &domain.DailyReport{
+		Date:        end.Add(-time.Nanosecond).Truncate(24 * time.Hour),
+		Symbol:      r.cfg.Symbol,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		CreatedAt:   r.now().UTC(),
+	}
+	for _, pos := range positions {
+		report.Trades++
+		if pos.PNL > 0 {
+			report.Wins++
+		} else {
+			report.Losses++
+		}
+		// PNL is stored net of the position's recorded fees; positions closed without them get
+		// an estimate instead, so fees are deducted exactly once
+		fees := pos.Fees
+		if fees == 0 {
+			fees = (pos.EntryPrice + pos.ExitPrice) * pos.Quantity * r.cfg.FeeRate
+		}
+		report.GrossPnL += pos.PNL + pos.Fees
+		report.Fees += fees
+	}
+	if report.Trades > 0 {
+		report.WinRate = float64(report.Wins) / float64(report.Trades)
+	}
+	report.NetPnL = report.GrossPnL - report.Fees
+
+	// A missing balance shouldn't prevent the rest of the report from going out
+	report.Balance, err = r.exchange.GetAccountBalance(ctx, r.cfg.BalanceAsset)
+	if err != nil {
+		r.logger.Warn(ctx, "Failed to get account balance for daily report", map[string]interface{}{
+			"asset": r.cfg.BalanceAsset,
+			"error": err.Error(),
+		})
+	}
+	return report, nil
+}
+
+// format renders the report's notification, with the amounts converted to the reporting currency
+// if a converter is set. Without a rate the report goes out in the balance asset only.
+func (r *DailyReporter) format(ctx context.Context, report *domain.DailyReport) string {
+	message := FormatDailyReport(report, r.cfg.BalanceAsset)
+	if r.cfg.Converter == nil {
+		return message
+	}
+	rate, err := r.cfg.Converter.Rate(ctx)
+	if err != nil {
+		r.logger.Warn(ctx, "Failed to get currency conversion rate for daily report", map[string]interface{}{
+			"currency": r.cfg.Converter.Currency(),
+			"error":    err.Error(),
+		})
+		return message
+	}
+	return message + "\n\n" + FormatDailyReportConversion(report, r.cfg.BalanceAsset, r.cfg.Converter.Currency(), rate)
+}
+
+// dailyReportSubject returns the notification subject line for a report.
+func dailyReportSubject(report *domain.DailyReport) string {
+	return fmt.Sprintf("Daily report %s %s", report.Symbol, report.Date.Format("2006-01-02"))
+}
+
+// FormatDailyReport renders a report as a human-readable plain-text summary.
+func FormatDailyReport(report *domain.DailyReport, asset string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Period: %s - %s UTC\n", report.PeriodStart.Format("2006-01-02 15:04"), report.PeriodEnd.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "Trades: %d (%d wins / %d losses)\n", report.Trades, report.Wins, report.Losses)
+	fmt.Fprintf(&b, "Win rate: %.1f%%\n", report.WinRate*100)
+	fmt.Fprintf(&b, "Gross PnL: %.2f %s\n", report.GrossPnL, asset)
+	fmt.Fprintf(&b, "Fees: %.2f %s\n", report.Fees, asset)
+	fmt.Fprintf(&b, "Net PnL: %.2f %s\n", report.NetPnL, asset)
+	fmt.Fprintf(&b, "Balance: %.2f %s", report.Balance, asset)
+	return b.String()
+}
+
+// FormatDailyReportConversion renders a report's PnL and balance in currency, at rate units of
+// currency per unit of asset.
+func FormatDailyReportConversion(report *domain.DailyReport, asset, currency string, rate float64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "In %s (1 %s = %.4f %s):\n", currency, asset, rate, currency)
+	fmt.Fprintf(&b, "Gross PnL: %.2f %s\n", report.GrossPnL*rate, currency)
+	fmt.Fprintf(&b, "Net PnL: %.2f %s\n", report.NetPnL*rate, currency)
+	fmt.Fprintf(&b, "Balance: %.2f %s", report.Balance*rate, currency)
+	return b.String()
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+type mockReportRepo struct {
+	saved   []*domain.DailyReport
+	saveErr error
+}
+
+func (m *mockReportRepo) SaveDailyReport(ctx context.Context, report *domain.DailyReport) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.saved = append(m.saved, report)
+	return nil
+}
+
+func (m *mockReportRepo) FindDailyReport(ctx context.Context, symbol string, date time.Time) (*domain.DailyReport, error) {
+	for _, report := range m.saved {
+		if report.Symbol == symbol && report.Date.Equal(date) {
+			return report, nil
+		}
+	}
+	return nil, nil
+}
+
+type mockNotifier struct {
+	mu        sync.Mutex // The trading service notifies from background goroutines
+	subjects  []string
+	messages  []string
+	notifyErr error
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, subject, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.notifyErr != nil {
+		return m.notifyErr
+	}
+	m.subjects = append(m.subjects, subject)
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func TestNewDailyReporter(t *testing.T) {
+	cfg := DailyReportConfig{Symbol: "ETHUSDT", At: 23 * time.Hour}
+	_, err := NewDailyReporter(cfg, &mockLogger{}, &mockExchange{}, &mockTradeRepo{}, &mockReportRepo{}, nil)
+	require.NoError(t, err, "notifier is optional")
+
+	_, err = NewDailyReporter(cfg, &mockLogger{}, &mockExchange{}, &mockTradeRepo{}, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT", At: 24 * time.Hour}, &mockLogger{}, &mockExchange{}, &mockTradeRepo{}, &mockReportRepo{}, nil)
+	assert.Error(t, err)
+
+	_, err = NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT", FeeRate: -0.1}, &mockLogger{}, &mockExchange{}, &mockTradeRepo{}, &mockReportRepo{}, nil)
+	assert.Error(t, err)
+}
+
+func TestDailyReporter_nextRun(t *testing.T) {
+	r, err := NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT", At: 20*time.Hour + 30*time.Minute},
+		&mockLogger{}, &mockExchange{}, &mockTradeRepo{}, &mockReportRepo{}, nil)
+	require.NoError(t, err)
+
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, day.Add(20*time.Hour+30*time.Minute), r.nextRun(day.Add(8*time.Hour)))
+	assert.Equal(t, day.Add(44*time.Hour+30*time.Minute), r.nextRun(day.Add(20*time.Hour+30*time.Minute)), "exactly at the scheduled time runs tomorrow")
+	assert.Equal(t, day.Add(20*time.Hour+30*time.Minute), r.nextRun(day.Add(time.Hour).In(time.FixedZone("UTC+5", 5*60*60))), "schedule is in UTC")
+}
+
+func TestDailyReporter_Send(t *testing.T) {
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	tradeRepo := &mockTradeRepo{trades: []*domain.Position{
+		{Symbol: "ETHUSDT", EntryPrice: 2000, ExitPrice: 2100, Quantity: 0.1, PNL: 10, ExitTime: day.Add(-time.Hour)},                  // Previous window
+		{Symbol: "ETHUSDT", EntryPrice: 2000, ExitPrice: 2100, Quantity: 0.1, PNL: 9.59, Fees: 0.41, ExitTime: day.Add(2 * time.Hour)}, // Net of recorded fees
+		{Symbol: "ETHUSDT", EntryPrice: 2000, ExitPrice: 1950, Quantity: 0.1, PNL: -5, ExitTime: day.Add(5 * time.Hour)},
+		{Symbol: "ETHUSDT", EntryPrice: 2000, ExitPrice: 2025, Quantity: 0.2, PNL: 10, ExitTime: day.Add(23 * time.Hour)},
+	}}
+	reportRepo := &mockReportRepo{}
+	notifier := &mockNotifier{}
+	exchange := &mockExchange{balance: 1015}
+
+	r, err := NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT", FeeRate: 0.001},
+		&mockLogger{}, exchange, tradeRepo, reportRepo, notifier)
+	require.NoError(t, err)
+
+	// A report sent at midnight covers the previous day
+	report, err := r.Send(context.Background(), day.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, day, report.Date)
+	assert.Equal(t, 3, report.Trades)
+	assert.Equal(t, 2, report.Wins)
+	assert.Equal(t, 1, report.Losses)
+	assert.InDelta(t, 2.0/3.0, report.WinRate, 1e-9)
+	assert.InDelta(t, 15.0, report.GrossPnL, 1e-9)
+	assert.InDelta(t, 1.61, report.Fees, 1e-9) // 0.41 recorded + (395 + 805) * 0.001 estimated
+	assert.InDelta(t, 13.39, report.NetPnL, 1e-9)
+	assert.Equal(t, 1015.0, report.Balance)
+
+	require.Len(t, reportRepo.saved, 1)
+	require.Len(t, notifier.subjects, 1)
+	assert.Equal(t, "Daily report ETHUSDT 2025-05-01", notifier.subjects[0])
+	assert.Contains(t, notifier.messages[0], "Trades: 3 (2 wins / 1 losses)")
+	assert.Contains(t, notifier.messages[0], "Net PnL: 13.39 USDT")
+}
+
+func TestDailyReporter_SendErrors(t *testing.T) {
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// Failing to load trades aborts the report
+	r, err := NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT"}, &mockLogger{}, &mockExchange{},
+		&mockTradeRepo{findClosedErr: ports.ErrQueryFailed}, &mockReportRepo{}, &mockNotifier{})
+	require.NoError(t, err)
+	_, err = r.Send(context.Background(), day)
+	require.ErrorIs(t, err, ports.ErrQueryFailed)
+
+	// A failed save still notifies; a missing balance is tolerated
+	notifier := &mockNotifier{}
+	logger := &mockLogger{}
+	r, err = NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT"}, logger, &mockExchange{balanceErr: errors.New("boom")},
+		&mockTradeRepo{}, &mockReportRepo{saveErr: ports.ErrUpdateFailed}, notifier)
+	require.NoError(t, err)
+	report, err := r.Send(context.Background(), day)
+	require.ErrorIs(t, err, ports.ErrUpdateFailed)
+	require.NotNil(t, report)
+	assert.Len(t, notifier.subjects, 1)
+	assert.Contains(t, logger.warnMsgs, "Failed to get account balance for daily report")
+
+	// Notification failures are reported after the report is saved
+	reportRepo := &mockReportRepo{}
+	r, err = NewDailyReporter(DailyReportConfig{Symbol: "ETHUSDT"}, &mockLogger{}, &mockExchange{},
+		&mockTradeRepo{}, reportRepo, &mockNotifier{notifyErr: ports.ErrNotificationFailed})
+	require.NoError(t, err)
+	_, err = r.Send(context.Background(), day)
+	require.ErrorIs(t, err, ports.ErrNotificationFailed)
+	assert.Len(t, reportRepo.saved, 1)
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// SafeModeConfig holds configuration for exchange maintenance and outage detection.
+type SafeModeConfig struct {
+	CheckInterval    time.Duration         // How often the exchange is pinged
+	FailureThreshold int                   // Consecutive failed pings or outage errors that enter safe mode
+	RecoveryChecks   int                   // Consecutive successful pings that end safe mode (0 means 1)
+	Action           domain.SafeModeAction // What happens to open positions on entering safe mode
+	TightenStop      float64               // Stop distance from the price for SafeModeTighten (e.g., 0.005 for 0.5%)
+}
+
+// WithSafeMode pings the exchange every CheckInterval and enters safe mode when it reports
+// maintenance, or when pings or order requests fail with connectivity errors FailureThreshold
+// times in a row. Safe mode refuses new entries, applies the configured action to open positions,
+// records the event in repo (optional) and notifies the operator. It ends automatically after
+// RecoveryChecks successful pings in a row.
+func WithSafeMode(cfg SafeModeConfig, repo ports.SafeModeRepository) Option {
+	return func(s *TradingService) {
+		if cfg.RecoveryChecks <= 0 {
+			cfg.RecoveryChecks = 1
+		}
+		s.safeMode = &cfg
+		s.safeModeRepo = repo
+	}
+}
+
+// isExchangeOutage reports whether err means the exchange is under maintenance or unreachable,
+// rather than rejecting a particular request.
+func isExchangeOutage(err error) bool {
+	return errors.Is(err, ports.ErrExchangeMaintenance) || errors.Is(err, ports.ErrExchangeUnavailable) ||
+		errors.Is(err, ports.ErrConnectionFailed) || errors.Is(err, ports.ErrTimeout)
+}
+
+// restoreSafeMode resumes safe mode if the previous run stopped during an outage; the health
+// checks end it once the exchange responds again. Failures are logged only.
+func (s *TradingService) restoreSafeMode(ctx context.Context) {
+	if s.safeMode == nil || s.safeModeRepo == nil {
+		return
+	}
+	event, err := s.safeModeRepo.FindActiveSafeModeEvent(ctx, s.cfg.Symbol)
+	if err != nil {
+		s.logger.Error(ctx, err, "Failed to load active safe mode event")
+		return
+	}
+	if event == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.safeModeEvent = event
+	s.logger.Warn(ctx, "Resuming in safe mode after restart", map[string]interface{}{
+		"symbol": s.cfg.Symbol,
+		"reason": event.Reason,
+		"since":  event.StartedAt,
+	})
+}
+
+// safeModeBlocksEntries reports whether entries are refused because the exchange is in safe mode
+// (an entry blocker of the gate). Assumes the caller holds the lock.
+func (s *TradingService) safeModeBlocksEntries() (bool, string) {
+	if s.safeModeEvent != nil {
+		return true, "safe mode: " + s.safeModeEvent.Reason
+	}
+	return false, ""
+}
+
+// runSafeModeMonitor pings the exchange every CheckInterval until ctx is canceled.
+func (s *TradingService) runSafeModeMonitor(ctx context.Context) {
+	ticker := time.NewTicker(s.safeMode.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := s.exchange.Ping(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		s.mu.Lock()
+		s.recordHealthCheck(ctx, err, s.now())
+		s.mu.Unlock()
+	}
+}
+
+// recordHealthCheck updates the failure and recovery counters with the outcome of a health
+// check (err is nil when the exchange responded) and enters or ends safe mode. A maintenance
+// error enters safe mode right away. Assumes the caller holds the lock.
+func (s *TradingService) recordHealthCheck(ctx context.Context, err error, now time.Time) {
+	if err != nil {
+		s.healthyChecks = 0
+		s.exchangeFailures++
+		if s.safeModeEvent != nil {
+			return
+		}
+		if errors.Is(err, ports.ErrExchangeMaintenance) || s.exchangeFailures >= s.safeMode.FailureThreshold {
+			s.enterSafeMode(ctx, err, now)
+		}
+		return
+	}
+
+	s.exchangeFailures = 0
+	if s.safeModeEvent == nil {
+		return
+	}
+	s.healthyChecks++
+	if s.safeMode.Action == domain.SafeModeClose {
+		// Retry closes that failed while the exchange was down before resuming
+		s.applySafeModeAction(ctx)
+	}
+	if s.healthyChecks >= s.safeMode.RecoveryChecks {
+		s.endSafeMode(ctx, now)
+	}
+}
+
+// enterSafeMode stops new entries, records and announces the event and applies the configured
+// action to open positions. Assumes the caller holds the lock.
+func (s *TradingService) enterSafeMode(ctx context.Context, cause error, now time.Time) {
+	event := &domain.SafeModeEvent{Symbol: s.cfg.Symbol, Reason: cause.Error(), StartedAt: now.UTC()}
+	if s.safeModeRepo != nil {
+		if err := s.safeModeRepo.SaveSafeModeEvent(ctx, event); err != nil {
+			s.logger.Error(ctx, err, "Failed to save safe mode event")
+		}
+	}
+	s.safeModeEvent = event
+	s.healthyChecks = 0
+
+	s.logger.Warn(ctx, "Exchange unavailable, entering safe mode", map[string]interface{}{
+		"symbol":   s.cfg.Symbol,
+		"reason":   event.Reason,
+		"failures": s.exchangeFailures,
+		"action":   s.safeMode.Action,
+	})
+	s.notify(ctx, fmt.Sprintf("%s safe mode: exchange unavailable", s.cfg.Symbol),
+		fmt.Sprintf("Symbol: %s\nReason: %s\nOpen positions: %d (action: %s)\nNew entries are paused until the exchange is healthy again",
+			s.cfg.Symbol, event.Reason, len(s.positions.OpenPositions()), s.safeMode.Action), nil)
+	s.applySafeModeAction(ctx)
+}
+
+// endSafeMode resumes entries and records and announces the end of the event.
+// Assumes the caller holds the lock.
+func (s *TradingService) endSafeMode(ctx context.Context, now time.Time) {
+	event := s.safeModeEvent
+	s.safeModeEvent = nil
+	s.healthyChecks = 0
+	if s.safeModeRepo != nil && event.ID != 0 {
+		if err := s.safeModeRepo.EndSafeModeEvent(ctx, event.ID, now); err != nil {
+			s.logger.Error(ctx, err, "Failed to save end of safe mode event", map[string]interface{}{"eventID": event.ID})
+		}
+	}
+	duration := now.Sub(event.StartedAt).Round(time.Second)
+	s.logger.Info(ctx, "Exchange healthy again, leaving safe mode", map[string]interface{}{
+		"symbol":   s.cfg.Symbol,
+		"duration": duration.String(),
+	})
+	s.notify(ctx, fmt.Sprintf("%s safe mode ended", s.cfg.Symbol),
+		fmt.Sprintf("Symbol: %s\nReason: %s\nDuration: %s\nNew entries are allowed again", s.cfg.Symbol, event.Reason, duration), nil)
+}
+
+// applySafeModeAction tightens the stops of open positions or market-closes them, as configured.
+// Failed closes are retried by later healthy checks while safe mode lasts; the SL/TP orders on
+// the exchange stay in place as the backstop. Assumes the caller holds the lock.
+func (s *TradingService) applySafeModeAction(ctx context.Context) {
+	price, _ := s.signals.LastPrice()
+	for _, pos := range s.positions.OpenPositions() {
+		switch s.safeMode.Action {
+		case domain.SafeModeTighten:
+			stop, ok := tightenedStop(pos, price, s.safeMode.TightenStop)
+			if !ok {
+				continue
+			}
+			s.logger.Info(ctx, "Tightening stop loss in safe mode", map[string]interface{}{
+				"positionID": pos.ID,
+				"side":       pos.PositionSide(),
+				"oldStop":    pos.StopLoss,
+				"newStop":    stop,
+			})
+			pos.StopLoss = stop
+			if err := s.positions.Save(ctx, pos); err != nil {
+				s.logger.Error(ctx, err, "Failed to save tightened stop loss", map[string]interface{}{"positionID": pos.ID})
+			}
+		case domain.SafeModeClose:
+			if err := s.closePosition(ctx, pos, price, domain.CloseReasonSafeMode); err != nil {
+				s.logger.Error(ctx, err, "Failed to close position in safe mode, will retry", map[string]interface{}{"positionID": pos.ID})
+			}
+		}
+	}
+}
+
+// tightenedStop returns a stop no further than distance from price. Stops are only ever moved
+// closer to the price; ok is false when the stop stays.
+func tightenedStop(pos *domain.Position, price, distance float64) (float64, bool) {
+	if price <= 0 || distance <= 0 {
+		return pos.StopLoss, false
+	}
+	if pos.IsShort() {
+		stop := price * (1 + distance)
+		if pos.StopLoss > 0 && stop >= pos.StopLoss {
+			return pos.StopLoss, false
+		}
+		return stop, true
+	}
+	stop := price * (1 - distance)
+	if stop <= pos.StopLoss {
+		return pos.StopLoss, false
+	}
+	return stop, true
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+type mockSafeModeRepo struct {
+	events []*domain.SafeModeEvent
+}
+
+func (m *mockSafeModeRepo) SaveSafeModeEvent(ctx context.Context, event *domain.SafeModeEvent) error {
+	event.ID = int64(len(m.events) + 1)
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockSafeModeRepo) EndSafeModeEvent(ctx context.Context, id int64, endedAt time.Time) error {
+	for _, event := range m.events {
+		if event.ID == id {
+			event.EndedAt = endedAt
+			return nil
+		}
+	}
+	return ports.ErrNotFound
+}
+
+func (m *mockSafeModeRepo) FindActiveSafeModeEvent(ctx context.Context, symbol string) (*domain.SafeModeEvent, error) {
+	for _, event := range m.events {
+		if event.Symbol == symbol && event.Active() {
+			return event, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestTradingService_SafeMode(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 0.1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	now := time.Now()
+	newService := func(t *testing.T, action domain.SafeModeAction, exchange *mockExchange) (*TradingService, *mockSafeModeRepo, *mockPositionRepo) {
+		repo := &mockSafeModeRepo{}
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{},
+			WithSafeMode(SafeModeConfig{CheckInterval: time.Second, FailureThreshold: 3, RecoveryChecks: 2, Action: action, TightenStop: 0.01}, repo))
+		require.NoError(t, err)
+		service.signals.klines = []*domain.Kline{{Symbol: "ETHUSDT", Close: 2000}}
+		return service, repo, posRepo
+	}
+
+	t.Run("maintenance enters safe mode at once and recovery resumes entries", func(t *testing.T) {
+		service, repo, _ := newService(t, domain.SafeModeKeep, &mockExchange{})
+		service.recordHealthCheck(ctx, fmt.Errorf("ping: %w", ports.ErrExchangeMaintenance), now)
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Contains(t, reason, "safe mode: ping: exchange is under maintenance")
+		require.Len(t, repo.events, 1)
+		assert.True(t, repo.events[0].Active())
+
+		service.recordHealthCheck(ctx, nil, now.Add(time.Minute))
+		ok, _ = service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok, "one healthy check is not enough")
+
+		service.recordHealthCheck(ctx, nil, now.Add(2*time.Minute))
+		ok, _ = service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		assert.Equal(t, now.Add(2*time.Minute), repo.events[0].EndedAt)
+	})
+
+	t.Run("connectivity failures enter safe mode at the threshold", func(t *testing.T) {
+		service, repo, _ := newService(t, domain.SafeModeKeep, &mockExchange{})
+		service.observeExchangeError(ctx, ports.ErrInsufficientFunds) // Not an outage
+		service.observeExchangeError(ctx, ports.ErrTimeout)
+		service.observeExchangeError(ctx, ports.ErrExchangeUnavailable)
+		assert.Nil(t, service.safeModeEvent)
+		assert.Empty(t, repo.events)
+
+		service.observeExchangeError(ctx, ports.ErrConnectionFailed)
+		require.NotNil(t, service.safeModeEvent)
+		assert.Len(t, repo.events, 1)
+	})
+
+	t.Run("a healthy check resets the failure count", func(t *testing.T) {
+		service, _, _ := newService(t, domain.SafeModeKeep, &mockExchange{})
+		service.recordHealthCheck(ctx, ports.ErrTimeout, now)
+		service.recordHealthCheck(ctx, ports.ErrTimeout, now)
+		service.recordHealthCheck(ctx, nil, now)
+		service.recordHealthCheck(ctx, ports.ErrTimeout, now)
+		assert.Nil(t, service.safeModeEvent)
+	})
+
+	t.Run("tighten action pulls stops towards the last price", func(t *testing.T) {
+		service, _, posRepo := newService(t, domain.SafeModeTighten, &mockExchange{})
+		long := &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 1950, StopLoss: 1900, Status: domain.StatusOpen}
+		short := &domain.Position{ID: 2, Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2010, StopLoss: 2015, Status: domain.StatusOpen}
+		service.positions.long, service.positions.short = long, short
+		service.recordHealthCheck(ctx, ports.ErrExchangeMaintenance, now)
+		assert.InDelta(t, 1980, long.StopLoss, 1e-9)
+		assert.InDelta(t, 2015, short.StopLoss, 1e-9, "a stop closer than the distance stays")
+		assert.Same(t, long, posRepo.positions["ETHUSDT"])
+	})
+
+	t.Run("close action closes open positions", func(t *testing.T) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{"market_SELL": {OrderID: 4, AvgPrice: 1995}}}
+		service, _, _ := newService(t, domain.SafeModeClose, exchange)
+		service.positions.long = &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 1950, Quantity: 0.1, StopLoss: 1900, Status: domain.StatusOpen}
+		service.recordHealthCheck(ctx, ports.ErrExchangeMaintenance, now)
+		assert.Nil(t, service.positions.long)
+		assert.Equal(t, domain.CloseReasonSafeMode, service.gate.lastExitReason)
+	})
+
+	t.Run("restart resumes an active event", func(t *testing.T) {
+		service, repo, _ := newService(t, domain.SafeModeKeep, &mockExchange{})
+		repo.events = []*domain.SafeModeEvent{{ID: 5, Symbol: "ETHUSDT", Reason: "exchange is under maintenance", StartedAt: now}}
+		service.restoreSafeMode(ctx)
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "safe mode: exchange is under maintenance", reason)
+	})
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// WithScaleIn enters positions in parts: the entry signal opens the plan's initial share of the
+// configured quantity and the rest is added each time the price improves by the plan's next step,
+// up to the configured quantity. The plan must be validated.
+func WithScaleIn(plan domain.ScaleInPlan) Option {
+	return func(s *TradingService) {
+		s.sizer.scaleIn = plan
+	}
+}
+
+// addScaleIns adds to each open position whose price reached its next scale-in level. Adds are
+// skipped while entries are paused, like new positions.
+// Assumes the caller holds the lock.
+func (s *TradingService) addScaleIns(ctx context.Context, price float64) {
+	if !s.sizer.ScaleIn().Enabled() {
+		return
+	}
+	for _, pos := range s.positions.OpenPositions() {
+		if !s.sizer.ScaleIn().AddDue(pos, price) {
+			continue
+		}
+		if paused, reason := s.gate.Paused(); paused {
+			s.logger.Debug(ctx, "Scale-in add skipped", map[string]interface{}{"positionID": pos.ID, "reason": reason})
+			continue
+		}
+		if err := s.addToPosition(ctx, pos, price); errors.Is(err, errDailyVolumeCap) {
+			s.logger.Debug(ctx, "Scale-in add skipped", map[string]interface{}{"positionID": pos.ID, "reason": err.Error()})
+		} else if err != nil {
+			s.logger.Error(ctx, err, "Failed to scale in", map[string]interface{}{"positionID": pos.ID})
+			s.resyncOnClockSkew(err)
+			s.observeExchangeError(ctx, err)
+		}
+	}
+}
+
+// addToPosition buys (or for a short, sells) the next scale-in add at market and resizes the
+// position's SL/TP orders to its new quantity, keeping their price levels. If the resized stop loss
+// can't be placed the add is closed again, since it would be unprotected.
+func (s *TradingService) addToPosition(ctx context.Context, pos *domain.Position, price float64) error {
+	op := "addToPosition"
+	positionSide := pos.PositionSide()
+	exchangeSide := s.positions.ExchangeSide(positionSide)
+
+	quantity, leverage, err := s.sizer.AddSize(ctx, op, pos, price)
+	if err != nil {
+		return err
+	}
+	quantityStr := s.formatQuantity(quantity)
+	s.logger.Info(ctx, op+": Price reached the next scale-in level", map[string]interface{}{
+		"positionID": pos.ID,
+		"side":       positionSide,
+		"add":        pos.ScaleIns + 1,
+		"quantity":   quantityStr,
+		"price":      price,
+	})
+
+	order, err := s.positions.PlaceMarketOrder(ctx, positionSide, domain.OrderPurposeScaleIn, pos.ID, quantityStr, "")
+	if err != nil {
+		return fmt.Errorf("scale-in market order failed: %w", err)
+	}
+	addFills := s.orderFills(ctx, order, domain.FillRoleScaleIn)
+	fillPrice := averageFillPrice(addFills, order.AvgPrice)
+	if fillPrice == 0 {
+		fillPrice = price
+	}
+	s.sizer.RecordVolume(ctx, quantity, fillPrice)
+
+	entryPrice, previousQuantity, scaleIns, previousLeverage := pos.EntryPrice, pos.Quantity, pos.ScaleIns, pos.Leverage
+	pos.Leverage = leverage
+	err = pos.ScaleIn(quantity, fillPrice)
+	if err == nil {
+		err = s.positions.ResizeProtectiveOrders(ctx, op, pos)
+	}
+	if err != nil {
+		s.logger.Warn(ctx, op+": Closing the unprotected add again...", map[string]interface{}{"positionID": pos.ID})
+		if closeErr := s.positions.EmergencyClose(ctx, fillPrice, quantityStr, positionSide.EntrySide(), exchangeSide); closeErr != nil {
+			s.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED after scale-in")
+			s.notifyCritical(ctx, "Emergency close of a scale-in add failed", closeErr)
+		}
+		pos.EntryPrice, pos.Quantity, pos.ScaleIns, pos.Leverage = entryPrice, previousQuantity, scaleIns, previousLeverage
+		s.restoreStopLoss(ctx, op, pos)
+		return fmt.Errorf("failed to protect scale-in add: %w (emergency close attempted)", err)
+	}
+
+	pos.Fees += domain.SummarizeFills(addFills).Commission
+	s.saveOrderFills(ctx, pos.ID, addFills)
+	if err := s.positions.Save(ctx, pos); err != nil {
+		// The exchange orders already match the new size; only the saved record lags behind
+		s.logger.Error(ctx, err, op+": Failed to save scaled-in position", map[string]interface{}{"positionID": pos.ID})
+	}
+	s.logger.Info(ctx, op+": Added to position", map[string]interface{}{
+		"positionID": pos.ID,
+		"fillPrice":  fillPrice,
+		"entryPrice": pos.EntryPrice,
+		"quantity":   pos.Quantity,
+		"scaleIns":   pos.ScaleIns,
+	})
+	return nil
+}
+
+// restoreStopLoss puts back the stop loss of a position whose resize failed after its old stop had
+// been canceled, for the quantity it has after the add was closed again, and saves the new order
+// ID. A position left without a stop is reported as critical.
+func (s *TradingService) restoreStopLoss(ctx context.Context, op string, pos *domain.Position) {
+	if pos.StopLossOrderID != nil {
+		return
+	}
+	if err := s.positions.RestoreStopLoss(ctx, pos); err != nil {
+		s.logger.Error(ctx, err, op+": POSITION LEFT WITHOUT STOP LOSS", map[string]interface{}{"positionID": pos.ID})
+		s.notifyCritical(ctx, "Position left without a stop loss after a failed resize", err)
+		return
+	}
+	if err := s.positions.Save(ctx, pos); err != nil {
+		s.logger.Error(ctx, err, op+": Failed to save restored stop loss", map[string]interface{}{"positionID": pos.ID})
+	}
+}
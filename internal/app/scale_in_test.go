@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_ScaleIn(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  1.0,
+		StopLoss:  0.02,
+		MaxProfit: 0.05,
+		MaxOrders: 5,
+		Leverage:  10,
+	}
+	plan := domain.ScaleInPlan{InitialFraction: 0.5, Steps: []float64{0.003, 0.006}}
+	klineOpen := time.Date(2025, 6, 11, 12, 30, 0, 0, time.UTC)
+	newService := func(t *testing.T, opts ...Option) (*TradingService, *mockExchange, *mockPositionRepo) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY": {OrderID: 1, AvgPrice: 2000, Status: "FILLED"},
+			"stop_SELL":  {OrderID: 2, Status: "NEW"},
+			"tp_SELL":    {OrderID: 3, Status: "NEW"},
+		}}
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{},
+			append([]Option{WithScaleIn(plan)}, opts...)...)
+		require.NoError(t, err)
+		return service, exchange, posRepo
+	}
+	ctx := context.Background()
+
+	t.Run("entry takes the initial share and adds follow the price", func(t *testing.T) {
+		service, exchange, posRepo := newService(t)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, klineOpen))
+		pos := service.positions.long
+		require.NotNil(t, pos)
+		assert.Equal(t, "0.500", exchange.marketOrderQty)
+		assert.Equal(t, 2000.0, pos.ScaleInBasePrice)
+
+		// Not yet at the first level (1994)
+		service.addScaleIns(ctx, 1995)
+		assert.Zero(t, pos.ScaleIns)
+
+		exchange.orderResponses["market_BUY"] = &ports.OrderResponse{OrderID: 4, AvgPrice: 1994, Status: "FILLED"}
+		exchange.orderResponses["stop_SELL"] = &ports.OrderResponse{OrderID: 5, Status: "NEW"}
+		exchange.orderResponses["tp_SELL"] = &ports.OrderResponse{OrderID: 6, Status: "NEW"}
+		service.addScaleIns(ctx, 1994)
+		assert.Equal(t, "0.250", exchange.marketOrderQty)
+		assert.Equal(t, 1, pos.ScaleIns)
+		assert.Equal(t, 0.75, pos.Quantity)
+		assert.InDelta(t, 1998, pos.EntryPrice, 1e-9)
+		assert.InDelta(t, 1960, pos.StopLoss, 1e-9, "stop levels are kept")
+		assert.Equal(t, "5", *pos.StopLossOrderID)
+		assert.Equal(t, "6", *pos.TakeProfitOrderID)
+		assert.Same(t, pos, posRepo.positions["ETHUSDT"])
+
+		service.addScaleIns(ctx, 1980)
+		assert.Equal(t, 2, pos.ScaleIns)
+		assert.InDelta(t, 1.0, pos.Quantity, 1e-9)
+		service.addScaleIns(ctx, 1900)
+		assert.Equal(t, 2, pos.ScaleIns, "no adds beyond the full size")
+	})
+
+	t.Run("unprotected add is closed again", func(t *testing.T) {
+		service, exchange, _ := newService(t)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, klineOpen))
+		pos := service.positions.long
+		require.NotNil(t, pos)
+
+		exchange.stopErrs = []error{ports.ErrExchangeUnavailable}
+		exchange.orderResponses["stop_SELL"] = &ports.OrderResponse{OrderID: 7, Status: "NEW"}
+		exchange.positionSides = nil
+		service.addScaleIns(ctx, 1990)
+		assert.Zero(t, pos.ScaleIns)
+		assert.Equal(t, 0.5, pos.Quantity)
+		assert.Equal(t, 2000.0, pos.EntryPrice)
+		assert.Len(t, exchange.positionSides, 2, "add and its emergency close")
+
+		// The old stop was canceled before the resized one failed; a new one covers the position again
+		assert.Equal(t, []int64{2}, exchange.canceledOrders)
+		assert.Equal(t, []string{"0.500", "0.750", "0.500"}, exchange.stopQty)
+		require.NotNil(t, pos.StopLossOrderID)
+		assert.Equal(t, "7", *pos.StopLossOrderID)
+	})
+
+	t.Run("stop is only replaced once the old one is canceled", func(t *testing.T) {
+		service, exchange, _ := newService(t)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, klineOpen))
+		pos := service.positions.long
+		require.NotNil(t, pos)
+
+		exchange.orderErrors = map[string]error{"cancel_2": ports.ErrExchangeUnavailable}
+		exchange.positionSides = nil
+		service.addScaleIns(ctx, 1990)
+		assert.Zero(t, pos.ScaleIns)
+		assert.Equal(t, []string{"0.500"}, exchange.stopQty, "Expected no second stop next to the old one")
+		assert.Equal(t, "2", *pos.StopLossOrderID)
+		assert.Len(t, exchange.positionSides, 2, "add and its emergency close")
+	})
+
+	t.Run("adds pause with entries", func(t *testing.T) {
+		schedule := &risk.BlackoutSchedule{Events: []risk.BlackoutEvent{{Name: "CPI", At: time.Now(), Before: time.Hour, After: time.Hour}}}
+		require.NoError(t, schedule.Validate())
+		service, _, _ := newService(t)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, klineOpen))
+		service.gate.blackout = schedule
+
+		service.addScaleIns(ctx, 1990)
+		assert.Zero(t, service.positions.long.ScaleIns)
+	})
+}
@@ -2,38 +2,214 @@ package app
 
 import (
 	"context"
-	"errors" // Need for error checking in cancelOrderWarn
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
-	"strconv" // Need for formatting quantity/prices
 	"sync"
 	"syscall"
 	"time"
 
 	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/dataquality"
 	"cryptoMegaBot/internal/domain"
 	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"cryptoMegaBot/internal/utils"
 )
 
 const (
-	maxKlineCacheSize = 500 // Limit cache size to avoid memory issues
+	maxKlineCacheSize = 500  // Limit cache size to avoid memory issues
+	primaryInterval   = "1m" // Kline interval whose closes drive strategy evaluation
 )
 
-// TradingService orchestrates the trading bot's operations.
+// TradingService orchestrates the trading bot's operations: it feeds the kline streams to its
+// SignalEngine, asks its EntryGate whether a signal may be acted on and its EntrySizer how large
+// the entry is, and has its PositionManager place it. The mutex serializes the stream handlers,
+// the control API and the background tasks, so none of the components needs locking of its own.
 type TradingService struct {
-	cfg        *config.Config
-	logger     ports.Logger
-	exchange   ports.ExchangeClient
-	posRepo    ports.PositionRepository
-	tradeRepo  ports.TradeRepository
-	strategy   ports.Strategy
-	klineCache []*domain.Kline // Simple cache for strategy calculations
+	cfg       *config.Config
+	logger    ports.Logger
+	exchange  ports.ExchangeClient
+	tradeRepo ports.TradeRepository
+	signals   *SignalEngine                 // Strategy and the klines it evaluates, protected by mu
+	positions *PositionManager              // Open positions and the orders managing them, protected by mu
+	gate      *EntryGate                    // Risk checks deciding whether entries are allowed, protected by mu
+	sizer     *EntrySizer                   // Entry and scale-in sizing, protected by mu
+	stateRepo ports.StrategyStateRepository // Optional: persists strategy state across restarts
+	intents   ports.EntryIntentRepository   // Optional: records entry orders before placement to prevent double entries
+	liquidity *strategies.LiquidityFilter   // Optional: skips entries into thin or wide order books
+	reporter  *DailyReporter                // Optional: sends a daily trading summary
+	clock     *ClockMonitor                 // Optional: detects clock drift and resyncs server time
+	notifier  ports.Notifier                // Optional: announces entries, exits and critical errors
+	events    ports.EventBus                // Trading events for subscribers (notifications, audit, metrics)
+	registry  *StrategyRegistry             // Optional: strategies the control API can switch to
+	intervals []string                      // Additional kline intervals streamed for multi-timeframe analysis
+
+	notifications sync.WaitGroup // Tracks notifications still being delivered
 
 	// State fields
-	mu              sync.Mutex // Protects access to state fields below
-	currentPosition *domain.Position
-	tradesToday     int
+	mu             sync.Mutex // Protects access to state fields below
+	startingEquity float64    // Account balance at startup (equity baseline for kill switch / drawdown throttle)
+	realizedPnL    float64    // PNL realized since startup
+
+	// activeStrategy is the registered name and params of the strategy (when a registry is set)
+	activeStrategy strategySelection
+
+	// Stream watchdog (optional), protected by mu
+	watchdog         bool      // Whether stalls and gaps of the primary stream are detected
+	watchdogPause    bool      // Whether entries are paused while the stream is discontinuous
+	lastKlineAt      time.Time // When the last final primary kline was received
+	streamIssue      string    // Latest stall or gap; empty while the stream is continuous
+	streamIssueSince time.Time // When continuity was lost
+	streamGaps       int
+	streamStalls     int
+
+	// Equity curve for the dashboard (optional), protected by mu
+	recordEquity  bool
+	equityHistory []ports.EquityPoint
+
+	// Kline anomaly detection (optional), protected by mu
+	anomalyDetector *dataquality.Detector
+
+	// Kline cache persistence for warm starts (optional)
+	klineStore        ports.KlineCacheRepository
+	klineSaveInterval time.Duration
+
+	// Periodic database maintenance (optional)
+	dbMaintainer  ports.DatabaseMaintainer
+	dbMaintenance DBMaintenanceConfig
+
+	// Exchange maintenance/outage safe mode (optional), protected by mu
+	safeMode         *SafeModeConfig
+	safeModeRepo     ports.SafeModeRepository
+	safeModeEvent    *domain.SafeModeEvent // Active event; nil while the exchange is healthy
+	exchangeFailures int                   // Consecutive failed health checks and outage errors
+	healthyChecks    int                   // Consecutive successful health checks while in safe mode
+
+	lastUserConfigAlert time.Time // When a configuration error (API keys, permissions) was last alerted, protected by mu
+
+	// WebSocket reconnect alerts (optional), protected by mu
+	reconnectAlerts   *ReconnectAlertConfig
+	reconnectStats    ports.ReconnectStats // Latest sample of the exchange client's statistics
+	reconnectSamples  []reconnectSample    // Reconnect counts within the window, oldest (the baseline) first
+	reconnectAlerting bool                 // Whether the alert threshold is reached
+
+	// Exchange request latency alerts (optional), protected by mu
+	latencyAlerts   *LatencyAlertConfig
+	latencyStats    map[string]ports.OperationLatency // Latest sample of the exchange client's statistics
+	latencyAlerting map[string]bool                   // Operations whose p95 latency is above the threshold
+
+	// Order fill recording (optional)
+	fillRepo ports.OrderFillRepository
+
+	// Order log for auditing (optional)
+	orderLog ports.OrderRepository
+
+	// Maximum position holding time enforced by the service (optional; 0 disables)
+	maxHolding time.Duration
+
+	// Limit entries requested by the strategy (optional), protected by mu
+	limitEntries *LimitEntryConfig
+	pendingLimit map[domain.PositionSide]*pendingLimitEntry // Limit entries resting on the exchange by side
+
+	// Reporting currency conversion for the dashboard (optional)
+	converter *CurrencyConverter
+
+	// Open interest snapshots for strategies that use them, protected by mu
+	openInterest   []*domain.OpenInterest
+	openInterestAt time.Time // When the snapshots were last fetched
+
+	// Source of the current time (clock.Real unless set with WithClock)
+	timeSource ports.Clock
+
+	// Slippage and spread checks of market entries (optional)
+	slippageGuard *SlippageGuardConfig
+
+	// Additional intervals resampled from the primary stream (optional; nil streams each), protected by mu
+	aggregators map[string]*utils.KlineAggregator
+
+	// Funding and commission accrual from the income history (optional)
+	incomeAccrual *IncomeAccrualConfig
+}
+
+// Option configures optional TradingService dependencies.
+type Option func(*TradingService)
+
+// WithStateRepository enables persisting the strategy's internal state (if it implements
+// ports.StatefulStrategy) so risk throttles survive restarts.
+func WithStateRepository(repo ports.StrategyStateRepository) Option {
+	return func(s *TradingService) {
+		s.stateRepo = repo
+	}
+}
+
+// WithKillSwitch enables the equity-curve kill switch, which pauses new entries
+// when drawdown or losing-day limits are breached.
+func WithKillSwitch(ks *risk.KillSwitch) Option {
+	return func(s *TradingService) {
+		s.gate.killSwitch = ks
+	}
+}
+
+// WithLiquidityFilter enables an order book check that skips entries when the
+// spread or top-of-book depth is worse than the filter's thresholds.
+func WithLiquidityFilter(filter *strategies.LiquidityFilter) Option {
+	return func(s *TradingService) {
+		s.liquidity = filter
+	}
+}
+
+// WithRiskManager enables drawdown-aware position sizing: the configured quantity
+// is scaled down by the risk manager's throttle curve as equity falls from its peak.
+func WithRiskManager(rm *risk.RiskManager) Option {
+	return func(s *TradingService) {
+		s.sizer.riskMgr = rm
+	}
+}
+
+// WithDailyReporter runs the daily summary report scheduler alongside the service.
+func WithDailyReporter(r *DailyReporter) Option {
+	return func(s *TradingService) {
+		s.reporter = r
+	}
+}
+
+// WithClockMonitor periodically checks local vs exchange clock drift and
+// resynchronizes server time when it exceeds the monitor's tolerance.
+func WithClockMonitor(c *ClockMonitor) Option {
+	return func(s *TradingService) {
+		s.clock = c
+	}
+}
+
+// WithClock takes the current time from c instead of the system clock, e.g. a fake clock in
+// simulations and tests.
+func WithClock(c ports.Clock) Option {
+	return func(s *TradingService) {
+		if c != nil {
+			s.timeSource = c
+		}
+	}
+}
+
+// WithNotifier sends a notification for every position entry and exit, and for
+// critical errors such as a failed emergency close.
+func WithNotifier(n ports.Notifier) Option {
+	return func(s *TradingService) {
+		s.notifier = n
+	}
+}
+
+// WithStrategyRegistry enables switching the strategy at runtime through the control API.
+// active is the registered name of the strategy passed to NewTradingService.
+func WithStrategyRegistry(registry *StrategyRegistry, active string) Option {
+	return func(s *TradingService) {
+		s.registry = registry
+		s.activeStrategy = strategySelection{Name: active}
+	}
 }
 
 // NewTradingService creates a new application service instance.
@@ -44,6 +220,7 @@ func NewTradingService(
 	posRepo ports.PositionRepository,
 	tradeRepo ports.TradeRepository,
 	strat ports.Strategy,
+	opts ...Option,
 ) (*TradingService, error) {
 
 	// Validate dependencies
@@ -65,15 +242,59 @@ func NewTradingService(
 		return nil, fmt.Errorf("configuration MaxOrders must be positive")
 	}
 
-	return &TradingService{
+	s := &TradingService{
 		cfg:        cfg,
 		logger:     logger,
 		exchange:   exchange,
-		posRepo:    posRepo,
 		tradeRepo:  tradeRepo,
-		strategy:   strat,
-		klineCache: make([]*domain.Kline, 0, maxKlineCacheSize), // Initialize cache
-	}, nil
+		signals:    NewSignalEngine(strat),
+		gate:       NewEntryGate(cfg),
+		sizer:      NewEntrySizer(cfg, logger, exchange),
+		timeSource: clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.events == nil {
+		s.events = NewEventBus(logger)
+	}
+	s.positions = NewPositionManager(PositionManagerConfig{
+		Config:        cfg,
+		Logger:        logger,
+		Exchange:      exchange,
+		Repo:          posRepo,
+		OrderLog:      s.orderLog,
+		Clock:         s.timeSource,
+		OnOrderResult: s.recordOrderResult,
+		OnEvent:       s.publish,
+		OnCritical:    s.notifyCritical,
+		OnNotice:      func(ctx context.Context, subject, message string) { s.notify(ctx, subject, message, nil) },
+	})
+	s.gate.attach(s.timeSource, s.positions, s, s.streamBlocksEntries, s.safeModeBlocksEntries)
+	s.sizer.attach(s.timeSource, s.positions, s)
+	s.subscribeInternal()
+	s.intervals = additionalIntervals(cfg.KlineIntervals, strat)
+	return s, nil
+}
+
+// additionalIntervals merges the configured intervals with those a multi-timeframe strategy
+// needs, dropping duplicates and the primary interval.
+func additionalIntervals(configured []string, strat ports.Strategy) []string {
+	candidates := append([]string(nil), configured...)
+	if mtf, ok := strat.(ports.MultiTimeframeStrategy); ok {
+		candidates = append(candidates, mtf.Timeframes()...)
+	}
+
+	seen := map[string]bool{primaryInterval: true}
+	var intervals []string
+	for _, interval := range candidates {
+		if interval == "" || seen[interval] {
+			continue
+		}
+		seen[interval] = true
+		intervals = append(intervals, interval)
+	}
+	return intervals
 }
 
 // Start begins the trading bot's main loop.
@@ -101,13 +322,21 @@ func (s *TradingService) Start(ctx context.Context) error {
 		return fmt.Errorf("failed to set server time: %w", err)
 	}
 	s.logger.Info(ctx, "Server time synchronized")
+	if s.clock != nil {
+		go s.clock.Run(ctx) // Stops when ctx is canceled
+		s.logger.Info(ctx, "Clock drift monitor started")
+	}
 
 	// 2. Check if futures trading is enabled
-	pos, err := s.exchange.GetPositionRisk(ctx, s.cfg.Symbol)
+	risks, err := s.exchange.GetPositionRisks(ctx, s.cfg.Symbol)
 	if err != nil {
 		s.logger.Error(ctx, err, "Failed to check futures trading status", map[string]interface{}{"symbol": s.cfg.Symbol})
 		return fmt.Errorf("failed to check futures trading status: %w", err)
 	}
+	var pos *ports.PositionRisk // Leverage and margin type are per symbol, so any side will do
+	if len(risks) > 0 {
+		pos = risks[0]
+	}
 
 	// 3. Set Leverage only if current leverage is different
 	currentLeverage := 1 // Default leverage
@@ -143,24 +372,35 @@ func (s *TradingService) Start(ctx context.Context) error {
 		})
 	}
 
-	// 4. Sync existing position state (if any)
+	// 3.1 Fetch the leverage brackets entries are kept within
+	s.sizer.LoadLeverageBrackets(ctx)
+
+	// 4. Ensure the configured margin mode before any orders are placed
+	if err := s.ensureMarginType(ctx, pos); err != nil {
+		return fmt.Errorf("failed to set margin type: %w", err)
+	}
+
+	// 4.1 Ensure the configured position mode (hedge or one-way)
+	if err := s.ensurePositionMode(ctx); err != nil {
+		return fmt.Errorf("failed to set position mode: %w", err)
+	}
+
+	// 5. Sync existing position state (if any), one position per side
 	s.logger.Info(ctx, "Synchronizing initial state...")
-	openPos, err := s.posRepo.FindOpenBySymbol(ctx, s.cfg.Symbol)
-	if err != nil {
-		// Log error but continue, assuming no open position if DB fails? Or make it fatal?
-		// Let's make it fatal for now, as state is critical.
-		s.logger.Error(ctx, err, "Failed to check for existing open position")
-		s.logger.Info(ctx, "No existing open position found")
-		return fmt.Errorf("failed to query open position: %w", err)
-	}
-	if openPos != nil {
-		s.currentPosition = openPos
-		s.logger.Info(ctx, "Found existing open position", map[string]interface{}{"positionID": openPos.ID, "entryPrice": openPos.EntryPrice, "takeProfit": openPos.TakeProfit, "stopLoss": openPos.StopLoss})
-		// TODO: Potentially sync SL/TP order status with exchange here? This is complex.
-		// For now, assume SL/TP orders placed previously are still active if the position is open.
-		// A more robust solution would involve querying open orders.
-	} else {
-		s.logger.Info(ctx, "No existing open position found")
+	if err := s.positions.LoadOpen(ctx); err != nil {
+		return err
+	}
+
+	// 5.1 Settle entries the previous run placed without saving their position
+	if err := s.reconcileEntryIntents(ctx); err != nil {
+		s.logger.Error(ctx, err, "Failed to reconcile pending entries")
+		return fmt.Errorf("failed to reconcile pending entries: %w", err)
+	}
+
+	// 5.2 Cancel SL/TP orders left without a position by a crash
+	if err := s.cancelOrphanedOrders(ctx); err != nil {
+		s.logger.Error(ctx, err, "Failed to clean up open orders")
+		return fmt.Errorf("failed to clean up open orders: %w", err)
 	}
 
 	tradesCount, err := s.tradeRepo.CountTodayBySymbol(ctx, s.cfg.Symbol)
@@ -169,13 +409,47 @@ func (s *TradingService) Start(ctx context.Context) error {
 		s.logger.Error(ctx, err, "Failed to count trades for today")
 		return fmt.Errorf("failed to count today's trades: %w", err)
 	}
-	s.tradesToday = tradesCount
-	s.logger.Info(ctx, "Initial state synchronized", map[string]interface{}{"tradesToday": s.tradesToday})
+	s.gate.RestoreTradesToday(tradesCount)
+	if err := s.sizer.RestoreDailyVolume(ctx); err != nil {
+		// Fatal like the trade count: the caps would otherwise restart from zero
+		s.logger.Error(ctx, err, "Failed to load today's traded volume")
+		return fmt.Errorf("failed to load today's traded volume: %w", err)
+	}
+	s.logger.Info(ctx, "Initial state synchronized", map[string]interface{}{"tradesToday": tradesCount})
+
+	// Re-apply a strategy switched at runtime before the restart, then restore its persisted
+	// state (loss counters, volatility history)
+	s.restoreActiveStrategy(ctx)
+	s.restoreStrategyState(ctx)
+	s.restoreLastExit(ctx)
+	s.restoreStreak(ctx)
+	s.restoreSafeMode(ctx)
+
+	// Equity baseline for the kill switch, drawdown throttle and equity curve
+	if s.gate.killSwitch != nil || s.sizer.riskMgr != nil || s.recordEquity || s.gate.equityTrail != nil {
+		balance, err := s.exchange.GetAccountBalance(ctx, s.cfg.MarginAsset())
+		if err != nil {
+			s.logger.Error(ctx, err, "Failed to get account balance for equity tracking")
+			return fmt.Errorf("failed to get starting equity: %w", err)
+		}
+		s.startingEquity = balance
+		s.gate.UpdateEquity(balance)
+		if s.gate.killSwitch != nil {
+			s.logger.Info(ctx, "Kill switch enabled", map[string]interface{}{"startingEquity": balance})
+		}
+		s.sizer.UpdateEquity(ctx, balance)
+		if s.sizer.riskMgr != nil {
+			s.logger.Info(ctx, "Drawdown throttle enabled", map[string]interface{}{"startingEquity": balance})
+		}
+		if s.gate.equityTrail != nil {
+			s.restoreEquityTrail(ctx, balance)
+		}
+	}
 
-	// 5. Load initial klines for strategy
-	requiredPoints := s.strategy.RequiredDataPoints()
+	// 6. Load initial klines for strategy
+	requiredPoints := s.signals.Strategy().RequiredDataPoints()
 	s.logger.Info(ctx, "Loading initial klines for strategy", map[string]interface{}{"requiredPoints": requiredPoints})
-	initialKlines, err := s.exchange.GetKlines(ctx, s.cfg.Symbol, "1m", requiredPoints)
+	initialKlines, err := s.loadInitialKlines(ctx, requiredPoints)
 	if err != nil {
 		s.logger.Error(ctx, err, "Failed to load initial klines for strategy")
 		return fmt.Errorf("failed to load initial klines: %w", err)
@@ -185,46 +459,317 @@ func (s *TradingService) Start(ctx context.Context) error {
 		s.logger.Error(ctx, err, "Insufficient historical data")
 		return err
 	}
-	s.klineCache = initialKlines // Assuming GetKlines returns []*domain.Kline
-	s.logger.Info(ctx, "Loaded initial klines", map[string]interface{}{"count": len(s.klineCache)})
+	s.signals.SetKlines(initialKlines)
+	s.logger.Info(ctx, "Loaded initial klines", map[string]interface{}{"count": len(s.signals.Klines())})
+	s.seedAnomalyDetector()
+
+	// Close positions whose maximum holding time ran out while the bot was down
+	if s.maxHolding > 0 {
+		s.mu.Lock()
+		price, _ := s.signals.LastPrice()
+		s.closeExpiredPositions(ctx, price, s.now())
+		s.mu.Unlock()
+	}
 
-	// --- Start WebSocket Stream ---
-	wsDoneCh, wsStopCh, err := s.exchange.StreamKlines(ctx, s.cfg.Symbol, "1m", s.handleKlineEvent, s.handleWsError)
+	// Load initial klines for the additional timeframes
+	for _, interval := range s.intervals {
+		klines, err := s.exchange.GetKlines(ctx, s.cfg.Symbol, interval, requiredPoints)
+		if err != nil {
+			s.logger.Error(ctx, err, "Failed to load initial klines for timeframe", map[string]interface{}{"interval": interval})
+			return fmt.Errorf("failed to load initial %s klines: %w", interval, err)
+		}
+		s.mu.Lock()
+		s.signals.SetTimeframe(interval, klines)
+		s.mu.Unlock()
+		s.logger.Info(ctx, "Loaded initial timeframe klines", map[string]interface{}{"interval": interval, "count": len(klines)})
+	}
+	if s.aggregators != nil {
+		if err := s.startAggregation(ctx); err != nil {
+			s.logger.Error(ctx, err, "Failed to start kline aggregation")
+			return err
+		}
+	}
+
+	// --- Start WebSocket Streams ---
+	wsDoneCh, wsStopCh, err := s.exchange.StreamKlines(ctx, s.cfg.Symbol, primaryInterval, s.handleKlineEvent, s.handleWsError)
 	if err != nil {
 		s.logger.Error(ctx, err, "Failed to start WebSocket stream")
 		return fmt.Errorf("failed to start WebSocket stream: %w", err)
 	}
-	s.logger.Info(ctx, "WebSocket stream started", map[string]interface{}{"symbol": s.cfg.Symbol, "interval": "1m"})
+	streams := []klineStream{{interval: primaryInterval, doneCh: wsDoneCh, stopCh: wsStopCh}}
+	s.logger.Info(ctx, "WebSocket stream started", map[string]interface{}{"symbol": s.cfg.Symbol, "interval": primaryInterval})
+
+	streamed := s.intervals
+	if s.aggregators != nil {
+		streamed = nil // Built from the primary stream
+	}
+	for _, interval := range streamed {
+		doneCh, stopCh, err := s.exchange.StreamKlines(ctx, s.cfg.Symbol, interval, s.timeframeKlineHandler(interval), s.handleWsError)
+		if err != nil {
+			s.logger.Error(ctx, err, "Failed to start WebSocket stream", map[string]interface{}{"interval": interval})
+			s.stopStreams(ctx, streams)
+			return fmt.Errorf("failed to start %s WebSocket stream: %w", interval, err)
+		}
+		streams = append(streams, klineStream{interval: interval, doneCh: doneCh, stopCh: stopCh})
+		s.logger.Info(ctx, "WebSocket stream started", map[string]interface{}{"symbol": s.cfg.Symbol, "interval": interval})
+	}
+
+	// Stream watchdog stops when ctx is canceled; the initial klines count as the last received
+	if s.watchdog {
+		s.mu.Lock()
+		s.lastKlineAt = s.now()
+		s.mu.Unlock()
+		go s.runStreamWatchdog(ctx)
+		s.logger.Info(ctx, "Kline stream watchdog started", map[string]interface{}{"pauseEntries": s.watchdogPause})
+	}
+
+	// Exchange health checks stop when ctx is canceled
+	if s.safeMode != nil {
+		go s.runSafeModeMonitor(ctx)
+		s.logger.Info(ctx, "Exchange safe mode monitor started", map[string]interface{}{
+			"checkInterval": s.safeMode.CheckInterval.String(),
+			"action":        s.safeMode.Action,
+		})
+	}
+
+	// Income accrual stops when ctx is canceled
+	s.startIncomeAccrual(ctx)
+
+	// Reconnect statistics sampler stops when ctx is canceled
+	if s.reconnectAlerts != nil {
+		if provider, ok := s.exchange.(ports.ReconnectStatsProvider); ok {
+			go s.runReconnectMonitor(ctx, provider)
+			s.logger.Info(ctx, "Stream reconnect monitor started", map[string]interface{}{
+				"threshold": s.reconnectAlerts.Threshold,
+				"window":    s.reconnectAlerts.Window.String(),
+			})
+		} else {
+			s.logger.Warn(ctx, "Exchange client doesn't report reconnect statistics, reconnect alerts disabled")
+		}
+	}
+
+	// Latency statistics sampler stops when ctx is canceled
+	if s.latencyAlerts != nil {
+		if provider, ok := s.exchange.(ports.LatencyStatsProvider); ok {
+			go s.runLatencyMonitor(ctx, provider)
+			s.logger.Info(ctx, "Exchange latency monitor started", map[string]interface{}{
+				"p95Threshold": s.latencyAlerts.P95Threshold.String(),
+			})
+		} else {
+			s.logger.Warn(ctx, "Exchange client doesn't report latency statistics, latency alerts disabled")
+		}
+	}
+
+	// Kline cache saver stops when ctx is canceled
+	if s.klineStore != nil && s.klineSaveInterval > 0 {
+		go s.runKlineCacheSaver(ctx)
+		s.logger.Info(ctx, "Kline cache persistence started", map[string]interface{}{"saveInterval": s.klineSaveInterval.String()})
+	}
+
+	// Database maintenance stops when ctx is canceled
+	if s.dbMaintainer != nil && s.dbMaintenance.Interval > 0 {
+		go s.runDBMaintenance(ctx)
+		s.logger.Info(ctx, "Database maintenance started", map[string]interface{}{
+			"interval":       s.dbMaintenance.Interval.String(),
+			"retention":      s.dbMaintenance.Retention.String(),
+			"vacuum":         s.dbMaintenance.Vacuum,
+			"integrityCheck": s.dbMaintenance.IntegrityCheck,
+		})
+	}
+
+	// Session end scheduler stops when ctx is canceled
+	if s.gate.sessionEnd > 0 {
+		go s.runSessionEnd(ctx)
+		s.logger.Info(ctx, "Session end scheduler started", map[string]interface{}{"atUTC": s.gate.sessionEnd.String()})
+	}
+
+	// Limit entry monitor stops when ctx is canceled
+	if s.limitEntries != nil {
+		go s.runLimitEntries(ctx)
+		s.logger.Info(ctx, "Limit entry monitor started", map[string]interface{}{
+			"timeout":          s.limitEntries.Timeout.String(),
+			"expiryBars":       s.limitEntries.ExpiryBars,
+			"fallbackToMarket": s.limitEntries.FallbackToMarket,
+			"postOnly":         s.limitEntries.PostOnly,
+		})
+	}
+
+	// Daily report scheduler stops when ctx is canceled
+	if s.reporter != nil {
+		go s.reporter.Run(ctx)
+		s.logger.Info(ctx, "Daily report scheduler started")
+	}
 
 	// --- Main Loop ---
 	// The main work happens in handleKlineEvent triggered by the WebSocket stream.
-	// We just need to wait for the context to be canceled or the WebSocket to finish.
+	// We just need to wait for the context to be canceled or any WebSocket to finish.
+	streamStopped := make(chan string, len(streams))
+	for _, st := range streams {
+		go func(st klineStream) {
+			select {
+			case <-st.doneCh:
+				streamStopped <- st.interval
+			case <-ctx.Done():
+			}
+		}(st)
+	}
 
 	select {
 	case <-ctx.Done():
 		s.logger.Info(ctx, "Main context cancelled, initiating shutdown...")
-		// Signal WebSocket to stop
+		s.stopStreams(ctx, streams)
+	case interval := <-streamStopped:
+		// WebSocket closed unexpectedly (e.g., max reconnect attempts failed)
+		err := fmt.Errorf("websocket stream closed unexpectedly")
+		s.logger.Error(ctx, err, "WebSocket stream stopped", map[string]interface{}{"interval": interval})
+		s.notifyCritical(ctx, fmt.Sprintf("Trading stopped: %s stream closed", interval), err)
+		s.mu.Lock()
+		s.cancelLimitEntries(context.Background())
+		s.mu.Unlock()
+		s.saveKlineCache(context.Background())
+		s.notifications.Wait()
+		// The service should probably exit here; the deferred cancel stops the other streams.
+		return fmt.Errorf("websocket stream stopped unexpectedly (%s)", interval)
+	}
+
+	// Persist strategy state and the kline cache for the next run; ctx is already canceled here
+	s.mu.Lock()
+	s.cancelLimitEntries(context.Background()) // Entries must not fill while nobody protects them
+	s.persistStrategyState(context.Background())
+	s.mu.Unlock()
+	s.saveKlineCache(context.Background())
+
+	s.notifications.Wait()
+	s.logger.Info(ctx, "Trading Service stopped.")
+	return nil
+}
+
+// klineStream tracks the control channels of one kline WebSocket stream.
+type klineStream struct {
+	interval string
+	doneCh   chan struct{}
+	stopCh   chan struct{}
+}
+
+// stopStreams signals every stream to stop and waits briefly for them to close.
+func (s *TradingService) stopStreams(ctx context.Context, streams []klineStream) {
+	for _, st := range streams {
 		select {
-		case wsStopCh <- struct{}{}:
-			s.logger.Info(ctx, "Stop signal sent to WebSocket stream")
+		case st.stopCh <- struct{}{}:
+			s.logger.Info(ctx, "Stop signal sent to WebSocket stream", map[string]interface{}{"interval": st.interval})
 		default:
-			s.logger.Warn(ctx, "Failed to send stop signal to WebSocket (already closed?)")
+			s.logger.Warn(ctx, "Failed to send stop signal to WebSocket (already closed?)", map[string]interface{}{"interval": st.interval})
 		}
-		// Wait briefly for WebSocket to close gracefully
+	}
+
+	// Wait briefly for the streams to close gracefully, sharing one timeout
+	timeout := time.After(5 * time.Second)
+	for _, st := range streams {
 		select {
-		case <-wsDoneCh:
-			s.logger.Info(ctx, "WebSocket stream shut down gracefully")
-		case <-time.After(5 * time.Second): // Timeout for WS shutdown
-			s.logger.Warn(ctx, "Timeout waiting for WebSocket stream to shut down")
+		case <-st.doneCh:
+			s.logger.Info(ctx, "WebSocket stream shut down gracefully", map[string]interface{}{"interval": st.interval})
+		case <-timeout:
+			s.logger.Warn(ctx, "Timeout waiting for WebSocket stream to shut down", map[string]interface{}{"interval": st.interval})
+			return
 		}
-	case <-wsDoneCh:
-		// WebSocket closed unexpectedly (e.g., max reconnect attempts failed)
-		s.logger.Error(ctx, fmt.Errorf("websocket stream closed unexpectedly"), "WebSocket stream stopped")
-		// The service should probably exit here.
-		return fmt.Errorf("websocket stream stopped unexpectedly")
 	}
+}
 
-	s.logger.Info(ctx, "Trading Service stopped.")
+// restoreStrategyState loads previously saved strategy state, if the strategy supports it.
+// Failures are logged but not fatal: the strategy simply starts with fresh state.
+func (s *TradingService) restoreStrategyState(ctx context.Context) {
+	op := "restoreStrategyState"
+	stateful, ok := s.signals.Strategy().(ports.StatefulStrategy)
+	if !ok || s.stateRepo == nil {
+		return
+	}
+
+	data, err := s.stateRepo.LoadStrategyState(ctx, stateful.Name(), s.cfg.Symbol)
+	if err != nil {
+		s.logger.Error(ctx, err, op+": Failed to load strategy state, starting fresh")
+		return
+	}
+	if data == nil {
+		s.logger.Info(ctx, op+": No saved strategy state found", map[string]interface{}{"strategy": stateful.Name()})
+		return
+	}
+	if err := stateful.LoadState(ctx, data); err != nil {
+		s.logger.Error(ctx, err, op+": Failed to restore strategy state, starting fresh")
+		return
+	}
+	s.logger.Info(ctx, op+": Strategy state restored", map[string]interface{}{"strategy": stateful.Name()})
+}
+
+// persistStrategyState saves the strategy's current state, if the strategy supports it.
+// Assumes the caller holds the lock.
+func (s *TradingService) persistStrategyState(ctx context.Context) {
+	op := "persistStrategyState"
+	stateful, ok := s.signals.Strategy().(ports.StatefulStrategy)
+	if !ok || s.stateRepo == nil {
+		return
+	}
+
+	data, err := stateful.SaveState(ctx)
+	if err != nil {
+		s.logger.Error(ctx, err, op+": Failed to serialize strategy state")
+		return
+	}
+	if err := s.stateRepo.SaveStrategyState(ctx, stateful.Name(), s.cfg.Symbol, data); err != nil {
+		s.logger.Error(ctx, err, op+": Failed to save strategy state")
+	}
+}
+
+// ensureMarginType makes sure the symbol uses the configured margin mode.
+// The exchange reports "no need to change" when the mode is already set; that is treated as success.
+func (s *TradingService) ensureMarginType(ctx context.Context, pos *ports.PositionRisk) error {
+	op := "ensureMarginType"
+	if s.cfg.MarginType == "" {
+		return nil // Nothing configured, keep the account default
+	}
+	if pos != nil && pos.MarginType == s.cfg.MarginType {
+		s.logger.Info(ctx, "Margin type already set correctly", map[string]interface{}{
+			"symbol":     s.cfg.Symbol,
+			"marginType": s.cfg.MarginType,
+		})
+		return nil
+	}
+
+	err := s.exchange.ChangeMarginType(ctx, s.cfg.Symbol, s.cfg.MarginType)
+	if errors.Is(err, ports.ErrNoChangeNeeded) {
+		s.logger.Info(ctx, "Margin type already set correctly", map[string]interface{}{
+			"symbol":     s.cfg.Symbol,
+			"marginType": s.cfg.MarginType,
+		})
+		return nil
+	}
+	if err != nil {
+		s.logger.Error(ctx, err, op+": failed to change margin type", map[string]interface{}{
+			"symbol":     s.cfg.Symbol,
+			"marginType": s.cfg.MarginType,
+		})
+		return err
+	}
+	s.logger.Info(ctx, "Margin type set successfully", map[string]interface{}{
+		"symbol":     s.cfg.Symbol,
+		"marginType": s.cfg.MarginType,
+	})
+	return nil
+}
+
+// ensurePositionMode switches the account to hedge mode or one-way mode as configured.
+// The exchange reports "no need to change" when the mode is already set; that is treated as success.
+func (s *TradingService) ensurePositionMode(ctx context.Context) error {
+	op := "ensurePositionMode"
+	err := s.exchange.SetPositionMode(ctx, s.cfg.HedgeMode)
+	if errors.Is(err, ports.ErrNoChangeNeeded) {
+		s.logger.Info(ctx, "Position mode already set correctly", map[string]interface{}{"hedgeMode": s.cfg.HedgeMode})
+		return nil
+	}
+	if err != nil {
+		s.logger.Error(ctx, err, op+": failed to change position mode", map[string]interface{}{"hedgeMode": s.cfg.HedgeMode})
+		return err
+	}
+	s.logger.Info(ctx, "Position mode set successfully", map[string]interface{}{"hedgeMode": s.cfg.HedgeMode})
 	return nil
 }
 
@@ -251,50 +796,110 @@ func (s *TradingService) handleKlineEvent(kline *domain.Kline) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Update kline cache
-	s.klineCache = append(s.klineCache, kline)
-	// Trim cache if it exceeds max size
-	if len(s.klineCache) > maxKlineCacheSize {
-		// Keep the most recent maxKlineCacheSize elements
-		s.klineCache = s.klineCache[len(s.klineCache)-maxKlineCacheSize:]
+	// Screen out anomalous klines before anything uses them
+	if kline = s.screenKline(ctx, kline, s.now()); kline == nil {
+		return
+	}
+	currentPrice = kline.Close
+
+	// Update kline cache, refilling it first if klines went missing
+	if s.watchdog {
+		s.checkKlineContinuity(ctx, kline, s.now())
+	}
+	s.addToKlineCache(kline)
+	s.aggregateKline(kline)
+	received := *kline
+	s.publish(ctx, ports.Event{Type: ports.EventKlineReceived, Kline: &received})
+
+	// Hand the per-timeframe caches to multi-timeframe strategies before evaluating
+	s.signals.ProvideTimeframeData()
+	s.provideOpenInterest(ctx)
+
+	// Pull stops closer while a blackout is active, before the exit checks use them
+	s.tightenStopsForBlackout(ctx, currentPrice, s.now())
+
+	// Open filled limit entries and expire those that rested too long
+	s.checkLimitEntries(ctx, currentPrice, true, s.now())
+
+	// Flatten positions still open after the session end; no entries follow until midnight
+	if s.gate.SessionEnded(s.now()) && s.flattenSession(ctx, currentPrice) {
+		return
+	}
+
+	// Flatten and stop for the day once equity fell back to the floor locked in by the equity trail
+	if s.checkEquityTrail(ctx, currentPrice, s.now()) {
+		return
+	}
+
+	// Close positions held past the maximum holding time, whatever the strategy says
+	if s.closeExpiredPositions(ctx, currentPrice, s.now()) {
+		return
 	}
 
 	// --- Check Close Conditions ---
-	if s.currentPosition != nil {
+	closeAttempted := false
+	for _, pos := range s.positions.OpenPositions() {
 		// Check strategy-based exit conditions first
-		shouldClose, reason := s.strategy.ShouldClosePosition(ctx, s.currentPosition, s.klineCache, currentPrice)
-		if shouldClose {
-			s.logger.Info(ctx, "Strategy indicates position should be closed", map[string]interface{}{"positionID": s.currentPosition.ID, "reason": reason})
-			// Attempt to close the position
-			err := s.closePosition(ctx, currentPrice, reason)
-			if err != nil {
-				s.logger.Error(ctx, err, "Failed to close position based on strategy signal", map[string]interface{}{"positionID": s.currentPosition.ID})
-				// Decide how to handle failure: retry? alert? For now, just log.
-			}
-			// Whether close succeeded or failed, we don't check for entry in the same event
-			return
+		shouldClose, reason := s.signals.ExitSignal(ctx, pos, currentPrice)
+		if !shouldClose {
+			// Note: SL/TP might be handled by exchange orders directly.
+			// If ShouldClosePosition also checks SL/TP, this covers it.
+			// If SL/TP are purely exchange-based, we might need order update events.
+			continue
+		}
+		s.logger.Info(ctx, "Strategy indicates position should be closed", map[string]interface{}{"positionID": pos.ID, "side": pos.PositionSide(), "reason": reason})
+		signaled := *pos
+		s.publish(ctx, ports.Event{Type: ports.EventSignalGenerated, Side: pos.PositionSide(), Exit: true, Position: &signaled, Price: currentPrice, Reason: string(reason)})
+		// Attempt to close the position
+		if err := s.closePosition(ctx, pos, currentPrice, reason); err != nil {
+			s.logger.Error(ctx, err, "Failed to close position based on strategy signal", map[string]interface{}{"positionID": pos.ID})
+			// Decide how to handle failure: retry? alert? For now, just log.
+			s.resyncOnClockSkew(err)
+			s.observeExchangeError(ctx, err)
 		}
-		// Note: SL/TP might be handled by exchange orders directly.
-		// If ShouldClosePosition also checks SL/TP, this covers it.
-		// If SL/TP are purely exchange-based, we might need order update events.
+		closeAttempted = true
+	}
+	if closeAttempted {
+		// Whether close succeeded or failed, we don't check for entry in the same event
+		return
 	}
 
+	// Track realized+unrealized equity for the kill switch
+	s.updateEquity(ctx, currentPrice)
+
+	// Add to positions whose price reached their next scale-in level
+	s.addScaleIns(ctx, currentPrice)
+
 	// --- Check Entry Conditions ---
-	if s.currentPosition == nil { // Only check entry if no position is open
-		canTradeNow, reason := s.canTrade(ctx)
+	for _, side := range s.signals.EntrySides() {
+		canTradeNow, reason := s.gate.Allow(side)
 		if !canTradeNow {
-			s.logger.Debug(ctx, "Cannot trade now", map[string]interface{}{"reason": reason})
-			return
+			s.logger.Debug(ctx, "Cannot trade now", map[string]interface{}{"side": side, "reason": reason})
+			continue
 		}
 
 		// Check strategy entry conditions
-		if s.strategy.ShouldEnterTrade(ctx, s.klineCache, currentPrice) {
-			s.logger.Info(ctx, "Strategy indicates a trade should be entered")
-			// Attempt to enter a position (assuming LONG for now)
-			err := s.enterPosition(ctx, currentPrice)
-			if err != nil {
-				s.logger.Error(ctx, err, "Failed to enter position based on strategy signal")
+		if s.signals.EntrySignal(ctx, side, currentPrice) {
+			s.logger.Info(ctx, "Strategy indicates a trade should be entered", map[string]interface{}{"side": side})
+			s.publish(ctx, ports.Event{Type: ports.EventSignalGenerated, Side: side, Price: currentPrice})
+			if ok, reason := s.checkLiquidity(ctx); !ok {
+				s.logger.Info(ctx, "Skipping entry due to insufficient liquidity", map[string]interface{}{"reason": reason})
+				return
+			}
+			if ok, reason := s.checkSpread(ctx); !ok {
+				s.logger.Info(ctx, "Skipping entry due to a wide spread", map[string]interface{}{"reason": reason})
+				return
+			}
+			err := s.enterPosition(ctx, side, currentPrice, kline.OpenTime)
+			if errors.Is(err, errInsufficientBalance) {
+				s.logger.Warn(ctx, "Skipping entry: insufficient balance", map[string]interface{}{"side": side, "reason": err.Error()})
+			} else if errors.Is(err, risk.ErrMaxExposure) {
+				s.logger.Warn(ctx, "Skipping entry: maximum exposure reached", map[string]interface{}{"side": side, "reason": err.Error()})
+			} else if err != nil {
+				s.logger.Error(ctx, err, "Failed to enter position based on strategy signal", map[string]interface{}{"side": side})
 				// Decide how to handle failure. Log for now.
+				s.resyncOnClockSkew(err)
+				s.observeExchangeError(ctx, err)
 			}
 			// Whether entry succeeded or failed, processing for this event is done.
 			return
@@ -302,6 +907,34 @@ func (s *TradingService) handleKlineEvent(kline *domain.Kline) {
 	}
 }
 
+// timeframeKlineHandler returns a stream handler that caches final klines of an additional
+// interval. Only primary interval klines trigger strategy evaluation.
+func (s *TradingService) timeframeKlineHandler(interval string) func(kline *domain.Kline) {
+	return func(kline *domain.Kline) {
+		if !kline.IsFinal {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.signals.AddTimeframeKline(interval, kline)
+	}
+}
+
+// now returns the current time from the service's clock.
+func (s *TradingService) now() time.Time {
+	return s.timeSource.Now()
+}
+
+// resyncOnClockSkew asks the clock monitor for an immediate drift check when the
+// exchange rejected a request because of its timestamp.
+func (s *TradingService) resyncOnClockSkew(err error) {
+	if s.clock != nil && errors.Is(err, ports.ErrClockSkew) {
+		s.clock.RequestCheck()
+	}
+}
+
 // handleWsError handles errors reported by the WebSocket stream.
 func (s *TradingService) handleWsError(err error) {
 	ctx := context.Background() // Use a background context for handlers
@@ -314,89 +947,93 @@ func (s *TradingService) handleWsError(err error) {
 
 // --- Private helper methods for trading actions ---
 
-// canTrade checks if the bot is currently allowed to open a new position.
-// NOTE: This method assumes the mutex `s.mu` is already locked by the caller (`handleKlineEvent`).
-func (s *TradingService) canTrade(ctx context.Context) (bool, string) {
-	// 1. Check if position is already open
-	if s.currentPosition != nil {
-		return false, fmt.Sprintf("position %d already open", s.currentPosition.ID)
+// checkLiquidity fetches the order book and applies the liquidity filter, if configured.
+// Fails closed: if the order book can't be fetched the entry is skipped.
+func (s *TradingService) checkLiquidity(ctx context.Context) (bool, string) {
+	if s.liquidity == nil {
+		return true, ""
 	}
-
-	// 2. Check daily trade limit
-	// We need to refresh tradesToday count from DB in case the bot restarted mid-day
-	// Or, trust the in-memory count if it's guaranteed to be accurate since start.
-	// Let's trust in-memory for now, but add a TODO to consider DB refresh.
-	// TODO: Consider refreshing tradesToday from DB periodically or on error?
-	if s.tradesToday >= s.cfg.MaxOrders {
-		return false, fmt.Sprintf("daily trade limit reached (%d/%d)", s.tradesToday, s.cfg.MaxOrders)
+	depth, err := s.exchange.GetOrderBookDepth(ctx, s.cfg.Symbol, s.liquidity.DepthLevels())
+	if err != nil {
+		s.logger.Error(ctx, err, "Failed to fetch order book depth for liquidity check")
+		return false, "failed to fetch order book depth"
 	}
+	return s.liquidity.Allow(depth)
+}
 
-	// 3. Check minimum balance (Optional but recommended)
-	// balance, err := s.exchange.GetAccountBalance(ctx, "USDT") // Assuming USDT balance
-	// if err != nil {
-	// 	s.logger.Error(ctx, err, "Failed to get account balance for canTrade check")
-	// 	return false, "failed to get balance" // Fail safe if balance check fails
-	// }
-	// minBalance := s.cfg.Quantity * currentPrice / float64(s.cfg.Leverage) // Rough estimate
-	// if balance < minBalance { // Add some buffer?
-	// 	return false, fmt.Sprintf("insufficient balance (%.2f) for estimated cost (%.2f)", balance, minBalance)
-	// }
-
-	return true, "" // All checks passed
+// formatPrice formats a price for the exchange, rounded to the configured tick size.
+func (s *TradingService) formatPrice(price float64) string {
+	return s.cfg.OrderPrecision().FormatPrice(price)
 }
 
-// formatPrice formats a float64 price into a string suitable for the Binance API.
-// TODO: Determine the correct precision required by the Binance API for the specific symbol.
-func formatPrice(price float64) string {
-	// Example: Format to 2 decimal places. Adjust precision as needed.
-	return strconv.FormatFloat(price, 'f', 2, 64)
+// formatQuantity formats a quantity for the exchange, rounded down to the configured step size.
+func (s *TradingService) formatQuantity(quantity float64) string {
+	return s.cfg.OrderPrecision().FormatQuantity(quantity)
 }
 
-// formatQuantity formats a float64 quantity into a string suitable for the Binance API.
-// TODO: Determine the correct precision required by the Binance API for the specific symbol.
-func formatQuantity(quantity float64) string {
-	// Example: Format to 3 decimal places for ETH. Adjust precision as needed.
-	return strconv.FormatFloat(quantity, 'f', 3, 64)
+// enterPosition opens a position on positionSide and places its SL/TP orders. It enters with a
+// market order, or rests a limit order if the strategy asks for one (see WithLimitEntries).
+// klineOpenTime identifies the signal: the entry order's client order ID is derived from it, so
+// the same kline can't open a position twice (see reconcileEntryIntents).
+func (s *TradingService) enterPosition(ctx context.Context, positionSide domain.PositionSide, entryPrice float64, klineOpenTime time.Time) error {
+	if order, ok := s.limitEntryOrder(ctx, positionSide, entryPrice); ok {
+		return s.placeLimitEntry(ctx, positionSide, order, klineOpenTime)
+	}
+	return s.enterWithMarketOrder(ctx, positionSide, entryPrice, klineOpenTime)
 }
 
-func (s *TradingService) enterPosition(ctx context.Context, entryPrice float64) error {
+// enterWithMarketOrder opens a position on positionSide with a market order and places its SL/TP orders.
+func (s *TradingService) enterWithMarketOrder(ctx context.Context, positionSide domain.PositionSide, entryPrice float64, klineOpenTime time.Time) error {
 	op := "enterPosition"
-	s.logger.Info(ctx, op+": Attempting to enter position", map[string]interface{}{"entryPrice": entryPrice})
+	s.logger.Info(ctx, op+": Attempting to enter position", map[string]interface{}{"side": positionSide, "entryPrice": entryPrice})
 
 	// --- Calculations ---
-	// 1. Quantity (Fixed from config)
-	quantity := s.cfg.Quantity
-	quantityStr := formatQuantity(quantity)
-
-	// 2. SL/TP Prices (Assuming LONG position based on strategy description)
-	// Strategy: Enter on uptrend -> LONG only for now.
-	// TODO: Add logic for SHORT positions if strategy requires it.
-	side := domain.Buy // Correct constant
-	slPrice := entryPrice * (1 - s.cfg.StopLoss)
-	tpPrice := entryPrice * (1 + s.cfg.MaxProfit) // Using MaxProfit as per user feedback
-	slPriceStr := formatPrice(slPrice)
-	tpPriceStr := formatPrice(tpPrice)
+	quantity, leverage, err := s.sizer.EntrySize(ctx, op, entryPrice)
+	if err != nil {
+		return err
+	}
+	quantityStr := s.formatQuantity(quantity)
+
+	// 2. SL/TP Prices: below/above entry for a long, mirrored for a short
+	side := positionSide.EntrySide()
+	slPrice, tpPrice := s.exitPrices(positionSide, entryPrice)
 
 	s.logger.Info(ctx, op+": Calculated parameters", map[string]interface{}{
 		"side":       side,
 		"quantity":   quantityStr,
-		"stopLoss":   slPriceStr,
-		"takeProfit": tpPriceStr,
+		"stopLoss":   s.formatPrice(slPrice),
+		"takeProfit": s.formatPrice(tpPrice),
 	})
 
 	// --- Order Placement ---
-	var entryOrder, slOrder, tpOrder *ports.OrderResponse
-	var err error
+	// 3. Record the entry before placing it, so a restart can tell whether the order went out
+	clientOrderID := domain.EntryClientOrderID(s.cfg.Symbol, positionSide, klineOpenTime)
+	intent, err := s.saveEntryIntent(ctx, clientOrderID, positionSide, klineOpenTime, quantity)
+	if err != nil {
+		return err
+	}
 
-	// 3. Place entry market order
-	s.logger.Info(ctx, op+": Placing entry market order...")
-	entryOrder, err = s.exchange.PlaceMarketOrder(ctx, s.cfg.Symbol, side, quantityStr)
+	// 3.1 Place entry market order
+	s.logger.Info(ctx, op+": Placing entry market order...", map[string]interface{}{"clientOrderID": clientOrderID})
+	entryOrder, err := s.positions.PlaceMarketOrder(ctx, positionSide, domain.OrderPurposeEntry, 0, quantityStr, clientOrderID)
 	if err != nil {
 		s.logger.Error(ctx, err, op+": Failed to place entry market order")
+		// The order may still have reached the exchange (e.g., on a timeout)
+		if intent != nil {
+			if recErr := s.reconcileEntryIntent(ctx, intent); recErr != nil {
+				s.logger.Warn(ctx, op+": Entry order outcome unknown, it is checked again on restart", map[string]interface{}{
+					"clientOrderID": clientOrderID,
+					"error":         recErr.Error(),
+				})
+			} else if s.positions.Position(positionSide) != nil {
+				return nil // The order filled after all and its position was adopted
+			}
+		}
 		return fmt.Errorf("entry market order failed: %w", err)
 	}
 	// Use the actual filled price if available, otherwise fallback to kline price
-	actualEntryPrice := entryOrder.AvgPrice
+	entryFills := s.orderFills(ctx, entryOrder, domain.FillRoleEntry)
+	actualEntryPrice := averageFillPrice(entryFills, entryOrder.AvgPrice)
 	if actualEntryPrice == 0 {
 		s.logger.Warn(ctx, op+": Entry order AvgPrice is 0, using kline close price as fallback", map[string]interface{}{"orderID": entryOrder.OrderID, "fallbackPrice": entryPrice})
 		actualEntryPrice = entryPrice
@@ -404,126 +1041,98 @@ func (s *TradingService) enterPosition(ctx context.Context, entryPrice float64)
 	} else {
 		s.logger.Info(ctx, op+": Entry order filled", map[string]interface{}{"orderID": entryOrder.OrderID, "avgPrice": actualEntryPrice})
 	}
+	s.sizer.RecordVolume(ctx, quantity, actualEntryPrice) // The fill counts even if protecting it fails
 
-	// 4. Place SL order (opposite side)
-	slSide := domain.Sell // Correct constant, opposite of entry
-	s.logger.Info(ctx, op+": Placing stop loss market order...")
-	slOrder, err = s.exchange.PlaceStopMarketOrder(ctx, s.cfg.Symbol, slSide, quantityStr, slPriceStr)
-	if err != nil {
-		s.logger.Error(ctx, err, op+": Failed to place stop loss order")
-		// Critical failure: We have an open position without a stop loss.
-		// Attempt to close the position immediately as a safety measure.
-		s.logger.Warn(ctx, op+": Attempting emergency close due to SL placement failure...")
-		closeErr := s.emergencyClose(ctx, actualEntryPrice, quantityStr, side)
-		if closeErr != nil {
-			s.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED")
-			// This is a very bad state. Manual intervention likely required.
-			// Consider alerting mechanisms here.
-		}
-		return fmt.Errorf("stop loss order failed after entry: %w (emergency close attempted)", err)
+	newPosition := &domain.Position{
+		Symbol:     s.cfg.Symbol,
+		Side:       positionSide,
+		EntryPrice: actualEntryPrice, // Use actual filled price
+		Quantity:   quantity,
+		Leverage:   leverage,
+		StopLoss:   slPrice,
+		TakeProfit: tpPrice,
+		EntryTime:  s.now().UTC(), // Use current time
+		Fees:       domain.SummarizeFills(entryFills).Commission,
+	}
+	if tagger, ok := s.signals.Strategy().(ports.EntryTagger); ok {
+		newPosition.EntryTag = tagger.LastEntryTag() // Record why we entered for later analysis
+	}
+	if s.sizer.ScaleIn().Enabled() {
+		newPosition.ScaleInBasePrice = actualEntryPrice // Adds are measured from the initial fill
+	}
+	err = s.protectPosition(ctx, op, newPosition, entryOrder.OrderID)
+	s.finishEntryIntent(ctx, intent, err == nil)
+	if err == nil {
+		s.saveOrderFills(ctx, newPosition.ID, entryFills)
+		s.guardSlippage(ctx, newPosition, entryPrice)
 	}
-	s.logger.Info(ctx, op+": Stop loss order placed", map[string]interface{}{"orderID": slOrder.OrderID, "stopPrice": slPriceStr})
+	return err
+}
 
-	// 5. Place TP order (opposite side)
-	tpSide := domain.Sell // Correct constant, opposite of entry
-	s.logger.Info(ctx, op+": Placing take profit market order...")
-	tpOrder, err = s.exchange.PlaceTakeProfitMarketOrder(ctx, s.cfg.Symbol, tpSide, quantityStr, tpPriceStr)
-	if err != nil {
-		s.logger.Error(ctx, err, op+": Failed to place take profit order")
-		// Less critical than SL failure, but still problematic.
-		// Cancel the SL order and close the position.
-		s.logger.Warn(ctx, op+": Attempting emergency close due to TP placement failure...")
-		cancelErr := s.cancelOrderWarn(ctx, s.cfg.Symbol, slOrder.OrderID, "SL")
-		if cancelErr != nil {
-			// Log but proceed with close attempt
-			s.logger.Error(ctx, cancelErr, op+": Failed to cancel SL order during TP failure cleanup")
-		}
-		closeErr := s.emergencyClose(ctx, actualEntryPrice, quantityStr, side)
-		if closeErr != nil {
-			s.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED after TP failure")
-		}
-		return fmt.Errorf("take profit order failed after entry: %w (emergency close attempted)", err)
+// exitPrices returns the SL/TP prices for an entry at entryPrice, rounded to the tick size:
+// below/above it for a long, mirrored for a short.
+func (s *TradingService) exitPrices(positionSide domain.PositionSide, entryPrice float64) (slPrice, tpPrice float64) {
+	precision := s.cfg.OrderPrecision() // Record the prices actually ordered
+	if positionSide == domain.PositionSideShort {
+		return precision.RoundPrice(entryPrice * (1 + s.cfg.StopLoss)), precision.RoundPrice(entryPrice * (1 - s.cfg.MaxProfit))
 	}
-	s.logger.Info(ctx, op+": Take profit order placed", map[string]interface{}{"orderID": tpOrder.OrderID, "stopPrice": tpPriceStr})
+	return precision.RoundPrice(entryPrice * (1 - s.cfg.StopLoss)), precision.RoundPrice(entryPrice * (1 + s.cfg.MaxProfit)) // Using MaxProfit as per user feedback
+}
 
-	// --- Persistence and State Update ---
-	// 6. Create domain.Position object
-	newPosition := &domain.Position{
-		Symbol:            s.cfg.Symbol,
-		EntryPrice:        actualEntryPrice, // Use actual filled price
-		Quantity:          quantity,
-		Leverage:          s.cfg.Leverage,
-		StopLoss:          slPrice,
-		TakeProfit:        tpPrice,
-		EntryTime:         time.Now().UTC(), // Use current time
-		Status:            domain.StatusOpen,
-		StopLossOrderID:   ptrToString(strconv.FormatInt(slOrder.OrderID, 10)), // Store order IDs
-		TakeProfitOrderID: ptrToString(strconv.FormatInt(tpOrder.OrderID, 10)),
-	}
-
-	// 7. Save position via posRepo.Create
-	posID, err := s.posRepo.Create(ctx, newPosition)
-	if err != nil {
-		s.logger.Error(ctx, err, op+": Failed to save new position to repository")
-		// This is also problematic. We have orders placed but no DB record.
-		// Attempt to cancel orders and close position.
-		s.logger.Warn(ctx, op+": Attempting emergency close due to DB save failure...")
-		cancelSlErr := s.cancelOrderWarn(ctx, s.cfg.Symbol, slOrder.OrderID, "SL")
-		cancelTpErr := s.cancelOrderWarn(ctx, s.cfg.Symbol, tpOrder.OrderID, "TP")
-		closeErr := s.emergencyClose(ctx, actualEntryPrice, quantityStr, side)
-		// Log all errors
-		if cancelSlErr != nil {
-			s.logger.Error(ctx, cancelSlErr, op+": Failed to cancel SL order during DB failure cleanup")
-		}
-		if cancelTpErr != nil {
-			s.logger.Error(ctx, cancelTpErr, op+": Failed to cancel TP order during DB failure cleanup")
-		}
-		if closeErr != nil {
-			s.logger.Error(ctx, closeErr, op+": EMERGENCY CLOSE FAILED after DB failure")
-		}
-		return fmt.Errorf("failed to save position to DB after placing orders: %w (emergency close attempted)", err)
+// protectPosition has the position manager protect, save and track a filled entry (see
+// PositionManager.Protect), then counts it as one of the day's trades and announces it.
+func (s *TradingService) protectPosition(ctx context.Context, op string, newPosition *domain.Position, entryOrderID int64) error {
+	if err := s.positions.Protect(ctx, op, newPosition, entryOrderID); err != nil {
+		return err
 	}
-	newPosition.ID = posID // Set the ID returned by the database
-	s.logger.Info(ctx, op+": New position saved to DB", map[string]interface{}{"positionID": newPosition.ID})
+	tradesToday := s.gate.RecordEntry()
+	s.logger.Info(ctx, op+": Internal state updated", map[string]interface{}{"tradesToday": tradesToday})
 
-	// 8. Update internal state
-	s.currentPosition = newPosition
-	s.tradesToday++
-	s.logger.Info(ctx, op+": Internal state updated", map[string]interface{}{"tradesToday": s.tradesToday})
+	s.publishPosition(ctx, ports.EventPositionOpened, newPosition)
+	if tradesToday == s.cfg.MaxOrders {
+		s.publish(ctx, ports.Event{Type: ports.EventRiskLimitBreached, Reason: fmt.Sprintf("daily trade limit reached (%d/%d)", tradesToday, s.cfg.MaxOrders)})
+	}
 
 	return nil // Position successfully entered
 }
 
-func (s *TradingService) closePosition(ctx context.Context, exitPrice float64, reason domain.CloseReason) error {
+// closePosition closes positionToClose with a market order, cancels its SL/TP orders and records the result.
+func (s *TradingService) closePosition(ctx context.Context, positionToClose *domain.Position, exitPrice float64, reason domain.CloseReason) error {
 	op := "closePosition"
-	if s.currentPosition == nil {
+	if positionToClose == nil {
 		s.logger.Warn(ctx, op+": Attempted to close position, but no position is currently open")
 		return fmt.Errorf("no open position to close")
 	}
 
-	positionToClose := s.currentPosition
+	side := positionToClose.PositionSide()
 	s.logger.Info(ctx, op+": Attempting to close position", map[string]interface{}{
 		"positionID": positionToClose.ID,
+		"side":       side,
 		"exitPrice":  exitPrice,
 		"reason":     reason,
 	})
 
 	// --- Order Placement and Cleanup ---
-	// 1. Determine closing side (opposite of entry)
-	// Assuming LONG entry for now
-	closeSide := domain.Sell // Correct constant
-	quantityStr := formatQuantity(positionToClose.Quantity)
+	// 0. Cancel the rest of a limit entry still filling into the position, adding its last fills,
+	// so nothing fills after the close
+	if pending := s.pendingLimit[side]; pending != nil && pending.position == positionToClose {
+		s.cancelLimitEntry(ctx, pending)
+	}
+
+	// 1. Quantity to close; the order goes to the opposite side of the entry
+	quantityStr := s.formatQuantity(positionToClose.Quantity)
 
 	// 2. Place market order to close
 	s.logger.Info(ctx, op+": Placing closing market order...")
-	closeOrder, err := s.exchange.PlaceMarketOrder(ctx, s.cfg.Symbol, closeSide, quantityStr)
+	closeOrder, err := s.positions.PlaceMarketOrder(ctx, side, domain.OrderPurposeExit, positionToClose.ID, quantityStr, "")
 	if err != nil {
 		s.logger.Error(ctx, err, op+": Failed to place closing market order", map[string]interface{}{"positionID": positionToClose.ID})
 		// If closing fails, the position remains open. SL/TP orders should still be active.
 		// Log the error and return. Manual intervention might be needed if it persists.
 		return fmt.Errorf("failed to place closing market order for position %d: %w", positionToClose.ID, err)
 	}
-	actualExitPrice := closeOrder.AvgPrice
+	closeFills := s.orderFills(ctx, closeOrder, domain.FillRoleExit)
+	actualExitPrice := averageFillPrice(closeFills, closeOrder.AvgPrice)
 	if actualExitPrice == 0 {
 		s.logger.Warn(ctx, op+": Close order AvgPrice is 0, using kline close price as fallback", map[string]interface{}{"orderID": closeOrder.OrderID, "fallbackPrice": exitPrice})
 		actualExitPrice = exitPrice
@@ -531,86 +1140,43 @@ func (s *TradingService) closePosition(ctx context.Context, exitPrice float64, r
 	s.logger.Info(ctx, op+": Closing market order placed successfully", map[string]interface{}{"orderID": closeOrder.OrderID, "avgPrice": actualExitPrice})
 
 	// 3. Cancel existing SL/TP orders (Important!)
-	// Use helper to log warnings instead of failing the whole close operation if cancellation fails
-	if positionToClose.StopLossOrderID != nil {
-		slOrderID, _ := strconv.ParseInt(*positionToClose.StopLossOrderID, 10, 64)
-		_ = s.cancelOrderWarn(ctx, s.cfg.Symbol, slOrderID, "SL")
+	// Failed cancellations are only logged instead of failing the whole close operation
+	s.positions.CancelProtectiveOrders(ctx, positionToClose)
+
+	// --- Persistence and State Update ---
+	// 4-5. Mark the domain.Position closed; Close calculates the side-aware PNL, net of the
+	// commissions of the recorded fills and of the funding and commissions booked while open
+	s.accrueIncome(ctx, positionToClose)
+	if s.fillRepo != nil {
+		positionToClose.Fees = s.positionFees(ctx, positionToClose) + domain.SummarizeFills(closeFills).Commission
 	}
-	if positionToClose.TakeProfitOrderID != nil {
-		tpOrderID, _ := strconv.ParseInt(*positionToClose.TakeProfitOrderID, 10, 64)
-		_ = s.cancelOrderWarn(ctx, s.cfg.Symbol, tpOrderID, "TP")
+	if err := positionToClose.Close(actualExitPrice, s.now().UTC(), reason); err != nil {
+		s.logger.Error(ctx, err, op+": Failed to mark position closed", map[string]interface{}{"positionID": positionToClose.ID})
+		return fmt.Errorf("failed to close position %d: %w", positionToClose.ID, err)
 	}
+	pnl := positionToClose.PNL
+	s.realizedPnL += pnl
+	s.logger.Info(ctx, op+": Calculated PNL", map[string]interface{}{"positionID": positionToClose.ID, "pnl": pnl, "fees": positionToClose.Fees})
 
-	// --- Persistence and State Update ---
-	// 4. Calculate PNL
-	// Simple PNL calculation (assuming LONG position)
-	// TODO: Refine PNL calculation (consider fees, funding rates if applicable)
-	pnl := (actualExitPrice - positionToClose.EntryPrice) * positionToClose.Quantity
-	s.logger.Info(ctx, op+": Calculated PNL", map[string]interface{}{"positionID": positionToClose.ID, "pnl": pnl})
-
-	// 5. Update domain.Position object
-	positionToClose.ExitPrice = actualExitPrice
-	positionToClose.ExitTime = time.Now().UTC()
-	positionToClose.Status = domain.StatusClosed
-	positionToClose.PNL = pnl
-	positionToClose.CloseReason = reason
-
-	// 6. Save updated position via posRepo.Update
-	err = s.posRepo.Update(ctx, positionToClose)
+	// 6. Save updated position
+	err = s.positions.Save(ctx, positionToClose)
 	if err != nil {
 		// Log error and return it since this is a critical operation
 		s.logger.Error(ctx, err, op+": Failed to update closed position in repository", map[string]interface{}{"positionID": positionToClose.ID})
 		return fmt.Errorf("failed to update closed position in repository: %w", err)
 	}
 	s.logger.Info(ctx, op+": Closed position updated in DB", map[string]interface{}{"positionID": positionToClose.ID})
+	s.saveOrderFills(ctx, positionToClose.ID, closeFills)
 
 	// 7. Update internal state
-	s.currentPosition = nil
+	s.positions.Untrack(side)
+	s.recordExit(ctx, positionToClose)
 	s.logger.Info(ctx, op+": Position closed successfully, internal state updated", map[string]interface{}{"positionID": positionToClose.ID})
 
-	return nil // Position successfully closed
-}
-
-// emergencyClose places a market order to close the current exposure.
-// Assumes entrySide was the side used to open the position.
-// Used when SL/TP placement fails after entry.
-func (s *TradingService) emergencyClose(ctx context.Context, entryPrice float64, quantityStr string, entrySide domain.OrderSide) error {
-	op := "emergencyClose"
-	closeSide := domain.Sell      // Correct constant
-	if entrySide == domain.Sell { // Correct constant
-		closeSide = domain.Buy // Correct constant
-	}
-	s.logger.Warn(ctx, op+": Placing emergency closing order", map[string]interface{}{"side": closeSide, "quantity": quantityStr})
-	_, err := s.exchange.PlaceMarketOrder(ctx, s.cfg.Symbol, closeSide, quantityStr)
-	if err != nil {
-		s.logger.Error(ctx, err, op+": FAILED TO PLACE EMERGENCY CLOSE ORDER")
-		return fmt.Errorf("emergency close order placement failed: %w", err)
-	}
-	s.logger.Info(ctx, op+": Emergency close order placed successfully")
-	// Note: This does not update DB state, as the position might not have been saved yet.
-	// It's purely a safety mechanism on the exchange side.
-	return nil
-}
+	s.publishPosition(ctx, ports.EventPositionClosed, positionToClose)
 
-// cancelOrderWarn attempts to cancel an order and logs a warning on failure.
-func (s *TradingService) cancelOrderWarn(ctx context.Context, symbol string, orderID int64, orderType string) error {
-	op := "cancelOrderWarn"
-	s.logger.Info(ctx, op+": Attempting to cancel order", map[string]interface{}{"symbol": symbol, "orderID": orderID, "type": orderType})
-	_, err := s.exchange.CancelOrder(ctx, symbol, orderID)
-	if err != nil {
-		// Ignore "Order does not exist" errors, as it might have already been filled or cancelled.
-		if errors.Is(err, ports.ErrOrderNotFound) {
-			s.logger.Warn(ctx, op+": Order not found, likely already filled or cancelled", map[string]interface{}{"orderID": orderID, "type": orderType})
-			return nil // Not an error in this context
-		}
-		s.logger.Error(ctx, err, op+": Failed to cancel order", map[string]interface{}{"orderID": orderID, "type": orderType})
-		return err // Return other errors
-	}
-	s.logger.Info(ctx, op+": Order cancelled successfully", map[string]interface{}{"orderID": orderID, "type": orderType})
-	return nil
-}
+	// 8. Persist strategy state so loss counters survive a restart
+	s.persistStrategyState(ctx)
 
-// ptrToString converts a string to a pointer to a string.
-func ptrToString(s string) *string {
-	return &s
+	return nil // Position successfully closed
 }
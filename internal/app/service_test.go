@@ -2,7 +2,9 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,9 +12,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/clock"
 	"cryptoMegaBot/internal/domain"
 	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
 	"cryptoMegaBot/internal/strategy"
+	"cryptoMegaBot/internal/strategy/strategies"
 )
 
 // Mock implementations
@@ -61,20 +66,116 @@ func (m *mockStrategy) ShouldClosePosition(ctx context.Context, position *domain
 	return m.shouldClose, m.closeReason
 }
 
+// mockMultiTimeframeStrategy extends mockStrategy with additional timeframes
+type mockMultiTimeframeStrategy struct {
+	mockStrategy
+	timeframes []string
+	data       map[string][]*domain.Kline
+}
+
+func (m *mockMultiTimeframeStrategy) Timeframes() []string {
+	return m.timeframes
+}
+
+func (m *mockMultiTimeframeStrategy) SetTimeframeData(klines map[string][]*domain.Kline) {
+	m.data = klines
+}
+
+// mockStatefulStrategy extends mockStrategy with state persistence hooks
+type mockStatefulStrategy struct {
+	mockStrategy
+	state []byte
+}
+
+func (m *mockStatefulStrategy) Name() string {
+	return "MockStateful"
+}
+
+func (m *mockStatefulStrategy) SaveState(ctx context.Context) ([]byte, error) {
+	return m.state, nil
+}
+
+func (m *mockStatefulStrategy) LoadState(ctx context.Context, data []byte) error {
+	m.state = data
+	return nil
+}
+
+// mockShortStrategy extends mockStrategy with short entry signals
+type mockShortStrategy struct {
+	mockStrategy
+	shouldShort bool
+}
+
+func (m *mockShortStrategy) ShouldEnterShort(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	return m.shouldShort
+}
+
+type mockStateRepo struct {
+	states  map[string][]byte
+	loadErr error
+}
+
+func (m *mockStateRepo) SaveStrategyState(ctx context.Context, strategyName, symbol string, state []byte) error {
+	m.states[strategyName+"/"+symbol] = state
+	return nil
+}
+
+func (m *mockStateRepo) LoadStrategyState(ctx context.Context, strategyName, symbol string) ([]byte, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	return m.states[strategyName+"/"+symbol], nil
+}
+
 type mockExchange struct {
 	serverTimeErr   error
 	leverageErr     error
+	marginTypeErr   error
+	marginTypeCalls int
+	positionModeErr error
+	hedgeMode       *bool // Mode requested by the last SetPositionMode call
 	markPrice       float64
 	markPriceErr    error
 	orderResponses  map[string]*ports.OrderResponse
 	orderErrors     map[string]error
 	klines          []*domain.Kline
 	klinesErr       error
+	klineLimits     []int // Limits of GetKlines calls
 	positionRisk    *ports.PositionRisk
+	positionRisks   []*ports.PositionRisk // Returned by successive GetPositionRisks calls before positionRisk
+	otherSideRisk   *ports.PositionRisk   // Other hedge mode side, returned alongside by every call
 	positionRiskErr error
 	serverTime      time.Time
 	balance         float64
 	balanceErr      error
+	depth           *ports.OrderBookDepth
+	depthErr        error
+	marketOrderQty  string
+	positionSides   []domain.PositionSide // Position sides of placed market orders
+	clientOrderIDs  []string              // Client order IDs of placed market orders
+	limitPrices     []string              // Prices of placed limit orders
+	tickerStats     *ports.TickerStats
+	tickerStatsErr  error
+	bookTicker      *ports.BookTicker
+	bookTickerErr   error
+	income          []ports.IncomeRecord
+	incomeErr       error
+	openInterest    []*domain.OpenInterest
+	openInterestErr error
+	oiFetches       int
+	ordersByClient  map[string]*ports.OrderResponse
+	getOrderErr     error
+	openOrders      []*ports.OrderResponse
+	openOrdersErr   error
+	canceledOrders  []int64  // IDs passed to CancelOrder
+	reduceOnlyQty   []string // Quantities of placed reduce-only market orders
+	stopQty         []string // Quantities of placed stop loss orders
+	stopErrs        []error  // Returned by successive stop loss orders before orderErrors
+	pingErr         error
+
+	mu                sync.Mutex
+	klineIntervals    []string // Intervals requested from GetKlines
+	streamedIntervals []string // Intervals passed to StreamKlines
 }
 
 func (m *mockExchange) GetServerTime(ctx context.Context) (time.Time, error) {
@@ -101,45 +202,143 @@ func (m *mockExchange) SetLeverage(ctx context.Context, symbol string, leverage
 	return m.leverageErr
 }
 
-func (m *mockExchange) PlaceMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string) (*ports.OrderResponse, error) {
+func (m *mockExchange) ChangeMarginType(ctx context.Context, symbol string, marginType domain.MarginType) error {
+	m.marginTypeCalls++
+	return m.marginTypeErr
+}
+
+func (m *mockExchange) SetPositionMode(ctx context.Context, hedgeMode bool) error {
+	m.hedgeMode = &hedgeMode
+	return m.positionModeErr
+}
+
+func (m *mockExchange) PlaceMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, clientOrderID string) (*ports.OrderResponse, error) {
 	key := "market_" + string(side)
+	m.marketOrderQty = quantity
+	m.positionSides = append(m.positionSides, positionSide)
+	m.clientOrderIDs = append(m.clientOrderIDs, clientOrderID)
+	return m.orderResponses[key], m.orderErrors[key]
+}
+
+func (m *mockExchange) PlaceLimitOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, price string, clientOrderID string) (*ports.OrderResponse, error) {
+	key := "limit_" + string(side)
+	m.limitPrices = append(m.limitPrices, price)
 	return m.orderResponses[key], m.orderErrors[key]
 }
 
-func (m *mockExchange) PlaceStopMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
+func (m *mockExchange) GetTickerStats(ctx context.Context, symbol string) (*ports.TickerStats, error) {
+	return m.tickerStats, m.tickerStatsErr
+}
+
+func (m *mockExchange) GetBookTicker(ctx context.Context, symbol string) (*ports.BookTicker, error) {
+	return m.bookTicker, m.bookTickerErr
+}
+
+func (m *mockExchange) GetIncomeHistory(ctx context.Context, symbol, incomeType string, start, end time.Time) ([]ports.IncomeRecord, error) {
+	var records []ports.IncomeRecord
+	for _, r := range m.income {
+		if !r.Time.Before(start) && !r.Time.After(end) {
+			records = append(records, r)
+		}
+	}
+	return records, m.incomeErr
+}
+
+func (m *mockExchange) GetOpenInterestHistory(ctx context.Context, symbol, period string, start, end time.Time) ([]*domain.OpenInterest, error) {
+	m.oiFetches++
+	return m.openInterest, m.openInterestErr
+}
+
+func (m *mockExchange) GetOrderByClientID(ctx context.Context, symbol string, clientOrderID string) (*ports.OrderResponse, error) {
+	if m.getOrderErr != nil {
+		return nil, m.getOrderErr
+	}
+	if order, ok := m.ordersByClient[clientOrderID]; ok {
+		return order, nil
+	}
+	return nil, ports.ErrOrderNotFound
+}
+
+func (m *mockExchange) ListOpenOrders(ctx context.Context, symbol string) ([]*ports.OrderResponse, error) {
+	return m.openOrders, m.openOrdersErr
+}
+
+func (m *mockExchange) PlaceStopMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
 	key := "stop_" + string(side)
+	m.stopQty = append(m.stopQty, quantity)
+	if len(m.stopErrs) > 0 {
+		err := m.stopErrs[0]
+		m.stopErrs = m.stopErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
 	return m.orderResponses[key], m.orderErrors[key]
 }
 
-func (m *mockExchange) PlaceTakeProfitMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
+func (m *mockExchange) PlaceTakeProfitMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
 	key := "tp_" + string(side)
 	return m.orderResponses[key], m.orderErrors[key]
 }
 
-func (m *mockExchange) GetPositionRisk(ctx context.Context, symbol string) (*ports.PositionRisk, error) {
+func (m *mockExchange) GetPositionRisks(ctx context.Context, symbol string) ([]*ports.PositionRisk, error) {
 	if m.positionRiskErr != nil {
 		return nil, m.positionRiskErr
 	}
-	return m.positionRisk, nil
+	risk := m.positionRisk
+	if len(m.positionRisks) > 0 {
+		risk = m.positionRisks[0]
+		m.positionRisks = m.positionRisks[1:]
+	}
+	var risks []*ports.PositionRisk
+	for _, r := range []*ports.PositionRisk{risk, m.otherSideRisk} {
+		if r != nil {
+			risks = append(risks, r)
+		}
+	}
+	return risks, nil
+}
+
+func (m *mockExchange) PlaceReduceOnlyMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string) (*ports.OrderResponse, error) {
+	m.reduceOnlyQty = append(m.reduceOnlyQty, quantity)
+	key := "reduce_" + string(side)
+	return m.orderResponses[key], m.orderErrors[key]
 }
 
 func (m *mockExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) (*ports.OrderResponse, error) {
 	key := "cancel_" + strconv.FormatInt(orderID, 10)
+	m.canceledOrders = append(m.canceledOrders, orderID)
 	return m.orderResponses[key], m.orderErrors[key]
 }
 
 func (m *mockExchange) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]*domain.Kline, error) {
+	m.mu.Lock()
+	m.klineIntervals = append(m.klineIntervals, interval)
+	m.klineLimits = append(m.klineLimits, limit)
+	m.mu.Unlock()
 	return m.klines, m.klinesErr
 }
 
 func (m *mockExchange) StreamKlines(ctx context.Context, symbol string, interval string, klineHandler func(*domain.Kline), errorHandler func(error)) (chan struct{}, chan struct{}, error) {
+	m.mu.Lock()
+	m.streamedIntervals = append(m.streamedIntervals, interval)
+	m.mu.Unlock()
+
 	doneCh := make(chan struct{})
-	stopCh := make(chan struct{})
+	stopCh := make(chan struct{}, 1)
+	go func() {
+		<-stopCh
+		close(doneCh)
+	}()
 	return doneCh, stopCh, nil
 }
 
+func (m *mockExchange) GetOrderBookDepth(ctx context.Context, symbol string, limit int) (*ports.OrderBookDepth, error) {
+	return m.depth, m.depthErr
+}
+
 func (m *mockExchange) Ping(ctx context.Context) error {
-	return nil
+	return m.pingErr
 }
 
 type mockPositionRepo struct {
@@ -158,7 +357,7 @@ func (m *mockPositionRepo) Create(ctx context.Context, pos *domain.Position) (in
 		return 0, m.createErr
 	}
 	pos.ID = 1 // Assign test ID
-	m.positions[pos.Symbol] = pos
+	m.positions[positionKey(pos.Symbol, pos.PositionSide())] = pos
 	return pos.ID, nil
 }
 
@@ -166,10 +365,18 @@ func (m *mockPositionRepo) Update(ctx context.Context, pos *domain.Position) err
 	if m.updateErr != nil {
 		return m.updateErr
 	}
-	m.positions[pos.Symbol] = pos
+	m.positions[positionKey(pos.Symbol, pos.PositionSide())] = pos
 	return nil
 }
 
+// positionKey stores longs under the bare symbol and shorts under symbol/SHORT.
+func positionKey(symbol string, side domain.PositionSide) string {
+	if side == domain.PositionSideShort {
+		return symbol + "/" + string(side)
+	}
+	return symbol
+}
+
 func (m *mockPositionRepo) FindOpenBySymbol(ctx context.Context, symbol string) (*domain.Position, error) {
 	if m.findOpenErr != nil {
 		return nil, m.findOpenErr
@@ -181,6 +388,17 @@ func (m *mockPositionRepo) FindOpenBySymbol(ctx context.Context, symbol string)
 	return nil, nil
 }
 
+func (m *mockPositionRepo) FindOpenBySymbolAndSide(ctx context.Context, symbol string, side domain.PositionSide) (*domain.Position, error) {
+	if m.findOpenErr != nil {
+		return nil, m.findOpenErr
+	}
+	pos := m.positions[positionKey(symbol, side)]
+	if pos != nil && pos.Status == domain.StatusOpen {
+		return pos, nil
+	}
+	return nil, nil
+}
+
 func (m *mockPositionRepo) FindByID(ctx context.Context, id int64) (*domain.Position, error) {
 	if m.findByIDErr != nil {
 		return nil, m.findByIDErr
@@ -226,6 +444,19 @@ func (m *mockTradeRepo) CountTodayBySymbol(ctx context.Context, symbol string) (
 	return m.todayCount, m.todayCountErr
 }
 
+func (m *mockTradeRepo) FindClosedBetween(ctx context.Context, symbol string, from, to time.Time) ([]*domain.Position, error) {
+	if m.findClosedErr != nil {
+		return nil, m.findClosedErr
+	}
+	var positions []*domain.Position
+	for _, pos := range m.trades {
+		if !pos.ExitTime.Before(from) && pos.ExitTime.Before(to) {
+			positions = append(positions, pos)
+		}
+	}
+	return positions, nil
+}
+
 func TestNewTradingService(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -359,7 +590,7 @@ func TestTradingService_handleKlineEvent(t *testing.T) {
 				// No setup needed for this test
 			},
 			checkState: func(t *testing.T, s *TradingService) {
-				assert.Nil(t, s.currentPosition)
+				assert.Nil(t, s.positions.long)
 			},
 			wantPosition: false,
 		},
@@ -421,9 +652,9 @@ func TestTradingService_handleKlineEvent(t *testing.T) {
 				t.todayCount = 2 // Below max orders
 			},
 			checkState: func(t *testing.T, s *TradingService) {
-				assert.NotNil(t, s.currentPosition)
-				assert.Equal(t, domain.StatusOpen, s.currentPosition.Status)
-				assert.Equal(t, 2005.0, s.currentPosition.EntryPrice)
+				assert.NotNil(t, s.positions.long)
+				assert.Equal(t, domain.StatusOpen, s.positions.long.Status)
+				assert.Equal(t, 2005.0, s.positions.long.EntryPrice)
 			},
 			wantPosition: true,
 		},
@@ -470,7 +701,7 @@ func TestTradingService_handleKlineEvent(t *testing.T) {
 				}
 			},
 			checkState: func(t *testing.T, s *TradingService) {
-				assert.Nil(t, s.currentPosition)
+				assert.Nil(t, s.positions.long)
 			},
 			wantPosition: false,
 		},
@@ -493,7 +724,7 @@ func TestTradingService_handleKlineEvent(t *testing.T) {
 				e.balanceErr = nil
 			},
 			checkState: func(t *testing.T, s *TradingService) {
-				assert.Nil(t, s.currentPosition)
+				assert.Nil(t, s.positions.long)
 			},
 			wantPosition: false,
 		},
@@ -515,7 +746,7 @@ func TestTradingService_handleKlineEvent(t *testing.T) {
 				e.balanceErr = assert.AnError
 			},
 			checkState: func(t *testing.T, s *TradingService) {
-				assert.Nil(t, s.currentPosition)
+				assert.Nil(t, s.positions.long)
 			},
 			wantPosition: false,
 		},
@@ -551,7 +782,7 @@ func TestTradingService_handleKlineEvent(t *testing.T) {
 	}
 }
 
-func TestTradingService_canTrade(t *testing.T) {
+func TestEntryGate_Allow(t *testing.T) {
 	cfg := &config.Config{
 		Symbol:    "ETHUSDT",
 		Quantity:  0.1,
@@ -570,8 +801,8 @@ func TestTradingService_canTrade(t *testing.T) {
 		{
 			name: "can trade - all conditions met",
 			mockSetup: func(s *TradingService) {
-				s.currentPosition = nil
-				s.tradesToday = 0
+				s.positions.long = nil
+				s.gate.tradesToday = 0
 			},
 			wantCan:    true,
 			wantReason: "",
@@ -579,7 +810,7 @@ func TestTradingService_canTrade(t *testing.T) {
 		{
 			name: "cannot trade - position already open",
 			mockSetup: func(s *TradingService) {
-				s.currentPosition = &domain.Position{
+				s.positions.long = &domain.Position{
 					ID:     1,
 					Symbol: "ETHUSDT",
 					Status: domain.StatusOpen,
@@ -591,12 +822,22 @@ func TestTradingService_canTrade(t *testing.T) {
 		{
 			name: "cannot trade - daily limit reached",
 			mockSetup: func(s *TradingService) {
-				s.currentPosition = nil
-				s.tradesToday = 5
+				s.positions.long = nil
+				s.gate.tradesToday = 5
 			},
 			wantCan:    false,
 			wantReason: "daily trade limit reached (5/5)",
 		},
+		{
+			name: "cannot trade - short-only trade direction",
+			mockSetup: func(s *TradingService) {
+				shortOnly := *s.cfg
+				shortOnly.Direction = domain.TradeDirectionShort
+				s.gate.cfg = &shortOnly
+			},
+			wantCan:    false,
+			wantReason: "LONG entries disabled by TRADE_DIRECTION=short",
+		},
 	}
 
 	for _, tt := range tests {
@@ -614,8 +855,8 @@ func TestTradingService_canTrade(t *testing.T) {
 			// Setup test state
 			tt.mockSetup(service)
 
-			// Test canTrade
-			can, reason := service.canTrade(context.Background())
+			// Ask the entry gate
+			can, reason := service.gate.Allow(domain.PositionSideLong)
 			assert.Equal(t, tt.wantCan, can)
 			assert.Equal(t, tt.wantReason, reason)
 		})
@@ -627,6 +868,7 @@ func TestTradingService_Start(t *testing.T) {
 		name            string
 		serverTimeErr   error
 		leverageErr     error
+		marginTypeErr   error
 		findOpenErr     error
 		countTodayErr   error
 		klinesErr       error
@@ -693,6 +935,21 @@ func TestTradingService_Start(t *testing.T) {
 			openPosition:    nil,
 			serverTime:      time.Now(),
 		},
+		{
+			name:           "margin type change failure",
+			marginTypeErr:  ports.ErrInvalidRequest,
+			klines:         generateTestKlines(100),
+			expectedError:  true,
+			expectedErrMsg: "failed to set margin type",
+			serverTime:     time.Now(),
+		},
+		{
+			name:          "margin type already set",
+			marginTypeErr: ports.ErrNoChangeNeeded,
+			klines:        generateTestKlines(100),
+			todayCount:    1,
+			expectedError: false,
+		},
 		{
 			name:   "successful start with existing position and leverage",
 			klines: generateTestKlines(100),
@@ -717,6 +974,7 @@ func TestTradingService_Start(t *testing.T) {
 				serverTime:      tt.serverTime,
 				serverTimeErr:   tt.serverTimeErr,
 				leverageErr:     tt.leverageErr,
+				marginTypeErr:   tt.marginTypeErr,
 				klines:          tt.klines,
 				klinesErr:       tt.klinesErr,
 				positionRisk:    tt.positionRisk,
@@ -739,12 +997,13 @@ func TestTradingService_Start(t *testing.T) {
 
 			// Create service
 			cfg := &config.Config{
-				Symbol:    "ETHUSDT",
-				Quantity:  0.1,
-				StopLoss:  0.02,
-				MaxProfit: 0.05,
-				MaxOrders: 5,
-				Leverage:  10,
+				Symbol:     "ETHUSDT",
+				Quantity:   0.1,
+				StopLoss:   0.02,
+				MaxProfit:  0.05,
+				MaxOrders:  5,
+				Leverage:   10,
+				MarginType: domain.MarginTypeIsolated,
 			}
 
 			svc, err := NewTradingService(cfg, logger, exchange, posRepo, tradeRepo, strat)
@@ -779,6 +1038,8 @@ func TestTradingService_Start(t *testing.T) {
 			// Verify logs based on scenario
 			if tt.serverTimeErr != nil {
 				assert.Contains(t, logger.errorMsgs, "Failed to synchronize server time")
+			} else if tt.marginTypeErr != nil && tt.expectedError {
+				assert.Contains(t, logger.errorMsgs, "ensureMarginType: failed to change margin type")
 			} else if tt.openPosition != nil {
 				assert.Contains(t, logger.infoMsgs, "Found existing open position")
 			} else if tt.positionRiskErr == nil {
@@ -1003,14 +1264,14 @@ func TestTradingService_enterPosition(t *testing.T) {
 				tt.mockSetup(exchange, posRepo)
 			}
 
-			err = service.enterPosition(context.Background(), tt.entryPrice)
+			err = service.enterPosition(context.Background(), domain.PositionSideLong, tt.entryPrice, time.Now())
 			if tt.expectedError {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedErrMsg)
 			} else {
 				assert.NoError(t, err)
-				assert.NotNil(t, service.currentPosition)
-				assert.Equal(t, domain.StatusOpen, service.currentPosition.Status)
+				assert.NotNil(t, service.positions.long)
+				assert.Equal(t, domain.StatusOpen, service.positions.long.Status)
 			}
 		})
 	}
@@ -1120,22 +1381,436 @@ func TestTradingService_closePosition(t *testing.T) {
 				StopLossOrderID:   ptrToString("2"),
 				TakeProfitOrderID: ptrToString("3"),
 			}
-			service.currentPosition = pos
+			service.positions.long = pos
 			posRepo.positions["ETHUSDT"] = pos
 
 			if tt.mockSetup != nil {
 				tt.mockSetup(exchange, posRepo)
 			}
 
-			err = service.closePosition(context.Background(), tt.exitPrice, tt.closeReason)
+			err = service.closePosition(context.Background(), service.positions.long, tt.exitPrice, tt.closeReason)
 			if tt.expectedError {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.expectedErrMsg)
-				assert.NotNil(t, service.currentPosition) // Position should not be nil on error
+				assert.NotNil(t, service.positions.long) // Position should not be nil on error
 			} else {
 				assert.NoError(t, err)
-				assert.Nil(t, service.currentPosition)
+				assert.Nil(t, service.positions.long)
 			}
 		})
 	}
 }
+
+func TestTradingService_ensureMarginType(t *testing.T) {
+	tests := []struct {
+		name          string
+		marginType    domain.MarginType
+		positionRisk  *ports.PositionRisk
+		marginTypeErr error
+		expectedCalls int
+		expectedError bool
+	}{
+		{
+			name:          "margin type not configured",
+			marginType:    "",
+			expectedCalls: 0,
+		},
+		{
+			name:          "already in configured mode",
+			marginType:    domain.MarginTypeIsolated,
+			positionRisk:  &ports.PositionRisk{Symbol: "ETHUSDT", MarginType: domain.MarginTypeIsolated},
+			expectedCalls: 0,
+		},
+		{
+			name:          "change required",
+			marginType:    domain.MarginTypeIsolated,
+			positionRisk:  &ports.PositionRisk{Symbol: "ETHUSDT", MarginType: domain.MarginTypeCrossed},
+			expectedCalls: 1,
+		},
+		{
+			name:          "exchange reports no change needed",
+			marginType:    domain.MarginTypeCrossed,
+			marginTypeErr: fmt.Errorf("ChangeMarginType failed: %w", ports.ErrNoChangeNeeded),
+			expectedCalls: 1,
+		},
+		{
+			name:          "change fails",
+			marginType:    domain.MarginTypeCrossed,
+			marginTypeErr: ports.ErrInvalidRequest,
+			expectedCalls: 1,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Symbol:     "ETHUSDT",
+				MarginType: tt.marginType,
+				Quantity:   0.1,
+				StopLoss:   0.01,
+				MaxProfit:  0.02,
+				MaxOrders:  5,
+			}
+			exchange := &mockExchange{marginTypeErr: tt.marginTypeErr}
+			service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{})
+			require.NoError(t, err)
+
+			err = service.ensureMarginType(context.Background(), tt.positionRisk)
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedCalls, exchange.marginTypeCalls)
+		})
+	}
+}
+
+func TestTradingService_StrategyStatePersistence(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	stateRepo := &mockStateRepo{
+		states: map[string][]byte{"MockStateful/ETHUSDT": []byte(`{"dailyLossCount":2}`)},
+	}
+	strat := &mockStatefulStrategy{}
+
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, strat,
+		WithStateRepository(stateRepo))
+	require.NoError(t, err)
+
+	// Restore loads the saved state into the strategy
+	service.restoreStrategyState(context.Background())
+	assert.Equal(t, `{"dailyLossCount":2}`, string(strat.state))
+
+	// Persist writes the strategy's current state back
+	strat.state = []byte(`{"dailyLossCount":3}`)
+	service.persistStrategyState(context.Background())
+	assert.Equal(t, `{"dailyLossCount":3}`, string(stateRepo.states["MockStateful/ETHUSDT"]))
+
+	// Load failures leave the strategy with fresh state
+	stateRepo.loadErr = assert.AnError
+	strat.state = nil
+	service.restoreStrategyState(context.Background())
+	assert.Nil(t, strat.state)
+}
+
+func TestTradingService_KillSwitch(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	ks := risk.NewKillSwitch(risk.KillSwitchConfig{MaxDrawdown: 0.1, CoolDown: time.Hour})
+	logger := &mockLogger{}
+
+	service, err := NewTradingService(cfg, logger, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+		WithKillSwitch(ks))
+	require.NoError(t, err)
+	service.startingEquity = 1000
+
+	// Open position losing 150 USDT unrealized (15% drawdown)
+	service.positions.long = &domain.Position{ID: 1, EntryPrice: 2000, Quantity: 1, Status: domain.StatusOpen}
+	service.updateEquity(context.Background(), 2000)
+	service.updateEquity(context.Background(), 1850)
+	assert.Contains(t, logger.warnMsgs, "Kill switch tripped, pausing new entries")
+
+	service.positions.long = nil
+	can, reason := service.gate.Allow(domain.PositionSideLong)
+	assert.False(t, can)
+	assert.Contains(t, reason, "kill switch active")
+
+	status := service.Status(context.Background())
+	require.NotNil(t, status.KillSwitch)
+	assert.True(t, status.KillSwitch.Tripped)
+
+	// Manual resume allows entries again
+	require.NoError(t, service.ResumeTrading(context.Background()))
+	can, _ = service.gate.Allow(domain.PositionSideLong)
+	assert.True(t, can)
+}
+
+func TestTradingService_DrawdownThrottle(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	rm := risk.NewRiskManager(risk.RiskConfig{DrawdownThrottle: risk.DefaultDrawdownThrottle})
+	exchange := &mockExchange{orderErrors: map[string]error{"market_BUY": assert.AnError}}
+
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+		WithRiskManager(rm))
+	require.NoError(t, err)
+	service.startingEquity = 1000
+
+	// No drawdown: full configured size
+	service.updateEquity(context.Background(), 2000)
+	_ = service.enterPosition(context.Background(), domain.PositionSideLong, 2000, time.Now())
+	assert.Equal(t, "0.100", exchange.marketOrderQty)
+
+	// 10% drawdown from peak: half size
+	service.realizedPnL = -100
+	service.updateEquity(context.Background(), 2000)
+	_ = service.enterPosition(context.Background(), domain.PositionSideLong, 2000, time.Now())
+	assert.Equal(t, "0.050", exchange.marketOrderQty)
+	assert.InDelta(t, 0.1, rm.GetStats().CurrentDrawdown, 1e-9)
+}
+
+func TestTradingService_QuoteQuantity(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:       "ETHUSDT",
+		Quantity:     500,
+		QuantityMode: domain.QuantityModeQuote,
+		StopLoss:     0.01,
+		MaxProfit:    0.02,
+		MaxOrders:    5,
+	}
+	exchange := &mockExchange{orderErrors: map[string]error{"market_BUY": assert.AnError}}
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{})
+	require.NoError(t, err)
+
+	// 500 USDT at 2000 is 0.25 ETH
+	_ = service.enterPosition(context.Background(), domain.PositionSideLong, 2000, time.Now())
+	assert.Equal(t, "0.250", exchange.marketOrderQty)
+
+	// At 3000 it's 0.1666..., rounded down to the step size
+	_ = service.enterPosition(context.Background(), domain.PositionSideLong, 3000, time.Now())
+	assert.Equal(t, "0.166", exchange.marketOrderQty)
+
+	// Too small to buy a single step
+	cfg.Quantity = 1
+	err = service.enterPosition(context.Background(), domain.PositionSideLong, 3000, time.Now())
+	assert.ErrorContains(t, err, "below the step size")
+}
+
+func TestTradingService_checkLiquidity(t *testing.T) {
+	book := func(bid, ask, qty float64) *ports.OrderBookDepth {
+		return &ports.OrderBookDepth{
+			Symbol: "ETHUSDT",
+			Bids:   []ports.OrderBookLevel{{Price: bid, Quantity: qty}, {Price: bid - 0.1, Quantity: qty}},
+			Asks:   []ports.OrderBookLevel{{Price: ask, Quantity: qty}, {Price: ask + 0.1, Quantity: qty}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		depth      *ports.OrderBookDepth
+		depthErr   error
+		wantAllow  bool
+		wantReason string
+	}{
+		{
+			name:      "tight and deep book",
+			depth:     book(2000.0, 2000.1, 20), // ~80k USDT per side
+			wantAllow: true,
+		},
+		{
+			name:       "spread too wide",
+			depth:      book(2000.0, 2003.0, 20),
+			wantReason: "spread",
+		},
+		{
+			name:       "book too thin",
+			depth:      book(2000.0, 2000.1, 1),
+			wantReason: "depth",
+		},
+		{
+			name:       "empty book",
+			depth:      &ports.OrderBookDepth{Symbol: "ETHUSDT"},
+			wantReason: "empty",
+		},
+		{
+			name:       "depth fetch fails",
+			depthErr:   assert.AnError,
+			wantReason: "failed to fetch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Symbol:    "ETHUSDT",
+				Quantity:  0.1,
+				StopLoss:  0.01,
+				MaxProfit: 0.02,
+				MaxOrders: 5,
+			}
+			filter := strategies.NewLiquidityFilter(strategies.LiquidityFilterConfig{
+				MaxSpreadPct: 0.0005,
+				MinDepth:     50000,
+				DepthLevels:  2,
+			})
+			exchange := &mockExchange{depth: tt.depth, depthErr: tt.depthErr}
+			service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+				WithLiquidityFilter(filter))
+			require.NoError(t, err)
+
+			allow, reason := service.checkLiquidity(context.Background())
+			assert.Equal(t, tt.wantAllow, allow)
+			assert.Contains(t, reason, tt.wantReason)
+		})
+	}
+}
+
+func TestTradingService_MultiTimeframe(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:         "ETHUSDT",
+		Quantity:       0.1,
+		StopLoss:       0.01,
+		MaxProfit:      0.02,
+		MaxOrders:      5,
+		KlineIntervals: []string{"15m", "1m", "1h"},
+	}
+	strat := &mockMultiTimeframeStrategy{timeframes: []string{"1h", "5m"}}
+
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, strat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"15m", "1h", "5m"}, service.intervals, "primary interval and duplicates are dropped")
+
+	// Additional intervals only update their cache
+	handler := service.timeframeKlineHandler("1h")
+	handler(&domain.Kline{Symbol: "ETHUSDT", Interval: "1h", Close: 2000, IsFinal: false})
+	handler(&domain.Kline{Symbol: "ETHUSDT", Interval: "1h", Close: 2010, IsFinal: true})
+	assert.Len(t, service.signals.timeframes["1h"], 1)
+	assert.Nil(t, strat.data, "additional intervals don't trigger evaluation")
+
+	// Primary klines pass every timeframe to the strategy
+	service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Interval: "1m", Close: 2005, IsFinal: true})
+	require.NotNil(t, strat.data)
+	assert.Len(t, strat.data["1m"], 1)
+	assert.Len(t, strat.data["1h"], 1)
+	assert.Equal(t, 2010.0, strat.data["1h"][0].Close)
+}
+
+func TestTradingService_StartStreamsTimeframes(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:     "ETHUSDT",
+		Quantity:   0.1,
+		StopLoss:   0.02,
+		MaxProfit:  0.05,
+		MaxOrders:  5,
+		Leverage:   10,
+		MarginType: domain.MarginTypeIsolated,
+	}
+	exchange := &mockExchange{
+		klines:         generateTestKlines(100),
+		orderResponses: make(map[string]*ports.OrderResponse),
+		orderErrors:    make(map[string]error),
+	}
+	strat := &mockMultiTimeframeStrategy{timeframes: []string{"15m", "1h"}}
+
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)}, &mockTradeRepo{}, strat)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- service.Start(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-errCh)
+
+	assert.Equal(t, []string{"1m", "15m", "1h"}, exchange.klineIntervals)
+	assert.Equal(t, []string{"1m", "15m", "1h"}, exchange.streamedIntervals)
+	assert.Len(t, service.signals.timeframes["15m"], 100)
+}
+
+func TestTradingService_HedgeMode(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+		HedgeMode: true,
+	}
+	// Market orders report no average price, so fills fall back to the kline close
+	exchange := &mockExchange{
+		orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY":  {OrderID: 1},
+			"stop_SELL":   {OrderID: 2},
+			"tp_SELL":     {OrderID: 3},
+			"market_SELL": {OrderID: 4},
+			"stop_BUY":    {OrderID: 5},
+			"tp_BUY":      {OrderID: 6},
+		},
+	}
+	strat := &mockShortStrategy{mockStrategy: mockStrategy{shouldEnter: true}, shouldShort: true}
+	posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, strat)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// The position mode is applied at startup; "no change needed" is not an error
+	exchange.positionModeErr = fmt.Errorf("SetPositionMode failed: %w", ports.ErrNoChangeNeeded)
+	require.NoError(t, service.ensurePositionMode(ctx))
+	require.NotNil(t, exchange.hedgeMode)
+	assert.True(t, *exchange.hedgeMode)
+
+	// The first signal opens the long, the next one the short next to it
+	service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Interval: "1m", Close: 2000, IsFinal: true})
+	service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Interval: "1m", Close: 2000, IsFinal: true})
+	require.NotNil(t, service.positions.long)
+	require.NotNil(t, service.positions.short)
+	assert.Equal(t, domain.PositionSideLong, service.positions.long.Side)
+	assert.Equal(t, domain.PositionSideShort, service.positions.short.Side)
+	assert.InDelta(t, 2020.0, service.positions.short.StopLoss, 1e-9)
+	assert.InDelta(t, 1960.0, service.positions.short.TakeProfit, 1e-9)
+	assert.Equal(t, []domain.PositionSide{domain.PositionSideLong, domain.PositionSideShort}, exchange.positionSides)
+	assert.True(t, service.Status(ctx).HasOpenPosition)
+
+	can, reason := service.gate.Allow(domain.PositionSideShort)
+	assert.False(t, can)
+	assert.Contains(t, reason, "already open")
+
+	// Closing the short buys it back on the SHORT side and leaves the long open
+	require.NoError(t, service.closePosition(ctx, service.positions.short, 1950, domain.CloseReasonTakeProfit))
+	assert.Nil(t, service.positions.short)
+	assert.NotNil(t, service.positions.long)
+	assert.Equal(t, domain.PositionSideShort, exchange.positionSides[len(exchange.positionSides)-1])
+	closed, err := posRepo.FindOpenBySymbolAndSide(ctx, "ETHUSDT", domain.PositionSideShort)
+	require.NoError(t, err)
+	assert.Nil(t, closed)
+	assert.InDelta(t, 5.0, posRepo.positions["ETHUSDT/SHORT"].PNL, 1e-9) // (2000 - 1950) * 0.1
+
+	// In one-way mode an open long blocks shorts and orders use the BOTH side
+	service.cfg.HedgeMode = false
+	can, reason = service.gate.Allow(domain.PositionSideShort)
+	assert.False(t, can)
+	assert.Contains(t, reason, "one-way mode")
+	assert.Equal(t, domain.PositionSideBoth, service.positions.ExchangeSide(domain.PositionSideLong))
+}
+
+func TestTradingService_WithClock(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+		"market_BUY": {OrderID: 1, AvgPrice: 2000},
+		"stop_SELL":  {OrderID: 2},
+		"tp_SELL":    {OrderID: 3},
+	}}
+	now := time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(now)
+	service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+		&mockTradeRepo{}, &mockStrategy{}, WithClock(fake))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, now))
+	assert.Equal(t, now, service.positions.long.EntryTime)
+
+	fake.Advance(2 * time.Hour)
+	status := service.Status(ctx)
+	assert.Equal(t, now.Add(2*time.Hour), status.Timestamp)
+}
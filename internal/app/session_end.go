@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// WithSessionEnd enables day trading sessions ending at end, a UTC time of day given as an offset
+// from midnight (which must be positive): open positions are market-closed at the session end and
+// new entries are refused from then until UTC midnight.
+func WithSessionEnd(end time.Duration) Option {
+	return func(s *TradingService) {
+		s.gate.sessionEnd = end
+	}
+}
+
+// SessionEnded reports whether now is past the session end of its UTC day.
+func (g *EntryGate) SessionEnded(now time.Time) bool {
+	return domain.SessionEnded(now, g.sessionEnd)
+}
+
+// runSessionEnd flattens the open positions at each session end until ctx is canceled.
+func (s *TradingService) runSessionEnd(ctx context.Context) {
+	for {
+		next := domain.NextSessionEnd(s.now(), s.gate.sessionEnd)
+		s.logger.Debug(ctx, "Next session end scheduled", map[string]interface{}{"at": next})
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.mu.Lock()
+		price, _ := s.signals.LastPrice()
+		s.flattenSession(ctx, price)
+		s.mu.Unlock()
+	}
+}
+
+// flattenSession market-closes every open position because the session has ended and reports
+// whether any close was attempted. Positions it fails to close (or restored on a restart after
+// the session end) are retried on the next kline. Assumes the caller holds the lock.
+func (s *TradingService) flattenSession(ctx context.Context, price float64) bool {
+	open := s.positions.OpenPositions()
+	for _, pos := range open {
+		s.logger.Info(ctx, "Session ended, closing position", map[string]interface{}{"positionID": pos.ID, "side": pos.PositionSide()})
+		if err := s.closePosition(ctx, pos, price, domain.CloseReasonSessionEnd); err != nil {
+			s.logger.Error(ctx, err, "Failed to close position at session end, will retry", map[string]interface{}{"positionID": pos.ID})
+			s.resyncOnClockSkew(err)
+			s.observeExchangeError(ctx, err)
+		}
+	}
+	return len(open) > 0
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestTradingService_SessionEnd(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	now := time.Now()
+	newService := func(t *testing.T, end time.Duration, exchange *mockExchange) (*TradingService, *mockPositionRepo) {
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{shouldEnter: true},
+			WithSessionEnd(end))
+		require.NoError(t, err)
+		return service, posRepo
+	}
+
+	t.Run("after the session end positions are flattened and entries refused", func(t *testing.T) {
+		// The session ends a nanosecond after UTC midnight, so it has ended by now
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{"market_SELL": {OrderID: 4, AvgPrice: 2010}}}
+		service, _ := newService(t, time.Nanosecond, exchange)
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Equal(t, "session ended", reason)
+
+		service.positions.long = &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.1, StopLoss: 1980, Status: domain.StatusOpen}
+		service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Close: 2010, CloseTime: now, IsFinal: true})
+		assert.Nil(t, service.positions.long)
+		assert.Equal(t, domain.CloseReasonSessionEnd, service.gate.lastExitReason)
+	})
+
+	t.Run("before the session end trading is allowed", func(t *testing.T) {
+		// A session ending at 24:00 never ends within the day
+		service, _ := newService(t, 24*time.Hour, &mockExchange{})
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+
+		pos := &domain.Position{ID: 1, Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.1, StopLoss: 1980, Status: domain.StatusOpen}
+		service.positions.long = pos
+		assert.False(t, service.gate.SessionEnded(now))
+		service.handleKlineEvent(&domain.Kline{Symbol: "ETHUSDT", Close: 2010, CloseTime: now, IsFinal: true})
+		assert.Same(t, pos, service.positions.long)
+	})
+}
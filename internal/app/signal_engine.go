@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// SignalEngine turns the kline stream into trading signals. It keeps the klines the strategy
+// evaluates, of the primary interval and of any additional ones, and asks the strategy whether to
+// enter or exit. It holds no positions and sends no orders; TradingService acts on its signals.
+// It isn't safe for concurrent use: the service calls it with its lock held.
+type SignalEngine struct {
+	strategy   ports.Strategy
+	klines     []*domain.Kline            // Final primary klines, oldest first
+	timeframes map[string][]*domain.Kline // Final klines of each additional interval, oldest first
+}
+
+// NewSignalEngine creates a signal engine evaluating strat, with empty kline caches.
+func NewSignalEngine(strat ports.Strategy) *SignalEngine {
+	return &SignalEngine{
+		strategy:   strat,
+		klines:     make([]*domain.Kline, 0, maxKlineCacheSize),
+		timeframes: make(map[string][]*domain.Kline),
+	}
+}
+
+// Strategy returns the strategy the signals come from.
+func (e *SignalEngine) Strategy() ports.Strategy {
+	return e.strategy
+}
+
+// SetStrategy switches the strategy the signals come from. The kline caches are kept.
+func (e *SignalEngine) SetStrategy(strat ports.Strategy) {
+	e.strategy = strat
+}
+
+// Klines returns the cached primary klines, oldest first.
+func (e *SignalEngine) Klines() []*domain.Kline {
+	return e.klines
+}
+
+// SetKlines replaces the cached primary klines, keeping the most recent maxKlineCacheSize.
+func (e *SignalEngine) SetKlines(klines []*domain.Kline) {
+	e.klines = trimKlines(klines)
+}
+
+// LastPrice returns the close of the latest cached primary kline, or false if none is cached.
+func (e *SignalEngine) LastPrice() (float64, bool) {
+	if len(e.klines) == 0 {
+		return 0, false
+	}
+	return e.klines[len(e.klines)-1].Close, true
+}
+
+// AddKline appends a final primary kline.
+func (e *SignalEngine) AddKline(kline *domain.Kline) {
+	e.klines = trimKlines(append(e.klines, kline))
+}
+
+// MergeKline adds a final primary kline unless it's older than the latest cached one; a kline
+// already cached (e.g., after a refill) replaces the cached copy instead of being added twice.
+func (e *SignalEngine) MergeKline(kline *domain.Kline) {
+	if n := len(e.klines); n > 0 {
+		last := e.klines[n-1]
+		if kline.OpenTime.Equal(last.OpenTime) {
+			e.klines[n-1] = kline
+			return
+		}
+		if kline.OpenTime.Before(last.OpenTime) {
+			return
+		}
+	}
+	e.AddKline(kline)
+}
+
+// Timeframe returns the cached klines of an additional interval, oldest first.
+func (e *SignalEngine) Timeframe(interval string) []*domain.Kline {
+	return e.timeframes[interval]
+}
+
+// SetTimeframe replaces the cached klines of an additional interval.
+func (e *SignalEngine) SetTimeframe(interval string, klines []*domain.Kline) {
+	e.timeframes[interval] = trimKlines(klines)
+}
+
+// AddTimeframeKline appends a final kline of an additional interval.
+func (e *SignalEngine) AddTimeframeKline(interval string, kline *domain.Kline) {
+	e.timeframes[interval] = trimKlines(append(e.timeframes[interval], kline))
+}
+
+// ResetTimeframes drops the cached klines of every additional interval.
+func (e *SignalEngine) ResetTimeframes() {
+	e.timeframes = make(map[string][]*domain.Kline)
+}
+
+// ProvideTimeframeData passes the kline caches of all streamed intervals to strategies that
+// implement ports.MultiTimeframeStrategy.
+func (e *SignalEngine) ProvideTimeframeData() {
+	mtf, ok := e.strategy.(ports.MultiTimeframeStrategy)
+	if !ok {
+		return
+	}
+
+	data := make(map[string][]*domain.Kline, len(e.timeframes)+1)
+	for interval, klines := range e.timeframes {
+		data[interval] = klines
+	}
+	data[primaryInterval] = e.klines
+	mtf.SetTimeframeData(data)
+}
+
+// EntrySides returns the sides the strategy can open positions on: always long, and short
+// when the strategy implements ports.ShortStrategy.
+func (e *SignalEngine) EntrySides() []domain.PositionSide {
+	sides := []domain.PositionSide{domain.PositionSideLong}
+	if _, ok := e.strategy.(ports.ShortStrategy); ok {
+		sides = append(sides, domain.PositionSideShort)
+	}
+	return sides
+}
+
+// EntrySignal asks the strategy whether to open a position on side at price.
+func (e *SignalEngine) EntrySignal(ctx context.Context, side domain.PositionSide, price float64) bool {
+	if side == domain.PositionSideShort {
+		shorter, ok := e.strategy.(ports.ShortStrategy)
+		return ok && shorter.ShouldEnterShort(ctx, e.klines, price)
+	}
+	return e.strategy.ShouldEnterTrade(ctx, e.klines, price)
+}
+
+// ExitSignal asks the strategy whether to close pos at price, and why.
+func (e *SignalEngine) ExitSignal(ctx context.Context, pos *domain.Position, price float64) (bool, domain.CloseReason) {
+	return e.strategy.ShouldClosePosition(ctx, pos, e.klines, price)
+}
+
+// trimKlines keeps the most recent maxKlineCacheSize klines.
+func trimKlines(klines []*domain.Kline) []*domain.Kline {
+	if len(klines) > maxKlineCacheSize {
+		return klines[len(klines)-maxKlineCacheSize:]
+	}
+	return klines
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+func TestSignalEngine(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	kline := func(i int, close float64) *domain.Kline {
+		return &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Minute), Close: close, IsFinal: true}
+	}
+
+	t.Run("caches klines up to the limit", func(t *testing.T) {
+		engine := NewSignalEngine(&mockStrategy{})
+		_, ok := engine.LastPrice()
+		assert.False(t, ok)
+		for i := 0; i < maxKlineCacheSize+10; i++ {
+			engine.AddKline(kline(i, float64(i)))
+		}
+		require.Len(t, engine.Klines(), maxKlineCacheSize)
+		assert.Equal(t, start.Add(10*time.Minute), engine.Klines()[0].OpenTime)
+		price, ok := engine.LastPrice()
+		assert.True(t, ok)
+		assert.Equal(t, float64(maxKlineCacheSize+9), price)
+	})
+
+	t.Run("merges repeated and late klines", func(t *testing.T) {
+		engine := NewSignalEngine(&mockStrategy{})
+		engine.SetKlines([]*domain.Kline{kline(0, 100), kline(1, 101)})
+		engine.MergeKline(kline(1, 105)) // Same kline again, e.g. after a refill
+		engine.MergeKline(kline(0, 90))  // Older than the cache
+		engine.MergeKline(kline(2, 102))
+		require.Len(t, engine.Klines(), 3)
+		assert.Equal(t, 105.0, engine.Klines()[1].Close)
+		assert.Equal(t, 100.0, engine.Klines()[0].Close)
+	})
+
+	t.Run("signals come from the strategy", func(t *testing.T) {
+		strat := &mockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonTakeProfit}
+		engine := NewSignalEngine(strat)
+		assert.Equal(t, []domain.PositionSide{domain.PositionSideLong}, engine.EntrySides())
+		assert.True(t, engine.EntrySignal(ctx, domain.PositionSideLong, 2000))
+		assert.False(t, engine.EntrySignal(ctx, domain.PositionSideShort, 2000), "a long-only strategy never shorts")
+		shouldClose, reason := engine.ExitSignal(ctx, &domain.Position{Side: domain.PositionSideLong}, 2000)
+		assert.True(t, shouldClose)
+		assert.Equal(t, domain.CloseReasonTakeProfit, reason)
+
+		shorter := &mockShortStrategy{shouldShort: true}
+		engine.SetStrategy(shorter)
+		assert.Same(t, shorter, engine.Strategy())
+		assert.Equal(t, []domain.PositionSide{domain.PositionSideLong, domain.PositionSideShort}, engine.EntrySides())
+		assert.False(t, engine.EntrySignal(ctx, domain.PositionSideLong, 2000))
+		assert.True(t, engine.EntrySignal(ctx, domain.PositionSideShort, 2000))
+	})
+
+	t.Run("timeframe data reaches multi-timeframe strategies", func(t *testing.T) {
+		strat := &mockMultiTimeframeStrategy{timeframes: []string{"1h"}}
+		engine := NewSignalEngine(strat)
+		engine.AddKline(kline(0, 100))
+		engine.SetTimeframe("1h", []*domain.Kline{kline(0, 99)})
+		engine.AddTimeframeKline("1h", kline(60, 101))
+		engine.ProvideTimeframeData()
+		require.Len(t, strat.data["1h"], 2)
+		require.Len(t, strat.data[primaryInterval], 1)
+
+		engine.ResetTimeframes()
+		assert.Empty(t, engine.Timeframe("1h"))
+	})
+}
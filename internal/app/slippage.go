@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// SlippageGuardConfig holds configuration for the slippage and spread checks of market entries.
+type SlippageGuardConfig struct {
+	MaxSlippageBps float64 // Adverse fill slippage vs the signal price that raises an alert, in bps (0 disables)
+	ExitOnSlippage bool    // Close entries whose slippage exceeds MaxSlippageBps right away
+	MaxSpreadBps   float64 // Entries are skipped while the best bid/ask spread exceeds this, in bps of the mid price (0 disables)
+}
+
+// WithSlippageGuard compares the average fill price of each market entry to the signal price and
+// logs and notifies when it is worse by more than MaxSlippageBps, closing the position with
+// domain.CloseReasonSlippage if ExitOnSlippage is set. With MaxSpreadBps, the best bid and ask
+// are fetched before each entry (from the book ticker if the exchange client is a
+// ports.BookTickerProvider, otherwise from the order book) and the entry is skipped while the
+// spread is too wide.
+func WithSlippageGuard(cfg SlippageGuardConfig) Option {
+	return func(s *TradingService) {
+		s.slippageGuard = &cfg
+	}
+}
+
+// slippageBps returns how much worse than signalPrice an entry on positionSide filled at
+// fillPrice, in bps of signalPrice. Fills better than the signal price are negative.
+func slippageBps(positionSide domain.PositionSide, signalPrice, fillPrice float64) float64 {
+	if signalPrice <= 0 {
+		return 0
+	}
+	slippage := (fillPrice - signalPrice) / signalPrice * 10000
+	if positionSide == domain.PositionSideShort {
+		return -slippage // Shorts sell, so a lower fill is worse
+	}
+	return slippage
+}
+
+// checkSpread fetches the best bid and ask and reports whether an entry is allowed at the
+// current spread. Fails closed: if the spread can't be fetched the entry is skipped. Always
+// allows entries without a configured spread limit.
+func (s *TradingService) checkSpread(ctx context.Context) (bool, string) {
+	if s.slippageGuard == nil || s.slippageGuard.MaxSpreadBps <= 0 {
+		return true, ""
+	}
+	bid, ask, err := s.bestBidAsk(ctx)
+	if err != nil {
+		s.logger.Error(ctx, err, "Failed to fetch best bid/ask for spread check")
+		return false, "failed to fetch best bid/ask"
+	}
+	if bid <= 0 || ask < bid {
+		return false, fmt.Sprintf("invalid best bid/ask %.8f/%.8f", bid, ask)
+	}
+	mid := (bid + ask) / 2
+	spreadBps := (ask - bid) / mid * 10000
+	if spreadBps > s.slippageGuard.MaxSpreadBps {
+		return false, fmt.Sprintf("spread %.2f bps exceeds max %.2f bps", spreadBps, s.slippageGuard.MaxSpreadBps)
+	}
+	return true, ""
+}
+
+// bestBidAsk returns the symbol's best bid and ask from the book ticker, or from the top of the
+// order book if the exchange client has no book ticker.
+func (s *TradingService) bestBidAsk(ctx context.Context) (float64, float64, error) {
+	if provider, ok := s.exchange.(ports.BookTickerProvider); ok {
+		book, err := provider.GetBookTicker(ctx, s.cfg.Symbol)
+		if err != nil {
+			return 0, 0, err
+		}
+		return book.BidPrice, book.AskPrice, nil
+	}
+	depth, err := s.exchange.GetOrderBookDepth(ctx, s.cfg.Symbol, 5)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		return 0, 0, fmt.Errorf("order book of %s is empty", s.cfg.Symbol)
+	}
+	return depth.Bids[0].Price, depth.Asks[0].Price, nil
+}
+
+// guardSlippage checks the fill of a market entry opened at signalPrice and alerts, and exits
+// if configured, when it slipped too far. Assumes the caller holds the lock.
+func (s *TradingService) guardSlippage(ctx context.Context, pos *domain.Position, signalPrice float64) {
+	if s.slippageGuard == nil || s.slippageGuard.MaxSlippageBps <= 0 {
+		return
+	}
+	slippage := slippageBps(pos.PositionSide(), signalPrice, pos.EntryPrice)
+	if slippage <= s.slippageGuard.MaxSlippageBps {
+		return
+	}
+	s.logger.Warn(ctx, "Entry fill slipped beyond the limit", map[string]interface{}{
+		"positionID":     pos.ID,
+		"side":           pos.PositionSide(),
+		"signalPrice":    signalPrice,
+		"fillPrice":      pos.EntryPrice,
+		"slippageBps":    slippage,
+		"maxSlippageBps": s.slippageGuard.MaxSlippageBps,
+		"exit":           s.slippageGuard.ExitOnSlippage,
+	})
+	action := "Position kept open"
+	if s.slippageGuard.ExitOnSlippage {
+		action = "Position closed"
+		if err := s.closePosition(ctx, pos, pos.EntryPrice, domain.CloseReasonSlippage); err != nil {
+			s.logger.Error(ctx, err, "Failed to close position after excessive slippage", map[string]interface{}{"positionID": pos.ID})
+			action = fmt.Sprintf("Closing the position failed: %v", err)
+		}
+	}
+	s.notify(ctx, fmt.Sprintf("%s entry slipped %.1f bps", s.cfg.Symbol, slippage),
+		fmt.Sprintf("Symbol: %s\nSide: %s\nSignal price: %.4f\nFill price: %.4f\nSlippage: %.1f bps (max %.1f bps)\n%s",
+			s.cfg.Symbol, pos.PositionSide(), signalPrice, pos.EntryPrice, slippage, s.slippageGuard.MaxSlippageBps, action), nil)
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestSlippageBps(t *testing.T) {
+	assert.InDelta(t, 10.0, slippageBps(domain.PositionSideLong, 2000, 2002), 1e-9)
+	assert.InDelta(t, -10.0, slippageBps(domain.PositionSideLong, 2000, 1998), 1e-9)
+	assert.InDelta(t, 10.0, slippageBps(domain.PositionSideShort, 2000, 1998), 1e-9)
+	assert.Zero(t, slippageBps(domain.PositionSideLong, 0, 2000))
+}
+
+func TestTradingService_SlippageGuard(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ctx := context.Background()
+	newService := func(t *testing.T, fill float64, guard SlippageGuardConfig) (*TradingService, *mockExchange, *mockNotifier) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY":  {OrderID: 1, AvgPrice: fill},
+			"stop_SELL":   {OrderID: 2},
+			"tp_SELL":     {OrderID: 3},
+			"market_SELL": {OrderID: 4, AvgPrice: fill},
+		}}
+		notifier := &mockNotifier{}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithSlippageGuard(guard), WithNotifier(notifier))
+		require.NoError(t, err)
+		return service, exchange, notifier
+	}
+
+	t.Run("fill within the limit is kept quietly", func(t *testing.T) {
+		service, _, notifier := newService(t, 2001, SlippageGuardConfig{MaxSlippageBps: 10, ExitOnSlippage: true})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		service.notifications.Wait()
+		assert.NotNil(t, service.positions.Position(domain.PositionSideLong))
+		assert.Len(t, notifier.subjects, 1) // Only the entry notification
+	})
+
+	t.Run("excessive slippage is reported", func(t *testing.T) {
+		service, _, notifier := newService(t, 2004, SlippageGuardConfig{MaxSlippageBps: 10})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		service.notifications.Wait()
+		assert.NotNil(t, service.positions.Position(domain.PositionSideLong))
+		assert.Contains(t, notifier.subjects, "ETHUSDT entry slipped 20.0 bps")
+	})
+
+	t.Run("excessive slippage exits when configured", func(t *testing.T) {
+		service, exchange, notifier := newService(t, 2004, SlippageGuardConfig{MaxSlippageBps: 10, ExitOnSlippage: true})
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		service.notifications.Wait()
+		assert.Nil(t, service.positions.Position(domain.PositionSideLong))
+		assert.ElementsMatch(t, []int64{2, 3}, exchange.canceledOrders)
+		assert.Contains(t, notifier.subjects, "ETHUSDT entry slipped 20.0 bps")
+		assert.Equal(t, domain.CloseReasonSlippage, service.gate.lastExitReason)
+	})
+}
+
+func TestTradingService_CheckSpread(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	newService := func(t *testing.T, exchange ports.ExchangeClient, maxSpreadBps float64) *TradingService {
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{}, &mockStrategy{}, WithSlippageGuard(SlippageGuardConfig{MaxSpreadBps: maxSpreadBps}))
+		require.NoError(t, err)
+		return service
+	}
+	ctx := context.Background()
+
+	t.Run("tight spread allows entries", func(t *testing.T) {
+		service := newService(t, &mockExchange{bookTicker: &ports.BookTicker{BidPrice: 1999.9, AskPrice: 2000.1}}, 5)
+		ok, reason := service.checkSpread(ctx)
+		assert.True(t, ok, reason)
+	})
+
+	t.Run("wide spread skips entries", func(t *testing.T) {
+		service := newService(t, &mockExchange{bookTicker: &ports.BookTicker{BidPrice: 1998, AskPrice: 2002}}, 5)
+		ok, reason := service.checkSpread(ctx)
+		assert.False(t, ok)
+		assert.Contains(t, reason, "spread 20.00 bps exceeds max 5.00 bps")
+	})
+
+	t.Run("fails closed when the book ticker is unavailable", func(t *testing.T) {
+		service := newService(t, &mockExchange{bookTickerErr: errors.New("timeout")}, 5)
+		ok, _ := service.checkSpread(ctx)
+		assert.False(t, ok)
+	})
+
+	t.Run("falls back to the order book", func(t *testing.T) {
+		exchange := &mockExchange{depth: &ports.OrderBookDepth{
+			Bids: []ports.OrderBookLevel{{Price: 1998, Quantity: 1}},
+			Asks: []ports.OrderBookLevel{{Price: 2002, Quantity: 1}},
+		}}
+		// Hide the mock's book ticker behind the plain exchange client interface
+		service := newService(t, struct{ ports.ExchangeClient }{exchange}, 5)
+		ok, reason := service.checkSpread(ctx)
+		assert.False(t, ok)
+		assert.Contains(t, reason, "spread 20.00 bps")
+	})
+
+	t.Run("disabled without a limit", func(t *testing.T) {
+		service := newService(t, &mockExchange{bookTickerErr: errors.New("timeout")}, 0)
+		ok, _ := service.checkSpread(ctx)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,245 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// activeStrategyKey is the strategy state key under which the active strategy selection is
+// persisted, so strategies switched at runtime survive restarts.
+const activeStrategyKey = "active_strategy"
+
+// StrategyRegistry maps strategy names to factories so the active strategy can be switched at runtime.
+type StrategyRegistry struct {
+	factories map[string]ports.StrategyFactory
+}
+
+// NewStrategyRegistry creates an empty strategy registry.
+func NewStrategyRegistry() *StrategyRegistry {
+	return &StrategyRegistry{factories: make(map[string]ports.StrategyFactory)}
+}
+
+// Register adds a strategy factory under name.
+func (r *StrategyRegistry) Register(name string, factory ports.StrategyFactory) error {
+	if name == "" || factory == nil {
+		return fmt.Errorf("strategy name and factory are required")
+	}
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("strategy %q is already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// New builds the named strategy with params. Unknown names and rejected params are reported
+// as ports.ErrConfigurationError.
+func (r *StrategyRegistry) New(name string, params map[string]float64) (ports.Strategy, error) {
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q (available: %s): %w", name, strings.Join(r.Names(), ", "), ports.ErrConfigurationError)
+	}
+	strat, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create strategy %s: %v: %w", name, err, ports.ErrConfigurationError)
+	}
+	return strat, nil
+}
+
+// Names returns the registered strategy names in alphabetical order.
+func (r *StrategyRegistry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// strategySelection identifies the active strategy by its registered name and parameters.
+type strategySelection struct {
+	Name       string             `json:"name"`
+	Params     map[string]float64 `json:"params,omitempty"`
+	SwitchedAt time.Time          `json:"switchedAt,omitempty"`
+}
+
+// SwitchStrategy replaces the active strategy without a restart (implements ports.TradingController).
+// Open positions are closed at market first if requested; otherwise the new strategy manages them.
+// Kline streams can't change while running, so strategies needing intervals that aren't streamed
+// are rejected.
+func (s *TradingService) SwitchStrategy(ctx context.Context, req ports.StrategySwitchRequest) error {
+	if s.registry == nil {
+		return fmt.Errorf("strategy switching is not enabled: %w", ports.ErrConfigurationError)
+	}
+	strat, err := s.registry.New(req.Name, req.Params)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if missing := missingIntervals(s.intervals, additionalIntervals(s.cfg.KlineIntervals, strat)); len(missing) > 0 {
+		return fmt.Errorf("strategy %s needs kline intervals %v that are not streamed, restart required: %w",
+			req.Name, missing, ports.ErrConfigurationError)
+	}
+	// Before Start the cache is empty and is loaded for the new strategy anyway
+	if required := strat.RequiredDataPoints(); len(s.signals.Klines()) > 0 && len(s.signals.Klines()) < required {
+		klines, err := s.exchange.GetKlines(ctx, s.cfg.Symbol, primaryInterval, required)
+		if err != nil {
+			return fmt.Errorf("failed to load klines for strategy %s: %w", req.Name, err)
+		}
+		s.signals.SetKlines(klines)
+	}
+
+	if req.ClosePositions {
+		for _, pos := range s.positions.OpenPositions() {
+			price, err := s.exchange.GetMarkPrice(ctx, s.cfg.Symbol)
+			if err != nil {
+				return fmt.Errorf("failed to get price to close positions before switching strategy: %w", err)
+			}
+			if err := s.closePosition(ctx, pos, price, domain.CloseReasonManual); err != nil {
+				return fmt.Errorf("failed to close %s position before switching strategy: %w", pos.PositionSide(), err)
+			}
+		}
+	}
+
+	previous := s.activeStrategy.Name
+	s.persistStrategyState(ctx)
+	go s.closeStrategy(ctx, s.signals.Strategy())
+	s.signals.SetStrategy(strat)
+	s.activeStrategy = strategySelection{Name: req.Name, Params: copyParams(req.Params), SwitchedAt: s.now()}
+	s.restoreStrategyState(ctx)
+	s.signals.ProvideTimeframeData()
+	s.persistActiveStrategy(ctx)
+
+	openPositions := len(s.positions.OpenPositions())
+	s.logger.Warn(ctx, "Strategy switched", map[string]interface{}{
+		"symbol":        s.cfg.Symbol,
+		"from":          previous,
+		"to":            req.Name,
+		"params":        req.Params,
+		"openPositions": openPositions,
+	})
+	s.notify(ctx, fmt.Sprintf("Strategy switched to %s", req.Name),
+		fmt.Sprintf("Symbol: %s\nFrom: %s\nTo: %s\nParams: %v\nOpen positions kept: %d", s.cfg.Symbol, previous, req.Name, req.Params, openPositions), nil)
+	return nil
+}
+
+// closeStrategy releases the resources of a strategy that was switched away from, e.g. the
+// process of an external strategy.
+func (s *TradingService) closeStrategy(ctx context.Context, strat ports.Strategy) {
+	closer, ok := strat.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		s.logger.Error(ctx, err, "Failed to close previous strategy")
+	}
+}
+
+// strategyStatus describes the active strategy. Assumes the caller holds the lock.
+func (s *TradingService) strategyStatus() *ports.StrategyStatus {
+	if s.registry == nil {
+		return nil
+	}
+	return &ports.StrategyStatus{
+		Name:       s.activeStrategy.Name,
+		Params:     copyParams(s.activeStrategy.Params),
+		SwitchedAt: s.activeStrategy.SwitchedAt,
+		Available:  s.registry.Names(),
+	}
+}
+
+// restoreActiveStrategy re-applies the strategy selected at runtime before the last restart.
+// It runs before the streams start, so the restored strategy may use other intervals. Failures
+// are logged and the configured strategy is kept.
+func (s *TradingService) restoreActiveStrategy(ctx context.Context) {
+	op := "restoreActiveStrategy"
+	if s.registry == nil || s.stateRepo == nil {
+		return
+	}
+
+	data, err := s.stateRepo.LoadStrategyState(ctx, activeStrategyKey, s.cfg.Symbol)
+	if err != nil {
+		s.logger.Error(ctx, err, op+": Failed to load active strategy, keeping configured strategy")
+		return
+	}
+	if data == nil {
+		return
+	}
+	var selection strategySelection
+	if err := json.Unmarshal(data, &selection); err != nil {
+		s.logger.Error(ctx, err, op+": Failed to parse active strategy, keeping configured strategy")
+		return
+	}
+	strat, err := s.registry.New(selection.Name, selection.Params)
+	if err != nil {
+		s.logger.Error(ctx, err, op+": Failed to create active strategy, keeping configured strategy")
+		return
+	}
+
+	s.mu.Lock()
+	s.closeStrategy(ctx, s.signals.Strategy())
+	s.signals.SetStrategy(strat)
+	s.activeStrategy = selection
+	s.intervals = additionalIntervals(s.cfg.KlineIntervals, strat)
+	s.signals.ResetTimeframes()
+	s.mu.Unlock()
+	s.logger.Info(ctx, op+": Strategy switched at runtime restored", map[string]interface{}{
+		"strategy":   selection.Name,
+		"params":     selection.Params,
+		"switchedAt": selection.SwitchedAt,
+	})
+}
+
+// persistActiveStrategy saves the active strategy selection. Assumes the caller holds the lock.
+func (s *TradingService) persistActiveStrategy(ctx context.Context) {
+	op := "persistActiveStrategy"
+	if s.stateRepo == nil {
+		return
+	}
+
+	data, err := json.Marshal(s.activeStrategy)
+	if err != nil {
+		s.logger.Error(ctx, err, op+": Failed to serialize active strategy")
+		return
+	}
+	if err := s.stateRepo.SaveStrategyState(ctx, activeStrategyKey, s.cfg.Symbol, data); err != nil {
+		s.logger.Error(ctx, err, op+": Failed to save active strategy")
+	}
+}
+
+// missingIntervals returns the intervals in needed that are not in streamed.
+func missingIntervals(streamed, needed []string) []string {
+	have := make(map[string]bool, len(streamed))
+	for _, interval := range streamed {
+		have[interval] = true
+	}
+	var missing []string
+	for _, interval := range needed {
+		if !have[interval] {
+			missing = append(missing, interval)
+		}
+	}
+	return missing
+}
+
+// copyParams returns a copy of params so callers can't modify the stored selection.
+func copyParams(params map[string]float64) map[string]float64 {
+	if params == nil {
+		return nil
+	}
+	copied := make(map[string]float64, len(params))
+	for k, v := range params {
+		copied[k] = v
+	}
+	return copied
+}
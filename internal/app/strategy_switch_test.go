@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegistry registers a plain strategy and a multi-timeframe strategy needing the 1h interval.
+// The plain strategy rejects any parameter other than "points"
+func testRegistry(t *testing.T) *StrategyRegistry {
+	t.Helper()
+	registry := NewStrategyRegistry()
+	require.NoError(t, registry.Register("plain", func(params map[string]float64) (ports.Strategy, error) {
+		for name := range params {
+			if name != "points" {
+				return nil, assert.AnError
+			}
+		}
+		return &mockStrategy{}, nil
+	}))
+	require.NoError(t, registry.Register("mtf", func(params map[string]float64) (ports.Strategy, error) {
+		return &mockMultiTimeframeStrategy{timeframes: []string{"1h"}}, nil
+	}))
+	return registry
+}
+
+func TestStrategyRegistry(t *testing.T) {
+	registry := testRegistry(t)
+	assert.Equal(t, []string{"mtf", "plain"}, registry.Names())
+	assert.Error(t, registry.Register("plain", func(map[string]float64) (ports.Strategy, error) { return &mockStrategy{}, nil }))
+	assert.Error(t, registry.Register("", nil))
+
+	strat, err := registry.New("plain", map[string]float64{"points": 10})
+	require.NoError(t, err)
+	assert.IsType(t, &mockStrategy{}, strat)
+
+	_, err = registry.New("unknown", nil)
+	assert.ErrorIs(t, err, ports.ErrConfigurationError)
+	_, err = registry.New("plain", map[string]float64{"bogus": 1})
+	assert.ErrorIs(t, err, ports.ErrConfigurationError)
+}
+
+func TestTradingService_SwitchStrategy(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.02,
+		MaxProfit: 0.05,
+		MaxOrders: 5,
+		Leverage:  10,
+	}
+	openPosition := func() *domain.Position {
+		return &domain.Position{
+			ID:         1,
+			Symbol:     "ETHUSDT",
+			EntryPrice: 2000.0,
+			Quantity:   0.1,
+			Status:     domain.StatusOpen,
+			EntryTime:  time.Now().Add(-time.Hour),
+		}
+	}
+	newService := func(t *testing.T, opts ...Option) (*TradingService, *mockExchange, *mockStateRepo) {
+		exchange := &mockExchange{
+			markPrice: 2100.0,
+			orderResponses: map[string]*ports.OrderResponse{
+				"market_SELL": {OrderID: 1, Symbol: "ETHUSDT", ExecutedQty: 0.1, AvgPrice: 2100.0, Status: "FILLED"},
+			},
+			orderErrors: make(map[string]error),
+		}
+		stateRepo := &mockStateRepo{states: make(map[string][]byte)}
+		posRepo := &mockPositionRepo{positions: make(map[string]*domain.Position)}
+		opts = append([]Option{WithStateRepository(stateRepo)}, opts...)
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, posRepo, &mockTradeRepo{}, &mockStrategy{}, opts...)
+		require.NoError(t, err)
+		service.positions.long = openPosition()
+		posRepo.positions["ETHUSDT"] = service.positions.long
+		return service, exchange, stateRepo
+	}
+
+	t.Run("disabled without registry", func(t *testing.T) {
+		service, _, _ := newService(t)
+		err := service.SwitchStrategy(context.Background(), ports.StrategySwitchRequest{Name: "plain"})
+		assert.ErrorIs(t, err, ports.ErrConfigurationError)
+		assert.Nil(t, service.Status(context.Background()).Strategy)
+	})
+
+	t.Run("keeps open position", func(t *testing.T) {
+		service, _, stateRepo := newService(t, WithStrategyRegistry(testRegistry(t), "plain"))
+		initial := service.signals.strategy
+
+		params := map[string]float64{"points": 20}
+		require.NoError(t, service.SwitchStrategy(context.Background(), ports.StrategySwitchRequest{Name: "plain", Params: params}))
+		params["points"] = 30 // The stored selection is a copy
+
+		assert.NotSame(t, initial, service.signals.strategy)
+		assert.NotNil(t, service.positions.long)
+		status := service.Status(context.Background()).Strategy
+		require.NotNil(t, status)
+		assert.Equal(t, "plain", status.Name)
+		assert.Equal(t, map[string]float64{"points": 20}, status.Params)
+		assert.False(t, status.SwitchedAt.IsZero())
+		assert.Equal(t, []string{"mtf", "plain"}, status.Available)
+
+		var saved strategySelection
+		require.NoError(t, json.Unmarshal(stateRepo.states[activeStrategyKey+"/ETHUSDT"], &saved))
+		assert.Equal(t, "plain", saved.Name)
+		assert.Equal(t, map[string]float64{"points": 20}, saved.Params)
+	})
+
+	t.Run("closes open position first", func(t *testing.T) {
+		service, _, _ := newService(t, WithStrategyRegistry(testRegistry(t), "plain"))
+		require.NoError(t, service.SwitchStrategy(context.Background(), ports.StrategySwitchRequest{Name: "plain", ClosePositions: true}))
+		assert.Nil(t, service.positions.long)
+	})
+
+	t.Run("keeps strategy when close fails", func(t *testing.T) {
+		service, exchange, stateRepo := newService(t, WithStrategyRegistry(testRegistry(t), "plain"))
+		exchange.orderErrors["market_SELL"] = assert.AnError
+		initial := service.signals.strategy
+
+		err := service.SwitchStrategy(context.Background(), ports.StrategySwitchRequest{Name: "plain", ClosePositions: true})
+		assert.Error(t, err)
+		assert.Same(t, initial, service.signals.strategy)
+		assert.NotNil(t, service.positions.long)
+		assert.Empty(t, stateRepo.states)
+	})
+
+	t.Run("rejects strategy needing unstreamed intervals", func(t *testing.T) {
+		service, _, _ := newService(t, WithStrategyRegistry(testRegistry(t), "plain"))
+		err := service.SwitchStrategy(context.Background(), ports.StrategySwitchRequest{Name: "mtf"})
+		assert.ErrorIs(t, err, ports.ErrConfigurationError)
+		assert.Equal(t, "plain", service.activeStrategy.Name)
+	})
+
+	t.Run("rejects invalid params", func(t *testing.T) {
+		service, _, _ := newService(t, WithStrategyRegistry(testRegistry(t), "plain"))
+		err := service.SwitchStrategy(context.Background(), ports.StrategySwitchRequest{Name: "plain", Params: map[string]float64{"bogus": 1}})
+		assert.ErrorIs(t, err, ports.ErrConfigurationError)
+	})
+}
+
+func TestTradingService_restoreActiveStrategy(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	stateRepo := &mockStateRepo{
+		states: map[string][]byte{activeStrategyKey + "/ETHUSDT": []byte(`{"name":"mtf","switchedAt":"2026-01-02T03:04:05Z"}`)},
+	}
+	service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+		WithStateRepository(stateRepo), WithStrategyRegistry(testRegistry(t), "plain"))
+	require.NoError(t, err)
+
+	service.restoreActiveStrategy(context.Background())
+	assert.IsType(t, &mockMultiTimeframeStrategy{}, service.signals.strategy)
+	assert.Equal(t, "mtf", service.activeStrategy.Name)
+	assert.Equal(t, []string{"1h"}, service.intervals) // Streamed once Start continues
+
+	// Unknown strategies keep the configured one
+	stateRepo.states[activeStrategyKey+"/ETHUSDT"] = []byte(`{"name":"removed"}`)
+	service, err = NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+		WithStateRepository(stateRepo), WithStrategyRegistry(testRegistry(t), "plain"))
+	require.NoError(t, err)
+	service.restoreActiveStrategy(context.Background())
+	assert.IsType(t, &mockStrategy{}, service.signals.strategy)
+	assert.Equal(t, "plain", service.activeStrategy.Name)
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+
+	"cryptoMegaBot/internal/risk"
+)
+
+// WithStreakSizing scales the quantity of new entries and scale-in adds by the sizer's ladder of
+// recent results, e.g. more size after a run of wins and less after a run of losses. The streak
+// is rebuilt from the trade history on startup, so a restart doesn't reset it.
+func WithStreakSizing(sizer *risk.StreakSizer) Option {
+	return func(s *TradingService) {
+		s.sizer.streakSizer = sizer
+	}
+}
+
+// restoreStreak rebuilds the streak from the most recently closed positions, as many as the
+// ladder's longest step needs. Failures are logged only, leaving the sizer without a streak.
+func (s *TradingService) restoreStreak(ctx context.Context) {
+	if s.sizer.streakSizer == nil {
+		return
+	}
+	closed, err := s.tradeRepo.FindClosedBySymbol(ctx, s.cfg.Symbol, s.sizer.streakSizer.Ladder().MaxStreak())
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to load closed positions for streak sizing", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	// Most recent first; the sizer wants the oldest first
+	pnls := make([]float64, len(closed))
+	for i, pos := range closed {
+		pnls[len(closed)-1-i] = pos.PNL
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sizer.streakSizer.Restore(pnls)
+	s.logger.Info(ctx, "Win/loss streak restored", map[string]interface{}{
+		"streak": s.sizer.streakSizer.Streak(),
+		"factor": s.sizer.streakSizer.Factor(),
+	})
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+)
+
+func TestTradingService_StreakSizing(t *testing.T) {
+	cfg := &config.Config{Symbol: "ETHUSDT", Quantity: 1, StopLoss: 0.01, MaxProfit: 0.02, MaxOrders: 5}
+	ladder, err := risk.ParseStreakLadder("2W:1.5,2L:0.5")
+	require.NoError(t, err)
+	ctx := context.Background()
+	newService := func(t *testing.T, history []*domain.Position) (*TradingService, *mockExchange, *risk.StreakSizer) {
+		exchange := &mockExchange{orderResponses: map[string]*ports.OrderResponse{
+			"market_BUY":  {OrderID: 1, AvgPrice: 2000},
+			"stop_SELL":   {OrderID: 2},
+			"tp_SELL":     {OrderID: 3},
+			"market_SELL": {OrderID: 4, AvgPrice: 2100},
+		}}
+		sizer := risk.NewStreakSizer(ladder)
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{positions: make(map[string]*domain.Position)},
+			&mockTradeRepo{trades: history}, &mockStrategy{}, WithStreakSizing(sizer))
+		require.NoError(t, err)
+		return service, exchange, sizer
+	}
+
+	t.Run("losing streak from the history halves the entry", func(t *testing.T) {
+		// Most recent first: two losses after a win
+		service, exchange, sizer := newService(t, []*domain.Position{{PNL: -5}, {PNL: -3}, {PNL: 10}})
+		service.restoreStreak(ctx)
+		assert.Equal(t, -2, sizer.Streak())
+
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "0.500", exchange.marketOrderQty)
+	})
+
+	t.Run("closing a winner extends the streak", func(t *testing.T) {
+		service, exchange, sizer := newService(t, []*domain.Position{{PNL: 4}})
+		service.restoreStreak(ctx)
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "1.000", exchange.marketOrderQty)
+
+		require.NoError(t, service.closePosition(ctx, service.positions.long, 2100, domain.CloseReasonTakeProfit))
+		assert.Equal(t, 2, sizer.Streak())
+		require.NoError(t, service.enterPosition(ctx, domain.PositionSideLong, 2000, time.Now()))
+		assert.Equal(t, "1.500", exchange.marketOrderQty)
+	})
+}
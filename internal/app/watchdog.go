@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+const (
+	// primaryIntervalDuration is the length of a primaryInterval kline.
+	primaryIntervalDuration = time.Minute
+	// staleStreamFactor is how many intervals may pass without a kline before the stream is stale.
+	staleStreamFactor = 2
+)
+
+// WithStreamWatchdog detects a primary kline stream that delivers nothing for more than twice
+// the interval, and gaps between consecutive klines. Either refills the kline cache from the
+// REST API and sends a warning notification. With pauseEntries, new entries wait until a kline
+// arrives that continues the cache again.
+func WithStreamWatchdog(pauseEntries bool) Option {
+	return func(s *TradingService) {
+		s.watchdog = true
+		s.watchdogPause = pauseEntries
+	}
+}
+
+// runStreamWatchdog checks the primary stream for stalls until ctx is canceled.
+func (s *TradingService) runStreamWatchdog(ctx context.Context) {
+	ticker := time.NewTicker(primaryIntervalDuration / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.checkStreamStale(ctx, now)
+		}
+	}
+}
+
+// checkStreamStale reports the stream as discontinuous when no final kline has arrived for
+// longer than staleStreamFactor intervals. A stall is reported once, until klines resume.
+func (s *TradingService) checkStreamStale(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastKlineAt.IsZero() || s.streamIssue != "" {
+		return
+	}
+	silence := now.Sub(s.lastKlineAt)
+	if silence <= staleStreamFactor*primaryIntervalDuration {
+		return
+	}
+	s.streamStalls++
+	s.markStreamDiscontinuous(ctx, now, fmt.Sprintf("no %s kline received for %s", primaryInterval, silence.Round(time.Second)))
+}
+
+// checkKlineContinuity records the arrival of a final primary kline and compares it with the
+// cache: a kline more than one interval after the last cached one opens a gap, a kline
+// continuing the cache restores continuity. Assumes the caller holds the lock.
+func (s *TradingService) checkKlineContinuity(ctx context.Context, kline *domain.Kline, now time.Time) {
+	s.lastKlineAt = now
+	klines := s.signals.Klines()
+	if len(klines) == 0 {
+		return
+	}
+
+	expected := klines[len(klines)-1].OpenTime.Add(primaryIntervalDuration)
+	switch {
+	case kline.OpenTime.After(expected):
+		missing := int(kline.OpenTime.Sub(expected) / primaryIntervalDuration)
+		s.streamGaps++
+		s.markStreamDiscontinuous(ctx, now, fmt.Sprintf("%d %s klines missing before %s", missing, primaryInterval, kline.OpenTime.UTC().Format(time.RFC3339)))
+	case kline.OpenTime.Equal(expected) && s.streamIssue != "":
+		s.logger.Info(ctx, "Kline stream continuity restored", map[string]interface{}{
+			"symbol": s.cfg.Symbol,
+			"issue":  s.streamIssue,
+			"since":  s.streamIssueSince,
+		})
+		s.notify(ctx, fmt.Sprintf("%s kline stream restored", s.cfg.Symbol),
+			fmt.Sprintf("Symbol: %s\nIssue: %s\nSince: %s", s.cfg.Symbol, s.streamIssue, s.streamIssueSince.UTC().Format(time.RFC3339)), nil)
+		s.streamIssue = ""
+		s.streamIssueSince = time.Time{}
+	}
+}
+
+// markStreamDiscontinuous records a stall or gap, refills the kline cache and sends a warning.
+// Assumes the caller holds the lock.
+func (s *TradingService) markStreamDiscontinuous(ctx context.Context, now time.Time, issue string) {
+	if s.streamIssue == "" {
+		s.streamIssueSince = now
+	}
+	s.streamIssue = issue
+
+	refilled := s.refillKlineCache(ctx, now)
+	s.logger.Warn(ctx, "Kline stream discontinuous", map[string]interface{}{
+		"symbol":        s.cfg.Symbol,
+		"issue":         issue,
+		"cacheRefilled": refilled,
+		"entriesPaused": s.watchdogPause,
+	})
+	body := fmt.Sprintf("Symbol: %s\nIssue: %s\nKline cache refilled: %t", s.cfg.Symbol, issue, refilled)
+	if s.watchdogPause {
+		body += "\nNew entries are paused until continuity is restored"
+	}
+	s.notify(ctx, fmt.Sprintf("%s kline stream discontinuous", s.cfg.Symbol), body, nil)
+}
+
+// refillKlineCache replaces the kline cache with closed klines from the REST API, dropping the
+// kline still forming at now. Failures are logged and keep the current cache. Assumes the
+// caller holds the lock.
+func (s *TradingService) refillKlineCache(ctx context.Context, now time.Time) bool {
+	limit := s.signals.Strategy().RequiredDataPoints()
+	if cached := len(s.signals.Klines()); cached > limit {
+		limit = cached
+	}
+	klines, err := s.exchange.GetKlines(ctx, s.cfg.Symbol, primaryInterval, limit+1)
+	if err != nil {
+		s.logger.Error(ctx, err, "Failed to refill kline cache", map[string]interface{}{"symbol": s.cfg.Symbol})
+		return false
+	}
+	if n := len(klines); n > 0 && klines[n-1].CloseTime.After(now) {
+		klines = klines[:n-1]
+	}
+	if len(klines) == 0 {
+		return false
+	}
+	s.signals.SetKlines(klines)
+	return true
+}
+
+// addToKlineCache adds a final primary kline to the signal engine's cache. With the watchdog
+// enabled, klines already in the cache (e.g., after a refill) replace the cached copy instead of
+// being appended twice. Assumes the caller holds the lock.
+func (s *TradingService) addToKlineCache(kline *domain.Kline) {
+	if s.watchdog {
+		s.signals.MergeKline(kline)
+		return
+	}
+	s.signals.AddKline(kline)
+}
+
+// streamBlocksEntries reports whether entries are paused because the primary stream is
+// discontinuous (an entry blocker of the gate). Assumes the caller holds the lock.
+func (s *TradingService) streamBlocksEntries() (bool, string) {
+	if s.watchdogPause && s.streamIssue != "" {
+		return true, "kline stream discontinuous: " + s.streamIssue
+	}
+	return false, ""
+}
+
+// streamStatus describes the primary stream's continuity. Assumes the caller holds the lock.
+func (s *TradingService) streamStatus() *ports.StreamStatus {
+	if !s.watchdog {
+		return nil
+	}
+	return &ports.StreamStatus{
+		LastKlineAt:   s.lastKlineAt,
+		Continuous:    s.streamIssue == "",
+		Issue:         s.streamIssue,
+		IssueSince:    s.streamIssueSince,
+		EntriesPaused: s.watchdogPause && s.streamIssue != "",
+		Gaps:          s.streamGaps,
+		Stalls:        s.streamStalls,
+	}
+}
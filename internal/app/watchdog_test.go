@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/config"
+	"cryptoMegaBot/internal/domain"
+)
+
+// minuteKlines returns final 1m klines opening at start plus each of the given minutes
+func minuteKlines(start time.Time, minutes ...int) []*domain.Kline {
+	klines := make([]*domain.Kline, len(minutes))
+	for i, m := range minutes {
+		open := start.Add(time.Duration(m) * time.Minute)
+		klines[i] = &domain.Kline{OpenTime: open, CloseTime: open.Add(time.Minute - time.Millisecond), Close: 2000, IsFinal: true}
+	}
+	return klines
+}
+
+func TestTradingService_StreamWatchdog(t *testing.T) {
+	cfg := &config.Config{
+		Symbol:    "ETHUSDT",
+		Quantity:  0.1,
+		StopLoss:  0.01,
+		MaxProfit: 0.02,
+		MaxOrders: 5,
+	}
+	start := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	newService := func(t *testing.T, pause bool) (*TradingService, *mockExchange, *mockNotifier) {
+		exchange := &mockExchange{}
+		notifier := &mockNotifier{}
+		service, err := NewTradingService(cfg, &mockLogger{}, exchange, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{},
+			WithStreamWatchdog(pause), WithNotifier(notifier))
+		require.NoError(t, err)
+		service.signals.klines = minuteKlines(start, 0, 1, 2, 3)
+		return service, exchange, notifier
+	}
+
+	t.Run("gap refills cache and pauses entries until continuous", func(t *testing.T) {
+		service, exchange, notifier := newService(t, true)
+		exchange.klines = minuteKlines(start, 0, 1, 2, 3, 4, 5, 6)
+
+		service.handleKlineEvent(minuteKlines(start, 6)[0])
+		assert.Equal(t, 1, service.streamGaps)
+		assert.Len(t, service.signals.klines, 7) // Refilled; the streamed kline replaced the REST copy
+		ok, reason := service.gate.Allow(domain.PositionSideLong)
+		assert.False(t, ok)
+		assert.Contains(t, reason, "2 1m klines missing")
+		status := service.Status(context.Background()).Stream
+		require.NotNil(t, status)
+		assert.False(t, status.Continuous)
+		assert.True(t, status.EntriesPaused)
+
+		service.handleKlineEvent(minuteKlines(start, 7)[0])
+		ok, _ = service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		assert.True(t, service.Status(context.Background()).Stream.Continuous)
+		assert.Len(t, service.signals.klines, 8)
+
+		service.notifications.Wait()
+		assert.ElementsMatch(t, []string{"ETHUSDT kline stream discontinuous", "ETHUSDT kline stream restored"}, notifier.subjects)
+	})
+
+	t.Run("refill drops the forming kline", func(t *testing.T) {
+		service, exchange, _ := newService(t, false)
+		now := start.Add(10*time.Minute + 30*time.Second)
+		exchange.klines = minuteKlines(start, 7, 8, 9, 10)
+		assert.True(t, service.refillKlineCache(context.Background(), now))
+		assert.Len(t, service.signals.klines, 3)
+		assert.Equal(t, start.Add(9*time.Minute), service.signals.klines[2].OpenTime)
+	})
+
+	t.Run("stall is reported once", func(t *testing.T) {
+		service, exchange, notifier := newService(t, false)
+		exchange.klinesErr = assert.AnError // The cache is kept when the refill fails
+		service.lastKlineAt = start.Add(4 * time.Minute)
+
+		service.checkStreamStale(context.Background(), start.Add(5*time.Minute+30*time.Second))
+		assert.Equal(t, 0, service.streamStalls)
+
+		service.checkStreamStale(context.Background(), start.Add(7*time.Minute))
+		service.checkStreamStale(context.Background(), start.Add(8*time.Minute))
+		assert.Equal(t, 1, service.streamStalls)
+		assert.Contains(t, service.streamIssue, "no 1m kline received for 3m0s")
+		assert.Len(t, service.signals.klines, 4)
+
+		// Entries continue without pausing
+		ok, _ := service.gate.Allow(domain.PositionSideLong)
+		assert.True(t, ok)
+		service.notifications.Wait()
+		assert.Len(t, notifier.subjects, 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service, err := NewTradingService(cfg, &mockLogger{}, &mockExchange{}, &mockPositionRepo{}, &mockTradeRepo{}, &mockStrategy{})
+		require.NoError(t, err)
+		service.signals.klines = minuteKlines(start, 0)
+		service.handleKlineEvent(minuteKlines(start, 5)[0])
+		assert.Equal(t, 0, service.streamGaps)
+		assert.Len(t, service.signals.klines, 2)
+		assert.Nil(t, service.Status(context.Background()).Stream)
+	})
+}
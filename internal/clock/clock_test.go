@@ -0,0 +1,30 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"cryptoMegaBot/internal/ports"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	var c ports.Clock = NewFake(start)
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Expected %v, got %v", start, got)
+	}
+
+	fake := c.(*Fake)
+	fake.Advance(90 * time.Minute)
+	if got := c.Now(); !got.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("Expected the clock to advance 90 minutes, got %v", got)
+	}
+	fake.Set(start)
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Expected the clock to be set back to %v, got %v", start, got)
+	}
+
+	if since := time.Since(Real{}.Now()); since < 0 || since > time.Second {
+		t.Errorf("Expected the real clock to return the system time, %v off", since)
+	}
+}
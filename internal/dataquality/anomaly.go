@@ -0,0 +1,270 @@
+// Package dataquality screens streamed klines for anomalies (zero volume, price jumps,
+// out-of-order timestamps, duplicate candles and inconsistent prices) before they reach a
+// strategy, and detects when anomalies cluster
+package dataquality
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"fmt"
+	"math"
+	"time"
+)
+
+// AnomalyType identifies what is wrong with a kline
+type AnomalyType string
+
+const (
+	AnomalyZeroVolume    AnomalyType = "ZERO_VOLUME"    // No volume traded in the interval
+	AnomalyPriceJump     AnomalyType = "PRICE_JUMP"     // Close moved more than JumpSigma standard deviations from the previous close
+	AnomalyOutOfOrder    AnomalyType = "OUT_OF_ORDER"   // Opened before the last accepted kline
+	AnomalyDuplicate     AnomalyType = "DUPLICATE"      // Same open time as the last accepted kline
+	AnomalyInvalidPrices AnomalyType = "INVALID_PRICES" // Non-positive prices, or high/low not bounding open and close
+)
+
+// Action is what is done with anomalous klines
+type Action string
+
+const (
+	ActionFlag   Action = "flag"   // Log and pass them on unchanged
+	ActionDrop   Action = "drop"   // Drop them
+	ActionRepair Action = "repair" // Fix them where possible (clamp jumps, rebuild high/low), drop the rest
+)
+
+// Anomaly is one problem found with a kline
+type Anomaly struct {
+	Type   AnomalyType
+	Detail string
+}
+
+func (a Anomaly) String() string {
+	return string(a.Type) + ": " + a.Detail
+}
+
+// Config configures the detector. Zero values take the defaults
+type Config struct {
+	Action        Action        // What to do with anomalous klines (default ActionFlag)
+	JumpSigma     float64       // Close-to-close return, in standard deviations of recent returns, that is a jump (default 8)
+	Window        int           // Recent returns the standard deviation is taken over (default 60)
+	ClusterCount  int           // Anomalies within ClusterWindow that form a cluster (default 5)
+	ClusterWindow time.Duration // Window anomalies are counted in for clusters (default 30m)
+}
+
+// Defaults of Config
+const (
+	defaultJumpSigma     = 8
+	defaultWindow        = 60
+	defaultClusterCount  = 5
+	defaultClusterWindow = 30 * time.Minute
+
+	// minJumpSamples is how many returns are needed before jumps are detected
+	minJumpSamples = 20
+)
+
+// Result is the outcome of screening one kline
+type Result struct {
+	Anomalies []Anomaly
+	// Kline is the kline to pass on: the original, a repaired copy, or nil if it was dropped
+	Kline *domain.Kline
+	// Clustered is set on the anomaly that completes a cluster; it's reported once per cluster,
+	// until the count falls below ClusterCount again
+	Clustered bool
+}
+
+// Detector screens the klines of one stream, oldest first. It isn't safe for concurrent use
+type Detector struct {
+	cfg       Config
+	last      *domain.Kline // Last accepted kline
+	returns   []float64     // Recent log returns of accepted closes, oldest first
+	anomalies []time.Time   // When recent anomalies were found, oldest first
+	clustered bool          // Whether the current cluster was reported
+}
+
+// NewDetector creates a detector, filling in the defaults
+func NewDetector(cfg Config) (*Detector, error) {
+	switch cfg.Action {
+	case "":
+		cfg.Action = ActionFlag
+	case ActionFlag, ActionDrop, ActionRepair:
+	default:
+		return nil, fmt.Errorf("unknown anomaly action %q", cfg.Action)
+	}
+	if cfg.JumpSigma < 0 || cfg.Window < 0 || cfg.ClusterCount < 0 || cfg.ClusterWindow < 0 {
+		return nil, fmt.Errorf("anomaly detection settings can't be negative")
+	}
+	if cfg.JumpSigma == 0 {
+		cfg.JumpSigma = defaultJumpSigma
+	}
+	if cfg.Window == 0 {
+		cfg.Window = defaultWindow
+	}
+	if cfg.ClusterCount == 0 {
+		cfg.ClusterCount = defaultClusterCount
+	}
+	if cfg.ClusterWindow == 0 {
+		cfg.ClusterWindow = defaultClusterWindow
+	}
+	return &Detector{cfg: cfg}, nil
+}
+
+// Seed accepts historical klines (oldest first) without screening them, so jumps can be
+// detected from the first streamed kline
+func (d *Detector) Seed(klines []*domain.Kline) {
+	for _, k := range klines {
+		if k != nil && (d.last == nil || k.OpenTime.After(d.last.OpenTime)) {
+			d.accept(k)
+		}
+	}
+}
+
+// Check screens a final kline received at now and applies the configured action
+func (d *Detector) Check(kline *domain.Kline, now time.Time) Result {
+	anomalies := d.inspect(kline)
+	if len(anomalies) == 0 {
+		d.accept(kline)
+		return Result{Kline: kline}
+	}
+
+	result := Result{Anomalies: anomalies, Clustered: d.recordAnomaly(now)}
+	switch d.cfg.Action {
+	case ActionFlag:
+		result.Kline = kline
+	case ActionRepair:
+		result.Kline = d.repair(kline, anomalies)
+	}
+	if result.Kline != nil && !hasOrderingAnomaly(anomalies) {
+		d.accept(result.Kline)
+	}
+	return result
+}
+
+// inspect returns the anomalies of kline relative to the accepted klines
+func (d *Detector) inspect(kline *domain.Kline) []Anomaly {
+	var anomalies []Anomaly
+	if d.last != nil {
+		switch {
+		case kline.OpenTime.Equal(d.last.OpenTime):
+			anomalies = append(anomalies, Anomaly{AnomalyDuplicate, "opened at " + kline.OpenTime.UTC().Format(time.RFC3339) + " like the previous kline"})
+		case kline.OpenTime.Before(d.last.OpenTime):
+			anomalies = append(anomalies, Anomaly{AnomalyOutOfOrder, fmt.Sprintf("opened at %s, before the previous kline at %s",
+				kline.OpenTime.UTC().Format(time.RFC3339), d.last.OpenTime.UTC().Format(time.RFC3339))})
+		}
+	}
+	if kline.Open <= 0 || kline.High <= 0 || kline.Low <= 0 || kline.Close <= 0 {
+		anomalies = append(anomalies, Anomaly{AnomalyInvalidPrices, fmt.Sprintf("non-positive price (O %g H %g L %g C %g)", kline.Open, kline.High, kline.Low, kline.Close)})
+		return anomalies // The checks below need valid prices
+	}
+	if kline.High < max(kline.Open, kline.Close, kline.Low) || kline.Low > min(kline.Open, kline.Close, kline.High) {
+		anomalies = append(anomalies, Anomaly{AnomalyInvalidPrices, fmt.Sprintf("high %g and low %g don't bound open %g and close %g", kline.High, kline.Low, kline.Open, kline.Close)})
+	}
+	if kline.Volume <= 0 {
+		anomalies = append(anomalies, Anomaly{AnomalyZeroVolume, "no volume traded"})
+	}
+	if limit, ok := d.jumpLimit(); ok && d.last != nil && !hasOrderingAnomaly(anomalies) {
+		if r := math.Log(kline.Close / d.last.Close); math.Abs(r) > limit {
+			anomalies = append(anomalies, Anomaly{AnomalyPriceJump, fmt.Sprintf("close moved %.2f%% from %g, more than %.2f%% (%g sigma)",
+				(math.Exp(r)-1)*100, d.last.Close, (math.Exp(limit)-1)*100, d.cfg.JumpSigma)})
+		}
+	}
+	return anomalies
+}
+
+// jumpLimit returns the largest absolute log return that isn't a jump, once enough returns were seen
+func (d *Detector) jumpLimit() (float64, bool) {
+	if len(d.returns) < minJumpSamples {
+		return 0, false
+	}
+	var mean, variance float64
+	for _, r := range d.returns {
+		mean += r
+	}
+	mean /= float64(len(d.returns))
+	for _, r := range d.returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(d.returns)-1))
+	if stdDev == 0 {
+		return 0, false // A flat market gives no scale to measure jumps by
+	}
+	return d.cfg.JumpSigma * stdDev, true
+}
+
+// repair returns a fixed copy of kline, or nil if its anomalies can't be repaired
+func (d *Detector) repair(kline *domain.Kline, anomalies []Anomaly) *domain.Kline {
+	fixed := *kline
+	for _, a := range anomalies {
+		switch a.Type {
+		case AnomalyOutOfOrder, AnomalyDuplicate:
+			return nil // Already superseded by the accepted kline
+		case AnomalyInvalidPrices:
+			if fixed.Open <= 0 || fixed.High <= 0 || fixed.Low <= 0 || fixed.Close <= 0 {
+				return nil
+			}
+			fixed.High, fixed.Low = max(fixed.Open, fixed.High, fixed.Low, fixed.Close), min(fixed.Open, fixed.High, fixed.Low, fixed.Close)
+		case AnomalyPriceJump:
+			// Clamp the prices to the largest move that isn't a jump
+			limit, _ := d.jumpLimit()
+			lower, upper := d.last.Close*math.Exp(-limit), d.last.Close*math.Exp(limit)
+			clamp := func(p float64) float64 { return min(max(p, lower), upper) }
+			fixed.Open, fixed.High, fixed.Low, fixed.Close = clamp(fixed.Open), clamp(fixed.High), clamp(fixed.Low), clamp(fixed.Close)
+		case AnomalyZeroVolume:
+			// Nothing to repair: the prices may still be right
+		}
+	}
+	return &fixed
+}
+
+// accept makes kline the last accepted one and records its return
+func (d *Detector) accept(kline *domain.Kline) {
+	if d.last != nil && d.last.Close > 0 && kline.Close > 0 {
+		d.returns = append(d.returns, math.Log(kline.Close/d.last.Close))
+		if len(d.returns) > d.cfg.Window {
+			d.returns = d.returns[len(d.returns)-d.cfg.Window:]
+		}
+	}
+	d.last = kline
+}
+
+// recordAnomaly counts an anomaly found at now and reports whether it completes a cluster
+func (d *Detector) recordAnomaly(now time.Time) bool {
+	d.anomalies = append(d.anomalies, now)
+	cutoff := now.Add(-d.cfg.ClusterWindow)
+	for len(d.anomalies) > 0 && !d.anomalies[0].After(cutoff) {
+		d.anomalies = d.anomalies[1:]
+	}
+	if len(d.anomalies) < d.cfg.ClusterCount {
+		d.clustered = false
+		return false
+	}
+	if d.clustered {
+		return false
+	}
+	d.clustered = true
+	return true
+}
+
+// RecentAnomalies returns the number of anomalies within the cluster window before now
+func (d *Detector) RecentAnomalies(now time.Time) int {
+	cutoff := now.Add(-d.cfg.ClusterWindow)
+	n := 0
+	for _, t := range d.anomalies {
+		if t.After(cutoff) {
+			n++
+		}
+	}
+	return n
+}
+
+// Config returns the detector's configuration with the defaults filled in
+func (d *Detector) Config() Config {
+	return d.cfg
+}
+
+// hasOrderingAnomaly reports whether the kline doesn't follow the accepted ones
+func hasOrderingAnomaly(anomalies []Anomaly) bool {
+	for _, a := range anomalies {
+		if a.Type == AnomalyOutOfOrder || a.Type == AnomalyDuplicate {
+			return true
+		}
+	}
+	return false
+}
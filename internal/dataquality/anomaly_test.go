@@ -0,0 +1,186 @@
+package dataquality
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// kline returns a consistent 1m kline opening at start plus minute
+func kline(minute int, close float64) *domain.Kline {
+	open := start.Add(time.Duration(minute) * time.Minute)
+	return &domain.Kline{
+		OpenTime:  open,
+		CloseTime: open.Add(time.Minute - time.Millisecond),
+		Open:      close,
+		High:      close + 1,
+		Low:       close - 1,
+		Close:     close,
+		Volume:    10,
+		IsFinal:   true,
+	}
+}
+
+// history returns n klines oscillating by about 0.1% around 2000
+func history(n int) []*domain.Kline {
+	klines := make([]*domain.Kline, n)
+	for i := range klines {
+		klines[i] = kline(i, 2000+2*math.Sin(float64(i)))
+	}
+	return klines
+}
+
+func newDetector(t *testing.T, cfg Config) *Detector {
+	t.Helper()
+	d, err := NewDetector(cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	d.Seed(history(40))
+	return d
+}
+
+func types(anomalies []Anomaly) []AnomalyType {
+	out := make([]AnomalyType, len(anomalies))
+	for i, a := range anomalies {
+		out[i] = a.Type
+	}
+	return out
+}
+
+func TestNewDetector(t *testing.T) {
+	d, err := NewDetector(Config{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cfg := d.Config()
+	if cfg.Action != ActionFlag || cfg.JumpSigma != defaultJumpSigma || cfg.ClusterWindow != defaultClusterWindow {
+		t.Errorf("Defaults not applied: %+v", cfg)
+	}
+	if _, err := NewDetector(Config{Action: "ignore"}); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+	if _, err := NewDetector(Config{JumpSigma: -1}); err == nil {
+		t.Error("Expected error for negative jump sigma")
+	}
+}
+
+func TestDetector_Check(t *testing.T) {
+	now := start.Add(time.Hour)
+
+	t.Run("normal kline passes", func(t *testing.T) {
+		d := newDetector(t, Config{Action: ActionDrop})
+		k := kline(40, 2001)
+		result := d.Check(k, now)
+		if len(result.Anomalies) != 0 || result.Kline != k {
+			t.Errorf("Unexpected result %+v", result)
+		}
+	})
+
+	t.Run("flag passes anomalies on", func(t *testing.T) {
+		d := newDetector(t, Config{Action: ActionFlag})
+		k := kline(40, 2001)
+		k.Volume = 0
+		result := d.Check(k, now)
+		if got := types(result.Anomalies); len(got) != 1 || got[0] != AnomalyZeroVolume {
+			t.Errorf("Expected zero volume, got %v", got)
+		}
+		if result.Kline != k {
+			t.Error("Flagged kline should be passed on unchanged")
+		}
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		d := newDetector(t, Config{Action: ActionDrop})
+		jump := kline(40, 2200)
+		result := d.Check(jump, now)
+		if got := types(result.Anomalies); len(got) != 1 || got[0] != AnomalyPriceJump {
+			t.Errorf("Expected price jump, got %v", got)
+		}
+		if result.Kline != nil {
+			t.Error("Jump should be dropped")
+		}
+		// The dropped kline isn't the reference: the next one is compared with the last accepted close
+		if result := d.Check(kline(41, 2001), now); len(result.Anomalies) != 0 {
+			t.Errorf("Unexpected anomalies %v", result.Anomalies)
+		}
+		if got := types(d.Check(kline(41, 2001), now).Anomalies); len(got) != 1 || got[0] != AnomalyDuplicate {
+			t.Errorf("Expected duplicate, got %v", got)
+		}
+		if got := types(d.Check(kline(30, 2001), now).Anomalies); len(got) != 1 || got[0] != AnomalyOutOfOrder {
+			t.Errorf("Expected out of order, got %v", got)
+		}
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		d := newDetector(t, Config{Action: ActionRepair})
+		jump := kline(40, 2200)
+		result := d.Check(jump, now)
+		if result.Kline == nil || result.Kline == jump {
+			t.Fatal("Expected a repaired copy")
+		}
+		if result.Kline.Close >= 2050 || result.Kline.Close <= 2000 || result.Kline.High < result.Kline.Close {
+			t.Errorf("Jump not clamped: %+v", result.Kline)
+		}
+		if jump.Close != 2200 {
+			t.Error("Original kline was modified")
+		}
+
+		bad := kline(41, result.Kline.Close)
+		bad.High, bad.Low = bad.Close-5, bad.Close+5
+		result = d.Check(bad, now)
+		if got := types(result.Anomalies); len(got) != 1 || got[0] != AnomalyInvalidPrices {
+			t.Fatalf("Expected invalid prices, got %v", got)
+		}
+		if result.Kline == nil || result.Kline.High != bad.Close+5 || result.Kline.Low != bad.Close-5 {
+			t.Errorf("High/low not rebuilt: %+v", result.Kline)
+		}
+
+		zero := kline(42, 0)
+		if result := d.Check(zero, now); result.Kline != nil {
+			t.Error("Non-positive prices can't be repaired")
+		}
+	})
+
+	t.Run("no jump detection without enough history", func(t *testing.T) {
+		d, _ := NewDetector(Config{})
+		d.Seed(history(5))
+		if result := d.Check(kline(5, 2200), now); len(result.Anomalies) != 0 {
+			t.Errorf("Unexpected anomalies %v", result.Anomalies)
+		}
+	})
+}
+
+func TestDetector_Clusters(t *testing.T) {
+	d := newDetector(t, Config{ClusterCount: 3, ClusterWindow: 10 * time.Minute})
+	now := start.Add(time.Hour)
+	zeroVolume := func(minute int) *domain.Kline {
+		k := kline(minute, 2000)
+		k.Volume = 0
+		return k
+	}
+
+	var alerts []int
+	for i := 0; i < 5; i++ {
+		if d.Check(zeroVolume(40+i), now.Add(time.Duration(i)*time.Minute)).Clustered {
+			alerts = append(alerts, i)
+		}
+	}
+	if len(alerts) != 1 || alerts[0] != 2 {
+		t.Errorf("Expected a single alert on the third anomaly, got %v", alerts)
+	}
+	if n := d.RecentAnomalies(now.Add(4 * time.Minute)); n != 5 {
+		t.Errorf("Expected 5 recent anomalies, got %d", n)
+	}
+
+	// Once the cluster has passed, a new one alerts again
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if clustered := d.Check(zeroVolume(50+i), later.Add(time.Duration(i)*time.Minute)).Clustered; clustered != (i == 2) {
+			t.Errorf("Anomaly %d: clustered %t", i, clustered)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// BacktestRun records one backtest: the strategy and parameters it ran with, the data it ran on
+// and the metrics it produced, so runs can be compared later.
+type BacktestRun struct {
+	ID         int64              // Unique identifier (from DB)
+	Strategy   string             // Strategy name, e.g. "MACrossover"
+	Symbol     string             // Trading symbol (e.g., "ETHUSDT")
+	Interval   string             // Base kline interval of the run
+	Params     map[string]float64 // Strategy and backtest parameters, e.g. "take_profit": 0.02
+	ConfigHash string             // Hash of the strategy and parameters (see ComputeConfigHash)
+	DataStart  time.Time          // Open time of the first kline
+	DataEnd    time.Time          // Close time of the last kline
+	Seed       int64              // Random seed, to reproduce the run
+	CreatedAt  time.Time          // When the run finished
+
+	// Metrics
+	TotalTrades  int
+	WinRate      float64
+	TotalProfit  float64
+	MaxDrawdown  float64
+	ProfitFactor float64
+	SharpeRatio  float64
+	FinalBalance float64
+}
+
+// ComputeConfigHash returns a short, git-like hash of the strategy name and parameters. Runs with
+// the same hash used the same configuration, whatever data they ran on.
+func (r *BacktestRun) ComputeConfigHash() string {
+	// Maps are marshaled with sorted keys, so equal parameters always hash the same
+	data, _ := json.Marshal(struct {
+		Strategy string             `json:"strategy"`
+		Params   map[string]float64 `json:"params"`
+	}{r.Strategy, r.Params})
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
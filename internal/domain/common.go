@@ -8,6 +8,32 @@ const (
 	Sell OrderSide = "SELL"
 )
 
+// PositionSide identifies which side of a hedge-mode account a position or order belongs to.
+// One-way mode accounts use PositionSideBoth for every order.
+type PositionSide string
+
+const (
+	PositionSideBoth  PositionSide = "BOTH"
+	PositionSideLong  PositionSide = "LONG"
+	PositionSideShort PositionSide = "SHORT"
+)
+
+// EntrySide returns the order side that opens a position on this side.
+func (s PositionSide) EntrySide() OrderSide {
+	if s == PositionSideShort {
+		return Sell
+	}
+	return Buy
+}
+
+// ExitSide returns the order side that reduces or closes a position on this side.
+func (s PositionSide) ExitSide() OrderSide {
+	if s == PositionSideShort {
+		return Buy
+	}
+	return Sell
+}
+
 // PositionStatus represents the status of a trading position.
 type PositionStatus string
 
@@ -31,4 +57,38 @@ const (
 	CloseReasonVolatilityDrop CloseReason = "VOLATILITY_DROP" // Position closed due to volatility drop
 	CloseReasonConsolidation  CloseReason = "CONSOLIDATION"   // Position closed due to price consolidation
 	CloseReasonMarketClose    CloseReason = "MARKET_CLOSE"    // Position closed due to approaching market close
+	CloseReasonTrailingStop   CloseReason = "TRAILING_STOP"   // Trailing stop hit after the position was in profit
+	CloseReasonBreakEven      CloseReason = "BREAK_EVEN"      // Stop moved to (or above) entry was hit
+	CloseReasonResistance     CloseReason = "RESISTANCE"      // Profitable position reached a volume profile resistance zone
+	CloseReasonSafeMode       CloseReason = "SAFE_MODE"       // Closed when the exchange went into maintenance or became unreachable
+	CloseReasonSessionEnd     CloseReason = "SESSION_END"     // Flattened at the configured end of the trading session
+	CloseReasonSlippage       CloseReason = "SLIPPAGE"        // Exited right after an entry that filled too far from the signal price
+	CloseReasonEquityTrail    CloseReason = "EQUITY_TRAIL"    // Flattened when the account gave back its locked-in daily profit
+)
+
+// SignalSource identifies the kind of signal that triggered an entry.
+type SignalSource string
+
+const (
+	SignalSourceCrossover SignalSource = "crossover" // Fast MA crossed above slow MA
+	SignalSourcePullback  SignalSource = "pullback"  // Pullback within an established uptrend
+	SignalSourceScalp     SignalSource = "scalp"     // Short timeframe scalping opportunity
+	SignalSourceTrend     SignalSource = "trend"     // Price above trend filters (basic strategy)
+	SignalSourceUnknown   SignalSource = ""          // Not tagged (e.g., trades recorded before tagging)
+)
+
+// MarginType represents the margin mode of a futures position.
+type MarginType string
+
+const (
+	MarginTypeIsolated MarginType = "ISOLATED"
+	MarginTypeCrossed  MarginType = "CROSSED"
+)
+
+// QuantityMode is the currency the configured trade quantity is given in.
+type QuantityMode string
+
+const (
+	QuantityModeBase  QuantityMode = "base"  // Base asset amount (e.g., 0.5 ETH)
+	QuantityModeQuote QuantityMode = "quote" // Quote currency amount converted at the entry price (e.g., 500 USDT)
 )
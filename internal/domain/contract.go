@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"cryptoMegaBot/internal/money"
+)
+
+// ContractType is how a futures symbol is margined and settled.
+type ContractType string
+
+const (
+	// ContractTypeUSDT is a linear contract margined and settled in USDT (e.g., ETHUSDT); quantities
+	// are in the base asset.
+	ContractTypeUSDT ContractType = "USDT_MARGINED"
+	// ContractTypeCoin is an inverse contract margined and settled in the base coin (e.g.,
+	// ETHUSD_PERP); quantities are whole contracts of a fixed USD value.
+	ContractTypeCoin ContractType = "COIN_MARGINED"
+)
+
+// ParseContractType parses "USDT_MARGINED" (or "USDT", or empty) and "COIN_MARGINED" (or "COIN").
+func ParseContractType(value string) (ContractType, error) {
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "", "USDT", string(ContractTypeUSDT):
+		return ContractTypeUSDT, nil
+	case "COIN", string(ContractTypeCoin):
+		return ContractTypeCoin, nil
+	default:
+		return "", fmt.Errorf("invalid contract type %q: must be USDT_MARGINED or COIN_MARGINED", value)
+	}
+}
+
+// Contract describes the futures contract a symbol trades. The zero value is a USDT-margined
+// contract, so positions and configurations without one keep the linear math.
+type Contract struct {
+	Type ContractType // USDT_MARGINED or COIN_MARGINED; empty is treated as USDT_MARGINED
+	Size float64      // USD value of one COIN_MARGINED contract (e.g., 10 for ETHUSD_PERP); unused for USDT_MARGINED
+}
+
+// IsInverse reports whether the contract is COIN-margined, with PnL and margin in the base coin.
+func (c Contract) IsInverse() bool {
+	return c.Type == ContractTypeCoin
+}
+
+// Validate checks that a COIN-margined contract has a positive size.
+func (c Contract) Validate() error {
+	if c.IsInverse() && c.Size <= 0 {
+		return fmt.Errorf("contract size %v must be positive for %s contracts", c.Size, ContractTypeCoin)
+	}
+	return nil
+}
+
+// MarginAsset returns the asset symbol's positions are margined in: the base coin of a
+// COIN-margined symbol (ETH for ETHUSD_PERP), USDT otherwise.
+func (c Contract) MarginAsset(symbol string) string {
+	if !c.IsInverse() {
+		return "USDT"
+	}
+	if i := strings.Index(strings.ToUpper(symbol), "USD"); i > 0 {
+		return strings.ToUpper(symbol[:i])
+	}
+	return strings.ToUpper(symbol)
+}
+
+// Notional returns the value of quantity at price in the margin asset: price times quantity for
+// USDT-margined contracts, the contracts' USD value divided by price for COIN-margined ones.
+func (c Contract) Notional(price, quantity float64) float64 {
+	if !c.IsInverse() {
+		return money.Notional(price, quantity)
+	}
+	return money.InverseNotional(price, quantity, c.Size)
+}
+
+// PnL returns the gross profit, in the margin asset, of quantity entered at entryPrice and exited
+// at exitPrice. COIN-margined PnL is non-linear in price: it is the difference of the contracts'
+// coin value at the two prices.
+func (c Contract) PnL(entryPrice, exitPrice, quantity float64, short bool) float64 {
+	if !c.IsInverse() {
+		return money.PnL(entryPrice, exitPrice, quantity, short)
+	}
+	return money.InversePnL(entryPrice, exitPrice, quantity, c.Size, short)
+}
+
+// Quantity returns the order quantity worth quote (a USD or USDT amount) at price: base asset for
+// USDT-margined contracts, whole contracts for COIN-margined ones (which don't depend on price).
+// Rounding to the step size is left to the caller.
+func (c Contract) Quantity(quote, price float64) float64 {
+	if !c.IsInverse() {
+		if price <= 0 {
+			return 0
+		}
+		return money.Float(money.Decimal(quote).Div(money.Decimal(price)))
+	}
+	return money.Float(money.Decimal(quote).Div(money.Decimal(c.Size)).Floor())
+}
+
+// LiquidationPrice returns the mark price at which an isolated-margin position entered at
+// entryPrice is liquidated (see Position.LiquidationPrice). It returns 0 for positions that
+// can't be liquidated: USDT-margined longs and COIN-margined shorts without leverage.
+func (c Contract) LiquidationPrice(entryPrice float64, leverage int, maintenanceMarginRate float64, short bool) float64 {
+	l := float64(leverage)
+	if l < 1 {
+		l = 1
+	}
+	if !c.IsInverse() {
+		if short {
+			return entryPrice * (1 + 1/l) / (1 + maintenanceMarginRate)
+		}
+		return entryPrice * (1 - 1/l) / (1 - maintenanceMarginRate)
+	}
+	// The coin margin is contracts*Size/(entry*l); the position is liquidated when the margin left
+	// after the loss falls to maintenanceMarginRate times the contracts' coin value at the mark
+	if short {
+		if l == 1 {
+			return 0
+		}
+		return entryPrice * (1 - maintenanceMarginRate) / (1 - 1/l)
+	}
+	return entryPrice * (1 + maintenanceMarginRate) / (1 + 1/l)
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseContractType(t *testing.T) {
+	for value, expected := range map[string]ContractType{
+		"":               ContractTypeUSDT,
+		"usdt":           ContractTypeUSDT,
+		"USDT_MARGINED":  ContractTypeUSDT,
+		"coin":           ContractTypeCoin,
+		" COIN_MARGINED": ContractTypeCoin,
+	} {
+		got, err := ParseContractType(value)
+		if err != nil || got != expected {
+			t.Errorf("ParseContractType(%q) = %q, %v; expected %q", value, got, err, expected)
+		}
+	}
+	if _, err := ParseContractType("quanto"); err == nil {
+		t.Error("Expected an error for an unknown contract type")
+	}
+}
+
+func TestContract(t *testing.T) {
+	linear := Contract{}
+	inverse := Contract{Type: ContractTypeCoin, Size: 10}
+
+	if linear.IsInverse() || !inverse.IsInverse() {
+		t.Error("Expected only the COIN-margined contract to be inverse")
+	}
+	if err := (Contract{Type: ContractTypeCoin}).Validate(); err == nil {
+		t.Error("Expected a COIN-margined contract without a size to be invalid")
+	}
+	if err := linear.Validate(); err != nil {
+		t.Errorf("Expected a USDT-margined contract without a size to be valid, got %v", err)
+	}
+	if got := inverse.MarginAsset("ETHUSD_PERP"); got != "ETH" {
+		t.Errorf("Expected margin asset ETH, got %s", got)
+	}
+	if got := linear.MarginAsset("ETHUSDT"); got != "USDT" {
+		t.Errorf("Expected margin asset USDT, got %s", got)
+	}
+
+	// Sizing: 1000 USD buys 0.5 ETH at 2000, or 100 contracts of 10 USD at any price
+	if got := linear.Quantity(1000, 2000); got != 0.5 {
+		t.Errorf("Expected a quantity of 0.5, got %v", got)
+	}
+	if got := inverse.Quantity(1005, 2000); got != 100 {
+		t.Errorf("Expected 100 whole contracts, got %v", got)
+	}
+	if got := inverse.Notional(2000, 100); got != 0.5 {
+		t.Errorf("Expected a notional of 0.5 ETH, got %v", got)
+	}
+	if got := inverse.PnL(2000, 2500, 100, false); got != 0.1 {
+		t.Errorf("Expected a PnL of 0.1 ETH, got %v", got)
+	}
+}
+
+func TestContractLiquidationPrice(t *testing.T) {
+	inverse := Contract{Type: ContractTypeCoin, Size: 10}
+	const mmr, entry, contracts = 0.005, 2000.0, 100.0
+	for _, short := range []bool{false, true} {
+		liq := inverse.LiquidationPrice(entry, 10, mmr, short)
+		// At the liquidation price the coin margin left equals the maintenance margin
+		margin := inverse.Notional(entry, contracts) / 10
+		left := margin + inverse.PnL(entry, liq, contracts, short)
+		if math.Abs(left-mmr*inverse.Notional(liq, contracts)) > 1e-12 {
+			t.Errorf("short=%v: expected margin left %v to equal the maintenance margin %v at %v", short, left, mmr*inverse.Notional(liq, contracts), liq)
+		}
+	}
+	if got := inverse.LiquidationPrice(entry, 1, mmr, true); got != 0 {
+		t.Errorf("Expected an unleveraged inverse short never to be liquidated, got %f", got)
+	}
+	if got := inverse.LiquidationPrice(entry, 1, mmr, false); got <= 0 || got >= entry {
+		t.Errorf("Expected an unleveraged inverse long to be liquidated below the entry, got %f", got)
+	}
+}
+
+func TestInversePosition(t *testing.T) {
+	pos := &Position{Contract: Contract{Type: ContractTypeCoin, Size: 10}, Leverage: 5}
+	if err := pos.ApplyPartialFill(200, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if err := pos.ApplyPartialFill(100, 4000); err != nil {
+		t.Fatal(err)
+	}
+	if pos.EntryPrice != 2400 {
+		t.Errorf("Expected the harmonic average entry 2400, got %v", pos.EntryPrice)
+	}
+	if err := pos.Open(); err != nil {
+		t.Fatal(err)
+	}
+	pos.Fees = 0.001
+	if err := pos.Close(3000, time.Now(), CloseReasonTakeProfit); err != nil {
+		t.Fatal(err)
+	}
+	// 3000 USD of contracts: 1.25 ETH at entry, 1 ETH at exit
+	if math.Abs(pos.PNL-0.249) > 1e-12 {
+		t.Errorf("Expected a PNL of 0.249 ETH, got %v", pos.PNL)
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TradeDirection restricts the sides new positions may be opened on.
+type TradeDirection string
+
+const (
+	TradeDirectionBoth  TradeDirection = "both"  // Long and short entries
+	TradeDirectionLong  TradeDirection = "long"  // Long entries only
+	TradeDirectionShort TradeDirection = "short" // Short entries only
+)
+
+// ParseTradeDirection parses "both" (or empty), "long" (or "long_only") and "short" (or
+// "short_only"), ignoring case.
+func ParseTradeDirection(value string) (TradeDirection, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", string(TradeDirectionBoth):
+		return TradeDirectionBoth, nil
+	case string(TradeDirectionLong), "long_only":
+		return TradeDirectionLong, nil
+	case string(TradeDirectionShort), "short_only":
+		return TradeDirectionShort, nil
+	default:
+		return "", fmt.Errorf("invalid trade direction %q: must be both, long or short", value)
+	}
+}
+
+// Allows reports whether positions may be opened on side. The zero value allows both sides.
+func (d TradeDirection) Allows(side PositionSide) bool {
+	switch d {
+	case TradeDirectionLong:
+		return side != PositionSideShort
+	case TradeDirectionShort:
+		return side == PositionSideShort
+	default:
+		return true
+	}
+}
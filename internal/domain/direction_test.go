@@ -0,0 +1,41 @@
+package domain
+
+import "testing"
+
+func TestParseTradeDirection(t *testing.T) {
+	for value, expected := range map[string]TradeDirection{
+		"":           TradeDirectionBoth,
+		"BOTH":       TradeDirectionBoth,
+		"long":       TradeDirectionLong,
+		"long_only":  TradeDirectionLong,
+		" Short":     TradeDirectionShort,
+		"short_only": TradeDirectionShort,
+	} {
+		got, err := ParseTradeDirection(value)
+		if err != nil || got != expected {
+			t.Errorf("ParseTradeDirection(%q) = %q, %v; expected %q", value, got, err, expected)
+		}
+	}
+	if _, err := ParseTradeDirection("sideways"); err == nil {
+		t.Error("Expected an error for an unknown trade direction")
+	}
+}
+
+func TestTradeDirectionAllows(t *testing.T) {
+	for _, tt := range []struct {
+		direction   TradeDirection
+		long, short bool
+	}{
+		{"", true, true},
+		{TradeDirectionBoth, true, true},
+		{TradeDirectionLong, true, false},
+		{TradeDirectionShort, false, true},
+	} {
+		if got := tt.direction.Allows(PositionSideLong); got != tt.long {
+			t.Errorf("%q allows long: got %v, expected %v", tt.direction, got, tt.long)
+		}
+		if got := tt.direction.Allows(PositionSideShort); got != tt.short {
+			t.Errorf("%q allows short: got %v, expected %v", tt.direction, got, tt.short)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// maxClientOrderIDLength is the longest client order ID the exchange accepts.
+const maxClientOrderIDLength = 36
+
+// EntryIntentStatus tracks an entry order from before it is placed until its position is saved.
+type EntryIntentStatus string
+
+const (
+	EntryIntentPending EntryIntentStatus = "PENDING" // Saved before placement; the outcome is not known yet
+	EntryIntentOpened  EntryIntentStatus = "OPENED"  // The order filled and its position was saved
+	EntryIntentFailed  EntryIntentStatus = "FAILED"  // The order never reached the exchange or didn't fill
+)
+
+// EntryIntent records an entry order before it is sent to the exchange, so that after a crash
+// between placing the order and saving the position a restart can find out whether it filled.
+type EntryIntent struct {
+	ClientOrderID string            // Deterministic order ID, see EntryClientOrderID
+	Symbol        string            // Trading symbol (e.g., "ETHUSDT")
+	Side          PositionSide      // LONG or SHORT
+	KlineOpenTime time.Time         // Open time of the kline whose signal triggered the entry
+	Quantity      float64           // Quantity ordered
+	Status        EntryIntentStatus // Current status
+	CreatedAt     time.Time         // When the intent was saved
+}
+
+// EntryClientOrderID derives the client order ID of the entry triggered by the kline opening at
+// klineOpenTime. The same signal always maps to the same ID, so a restart that processes the
+// kline again cannot enter twice. Symbols too long for the exchange's limit are hashed.
+func EntryClientOrderID(symbol string, side PositionSide, klineOpenTime time.Time) string {
+	return entryClientOrderID("cmb", symbol, side, klineOpenTime)
+}
+
+// LimitEntryClientOrderID derives the client order ID of a limit entry triggered by the kline
+// opening at klineOpenTime. It differs from EntryClientOrderID, so a market order falling back
+// for an expired limit entry of the same signal gets its own ID.
+func LimitEntryClientOrderID(symbol string, side PositionSide, klineOpenTime time.Time) string {
+	return entryClientOrderID("cml", symbol, side, klineOpenTime)
+}
+
+func entryClientOrderID(prefix, symbol string, side PositionSide, klineOpenTime time.Time) string {
+	sideCode := "L"
+	if side == PositionSideShort {
+		sideCode = "S"
+	}
+	id := fmt.Sprintf("%s-%s-%s-%d", prefix, symbol, sideCode, klineOpenTime.UnixMilli())
+	if len(id) <= maxClientOrderIDLength {
+		return id
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(symbol))
+	return fmt.Sprintf("%s-%08x-%s-%d", prefix, h.Sum32(), sideCode, klineOpenTime.UnixMilli())
+}
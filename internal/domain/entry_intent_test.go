@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryClientOrderID(t *testing.T) {
+	openTime := time.Date(2025, 6, 11, 12, 30, 0, 0, time.UTC)
+
+	long := EntryClientOrderID("ETHUSDT", PositionSideLong, openTime)
+	if long != "cmb-ETHUSDT-L-1749645000000" {
+		t.Errorf("Unexpected long client order ID %q", long)
+	}
+	if again := EntryClientOrderID("ETHUSDT", PositionSideLong, openTime.In(time.FixedZone("CEST", 2*3600))); again != long {
+		t.Errorf("Expected the same kline to give the same ID, got %q and %q", long, again)
+	}
+	if short := EntryClientOrderID("ETHUSDT", PositionSideShort, openTime); short == long || !strings.Contains(short, "-S-") {
+		t.Errorf("Expected a distinct short client order ID, got %q", short)
+	}
+	if next := EntryClientOrderID("ETHUSDT", PositionSideLong, openTime.Add(time.Minute)); next == long {
+		t.Error("Expected the next kline to give a new ID")
+	}
+
+	if limit := LimitEntryClientOrderID("ETHUSDT", PositionSideLong, openTime); limit != "cml-ETHUSDT-L-1749645000000" {
+		t.Errorf("Unexpected limit entry client order ID %q", limit)
+	}
+
+	longSymbol := EntryClientOrderID("1000000MOGUSDTPERPETUAL", PositionSideLong, openTime)
+	if len(longSymbol) > maxClientOrderIDLength {
+		t.Errorf("Expected at most %d characters, got %q", maxClientOrderIDLength, longSymbol)
+	}
+}
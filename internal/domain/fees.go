@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"time"
+
+	"cryptoMegaBot/internal/money"
+)
+
+// DefaultFundingInterval is the time between funding payments on Binance perpetual futures.
+const DefaultFundingInterval = 8 * time.Hour
+
+// FeeModel describes the cost of holding a long futures position: a fee on each fill and
+// funding paid periodically while the position is open.
+type FeeModel struct {
+	TakerRate       float64       // Fee rate charged on each fill (e.g., 0.0004 for 0.04%)
+	FundingRate     float64       // Funding rate paid per FundingInterval (e.g., 0.0001 for 0.01%)
+	FundingInterval time.Duration // Time between funding payments (0 uses DefaultFundingInterval)
+}
+
+// Fees returns the entry and exit fees for a round trip of quantity.
+func (f FeeModel) Fees(entryPrice, exitPrice, quantity float64) float64 {
+	return money.Fee(entryPrice, quantity, f.TakerRate) + money.Fee(exitPrice, quantity, f.TakerRate)
+}
+
+// Funding returns the funding paid on a position of quantity held for the given duration.
+// Only completed funding intervals are charged.
+func (f FeeModel) Funding(entryPrice, quantity float64, held time.Duration) float64 {
+	return money.Fee(entryPrice, quantity, f.FundingRate) * float64(f.fundingPeriods(held))
+}
+
+// BreakEvenPrice returns the exit price at which a long position entered at entryPrice and held
+// for the given duration closes with zero PNL after round-trip fees and accrued funding.
+// The result doesn't depend on quantity since every cost scales with it.
+func (f FeeModel) BreakEvenPrice(entryPrice float64, held time.Duration) float64 {
+	if f.TakerRate >= 1 {
+		return entryPrice // Invalid model; avoid dividing by zero or a negative number
+	}
+	funding := entryPrice * f.FundingRate * float64(f.fundingPeriods(held))
+	return (entryPrice*(1+f.TakerRate) + funding) / (1 - f.TakerRate)
+}
+
+// fundingPeriods returns the number of completed funding intervals in held.
+func (f FeeModel) fundingPeriods(held time.Duration) int64 {
+	interval := f.FundingInterval
+	if interval <= 0 {
+		interval = DefaultFundingInterval
+	}
+	if held <= 0 {
+		return 0
+	}
+	return int64(held / interval)
+}
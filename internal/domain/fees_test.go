@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFeeModelBreakEvenPrice(t *testing.T) {
+	model := FeeModel{TakerRate: 0.0004, FundingRate: 0.0001}
+
+	tests := []struct {
+		name string
+		held time.Duration
+	}{
+		{name: "no funding yet", held: 7 * time.Hour},
+		{name: "one funding payment", held: 8 * time.Hour},
+		{name: "three funding payments", held: 25 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, quantity := 2000.0, 0.5
+			exit := model.BreakEvenPrice(entry, tt.held)
+			if exit <= entry {
+				t.Fatalf("Expected break-even above entry %f, got %f", entry, exit)
+			}
+
+			pnl := (exit-entry)*quantity - model.Fees(entry, exit, quantity) - model.Funding(entry, quantity, tt.held)
+			if math.Abs(pnl) > 1e-9 {
+				t.Errorf("Expected zero PNL at break-even %f, got %f", exit, pnl)
+			}
+		})
+	}
+
+	if got := model.Funding(2000, 1, 25*time.Hour); math.Abs(got-0.6) > 1e-9 {
+		t.Errorf("Expected funding 0.6 for three completed intervals, got %f", got)
+	}
+	if got := (FeeModel{}).BreakEvenPrice(2000, time.Hour); got != 2000 {
+		t.Errorf("Expected a zero fee model to break even at entry, got %f", got)
+	}
+}
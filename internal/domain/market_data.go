@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// FundingRate is a funding rate settlement of a perpetual futures contract.
+type FundingRate struct {
+	Symbol      string    // Trading symbol
+	FundingTime time.Time // Settlement time
+	Rate        float64   // Funding rate; positive when longs pay shorts
+	MarkPrice   float64   // Mark price at settlement (zero if the exchange didn't report it)
+}
+
+// OpenInterest is a snapshot of the total open interest of a futures contract.
+type OpenInterest struct {
+	Symbol    string    // Trading symbol
+	Time      time.Time // Snapshot time
+	Period    string    // Snapshot period (e.g., "5m", "1h")
+	Contracts float64   // Open interest in contracts (base asset)
+	Value     float64   // Open interest in quote asset
+}
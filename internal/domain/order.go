@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// OrderPurpose tells why the bot sent an order.
+type OrderPurpose string
+
+const (
+	OrderPurposeEntry          OrderPurpose = "ENTRY"           // Opens a position
+	OrderPurposeScaleIn        OrderPurpose = "SCALE_IN"        // Adds to an open position
+	OrderPurposeExit           OrderPurpose = "EXIT"            // Closes a position
+	OrderPurposeStopLoss       OrderPurpose = "STOP_LOSS"       // Protective stop of a position
+	OrderPurposeTakeProfit     OrderPurpose = "TAKE_PROFIT"     // Take profit of a position
+	OrderPurposeEmergencyClose OrderPurpose = "EMERGENCY_CLOSE" // Unwinds an entry that couldn't be protected or saved
+)
+
+// OrderStatusRejected is the status of orders the exchange didn't accept (or that couldn't be sent).
+const OrderStatusRejected = "REJECTED"
+
+// Order is an order the bot sent to the exchange, kept so operators can audit what was sent.
+// Orders the exchange rejected are kept too, with their error.
+type Order struct {
+	ID            int64        // Unique identifier (from DB)
+	OrderID       int64        // Exchange's order ID; 0 if rejected
+	ClientOrderID string       // Client order ID sent with the order, if any
+	PositionID    int64        // Position the order belongs to; 0 if none was opened
+	Symbol        string       // Trading symbol (e.g., "ETHUSDT")
+	Side          OrderSide    // BUY or SELL
+	PositionSide  PositionSide // BOTH in one-way mode, LONG or SHORT in hedge mode
+	Type          string       // MARKET, LIMIT, STOP_MARKET or TAKE_PROFIT_MARKET
+	Purpose       OrderPurpose // Why the order was sent
+	Quantity      float64      // Ordered quantity (0 for orders closing the whole position)
+	Price         float64      // Limit price, for limit orders
+	StopPrice     float64      // Trigger price, for stop and take profit orders
+	Status        string       // Exchange's status in the order response (e.g., NEW, FILLED), or REJECTED
+	Error         string       // Why the order was rejected
+	CreatedAt     time.Time    // When the order was sent
+}
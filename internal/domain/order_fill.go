@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"cryptoMegaBot/internal/money"
+)
+
+// FillRole tells which order of a position a fill belongs to.
+type FillRole string
+
+const (
+	FillRoleEntry   FillRole = "ENTRY"    // Fill of the entry order
+	FillRoleScaleIn FillRole = "SCALE_IN" // Fill of a scale-in add
+	FillRoleExit    FillRole = "EXIT"     // Fill of the closing order
+)
+
+// OrderFill is one execution of an order the bot placed for a position.
+type OrderFill struct {
+	ID              int64     // Unique identifier (from DB)
+	PositionID      int64     // Position the order was placed for
+	OrderID         int64     // Exchange's order ID
+	TradeID         int64     // Exchange's trade ID of the execution
+	Symbol          string    // Trading symbol (e.g., "ETHUSDT")
+	Side            OrderSide // BUY or SELL
+	Role            FillRole  // Entry, scale-in add or exit
+	Price           float64   // Execution price
+	Quantity        float64   // Executed quantity
+	Commission      float64   // Commission charged for the execution
+	CommissionAsset string    // Asset the commission was charged in (e.g., USDT or BNB)
+	Time            time.Time // Execution time
+}
+
+// QuoteCommission returns the fill's commission if it was charged in the symbol's quote asset.
+// Commissions paid in other assets (e.g., BNB) can't be converted and count as zero.
+func (f *OrderFill) QuoteCommission() float64 {
+	if f.CommissionAsset != "" && strings.HasSuffix(f.Symbol, f.CommissionAsset) {
+		return f.Commission
+	}
+	return 0
+}
+
+// FillSummary aggregates the fills of one or more orders.
+type FillSummary struct {
+	Quantity   float64 // Total executed quantity
+	AvgPrice   float64 // Volume-weighted average execution price (0 without fills)
+	Commission float64 // Total commission in the quote asset
+}
+
+// SummarizeFills returns the total quantity, average price and quote commission of fills.
+func SummarizeFills(fills []*OrderFill) FillSummary {
+	var summary FillSummary
+	for _, f := range fills {
+		summary.AvgPrice = money.AveragePrice(summary.AvgPrice, summary.Quantity, f.Price, f.Quantity)
+		summary.Quantity = money.Float(money.Decimal(summary.Quantity).Add(money.Decimal(f.Quantity)))
+		summary.Commission = money.Float(money.Decimal(summary.Commission).Add(money.Decimal(f.QuoteCommission())))
+	}
+	return summary
+}
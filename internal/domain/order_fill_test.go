@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummarizeFills(t *testing.T) {
+	fills := []*OrderFill{
+		{Symbol: "ETHUSDT", Price: 2000, Quantity: 0.3, Commission: 0.24, CommissionAsset: "USDT"},
+		{Symbol: "ETHUSDT", Price: 2010, Quantity: 0.7, Commission: 0.5628, CommissionAsset: "USDT"},
+		{Symbol: "ETHUSDT", Price: 2010, Quantity: 0, Commission: 0.001, CommissionAsset: "BNB"}, // Not convertible
+	}
+	summary := SummarizeFills(fills)
+	if summary.Quantity != 1 {
+		t.Errorf("Quantity = %v, want 1", summary.Quantity)
+	}
+	if math.Abs(summary.AvgPrice-2007) > 1e-9 {
+		t.Errorf("AvgPrice = %v, want 2007", summary.AvgPrice)
+	}
+	if math.Abs(summary.Commission-0.8028) > 1e-9 {
+		t.Errorf("Commission = %v, want 0.8028", summary.Commission)
+	}
+
+	if empty := SummarizeFills(nil); empty != (FillSummary{}) {
+		t.Errorf("SummarizeFills(nil) = %+v, want the zero summary", empty)
+	}
+}
@@ -1,6 +1,21 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"cryptoMegaBot/internal/money"
+)
+
+// Position lifecycle errors.
+var (
+	ErrPositionAlreadyOpen = errors.New("position is already open")
+	ErrPositionClosed      = errors.New("position is already closed")
+	ErrPositionNotOpen     = errors.New("position is not open")
+	ErrInvalidQuantity     = errors.New("invalid position quantity")
+	ErrInvalidPrice        = errors.New("invalid price")
+)
 
 // Position represents a trading position held by the bot.
 type Position struct {
@@ -15,7 +30,8 @@ type Position struct {
 	EntryTime  time.Time      // Timestamp when the position was entered
 	ExitTime   time.Time      // Timestamp when the position was exited (zero value if open)
 	Status     PositionStatus // Current status (open, closed)
-	PNL        float64        // Profit and Loss for the position (calculated on close)
+	PNL        float64        // Profit and Loss for the position (calculated on close, net of Fees and Funding)
+	Side       PositionSide   `db:"side"` // LONG or SHORT; empty is treated as LONG
 
 	// Associated order IDs for SL/TP management (nullable in DB)
 	StopLossOrderID   *string     `db:"stop_loss_order_id"`
@@ -25,9 +41,185 @@ type Position struct {
 	// Trailing stop parameters
 	TrailingStopDistance float64 `db:"trailing_stop_distance"` // Distance for trailing stop in price units
 	TrailingStopPrice    float64 `db:"trailing_stop_price"`    // Current trailing stop price level
+
+	// Price excursions while open, as fractions of the entry price (not persisted)
+	MAE float64 // Maximum adverse excursion: furthest move against the position (e.g., 0.01 for 1%)
+	MFE float64 // Maximum favorable excursion: furthest move in the position's favor
+
+	// Scale-in state (see ScaleInPlan); EntryPrice is the blended price of all fills
+	ScaleInBasePrice float64 `db:"scale_in_base_price"` // Fill price of the initial entry the adds are measured from (0 without scaling in)
+	ScaleIns         int     `db:"scale_ins"`           // Adds filled so far
+
+	// Commissions of the position's order fills or income history, deducted from PNL on close (0
+	// if they are unknown, see OrderFill)
+	Fees float64
+	// Funding fees received while open (negative when paid), added to PNL on close
+	Funding float64
+
+	// Contract the symbol trades (not persisted, set from the symbol's configuration); the zero
+	// value is USDT-margined. For COIN-margined contracts Quantity is in contracts and PNL, Fees
+	// and Funding are in the base coin
+	Contract Contract
+
+	EntryTag // Why the position was entered
+}
+
+// Trade converts a closed position into a trade record, carrying over its entry tag.
+func (p *Position) Trade() *Trade {
+	return &Trade{
+		PositionID:  p.ID,
+		Symbol:      p.Symbol,
+		Side:        p.PositionSide(),
+		EntryPrice:  p.EntryPrice,
+		ExitPrice:   p.ExitPrice,
+		Quantity:    p.Quantity,
+		Leverage:    p.Leverage,
+		PNL:         p.PNL,
+		EntryTime:   p.EntryTime,
+		ExitTime:    p.ExitTime,
+		CloseReason: p.CloseReason,
+		MAE:         p.MAE,
+		MFE:         p.MFE,
+		EntryTag:    p.EntryTag,
+	}
+}
+
+// PositionSide returns the side of the position, defaulting to LONG when unset.
+func (p *Position) PositionSide() PositionSide {
+	if p.Side == PositionSideShort {
+		return PositionSideShort
+	}
+	return PositionSideLong
+}
+
+// IsShort reports whether the position profits from a falling price.
+func (p *Position) IsShort() bool {
+	return p.Side == PositionSideShort
 }
 
 // IsOpen checks if the position status is open.
 func (p *Position) IsOpen() bool {
 	return p.Status == StatusOpen
 }
+
+// Open validates the entry fields and marks a new position as open.
+// Partial fills received before opening should be applied with ApplyPartialFill first.
+func (p *Position) Open() error {
+	switch p.Status {
+	case StatusOpen:
+		return ErrPositionAlreadyOpen
+	case StatusClosed:
+		return ErrPositionClosed
+	}
+	if p.EntryPrice <= 0 {
+		return fmt.Errorf("%w: entry price %v must be positive", ErrInvalidPrice, p.EntryPrice)
+	}
+	if p.Quantity <= 0 {
+		return fmt.Errorf("%w: %v must be positive", ErrInvalidQuantity, p.Quantity)
+	}
+	p.Status = StatusOpen
+	return nil
+}
+
+// Close marks an open position as closed at exitPrice and records its realized PNL, net of Fees
+// and Funding.
+// A position can only be closed once.
+func (p *Position) Close(exitPrice float64, exitTime time.Time, reason CloseReason) error {
+	if p.Status == StatusClosed {
+		return ErrPositionClosed
+	}
+	if p.Status != StatusOpen {
+		return ErrPositionNotOpen
+	}
+	if exitPrice <= 0 {
+		return fmt.Errorf("%w: exit price %v must be positive", ErrInvalidPrice, exitPrice)
+	}
+	p.PNL = money.Float(money.Decimal(p.UnrealizedPnL(exitPrice)).Sub(money.Decimal(p.Fees)).Add(money.Decimal(p.Funding)))
+	p.ExitPrice = exitPrice
+	p.ExitTime = exitTime
+	p.CloseReason = reason
+	p.Status = StatusClosed
+	return nil
+}
+
+// ApplyPartialFill adjusts the position for a fill of qty at price. Positive quantities add to the
+// position and move EntryPrice to the volume-weighted average (the contract-weighted harmonic
+// average on COIN-margined contracts); negative quantities reduce it.
+// The resulting quantity must stay non-negative, and closed positions cannot be changed.
+func (p *Position) ApplyPartialFill(qty, price float64) error {
+	if p.Status == StatusClosed {
+		return ErrPositionClosed
+	}
+	newQty := money.Float(money.Decimal(p.Quantity).Add(money.Decimal(qty)))
+	if newQty < 0 {
+		return fmt.Errorf("%w: fill of %v would leave %v", ErrInvalidQuantity, qty, newQty)
+	}
+	if qty > 0 {
+		if price <= 0 {
+			return fmt.Errorf("%w: fill price %v must be positive", ErrInvalidPrice, price)
+		}
+		if p.Contract.IsInverse() {
+			p.EntryPrice = money.InverseAveragePrice(p.EntryPrice, p.Quantity, price, qty)
+		} else {
+			p.EntryPrice = money.AveragePrice(p.EntryPrice, p.Quantity, price, qty)
+		}
+	}
+	p.Quantity = newQty
+	return nil
+}
+
+// ScaleIn adds a fill of qty at price to an open position, blending it into EntryPrice, and counts
+// it as a scale-in add.
+func (p *Position) ScaleIn(qty, price float64) error {
+	if !p.IsOpen() {
+		return ErrPositionNotOpen
+	}
+	if qty <= 0 {
+		return fmt.Errorf("%w: add of %v must be positive", ErrInvalidQuantity, qty)
+	}
+	if err := p.ApplyPartialFill(qty, price); err != nil {
+		return err
+	}
+	p.ScaleIns++
+	return nil
+}
+
+// TrackExcursion updates MAE and MFE with prices traded while the position was open (e.g., a
+// kline's low, high and close). Non-positive prices are ignored.
+func (p *Position) TrackExcursion(prices ...float64) {
+	if p.EntryPrice <= 0 {
+		return
+	}
+	for _, price := range prices {
+		if price <= 0 {
+			continue
+		}
+		move := (price - p.EntryPrice) / p.EntryPrice
+		if p.IsShort() {
+			move = -move
+		}
+		if move > p.MFE {
+			p.MFE = move
+		}
+		if -move > p.MAE {
+			p.MAE = -move
+		}
+	}
+}
+
+// LiquidationPrice returns the mark price at which an isolated-margin position is liquidated: the
+// price where the margin left after the unrealized loss falls to the maintenance margin, given the
+// exchange's maintenance margin rate. Positions that can't be liquidated (USDT-margined longs and
+// COIN-margined shorts without leverage) return 0.
+func (p *Position) LiquidationPrice(maintenanceMarginRate float64) float64 {
+	return p.Contract.LiquidationPrice(p.EntryPrice, p.Leverage, maintenanceMarginRate, p.IsShort())
+}
+
+// UnrealizedPnL returns the gross PNL of an open position at markPrice.
+// Closed positions return 0; their result is in PNL.
+func (p *Position) UnrealizedPnL(markPrice float64) float64 {
+	if !p.IsOpen() {
+		return 0
+	}
+	return p.Contract.PnL(p.EntryPrice, markPrice, p.Quantity, p.IsShort())
+}
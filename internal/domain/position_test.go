@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPositionLifecycle(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	p := &Position{Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.5, EntryTime: now}
+
+	if pnl := p.UnrealizedPnL(2100); pnl != 0 {
+		t.Errorf("Expected 0 unrealized PNL before opening, got %f", pnl)
+	}
+	if err := p.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := p.Open(); !errors.Is(err, ErrPositionAlreadyOpen) {
+		t.Errorf("Expected ErrPositionAlreadyOpen, got %v", err)
+	}
+	if pnl := p.UnrealizedPnL(2100); pnl != 50 {
+		t.Errorf("Expected unrealized PNL 50, got %f", pnl)
+	}
+
+	if err := p.Close(0, now, CloseReasonMarket); !errors.Is(err, ErrInvalidPrice) {
+		t.Errorf("Expected ErrInvalidPrice for zero exit price, got %v", err)
+	}
+	if err := p.Close(1900, now.Add(time.Hour), CloseReasonStopLoss); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if p.Status != StatusClosed || p.PNL != -50 || p.ExitPrice != 1900 || p.CloseReason != CloseReasonStopLoss {
+		t.Errorf("Unexpected closed position: %+v", p)
+	}
+	if err := p.Close(1950, now.Add(2*time.Hour), CloseReasonMarket); !errors.Is(err, ErrPositionClosed) {
+		t.Errorf("Expected ErrPositionClosed when closing twice, got %v", err)
+	}
+	if err := p.ApplyPartialFill(0.1, 1950); !errors.Is(err, ErrPositionClosed) {
+		t.Errorf("Expected ErrPositionClosed for fill on closed position, got %v", err)
+	}
+	if pnl := p.UnrealizedPnL(2100); pnl != 0 {
+		t.Errorf("Expected 0 unrealized PNL after closing, got %f", pnl)
+	}
+}
+
+func TestShortPositionPnL(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	p := &Position{Symbol: "ETHUSDT", Side: PositionSideShort, EntryPrice: 2000, Quantity: 0.5, EntryTime: now}
+	if err := p.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if pnl := p.UnrealizedPnL(1900); pnl != 50 {
+		t.Errorf("Expected unrealized PNL 50 for a short below entry, got %f", pnl)
+	}
+	if err := p.Close(2100, now.Add(time.Hour), CloseReasonStopLoss); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if p.PNL != -50 {
+		t.Errorf("Expected PNL -50 for a short closed above entry, got %f", p.PNL)
+	}
+	withFees := &Position{Symbol: "ETHUSDT", EntryPrice: 2000, Quantity: 0.5, EntryTime: now, Fees: 0.8, Funding: -0.3}
+	if err := withFees.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := withFees.Close(2100, now.Add(time.Hour), CloseReasonTakeProfit); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if withFees.PNL != 48.9 {
+		t.Errorf("Expected PNL 48.9 net of fees and funding, got %f", withFees.PNL)
+	}
+	if trade := p.Trade(); trade.Side != PositionSideShort {
+		t.Errorf("Expected trade side SHORT, got %s", trade.Side)
+	}
+	if side := (&Position{}).PositionSide(); side != PositionSideLong {
+		t.Errorf("Expected an unset side to default to LONG, got %s", side)
+	}
+	if PositionSideShort.EntrySide() != Sell || PositionSideShort.ExitSide() != Buy || PositionSideLong.EntrySide() != Buy {
+		t.Error("Unexpected entry/exit order sides")
+	}
+}
+
+func TestPositionTrackExcursion(t *testing.T) {
+	long := &Position{EntryPrice: 100}
+	long.TrackExcursion(98, 105, 101)
+	long.TrackExcursion(0, 99) // Missing prices are ignored
+	if math.Abs(long.MAE-0.02) > 1e-9 || math.Abs(long.MFE-0.05) > 1e-9 {
+		t.Errorf("Expected long MAE 0.02 and MFE 0.05, got %f and %f", long.MAE, long.MFE)
+	}
+
+	short := &Position{EntryPrice: 100, Side: PositionSideShort}
+	short.TrackExcursion(98, 105)
+	if math.Abs(short.MAE-0.05) > 1e-9 || math.Abs(short.MFE-0.02) > 1e-9 {
+		t.Errorf("Expected short MAE 0.05 and MFE 0.02, got %f and %f", short.MAE, short.MFE)
+	}
+	if trade := short.Trade(); trade.MAE != short.MAE || trade.MFE != short.MFE {
+		t.Errorf("Expected the trade to carry the excursions, got MAE %f MFE %f", trade.MAE, trade.MFE)
+	}
+
+	favorable := &Position{EntryPrice: 100}
+	favorable.TrackExcursion(101, 103)
+	if favorable.MAE != 0 {
+		t.Errorf("Expected no adverse excursion when price never dropped, got %f", favorable.MAE)
+	}
+}
+
+func TestPositionOpenValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		position Position
+		wantErr  error
+	}{
+		{name: "zero entry price", position: Position{Quantity: 1}, wantErr: ErrInvalidPrice},
+		{name: "zero quantity", position: Position{EntryPrice: 100}, wantErr: ErrInvalidQuantity},
+		{name: "already closed", position: Position{EntryPrice: 100, Quantity: 1, Status: StatusClosed}, wantErr: ErrPositionClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.position.Open(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	var pending Position
+	if err := pending.Close(100, time.Now(), CloseReasonMarket); !errors.Is(err, ErrPositionNotOpen) {
+		t.Errorf("Expected ErrPositionNotOpen when closing a position that was never opened, got %v", err)
+	}
+}
+
+func TestPositionApplyPartialFill(t *testing.T) {
+	p := &Position{}
+	if err := p.ApplyPartialFill(1, 100); err != nil {
+		t.Fatalf("ApplyPartialFill failed: %v", err)
+	}
+	if err := p.ApplyPartialFill(3, 104); err != nil {
+		t.Fatalf("ApplyPartialFill failed: %v", err)
+	}
+	if p.Quantity != 4 || math.Abs(p.EntryPrice-103) > 1e-9 {
+		t.Errorf("Expected quantity 4 at average 103, got %f at %f", p.Quantity, p.EntryPrice)
+	}
+
+	// Reductions keep the average entry price
+	if err := p.ApplyPartialFill(-1, 110); err != nil {
+		t.Fatalf("ApplyPartialFill failed: %v", err)
+	}
+	if p.Quantity != 3 || math.Abs(p.EntryPrice-103) > 1e-9 {
+		t.Errorf("Expected quantity 3 at average 103, got %f at %f", p.Quantity, p.EntryPrice)
+	}
+
+	if err := p.ApplyPartialFill(-5, 110); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("Expected ErrInvalidQuantity for negative resulting quantity, got %v", err)
+	}
+	if err := p.ApplyPartialFill(1, 0); !errors.Is(err, ErrInvalidPrice) {
+		t.Errorf("Expected ErrInvalidPrice for zero fill price, got %v", err)
+	}
+	if p.Quantity != 3 {
+		t.Errorf("Expected rejected fills to leave quantity unchanged, got %f", p.Quantity)
+	}
+}
+
+func TestPositionLiquidationPrice(t *testing.T) {
+	long := &Position{EntryPrice: 2000, Quantity: 1, Leverage: 10}
+	if got := long.LiquidationPrice(0.005); math.Abs(got-1809.045226) > 1e-6 {
+		t.Errorf("Expected long liquidation price 1809.045226, got %f", got)
+	}
+	short := &Position{Side: PositionSideShort, EntryPrice: 2000, Quantity: 1, Leverage: 10}
+	if got := short.LiquidationPrice(0.005); math.Abs(got-2189.054726) > 1e-6 {
+		t.Errorf("Expected short liquidation price 2189.054726, got %f", got)
+	}
+	if got := (&Position{EntryPrice: 2000, Leverage: 1}).LiquidationPrice(0.005); got != 0 {
+		t.Errorf("Expected an unleveraged long never to be liquidated, got %f", got)
+	}
+
+	// At the liquidation price the margin left equals the maintenance margin
+	liq := long.LiquidationPrice(0.005)
+	margin := long.EntryPrice * long.Quantity / float64(long.Leverage)
+	if left := margin + (liq-long.EntryPrice)*long.Quantity; math.Abs(left-0.005*liq*long.Quantity) > 1e-9 {
+		t.Errorf("Expected margin left %f to equal the maintenance margin %f", left, 0.005*liq*long.Quantity)
+	}
+}
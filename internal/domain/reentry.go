@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidReEntryPolicy is returned for re-entry rules that can't be parsed.
+var ErrInvalidReEntryPolicy = errors.New("invalid re-entry policy")
+
+// closeReasons lists the close reasons a re-entry rule can refer to.
+var closeReasons = []CloseReason{
+	CloseReasonStopLoss, CloseReasonTakeProfit, CloseReasonMarket, CloseReasonLiquidation,
+	CloseReasonManual, CloseReasonTrendReversal, CloseReasonTimeLimit, CloseReasonVolatilityDrop,
+	CloseReasonConsolidation, CloseReasonMarketClose, CloseReasonTrailingStop, CloseReasonBreakEven,
+	CloseReasonResistance, CloseReasonSafeMode, CloseReasonSessionEnd, CloseReasonSlippage,
+}
+
+// ReEntryRule restricts new entries after a position closed for a given reason.
+type ReEntryRule struct {
+	Cooldown         time.Duration // Minimum time from the exit to the next entry (0 allows immediate re-entry)
+	RequireCrossover bool          // Whether the next entry needs a fresh MA crossover after the exit
+}
+
+// ReEntryPolicy maps close reasons to their re-entry rule. Reasons without a rule allow
+// immediate re-entry; the nil policy restricts nothing.
+type ReEntryPolicy map[CloseReason]ReEntryRule
+
+// Rule returns the re-entry rule for reason.
+func (p ReEntryPolicy) Rule(reason CloseReason) ReEntryRule {
+	return p[reason]
+}
+
+// CooldownRemaining returns how much longer entries are blocked at now after a position closed
+// for reason at exitTime (0 once the cooldown has passed).
+func (p ReEntryPolicy) CooldownRemaining(reason CloseReason, exitTime, now time.Time) time.Duration {
+	remaining := exitTime.Add(p.Rule(reason).Cooldown).Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// ParseReEntryPolicy parses comma-separated "reason:cooldown[:crossover]" rules, e.g.
+// "TP:0,SL:30m,TREND_REVERSAL:0:crossover". Reasons are close reasons (case-insensitive) and
+// cooldowns Go durations. An empty spec returns a nil policy.
+func ParseReEntryPolicy(spec string) (ReEntryPolicy, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	policy := make(ReEntryPolicy)
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("%w: %q must be reason:cooldown[:crossover]", ErrInvalidReEntryPolicy, item)
+		}
+		reason, ok := parseCloseReason(parts[0])
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown close reason %q", ErrInvalidReEntryPolicy, parts[0])
+		}
+		if _, dup := policy[reason]; dup {
+			return nil, fmt.Errorf("%w: duplicate rule for %s", ErrInvalidReEntryPolicy, reason)
+		}
+		cooldown, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || cooldown < 0 {
+			return nil, fmt.Errorf("%w: cooldown %q of %s must be a non-negative duration", ErrInvalidReEntryPolicy, parts[1], reason)
+		}
+		rule := ReEntryRule{Cooldown: cooldown}
+		if len(parts) == 3 {
+			if !strings.EqualFold(strings.TrimSpace(parts[2]), "crossover") {
+				return nil, fmt.Errorf("%w: unknown option %q for %s", ErrInvalidReEntryPolicy, parts[2], reason)
+			}
+			rule.RequireCrossover = true
+		}
+		policy[reason] = rule
+	}
+	return policy, nil
+}
+
+// parseCloseReason matches name case-insensitively against the known close reasons.
+func parseCloseReason(name string) (CloseReason, bool) {
+	name = strings.TrimSpace(name)
+	for _, reason := range closeReasons {
+		if strings.EqualFold(name, string(reason)) {
+			return reason, true
+		}
+	}
+	return "", false
+}
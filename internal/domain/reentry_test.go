@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseReEntryPolicy(t *testing.T) {
+	policy, err := ParseReEntryPolicy("tp:0, SL:30m, TREND_REVERSAL:0:crossover")
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+	if rule := policy.Rule(CloseReasonTakeProfit); rule.Cooldown != 0 || rule.RequireCrossover {
+		t.Errorf("Expected immediate re-entry after TP, got %+v", rule)
+	}
+	if rule := policy.Rule(CloseReasonStopLoss); rule.Cooldown != 30*time.Minute || rule.RequireCrossover {
+		t.Errorf("Expected a 30m cooldown after SL, got %+v", rule)
+	}
+	if rule := policy.Rule(CloseReasonTrendReversal); !rule.RequireCrossover {
+		t.Errorf("Expected a fresh crossover after a trend reversal, got %+v", rule)
+	}
+	if rule := policy.Rule(CloseReasonTimeLimit); rule != (ReEntryRule{}) {
+		t.Errorf("Expected no rule for unlisted reasons, got %+v", rule)
+	}
+
+	if policy, err := ParseReEntryPolicy(""); err != nil || policy != nil {
+		t.Errorf("Expected a nil policy for an empty spec, got %v %v", policy, err)
+	}
+	for _, spec := range []string{"SL", "SL:-5m", "SL:soon", "NOPE:5m", "SL:5m:later", "SL:5m,SL:10m"} {
+		if _, err := ParseReEntryPolicy(spec); !errors.Is(err, ErrInvalidReEntryPolicy) {
+			t.Errorf("Expected ErrInvalidReEntryPolicy for %q, got %v", spec, err)
+		}
+	}
+}
+
+func TestReEntryPolicyCooldownRemaining(t *testing.T) {
+	policy := ReEntryPolicy{CloseReasonStopLoss: {Cooldown: 30 * time.Minute}}
+	exit := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	if got := policy.CooldownRemaining(CloseReasonStopLoss, exit, exit.Add(10*time.Minute)); got != 20*time.Minute {
+		t.Errorf("Expected 20m remaining, got %v", got)
+	}
+	if got := policy.CooldownRemaining(CloseReasonStopLoss, exit, exit.Add(time.Hour)); got != 0 {
+		t.Errorf("Expected the cooldown to have passed, got %v", got)
+	}
+	if got := policy.CooldownRemaining(CloseReasonTakeProfit, exit, exit); got != 0 {
+		t.Errorf("Expected no cooldown without a rule, got %v", got)
+	}
+	var none ReEntryPolicy
+	if got := none.CooldownRemaining(CloseReasonStopLoss, exit, exit); got != 0 {
+		t.Errorf("Expected the nil policy to restrict nothing, got %v", got)
+	}
+}
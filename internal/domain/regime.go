@@ -0,0 +1,53 @@
+package domain
+
+import "strings"
+
+// TrendRegime classifies how directional the market is.
+type TrendRegime string
+
+const (
+	TrendRegimeTrending TrendRegime = "trending" // Price moves mostly in one direction
+	TrendRegimeChoppy   TrendRegime = "choppy"   // Price moves back and forth without much net progress
+)
+
+// VolatilityRegime buckets recent volatility relative to its longer-term baseline.
+type VolatilityRegime string
+
+const (
+	VolatilityRegimeLow    VolatilityRegime = "low"
+	VolatilityRegimeNormal VolatilityRegime = "normal"
+	VolatilityRegimeHigh   VolatilityRegime = "high"
+)
+
+// TrendDirection is the direction of the higher timeframe trend.
+type TrendDirection string
+
+const (
+	TrendDirectionUp   TrendDirection = "up"
+	TrendDirectionDown TrendDirection = "down"
+	TrendDirectionFlat TrendDirection = "flat"
+)
+
+// MarketRegime describes the market at a trade's entry. Fields are empty when there wasn't enough
+// history to classify them (or for trades recorded before regimes were tagged).
+type MarketRegime struct {
+	Trend      TrendRegime
+	Volatility VolatilityRegime
+	HigherTF   TrendDirection // Direction of the higher timeframe trend
+}
+
+// IsZero reports whether no part of the regime is known.
+func (r MarketRegime) IsZero() bool {
+	return r == MarketRegime{}
+}
+
+// String returns the regime as "trend/volatility/direction", with "?" for unknown parts.
+func (r MarketRegime) String() string {
+	parts := []string{string(r.Trend), string(r.Volatility), string(r.HigherTF)}
+	for i, part := range parts {
+		if part == "" {
+			parts[i] = "?"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// DailyReport summarizes one day of closed trades for a symbol.
+type DailyReport struct {
+	Date        time.Time // UTC day the report belongs to (midnight)
+	Symbol      string    // Trading symbol (e.g., "ETHUSDT")
+	PeriodStart time.Time // Start of the covered window (inclusive)
+	PeriodEnd   time.Time // End of the covered window (exclusive)
+	Trades      int       // Number of positions closed in the window
+	Wins        int       // Trades with positive PNL
+	Losses      int       // Trades with zero or negative PNL
+	WinRate     float64   // Wins as a fraction of trades (0.6 = 60%)
+	GrossPnL    float64   // Sum of position PNL before fees (funding included)
+	Fees        float64   // Entry and exit fees recorded on the positions, estimated for positions without them
+	NetPnL      float64   // GrossPnL minus Fees
+	Balance     float64   // Account balance when the report was compiled
+	CreatedAt   time.Time // When the report was compiled
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SafeModeAction is what happens to open positions when the bot enters safe mode.
+type SafeModeAction string
+
+const (
+	SafeModeKeep    SafeModeAction = "none"    // Leave positions to their SL/TP orders on the exchange
+	SafeModeTighten SafeModeAction = "tighten" // Pull the stops of open positions closer to the price
+	SafeModeClose   SafeModeAction = "close"   // Market-close open positions
+)
+
+// ParseSafeModeAction parses "none" (or empty), "tighten" or "close".
+func ParseSafeModeAction(value string) (SafeModeAction, error) {
+	switch action := SafeModeAction(strings.ToLower(strings.TrimSpace(value))); action {
+	case "", SafeModeKeep:
+		return SafeModeKeep, nil
+	case SafeModeTighten, SafeModeClose:
+		return action, nil
+	default:
+		return "", fmt.Errorf("unknown safe mode action %q (none, tighten or close)", value)
+	}
+}
+
+// SafeModeEvent records a period in which the bot stopped opening positions because the exchange
+// was under maintenance or unreachable.
+type SafeModeEvent struct {
+	ID        int64     // Unique identifier (from DB)
+	Symbol    string    // Trading symbol (e.g., "ETHUSDT")
+	Reason    string    // What triggered safe mode, e.g. the maintenance error
+	StartedAt time.Time // When safe mode was entered
+	EndedAt   time.Time // When the exchange was healthy again; zero while safe mode is active
+}
+
+// Active reports whether safe mode is still in effect.
+func (e *SafeModeEvent) Active() bool {
+	return e.EndedAt.IsZero()
+}
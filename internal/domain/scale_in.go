@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidScaleInPlan is returned for scale-in plans that can't be executed.
+var ErrInvalidScaleInPlan = errors.New("invalid scale-in plan")
+
+// ScaleInPlan describes a scale-in (dollar-cost averaging) entry. The entry signal opens
+// InitialFraction of the full position size; the rest is added in equal parts each time the price
+// improves by the next of Steps from the initial fill (lower for a long, higher for a short), so
+// the position never exceeds the full size. The zero value disables scaling in.
+type ScaleInPlan struct {
+	InitialFraction float64   // Share of the full size entered on the signal, in (0, 1)
+	Steps           []float64 // Price improvements from the initial fill for each add (e.g., 0.003, 0.006 for -0.3%, -0.6% on a long)
+}
+
+// Enabled reports whether the plan adds to positions.
+func (p ScaleInPlan) Enabled() bool {
+	return len(p.Steps) > 0
+}
+
+// Validate checks that the plan splits the full size and its steps are strictly increasing
+// improvements below 100%.
+func (p ScaleInPlan) Validate() error {
+	if !p.Enabled() {
+		return nil
+	}
+	if p.InitialFraction <= 0 || p.InitialFraction >= 1 {
+		return fmt.Errorf("%w: initial fraction %v must be between 0 and 1", ErrInvalidScaleInPlan, p.InitialFraction)
+	}
+	for i, step := range p.Steps {
+		if step <= 0 || step >= 1 {
+			return fmt.Errorf("%w: step %v must be between 0 and 1", ErrInvalidScaleInPlan, step)
+		}
+		if i > 0 && step <= p.Steps[i-1] {
+			return fmt.Errorf("%w: steps must be strictly increasing", ErrInvalidScaleInPlan)
+		}
+	}
+	return nil
+}
+
+// LastStep returns the largest price improvement of the plan, or 0 if it has no steps. The steps
+// of a validated plan are increasing, so it is the last one.
+func (p ScaleInPlan) LastStep() float64 {
+	if len(p.Steps) == 0 {
+		return 0
+	}
+	return p.Steps[len(p.Steps)-1]
+}
+
+// InitialQuantity returns the quantity entered on the signal for a full size of fullQuantity.
+func (p ScaleInPlan) InitialQuantity(fullQuantity float64) float64 {
+	if !p.Enabled() {
+		return fullQuantity
+	}
+	return fullQuantity * p.InitialFraction
+}
+
+// AddQuantity returns the quantity of each add for a full size of fullQuantity.
+func (p ScaleInPlan) AddQuantity(fullQuantity float64) float64 {
+	if !p.Enabled() {
+		return 0
+	}
+	return fullQuantity * (1 - p.InitialFraction) / float64(len(p.Steps))
+}
+
+// NextAddPrice returns the price at which the position's next add is due. It returns false once
+// all adds are filled or if the position didn't scale in.
+func (p ScaleInPlan) NextAddPrice(position *Position) (float64, bool) {
+	if position.ScaleInBasePrice <= 0 || position.ScaleIns >= len(p.Steps) {
+		return 0, false
+	}
+	step := p.Steps[position.ScaleIns]
+	if position.IsShort() {
+		return position.ScaleInBasePrice * (1 + step), true
+	}
+	return position.ScaleInBasePrice * (1 - step), true
+}
+
+// AddDue reports whether price reached the position's next add.
+func (p ScaleInPlan) AddDue(position *Position, price float64) bool {
+	level, ok := p.NextAddPrice(position)
+	if !ok || price <= 0 {
+		return false
+	}
+	if position.IsShort() {
+		return price >= level
+	}
+	return price <= level
+}
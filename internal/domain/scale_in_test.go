@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestScaleInPlan(t *testing.T) {
+	plan := ScaleInPlan{InitialFraction: 0.5, Steps: []float64{0.003, 0.006}}
+	if err := plan.Validate(); err != nil {
+		t.Fatalf("Unexpected validation error: %v", err)
+	}
+	if got := plan.InitialQuantity(1); got != 0.5 {
+		t.Errorf("Expected initial quantity 0.5, got %f", got)
+	}
+	if got := plan.AddQuantity(1); got != 0.25 {
+		t.Errorf("Expected add quantity 0.25, got %f", got)
+	}
+	if got := plan.LastStep(); got != 0.006 {
+		t.Errorf("Expected last step 0.006, got %f", got)
+	}
+	if got := (ScaleInPlan{}).LastStep(); got != 0 {
+		t.Errorf("Expected no last step for a disabled plan, got %f", got)
+	}
+
+	long := &Position{EntryPrice: 2000, Quantity: 0.5, ScaleInBasePrice: 2000}
+	if err := long.Open(); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if level, ok := plan.NextAddPrice(long); !ok || math.Abs(level-1994) > 1e-9 {
+		t.Errorf("Expected the first add at 1994, got %f (%v)", level, ok)
+	}
+	if plan.AddDue(long, 1995) || !plan.AddDue(long, 1994) {
+		t.Error("Expected the first add to be due from 1994")
+	}
+	if err := long.ScaleIn(0.25, 1994); err != nil {
+		t.Fatalf("ScaleIn failed: %v", err)
+	}
+	if long.ScaleIns != 1 || long.Quantity != 0.75 || math.Abs(long.EntryPrice-1998) > 1e-9 {
+		t.Errorf("Expected 1 add, quantity 0.75 and blended entry 1998, got %d, %f and %f", long.ScaleIns, long.Quantity, long.EntryPrice)
+	}
+	if level, _ := plan.NextAddPrice(long); math.Abs(level-1988) > 1e-9 {
+		t.Errorf("Expected the second add at 1988 (measured from the initial fill), got %f", level)
+	}
+	if err := long.ScaleIn(0.25, 1988); err != nil {
+		t.Fatalf("ScaleIn failed: %v", err)
+	}
+	if _, ok := plan.NextAddPrice(long); ok || plan.AddDue(long, 1000) {
+		t.Error("Expected no adds after the last step")
+	}
+
+	short := &Position{Side: PositionSideShort, EntryPrice: 2000, Quantity: 0.5, ScaleInBasePrice: 2000}
+	if plan.AddDue(short, 2005) || !plan.AddDue(short, 2006) {
+		t.Error("Expected a short's first add to be due from 2006")
+	}
+	if plan.AddDue(&Position{EntryPrice: 2000, Quantity: 1}, 1000) {
+		t.Error("Expected no adds for a position entered without scaling in")
+	}
+	if err := (&Position{EntryPrice: 2000, Quantity: 1}).ScaleIn(0.25, 1990); !errors.Is(err, ErrPositionNotOpen) {
+		t.Errorf("Expected ErrPositionNotOpen for a position that isn't open, got %v", err)
+	}
+}
+
+func TestScaleInPlanValidate(t *testing.T) {
+	if err := (ScaleInPlan{}).Validate(); err != nil {
+		t.Errorf("Expected the zero plan to be valid, got %v", err)
+	}
+	if (ScaleInPlan{}).InitialQuantity(1) != 1 {
+		t.Error("Expected a disabled plan to enter the full size")
+	}
+	invalid := []ScaleInPlan{
+		{InitialFraction: 0, Steps: []float64{0.003}},
+		{InitialFraction: 1, Steps: []float64{0.003}},
+		{InitialFraction: 0.5, Steps: []float64{0}},
+		{InitialFraction: 0.5, Steps: []float64{0.006, 0.003}},
+	}
+	for _, plan := range invalid {
+		if err := plan.Validate(); !errors.Is(err, ErrInvalidScaleInPlan) {
+			t.Errorf("Expected ErrInvalidScaleInPlan for %+v, got %v", plan, err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// SessionEnded reports whether t falls at or after the end of its UTC day's trading session.
+// end is the session end as an offset from UTC midnight; zero means sessions never end. Day
+// trading flattens positions at the session end and opens none until the next UTC day.
+func SessionEnded(t time.Time, end time.Duration) bool {
+	if end <= 0 {
+		return false
+	}
+	t = t.UTC()
+	return t.Sub(t.Truncate(24*time.Hour)) >= end
+}
+
+// NextSessionEnd returns the first session end strictly after t. end must be positive.
+func NextSessionEnd(t time.Time, end time.Duration) time.Time {
+	t = t.UTC()
+	next := t.Truncate(24 * time.Hour).Add(end)
+	if !next.After(t) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
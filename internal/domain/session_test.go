@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionEnded(t *testing.T) {
+	end := 21 * time.Hour
+	day := time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		end  time.Duration
+		want bool
+	}{
+		{name: "before the end", t: day.Add(20*time.Hour + 59*time.Minute), end: end, want: false},
+		{name: "at the end", t: day.Add(end), end: end, want: true},
+		{name: "after the end", t: day.Add(23 * time.Hour), end: end, want: true},
+		{name: "next day", t: day.Add(24 * time.Hour), end: end, want: false},
+		{name: "other time zone", t: day.Add(22 * time.Hour).In(time.FixedZone("UTC+3", 3*3600)), end: end, want: true},
+		{name: "disabled", t: day.Add(23 * time.Hour), end: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SessionEnded(tt.t, tt.end); got != tt.want {
+				t.Errorf("SessionEnded(%s, %s) = %v, want %v", tt.t, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextSessionEnd(t *testing.T) {
+	end := 21 * time.Hour
+	day := time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC)
+
+	if got := NextSessionEnd(day.Add(10*time.Hour), end); !got.Equal(day.Add(end)) {
+		t.Errorf("Expected today's session end, got %s", got)
+	}
+	if got := NextSessionEnd(day.Add(end), end); !got.Equal(day.Add(24*time.Hour + end)) {
+		t.Errorf("Expected tomorrow's session end at the end itself, got %s", got)
+	}
+}
@@ -4,15 +4,30 @@ import "time"
 
 // Trade represents a completed trade event.
 type Trade struct {
-	ID          int64       // Unique identifier for the trade (usually from DB)
-	PositionID  int64       // Identifier of the position this trade closed (optional)
-	Symbol      string      // Trading symbol (e.g., "ETHUSDT")
-	EntryPrice  float64     // Price at which the position was entered
-	ExitPrice   float64     // Price at which the position was exited
-	Quantity    float64     // Size of the position traded
-	Leverage    int         // Leverage used for the position
-	PNL         float64     // Profit and Loss for this trade
-	EntryTime   time.Time   // Timestamp when the position was entered
-	ExitTime    time.Time   // Timestamp when the position was exited
-	CloseReason CloseReason // Reason why the position was closed (SL, TP, etc.)
+	ID          int64        // Unique identifier for the trade (usually from DB)
+	PositionID  int64        // Identifier of the position this trade closed (optional)
+	Symbol      string       // Trading symbol (e.g., "ETHUSDT")
+	Side        PositionSide // LONG or SHORT
+	EntryPrice  float64      // Price at which the position was entered
+	ExitPrice   float64      // Price at which the position was exited
+	Quantity    float64      // Size of the position traded
+	Leverage    int          // Leverage used for the position
+	PNL         float64      // Profit and Loss for this trade
+	EntryTime   time.Time    // Timestamp when the position was entered
+	ExitTime    time.Time    // Timestamp when the position was exited
+	CloseReason CloseReason  // Reason why the position was closed (SL, TP, etc.)
+	MAE         float64      // Maximum adverse excursion while open, as a fraction of the entry price
+	MFE         float64      // Maximum favorable excursion while open, as a fraction of the entry price
+	Regime      MarketRegime // Market regime at entry (tagged by backtests)
+
+	EntryTag // Why the position was entered
+}
+
+// EntryTag holds structured metadata describing why a position was entered,
+// so performance can be analyzed by entry type.
+type EntryTag struct {
+	EntryReason       string       // Human-readable entry reason
+	SignalSource      SignalSource // Kind of signal that triggered the entry
+	ConfirmationCount int          // Number of confirmation conditions met at entry
+	EntryATR          float64      // ATR at entry, in price units
 }
@@ -0,0 +1,184 @@
+// Package money does the price, quantity and PnL arithmetic that reaches orders and balances in
+// decimal, so values like 0.1 + 0.2 don't pick up binary floating point error on their way to the
+// exchange or into the books. The rest of the bot works in float64; the helpers here convert at
+// the boundaries.
+package money
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidPrecision is returned for tick or step sizes that aren't positive decimals.
+var ErrInvalidPrecision = errors.New("invalid precision")
+
+// Precision holds a symbol's order precision: prices are multiples of TickSize and quantities
+// multiples of StepSize (Binance's PRICE_FILTER and LOT_SIZE filters).
+type Precision struct {
+	TickSize decimal.Decimal
+	StepSize decimal.Decimal
+}
+
+// DefaultPrecision is the precision of ETHUSDT perpetual futures: 0.01 price ticks and 0.001
+// quantity steps.
+func DefaultPrecision() Precision {
+	return Precision{TickSize: decimal.New(1, -2), StepSize: decimal.New(1, -3)}
+}
+
+// ParsePrecision parses a tick size and a step size (e.g., "0.01" and "0.001").
+func ParsePrecision(tickSize, stepSize string) (Precision, error) {
+	tick, err := parsePositive(tickSize)
+	if err != nil {
+		return Precision{}, fmt.Errorf("%w: tick size: %v", ErrInvalidPrecision, err)
+	}
+	step, err := parsePositive(stepSize)
+	if err != nil {
+		return Precision{}, fmt.Errorf("%w: step size: %v", ErrInvalidPrecision, err)
+	}
+	return Precision{TickSize: tick, StepSize: step}, nil
+}
+
+// parsePositive parses a decimal that must be greater than zero.
+func parsePositive(value string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if !d.IsPositive() {
+		return decimal.Zero, fmt.Errorf("%s must be positive", value)
+	}
+	return d, nil
+}
+
+// RoundPrice rounds price to the nearest tick.
+func (p Precision) RoundPrice(price float64) float64 {
+	return Float(p.price(price))
+}
+
+// RoundQuantity rounds quantity down to a whole number of steps, so an order never exceeds the
+// size asked for.
+func (p Precision) RoundQuantity(quantity float64) float64 {
+	return Float(p.quantity(quantity))
+}
+
+// FormatPrice formats price rounded to the nearest tick with the tick size's decimal places,
+// as the exchange expects it in orders.
+func (p Precision) FormatPrice(price float64) string {
+	return p.price(price).StringFixed(places(p.TickSize))
+}
+
+// FormatQuantity formats quantity rounded down to the step size with its decimal places.
+func (p Precision) FormatQuantity(quantity float64) string {
+	return p.quantity(quantity).StringFixed(places(p.StepSize))
+}
+
+// QuoteToQuantity returns the base asset quantity quote buys at price, rounded down to the step
+// size. It returns 0 for a non-positive price.
+func (p Precision) QuoteToQuantity(quote, price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	return Float(Decimal(quote).DivRound(Decimal(price), 16).Div(p.StepSize).Truncate(0).Mul(p.StepSize))
+}
+
+func (p Precision) price(price float64) decimal.Decimal {
+	return Decimal(price).Div(p.TickSize).Round(0).Mul(p.TickSize)
+}
+
+func (p Precision) quantity(quantity float64) decimal.Decimal {
+	return Decimal(quantity).Div(p.StepSize).Truncate(0).Mul(p.StepSize)
+}
+
+// places returns the number of decimal places of d (e.g., 2 for 0.01, 0 for 5).
+func places(d decimal.Decimal) int32 {
+	if exp := d.Exponent(); exp < 0 {
+		return -exp
+	}
+	return 0
+}
+
+// Decimal converts a float64 to a decimal via its shortest representation, so 0.1 becomes
+// exactly 0.1.
+func Decimal(f float64) decimal.Decimal {
+	return decimal.NewFromFloat(f)
+}
+
+// Float converts a decimal back to the nearest float64.
+func Float(d decimal.Decimal) float64 {
+	return d.InexactFloat64()
+}
+
+// Notional returns price times quantity.
+func Notional(price, quantity float64) float64 {
+	return Float(Decimal(price).Mul(Decimal(quantity)))
+}
+
+// PnL returns the gross profit of quantity bought at entryPrice and sold at exitPrice, or sold
+// and bought back for a short.
+func PnL(entryPrice, exitPrice, quantity float64, short bool) float64 {
+	move := Decimal(exitPrice).Sub(Decimal(entryPrice))
+	if short {
+		move = move.Neg()
+	}
+	return Float(move.Mul(Decimal(quantity)))
+}
+
+// InverseNotional returns the coin value at price of contracts of contractSize USD each, as
+// held on COIN-margined (inverse) futures. It returns 0 for a non-positive price.
+func InverseNotional(price, contracts, contractSize float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	return Float(Decimal(contracts).Mul(Decimal(contractSize)).Div(Decimal(price)))
+}
+
+// InversePnL returns the gross coin profit of contracts of contractSize USD each bought at
+// entryPrice and sold at exitPrice, or sold and bought back for a short. Unlike PnL it isn't
+// linear in price: a long gains less coin from a rise than it loses from an equal fall.
+func InversePnL(entryPrice, exitPrice, contracts, contractSize float64, short bool) float64 {
+	if entryPrice <= 0 || exitPrice <= 0 {
+		return 0
+	}
+	value := Decimal(contracts).Mul(Decimal(contractSize))
+	move := value.Div(Decimal(entryPrice)).Sub(value.Div(Decimal(exitPrice)))
+	if short {
+		move = move.Neg()
+	}
+	return Float(move)
+}
+
+// Fee returns rate times the notional of quantity at price.
+func Fee(price, quantity, rate float64) float64 {
+	return Float(Decimal(price).Mul(Decimal(quantity)).Mul(Decimal(rate)))
+}
+
+// AveragePrice returns the volume-weighted average price of holding quantity at price and adding
+// addQuantity at addPrice. It returns price if the combined quantity is zero.
+func AveragePrice(price, quantity, addPrice, addQuantity float64) float64 {
+	total := Decimal(quantity).Add(Decimal(addQuantity))
+	if total.IsZero() {
+		return price
+	}
+	cost := Decimal(price).Mul(Decimal(quantity)).Add(Decimal(addPrice).Mul(Decimal(addQuantity)))
+	return Float(cost.DivRound(total, 16))
+}
+
+// InverseAveragePrice returns the average entry price of holding contracts at price and adding
+// addContracts at addPrice on COIN-margined futures: the contract-weighted harmonic average, at
+// which the combined contracts are worth as much coin as the two fills. Legs without contracts or
+// a positive price are left out; it returns price if nothing is left.
+func InverseAveragePrice(price, contracts, addPrice, addContracts float64) float64 {
+	total, coin := decimal.Zero, decimal.Zero
+	for _, leg := range [][2]float64{{price, contracts}, {addPrice, addContracts}} {
+		if leg[0] > 0 && leg[1] != 0 {
+			total = total.Add(Decimal(leg[1]))
+			coin = coin.Add(Decimal(leg[1]).DivRound(Decimal(leg[0]), 16))
+		}
+	}
+	if coin.IsZero() {
+		return price
+	}
+	return Float(total.DivRound(coin, 16))
+}
@@ -0,0 +1,138 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPrecisionFormat(t *testing.T) {
+	precision := DefaultPrecision()
+	prices := []struct {
+		price    float64
+		expected string
+	}{
+		{1960, "1960.00"},
+		{2000 * (1 - 0.0025), "1995.00"}, // 1994.9999999999998 in float64
+		{1999.994, "1999.99"},
+		{1999.995, "2000.00"},
+		{0.1 + 0.2, "0.30"},
+	}
+	for _, tt := range prices {
+		if got := precision.FormatPrice(tt.price); got != tt.expected {
+			t.Errorf("FormatPrice(%v) = %s, expected %s", tt.price, got, tt.expected)
+		}
+	}
+
+	quantities := []struct {
+		quantity float64
+		expected string
+	}{
+		{0.5, "0.500"},
+		{0.1 + 0.2, "0.300"}, // 0.30000000000000004 in float64
+		{0.2999, "0.299"},    // Rounded down, never above the size asked for
+		{1.0 / 3, "0.333"},
+		{0.0004, "0.000"},
+	}
+	for _, tt := range quantities {
+		if got := precision.FormatQuantity(tt.quantity); got != tt.expected {
+			t.Errorf("FormatQuantity(%v) = %s, expected %s", tt.quantity, got, tt.expected)
+		}
+	}
+
+	// Coarser ticks and steps
+	precision, err := ParsePrecision("0.5", "1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := precision.FormatPrice(100.74); got != "100.5" {
+		t.Errorf("Expected 100.5, got %s", got)
+	}
+	if got := precision.FormatQuantity(2.9); got != "2" {
+		t.Errorf("Expected 2, got %s", got)
+	}
+	if got := precision.RoundPrice(100.76); got != 101 {
+		t.Errorf("Expected 101, got %v", got)
+	}
+	if got := precision.RoundQuantity(2.9); got != 2 {
+		t.Errorf("Expected 2, got %v", got)
+	}
+}
+
+func TestQuoteToQuantity(t *testing.T) {
+	precision := DefaultPrecision()
+	tests := []struct {
+		quote, price, expected float64
+	}{
+		{500, 2000, 0.25},
+		{500, 3000, 0.166}, // 0.1666..., rounded down to the step
+		{0.3, 0.1, 3},      // 2.9999999999999996 in float64
+		{1, 2000, 0},       // Below one step
+		{500, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := precision.QuoteToQuantity(tt.quote, tt.price); got != tt.expected {
+			t.Errorf("QuoteToQuantity(%v, %v) = %v, expected %v", tt.quote, tt.price, got, tt.expected)
+		}
+	}
+}
+
+func TestParsePrecision(t *testing.T) {
+	for _, tt := range []struct{ tick, step string }{
+		{"0", "0.001"},
+		{"0.01", "-1"},
+		{"abc", "0.001"},
+		{"0.01", ""},
+	} {
+		if _, err := ParsePrecision(tt.tick, tt.step); !errors.Is(err, ErrInvalidPrecision) {
+			t.Errorf("ParsePrecision(%q, %q): expected ErrInvalidPrecision, got %v", tt.tick, tt.step, err)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	if got := PnL(2000, 2000.1, 3, false); got != 0.3 {
+		t.Errorf("Expected a long PnL of exactly 0.3, got %v", got)
+	}
+	if got := PnL(2000, 2000.1, 3, true); got != -0.3 {
+		t.Errorf("Expected a short PnL of exactly -0.3, got %v", got)
+	}
+	if got := Fee(2000.1, 0.3, 0.0004); got != 0.240012 {
+		t.Errorf("Expected a fee of exactly 0.240012, got %v", got)
+	}
+	if got := Notional(0.1, 3); got != 0.3 {
+		t.Errorf("Expected a notional of exactly 0.3, got %v", got)
+	}
+	if got := AveragePrice(100, 0.5, 99.7, 0.25); got != 99.9 {
+		t.Errorf("Expected an average price of exactly 99.9, got %v", got)
+	}
+	if got := AveragePrice(100, 0.5, 99, -0.5); got != 100 {
+		t.Errorf("Expected the price for a zero quantity, got %v", got)
+	}
+}
+
+func TestInverseArithmetic(t *testing.T) {
+	// 100 contracts of 10 USD are worth 0.5 coin at 2000 and 0.4 coin at 2500
+	if got := InverseNotional(2000, 100, 10); got != 0.5 {
+		t.Errorf("Expected an inverse notional of exactly 0.5, got %v", got)
+	}
+	if got := InversePnL(2000, 2500, 100, 10, false); got != 0.1 {
+		t.Errorf("Expected a long inverse PnL of exactly 0.1, got %v", got)
+	}
+	if got := InversePnL(2000, 2500, 100, 10, true); got != -0.1 {
+		t.Errorf("Expected a short inverse PnL of exactly -0.1, got %v", got)
+	}
+	// Non-linear: an equal fall loses more coin than the rise gained
+	if got := InversePnL(2000, 1500, 100, 10, false); math.Abs(got+1.0/6) > 1e-12 {
+		t.Errorf("Expected a long inverse PnL of -1/6, got %v", got)
+	}
+	if got := InversePnL(2000, 0, 100, 10, false); got != 0 {
+		t.Errorf("Expected no PnL for a non-positive price, got %v", got)
+	}
+	if got := InverseAveragePrice(2000, 200, 4000, 100); got != 2400 {
+		t.Errorf("Expected a harmonic average price of exactly 2400, got %v", got)
+	}
+	if got := InverseAveragePrice(0, 0, 2000, 5); got != 2000 {
+		t.Errorf("Expected the first fill's price, got %v", got)
+	}
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "time"
+
+// Clock is the source of the current time. Trading logic takes the time from a Clock instead of
+// calling time.Now, so simulations and tests can control it.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
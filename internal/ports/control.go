@@ -0,0 +1,148 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// KillSwitchStatus is a snapshot of the equity-curve kill switch state.
+type KillSwitchStatus struct {
+	Tripped       bool      `json:"tripped"`             // Whether new entries are paused
+	Reason        string    `json:"reason,omitempty"`    // Why the switch tripped
+	TrippedAt     time.Time `json:"trippedAt,omitempty"` // When the switch tripped
+	ResumeAt      time.Time `json:"resumeAt,omitempty"`  // When entries resume automatically
+	PeakEquity    float64   `json:"peakEquity"`          // Highest equity observed since the last reset
+	CurrentEquity float64   `json:"currentEquity"`       // Latest realized+unrealized equity
+	Drawdown      float64   `json:"drawdown"`            // Current drawdown from peak (0.1 = 10%)
+	LosingDays    int       `json:"losingDays"`          // Current streak of losing days
+}
+
+// EquityTrailStatus is a snapshot of the account equity trail for the current UTC day.
+type EquityTrailStatus struct {
+	Armed          bool      `json:"armed"`              // Whether the daily profit reached the target
+	Locked         bool      `json:"locked"`             // Whether equity fell back to the floor, stopping trading for the day
+	Reason         string    `json:"reason,omitempty"`   // Why the day was locked in
+	LockedAt       time.Time `json:"lockedAt,omitempty"` // When the day was locked in
+	DayStartEquity float64   `json:"dayStartEquity"`     // Equity at the start of the day
+	CurrentEquity  float64   `json:"currentEquity"`      // Latest realized+unrealized equity
+	DailyProfit    float64   `json:"dailyProfit"`        // Current equity minus the day's starting equity
+	PeakProfit     float64   `json:"peakProfit"`         // Highest daily profit since the trail armed
+	Target         float64   `json:"target"`             // Daily profit that arms the trail
+	Floor          float64   `json:"floor,omitempty"`    // Equity that locks in the day, once armed
+}
+
+// ClockStatus is a snapshot of the local vs exchange clock drift monitor.
+type ClockStatus struct {
+	DriftMs     int64     `json:"driftMs"`              // Exchange time minus synchronized local time at the last check
+	MaxDriftMs  int64     `json:"maxDriftMs"`           // Drift that triggers a resync
+	RoundTripMs int64     `json:"roundTripMs"`          // Latency of the last server time request
+	LastCheck   time.Time `json:"lastCheck,omitempty"`  // When drift was last measured
+	LastResync  time.Time `json:"lastResync,omitempty"` // When the exchange client was last resynchronized
+	Resyncs     int       `json:"resyncs"`              // Number of resyncs since startup
+	LastError   string    `json:"lastError,omitempty"`  // Error of the last failed check, if any
+}
+
+// StreamStatus is a snapshot of the primary kline stream's continuity, checked by the stream watchdog.
+type StreamStatus struct {
+	LastKlineAt   time.Time `json:"lastKlineAt,omitempty"` // When the last final kline was received
+	Continuous    bool      `json:"continuous"`            // False after a stall or gap until klines continue the cache again
+	Issue         string    `json:"issue,omitempty"`       // Latest stall or gap while not continuous
+	IssueSince    time.Time `json:"issueSince,omitempty"`  // When continuity was lost
+	EntriesPaused bool      `json:"entriesPaused"`         // Whether new entries wait for continuity
+	Gaps          int       `json:"gaps"`                  // Gaps between consecutive klines since startup
+	Stalls        int       `json:"stalls"`                // Stream stalls since startup
+}
+
+// ReconnectStatus is a snapshot of the exchange stream reconnections against the alert threshold.
+type ReconnectStatus struct {
+	ReconnectStats
+	RecentReconnects int   `json:"recentReconnects"` // Reconnects within the alert window
+	WindowMs         int64 `json:"windowMs"`         // Rolling window reconnects are counted in
+	Threshold        int   `json:"threshold"`        // Reconnects within the window that trigger an alert
+	Alerting         bool  `json:"alerting"`         // Whether the threshold is currently reached
+}
+
+// LatencyStatus is a snapshot of the exchange request latencies against the alert threshold.
+type LatencyStatus struct {
+	Operations     map[string]OperationLatency `json:"operations"`         // By operation, e.g. place_order
+	P95ThresholdMs int64                       `json:"p95ThresholdMs"`     // p95 latency that triggers an alert
+	Alerting       []string                    `json:"alerting,omitempty"` // Operations whose p95 latency is above the threshold
+}
+
+// CircuitBreakerStatus is a snapshot of the order circuit breaker.
+type CircuitBreakerStatus struct {
+	State               string    `json:"state"`               // CLOSED, OPEN or HALF_OPEN
+	ConsecutiveFailures int       `json:"consecutiveFailures"` // Consecutive order failures within the window
+	MaxFailures         int       `json:"maxFailures"`         // Failures that open the breaker
+	LastError           string    `json:"lastError,omitempty"` // Error of the last failed order
+	OpenedAt            time.Time `json:"openedAt,omitempty"`  // When the breaker last opened, unless closed
+	ProbeAt             time.Time `json:"probeAt,omitempty"`   // When a probe order is allowed, unless closed
+	Trips               int       `json:"trips"`               // Times the breaker opened since startup
+}
+
+// StrategyFactory builds a strategy instance. Params override the strategy's configured
+// parameters; factories reject parameters they don't know.
+type StrategyFactory func(params map[string]float64) (Strategy, error)
+
+// StrategyStatus describes the active strategy and the strategies it can be switched to.
+type StrategyStatus struct {
+	Name       string             `json:"name"`                 // Registered name of the active strategy
+	Params     map[string]float64 `json:"params,omitempty"`     // Parameter overrides of the active strategy
+	SwitchedAt time.Time          `json:"switchedAt,omitempty"` // When the strategy was last switched at runtime
+	Available  []string           `json:"available"`            // Registered strategy names
+}
+
+// StrategySwitchRequest asks the trading service to replace its strategy at runtime.
+// Switching to the active strategy with new params updates its parameters.
+type StrategySwitchRequest struct {
+	Name   string             `json:"name"`
+	Params map[string]float64 `json:"params,omitempty"`
+	// ClosePositions closes open positions at market before switching. Otherwise they are kept
+	// and managed by the new strategy.
+	ClosePositions bool `json:"closePositions"`
+}
+
+// TradingStatus is a snapshot of the trading service state exposed to operators.
+type TradingStatus struct {
+	Symbol          string                `json:"symbol"`
+	HasOpenPosition bool                  `json:"hasOpenPosition"`
+	TradesToday     int                   `json:"tradesToday"`
+	MaxOrders       int                   `json:"maxOrders"`
+	Paused          string                `json:"paused,omitempty"`         // Reason new entries were paused by an operator, if they are
+	KillSwitch      *KillSwitchStatus     `json:"killSwitch,omitempty"`     // Nil if the kill switch is disabled
+	EquityTrail     *EquityTrailStatus    `json:"equityTrail,omitempty"`    // Nil if the equity trail is disabled
+	Clock           *ClockStatus          `json:"clock,omitempty"`          // Nil if clock drift monitoring is disabled
+	Strategy        *StrategyStatus       `json:"strategy,omitempty"`       // Nil if strategy switching is disabled
+	Stream          *StreamStatus         `json:"stream,omitempty"`         // Nil if the stream watchdog is disabled
+	Reconnects      *ReconnectStatus      `json:"reconnects,omitempty"`     // Nil if reconnect alerts are disabled
+	Latency         *LatencyStatus        `json:"latency,omitempty"`        // Nil if latency alerts are disabled
+	CircuitBreaker  *CircuitBreakerStatus `json:"circuitBreaker,omitempty"` // Nil if the order circuit breaker is disabled
+	Blackout        string                `json:"blackout,omitempty"`       // Name of the active blackout window, if any
+	Timestamp       time.Time             `json:"timestamp"`
+}
+
+// TradingController exposes operator controls over a running trading service.
+// Implemented by the application service and consumed by control adapters (e.g., HTTP API).
+type TradingController interface {
+	// Status returns a snapshot of the current trading state.
+	Status(ctx context.Context) TradingStatus
+
+	// ResumeTrading lifts an operator pause, clears a tripped kill switch and unlocks a locked-in
+	// equity trail so new entries are allowed again.
+	ResumeTrading(ctx context.Context) error
+
+	// PauseTrading stops new entries until ResumeTrading. Open positions are still managed.
+	PauseTrading(ctx context.Context, reason string) error
+
+	// OpenPositions returns copies of the open positions (at most one per side).
+	OpenPositions(ctx context.Context) []domain.Position
+
+	// ForceClose closes the open positions on side (every open position if side is empty) at
+	// market, returning them as closed.
+	ForceClose(ctx context.Context, side domain.PositionSide) ([]domain.Position, error)
+
+	// SwitchStrategy replaces the active strategy (or updates its parameters) without a restart.
+	SwitchStrategy(ctx context.Context, req StrategySwitchRequest) error
+}
@@ -0,0 +1,73 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// DashboardPosition is an open position with its unrealized PnL at the last price.
+type DashboardPosition struct {
+	ID            int64     `json:"id"`
+	Side          string    `json:"side"`
+	EntryPrice    float64   `json:"entryPrice"`
+	Quantity      float64   `json:"quantity"`
+	StopLoss      float64   `json:"stopLoss"`
+	TakeProfit    float64   `json:"takeProfit"`
+	EntryTime     time.Time `json:"entryTime"`
+	UnrealizedPnL float64   `json:"unrealizedPnl"`
+}
+
+// DashboardTrade is a position closed today.
+type DashboardTrade struct {
+	Side        string    `json:"side"`
+	EntryPrice  float64   `json:"entryPrice"`
+	ExitPrice   float64   `json:"exitPrice"`
+	Quantity    float64   `json:"quantity"`
+	PNL         float64   `json:"pnl"`
+	EntryTime   time.Time `json:"entryTime"`
+	ExitTime    time.Time `json:"exitTime"`
+	CloseReason string    `json:"closeReason"`
+}
+
+// EquityPoint is a snapshot of the account equity (balance plus unrealized PnL).
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// LogLine is a formatted log entry.
+type LogLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// DashboardSnapshot is the live trading data shown on the web dashboard.
+type DashboardSnapshot struct {
+	Symbol        string              `json:"symbol"`
+	Price         float64             `json:"price"`               // Close of the last kline
+	PriceTime     time.Time           `json:"priceTime,omitempty"` // Close time of the last kline
+	Positions     []DashboardPosition `json:"positions"`
+	TradesToday   []DashboardTrade    `json:"tradesToday"`
+	RealizedToday float64             `json:"realizedToday"` // PNL of the trades closed today
+	Equity        []EquityPoint       `json:"equity"`        // Equity curve, oldest first
+	Logs          []LogLine           `json:"logs,omitempty"`
+	Timestamp     time.Time           `json:"timestamp"`
+
+	// Reporting currency (optional): the amounts above are in the quote asset, and multiplying
+	// them by ConversionRate gives them in Currency
+	Currency       string  `json:"currency,omitempty"`
+	ConversionRate float64 `json:"conversionRate,omitempty"`
+}
+
+// DashboardProvider supplies live trading data to the web dashboard.
+type DashboardProvider interface {
+	// Dashboard returns a snapshot of prices, positions, today's trades and the equity curve.
+	Dashboard(ctx context.Context) (DashboardSnapshot, error)
+}
+
+// LogHistory keeps the most recent log lines.
+type LogHistory interface {
+	// Recent returns the kept log lines, oldest first.
+	Recent() []LogLine
+}
@@ -16,6 +16,7 @@ var (
 
 	// Exchange Specific Errors
 	ErrExchangeUnavailable  = errors.New("exchange API is unavailable")
+	ErrExchangeMaintenance  = errors.New("exchange is under maintenance")
 	ErrConnectionFailed     = errors.New("failed to connect to the exchange")
 	ErrRateLimited          = errors.New("API rate limit exceeded")
 	ErrAuthenticationFailed = errors.New("exchange authentication failed (check API keys)")
@@ -25,6 +26,10 @@ var (
 	ErrPositionNotFound     = errors.New("position not found on the exchange")
 	ErrOrderPlacementFailed = errors.New("failed to place order")
 	ErrOrderCancelFailed    = errors.New("failed to cancel order")
+	ErrReduceOnlyRejected   = errors.New("reduce-only order would increase or open a position")
+	ErrPostOnlyRejected     = errors.New("post-only order would execute immediately as taker")
+	ErrNoChangeNeeded       = errors.New("requested setting is already in effect")
+	ErrClockSkew            = errors.New("request timestamp outside the exchange's receive window (clock drift)")
 
 	// Database Specific Errors
 	ErrDuplicateEntry = errors.New("database record already exists")
@@ -32,4 +37,63 @@ var (
 	ErrQueryFailed    = errors.New("database query failed")
 	ErrUpdateFailed   = errors.New("database update failed")
 	ErrDeleteFailed   = errors.New("database delete failed")
+
+	// Notification Errors
+	ErrNotificationFailed = errors.New("failed to deliver notification")
+)
+
+// ErrorCategory classifies an error by what can be done about it, so callers decide whether to
+// retry, alert or give up without knowing every error value.
+type ErrorCategory string
+
+const (
+	// CategoryTransient errors may succeed when retried later: outages, maintenance, timeouts,
+	// dropped connections, rate limits and clock drift.
+	CategoryTransient ErrorCategory = "transient"
+	// CategoryPermanent errors fail the same way on every retry: missing or duplicate records,
+	// canceled operations.
+	CategoryPermanent ErrorCategory = "permanent"
+	// CategoryUserConfig errors need the operator to fix the setup: API keys, permissions and
+	// invalid settings.
+	CategoryUserConfig ErrorCategory = "user-config"
+	// CategoryExchangeRejection errors are the exchange refusing this particular request: its
+	// parameters, the account's funds, reduce-only and post-only rules.
+	CategoryExchangeRejection ErrorCategory = "exchange-rejection"
+	// CategoryUnknown errors are not wrapped in a classified error.
+	CategoryUnknown ErrorCategory = "unknown"
 )
+
+// errorCategories lists the classified errors. Errors wrapping several of them (e.g., a
+// maintenance error that is also a rejected order) take the category listed first.
+var errorCategories = []struct {
+	category ErrorCategory
+	errs     []error
+}{
+	{CategoryTransient, []error{ErrExchangeUnavailable, ErrExchangeMaintenance, ErrConnectionFailed, ErrTimeout,
+		ErrRateLimited, ErrClockSkew, ErrDBConnection, ErrNotificationFailed}},
+	{CategoryUserConfig, []error{ErrConfigurationError, ErrAuthenticationFailed, ErrInvalidAPIKeys, ErrPermissionDenied}},
+	{CategoryExchangeRejection, []error{ErrInsufficientFunds, ErrInvalidRequest, ErrReduceOnlyRejected, ErrPostOnlyRejected,
+		ErrOrderPlacementFailed, ErrOrderCancelFailed, ErrNoChangeNeeded}},
+	{CategoryPermanent, []error{ErrNotFound, ErrOrderNotFound, ErrPositionNotFound, ErrDuplicateEntry, ErrContextCanceled}},
+}
+
+// Category returns the category of err, CategoryUnknown if it wraps none of the classified errors
+// (or is nil).
+func Category(err error) ErrorCategory {
+	if err == nil {
+		return CategoryUnknown
+	}
+	for _, class := range errorCategories {
+		for _, target := range class.errs {
+			if errors.Is(err, target) {
+				return class.category
+			}
+		}
+	}
+	return CategoryUnknown
+}
+
+// IsRetryable reports whether retrying the operation that failed with err may succeed.
+func IsRetryable(err error) bool {
+	return Category(err) == CategoryTransient
+}
@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCategory(t *testing.T) {
+	tests := []struct {
+		err       error
+		want      ErrorCategory
+		retryable bool
+	}{
+		{fmt.Errorf("GetKlines failed: %w: %w", ErrExchangeUnavailable, errors.New("502")), CategoryTransient, true},
+		{ErrRateLimited, CategoryTransient, true},
+		{fmt.Errorf("place order: %w", ErrClockSkew), CategoryTransient, true},
+		{ErrInvalidAPIKeys, CategoryUserConfig, false},
+		{fmt.Errorf("invalid: %w", ErrConfigurationError), CategoryUserConfig, false},
+		{ErrInsufficientFunds, CategoryExchangeRejection, false},
+		{ErrReduceOnlyRejected, CategoryExchangeRejection, false},
+		{ErrOrderNotFound, CategoryPermanent, false},
+		{fmt.Errorf("canceled: %w: %w", ErrContextCanceled, context.Canceled), CategoryPermanent, false},
+		{ErrUnknown, CategoryUnknown, false},
+		{errors.New("something else"), CategoryUnknown, false},
+		{nil, CategoryUnknown, false},
+	}
+	for _, tt := range tests {
+		if got := Category(tt.err); got != tt.want {
+			t.Errorf("Category(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+		if got := IsRetryable(tt.err); got != tt.retryable {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.retryable)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// EventType identifies a trading event published on the event bus.
+type EventType string
+
+const (
+	EventKlineReceived     EventType = "KLINE_RECEIVED"      // A final kline of the primary interval was received
+	EventSignalGenerated   EventType = "SIGNAL_GENERATED"    // The strategy signaled an entry or an exit
+	EventOrderPlaced       EventType = "ORDER_PLACED"        // A market order was accepted by the exchange
+	EventOrderFilled       EventType = "ORDER_FILLED"        // A market order was (partially) filled
+	EventPositionOpened    EventType = "POSITION_OPENED"     // A position was protected, saved and opened
+	EventPositionClosed    EventType = "POSITION_CLOSED"     // A position was closed and saved
+	EventRiskLimitBreached EventType = "RISK_LIMIT_BREACHED" // A risk limit stopped new entries
+)
+
+// Event is a trading event. Only the fields relevant to its Type are set. Kline and Position are
+// snapshots taken when the event was published, so subscribers may keep them.
+type Event struct {
+	Type   EventType
+	Symbol string
+	Time   time.Time
+
+	Kline    *domain.Kline       // KLINE_RECEIVED
+	Side     domain.PositionSide // SIGNAL_GENERATED, ORDER_* and POSITION_* events
+	Exit     bool                // SIGNAL_GENERATED and ORDER_*: whether it closes (part of) a position
+	Position *domain.Position    // POSITION_* events and exit signals
+	OrderID  int64               // ORDER_* events
+	Quantity float64             // ORDER_* events: ordered or filled base quantity
+	Price    float64             // SIGNAL_GENERATED: signal price; ORDER_FILLED: average fill price
+	Reason   string              // SIGNAL_GENERATED: close reason of exits; RISK_LIMIT_BREACHED: the limit
+}
+
+// EventHandler receives the events a subscriber subscribed to.
+type EventHandler func(ctx context.Context, event Event)
+
+// EventBus delivers trading events to subscribers, so subsystems such as notifications, metrics
+// or audit logs can observe trading without changes to its core logic.
+type EventBus interface {
+	// Publish delivers event to the handlers subscribed to its type.
+	Publish(ctx context.Context, event Event)
+
+	// Subscribe registers handler for events of the given types (every event if none are given)
+	// and returns a function that removes the subscription.
+	Subscribe(handler EventHandler, types ...EventType) (unsubscribe func())
+}
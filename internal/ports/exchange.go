@@ -21,23 +21,86 @@ type OrderResponse struct {
 	Type          string    // Order type (e.g., MARKET, LIMIT, STOP_MARKET)
 	Side          string    // Order side (BUY, SELL)
 	Timestamp     time.Time // Time the order response was generated
+	StopPrice     float64   // Trigger price of stop and take-profit orders
+	ClosePosition bool      // Whether the order closes the whole position when triggered (the bot's SL/TP orders)
+
+	// Executions of the order, if the exchange reports them with the response (see OrderFillProvider)
+	Fills []AccountFill
 }
 
 // PositionRisk represents the risk details for an open position.
 type PositionRisk struct {
-	Symbol           string  // Symbol of the position
-	PositionAmt      float64 // Current position amount (positive for long, negative for short)
-	EntryPrice       float64 // Average entry price of the position
-	MarkPrice        float64 // Current mark price
-	UnRealizedProfit float64 // Unrealized profit/loss
-	LiquidationPrice float64 // Estimated liquidation price
-	Leverage         int     // Current leverage for the position
-	IsolatedMargin   float64 // Isolated margin (if applicable)
-	IsAutoAddMargin  bool    // Whether auto margin add is enabled
-	MaxNotionalValue float64 // Maximum notional value allowed
+	Symbol           string              // Symbol of the position
+	PositionAmt      float64             // Current position amount (positive for long, negative for short)
+	EntryPrice       float64             // Average entry price of the position
+	MarkPrice        float64             // Current mark price
+	UnRealizedProfit float64             // Unrealized profit/loss
+	LiquidationPrice float64             // Estimated liquidation price
+	Leverage         int                 // Current leverage for the position
+	IsolatedMargin   float64             // Isolated margin (if applicable)
+	IsAutoAddMargin  bool                // Whether auto margin add is enabled
+	MaxNotionalValue float64             // Maximum notional value allowed
+	MarginType       domain.MarginType   // Margin mode of the position (ISOLATED or CROSSED)
+	PositionSide     domain.PositionSide // BOTH in one-way mode, LONG or SHORT in hedge mode
 	// UpdateTime       time.Time // No direct UpdateTime field in futures.PositionRisk
 }
 
+// PositionRiskFor returns the risk of the side (LONG or SHORT) position among risks: the hedge mode
+// side of that name, or the one-way (BOTH) position if its amount points that way. Returns nil if
+// there is no such position.
+func PositionRiskFor(risks []*PositionRisk, side domain.PositionSide) *PositionRisk {
+	for _, risk := range risks {
+		switch {
+		case risk == nil || risk.PositionAmt == 0:
+		case risk.PositionSide == side:
+			return risk
+		case risk.PositionSide != domain.PositionSideLong && risk.PositionSide != domain.PositionSideShort:
+			if (side == domain.PositionSideShort) == (risk.PositionAmt < 0) {
+				return risk
+			}
+		}
+	}
+	return nil
+}
+
+// OrderBookLevel is a single price level in the order book.
+type OrderBookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBookDepth is a snapshot of the top of the order book.
+type OrderBookDepth struct {
+	Symbol    string
+	Bids      []OrderBookLevel // Best (highest) bid first
+	Asks      []OrderBookLevel // Best (lowest) ask first
+	Timestamp time.Time
+}
+
+// AccountFill is one execution of the account's orders, as reported by the exchange's trade history.
+type AccountFill struct {
+	ID              int64               // Exchange's trade ID
+	OrderID         int64               // Order the fill belongs to
+	Symbol          string              // Symbol of the fill
+	Side            domain.OrderSide    // BUY or SELL
+	PositionSide    domain.PositionSide // BOTH in one-way mode, LONG or SHORT in hedge mode
+	Price           float64             // Execution price
+	Quantity        float64             // Executed quantity
+	RealizedPnL     float64             // PnL realized by the fill (before commission)
+	Commission      float64             // Commission charged for the fill
+	CommissionAsset string              // Asset the commission was charged in (e.g., USDT or BNB)
+	Time            time.Time           // Execution time
+}
+
+// IncomeRecord is an entry of the account's income history (realized PnL, commissions, funding fees, etc.).
+type IncomeRecord struct {
+	Symbol     string    // Symbol the income relates to (empty for account-wide entries such as transfers)
+	IncomeType string    // Exchange's income type (e.g., FUNDING_FEE, COMMISSION, REALIZED_PNL)
+	Income     float64   // Amount, negative when paid
+	Asset      string    // Asset of the amount
+	Time       time.Time // When the income was booked
+}
+
 // ExchangeClient defines the interface for interacting with a cryptocurrency exchange.
 // This abstraction allows decoupling the core bot logic from specific exchange implementations.
 type ExchangeClient interface {
@@ -57,20 +120,42 @@ type ExchangeClient interface {
 	SetLeverage(ctx context.Context, symbol string, leverage int) error
 
 	// PlaceMarketOrder places a market order.
+	// positionSide selects the LONG or SHORT side in hedge mode; use PositionSideBoth in one-way mode.
+	// clientOrderID tags the order so it can be looked up with GetOrderByClientID; leave it empty
+	// to let the exchange assign one.
 	// Returns the essential order details upon successful execution.
-	PlaceMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string) (*OrderResponse, error)
+	PlaceMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, clientOrderID string) (*OrderResponse, error)
+
+	// GetOrderByClientID retrieves an order by the client order ID it was placed with, including
+	// filled, canceled and expired orders.
+	// Returns ErrOrderNotFound if the exchange has no such order for the symbol.
+	GetOrderByClientID(ctx context.Context, symbol string, clientOrderID string) (*OrderResponse, error)
+
+	// ListOpenOrders retrieves all orders for a symbol that are still open (not yet filled,
+	// canceled or expired), including untriggered stop and take-profit orders.
+	ListOpenOrders(ctx context.Context, symbol string) ([]*OrderResponse, error)
 
 	// PlaceStopMarketOrder places a stop-market order.
 	// Returns the essential order details upon successful placement.
-	PlaceStopMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string, stopPrice string) (*OrderResponse, error)
+	PlaceStopMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (*OrderResponse, error)
 
 	// PlaceTakeProfitMarketOrder places a take-profit-market order.
 	// Returns the essential order details upon successful placement.
-	PlaceTakeProfitMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, quantity string, stopPrice string) (*OrderResponse, error)
+	PlaceTakeProfitMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (*OrderResponse, error)
+
+	// SetPositionMode switches the account between hedge mode (separate LONG and SHORT
+	// positions per symbol) and one-way mode.
+	// Returns ErrNoChangeNeeded if the account is already in the requested mode.
+	SetPositionMode(ctx context.Context, hedgeMode bool) error
 
-	// GetPositionRisk retrieves the risk information for a specific position symbol.
-	// Returns nil if no position exists for the symbol.
-	GetPositionRisk(ctx context.Context, symbol string) (*PositionRisk, error)
+	// ChangeMarginType switches the margin mode (isolated or cross) for a symbol.
+	// Returns ErrNoChangeNeeded if the symbol is already in the requested mode.
+	ChangeMarginType(ctx context.Context, symbol string, marginType domain.MarginType) error
+
+	// GetPositionRisks retrieves the risk information of a symbol's open positions: the one-way
+	// (BOTH) position, or each hedge mode side (LONG, SHORT) with a non-zero amount.
+	// Returns an empty slice if no position exists for the symbol.
+	GetPositionRisks(ctx context.Context, symbol string) ([]*PositionRisk, error)
 
 	// StreamKlines starts a WebSocket stream for K-line/candlestick data.
 	// It takes handlers for processing domain.Kline events and errors.
@@ -86,6 +171,155 @@ type ExchangeClient interface {
 	// GetKlines retrieves historical klines/candlestick data for the given symbol.
 	GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]*domain.Kline, error)
 
+	// GetOrderBookDepth retrieves a snapshot of the order book with up to limit levels per side.
+	GetOrderBookDepth(ctx context.Context, symbol string, limit int) (*OrderBookDepth, error)
+
 	// CancelOrder cancels an existing open order by its ID.
 	CancelOrder(ctx context.Context, symbol string, orderID int64) (*OrderResponse, error) // Returns details of the cancelled order
 }
+
+// ReconnectStats counts the reconnections of an exchange client's kline streams since startup.
+type ReconnectStats struct {
+	Reconnects          int       `json:"reconnects"`                 // Connections re-established after a stream dropped
+	Failures            int       `json:"failures"`                   // Failed connection attempts
+	ConsecutiveFailures int       `json:"consecutiveFailures"`        // Failed attempts in a row of the stream failing the longest
+	DowntimeMs          int64     `json:"downtimeMs"`                 // Time streams spent disconnected, including current outages
+	Disconnected        int       `json:"disconnected"`               // Streams currently disconnected
+	LastDisconnectAt    time.Time `json:"lastDisconnectAt,omitempty"` // When a stream last dropped
+	LastReconnectAt     time.Time `json:"lastReconnectAt,omitempty"`  // When a stream last reconnected
+}
+
+// ReconnectStatsProvider is implemented by exchange clients that track the reconnections of
+// their kline streams.
+type ReconnectStatsProvider interface {
+	ReconnectStats() ReconnectStats
+}
+
+// Exchange operations whose request latency is tracked.
+const (
+	LatencyPlaceOrder  = "place_order"  // Market, limit, stop-market and take-profit-market orders
+	LatencyCancelOrder = "cancel_order" // Order cancellations
+	LatencyFetchKlines = "fetch_klines" // Historical kline requests
+)
+
+// OperationLatency summarizes the request latency of an exchange operation. Percentiles are over
+// the most recent requests.
+type OperationLatency struct {
+	Count   int   `json:"count"`   // Requests since startup
+	Errors  int   `json:"errors"`  // Failed requests since startup
+	Samples int   `json:"samples"` // Recent requests the percentiles are calculated from
+	P50Ms   int64 `json:"p50Ms"`
+	P95Ms   int64 `json:"p95Ms"`
+	P99Ms   int64 `json:"p99Ms"`
+	MaxMs   int64 `json:"maxMs"`  // Slowest recent request
+	LastMs  int64 `json:"lastMs"` // Latest request
+}
+
+// LatencyStatsProvider is implemented by exchange clients that track the latency of their
+// requests, keyed by operation (e.g., LatencyPlaceOrder).
+type LatencyStatsProvider interface {
+	LatencyStats() map[string]OperationLatency
+}
+
+// LeverageBracket is one notional tier of a symbol's leverage brackets: positions with a notional
+// value between NotionalFloor and NotionalCap can use at most InitialLeverage.
+type LeverageBracket struct {
+	Bracket          int     `json:"bracket"`          // Tier number, 1 being the smallest notional
+	InitialLeverage  int     `json:"initialLeverage"`  // Maximum leverage within the tier
+	NotionalFloor    float64 `json:"notionalFloor"`    // Lowest notional of the tier (quote currency)
+	NotionalCap      float64 `json:"notionalCap"`      // Highest notional of the tier (quote currency)
+	MaintMarginRatio float64 `json:"maintMarginRatio"` // Maintenance margin rate within the tier
+}
+
+// LeverageBracketProvider is implemented by exchange clients that can fetch a symbol's leverage
+// brackets.
+type LeverageBracketProvider interface {
+	// GetLeverageBrackets returns the symbol's brackets ordered by notional, smallest first.
+	GetLeverageBrackets(ctx context.Context, symbol string) ([]LeverageBracket, error)
+}
+
+// TickerPriceSource provides the last traded price of symbols, e.g. for currency conversions.
+// Exchange clients implement it through GetTickerPrice.
+type TickerPriceSource interface {
+	// GetTickerPrice retrieves the last ticker price for a given symbol.
+	GetTickerPrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// TickerStats are a symbol's rolling 24h ticker statistics.
+type TickerStats struct {
+	Symbol             string  // Symbol of the statistics
+	LastPrice          float64 // Last traded price
+	PriceChangePercent float64 // Price change over the window in percent
+	Volume             float64 // Base asset volume traded in the window
+	QuoteVolume        float64 // Quote asset volume traded in the window
+}
+
+// TickerStatsProvider is implemented by exchange clients that can fetch a symbol's 24h ticker
+// statistics.
+type TickerStatsProvider interface {
+	// GetTickerStats returns the symbol's rolling 24h statistics.
+	GetTickerStats(ctx context.Context, symbol string) (*TickerStats, error)
+}
+
+// BookTicker is a symbol's best bid and ask.
+type BookTicker struct {
+	Symbol   string
+	BidPrice float64 // Best bid price
+	BidQty   float64 // Quantity at the best bid
+	AskPrice float64 // Best ask price
+	AskQty   float64 // Quantity at the best ask
+}
+
+// BookTickerProvider is implemented by exchange clients that can fetch a symbol's best bid and
+// ask without the full order book.
+type BookTickerProvider interface {
+	// GetBookTicker returns the symbol's current best bid and ask.
+	GetBookTicker(ctx context.Context, symbol string) (*BookTicker, error)
+}
+
+// OpenInterestProvider is implemented by exchange clients that can fetch a symbol's open interest
+// history.
+type OpenInterestProvider interface {
+	// GetOpenInterestHistory returns the open interest snapshots at period (e.g., "5m") between
+	// start and end, oldest first.
+	GetOpenInterestHistory(ctx context.Context, symbol, period string, start, end time.Time) ([]*domain.OpenInterest, error)
+}
+
+// LimitOrderPlacer is implemented by exchange clients that can place limit orders, e.g. for
+// entries that wait for a better price.
+type LimitOrderPlacer interface {
+	// PlaceLimitOrder places a good-till-canceled limit order at price. Like PlaceMarketOrder,
+	// clientOrderID tags the order so it can be looked up with GetOrderByClientID.
+	PlaceLimitOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, price string, clientOrderID string) (*OrderResponse, error)
+}
+
+// PostOnlyOrderPlacer is implemented by exchange clients that can place post-only limit orders,
+// which only ever rest on the book as maker.
+type PostOnlyOrderPlacer interface {
+	// PlacePostOnlyLimitOrder places a limit order at price like PlaceLimitOrder, but fails with
+	// ErrPostOnlyRejected instead of filling right away when price would cross the book.
+	PlacePostOnlyLimitOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, price string, clientOrderID string) (*OrderResponse, error)
+}
+
+// ReduceOnlyOrderPlacer is implemented by exchange clients that can place reduce-only market
+// orders, which only ever shrink a position and so can't open or flip one. An order that would
+// open or grow a position fails with ErrReduceOnlyRejected.
+type ReduceOnlyOrderPlacer interface {
+	PlaceReduceOnlyMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string) (*OrderResponse, error)
+}
+
+// IncomeHistoryProvider is implemented by exchange clients that can fetch the account's income
+// history (funding fees, commissions, realized PnL).
+type IncomeHistoryProvider interface {
+	// GetIncomeHistory fetches a symbol's income records booked between start and end, oldest
+	// first. An empty incomeType returns every type.
+	GetIncomeHistory(ctx context.Context, symbol, incomeType string, start, end time.Time) ([]IncomeRecord, error)
+}
+
+// OrderFillProvider is implemented by exchange clients that can look up the executions of an
+// order, for orders whose response doesn't include them.
+type OrderFillProvider interface {
+	// GetOrderFills returns the fills of the order, ordered by execution time. The list may be
+	// empty if the exchange hasn't reported the executions yet.
+	GetOrderFills(ctx context.Context, symbol string, orderID int64) ([]AccountFill, error)
+}
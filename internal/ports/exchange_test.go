@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"testing"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+func TestPositionRiskFor(t *testing.T) {
+	oneWayLong := &PositionRisk{PositionAmt: 0.1, PositionSide: domain.PositionSideBoth}
+	oneWayShort := &PositionRisk{PositionAmt: -0.1, PositionSide: domain.PositionSideBoth}
+	hedgeLong := &PositionRisk{PositionAmt: 0.2, PositionSide: domain.PositionSideLong}
+	hedgeShort := &PositionRisk{PositionAmt: -0.3, PositionSide: domain.PositionSideShort}
+	flatShort := &PositionRisk{PositionAmt: 0, PositionSide: domain.PositionSideShort}
+
+	tests := []struct {
+		name  string
+		risks []*PositionRisk
+		side  domain.PositionSide
+		want  *PositionRisk
+	}{
+		{"flat", nil, domain.PositionSideLong, nil},
+		{"one-way long", []*PositionRisk{oneWayLong}, domain.PositionSideLong, oneWayLong},
+		{"one-way long isn't short", []*PositionRisk{oneWayLong}, domain.PositionSideShort, nil},
+		{"one-way short", []*PositionRisk{oneWayShort}, domain.PositionSideShort, oneWayShort},
+		{"hedge long next to a short", []*PositionRisk{hedgeShort, hedgeLong}, domain.PositionSideLong, hedgeLong},
+		{"hedge short next to a long", []*PositionRisk{hedgeLong, hedgeShort}, domain.PositionSideShort, hedgeShort},
+		{"hedge side without amount", []*PositionRisk{hedgeLong, flatShort}, domain.PositionSideShort, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PositionRiskFor(tt.risks, tt.side); got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
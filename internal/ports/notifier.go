@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// Notifier delivers messages to operators (e.g., Telegram chat, email).
+type Notifier interface {
+	// Notify sends a message with a short subject line.
+	Notify(ctx context.Context, subject, message string) error
+}
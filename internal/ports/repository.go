@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"cryptoMegaBot/internal/domain"
 )
@@ -15,6 +16,9 @@ type PositionRepository interface {
 	// FindOpenBySymbol retrieves the currently open position for a given symbol, if any.
 	// Returns nil, nil if no open position is found.
 	FindOpenBySymbol(ctx context.Context, symbol string) (*domain.Position, error)
+	// FindOpenBySymbolAndSide retrieves the open position on one side of a symbol, if any.
+	// Returns nil, nil if no open position is found.
+	FindOpenBySymbolAndSide(ctx context.Context, symbol string, side domain.PositionSide) (*domain.Position, error)
 	// FindByID retrieves a position by its unique ID.
 	// Returns nil, nil if not found.
 	FindByID(ctx context.Context, id int64) (*domain.Position, error)
@@ -34,4 +38,163 @@ type TradeRepository interface {
 
 	// CountTodayBySymbol counts the number of *closed* positions executed today for a given symbol.
 	CountTodayBySymbol(ctx context.Context, symbol string) (int, error)
+
+	// FindClosedBetween retrieves *closed* positions for a symbol whose exit time is in [from, to),
+	// ordered by exit time ascending.
+	FindClosedBetween(ctx context.Context, symbol string, from, to time.Time) ([]*domain.Position, error)
+}
+
+// DailyReportRepository defines the interface for persisting daily summary reports.
+type DailyReportRepository interface {
+	// SaveDailyReport stores (or replaces) the report for its date and symbol.
+	SaveDailyReport(ctx context.Context, report *domain.DailyReport) error
+	// FindDailyReport retrieves the report for a symbol and UTC date.
+	// Returns nil, nil if no report exists.
+	FindDailyReport(ctx context.Context, symbol string, date time.Time) (*domain.DailyReport, error)
+}
+
+// ImportedTradeRepository defines the interface for storing trades rebuilt from the exchange's
+// trade history, kept apart from the positions the bot opened itself.
+type ImportedTradeRepository interface {
+	// SaveImportedTrades stores trades, skipping those already imported (same symbol, side and
+	// entry time). Returns the number of new trades.
+	SaveImportedTrades(ctx context.Context, trades []*domain.Trade) (int, error)
+	// FindImportedTrades retrieves imported trades for a symbol whose exit time is in [from, to),
+	// ordered by entry time ascending.
+	FindImportedTrades(ctx context.Context, symbol string, from, to time.Time) ([]*domain.Trade, error)
+}
+
+// EntryIntentRepository defines the interface for persisting entry orders before they are placed,
+// so a restart can find out whether an entry reached the exchange.
+type EntryIntentRepository interface {
+	// SaveEntryIntent stores a new intent.
+	// Returns ErrDuplicateEntry if an intent with the same client order ID already exists.
+	SaveEntryIntent(ctx context.Context, intent *domain.EntryIntent) error
+	// UpdateEntryIntentStatus sets the status of the intent with the given client order ID.
+	UpdateEntryIntentStatus(ctx context.Context, clientOrderID string, status domain.EntryIntentStatus) error
+	// FindPendingEntryIntents retrieves the symbol's intents still in EntryIntentPending, oldest first.
+	FindPendingEntryIntents(ctx context.Context, symbol string) ([]*domain.EntryIntent, error)
+}
+
+// DailyVolumeRepository defines the interface for persisting the entry volume traded per UTC day,
+// so the daily caps survive a restart.
+type DailyVolumeRepository interface {
+	// AddDailyVolume adds an entry fill's quote notional and base asset quantity to the symbol's
+	// totals for the UTC day of date.
+	AddDailyVolume(ctx context.Context, symbol string, date time.Time, notional, volume float64) error
+	// FindDailyVolume retrieves the symbol's totals for the UTC day of date.
+	// Returns zero totals if nothing was traded that day.
+	FindDailyVolume(ctx context.Context, symbol string, date time.Time) (notional, volume float64, err error)
+}
+
+// KlineCacheRepository defines the interface for persisting the service's kline cache, so a
+// restart can warm-start from it instead of refetching the full history.
+type KlineCacheRepository interface {
+	// SaveKlines replaces the saved klines of a symbol and interval.
+	SaveKlines(ctx context.Context, symbol, interval string, klines []*domain.Kline) error
+	// LoadKlines retrieves the saved klines of a symbol and interval, ordered by open time ascending.
+	// Returns an empty slice if nothing has been saved yet.
+	LoadKlines(ctx context.Context, symbol, interval string) ([]*domain.Kline, error)
+}
+
+// SafeModeRepository defines the interface for persisting the periods the bot spent in safe mode
+// during exchange maintenance or outages, so an outage spanning a restart is resumed.
+type SafeModeRepository interface {
+	// SaveSafeModeEvent stores a new event and sets its ID.
+	SaveSafeModeEvent(ctx context.Context, event *domain.SafeModeEvent) error
+	// EndSafeModeEvent sets the end time of the event with the given ID.
+	EndSafeModeEvent(ctx context.Context, id int64, endedAt time.Time) error
+	// FindActiveSafeModeEvent retrieves the symbol's latest event without an end time.
+	// Returns nil, nil if safe mode is not active.
+	FindActiveSafeModeEvent(ctx context.Context, symbol string) (*domain.SafeModeEvent, error)
+}
+
+// OrderFillRepository defines the interface for persisting the executions of the orders placed
+// for positions, so average prices and fees are known after a restart.
+type OrderFillRepository interface {
+	// SaveOrderFills stores fills, skipping those already saved (same order and trade ID).
+	SaveOrderFills(ctx context.Context, fills []*domain.OrderFill) error
+	// FindOrderFills retrieves the fills of a position, ordered by execution time ascending.
+	FindOrderFills(ctx context.Context, positionID int64) ([]*domain.OrderFill, error)
+}
+
+// OrderFilter selects logged orders; zero fields match every order.
+type OrderFilter struct {
+	Symbol     string
+	Status     string    // Exchange status (e.g., NEW, FILLED) or REJECTED
+	PositionID int64     // Orders of this position
+	From       time.Time // Orders sent at or after From
+	To         time.Time // Orders sent before To
+	Limit      int       // Maximum number of orders, newest first (0 for all)
+	Offset     int       // Matching orders to skip, for pagination
+}
+
+// OrderRepository defines the interface for logging the orders the bot sends to the exchange, so
+// operators can audit them.
+type OrderRepository interface {
+	// SaveOrder stores an order and sets its ID.
+	SaveOrder(ctx context.Context, order *domain.Order) error
+	// AssignOrders links the orders with the given exchange order IDs to a position, for orders
+	// placed before their position was saved.
+	AssignOrders(ctx context.Context, positionID int64, orderIDs []int64) error
+	// FindOrders retrieves a page of the orders matching the filter, newest first, and the total
+	// number of matching orders.
+	FindOrders(ctx context.Context, filter OrderFilter) ([]*domain.Order, int, error)
+}
+
+// BacktestRunFilter selects stored backtest runs; empty fields match every run.
+type BacktestRunFilter struct {
+	Strategy   string
+	Symbol     string
+	ConfigHash string
+	Limit      int // Maximum number of runs, newest first (0 for all)
+}
+
+// BacktestRunRepository defines the interface for storing backtest runs and their trades, so
+// results can be compared across runs.
+type BacktestRunRepository interface {
+	// SaveBacktestRun stores a run with its trades and sets the run's ID.
+	SaveBacktestRun(ctx context.Context, run *domain.BacktestRun, trades []*domain.Trade) error
+	// FindBacktestRuns retrieves the runs matching the filter, newest first.
+	FindBacktestRuns(ctx context.Context, filter BacktestRunFilter) ([]*domain.BacktestRun, error)
+	// FindBacktestTrades retrieves the trades of a run, ordered by entry time ascending.
+	FindBacktestTrades(ctx context.Context, runID int64) ([]*domain.Trade, error)
+}
+
+// MaintenanceOptions selects the optional database maintenance tasks; zero fields skip them.
+type MaintenanceOptions struct {
+	PruneBefore    time.Time // Delete cached klines opened and logged orders sent before this time
+	Vacuum         bool      // Rebuild the database file to return the space of deleted rows
+	IntegrityCheck bool      // Verify the database structure
+}
+
+// MaintenanceReport describes a database maintenance run and the state of the database after it.
+type MaintenanceReport struct {
+	PrunedKlines      int64
+	PrunedOrders      int64
+	Vacuumed          bool
+	CheckpointedPages int              // WAL pages copied into the database by the checkpoint
+	WALBusy           bool             // Whether readers or writers kept the checkpoint from completing
+	IntegrityChecked  bool             // Whether the integrity check ran
+	IntegrityErrors   []string         // Problems found by the integrity check (empty if it passed)
+	SizeBytes         int64            // Size of the database file
+	WALSizeBytes      int64            // Size of the write-ahead log file
+	TableRows         map[string]int64 // Row count of each table
+	Duration          time.Duration
+}
+
+// DatabaseMaintainer defines the interface for the periodic upkeep of the database.
+type DatabaseMaintainer interface {
+	// Maintain prunes and vacuums the database as selected by opts, checkpoints the write-ahead
+	// log, checks the integrity if selected and reports the database size and row counts.
+	Maintain(ctx context.Context, opts MaintenanceOptions) (*MaintenanceReport, error)
+}
+
+// StrategyStateRepository defines the interface for persisting strategy state across restarts.
+type StrategyStateRepository interface {
+	// SaveStrategyState stores (or replaces) the serialized state for a strategy and symbol.
+	SaveStrategyState(ctx context.Context, strategyName, symbol string, state []byte) error
+	// LoadStrategyState retrieves the serialized state for a strategy and symbol.
+	// Returns nil, nil if no state has been saved yet.
+	LoadStrategyState(ctx context.Context, strategyName, symbol string) ([]byte, error)
 }
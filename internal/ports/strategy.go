@@ -17,3 +17,62 @@ type Strategy interface {
 	// ShouldClosePosition implements the logic to decide if an open position should be closed.
 	ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason)
 }
+
+// EntryTagger is implemented by strategies that can describe their most recent entry
+// signal, so positions and trades can be tagged with why they were entered.
+type EntryTagger interface {
+	// LastEntryTag returns the tag of the last ShouldEnterTrade call that returned true.
+	LastEntryTag() domain.EntryTag
+}
+
+// ShortStrategy is implemented by strategies that can also signal short entries.
+// The trading service only opens short positions for strategies that implement it.
+type ShortStrategy interface {
+	// ShouldEnterShort implements the logic to decide if a short position should be entered.
+	ShouldEnterShort(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool
+}
+
+// ExitAwareStrategy is implemented by strategies whose entries depend on how the previous
+// position ended (e.g., re-entry rules per close reason).
+type ExitAwareStrategy interface {
+	// PositionClosed is called with each position after it has been closed.
+	PositionClosed(ctx context.Context, position *domain.Position)
+}
+
+// MultiTimeframeStrategy is implemented by strategies that analyze klines from several
+// intervals (e.g., a higher timeframe trend filter) in addition to the primary one.
+type MultiTimeframeStrategy interface {
+	// Timeframes returns the additional kline intervals the strategy needs (e.g., "15m", "1h").
+	Timeframes() []string
+
+	// SetTimeframeData provides the latest closed klines per interval, including the primary one.
+	// It is called before every ShouldEnterTrade / ShouldClosePosition evaluation.
+	SetTimeframeData(klines map[string][]*domain.Kline)
+}
+
+// OpenInterestStrategy is implemented by strategies that use the open interest history as a
+// signal (e.g., requiring open interest to rise alongside price).
+type OpenInterestStrategy interface {
+	// OpenInterestPeriod returns the period of the snapshots the strategy needs (e.g., "5m"),
+	// or "" if it doesn't currently use open interest.
+	OpenInterestPeriod() string
+
+	// SetOpenInterest provides the latest open interest snapshots, oldest first.
+	// It is called before ShouldEnterTrade evaluations whenever snapshots are available.
+	SetOpenInterest(snapshots []*domain.OpenInterest)
+}
+
+// StatefulStrategy is implemented by strategies whose internal risk state
+// (e.g., loss counters) should survive a restart.
+type StatefulStrategy interface {
+	Strategy
+
+	// Name identifies the strategy when its state is stored.
+	Name() string
+
+	// SaveState serializes the strategy's internal state.
+	SaveState(ctx context.Context) ([]byte, error)
+
+	// LoadState restores internal state previously produced by SaveState.
+	LoadState(ctx context.Context, data []byte) error
+}
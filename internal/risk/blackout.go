@@ -0,0 +1,169 @@
+package risk
+
+import (
+	"bytes"
+	"cryptoMegaBot/internal/domain"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BlackoutSchedule lists periods around news releases (e.g., CPI or FOMC) or recurring volatile
+// hours during which no new positions are opened and open positions' stops can be tightened.
+// It is usually loaded from a YAML file:
+//
+//	tighten_stop: 0.005   # Move stops to within 0.5% of the price during a blackout (0 keeps them)
+//	events:
+//	  - name: CPI
+//	    at: 2025-06-11T12:30:00Z
+//	    before: 30m
+//	    after: 1h
+//	recurring:
+//	  - name: Weekly open
+//	    days: [sun]         # Weekdays (mon..sun); empty means every day
+//	    time: "23:00"       # UTC start
+//	    duration: 2h
+type BlackoutSchedule struct {
+	TightenStop float64             `yaml:"tighten_stop"` // Maximum distance of the stop from the price during a blackout, as a fraction; 0 disables
+	Events      []BlackoutEvent     `yaml:"events"`
+	Recurring   []RecurringBlackout `yaml:"recurring"`
+}
+
+// BlackoutEvent is a one-off blackout from Before ahead of At until After it
+type BlackoutEvent struct {
+	Name   string        `yaml:"name"`
+	At     time.Time     `yaml:"at"`
+	Before time.Duration `yaml:"before"`
+	After  time.Duration `yaml:"after"`
+}
+
+// RecurringBlackout is a blackout repeating on the given UTC weekdays at the same time of day
+type RecurringBlackout struct {
+	Name     string        `yaml:"name"`
+	Days     []string      `yaml:"days"`
+	Time     string        `yaml:"time"`
+	Duration time.Duration `yaml:"duration"`
+
+	weekdays map[time.Weekday]bool // Parsed Days; nil means every day
+	start    time.Duration         // Parsed Time as an offset from midnight UTC
+}
+
+// parseWeekday accepts full or three-letter day names in any case
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// LoadBlackoutSchedule reads and validates a blackout schedule from a YAML file
+func LoadBlackoutSchedule(path string) (*BlackoutSchedule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blackout schedule: %w", err)
+	}
+	var schedule BlackoutSchedule
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse blackout schedule %s: %w", path, err)
+	}
+	if err := schedule.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid blackout schedule %s: %w", path, err)
+	}
+	return &schedule, nil
+}
+
+// Validate checks the schedule and parses the recurring windows' days and times. Schedules
+// built in code must be validated before use
+func (s *BlackoutSchedule) Validate() error {
+	if s.TightenStop < 0 || s.TightenStop >= 1 {
+		return fmt.Errorf("tighten_stop must be between 0 and 1")
+	}
+	for i, event := range s.Events {
+		if event.At.IsZero() {
+			return fmt.Errorf("event %d (%s): time is required", i+1, event.Name)
+		}
+		if event.Before < 0 || event.After < 0 || event.Before+event.After <= 0 {
+			return fmt.Errorf("event %d (%s): before and after must not be negative and span a positive window", i+1, event.Name)
+		}
+	}
+	for i := range s.Recurring {
+		window := &s.Recurring[i]
+		if window.Duration <= 0 || window.Duration > 24*time.Hour {
+			return fmt.Errorf("recurring window %d (%s): duration must be between 0 and 24h", i+1, window.Name)
+		}
+		t, err := time.Parse("15:04", strings.TrimSpace(window.Time))
+		if err != nil {
+			return fmt.Errorf("recurring window %d (%s): expected time as HH:MM, got %q", i+1, window.Name, window.Time)
+		}
+		window.start = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		window.weekdays = nil
+		for _, day := range window.Days {
+			weekday, ok := parseWeekday(day)
+			if !ok {
+				return fmt.Errorf("recurring window %d (%s): unknown day %q", i+1, window.Name, day)
+			}
+			if window.weekdays == nil {
+				window.weekdays = make(map[time.Weekday]bool)
+			}
+			window.weekdays[weekday] = true
+		}
+	}
+	return nil
+}
+
+// Active reports whether t falls in a blackout and, if so, the name of the window. A nil
+// schedule is never active
+func (s *BlackoutSchedule) Active(t time.Time) (bool, string) {
+	if s == nil {
+		return false, ""
+	}
+	for _, event := range s.Events {
+		if !t.Before(event.At.Add(-event.Before)) && t.Before(event.At.Add(event.After)) {
+			return true, event.Name
+		}
+	}
+	t = t.UTC()
+	today := t.Truncate(24 * time.Hour)
+	for _, window := range s.Recurring {
+		// A window that started yesterday may still be running
+		for _, day := range []time.Time{today, today.Add(-24 * time.Hour)} {
+			if window.weekdays != nil && !window.weekdays[day.Weekday()] {
+				continue
+			}
+			start := day.Add(window.start)
+			if !t.Before(start) && t.Before(start.Add(window.Duration)) {
+				return true, window.Name
+			}
+		}
+	}
+	return false, ""
+}
+
+// TightenedStop returns the stop for position during a blackout: no further than TightenStop
+// from price. Stops are only ever moved closer to the price; ok is false when the stop stays
+func (s *BlackoutSchedule) TightenedStop(position *domain.Position, price float64) (stop float64, ok bool) {
+	if s == nil || s.TightenStop <= 0 || price <= 0 {
+		return position.StopLoss, false
+	}
+	if position.IsShort() {
+		stop = price * (1 + s.TightenStop)
+		if position.StopLoss > 0 && stop >= position.StopLoss {
+			return position.StopLoss, false
+		}
+		return stop, true
+	}
+	stop = price * (1 - s.TightenStop)
+	if stop <= position.StopLoss {
+		return position.StopLoss, false
+	}
+	return stop, true
+}
@@ -0,0 +1,142 @@
+package risk
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadBlackoutSchedule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blackouts.yaml")
+	content := `tighten_stop: 0.005
+events:
+  - name: CPI
+    at: 2025-06-11T12:30:00Z
+    before: 30m
+    after: 1h
+recurring:
+  - name: Weekly open
+    days: [Sun, monday]
+    time: "23:00"
+    duration: 2h
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule, err := LoadBlackoutSchedule(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if schedule.TightenStop != 0.005 || len(schedule.Events) != 1 || len(schedule.Recurring) != 1 {
+		t.Fatalf("Unexpected schedule %+v", schedule)
+	}
+	if schedule.Events[0].Before != 30*time.Minute || schedule.Events[0].After != time.Hour {
+		t.Errorf("Unexpected event window %+v", schedule.Events[0])
+	}
+	window := schedule.Recurring[0]
+	if window.start != 23*time.Hour || !window.weekdays[time.Sunday] || !window.weekdays[time.Monday] || len(window.weekdays) != 2 {
+		t.Errorf("Unexpected recurring window %+v", window)
+	}
+}
+
+func TestLoadBlackoutScheduleInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":   "tighten: 0.01\n",
+		"missing time":    "events:\n  - name: FOMC\n    after: 1h\n",
+		"empty window":    "events:\n  - name: FOMC\n    at: 2025-06-18T18:00:00Z\n",
+		"bad time":        "recurring:\n  - name: Open\n    time: \"25:00\"\n    duration: 1h\n",
+		"bad day":         "recurring:\n  - name: Open\n    days: [funday]\n    time: \"00:00\"\n    duration: 1h\n",
+		"long duration":   "recurring:\n  - name: Open\n    time: \"00:00\"\n    duration: 25h\n",
+		"tighten too big": "tighten_stop: 1\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "blackouts.yaml")
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadBlackoutSchedule(path); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestBlackoutActive(t *testing.T) {
+	schedule := &BlackoutSchedule{
+		Events: []BlackoutEvent{
+			{Name: "CPI", At: time.Date(2025, 6, 11, 12, 30, 0, 0, time.UTC), Before: 30 * time.Minute, After: time.Hour},
+		},
+		Recurring: []RecurringBlackout{
+			// Sunday 23:00 until Monday 01:00 UTC
+			{Name: "Weekly open", Days: []string{"sun"}, Time: "23:00", Duration: 2 * time.Hour},
+			{Name: "Daily settlement", Time: "08:00", Duration: 10 * time.Minute},
+		},
+	}
+	if err := schedule.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		at     time.Time
+		active bool
+		name   string
+	}{
+		{time.Date(2025, 6, 11, 11, 59, 0, 0, time.UTC), false, ""},
+		{time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC), true, "CPI"},
+		{time.Date(2025, 6, 11, 13, 29, 0, 0, time.UTC), true, "CPI"},
+		{time.Date(2025, 6, 11, 13, 30, 0, 0, time.UTC), false, ""},
+		{time.Date(2025, 6, 15, 22, 59, 0, 0, time.UTC), false, ""}, // Sunday
+		{time.Date(2025, 6, 15, 23, 30, 0, 0, time.UTC), true, "Weekly open"},
+		{time.Date(2025, 6, 16, 0, 59, 0, 0, time.UTC), true, "Weekly open"}, // Monday, past midnight
+		{time.Date(2025, 6, 16, 1, 0, 0, 0, time.UTC), false, ""},
+		{time.Date(2025, 6, 16, 23, 30, 0, 0, time.UTC), false, ""}, // Monday is not listed
+		{time.Date(2025, 6, 12, 8, 5, 0, 0, time.UTC), true, "Daily settlement"},
+		{time.Date(2025, 6, 12, 8, 10, 0, 0, time.UTC), false, ""},
+		// Times in other zones are compared in UTC
+		{time.Date(2025, 6, 12, 10, 5, 0, 0, time.FixedZone("CEST", 2*3600)), true, "Daily settlement"},
+	}
+	for _, tt := range tests {
+		active, name := schedule.Active(tt.at)
+		if active != tt.active || name != tt.name {
+			t.Errorf("Active(%v) = %v %q, expected %v %q", tt.at, active, name, tt.active, tt.name)
+		}
+	}
+
+	var none *BlackoutSchedule
+	if active, _ := none.Active(time.Now()); active {
+		t.Error("Expected a nil schedule never to be active")
+	}
+}
+
+func TestBlackoutTightenedStop(t *testing.T) {
+	schedule := &BlackoutSchedule{TightenStop: 0.01}
+
+	long := &domain.Position{EntryPrice: 100, StopLoss: 95}
+	stop, ok := schedule.TightenedStop(long, 102)
+	if !ok || math.Abs(stop-100.98) > 1e-9 {
+		t.Errorf("Expected long stop tightened to 100.98, got %v %v", stop, ok)
+	}
+	long.StopLoss = 101.5
+	if stop, ok := schedule.TightenedStop(long, 102); ok || stop != 101.5 {
+		t.Errorf("Expected tighter long stop to stay, got %v %v", stop, ok)
+	}
+
+	short := &domain.Position{EntryPrice: 100, StopLoss: 105, Side: domain.PositionSideShort}
+	stop, ok = schedule.TightenedStop(short, 98)
+	if !ok || math.Abs(stop-98.98) > 1e-9 {
+		t.Errorf("Expected short stop tightened to 98.98, got %v %v", stop, ok)
+	}
+	short.StopLoss = 98.5
+	if stop, ok := schedule.TightenedStop(short, 98); ok || stop != 98.5 {
+		t.Errorf("Expected tighter short stop to stay, got %v %v", stop, ok)
+	}
+
+	if _, ok := (&BlackoutSchedule{}).TightenedStop(long, 102); ok {
+		t.Error("Expected no tightening when tighten_stop is 0")
+	}
+}
@@ -0,0 +1,146 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// weekdayNames are the three-letter day names a trading calendar is written with
+var weekdayNames = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// TradingCalendar scales position size (or disables entries) by UTC weekday and date, e.g. for
+// reduced size on thin weekends or no trading on holidays. Days it doesn't list trade at full size;
+// a factor of 0 disables new entries for the day. It's written as comma-separated day:factor
+// entries, where day is a three-letter weekday or a YYYY-MM-DD date, e.g. "SAT:0.5,SUN:0,2025-12-25:0".
+// A date overrides its weekday. A nil calendar trades every day at full size
+type TradingCalendar struct {
+	Weekdays map[time.Weekday]float64
+	Dates    map[string]float64 // Keyed by UTC date, YYYY-MM-DD
+}
+
+// ParseTradingCalendar parses a calendar such as "SAT:0.5,SUN:0,2025-12-25:0" (empty disables)
+func ParseTradingCalendar(spec string) (*TradingCalendar, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	calendar := &TradingCalendar{Weekdays: make(map[time.Weekday]float64), Dates: make(map[string]float64)}
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid calendar entry %q: expected day:factor", item)
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid factor in %q: %w", item, err)
+		}
+		if factor < 0 {
+			return nil, fmt.Errorf("factor %v in %q must not be negative", factor, item)
+		}
+		day := strings.ToUpper(strings.TrimSpace(parts[0]))
+		if weekday, ok := weekdayNames[day]; ok {
+			if _, dup := calendar.Weekdays[weekday]; dup {
+				return nil, fmt.Errorf("duplicate day in %q", item)
+			}
+			calendar.Weekdays[weekday] = factor
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			return nil, fmt.Errorf("invalid day in %q: expected MON..SUN or YYYY-MM-DD", item)
+		}
+		key := date.Format(time.DateOnly)
+		if _, dup := calendar.Dates[key]; dup {
+			return nil, fmt.Errorf("duplicate date in %q", item)
+		}
+		calendar.Dates[key] = factor
+	}
+	return calendar, nil
+}
+
+// String formats the calendar in the form ParseTradingCalendar reads, weekdays from Monday first,
+// then dates in order
+func (c *TradingCalendar) String() string {
+	if c == nil {
+		return ""
+	}
+	var entries []string
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+		if factor, ok := c.Weekdays[weekday]; ok {
+			entries = append(entries, strings.ToUpper(weekday.String()[:3])+":"+strconv.FormatFloat(factor, 'f', -1, 64))
+		}
+	}
+	dates := make([]string, 0, len(c.Dates))
+	for date := range c.Dates {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		entries = append(entries, date+":"+strconv.FormatFloat(c.Dates[date], 'f', -1, 64))
+	}
+	return strings.Join(entries, ",")
+}
+
+// MarshalText implements encoding.TextMarshaler, so calendars serialize in their string form
+func (c *TradingCalendar) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (c *TradingCalendar) UnmarshalText(text []byte) error {
+	calendar, err := ParseTradingCalendar(string(text))
+	if err != nil {
+		return err
+	}
+	if calendar == nil {
+		calendar = &TradingCalendar{}
+	}
+	*c = *calendar
+	return nil
+}
+
+// Factor returns the position size multiplier on the UTC day of t: the date's if listed, else the
+// weekday's, else 1
+func (c *TradingCalendar) Factor(t time.Time) float64 {
+	if c == nil {
+		return 1
+	}
+	t = t.UTC()
+	if factor, ok := c.Dates[t.Format(time.DateOnly)]; ok {
+		return factor
+	}
+	if factor, ok := c.Weekdays[t.Weekday()]; ok {
+		return factor
+	}
+	return 1
+}
+
+// Closed reports whether the calendar disables new entries on the UTC day of t and, if so, why
+func (c *TradingCalendar) Closed(t time.Time) (bool, string) {
+	if c.Factor(t) > 0 {
+		return false, ""
+	}
+	t = t.UTC()
+	if _, ok := c.Dates[t.Format(time.DateOnly)]; ok {
+		return true, "no entries on " + t.Format(time.DateOnly)
+	}
+	return true, "no entries on " + t.Weekday().String()
+}
+
+// Apply scales quantity by the factor of the UTC day of t
+func (c *TradingCalendar) Apply(quantity float64, t time.Time) float64 {
+	return quantity * c.Factor(t)
+}
@@ -0,0 +1,87 @@
+package risk
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTradingCalendar(t *testing.T) {
+	calendar, err := ParseTradingCalendar(" sun:0, SAT:0.5,2025-12-25:0 ,fri:0.75")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := calendar.String(); got != "FRI:0.75,SAT:0.5,SUN:0,2025-12-25:0" {
+		t.Errorf("Unexpected string form %q", got)
+	}
+
+	if calendar, err := ParseTradingCalendar(""); err != nil || calendar != nil {
+		t.Errorf("Expected an empty calendar to disable it, got %v, %v", calendar, err)
+	}
+	for _, spec := range []string{"SAT", "SAT:-1", "SAT:abc", "XYZ:0", "2025-13-01:0", "SAT:0.5,sat:0", "2025-12-25:0,2025-12-25:1"} {
+		if _, err := ParseTradingCalendar(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestTradingCalendarFactor(t *testing.T) {
+	calendar, err := ParseTradingCalendar("SAT:0.5,SUN:0,2025-12-25:0,2025-12-28:1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tests := []struct {
+		name   string
+		time   time.Time
+		factor float64
+		closed string
+	}{
+		{"weekday not listed", time.Date(2025, 12, 24, 12, 0, 0, 0, time.UTC), 1, ""},
+		{"reduced weekday", time.Date(2025, 12, 27, 12, 0, 0, 0, time.UTC), 0.5, ""},
+		{"disabled weekday", time.Date(2025, 12, 21, 12, 0, 0, 0, time.UTC), 0, "no entries on Sunday"},
+		{"holiday", time.Date(2025, 12, 25, 23, 59, 0, 0, time.UTC), 0, "no entries on 2025-12-25"},
+		{"date overrides its weekday", time.Date(2025, 12, 28, 0, 0, 0, 0, time.UTC), 1, ""},
+		{"UTC day of another zone", time.Date(2025, 12, 26, 1, 0, 0, 0, time.FixedZone("UTC+3", 3*3600)), 0, "no entries on 2025-12-25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calendar.Factor(tt.time); got != tt.factor {
+				t.Errorf("Expected factor %v, got %v", tt.factor, got)
+			}
+			closed, reason := calendar.Closed(tt.time)
+			if closed != (tt.closed != "") || reason != tt.closed {
+				t.Errorf("Expected closed reason %q, got %v %q", tt.closed, closed, reason)
+			}
+			if got := calendar.Apply(2, tt.time); got != 2*tt.factor {
+				t.Errorf("Expected quantity %v, got %v", 2*tt.factor, got)
+			}
+		})
+	}
+
+	var disabled *TradingCalendar
+	if disabled.Factor(time.Now()) != 1 || disabled.Apply(2, time.Now()) != 2 {
+		t.Error("Expected a nil calendar to leave sizes unchanged")
+	}
+	if closed, _ := disabled.Closed(time.Now()); closed {
+		t.Error("Expected a nil calendar to allow entries")
+	}
+}
+
+func TestTradingCalendarText(t *testing.T) {
+	var settings struct {
+		Calendar *TradingCalendar `json:"calendar"`
+	}
+	if err := json.Unmarshal([]byte(`{"calendar":"sun:0,SAT:0.5"}`), &settings); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != `{"calendar":"SAT:0.5,SUN:0"}` {
+		t.Errorf("Unexpected round trip %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"calendar":"SAT"}`), &settings); err == nil {
+		t.Error("Expected an error for an invalid calendar")
+	}
+}
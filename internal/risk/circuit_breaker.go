@@ -0,0 +1,135 @@
+package risk
+
+import (
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "CLOSED"    // Orders are placed normally
+	BreakerOpen     BreakerState = "OPEN"      // New orders are refused until the open period ends
+	BreakerHalfOpen BreakerState = "HALF_OPEN" // The next order is a probe: success closes the breaker, failure reopens it
+)
+
+// CircuitBreakerConfig holds configuration for the order circuit breaker
+type CircuitBreakerConfig struct {
+	MaxFailures int           // Consecutive order failures within Window that open the breaker
+	Window      time.Duration // Window the consecutive failures must fall in
+	OpenFor     time.Duration // How long the breaker stays open before a probe order is allowed
+}
+
+// CircuitBreaker stops new orders after repeated exchange failures or rate-limit errors, and
+// lets a probe order through once the open period ends
+type CircuitBreaker struct {
+	mu     sync.Mutex
+	config CircuitBreakerConfig
+
+	state     BreakerState
+	failures  []time.Time // Consecutive failures within the window, oldest first
+	lastError string
+	openedAt  time.Time
+	trips     int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(config CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if config.MaxFailures <= 0 {
+		return nil, fmt.Errorf("circuit breaker max failures must be positive")
+	}
+	if config.Window <= 0 || config.OpenFor <= 0 {
+		return nil, fmt.Errorf("circuit breaker window and open period must be positive")
+	}
+	return &CircuitBreaker{config: config, state: BreakerClosed}, nil
+}
+
+// Allow reports whether new orders may be placed and, if not, why. Once the open period has
+// passed the breaker becomes half-open and allows the probe
+func (b *CircuitBreaker) Allow(now time.Time) (bool, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		if now.Before(b.openedAt.Add(b.config.OpenFor)) {
+			return false, fmt.Sprintf("%d consecutive order failures, last: %s", len(b.failures), b.lastError)
+		}
+		b.state = BreakerHalfOpen
+	}
+	return true, ""
+}
+
+// Record records the result of an order placement and returns the state before and after it.
+// Rejections that don't indicate an exchange problem (e.g. insufficient funds) count as neither
+// failure nor success
+func (b *CircuitBreaker) Record(err error, now time.Time) (from, to BreakerState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	from = b.state
+	switch {
+	case err == nil && b.state == BreakerOpen:
+		// Exits are still placed while open; only the probe closes the breaker
+	case err == nil:
+		b.state = BreakerClosed
+		b.failures = nil
+	case !IsExchangeFailure(err):
+	case b.state == BreakerOpen:
+		b.lastError = err.Error()
+	case b.state == BreakerHalfOpen:
+		// The probe failed
+		b.lastError = err.Error()
+		b.open(now)
+	default:
+		b.lastError = err.Error()
+		b.failures = append(b.failures, now)
+		cutoff := now.Add(-b.config.Window)
+		for len(b.failures) > 0 && !b.failures[0].After(cutoff) {
+			b.failures = b.failures[1:]
+		}
+		if b.state == BreakerClosed && len(b.failures) >= b.config.MaxFailures {
+			b.open(now)
+		}
+	}
+	return from, b.state
+}
+
+// open opens the breaker at now. Assumes the caller holds the lock
+func (b *CircuitBreaker) open(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.trips++
+}
+
+// Status returns a snapshot of the circuit breaker state
+func (b *CircuitBreaker) Status(now time.Time) ports.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := ports.CircuitBreakerStatus{
+		State:               string(b.state),
+		ConsecutiveFailures: len(b.failures),
+		MaxFailures:         b.config.MaxFailures,
+		LastError:           b.lastError,
+		Trips:               b.trips,
+	}
+	if b.state != BreakerClosed {
+		status.OpenedAt = b.openedAt
+		status.ProbeAt = b.openedAt.Add(b.config.OpenFor)
+	}
+	return status
+}
+
+// IsExchangeFailure reports whether an order error points at the exchange (unavailable, rate
+// limited, timing out, rejecting the keys, ...) rather than at the order itself: exchange
+// rejections and permanent errors (canceled, not found) don't count
+func IsExchangeFailure(err error) bool {
+	switch ports.Category(err) {
+	case ports.CategoryExchangeRejection, ports.CategoryPermanent:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package risk
+
+import (
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNewCircuitBreaker(t *testing.T) {
+	if _, err := NewCircuitBreaker(CircuitBreakerConfig{MaxFailures: 0, Window: time.Minute, OpenFor: time.Minute}); err == nil {
+		t.Error("Expected error for zero max failures")
+	}
+	if _, err := NewCircuitBreaker(CircuitBreakerConfig{MaxFailures: 3, OpenFor: time.Minute}); err == nil {
+		t.Error("Expected error for zero window")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	newBreaker := func(t *testing.T) *CircuitBreaker {
+		b, err := NewCircuitBreaker(CircuitBreakerConfig{MaxFailures: 3, Window: time.Minute, OpenFor: 5 * time.Minute})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return b
+	}
+	rateLimited := fmt.Errorf("place order: %w", ports.ErrRateLimited)
+
+	t.Run("opens after consecutive failures and closes after a probe", func(t *testing.T) {
+		b := newBreaker(t)
+		b.Record(rateLimited, now)
+		b.Record(rateLimited, now.Add(10*time.Second))
+		if from, to := b.Record(rateLimited, now.Add(20*time.Second)); from != BreakerClosed || to != BreakerOpen {
+			t.Fatalf("Expected CLOSED -> OPEN, got %s -> %s", from, to)
+		}
+		if ok, reason := b.Allow(now.Add(time.Minute)); ok || reason == "" {
+			t.Errorf("Expected orders refused with a reason, got %t %q", ok, reason)
+		}
+		status := b.Status(now.Add(time.Minute))
+		if status.State != "OPEN" || status.Trips != 1 || !status.ProbeAt.Equal(now.Add(20*time.Second+5*time.Minute)) {
+			t.Errorf("Unexpected status %+v", status)
+		}
+
+		// A failed probe keeps it open for another period
+		if ok, _ := b.Allow(now.Add(6 * time.Minute)); !ok {
+			t.Fatal("Expected the probe to be allowed")
+		}
+		if _, to := b.Record(rateLimited, now.Add(6*time.Minute)); to != BreakerOpen {
+			t.Fatalf("Expected OPEN after a failed probe, got %s", to)
+		}
+		if ok, _ := b.Allow(now.Add(7 * time.Minute)); ok {
+			t.Error("Expected orders refused after a failed probe")
+		}
+
+		if ok, _ := b.Allow(now.Add(12 * time.Minute)); !ok {
+			t.Fatal("Expected the probe to be allowed")
+		}
+		if from, to := b.Record(nil, now.Add(12*time.Minute)); from != BreakerHalfOpen || to != BreakerClosed {
+			t.Errorf("Expected HALF_OPEN -> CLOSED, got %s -> %s", from, to)
+		}
+		if status := b.Status(now.Add(12 * time.Minute)); status.ConsecutiveFailures != 0 || status.Trips != 2 {
+			t.Errorf("Unexpected status %+v", status)
+		}
+	})
+
+	t.Run("failures must be consecutive and within the window", func(t *testing.T) {
+		b := newBreaker(t)
+		b.Record(rateLimited, now)
+		b.Record(rateLimited, now.Add(10*time.Second))
+		b.Record(nil, now.Add(20*time.Second))
+		b.Record(rateLimited, now.Add(30*time.Second))
+		b.Record(rateLimited, now.Add(2*time.Minute))
+		if _, to := b.Record(rateLimited, now.Add(3*time.Minute)); to != BreakerClosed {
+			t.Errorf("Expected CLOSED, got %s", to)
+		}
+	})
+
+	t.Run("order rejections don't count", func(t *testing.T) {
+		b := newBreaker(t)
+		for i := 0; i < 5; i++ {
+			b.Record(fmt.Errorf("place order: %w", ports.ErrInsufficientFunds), now)
+		}
+		if status := b.Status(now); status.State != "CLOSED" || status.ConsecutiveFailures != 0 {
+			t.Errorf("Unexpected status %+v", status)
+		}
+	})
+}
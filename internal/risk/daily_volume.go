@@ -0,0 +1,90 @@
+package risk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DailyVolumeConfig holds configuration for the daily trading volume caps
+type DailyVolumeConfig struct {
+	MaxNotional float64 // Quote notional of entries allowed per UTC day (e.g., 50000 USDT); 0 disables
+	MaxVolume   float64 // Base asset quantity of entries allowed per UTC day (e.g., 20 ETH); 0 disables
+}
+
+// DailyVolumeCap tracks the notional and quantity of the entries filled during the current UTC day
+// and refuses new entries that would take either total past its cap. Totals reset at UTC midnight.
+type DailyVolumeCap struct {
+	mu     sync.Mutex
+	config DailyVolumeConfig
+
+	day      time.Time // Start of the day currently being tracked (UTC)
+	notional float64
+	volume   float64
+}
+
+// NewDailyVolumeCap creates a new daily volume cap instance
+func NewDailyVolumeCap(config DailyVolumeConfig) *DailyVolumeCap {
+	return &DailyVolumeCap{config: config}
+}
+
+// Restore sets the totals of the day containing now, e.g. from the values persisted before a restart
+func (c *DailyVolumeCap) Restore(now time.Time, notional, volume float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.day = now.UTC().Truncate(24 * time.Hour)
+	c.notional = notional
+	c.volume = volume
+}
+
+// Record adds a filled entry to the totals of the day containing now
+func (c *DailyVolumeCap) Record(now time.Time, notional, volume float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollOver(now)
+	c.notional += notional
+	c.volume += volume
+}
+
+// Reached reports whether either cap is already used up today and why
+func (c *DailyVolumeCap) Reached(now time.Time) (bool, string) {
+	ok, reason := c.Allow(now, 0, 0)
+	return !ok, reason
+}
+
+// Allow reports whether an entry of the given notional and quantity fits within today's caps.
+// Once a cap is used up, every entry is refused until the next UTC day.
+func (c *DailyVolumeCap) Allow(now time.Time, notional, volume float64) (bool, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollOver(now)
+	if limit := c.config.MaxNotional; limit > 0 && (c.notional >= limit || c.notional+notional > limit) {
+		return false, fmt.Sprintf("daily notional cap reached (%.2f traded + %.2f > %.2f)", c.notional, notional, limit)
+	}
+	if limit := c.config.MaxVolume; limit > 0 && (c.volume >= limit || c.volume+volume > limit) {
+		return false, fmt.Sprintf("daily volume cap reached (%g traded + %g > %g)", c.volume, volume, limit)
+	}
+	return true, ""
+}
+
+// Usage returns the notional and quantity traded so far on the day containing now
+func (c *DailyVolumeCap) Usage(now time.Time) (notional, volume float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rollOver(now)
+	return c.notional, c.volume
+}
+
+// rollOver resets the totals when now falls on a later UTC day. Assumes the lock is held.
+func (c *DailyVolumeCap) rollOver(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if day.After(c.day) {
+		c.day = day
+		c.notional = 0
+		c.volume = 0
+	}
+}
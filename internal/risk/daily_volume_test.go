@@ -0,0 +1,52 @@
+package risk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyVolumeCapNotional(t *testing.T) {
+	c := NewDailyVolumeCap(DailyVolumeConfig{MaxNotional: 10000})
+	day := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	if ok, _ := c.Allow(day, 6000, 3); !ok {
+		t.Fatal("Expected first entry within the cap to be allowed")
+	}
+	c.Record(day, 6000, 3)
+
+	if ok, reason := c.Allow(day.Add(time.Hour), 6000, 3); ok || reason == "" {
+		t.Errorf("Expected entry taking the total past the cap to be refused with a reason, got %v %q", ok, reason)
+	}
+	if ok, _ := c.Allow(day.Add(time.Hour), 4000, 2); !ok {
+		t.Error("Expected entry filling the cap exactly to be allowed")
+	}
+	c.Record(day.Add(time.Hour), 4000, 2)
+	if reached, _ := c.Reached(day.Add(2 * time.Hour)); !reached {
+		t.Error("Expected cap to be reached")
+	}
+
+	// UTC midnight resets the totals
+	next := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)
+	if reached, _ := c.Reached(next); reached {
+		t.Error("Expected cap to reset at UTC midnight")
+	}
+	if notional, volume := c.Usage(next); notional != 0 || volume != 0 {
+		t.Errorf("Expected zero usage on the new day, got %v %v", notional, volume)
+	}
+}
+
+func TestDailyVolumeCapVolumeAndRestore(t *testing.T) {
+	c := NewDailyVolumeCap(DailyVolumeConfig{MaxVolume: 5})
+	day := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	c.Restore(day, 9000, 4.5)
+	if ok, _ := c.Allow(day, 2000, 1); ok {
+		t.Error("Expected restored volume to count against the cap")
+	}
+	if ok, _ := c.Allow(day, 1000, 0.5); !ok {
+		t.Error("Expected notional to be unlimited without MaxNotional")
+	}
+	if notional, volume := c.Usage(day); notional != 9000 || volume != 4.5 {
+		t.Errorf("Unexpected usage %v %v", notional, volume)
+	}
+}
@@ -0,0 +1,150 @@
+package risk
+
+import (
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EquityTrailConfig holds configuration for the account equity trail
+type EquityTrailConfig struct {
+	Target   float64 // Daily profit, as a fraction of the day's starting equity, that arms the trail (e.g., 0.02 for 2%)
+	Giveback float64 // Share of the day's peak profit given back before locking in (e.g., 0.5 keeps half of it)
+}
+
+// Validate checks the configuration
+func (c EquityTrailConfig) Validate() error {
+	if c.Target <= 0 {
+		return fmt.Errorf("equity trail target must be positive, got %v", c.Target)
+	}
+	if c.Giveback < 0 || c.Giveback > 1 {
+		return fmt.Errorf("equity trail giveback must be between 0 and 1, got %v", c.Giveback)
+	}
+	return nil
+}
+
+// EquityTrail trails the day's realized+unrealized profit: once it reaches the target, a floor is
+// set at the peak profit less the giveback and raised with every new peak. When equity falls back
+// to the floor, the trail locks in the day: the positions are meant to be flattened and no new
+// entries made until the next UTC day
+type EquityTrail struct {
+	mu     sync.Mutex
+	config EquityTrailConfig
+
+	day            time.Time // Start of the day being trailed (UTC)
+	dayStartEquity float64
+	equity         float64
+	peakProfit     float64
+	armed          bool
+	floor          float64
+
+	locked   bool
+	lockedAt time.Time
+	reason   string
+}
+
+// NewEquityTrail creates an equity trail
+func NewEquityTrail(config EquityTrailConfig) (*EquityTrail, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &EquityTrail{config: config}, nil
+}
+
+// Update records the latest equity, starting a new day at the first update after UTC midnight, and
+// raises the floor or locks in the day. Returns true only when this update locked it in.
+func (t *EquityTrail) Update(equity float64, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollOver(now, equity)
+	t.equity = equity
+	if t.locked {
+		return false
+	}
+
+	profit := equity - t.dayStartEquity
+	if !t.armed && t.dayStartEquity > 0 && profit >= t.dayStartEquity*t.config.Target {
+		t.armed = true
+	}
+	if !t.armed {
+		return false
+	}
+	if profit > t.peakProfit {
+		t.peakProfit = profit
+		t.floor = t.dayStartEquity + profit*(1-t.config.Giveback)
+		return false
+	}
+	if equity <= t.floor {
+		t.locked = true
+		t.lockedAt = now
+		t.reason = fmt.Sprintf("equity %.2f fell back to the floor %.2f after a daily profit peak of %.2f", equity, t.floor, t.peakProfit)
+		return true
+	}
+	return false
+}
+
+// Locked reports whether the day is locked in and why
+func (t *EquityTrail) Locked(now time.Time) (bool, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollOver(now, t.equity)
+	return t.locked, t.reason
+}
+
+// Resume unlocks the day, restarting the trail from the current equity
+func (t *EquityTrail) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.startDay(t.day, t.equity)
+}
+
+// Status returns a snapshot of the equity trail state
+func (t *EquityTrail) Status(now time.Time) ports.EquityTrailStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollOver(now, t.equity)
+	status := ports.EquityTrailStatus{
+		Armed:          t.armed,
+		Locked:         t.locked,
+		Reason:         t.reason,
+		DayStartEquity: t.dayStartEquity,
+		CurrentEquity:  t.equity,
+		DailyProfit:    t.equity - t.dayStartEquity,
+		PeakProfit:     t.peakProfit,
+		Target:         t.dayStartEquity * t.config.Target,
+	}
+	if t.armed {
+		status.Floor = t.floor
+	}
+	if t.locked {
+		status.LockedAt = t.lockedAt
+	}
+	return status
+}
+
+// rollOver starts a new day from equity on the first call after UTC midnight (or the first call
+// at all). Assumes the lock is held.
+func (t *EquityTrail) rollOver(now time.Time, equity float64) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if t.day.IsZero() || day.After(t.day) {
+		t.startDay(day, equity)
+	}
+}
+
+// startDay resets the trail for day, starting from equity. Assumes the lock is held.
+func (t *EquityTrail) startDay(day time.Time, equity float64) {
+	t.day = day
+	t.dayStartEquity = equity
+	t.equity = equity
+	t.peakProfit = 0
+	t.armed = false
+	t.floor = 0
+	t.locked = false
+	t.lockedAt = time.Time{}
+	t.reason = ""
+}
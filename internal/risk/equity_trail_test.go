@@ -0,0 +1,75 @@
+package risk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEquityTrail(t *testing.T) {
+	trail, err := NewEquityTrail(EquityTrailConfig{Target: 0.02, Giveback: 0.5})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	start := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	trail.Update(1000, start)
+	if trail.Update(1015, start.Add(time.Minute)) || trail.Status(start).Armed {
+		t.Error("Expected the trail not to arm below the target")
+	}
+	if trail.Update(1005, start.Add(2*time.Minute)) {
+		t.Error("Expected no lock before the trail armed")
+	}
+	trail.Update(1020, start.Add(3*time.Minute)) // Target reached: floor at 1010
+	trail.Update(1040, start.Add(4*time.Minute)) // New peak: floor raised to 1020
+	status := trail.Status(start.Add(4 * time.Minute))
+	if !status.Armed || status.Floor != 1020 || status.PeakProfit != 40 {
+		t.Errorf("Unexpected status after the peak: %+v", status)
+	}
+	if trail.Update(1030, start.Add(5*time.Minute)) {
+		t.Error("Expected no lock above the floor")
+	}
+	if trail.Status(start).Floor != 1020 {
+		t.Error("Expected the floor to stay at the peak's level on a pullback")
+	}
+	if !trail.Update(1019, start.Add(6*time.Minute)) {
+		t.Error("Expected the trail to lock in at the floor")
+	}
+	if locked, reason := trail.Locked(start.Add(7 * time.Minute)); !locked || reason == "" {
+		t.Errorf("Expected the day to be locked with a reason, got %v %q", locked, reason)
+	}
+	if trail.Update(1050, start.Add(8*time.Minute)) {
+		t.Error("Expected a locked day to stay locked")
+	}
+
+	// The next UTC day starts over from the latest equity
+	nextDay := start.Add(16 * time.Hour)
+	if locked, _ := trail.Locked(nextDay); locked {
+		t.Error("Expected the lock to end at midnight UTC")
+	}
+	if status := trail.Status(nextDay); status.DayStartEquity != 1050 || status.Armed {
+		t.Errorf("Expected a fresh day from 1050, got %+v", status)
+	}
+}
+
+func TestEquityTrailResumeAndValidate(t *testing.T) {
+	trail, _ := NewEquityTrail(EquityTrailConfig{Target: 0.01})
+	now := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	trail.Update(1000, now)
+	trail.Update(1020, now) // Giveback 0: the floor is the peak itself
+	if !trail.Update(1019, now) {
+		t.Fatal("Expected any pullback from the peak to lock in without a giveback")
+	}
+	trail.Resume()
+	if locked, _ := trail.Locked(now); locked {
+		t.Error("Expected Resume to unlock the day")
+	}
+	if status := trail.Status(now); status.DayStartEquity != 1019 || status.Armed {
+		t.Errorf("Expected the trail to restart from the current equity, got %+v", status)
+	}
+
+	for _, config := range []EquityTrailConfig{{}, {Target: 0.02, Giveback: 1.5}, {Target: 0.02, Giveback: -0.1}} {
+		if _, err := NewEquityTrail(config); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package risk
+
+import (
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// KillSwitchConfig holds configuration for the equity-curve kill switch
+type KillSwitchConfig struct {
+	MaxDrawdown   float64       // Drawdown from peak equity that pauses entries (e.g., 0.1 for 10%); 0 disables
+	MaxLosingDays int           // Consecutive losing days that pause entries; 0 disables
+	CoolDown      time.Duration // How long entries stay paused before resuming automatically
+}
+
+// KillSwitch monitors the realized+unrealized equity curve and pauses new entries
+// when drawdown or a losing-day streak exceeds the configured limits
+type KillSwitch struct {
+	mu     sync.Mutex
+	config KillSwitchConfig
+
+	peakEquity     float64
+	currentEquity  float64
+	day            time.Time // Start of the day currently being tracked (UTC)
+	dayStartEquity float64
+	losingDays     int
+
+	tripped   bool
+	reason    string
+	trippedAt time.Time
+}
+
+// NewKillSwitch creates a new kill switch instance
+func NewKillSwitch(config KillSwitchConfig) *KillSwitch {
+	return &KillSwitch{config: config}
+}
+
+// Update records the latest equity and trips the switch if a limit is breached.
+// Returns true only when this update tripped the switch.
+func (k *KillSwitch) Update(equity float64, now time.Time) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.resumeIfCooledDown(now)
+
+	day := now.UTC().Truncate(24 * time.Hour)
+	if k.day.IsZero() {
+		// First observation
+		k.day = day
+		k.dayStartEquity = equity
+		k.peakEquity = equity
+	} else if day.After(k.day) {
+		// Day rolled over: the last equity seen closes the previous day
+		if k.currentEquity < k.dayStartEquity {
+			k.losingDays++
+		} else {
+			k.losingDays = 0
+		}
+		k.day = day
+		k.dayStartEquity = k.currentEquity
+	}
+
+	k.currentEquity = equity
+	if equity > k.peakEquity {
+		k.peakEquity = equity
+	}
+
+	if k.tripped {
+		return false
+	}
+
+	if k.config.MaxDrawdown > 0 && k.drawdown() >= k.config.MaxDrawdown {
+		k.trip(fmt.Sprintf("equity drawdown %.2f%% exceeds limit %.2f%%", k.drawdown()*100, k.config.MaxDrawdown*100), now)
+		return true
+	}
+	if k.config.MaxLosingDays > 0 && k.losingDays >= k.config.MaxLosingDays {
+		k.trip(fmt.Sprintf("%d consecutive losing days (limit %d)", k.losingDays, k.config.MaxLosingDays), now)
+		return true
+	}
+	return false
+}
+
+// IsTripped reports whether new entries are currently paused and why
+func (k *KillSwitch) IsTripped(now time.Time) (bool, string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.resumeIfCooledDown(now)
+	return k.tripped, k.reason
+}
+
+// Resume re-enables entries immediately, restarting drawdown and losing-day tracking from the current equity
+func (k *KillSwitch) Resume() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.reset()
+}
+
+// Status returns a snapshot of the kill switch state
+func (k *KillSwitch) Status(now time.Time) ports.KillSwitchStatus {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.resumeIfCooledDown(now)
+	status := ports.KillSwitchStatus{
+		Tripped:       k.tripped,
+		Reason:        k.reason,
+		PeakEquity:    k.peakEquity,
+		CurrentEquity: k.currentEquity,
+		Drawdown:      k.drawdown(),
+		LosingDays:    k.losingDays,
+	}
+	if k.tripped {
+		status.TrippedAt = k.trippedAt
+		status.ResumeAt = k.trippedAt.Add(k.config.CoolDown)
+	}
+	return status
+}
+
+// drawdown returns the current drawdown from peak equity. Assumes the lock is held.
+func (k *KillSwitch) drawdown() float64 {
+	if k.peakEquity <= 0 {
+		return 0
+	}
+	return (k.peakEquity - k.currentEquity) / k.peakEquity
+}
+
+// trip pauses entries. Assumes the lock is held.
+func (k *KillSwitch) trip(reason string, now time.Time) {
+	k.tripped = true
+	k.reason = reason
+	k.trippedAt = now
+}
+
+// resumeIfCooledDown resumes entries once the cool-down has elapsed. Assumes the lock is held.
+func (k *KillSwitch) resumeIfCooledDown(now time.Time) {
+	if k.tripped && !now.Before(k.trippedAt.Add(k.config.CoolDown)) {
+		k.reset()
+	}
+}
+
+// reset clears the tripped state and restarts tracking from the current equity. Assumes the lock is held.
+func (k *KillSwitch) reset() {
+	k.tripped = false
+	k.reason = ""
+	k.trippedAt = time.Time{}
+	k.peakEquity = k.currentEquity
+	k.dayStartEquity = k.currentEquity
+	k.losingDays = 0
+}
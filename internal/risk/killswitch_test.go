@@ -0,0 +1,85 @@
+package risk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKillSwitchDrawdown(t *testing.T) {
+	ks := NewKillSwitch(KillSwitchConfig{
+		MaxDrawdown: 0.1,
+		CoolDown:    time.Hour,
+	})
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	ks.Update(1000, start)
+	ks.Update(1200, start.Add(time.Minute)) // New peak
+	if ks.Update(1100, start.Add(2*time.Minute)) {
+		t.Error("Expected kill switch not to trip at 8.3% drawdown")
+	}
+	if !ks.Update(1080, start.Add(3*time.Minute)) {
+		t.Error("Expected kill switch to trip at 10% drawdown")
+	}
+
+	tripped, reason := ks.IsTripped(start.Add(30 * time.Minute))
+	if !tripped || reason == "" {
+		t.Errorf("Expected kill switch to be tripped with a reason, got %v %q", tripped, reason)
+	}
+
+	status := ks.Status(start.Add(30 * time.Minute))
+	if status.ResumeAt != start.Add(3*time.Minute).Add(time.Hour) {
+		t.Errorf("Unexpected resume time %v", status.ResumeAt)
+	}
+
+	// Cool-down elapsed: entries resume and the peak restarts from current equity
+	tripped, _ = ks.IsTripped(start.Add(2 * time.Hour))
+	if tripped {
+		t.Error("Expected kill switch to resume after cool-down")
+	}
+	if ks.Update(1075, start.Add(2*time.Hour)) {
+		t.Error("Expected kill switch not to re-trip after resuming from the new peak")
+	}
+}
+
+func TestKillSwitchLosingDays(t *testing.T) {
+	ks := NewKillSwitch(KillSwitchConfig{
+		MaxLosingDays: 2,
+		CoolDown:      24 * time.Hour,
+	})
+	day := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	ks.Update(1000, day)
+	ks.Update(990, day.Add(time.Hour)) // Day 1 ends lower
+	if ks.Update(990, day.Add(24*time.Hour)) {
+		t.Error("Expected kill switch not to trip after one losing day")
+	}
+	ks.Update(980, day.Add(25*time.Hour)) // Day 2 ends lower
+	if !ks.Update(980, day.Add(48*time.Hour)) {
+		t.Error("Expected kill switch to trip after two losing days")
+	}
+	if status := ks.Status(day.Add(48 * time.Hour)); status.LosingDays != 2 {
+		t.Errorf("Expected 2 losing days, got %d", status.LosingDays)
+	}
+}
+
+func TestKillSwitchManualResume(t *testing.T) {
+	ks := NewKillSwitch(KillSwitchConfig{
+		MaxDrawdown: 0.05,
+		CoolDown:    24 * time.Hour,
+	})
+	now := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	ks.Update(1000, now)
+	ks.Update(900, now.Add(time.Minute))
+	if tripped, _ := ks.IsTripped(now.Add(time.Minute)); !tripped {
+		t.Fatal("Expected kill switch to be tripped")
+	}
+
+	ks.Resume()
+	if tripped, _ := ks.IsTripped(now.Add(2 * time.Minute)); tripped {
+		t.Error("Expected kill switch to be cleared after manual resume")
+	}
+	if status := ks.Status(now.Add(2 * time.Minute)); status.PeakEquity != 900 {
+		t.Errorf("Expected peak equity to reset to 900, got %f", status.PeakEquity)
+	}
+}
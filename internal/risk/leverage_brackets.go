@@ -0,0 +1,34 @@
+package risk
+
+import (
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+)
+
+// BracketFor returns the leverage bracket a position with the given notional value falls in.
+// Returns false if brackets is empty or the notional exceeds the cap of the last bracket
+func BracketFor(brackets []ports.LeverageBracket, notional float64) (ports.LeverageBracket, bool) {
+	for _, b := range brackets {
+		if notional <= b.NotionalCap {
+			return b, true
+		}
+	}
+	return ports.LeverageBracket{}, false
+}
+
+// FitLeverage returns leverage, lowered to the maximum of the bracket a position with the given
+// notional value falls in, and that bracket. Returns an error if the notional exceeds every bracket
+func FitLeverage(brackets []ports.LeverageBracket, leverage int, notional float64) (int, ports.LeverageBracket, error) {
+	bracket, ok := BracketFor(brackets, notional)
+	if !ok {
+		if len(brackets) == 0 {
+			return 0, bracket, fmt.Errorf("no leverage brackets")
+		}
+		return 0, bracket, fmt.Errorf("notional %.2f exceeds the maximum of %.2f allowed by the leverage brackets",
+			notional, brackets[len(brackets)-1].NotionalCap)
+	}
+	if leverage > bracket.InitialLeverage {
+		leverage = bracket.InitialLeverage
+	}
+	return leverage, bracket, nil
+}
@@ -0,0 +1,45 @@
+package risk
+
+import (
+	"cryptoMegaBot/internal/ports"
+	"testing"
+)
+
+func TestFitLeverage(t *testing.T) {
+	brackets := []ports.LeverageBracket{
+		{Bracket: 1, InitialLeverage: 125, NotionalFloor: 0, NotionalCap: 50000},
+		{Bracket: 2, InitialLeverage: 100, NotionalFloor: 50000, NotionalCap: 250000},
+		{Bracket: 3, InitialLeverage: 20, NotionalFloor: 250000, NotionalCap: 1000000},
+	}
+	tests := []struct {
+		name        string
+		leverage    int
+		notional    float64
+		want        int
+		wantBracket int
+	}{
+		{"within the first bracket", 20, 1000, 20, 1},
+		{"bracket cap is inclusive", 120, 50000, 120, 1},
+		{"lowered in a higher bracket", 50, 300000, 20, 3},
+		{"below the bracket maximum", 10, 300000, 10, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, bracket, err := FitLeverage(brackets, tt.leverage, tt.notional)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want || bracket.Bracket != tt.wantBracket {
+				t.Errorf("FitLeverage(%d, %.0f) = %dx in bracket %d, want %dx in bracket %d",
+					tt.leverage, tt.notional, got, bracket.Bracket, tt.want, tt.wantBracket)
+			}
+		})
+	}
+
+	if _, _, err := FitLeverage(brackets, 10, 2000000); err == nil {
+		t.Error("Expected an error for a notional above the last bracket")
+	}
+	if _, _, err := FitLeverage(nil, 10, 100); err == nil {
+		t.Error("Expected an error without brackets")
+	}
+}
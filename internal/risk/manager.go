@@ -2,10 +2,14 @@ package risk
 
 import (
 	"context"
+	"cryptoMegaBot/internal/clock"
 	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"errors"
 	"fmt"
 	"math"
-	"time"
+	"strconv"
+	"strings"
 )
 
 // RiskConfig holds configuration for risk management
@@ -18,8 +22,40 @@ type RiskConfig struct {
 	PositionSizePercent float64
 	StopLossPercent     float64
 	TakeProfitPercent   float64
+
+	// DrawdownThrottle scales position size down as CurrentDrawdown grows.
+	// Nil disables throttling; see DefaultDrawdownThrottle.
+	DrawdownThrottle []ThrottlePoint
+
+	// MaxVolumeShare caps a position's notional at this fraction of the symbol's rolling 24h quote
+	// volume (e.g., 0.001 for 0.1%), so sizes stay within what the market can absorb. 0 disables
+	MaxVolumeShare float64
+
+	// MaxExposurePct caps the notional of all open positions, a new entry included, at this
+	// fraction of equity (e.g., 2 for 200%). 0 disables
+	MaxExposurePct float64
+
+	// Clock is the source of the current time (nil uses the system clock)
+	Clock ports.Clock
 }
 
+// ThrottlePoint maps a drawdown level to the fraction of normal position size allowed at that level
+type ThrottlePoint struct {
+	Drawdown float64 // Drawdown from peak equity (e.g., 0.1 for 10%)
+	Factor   float64 // Position size multiplier at this drawdown (e.g., 0.5 for half size)
+}
+
+// DefaultDrawdownThrottle allows full size below 5% drawdown, half size at 10% and a quarter from 15%
+var DefaultDrawdownThrottle = []ThrottlePoint{
+	{Drawdown: 0.05, Factor: 1.0},
+	{Drawdown: 0.10, Factor: 0.5},
+	{Drawdown: 0.15, Factor: 0.25},
+}
+
+// ErrMaxExposure is returned (wrapped) for entries that would take the open notional above the
+// maximum exposure
+var ErrMaxExposure = errors.New("maximum exposure exceeded")
+
 // RiskManager implements risk management functionality
 type RiskManager struct {
 	config RiskConfig
@@ -35,10 +71,15 @@ type RiskStats struct {
 	DailyTrades     int
 	MaxDailyTrades  int
 	LastResetTime   int64
+	PeakEquity      float64
+	QuoteVolume24h  float64 // Symbol's latest rolling 24h quote volume (0 until recorded)
 }
 
 // NewRiskManager creates a new risk manager instance
 func NewRiskManager(config RiskConfig) *RiskManager {
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
 	return &RiskManager{
 		config: config,
 		stats: &RiskStats{
@@ -59,6 +100,11 @@ func (r *RiskManager) ValidatePosition(ctx context.Context, position *domain.Pos
 		return fmt.Errorf("leverage %d exceeds maximum allowed %d", position.Leverage, r.config.MaxLeverage)
 	}
 
+	// Check the notional against the 24h volume
+	if limit := r.MaxNotional(); limit > 0 && position.Quantity*position.EntryPrice > limit {
+		return fmt.Errorf("position notional %f exceeds %f, %v of the 24h quote volume", position.Quantity*position.EntryPrice, limit, r.config.MaxVolumeShare)
+	}
+
 	// Check number of open positions
 	if r.stats.OpenPositions >= r.config.MaxOpenPositions {
 		return fmt.Errorf("number of open positions %d exceeds maximum allowed %d", r.stats.OpenPositions, r.config.MaxOpenPositions)
@@ -71,9 +117,8 @@ func (r *RiskManager) ValidatePosition(ctx context.Context, position *domain.Pos
 	}
 
 	// Check total exposure
-	newTotalExposure := r.stats.TotalExposure + positionValue
-	if newTotalExposure > accountBalance*float64(r.config.MaxOpenPositions) {
-		return fmt.Errorf("total exposure would exceed maximum allowed")
+	if err := r.CheckExposure(r.stats.TotalExposure, position.Quantity*position.EntryPrice, accountBalance); err != nil {
+		return err
 	}
 
 	return nil
@@ -92,10 +137,10 @@ func (r *RiskManager) UpdateStats(ctx context.Context, trade *domain.Trade, acco
 	// Update open positions count
 	if trade.CloseReason == "" {
 		r.stats.OpenPositions++
-		r.stats.TotalExposure += trade.Quantity * trade.EntryPrice * float64(trade.Leverage)
+		r.stats.TotalExposure += trade.Quantity * trade.EntryPrice
 	} else {
 		r.stats.OpenPositions--
-		r.stats.TotalExposure -= trade.Quantity * trade.EntryPrice * float64(trade.Leverage)
+		r.stats.TotalExposure -= trade.Quantity * trade.EntryPrice
 	}
 
 	// Update daily trades count
@@ -106,7 +151,100 @@ func (r *RiskManager) UpdateStats(ctx context.Context, trade *domain.Trade, acco
 func (r *RiskManager) ResetDailyStats(ctx context.Context) {
 	r.stats.DailyPnL = 0
 	r.stats.DailyTrades = 0
-	r.stats.LastResetTime = time.Now().Unix()
+	r.stats.LastResetTime = r.config.Clock.Now().Unix()
+}
+
+// UpdateEquity records the latest account equity and recalculates CurrentDrawdown from the peak
+func (r *RiskManager) UpdateEquity(ctx context.Context, equity float64) {
+	if equity > r.stats.PeakEquity {
+		r.stats.PeakEquity = equity
+	}
+	if r.stats.PeakEquity > 0 {
+		r.stats.CurrentDrawdown = (r.stats.PeakEquity - equity) / r.stats.PeakEquity
+	}
+}
+
+// ThrottleFactor returns the position size multiplier for the current drawdown, interpolating linearly between curve points
+func (r *RiskManager) ThrottleFactor() float64 {
+	curve := r.config.DrawdownThrottle
+	if len(curve) == 0 {
+		return 1.0
+	}
+
+	dd := r.stats.CurrentDrawdown
+	if dd <= curve[0].Drawdown {
+		return curve[0].Factor
+	}
+	for i := 1; i < len(curve); i++ {
+		if dd <= curve[i].Drawdown {
+			prev, next := curve[i-1], curve[i]
+			ratio := (dd - prev.Drawdown) / (next.Drawdown - prev.Drawdown)
+			return prev.Factor + ratio*(next.Factor-prev.Factor)
+		}
+	}
+	return curve[len(curve)-1].Factor
+}
+
+// ApplyThrottle scales a position size by the current drawdown throttle
+func (r *RiskManager) ApplyThrottle(positionSize float64) float64 {
+	return positionSize * r.ThrottleFactor()
+}
+
+// ExposureLimitEnabled reports whether the notional of open positions is capped by equity
+func (r *RiskManager) ExposureLimitEnabled() bool {
+	return r.config.MaxExposurePct > 0
+}
+
+// CheckExposure returns an error wrapping ErrMaxExposure if a new position of newNotional next to
+// openNotional already open would exceed the maximum exposure at equity. Always nil when the
+// limit is disabled
+func (r *RiskManager) CheckExposure(openNotional, newNotional, equity float64) error {
+	return CheckExposure(openNotional, newNotional, equity, r.config.MaxExposurePct)
+}
+
+// CheckExposure returns an error wrapping ErrMaxExposure if openNotional plus newNotional exceeds
+// maxExposure (a fraction of equity; 0 disables the check)
+func CheckExposure(openNotional, newNotional, equity, maxExposure float64) error {
+	if maxExposure <= 0 {
+		return nil
+	}
+	if equity <= 0 {
+		return fmt.Errorf("%w: no equity to hold a notional of %.2f", ErrMaxExposure, openNotional+newNotional)
+	}
+	exposure := (openNotional + newNotional) / equity
+	if exposure > maxExposure {
+		return fmt.Errorf("%w: notional %.2f open plus %.2f new is %.0f%% of equity %.2f, above the maximum of %.0f%%",
+			ErrMaxExposure, openNotional, newNotional, exposure*100, equity, maxExposure*100)
+	}
+	return nil
+}
+
+// VolumeCapEnabled reports whether position notional is capped by the 24h quote volume
+func (r *RiskManager) VolumeCapEnabled() bool {
+	return r.config.MaxVolumeShare > 0
+}
+
+// UpdateQuoteVolume records the symbol's latest rolling 24h quote volume
+func (r *RiskManager) UpdateQuoteVolume(volume float64) {
+	r.stats.QuoteVolume24h = volume
+}
+
+// MaxNotional returns the largest position notional the 24h quote volume allows, or 0 if the cap
+// is disabled or no volume was recorded yet
+func (r *RiskManager) MaxNotional() float64 {
+	if !r.VolumeCapEnabled() || r.stats.QuoteVolume24h <= 0 {
+		return 0
+	}
+	return r.stats.QuoteVolume24h * r.config.MaxVolumeShare
+}
+
+// CapToVolume limits a position size at price to the notional the 24h quote volume allows
+func (r *RiskManager) CapToVolume(positionSize, price float64) float64 {
+	limit := r.MaxNotional()
+	if limit <= 0 || price <= 0 {
+		return positionSize
+	}
+	return math.Min(positionSize, limit/price)
 }
 
 // GetPositionSize calculates the appropriate position size based on risk parameters
@@ -114,6 +252,10 @@ func (r *RiskManager) GetPositionSize(ctx context.Context, accountBalance float6
 	// Calculate position size based on account balance and risk parameters
 	positionSize := accountBalance * r.config.PositionSizePercent / currentPrice
 
+	// Scale down while in drawdown and keep within the 24h volume
+	positionSize = r.ApplyThrottle(positionSize)
+	positionSize = r.CapToVolume(positionSize, currentPrice)
+
 	// Ensure position size doesn't exceed maximum allowed
 	return math.Min(positionSize, r.config.MaxPositionSize)
 }
@@ -154,6 +296,41 @@ func (r *RiskManager) CheckRiskLimits(ctx context.Context, accountBalance float6
 	return nil
 }
 
+// ParseThrottleCurve parses a drawdown throttle curve of drawdown:factor pairs such as "0.05:1,0.10:0.5,0.15:0.25"
+func ParseThrottleCurve(spec string) ([]ThrottlePoint, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	var curve []ThrottlePoint
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid throttle point %q: expected drawdown:factor", pair)
+		}
+		dd, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid drawdown in %q: %w", pair, err)
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid factor in %q: %w", pair, err)
+		}
+		if dd < 0 || dd >= 1 {
+			return nil, fmt.Errorf("drawdown %v in %q must be between 0 and 1", dd, pair)
+		}
+		if factor < 0 || factor > 1 {
+			return nil, fmt.Errorf("factor %v in %q must be between 0 and 1", factor, pair)
+		}
+		if len(curve) > 0 && dd <= curve[len(curve)-1].Drawdown {
+			return nil, fmt.Errorf("drawdowns must be strictly increasing (%q)", pair)
+		}
+		curve = append(curve, ThrottlePoint{Drawdown: dd, Factor: factor})
+	}
+	return curve, nil
+}
+
 // GetStats returns the current risk management statistics
 func (r *RiskManager) GetStats() *RiskStats {
 	return r.stats
@@ -2,7 +2,10 @@ package risk
 
 import (
 	"context"
+	"cryptoMegaBot/internal/clock"
 	"cryptoMegaBot/internal/domain"
+	"errors"
+	"math"
 	"testing"
 	"time"
 )
@@ -181,3 +184,144 @@ func TestRiskManagerReset(t *testing.T) {
 		t.Error("Expected LastResetTime to be set")
 	}
 }
+
+func TestDrawdownThrottle(t *testing.T) {
+	manager := NewRiskManager(RiskConfig{
+		MaxPositionSize:     10.0,
+		PositionSizePercent: 0.1,
+		DrawdownThrottle:    DefaultDrawdownThrottle,
+	})
+	manager.UpdateEquity(context.Background(), 100000)
+
+	tests := []struct {
+		equity     float64
+		wantFactor float64
+	}{
+		{100000, 1.0},  // No drawdown
+		{96000, 1.0},   // 4% drawdown
+		{92500, 0.75},  // 7.5% drawdown, halfway between 5% and 10%
+		{90000, 0.5},   // 10% drawdown
+		{80000, 0.25},  // 20% drawdown
+		{120000, 1.0},  // New peak
+		{102000, 0.25}, // 15% below the new peak
+	}
+
+	for _, tt := range tests {
+		manager.UpdateEquity(context.Background(), tt.equity)
+		if got := manager.ThrottleFactor(); math.Abs(got-tt.wantFactor) > 1e-9 {
+			t.Errorf("Equity %.0f: expected throttle factor %f, got %f", tt.equity, tt.wantFactor, got)
+		}
+	}
+
+	// GetPositionSize applies the throttle (15% drawdown -> quarter size)
+	positionSize := manager.GetPositionSize(context.Background(), 100000, 50000)
+	expectedSize := 100000 * 0.1 / 50000 * 0.25
+	if math.Abs(positionSize-expectedSize) > 1e-9 {
+		t.Errorf("Expected throttled position size %f, got %f", expectedSize, positionSize)
+	}
+}
+
+func TestVolumeCap(t *testing.T) {
+	manager := NewRiskManager(RiskConfig{
+		MaxPositionSize:     10.0,
+		MaxLeverage:         5,
+		MaxOpenPositions:    3,
+		MaxDailyLoss:        1,
+		PositionSizePercent: 0.5,
+		MaxVolumeShare:      0.001,
+	})
+
+	// Without a recorded volume nothing is capped
+	if manager.MaxNotional() != 0 || manager.CapToVolume(2, 50000) != 2 {
+		t.Error("Expected no cap before the volume is recorded")
+	}
+
+	manager.UpdateQuoteVolume(50_000_000) // 50k notional at 0.1%
+	if got := manager.MaxNotional(); math.Abs(got-50000) > 1e-9 {
+		t.Errorf("Expected a notional cap of 50000, got %f", got)
+	}
+	if got := manager.CapToVolume(2, 50000); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected the size capped to 1, got %f", got)
+	}
+	if got := manager.CapToVolume(0.5, 50000); got != 0.5 {
+		t.Errorf("Expected a size within the cap unchanged, got %f", got)
+	}
+	if got := manager.GetPositionSize(context.Background(), 200000, 50000); math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected GetPositionSize capped to 1, got %f", got)
+	}
+
+	position := &domain.Position{Symbol: "BTCUSDT", EntryPrice: 50000, Quantity: 1.5, Leverage: 1, Status: domain.StatusOpen}
+	if err := manager.ValidatePosition(context.Background(), position, 1000000); err == nil {
+		t.Error("Expected an error for a notional above the volume cap")
+	}
+	position.Quantity = 0.8
+	if err := manager.ValidatePosition(context.Background(), position, 1000000); err != nil {
+		t.Errorf("Expected a position within the volume cap to be valid, got %v", err)
+	}
+
+	if NewRiskManager(RiskConfig{}).VolumeCapEnabled() {
+		t.Error("Expected the volume cap to be disabled by default")
+	}
+}
+
+func TestMaxExposure(t *testing.T) {
+	manager := NewRiskManager(RiskConfig{MaxPositionSize: 10, MaxLeverage: 5, MaxOpenPositions: 3, MaxDailyLoss: 1, MaxExposurePct: 2})
+	if !manager.ExposureLimitEnabled() {
+		t.Fatal("Expected the exposure limit to be enabled")
+	}
+	if err := manager.CheckExposure(1000, 1000, 1000); err != nil {
+		t.Errorf("Expected 200%% of equity to be allowed, got %v", err)
+	}
+	if err := manager.CheckExposure(1500, 1000, 1000); !errors.Is(err, ErrMaxExposure) {
+		t.Errorf("Expected ErrMaxExposure at 250%% of equity, got %v", err)
+	}
+	if err := manager.CheckExposure(0, 100, 0); !errors.Is(err, ErrMaxExposure) {
+		t.Errorf("Expected ErrMaxExposure without equity, got %v", err)
+	}
+
+	// ValidatePosition counts the notional of the open positions
+	manager.UpdateStats(context.Background(), &domain.Trade{EntryPrice: 100, Quantity: 15, Leverage: 5}, 1000)
+	position := &domain.Position{EntryPrice: 100, Quantity: 6, Leverage: 1}
+	if err := manager.ValidatePosition(context.Background(), position, 1000); !errors.Is(err, ErrMaxExposure) {
+		t.Errorf("Expected ErrMaxExposure with 1500 open, got %v", err)
+	}
+
+	if err := CheckExposure(5000, 5000, 1000, 0); err != nil {
+		t.Errorf("Expected no limit when disabled, got %v", err)
+	}
+}
+
+func TestParseThrottleCurve(t *testing.T) {
+	curve, err := ParseThrottleCurve("0.05:1, 0.10:0.5,0.15:0.25")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(curve) != 3 || curve[1] != (ThrottlePoint{Drawdown: 0.10, Factor: 0.5}) {
+		t.Errorf("Unexpected curve: %+v", curve)
+	}
+
+	if curve, err := ParseThrottleCurve(""); err != nil || curve != nil {
+		t.Errorf("Expected empty spec to disable throttling, got %+v, %v", curve, err)
+	}
+
+	for _, spec := range []string{"0.05", "0.1:abc", "0.1:1.5", "0.1:1,0.05:0.5", "1.2:0.5"} {
+		if _, err := ParseThrottleCurve(spec); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestRiskManagerClock(t *testing.T) {
+	now := time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC)
+	rm := NewRiskManager(RiskConfig{Clock: clock.NewFake(now)})
+	rm.UpdateStats(context.Background(), &domain.Trade{PNL: -10}, 1000)
+	rm.ResetDailyStats(context.Background())
+
+	stats := rm.GetStats()
+	if stats.DailyPnL != 0 || stats.DailyTrades != 0 {
+		t.Errorf("Expected daily stats to be reset, got PnL %v and %d trades", stats.DailyPnL, stats.DailyTrades)
+	}
+	if stats.LastResetTime != now.Unix() {
+		t.Errorf("Expected the reset time from the clock (%d), got %d", now.Unix(), stats.LastResetTime)
+	}
+}
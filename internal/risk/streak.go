@@ -0,0 +1,196 @@
+package risk
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StreakStep scales position size once a run of consecutive wins or losses reaches Streak
+type StreakStep struct {
+	Streak int     // Consecutive results that activate the step: positive for wins, negative for losses
+	Factor float64 // Position size multiplier while the step is active (e.g., 1.25 or 0.5)
+}
+
+// StreakLadder is a list of streak steps, ordered from the longest loss streak to the longest win
+// streak. It's written as comma-separated count+W/L:factor steps, e.g. "3W:1.25,2L:0.5" for 25%
+// more size after 3 wins in a row and half size after 2 losses in a row
+type StreakLadder []StreakStep
+
+// ParseStreakLadder parses a ladder such as "3W:1.25,5W:1.5,2L:0.5" (empty disables)
+func ParseStreakLadder(spec string) (StreakLadder, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	var ladder StreakLadder
+	seen := make(map[int]bool)
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid streak step %q: expected count+W/L:factor", item)
+		}
+		run := strings.ToUpper(strings.TrimSpace(parts[0]))
+		if len(run) < 2 {
+			return nil, fmt.Errorf("invalid streak in %q: expected a count followed by W or L", item)
+		}
+		count, err := strconv.Atoi(run[:len(run)-1])
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid streak in %q: count must be a positive integer", item)
+		}
+		switch run[len(run)-1] {
+		case 'W':
+		case 'L':
+			count = -count
+		default:
+			return nil, fmt.Errorf("invalid streak in %q: expected W (wins) or L (losses)", item)
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid factor in %q: %w", item, err)
+		}
+		if factor <= 0 {
+			return nil, fmt.Errorf("factor %v in %q must be positive", factor, item)
+		}
+		if seen[count] {
+			return nil, fmt.Errorf("duplicate streak in %q", item)
+		}
+		seen[count] = true
+		ladder = append(ladder, StreakStep{Streak: count, Factor: factor})
+	}
+	sort.Slice(ladder, func(i, j int) bool { return ladder[i].Streak < ladder[j].Streak })
+	return ladder, nil
+}
+
+// String formats the ladder in the form ParseStreakLadder reads
+func (l StreakLadder) String() string {
+	steps := make([]string, len(l))
+	for i, step := range l {
+		if step.Streak < 0 {
+			steps[i] = fmt.Sprintf("%dL:%s", -step.Streak, strconv.FormatFloat(step.Factor, 'f', -1, 64))
+		} else {
+			steps[i] = fmt.Sprintf("%dW:%s", step.Streak, strconv.FormatFloat(step.Factor, 'f', -1, 64))
+		}
+	}
+	return strings.Join(steps, ",")
+}
+
+// MarshalText implements encoding.TextMarshaler, so ladders serialize in their string form
+func (l StreakLadder) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *StreakLadder) UnmarshalText(text []byte) error {
+	ladder, err := ParseStreakLadder(string(text))
+	if err != nil {
+		return err
+	}
+	*l = ladder
+	return nil
+}
+
+// Factor returns the multiplier of the longest step the streak has reached in its direction, or
+// 1 if it hasn't reached any
+func (l StreakLadder) Factor(streak int) float64 {
+	factor := 1.0
+	for _, step := range l {
+		switch {
+		case streak > 0 && step.Streak > 0 && step.Streak <= streak:
+			factor = step.Factor // Ascending, so the last match is the longest
+		case streak < 0 && step.Streak < 0 && step.Streak >= streak:
+			return step.Factor // The first match is the longest loss streak reached
+		}
+	}
+	return factor
+}
+
+// MaxStreak returns the longest streak, wins or losses, any step needs
+func (l StreakLadder) MaxStreak() int {
+	longest := 0
+	for _, step := range l {
+		if n := abs(step.Streak); n > longest {
+			longest = n
+		}
+	}
+	return longest
+}
+
+// StreakSizer tracks the current win or loss streak and scales position sizes by a ladder. A nil
+// sizer leaves sizes unchanged. It isn't safe for concurrent use
+type StreakSizer struct {
+	ladder StreakLadder
+	streak int // Positive for consecutive wins, negative for consecutive losses
+}
+
+// NewStreakSizer creates a sizer for ladder starting without a streak
+func NewStreakSizer(ladder StreakLadder) *StreakSizer {
+	return &StreakSizer{ladder: ladder}
+}
+
+// Record adds a closed trade's PnL to the streak: a profit is a win, anything else a loss
+func (s *StreakSizer) Record(pnl float64) {
+	if s == nil {
+		return
+	}
+	switch {
+	case pnl > 0 && s.streak > 0:
+		s.streak++
+	case pnl > 0:
+		s.streak = 1
+	case s.streak < 0:
+		s.streak--
+	default:
+		s.streak = -1
+	}
+}
+
+// Restore rebuilds the streak from the PnL of recent closed trades, oldest first (e.g., after a
+// restart)
+func (s *StreakSizer) Restore(pnls []float64) {
+	if s == nil {
+		return
+	}
+	s.streak = 0
+	for _, pnl := range pnls {
+		s.Record(pnl)
+	}
+}
+
+// Streak returns the current streak: positive for consecutive wins, negative for consecutive losses
+func (s *StreakSizer) Streak() int {
+	if s == nil {
+		return 0
+	}
+	return s.streak
+}
+
+// Factor returns the position size multiplier for the current streak
+func (s *StreakSizer) Factor() float64 {
+	if s == nil {
+		return 1.0
+	}
+	return s.ladder.Factor(s.streak)
+}
+
+// Apply scales a position size by the current streak's factor
+func (s *StreakSizer) Apply(positionSize float64) float64 {
+	return positionSize * s.Factor()
+}
+
+// Ladder returns the sizer's ladder
+func (s *StreakSizer) Ladder() StreakLadder {
+	if s == nil {
+		return nil
+	}
+	return s.ladder
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,103 @@
+package risk
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseStreakLadder(t *testing.T) {
+	ladder, err := ParseStreakLadder(" 5w:1.5, 2L:0.5,3W:1.25,4l:0.25 ")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := StreakLadder{{Streak: -4, Factor: 0.25}, {Streak: -2, Factor: 0.5}, {Streak: 3, Factor: 1.25}, {Streak: 5, Factor: 1.5}}
+	if len(ladder) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, ladder)
+	}
+	for i := range expected {
+		if ladder[i] != expected[i] {
+			t.Errorf("Step %d: expected %v, got %v", i, expected[i], ladder[i])
+		}
+	}
+	if got := ladder.String(); got != "4L:0.25,2L:0.5,3W:1.25,5W:1.5" {
+		t.Errorf("Unexpected string form %q", got)
+	}
+	if ladder.MaxStreak() != 5 {
+		t.Errorf("Expected a longest streak of 5, got %d", ladder.MaxStreak())
+	}
+
+	if ladder, err := ParseStreakLadder(""); err != nil || ladder != nil {
+		t.Errorf("Expected an empty ladder to disable sizing, got %v, %v", ladder, err)
+	}
+	for _, spec := range []string{"3:1.25", "W:1.25", "0W:1", "3X:1", "3W", "3W:0", "3W:-1", "3W:abc", "3W:1.2,3W:1.3"} {
+		if _, err := ParseStreakLadder(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestStreakLadderText(t *testing.T) {
+	var settings struct {
+		Ladder StreakLadder `json:"ladder"`
+	}
+	if err := json.Unmarshal([]byte(`{"ladder":"3W:1.25,2L:0.5"}`), &settings); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(data) != `{"ladder":"2L:0.5,3W:1.25"}` {
+		t.Errorf("Unexpected round trip %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"ladder":"3W"}`), &settings); err == nil {
+		t.Error("Expected an error for an invalid ladder")
+	}
+}
+
+func TestStreakSizer(t *testing.T) {
+	ladder, err := ParseStreakLadder("3W:1.25,5W:1.5,2L:0.5,4L:0.25")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sizer := NewStreakSizer(ladder)
+
+	tests := []struct {
+		pnl    float64
+		streak int
+		factor float64
+	}{
+		{pnl: 10, streak: 1, factor: 1},
+		{pnl: 5, streak: 2, factor: 1},
+		{pnl: 8, streak: 3, factor: 1.25},
+		{pnl: 1, streak: 4, factor: 1.25},
+		{pnl: 2, streak: 5, factor: 1.5},
+		{pnl: 3, streak: 6, factor: 1.5},
+		{pnl: -4, streak: -1, factor: 1},
+		{pnl: 0, streak: -2, factor: 0.5}, // Breakeven counts as a loss
+		{pnl: -1, streak: -3, factor: 0.5},
+		{pnl: -2, streak: -4, factor: 0.25},
+		{pnl: 7, streak: 1, factor: 1},
+	}
+	for i, tt := range tests {
+		sizer.Record(tt.pnl)
+		if sizer.Streak() != tt.streak || math.Abs(sizer.Factor()-tt.factor) > 1e-9 {
+			t.Errorf("Trade %d: expected streak %d with factor %v, got %d with %v", i, tt.streak, tt.factor, sizer.Streak(), sizer.Factor())
+		}
+	}
+	if got := sizer.Apply(2); got != 2 {
+		t.Errorf("Expected an unscaled size, got %v", got)
+	}
+
+	sizer.Restore([]float64{5, -1, -2})
+	if sizer.Streak() != -2 || sizer.Apply(2) != 1 {
+		t.Errorf("Expected a restored 2 loss streak halving the size, got streak %d and size %v", sizer.Streak(), sizer.Apply(2))
+	}
+
+	var disabled *StreakSizer
+	disabled.Record(10)
+	if disabled.Apply(2) != 2 || disabled.Streak() != 0 {
+		t.Error("Expected a nil sizer to leave sizes unchanged")
+	}
+}
@@ -0,0 +1,208 @@
+// Package screener ranks symbols by how worth trading their market currently is, from daily
+// klines: the volatility percentile (today's ATR as a share of the price against its own recent
+// history), the trend strength (how directly the price moved over the trend period) and the traded
+// quote volume. The top of the ranking is the list of symbols to trade for the day
+package screener
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/indicators"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Config holds the screening parameters. Zero values use the defaults
+type Config struct {
+	ATRPeriod        int     // Period of the ATR (default 14)
+	Lookback         int     // Daily ATR readings today's reading is ranked against (default 90)
+	TrendPeriod      int     // Bars the trend strength is measured over (default 20)
+	VolumePeriod     int     // Bars the quote volume is averaged over (default 7)
+	MinQuoteVolume   float64 // Symbols averaging less daily quote volume are excluded (default 0)
+	VolatilityWeight float64 // Weight of the volatility percentile in the score (default 1)
+	TrendWeight      float64 // Weight of the trend strength in the score (default 1)
+	VolumeWeight     float64 // Weight of the volume rank in the score (default 1)
+}
+
+// withDefaults fills in the defaults of unset parameters
+func (c Config) withDefaults() Config {
+	if c.ATRPeriod <= 0 {
+		c.ATRPeriod = 14
+	}
+	if c.Lookback <= 0 {
+		c.Lookback = 90
+	}
+	if c.TrendPeriod <= 0 {
+		c.TrendPeriod = 20
+	}
+	if c.VolumePeriod <= 0 {
+		c.VolumePeriod = 7
+	}
+	if c.VolatilityWeight == 0 && c.TrendWeight == 0 && c.VolumeWeight == 0 {
+		c.VolatilityWeight, c.TrendWeight, c.VolumeWeight = 1, 1, 1
+	}
+	return c
+}
+
+// Validate checks the weights
+func (c Config) Validate() error {
+	if c.VolatilityWeight < 0 || c.TrendWeight < 0 || c.VolumeWeight < 0 {
+		return fmt.Errorf("weights cannot be negative")
+	}
+	if c.MinQuoteVolume < 0 {
+		return fmt.Errorf("minimum quote volume cannot be negative")
+	}
+	return nil
+}
+
+// RequiredKlines returns the number of daily klines Evaluate needs
+func (c Config) RequiredKlines() int {
+	c = c.withDefaults()
+	required := c.ATRPeriod + c.Lookback
+	if c.TrendPeriod+1 > required {
+		required = c.TrendPeriod + 1
+	}
+	if c.VolumePeriod > required {
+		required = c.VolumePeriod
+	}
+	return required
+}
+
+// Metrics are a symbol's readings at its last kline
+type Metrics struct {
+	Symbol               string  `json:"symbol"`
+	ATRPct               float64 `json:"atrPct"`               // ATR as a percentage of the close
+	VolatilityPercentile float64 `json:"volatilityPercentile"` // Share (0-100) of the lookback's ATR readings at or below ATRPct
+	TrendStrength        float64 `json:"trendStrength"`        // Net move over the trend period divided by the sum of the bar-to-bar moves (0-1)
+	TrendChangePct       float64 `json:"trendChangePct"`       // Net move over the trend period, in percent (negative when falling)
+	QuoteVolume          float64 `json:"quoteVolume"`          // Average daily volume in the quote currency
+}
+
+// Evaluate computes the metrics of a symbol from its daily klines (oldest first, the last one the
+// most recent closed day)
+func Evaluate(symbol string, klines []*domain.Kline, config Config) (Metrics, error) {
+	config = config.withDefaults()
+	if required := config.RequiredKlines(); len(klines) < required {
+		return Metrics{}, fmt.Errorf("%s: not enough klines (%d) to screen, need %d", symbol, len(klines), required)
+	}
+	n := len(klines)
+
+	// ATR percentage of every kline since the ATR became ready, then the last Lookback of them
+	atr := indicators.NewATRStream(indicators.ATRConfig{IndicatorConfig: indicators.IndicatorConfig{Period: config.ATRPeriod}})
+	var readings []float64
+	for _, k := range klines {
+		value, ready := atr.Update(k)
+		if !ready {
+			continue
+		}
+		if k.Close <= 0 {
+			return Metrics{}, fmt.Errorf("%s: non-positive close at %s", symbol, k.OpenTime.Format("2006-01-02"))
+		}
+		readings = append(readings, value/k.Close*100)
+	}
+	if len(readings) > config.Lookback {
+		readings = readings[len(readings)-config.Lookback:]
+	}
+	latest := readings[len(readings)-1]
+	var atOrBelow int
+	for _, r := range readings {
+		if r <= latest {
+			atOrBelow++
+		}
+	}
+
+	// Efficiency ratio: 1 when every bar moved the same way, near 0 when the price went nowhere
+	trendKlines := klines[n-config.TrendPeriod-1:]
+	var path float64
+	for i := 1; i < len(trendKlines); i++ {
+		path += math.Abs(trendKlines[i].Close - trendKlines[i-1].Close)
+	}
+	first, last := trendKlines[0].Close, trendKlines[len(trendKlines)-1].Close
+	var strength float64
+	if path > 0 {
+		strength = math.Abs(last-first) / path
+	}
+
+	var volume float64
+	for _, k := range klines[n-config.VolumePeriod:] {
+		volume += k.Volume * k.Close
+	}
+
+	return Metrics{
+		Symbol:               symbol,
+		ATRPct:               latest,
+		VolatilityPercentile: float64(atOrBelow) / float64(len(readings)) * 100,
+		TrendStrength:        strength,
+		TrendChangePct:       (last/first - 1) * 100,
+		QuoteVolume:          volume / float64(config.VolumePeriod),
+	}, nil
+}
+
+// Ranking is a symbol's place in the screen
+type Ranking struct {
+	Metrics
+	Score    float64 `json:"score"`              // Weighted mean of the normalized metrics (0-1)
+	Rank     int     `json:"rank"`               // 1 for the best symbol, 0 when excluded
+	Excluded string  `json:"excluded,omitempty"` // Why the symbol was left out of the ranking
+}
+
+// Rank scores the symbols and orders them best first, excluded symbols last. The volatility
+// percentile and trend strength are already comparable across symbols; quote volumes differ by
+// orders of magnitude, so each symbol's volume is scored by its rank among the others instead
+func Rank(metrics []Metrics, config Config) []Ranking {
+	config = config.withDefaults()
+	rankings := make([]Ranking, len(metrics))
+	var eligible []int
+	for i, m := range metrics {
+		rankings[i].Metrics = m
+		if m.QuoteVolume < config.MinQuoteVolume {
+			rankings[i].Excluded = fmt.Sprintf("volume %.0f below %.0f", m.QuoteVolume, config.MinQuoteVolume)
+			continue
+		}
+		eligible = append(eligible, i)
+	}
+
+	volumeScore := make(map[int]float64, len(eligible))
+	byVolume := append([]int(nil), eligible...)
+	sort.SliceStable(byVolume, func(a, b int) bool { return metrics[byVolume[a]].QuoteVolume < metrics[byVolume[b]].QuoteVolume })
+	for pos, i := range byVolume {
+		if len(byVolume) == 1 {
+			volumeScore[i] = 1
+		} else {
+			volumeScore[i] = float64(pos) / float64(len(byVolume)-1)
+		}
+	}
+
+	totalWeight := config.VolatilityWeight + config.TrendWeight + config.VolumeWeight
+	for _, i := range eligible {
+		m := metrics[i]
+		rankings[i].Score = (config.VolatilityWeight*m.VolatilityPercentile/100 +
+			config.TrendWeight*m.TrendStrength +
+			config.VolumeWeight*volumeScore[i]) / totalWeight
+	}
+
+	sort.SliceStable(rankings, func(a, b int) bool {
+		if (rankings[a].Excluded == "") != (rankings[b].Excluded == "") {
+			return rankings[a].Excluded == ""
+		}
+		return rankings[a].Score > rankings[b].Score
+	})
+	for i := range rankings {
+		if rankings[i].Excluded == "" {
+			rankings[i].Rank = i + 1
+		}
+	}
+	return rankings
+}
+
+// Top returns the symbols of the best n ranked symbols (all of them when n <= 0)
+func Top(rankings []Ranking, n int) []string {
+	var symbols []string
+	for _, r := range rankings {
+		if r.Excluded != "" || (n > 0 && len(symbols) >= n) {
+			continue
+		}
+		symbols = append(symbols, r.Symbol)
+	}
+	return symbols
+}
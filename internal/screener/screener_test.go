@@ -0,0 +1,89 @@
+package screener
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/klinegen"
+	"testing"
+	"time"
+)
+
+func daily(t *testing.T, volume float64, segments ...klinegen.Segment) []*domain.Kline {
+	t.Helper()
+	series, err := klinegen.Generate(klinegen.Config{Seed: 7, Interval: 24 * time.Hour, Volatility: 0.01, Volume: volume}, segments...)
+	if err != nil {
+		t.Fatalf("Failed to generate klines: %v", err)
+	}
+	return series.Klines
+}
+
+func TestEvaluate(t *testing.T) {
+	config := Config{}
+	trending, err := Evaluate("TRENDUSDT", daily(t, 1000, klinegen.Chop(100, 0.02), klinegen.Uptrend(25, 0.02)), config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ranging, err := Evaluate("CHOPUSDT", daily(t, 1000, klinegen.Chop(125, 0.02)), config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if trending.TrendStrength <= ranging.TrendStrength || trending.TrendChangePct <= 0 {
+		t.Errorf("Expected the uptrend to be stronger: %+v vs %+v", trending, ranging)
+	}
+
+	spiking, err := Evaluate("SPIKEUSDT", daily(t, 1000, klinegen.Chop(115, 0.02), klinegen.VolatilitySpike(10, 4)), config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if spiking.VolatilityPercentile < 90 {
+		t.Errorf("Expected a volatility spike at the top of its history, got percentile %.1f", spiking.VolatilityPercentile)
+	}
+	if spiking.QuoteVolume <= 0 {
+		t.Errorf("Expected a positive quote volume, got %f", spiking.QuoteVolume)
+	}
+
+	if _, err := Evaluate("SHORTUSDT", daily(t, 1000, klinegen.Chop(50, 0.02)), config); err == nil {
+		t.Errorf("Expected an error without enough klines")
+	}
+}
+
+func TestRank(t *testing.T) {
+	metrics := []Metrics{
+		{Symbol: "AUSDT", VolatilityPercentile: 50, TrendStrength: 0.2, QuoteVolume: 5e6},
+		{Symbol: "BUSDT", VolatilityPercentile: 90, TrendStrength: 0.6, QuoteVolume: 8e8},
+		{Symbol: "CUSDT", VolatilityPercentile: 95, TrendStrength: 0.9, QuoteVolume: 1e5},
+		{Symbol: "DUSDT", VolatilityPercentile: 10, TrendStrength: 0.1, QuoteVolume: 2e9},
+	}
+	rankings := Rank(metrics, Config{MinQuoteVolume: 1e6})
+
+	var order []string
+	for _, r := range rankings {
+		order = append(order, r.Symbol)
+	}
+	want := []string{"BUSDT", "DUSDT", "AUSDT", "CUSDT"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Expected order %v, got %v", want, order)
+		}
+	}
+	if last := rankings[3]; last.Excluded == "" || last.Rank != 0 {
+		t.Errorf("Expected the illiquid symbol to be excluded, got %+v", last)
+	}
+	if rankings[0].Rank != 1 || rankings[2].Rank != 3 {
+		t.Errorf("Unexpected ranks: %+v", rankings)
+	}
+
+	if top := Top(rankings, 2); len(top) != 2 || top[0] != "BUSDT" || top[1] != "DUSDT" {
+		t.Errorf("Expected the top 2 symbols, got %v", top)
+	}
+	if all := Top(rankings, 0); len(all) != 3 {
+		t.Errorf("Expected every eligible symbol, got %v", all)
+	}
+
+	// Weighting the trend alone puts the strongest trend first
+	if top := Top(Rank(metrics, Config{TrendWeight: 1}), 1); top[0] != "CUSDT" {
+		t.Errorf("Expected the strongest trend first, got %v", top)
+	}
+	if err := (Config{VolumeWeight: -1}).Validate(); err == nil {
+		t.Errorf("Expected negative weights to be rejected")
+	}
+}
@@ -0,0 +1,98 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// BootstrapResult is a paired bootstrap test of the difference between two PNL series
+type BootstrapResult struct {
+	MeanDiff   float64 // Observed mean of a[i] - b[i]
+	CILow      float64 // 2.5th percentile of the bootstrapped mean differences
+	CIHigh     float64 // 97.5th percentile of the bootstrapped mean differences
+	PValue     float64 // Two-sided probability of a difference at least this large if the means were equal
+	Periods    int     // Paired periods the test ran on
+	Iterations int     // Bootstrap resamples
+}
+
+// Significant reports whether the difference is significant at the given level (e.g., 0.05)
+func (r BootstrapResult) Significant(alpha float64) bool {
+	return r.PValue < alpha
+}
+
+// DailyPNL returns the realized PNL per UTC day from start to end (both days included), by exit
+// time. Days without exits are 0, so runs over the same period give series of the same length
+// that can be compared day by day. Trades exiting outside the period are ignored
+func DailyPNL(trades []*domain.Trade, start, end time.Time) []float64 {
+	first := utcDay(start)
+	last := utcDay(end)
+	if last.Before(first) {
+		return nil
+	}
+	days := int(last.Sub(first)/(24*time.Hour)) + 1
+	pnl := make([]float64, days)
+	for _, trade := range trades {
+		day := int(utcDay(trade.ExitTime).Sub(first) / (24 * time.Hour))
+		if trade.ExitTime.Before(first) || day >= days {
+			continue
+		}
+		pnl[day] += trade.PNL
+	}
+	return pnl
+}
+
+// BootstrapMeanDifference tests whether the mean of a differs from the mean of b, where a[i] and
+// b[i] belong to the same period (e.g., the DailyPNL of two strategies over the same klines). The
+// period differences are resampled with replacement iterations times; the p-value is the share of
+// resampled means, shifted to a zero mean, at least as far from 0 as the observed mean. The same
+// seed always gives the same result
+func BootstrapMeanDifference(a, b []float64, iterations int, seed int64) (BootstrapResult, error) {
+	if len(a) != len(b) {
+		return BootstrapResult{}, fmt.Errorf("paired series differ in length: %d vs %d", len(a), len(b))
+	}
+	if len(a) < 2 {
+		return BootstrapResult{}, fmt.Errorf("at least 2 paired periods are required, got %d", len(a))
+	}
+	if iterations <= 0 {
+		return BootstrapResult{}, fmt.Errorf("bootstrap iterations must be positive, got %d", iterations)
+	}
+
+	diffs := make([]float64, len(a))
+	for i := range a {
+		diffs[i] = a[i] - b[i]
+	}
+	observed := mean(diffs)
+
+	rng := utils.NewRand(seed)
+	means := make([]float64, iterations)
+	extreme := 0
+	for i := range means {
+		var sum float64
+		for range diffs {
+			sum += diffs[rng.Intn(len(diffs))]
+		}
+		means[i] = sum / float64(len(diffs))
+		if math.Abs(means[i]-observed) >= math.Abs(observed) {
+			extreme++
+		}
+	}
+	sort.Float64s(means)
+
+	return BootstrapResult{
+		MeanDiff:   observed,
+		CILow:      percentile(means, 0.025),
+		CIHigh:     percentile(means, 0.975),
+		PValue:     float64(extreme) / float64(iterations),
+		Periods:    len(diffs),
+		Iterations: iterations,
+	}, nil
+}
+
+// utcDay truncates t to the start of its UTC day
+func utcDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,102 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDailyPNL(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 3, 23, 59, 59, 0, time.UTC)
+	trades := []*domain.Trade{
+		{PNL: 10, ExitTime: start.Add(2 * time.Hour)},
+		{PNL: -4, ExitTime: start.Add(20 * time.Hour)},
+		{PNL: 7, ExitTime: start.Add(50 * time.Hour)},
+		{PNL: 100, ExitTime: start.Add(-time.Hour)},   // Before the period
+		{PNL: 100, ExitTime: end.Add(24 * time.Hour)}, // After the period
+	}
+
+	got := DailyPNL(trades, start, end)
+	want := []float64{6, 0, 7}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d days, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Day %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+
+	if got := DailyPNL(trades, end, start); got != nil {
+		t.Errorf("Expected nil for an inverted period, got %v", got)
+	}
+}
+
+func TestBootstrapMeanDifference(t *testing.T) {
+	t.Run("Clearly better series is significant", func(t *testing.T) {
+		a := make([]float64, 60)
+		b := make([]float64, 60)
+		for i := range a {
+			a[i] = 10 + float64(i%5)
+			b[i] = float64(i%7) - 3
+		}
+		result, err := BootstrapMeanDifference(a, b, 2000, 1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.MeanDiff <= 0 {
+			t.Errorf("Expected a positive mean difference, got %v", result.MeanDiff)
+		}
+		if !result.Significant(0.05) {
+			t.Errorf("Expected a significant difference, got p=%v", result.PValue)
+		}
+		if result.CILow <= 0 || result.CIHigh < result.CILow {
+			t.Errorf("Expected a positive confidence interval, got [%v, %v]", result.CILow, result.CIHigh)
+		}
+		if result.Periods != 60 || result.Iterations != 2000 {
+			t.Errorf("Unexpected counts: %d periods, %d iterations", result.Periods, result.Iterations)
+		}
+	})
+
+	t.Run("Noise around the same mean is not significant", func(t *testing.T) {
+		a := []float64{5, -3, 2, -1, 4, -6, 1, 0, -2, 3}
+		b := []float64{-2, 4, -1, 3, -5, 2, 0, 1, 3, -2}
+		result, err := BootstrapMeanDifference(a, b, 2000, 1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Significant(0.05) {
+			t.Errorf("Expected no significant difference, got p=%v", result.PValue)
+		}
+		if result.CILow > 0 || result.CIHigh < 0 {
+			t.Errorf("Expected the confidence interval to contain 0, got [%v, %v]", result.CILow, result.CIHigh)
+		}
+	})
+
+	t.Run("Same seed reproduces the result", func(t *testing.T) {
+		a := []float64{1, 2, 3, 4, 5}
+		b := []float64{2, 1, 2, 3, 1}
+		first, _ := BootstrapMeanDifference(a, b, 500, 7)
+		second, _ := BootstrapMeanDifference(a, b, 500, 7)
+		if first != second {
+			t.Errorf("Expected identical results, got %+v and %+v", first, second)
+		}
+		if math.Abs(first.MeanDiff-1.2) > 1e-9 {
+			t.Errorf("Expected mean difference 1.2, got %v", first.MeanDiff)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		if _, err := BootstrapMeanDifference([]float64{1, 2}, []float64{1}, 100, 1); err == nil {
+			t.Error("Expected an error for series of different lengths")
+		}
+		if _, err := BootstrapMeanDifference([]float64{1}, []float64{1}, 100, 1); err == nil {
+			t.Error("Expected an error for a single period")
+		}
+		if _, err := BootstrapMeanDifference([]float64{1, 2}, []float64{1, 2}, 0, 1); err == nil {
+			t.Error("Expected an error for no iterations")
+		}
+	})
+}
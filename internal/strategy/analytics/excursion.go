@@ -0,0 +1,83 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"sort"
+)
+
+// Distribution summarizes a set of values, e.g. the MAE of every trade
+type Distribution struct {
+	Count  int
+	Mean   float64
+	Median float64
+	P75    float64
+	P90    float64
+	P95    float64
+	Max    float64
+}
+
+// ExcursionStats holds MAE/MFE distributions (fractions of the entry price) used to tune stop and
+// target distances: a stop wider than most winners' MAE rarely cuts a winner short, and a target
+// inside most losers' MFE would have turned some of them into wins
+type ExcursionStats struct {
+	MAE        Distribution // Adverse excursion of all trades
+	MFE        Distribution // Favorable excursion of all trades
+	WinnersMAE Distribution // How far winning trades went against the position before recovering
+	WinnersMFE Distribution // Best unrealized profit of winning trades, to compare with what they captured
+	LosersMFE  Distribution // How far losing trades went in the position's favor before failing
+}
+
+// AnalyzeExcursions builds the MAE/MFE distributions of trades. Trades are split into winners and
+// losers by PNL, like AnalyzePerformance
+func AnalyzeExcursions(trades []*domain.Trade) ExcursionStats {
+	var mae, mfe, winnersMAE, winnersMFE, losersMFE []float64
+	for _, trade := range trades {
+		mae = append(mae, trade.MAE)
+		mfe = append(mfe, trade.MFE)
+		if trade.PNL > 0 {
+			winnersMAE = append(winnersMAE, trade.MAE)
+			winnersMFE = append(winnersMFE, trade.MFE)
+		} else {
+			losersMFE = append(losersMFE, trade.MFE)
+		}
+	}
+	return ExcursionStats{
+		MAE:        NewDistribution(mae),
+		MFE:        NewDistribution(mfe),
+		WinnersMAE: NewDistribution(winnersMAE),
+		WinnersMFE: NewDistribution(winnersMFE),
+		LosersMFE:  NewDistribution(losersMFE),
+	}
+}
+
+// NewDistribution summarizes values. Percentiles are linearly interpolated between the closest ranks
+func NewDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	return Distribution{
+		Count:  len(sorted),
+		Mean:   sum / float64(len(sorted)),
+		Median: percentile(sorted, 0.5),
+		P75:    percentile(sorted, 0.75),
+		P90:    percentile(sorted, 0.9),
+		P95:    percentile(sorted, 0.95),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the p-th quantile (0-1) of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
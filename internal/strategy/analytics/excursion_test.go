@@ -0,0 +1,45 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+)
+
+func TestNewDistribution(t *testing.T) {
+	d := NewDistribution([]float64{0.05, 0.01, 0.03, 0.02, 0.04})
+	if d.Count != 5 || math.Abs(d.Mean-0.03) > 1e-9 || d.Median != 0.03 || d.Max != 0.05 {
+		t.Errorf("Unexpected distribution: %+v", d)
+	}
+	if math.Abs(d.P75-0.04) > 1e-9 || math.Abs(d.P90-0.046) > 1e-9 {
+		t.Errorf("Expected interpolated P75 0.04 and P90 0.046, got %f and %f", d.P75, d.P90)
+	}
+
+	if empty := NewDistribution(nil); empty != (Distribution{}) {
+		t.Errorf("Expected an empty distribution, got %+v", empty)
+	}
+}
+
+func TestAnalyzeExcursions(t *testing.T) {
+	trades := []*domain.Trade{
+		{PNL: 10, MAE: 0.004, MFE: 0.03},
+		{PNL: 20, MAE: 0.006, MFE: 0.05},
+		{PNL: -15, MAE: 0.02, MFE: 0.008},
+	}
+
+	stats := AnalyzeExcursions(trades)
+	if stats.MAE.Count != 3 || stats.MAE.Max != 0.02 {
+		t.Errorf("Unexpected MAE distribution: %+v", stats.MAE)
+	}
+	if stats.WinnersMAE.Count != 2 || math.Abs(stats.WinnersMAE.Mean-0.005) > 1e-9 {
+		t.Errorf("Unexpected winners' MAE distribution: %+v", stats.WinnersMAE)
+	}
+	if stats.WinnersMFE.Max != 0.05 || stats.LosersMFE.Count != 1 || stats.LosersMFE.Max != 0.008 {
+		t.Errorf("Unexpected MFE distributions: winners %+v, losers %+v", stats.WinnersMFE, stats.LosersMFE)
+	}
+
+	metrics := AnalyzePerformance(trades, 1000)
+	if metrics.Excursions.MFE.Count != 3 {
+		t.Errorf("Expected AnalyzePerformance to include excursions, got %+v", metrics.Excursions)
+	}
+}
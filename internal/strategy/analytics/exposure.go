@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"sort"
+	"time"
+)
+
+// ExposureStats describes how long a strategy stays in the market and how often it trades, to
+// judge whether a profitable strategy is deployable intraday
+type ExposureStats struct {
+	Start           time.Time    // Start of the analyzed period
+	End             time.Time    // End of the analyzed period
+	TimeInMarket    float64      // Fraction of the period with at least one open position (0.25 = 25%)
+	TradesPerDay    Distribution // Entries per UTC day of the period, including days without trades
+	AverageBarsHeld float64      // Average trade duration in bars (0 when the bar interval is unknown)
+}
+
+// AnalyzeExposure measures time in market and trade frequency over the period from start to end.
+// Zero start or end default to the first entry or last exit. barInterval converts holding times
+// to bars; 0 leaves AverageBarsHeld unset
+func AnalyzeExposure(trades []*domain.Trade, start, end time.Time, barInterval time.Duration) ExposureStats {
+	if len(trades) == 0 {
+		return ExposureStats{Start: start, End: end}
+	}
+
+	sorted := append([]*domain.Trade(nil), trades...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].EntryTime.Before(sorted[j].EntryTime)
+	})
+	if start.IsZero() {
+		start = sorted[0].EntryTime
+	}
+	if end.IsZero() {
+		for _, trade := range sorted {
+			if trade.ExitTime.After(end) {
+				end = trade.ExitTime
+			}
+		}
+	}
+	stats := ExposureStats{Start: start, End: end}
+	if !end.After(start) {
+		return stats
+	}
+
+	if barInterval > 0 {
+		var held time.Duration
+		for _, trade := range sorted {
+			held += trade.ExitTime.Sub(trade.EntryTime)
+		}
+		stats.AverageBarsHeld = float64(held) / float64(barInterval) / float64(len(sorted))
+	}
+
+	stats.TimeInMarket = float64(timeInMarket(sorted, start, end)) / float64(end.Sub(start))
+	stats.TradesPerDay = NewDistribution(tradesPerDay(sorted, start, end))
+	return stats
+}
+
+// timeInMarket returns the time within the period covered by at least one trade, counting
+// overlapping trades (e.g., a long and a short in hedge mode) once. trades must be sorted by entry
+func timeInMarket(trades []*domain.Trade, start, end time.Time) time.Duration {
+	var total time.Duration
+	var spanStart, spanEnd time.Time
+	for _, trade := range trades {
+		entry, exit := trade.EntryTime, trade.ExitTime
+		if entry.Before(start) {
+			entry = start
+		}
+		if exit.After(end) {
+			exit = end
+		}
+		if !exit.After(entry) {
+			continue
+		}
+		if spanEnd.IsZero() || entry.After(spanEnd) {
+			total += spanEnd.Sub(spanStart)
+			spanStart, spanEnd = entry, exit
+		} else if exit.After(spanEnd) {
+			spanEnd = exit
+		}
+	}
+	return total + spanEnd.Sub(spanStart)
+}
+
+// tradesPerDay counts the entries on each UTC day the period touches
+func tradesPerDay(trades []*domain.Trade, start, end time.Time) []float64 {
+	firstDay := start.UTC().Truncate(24 * time.Hour)
+	days := int(end.UTC().Sub(firstDay)/(24*time.Hour)) + 1
+	if end.UTC().Equal(firstDay.Add(time.Duration(days-1) * 24 * time.Hour)) {
+		days-- // A period ending exactly at midnight doesn't touch the next day
+	}
+
+	counts := make([]float64, days)
+	for _, trade := range trades {
+		if trade.EntryTime.Before(start) || trade.EntryTime.After(end) {
+			continue
+		}
+		if day := int(trade.EntryTime.UTC().Sub(firstDay) / (24 * time.Hour)); day < days {
+			counts[day]++
+		}
+	}
+	return counts
+}
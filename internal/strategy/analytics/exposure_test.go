@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeExposure(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(4 * 24 * time.Hour) // Four days, the last two without trades
+	trades := []*domain.Trade{
+		// Overlapping long and short count once: 10:00-14:00
+		{EntryTime: start.Add(10 * time.Hour), ExitTime: start.Add(13 * time.Hour)},
+		{EntryTime: start.Add(12 * time.Hour), ExitTime: start.Add(14 * time.Hour)},
+		// Next day 08:00-12:00
+		{EntryTime: start.Add(32 * time.Hour), ExitTime: start.Add(36 * time.Hour)},
+	}
+
+	stats := AnalyzeExposure(trades, start, end, 15*time.Minute)
+	if want := 8.0 / 96; math.Abs(stats.TimeInMarket-want) > 1e-9 {
+		t.Errorf("Expected time in market %f, got %f", want, stats.TimeInMarket)
+	}
+	if want := 9.0 * 4 / 3; math.Abs(stats.AverageBarsHeld-want) > 1e-9 {
+		t.Errorf("Expected %f bars held on average, got %f", want, stats.AverageBarsHeld)
+	}
+	if d := stats.TradesPerDay; d.Count != 4 || d.Max != 2 || math.Abs(d.Mean-0.75) > 1e-9 || d.Median != 0.5 {
+		t.Errorf("Unexpected trades per day distribution: %+v", d)
+	}
+
+	// Without a period the span of the trades is used and bars are unknown
+	stats = AnalyzeExposure(trades, time.Time{}, time.Time{}, 0)
+	if !stats.Start.Equal(start.Add(10*time.Hour)) || !stats.End.Equal(start.Add(36*time.Hour)) {
+		t.Errorf("Expected the period to span the trades, got %v to %v", stats.Start, stats.End)
+	}
+	if want := 8.0 / 26; math.Abs(stats.TimeInMarket-want) > 1e-9 || stats.AverageBarsHeld != 0 {
+		t.Errorf("Unexpected exposure over the trade span: %+v", stats)
+	}
+	if stats.TradesPerDay.Count != 2 {
+		t.Errorf("Expected 2 days in the trade span, got %d", stats.TradesPerDay.Count)
+	}
+
+	if empty := AnalyzeExposure(nil, start, end, time.Minute); empty.TimeInMarket != 0 || empty.TradesPerDay.Count != 0 {
+		t.Errorf("Expected empty exposure without trades, got %+v", empty)
+	}
+}
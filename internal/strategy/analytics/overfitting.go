@@ -0,0 +1,194 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+)
+
+// eulerMascheroni is the Euler-Mascheroni constant of the expected maximum Sharpe ratio
+const eulerMascheroni = 0.5772156649015329
+
+// DeflatedSharpe is the Sharpe ratio of the best of several trials (e.g., optimizer parameter sets)
+// corrected for the selection among them, the non-normality of the returns and the track record
+// length (Bailey and López de Prado, 2014)
+type DeflatedSharpe struct {
+	Sharpe            float64 // Per-period Sharpe ratio of the selected trial's returns
+	ExpectedMaxSharpe float64 // Sharpe ratio the best of the trials is expected to reach by luck alone
+	// Probability that the true Sharpe ratio exceeds ExpectedMaxSharpe (the deflated Sharpe ratio);
+	// values below 0.95 mean the selected trial's Sharpe ratio isn't significant at 5%
+	Probability float64
+	Trials      int // Trials the selection was made from
+	Periods     int // Returns the Sharpe ratio was measured on
+}
+
+// DeflatedSharpeRatio deflates the Sharpe ratio of returns, the per-period returns of the best of
+// trials independent trials whose Sharpe ratios have a variance of sharpeVariance. A single trial
+// involves no selection, so only skewness, kurtosis and the number of periods count
+func DeflatedSharpeRatio(returns []float64, trials int, sharpeVariance float64) (DeflatedSharpe, error) {
+	if len(returns) < 2 {
+		return DeflatedSharpe{}, fmt.Errorf("at least 2 returns are required, got %d", len(returns))
+	}
+	if trials < 1 {
+		return DeflatedSharpe{}, fmt.Errorf("trials must be positive, got %d", trials)
+	}
+	if sharpeVariance < 0 || math.IsNaN(sharpeVariance) {
+		return DeflatedSharpe{}, fmt.Errorf("Sharpe ratio variance must not be negative, got %v", sharpeVariance)
+	}
+
+	result := DeflatedSharpe{Sharpe: SharpeRatio(returns), Trials: trials, Periods: len(returns)}
+	result.ExpectedMaxSharpe = ExpectedMaxSharpe(trials, sharpeVariance)
+
+	// Standard error of the Sharpe ratio estimate under skewed, fat-tailed returns
+	skewness, kurtosis := moments(returns)
+	variance := 1 - skewness*result.Sharpe + (kurtosis-1)/4*result.Sharpe*result.Sharpe
+	if variance <= 0 {
+		return DeflatedSharpe{}, fmt.Errorf("returns are too skewed for the Sharpe ratio's standard error (skewness %.2f, kurtosis %.2f)", skewness, kurtosis)
+	}
+	z := (result.Sharpe - result.ExpectedMaxSharpe) * math.Sqrt(float64(len(returns)-1)) / math.Sqrt(variance)
+	result.Probability = normalCDF(z)
+	return result, nil
+}
+
+// ExpectedMaxSharpe returns the Sharpe ratio the best of trials independent trials is expected to
+// reach when their true Sharpe ratios are all 0 and the estimates have a variance of sharpeVariance
+func ExpectedMaxSharpe(trials int, sharpeVariance float64) float64 {
+	if trials <= 1 || sharpeVariance <= 0 {
+		return 0
+	}
+	n := float64(trials)
+	return math.Sqrt(sharpeVariance) * ((1-eulerMascheroni)*normalQuantile(1-1/n) + eulerMascheroni*normalQuantile(1-1/(n*math.E)))
+}
+
+// PBOResult is the probability of backtest overfitting estimated by combinatorially symmetric
+// cross-validation (Bailey, Borwein, López de Prado and Zhu, 2015)
+type PBOResult struct {
+	// Share of the combinations where the trial with the best in-sample Sharpe ratio ranked in the
+	// bottom half out of sample. Around 0.5 or above, picking the in-sample best is no better than
+	// picking at random
+	Probability  float64
+	Combinations int       // In-sample/out-of-sample splits evaluated
+	Logits       []float64 // Logit of the out-of-sample relative rank of the in-sample best, per combination
+}
+
+// ProbabilityOfOverfitting estimates how often selecting the best trial in-sample picks one that
+// underperforms out of sample. performance holds the per-period returns of each trial over the same
+// periods (e.g., the DailyPNL of each parameter set). The periods are split into partitions
+// contiguous blocks (an even number; the oldest periods that don't fill a block are dropped) and
+// every combination of half of the blocks is used in-sample, the rest out of sample, ranking the
+// trials by their Sharpe ratio
+func ProbabilityOfOverfitting(performance [][]float64, partitions int) (PBOResult, error) {
+	if len(performance) < 2 {
+		return PBOResult{}, fmt.Errorf("at least 2 trials are required, got %d", len(performance))
+	}
+	if partitions < 2 || partitions%2 != 0 {
+		return PBOResult{}, fmt.Errorf("partitions must be an even number of at least 2, got %d", partitions)
+	}
+	periods := len(performance[0])
+	for i, returns := range performance {
+		if len(returns) != periods {
+			return PBOResult{}, fmt.Errorf("trial %d has %d periods, expected %d", i, len(returns), periods)
+		}
+	}
+	blockSize := periods / partitions
+	if blockSize < 2 {
+		return PBOResult{}, fmt.Errorf("%d periods are too few for %d partitions of at least 2 periods", periods, partitions)
+	}
+	offset := periods - blockSize*partitions
+
+	var result PBOResult
+	overfit := 0
+	inSample := make([]int, 0, partitions/2)
+	var combine func(next int)
+	combine = func(next int) {
+		if len(inSample) == partitions/2 {
+			logit := cscvLogit(performance, inSample, partitions, blockSize, offset)
+			result.Logits = append(result.Logits, logit)
+			if logit <= 0 {
+				overfit++
+			}
+			return
+		}
+		for block := next; block <= partitions-(partitions/2-len(inSample)); block++ {
+			inSample = append(inSample, block)
+			combine(block + 1)
+			inSample = inSample[:len(inSample)-1]
+		}
+	}
+	combine(0)
+
+	result.Combinations = len(result.Logits)
+	result.Probability = float64(overfit) / float64(result.Combinations)
+	return result, nil
+}
+
+// cscvLogit returns the logit of the out-of-sample relative rank of the trial with the best
+// in-sample Sharpe ratio, with the blocks listed in inSample in-sample and the others out of sample
+func cscvLogit(performance [][]float64, inSample []int, partitions, blockSize, offset int) float64 {
+	isBlock := make([]bool, partitions)
+	for _, block := range inSample {
+		isBlock[block] = true
+	}
+	isSharpe := make([]float64, len(performance))
+	oosSharpe := make([]float64, len(performance))
+	for trial, returns := range performance {
+		var is, oos []float64
+		for block := 0; block < partitions; block++ {
+			start := offset + block*blockSize
+			if isBlock[block] {
+				is = append(is, returns[start:start+blockSize]...)
+			} else {
+				oos = append(oos, returns[start:start+blockSize]...)
+			}
+		}
+		isSharpe[trial] = SharpeRatio(is)
+		oosSharpe[trial] = SharpeRatio(oos)
+	}
+
+	best := 0
+	for trial := range isSharpe {
+		if isSharpe[trial] > isSharpe[best] {
+			best = trial
+		}
+	}
+	// Rank from 1 (worst) to the number of trials, ties sharing the average rank
+	lower, equal := 0, 0
+	for trial, sharpe := range oosSharpe {
+		switch {
+		case sharpe < oosSharpe[best]:
+			lower++
+		case sharpe == oosSharpe[best] && trial != best:
+			equal++
+		}
+	}
+	rank := float64(lower) + float64(equal)/2 + 1
+	omega := rank / float64(len(performance)+1)
+	return math.Log(omega / (1 - omega))
+}
+
+// moments returns the skewness and (non-excess) kurtosis of values, both 0 without variation
+func moments(values []float64) (skewness, kurtosis float64) {
+	m := mean(values)
+	var m2, m3, m4 float64
+	for _, v := range values {
+		d := v - m
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	n := float64(len(values))
+	m2, m3, m4 = m2/n, m3/n, m4/n
+	if m2 == 0 {
+		return 0, 0
+	}
+	return m3 / math.Pow(m2, 1.5), m4 / (m2 * m2)
+}
+
+// normalCDF returns the standard normal cumulative distribution function at x
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// normalQuantile returns the standard normal quantile of p (0 < p < 1)
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
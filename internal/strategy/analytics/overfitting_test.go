@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExpectedMaxSharpe(t *testing.T) {
+	if got := ExpectedMaxSharpe(100, 1); math.Abs(got-2.5306) > 1e-4 {
+		t.Errorf("Expected a maximum Sharpe ratio of 2.5306 out of 100 trials, got %f", got)
+	}
+	if got := ExpectedMaxSharpe(1, 1); got != 0 {
+		t.Errorf("Expected no selection bias for a single trial, got %f", got)
+	}
+}
+
+func TestDeflatedSharpeRatio(t *testing.T) {
+	// 2% and -1% alternating: no skew, a Sharpe ratio of 1/3 scaled by the sample deviation
+	returns := make([]float64, 40)
+	for i := range returns {
+		returns[i] = 0.02
+		if i%2 == 1 {
+			returns[i] = -0.01
+		}
+	}
+	sharpe := math.Sqrt(39.0/40) / 3
+
+	single, err := DeflatedSharpeRatio(returns, 1, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(single.Sharpe-sharpe) > 1e-9 || math.Abs(single.Probability-normalCDF(sharpe*math.Sqrt(39))) > 1e-9 {
+		t.Errorf("Unexpected single trial result %+v", single)
+	}
+	if single.Probability < 0.95 {
+		t.Errorf("Expected a significant Sharpe ratio for a single trial, got %f", single.Probability)
+	}
+
+	// The same track record picked out of 100 trials could well be luck
+	selected, err := DeflatedSharpeRatio(returns, 100, 0.01)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(selected.ExpectedMaxSharpe-0.25306) > 1e-4 || selected.Probability >= 0.95 || selected.Probability >= single.Probability {
+		t.Errorf("Expected the selection to deflate the Sharpe ratio, got %+v", selected)
+	}
+
+	if _, err := DeflatedSharpeRatio(returns[:1], 1, 0); err == nil {
+		t.Error("Expected an error for a single return")
+	}
+	if _, err := DeflatedSharpeRatio(returns, 0, 0); err == nil {
+		t.Error("Expected an error without trials")
+	}
+}
+
+func TestProbabilityOfOverfitting(t *testing.T) {
+	t.Run("Consistently best trial", func(t *testing.T) {
+		performance := [][]float64{
+			{0.02, 0.01, 0.02, 0.01, 0.02, 0.01, 0.02, 0.01},
+			{0.01, -0.01, 0.01, -0.01, 0.01, -0.01, 0.01, -0.01},
+			{-0.01, 0.00, -0.01, 0.00, -0.01, 0.00, -0.01, 0.00},
+		}
+		result, err := ProbabilityOfOverfitting(performance, 4)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Combinations != 6 || result.Probability != 0 {
+			t.Errorf("Expected no overfitting over 6 combinations, got %+v", result)
+		}
+		for _, logit := range result.Logits {
+			if math.Abs(logit-math.Log(3)) > 1e-9 {
+				t.Errorf("Expected the best trial to rank first out of sample, got logit %f", logit)
+			}
+		}
+	})
+
+	t.Run("In-sample best reverses out of sample", func(t *testing.T) {
+		performance := [][]float64{
+			{0.02, 0.01, -0.01, -0.02},
+			{-0.01, -0.02, 0.02, 0.01},
+		}
+		result, err := ProbabilityOfOverfitting(performance, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Combinations != 2 || result.Probability != 1 {
+			t.Errorf("Expected every combination to overfit, got %+v", result)
+		}
+	})
+
+	t.Run("Invalid input", func(t *testing.T) {
+		valid := [][]float64{{1, 2, 3, 4}, {4, 3, 2, 1}}
+		for name, tt := range map[string]struct {
+			performance [][]float64
+			partitions  int
+		}{
+			"single trial":   {valid[:1], 2},
+			"odd partitions": {valid, 3},
+			"too few":        {valid, 4},
+			"ragged":         {[][]float64{{1, 2, 3, 4}, {1, 2, 3}}, 2},
+		} {
+			if _, err := ProbabilityOfOverfitting(tt.performance, tt.partitions); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
@@ -34,6 +34,14 @@ type PerformanceMetrics struct {
 	MonthlyReturns       map[string]float64
 	Drawdowns            []Drawdown
 	EquityCurve          []EquityPoint
+	Excursions           ExcursionStats  // MAE/MFE distributions
+	Exposure             ExposureStats   // Time in market and trade frequency over the span of the trades (see AnalyzeExposure for a full period)
+	Regimes              RegimeBreakdown // Win rate and expectancy by the market regime at entry
+
+	// Risk-Adjusted Returns (per-trade returns on the balance before each trade)
+	SortinoRatio         float64 // Mean return over downside deviation
+	CalmarRatio          float64 // Compound annual growth rate over MaxDrawdown
+	AnnualizedVolatility float64 // Standard deviation of returns scaled by the trades per year
 }
 
 // Drawdown represents a drawdown period
@@ -48,9 +56,10 @@ type Drawdown struct {
 
 // EquityPoint represents a point on the equity curve
 type EquityPoint struct {
-	Time     time.Time
-	Value    float64
-	Drawdown float64
+	Time          time.Time
+	Value         float64
+	Drawdown      float64
+	RollingSharpe float64 // Sharpe ratio of the last RollingSharpeWindow trades (0 until enough trades)
 }
 
 // AnalyzePerformance calculates comprehensive performance metrics from trades
@@ -76,9 +85,10 @@ func AnalyzePerformance(trades []*domain.Trade, initialBalance float64) *Perform
 	var currentDrawdown *Drawdown
 	var consecutiveWins, consecutiveLosses int
 	var maxConsecutiveWins, maxConsecutiveLosses int
+	returns := TradeReturns(trades, initialBalance)
 
 	// Process each trade
-	for _, trade := range trades {
+	for i, trade := range trades {
 		// Update basic metrics
 		metrics.TotalTrades++
 		if trade.PNL > 0 {
@@ -137,11 +147,15 @@ func AnalyzePerformance(trades []*domain.Trade, initialBalance float64) *Perform
 		}
 
 		// Add equity curve point
-		metrics.EquityCurve = append(metrics.EquityCurve, EquityPoint{
+		point := EquityPoint{
 			Time:     trade.ExitTime,
 			Value:    currentBalance,
 			Drawdown: (peakBalance - currentBalance) / peakBalance,
-		})
+		}
+		if i+1 >= RollingSharpeWindow {
+			point.RollingSharpe = SharpeRatio(returns[i+1-RollingSharpeWindow : i+1])
+		}
+		metrics.EquityCurve = append(metrics.EquityCurve, point)
 	}
 
 	// Close any open drawdown
@@ -186,6 +200,16 @@ func AnalyzePerformance(trades []*domain.Trade, initialBalance float64) *Perform
 		if metrics.AverageLoss != 0 {
 			metrics.RiskRewardRatio = metrics.AverageWin / -metrics.AverageLoss
 		}
+
+		// Calculate risk-adjusted returns
+		metrics.SharpeRatio = SharpeRatio(returns)
+		metrics.SortinoRatio = SortinoRatio(returns)
+		metrics.CalmarRatio = CalmarRatio(trades, initialBalance, metrics.FinalBalance, metrics.MaxDrawdown)
+		metrics.AnnualizedVolatility = AnnualizedVolatility(returns, trades)
+
+		metrics.Excursions = AnalyzeExcursions(trades)
+		metrics.Exposure = AnalyzeExposure(trades, time.Time{}, time.Time{}, 0)
+		metrics.Regimes = AnalyzeRegimes(trades)
 	}
 
 	return metrics
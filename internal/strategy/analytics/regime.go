@@ -0,0 +1,192 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"sort"
+)
+
+// RegimeConfig holds the parameters of DetectRegime. Zero values use the defaults
+type RegimeConfig struct {
+	Lookback           int     // Bars of the trend efficiency and the recent volatility (default 20)
+	TrendThreshold     float64 // Efficiency ratio at or above which the market is trending (default 0.3)
+	VolatilityLookback int     // Bars of the baseline volatility the recent one is compared to (default 100)
+	LowVolatility      float64 // Recent/baseline volatility ratio below which volatility is low (default 0.75)
+	HighVolatility     float64 // Recent/baseline volatility ratio above which volatility is high (default 1.5)
+	HigherTFBars       int     // Bars of the moving average that stands in for the higher timeframe trend (default 240)
+	DirectionBand      float64 // Distance of the close from that average (fraction) within which it's flat (default 0.002)
+}
+
+// withDefaults fills in the defaults of unset parameters
+func (c RegimeConfig) withDefaults() RegimeConfig {
+	if c.Lookback <= 0 {
+		c.Lookback = 20
+	}
+	if c.TrendThreshold <= 0 {
+		c.TrendThreshold = 0.3
+	}
+	if c.VolatilityLookback <= 0 {
+		c.VolatilityLookback = 100
+	}
+	if c.LowVolatility <= 0 {
+		c.LowVolatility = 0.75
+	}
+	if c.HighVolatility <= 0 {
+		c.HighVolatility = 1.5
+	}
+	if c.HigherTFBars <= 0 {
+		c.HigherTFBars = 240
+	}
+	if c.DirectionBand <= 0 {
+		c.DirectionBand = 0.002
+	}
+	return c
+}
+
+// DetectRegime classifies the market at the last of klines (oldest first):
+//   - trend: Kaufman's efficiency ratio (net move over the sum of bar-to-bar moves) of the last
+//     Lookback closes, trending at or above TrendThreshold and choppy below
+//   - volatility: mean true range of the last Lookback bars over that of the last
+//     VolatilityLookback bars, bucketed by LowVolatility and HighVolatility
+//   - higher timeframe direction: close above, below or within DirectionBand of the simple
+//     moving average of the last HigherTFBars closes
+//
+// Parts without enough history are left empty
+func DetectRegime(klines []*domain.Kline, config RegimeConfig) domain.MarketRegime {
+	config = config.withDefaults()
+	var regime domain.MarketRegime
+	n := len(klines)
+	if n == 0 {
+		return regime
+	}
+
+	if n > config.Lookback {
+		var path float64
+		for i := n - config.Lookback; i < n; i++ {
+			path += math.Abs(klines[i].Close - klines[i-1].Close)
+		}
+		efficiency := 0.0
+		if path > 0 {
+			efficiency = math.Abs(klines[n-1].Close-klines[n-1-config.Lookback].Close) / path
+		}
+		regime.Trend = domain.TrendRegimeChoppy
+		if efficiency >= config.TrendThreshold {
+			regime.Trend = domain.TrendRegimeTrending
+		}
+	}
+
+	if n > config.VolatilityLookback && config.VolatilityLookback > config.Lookback {
+		recent := meanTrueRange(klines[n-config.Lookback-1:])
+		baseline := meanTrueRange(klines[n-config.VolatilityLookback-1:])
+		if baseline > 0 {
+			ratio := recent / baseline
+			switch {
+			case ratio < config.LowVolatility:
+				regime.Volatility = domain.VolatilityRegimeLow
+			case ratio > config.HighVolatility:
+				regime.Volatility = domain.VolatilityRegimeHigh
+			default:
+				regime.Volatility = domain.VolatilityRegimeNormal
+			}
+		}
+	}
+
+	if n >= config.HigherTFBars {
+		var sum float64
+		for _, k := range klines[n-config.HigherTFBars:] {
+			sum += k.Close
+		}
+		average := sum / float64(config.HigherTFBars)
+		if average > 0 {
+			distance := (klines[n-1].Close - average) / average
+			switch {
+			case distance > config.DirectionBand:
+				regime.HigherTF = domain.TrendDirectionUp
+			case distance < -config.DirectionBand:
+				regime.HigherTF = domain.TrendDirectionDown
+			default:
+				regime.HigherTF = domain.TrendDirectionFlat
+			}
+		}
+	}
+	return regime
+}
+
+// meanTrueRange returns the mean true range of klines[1:], using klines[0] for the first
+// previous close
+func meanTrueRange(klines []*domain.Kline) float64 {
+	if len(klines) < 2 {
+		return 0
+	}
+	var sum float64
+	for i := 1; i < len(klines); i++ {
+		prevClose := klines[i-1].Close
+		k := klines[i]
+		sum += math.Max(k.High-k.Low, math.Max(math.Abs(k.High-prevClose), math.Abs(k.Low-prevClose)))
+	}
+	return sum / float64(len(klines)-1)
+}
+
+// RegimeStats summarizes the trades entered in one regime
+type RegimeStats struct {
+	Regime     string // Regime label (empty for trades without one)
+	Trades     int
+	WinRate    float64
+	TotalPNL   float64
+	Expectancy float64 // Average PNL per trade
+}
+
+// RegimeBreakdown reports trade results per market regime, each list sorted by label
+type RegimeBreakdown struct {
+	Trend      []RegimeStats // By trending/choppy
+	Volatility []RegimeStats // By volatility bucket
+	HigherTF   []RegimeStats // By higher timeframe direction
+	Combined   []RegimeStats // By the full regime, e.g. "trending/high/up"
+}
+
+// AnalyzeRegimes groups trades by the regime tagged at entry, to show where a strategy earns (or
+// loses) its PNL. Trades without a regime are left out
+func AnalyzeRegimes(trades []*domain.Trade) RegimeBreakdown {
+	trend := make(map[string][]*domain.Trade)
+	volatility := make(map[string][]*domain.Trade)
+	higherTF := make(map[string][]*domain.Trade)
+	combined := make(map[string][]*domain.Trade)
+	for _, trade := range trades {
+		r := trade.Regime
+		if r.IsZero() {
+			continue
+		}
+		trend[string(r.Trend)] = append(trend[string(r.Trend)], trade)
+		volatility[string(r.Volatility)] = append(volatility[string(r.Volatility)], trade)
+		higherTF[string(r.HigherTF)] = append(higherTF[string(r.HigherTF)], trade)
+		combined[r.String()] = append(combined[r.String()], trade)
+	}
+	return RegimeBreakdown{
+		Trend:      regimeStats(trend),
+		Volatility: regimeStats(volatility),
+		HigherTF:   regimeStats(higherTF),
+		Combined:   regimeStats(combined),
+	}
+}
+
+// regimeStats summarizes each group of trades, sorted by label
+func regimeStats(groups map[string][]*domain.Trade) []RegimeStats {
+	stats := make([]RegimeStats, 0, len(groups))
+	for label, group := range groups {
+		s := RegimeStats{Regime: label, Trades: len(group)}
+		wins := 0
+		for _, trade := range group {
+			s.TotalPNL += trade.PNL
+			if trade.PNL > 0 {
+				wins++
+			}
+		}
+		s.WinRate = float64(wins) / float64(len(group))
+		s.Expectancy = s.TotalPNL / float64(len(group))
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Regime < stats[j].Regime
+	})
+	return stats
+}
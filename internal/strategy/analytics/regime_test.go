@@ -0,0 +1,88 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+)
+
+// regimeKlines builds klines from closes, each bar ranging rangeFor(i) around its close
+func regimeKlines(closes []float64, rangeFor func(i int) float64) []*domain.Kline {
+	klines := make([]*domain.Kline, len(closes))
+	for i, c := range closes {
+		r := rangeFor(i)
+		klines[i] = &domain.Kline{Open: c, High: c + r/2, Low: c - r/2, Close: c}
+	}
+	return klines
+}
+
+func TestDetectRegime(t *testing.T) {
+	config := RegimeConfig{Lookback: 10, VolatilityLookback: 40, HigherTFBars: 50, DirectionBand: 0.01}
+	constantRange := func(int) float64 { return 1 }
+
+	// A steady climb: trending, above its average, volatility unchanged
+	rising := make([]float64, 60)
+	for i := range rising {
+		rising[i] = 100 + float64(i)
+	}
+	regime := DetectRegime(regimeKlines(rising, constantRange), config)
+	want := domain.MarketRegime{Trend: domain.TrendRegimeTrending, Volatility: domain.VolatilityRegimeNormal, HigherTF: domain.TrendDirectionUp}
+	if regime != want {
+		t.Errorf("Expected %v for a steady climb, got %v", want, regime)
+	}
+
+	// Alternating closes around a level, with the range widening over the last bars
+	choppy := make([]float64, 60)
+	for i := range choppy {
+		choppy[i] = 100 + float64(i%2)
+	}
+	widening := func(i int) float64 {
+		if i >= 50 {
+			return 6
+		}
+		return 1
+	}
+	regime = DetectRegime(regimeKlines(choppy, widening), config)
+	want = domain.MarketRegime{Trend: domain.TrendRegimeChoppy, Volatility: domain.VolatilityRegimeHigh, HigherTF: domain.TrendDirectionFlat}
+	if regime != want {
+		t.Errorf("Expected %v for a widening range, got %v", want, regime)
+	}
+
+	// Too little history leaves the longer-term parts unknown
+	regime = DetectRegime(regimeKlines(rising[:20], constantRange), config)
+	if regime.Trend != domain.TrendRegimeTrending || regime.Volatility != "" || regime.HigherTF != "" {
+		t.Errorf("Expected only the trend to be known, got %+v", regime)
+	}
+	if got := regime.String(); got != "trending/?/?" {
+		t.Errorf("Expected trending/?/?, got %s", got)
+	}
+	if !DetectRegime(nil, config).IsZero() {
+		t.Error("Expected no regime without klines")
+	}
+}
+
+func TestAnalyzeRegimes(t *testing.T) {
+	trendingUp := domain.MarketRegime{Trend: domain.TrendRegimeTrending, Volatility: domain.VolatilityRegimeNormal, HigherTF: domain.TrendDirectionUp}
+	choppyFlat := domain.MarketRegime{Trend: domain.TrendRegimeChoppy, Volatility: domain.VolatilityRegimeNormal, HigherTF: domain.TrendDirectionFlat}
+	trades := []*domain.Trade{
+		{PNL: 30, Regime: trendingUp},
+		{PNL: -10, Regime: trendingUp},
+		{PNL: -20, Regime: choppyFlat},
+		{PNL: 50}, // Untagged trades are left out
+	}
+
+	breakdown := AnalyzeRegimes(trades)
+	if len(breakdown.Trend) != 2 || breakdown.Trend[0].Regime != "choppy" || breakdown.Trend[1].Regime != "trending" {
+		t.Fatalf("Unexpected trend breakdown: %+v", breakdown.Trend)
+	}
+	trending := breakdown.Trend[1]
+	if trending.Trades != 2 || trending.WinRate != 0.5 || trending.TotalPNL != 20 || math.Abs(trending.Expectancy-10) > 1e-9 {
+		t.Errorf("Unexpected trending stats: %+v", trending)
+	}
+	if len(breakdown.Volatility) != 1 || breakdown.Volatility[0].Trades != 3 {
+		t.Errorf("Unexpected volatility breakdown: %+v", breakdown.Volatility)
+	}
+	if len(breakdown.Combined) != 2 || breakdown.Combined[1].Regime != "trending/normal/up" {
+		t.Errorf("Unexpected combined breakdown: %+v", breakdown.Combined)
+	}
+}
@@ -0,0 +1,131 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"time"
+)
+
+// RollingSharpeWindow is the number of trades in the rolling Sharpe ratio of the equity curve
+const RollingSharpeWindow = 30
+
+// TradeReturns returns each trade's PNL as a fraction of the balance before it, starting from
+// initialBalance. These per-trade returns are the periods of the Sharpe and Sortino ratios.
+// A trade taken with no balance left has a zero return.
+func TradeReturns(trades []*domain.Trade, initialBalance float64) []float64 {
+	returns := make([]float64, 0, len(trades))
+	balance := initialBalance
+	for _, trade := range trades {
+		if balance > 0 {
+			returns = append(returns, trade.PNL/balance)
+		} else {
+			returns = append(returns, 0)
+		}
+		balance += trade.PNL
+	}
+	return returns
+}
+
+// SharpeRatio returns the mean return divided by the sample standard deviation of returns,
+// assuming a risk-free rate of 0. Returns 0 for fewer than two returns or no variation.
+func SharpeRatio(returns []float64) float64 {
+	stdDev := sampleStdDev(returns)
+	if stdDev == 0 {
+		return 0
+	}
+	return mean(returns) / stdDev
+}
+
+// SortinoRatio returns the mean return divided by the downside deviation (the root mean square
+// of the negative returns), so only losses count as risk. Returns 0 for fewer than two returns
+// or no losing returns.
+func SortinoRatio(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+	var downside float64
+	for _, r := range returns {
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	if downside == 0 {
+		return 0
+	}
+	return mean(returns) / math.Sqrt(downside/float64(len(returns)))
+}
+
+// AnnualizedVolatility scales the standard deviation of per-trade returns to a year, using the
+// trade frequency over the period from the first entry to the last exit
+func AnnualizedVolatility(returns []float64, trades []*domain.Trade) float64 {
+	perYear := tradesPerYear(trades)
+	if perYear == 0 {
+		return 0
+	}
+	return sampleStdDev(returns) * math.Sqrt(perYear)
+}
+
+// CalmarRatio returns the compound annual growth rate from initialBalance to finalBalance over
+// the trades' period divided by the maximum drawdown. Returns 0 without a drawdown or period.
+func CalmarRatio(trades []*domain.Trade, initialBalance, finalBalance, maxDrawdown float64) float64 {
+	years := tradingYears(trades)
+	if maxDrawdown <= 0 || years == 0 || initialBalance <= 0 || finalBalance <= 0 {
+		return 0
+	}
+	annualReturn := math.Pow(finalBalance/initialBalance, 1/years) - 1
+	return annualReturn / maxDrawdown
+}
+
+// tradesPerYear returns the number of trades per year over the trades' period
+func tradesPerYear(trades []*domain.Trade) float64 {
+	years := tradingYears(trades)
+	if years == 0 {
+		return 0
+	}
+	return float64(len(trades)) / years
+}
+
+// tradingYears returns the time from the first entry to the last exit in years
+func tradingYears(trades []*domain.Trade) float64 {
+	if len(trades) == 0 {
+		return 0
+	}
+	start, end := trades[0].EntryTime, trades[0].ExitTime
+	for _, trade := range trades[1:] {
+		if trade.EntryTime.Before(start) {
+			start = trade.EntryTime
+		}
+		if trade.ExitTime.After(end) {
+			end = trade.ExitTime
+		}
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours() / (365 * 24 * time.Hour).Hours()
+}
+
+// mean returns the arithmetic mean of values (0 if empty)
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// sampleStdDev returns the sample standard deviation of values (0 for fewer than two)
+func sampleStdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	var variance float64
+	for _, v := range values {
+		variance += (v - m) * (v - m)
+	}
+	return math.Sqrt(variance / float64(len(values)-1))
+}
@@ -0,0 +1,108 @@
+package analytics
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSharpeRatio(t *testing.T) {
+	tests := []struct {
+		name          string
+		returns       []float64
+		expectedRatio float64
+	}{
+		{
+			name:          "Positive returns",
+			returns:       []float64{0.1, 0.2, 0.15},
+			expectedRatio: 3.0, // mean: 0.15, std dev: 0.05
+		},
+		{
+			name:          "Negative returns",
+			returns:       []float64{-0.1, -0.2, -0.15},
+			expectedRatio: -3.0,
+		},
+		{
+			name:          "Mixed returns",
+			returns:       []float64{-0.1, 0.2, 0.0},
+			expectedRatio: 0.218218, // mean: 0.0333, std dev: 0.1528
+		},
+		{
+			name:          "Single return",
+			returns:       []float64{0.1},
+			expectedRatio: 0,
+		},
+		{
+			name:          "Empty returns",
+			returns:       []float64{},
+			expectedRatio: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ratio := SharpeRatio(tt.returns)
+			if math.Abs(ratio-tt.expectedRatio) > 0.0001 {
+				t.Errorf("Expected Sharpe ratio %f, got %f", tt.expectedRatio, ratio)
+			}
+		})
+	}
+}
+
+func TestSortinoRatio(t *testing.T) {
+	// mean 0.025, downside deviation sqrt((0.01+0.0025)/4) = 0.0559
+	ratio := SortinoRatio([]float64{0.1, -0.1, 0.15, -0.05})
+	if math.Abs(ratio-0.447214) > 0.0001 {
+		t.Errorf("Expected Sortino ratio 0.447214, got %f", ratio)
+	}
+	if ratio := SortinoRatio([]float64{0.1, 0.2}); ratio != 0 {
+		t.Errorf("Expected 0 without losing returns, got %f", ratio)
+	}
+}
+
+func TestTradeReturns(t *testing.T) {
+	trades := []*domain.Trade{{PNL: 100}, {PNL: -220}, {PNL: 44}}
+	returns := TradeReturns(trades, 1000)
+	expected := []float64{0.1, -0.2, 0.05}
+	for i, r := range expected {
+		if math.Abs(returns[i]-r) > 1e-9 {
+			t.Errorf("Return %d: expected %f, got %f", i, r, returns[i])
+		}
+	}
+}
+
+func TestRiskAdjustedMetrics(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var trades []*domain.Trade
+	for i := 0; i < 40; i++ {
+		pnl := 30.0
+		if i%3 == 2 {
+			pnl = -40
+		}
+		entry := start.Add(time.Duration(i) * 24 * time.Hour)
+		trades = append(trades, &domain.Trade{PNL: pnl, EntryTime: entry, ExitTime: entry.Add(12 * time.Hour)})
+	}
+
+	metrics := AnalyzePerformance(trades, 10000)
+	if metrics.SharpeRatio <= 0 || metrics.SortinoRatio <= 0 || metrics.CalmarRatio <= 0 || metrics.AnnualizedVolatility <= 0 {
+		t.Errorf("Expected positive risk-adjusted metrics, got Sharpe %f Sortino %f Calmar %f Vol %f",
+			metrics.SharpeRatio, metrics.SortinoRatio, metrics.CalmarRatio, metrics.AnnualizedVolatility)
+	}
+
+	// Volatility is the per-trade standard deviation scaled by about 40 trades per 39.5 days
+	returns := TradeReturns(trades, 10000)
+	expectedVol := sampleStdDev(returns) * math.Sqrt(40/(39.5/365))
+	if math.Abs(metrics.AnnualizedVolatility-expectedVol) > 1e-9 {
+		t.Errorf("Expected annualized volatility %f, got %f", expectedVol, metrics.AnnualizedVolatility)
+	}
+
+	// The rolling Sharpe starts once a full window of trades is available
+	if metrics.EquityCurve[RollingSharpeWindow-2].RollingSharpe != 0 {
+		t.Error("Expected no rolling Sharpe before a full window")
+	}
+	last := metrics.EquityCurve[len(metrics.EquityCurve)-1].RollingSharpe
+	if expected := SharpeRatio(returns[len(returns)-RollingSharpeWindow:]); last != expected {
+		t.Errorf("Expected rolling Sharpe %f, got %f", expected, last)
+	}
+}
@@ -3,7 +3,12 @@ package backtesting
 import (
 	"context"
 	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/money"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+	"cryptoMegaBot/internal/strategy/analytics"
 	"cryptoMegaBot/internal/strategy/strategies"
+	"cryptoMegaBot/internal/utils"
 	"fmt"
 	"time"
 )
@@ -18,6 +23,165 @@ type BacktestConfig struct {
 	TakeProfit   float64
 	Symbol       string
 	Leverage     int
+
+	// Currency of PositionSize: with domain.QuantityModeQuote it's a quote amount converted at each
+	// fill price and rounded down to Precision's step size (zero value uses money.DefaultPrecision)
+	QuantityMode domain.QuantityMode
+	Precision    money.Precision
+
+	// Limit order entries
+	LimitOrderExpiryBars int // Default bars a limit entry stays active when the strategy doesn't specify one (default 3)
+
+	// Optional risk manager; when set, PositionSize is throttled by its drawdown curve and entries
+	// whose notional exceeds its maximum exposure of the balance are skipped
+	RiskManager *risk.RiskManager
+
+	// Optional win/loss streak sizing; when set, PositionSize is scaled by its ladder and its
+	// streak is updated by the run's trades (warm-up trades excluded)
+	StreakSizer *risk.StreakSizer
+
+	// Positions use isolated margin (entry price times quantity) and are liquidated when the margin
+	// left falls to the maintenance margin (0 uses defaultMaintenanceMarginRate)
+	MaintenanceMarginRate float64
+
+	// Optional news/volatility blackout windows, evaluated at each bar's open time: entry signals
+	// are skipped while one is active and stops are tightened if the schedule sets tighten_stop
+	Blackout *risk.BlackoutSchedule
+
+	// Optional trading calendar, evaluated at each bar's open time: PositionSize is scaled by the
+	// day's factor and entry signals are skipped on the days it disables
+	Calendar *risk.TradingCalendar
+
+	// Optional day trading session end (UTC time of day as an offset from midnight, 0 disables):
+	// a position still open at the first bar opening at or after it is closed at that bar's open,
+	// and no entries are made or limit entries filled until UTC midnight
+	SessionEnd time.Duration
+
+	// Optional scale-in entries: the entry signal opens the plan's initial share of PositionSize and
+	// the rest is added as limit fills at the plan's price improvements (not during blackouts)
+	ScaleIn domain.ScaleInPlan
+
+	// Parameters of the market regime each trade is tagged with at entry (zero value uses the
+	// analytics.RegimeConfig defaults)
+	Regime analytics.RegimeConfig
+
+	// Optional open interest snapshots, oldest first, for strategies that implement
+	// ports.OpenInterestStrategy: each bar they get the snapshots taken by its close
+	OpenInterest []*domain.OpenInterest
+
+	// Seed for any randomness in the run (0 picks a fresh seed, which is recorded in the result)
+	Seed int64
+
+	// Trading fees and funding charged on each trade (zero value uses defaultFees)
+	Fees domain.FeeModel
+
+	// Adverse price move (fraction) on market fills: entries at market and exits other than take
+	// profits filled inside a bar and liquidations (e.g., 0.0005 for 0.05%)
+	Slippage float64
+
+	// Warm-up bars after RequiredDataPoints while long-period indicators settle. The strategy
+	// trades them as usual, but those trades are reported separately in the result and don't
+	// count towards the statistics, balance or drawdown
+	WarmupBars int
+
+	// Optional progress reporting: Progress is called every ProgressInterval bars (default 1% of
+	// the run) and after the last bar
+	Progress         ProgressCallback
+	ProgressInterval int
+
+	// Optional channel receiving every closed trade as it happens. Backtest never closes it and
+	// blocks on each send until it's received or ctx is canceled
+	TradeEvents chan<- TradeEvent
+
+	// Optional NDJSON log every closed trade is appended to as it happens (see TradeLog). The run
+	// fails if a trade can't be written
+	TradeLog *TradeLog
+
+	// Record each trade's stop-loss, take-profit and trailing stop levels bar by bar in
+	// BacktestResult.StopPaths (e.g., for charting)
+	RecordStopPaths bool
+
+	// Check each bar's high and low against the position's stop, trailing stop and take profit
+	// before the strategy's close check (IntrabarOff only checks exits at the close)
+	Intrabar IntrabarFill
+
+	// When market entries and the strategy's exits fill: at the open of the bar after the signal
+	// (ExecutionNextOpen, the zero value) or at the signal bar's close (ExecutionSignalClose).
+	// Stops and take profits inside a bar, liquidations and session end exits are unaffected
+	Timing ExecutionTiming
+
+	// Sides entries may be opened on (zero value allows both). Backtests only open long positions,
+	// so TradeDirectionShort skips every entry signal
+	Direction domain.TradeDirection
+}
+
+// Progress is a snapshot of a running backtest
+type Progress struct {
+	Bar       int       // Bars processed so far
+	TotalBars int       // Bars the run will process in total
+	Time      time.Time // Open time of the last processed bar
+	Balance   float64   // Realized balance
+	Equity    float64   // Balance plus the open position's unrealized PNL after fees
+	Trades    int       // Closed trades counted in the statistics
+}
+
+// Percent returns the share of bars processed, from 0 to 100
+func (p Progress) Percent() float64 {
+	if p.TotalBars == 0 {
+		return 100
+	}
+	return float64(p.Bar) / float64(p.TotalBars) * 100
+}
+
+// ProgressCallback receives progress updates from Backtest. It runs on the backtest goroutine,
+// so it should return quickly
+type ProgressCallback func(Progress)
+
+// StopLevel is a position's protective levels after the strategy evaluated it on a bar
+type StopLevel struct {
+	Time         time.Time
+	StopLoss     float64
+	TakeProfit   float64
+	TrailingStop float64 // 0 until the trailing stop activates
+}
+
+// TradeEvent is sent on BacktestConfig.TradeEvents when a trade closes
+type TradeEvent struct {
+	Trade   *domain.Trade
+	Warmup  bool    // Entered during the warm-up window and excluded from the statistics
+	Balance float64 // Realized balance after the trade
+}
+
+// ShouldReportProgress reports whether a progress update is due after bar (1-based) of totalBars
+func (c BacktestConfig) ShouldReportProgress(bar, totalBars int) bool {
+	interval := c.ProgressInterval
+	if interval <= 0 {
+		interval = totalBars / 100
+	}
+	if interval < 1 {
+		interval = 1
+	}
+	return bar%interval == 0 || bar == totalBars
+}
+
+// defaultLimitOrderExpiryBars is used when neither the strategy nor the config set an expiry
+const defaultLimitOrderExpiryBars = 3
+
+// defaultMaintenanceMarginRate is used when the config doesn't set one (Binance's lowest ETHUSDT tier)
+const defaultMaintenanceMarginRate = 0.005
+
+// defaultFees is used when the config doesn't set a fee model (0.1% per fill, no funding)
+var defaultFees = domain.FeeModel{TakerRate: 0.001}
+
+// pendingEntry is a resting limit entry waiting to be filled, or a market entry filling at the
+// next bar's open
+type pendingEntry struct {
+	market      bool // Market entry (ExecutionNextOpen); price is unused
+	price       float64
+	expiryIndex int                 // Last kline index at which the order can still fill
+	tag         domain.EntryTag     // Entry tag captured when the signal fired
+	regime      domain.MarketRegime // Market regime when the signal fired
+	warmup      bool                // Placed during the warm-up window
 }
 
 // BacktestResult holds the results of a backtest
@@ -35,156 +199,587 @@ type BacktestResult struct {
 	FinalBalance       float64
 	ReturnOnInvestment float64
 	Trades             []*domain.Trade
+
+	// Limit order statistics
+	LimitOrdersPlaced  int
+	LimitOrdersFilled  int
+	LimitOrdersExpired int
+
+	// Entry signals skipped because a blackout window was active
+	BlackoutSkipped int
+
+	// Entry signals skipped on days the trading calendar disables
+	CalendarSkipped int
+
+	// Scale-in adds filled (see BacktestConfig.ScaleIn)
+	ScaleIns int
+
+	// Positions closed at the session end (see BacktestConfig.SessionEnd)
+	SessionEndExits int
+
+	// Margin accounting
+	Liquidations              []*domain.Trade // Trades closed by liquidation, also included in Trades
+	LiquidationLoss           float64         // Total PNL of the liquidated trades
+	InsufficientMarginSkipped int             // Entries skipped because their margin exceeded the balance
+	MaxExposureSkipped        int             // Entries skipped because their notional exceeded the maximum exposure of the balance
+
+	// Intrabar exits (see BacktestConfig.Intrabar)
+	IntrabarExits     int // Exits filled at a stop or take-profit level inside a bar
+	IntrabarAmbiguous int // Of those, bars reaching both levels, resolved by the fill model
+
+	// Stop levels of each trade when BacktestConfig.RecordStopPaths is set: StopPaths[i] belongs to
+	// Trades[i] and starts at its entry
+	StopPaths [][]StopLevel
+
+	// Seed used for the run; pass it back in BacktestConfig.Seed to reproduce the result
+	Seed int64
+
+	// Warm-up window, excluded from everything above
+	WarmupBars   int
+	WarmupEnd    time.Time       // Open time of the first bar counted in the statistics
+	WarmupTrades []*domain.Trade // Trades entered during the warm-up
+	WarmupProfit float64
+
+	// Set when ctx was canceled mid-run; the statistics then cover only the first BarsProcessed bars
+	Aborted       bool
+	BarsProcessed int
 }
 
-// Backtest runs a backtest for a given strategy
+// Backtest runs a backtest for a given strategy. Each position ties up its isolated margin (entry
+// price times quantity): entries needing more than the balance are skipped, and positions whose
+// bar trades through their liquidation price are closed there, losing the whole margin. If ctx is
+// canceled mid-run, the partial result is returned (with Aborted set) together with the context's error
 func Backtest(ctx context.Context, strategy strategies.Strategy, klines []*domain.Kline, config BacktestConfig) (*BacktestResult, error) {
 	if len(klines) < strategy.RequiredDataPoints() {
 		return nil, fmt.Errorf("not enough data points for strategy")
 	}
+	if config.WarmupBars < 0 {
+		return nil, fmt.Errorf("warm-up bars cannot be negative")
+	}
+	warmupEnd := strategy.RequiredDataPoints() + config.WarmupBars
+	if warmupEnd >= len(klines) && config.WarmupBars > 0 {
+		return nil, fmt.Errorf("not enough data points for a %d bar warm-up", config.WarmupBars)
+	}
 
 	result := &BacktestResult{
 		FinalBalance: config.InitialFunds,
+		Seed:         utils.ResolveSeed(config.Seed),
+		WarmupBars:   config.WarmupBars,
+	}
+	if warmupEnd < len(klines) {
+		result.WarmupEnd = klines[warmupEnd].OpenTime
 	}
 
 	var currentPosition *domain.Position
+	var stopPath []StopLevel
+	var positionInWarmup bool
+	var positionRegime domain.MarketRegime
+	var pendingOrder *pendingEntry
+	var pendingExit domain.CloseReason // Strategy exit filling at the next bar's open
 	var peakBalance = config.InitialFunds
 	var trades []*domain.Trade
 
+	expiryBars := config.LimitOrderExpiryBars
+	if expiryBars <= 0 {
+		expiryBars = defaultLimitOrderExpiryBars
+	}
+	fees := config.Fees
+	if fees == (domain.FeeModel{}) {
+		fees = defaultFees
+	}
+	maintenanceMarginRate := config.MaintenanceMarginRate
+	if maintenanceMarginRate <= 0 {
+		maintenanceMarginRate = defaultMaintenanceMarginRate
+	}
+	entryProvider, usesEntryOrders := strategy.(strategies.EntryOrderProvider)
+	tagger, tagsEntries := strategy.(ports.EntryTagger)
+	oiStrategy, usesOpenInterest := strategy.(ports.OpenInterestStrategy)
+	usesOpenInterest = usesOpenInterest && len(config.OpenInterest) > 0
+	oiIndex := 0 // Snapshots taken by the current bar's close
+	if config.RiskManager != nil {
+		config.RiskManager.UpdateEquity(ctx, config.InitialFunds)
+	}
+
 	// Sort klines by time
 	// Note: Assuming klines are already sorted by time
 
+	totalBars := len(klines) - strategy.RequiredDataPoints()
+
 	// Iterate through klines
 	for i := strategy.RequiredDataPoints(); i < len(klines); i++ {
+		if ctx.Err() != nil {
+			result.Aborted = true
+			break
+		}
 		currentKline := klines[i]
 		historicalKlines := klines[:i+1]
+		inWarmup := i < warmupEnd
+		sessionEnded := domain.SessionEnded(currentKline.OpenTime, config.SessionEnd)
+
+		// An entry still pending at the session end is canceled
+		if pendingOrder != nil && sessionEnded {
+			if !pendingOrder.warmup && !pendingOrder.market {
+				result.LimitOrdersExpired++
+			}
+			pendingOrder = nil
+		}
+
+		// Try to fill a resting limit entry or a market entry signaled on an earlier bar
+		if pendingOrder != nil {
+			fillPrice, filled := config.Slipped(openPrice(klines, i), true), true
+			if !pendingOrder.market {
+				fillPrice, filled = limitOrderFill(pendingOrder.price, currentKline)
+			}
+			if filled {
+				pos, err := newPosition(config, fillPrice, currentKline.OpenTime)
+				if err == nil && margin(pos) > result.FinalBalance {
+					if !pendingOrder.warmup {
+						result.InsufficientMarginSkipped++
+					}
+				} else if err == nil && config.exceedsExposure(pos, result.FinalBalance) {
+					if !pendingOrder.warmup {
+						result.MaxExposureSkipped++
+					}
+				} else if err == nil {
+					pos.EntryTag = pendingOrder.tag
+					currentPosition = pos
+					positionRegime = pendingOrder.regime
+					stopPath = nil // Recorded with the exit check below
+					positionInWarmup = pendingOrder.warmup
+					if !pendingOrder.warmup {
+						result.TotalTrades++
+						if !pendingOrder.market {
+							result.LimitOrdersFilled++
+						}
+					}
+				} else if !pendingOrder.warmup && !pendingOrder.market {
+					result.LimitOrdersExpired++ // Throttled to zero size; the fill is dropped
+				}
+				pendingOrder = nil
+			} else if i >= pendingOrder.expiryIndex {
+				if !pendingOrder.warmup {
+					result.LimitOrdersExpired++
+				}
+				pendingOrder = nil
+			}
+		}
+
+		blackout, _ := config.Blackout.Active(currentKline.OpenTime)
 
 		// Check if we should close an existing position
 		if currentPosition != nil {
-			shouldClose, reason := strategy.ShouldClosePosition(ctx, currentPosition, historicalKlines, currentKline.Close)
+			if !sessionEnded {
+				trackExcursion(currentPosition, currentKline)
+			}
+			if blackout {
+				if stop, ok := config.Blackout.TightenedStop(currentPosition, currentKline.Close); ok {
+					currentPosition.StopLoss = stop
+				}
+			}
+			exitPrice := currentKline.Close
+			marketExit := true // Take profits filled inside a bar rest as limit orders
+			var shouldClose bool
+			var reason domain.CloseReason
+			liquidationPrice, liquidated := liquidationFill(currentPosition, currentKline, maintenanceMarginRate)
+			stopPrice, stopReason, ambiguous, stopped := config.Intrabar.Exit(currentPosition, currentKline)
+			if pendingExit != "" {
+				// Signaled at the previous bar's close, filled at this bar's open
+				shouldClose, reason, exitPrice = true, pendingExit, openPrice(klines, i)
+				pendingExit = ""
+			} else if sessionEnded {
+				// Flattened at the bar's open, before it trades towards any stop
+				shouldClose, reason, exitPrice = true, domain.CloseReasonSessionEnd, currentKline.Open
+				if !positionInWarmup {
+					result.SessionEndExits++
+				}
+			} else if stopped && (!liquidated || stopPrice > liquidationPrice) {
+				// The level is reached before the liquidation price on the way down
+				shouldClose, reason, exitPrice = true, stopReason, stopPrice
+				marketExit = stopReason != domain.CloseReasonTakeProfit
+				if !positionInWarmup {
+					result.IntrabarExits++
+					if ambiguous {
+						result.IntrabarAmbiguous++
+					}
+				}
+			} else if liquidated {
+				shouldClose, reason, exitPrice, marketExit = true, domain.CloseReasonLiquidation, liquidationPrice, false
+			} else {
+				shouldClose, reason = strategy.ShouldClosePosition(ctx, currentPosition, historicalKlines, currentKline.Close)
+				if shouldClose && config.Timing == ExecutionNextOpen {
+					pendingExit, shouldClose = reason, false
+				}
+			}
+			if config.RecordStopPaths {
+				stopPath = append(stopPath, stopLevel(currentPosition, currentKline.OpenTime))
+			}
 			if shouldClose {
+				if marketExit {
+					exitPrice = config.Slipped(exitPrice, false)
+				}
+
 				// Calculate profit/loss
-				pnl := calculatePNL(currentPosition, currentKline.Close)
-				result.TotalProfit += pnl
-				result.FinalBalance += pnl
-
-				// Update trade statistics
-				if pnl > 0 {
-					result.WinningTrades++
-					result.AverageWin = (result.AverageWin*float64(result.WinningTrades-1) + pnl) / float64(result.WinningTrades)
-				} else {
-					result.LosingTrades++
-					result.AverageLoss = (result.AverageLoss*float64(result.LosingTrades-1) + pnl) / float64(result.LosingTrades)
+				pnl := calculatePNL(currentPosition, exitPrice, currentKline.OpenTime, fees)
+				if reason == domain.CloseReasonLiquidation {
+					pnl = liquidationPNL(currentPosition, currentKline.OpenTime, fees)
 				}
 
-				// Update max drawdown
-				if result.FinalBalance > peakBalance {
-					peakBalance = result.FinalBalance
+				// Record trade (with fee-adjusted PNL rather than the position's gross PNL)
+				if err := currentPosition.Close(exitPrice, currentKline.OpenTime, reason); err != nil {
+					return nil, fmt.Errorf("failed to close backtest position: %w", err)
 				}
-				drawdown := (peakBalance - result.FinalBalance) / peakBalance
-				if drawdown > result.MaxDrawdown {
-					result.MaxDrawdown = drawdown
+				if aware, ok := strategy.(ports.ExitAwareStrategy); ok {
+					aware.PositionClosed(ctx, currentPosition)
 				}
+				trade := currentPosition.Trade()
+				trade.PNL = pnl
+				trade.Regime = positionRegime
+
+				if positionInWarmup {
+					// Warm-up trades leave the balance and statistics untouched
+					result.WarmupProfit += pnl
+					result.WarmupTrades = append(result.WarmupTrades, trade)
+				} else {
+					result.TotalProfit += pnl
+					result.FinalBalance += pnl
+
+					// Update trade statistics
+					if pnl > 0 {
+						result.WinningTrades++
+						result.AverageWin = (result.AverageWin*float64(result.WinningTrades-1) + pnl) / float64(result.WinningTrades)
+					} else {
+						result.LosingTrades++
+						result.AverageLoss = (result.AverageLoss*float64(result.LosingTrades-1) + pnl) / float64(result.LosingTrades)
+					}
 
-				// Record trade
-				trade := &domain.Trade{
-					PositionID:  currentPosition.ID,
-					Symbol:      config.Symbol,
-					EntryPrice:  currentPosition.EntryPrice,
-					ExitPrice:   currentKline.Close,
-					Quantity:    currentPosition.Quantity,
-					Leverage:    currentPosition.Leverage,
-					PNL:         pnl,
-					EntryTime:   currentPosition.EntryTime,
-					ExitTime:    currentKline.OpenTime,
-					CloseReason: reason,
+					// Update max drawdown
+					if result.FinalBalance > peakBalance {
+						peakBalance = result.FinalBalance
+					}
+					drawdown := (peakBalance - result.FinalBalance) / peakBalance
+					if drawdown > result.MaxDrawdown {
+						result.MaxDrawdown = drawdown
+					}
+					if config.RiskManager != nil {
+						config.RiskManager.UpdateEquity(ctx, result.FinalBalance)
+					}
+					config.StreakSizer.Record(pnl)
+					trades = append(trades, trade)
+					if reason == domain.CloseReasonLiquidation {
+						result.Liquidations = append(result.Liquidations, trade)
+						result.LiquidationLoss += pnl
+					}
+					if config.RecordStopPaths {
+						result.StopPaths = append(result.StopPaths, stopPath)
+					}
 				}
-				trades = append(trades, trade)
+				event := TradeEvent{Trade: trade, Warmup: positionInWarmup, Balance: result.FinalBalance}
+				if err := config.TradeLog.Write(event); err != nil {
+					return nil, err
+				}
+				sendTradeEvent(ctx, config.TradeEvents, event)
 
 				currentPosition = nil
 			}
 		}
 
-		// Check if we should open a new position
-		if currentPosition == nil && strategy.ShouldEnterTrade(ctx, historicalKlines, currentKline.Close) {
-			currentPosition = &domain.Position{
-				Symbol:               config.Symbol,
-				EntryPrice:           currentKline.Close,
-				Quantity:             config.PositionSize,
-				Leverage:             config.Leverage,
-				StopLoss:             currentKline.Close * (1 - config.StopLoss),
-				TakeProfit:           currentKline.Close * (1 + config.TakeProfit),
-				EntryTime:            currentKline.OpenTime,
-				Status:               domain.StatusOpen,
-				TrailingStopPrice:    0, // Will be initialized when profit reaches threshold
-				TrailingStopDistance: 0, // Will be set when trailing stop is activated
-			}
-			result.TotalTrades++
+		// Add to the position at the scale-in levels, from the bar after the entry until an exit is signaled
+		if currentPosition != nil && !blackout && pendingExit == "" && currentPosition.EntryTime.Before(currentKline.OpenTime) &&
+			scaleIn(config, currentPosition, currentKline, result.FinalBalance) && !positionInWarmup {
+			result.ScaleIns++
+		}
+
+		// Hand over the open interest known at the bar's close
+		if usesOpenInterest {
+			for oiIndex < len(config.OpenInterest) && !config.OpenInterest[oiIndex].Time.After(currentKline.CloseTime) {
+				oiIndex++
+			}
+			if oiIndex > 0 {
+				oiStrategy.SetOpenInterest(config.OpenInterest[:oiIndex])
+			}
+		}
+
+		// Check if we should open a new position (skipped while a limit entry is resting)
+		enter := currentPosition == nil && pendingOrder == nil && config.Direction.Allows(domain.PositionSideLong) &&
+			strategy.ShouldEnterTrade(ctx, historicalKlines, currentKline.Close)
+		if enter && sessionEnded {
+			enter = false
+		}
+		if enter && blackout {
+			if !inWarmup {
+				result.BlackoutSkipped++
+			}
+			enter = false
+		}
+		if closed, _ := config.Calendar.Closed(currentKline.OpenTime); enter && closed {
+			if !inWarmup {
+				result.CalendarSkipped++
+			}
+			enter = false
+		}
+		if enter {
+			order := strategies.EntryOrder{Type: strategies.EntryOrderMarket}
+			if usesEntryOrders {
+				order = entryProvider.GetEntryOrder(ctx, historicalKlines, currentKline.Close)
+			}
+			var tag domain.EntryTag
+			if tagsEntries {
+				tag = tagger.LastEntryTag()
+			}
+			regime := analytics.DetectRegime(historicalKlines, config.Regime)
+
+			if order.Type == strategies.EntryOrderLimit && order.LimitPrice > 0 {
+				// Limit orders rest from the next bar onwards
+				bars := order.ExpiryBars
+				if bars <= 0 {
+					bars = expiryBars
+				}
+				pendingOrder = &pendingEntry{price: order.LimitPrice, expiryIndex: i + bars, tag: tag, regime: regime, warmup: inWarmup}
+				if !inWarmup {
+					result.LimitOrdersPlaced++
+				}
+			} else if config.Timing == ExecutionNextOpen {
+				// Market orders fill at the next bar's open
+				pendingOrder = &pendingEntry{market: true, expiryIndex: i + 1, tag: tag, regime: regime, warmup: inWarmup}
+			} else if pos, err := newPosition(config, config.Slipped(currentKline.Close, true), currentKline.OpenTime); err == nil && margin(pos) > result.FinalBalance {
+				if !inWarmup {
+					result.InsufficientMarginSkipped++
+				}
+			} else if err == nil && config.exceedsExposure(pos, result.FinalBalance) {
+				if !inWarmup {
+					result.MaxExposureSkipped++
+				}
+			} else if err == nil {
+				pos.EntryTag = tag
+				currentPosition = pos
+				positionRegime = regime
+				stopPath = nil
+				if config.RecordStopPaths {
+					stopPath = []StopLevel{stopLevel(pos, currentKline.OpenTime)}
+				}
+				positionInWarmup = inWarmup
+				if !inWarmup {
+					result.TotalTrades++
+				}
+			}
+		}
+
+		result.BarsProcessed++
+		if config.Progress != nil && config.ShouldReportProgress(result.BarsProcessed, totalBars) {
+			equity := result.FinalBalance
+			if currentPosition != nil && !positionInWarmup {
+				equity += calculatePNL(currentPosition, currentKline.Close, currentKline.OpenTime, fees)
+			}
+			config.Progress(Progress{
+				Bar:       result.BarsProcessed,
+				TotalBars: totalBars,
+				Time:      currentKline.OpenTime,
+				Balance:   result.FinalBalance,
+				Equity:    equity,
+				Trades:    len(trades),
+			})
 		}
 	}
 
-	// Calculate final statistics
-	result.WinRate = float64(result.WinningTrades) / float64(result.TotalTrades)
+	// A limit order still resting at the end of the data never filled
+	if pendingOrder != nil && !pendingOrder.warmup && !pendingOrder.market && !result.Aborted {
+		result.LimitOrdersExpired++
+	}
+
+	finalizeResult(result, trades, config.InitialFunds)
+	if result.Aborted {
+		return result, ctx.Err()
+	}
+	return result, nil
+}
+
+// finalizeResult computes the summary statistics from the counters and closed trades
+func finalizeResult(result *BacktestResult, trades []*domain.Trade, initialFunds float64) {
+	if result.TotalTrades > 0 {
+		result.WinRate = float64(result.WinningTrades) / float64(result.TotalTrades)
+	}
 	if result.AverageLoss != 0 {
 		result.ProfitFactor = result.AverageWin / -result.AverageLoss
 	}
-	result.ReturnOnInvestment = (result.FinalBalance - config.InitialFunds) / config.InitialFunds
+	result.ReturnOnInvestment = (result.FinalBalance - initialFunds) / initialFunds
 
-	// Calculate Sharpe Ratio (assuming risk-free rate of 0 for simplicity)
-	if len(trades) > 1 {
-		var returns []float64
-		for i := 1; i < len(trades); i++ {
-			returns = append(returns, trades[i].PNL/trades[i-1].PNL-1)
-		}
-		result.SharpeRatio = calculateSharpeRatio(returns)
-	}
+	// Calculate Sharpe Ratio (assuming risk-free rate of 0) from each trade's return on the balance before it
+	result.SharpeRatio = analytics.SharpeRatio(analytics.TradeReturns(trades, initialFunds))
 
 	result.Trades = trades
+}
 
-	return result, nil
+// sendTradeEvent delivers event on events, giving up if ctx is canceled. A nil channel is skipped
+func sendTradeEvent(ctx context.Context, events chan<- TradeEvent, event TradeEvent) {
+	if events == nil {
+		return
+	}
+	select {
+	case events <- event:
+	case <-ctx.Done():
+	}
 }
 
-// calculatePNL calculates the profit/loss for a position including trading fees
-func calculatePNL(position *domain.Position, currentPrice float64) float64 {
-	// Trading fee (0.1% for maker/taker on Binance futures)
-	const tradingFee = 0.001
+// newPosition opens a long position at the given entry price using the backtest's SL/TP settings.
+// It fails if the position is invalid, e.g. when the drawdown throttle or the trading calendar
+// reduces the size to zero.
+func newPosition(config BacktestConfig, entryPrice float64, entryTime time.Time) (*domain.Position, error) {
+	quantity := config.ScaleIn.InitialQuantity(config.PositionSize)
+	if config.RiskManager != nil {
+		quantity = config.RiskManager.ApplyThrottle(quantity)
+	}
+	quantity = config.StreakSizer.Apply(quantity)
+	quantity = config.Calendar.Apply(quantity, entryTime)
+	quantity = config.baseQuantity(quantity, entryPrice)
+	position := &domain.Position{
+		Symbol:               config.Symbol,
+		EntryPrice:           entryPrice,
+		Quantity:             quantity,
+		Leverage:             config.Leverage,
+		StopLoss:             entryPrice * (1 - config.StopLoss),
+		TakeProfit:           entryPrice * (1 + config.TakeProfit),
+		EntryTime:            entryTime,
+		TrailingStopPrice:    0, // Will be initialized when profit reaches threshold
+		TrailingStopDistance: 0, // Will be set when trailing stop is activated
+	}
+	if config.ScaleIn.Enabled() {
+		position.ScaleInBasePrice = entryPrice
+	}
+	if err := position.Open(); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
 
-	// Calculate raw PNL
-	rawPnl := (currentPrice - position.EntryPrice) * position.Quantity * float64(position.Leverage)
+// scaleIn fills the position's next scale-in add if the kline trades through its level, like a
+// resting limit order. The add is skipped when its margin would exceed balance; stops are kept.
+func scaleIn(config BacktestConfig, position *domain.Position, kline *domain.Kline, balance float64) bool {
+	level, ok := config.ScaleIn.NextAddPrice(position)
+	if !ok {
+		return false
+	}
+	fillPrice, filled := limitOrderFill(level, kline)
+	if !filled {
+		return false
+	}
+	quantity := config.ScaleIn.AddQuantity(config.PositionSize)
+	if config.RiskManager != nil {
+		quantity = config.RiskManager.ApplyThrottle(quantity)
+	}
+	quantity = config.StreakSizer.Apply(quantity)
+	quantity = config.Calendar.Apply(quantity, kline.OpenTime)
+	quantity = config.baseQuantity(quantity, fillPrice)
+	if quantity <= 0 || margin(position)+fillPrice*quantity > balance {
+		return false
+	}
+	return position.ScaleIn(quantity, fillPrice) == nil
+}
 
-	// Calculate fees (entry and exit)
-	entryFee := position.EntryPrice * position.Quantity * tradingFee
-	exitFee := currentPrice * position.Quantity * tradingFee
-	totalFees := (entryFee + exitFee) * float64(position.Leverage)
+// baseQuantity returns amount (PositionSize or a share of it) in the base asset at price,
+// converting it in quote mode
+func (c BacktestConfig) baseQuantity(amount, price float64) float64 {
+	if c.QuantityMode != domain.QuantityModeQuote {
+		return amount
+	}
+	precision := c.Precision
+	if !precision.TickSize.IsPositive() || !precision.StepSize.IsPositive() {
+		precision = money.DefaultPrecision()
+	}
+	return precision.QuoteToQuantity(amount, price)
+}
 
-	// Net PNL after fees
-	return rawPnl - totalFees
+// Slipped returns the price a market buy (or sell) at price fills at after Slippage
+func (c BacktestConfig) Slipped(price float64, buy bool) float64 {
+	if c.Slippage <= 0 {
+		return price
+	}
+	if buy {
+		return price * (1 + c.Slippage)
+	}
+	return price * (1 - c.Slippage)
 }
 
-// calculateSharpeRatio calculates the Sharpe ratio for a series of returns
-func calculateSharpeRatio(returns []float64) float64 {
-	if len(returns) < 2 {
-		return 0
+// exceedsExposure reports whether pos, the only open position, would exceed the risk manager's
+// maximum exposure of balance
+func (c BacktestConfig) exceedsExposure(pos *domain.Position, balance float64) bool {
+	return c.RiskManager != nil && c.RiskManager.CheckExposure(0, pos.Quantity*pos.EntryPrice, balance) != nil
+}
+
+// stopLevel snapshots the position's protective levels at t
+func stopLevel(position *domain.Position, t time.Time) StopLevel {
+	return StopLevel{Time: t, StopLoss: position.StopLoss, TakeProfit: position.TakeProfit, TrailingStop: position.TrailingStopPrice}
+}
+
+// margin returns the isolated margin a backtest position ties up: its entry price times quantity
+func margin(position *domain.Position) float64 {
+	return money.Notional(position.EntryPrice, position.Quantity)
+}
+
+// liquidationFill checks whether a long position is liquidated during a kline and returns its
+// liquidation price. On the bar a limit entry filled only the close counts, as in trackExcursion,
+// and so it does for klines without a low
+func liquidationFill(position *domain.Position, kline *domain.Kline, maintenanceMarginRate float64) (float64, bool) {
+	liquidationPrice := position.LiquidationPrice(maintenanceMarginRate)
+	low := kline.Low
+	if low <= 0 || position.EntryTime.Equal(kline.OpenTime) {
+		low = kline.Close
+	}
+	if liquidationPrice <= 0 || low > liquidationPrice {
+		return 0, false
 	}
+	return liquidationPrice, true
+}
+
+// liquidationPNL returns the PNL of a liquidated position: its whole margin is lost (the
+// maintenance margin left at the liquidation price goes to the exchange), together with the entry
+// fee and the funding paid while it was open
+func liquidationPNL(position *domain.Position, exitTime time.Time, fees domain.FeeModel) float64 {
+	costs := fees.Fees(position.EntryPrice, 0, position.Quantity) +
+		fees.Funding(position.EntryPrice, position.Quantity, exitTime.Sub(position.EntryTime))
+	return -margin(position) - costs*float64(position.Leverage)
+}
 
-	// Calculate mean return
-	var sum float64
-	for _, r := range returns {
-		sum += r
+// trackExcursion updates the position's MAE and MFE with the kline's range. On the bar a limit
+// entry filled only the close counts, since the fill's place within the bar is unknown
+func trackExcursion(position *domain.Position, kline *domain.Kline) {
+	if position.EntryTime.Equal(kline.OpenTime) {
+		position.TrackExcursion(kline.Close)
+		return
 	}
-	mean := sum / float64(len(returns))
+	position.TrackExcursion(kline.Low, kline.High, kline.Close)
+}
 
-	// Calculate standard deviation
-	var variance float64
-	for _, r := range returns {
-		variance += (r - mean) * (r - mean)
+// openPrice returns the open of klines[i], or the previous bar's close for klines without an open
+func openPrice(klines []*domain.Kline, i int) float64 {
+	if klines[i].Open > 0 || i == 0 {
+		return klines[i].Open
 	}
-	variance /= float64(len(returns) - 1)
-	stdDev := variance
+	return klines[i-1].Close
+}
 
-	// Calculate Sharpe ratio (assuming risk-free rate of 0)
-	if stdDev == 0 {
-		return 0
+// limitOrderFill checks whether a buy limit order fills during a kline.
+// The order only fills if price trades through the limit (low strictly below it); touching the
+// level is not enough since queue position is unknown. A bar that opens below the limit fills at the open.
+func limitOrderFill(limitPrice float64, kline *domain.Kline) (float64, bool) {
+	if kline.Low >= limitPrice {
+		return 0, false
+	}
+	if kline.Open > 0 && kline.Open < limitPrice {
+		return kline.Open, true
 	}
-	return mean / stdDev
+	return limitPrice, true
+}
+
+// calculatePNL calculates the profit/loss for a position closed at exitTime including trading fees and funding
+func calculatePNL(position *domain.Position, currentPrice float64, exitTime time.Time, fees domain.FeeModel) float64 {
+	// Calculate raw PNL
+	rawPnl := money.PnL(position.EntryPrice, currentPrice, position.Quantity, false) * float64(position.Leverage)
+
+	// Calculate fees (entry and exit) and funding accrued while holding
+	costs := fees.Fees(position.EntryPrice, currentPrice, position.Quantity) +
+		fees.Funding(position.EntryPrice, position.Quantity, exitTime.Sub(position.EntryTime))
+	totalFees := costs * float64(position.Leverage)
+
+	// Net PNL after fees
+	return rawPnl - totalFees
 }
@@ -3,6 +3,10 @@ package backtesting
 import (
 	"context"
 	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/risk"
+	"cryptoMegaBot/internal/strategy/analytics"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"math"
 	"testing"
 	"time"
 )
@@ -30,6 +34,29 @@ func (m *MockStrategy) ShouldClosePosition(ctx context.Context, position *domain
 	return m.shouldClose, m.closeReason
 }
 
+func (m *MockStrategy) GetPositionSize(ctx context.Context, klines []*domain.Kline, availableFunds float64) float64 {
+	return 0.1 // Return a default value for testing
+}
+
+func (m *MockStrategy) GetATR(ctx context.Context, klines []*domain.Kline) (float64, error) {
+	return 1.0, nil // Return a default value for testing
+}
+
+// MockLimitStrategy enters with a limit order at a fixed price
+type MockLimitStrategy struct {
+	MockStrategy
+	limitPrice float64
+	expiryBars int
+}
+
+func (m *MockLimitStrategy) GetEntryOrder(ctx context.Context, klines []*domain.Kline, currentPrice float64) strategies.EntryOrder {
+	return strategies.EntryOrder{
+		Type:       strategies.EntryOrderLimit,
+		LimitPrice: m.limitPrice,
+		ExpiryBars: m.expiryBars,
+	}
+}
+
 func TestBacktest(t *testing.T) {
 	// Create test data
 	now := time.Now()
@@ -62,6 +89,7 @@ func TestBacktest(t *testing.T) {
 				TakeProfit:   0.02,
 				Symbol:       "BTCUSDT",
 				Leverage:     1,
+				Timing:       ExecutionSignalClose,
 			},
 			expectedTrades: 1,
 			expectedError:  false,
@@ -110,6 +138,9 @@ func TestBacktest(t *testing.T) {
 			if result.TotalTrades != tt.expectedTrades {
 				t.Errorf("Expected %d trades, got %d", tt.expectedTrades, result.TotalTrades)
 			}
+			if result.Seed == 0 {
+				t.Error("Expected the run's seed to be recorded in the result")
+			}
 		})
 	}
 }
@@ -129,7 +160,7 @@ func TestCalculatePNL(t *testing.T) {
 				Leverage:   2,
 			},
 			currentPrice: 110.0,
-			expectedPNL:  20.0, // (110 - 100) * 1 * 2
+			expectedPNL:  19.58, // (110 - 100) * 1 * 2 - (100 + 110) * 0.001 * 2
 		},
 		{
 			name: "Losing long position",
@@ -139,7 +170,7 @@ func TestCalculatePNL(t *testing.T) {
 				Leverage:   2,
 			},
 			currentPrice: 90.0,
-			expectedPNL:  -20.0, // (90 - 100) * 1 * 2
+			expectedPNL:  -20.38, // (90 - 100) * 1 * 2 - (100 + 90) * 0.001 * 2
 		},
 		{
 			name: "Zero PNL",
@@ -149,60 +180,823 @@ func TestCalculatePNL(t *testing.T) {
 				Leverage:   2,
 			},
 			currentPrice: 100.0,
-			expectedPNL:  0.0,
+			expectedPNL:  -0.4, // Fees only
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pnl := calculatePNL(tt.position, tt.currentPrice)
-			if pnl != tt.expectedPNL {
+			pnl := calculatePNL(tt.position, tt.currentPrice, tt.position.EntryTime, defaultFees)
+			if math.Abs(pnl-tt.expectedPNL) > 1e-9 {
 				t.Errorf("Expected PNL %f, got %f", tt.expectedPNL, pnl)
 			}
 		})
 	}
+
+	// Funding is charged for each completed interval the position was held
+	entryTime := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	position := &domain.Position{EntryPrice: 100.0, Quantity: 1.0, Leverage: 2, EntryTime: entryTime}
+	fees := domain.FeeModel{TakerRate: 0.0004, FundingRate: 0.0001}
+	pnl := calculatePNL(position, 110.0, entryTime.Add(17*time.Hour), fees)
+	expected := 19.792 // 20 - ((100 + 110) * 0.0004 + 2 * 100 * 0.0001) * 2
+	if math.Abs(pnl-expected) > 1e-9 {
+		t.Errorf("Expected PNL %f with funding, got %f", expected, pnl)
+	}
 }
 
-func TestCalculateSharpeRatio(t *testing.T) {
+func TestBacktestLimitEntries(t *testing.T) {
+	now := time.Now()
+	kline := func(offset int, open, high, low, close float64) *domain.Kline {
+		return &domain.Kline{
+			OpenTime: now.Add(time.Duration(offset) * time.Hour),
+			Open:     open, High: high, Low: low, Close: close,
+		}
+	}
+	config := BacktestConfig{
+		InitialFunds: 1000.0,
+		PositionSize: 1.0,
+		StopLoss:     0.02,
+		TakeProfit:   0.02,
+		Symbol:       "BTCUSDT",
+		Leverage:     1,
+		Timing:       ExecutionSignalClose, // Fills close on their own bar so the entry price is recorded
+	}
+
 	tests := []struct {
-		name          string
-		returns       []float64
-		expectedRatio float64
+		name            string
+		klines          []*domain.Kline
+		limitPrice      float64
+		expiryBars      int
+		expectedFilled  int
+		expectedExpired int
+		expectedEntry   float64
 	}{
 		{
-			name:          "Positive returns",
-			returns:       []float64{0.1, 0.2, 0.15},
-			expectedRatio: 60.0, // mean: 0.15, std dev: 0.0025
-		},
-		{
-			name:          "Negative returns",
-			returns:       []float64{-0.1, -0.2, -0.15},
-			expectedRatio: -60.0, // mean: -0.15, std dev: 0.0025
+			name: "fills when price trades through the limit",
+			klines: []*domain.Kline{
+				kline(0, 100, 101, 99, 100),
+				kline(1, 100, 101, 99, 100),
+				kline(2, 100, 101, 99, 100), // Signal bar
+				kline(3, 100, 100, 97, 98),  // Low below 98.5
+			},
+			limitPrice:     98.5,
+			expiryBars:     2,
+			expectedFilled: 1,
+			expectedEntry:  98.5,
 		},
 		{
-			name:          "Mixed returns",
-			returns:       []float64{-0.1, 0.2, 0.0},
-			expectedRatio: 1.428571, // mean: 0.033, std dev: 0.023
+			name: "touching the limit does not fill",
+			klines: []*domain.Kline{
+				kline(0, 100, 101, 99, 100),
+				kline(1, 100, 101, 99, 100),
+				kline(2, 100, 101, 99, 100),  // Signal bar
+				kline(3, 100, 100, 98.5, 99), // Low equals limit
+			},
+			limitPrice:      98.5,
+			expiryBars:      1,
+			expectedExpired: 1,
 		},
 		{
-			name:          "Single return",
-			returns:       []float64{0.1},
-			expectedRatio: 0,
+			name: "gap below the limit fills at the open",
+			klines: []*domain.Kline{
+				kline(0, 100, 101, 99, 100),
+				kline(1, 100, 101, 99, 100),
+				kline(2, 100, 101, 99, 100), // Signal bar
+				kline(3, 97, 98, 96, 97),
+			},
+			limitPrice:     98.5,
+			expiryBars:     1,
+			expectedFilled: 1,
+			expectedEntry:  97,
 		},
 		{
-			name:          "Empty returns",
-			returns:       []float64{},
-			expectedRatio: 0,
+			name: "expires after the configured number of bars",
+			klines: []*domain.Kline{
+				kline(0, 100, 101, 99, 100),
+				kline(1, 100, 101, 99, 100),
+				kline(2, 100, 101, 99, 100), // Signal bar
+				kline(3, 100, 101, 99, 100),
+				kline(4, 100, 101, 90, 95), // Would fill, but the order expired
+			},
+			limitPrice:      98.5,
+			expiryBars:      1,
+			expectedFilled:  0,
+			expectedExpired: 1,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ratio := calculateSharpeRatio(tt.returns)
-			// Allow for small floating point differences
-			if ratio-tt.expectedRatio > 0.0001 || ratio-tt.expectedRatio < -0.0001 {
-				t.Errorf("Expected Sharpe ratio %f, got %f", tt.expectedRatio, ratio)
+			strategy := &MockLimitStrategy{
+				MockStrategy: MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket},
+				limitPrice:   tt.limitPrice,
+				expiryBars:   tt.expiryBars,
+			}
+			// Only place one order; filled positions close on the fill bar so the entry price is recorded
+			result, err := Backtest(context.Background(), &onceEntryStrategy{MockLimitStrategy: strategy}, tt.klines, config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if result.LimitOrdersPlaced != 1 {
+				t.Errorf("Expected 1 limit order placed, got %d", result.LimitOrdersPlaced)
+			}
+			if result.LimitOrdersFilled != tt.expectedFilled {
+				t.Errorf("Expected %d limit orders filled, got %d", tt.expectedFilled, result.LimitOrdersFilled)
+			}
+			if result.LimitOrdersExpired != tt.expectedExpired {
+				t.Errorf("Expected %d limit orders expired, got %d", tt.expectedExpired, result.LimitOrdersExpired)
+			}
+			if result.TotalTrades != tt.expectedFilled {
+				t.Errorf("Expected %d trades, got %d", tt.expectedFilled, result.TotalTrades)
+			}
+			if tt.expectedFilled > 0 && result.Trades[0].EntryPrice != tt.expectedEntry {
+				t.Errorf("Expected entry price %f, got %f", tt.expectedEntry, result.Trades[0].EntryPrice)
 			}
 		})
 	}
 }
+
+func TestBacktestDrawdownThrottle(t *testing.T) {
+	now := time.Now()
+	klines := []*domain.Kline{
+		{OpenTime: now.Add(-4 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-3 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-2 * time.Hour), Close: 100.0}, // First entry
+		{OpenTime: now.Add(-1 * time.Hour), Close: 85.0},  // Loss of ~15% of funds, re-entry
+		{OpenTime: now, Close: 85.0},
+	}
+	config := BacktestConfig{
+		Timing:       ExecutionSignalClose,
+		InitialFunds: 100.0,
+		PositionSize: 1.0,
+		StopLoss:     0.2,
+		TakeProfit:   0.2,
+		Symbol:       "BTCUSDT",
+		Leverage:     1,
+		RiskManager:  risk.NewRiskManager(risk.RiskConfig{DrawdownThrottle: risk.DefaultDrawdownThrottle}),
+	}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) < 2 {
+		t.Fatalf("Expected at least 2 trades, got %d", len(result.Trades))
+	}
+	if result.Trades[0].Quantity != 1.0 {
+		t.Errorf("Expected full size before drawdown, got %f", result.Trades[0].Quantity)
+	}
+	if result.Trades[1].Quantity != 0.25 {
+		t.Errorf("Expected quarter size after 15%% drawdown, got %f", result.Trades[1].Quantity)
+	}
+}
+
+func TestBacktestStreakSizing(t *testing.T) {
+	now := time.Now()
+	closes := []float64{100, 100, 100, 110, 100, 105}
+	klines := make([]*domain.Kline, len(closes))
+	for i, close := range closes {
+		klines[i] = &domain.Kline{OpenTime: now.Add(time.Duration(i-len(closes)) * time.Hour), Close: close}
+	}
+	ladder, err := risk.ParseStreakLadder("1W:2,1L:0.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizer := risk.NewStreakSizer(ladder)
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 1.0, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "BTCUSDT", Leverage: 1, StreakSizer: sizer, Timing: ExecutionSignalClose}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// A win doubles the next entry, the following loss halves the one after it
+	expected := []float64{1, 2, 0.5}
+	if len(result.Trades) != len(expected) {
+		t.Fatalf("Expected %d trades, got %d", len(expected), len(result.Trades))
+	}
+	for i, quantity := range expected {
+		if result.Trades[i].Quantity != quantity {
+			t.Errorf("Trade %d: expected quantity %v, got %v", i, quantity, result.Trades[i].Quantity)
+		}
+	}
+	if sizer.Streak() != 1 {
+		t.Errorf("Expected the run to end on a win, got streak %d", sizer.Streak())
+	}
+}
+
+// stopRecordingStrategy holds its position and records the stop it sees on every bar
+type stopRecordingStrategy struct {
+	MockStrategy
+	stops []float64
+}
+
+func (m *stopRecordingStrategy) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	m.stops = append(m.stops, position.StopLoss)
+	return false, ""
+}
+
+func TestBacktestBlackout(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 6)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Close: 100.0}
+	}
+	blackout := &risk.BlackoutSchedule{
+		TightenStop: 0.01,
+		Events:      []risk.BlackoutEvent{{Name: "CPI", At: klines[4].OpenTime, After: time.Hour}},
+	}
+	if err := blackout.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 1.0, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "BTCUSDT", Leverage: 1, Blackout: blackout, Timing: ExecutionSignalClose}
+
+	// Entries on bars 2, 3 and 5; the one on bar 4 falls in the blackout
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.BlackoutSkipped != 1 || result.TotalTrades != 3 {
+		t.Errorf("Expected 1 entry skipped and 3 taken, got %d skipped and %d taken", result.BlackoutSkipped, result.TotalTrades)
+	}
+
+	// The stop of the position entered on bar 2 is only tightened during the blackout
+	holder := &stopRecordingStrategy{MockStrategy: MockStrategy{shouldEnter: true}}
+	if _, err := Backtest(context.Background(), holder, klines, config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []float64{80, 99, 99}
+	if len(holder.stops) != len(expected) {
+		t.Fatalf("Expected %d exit checks, got %v", len(expected), holder.stops)
+	}
+	for i, stop := range expected {
+		if math.Abs(holder.stops[i]-stop) > 1e-9 {
+			t.Errorf("Exit check %d: expected stop %f, got %f", i, stop, holder.stops[i])
+		}
+	}
+}
+
+func TestBacktestSessionEnd(t *testing.T) {
+	start := time.Date(2025, 6, 11, 18, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 8)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: 100.0, High: 101.0, Low: 99.0, Close: 100.0}
+	}
+	klines[3].Open = 100.5 // 21:00, the session end
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 1.0, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "BTCUSDT", Leverage: 1, SessionEnd: 21 * time.Hour, Timing: ExecutionSignalClose}
+
+	// Enters at 20:00, is flattened at the 21:00 open and re-enters after midnight
+	strategy := &MockStrategy{shouldEnter: true}
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalTrades != 2 || result.SessionEndExits != 1 || len(result.Trades) != 1 {
+		t.Fatalf("Expected 2 entries and 1 session end exit, got %d entries, %d session end exits and %d trades",
+			result.TotalTrades, result.SessionEndExits, len(result.Trades))
+	}
+	trade := result.Trades[0]
+	if trade.CloseReason != domain.CloseReasonSessionEnd || trade.ExitPrice != 100.5 || !trade.ExitTime.Equal(klines[3].OpenTime) {
+		t.Errorf("Expected a SESSION_END exit at 100.5 at %s, got %s at %f at %s", klines[3].OpenTime, trade.CloseReason, trade.ExitPrice, trade.ExitTime)
+	}
+
+	// A limit entry resting at the session end is canceled rather than filled
+	limit := &MockLimitStrategy{MockStrategy: MockStrategy{shouldEnter: true}, limitPrice: 99.5, expiryBars: 10}
+	result, err = Backtest(context.Background(), limit, klines[:6], config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.LimitOrdersFilled != 0 || result.LimitOrdersExpired != 1 {
+		t.Errorf("Expected the limit entry to be canceled, got %d filled and %d expired", result.LimitOrdersFilled, result.LimitOrdersExpired)
+	}
+}
+
+func TestBacktestDirection(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 6)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Close: 100.0}
+	}
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 1.0, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "BTCUSDT", Leverage: 1, Timing: ExecutionSignalClose}
+
+	for direction, expected := range map[domain.TradeDirection]int{"": 4, domain.TradeDirectionLong: 4, domain.TradeDirectionShort: 0} {
+		config.Direction = direction
+		strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+		result, err := Backtest(context.Background(), strategy, klines, config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.TotalTrades != expected {
+			t.Errorf("Direction %q: expected %d trades, got %d", direction, expected, result.TotalTrades)
+		}
+	}
+}
+
+func TestBacktestTradingCalendar(t *testing.T) {
+	start := time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC) // Thursday
+	klines := make([]*domain.Kline, 6)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * 24 * time.Hour), Close: 100.0}
+	}
+	calendar, err := risk.ParseTradingCalendar("SAT:0.5,SUN:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 1.0, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "BTCUSDT", Leverage: 1, Calendar: calendar, Timing: ExecutionSignalClose}
+
+	// Entries on Saturday at half size, Monday and Tuesday; the one on Sunday is skipped
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.CalendarSkipped != 1 {
+		t.Errorf("Expected 1 entry skipped on Sunday, got %d", result.CalendarSkipped)
+	}
+	for _, trade := range result.Trades {
+		expected := 1.0
+		switch trade.EntryTime.Weekday() {
+		case time.Saturday:
+			expected = 0.5
+		case time.Sunday:
+			t.Errorf("Unexpected entry on Sunday %v", trade.EntryTime)
+		}
+		if trade.Quantity != expected {
+			t.Errorf("Entry on %s: expected quantity %v, got %v", trade.EntryTime.Weekday(), expected, trade.Quantity)
+		}
+	}
+	if result.TotalTrades != 3 {
+		t.Errorf("Expected 3 trades, got %d", result.TotalTrades)
+	}
+}
+
+func TestBacktestLiquidation(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 6)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: 100, High: 101, Low: 99, Close: 100}
+	}
+	klines[3].Low = 85 // Wick through the 10x long's liquidation price
+	noFees := domain.FeeModel{TakerRate: 1e-12}
+	config := BacktestConfig{InitialFunds: 1000, PositionSize: 1, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "ETHUSDT", Leverage: 10, Fees: noFees, Timing: ExecutionSignalClose}
+
+	// Entered on bar 2 and held; liquidated on bar 3, then entered again on bar 3 and held to the end
+	result, err := Backtest(context.Background(), &MockStrategy{shouldEnter: true}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Liquidations) != 1 {
+		t.Fatalf("Expected 1 liquidation, got %d", len(result.Liquidations))
+	}
+	liquidation := result.Liquidations[0]
+	if liquidation.CloseReason != domain.CloseReasonLiquidation || !liquidation.ExitTime.Equal(klines[3].OpenTime) {
+		t.Errorf("Expected a liquidation at bar 3, got %s at %v", liquidation.CloseReason, liquidation.ExitTime)
+	}
+	if expected := 100 * 0.9 / (1 - defaultMaintenanceMarginRate); math.Abs(liquidation.ExitPrice-expected) > 1e-9 {
+		t.Errorf("Expected liquidation price %f, got %f", expected, liquidation.ExitPrice)
+	}
+
+	// The whole margin (entry price times quantity) is lost, never more
+	if math.Abs(result.LiquidationLoss+100) > 1e-6 || math.Abs(result.FinalBalance-900) > 1e-6 {
+		t.Errorf("Expected a loss of 100 and a balance of 900, got %f and %f", result.LiquidationLoss, result.FinalBalance)
+	}
+
+	// Without leverage the same wick is just a drawdown
+	config.Leverage = 1
+	result, err = Backtest(context.Background(), &MockStrategy{shouldEnter: true}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Liquidations) != 0 {
+		t.Errorf("Expected no liquidations without leverage, got %d", len(result.Liquidations))
+	}
+}
+
+func TestBacktestInsufficientMargin(t *testing.T) {
+	now := time.Now()
+	klines := []*domain.Kline{
+		{OpenTime: now.Add(-3 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-2 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-1 * time.Hour), Close: 100.0},
+		{OpenTime: now, Close: 100.0},
+	}
+	config := BacktestConfig{InitialFunds: 50, PositionSize: 1, StopLoss: 0.1, TakeProfit: 0.1, Symbol: "ETHUSDT", Leverage: 10, Timing: ExecutionSignalClose}
+	result, err := Backtest(context.Background(), &MockStrategy{shouldEnter: true}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalTrades != 0 || result.InsufficientMarginSkipped != 2 {
+		t.Errorf("Expected both entries skipped for a margin of 100 with a balance of 50, got %d trades and %d skipped",
+			result.TotalTrades, result.InsufficientMarginSkipped)
+	}
+}
+
+func TestBacktestMaxExposure(t *testing.T) {
+	now := time.Now()
+	klines := []*domain.Kline{
+		{OpenTime: now.Add(-3 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-2 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-1 * time.Hour), Close: 100.0},
+		{OpenTime: now, Close: 100.0},
+	}
+	// 60 notional is 60% of the balance, above the 50% limit
+	config := BacktestConfig{
+		Timing:       ExecutionSignalClose,
+		InitialFunds: 100, PositionSize: 0.6, StopLoss: 0.1, TakeProfit: 0.1, Symbol: "ETHUSDT", Leverage: 10,
+		RiskManager: risk.NewRiskManager(risk.RiskConfig{MaxExposurePct: 0.5}),
+	}
+	result, err := Backtest(context.Background(), &MockStrategy{shouldEnter: true}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalTrades != 0 || result.MaxExposureSkipped != 2 {
+		t.Errorf("Expected both entries skipped at the exposure limit, got %d trades and %d skipped",
+			result.TotalTrades, result.MaxExposureSkipped)
+	}
+
+	config.PositionSize = 0.5
+	result, err = Backtest(context.Background(), &MockStrategy{shouldEnter: true}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalTrades != 1 || result.MaxExposureSkipped != 0 {
+		t.Errorf("Expected an entry at 50%% to be taken, got %d trades and %d skipped", result.TotalTrades, result.MaxExposureSkipped)
+	}
+}
+
+func TestBacktestQuoteQuantity(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	closes := []float64{300, 300, 300, 330}
+	klines := make([]*domain.Kline, len(closes))
+	for i, price := range closes {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: price, High: price, Low: price, Close: price}
+	}
+	config := BacktestConfig{
+		Timing:       ExecutionSignalClose,
+		InitialFunds: 1000, PositionSize: 100, QuantityMode: domain.QuantityModeQuote,
+		StopLoss: 0.1, TakeProfit: 0.5, Symbol: "ETHUSDT", Leverage: 1,
+	}
+	result, err := Backtest(context.Background(), &closeAboveStrategy{MockStrategy: MockStrategy{shouldEnter: true}, closeAbove: 330}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(result.Trades))
+	}
+	// 100 USDT at 300 is 0.3333..., rounded down to the 0.001 step
+	if got := result.Trades[0].Quantity; got != 0.333 {
+		t.Errorf("Expected a quantity of 0.333, got %v", got)
+	}
+}
+
+func TestBacktestSlippage(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	closes := []float64{100, 100, 100, 110}
+	klines := make([]*domain.Kline, len(closes))
+	for i, price := range closes {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: price, High: price, Low: price, Close: price}
+	}
+	config := BacktestConfig{
+		Timing:       ExecutionSignalClose,
+		InitialFunds: 1000, PositionSize: 1, StopLoss: 0.5, TakeProfit: 0.5, Symbol: "ETHUSDT", Leverage: 1,
+		Fees: domain.FeeModel{FundingInterval: domain.DefaultFundingInterval}, Slippage: 0.01,
+	}
+	result, err := Backtest(context.Background(), &closeAboveStrategy{MockStrategy: MockStrategy{shouldEnter: true}, closeAbove: 110}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(result.Trades))
+	}
+	// Bought 1% above the close, sold 1% below it
+	trade := result.Trades[0]
+	if trade.EntryPrice != 101 || trade.ExitPrice != 108.9 || math.Abs(trade.PNL-7.9) > 1e-9 {
+		t.Errorf("Expected entry 101, exit 108.9 and PNL 7.9, got %v, %v and %v", trade.EntryPrice, trade.ExitPrice, trade.PNL)
+	}
+}
+
+// closeAboveStrategy closes positions once the price reaches closeAbove
+type closeAboveStrategy struct {
+	MockStrategy
+	closeAbove float64
+}
+
+func (m *closeAboveStrategy) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	return currentPrice >= m.closeAbove, domain.CloseReasonTakeProfit
+}
+
+func TestBacktestScaleIn(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	bars := []struct{ open, low, close float64 }{
+		{100, 100, 100},
+		{100, 100, 100},
+		{100, 100, 100},    // Entry at 100
+		{100, 99.5, 99.8},  // Trades through the first level (99.7)
+		{99.8, 99.5, 99.6}, // Second level (99.4) not reached
+		{99.2, 99, 99.5},   // Opens below the second level and fills at the open
+		{99.5, 99.5, 101},  // Closed
+	}
+	klines := make([]*domain.Kline, len(bars))
+	for i, bar := range bars {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: bar.open, High: bar.close, Low: bar.low, Close: bar.close}
+	}
+	config := BacktestConfig{
+		Timing:       ExecutionSignalClose,
+		InitialFunds: 1000, PositionSize: 1, StopLoss: 0.1, TakeProfit: 0.1, Symbol: "ETHUSDT", Leverage: 1,
+		ScaleIn: domain.ScaleInPlan{InitialFraction: 0.5, Steps: []float64{0.003, 0.006}},
+	}
+	result, err := Backtest(context.Background(), &closeAboveStrategy{MockStrategy: MockStrategy{shouldEnter: true}, closeAbove: 101}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ScaleIns != 2 || len(result.Trades) != 1 {
+		t.Fatalf("Expected 2 adds to 1 trade, got %d adds and %d trades", result.ScaleIns, len(result.Trades))
+	}
+	trade := result.Trades[0]
+	if expected := (100*0.5 + 99.7*0.25 + 99.2*0.25); math.Abs(trade.EntryPrice-expected) > 1e-9 || math.Abs(trade.Quantity-1) > 1e-9 {
+		t.Errorf("Expected a blended entry of %f for the full size, got %f for %f", expected, trade.EntryPrice, trade.Quantity)
+	}
+
+	// Adds that don't fit the balance are skipped
+	config.InitialFunds = 60
+	result, err = Backtest(context.Background(), &closeAboveStrategy{MockStrategy: MockStrategy{shouldEnter: true}, closeAbove: 101}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ScaleIns != 0 || math.Abs(result.Trades[0].Quantity-0.5) > 1e-9 {
+		t.Errorf("Expected no adds with a balance of 60, got %d", result.ScaleIns)
+	}
+}
+
+// taggingStrategy tags every entry it signals
+type taggingStrategy struct {
+	MockStrategy
+	tag domain.EntryTag
+}
+
+func (m *taggingStrategy) LastEntryTag() domain.EntryTag {
+	return m.tag
+}
+
+func TestBacktestEntryTags(t *testing.T) {
+	now := time.Now()
+	klines := []*domain.Kline{
+		{OpenTime: now.Add(-3 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-2 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-1 * time.Hour), Close: 101.0},
+		{OpenTime: now, Close: 102.0},
+	}
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 0.1, StopLoss: 0.1, TakeProfit: 0.1, Symbol: "BTCUSDT", Leverage: 1, Timing: ExecutionSignalClose}
+	tag := domain.EntryTag{EntryReason: "pullback entry", SignalSource: domain.SignalSourcePullback, ConfirmationCount: 3, EntryATR: 1.5}
+	strategy := &taggingStrategy{
+		MockStrategy: MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonTrailingStop},
+		tag:          tag,
+	}
+
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) == 0 {
+		t.Fatal("Expected at least one trade")
+	}
+	for i, trade := range result.Trades {
+		if trade.EntryTag != tag {
+			t.Errorf("Trade %d: expected entry tag %+v, got %+v", i, tag, trade.EntryTag)
+		}
+		if trade.CloseReason != domain.CloseReasonTrailingStop {
+			t.Errorf("Trade %d: expected close reason %s, got %s", i, domain.CloseReasonTrailingStop, trade.CloseReason)
+		}
+	}
+}
+
+func TestBacktestRegimes(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 6)
+	for i := range klines {
+		price := 100 + float64(i)
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: price, High: price + 0.5, Low: price - 0.5, Close: price}
+	}
+	config := BacktestConfig{
+		InitialFunds: 1000, PositionSize: 1, StopLoss: 0.5, TakeProfit: 0.5, Symbol: "ETHUSDT", Leverage: 1,
+		Regime: analytics.RegimeConfig{Lookback: 2, VolatilityLookback: 3, HigherTFBars: 3},
+	}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) == 0 {
+		t.Fatal("Expected at least one trade")
+	}
+	// The first entry, on the third bar, has too little history for the volatility bucket
+	want := domain.MarketRegime{Trend: domain.TrendRegimeTrending, HigherTF: domain.TrendDirectionUp}
+	if got := result.Trades[0].Regime; got != want {
+		t.Errorf("Expected regime %v, got %v", want, got)
+	}
+	for i, trade := range result.Trades[1:] {
+		if trade.Regime.Volatility != domain.VolatilityRegimeNormal {
+			t.Errorf("Trade %d: expected normal volatility, got %v", i+1, trade.Regime)
+		}
+	}
+}
+
+func TestBacktestWarmup(t *testing.T) {
+	now := time.Now()
+	klines := []*domain.Kline{
+		{OpenTime: now.Add(-5 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-4 * time.Hour), Close: 100.0},
+		{OpenTime: now.Add(-3 * time.Hour), Close: 100.0}, // Warm-up entry
+		{OpenTime: now.Add(-2 * time.Hour), Close: 90.0},  // Warm-up loss, warm-up re-entry
+		{OpenTime: now.Add(-1 * time.Hour), Close: 100.0}, // Warm-up gain, first counted entry
+		{OpenTime: now, Close: 110.0},                     // Counted gain, entry left open
+	}
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 1.0, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "BTCUSDT", Leverage: 1, WarmupBars: 2, Timing: ExecutionSignalClose}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.WarmupTrades) != 2 {
+		t.Fatalf("Expected 2 warm-up trades, got %d", len(result.WarmupTrades))
+	}
+	if !result.WarmupEnd.Equal(klines[4].OpenTime) {
+		t.Errorf("Expected warm-up to end at %v, got %v", klines[4].OpenTime, result.WarmupEnd)
+	}
+	warmupPnL := result.WarmupTrades[0].PNL + result.WarmupTrades[1].PNL
+	if math.Abs(result.WarmupProfit-warmupPnL) > 1e-9 {
+		t.Errorf("Expected warm-up profit %f, got %f", warmupPnL, result.WarmupProfit)
+	}
+	if result.TotalTrades != 2 || len(result.Trades) != 1 || result.LosingTrades != 0 {
+		t.Errorf("Expected only post warm-up trades to be counted, got %d trades (%d closed, %d losing)",
+			result.TotalTrades, len(result.Trades), result.LosingTrades)
+	}
+	if result.MaxDrawdown != 0 {
+		t.Errorf("Expected the warm-up loss to be excluded from drawdown, got %f", result.MaxDrawdown)
+	}
+	if math.Abs(result.FinalBalance-(1000.0+result.Trades[0].PNL)) > 1e-9 {
+		t.Errorf("Expected balance to only include counted trades, got %f", result.FinalBalance)
+	}
+
+	config.WarmupBars = len(klines)
+	if _, err := Backtest(context.Background(), strategy, klines, config); err == nil {
+		t.Error("Expected an error when the warm-up leaves no bars")
+	}
+}
+
+func TestBacktestProgressAndTradeEvents(t *testing.T) {
+	now := time.Now()
+	klines := make([]*domain.Kline, 12)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: now.Add(time.Duration(i) * time.Hour), Close: 100.0 + float64(i)}
+	}
+	events := make(chan TradeEvent, len(klines))
+	var updates []Progress
+	config := BacktestConfig{
+		Timing:           ExecutionSignalClose,
+		InitialFunds:     1000.0,
+		PositionSize:     1.0,
+		StopLoss:         0.2,
+		TakeProfit:       0.2,
+		Symbol:           "BTCUSDT",
+		Leverage:         1,
+		Progress:         func(p Progress) { updates = append(updates, p) },
+		ProgressInterval: 3,
+		TradeEvents:      events,
+	}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	close(events)
+
+	// 10 bars after the 2 required data points: updates after bars 3, 6, 9 and the last one
+	if len(updates) != 4 {
+		t.Fatalf("Expected 4 progress updates, got %d", len(updates))
+	}
+	last := updates[len(updates)-1]
+	if last.Bar != 10 || last.TotalBars != 10 || last.Percent() != 100 || !last.Time.Equal(klines[11].OpenTime) {
+		t.Errorf("Expected the final update at bar 10 of 10, got %+v", last)
+	}
+	if last.Balance != result.FinalBalance || last.Trades != len(result.Trades) {
+		t.Errorf("Expected the final update to match the result, got %+v", last)
+	}
+	if last.Equity >= last.Balance {
+		t.Errorf("Expected equity to deduct the fees of the position opened on the last bar, got equity %f balance %f", last.Equity, last.Balance)
+	}
+
+	var received int
+	for event := range events {
+		if event.Trade != result.Trades[received] || event.Warmup {
+			t.Errorf("Unexpected trade event %d: %+v", received, event)
+		}
+		received++
+	}
+	if received != len(result.Trades) || result.BarsProcessed != 10 || result.Aborted {
+		t.Errorf("Expected %d trade events over 10 bars, got %d over %d", len(result.Trades), received, result.BarsProcessed)
+	}
+}
+
+func TestBacktestCanceled(t *testing.T) {
+	now := time.Now()
+	klines := make([]*domain.Kline, 12)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: now.Add(time.Duration(i) * time.Hour), Close: 100.0 + float64(i)}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	config := BacktestConfig{
+		Timing:           ExecutionSignalClose,
+		InitialFunds:     1000.0,
+		PositionSize:     1.0,
+		StopLoss:         0.2,
+		TakeProfit:       0.2,
+		Symbol:           "BTCUSDT",
+		Leverage:         1,
+		ProgressInterval: 1,
+		Progress: func(p Progress) {
+			if p.Bar == 5 {
+				cancel()
+			}
+		},
+	}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+
+	result, err := Backtest(ctx, strategy, klines, config)
+	if err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if result == nil || !result.Aborted || result.BarsProcessed != 5 {
+		t.Fatalf("Expected a partial result after 5 bars, got %+v", result)
+	}
+	if len(result.Trades) != 4 || result.TotalProfit <= 0 || result.ReturnOnInvestment <= 0 {
+		t.Errorf("Expected statistics for the 4 trades closed before the cancel, got %d trades, profit %f",
+			len(result.Trades), result.TotalProfit)
+	}
+}
+
+func TestBacktestExcursions(t *testing.T) {
+	now := time.Now()
+	klines := []*domain.Kline{
+		{OpenTime: now.Add(-4 * time.Hour), Low: 99, High: 101, Close: 100},
+		{OpenTime: now.Add(-3 * time.Hour), Low: 99, High: 101, Close: 100},
+		{OpenTime: now.Add(-2 * time.Hour), Low: 90, High: 101, Close: 100}, // Entry at the close; the low is before it
+		{OpenTime: now.Add(-1 * time.Hour), Low: 97, High: 104, Close: 102},
+		{OpenTime: now, Low: 95, High: 103, Close: 98},
+	}
+	config := BacktestConfig{InitialFunds: 1000.0, PositionSize: 1.0, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "BTCUSDT", Leverage: 1, Timing: ExecutionSignalClose}
+	strategy := &closeAfterStrategy{bars: 2}
+
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(result.Trades))
+	}
+	trade := result.Trades[0]
+	if math.Abs(trade.MAE-0.05) > 1e-9 || math.Abs(trade.MFE-0.04) > 1e-9 {
+		t.Errorf("Expected MAE 0.05 and MFE 0.04 from the bars after entry, got %f and %f", trade.MAE, trade.MFE)
+	}
+}
+
+// closeAfterStrategy enters once and closes after the position has been evaluated for a number of bars
+type closeAfterStrategy struct {
+	MockStrategy
+	entered bool
+	bars    int
+	seen    int
+}
+
+func (c *closeAfterStrategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	if c.entered {
+		return false
+	}
+	c.entered = true
+	return true
+}
+
+func (c *closeAfterStrategy) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	c.seen++
+	return c.seen >= c.bars, domain.CloseReasonMarket
+}
+
+// onceEntryStrategy signals a single entry and nothing afterwards
+type onceEntryStrategy struct {
+	*MockLimitStrategy
+	entered bool
+}
+
+func (o *onceEntryStrategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	if o.entered {
+		return false
+	}
+	o.entered = true
+	return true
+}
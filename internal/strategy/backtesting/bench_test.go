@@ -0,0 +1,120 @@
+package backtesting
+
+import (
+	"context"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"cryptoMegaBot/internal/utils"
+	"os"
+	"testing"
+	"time"
+)
+
+// loadBenchKlines loads the bundled kline fixture. BENCH_KLINES overrides its path (cmd/bench sets it)
+func loadBenchKlines(b *testing.B) []*domain.Kline {
+	b.Helper()
+	path := os.Getenv("BENCH_KLINES")
+	if path == "" {
+		path = "../testdata/ETHUSDT_15m_bench.csv.gz"
+	}
+	klines, err := utils.ReadKlinesFromCSV(path)
+	if err != nil {
+		b.Fatalf("Failed to load benchmark klines: %v", err)
+	}
+	return klines
+}
+
+// benchConfig is the backtest configuration shared by the benchmarks
+func benchConfig() BacktestConfig {
+	return BacktestConfig{
+		InitialFunds: 1000,
+		PositionSize: 0.1,
+		StopLoss:     0.01,
+		TakeProfit:   0.02,
+		Symbol:       "ETHUSDT",
+		Leverage:     4,
+		Seed:         1,
+	}
+}
+
+// newBenchMACrossover creates the MA crossover strategy with the backtest runner's single-timeframe settings
+func newBenchMACrossover(b *testing.B) strategies.Strategy {
+	b.Helper()
+	strategy, err := strategies.NewImprovedMACrossover(strategies.MACrossoverConfig{
+		FastMAPeriod:           8,
+		SlowMAPeriod:           21,
+		SignalPeriod:           9,
+		ATRPeriod:              14,
+		ATRMultiplier:          2.5,
+		MaxDailyLosses:         2,
+		MaxConsecutiveLosses:   2,
+		MaxHoldingTime:         2 * time.Hour,
+		PartialProfitPct:       0.005,
+		TrailingActivePct:      0.002,
+		BreakEvenActivation:    0.002,
+		TrailingStopTightening: true,
+		InitialRiskPerTrade:    0.005,
+		MaxLeverageUsed:        4.0,
+	}, logger.NewStdLogger(logger.LevelError))
+	if err != nil {
+		b.Fatalf("Failed to create strategy: %v", err)
+	}
+	return strategy
+}
+
+// reportPerBar adds an ns/bar metric for benchmarks processing bars klines per iteration
+func reportPerBar(b *testing.B, bars int) {
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*bars), "ns/bar")
+}
+
+func BenchmarkBacktestMACrossover(b *testing.B) {
+	klines := loadBenchKlines(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		// A fresh strategy per run, since it keeps trading state between bars
+		b.StopTimer()
+		strategy := newBenchMACrossover(b)
+		b.StartTimer()
+		if _, err := Backtest(ctx, strategy, klines, benchConfig()); err != nil {
+			b.Fatalf("Backtest failed: %v", err)
+		}
+	}
+	reportPerBar(b, len(klines))
+}
+
+// BenchmarkBacktestLoop measures the backtester's own overhead with a strategy that does no work
+func BenchmarkBacktestLoop(b *testing.B) {
+	klines := loadBenchKlines(b)
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonTakeProfit}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := Backtest(ctx, strategy, klines, benchConfig()); err != nil {
+			b.Fatalf("Backtest failed: %v", err)
+		}
+	}
+	reportPerBar(b, len(klines))
+}
+
+func BenchmarkBacktestPortfolio(b *testing.B) {
+	klines := loadBenchKlines(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		symbols := []PortfolioSymbol{
+			{Symbol: "ETHUSDT", Klines: klines, Strategy: newBenchMACrossover(b)},
+			{Symbol: "ETHUSDT2", Klines: klines, Strategy: newBenchMACrossover(b)},
+		}
+		b.StartTimer()
+		if _, err := BacktestPortfolio(ctx, symbols, PortfolioConfig{InitialFunds: 1000, PositionSize: 0.1, StopLoss: 0.01, TakeProfit: 0.02, Leverage: 4, MaxConcurrentPositions: 2}); err != nil {
+			b.Fatalf("Portfolio backtest failed: %v", err)
+		}
+	}
+	reportPerBar(b, 2*len(klines))
+}
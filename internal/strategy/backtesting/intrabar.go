@@ -0,0 +1,73 @@
+package backtesting
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"fmt"
+	"strings"
+)
+
+// IntrabarFill selects whether exits are checked against each bar's high and low, and which level
+// fills first when a bar's range spans both the stop and the take profit
+type IntrabarFill string
+
+const (
+	// IntrabarOff leaves exits to the strategy at each bar's close (stops inside a bar are missed)
+	IntrabarOff IntrabarFill = ""
+	// IntrabarPessimistic fills the stop first when a bar reaches both levels
+	IntrabarPessimistic IntrabarFill = "pessimistic"
+	// IntrabarOptimistic fills the take profit first when a bar reaches both levels
+	IntrabarOptimistic IntrabarFill = "optimistic"
+)
+
+// ParseIntrabarFill parses "off" (or empty), "pessimistic" or "optimistic"
+func ParseIntrabarFill(value string) (IntrabarFill, error) {
+	switch mode := IntrabarFill(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "off", IntrabarOff:
+		return IntrabarOff, nil
+	case IntrabarPessimistic, IntrabarOptimistic:
+		return mode, nil
+	default:
+		return IntrabarOff, fmt.Errorf("unknown intrabar fill model %q (off, pessimistic or optimistic)", value)
+	}
+}
+
+// Exit checks whether a long position's stop (the higher of its stop loss and trailing
+// stop) or take profit is reached inside a kline and returns the fill price and close reason.
+// A bar that opens through a level fills at the open; when the range spans both levels the mode
+// decides which one filled first and ambiguous is set. On the bar a limit entry filled only the
+// close is known, as in trackExcursion, so the strategy's close check decides there
+func (mode IntrabarFill) Exit(position *domain.Position, kline *domain.Kline) (price float64, reason domain.CloseReason, ambiguous, hit bool) {
+	if mode == IntrabarOff || position.EntryTime.Equal(kline.OpenTime) || kline.Low <= 0 || kline.High <= 0 {
+		return 0, "", false, false
+	}
+
+	stop, stopReason := position.StopLoss, domain.CloseReasonStopLoss
+	if position.TrailingStopPrice > stop {
+		stop, stopReason = position.TrailingStopPrice, domain.CloseReasonTrailingStop
+	} else if stop >= position.EntryPrice {
+		stopReason = domain.CloseReasonBreakEven // Stop had been moved to breakeven or into profit
+	}
+	stopHit := stop > 0 && kline.Low <= stop
+	targetHit := position.TakeProfit > 0 && kline.High >= position.TakeProfit
+
+	// A gap through a level fills at the open, before anything else in the bar
+	if stopHit && kline.Open > 0 && kline.Open <= stop {
+		return kline.Open, stopReason, false, true
+	}
+	if targetHit && kline.Open >= position.TakeProfit {
+		return kline.Open, domain.CloseReasonTakeProfit, false, true
+	}
+
+	switch {
+	case stopHit && targetHit:
+		if mode == IntrabarOptimistic {
+			return position.TakeProfit, domain.CloseReasonTakeProfit, true, true
+		}
+		return stop, stopReason, true, true
+	case stopHit:
+		return stop, stopReason, false, true
+	case targetHit:
+		return position.TakeProfit, domain.CloseReasonTakeProfit, false, true
+	}
+	return 0, "", false, false
+}
@@ -0,0 +1,150 @@
+package backtesting
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestIntrabarFillExit(t *testing.T) {
+	entry := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	bar := entry.Add(time.Hour)
+	newPosition := func() *domain.Position {
+		return &domain.Position{EntryPrice: 100, StopLoss: 98, TakeProfit: 103, EntryTime: entry}
+	}
+
+	tests := []struct {
+		name          string
+		position      func() *domain.Position
+		kline         domain.Kline
+		mode          IntrabarFill
+		wantHit       bool
+		wantPrice     float64
+		wantReason    domain.CloseReason
+		wantAmbiguous bool
+	}{
+		{name: "Off", position: newPosition, kline: domain.Kline{OpenTime: bar, Open: 100, High: 101, Low: 95, Close: 100}, mode: IntrabarOff},
+		{name: "Inside both levels", position: newPosition, kline: domain.Kline{OpenTime: bar, Open: 100, High: 102, Low: 99, Close: 101}, mode: IntrabarPessimistic},
+		{
+			name: "Stop wicked through", position: newPosition, mode: IntrabarPessimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 100, High: 101, Low: 97, Close: 100},
+			wantHit: true, wantPrice: 98, wantReason: domain.CloseReasonStopLoss,
+		},
+		{
+			name: "Take profit reached", position: newPosition, mode: IntrabarPessimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 100, High: 104, Low: 99, Close: 100},
+			wantHit: true, wantPrice: 103, wantReason: domain.CloseReasonTakeProfit,
+		},
+		{
+			name: "Both levels, pessimistic", position: newPosition, mode: IntrabarPessimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 100, High: 104, Low: 97, Close: 100},
+			wantHit: true, wantPrice: 98, wantReason: domain.CloseReasonStopLoss, wantAmbiguous: true,
+		},
+		{
+			name: "Both levels, optimistic", position: newPosition, mode: IntrabarOptimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 100, High: 104, Low: 97, Close: 100},
+			wantHit: true, wantPrice: 103, wantReason: domain.CloseReasonTakeProfit, wantAmbiguous: true,
+		},
+		{
+			name: "Gap below the stop fills at the open", position: newPosition, mode: IntrabarOptimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 96, High: 104, Low: 95, Close: 103},
+			wantHit: true, wantPrice: 96, wantReason: domain.CloseReasonStopLoss,
+		},
+		{
+			name: "Gap above the take profit fills at the open", position: newPosition, mode: IntrabarPessimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 105, High: 106, Low: 97, Close: 100},
+			wantHit: true, wantPrice: 105, wantReason: domain.CloseReasonTakeProfit,
+		},
+		{
+			name: "Trailing stop above the stop loss",
+			position: func() *domain.Position {
+				p := newPosition()
+				p.TrailingStopPrice = 101.5
+				return p
+			},
+			mode:    IntrabarPessimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 102, High: 102.5, Low: 101, Close: 101.2},
+			wantHit: true, wantPrice: 101.5, wantReason: domain.CloseReasonTrailingStop,
+		},
+		{
+			name: "Stop moved to breakeven",
+			position: func() *domain.Position {
+				p := newPosition()
+				p.StopLoss = 100.2
+				return p
+			},
+			mode:    IntrabarPessimistic,
+			kline:   domain.Kline{OpenTime: bar, Open: 101, High: 101.5, Low: 100, Close: 100.5},
+			wantHit: true, wantPrice: 100.2, wantReason: domain.CloseReasonBreakEven,
+		},
+		{
+			name: "Entry bar is left to the close check", position: newPosition, mode: IntrabarPessimistic,
+			kline: domain.Kline{OpenTime: entry, Open: 100, High: 104, Low: 97, Close: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, reason, ambiguous, hit := tt.mode.Exit(tt.position(), &tt.kline)
+			if hit != tt.wantHit || ambiguous != tt.wantAmbiguous {
+				t.Fatalf("Expected hit=%v ambiguous=%v, got hit=%v ambiguous=%v", tt.wantHit, tt.wantAmbiguous, hit, ambiguous)
+			}
+			if !hit {
+				return
+			}
+			if math.Abs(price-tt.wantPrice) > 1e-9 || reason != tt.wantReason {
+				t.Errorf("Expected %s at %v, got %s at %v", tt.wantReason, tt.wantPrice, reason, price)
+			}
+		})
+	}
+}
+
+func TestParseIntrabarFill(t *testing.T) {
+	for value, want := range map[string]IntrabarFill{"": IntrabarOff, "off": IntrabarOff, "Pessimistic": IntrabarPessimistic, "optimistic": IntrabarOptimistic} {
+		got, err := ParseIntrabarFill(value)
+		if err != nil || got != want {
+			t.Errorf("ParseIntrabarFill(%q) = %q, %v; expected %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseIntrabarFill("worst"); err == nil {
+		t.Error("Expected an error for an unknown fill model")
+	}
+}
+
+func TestBacktestIntrabarStops(t *testing.T) {
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 6)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: 100, High: 101, Low: 99, Close: 100}
+	}
+	klines[3].Low = 97 // Wicks through the 2% stop but closes back at 100
+	noFees := domain.FeeModel{TakerRate: 1e-12}
+	config := BacktestConfig{InitialFunds: 1000, PositionSize: 1, StopLoss: 0.02, TakeProfit: 0.05, Symbol: "ETHUSDT", Leverage: 1, Fees: noFees, Timing: ExecutionSignalClose}
+
+	// Checked at the close only, the wick is missed and the position is never stopped out
+	result, err := Backtest(context.Background(), &closeAfterStrategy{bars: 100}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) != 0 || result.IntrabarExits != 0 {
+		t.Fatalf("Expected no closed trades, got %d", len(result.Trades))
+	}
+
+	config.Intrabar = IntrabarPessimistic
+	result, err = Backtest(context.Background(), &closeAfterStrategy{bars: 100}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(result.Trades))
+	}
+	trade := result.Trades[0]
+	if trade.CloseReason != domain.CloseReasonStopLoss || !trade.ExitTime.Equal(klines[3].OpenTime) || math.Abs(trade.ExitPrice-98) > 1e-9 {
+		t.Errorf("Expected a stop loss at 98 on bar 3, got %s at %v on %v", trade.CloseReason, trade.ExitPrice, trade.ExitTime)
+	}
+	if result.IntrabarExits != 1 || result.IntrabarAmbiguous != 0 {
+		t.Errorf("Expected 1 unambiguous intrabar exit, got %d (%d ambiguous)", result.IntrabarExits, result.IntrabarAmbiguous)
+	}
+}
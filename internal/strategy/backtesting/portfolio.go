@@ -0,0 +1,330 @@
+package backtesting
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/money"
+	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
+	"cryptoMegaBot/internal/strategy/analytics"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PortfolioSymbol is one symbol of a portfolio backtest. Each symbol needs its own strategy
+// instance, since strategies may keep state between bars
+type PortfolioSymbol struct {
+	Symbol       string
+	Strategy     strategies.Strategy
+	Klines       []*domain.Kline // Sorted by open time
+	PositionSize float64         // Quantity per trade, in the currency of PortfolioConfig.QuantityMode (0 uses PortfolioConfig.PositionSize)
+	Precision    money.Precision // Quantity step of quote conversions (zero value uses money.DefaultPrecision)
+}
+
+// PortfolioConfig holds configuration for a portfolio backtest
+type PortfolioConfig struct {
+	InitialFunds float64
+	PositionSize float64
+	StopLoss     float64
+	TakeProfit   float64
+	Leverage     int
+
+	// Currency of the position sizes, as in BacktestConfig
+	QuantityMode domain.QuantityMode
+
+	// Maximum positions open at the same time across all symbols (0 means no limit)
+	MaxConcurrentPositions int
+
+	// Maximum notional of all open positions, a new entry included, as a fraction of the balance
+	// (e.g., 2 for 200%; 0 means no limit)
+	MaxExposurePct float64
+
+	// Trading fees and funding charged on each trade (zero value uses defaultFees)
+	Fees domain.FeeModel
+
+	// Maintenance margin rate for liquidations, as in BacktestConfig (0 uses defaultMaintenanceMarginRate)
+	MaintenanceMarginRate float64
+
+	// Optional news/volatility blackout windows, applied to all symbols as in BacktestConfig
+	Blackout *risk.BlackoutSchedule
+
+	// Optional trading calendar, applied to all symbols as in BacktestConfig
+	Calendar *risk.TradingCalendar
+
+	// Parameters of the market regime trades are tagged with at entry, as in BacktestConfig
+	Regime analytics.RegimeConfig
+
+	// Sides entries may be opened on, as in BacktestConfig
+	Direction domain.TradeDirection
+}
+
+// PortfolioResult holds the results of a portfolio backtest
+type PortfolioResult struct {
+	// Statistics over all trades against the shared balance
+	Combined *BacktestResult
+
+	// Statistics per symbol. Balances, returns and drawdowns are the symbol's contribution to the
+	// shared balance, measured against the full initial funds
+	Symbols map[string]*BacktestResult
+
+	// Entry signals that were not taken
+	SkippedMaxPositions      int // The concurrent position limit was reached
+	SkippedInsufficientFunds int // The position's margin exceeded the free balance
+	SkippedBlackout          int // A blackout window was active
+	SkippedCalendar          int // The trading calendar disabled the day
+	SkippedMaxExposure       int // The open notional would have exceeded MaxExposurePct of the balance
+
+	MaxOpenPositions int // Most positions open at the same time
+}
+
+// portfolioSlot tracks the simulation state of one symbol
+type portfolioSlot struct {
+	symbol      PortfolioSymbol
+	config      BacktestConfig // Position settings for newPosition
+	next        int            // Index of the next kline to process
+	position    *domain.Position
+	regime      domain.MarketRegime // Market regime at the position's entry
+	result      *BacktestResult
+	trades      []*domain.Trade
+	peakBalance float64
+}
+
+// BacktestPortfolio simulates several symbols trading against one shared balance. Bars of all
+// symbols are processed in chronological order; at each time exits are handled for every symbol
+// before entries, so closed positions free their slot and margin for new ones. Entries fill at
+// market on the signal bar's close and need the position's margin (entry price times quantity)
+// to be available in the realized balance not already committed to open positions; limit entry
+// orders are not simulated. Positions are liquidated as in Backtest. If ctx is canceled mid-run, the partial result is returned (with
+// Combined.Aborted set) together with the context's error
+func BacktestPortfolio(ctx context.Context, symbols []PortfolioSymbol, config PortfolioConfig) (*PortfolioResult, error) {
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("no symbols to backtest")
+	}
+	if config.InitialFunds <= 0 {
+		return nil, fmt.Errorf("initial funds must be positive")
+	}
+	if config.MaxConcurrentPositions < 0 {
+		return nil, fmt.Errorf("max concurrent positions cannot be negative")
+	}
+	fees := config.Fees
+	if fees == (domain.FeeModel{}) {
+		fees = defaultFees
+	}
+	maintenanceMarginRate := config.MaintenanceMarginRate
+	if maintenanceMarginRate <= 0 {
+		maintenanceMarginRate = defaultMaintenanceMarginRate
+	}
+
+	result := &PortfolioResult{
+		Combined: &BacktestResult{FinalBalance: config.InitialFunds},
+		Symbols:  make(map[string]*BacktestResult, len(symbols)),
+	}
+	slots := make([]*portfolioSlot, 0, len(symbols))
+	for _, sym := range symbols {
+		if sym.Strategy == nil {
+			return nil, fmt.Errorf("no strategy for symbol %s", sym.Symbol)
+		}
+		if _, exists := result.Symbols[sym.Symbol]; exists {
+			return nil, fmt.Errorf("duplicate symbol %s", sym.Symbol)
+		}
+		if len(sym.Klines) <= sym.Strategy.RequiredDataPoints() {
+			return nil, fmt.Errorf("not enough data points for strategy on %s", sym.Symbol)
+		}
+		size := sym.PositionSize
+		if size <= 0 {
+			size = config.PositionSize
+		}
+		slot := &portfolioSlot{
+			symbol: sym,
+			config: BacktestConfig{
+				Symbol:       sym.Symbol,
+				PositionSize: size,
+				QuantityMode: config.QuantityMode,
+				Precision:    sym.Precision,
+				StopLoss:     config.StopLoss,
+				TakeProfit:   config.TakeProfit,
+				Leverage:     config.Leverage,
+				Calendar:     config.Calendar,
+			},
+			result:      &BacktestResult{FinalBalance: config.InitialFunds},
+			peakBalance: config.InitialFunds,
+		}
+		result.Symbols[sym.Symbol] = slot.result
+		slots = append(slots, slot)
+	}
+
+	combined := result.Combined
+	peakBalance := config.InitialFunds
+	var trades []*domain.Trade
+	openPositions := 0
+	usedMargin := 0.0
+
+	for _, t := range portfolioTimes(symbols) {
+		if ctx.Err() != nil {
+			combined.Aborted = true
+			break
+		}
+
+		// Bars of this time, per symbol; symbols without one are skipped
+		active := make([]*portfolioSlot, 0, len(slots))
+		for _, slot := range slots {
+			if slot.next < len(slot.symbol.Klines) && slot.symbol.Klines[slot.next].OpenTime.Equal(t) {
+				active = append(active, slot)
+			}
+		}
+
+		// Exits first
+		for _, slot := range active {
+			i := slot.next
+			if slot.position == nil {
+				continue
+			}
+			kline := slot.symbol.Klines[i]
+			trackExcursion(slot.position, kline)
+			if blackout, _ := config.Blackout.Active(kline.OpenTime); blackout {
+				if stop, ok := config.Blackout.TightenedStop(slot.position, kline.Close); ok {
+					slot.position.StopLoss = stop
+				}
+			}
+			exitPrice := kline.Close
+			var shouldClose bool
+			var reason domain.CloseReason
+			if liquidationPrice, liquidated := liquidationFill(slot.position, kline, maintenanceMarginRate); liquidated {
+				shouldClose, reason, exitPrice = true, domain.CloseReasonLiquidation, liquidationPrice
+			} else {
+				shouldClose, reason = slot.symbol.Strategy.ShouldClosePosition(ctx, slot.position, slot.symbol.Klines[:i+1], kline.Close)
+			}
+			if !shouldClose {
+				continue
+			}
+			pnl := calculatePNL(slot.position, exitPrice, kline.OpenTime, fees)
+			if reason == domain.CloseReasonLiquidation {
+				pnl = liquidationPNL(slot.position, kline.OpenTime, fees)
+			}
+			if err := slot.position.Close(exitPrice, kline.OpenTime, reason); err != nil {
+				return nil, fmt.Errorf("failed to close backtest position on %s: %w", slot.symbol.Symbol, err)
+			}
+			trade := slot.position.Trade()
+			trade.PNL = pnl
+			trade.Regime = slot.regime
+			usedMargin -= margin(slot.position)
+			openPositions--
+			slot.position = nil
+
+			recordTrade(combined, pnl, &peakBalance)
+			trades = append(trades, trade)
+			recordTrade(slot.result, pnl, &slot.peakBalance)
+			slot.trades = append(slot.trades, trade)
+			if reason == domain.CloseReasonLiquidation {
+				for _, r := range []*BacktestResult{combined, slot.result} {
+					r.Liquidations = append(r.Liquidations, trade)
+					r.LiquidationLoss += pnl
+				}
+			}
+		}
+
+		// Then entries
+		for _, slot := range active {
+			i := slot.next
+			slot.next++
+			if slot.position != nil || i < slot.symbol.Strategy.RequiredDataPoints() || !config.Direction.Allows(domain.PositionSideLong) {
+				continue
+			}
+			kline := slot.symbol.Klines[i]
+			history := slot.symbol.Klines[:i+1]
+			if !slot.symbol.Strategy.ShouldEnterTrade(ctx, history, kline.Close) {
+				continue
+			}
+			if blackout, _ := config.Blackout.Active(kline.OpenTime); blackout {
+				result.SkippedBlackout++
+				continue
+			}
+			if closed, _ := config.Calendar.Closed(kline.OpenTime); closed {
+				result.SkippedCalendar++
+				continue
+			}
+			if config.MaxConcurrentPositions > 0 && openPositions >= config.MaxConcurrentPositions {
+				result.SkippedMaxPositions++
+				continue
+			}
+			pos, err := newPosition(slot.config, kline.Close, kline.OpenTime)
+			if err != nil {
+				continue
+			}
+			required := margin(pos)
+			if required > combined.FinalBalance-usedMargin {
+				result.SkippedInsufficientFunds++
+				continue
+			}
+			// The margin of a position is its notional, so usedMargin is the open notional
+			if risk.CheckExposure(usedMargin, pos.Quantity*pos.EntryPrice, combined.FinalBalance, config.MaxExposurePct) != nil {
+				result.SkippedMaxExposure++
+				continue
+			}
+			if tagger, ok := slot.symbol.Strategy.(ports.EntryTagger); ok {
+				pos.EntryTag = tagger.LastEntryTag()
+			}
+			slot.position = pos
+			slot.regime = analytics.DetectRegime(history, config.Regime)
+			usedMargin += required
+			openPositions++
+			if openPositions > result.MaxOpenPositions {
+				result.MaxOpenPositions = openPositions
+			}
+			combined.TotalTrades++
+			slot.result.TotalTrades++
+		}
+		combined.BarsProcessed++
+	}
+
+	for _, slot := range slots {
+		slot.result.Aborted = combined.Aborted
+		if processed := slot.next - slot.symbol.Strategy.RequiredDataPoints(); processed > 0 {
+			slot.result.BarsProcessed = processed
+		}
+		finalizeResult(slot.result, slot.trades, config.InitialFunds)
+	}
+	finalizeResult(combined, trades, config.InitialFunds)
+	if combined.Aborted {
+		return result, ctx.Err()
+	}
+	return result, nil
+}
+
+// recordTrade adds a closed trade's PNL to the result's balance and statistics, tracking the
+// drawdown from peakBalance
+func recordTrade(result *BacktestResult, pnl float64, peakBalance *float64) {
+	result.TotalProfit += pnl
+	result.FinalBalance += pnl
+	if pnl > 0 {
+		result.WinningTrades++
+		result.AverageWin = (result.AverageWin*float64(result.WinningTrades-1) + pnl) / float64(result.WinningTrades)
+	} else {
+		result.LosingTrades++
+		result.AverageLoss = (result.AverageLoss*float64(result.LosingTrades-1) + pnl) / float64(result.LosingTrades)
+	}
+	if result.FinalBalance > *peakBalance {
+		*peakBalance = result.FinalBalance
+	}
+	if drawdown := (*peakBalance - result.FinalBalance) / *peakBalance; drawdown > result.MaxDrawdown {
+		result.MaxDrawdown = drawdown
+	}
+}
+
+// portfolioTimes returns the distinct kline open times of all symbols in chronological order
+func portfolioTimes(symbols []PortfolioSymbol) []time.Time {
+	seen := make(map[int64]bool)
+	var times []time.Time
+	for _, sym := range symbols {
+		for _, kline := range sym.Klines {
+			if key := kline.OpenTime.UnixNano(); !seen[key] {
+				seen[key] = true
+				times = append(times, kline.OpenTime)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
@@ -0,0 +1,157 @@
+package backtesting
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+// portfolioKlines builds hourly klines from start with the given closes
+func portfolioKlines(start time.Time, closes ...float64) []*domain.Kline {
+	klines := make([]*domain.Kline, len(closes))
+	for i, c := range closes {
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: c, High: c, Low: c, Close: c}
+	}
+	return klines
+}
+
+func TestBacktestPortfolio(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	noFees := domain.FeeModel{TakerRate: 1e-12}
+	config := PortfolioConfig{InitialFunds: 1000, PositionSize: 1, StopLoss: 0.1, TakeProfit: 0.1, Leverage: 1, Fees: noFees}
+	alwaysTrading := func() *MockStrategy {
+		return &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonTakeProfit}
+	}
+
+	t.Run("shared balance and per-symbol metrics", func(t *testing.T) {
+		result, err := BacktestPortfolio(context.Background(), []PortfolioSymbol{
+			{Symbol: "ETHUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100, 110, 120)},
+			{Symbol: "BTCUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start.Add(30*time.Minute), 200, 200, 200, 190, 180)},
+		}, config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		eth, btc := result.Symbols["ETHUSDT"], result.Symbols["BTCUSDT"]
+		if eth.TotalProfit < 19.99 || eth.TotalProfit > 20.01 {
+			t.Errorf("ETHUSDT profit = %f, want 20", eth.TotalProfit)
+		}
+		if btc.TotalProfit > -19.99 || btc.TotalProfit < -20.01 {
+			t.Errorf("BTCUSDT profit = %f, want -20", btc.TotalProfit)
+		}
+		if math.Abs(result.Combined.FinalBalance-1000) > 0.01 {
+			t.Errorf("combined balance = %f, want 1000", result.Combined.FinalBalance)
+		}
+		if len(result.Combined.Trades) != 4 || result.Combined.WinningTrades != 2 || result.Combined.LosingTrades != 2 {
+			t.Errorf("combined trades = %d (%d wins, %d losses), want 4 (2, 2)",
+				len(result.Combined.Trades), result.Combined.WinningTrades, result.Combined.LosingTrades)
+		}
+		if result.Combined.MaxDrawdown <= 0 {
+			t.Error("expected a drawdown after the BTCUSDT losses")
+		}
+		if result.MaxOpenPositions != 2 {
+			t.Errorf("max open positions = %d, want 2", result.MaxOpenPositions)
+		}
+
+		// Trades of both symbols are interleaved in chronological order
+		for i := 1; i < len(result.Combined.Trades); i++ {
+			if result.Combined.Trades[i].ExitTime.Before(result.Combined.Trades[i-1].ExitTime) {
+				t.Fatalf("trades not in chronological order at %d", i)
+			}
+		}
+		if result.Combined.Trades[0].Symbol != "ETHUSDT" || result.Combined.Trades[1].Symbol != "BTCUSDT" {
+			t.Errorf("unexpected trade order: %s, %s", result.Combined.Trades[0].Symbol, result.Combined.Trades[1].Symbol)
+		}
+	})
+
+	t.Run("max concurrent positions", func(t *testing.T) {
+		limited := config
+		limited.MaxConcurrentPositions = 1
+		result, err := BacktestPortfolio(context.Background(), []PortfolioSymbol{
+			{Symbol: "ETHUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100, 100)},
+			{Symbol: "BTCUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 200, 200, 200, 200)},
+		}, limited)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.MaxOpenPositions != 1 {
+			t.Errorf("max open positions = %d, want 1", result.MaxOpenPositions)
+		}
+		if result.SkippedMaxPositions != 2 {
+			t.Errorf("skipped entries = %d, want 2", result.SkippedMaxPositions)
+		}
+		if result.Symbols["BTCUSDT"].TotalTrades != 0 {
+			t.Errorf("BTCUSDT trades = %d, want 0", result.Symbols["BTCUSDT"].TotalTrades)
+		}
+	})
+
+	t.Run("max exposure", func(t *testing.T) {
+		limited := config
+		limited.MaxExposurePct = 0.5
+		result, err := BacktestPortfolio(context.Background(), []PortfolioSymbol{
+			{Symbol: "ETHUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100), PositionSize: 3},
+			{Symbol: "BTCUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100), PositionSize: 3},
+		}, limited)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 300 notional is 30% of the balance; a second one would make it 60%
+		if result.SkippedMaxExposure != 1 || result.Combined.TotalTrades != 1 {
+			t.Errorf("skipped = %d, trades = %d, want 1 and 1", result.SkippedMaxExposure, result.Combined.TotalTrades)
+		}
+	})
+
+	t.Run("margin limited by free balance", func(t *testing.T) {
+		result, err := BacktestPortfolio(context.Background(), []PortfolioSymbol{
+			{Symbol: "ETHUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100), PositionSize: 6},
+			{Symbol: "BTCUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100), PositionSize: 6},
+		}, config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.SkippedInsufficientFunds != 1 || result.Combined.TotalTrades != 1 {
+			t.Errorf("skipped = %d, trades = %d, want 1 and 1", result.SkippedInsufficientFunds, result.Combined.TotalTrades)
+		}
+	})
+
+	t.Run("liquidation", func(t *testing.T) {
+		leveraged := config
+		leveraged.Leverage = 10
+		holding := &MockStrategy{shouldEnter: true}
+		result, err := BacktestPortfolio(context.Background(), []PortfolioSymbol{
+			{Symbol: "ETHUSDT", Strategy: holding, Klines: portfolioKlines(start, 100, 100, 100, 85, 100)},
+		}, leveraged)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		eth := result.Symbols["ETHUSDT"]
+		if len(result.Combined.Liquidations) != 1 || len(eth.Liquidations) != 1 {
+			t.Fatalf("liquidations = %d combined, %d for ETHUSDT, want 1 and 1", len(result.Combined.Liquidations), len(eth.Liquidations))
+		}
+		if reason := result.Combined.Trades[0].CloseReason; reason != domain.CloseReasonLiquidation {
+			t.Errorf("close reason = %s, want %s", reason, domain.CloseReasonLiquidation)
+		}
+		if math.Abs(result.Combined.LiquidationLoss+100) > 0.01 {
+			t.Errorf("liquidation loss = %f, want -100 (the whole margin)", result.Combined.LiquidationLoss)
+		}
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := BacktestPortfolio(context.Background(), nil, config); err == nil {
+			t.Error("expected error without symbols")
+		}
+		short := []PortfolioSymbol{{Symbol: "ETHUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100)}}
+		if _, err := BacktestPortfolio(context.Background(), short, config); err == nil {
+			t.Error("expected error with too few klines")
+		}
+		duplicate := []PortfolioSymbol{
+			{Symbol: "ETHUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100)},
+			{Symbol: "ETHUSDT", Strategy: alwaysTrading(), Klines: portfolioKlines(start, 100, 100, 100)},
+		}
+		if _, err := BacktestPortfolio(context.Background(), duplicate, config); err == nil {
+			t.Error("expected error for duplicate symbols")
+		}
+	})
+}
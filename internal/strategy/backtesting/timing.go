@@ -0,0 +1,39 @@
+package backtesting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExecutionTiming selects when market orders from a strategy's signals fill: a signal is only
+// known once its bar has closed, so a live bot can act on it at the next bar's open at the earliest
+type ExecutionTiming string
+
+const (
+	// ExecutionNextOpen fills market entries and the strategy's exits at the open of the bar after
+	// the signal (the default)
+	ExecutionNextOpen ExecutionTiming = ""
+	// ExecutionSignalClose fills them at the close of the signal bar itself, which assumes the
+	// order executes at the price that produced the signal (look-ahead)
+	ExecutionSignalClose ExecutionTiming = "close"
+)
+
+// ParseExecutionTiming parses "next-open" (or empty) or "close"
+func ParseExecutionTiming(value string) (ExecutionTiming, error) {
+	switch timing := ExecutionTiming(strings.ToLower(strings.TrimSpace(value))); timing {
+	case "next-open", ExecutionNextOpen:
+		return ExecutionNextOpen, nil
+	case ExecutionSignalClose:
+		return timing, nil
+	default:
+		return ExecutionNextOpen, fmt.Errorf("unknown execution timing %q (next-open or close)", value)
+	}
+}
+
+// String returns the timing's flag value
+func (t ExecutionTiming) String() string {
+	if t == ExecutionNextOpen {
+		return "next-open"
+	}
+	return string(t)
+}
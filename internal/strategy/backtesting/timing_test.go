@@ -0,0 +1,82 @@
+package backtesting
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestParseExecutionTiming(t *testing.T) {
+	for value, expected := range map[string]ExecutionTiming{"": ExecutionNextOpen, "next-open": ExecutionNextOpen, " Close ": ExecutionSignalClose} {
+		if timing, err := ParseExecutionTiming(value); err != nil || timing != expected {
+			t.Errorf("ParseExecutionTiming(%q) = %q, %v; expected %q", value, timing, err, expected)
+		}
+	}
+	if _, err := ParseExecutionTiming("open"); err == nil {
+		t.Error("Expected an error for an unknown timing")
+	}
+	if ExecutionNextOpen.String() != "next-open" || ExecutionSignalClose.String() != "close" {
+		t.Errorf("Unexpected names %q and %q", ExecutionNextOpen, ExecutionSignalClose)
+	}
+}
+
+func TestBacktestExecutionTiming(t *testing.T) {
+	now := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 6)
+	for i := range klines {
+		open := 100 + float64(i)
+		klines[i] = &domain.Kline{OpenTime: now.Add(time.Duration(i) * time.Hour), Open: open, High: open + 1, Low: open - 1, Close: open + 0.5}
+	}
+	config := BacktestConfig{InitialFunds: 1000, PositionSize: 1, StopLoss: 0.2, TakeProfit: 0.2, Symbol: "ETHUSDT", Leverage: 1}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+
+	t.Run("next open by default", func(t *testing.T) {
+		result, err := Backtest(context.Background(), strategy, klines, config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// Signal at bar 2's close, filled at bar 3's open; the exit signaled there fills at bar 4's
+		// open and the next entry at bar 5's open stays open
+		if len(result.Trades) != 1 || result.TotalTrades != 2 {
+			t.Fatalf("Expected 1 closed trade of 2 entries, got %d of %d", len(result.Trades), result.TotalTrades)
+		}
+		trade := result.Trades[0]
+		if trade.EntryPrice != 103 || !trade.EntryTime.Equal(klines[3].OpenTime) || trade.ExitPrice != 104 || !trade.ExitTime.Equal(klines[4].OpenTime) {
+			t.Errorf("Expected an entry at 103 on bar 3 and an exit at 104 on bar 4, got %+v", trade)
+		}
+		if trade.CloseReason != domain.CloseReasonMarket || result.LimitOrdersPlaced+result.LimitOrdersExpired != 0 {
+			t.Errorf("Expected a market exit and no limit orders, got %s and %d/%d", trade.CloseReason, result.LimitOrdersPlaced, result.LimitOrdersExpired)
+		}
+	})
+
+	t.Run("signal close", func(t *testing.T) {
+		config := config
+		config.Timing = ExecutionSignalClose
+		result, err := Backtest(context.Background(), strategy, klines, config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Trades) != 3 || result.Trades[0].EntryPrice != 102.5 || result.Trades[0].ExitPrice != 103.5 {
+			t.Errorf("Expected 3 trades filled at the closes, got %d starting with %+v", len(result.Trades), result.Trades[0])
+		}
+	})
+
+	t.Run("klines without an open fill at the previous close", func(t *testing.T) {
+		noOpen := make([]*domain.Kline, len(klines))
+		for i, k := range klines {
+			copied := *k
+			copied.Open = 0
+			noOpen[i] = &copied
+		}
+		config := config
+		config.Slippage = 0.01
+		result, err := Backtest(context.Background(), strategy, noOpen, config)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Trades) != 1 || result.Trades[0].EntryPrice != 102.5*1.01 || result.Trades[0].ExitPrice != 103.5*0.99 {
+			t.Errorf("Expected slipped fills at the previous closes, got %+v", result.Trades)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package backtesting
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+)
+
+// TradeLog writes closed trades to a file as JSON lines (NDJSON) while a backtest runs. Each trade
+// is written with a single unbuffered write as soon as it closes, so the lines written so far
+// survive a crash and the file can be read (e.g., with IterateTradeLog) before the run finishes.
+// A nil *TradeLog discards trades.
+type TradeLog struct {
+	file *os.File
+}
+
+// tradeLogLine is a trade as written to a trade log, with the fields named like the trade CSV columns
+type tradeLogLine struct {
+	PositionID        int64     `json:"position_id"`
+	Symbol            string    `json:"symbol"`
+	Side              string    `json:"side"`
+	EntryPrice        float64   `json:"entry_price"`
+	ExitPrice         float64   `json:"exit_price"`
+	Quantity          float64   `json:"quantity"`
+	Leverage          int       `json:"leverage"`
+	PNL               float64   `json:"pnl"`
+	EntryTime         time.Time `json:"entry_time"`
+	ExitTime          time.Time `json:"exit_time"`
+	CloseReason       string    `json:"close_reason"`
+	EntryReason       string    `json:"entry_reason,omitempty"`
+	SignalSource      string    `json:"signal_source,omitempty"`
+	ConfirmationCount int       `json:"confirmation_count"`
+	EntryATR          float64   `json:"entry_atr"`
+	MAE               float64   `json:"mae"`
+	MFE               float64   `json:"mfe"`
+	RegimeTrend       string    `json:"regime_trend,omitempty"`
+	RegimeVolatility  string    `json:"regime_volatility,omitempty"`
+	RegimeHTF         string    `json:"regime_htf,omitempty"`
+	Warmup            bool      `json:"warmup"`  // Excluded from the statistics
+	Balance           float64   `json:"balance"` // Realized balance after the trade
+}
+
+// CreateTradeLog creates (or truncates) the trade log file at path.
+func CreateTradeLog(path string) (*TradeLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trade log: %w", err)
+	}
+	return &TradeLog{file: file}, nil
+}
+
+// Write appends event's trade to the log as one line
+func (l *TradeLog) Write(event TradeEvent) error {
+	if l == nil || event.Trade == nil {
+		return nil
+	}
+	t := event.Trade
+	line, err := json.Marshal(tradeLogLine{
+		PositionID:        t.PositionID,
+		Symbol:            t.Symbol,
+		Side:              string(t.Side),
+		EntryPrice:        t.EntryPrice,
+		ExitPrice:         t.ExitPrice,
+		Quantity:          t.Quantity,
+		Leverage:          t.Leverage,
+		PNL:               t.PNL,
+		EntryTime:         t.EntryTime,
+		ExitTime:          t.ExitTime,
+		CloseReason:       string(t.CloseReason),
+		EntryReason:       t.EntryReason,
+		SignalSource:      string(t.SignalSource),
+		ConfirmationCount: t.ConfirmationCount,
+		EntryATR:          t.EntryATR,
+		MAE:               t.MAE,
+		MFE:               t.MFE,
+		RegimeTrend:       string(t.Regime.Trend),
+		RegimeVolatility:  string(t.Regime.Volatility),
+		RegimeHTF:         string(t.Regime.HigherTF),
+		Warmup:            event.Warmup,
+		Balance:           event.Balance,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode trade: %w", err)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write trade log: %w", err)
+	}
+	return nil
+}
+
+// Path returns the name of the log file
+func (l *TradeLog) Path() string {
+	if l == nil {
+		return ""
+	}
+	return l.file.Name()
+}
+
+// Close closes the log file
+func (l *TradeLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// IterateTradeLog calls fn for every trade in a trade log, in the order they closed. It can read a
+// log that is still being written; a last line without its newline (the write in progress, or
+// cut off by a crash) is skipped. Returning an error from fn stops iteration and returns that error.
+func IterateTradeLog(path string, fn func(TradeEvent) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil // Incomplete last line, if any
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var l tradeLogLine
+		if err := json.Unmarshal(line, &l); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		if err := fn(l.event()); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadTradeLog returns the trades in a trade log (see IterateTradeLog)
+func ReadTradeLog(path string) ([]TradeEvent, error) {
+	var events []TradeEvent
+	err := IterateTradeLog(path, func(event TradeEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (l tradeLogLine) event() TradeEvent {
+	return TradeEvent{
+		Trade: &domain.Trade{
+			PositionID:  l.PositionID,
+			Symbol:      l.Symbol,
+			Side:        domain.PositionSide(l.Side),
+			EntryPrice:  l.EntryPrice,
+			ExitPrice:   l.ExitPrice,
+			Quantity:    l.Quantity,
+			Leverage:    l.Leverage,
+			PNL:         l.PNL,
+			EntryTime:   l.EntryTime,
+			ExitTime:    l.ExitTime,
+			CloseReason: domain.CloseReason(l.CloseReason),
+			MAE:         l.MAE,
+			MFE:         l.MFE,
+			Regime: domain.MarketRegime{
+				Trend:      domain.TrendRegime(l.RegimeTrend),
+				Volatility: domain.VolatilityRegime(l.RegimeVolatility),
+				HigherTF:   domain.TrendDirection(l.RegimeHTF),
+			},
+			EntryTag: domain.EntryTag{
+				EntryReason:       l.EntryReason,
+				SignalSource:      domain.SignalSource(l.SignalSource),
+				ConfirmationCount: l.ConfirmationCount,
+				EntryATR:          l.EntryATR,
+			},
+		},
+		Warmup:  l.Warmup,
+		Balance: l.Balance,
+	}
+}
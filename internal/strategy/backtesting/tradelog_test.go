@@ -0,0 +1,104 @@
+package backtesting
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBacktestTradeLog(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 12)
+	for i := range klines {
+		klines[i] = &domain.Kline{OpenTime: now.Add(time.Duration(i) * time.Hour), Close: 100.0 + float64(i)}
+	}
+	path := filepath.Join(t.TempDir(), "trades.ndjson")
+	tradeLog, err := CreateTradeLog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config := BacktestConfig{
+		InitialFunds: 1000.0,
+		PositionSize: 1.0,
+		StopLoss:     0.2,
+		TakeProfit:   0.2,
+		Symbol:       "BTCUSDT",
+		Leverage:     1,
+		TradeLog:     tradeLog,
+	}
+	strategy := &MockStrategy{shouldEnter: true, shouldClose: true, closeReason: domain.CloseReasonMarket}
+
+	result, err := Backtest(context.Background(), strategy, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := tradeLog.Close(); err != nil {
+		t.Fatalf("Unexpected error closing the log: %v", err)
+	}
+
+	events, err := ReadTradeLog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error reading the log: %v", err)
+	}
+	if len(result.Trades) == 0 || len(events) != len(result.Trades) {
+		t.Fatalf("Expected %d logged trades, got %d", len(result.Trades), len(events))
+	}
+	for i, event := range events {
+		want := result.Trades[i]
+		got := event.Trade
+		if got.Symbol != want.Symbol || got.Side != want.Side || got.EntryPrice != want.EntryPrice || got.ExitPrice != want.ExitPrice ||
+			got.PNL != want.PNL || !got.EntryTime.Equal(want.EntryTime) || !got.ExitTime.Equal(want.ExitTime) || got.CloseReason != want.CloseReason {
+			t.Errorf("Logged trade %d = %+v, want %+v", i, got, want)
+		}
+		if event.Warmup {
+			t.Errorf("Logged trade %d unexpectedly marked as warm-up", i)
+		}
+	}
+	if last := events[len(events)-1]; last.Balance != result.FinalBalance {
+		t.Errorf("Expected the last logged balance %f, got %f", result.FinalBalance, last.Balance)
+	}
+}
+
+func TestReadTradeLogSkipsIncompleteLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trades.ndjson")
+	tradeLog, err := CreateTradeLog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	trade := &domain.Trade{Symbol: "ETHUSDT", Side: domain.PositionSideShort, EntryPrice: 2000, ExitPrice: 1950, Quantity: 0.1, PNL: 5,
+		CloseReason: domain.CloseReasonTakeProfit, EntryTag: domain.EntryTag{SignalSource: domain.SignalSourcePullback, ConfirmationCount: 3}}
+	if err := tradeLog.Write(TradeEvent{Trade: trade, Warmup: true, Balance: 1005}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tradeLog.Close()
+
+	// A crash in the middle of the next write leaves a partial line behind
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	file.WriteString(`{"position_id":2,"symbol":"ETH`)
+	file.Close()
+
+	events, err := ReadTradeLog(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected the complete trade only, got %d", len(events))
+	}
+	got := events[0]
+	if got.Trade.Side != domain.PositionSideShort || got.Trade.PNL != 5 || got.Trade.CloseReason != domain.CloseReasonTakeProfit ||
+		got.Trade.ConfirmationCount != 3 || !got.Warmup || got.Balance != 1005 {
+		t.Errorf("Unexpected logged trade: %+v %+v", got, got.Trade)
+	}
+
+	// Appending to a nil log is a no-op
+	var none *TradeLog
+	if err := none.Write(TradeEvent{Trade: trade}); err != nil || none.Close() != nil {
+		t.Errorf("Expected a nil trade log to discard trades")
+	}
+}
@@ -7,65 +7,141 @@ import (
 	"math"
 )
 
+// ATRSmoothing defines how true ranges are averaged into the ATR
+type ATRSmoothing string
+
+const (
+	// WilderSmoothing seeds the ATR with the average of the first Period true ranges and then
+	// smooths each further true range in as ATR = (prevATR*(Period-1) + TR) / Period
+	WilderSmoothing ATRSmoothing = "WILDER"
+	// SimpleSmoothing averages the last Period true ranges
+	SimpleSmoothing ATRSmoothing = "SMA"
+)
+
 // ATRConfig holds configuration for the Average True Range indicator
 type ATRConfig struct {
 	IndicatorConfig
+	Smoothing ATRSmoothing // Defaults to WilderSmoothing
 }
 
 // ATR implements the Average True Range indicator
 type ATR struct {
+	BaseIndicator
 	config ATRConfig
 }
 
 // NewATR creates a new Average True Range indicator instance
 func NewATR(config ATRConfig) *ATR {
+	if config.Smoothing == "" {
+		config.Smoothing = WilderSmoothing
+	}
 	return &ATR{
-		config: config,
+		BaseIndicator: BaseIndicator{Config: config.IndicatorConfig},
+		config:        config,
 	}
 }
 
-// Calculate computes the Average True Range value for the given klines
+// Name returns the name of the indicator
+func (a *ATR) Name() string {
+	if a.config.Smoothing == SimpleSmoothing {
+		return "SMA-ATR"
+	}
+	return "ATR"
+}
+
+// RequiredDataPoints returns the minimum number of klines needed for calculation. Each true
+// range needs the previous close, so Period true ranges take Period+1 klines
+func (a *ATR) RequiredDataPoints() int {
+	return a.Config.Period + 1
+}
+
+// Calculate computes the Average True Range value for the given klines. With Wilder smoothing
+// the result depends on the whole series, so the same value is only reproduced from the same
+// starting kline
 func (a *ATR) Calculate(ctx context.Context, klines []*domain.Kline) (float64, error) {
-	period := a.config.Period
+	period := a.Config.Period
+	if period <= 0 {
+		return 0, fmt.Errorf("invalid ATR period %d", period)
+	}
 	if len(klines) < period+1 {
 		return 0, fmt.Errorf("not enough data points for ATR calculation: need %d, got %d", period+1, len(klines))
 	}
 
-	// Calculate true ranges
-	trueRanges := make([]float64, len(klines))
-
-	// First TR is just the high-low range
-	trueRanges[0] = klines[0].High - klines[0].Low
+	switch a.config.Smoothing {
+	case WilderSmoothing:
+		stream := NewATRStream(a.config)
+		var atr float64
+		for _, kline := range klines {
+			atr, _ = stream.Update(kline)
+		}
+		return atr, nil
+	case SimpleSmoothing:
+		sum := 0.0
+		for i := len(klines) - period; i < len(klines); i++ {
+			sum += TrueRange(klines[i], klines[i-1].Close)
+		}
+		return sum / float64(period), nil
+	default:
+		return 0, fmt.Errorf("unsupported ATR smoothing: %s", a.config.Smoothing)
+	}
+}
 
-	// Calculate subsequent TRs
-	for i := 1; i < len(klines); i++ {
-		high := klines[i].High
-		low := klines[i].Low
-		prevClose := klines[i-1].Close
+// TrueRange returns the greatest of the kline's high-low range and the distances of its high
+// and low from the previous close
+func TrueRange(kline *domain.Kline, prevClose float64) float64 {
+	return math.Max(kline.High-kline.Low, math.Max(math.Abs(kline.High-prevClose), math.Abs(kline.Low-prevClose)))
+}
 
-		// True Range is the greatest of:
-		// 1. Current High - Current Low
-		// 2. |Current High - Previous Close|
-		// 3. |Current Low - Previous Close|
-		tr1 := high - low
-		tr2 := math.Abs(high - prevClose)
-		tr3 := math.Abs(low - prevClose)
+// ATRStream computes the ATR incrementally, one kline at a time, without keeping the history.
+// Fed the same klines, it returns the same values as ATR.Calculate
+type ATRStream struct {
+	period    int
+	smoothing ATRSmoothing
+	prevClose float64
+	klines    int       // Klines seen so far
+	ranges    []float64 // Last Period true ranges (SMA only)
+	sum       float64   // Sum of ranges, or of the seed's true ranges (Wilder)
+	atr       float64
+}
 
-		trueRanges[i] = math.Max(tr1, math.Max(tr2, tr3))
+// NewATRStream creates an incremental ATR with the given configuration
+func NewATRStream(config ATRConfig) *ATRStream {
+	smoothing := config.Smoothing
+	if smoothing == "" {
+		smoothing = WilderSmoothing
 	}
+	return &ATRStream{period: config.Period, smoothing: smoothing}
+}
 
-	// Calculate ATR using Wilder's smoothing method
-	// First ATR is simple average of first 'period' true ranges
-	atr := 0.0
-	for i := 0; i < period; i++ {
-		atr += trueRanges[i]
+// Update adds the next kline and returns the current ATR. ready is false until Period+1 klines
+// have been seen
+func (s *ATRStream) Update(kline *domain.Kline) (atr float64, ready bool) {
+	if s.period <= 0 {
+		return 0, false
 	}
-	atr /= float64(period)
-
-	// Apply smoothing formula for remaining periods
-	for i := period; i < len(klines); i++ {
-		atr = (atr*float64(period-1) + trueRanges[i]) / float64(period)
+	s.klines++
+	prevClose := s.prevClose
+	s.prevClose = kline.Close
+	if s.klines == 1 {
+		return 0, false // No previous close yet
 	}
+	tr := TrueRange(kline, prevClose)
 
-	return atr, nil
+	switch {
+	case s.smoothing == SimpleSmoothing:
+		s.ranges = append(s.ranges, tr)
+		s.sum += tr
+		if len(s.ranges) > s.period {
+			s.sum -= s.ranges[0]
+			s.ranges = s.ranges[1:]
+		}
+		s.atr = s.sum / float64(len(s.ranges))
+	case s.klines <= s.period+1:
+		// Wilder seed: simple average of the first Period true ranges
+		s.sum += tr
+		s.atr = s.sum / float64(s.klines-1)
+	default:
+		s.atr = (s.atr*float64(s.period-1) + tr) / float64(s.period)
+	}
+	return s.atr, s.klines > s.period
 }
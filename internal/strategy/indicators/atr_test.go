@@ -0,0 +1,142 @@
+package indicators
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+// atrReferenceKlines are the first bars of the classic StockCharts ATR example (QQQ, April 2010).
+// Their true ranges are 0.58, 0.51, 0.50, 0.58, 0.41 and 0.26
+func atrReferenceKlines() []*domain.Kline {
+	now := time.Now()
+	prices := [][3]float64{ // High, low, close
+		{48.70, 47.79, 48.16},
+		{48.72, 48.14, 48.61},
+		{48.90, 48.39, 48.75},
+		{48.87, 48.37, 48.63},
+		{48.82, 48.24, 48.74},
+		{49.05, 48.64, 49.03},
+		{49.20, 48.94, 49.07},
+	}
+	klines := make([]*domain.Kline, len(prices))
+	for i, p := range prices {
+		klines[i] = &domain.Kline{
+			OpenTime: now.Add(time.Duration(i-len(prices)) * time.Hour),
+			High:     p[0],
+			Low:      p[1],
+			Close:    p[2],
+		}
+	}
+	return klines
+}
+
+func TestATR_Calculate(t *testing.T) {
+	klines := atrReferenceKlines()
+
+	tests := []struct {
+		name          string
+		config        ATRConfig
+		klines        []*domain.Kline
+		expectedValue float64
+		expectError   bool
+	}{
+		{
+			name:          "Wilder seed only",
+			config:        ATRConfig{IndicatorConfig: IndicatorConfig{Period: 3}},
+			klines:        klines[:4],
+			expectedValue: 0.53, // (0.58 + 0.51 + 0.50) / 3
+		},
+		{
+			name:          "Wilder smoothing",
+			config:        ATRConfig{IndicatorConfig: IndicatorConfig{Period: 3}, Smoothing: WilderSmoothing},
+			klines:        klines,
+			expectedValue: 0.420741, // 0.53 -> 0.546667 -> 0.501111 -> 0.420741
+		},
+		{
+			name:          "SMA",
+			config:        ATRConfig{IndicatorConfig: IndicatorConfig{Period: 3}, Smoothing: SimpleSmoothing},
+			klines:        klines,
+			expectedValue: 0.416667, // (0.58 + 0.41 + 0.26) / 3
+		},
+		{
+			name:        "Insufficient data",
+			config:      ATRConfig{IndicatorConfig: IndicatorConfig{Period: 7}},
+			klines:      klines,
+			expectError: true,
+		},
+		{
+			name:        "Invalid period",
+			config:      ATRConfig{IndicatorConfig: IndicatorConfig{Period: 0}},
+			klines:      klines,
+			expectError: true,
+		},
+		{
+			name:        "Unsupported smoothing",
+			config:      ATRConfig{IndicatorConfig: IndicatorConfig{Period: 3}, Smoothing: "EMA"},
+			klines:      klines,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atr := NewATR(tt.config)
+			value, err := atr.Calculate(context.Background(), tt.klines)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if math.Abs(value-tt.expectedValue) > 0.000001 {
+				t.Errorf("expected %f, got %f", tt.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestATRStream_MatchesCalculate(t *testing.T) {
+	klines := atrReferenceKlines()
+
+	for _, smoothing := range []ATRSmoothing{WilderSmoothing, SimpleSmoothing} {
+		t.Run(string(smoothing), func(t *testing.T) {
+			config := ATRConfig{IndicatorConfig: IndicatorConfig{Period: 3}, Smoothing: smoothing}
+			atr := NewATR(config)
+			stream := NewATRStream(config)
+
+			for i, kline := range klines {
+				value, ready := stream.Update(kline)
+				if ready != (i+1 >= atr.RequiredDataPoints()) {
+					t.Fatalf("kline %d: ready = %v", i, ready)
+				}
+				if !ready {
+					continue
+				}
+				expected, err := atr.Calculate(context.Background(), klines[:i+1])
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if math.Abs(value-expected) > 1e-9 {
+					t.Errorf("kline %d: stream %f, batch %f", i, value, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestATR_Name(t *testing.T) {
+	if name := NewATR(ATRConfig{}).Name(); name != "ATR" {
+		t.Errorf("expected ATR, got %s", name)
+	}
+	if name := NewATR(ATRConfig{Smoothing: SimpleSmoothing}).Name(); name != "SMA-ATR" {
+		t.Errorf("expected SMA-ATR, got %s", name)
+	}
+}
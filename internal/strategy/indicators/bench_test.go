@@ -0,0 +1,101 @@
+package indicators
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/utils"
+	"os"
+	"sync"
+	"testing"
+)
+
+// benchWindow is the trailing kline window each per-bar Calculate call receives
+const benchWindow = 500
+
+var (
+	benchKlinesOnce sync.Once
+	benchKlines     []*domain.Kline
+	benchKlinesErr  error
+)
+
+// loadBenchKlines loads the bundled kline fixture once per test binary. BENCH_KLINES overrides
+// its path (cmd/bench sets it)
+func loadBenchKlines(b *testing.B) []*domain.Kline {
+	b.Helper()
+	benchKlinesOnce.Do(func() {
+		path := os.Getenv("BENCH_KLINES")
+		if path == "" {
+			path = "../testdata/ETHUSDT_15m_bench.csv.gz"
+		}
+		benchKlines, benchKlinesErr = utils.ReadKlinesFromCSV(path)
+	})
+	if benchKlinesErr != nil {
+		b.Fatalf("Failed to load benchmark klines: %v", benchKlinesErr)
+	}
+	return benchKlines
+}
+
+// benchmarkCalculate runs one pass over the fixture, calculating the indicator on the trailing
+// window at every bar the way a strategy does, and reports the cost per bar
+func benchmarkCalculate(b *testing.B, indicator Indicator) {
+	klines := loadBenchKlines(b)
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := benchWindow; i <= len(klines); i++ {
+			if _, err := indicator.Calculate(ctx, klines[i-benchWindow:i]); err != nil {
+				b.Fatalf("Calculate failed at bar %d: %v", i, err)
+			}
+		}
+	}
+	reportPerBar(b, len(klines)-benchWindow+1)
+}
+
+// reportPerBar adds an ns/bar metric for benchmarks processing bars klines per iteration
+func reportPerBar(b *testing.B, bars int) {
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*bars), "ns/bar")
+}
+
+func BenchmarkATR(b *testing.B) {
+	benchmarkCalculate(b, NewATR(ATRConfig{IndicatorConfig: IndicatorConfig{Period: 14}}))
+}
+
+func BenchmarkATR_SMA(b *testing.B) {
+	benchmarkCalculate(b, NewATR(ATRConfig{IndicatorConfig: IndicatorConfig{Period: 14}, Smoothing: SimpleSmoothing}))
+}
+
+func BenchmarkATRStream(b *testing.B) {
+	klines := loadBenchKlines(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		stream := NewATRStream(ATRConfig{IndicatorConfig: IndicatorConfig{Period: 14}})
+		for _, k := range klines {
+			stream.Update(k)
+		}
+	}
+	reportPerBar(b, len(klines))
+}
+
+func BenchmarkSMA(b *testing.B) {
+	benchmarkCalculate(b, NewMovingAverage(MovingAverageConfig{IndicatorConfig: IndicatorConfig{Period: 21}, Type: SimpleMovingAverage}))
+}
+
+func BenchmarkEMA(b *testing.B) {
+	benchmarkCalculate(b, NewMovingAverage(MovingAverageConfig{IndicatorConfig: IndicatorConfig{Period: 21}, Type: ExponentialMovingAverage}))
+}
+
+func BenchmarkRSI(b *testing.B) {
+	benchmarkCalculate(b, NewRSI(RSIConfig{IndicatorConfig: IndicatorConfig{Period: 14}, Overbought: 70, Oversold: 30}))
+}
+
+func BenchmarkVolumeProfile(b *testing.B) {
+	benchmarkCalculate(b, NewVolumeProfile(VolumeProfileConfig{IndicatorConfig: IndicatorConfig{Period: 96}, Buckets: 24}))
+}
+
+// BenchmarkEMA_Cached measures cache hits: after the first pass every window is cached, as for
+// the optimizer's parameter combinations sharing an EMA period
+func BenchmarkEMA_Cached(b *testing.B) {
+	benchmarkCalculate(b, NewCache(0).Wrap(NewMovingAverage(MovingAverageConfig{IndicatorConfig: IndicatorConfig{Period: 21}, Type: ExponentialMovingAverage})))
+}
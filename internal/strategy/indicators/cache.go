@@ -0,0 +1,155 @@
+package indicators
+
+import (
+	"container/list"
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"sync"
+)
+
+// DefaultCacheSize is the number of values a cache holds when NewCache is given no size
+const DefaultCacheSize = 100_000
+
+// CacheStats counts the lookups of a Cache
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// HitRate returns the share of lookups answered from the cache, from 0 to 1
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// cacheKey identifies an indicator value: the indicator (by name and required data points, which
+// include its period) and the kline window it was calculated on. EMA, RSI and Wilder's ATR depend
+// on the whole window, so it's identified by its length and first kline as well as its last one,
+// whose close is included since a kline still forming keeps its open time while its close moves
+type cacheKey struct {
+	indicator string
+	required  int
+	symbol    string
+	interval  string
+	length    int
+	first     int64 // Open times in Unix nanoseconds
+	last      int64
+	lastClose float64
+}
+
+// cacheEntry is a cached value and its key, for removal on eviction
+type cacheEntry struct {
+	key   cacheKey
+	value float64
+}
+
+// Cache memoizes indicator values by kline window, so strategy instances calculating the same
+// indicator on the same klines (e.g., the optimizer's parameter combinations) compute it once.
+// It holds at most its size in values, evicting the least recently used. Safe for concurrent use
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[cacheKey]*list.Element
+	order   *list.List // Most recently used first
+	stats   CacheStats
+}
+
+// NewCache creates a cache holding up to size values (0 or less uses DefaultCacheSize)
+func NewCache(size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{size: size, entries: make(map[cacheKey]*list.Element), order: list.New()}
+}
+
+// Wrap returns indicator with its values memoized in the cache. Indicators are identified by
+// Name and RequiredDataPoints, so indicators sharing both must calculate the same values (true of
+// the package's moving averages, RSI and ATR). A nil cache returns indicator unchanged
+func (c *Cache) Wrap(indicator Indicator) Indicator {
+	if c == nil || indicator == nil {
+		return indicator
+	}
+	return &cachedIndicator{Indicator: indicator, cache: c}
+}
+
+// Stats returns the cache's lookup counters
+func (c *Cache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+// get returns the value cached under key, if any
+func (c *Cache) get(key cacheKey) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return 0, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).value, true
+}
+
+// put caches value under key, evicting the least recently used value when the cache is full
+func (c *Cache) put(key cacheKey, value float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).value = value // Calculated concurrently by another caller
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// cachedIndicator is an Indicator whose values are memoized in a Cache
+type cachedIndicator struct {
+	Indicator
+	cache *Cache
+}
+
+// Calculate returns the cached value for the klines, calculating and caching it on a miss.
+// Errors are not cached
+func (i *cachedIndicator) Calculate(ctx context.Context, klines []*domain.Kline) (float64, error) {
+	if len(klines) == 0 {
+		return i.Indicator.Calculate(ctx, klines)
+	}
+	first, last := klines[0], klines[len(klines)-1]
+	key := cacheKey{
+		indicator: i.Name(),
+		required:  i.RequiredDataPoints(),
+		symbol:    last.Symbol,
+		interval:  last.Interval,
+		length:    len(klines),
+		first:     first.OpenTime.UnixNano(),
+		last:      last.OpenTime.UnixNano(),
+		lastClose: last.Close,
+	}
+	if value, ok := i.cache.get(key); ok {
+		return value, nil
+	}
+	value, err := i.Indicator.Calculate(ctx, klines)
+	if err != nil {
+		return 0, err
+	}
+	i.cache.put(key, value)
+	return value, nil
+}
@@ -0,0 +1,143 @@
+package indicators
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingIndicator counts its Calculate calls
+type countingIndicator struct {
+	Indicator
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingIndicator) Calculate(ctx context.Context, klines []*domain.Kline) (float64, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	return c.Indicator.Calculate(ctx, klines)
+}
+
+func cacheKlines(n int) []*domain.Kline {
+	start := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, n)
+	for i := range klines {
+		klines[i] = &domain.Kline{Symbol: "ETHUSDT", Interval: "1h", OpenTime: start.Add(time.Duration(i) * time.Hour), Close: 100 + float64(i%7)}
+	}
+	return klines
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	klines := cacheKlines(30)
+	cache := NewCache(0)
+	ema := func() *countingIndicator {
+		return &countingIndicator{Indicator: NewMovingAverage(MovingAverageConfig{IndicatorConfig: IndicatorConfig{Period: 5}, Type: ExponentialMovingAverage})}
+	}
+	first, second := ema(), ema()
+
+	expected, _ := first.Indicator.Calculate(ctx, klines)
+	for _, indicator := range []Indicator{cache.Wrap(first), cache.Wrap(second)} {
+		if value, err := indicator.Calculate(ctx, klines); err != nil || value != expected {
+			t.Fatalf("Expected %f, got %f (%v)", expected, value, err)
+		}
+	}
+	if first.calls != 1 || second.calls != 0 {
+		t.Errorf("Expected the second instance to be served from the cache, got %d and %d calls", first.calls, second.calls)
+	}
+
+	// A window ending at the same kline but starting later is a different EMA
+	wrapped := cache.Wrap(second)
+	if value, _ := wrapped.Calculate(ctx, klines[10:]); second.calls != 1 || value == expected {
+		t.Errorf("Expected a shorter window to be calculated, got %f after %d calls", value, second.calls)
+	}
+
+	// So is the forming kline once its close moved
+	moved := append(append([]*domain.Kline(nil), klines[:29]...), &domain.Kline{Symbol: "ETHUSDT", Interval: "1h", OpenTime: klines[29].OpenTime, Close: 150})
+	if _, err := wrapped.Calculate(ctx, moved); err != nil || second.calls != 2 {
+		t.Errorf("Expected a moved close to be calculated, got %d calls (%v)", second.calls, err)
+	}
+
+	// Other periods and indicators are cached separately
+	rsi := &countingIndicator{Indicator: NewRSI(RSIConfig{IndicatorConfig: IndicatorConfig{Period: 5}})}
+	if _, err := cache.Wrap(rsi).Calculate(ctx, klines); err != nil || rsi.calls != 1 {
+		t.Errorf("Expected the RSI to be calculated, got %d calls (%v)", rsi.calls, err)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 4 || stats.Entries != 4 || stats.HitRate() != 0.2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	klines := cacheKlines(10)
+	cache := NewCache(2)
+	sma := &countingIndicator{Indicator: NewMovingAverage(MovingAverageConfig{IndicatorConfig: IndicatorConfig{Period: 3}, Type: SimpleMovingAverage})}
+	wrapped := cache.Wrap(sma)
+
+	wrapped.Calculate(ctx, klines[:5])
+	wrapped.Calculate(ctx, klines[:6])
+	wrapped.Calculate(ctx, klines[:5]) // Hit, now the most recently used
+	wrapped.Calculate(ctx, klines[:7]) // Evicts klines[:6]
+	if sma.calls != 3 {
+		t.Fatalf("Expected 3 calculations, got %d", sma.calls)
+	}
+	wrapped.Calculate(ctx, klines[:5])
+	wrapped.Calculate(ctx, klines[:6])
+	if sma.calls != 4 {
+		t.Errorf("Expected only the evicted window to be recalculated, got %d calculations", sma.calls)
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Evictions != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCacheSkipsErrorsAndNil(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache(10)
+	sma := &countingIndicator{Indicator: NewMovingAverage(MovingAverageConfig{IndicatorConfig: IndicatorConfig{Period: 5}, Type: SimpleMovingAverage})}
+	wrapped := cache.Wrap(sma)
+	for i := 0; i < 2; i++ {
+		if _, err := wrapped.Calculate(ctx, cacheKlines(3)); err == nil {
+			t.Fatal("Expected an error for too few klines")
+		}
+	}
+	if sma.calls != 2 || cache.Stats().Entries != 0 {
+		t.Errorf("Expected errors not to be cached, got %d calls and %+v", sma.calls, cache.Stats())
+	}
+
+	var disabled *Cache
+	if disabled.Wrap(sma) != Indicator(sma) || disabled.Stats() != (CacheStats{}) {
+		t.Error("Expected a nil cache to leave the indicator unchanged")
+	}
+}
+
+func TestCacheConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	klines := cacheKlines(50)
+	cache := NewCache(16)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			atr := cache.Wrap(NewATR(ATRConfig{IndicatorConfig: IndicatorConfig{Period: 5}}))
+			for i := 6; i <= len(klines); i++ {
+				if _, err := atr.Calculate(ctx, klines[:i]); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := cache.Stats(); stats.Hits+stats.Misses != 4*45 || stats.Entries != 16 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
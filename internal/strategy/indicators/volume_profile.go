@@ -0,0 +1,165 @@
+package indicators
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"fmt"
+	"math"
+)
+
+// VolumeProfileConfig holds configuration for the volume profile indicator
+type VolumeProfileConfig struct {
+	IndicatorConfig         // Period is the rolling window of klines the profile is built from
+	Buckets         int     // Number of equal price buckets the window's range is split into (default 24)
+	HighVolumeRatio float64 // Buckets with at least this multiple of the mean bucket volume are high volume nodes (default 1.5)
+	LowVolumeRatio  float64 // Buckets with at most this multiple of the mean bucket volume are low volume nodes (default 0.5)
+}
+
+// VolumeNode is a price bucket of the volume profile
+type VolumeNode struct {
+	Low    float64
+	High   float64
+	Volume float64
+}
+
+// Mid returns the middle price of the bucket
+func (n VolumeNode) Mid() float64 {
+	return (n.Low + n.High) / 2
+}
+
+// Contains reports whether price lies within the bucket
+func (n VolumeNode) Contains(price float64) bool {
+	return price >= n.Low && price <= n.High
+}
+
+// VolumeProfileResult is a volume-by-price histogram over the indicator's window
+type VolumeProfileResult struct {
+	Nodes           []VolumeNode // All buckets, lowest price first
+	PointOfControl  VolumeNode   // Bucket with the most volume
+	HighVolumeNodes []VolumeNode // Buckets where price was accepted, acting as support/resistance (lowest first)
+	LowVolumeNodes  []VolumeNode // Buckets price moved through quickly (lowest first)
+}
+
+// ResistanceAbove returns the nearest high volume node at or above price, i.e. one containing
+// price or lying entirely above it
+func (r *VolumeProfileResult) ResistanceAbove(price float64) (VolumeNode, bool) {
+	for _, node := range r.HighVolumeNodes {
+		if node.High >= price {
+			return node, true
+		}
+	}
+	return VolumeNode{}, false
+}
+
+// VolumeProfile implements a rolling volume profile (volume traded at each price level)
+type VolumeProfile struct {
+	BaseIndicator
+	config VolumeProfileConfig
+}
+
+// NewVolumeProfile creates a new volume profile indicator instance
+func NewVolumeProfile(config VolumeProfileConfig) *VolumeProfile {
+	if config.Buckets <= 0 {
+		config.Buckets = 24
+	}
+	if config.HighVolumeRatio <= 0 {
+		config.HighVolumeRatio = 1.5
+	}
+	if config.LowVolumeRatio <= 0 {
+		config.LowVolumeRatio = 0.5
+	}
+	return &VolumeProfile{
+		BaseIndicator: BaseIndicator{Config: config.IndicatorConfig},
+		config:        config,
+	}
+}
+
+// Name returns the name of the indicator
+func (v *VolumeProfile) Name() string {
+	return "VolumeProfile"
+}
+
+// Calculate returns the point of control, the middle price of the bucket with the most volume
+func (v *VolumeProfile) Calculate(ctx context.Context, klines []*domain.Kline) (float64, error) {
+	profile, err := v.Profile(ctx, klines)
+	if err != nil {
+		return 0, err
+	}
+	return profile.PointOfControl.Mid(), nil
+}
+
+// Profile builds the volume profile over the last Period klines. Each kline's volume is spread
+// evenly over its high-low range, so a bucket receives the share of the range it overlaps
+func (v *VolumeProfile) Profile(ctx context.Context, klines []*domain.Kline) (*VolumeProfileResult, error) {
+	period := v.Config.Period
+	if period <= 0 || len(klines) < period {
+		return nil, fmt.Errorf("not enough data (%d) to calculate volume profile for period %d", len(klines), period)
+	}
+	window := klines[len(klines)-period:]
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, k := range window {
+		low = math.Min(low, k.Low)
+		high = math.Max(high, k.High)
+	}
+	if high <= low {
+		return nil, fmt.Errorf("price range is empty, cannot build volume profile")
+	}
+
+	buckets := v.config.Buckets
+	size := (high - low) / float64(buckets)
+	nodes := make([]VolumeNode, buckets)
+	for i := range nodes {
+		nodes[i].Low = low + float64(i)*size
+		nodes[i].High = low + float64(i+1)*size
+	}
+
+	var total float64
+	for _, k := range window {
+		if k.Volume <= 0 {
+			continue
+		}
+		total += k.Volume
+		if k.High <= k.Low {
+			nodes[bucketIndex(k.Close, low, size, buckets)].Volume += k.Volume
+			continue
+		}
+		first := bucketIndex(k.Low, low, size, buckets)
+		last := bucketIndex(k.High, low, size, buckets)
+		for i := first; i <= last; i++ {
+			overlap := math.Min(k.High, nodes[i].High) - math.Max(k.Low, nodes[i].Low)
+			if overlap > 0 {
+				nodes[i].Volume += k.Volume * overlap / (k.High - k.Low)
+			}
+		}
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no volume traded in the last %d klines", period)
+	}
+
+	result := &VolumeProfileResult{Nodes: nodes}
+	mean := total / float64(buckets)
+	for _, node := range nodes {
+		if node.Volume > result.PointOfControl.Volume {
+			result.PointOfControl = node
+		}
+		if node.Volume >= mean*v.config.HighVolumeRatio {
+			result.HighVolumeNodes = append(result.HighVolumeNodes, node)
+		} else if node.Volume <= mean*v.config.LowVolumeRatio {
+			result.LowVolumeNodes = append(result.LowVolumeNodes, node)
+		}
+	}
+	return result, nil
+}
+
+// bucketIndex returns the bucket containing price, clamped to the profile's range
+func bucketIndex(price, low, size float64, buckets int) int {
+	i := int((price - low) / size)
+	if i < 0 {
+		return 0
+	}
+	if i >= buckets {
+		return buckets - 1
+	}
+	return i
+}
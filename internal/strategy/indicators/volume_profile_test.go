@@ -0,0 +1,93 @@
+package indicators
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"math"
+	"testing"
+)
+
+func TestVolumeProfile_Profile(t *testing.T) {
+	klines := []*domain.Kline{
+		{Low: 50, High: 60, Close: 55, Volume: 1000}, // Outside the window
+		{Low: 100, High: 110, Close: 105, Volume: 10},
+		{Low: 104, High: 106, Close: 105, Volume: 20},
+		{Low: 105, High: 105, Close: 105, Volume: 5}, // No range, all volume at the close
+	}
+	vp := NewVolumeProfile(VolumeProfileConfig{IndicatorConfig: IndicatorConfig{Period: 3}, Buckets: 10})
+
+	profile, err := vp.Profile(context.Background(), klines)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(profile.Nodes) != 10 || profile.Nodes[0].Low != 100 || profile.Nodes[9].High != 110 {
+		t.Fatalf("Expected 10 buckets from 100 to 110, got %+v", profile.Nodes)
+	}
+
+	var total float64
+	for _, node := range profile.Nodes {
+		total += node.Volume
+	}
+	if math.Abs(total-35) > 1e-9 {
+		t.Errorf("Expected the window's 35 volume to be distributed, got %f", total)
+	}
+
+	if profile.PointOfControl.Low != 105 || math.Abs(profile.PointOfControl.Volume-16) > 1e-9 {
+		t.Errorf("Expected point of control at 105-106 with volume 16, got %+v", profile.PointOfControl)
+	}
+	if len(profile.HighVolumeNodes) != 2 || profile.HighVolumeNodes[0].Low != 104 {
+		t.Errorf("Expected high volume nodes at 104 and 105, got %+v", profile.HighVolumeNodes)
+	}
+	if len(profile.LowVolumeNodes) != 8 {
+		t.Errorf("Expected 8 low volume nodes, got %d", len(profile.LowVolumeNodes))
+	}
+
+	poc, err := vp.Calculate(context.Background(), klines)
+	if err != nil || poc != 105.5 {
+		t.Errorf("Expected Calculate to return point of control 105.5, got %f (err %v)", poc, err)
+	}
+}
+
+func TestVolumeProfile_Zones(t *testing.T) {
+	profile := &VolumeProfileResult{HighVolumeNodes: []VolumeNode{
+		{Low: 100, High: 101, Volume: 10},
+		{Low: 105, High: 106, Volume: 10},
+	}}
+
+	tests := []struct {
+		name       string
+		price      float64
+		resistance float64 // Low of the expected node, 0 for none
+	}{
+		{name: "between zones", price: 103, resistance: 105},
+		{name: "inside a zone", price: 105.5, resistance: 105},
+		{name: "above all zones", price: 107, resistance: 0},
+		{name: "below all zones", price: 99, resistance: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, ok := profile.ResistanceAbove(tt.price)
+			if ok != (tt.resistance != 0) || node.Low != tt.resistance {
+				t.Errorf("Expected resistance at %f, got %+v (found %v)", tt.resistance, node, ok)
+			}
+		})
+	}
+}
+
+func TestVolumeProfile_Errors(t *testing.T) {
+	vp := NewVolumeProfile(VolumeProfileConfig{IndicatorConfig: IndicatorConfig{Period: 3}})
+
+	if _, err := vp.Profile(context.Background(), []*domain.Kline{{Low: 1, High: 2, Volume: 1}}); err == nil {
+		t.Error("Expected an error for insufficient data")
+	}
+
+	flat := []*domain.Kline{
+		{Low: 100, High: 100, Close: 100, Volume: 1},
+		{Low: 100, High: 100, Close: 100, Volume: 1},
+		{Low: 100, High: 100, Close: 100, Volume: 1},
+	}
+	if _, err := vp.Profile(context.Background(), flat); err == nil {
+		t.Error("Expected an error for an empty price range")
+	}
+}
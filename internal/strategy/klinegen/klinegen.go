@@ -0,0 +1,225 @@
+// Package klinegen generates deterministic synthetic kline series out of market regimes
+// (uptrends, downtrends, chop, volatility spikes and gaps), so strategy tests can assert
+// behavior per regime instead of hand-crafting price arrays
+package klinegen
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Regime identifies the market behavior of a segment
+type Regime string
+
+const (
+	RegimeUptrend         Regime = "uptrend"
+	RegimeDowntrend       Regime = "downtrend"
+	RegimeChop            Regime = "chop"
+	RegimeVolatilitySpike Regime = "volatility_spike"
+	RegimeGap             Regime = "gap"
+	RegimeMissing         Regime = "missing"
+)
+
+// defaultChopPeriod is the number of bars per up-and-down cycle of chop
+const defaultChopPeriod = 8
+
+// Segment is a stretch of the series in a single regime. Build segments with Uptrend,
+// Downtrend, Chop, VolatilitySpike, Gap and MissingBars
+type Segment struct {
+	Regime     Regime
+	Bars       int     // Klines in the segment (intervals skipped for RegimeMissing)
+	Drift      float64 // Per-bar return of trends (e.g., 0.002 for 0.2%)
+	Amplitude  float64 // Swing of chop around its starting price (e.g., 0.01 for 1%)
+	Period     int     // Bars per chop cycle
+	Multiplier float64 // Noise and volume multiplier of volatility spikes
+	Jump       float64 // Price jump of a gap from the previous close (e.g., -0.03 for 3% down)
+}
+
+// Uptrend rises by drift per bar on average
+func Uptrend(bars int, drift float64) Segment {
+	return Segment{Regime: RegimeUptrend, Bars: bars, Drift: math.Abs(drift)}
+}
+
+// Downtrend falls by drift per bar on average
+func Downtrend(bars int, drift float64) Segment {
+	return Segment{Regime: RegimeDowntrend, Bars: bars, Drift: -math.Abs(drift)}
+}
+
+// Chop oscillates around the price it starts at, swinging amplitude up and down every cycle
+func Chop(bars int, amplitude float64) Segment {
+	return Segment{Regime: RegimeChop, Bars: bars, Amplitude: math.Abs(amplitude), Period: defaultChopPeriod}
+}
+
+// VolatilitySpike moves sideways with multiplier times the usual noise and volume
+func VolatilitySpike(bars int, multiplier float64) Segment {
+	return Segment{Regime: RegimeVolatilitySpike, Bars: bars, Multiplier: multiplier}
+}
+
+// Gap is a single kline opening jump away from the previous close
+func Gap(jump float64) Segment {
+	return Segment{Regime: RegimeGap, Bars: 1, Jump: jump}
+}
+
+// MissingBars skips bars intervals without klines, like an exchange outage in recorded data.
+// The next kline opens at the last close
+func MissingBars(bars int) Segment {
+	return Segment{Regime: RegimeMissing, Bars: bars}
+}
+
+// Config holds the settings shared by all segments of a series
+type Config struct {
+	Symbol     string        // Symbol of the klines (defaults to ETHUSDT)
+	Interval   time.Duration // Kline interval (defaults to 15m)
+	Start      time.Time     // Open time of the first kline (defaults to 2024-01-01 UTC)
+	StartPrice float64       // Open of the first kline (defaults to 2000)
+	Volatility float64       // Per-bar return noise (defaults to 0.001; negative disables noise)
+	Volume     float64       // Average volume per kline (defaults to 1000)
+	Seed       int64         // Seed of the noise; the same config and segments give the same series
+}
+
+// Span is the index range [Start, End) of a segment's klines in a series
+type Span struct {
+	Regime Regime
+	Start  int
+	End    int
+}
+
+// Series is a generated kline series with the span of each segment
+type Series struct {
+	Klines []*domain.Kline
+	Spans  []Span // One per segment, in order
+}
+
+// Through returns the klines up to and including the last kline of the given segment, which is
+// the window a strategy sees at the end of that segment
+func (s *Series) Through(segment int) []*domain.Kline {
+	return s.Klines[:s.Spans[segment].End]
+}
+
+// LastClose returns the close of the last kline of the series
+func (s *Series) LastClose() float64 {
+	if len(s.Klines) == 0 {
+		return 0
+	}
+	return s.Klines[len(s.Klines)-1].Close
+}
+
+// Generate builds a series out of the segments, in order. Each kline opens at the previous
+// close (except after a gap jump) and all klines are final
+func Generate(cfg Config, segments ...Segment) (*Series, error) {
+	if cfg.Symbol == "" {
+		cfg.Symbol = "ETHUSDT"
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = 15 * time.Minute
+	}
+	if cfg.Start.IsZero() {
+		cfg.Start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if cfg.StartPrice == 0 {
+		cfg.StartPrice = 2000
+	}
+	if cfg.Volatility == 0 {
+		cfg.Volatility = 0.001
+	} else if cfg.Volatility < 0 {
+		cfg.Volatility = 0
+	}
+	if cfg.Volume == 0 {
+		cfg.Volume = 1000
+	}
+	if cfg.Interval < 0 || cfg.StartPrice < 0 || cfg.Volume < 0 {
+		return nil, fmt.Errorf("interval, start price and volume must be positive")
+	}
+
+	g := &generator{cfg: cfg, rng: utils.NewRand(cfg.Seed), price: cfg.StartPrice, openTime: cfg.Start}
+	series := &Series{Spans: make([]Span, 0, len(segments))}
+	for i, seg := range segments {
+		if seg.Bars <= 0 {
+			return nil, fmt.Errorf("segment %d (%s): bar count must be positive", i, seg.Regime)
+		}
+		start := len(series.Klines)
+		switch seg.Regime {
+		case RegimeUptrend, RegimeDowntrend:
+			for b := 0; b < seg.Bars; b++ {
+				series.Klines = append(series.Klines, g.next(g.price, seg.Drift, 1))
+			}
+		case RegimeChop:
+			if seg.Period < 2 {
+				return nil, fmt.Errorf("segment %d (chop): period must be at least 2 bars", i)
+			}
+			anchor := g.price
+			for b := 0; b < seg.Bars; b++ {
+				target := anchor * (1 + seg.Amplitude*math.Sin(2*math.Pi*float64(b+1)/float64(seg.Period)))
+				series.Klines = append(series.Klines, g.next(g.price, math.Log(target/g.price), 1))
+			}
+		case RegimeVolatilitySpike:
+			if seg.Multiplier <= 0 {
+				return nil, fmt.Errorf("segment %d (volatility spike): multiplier must be positive", i)
+			}
+			for b := 0; b < seg.Bars; b++ {
+				series.Klines = append(series.Klines, g.next(g.price, 0, seg.Multiplier))
+			}
+		case RegimeGap:
+			if seg.Jump <= -1 {
+				return nil, fmt.Errorf("segment %d (gap): jump must be above -100%%", i)
+			}
+			series.Klines = append(series.Klines, g.next(g.price*(1+seg.Jump), 0, 1))
+		case RegimeMissing:
+			g.openTime = g.openTime.Add(time.Duration(seg.Bars) * cfg.Interval)
+		default:
+			return nil, fmt.Errorf("segment %d: unknown regime %q", i, seg.Regime)
+		}
+		series.Spans = append(series.Spans, Span{Regime: seg.Regime, Start: start, End: len(series.Klines)})
+	}
+	return series, nil
+}
+
+// generator holds the running state of a series being generated
+type generator struct {
+	cfg      Config
+	rng      *rand.Rand
+	price    float64
+	openTime time.Time
+}
+
+// next returns a kline that opens at open and moves by drift (a log return) plus noise scaled by
+// multiplier, and advances the price and time
+func (g *generator) next(open, drift, multiplier float64) *domain.Kline {
+	vol := g.cfg.Volatility * multiplier
+	closePrice := open * math.Exp(drift+vol*g.rng.NormFloat64())
+	high := math.Max(open, closePrice) * (1 + math.Abs(g.rng.NormFloat64())*vol/2)
+	low := math.Min(open, closePrice) * (1 - math.Abs(g.rng.NormFloat64())*vol/2)
+	volume := g.cfg.Volume * multiplier * (1 + math.Abs(g.rng.NormFloat64())/2)
+
+	kline := &domain.Kline{
+		OpenTime:  g.openTime,
+		CloseTime: g.openTime.Add(g.cfg.Interval - time.Millisecond),
+		Symbol:    g.cfg.Symbol,
+		Interval:  intervalName(g.cfg.Interval),
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     closePrice,
+		Volume:    volume,
+		IsFinal:   true,
+	}
+	g.price = closePrice
+	g.openTime = g.openTime.Add(g.cfg.Interval)
+	return kline
+}
+
+// intervalName returns the Binance name of an interval (e.g., "15m", "1h", "1d")
+func intervalName(interval time.Duration) string {
+	switch {
+	case interval%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", interval/(24*time.Hour))
+	case interval%time.Hour == 0:
+		return fmt.Sprintf("%dh", interval/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", interval/time.Minute)
+	}
+}
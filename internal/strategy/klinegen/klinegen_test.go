@@ -0,0 +1,142 @@
+package klinegen
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := Config{Seed: 7, Volatility: 0.002}
+	series, err := Generate(cfg,
+		Uptrend(50, 0.004),
+		Downtrend(50, 0.004),
+		Chop(40, 0.01),
+		VolatilitySpike(20, 5),
+		MissingBars(3),
+		Gap(-0.05),
+	)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(series.Klines) != 161 {
+		t.Fatalf("got %d klines, want 161", len(series.Klines))
+	}
+	wantSpans := []Span{
+		{RegimeUptrend, 0, 50},
+		{RegimeDowntrend, 50, 100},
+		{RegimeChop, 100, 140},
+		{RegimeVolatilitySpike, 140, 160},
+		{RegimeMissing, 160, 160},
+		{RegimeGap, 160, 161},
+	}
+	if !reflect.DeepEqual(series.Spans, wantSpans) {
+		t.Errorf("spans = %v, want %v", series.Spans, wantSpans)
+	}
+
+	for i, k := range series.Klines {
+		if k.High < math.Max(k.Open, k.Close) || k.Low > math.Min(k.Open, k.Close) || k.Low <= 0 {
+			t.Errorf("kline %d has an invalid range: %+v", i, k)
+		}
+		if k.Symbol != "ETHUSDT" || k.Interval != "15m" || !k.IsFinal {
+			t.Errorf("kline %d has unexpected defaults: %+v", i, k)
+		}
+		if i == 0 || i == 160 {
+			continue
+		}
+		if k.Open != series.Klines[i-1].Close {
+			t.Errorf("kline %d opens at %.4f, previous close %.4f", i, k.Open, series.Klines[i-1].Close)
+		}
+		if gap := k.OpenTime.Sub(series.Klines[i-1].OpenTime); gap != 15*time.Minute {
+			t.Errorf("kline %d opens %s after the previous one", i, gap)
+		}
+	}
+
+	closeAt := func(i int) float64 { return series.Klines[i].Close }
+	if closeAt(49) < series.Klines[0].Open*1.1 {
+		t.Errorf("uptrend ended at %.2f from %.2f, want a rise of more than 10%%", closeAt(49), series.Klines[0].Open)
+	}
+	if closeAt(99) > closeAt(49)*0.9 {
+		t.Errorf("downtrend ended at %.2f from %.2f, want a fall of more than 10%%", closeAt(99), closeAt(49))
+	}
+	for i := 100; i < 140; i++ {
+		if math.Abs(closeAt(i)/closeAt(99)-1) > 0.02 {
+			t.Errorf("chop kline %d closed at %.2f, more than 2%% from %.2f", i, closeAt(i), closeAt(99))
+		}
+	}
+	if avgRange(series, 140, 160) < 2*avgRange(series, 100, 140) {
+		t.Errorf("volatility spike range %.4f is not well above the chop range %.4f", avgRange(series, 140, 160), avgRange(series, 100, 140))
+	}
+
+	gap := series.Klines[160]
+	if math.Abs(gap.Open/closeAt(159)-0.95) > 1e-9 {
+		t.Errorf("gap opened at %.2f after a close of %.2f, want 5%% lower", gap.Open, closeAt(159))
+	}
+	if skipped := gap.OpenTime.Sub(series.Klines[159].OpenTime); skipped != 4*15*time.Minute {
+		t.Errorf("gap kline opens %s after the previous one, want 1h (3 missing bars)", skipped)
+	}
+
+	again, err := Generate(cfg, Uptrend(50, 0.004), Downtrend(50, 0.004), Chop(40, 0.01), VolatilitySpike(20, 5), MissingBars(3), Gap(-0.05))
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if !reflect.DeepEqual(series, again) {
+		t.Error("the same seed generated a different series")
+	}
+	if got := series.Through(0); len(got) != 50 || got[49] != series.Klines[49] {
+		t.Errorf("Through(0) returned %d klines, want the 50 uptrend klines", len(got))
+	}
+}
+
+func TestGenerateWithoutNoise(t *testing.T) {
+	series, err := Generate(Config{Volatility: -1, StartPrice: 100, Interval: time.Hour}, Uptrend(10, 0.01), Chop(8, 0.02))
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for i, k := range series.Klines[:10] {
+		want := 100 * math.Exp(0.01*float64(i+1))
+		if math.Abs(k.Close-want) > 1e-9 || k.High != k.Close || k.Low != k.Open {
+			t.Errorf("kline %d = %+v, want a clean rise to %.4f", i, k, want)
+		}
+	}
+	anchor := series.Klines[9].Close
+	if peak := series.Klines[11].Close; math.Abs(peak-anchor*1.02) > 1e-9 {
+		t.Errorf("chop peaked at %.4f, want %.4f", peak, anchor*1.02)
+	}
+	if end := series.LastClose(); math.Abs(end-anchor) > 1e-9 {
+		t.Errorf("chop ended its cycle at %.4f, want %.4f", end, anchor)
+	}
+	if series.Klines[0].Interval != "1h" {
+		t.Errorf("interval = %s, want 1h", series.Klines[0].Interval)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []Segment
+	}{
+		{"empty segment", []Segment{Uptrend(0, 0.01)}},
+		{"chop without a cycle", []Segment{{Regime: RegimeChop, Bars: 10, Period: 1}}},
+		{"non-positive spike", []Segment{VolatilitySpike(10, 0)}},
+		{"gap to zero", []Segment{Gap(-1)}},
+		{"unknown regime", []Segment{{Regime: "sideways", Bars: 10}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(Config{}, tt.segments...); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// avgRange returns the average high-low range relative to the close over klines [start, end)
+func avgRange(series *Series, start, end int) float64 {
+	total := 0.0
+	for _, k := range series.Klines[start:end] {
+		total += (k.High - k.Low) / k.Close
+	}
+	return total / float64(end-start)
+}
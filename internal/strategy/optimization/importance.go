@@ -0,0 +1,127 @@
+package optimization
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// OptimizationResults are the results of an optimization, best score first
+type OptimizationResults []OptimizationResult
+
+// PartialDependence is the mean in-sample score of the results with a parameter at one value
+type PartialDependence struct {
+	Value     float64
+	MeanScore float64
+	Count     int // Results with the parameter at Value
+}
+
+// ParameterImportance tells how much a parameter drives the optimization score
+type ParameterImportance struct {
+	Name string
+	// Share of the score variance explained by the parameter's value alone, from 0 to 1 (eta
+	// squared). Over a full grid the shares of all parameters add up to at most 1, the rest being
+	// explained by their interactions
+	VarianceExplained float64
+	Spread            float64             // Best minus worst mean score across the parameter's values
+	Dependence        []PartialDependence // By ascending value
+}
+
+// Importance ranks the parameters by the share of the score variance they explain, most important
+// first. Results with a non-finite score are left out
+func (r OptimizationResults) Importance() []ParameterImportance {
+	var scores []float64
+	var results []OptimizationResult
+	for _, result := range r {
+		if !math.IsNaN(result.Score) && !math.IsInf(result.Score, 0) {
+			scores = append(scores, result.Score)
+			results = append(results, result)
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	mean := 0.0
+	for _, score := range scores {
+		mean += score
+	}
+	mean /= float64(len(scores))
+	total := 0.0
+	for _, score := range scores {
+		total += (score - mean) * (score - mean)
+	}
+
+	names := make(map[string]bool)
+	for _, result := range results {
+		for name := range result.Parameters {
+			names[name] = true
+		}
+	}
+	importance := make([]ParameterImportance, 0, len(names))
+	for name := range names {
+		importance = append(importance, parameterImportance(name, results, mean, total))
+	}
+	sort.Slice(importance, func(i, j int) bool {
+		if importance[i].VarianceExplained != importance[j].VarianceExplained {
+			return importance[i].VarianceExplained > importance[j].VarianceExplained
+		}
+		return importance[i].Name < importance[j].Name
+	})
+	return importance
+}
+
+// parameterImportance groups the results by the value of the parameter name and compares the
+// variance between the groups' mean scores to the total variance of the scores around mean
+func parameterImportance(name string, results []OptimizationResult, mean, total float64) ParameterImportance {
+	sums := make(map[float64]float64)
+	counts := make(map[float64]int)
+	for _, result := range results {
+		value, ok := result.Parameters[name]
+		if !ok {
+			continue
+		}
+		sums[value] += result.Score
+		counts[value]++
+	}
+
+	importance := ParameterImportance{Name: name, Dependence: make([]PartialDependence, 0, len(counts))}
+	between := 0.0
+	for value, count := range counts {
+		groupMean := sums[value] / float64(count)
+		between += float64(count) * (groupMean - mean) * (groupMean - mean)
+		importance.Dependence = append(importance.Dependence, PartialDependence{Value: value, MeanScore: groupMean, Count: count})
+	}
+	sort.Slice(importance.Dependence, func(i, j int) bool { return importance.Dependence[i].Value < importance.Dependence[j].Value })
+
+	if total > 0 {
+		importance.VarianceExplained = math.Min(between/total, 1)
+	}
+	best, worst := math.Inf(-1), math.Inf(1)
+	for _, d := range importance.Dependence {
+		best, worst = math.Max(best, d.MeanScore), math.Min(worst, d.MeanScore)
+	}
+	if len(importance.Dependence) > 0 {
+		importance.Spread = best - worst
+	}
+	return importance
+}
+
+// WriteImportance writes the parameters ranked by importance and the partial dependence table of
+// each, the mean score at every value tried
+func (r OptimizationResults) WriteImportance(w io.Writer) error {
+	importance := r.Importance()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Parameter\tVariance explained\tScore spread")
+	for _, p := range importance {
+		fmt.Fprintf(tw, "%s\t%.1f%%\t%.4f\n", p.Name, p.VarianceExplained*100, p.Spread)
+	}
+	for _, p := range importance {
+		fmt.Fprintf(tw, "\n%s\tMean score\tResults\n", p.Name)
+		for _, d := range p.Dependence {
+			fmt.Fprintf(tw, "%g\t%.4f\t%d\n", d.Value, d.MeanScore, d.Count)
+		}
+	}
+	return tw.Flush()
+}
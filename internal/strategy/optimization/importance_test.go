@@ -0,0 +1,75 @@
+package optimization
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// gridResults scores a 3x2 grid where fast drives the score and slow barely moves it
+func gridResults() OptimizationResults {
+	var results OptimizationResults
+	for _, fast := range []float64{5, 10, 15} {
+		for _, slow := range []float64{20, 30} {
+			score := fast / 5
+			if slow == 30 {
+				score += 0.1
+			}
+			results = append(results, OptimizationResult{Parameters: map[string]float64{"FastMAPeriod": fast, "SlowMAPeriod": slow}, Score: score})
+		}
+	}
+	return results
+}
+
+func TestImportance(t *testing.T) {
+	results := append(gridResults(), OptimizationResult{Parameters: map[string]float64{"FastMAPeriod": 5, "SlowMAPeriod": 20}, Score: math.Inf(1)})
+	importance := results.Importance()
+	if len(importance) != 2 || importance[0].Name != "FastMAPeriod" || importance[1].Name != "SlowMAPeriod" {
+		t.Fatalf("Expected FastMAPeriod to rank first, got %+v", importance)
+	}
+
+	// Scores 1/1.1, 2/2.1, 3/3.1: the total sum of squares is 4.015, of which fast explains 4 and
+	// slow 0.015, leaving no interaction
+	fast, slow := importance[0], importance[1]
+	if math.Abs(fast.VarianceExplained-4/4.015) > 1e-9 || math.Abs(slow.VarianceExplained-0.015/4.015) > 1e-9 {
+		t.Errorf("Unexpected variance explained %f and %f", fast.VarianceExplained, slow.VarianceExplained)
+	}
+	if math.Abs(fast.Spread-2) > 1e-9 || math.Abs(slow.Spread-0.1) > 1e-9 {
+		t.Errorf("Unexpected spreads %f and %f", fast.Spread, slow.Spread)
+	}
+
+	// The infinite score is left out of the partial dependence
+	expected := []PartialDependence{{Value: 5, MeanScore: 1.05, Count: 2}, {Value: 10, MeanScore: 2.05, Count: 2}, {Value: 15, MeanScore: 3.05, Count: 2}}
+	for i, d := range fast.Dependence {
+		if d.Value != expected[i].Value || math.Abs(d.MeanScore-expected[i].MeanScore) > 1e-9 || d.Count != expected[i].Count {
+			t.Errorf("Dependence %d: expected %+v, got %+v", i, expected[i], d)
+		}
+	}
+}
+
+func TestImportanceWithoutVariance(t *testing.T) {
+	results := OptimizationResults{
+		{Parameters: map[string]float64{"ATRMultiplier": 1}, Score: 0.5},
+		{Parameters: map[string]float64{"ATRMultiplier": 2}, Score: 0.5},
+	}
+	importance := results.Importance()
+	if len(importance) != 1 || importance[0].VarianceExplained != 0 || importance[0].Spread != 0 {
+		t.Errorf("Expected an unimportant parameter, got %+v", importance)
+	}
+	if OptimizationResults(nil).Importance() != nil {
+		t.Error("Expected no importance without results")
+	}
+}
+
+func TestWriteImportance(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gridResults().WriteImportance(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"FastMAPeriod  99.6%", "SlowMAPeriod  0.4%", "15            3.0500      2"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
@@ -6,8 +6,12 @@ import (
 	"cryptoMegaBot/internal/ports"
 	"cryptoMegaBot/internal/strategy/analytics"
 	"cryptoMegaBot/internal/strategy/backtesting"
+	"cryptoMegaBot/internal/strategy/indicators"
 	"cryptoMegaBot/internal/strategy/strategies"
+	"cryptoMegaBot/internal/utils"
+	"fmt"
 	"math"
+	"sort"
 	"sync"
 )
 
@@ -25,6 +29,15 @@ type OptimizationResult struct {
 	Parameters map[string]float64
 	Metrics    *analytics.PerformanceMetrics
 	Score      float64
+	Seed       int64     // Seed of the backtest run for these parameters
+	DailyPNL   []float64 // In-sample PNL per UTC day, the periods of the overfitting statistics (see OptimizationResults.Overfitting)
+
+	// Out-of-sample holdout (see OptimizerConfig.HoldoutPct); Metrics and Score above are in-sample
+	HoldoutMetrics  *analytics.PerformanceMetrics
+	HoldoutScore    float64
+	HoldoutDegraded bool // Holdout score fell below the in-sample score by more than MaxHoldoutDegradation
+
+	index int // Position in the parameter grid, used to break score ties deterministically
 }
 
 // OptimizerConfig holds configuration for the optimizer
@@ -39,8 +52,24 @@ type OptimizerConfig struct {
 	StartTime       int64
 	EndTime         int64
 	ScoreFunction   func(*analytics.PerformanceMetrics) float64
+	Seed            int64 // Base seed for reproducible runs (0 picks a fresh seed)
+
+	// Out-of-sample holdout: the last HoldoutPct of the klines (e.g., 0.2 for 20%) are kept out of the
+	// optimization and each parameter set is scored on them separately (0 disables). Results are still
+	// ranked by their in-sample score only
+	HoldoutPct float64
+	// Results whose holdout score is worse than the in-sample score by more than this fraction of it
+	// are flagged as degraded (0 uses defaultMaxHoldoutDegradation)
+	MaxHoldoutDegradation float64
+
+	// Indicator values cached across the parameter combinations, which share the same klines
+	// (0 uses indicators.DefaultCacheSize, negative disables the cache)
+	IndicatorCacheSize int
 }
 
+// defaultMaxHoldoutDegradation flags holdout scores less than half the in-sample score
+const defaultMaxHoldoutDegradation = 0.5
+
 // Optimizer implements strategy parameter optimization
 type Optimizer struct {
 	config OptimizerConfig
@@ -53,11 +82,38 @@ func NewOptimizer(config OptimizerConfig) *Optimizer {
 	}
 }
 
-// Optimize performs parameter optimization for a strategy
-func (o *Optimizer) Optimize(ctx context.Context, strategy strategies.Strategy, klines []*domain.Kline) ([]OptimizationResult, error) {
+// Optimize performs parameter optimization for a strategy. The results also tell which parameters
+// matter (see OptimizationResults.Importance) and whether the best of them is likely overfit (see
+// OptimizationResults.Overfitting)
+func (o *Optimizer) Optimize(ctx context.Context, strategy strategies.Strategy, klines []*domain.Kline) (OptimizationResults, error) {
+	if o.config.HoldoutPct < 0 || o.config.HoldoutPct >= 1 {
+		return nil, fmt.Errorf("holdout percentage %v must be between 0 and 1", o.config.HoldoutPct)
+	}
+
+	// Run backtest with a subset of data for faster optimization
+	// Use every 5th kline to speed up testing while maintaining pattern recognition
+	sampledKlines := sampleKlines(klines, 5)
+	inSampleKlines, holdoutStart := sampledKlines, len(sampledKlines)
+	if o.config.HoldoutPct > 0 {
+		holdoutStart = len(sampledKlines) - int(float64(len(sampledKlines))*o.config.HoldoutPct)
+		if holdoutStart == len(sampledKlines) {
+			return nil, fmt.Errorf("holdout of %v leaves no klines out of %d", o.config.HoldoutPct, len(sampledKlines))
+		}
+		inSampleKlines = sampledKlines[:holdoutStart]
+	}
+	maxDegradation := o.config.MaxHoldoutDegradation
+	if maxDegradation <= 0 {
+		maxDegradation = defaultMaxHoldoutDegradation
+	}
+
 	// Generate parameter combinations
 	combinations := o.generateParameterCombinations()
-	results := make([]OptimizationResult, 0, len(combinations))
+	seed := utils.ResolveSeed(o.config.Seed)
+	results := make(OptimizationResults, 0, len(combinations))
+	var cache *indicators.Cache
+	if o.config.IndicatorCacheSize >= 0 {
+		cache = indicators.NewCache(o.config.IndicatorCacheSize)
+	}
 
 	// Create a channel to receive results
 	resultChan := make(chan OptimizationResult, len(combinations))
@@ -69,9 +125,9 @@ func (o *Optimizer) Optimize(ctx context.Context, strategy strategies.Strategy,
 	semaphore := make(chan struct{}, maxConcurrency)
 
 	// Process each parameter combination
-	for _, params := range combinations {
+	for i, params := range combinations {
 		wg.Add(1)
-		go func(params map[string]float64) {
+		go func(index int, params map[string]float64) {
 			// Acquire semaphore
 			semaphore <- struct{}{}
 			defer func() {
@@ -81,27 +137,24 @@ func (o *Optimizer) Optimize(ctx context.Context, strategy strategies.Strategy,
 			}()
 
 			// Create strategy instance with current parameters
-			strategyInstance, err := o.createStrategyWithParams(strategy, params)
+			strategyInstance, err := o.createStrategyWithParams(strategy, params, cache)
 			if err != nil {
 				return
 			}
 
-			// Run backtest with a subset of data for faster optimization
-			// Use every 5th kline to speed up testing while maintaining pattern recognition
-			sampledKlines := sampleKlines(klines, 5)
-
 			backtestConfig := backtesting.BacktestConfig{
-				StartTime:    sampledKlines[0].OpenTime,
-				EndTime:      sampledKlines[len(sampledKlines)-1].CloseTime,
+				StartTime:    inSampleKlines[0].OpenTime,
+				EndTime:      inSampleKlines[len(inSampleKlines)-1].CloseTime,
 				InitialFunds: o.config.InitialFunds,
 				PositionSize: o.config.PositionSize,
 				StopLoss:     o.config.StopLoss,
 				TakeProfit:   o.config.TakeProfit,
 				Symbol:       o.config.Symbol,
 				Leverage:     o.config.Leverage,
+				Seed:         utils.DeriveSeed(seed, index), // Per-combination seed, independent of scheduling
 			}
 
-			result, err := backtesting.Backtest(ctx, strategyInstance, sampledKlines, backtestConfig)
+			result, err := backtesting.Backtest(ctx, strategyInstance, inSampleKlines, backtestConfig)
 			if err != nil {
 				return
 			}
@@ -112,13 +165,37 @@ func (o *Optimizer) Optimize(ctx context.Context, strategy strategies.Strategy,
 			// Calculate score
 			score := o.config.ScoreFunction(metrics)
 
-			// Send result
-			resultChan <- OptimizationResult{
+			optimizationResult := OptimizationResult{
 				Parameters: params,
 				Metrics:    metrics,
 				Score:      score,
+				Seed:       result.Seed,
+				DailyPNL:   analytics.DailyPNL(result.Trades, backtestConfig.StartTime, backtestConfig.EndTime),
+				index:      index,
+			}
+
+			// Score the same parameters on the holdout with a fresh strategy instance, preceded by
+			// the in-sample bars the strategy needs as history (it only trades the holdout bars)
+			if holdoutStart < len(sampledKlines) {
+				holdoutStrategy, err := o.createStrategyWithParams(strategy, params, cache)
+				if err != nil {
+					return
+				}
+				holdoutKlines := sampledKlines[max(0, holdoutStart-holdoutStrategy.RequiredDataPoints()):]
+				backtestConfig.StartTime = sampledKlines[holdoutStart].OpenTime
+				backtestConfig.EndTime = holdoutKlines[len(holdoutKlines)-1].CloseTime
+				holdoutResult, err := backtesting.Backtest(ctx, holdoutStrategy, holdoutKlines, backtestConfig)
+				if err != nil {
+					return
+				}
+				optimizationResult.HoldoutMetrics = analytics.AnalyzePerformance(holdoutResult.Trades, o.config.InitialFunds)
+				optimizationResult.HoldoutScore = o.config.ScoreFunction(optimizationResult.HoldoutMetrics)
+				optimizationResult.HoldoutDegraded = holdoutDegradation(score, optimizationResult.HoldoutScore) > maxDegradation
 			}
-		}(params)
+
+			// Send result
+			resultChan <- optimizationResult
+		}(i, params)
 	}
 
 	// Wait for all goroutines to complete
@@ -138,6 +215,18 @@ func (o *Optimizer) Optimize(ctx context.Context, strategy strategies.Strategy,
 	return results, nil
 }
 
+// holdoutDegradation returns how far the holdout score fell below the in-sample score, as a fraction
+// of the in-sample score (negative when the holdout scored better)
+func holdoutDegradation(inSample, holdout float64) float64 {
+	if inSample == 0 {
+		if holdout < 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return (inSample - holdout) / math.Abs(inSample)
+}
+
 // sampleKlines returns a subset of klines by taking every nth kline
 func sampleKlines(klines []*domain.Kline, n int) []*domain.Kline {
 	if n <= 1 {
@@ -185,8 +274,9 @@ func (o *Optimizer) generateParameterCombinations() []map[string]float64 {
 	return combinations
 }
 
-// createStrategyWithParams creates a strategy instance with the given parameters
-func (o *Optimizer) createStrategyWithParams(strategy strategies.Strategy, params map[string]float64) (strategies.Strategy, error) {
+// createStrategyWithParams creates a strategy instance with the given parameters, sharing cache's
+// indicator values (nil calculates them per instance)
+func (o *Optimizer) createStrategyWithParams(strategy strategies.Strategy, params map[string]float64, cache *indicators.Cache) (strategies.Strategy, error) {
 	// Get the strategy name to determine which type it is
 	strategyName := strategy.Name()
 
@@ -203,11 +293,12 @@ func (o *Optimizer) createStrategyWithParams(strategy strategies.Strategy, param
 	if strategyName == "Improved Moving Average Crossover" {
 		// Create a new MACrossover strategy with the optimized parameters
 		config := strategies.MACrossoverConfig{
-			FastMAPeriod:  int(params["FastMAPeriod"]),
-			SlowMAPeriod:  int(params["SlowMAPeriod"]),
-			SignalPeriod:  int(params["SignalPeriod"]),
-			ATRPeriod:     int(params["ATRPeriod"]),
-			ATRMultiplier: params["ATRMultiplier"],
+			FastMAPeriod:   int(params["FastMAPeriod"]),
+			SlowMAPeriod:   int(params["SlowMAPeriod"]),
+			SignalPeriod:   int(params["SignalPeriod"]),
+			ATRPeriod:      int(params["ATRPeriod"]),
+			ATRMultiplier:  params["ATRMultiplier"],
+			IndicatorCache: cache,
 		}
 
 		// Add optional multi-timeframe parameters if they exist
@@ -267,15 +358,15 @@ func (o *Optimizer) createStrategyWithParams(strategy strategies.Strategy, param
 	return strategy, nil
 }
 
-// sortResultsByScore sorts optimization results by score in descending order
+// sortResultsByScore sorts optimization results by score in descending order, breaking ties by
+// parameter grid order so the ranking doesn't depend on goroutine completion order
 func sortResultsByScore(results []OptimizationResult) {
-	for i := 0; i < len(results)-1; i++ {
-		for j := i + 1; j < len(results); j++ {
-			if results[i].Score < results[j].Score {
-				results[i], results[j] = results[j], results[i]
-			}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
 		}
-	}
+		return results[i].index < results[j].index
+	})
 }
 
 // DefaultScoreFunction provides a default scoring function for optimization
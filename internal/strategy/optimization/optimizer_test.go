@@ -5,6 +5,7 @@ import (
 	"cryptoMegaBot/internal/domain"
 	"cryptoMegaBot/internal/strategy/analytics"
 	"cryptoMegaBot/internal/strategy/strategies"
+	"math"
 	"testing"
 	"time"
 )
@@ -133,6 +134,125 @@ func TestOptimizer(t *testing.T) {
 	}
 }
 
+func TestOptimizerReproducible(t *testing.T) {
+	now := time.Now()
+	klines := []*domain.Kline{
+		{OpenTime: now.Add(-2 * time.Hour), Close: 100, CloseTime: now.Add(-time.Hour)},
+		{OpenTime: now.Add(-time.Hour), Close: 101, CloseTime: now},
+	}
+	config := OptimizerConfig{
+		ParameterRanges: []ParameterRange{
+			{Name: "param1", Min: 1, Max: 4, Step: 1, IsInt: true},
+		},
+		InitialFunds:  10000,
+		PositionSize:  0.1,
+		Symbol:        "BTCUSDT",
+		Leverage:      1,
+		ScoreFunction: DefaultScoreFunction,
+		Seed:          42,
+	}
+	strategy := NewMockStrategy(true, true, domain.CloseReasonTakeProfit)
+
+	first, err := NewOptimizer(config).Optimize(context.Background(), strategy, klines)
+	if err != nil {
+		t.Fatalf("Optimization failed: %v", err)
+	}
+	second, err := NewOptimizer(config).Optimize(context.Background(), strategy, klines)
+	if err != nil {
+		t.Fatalf("Optimization failed: %v", err)
+	}
+
+	if len(first) != len(second) {
+		t.Fatalf("Expected the same number of results, got %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].Parameters["param1"] != second[i].Parameters["param1"] || first[i].Seed != second[i].Seed {
+			t.Errorf("Result %d differs between runs with the same seed: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestOptimizerHoldout(t *testing.T) {
+	// Prices rise over the first 75% of the klines and fall over the last 25%
+	start := time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 100)
+	for i := range klines {
+		price := 100 + float64(i)
+		if i >= 75 {
+			price = 175 - float64(i-75)*2
+		}
+		openTime := start.Add(time.Duration(i) * time.Hour)
+		klines[i] = &domain.Kline{OpenTime: openTime, Open: price, High: price, Low: price, Close: price, CloseTime: openTime.Add(time.Hour)}
+	}
+	config := OptimizerConfig{
+		ParameterRanges: []ParameterRange{
+			{Name: "param1", Min: 1, Max: 2, Step: 1, IsInt: true},
+		},
+		InitialFunds:  10000,
+		PositionSize:  1,
+		Symbol:        "BTCUSDT",
+		Leverage:      1,
+		ScoreFunction: func(metrics *analytics.PerformanceMetrics) float64 { return metrics.TotalProfit },
+		Seed:          42,
+		HoldoutPct:    0.25,
+	}
+	strategy := NewMockStrategy(true, true, domain.CloseReasonTakeProfit)
+
+	results, err := NewOptimizer(config).Optimize(context.Background(), strategy, klines)
+	if err != nil {
+		t.Fatalf("Optimization failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.HoldoutMetrics == nil {
+			t.Fatal("Expected holdout metrics")
+		}
+		// 15 in-sample and 5 holdout bars after sampling every 5th kline; entries and exits fill at the
+		// next bar's open, so each trade takes two bars
+		if result.Metrics.TotalTrades != 6 || result.HoldoutMetrics.TotalTrades != 2 {
+			t.Errorf("Expected 6 in-sample and 2 holdout trades, got %d and %d", result.Metrics.TotalTrades, result.HoldoutMetrics.TotalTrades)
+		}
+		if result.Score <= 0 || result.HoldoutScore >= 0 || !result.HoldoutDegraded {
+			t.Errorf("Expected a profitable in-sample score and a degraded losing holdout, got %f and %f (degraded %v)",
+				result.Score, result.HoldoutScore, result.HoldoutDegraded)
+		}
+	}
+
+	// Without a holdout every kline is in-sample
+	config.HoldoutPct = 0
+	results, err = NewOptimizer(config).Optimize(context.Background(), strategy, klines)
+	if err != nil {
+		t.Fatalf("Optimization failed: %v", err)
+	}
+	if results[0].HoldoutMetrics != nil || results[0].Metrics.TotalTrades != 9 {
+		t.Errorf("Expected 9 in-sample trades and no holdout, got %d", results[0].Metrics.TotalTrades)
+	}
+
+	config.HoldoutPct = 1
+	if _, err := NewOptimizer(config).Optimize(context.Background(), strategy, klines); err == nil {
+		t.Error("Expected an error for a holdout of all klines")
+	}
+}
+
+func TestHoldoutDegradation(t *testing.T) {
+	tests := []struct {
+		inSample, holdout, expected float64
+	}{
+		{2, 1, 0.5},
+		{2, 3, -0.5},
+		{-1, -2, 1},
+		{0, 1, 0},
+		{0, -1, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		if got := holdoutDegradation(tt.inSample, tt.holdout); got != tt.expected {
+			t.Errorf("holdoutDegradation(%v, %v) = %v, expected %v", tt.inSample, tt.holdout, got, tt.expected)
+		}
+	}
+}
+
 func TestGenerateParameterCombinations(t *testing.T) {
 	config := OptimizerConfig{
 		ParameterRanges: []ParameterRange{
@@ -0,0 +1,104 @@
+package optimization
+
+import (
+	"cryptoMegaBot/internal/strategy/analytics"
+	"fmt"
+	"io"
+	"math"
+)
+
+// defaultPBOPartitions is the number of blocks the in-sample days are split into for the
+// probability of backtest overfitting (12,870 in-sample/out-of-sample combinations)
+const defaultPBOPartitions = 16
+
+// OverfittingReport tells whether the best result of an optimization is likely luck. Both statistics
+// use the Sharpe ratio of each result's in-sample DailyPNL
+type OverfittingReport struct {
+	Best           OptimizationResult       // Highest scoring result, whose Sharpe ratio is deflated
+	DeflatedSharpe analytics.DeflatedSharpe // Best's Sharpe ratio corrected for having been picked among all results
+	PBO            analytics.PBOResult      // How often the in-sample best underperforms out of sample
+}
+
+// Overfitting deflates the Sharpe ratio of the best scoring result by the number of results it was
+// picked from and estimates the probability of backtest overfitting over all of them, splitting the
+// days into partitions blocks (0 uses 16; fewer if there aren't 2 days per block). Results with a
+// non-finite score are left out
+func (r OptimizationResults) Overfitting(partitions int) (OverfittingReport, error) {
+	var results []OptimizationResult
+	for _, result := range r {
+		if !math.IsNaN(result.Score) && !math.IsInf(result.Score, 0) {
+			results = append(results, result)
+		}
+	}
+	if len(results) < 2 {
+		return OverfittingReport{}, fmt.Errorf("at least 2 results are required, got %d", len(results))
+	}
+
+	best := results[0]
+	performance := make([][]float64, len(results))
+	sharpes := make([]float64, len(results))
+	for i, result := range results {
+		if len(result.DailyPNL) != len(best.DailyPNL) {
+			return OverfittingReport{}, fmt.Errorf("results cover different periods: %d and %d days", len(result.DailyPNL), len(best.DailyPNL))
+		}
+		if result.Score > best.Score || (result.Score == best.Score && result.index < best.index) {
+			best = result
+		}
+		performance[i] = result.DailyPNL
+		sharpes[i] = analytics.SharpeRatio(result.DailyPNL)
+	}
+
+	report := OverfittingReport{Best: best}
+	var err error
+	if report.DeflatedSharpe, err = analytics.DeflatedSharpeRatio(best.DailyPNL, len(results), variance(sharpes)); err != nil {
+		return OverfittingReport{}, fmt.Errorf("failed to deflate the Sharpe ratio: %w", err)
+	}
+	if partitions <= 0 {
+		partitions = defaultPBOPartitions
+	}
+	partitions = min(partitions, len(best.DailyPNL)/2) &^ 1 // Even, with at least 2 days per block
+	if report.PBO, err = analytics.ProbabilityOfOverfitting(performance, partitions); err != nil {
+		return OverfittingReport{}, fmt.Errorf("failed to estimate the probability of backtest overfitting: %w", err)
+	}
+	return report, nil
+}
+
+// WriteOverfitting writes the deflated Sharpe ratio of the best result and the probability of
+// backtest overfitting (see Overfitting), with what they mean for picking the best parameters
+func (r OptimizationResults) WriteOverfitting(w io.Writer) error {
+	report, err := r.Overfitting(0)
+	if err != nil {
+		return err
+	}
+	dsr, pbo := report.DeflatedSharpe, report.PBO
+	fmt.Fprintf(w, "Best parameters: %v (score %.4f)\n", report.Best.Parameters, report.Best.Score)
+	fmt.Fprintf(w, "Daily Sharpe ratio %.4f vs %.4f expected from the best of %d results by luck: deflated Sharpe ratio %.1f%% over %d days\n",
+		dsr.Sharpe, dsr.ExpectedMaxSharpe, dsr.Trials, dsr.Probability*100, dsr.Periods)
+	fmt.Fprintf(w, "Probability of backtest overfitting: %.1f%% over %d combinations\n", pbo.Probability*100, pbo.Combinations)
+	switch {
+	case pbo.Probability >= 0.5:
+		fmt.Fprintln(w, "The in-sample best does no better out of sample than a random pick: the parameters are likely overfit")
+	case dsr.Probability < 0.95:
+		fmt.Fprintln(w, "The best Sharpe ratio isn't significant after accounting for the number of results tried")
+	default:
+		fmt.Fprintln(w, "The best parameters hold up after accounting for the number of results tried")
+	}
+	return nil
+}
+
+// variance returns the sample variance of values (0 for fewer than two)
+func variance(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values)-1)
+}
@@ -0,0 +1,60 @@
+package optimization
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// dailyResults returns results whose score follows the Sharpe ratio of their daily PNL: the best
+// one earns steadily, the others barely break even
+func dailyResults() OptimizationResults {
+	return OptimizationResults{
+		{Parameters: map[string]float64{"FastMAPeriod": 5}, Score: 3, DailyPNL: []float64{20, 10, 20, 10, 20, 10, 20, 10}, index: 0},
+		{Parameters: map[string]float64{"FastMAPeriod": 10}, Score: 2, DailyPNL: []float64{10, -10, 10, -10, 10, -10, 10, -10}, index: 1},
+		{Parameters: map[string]float64{"FastMAPeriod": 15}, Score: 1, DailyPNL: []float64{-10, 0, -10, 0, -10, 0, -10, 0}, index: 2},
+		{Parameters: map[string]float64{"FastMAPeriod": 20}, Score: math.NaN(), DailyPNL: []float64{0, 0}, index: 3},
+	}
+}
+
+func TestOverfitting(t *testing.T) {
+	report, err := dailyResults().Overfitting(0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Best.Parameters["FastMAPeriod"] != 5 {
+		t.Errorf("Expected the best scoring result to be deflated, got %v", report.Best.Parameters)
+	}
+	if report.DeflatedSharpe.Trials != 3 || report.DeflatedSharpe.Periods != 8 {
+		t.Errorf("Expected 3 trials over 8 days without the NaN score, got %+v", report.DeflatedSharpe)
+	}
+	if report.DeflatedSharpe.ExpectedMaxSharpe <= 0 || report.DeflatedSharpe.Probability <= 0.95 {
+		t.Errorf("Expected a positive selection bias and a significant Sharpe ratio, got %+v", report.DeflatedSharpe)
+	}
+	// 8 days only leave room for 4 blocks of 2
+	if report.PBO.Combinations != 6 || report.PBO.Probability != 0 {
+		t.Errorf("Expected no overfitting over 6 combinations, got %+v", report.PBO)
+	}
+
+	mismatched := dailyResults()
+	mismatched[1].DailyPNL = mismatched[1].DailyPNL[:4]
+	if _, err := mismatched.Overfitting(0); err == nil {
+		t.Error("Expected an error for results over different periods")
+	}
+	if _, err := dailyResults()[:1].Overfitting(0); err == nil {
+		t.Error("Expected an error for a single result")
+	}
+}
+
+func TestWriteOverfitting(t *testing.T) {
+	var buf bytes.Buffer
+	if err := dailyResults().WriteOverfitting(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"Best parameters: map[FastMAPeriod:5] (score 3.0000)", "from the best of 3 results", "Probability of backtest overfitting: 0.0% over 6 combinations", "hold up"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected %q in:\n%s", expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Package regime classifies the market a strategy trades in: the trend (up, down or range) from
+// the slope of a slow moving average, and the volatility (low, normal or high) from the ATR as a
+// share of the price. A Classifier is fed the klines bar by bar and only leaves a regime once its
+// reading has moved back past the threshold by a margin (hysteresis), so a market hovering around a
+// threshold doesn't flip the classification, and the strategies gated on it, every bar.
+package regime
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/indicators"
+	"fmt"
+)
+
+// Trend is the direction of the market
+type Trend string
+
+const (
+	TrendUp    Trend = "up"    // Slow moving average rising
+	TrendDown  Trend = "down"  // Slow moving average falling
+	TrendRange Trend = "range" // No significant slope either way
+)
+
+// Volatility buckets the ATR as a share of the price
+type Volatility string
+
+const (
+	VolatilityLow    Volatility = "low"
+	VolatilityNormal Volatility = "normal"
+	VolatilityHigh   Volatility = "high"
+)
+
+// Config holds the parameters of a Classifier. Zero values use the defaults
+type Config struct {
+	MAPeriod          int     // Period of the slow EMA the trend is measured on (default 21)
+	ATRPeriod         int     // Period of the ATR the volatility is measured on (default 14)
+	TrendLookback     int     // Bars between the moving average readings compared for the trend (default 5)
+	TrendThreshold    float64 // Change of the average over two lookbacks (percent) beyond which a trend starts (default 0.15)
+	LowVolatility     float64 // ATR as a percentage of the close below which volatility is low (default 0.15)
+	HighVolatility    float64 // ATR as a percentage of the close above which volatility is high (default 5.0)
+	Hysteresis        float64 // Share of a threshold a reading must move back past to leave its regime (default 0.25, negative disables)
+	VolatilityHistory int     // Readings averaged into State.AverageVolatilityPct (default 20)
+}
+
+// withDefaults fills in the defaults of unset parameters
+func (c Config) withDefaults() Config {
+	if c.MAPeriod <= 0 {
+		c.MAPeriod = 21
+	}
+	if c.ATRPeriod <= 0 {
+		c.ATRPeriod = 14
+	}
+	if c.TrendLookback <= 0 {
+		c.TrendLookback = 5
+	}
+	if c.TrendThreshold <= 0 {
+		c.TrendThreshold = 0.15
+	}
+	if c.LowVolatility <= 0 {
+		c.LowVolatility = 0.15
+	}
+	if c.HighVolatility <= 0 {
+		c.HighVolatility = 5.0
+	}
+	if c.Hysteresis < 0 {
+		c.Hysteresis = 0
+	} else if c.Hysteresis == 0 {
+		c.Hysteresis = 0.25
+	}
+	if c.VolatilityHistory <= 0 {
+		c.VolatilityHistory = 20
+	}
+	return c
+}
+
+// Validate checks that the thresholds leave room for every regime
+func (c Config) Validate() error {
+	c = c.withDefaults()
+	if c.Hysteresis >= 1 {
+		return fmt.Errorf("hysteresis %v must be below 1", c.Hysteresis)
+	}
+	if c.LowVolatility >= c.HighVolatility {
+		return fmt.Errorf("low volatility threshold %v must be below the high volatility threshold %v", c.LowVolatility, c.HighVolatility)
+	}
+	return nil
+}
+
+// State is the classification at a bar, with the readings it was made from
+type State struct {
+	Trend                Trend      `json:"trend"`
+	Volatility           Volatility `json:"volatility"`
+	TrendStrength        float64    `json:"trendStrength"`        // Change of the slow average over two lookbacks, in percent
+	VolatilityPct        float64    `json:"volatilityPct"`        // ATR as a percentage of the close
+	AverageVolatilityPct float64    `json:"averageVolatilityPct"` // Mean of the recent VolatilityPct readings
+}
+
+// IsZero reports whether no bar has been classified yet
+func (s State) IsZero() bool {
+	return s.Trend == "" && s.Volatility == ""
+}
+
+// VolatilityExpanding reports whether volatility is more than 10% above its recent average
+func (s State) VolatilityExpanding() bool {
+	return s.AverageVolatilityPct > 0 && s.VolatilityPct > s.AverageVolatilityPct*1.1
+}
+
+// Classifier classifies the market bar by bar. It keeps the current regime and recent volatility
+// between calls, so it should be updated once per bar and is not safe for concurrent use
+type Classifier struct {
+	config           Config
+	slowMA           *indicators.MovingAverage
+	atr              *indicators.ATR
+	state            State
+	recentVolatility []float64
+}
+
+// New creates a classifier
+func New(config Config) (*Classifier, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	config = config.withDefaults()
+	return &Classifier{
+		config: config,
+		slowMA: indicators.NewMovingAverage(indicators.MovingAverageConfig{
+			IndicatorConfig: indicators.IndicatorConfig{Period: config.MAPeriod},
+			Type:            indicators.ExponentialMovingAverage,
+		}),
+		atr: indicators.NewATR(indicators.ATRConfig{
+			IndicatorConfig: indicators.IndicatorConfig{Period: config.ATRPeriod},
+		}),
+		recentVolatility: make([]float64, 0, config.VolatilityHistory),
+	}, nil
+}
+
+// RequiredDataPoints returns the minimum number of klines Update needs
+func (c *Classifier) RequiredDataPoints() int {
+	required := c.config.MAPeriod + 2*c.config.TrendLookback
+	if atr := c.atr.RequiredDataPoints(); atr > required {
+		required = atr
+	}
+	return required
+}
+
+// Update classifies the market at the last of klines (oldest first) and returns the new state. On
+// error the state is left unchanged
+func (c *Classifier) Update(ctx context.Context, klines []*domain.Kline) (State, error) {
+	if len(klines) < c.RequiredDataPoints() {
+		return c.state, fmt.Errorf("not enough data (%d) to classify the market regime, need %d", len(klines), c.RequiredDataPoints())
+	}
+	n := len(klines)
+	lookback := c.config.TrendLookback
+	slowMA, err := c.slowMA.Calculate(ctx, klines)
+	if err != nil {
+		return c.state, fmt.Errorf("slow MA: %w", err)
+	}
+	prevSlowMA, err := c.slowMA.Calculate(ctx, klines[:n-lookback])
+	if err != nil {
+		return c.state, fmt.Errorf("previous slow MA: %w", err)
+	}
+	earlierSlowMA, err := c.slowMA.Calculate(ctx, klines[:n-2*lookback])
+	if err != nil {
+		return c.state, fmt.Errorf("earlier slow MA: %w", err)
+	}
+	atr, err := c.atr.Calculate(ctx, klines)
+	if err != nil {
+		return c.state, fmt.Errorf("ATR: %w", err)
+	}
+	price := klines[n-1].Close
+	if earlierSlowMA <= 0 || price <= 0 {
+		return c.state, fmt.Errorf("non-positive prices can't be classified")
+	}
+
+	strength := (slowMA/earlierSlowMA - 1) * 100
+	volatility := atr / price * 100
+	if len(c.recentVolatility) >= c.config.VolatilityHistory {
+		c.recentVolatility = c.recentVolatility[1:]
+	}
+	c.recentVolatility = append(c.recentVolatility, volatility)
+	var sum float64
+	for _, v := range c.recentVolatility {
+		sum += v
+	}
+
+	c.state = State{
+		Trend:                c.trend(slowMA, prevSlowMA, earlierSlowMA, strength),
+		Volatility:           c.volatility(volatility),
+		TrendStrength:        strength,
+		VolatilityPct:        volatility,
+		AverageVolatilityPct: sum / float64(len(c.recentVolatility)),
+	}
+	return c.state, nil
+}
+
+// trend starts a trend when the average moved steadily over both lookbacks by more than the
+// threshold, and keeps it until the move shrinks below the threshold less the hysteresis margin
+func (c *Classifier) trend(slowMA, prevSlowMA, earlierSlowMA, strength float64) Trend {
+	threshold := c.config.TrendThreshold
+	switch {
+	case slowMA > prevSlowMA && prevSlowMA > earlierSlowMA && strength > threshold:
+		return TrendUp
+	case slowMA < prevSlowMA && prevSlowMA < earlierSlowMA && strength < -threshold:
+		return TrendDown
+	}
+	exit := threshold * (1 - c.config.Hysteresis)
+	switch {
+	case c.state.Trend == TrendUp && strength > exit:
+		return TrendUp
+	case c.state.Trend == TrendDown && strength < -exit:
+		return TrendDown
+	}
+	return TrendRange
+}
+
+// volatility buckets pct by the thresholds, keeping a low or high regime until pct moved back past
+// its threshold by the hysteresis margin
+func (c *Classifier) volatility(pct float64) Volatility {
+	low, high, margin := c.config.LowVolatility, c.config.HighVolatility, c.config.Hysteresis
+	switch {
+	case pct < low:
+		return VolatilityLow
+	case pct > high:
+		return VolatilityHigh
+	case c.state.Volatility == VolatilityLow && pct < low*(1+margin):
+		return VolatilityLow
+	case c.state.Volatility == VolatilityHigh && pct > high*(1-margin):
+		return VolatilityHigh
+	}
+	return VolatilityNormal
+}
+
+// State returns the classification of the last update (zero before the first)
+func (c *Classifier) State() State {
+	return c.state
+}
+
+// RecentVolatility returns the recent volatility readings, oldest first
+func (c *Classifier) RecentVolatility() []float64 {
+	return append([]float64(nil), c.recentVolatility...)
+}
+
+// Restore resumes from a state and volatility readings saved from another classifier (e.g., across
+// restarts), keeping the most recent readings that fit the history
+func (c *Classifier) Restore(state State, recentVolatility []float64) {
+	if len(recentVolatility) > c.config.VolatilityHistory {
+		recentVolatility = recentVolatility[len(recentVolatility)-c.config.VolatilityHistory:]
+	}
+	c.state = state
+	c.recentVolatility = append(make([]float64, 0, c.config.VolatilityHistory), recentVolatility...)
+}
+
+// Reset forgets the current regime and the volatility history
+func (c *Classifier) Reset() {
+	c.Restore(State{}, nil)
+}
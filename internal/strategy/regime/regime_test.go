@@ -0,0 +1,144 @@
+package regime
+
+import (
+	"context"
+	"cryptoMegaBot/internal/strategy/klinegen"
+	"testing"
+)
+
+func TestClassifier_SyntheticRegimes(t *testing.T) {
+	tests := []struct {
+		name           string
+		segments       []klinegen.Segment
+		wantTrend      Trend
+		wantVolatility Volatility
+	}{
+		{
+			name:           "uptrend",
+			segments:       []klinegen.Segment{klinegen.Chop(40, 0.005), klinegen.Uptrend(60, 0.003)},
+			wantTrend:      TrendUp,
+			wantVolatility: VolatilityNormal,
+		},
+		{
+			name:           "downtrend",
+			segments:       []klinegen.Segment{klinegen.Chop(40, 0.005), klinegen.Downtrend(60, 0.003)},
+			wantTrend:      TrendDown,
+			wantVolatility: VolatilityNormal,
+		},
+		{
+			name:           "range",
+			segments:       []klinegen.Segment{klinegen.Uptrend(40, 0.003), klinegen.Chop(120, 0.004)},
+			wantTrend:      TrendRange,
+			wantVolatility: VolatilityNormal,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 5; seed++ {
+				series, err := klinegen.Generate(klinegen.Config{Seed: seed, Volatility: 0.003}, tt.segments...)
+				if err != nil {
+					t.Fatalf("Failed to generate klines: %v", err)
+				}
+				c, err := New(Config{})
+				if err != nil {
+					t.Fatalf("Failed to create classifier: %v", err)
+				}
+				var state State
+				for i := c.RequiredDataPoints(); i <= len(series.Klines); i++ {
+					if state, err = c.Update(ctx, series.Klines[:i]); err != nil {
+						t.Fatalf("seed %d: unexpected error at bar %d: %v", seed, i, err)
+					}
+				}
+				if state.Trend != tt.wantTrend || state.Volatility != tt.wantVolatility {
+					t.Errorf("seed %d: got %s/%s (strength %.3f%%, volatility %.3f%%), want %s/%s", seed,
+						state.Trend, state.Volatility, state.TrendStrength, state.VolatilityPct, tt.wantTrend, tt.wantVolatility)
+				}
+			}
+		})
+	}
+}
+
+func TestClassifier_Hysteresis(t *testing.T) {
+	// Readings hovering just around the thresholds after clearly crossing them
+	strengths := []float64{0.3, 0.14, 0.16, 0.12, 0.16, 0.1, 0.05}
+	volatilities := []float64{6, 4.9, 5.1, 4, 5.05, 3.5, 3}
+
+	tests := []struct {
+		name           string
+		hysteresis     float64
+		wantTrend      []Trend
+		wantVolatility []Volatility
+	}{
+		{
+			name:           "default margin",
+			wantTrend:      []Trend{TrendUp, TrendUp, TrendUp, TrendUp, TrendUp, TrendRange, TrendRange},
+			wantVolatility: []Volatility{VolatilityHigh, VolatilityHigh, VolatilityHigh, VolatilityHigh, VolatilityHigh, VolatilityNormal, VolatilityNormal},
+		},
+		{
+			name:           "disabled",
+			hysteresis:     -1,
+			wantTrend:      []Trend{TrendUp, TrendRange, TrendUp, TrendRange, TrendUp, TrendRange, TrendRange},
+			wantVolatility: []Volatility{VolatilityHigh, VolatilityNormal, VolatilityHigh, VolatilityNormal, VolatilityHigh, VolatilityNormal, VolatilityNormal},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(Config{Hysteresis: tt.hysteresis})
+			if err != nil {
+				t.Fatalf("Failed to create classifier: %v", err)
+			}
+			for i, strength := range strengths {
+				// A steadily rising average, so only the strength decides
+				c.state.Trend = c.trend(3, 2, 1, strength)
+				c.state.Volatility = c.volatility(volatilities[i])
+				if c.state.Trend != tt.wantTrend[i] || c.state.Volatility != tt.wantVolatility[i] {
+					t.Errorf("reading %d: got %s/%s, want %s/%s", i, c.state.Trend, c.state.Volatility, tt.wantTrend[i], tt.wantVolatility[i])
+				}
+			}
+		})
+	}
+
+	// A downtrend that reverses hard switches straight to an uptrend
+	c, _ := New(Config{})
+	c.state.Trend = TrendDown
+	if got := c.trend(3, 2, 1, 0.5); got != TrendUp {
+		t.Errorf("Expected a reversal to an uptrend, got %s", got)
+	}
+	c.state.Volatility = VolatilityLow
+	if got := c.volatility(0.17); got != VolatilityLow {
+		t.Errorf("Expected low volatility to hold within the margin, got %s", got)
+	}
+	if got := c.volatility(0.2); got != VolatilityNormal {
+		t.Errorf("Expected low volatility to end past the margin, got %s", got)
+	}
+}
+
+func TestClassifier_RestoreAndValidate(t *testing.T) {
+	c, err := New(Config{VolatilityHistory: 3})
+	if err != nil {
+		t.Fatalf("Failed to create classifier: %v", err)
+	}
+	saved := State{Trend: TrendUp, Volatility: VolatilityNormal}
+	c.Restore(saved, []float64{1, 2, 3, 4, 5})
+	if c.State() != saved {
+		t.Errorf("Expected the restored state, got %+v", c.State())
+	}
+	if got := c.RecentVolatility(); len(got) != 3 || got[0] != 3 || got[2] != 5 {
+		t.Errorf("Expected the last 3 readings, got %v", got)
+	}
+	c.Reset()
+	if !c.State().IsZero() || len(c.RecentVolatility()) != 0 {
+		t.Errorf("Expected a reset classifier to be empty")
+	}
+
+	if _, err := c.Update(context.Background(), nil); err == nil {
+		t.Errorf("Expected an error without enough klines")
+	}
+	for _, config := range []Config{{LowVolatility: 2, HighVolatility: 1}, {Hysteresis: 1}} {
+		if _, err := New(config); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+}
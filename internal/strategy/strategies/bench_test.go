@@ -0,0 +1,67 @@
+package strategies
+
+import (
+	"context"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/utils"
+	"os"
+	"testing"
+	"time"
+)
+
+// benchWindow is the trailing kline window each per-bar strategy call receives
+const benchWindow = 500
+
+// loadBenchKlines loads the bundled kline fixture. BENCH_KLINES overrides its path (cmd/bench sets it)
+func loadBenchKlines(b *testing.B) []*domain.Kline {
+	b.Helper()
+	path := os.Getenv("BENCH_KLINES")
+	if path == "" {
+		path = "../testdata/ETHUSDT_15m_bench.csv.gz"
+	}
+	klines, err := utils.ReadKlinesFromCSV(path)
+	if err != nil {
+		b.Fatalf("Failed to load benchmark klines: %v", err)
+	}
+	return klines
+}
+
+// benchMACrossoverConfig mirrors the backtest runner's single-timeframe settings
+func benchMACrossoverConfig() MACrossoverConfig {
+	return MACrossoverConfig{
+		FastMAPeriod:           8,
+		SlowMAPeriod:           21,
+		SignalPeriod:           9,
+		ATRPeriod:              14,
+		ATRMultiplier:          2.5,
+		MaxDailyLosses:         2,
+		MaxConsecutiveLosses:   2,
+		MaxHoldingTime:         2 * time.Hour,
+		PartialProfitPct:       0.005,
+		TrailingActivePct:      0.002,
+		BreakEvenActivation:    0.002,
+		TrailingStopTightening: true,
+		InitialRiskPerTrade:    0.005,
+		MaxLeverageUsed:        4.0,
+	}
+}
+
+func BenchmarkMACrossoverShouldEnterTrade(b *testing.B) {
+	klines := loadBenchKlines(b)
+	strategy, err := NewImprovedMACrossover(benchMACrossoverConfig(), logger.NewStdLogger(logger.LevelError))
+	if err != nil {
+		b.Fatalf("Failed to create strategy: %v", err)
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := benchWindow; i <= len(klines); i++ {
+			window := klines[i-benchWindow : i]
+			strategy.ShouldEnterTrade(ctx, window, window[len(window)-1].Close)
+		}
+	}
+	bars := len(klines) - benchWindow + 1
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*bars), "ns/bar")
+}
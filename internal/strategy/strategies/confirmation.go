@@ -0,0 +1,207 @@
+package strategies
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ConfirmationCondition identifies an entry confirmation condition
+type ConfirmationCondition string
+
+const (
+	ConfirmSignalLine      ConfirmationCondition = "signal_line" // Price above the signal line by more than Min (fraction)
+	ConfirmRSI             ConfirmationCondition = "rsi"         // RSI strictly between Min and Max
+	ConfirmMomentum        ConfirmationCondition = "momentum"    // 10-bar rate of change (percent) above Min
+	ConfirmVolume          ConfirmationCondition = "volume"      // Recent/past volume ratio above Min
+	ConfirmPattern         ConfirmationCondition = "pattern"     // Higher highs or higher lows
+	ConfirmVolatility      ConfirmationCondition = "volatility"  // ATR as a fraction of price below Max
+	ConfirmHigherTimeframe ConfirmationCondition = "higher_tf"   // Higher timeframe uptrend with strength above Min
+
+	// Open interest change (fraction) above Min while price rose over the same snapshots
+	ConfirmOpenInterest ConfirmationCondition = "open_interest"
+)
+
+// confirmationConditions lists all conditions in evaluation order
+var confirmationConditions = []ConfirmationCondition{
+	ConfirmSignalLine,
+	ConfirmRSI,
+	ConfirmMomentum,
+	ConfirmVolume,
+	ConfirmPattern,
+	ConfirmVolatility,
+	ConfirmHigherTimeframe,
+	ConfirmOpenInterest,
+}
+
+// ConfirmationRule configures the weight and thresholds of a single condition
+type ConfirmationRule struct {
+	Weight float64 // Score added when the condition is met (0 disables the condition)
+	Min    float64 // Lower threshold, meaning depends on the condition
+	Max    float64 // Upper threshold, meaning depends on the condition
+}
+
+// ConfirmationConfig holds the confirmation rules and the score an entry needs
+type ConfirmationConfig struct {
+	Rules    map[ConfirmationCondition]ConfirmationRule
+	MinScore float64 // Minimum total weight of met conditions to confirm an entry
+}
+
+// DefaultConfirmationConfig returns the confirmation rules MACrossover has always used:
+// every condition weighs 1 and at least 2 must be met
+func DefaultConfirmationConfig() ConfirmationConfig {
+	return ConfirmationConfig{
+		Rules: map[ConfirmationCondition]ConfirmationRule{
+			ConfirmSignalLine:      {Weight: 1},
+			ConfirmRSI:             {Weight: 1, Min: 35, Max: 68},
+			ConfirmMomentum:        {Weight: 1, Min: 0.3},
+			ConfirmVolume:          {Weight: 1, Min: 1.1},
+			ConfirmPattern:         {Weight: 1},
+			ConfirmVolatility:      {Weight: 1, Max: 0.015},
+			ConfirmHigherTimeframe: {Weight: 1, Min: 0.3},
+		},
+		MinScore: 2,
+	}
+}
+
+// ConfirmationInputs holds the indicator values the conditions are evaluated against
+type ConfirmationInputs struct {
+	Price                   float64
+	SignalMA                float64
+	RSI                     float64
+	Momentum                float64 // Percent
+	VolumeRatio             float64
+	ATR                     float64
+	HigherHigh              bool
+	HigherLow               bool
+	HigherTimeframeEnabled  bool
+	HigherTimeframeUptrend  bool
+	HigherTimeframeStrength float64
+
+	// Open interest change and price change over the same snapshots (fractions)
+	OpenInterestAvailable   bool
+	OpenInterestChange      float64
+	OpenInterestPriceChange float64
+}
+
+// ConfirmationResult is the outcome of scoring an entry
+type ConfirmationResult struct {
+	Score     float64                 // Total weight of met conditions
+	Count     int                     // Number of met conditions
+	Met       []ConfirmationCondition // Met conditions in evaluation order
+	Confirmed bool                    // Whether Score reached MinScore
+}
+
+// ConfirmationScorer scores entry confirmations using configurable weights and thresholds
+type ConfirmationScorer struct {
+	config ConfirmationConfig
+}
+
+// NewConfirmationScorer creates a new confirmation scorer instance
+func NewConfirmationScorer(config ConfirmationConfig) (*ConfirmationScorer, error) {
+	for cond, rule := range config.Rules {
+		if !isConfirmationCondition(cond) {
+			return nil, fmt.Errorf("unknown confirmation condition %q", cond)
+		}
+		if rule.Weight < 0 {
+			return nil, fmt.Errorf("confirmation %q weight cannot be negative", cond)
+		}
+	}
+	if config.MinScore < 0 {
+		return nil, fmt.Errorf("minimum confirmation score cannot be negative")
+	}
+	return &ConfirmationScorer{config: config}, nil
+}
+
+// Score evaluates every enabled condition and sums the weights of those that are met
+func (s *ConfirmationScorer) Score(in ConfirmationInputs) ConfirmationResult {
+	var result ConfirmationResult
+	for _, cond := range confirmationConditions {
+		rule, ok := s.config.Rules[cond]
+		if !ok || rule.Weight == 0 || !conditionMet(cond, rule, in) {
+			continue
+		}
+		result.Score += rule.Weight
+		result.Count++
+		result.Met = append(result.Met, cond)
+	}
+	result.Confirmed = result.Score >= s.config.MinScore
+	return result
+}
+
+// conditionMet evaluates a single condition against its rule's thresholds
+func conditionMet(cond ConfirmationCondition, rule ConfirmationRule, in ConfirmationInputs) bool {
+	switch cond {
+	case ConfirmSignalLine:
+		return in.Price > in.SignalMA*(1+rule.Min)
+	case ConfirmRSI:
+		return in.RSI > rule.Min && in.RSI < rule.Max
+	case ConfirmMomentum:
+		return in.Momentum > rule.Min
+	case ConfirmVolume:
+		return in.VolumeRatio > rule.Min
+	case ConfirmPattern:
+		return in.HigherHigh || in.HigherLow
+	case ConfirmVolatility:
+		return in.Price > 0 && in.ATR < in.Price*rule.Max
+	case ConfirmHigherTimeframe:
+		return in.HigherTimeframeEnabled && in.HigherTimeframeUptrend && in.HigherTimeframeStrength > rule.Min
+	case ConfirmOpenInterest:
+		return in.OpenInterestAvailable && in.OpenInterestChange > rule.Min && in.OpenInterestPriceChange > 0
+	}
+	return false
+}
+
+func isConfirmationCondition(cond ConfirmationCondition) bool {
+	for _, c := range confirmationConditions {
+		if c == cond {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseConfirmationRules overrides rules in base from a spec of name:weight[:min[:max]] entries
+// such as "rsi:1:40:65,momentum:2:0.5,volume:0". Omitted thresholds keep the base values
+func ParseConfirmationRules(spec string, base ConfirmationConfig) (ConfirmationConfig, error) {
+	rules := make(map[ConfirmationCondition]ConfirmationRule, len(base.Rules))
+	for cond, rule := range base.Rules {
+		rules[cond] = rule
+	}
+	result := ConfirmationConfig{Rules: rules, MinScore: base.MinScore}
+
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return result, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) < 2 || len(parts) > 4 {
+			return result, fmt.Errorf("invalid confirmation rule %q: expected name:weight[:min[:max]]", entry)
+		}
+		cond := ConfirmationCondition(strings.TrimSpace(parts[0]))
+		if !isConfirmationCondition(cond) {
+			return result, fmt.Errorf("unknown confirmation condition %q", cond)
+		}
+
+		rule := rules[cond]
+		values := []*float64{&rule.Weight, &rule.Min, &rule.Max}
+		for i, part := range parts[1:] {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue // Keep the base value
+			}
+			v, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return result, fmt.Errorf("invalid value in confirmation rule %q: %w", entry, err)
+			}
+			*values[i] = v
+		}
+		if rule.Weight < 0 {
+			return result, fmt.Errorf("confirmation %q weight cannot be negative", cond)
+		}
+		rules[cond] = rule
+	}
+	return result, nil
+}
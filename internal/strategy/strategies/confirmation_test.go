@@ -0,0 +1,117 @@
+package strategies
+
+import "testing"
+
+func TestConfirmationScorer(t *testing.T) {
+	inputs := ConfirmationInputs{
+		Price:       100,
+		SignalMA:    99,  // Above signal line
+		RSI:         50,  // Healthy
+		Momentum:    0.1, // Too weak
+		VolumeRatio: 1.0, // Flat volume
+		ATR:         2,   // Too volatile (2% of price)
+	}
+
+	tests := []struct {
+		name          string
+		config        ConfirmationConfig
+		wantScore     float64
+		wantCount     int
+		wantConfirmed bool
+	}{
+		{
+			name:          "default rules",
+			config:        DefaultConfirmationConfig(),
+			wantScore:     2,
+			wantCount:     2,
+			wantConfirmed: true,
+		},
+		{
+			name: "stricter minimum score",
+			config: ConfirmationConfig{
+				Rules:    DefaultConfirmationConfig().Rules,
+				MinScore: 3,
+			},
+			wantScore: 2,
+			wantCount: 2,
+		},
+		{
+			name: "weighted RSI and disabled signal line",
+			config: ConfirmationConfig{
+				Rules: map[ConfirmationCondition]ConfirmationRule{
+					ConfirmSignalLine: {Weight: 0},
+					ConfirmRSI:        {Weight: 2.5, Min: 40, Max: 60},
+				},
+				MinScore: 2,
+			},
+			wantScore:     2.5,
+			wantCount:     1,
+			wantConfirmed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer, err := NewConfirmationScorer(tt.config)
+			if err != nil {
+				t.Fatalf("NewConfirmationScorer failed: %v", err)
+			}
+			got := scorer.Score(inputs)
+			if got.Score != tt.wantScore || got.Count != tt.wantCount || got.Confirmed != tt.wantConfirmed {
+				t.Errorf("Expected score %v, count %d, confirmed %v; got %+v", tt.wantScore, tt.wantCount, tt.wantConfirmed, got)
+			}
+		})
+	}
+}
+
+func TestConfirmationScorer_OpenInterest(t *testing.T) {
+	scorer, err := NewConfirmationScorer(ConfirmationConfig{
+		Rules: map[ConfirmationCondition]ConfirmationRule{ConfirmOpenInterest: {Weight: 1, Min: 0.01}},
+	})
+	if err != nil {
+		t.Fatalf("NewConfirmationScorer failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		inputs ConfirmationInputs
+		want   bool
+	}{
+		{name: "rising with price", inputs: ConfirmationInputs{OpenInterestAvailable: true, OpenInterestChange: 0.02, OpenInterestPriceChange: 0.005}, want: true},
+		{name: "rise below minimum", inputs: ConfirmationInputs{OpenInterestAvailable: true, OpenInterestChange: 0.005, OpenInterestPriceChange: 0.005}},
+		{name: "falling price", inputs: ConfirmationInputs{OpenInterestAvailable: true, OpenInterestChange: 0.02, OpenInterestPriceChange: -0.001}},
+		{name: "no snapshots", inputs: ConfirmationInputs{OpenInterestChange: 0.02, OpenInterestPriceChange: 0.005}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scorer.Score(tt.inputs).Count == 1; got != tt.want {
+				t.Errorf("Expected open interest met %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseConfirmationRules(t *testing.T) {
+	cfg, err := ParseConfirmationRules("rsi:2:40, momentum::0.5,volume:0", DefaultConfirmationConfig())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := cfg.Rules[ConfirmRSI]; got != (ConfirmationRule{Weight: 2, Min: 40, Max: 68}) {
+		t.Errorf("Unexpected RSI rule: %+v", got)
+	}
+	if got := cfg.Rules[ConfirmMomentum]; got != (ConfirmationRule{Weight: 1, Min: 0.5}) {
+		t.Errorf("Unexpected momentum rule: %+v", got)
+	}
+	if got := cfg.Rules[ConfirmVolume]; got.Weight != 0 {
+		t.Errorf("Expected volume to be disabled, got %+v", got)
+	}
+	if DefaultConfirmationConfig().Rules[ConfirmRSI].Weight != 1 {
+		t.Error("Expected parsing not to modify the base config")
+	}
+
+	for _, spec := range []string{"rsi", "unknown:1", "rsi:abc", "rsi:-1", "rsi:1:2:3:4"} {
+		if _, err := ParseConfirmationRules(spec, DefaultConfirmationConfig()); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
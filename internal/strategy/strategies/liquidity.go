@@ -0,0 +1,75 @@
+package strategies
+
+import (
+	"cryptoMegaBot/internal/ports"
+	"fmt"
+)
+
+// LiquidityFilterConfig holds thresholds for the order book liquidity filter
+type LiquidityFilterConfig struct {
+	MaxSpreadPct float64 // Maximum bid/ask spread as a fraction of mid price (e.g., 0.0005 for 0.05%); 0 disables
+	MinDepth     float64 // Minimum quote notional (e.g., USDT) resting on each side within DepthLevels; 0 disables
+	DepthLevels  int     // Number of top levels to sum for the depth check (e.g., 5)
+}
+
+// LiquidityFilter rejects entries into thin or wide order books that would cause slippage
+type LiquidityFilter struct {
+	config LiquidityFilterConfig
+}
+
+// NewLiquidityFilter creates a new liquidity filter instance
+func NewLiquidityFilter(config LiquidityFilterConfig) *LiquidityFilter {
+	if config.DepthLevels <= 0 {
+		config.DepthLevels = 5 // Default to top 5 levels
+	}
+	return &LiquidityFilter{config: config}
+}
+
+// DepthLevels returns the number of order book levels the filter needs
+func (f *LiquidityFilter) DepthLevels() int {
+	return f.config.DepthLevels
+}
+
+// Allow reports whether the order book is liquid enough to enter, and the reason if it isn't
+func (f *LiquidityFilter) Allow(depth *ports.OrderBookDepth) (bool, string) {
+	if depth == nil || len(depth.Bids) == 0 || len(depth.Asks) == 0 {
+		return false, "order book is empty"
+	}
+
+	bestBid := depth.Bids[0].Price
+	bestAsk := depth.Asks[0].Price
+	mid := (bestBid + bestAsk) / 2
+	if mid <= 0 || bestAsk < bestBid {
+		return false, fmt.Sprintf("invalid top of book (bid %.8f, ask %.8f)", bestBid, bestAsk)
+	}
+
+	if f.config.MaxSpreadPct > 0 {
+		spread := (bestAsk - bestBid) / mid
+		if spread > f.config.MaxSpreadPct {
+			return false, fmt.Sprintf("spread %.4f%% exceeds maximum %.4f%%", spread*100, f.config.MaxSpreadPct*100)
+		}
+	}
+
+	if f.config.MinDepth > 0 {
+		bidDepth := sideDepth(depth.Bids, f.config.DepthLevels)
+		askDepth := sideDepth(depth.Asks, f.config.DepthLevels)
+		if bidDepth < f.config.MinDepth || askDepth < f.config.MinDepth {
+			return false, fmt.Sprintf("top %d levels depth (bid %.2f, ask %.2f) below minimum %.2f",
+				f.config.DepthLevels, bidDepth, askDepth, f.config.MinDepth)
+		}
+	}
+
+	return true, ""
+}
+
+// sideDepth sums the quote notional of the top levels of one side of the book
+func sideDepth(levels []ports.OrderBookLevel, maxLevels int) float64 {
+	var total float64
+	for i, level := range levels {
+		if i >= maxLevels {
+			break
+		}
+		total += level.Price * level.Quantity
+	}
+	return total
+}
@@ -2,9 +2,12 @@ package strategies
 
 import (
 	"context"
+	"cryptoMegaBot/internal/clock"
 	"cryptoMegaBot/internal/domain"
 	"cryptoMegaBot/internal/ports"
 	"cryptoMegaBot/internal/strategy/indicators"
+	"cryptoMegaBot/internal/strategy/regime"
+	"encoding/json"
 	"fmt"
 	"math"
 	"time"
@@ -31,13 +34,15 @@ type MACrossoverConfig struct {
 	ScalpSlowPeriod   int    // Slow MA period for scalping (e.g., 13)
 
 	// Day trading parameters
-	MaxDailyLosses         int           // Maximum number of losing trades per day before stopping
-	MaxConsecutiveLosses   int           // Maximum number of consecutive losses before reducing size
-	MaxHoldingTime         time.Duration // Maximum time to hold a position (e.g., 4h for day trading)
-	PartialProfitPct       float64       // Percentage at which to take partial profits (e.g., 0.01 for 1%)
-	TrailingActivePct      float64       // Percentage at which to activate trailing stop (e.g., 0.003 for 0.3%)
-	BreakEvenActivation    float64       // Percentage at which to move stop loss to breakeven (e.g., 0.002 for 0.2%)
-	TrailingStopTightening bool          // Whether to progressively tighten trailing stop as profit increases
+	MaxDailyLosses         int             // Maximum number of losing trades per day before stopping
+	MaxConsecutiveLosses   int             // Maximum number of consecutive losses before reducing size
+	MaxHoldingTime         time.Duration   // Maximum time to hold a position (e.g., 4h for day trading)
+	DisableTimeLimit       bool            // Whether to never close positions for holding too long (ignores MaxHoldingTime)
+	PartialProfitPct       float64         // Percentage at which to take partial profits (e.g., 0.01 for 1%)
+	TrailingActivePct      float64         // Percentage at which to activate trailing stop (e.g., 0.003 for 0.3%)
+	BreakEvenActivation    float64         // Percentage at which to move stop loss to breakeven (e.g., 0.002 for 0.2%)
+	Fees                   domain.FeeModel // Fees and funding used to compute the true breakeven price
+	TrailingStopTightening bool            // Whether to progressively tighten trailing stop as profit increases
 
 	// Risk management parameters
 	InitialRiskPerTrade       float64 // Initial risk per trade as percentage of account (e.g., 0.005 for 0.5%)
@@ -48,6 +53,41 @@ type MACrossoverConfig struct {
 	TradingStartHour int     // Hour to start trading (e.g., 8 for 8:00 AM)
 	TradingEndHour   int     // Hour to end trading (e.g., 20 for 8:00 PM)
 	MaxLeverageUsed  float64 // Maximum leverage to use (e.g., 4.0 for 4x)
+
+	// Limit entry parameters
+	UseLimitEntries      bool    // Whether to wait for a pullback with a limit order instead of entering at market
+	LimitEntryOffset     float64 // Limit price offset below the signal price (e.g., 0.001 for 0.1%)
+	LimitEntryExpiryBars int     // Bars the limit entry stays active (0 uses the backtest default)
+
+	// Entry confirmation scoring (nil Rules uses DefaultConfirmationConfig)
+	Confirmation ConfirmationConfig
+
+	// Open interest confirmation: long entries need open interest rising alongside price, as rallies
+	// on falling open interest are more likely to reverse. It's also scored as ConfirmOpenInterest
+	// (weight 1 unless Confirmation sets it). Entries aren't blocked while no recent snapshots are provided
+	UseOpenInterest      bool   // Whether to require rising open interest for entries
+	OpenInterestPeriod   string // Period of the open interest snapshots (e.g., "5m")
+	OpenInterestLookback int    // Snapshots the open interest and price change are measured over (e.g., 3)
+
+	// Volume profile support/resistance zones
+	UseVolumeProfile     bool    // Whether to skip entries just below resistance and exit profitable positions at resistance
+	VolumeProfilePeriod  int     // Klines the profile is built from (e.g., 96)
+	VolumeProfileBuckets int     // Price buckets in the profile (e.g., 24)
+	VolumeProfileZonePct float64 // Distance to a high volume node that counts as reaching it (e.g., 0.002 for 0.2%)
+
+	// Re-entry rules by the close reason of the previous position (nil allows immediate re-entry)
+	ReEntry domain.ReEntryPolicy
+
+	// Market regime classification: entries need an uptrend with normal volatility. The periods
+	// default to SlowMAPeriod and ATRPeriod, the rest to the regime package defaults
+	Regime regime.Config
+
+	// Source of the current time for the initial trading state (nil uses the system clock)
+	Clock ports.Clock
+
+	// Optional cache of the moving average, RSI and ATR values, shared by strategy instances that
+	// run on the same klines (e.g., the optimizer's parameter combinations)
+	IndicatorCache *indicators.Cache
 }
 
 // MACrossover implements an improved Moving Average Crossover strategy
@@ -55,19 +95,32 @@ type MACrossoverConfig struct {
 type MACrossover struct {
 	*BaseStrategy
 	config     MACrossoverConfig
-	fastMA     *indicators.MovingAverage
-	slowMA     *indicators.MovingAverage
-	signalLine *indicators.MovingAverage
-	atr        *indicators.ATR
-	rsi        *indicators.RSI
+	fastMA     indicators.Indicator
+	slowMA     indicators.Indicator
+	signalLine indicators.Indicator
+	atr        indicators.Indicator
+	rsi        indicators.Indicator
+
+	// Volume profile (nil unless UseVolumeProfile is set)
+	volumeProfile *indicators.VolumeProfile
+
+	// Entry confirmation scoring
+	confirmation *ConfirmationScorer
+	lastEntryTag domain.EntryTag // Tag of the most recent entry signal
+
+	// Open interest snapshots provided by the caller, oldest first (nil until provided)
+	openInterest []*domain.OpenInterest
 
 	// Multi-timeframe indicators
-	trendFastMA *indicators.MovingAverage
-	trendSlowMA *indicators.MovingAverage
+	trendFastMA indicators.Indicator
+	trendSlowMA indicators.Indicator
 
 	// Scalping timeframe indicators
-	scalpFastMA *indicators.MovingAverage
-	scalpSlowMA *indicators.MovingAverage
+	scalpFastMA indicators.Indicator
+	scalpSlowMA indicators.Indicator
+
+	// Klines per interval provided by the caller (nil when only the primary timeframe is available)
+	timeframeKlines map[string][]*domain.Kline
 
 	// Trading state
 	dailyLossCount    int
@@ -76,8 +129,8 @@ type MACrossover struct {
 	partialTakeProfit bool
 	lastTradeResult   float64
 
-	// Volatility tracking
-	recentVolatility []float64
+	// Market regime, with the volatility history
+	regime *regime.Classifier
 
 	// Performance tracking
 	winCount              int
@@ -85,6 +138,10 @@ type MACrossover struct {
 	totalPnL              float64
 	lastTradeTime         time.Time
 	consolidationDetected bool
+
+	// Last exit, for the re-entry rules (zero until a position was closed)
+	lastExitReason domain.CloseReason
+	lastExitTime   time.Time
 }
 
 // NewImprovedMACrossover creates a new Improved MA Crossover strategy instance
@@ -135,60 +192,123 @@ func NewImprovedMACrossover(config MACrossoverConfig, logger ports.Logger) (*MAC
 	if config.ScalpSlowPeriod == 0 {
 		config.ScalpSlowPeriod = 13 // Default to 13 periods for scalping slow MA
 	}
+	if config.UseLimitEntries && config.LimitEntryOffset == 0 {
+		config.LimitEntryOffset = 0.001 // Default to 0.1% below the signal price
+	}
+	if config.UseVolumeProfile {
+		if config.VolumeProfilePeriod == 0 {
+			config.VolumeProfilePeriod = 96 // Default to one day of 15m klines
+		}
+		if config.VolumeProfileZonePct == 0 {
+			config.VolumeProfileZonePct = 0.002 // Default to within 0.2% of a zone
+		}
+		if config.VolumeProfilePeriod < 0 || config.VolumeProfileZonePct < 0 {
+			return nil, fmt.Errorf("volume profile period and zone distance cannot be negative")
+		}
+	}
+	if config.Confirmation.Rules == nil {
+		config.Confirmation = DefaultConfirmationConfig()
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real{}
+	}
+	if config.UseOpenInterest {
+		if config.OpenInterestPeriod == "" {
+			config.OpenInterestPeriod = "5m" // Default to the finest period Binance serves
+		}
+		if config.OpenInterestLookback == 0 {
+			config.OpenInterestLookback = 3 // Default to the last 3 snapshots
+		}
+		if config.OpenInterestLookback < 0 {
+			return nil, fmt.Errorf("open interest lookback cannot be negative")
+		}
+		if _, ok := config.Confirmation.Rules[ConfirmOpenInterest]; !ok {
+			// Copy the rules so the caller's map isn't modified
+			rules := make(map[ConfirmationCondition]ConfirmationRule, len(config.Confirmation.Rules)+1)
+			for cond, rule := range config.Confirmation.Rules {
+				rules[cond] = rule
+			}
+			rules[ConfirmOpenInterest] = ConfirmationRule{Weight: 1}
+			config.Confirmation.Rules = rules
+		}
+	}
+	confirmation, err := NewConfirmationScorer(config.Confirmation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid confirmation config: %w", err)
+	}
+	if config.Regime.MAPeriod == 0 {
+		config.Regime.MAPeriod = config.SlowMAPeriod
+	}
+	if config.Regime.ATRPeriod == 0 {
+		config.Regime.ATRPeriod = config.ATRPeriod
+	}
+	classifier, err := regime.New(config.Regime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regime config: %w", err)
+	}
 
 	// Create indicators with simplified configuration
-	fastMA := indicators.NewMovingAverage(indicators.MovingAverageConfig{
+	cache := config.IndicatorCache
+	fastMA := cache.Wrap(indicators.NewMovingAverage(indicators.MovingAverageConfig{
 		IndicatorConfig: indicators.IndicatorConfig{Period: config.FastMAPeriod},
 		Type:            indicators.ExponentialMovingAverage, // EMA for faster response
-	})
+	}))
 
-	slowMA := indicators.NewMovingAverage(indicators.MovingAverageConfig{
+	slowMA := cache.Wrap(indicators.NewMovingAverage(indicators.MovingAverageConfig{
 		IndicatorConfig: indicators.IndicatorConfig{Period: config.SlowMAPeriod},
 		Type:            indicators.ExponentialMovingAverage,
-	})
+	}))
 
-	signalLine := indicators.NewMovingAverage(indicators.MovingAverageConfig{
+	signalLine := cache.Wrap(indicators.NewMovingAverage(indicators.MovingAverageConfig{
 		IndicatorConfig: indicators.IndicatorConfig{Period: config.SignalPeriod},
 		Type:            indicators.ExponentialMovingAverage,
-	})
+	}))
 
-	atr := indicators.NewATR(indicators.ATRConfig{
+	atr := cache.Wrap(indicators.NewATR(indicators.ATRConfig{
 		IndicatorConfig: indicators.IndicatorConfig{Period: config.ATRPeriod},
-	})
+	}))
 
 	// RSI for additional confirmation
-	rsi := indicators.NewRSI(indicators.RSIConfig{
+	rsi := cache.Wrap(indicators.NewRSI(indicators.RSIConfig{
 		IndicatorConfig: indicators.IndicatorConfig{Period: 14},
 		Overbought:      70,
 		Oversold:        30,
-	})
+	}))
+
+	var volumeProfile *indicators.VolumeProfile
+	if config.UseVolumeProfile {
+		volumeProfile = indicators.NewVolumeProfile(indicators.VolumeProfileConfig{
+			IndicatorConfig: indicators.IndicatorConfig{Period: config.VolumeProfilePeriod},
+			Buckets:         config.VolumeProfileBuckets,
+		})
+	}
 
 	// Create trend timeframe indicators if multi-timeframe is enabled
-	var trendFastMA, trendSlowMA *indicators.MovingAverage
+	var trendFastMA, trendSlowMA indicators.Indicator
 	if config.UseMultiTimeframe {
-		trendFastMA = indicators.NewMovingAverage(indicators.MovingAverageConfig{
+		trendFastMA = cache.Wrap(indicators.NewMovingAverage(indicators.MovingAverageConfig{
 			IndicatorConfig: indicators.IndicatorConfig{Period: config.FastMAPeriod},
 			Type:            indicators.ExponentialMovingAverage,
-		})
+		}))
 
-		trendSlowMA = indicators.NewMovingAverage(indicators.MovingAverageConfig{
+		trendSlowMA = cache.Wrap(indicators.NewMovingAverage(indicators.MovingAverageConfig{
 			IndicatorConfig: indicators.IndicatorConfig{Period: config.SlowMAPeriod},
 			Type:            indicators.ExponentialMovingAverage,
-		})
+		}))
 	}
 
 	// Create scalping timeframe indicators if enabled
-	var scalpFastMA, scalpSlowMA *indicators.MovingAverage
+	var scalpFastMA, scalpSlowMA indicators.Indicator
 	if config.UseScalpTimeframe {
-		scalpFastMA = indicators.NewMovingAverage(indicators.MovingAverageConfig{
+		scalpFastMA = cache.Wrap(indicators.NewMovingAverage(indicators.MovingAverageConfig{
 			IndicatorConfig: indicators.IndicatorConfig{Period: config.ScalpFastPeriod},
 			Type:            indicators.ExponentialMovingAverage,
-		})
+		}))
 
-		scalpSlowMA = indicators.NewMovingAverage(indicators.MovingAverageConfig{
+		scalpSlowMA = cache.Wrap(indicators.NewMovingAverage(indicators.MovingAverageConfig{
 			IndicatorConfig: indicators.IndicatorConfig{Period: config.ScalpSlowPeriod},
 			Type:            indicators.ExponentialMovingAverage,
-		})
+		}))
 	}
 
 	return &MACrossover{
@@ -199,20 +319,22 @@ func NewImprovedMACrossover(config MACrossoverConfig, logger ports.Logger) (*MAC
 		signalLine:            signalLine,
 		atr:                   atr,
 		rsi:                   rsi,
+		volumeProfile:         volumeProfile,
+		confirmation:          confirmation,
 		trendFastMA:           trendFastMA,
 		trendSlowMA:           trendSlowMA,
 		scalpFastMA:           scalpFastMA,
 		scalpSlowMA:           scalpSlowMA,
 		dailyLossCount:        0,
 		consecutiveLosses:     0,
-		lastLossResetDay:      time.Now().Truncate(24 * time.Hour),
+		lastLossResetDay:      config.Clock.Now().Truncate(24 * time.Hour),
 		partialTakeProfit:     false,
 		lastTradeResult:       0,
-		recentVolatility:      make([]float64, 0, 20), // Track last 20 ATR values
+		regime:                classifier,
 		winCount:              0,
 		lossCount:             0,
 		totalPnL:              0,
-		lastTradeTime:         time.Now(),
+		lastTradeTime:         config.Clock.Now(),
 		consolidationDetected: false,
 	}, nil
 }
@@ -222,6 +344,59 @@ func (m *MACrossover) Name() string {
 	return "Improved Moving Average Crossover"
 }
 
+// maCrossoverState is the persisted subset of MACrossover's trading state
+type maCrossoverState struct {
+	DailyLossCount    int          `json:"dailyLossCount"`
+	ConsecutiveLosses int          `json:"consecutiveLosses"`
+	LastLossResetDay  time.Time    `json:"lastLossResetDay"`
+	LastTradeResult   float64      `json:"lastTradeResult"`
+	RecentVolatility  []float64    `json:"recentVolatility"`
+	Regime            regime.State `json:"regime"`
+
+	LastExitReason domain.CloseReason `json:"lastExitReason,omitempty"`
+	LastExitTime   time.Time          `json:"lastExitTime,omitempty"`
+}
+
+// SaveState serializes the loss counters and volatility history so risk throttles survive a restart
+func (m *MACrossover) SaveState(ctx context.Context) ([]byte, error) {
+	return json.Marshal(maCrossoverState{
+		DailyLossCount:    m.dailyLossCount,
+		ConsecutiveLosses: m.consecutiveLosses,
+		LastLossResetDay:  m.lastLossResetDay,
+		LastTradeResult:   m.lastTradeResult,
+		RecentVolatility:  m.regime.RecentVolatility(),
+		Regime:            m.regime.State(),
+		LastExitReason:    m.lastExitReason,
+		LastExitTime:      m.lastExitTime,
+	})
+}
+
+// LoadState restores state produced by SaveState
+func (m *MACrossover) LoadState(ctx context.Context, data []byte) error {
+	var state maCrossoverState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to decode strategy state: %w", err)
+	}
+
+	m.dailyLossCount = state.DailyLossCount
+	m.consecutiveLosses = state.ConsecutiveLosses
+	m.lastLossResetDay = state.LastLossResetDay
+	m.lastTradeResult = state.LastTradeResult
+	m.lastExitReason = state.LastExitReason
+	m.lastExitTime = state.LastExitTime
+
+	m.regime.Restore(state.Regime, state.RecentVolatility) // States saved before regimes were kept restore only the volatility
+
+	m.logger.Info(ctx, "Strategy state restored", map[string]interface{}{
+		"dailyLossCount":    m.dailyLossCount,
+		"consecutiveLosses": m.consecutiveLosses,
+		"lastLossResetDay":  m.lastLossResetDay,
+		"volatilityPoints":  len(m.regime.RecentVolatility()),
+		"regime":            m.regime.State().Trend,
+	})
+	return nil
+}
+
 // RequiredDataPoints returns the minimum number of klines needed for the strategy
 func (m *MACrossover) RequiredDataPoints() int {
 	// Use the maximum period plus some buffer for calculations
@@ -229,70 +404,40 @@ func (m *MACrossover) RequiredDataPoints() int {
 	if m.config.ATRPeriod > maxPeriod {
 		maxPeriod = m.config.ATRPeriod
 	}
-	return maxPeriod + 30 // Add buffer for trend detection
+	required := maxPeriod + 30 // Add buffer for trend detection
+	if m.volumeProfile != nil && m.config.VolumeProfilePeriod > required {
+		required = m.config.VolumeProfilePeriod
+	}
+	return required
 }
 
-// detectMarketRegime determines if the market is in a tradeable regime
-// Returns: isUptrend, isTradeable, trendStrength
-func (m *MACrossover) detectMarketRegime(ctx context.Context, klines []*domain.Kline) (bool, bool, float64) {
-	// Calculate slow MA for multiple periods to determine trend direction
-	slowMA, err := m.slowMA.Calculate(ctx, klines)
-	if err != nil {
-		m.logger.Error(ctx, err, "Failed to calculate slow MA")
-		return false, false, 0
+// nearResistance reports whether price is inside or within VolumeProfileZonePct below a high volume
+// node of the volume profile, returning the node. It is always false when the profile is disabled
+func (m *MACrossover) nearResistance(ctx context.Context, klines []*domain.Kline, price float64) (indicators.VolumeNode, bool) {
+	if m.volumeProfile == nil {
+		return indicators.VolumeNode{}, false
 	}
-
-	// Calculate slow MA for previous periods to determine trend direction
-	prevKlines := klines[:len(klines)-5] // 5 periods ago
-	prevSlowMA, err := m.slowMA.Calculate(ctx, prevKlines)
+	profile, err := m.volumeProfile.Profile(ctx, klines)
 	if err != nil {
-		m.logger.Error(ctx, err, "Failed to calculate previous slow MA")
-		return false, false, 0
+		m.logger.Debug(ctx, "Failed to build volume profile", map[string]interface{}{"error": err.Error()})
+		return indicators.VolumeNode{}, false
 	}
-
-	// Calculate even earlier slow MA to confirm trend persistence
-	earlierKlines := klines[:len(klines)-10] // 10 periods ago
-	earlierSlowMA, err := m.slowMA.Calculate(ctx, earlierKlines)
-	if err != nil {
-		m.logger.Error(ctx, err, "Failed to calculate earlier slow MA")
-		return false, false, 0
+	node, ok := profile.ResistanceAbove(price)
+	if !ok || node.Low > price*(1+m.config.VolumeProfileZonePct) {
+		return indicators.VolumeNode{}, false
 	}
+	return node, true
+}
 
-	// Calculate ATR to measure volatility
-	atr, err := m.atr.Calculate(ctx, klines)
+// detectMarketRegime determines if the market is in a tradeable regime
+// Returns: isUptrend, isTradeable, trendStrength
+func (m *MACrossover) detectMarketRegime(ctx context.Context, klines []*domain.Kline) (bool, bool, float64) {
+	state, err := m.regime.Update(ctx, klines)
 	if err != nil {
-		m.logger.Error(ctx, err, "Failed to calculate ATR")
+		m.logger.Error(ctx, err, "Failed to classify market regime")
 		return false, false, 0
 	}
-
-	// Current price
-	currentPrice := klines[len(klines)-1].Close
-
-	// Determine trend direction and strength
-	isUptrend := slowMA > prevSlowMA && prevSlowMA > earlierSlowMA
-	trendStrength := (slowMA/earlierSlowMA - 1) * 100 // Percentage change
-
-	// Volatility as percentage of price
-	volatilityPercent := atr / currentPrice * 100
-
-	// Track recent volatility for trend analysis
-	if len(m.recentVolatility) >= 20 {
-		m.recentVolatility = m.recentVolatility[1:] // Remove oldest
-	}
-	m.recentVolatility = append(m.recentVolatility, volatilityPercent)
-
-	// Calculate average volatility
-	avgVolatility := 0.0
-	for _, v := range m.recentVolatility {
-		avgVolatility += v
-	}
-	if len(m.recentVolatility) > 0 {
-		avgVolatility /= float64(len(m.recentVolatility))
-	}
-
-	// Check if current volatility is expanding (good for trend following)
-	isVolatilityExpanding := len(m.recentVolatility) >= 5 &&
-		volatilityPercent > avgVolatility*1.1 // 10% above average
+	isUptrend := state.Trend == regime.TrendUp
 
 	// Check trading hours if enabled
 	isWithinTradingHours := true
@@ -313,32 +458,30 @@ func (m *MACrossover) detectMarketRegime(ctx context.Context, klines []*domain.K
 	isUnderLossLimit := m.dailyLossCount < m.config.MaxDailyLosses
 
 	// Market is tradeable if:
-	// 1. There's a clear trend direction
-	// 2. Trend strength is significant (> 0.15%) - reduced from 0.2% for more trades
-	// 3. Volatility is reasonable (not too low, not too high) - widened range
-	// 4. Within trading hours (if enabled)
-	// 5. Under daily loss limit
+	// 1. The regime is an uptrend (slow MA rising by more than the trend threshold)
+	// 2. Volatility is normal (not too low, not too high)
+	// 3. Within trading hours (if enabled)
+	// 4. Under daily loss limit
 	isTradeable := isUptrend &&
-		trendStrength > 0.15 &&
-		volatilityPercent > 0.15 && // Reduced from 0.2% for more trades
-		volatilityPercent < 5.0 && // Increased from 4.0% for more trades
+		state.Volatility == regime.VolatilityNormal &&
 		isWithinTradingHours &&
 		isUnderLossLimit
 
 	// Log detailed market regime information
 	m.logger.Debug(ctx, "Market regime analysis", map[string]interface{}{
-		"isUptrend":             isUptrend,
-		"trendStrength":         trendStrength,
-		"volatilityPercent":     volatilityPercent,
-		"avgVolatility":         avgVolatility,
-		"isVolatilityExpanding": isVolatilityExpanding,
+		"trend":                 state.Trend,
+		"volatility":            state.Volatility,
+		"trendStrength":         state.TrendStrength,
+		"volatilityPercent":     state.VolatilityPct,
+		"avgVolatility":         state.AverageVolatilityPct,
+		"isVolatilityExpanding": state.VolatilityExpanding(),
 		"isWithinTradingHours":  isWithinTradingHours,
 		"dailyLossCount":        m.dailyLossCount,
 		"isUnderLossLimit":      isUnderLossLimit,
 		"isTradeable":           isTradeable,
 	})
 
-	return isUptrend, isTradeable, trendStrength
+	return isUptrend, isTradeable, state.TrendStrength
 }
 
 // analyzeHigherTimeframe analyzes the trend on a higher timeframe
@@ -701,12 +844,33 @@ func (m *MACrossover) ShouldEnterTrade(ctx context.Context, klines []*domain.Kli
 		return false
 	}
 
+	// 0. Apply the re-entry rule of the last exit: wait out its cooldown, and only take a
+	// crossover formed after the exit if it requires a fresh one
+	freshCrossoverOnly := false
+	if !m.lastExitTime.IsZero() {
+		now := klines[len(klines)-1].CloseTime
+		if remaining := m.config.ReEntry.CooldownRemaining(m.lastExitReason, m.lastExitTime, now); remaining > 0 {
+			m.logger.Debug(ctx, "Re-entry cooldown active", map[string]interface{}{
+				"lastExitReason": m.lastExitReason,
+				"remaining":      remaining.String(),
+			})
+			return false
+		}
+		freshCrossoverOnly = m.config.ReEntry.Rule(m.lastExitReason).RequireCrossover
+	}
+
 	// 1. Check market regime first - only trade in favorable conditions
 	isUptrend, isTradeable, trendStrength := m.detectMarketRegime(ctx, klines)
 	if !isTradeable {
 		// Check for scalping opportunity even if main regime isn't tradeable
-		if m.config.UseScalpTimeframe && m.detectScalpingOpportunity(ctx, klines, currentPrice) {
+		if !freshCrossoverOnly && m.config.UseScalpTimeframe && m.detectScalpingOpportunity(ctx, m.klinesFor(m.config.ScalpTimeframe, klines), currentPrice) {
 			m.logger.Info(ctx, "Entering trade based on scalping opportunity despite unfavorable market regime", nil)
+			atr, _ := m.atr.Calculate(ctx, klines)
+			m.lastEntryTag = domain.EntryTag{
+				EntryReason:  "scalping opportunity in unfavorable regime",
+				SignalSource: domain.SignalSourceScalp,
+				EntryATR:     atr,
+			}
 			return true
 		}
 
@@ -723,9 +887,8 @@ func (m *MACrossover) ShouldEnterTrade(ctx context.Context, klines []*domain.Kli
 	var higherTimeframeTrendStrength float64
 
 	if m.config.UseMultiTimeframe {
-		// This would be implemented in a real system by passing the higher timeframe klines
-		// For now, we'll use the same klines but assume they're from the higher timeframe
-		higherTimeframeUptrend, higherTimeframeTrendStrength = m.analyzeHigherTimeframe(ctx, klines)
+		// Falls back to the primary klines when no higher timeframe data has been provided
+		higherTimeframeUptrend, higherTimeframeTrendStrength = m.analyzeHigherTimeframe(ctx, m.klinesFor(m.config.TrendTimeframe, klines))
 
 		// Only proceed if higher timeframe is in uptrend
 		if !higherTimeframeUptrend {
@@ -805,53 +968,62 @@ func (m *MACrossover) ShouldEnterTrade(ctx context.Context, klines []*domain.Kli
 		fastMA > calculateMA(klines, len(klines)-5, m.config.FastMAPeriod) && // Trend is rising
 		m.detectPullback(ctx, klines, currentPrice) // Detected a pullback
 
-	// Signal line confirmation
-	isAboveSignal := currentPrice > signalMA
-
-	// RSI condition: Not overbought, in healthy range (widened range)
-	isHealthyRSI := rsi > 35 && rsi < 68 // Widened from 40-65 for more trades
-
-	// Momentum condition: Positive momentum (reduced threshold)
-	isStrongMomentum := momentum > 0.3 // Reduced from 0.5 for more trades
-
-	// Volume condition: Increasing volume
-	isIncreasingVolume := volumeRatio > 1.1 // Reduced from 1.2 for more trades
-
-	// Pattern recognition
-	hasConfirmationPattern := isHigherHigh || isHigherLow
-
-	// Volatility check: ATR should be reasonable relative to price
-	isReasonableVolatility := atr < (currentPrice * 0.015) // Increased from 0.012 for more trades
-
-	// Count how many confirmation conditions are met
-	confirmationCount := 0
-	if isAboveSignal {
-		confirmationCount++
-	}
-	if isHealthyRSI {
-		confirmationCount++
-	}
-	if isStrongMomentum {
-		confirmationCount++
-	}
-	if isIncreasingVolume {
-		confirmationCount++
-	}
-	if hasConfirmationPattern {
-		confirmationCount++
-	}
-	if isReasonableVolatility {
-		confirmationCount++
+	// Open interest and price change over the recent snapshots
+	oiChange, oiPriceChange, oiAvailable := m.openInterestTrend(klines, currentPrice)
+
+	// Score confirmation conditions (signal line, RSI, momentum, volume, pattern, volatility,
+	// higher timeframe, open interest) using the configured weights and thresholds
+	confirmation := m.confirmation.Score(ConfirmationInputs{
+		Price:                   currentPrice,
+		SignalMA:                signalMA,
+		RSI:                     rsi,
+		Momentum:                momentum,
+		VolumeRatio:             volumeRatio,
+		ATR:                     atr,
+		HigherHigh:              isHigherHigh,
+		HigherLow:               isHigherLow,
+		HigherTimeframeEnabled:  m.config.UseMultiTimeframe,
+		HigherTimeframeUptrend:  higherTimeframeUptrend,
+		HigherTimeframeStrength: higherTimeframeTrendStrength,
+		OpenInterestAvailable:   oiAvailable,
+		OpenInterestChange:      oiChange,
+		OpenInterestPriceChange: oiPriceChange,
+	})
+	confirmationCount := confirmation.Count
+
+	// A rally on falling open interest is short covering rather than new longs and tends to reverse
+	if m.config.UseOpenInterest && oiAvailable && !conditionMet(ConfirmOpenInterest, m.config.Confirmation.Rules[ConfirmOpenInterest], ConfirmationInputs{
+		OpenInterestAvailable:   true,
+		OpenInterestChange:      oiChange,
+		OpenInterestPriceChange: oiPriceChange,
+	}) {
+		m.logger.Debug(ctx, "Entry skipped without rising open interest", map[string]interface{}{
+			"currentPrice":       currentPrice,
+			"openInterestChange": oiChange,
+			"priceChange":        oiPriceChange,
+		})
+		return false
 	}
 
-	// Add multi-timeframe confirmation if enabled
-	if m.config.UseMultiTimeframe && higherTimeframeUptrend && higherTimeframeTrendStrength > 0.3 {
-		confirmationCount++
+	crossoverEntry := hasCrossedAbove && isPriceAboveMAs
+	if freshCrossoverOnly {
+		// Only a crossover whose earlier bar opened after the exit counts as fresh
+		crossoverEntry = crossoverEntry && !klines[len(klines)-3].OpenTime.Before(m.lastExitTime)
+		isPullbackEntry = false
 	}
 
-	// Need primary conditions plus at least 2 confirmation conditions (reduced from 3)
+	// Need primary conditions plus enough confirmation score
 	// Also allow pullback entries in established uptrends
-	if ((hasCrossedAbove && isPriceAboveMAs) || isPullbackEntry) && confirmationCount >= 2 {
+	if (crossoverEntry || isPullbackEntry) && confirmation.Confirmed {
+		// Don't buy straight into a volume profile resistance zone
+		if zone, ok := m.nearResistance(ctx, klines, currentPrice); ok {
+			m.logger.Debug(ctx, "Entry skipped near volume profile resistance", map[string]interface{}{
+				"currentPrice":   currentPrice,
+				"resistanceLow":  zone.Low,
+				"resistanceHigh": zone.High,
+			})
+			return false
+		}
 		m.logger.Info(ctx, "Trade entry conditions met", map[string]interface{}{
 			"currentPrice":      currentPrice,
 			"fastMA":            fastMA,
@@ -863,39 +1035,166 @@ func (m *MACrossover) ShouldEnterTrade(ctx context.Context, klines []*domain.Kli
 			"atr":               atr,
 			"trendStrength":     trendStrength,
 			"confirmationCount": confirmationCount,
+			"confirmationScore": confirmation.Score,
 			"isPullbackEntry":   isPullbackEntry,
 			"hasCrossedAbove":   hasCrossedAbove,
 		})
+		m.lastEntryTag = domain.EntryTag{
+			EntryReason:       "fast MA crossed above slow MA",
+			SignalSource:      domain.SignalSourceCrossover,
+			ConfirmationCount: confirmationCount,
+			EntryATR:          atr,
+		}
+		if !crossoverEntry {
+			m.lastEntryTag.EntryReason = "pullback in established uptrend"
+			m.lastEntryTag.SignalSource = domain.SignalSourcePullback
+		}
 		return true
 	}
 
 	// Check for scalping opportunity as a last resort
-	if m.config.UseScalpTimeframe && m.detectScalpingOpportunity(ctx, klines, currentPrice) {
+	if !freshCrossoverOnly && m.config.UseScalpTimeframe && m.detectScalpingOpportunity(ctx, m.klinesFor(m.config.ScalpTimeframe, klines), currentPrice) {
 		m.logger.Info(ctx, "Trade entry conditions met via scalping opportunity", nil)
+		m.lastEntryTag = domain.EntryTag{
+			EntryReason:       "scalping opportunity",
+			SignalSource:      domain.SignalSourceScalp,
+			ConfirmationCount: confirmationCount,
+			EntryATR:          atr,
+		}
 		return true
 	}
 
 	m.logger.Debug(ctx, "Trade entry conditions not met", map[string]interface{}{
-		"currentPrice":       currentPrice,
-		"fastMA":             fastMA,
-		"slowMA":             slowMA,
-		"signalMA":           signalMA,
-		"rsi":                rsi,
-		"momentum":           momentum,
-		"volumeRatio":        volumeRatio,
-		"atr":                atr,
-		"hasCrossedAbove":    hasCrossedAbove,
-		"isPriceAboveMAs":    isPriceAboveMAs,
-		"isPullbackEntry":    isPullbackEntry,
-		"isAboveSignal":      isAboveSignal,
-		"isHealthyRSI":       isHealthyRSI,
-		"isStrongMomentum":   isStrongMomentum,
-		"isIncreasingVolume": isIncreasingVolume,
-		"confirmationCount":  confirmationCount,
+		"currentPrice":      currentPrice,
+		"fastMA":            fastMA,
+		"slowMA":            slowMA,
+		"signalMA":          signalMA,
+		"rsi":               rsi,
+		"momentum":          momentum,
+		"volumeRatio":       volumeRatio,
+		"atr":               atr,
+		"hasCrossedAbove":   hasCrossedAbove,
+		"isPriceAboveMAs":   isPriceAboveMAs,
+		"isPullbackEntry":   isPullbackEntry,
+		"confirmationsMet":  confirmation.Met,
+		"confirmationCount": confirmationCount,
+		"confirmationScore": confirmation.Score,
 	})
 	return false
 }
 
+// PositionClosed records the close reason and time of the last position for the re-entry rules
+// (implements ports.ExitAwareStrategy)
+func (m *MACrossover) PositionClosed(ctx context.Context, position *domain.Position) {
+	if position == nil || position.ExitTime.IsZero() {
+		return
+	}
+	m.lastExitReason = position.CloseReason
+	m.lastExitTime = position.ExitTime
+	if rule := m.config.ReEntry.Rule(position.CloseReason); rule != (domain.ReEntryRule{}) {
+		m.logger.Debug(ctx, "Re-entry rule applies to the next entry", map[string]interface{}{
+			"closeReason":      position.CloseReason,
+			"cooldown":         rule.Cooldown.String(),
+			"requireCrossover": rule.RequireCrossover,
+		})
+	}
+}
+
+// Timeframes returns the additional intervals the strategy analyzes (implements ports.MultiTimeframeStrategy)
+func (m *MACrossover) Timeframes() []string {
+	var timeframes []string
+	if m.config.UseMultiTimeframe && m.config.TrendTimeframe != "" {
+		timeframes = append(timeframes, m.config.TrendTimeframe)
+	}
+	if m.config.UseScalpTimeframe && m.config.ScalpTimeframe != "" {
+		timeframes = append(timeframes, m.config.ScalpTimeframe)
+	}
+	return timeframes
+}
+
+// SetTimeframeData provides the latest klines per interval (implements ports.MultiTimeframeStrategy)
+func (m *MACrossover) SetTimeframeData(klines map[string][]*domain.Kline) {
+	m.timeframeKlines = klines
+}
+
+// klinesFor returns the provided klines for a timeframe, or fallback if none have been provided
+func (m *MACrossover) klinesFor(timeframe string, fallback []*domain.Kline) []*domain.Kline {
+	if klines := m.timeframeKlines[timeframe]; len(klines) > 0 {
+		return klines
+	}
+	return fallback
+}
+
+// OpenInterestPeriod returns the period of the open interest snapshots the strategy needs, or ""
+// if it doesn't use open interest (implements ports.OpenInterestStrategy)
+func (m *MACrossover) OpenInterestPeriod() string {
+	if !m.config.UseOpenInterest {
+		return ""
+	}
+	return m.config.OpenInterestPeriod
+}
+
+// SetOpenInterest provides the latest open interest snapshots, oldest first (implements ports.OpenInterestStrategy)
+func (m *MACrossover) SetOpenInterest(snapshots []*domain.OpenInterest) {
+	m.openInterest = snapshots
+}
+
+// openInterestTrend returns the open interest change and the price change (fractions) over the
+// last OpenInterestLookback snapshots, or false if there aren't enough recent snapshots
+func (m *MACrossover) openInterestTrend(klines []*domain.Kline, currentPrice float64) (float64, float64, bool) {
+	lookback := m.config.OpenInterestLookback
+	n := len(m.openInterest)
+	if !m.config.UseOpenInterest || lookback <= 0 || n <= lookback || len(klines) == 0 {
+		return 0, 0, false
+	}
+	first, last := m.openInterest[n-1-lookback], m.openInterest[n-1]
+	if first.Contracts <= 0 {
+		return 0, 0, false
+	}
+	// Snapshots older than the window they span no longer describe the current move
+	if klines[len(klines)-1].CloseTime.Sub(last.Time) > last.Time.Sub(first.Time) {
+		return 0, 0, false
+	}
+
+	// Price at the first snapshot is the close of the last kline closed by then
+	startPrice := 0.0
+	for i := len(klines) - 1; i >= 0; i-- {
+		if !klines[i].CloseTime.After(first.Time) {
+			startPrice = klines[i].Close
+			break
+		}
+	}
+	if startPrice <= 0 {
+		return 0, 0, false
+	}
+	return (last.Contracts - first.Contracts) / first.Contracts, (currentPrice - startPrice) / startPrice, true
+}
+
+// LastEntryTag returns the tag of the most recent entry signal (implements ports.EntryTagger)
+func (m *MACrossover) LastEntryTag() domain.EntryTag {
+	return m.lastEntryTag
+}
+
+// GetEntryOrder decides how an entry signal is executed. With limit entries enabled the strategy
+// rests a limit below the signal price to buy the pullback, unless price is already recovering from one
+func (m *MACrossover) GetEntryOrder(ctx context.Context, klines []*domain.Kline, currentPrice float64) EntryOrder {
+	if !m.config.UseLimitEntries || m.detectPullback(ctx, klines, currentPrice) {
+		return EntryOrder{Type: EntryOrderMarket}
+	}
+
+	limitPrice := currentPrice * (1 - m.config.LimitEntryOffset)
+	m.logger.Debug(ctx, "Placing limit entry", map[string]interface{}{
+		"signalPrice": currentPrice,
+		"limitPrice":  limitPrice,
+		"expiryBars":  m.config.LimitEntryExpiryBars,
+	})
+	return EntryOrder{
+		Type:       EntryOrderLimit,
+		LimitPrice: limitPrice,
+		ExpiryBars: m.config.LimitEntryExpiryBars,
+	}
+}
+
 // ShouldClosePosition implements the strategy's exit logic with improved risk management
 func (m *MACrossover) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
 	if !position.IsOpen() {
@@ -945,14 +1244,14 @@ func (m *MACrossover) ShouldClosePosition(ctx context.Context, position *domain.
 		return true, domain.CloseReasonMarketClose
 	}
 
-	// 1. Dynamic time-based exit based on configuration
+	// 1. Dynamic time-based exit based on configuration (unless disabled)
 	currentKlineTime := klines[len(klines)-1].OpenTime
 	holdingTime := currentKlineTime.Sub(position.EntryTime)
 
 	// Use more sophisticated dynamic holding time calculation
 	adjustedMaxHoldingTime := m.calculateDynamicHoldingTime(ctx, klines, position, profitPercent)
 
-	if holdingTime > adjustedMaxHoldingTime {
+	if !m.config.DisableTimeLimit && holdingTime > adjustedMaxHoldingTime {
 		m.logger.Info(ctx, "Closing position due to max holding time reached", map[string]interface{}{
 			"entryTime":            position.EntryTime,
 			"currentKlineTime":     currentKlineTime,
@@ -1055,6 +1354,9 @@ func (m *MACrossover) ShouldClosePosition(ctx context.Context, position *domain.
 		}
 	}
 
+	// Breakeven covers round-trip fees and the funding accrued so far, not just the entry price
+	breakEven := m.config.Fees.BreakEvenPrice(position.EntryPrice, currentKlineTime.Sub(position.EntryTime))
+
 	// 2.1 Partial profit taking at 0.5% profit (was 1%)
 	if profitPercent >= m.config.PartialProfitPct*100 && !m.partialTakeProfit {
 		m.partialTakeProfit = true
@@ -1066,19 +1368,20 @@ func (m *MACrossover) ShouldClosePosition(ctx context.Context, position *domain.
 		})
 		// In a real implementation, we would reduce position size here
 		// For backtesting, we'll just log it and move stop loss to breakeven
-		if position.StopLoss < position.EntryPrice {
-			position.StopLoss = position.EntryPrice * 1.001 // Breakeven + 0.1%
+		if position.StopLoss < breakEven && currentPrice > breakEven*1.001 {
+			position.StopLoss = breakEven * 1.001 // Breakeven + 0.1%
 			m.logger.Info(ctx, "Moving stop loss to breakeven after partial profit", map[string]interface{}{
 				"newStopLoss": position.StopLoss,
 			})
 		}
 	}
 
-	// 2.2 Earlier breakeven activation
-	if profitPercent >= m.config.BreakEvenActivation*100 && position.StopLoss < position.EntryPrice {
-		position.StopLoss = position.EntryPrice * 1.0001 // Breakeven + 0.01%
+	// 2.2 Earlier breakeven activation, once price has cleared the fee-adjusted breakeven
+	if profitPercent >= m.config.BreakEvenActivation*100 && position.StopLoss < breakEven && currentPrice > breakEven*1.0001 {
+		position.StopLoss = breakEven * 1.0001 // Breakeven + 0.01%
 		m.logger.Info(ctx, "Moving stop loss to breakeven at small profit", map[string]interface{}{
 			"profitPercent": profitPercent,
+			"breakEven":     breakEven,
 			"newStopLoss":   position.StopLoss,
 		})
 	}
@@ -1090,7 +1393,7 @@ func (m *MACrossover) ShouldClosePosition(ctx context.Context, position *domain.
 			"trailingStopPrice": position.TrailingStopPrice,
 			"profitPercent":     profitPercent,
 		})
-		return true, domain.CloseReasonStopLoss
+		return true, domain.CloseReasonTrailingStop
 	}
 
 	// 3. Improved dynamic stop loss with wider initial stop
@@ -1122,9 +1425,25 @@ func (m *MACrossover) ShouldClosePosition(ctx context.Context, position *domain.
 			"atrStopLoss":     atrStopLoss,
 			"profitPercent":   profitPercent,
 		})
+		if dynamicStopLoss >= position.EntryPrice {
+			return true, domain.CloseReasonBreakEven // Stop had been moved to breakeven or into profit
+		}
 		return true, domain.CloseReasonStopLoss
 	}
 
+	// 3.1 Exit a profitable position at volume profile resistance, where price is likely to stall
+	if profitPercent > 0 {
+		if zone, ok := m.nearResistance(ctx, klines, currentPrice); ok && zone.Low > position.EntryPrice {
+			m.logger.Info(ctx, "Closing position at volume profile resistance", map[string]interface{}{
+				"currentPrice":   currentPrice,
+				"resistanceLow":  zone.Low,
+				"resistanceHigh": zone.High,
+				"profitPercent":  profitPercent,
+			})
+			return true, domain.CloseReasonResistance
+		}
+	}
+
 	// 4. Take profit check
 	if currentPrice >= position.TakeProfit {
 		m.logger.Info(ctx, "Take profit triggered", map[string]interface{}{
@@ -0,0 +1,253 @@
+package strategies
+
+import (
+	"context"
+	"cryptoMegaBot/internal/adapters/logger"
+	"cryptoMegaBot/internal/clock"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/indicators"
+	"cryptoMegaBot/internal/strategy/klinegen"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMACrossover_ShouldEnterTrade(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []klinegen.Segment
+		wantAny  bool // Whether any bar of the last segment signals an entry
+	}{
+		{
+			name:     "uptrend",
+			segments: []klinegen.Segment{klinegen.Uptrend(100, 0.003)},
+			wantAny:  true,
+		},
+		{
+			name:     "downtrend after chop",
+			segments: []klinegen.Segment{klinegen.Chop(60, 0.005), klinegen.Downtrend(40, 0.003)},
+		},
+		{
+			name:     "chop",
+			segments: []klinegen.Segment{klinegen.Chop(100, 0.01)},
+		},
+		{
+			name:     "gap down in an uptrend",
+			segments: []klinegen.Segment{klinegen.Uptrend(99, 0.002), klinegen.Gap(-0.05)},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 5; seed++ {
+				series, err := klinegen.Generate(klinegen.Config{Seed: seed, Volatility: 0.003}, tt.segments...)
+				if err != nil {
+					t.Fatalf("Failed to generate klines: %v", err)
+				}
+				strategy, err := NewImprovedMACrossover(benchMACrossoverConfig(), logger.NewStdLogger(logger.LevelError))
+				if err != nil {
+					t.Fatalf("Failed to create strategy: %v", err)
+				}
+
+				// Walk the whole series, as the strategy tracks recent volatility between calls
+				span := series.Spans[len(series.Spans)-1]
+				entries := 0
+				for i := strategy.RequiredDataPoints(); i <= len(series.Klines); i++ {
+					if strategy.ShouldEnterTrade(ctx, series.Klines[:i], series.Klines[i-1].Close) && i > span.Start {
+						entries++
+					}
+				}
+				if got := entries > 0; got != tt.wantAny {
+					t.Errorf("seed %d: %d entries in the %s, want entries: %v", seed, entries, span.Regime, tt.wantAny)
+				}
+			}
+		})
+	}
+}
+
+func TestMACrossover_TimeLimitExit(t *testing.T) {
+	series, err := klinegen.Generate(klinegen.Config{Seed: 1, Volatility: -1}, klinegen.Uptrend(100, 0.001))
+	if err != nil {
+		t.Fatalf("Failed to generate klines: %v", err)
+	}
+	last := series.Klines[len(series.Klines)-1]
+
+	for _, disabled := range []bool{false, true} {
+		config := benchMACrossoverConfig()
+		config.DisableTimeLimit = disabled
+		strategy, err := NewImprovedMACrossover(config, logger.NewStdLogger(logger.LevelError))
+		if err != nil {
+			t.Fatalf("Failed to create strategy: %v", err)
+		}
+
+		// Held for 10 hours without making a profit
+		position := &domain.Position{
+			Symbol:     "ETHUSDT",
+			EntryPrice: last.Close,
+			Quantity:   1,
+			EntryTime:  last.OpenTime.Add(-10 * time.Hour),
+			Status:     domain.StatusOpen,
+		}
+		_, reason := strategy.ShouldClosePosition(context.Background(), position, series.Klines, last.Close)
+		if got := reason == domain.CloseReasonTimeLimit; got == disabled {
+			t.Errorf("DisableTimeLimit %v: got close reason %q", disabled, reason)
+		}
+	}
+}
+
+func TestMACrossover_OpenInterestConfirmation(t *testing.T) {
+	series, err := klinegen.Generate(klinegen.Config{Seed: 1, Volatility: 0.003}, klinegen.Uptrend(100, 0.003))
+	if err != nil {
+		t.Fatalf("Failed to generate klines: %v", err)
+	}
+
+	// One snapshot per bar close, open interest growing or shrinking by 1% a bar
+	snapshots := func(growth float64) []*domain.OpenInterest {
+		result := make([]*domain.OpenInterest, len(series.Klines))
+		contracts := 1000.0
+		for i, k := range series.Klines {
+			result[i] = &domain.OpenInterest{Symbol: "ETHUSDT", Time: k.CloseTime, Period: "1h", Contracts: contracts}
+			contracts *= 1 + growth
+		}
+		return result
+	}
+
+	tests := []struct {
+		name         string
+		openInterest []*domain.OpenInterest
+		wantAny      bool
+	}{
+		{name: "rising open interest", openInterest: snapshots(0.01), wantAny: true},
+		{name: "falling open interest", openInterest: snapshots(-0.01)},
+		{name: "no snapshots", wantAny: true},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := benchMACrossoverConfig()
+			config.UseOpenInterest = true
+			config.OpenInterestPeriod = "1h"
+			strategy, err := NewImprovedMACrossover(config, logger.NewStdLogger(logger.LevelError))
+			if err != nil {
+				t.Fatalf("Failed to create strategy: %v", err)
+			}
+			if got := strategy.OpenInterestPeriod(); got != "1h" {
+				t.Errorf("Expected open interest period 1h, got %q", got)
+			}
+
+			entries := 0
+			for i := strategy.RequiredDataPoints(); i <= len(series.Klines); i++ {
+				if tt.openInterest != nil {
+					strategy.SetOpenInterest(tt.openInterest[:i])
+				}
+				if strategy.ShouldEnterTrade(ctx, series.Klines[:i], series.Klines[i-1].Close) {
+					entries++
+					if tag := strategy.LastEntryTag(); tt.openInterest != nil && tag.ConfirmationCount == 0 {
+						t.Errorf("Expected the open interest to count as a confirmation, got %+v", tag)
+					}
+				}
+			}
+			if got := entries > 0; got != tt.wantAny {
+				t.Errorf("%d entries, want entries: %v", entries, tt.wantAny)
+			}
+		})
+	}
+}
+
+func TestMACrossover_VolumeProfileResistance(t *testing.T) {
+	series, err := klinegen.Generate(klinegen.Config{Seed: 1, Volatility: 0.003}, klinegen.Uptrend(100, 0.003))
+	if err != nil {
+		t.Fatalf("Failed to generate klines: %v", err)
+	}
+	config := benchMACrossoverConfig()
+	config.UseVolumeProfile = true
+	config.VolumeProfilePeriod = 30
+	newStrategy := func() *MACrossover {
+		strategy, err := NewImprovedMACrossover(config, logger.NewStdLogger(logger.LevelError))
+		if err != nil {
+			t.Fatalf("Failed to create strategy: %v", err)
+		}
+		return strategy
+	}
+
+	// First entry of the uptrend with the volume profile enabled
+	ctx := context.Background()
+	strategy := newStrategy()
+	entry := 0
+	for i := strategy.RequiredDataPoints(); i <= len(series.Klines); i++ {
+		if strategy.ShouldEnterTrade(ctx, series.Klines[:i], series.Klines[i-1].Close) {
+			entry = i
+			break
+		}
+	}
+	if entry == 0 {
+		t.Fatal("Expected an entry in the uptrend")
+	}
+
+	// The same bars, except that heavy volume traded on the signal bar makes its price range a
+	// high volume node right at the entry price
+	blocked := newStrategy()
+	for i := blocked.RequiredDataPoints(); i < entry; i++ {
+		blocked.ShouldEnterTrade(ctx, series.Klines[:i], series.Klines[i-1].Close)
+	}
+	klines := append([]*domain.Kline(nil), series.Klines[:entry]...)
+	heavy := *klines[entry-1]
+	heavy.Volume *= 50
+	klines[entry-1] = &heavy
+	if zone, ok := blocked.nearResistance(ctx, klines, heavy.Close); !ok || !zone.Contains(heavy.Close) {
+		t.Fatalf("Expected a high volume node at %f, got %+v (found %v)", heavy.Close, zone, ok)
+	}
+	if blocked.ShouldEnterTrade(ctx, klines, heavy.Close) {
+		t.Error("Expected the entry to be skipped at a high volume node")
+	}
+}
+
+func TestMACrossover_Clock(t *testing.T) {
+	now := time.Date(2025, 6, 11, 15, 30, 0, 0, time.UTC)
+	config := benchMACrossoverConfig()
+	config.Clock = clock.NewFake(now)
+	strategy, err := NewImprovedMACrossover(config, logger.NewStdLogger(logger.LevelError))
+	if err != nil {
+		t.Fatalf("Failed to create strategy: %v", err)
+	}
+	if day := time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC); !strategy.lastLossResetDay.Equal(day) {
+		t.Errorf("Expected the loss day to start at %v, got %v", day, strategy.lastLossResetDay)
+	}
+	if !strategy.lastTradeTime.Equal(now) {
+		t.Errorf("Expected the last trade time %v, got %v", now, strategy.lastTradeTime)
+	}
+}
+
+func TestMACrossover_IndicatorCache(t *testing.T) {
+	series, err := klinegen.Generate(klinegen.Config{Seed: 3, Volatility: 0.003}, klinegen.Chop(60, 0.005), klinegen.Uptrend(100, 0.003))
+	if err != nil {
+		t.Fatalf("Failed to generate klines: %v", err)
+	}
+	ctx := context.Background()
+	walk := func(cache *indicators.Cache) []bool {
+		config := benchMACrossoverConfig()
+		config.IndicatorCache = cache
+		strategy, err := NewImprovedMACrossover(config, logger.NewStdLogger(logger.LevelError))
+		if err != nil {
+			t.Fatalf("Failed to create strategy: %v", err)
+		}
+		var signals []bool
+		for i := strategy.RequiredDataPoints(); i <= len(series.Klines); i++ {
+			signals = append(signals, strategy.ShouldEnterTrade(ctx, series.Klines[:i], series.Klines[i-1].Close))
+		}
+		return signals
+	}
+
+	expected := walk(nil)
+	cache := indicators.NewCache(0)
+	for run := 0; run < 2; run++ {
+		if got := walk(cache); !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Run %d: cached signals differ from the uncached ones", run)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits < stats.Misses {
+		t.Errorf("Expected the second instance to be served from the cache, got %+v", stats)
+	}
+}
@@ -0,0 +1,375 @@
+package strategies
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExitPolicy decides when a MetaStrategy closes a position based on its children's exit signals
+type ExitPolicy string
+
+const (
+	// ExitAny closes as soon as any child signals an exit
+	ExitAny ExitPolicy = "ANY"
+	// ExitQuorum closes when the weight of children signaling an exit reaches the quorum
+	ExitQuorum ExitPolicy = "QUORUM"
+	// ExitAll closes only when every child signals an exit
+	ExitAll ExitPolicy = "ALL"
+)
+
+// MetaChild is a strategy voting inside a MetaStrategy
+type MetaChild struct {
+	Strategy ports.Strategy
+	Weight   float64 // Vote weight (0 defaults to 1)
+}
+
+// MetaChildSpec names a registered strategy voting inside a MetaStrategy built from configuration
+type MetaChildSpec struct {
+	Name   string
+	Weight float64 // Vote weight (0 defaults to 1)
+}
+
+// ParseMetaChildren parses comma-separated strategy names with optional weights, such as
+// "ma_crossover:2,improved_ma_crossover"
+func ParseMetaChildren(spec string) ([]MetaChildSpec, error) {
+	var children []MetaChildSpec
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, weightStr, hasWeight := strings.Cut(item, ":")
+		child := MetaChildSpec{Name: strings.TrimSpace(name)}
+		if child.Name == "" {
+			return nil, fmt.Errorf("missing strategy name in %q", item)
+		}
+		if hasWeight {
+			weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64)
+			if err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid weight in %q: must be a positive number", item)
+			}
+			child.Weight = weight
+		}
+		children = append(children, child)
+	}
+	if len(children) == 0 {
+		return nil, errors.New("at least one child strategy is required")
+	}
+	return children, nil
+}
+
+// MetaStrategyConfig holds configuration for the voting meta-strategy
+type MetaStrategyConfig struct {
+	Name       string      // Name reported by the strategy (default lists the children)
+	Children   []MetaChild // Strategies that vote on entries and exits
+	Quorum     float64     // Total weight of agreeing children needed to enter (e.g., 2 for 2 of 3 equally weighted children)
+	ExitPolicy ExitPolicy  // When to close an open position (default ExitAny)
+}
+
+// MetaStrategy wraps several strategies and enters only when a weighted quorum of them agree.
+// Every child is evaluated on every call so stateful children (loss counters, trailing stops)
+// stay up to date even when outvoted. Position sizing and ATR come from the first child that
+// provides them
+type MetaStrategy struct {
+	config       MetaStrategyConfig
+	sizer        Strategy // First child implementing Strategy, nil if none
+	lastEntryTag domain.EntryTag
+}
+
+// NewMetaStrategy creates a new voting meta-strategy instance
+func NewMetaStrategy(config MetaStrategyConfig) (*MetaStrategy, error) {
+	if len(config.Children) == 0 {
+		return nil, errors.New("meta strategy needs at least one child strategy")
+	}
+
+	config.Children = append([]MetaChild(nil), config.Children...) // Weights are defaulted in place
+	var totalWeight float64
+	for i := range config.Children {
+		child := &config.Children[i]
+		if child.Strategy == nil {
+			return nil, fmt.Errorf("meta strategy child %d is nil", i)
+		}
+		if child.Weight < 0 {
+			return nil, fmt.Errorf("meta strategy child %d has negative weight %f", i, child.Weight)
+		}
+		if child.Weight == 0 {
+			child.Weight = 1
+		}
+		totalWeight += child.Weight
+	}
+	if config.Quorum <= 0 || config.Quorum > totalWeight {
+		return nil, fmt.Errorf("meta strategy quorum %f must be positive and at most the total weight %f", config.Quorum, totalWeight)
+	}
+
+	switch config.ExitPolicy {
+	case "":
+		config.ExitPolicy = ExitAny
+	case ExitAny, ExitQuorum, ExitAll:
+	default:
+		return nil, fmt.Errorf("unknown meta strategy exit policy %q", config.ExitPolicy)
+	}
+
+	m := &MetaStrategy{config: config}
+	for _, child := range config.Children {
+		if sizer, ok := child.Strategy.(Strategy); ok {
+			m.sizer = sizer
+			break
+		}
+	}
+	if m.config.Name == "" {
+		names := make([]string, len(config.Children))
+		for i, child := range config.Children {
+			names[i] = childName(child.Strategy, i)
+		}
+		m.config.Name = fmt.Sprintf("Meta (%s)", strings.Join(names, ", "))
+	}
+	return m, nil
+}
+
+// NewMetaStrategyFromSpecs creates a voting meta-strategy from configured children, building each
+// with newChild. A zero config.Quorum requires every child to agree. The children already built are
+// closed if a later one or the meta strategy itself can't be created
+func NewMetaStrategyFromSpecs(config MetaStrategyConfig, specs []MetaChildSpec, newChild func(name string) (ports.Strategy, error)) (*MetaStrategy, error) {
+	config.Children = nil
+	var totalWeight float64
+	for _, spec := range specs {
+		child, err := newChild(spec.Name)
+		if err != nil {
+			closeMetaChildren(config.Children)
+			return nil, fmt.Errorf("meta strategy child %s: %w", spec.Name, err)
+		}
+		config.Children = append(config.Children, MetaChild{Strategy: child, Weight: spec.Weight})
+		if spec.Weight == 0 {
+			totalWeight++
+		} else {
+			totalWeight += spec.Weight
+		}
+	}
+	if config.Quorum == 0 {
+		config.Quorum = totalWeight // All children must agree
+	}
+	meta, err := NewMetaStrategy(config)
+	if err != nil {
+		closeMetaChildren(config.Children)
+		return nil, err
+	}
+	return meta, nil
+}
+
+// closeMetaChildren releases the children already built when a meta strategy can't be created
+func closeMetaChildren(children []MetaChild) {
+	for _, child := range children {
+		if closer, ok := child.Strategy.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+}
+
+// Name returns the name of the strategy
+func (m *MetaStrategy) Name() string {
+	return m.config.Name
+}
+
+// RequiredDataPoints returns the largest requirement among the children
+func (m *MetaStrategy) RequiredDataPoints() int {
+	required := 0
+	for _, child := range m.config.Children {
+		if n := child.Strategy.RequiredDataPoints(); n > required {
+			required = n
+		}
+	}
+	return required
+}
+
+// ShouldEnterTrade enters when the weight of children signaling a long entry reaches the quorum
+func (m *MetaStrategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	return m.vote(func(child ports.Strategy) bool {
+		return child.ShouldEnterTrade(ctx, klines, currentPrice)
+	})
+}
+
+// ShouldEnterShort enters when the weight of children signaling a short entry reaches the quorum.
+// Children that can't go short vote against (implements ports.ShortStrategy)
+func (m *MetaStrategy) ShouldEnterShort(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	return m.vote(func(child ports.Strategy) bool {
+		short, ok := child.(ports.ShortStrategy)
+		return ok && short.ShouldEnterShort(ctx, klines, currentPrice)
+	})
+}
+
+// vote evaluates every child and reports whether the agreeing weight reaches the quorum. On
+// success the entry tag is built from the agreeing children
+func (m *MetaStrategy) vote(signal func(child ports.Strategy) bool) bool {
+	var weight float64
+	var agreeing []int
+	for i, child := range m.config.Children {
+		if signal(child.Strategy) {
+			weight += child.Weight
+			agreeing = append(agreeing, i)
+		}
+	}
+	if weight < m.config.Quorum {
+		return false
+	}
+	m.lastEntryTag = m.entryTag(agreeing, weight)
+	return true
+}
+
+// entryTag combines the tags of the agreeing children. The signal source and ATR are taken from
+// the first agreeing child that tags its entries
+func (m *MetaStrategy) entryTag(agreeing []int, weight float64) domain.EntryTag {
+	tag := domain.EntryTag{ConfirmationCount: len(agreeing)}
+	names := make([]string, 0, len(agreeing))
+	for _, i := range agreeing {
+		child := m.config.Children[i].Strategy
+		names = append(names, childName(child, i))
+		tagger, ok := child.(ports.EntryTagger)
+		if !ok {
+			continue
+		}
+		childTag := tagger.LastEntryTag()
+		if tag.SignalSource == "" {
+			tag.SignalSource = childTag.SignalSource
+			tag.EntryATR = childTag.EntryATR
+		}
+	}
+	tag.EntryReason = fmt.Sprintf("%.2f of %.2f quorum: %s", weight, m.config.Quorum, strings.Join(names, ", "))
+	return tag
+}
+
+// LastEntryTag returns the tag of the most recent entry signal (implements ports.EntryTagger)
+func (m *MetaStrategy) LastEntryTag() domain.EntryTag {
+	return m.lastEntryTag
+}
+
+// ShouldClosePosition applies the exit policy to the children's exit signals. The close reason is
+// that of the first child signaling an exit
+func (m *MetaStrategy) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	var weight, totalWeight float64
+	var reason domain.CloseReason
+	for _, child := range m.config.Children {
+		totalWeight += child.Weight
+		shouldClose, childReason := child.Strategy.ShouldClosePosition(ctx, position, klines, currentPrice)
+		if !shouldClose {
+			continue
+		}
+		weight += child.Weight
+		if reason == "" {
+			reason = childReason
+		}
+	}
+	if weight == 0 {
+		return false, ""
+	}
+
+	switch m.config.ExitPolicy {
+	case ExitQuorum:
+		return weight >= m.config.Quorum, reason
+	case ExitAll:
+		return weight >= totalWeight, reason
+	default:
+		return true, reason
+	}
+}
+
+// Timeframes returns the union of the children's additional intervals (implements ports.MultiTimeframeStrategy)
+func (m *MetaStrategy) Timeframes() []string {
+	var timeframes []string
+	seen := make(map[string]bool)
+	for _, child := range m.config.Children {
+		mtf, ok := child.Strategy.(ports.MultiTimeframeStrategy)
+		if !ok {
+			continue
+		}
+		for _, tf := range mtf.Timeframes() {
+			if !seen[tf] {
+				seen[tf] = true
+				timeframes = append(timeframes, tf)
+			}
+		}
+	}
+	return timeframes
+}
+
+// SetTimeframeData forwards the klines to the children that analyze several intervals
+// (implements ports.MultiTimeframeStrategy)
+func (m *MetaStrategy) SetTimeframeData(klines map[string][]*domain.Kline) {
+	for _, child := range m.config.Children {
+		if mtf, ok := child.Strategy.(ports.MultiTimeframeStrategy); ok {
+			mtf.SetTimeframeData(klines)
+		}
+	}
+}
+
+// OpenInterestPeriod returns the first period a child needs open interest snapshots at, or "" if
+// none uses open interest (implements ports.OpenInterestStrategy)
+func (m *MetaStrategy) OpenInterestPeriod() string {
+	for _, child := range m.config.Children {
+		if ois, ok := child.Strategy.(ports.OpenInterestStrategy); ok && ois.OpenInterestPeriod() != "" {
+			return ois.OpenInterestPeriod()
+		}
+	}
+	return ""
+}
+
+// SetOpenInterest forwards the snapshots to the children that use open interest
+// (implements ports.OpenInterestStrategy)
+func (m *MetaStrategy) SetOpenInterest(snapshots []*domain.OpenInterest) {
+	for _, child := range m.config.Children {
+		if ois, ok := child.Strategy.(ports.OpenInterestStrategy); ok {
+			ois.SetOpenInterest(snapshots)
+		}
+	}
+}
+
+// PositionClosed forwards the closed position to the children whose entries depend on how the
+// previous position ended (implements ports.ExitAwareStrategy)
+func (m *MetaStrategy) PositionClosed(ctx context.Context, position *domain.Position) {
+	for _, child := range m.config.Children {
+		if aware, ok := child.Strategy.(ports.ExitAwareStrategy); ok {
+			aware.PositionClosed(ctx, position)
+		}
+	}
+}
+
+// Close releases the resources of the children that hold any, e.g. an external strategy process
+// (implements io.Closer)
+func (m *MetaStrategy) Close() error {
+	var errs []error
+	for _, child := range m.config.Children {
+		if closer, ok := child.Strategy.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetPositionSize delegates to the first child that sizes positions, or returns 0 if none does
+func (m *MetaStrategy) GetPositionSize(ctx context.Context, klines []*domain.Kline, availableFunds float64) float64 {
+	if m.sizer == nil {
+		return 0
+	}
+	return m.sizer.GetPositionSize(ctx, klines, availableFunds)
+}
+
+// GetATR delegates to the first child that provides ATR
+func (m *MetaStrategy) GetATR(ctx context.Context, klines []*domain.Kline) (float64, error) {
+	if m.sizer == nil {
+		return 0, errors.New("no child strategy provides ATR")
+	}
+	return m.sizer.GetATR(ctx, klines)
+}
+
+// childName returns the child's name, or its index if it doesn't have one
+func childName(child ports.Strategy, index int) string {
+	if named, ok := child.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return fmt.Sprintf("child %d", index+1)
+}
@@ -0,0 +1,270 @@
+package strategies
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// voter is a child strategy with fixed entry and exit signals
+type voter struct {
+	name      string
+	enter     bool
+	short     bool
+	close     bool
+	reason    domain.CloseReason
+	required  int
+	evaluated int
+}
+
+func (v *voter) Name() string            { return v.name }
+func (v *voter) RequiredDataPoints() int { return v.required }
+
+func (v *voter) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	v.evaluated++
+	return v.enter
+}
+
+func (v *voter) ShouldEnterShort(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	return v.short
+}
+
+func (v *voter) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	v.evaluated++
+	return v.close, v.reason
+}
+
+func (v *voter) LastEntryTag() domain.EntryTag {
+	return domain.EntryTag{SignalSource: domain.SignalSource(v.name)}
+}
+
+func TestMetaStrategy_Entry(t *testing.T) {
+	tests := []struct {
+		name    string
+		votes   []bool
+		weights []float64
+		quorum  float64
+		want    bool
+	}{
+		{name: "2 of 3 agree", votes: []bool{true, false, true}, quorum: 2, want: true},
+		{name: "1 of 3 agrees", votes: []bool{true, false, false}, quorum: 2, want: false},
+		{name: "heavy child alone reaches quorum", votes: []bool{true, false, false}, weights: []float64{2, 1, 1}, quorum: 2, want: true},
+		{name: "light children fall short", votes: []bool{false, true, true}, weights: []float64{3, 0.5, 0.5}, quorum: 2, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var children []MetaChild
+			var voters []*voter
+			for i, vote := range tt.votes {
+				v := &voter{name: string(rune('a' + i)), enter: vote, required: 10 * (i + 1)}
+				voters = append(voters, v)
+				child := MetaChild{Strategy: v}
+				if tt.weights != nil {
+					child.Weight = tt.weights[i]
+				}
+				children = append(children, child)
+			}
+			meta, err := NewMetaStrategy(MetaStrategyConfig{Children: children, Quorum: tt.quorum})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if got := meta.ShouldEnterTrade(context.Background(), nil, 100); got != tt.want {
+				t.Errorf("Expected entry %v, got %v", tt.want, got)
+			}
+			for _, v := range voters {
+				if v.evaluated != 1 {
+					t.Errorf("Expected child %s to be evaluated once, got %d", v.name, v.evaluated)
+				}
+			}
+			if meta.RequiredDataPoints() != 30 {
+				t.Errorf("Expected the largest child requirement 30, got %d", meta.RequiredDataPoints())
+			}
+		})
+	}
+}
+
+func TestMetaStrategy_EntryTagAndShort(t *testing.T) {
+	a := &voter{name: "a", enter: true, short: true}
+	b := &voter{name: "b", enter: false}
+	c := &voter{name: "c", enter: true, short: true}
+	meta, err := NewMetaStrategy(MetaStrategyConfig{Children: []MetaChild{{Strategy: a}, {Strategy: b}, {Strategy: c}}, Quorum: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !meta.ShouldEnterTrade(context.Background(), nil, 100) {
+		t.Fatal("Expected a long entry")
+	}
+	tag := meta.LastEntryTag()
+	if tag.ConfirmationCount != 2 || tag.SignalSource != "a" || !strings.Contains(tag.EntryReason, "a, c") {
+		t.Errorf("Expected a tag combining a and c, got %+v", tag)
+	}
+	if !meta.ShouldEnterShort(context.Background(), nil, 100) {
+		t.Error("Expected a short entry when 2 of 3 children signal short")
+	}
+	c.short = false
+	if meta.ShouldEnterShort(context.Background(), nil, 100) {
+		t.Error("Expected no short entry when only 1 child signals short")
+	}
+}
+
+func TestMetaStrategy_ExitPolicy(t *testing.T) {
+	tests := []struct {
+		policy ExitPolicy
+		closes []bool
+		want   bool
+	}{
+		{policy: "", closes: []bool{false, true, false}, want: true},
+		{policy: ExitQuorum, closes: []bool{false, true, false}, want: false},
+		{policy: ExitQuorum, closes: []bool{true, true, false}, want: true},
+		{policy: ExitAll, closes: []bool{true, true, false}, want: false},
+		{policy: ExitAll, closes: []bool{true, true, true}, want: true},
+	}
+
+	for _, tt := range tests {
+		var children []MetaChild
+		var wantReason domain.CloseReason
+		for i, close := range tt.closes {
+			reason := domain.CloseReasonMarket
+			if i == 1 {
+				reason = domain.CloseReasonStopLoss
+			}
+			if close && wantReason == "" {
+				wantReason = reason
+			}
+			children = append(children, MetaChild{Strategy: &voter{name: "v", close: close, reason: reason}})
+		}
+		meta, err := NewMetaStrategy(MetaStrategyConfig{Children: children, Quorum: 2, ExitPolicy: tt.policy})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		got, reason := meta.ShouldClosePosition(context.Background(), &domain.Position{}, nil, 100)
+		if got != tt.want {
+			t.Errorf("%q with exits %v: expected close %v, got %v", tt.policy, tt.closes, tt.want, got)
+		}
+		if got && reason != wantReason {
+			t.Errorf("%q with exits %v: expected the first closing child's reason, got %s", tt.policy, tt.closes, reason)
+		}
+	}
+}
+
+func TestNewMetaStrategy_Errors(t *testing.T) {
+	child := MetaChild{Strategy: &voter{}}
+	configs := map[string]MetaStrategyConfig{
+		"no children":         {Quorum: 1},
+		"nil child":           {Children: []MetaChild{{}}, Quorum: 1},
+		"negative weight":     {Children: []MetaChild{{Strategy: &voter{}, Weight: -1}}, Quorum: 1},
+		"unreachable quorum":  {Children: []MetaChild{child, child}, Quorum: 3},
+		"zero quorum":         {Children: []MetaChild{child}},
+		"unknown exit policy": {Children: []MetaChild{child}, Quorum: 1, ExitPolicy: "MAJORITY"},
+	}
+	for name, config := range configs {
+		if _, err := NewMetaStrategy(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// exitAwareVoter is a voter that records the positions closed
+type exitAwareVoter struct {
+	voter
+	closed []*domain.Position
+	closes int
+}
+
+func (v *exitAwareVoter) PositionClosed(ctx context.Context, position *domain.Position) {
+	v.closed = append(v.closed, position)
+}
+
+func (v *exitAwareVoter) Close() error {
+	v.closes++
+	return nil
+}
+
+func TestMetaStrategy_ForwardsToChildren(t *testing.T) {
+	aware := &exitAwareVoter{}
+	meta, err := NewMetaStrategy(MetaStrategyConfig{Children: []MetaChild{{Strategy: &voter{}}, {Strategy: aware}}, Quorum: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	position := &domain.Position{ID: 1}
+	meta.PositionClosed(context.Background(), position)
+	if len(aware.closed) != 1 || aware.closed[0] != position {
+		t.Errorf("Expected the closed position to be forwarded, got %v", aware.closed)
+	}
+	if err := meta.Close(); err != nil || aware.closes != 1 {
+		t.Errorf("Expected the child to be closed once, got %d closes, error %v", aware.closes, err)
+	}
+}
+
+func TestNewMetaStrategyFromSpecs(t *testing.T) {
+	var built []*exitAwareVoter
+	newChild := func(name string) (ports.Strategy, error) {
+		if name == "broken" {
+			return nil, errors.New("unknown strategy")
+		}
+		child := &exitAwareVoter{voter: voter{name: name, enter: name != "bear"}}
+		built = append(built, child)
+		return child, nil
+	}
+
+	// A zero quorum requires the full weight of 3 to agree
+	meta, err := NewMetaStrategyFromSpecs(MetaStrategyConfig{}, []MetaChildSpec{{Name: "bull", Weight: 2}, {Name: "bear"}}, newChild)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if meta.ShouldEnterTrade(context.Background(), nil, 100) {
+		t.Error("Expected no entry without every child agreeing")
+	}
+	meta, err = NewMetaStrategyFromSpecs(MetaStrategyConfig{Quorum: 2}, []MetaChildSpec{{Name: "bull", Weight: 2}, {Name: "bear"}}, newChild)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !meta.ShouldEnterTrade(context.Background(), nil, 100) {
+		t.Error("Expected an entry once the configured quorum agrees")
+	}
+
+	built = nil
+	_, err = NewMetaStrategyFromSpecs(MetaStrategyConfig{}, []MetaChildSpec{{Name: "bull"}, {Name: "broken"}}, newChild)
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected the failing child to be named, got %v", err)
+	}
+	if len(built) != 1 || built[0].closes != 1 {
+		t.Errorf("Expected the child already built to be closed, got %v", built)
+	}
+
+	built = nil
+	if _, err := NewMetaStrategyFromSpecs(MetaStrategyConfig{Quorum: 5}, []MetaChildSpec{{Name: "bull"}}, newChild); err == nil {
+		t.Error("Expected an error for an unreachable quorum")
+	}
+	if len(built) != 1 || built[0].closes != 1 {
+		t.Errorf("Expected the children to be closed, got %v", built)
+	}
+}
+
+func TestParseMetaChildren(t *testing.T) {
+	children, err := ParseMetaChildren(" ma_crossover:2, improved_ma_crossover ,")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []MetaChildSpec{{Name: "ma_crossover", Weight: 2}, {Name: "improved_ma_crossover"}}
+	if len(children) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, children)
+	}
+	for i := range expected {
+		if children[i] != expected[i] {
+			t.Errorf("Child %d: expected %v, got %v", i, expected[i], children[i])
+		}
+	}
+	for _, spec := range []string{"", " , ", ":2", "ma_crossover:0", "ma_crossover:-1", "ma_crossover:abc"} {
+		if _, err := ParseMetaChildren(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
@@ -27,6 +27,30 @@ type Strategy interface {
 	GetATR(ctx context.Context, klines []*domain.Kline) (float64, error)
 }
 
+// EntryOrderType defines how an entry signal should be executed
+type EntryOrderType string
+
+const (
+	// EntryOrderMarket enters immediately at the current price
+	EntryOrderMarket EntryOrderType = "MARKET"
+	// EntryOrderLimit rests a limit order that only fills if price trades through it
+	EntryOrderLimit EntryOrderType = "LIMIT"
+)
+
+// EntryOrder describes the order a strategy wants to use for an entry
+type EntryOrder struct {
+	Type       EntryOrderType
+	LimitPrice float64 // Limit price (only for EntryOrderLimit)
+	ExpiryBars int     // Number of bars the limit order stays active; 0 uses the backtest or live default
+}
+
+// EntryOrderProvider is implemented by strategies that want to control how entries are executed.
+// Strategies that don't implement it always enter with market orders.
+type EntryOrderProvider interface {
+	// GetEntryOrder returns the entry order to use once ShouldEnterTrade has signaled an entry
+	GetEntryOrder(ctx context.Context, klines []*domain.Kline, currentPrice float64) EntryOrder
+}
+
 // BaseStrategy provides common functionality for strategies
 type BaseStrategy struct {
 	logger ports.Logger
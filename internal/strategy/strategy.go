@@ -18,12 +18,16 @@ type Config struct {
 	RSIPeriod         int     // e.g., 14
 	RSIOverbought     float64 // e.g., 70.0
 	RSIOversold       float64 // e.g., 30.0 (Not used in current logic, but good to have)
+
+	BreakEvenActivation float64         // Profit percentage at which the stop moves to breakeven (0 disables)
+	Fees                domain.FeeModel // Fees and funding used to compute the true breakeven price
 }
 
 // Strategy implements the trading logic.
 type Strategy struct {
-	cfg    Config
-	logger ports.Logger
+	cfg          Config
+	logger       ports.Logger
+	lastEntryTag domain.EntryTag // Tag of the most recent entry signal
 }
 
 // New creates a new Strategy instance.
@@ -38,6 +42,9 @@ func New(cfg Config, logger ports.Logger) (*Strategy, error) {
 	if cfg.ShortTermMAPeriod >= cfg.LongTermMAPeriod {
 		return nil, fmt.Errorf("short term MA period must be less than long term MA period")
 	}
+	if cfg.BreakEvenActivation < 0 {
+		return nil, fmt.Errorf("breakeven activation cannot be negative")
+	}
 	return &Strategy{cfg: cfg, logger: logger}, nil
 }
 
@@ -203,6 +210,11 @@ func (s *Strategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline,
 			"rsi":          rsi,
 			"rsiLimit":     s.cfg.RSIOverbought,
 		})
+		s.lastEntryTag = domain.EntryTag{
+			EntryReason:       "price above MAs and EMA with RSI below overbought",
+			SignalSource:      domain.SignalSourceTrend,
+			ConfirmationCount: 3, // Trend, RSI and EMA conditions
+		}
 		return true
 	}
 
@@ -219,6 +231,11 @@ func (s *Strategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline,
 	return false
 }
 
+// LastEntryTag returns the tag of the most recent entry signal (implements ports.EntryTagger).
+func (s *Strategy) LastEntryTag() domain.EntryTag {
+	return s.lastEntryTag
+}
+
 // ShouldClosePosition implements the logic to decide if an open position should be closed.
 // This is separate from SL/TP which might be handled by exchange order types.
 // This could implement trailing stops or other exit conditions based on indicators.
@@ -228,9 +245,23 @@ func (s *Strategy) ShouldClosePosition(ctx context.Context, position *domain.Pos
 	// Example: Implement a trailing stop loss.
 
 	// Check basic SL/TP (although exchange orders might handle this)
-	if position.IsOpen() {
+	if position.IsOpen() && position.IsShort() {
+		// A short's stop sits above entry and its target below
+		if currentPrice >= position.StopLoss {
+			s.logger.Info(ctx, "Stop loss condition met", map[string]interface{}{"positionID": position.ID, "side": position.Side, "currentPrice": currentPrice, "stopLoss": position.StopLoss})
+			return true, domain.CloseReasonStopLoss
+		}
+		if currentPrice <= position.TakeProfit {
+			s.logger.Info(ctx, "Take profit condition met", map[string]interface{}{"positionID": position.ID, "side": position.Side, "currentPrice": currentPrice, "takeProfit": position.TakeProfit})
+			return true, domain.CloseReasonTakeProfit
+		}
+	} else if position.IsOpen() {
+		s.moveStopToBreakEven(ctx, position, klines, currentPrice)
 		if currentPrice <= position.StopLoss {
 			s.logger.Info(ctx, "Stop loss condition met", map[string]interface{}{"positionID": position.ID, "currentPrice": currentPrice, "stopLoss": position.StopLoss})
+			if position.StopLoss >= position.EntryPrice {
+				return true, domain.CloseReasonBreakEven // Stop had been moved to breakeven
+			}
 			return true, domain.CloseReasonStopLoss
 		}
 		if currentPrice >= position.TakeProfit {
@@ -242,3 +273,29 @@ func (s *Strategy) ShouldClosePosition(ctx context.Context, position *domain.Pos
 	// No other conditions met
 	return false, ""
 }
+
+// moveStopToBreakEven raises the stop to the fee-adjusted breakeven price once the position's
+// profit reaches BreakEvenActivation, so a winner can no longer turn into a loss after fees and funding.
+// Only long positions are handled, matching domain.FeeModel.BreakEvenPrice
+func (s *Strategy) moveStopToBreakEven(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) {
+	if s.cfg.BreakEvenActivation <= 0 || len(klines) == 0 || position.EntryPrice <= 0 {
+		return
+	}
+	if currentPrice < position.EntryPrice*(1+s.cfg.BreakEvenActivation) {
+		return
+	}
+
+	held := klines[len(klines)-1].CloseTime.Sub(position.EntryTime)
+	breakEven := s.cfg.Fees.BreakEvenPrice(position.EntryPrice, held)
+	if position.StopLoss >= breakEven || currentPrice <= breakEven {
+		return
+	}
+
+	position.StopLoss = breakEven
+	s.logger.Info(ctx, "Moving stop loss to fee-adjusted breakeven", map[string]interface{}{
+		"positionID":   position.ID,
+		"currentPrice": currentPrice,
+		"entryPrice":   position.EntryPrice,
+		"newStopLoss":  breakEven,
+	})
+}
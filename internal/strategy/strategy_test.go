@@ -3,9 +3,11 @@ package strategy
 import (
 	"context"
 	"testing"
+	"time"
 
 	"cryptoMegaBot/internal/domain"
 	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/strategy/klinegen"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -381,6 +383,69 @@ func TestShouldEnterTrade(t *testing.T) {
 	}
 }
 
+func TestShouldEnterTrade_Regimes(t *testing.T) {
+	cfg := Config{
+		ShortTermMAPeriod: 10,
+		LongTermMAPeriod:  30,
+		EMAPeriod:         10,
+		RSIPeriod:         14,
+		RSIOverbought:     70.0,
+		RSIOversold:       30.0,
+	}
+
+	tests := []struct {
+		name     string
+		segments []klinegen.Segment
+		wantAny  bool // Whether any bar of the last segment signals an entry
+		noLast   bool // Whether the last bar must not signal one
+	}{
+		{
+			name:     "uptrend after chop",
+			segments: []klinegen.Segment{klinegen.Chop(60, 0.005), klinegen.Uptrend(40, 0.002)},
+			wantAny:  true,
+		},
+		{
+			name:     "steep uptrend ends overbought",
+			segments: []klinegen.Segment{klinegen.Chop(60, 0.005), klinegen.Uptrend(40, 0.01)},
+			wantAny:  true,
+			noLast:   true,
+		},
+		{
+			name:     "downtrend",
+			segments: []klinegen.Segment{klinegen.Chop(60, 0.005), klinegen.Downtrend(40, 0.003)},
+			noLast:   true,
+		},
+		{
+			name:     "gap down in an uptrend",
+			segments: []klinegen.Segment{klinegen.Uptrend(99, 0.002), klinegen.Gap(-0.05)},
+			noLast:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 5; seed++ {
+				series, err := klinegen.Generate(klinegen.Config{Seed: seed, Volatility: 0.003}, tt.segments...)
+				require.NoError(t, err)
+				s, err := New(cfg, &mockLogger{})
+				require.NoError(t, err)
+
+				span := series.Spans[len(series.Spans)-1]
+				entries := 0
+				for i := span.Start + 1; i <= span.End; i++ {
+					if s.ShouldEnterTrade(context.Background(), series.Klines[:i], series.Klines[i-1].Close) {
+						entries++
+					}
+				}
+				assert.Equal(t, tt.wantAny, entries > 0, "seed %d: %d entries", seed, entries)
+				if tt.noLast {
+					assert.False(t, s.ShouldEnterTrade(context.Background(), series.Klines, series.LastClose()), "seed %d: last bar", seed)
+				}
+			}
+		})
+	}
+}
+
 func TestShouldClosePosition(t *testing.T) {
 	cfg := Config{
 		ShortTermMAPeriod: 3,
@@ -444,6 +509,54 @@ func TestShouldClosePosition(t *testing.T) {
 			wantClose:    false,
 			wantReason:   "",
 		},
+		{
+			name: "short stop loss hit",
+			position: &domain.Position{
+				ID:         2,
+				Symbol:     "ETHUSDT",
+				Side:       domain.PositionSideShort,
+				EntryPrice: 2000.0,
+				StopLoss:   2100.0,
+				TakeProfit: 1800.0,
+				Status:     domain.StatusOpen,
+			},
+			klines:       []*domain.Kline{{Close: 2150}},
+			currentPrice: 2150.0,
+			wantClose:    true,
+			wantReason:   domain.CloseReasonStopLoss,
+		},
+		{
+			name: "short take profit hit",
+			position: &domain.Position{
+				ID:         2,
+				Symbol:     "ETHUSDT",
+				Side:       domain.PositionSideShort,
+				EntryPrice: 2000.0,
+				StopLoss:   2100.0,
+				TakeProfit: 1800.0,
+				Status:     domain.StatusOpen,
+			},
+			klines:       []*domain.Kline{{Close: 1750}},
+			currentPrice: 1750.0,
+			wantClose:    true,
+			wantReason:   domain.CloseReasonTakeProfit,
+		},
+		{
+			name: "short below entry but above target",
+			position: &domain.Position{
+				ID:         2,
+				Symbol:     "ETHUSDT",
+				Side:       domain.PositionSideShort,
+				EntryPrice: 2000.0,
+				StopLoss:   2100.0,
+				TakeProfit: 1800.0,
+				Status:     domain.StatusOpen,
+			},
+			klines:       []*domain.Kline{{Close: 1900}},
+			currentPrice: 1900.0,
+			wantClose:    false,
+			wantReason:   "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -458,3 +571,39 @@ func TestShouldClosePosition(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldClosePosition_BreakEven(t *testing.T) {
+	cfg := Config{
+		ShortTermMAPeriod:   3,
+		LongTermMAPeriod:    5,
+		EMAPeriod:           3,
+		RSIPeriod:           3,
+		BreakEvenActivation: 0.005,
+		Fees:                domain.FeeModel{TakerRate: 0.0004, FundingRate: 0.0001},
+	}
+	s, err := New(cfg, &mockLogger{})
+	require.NoError(t, err)
+
+	entryTime := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	position := &domain.Position{ID: 1, EntryPrice: 2000, StopLoss: 1900, TakeProfit: 2200, EntryTime: entryTime, Status: domain.StatusOpen}
+	kline := func(closeTime time.Time, price float64) []*domain.Kline {
+		return []*domain.Kline{{CloseTime: closeTime, Close: price}}
+	}
+
+	// Below the activation threshold the stop stays put
+	gotClose, _ := s.ShouldClosePosition(context.Background(), position, kline(entryTime.Add(time.Hour), 2005), 2005)
+	assert.False(t, gotClose)
+	assert.Equal(t, 1900.0, position.StopLoss)
+
+	// Past the threshold the stop covers round-trip fees and one funding payment, not just the entry price
+	gotClose, _ = s.ShouldClosePosition(context.Background(), position, kline(entryTime.Add(9*time.Hour), 2012), 2012)
+	assert.False(t, gotClose)
+	want := cfg.Fees.BreakEvenPrice(2000, 9*time.Hour)
+	assert.InDelta(t, want, position.StopLoss, 1e-9)
+	assert.Greater(t, position.StopLoss, 2000*1.0001)
+
+	// Falling back to it closes as a breakeven exit
+	gotClose, gotReason := s.ShouldClosePosition(context.Background(), position, kline(entryTime.Add(10*time.Hour), 2001), 2001)
+	assert.True(t, gotClose)
+	assert.Equal(t, domain.CloseReasonBreakEven, gotReason)
+}
@@ -0,0 +1,127 @@
+package visualization
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/backtesting"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+)
+
+//go:embed chart.html
+var chartPage string
+
+// chartTemplate renders the chart page with the chart data inlined, so it opens straight from disk
+var chartTemplate = template.Must(template.New("chart").Parse(chartPage))
+
+// Chart holds the data of a backtest chart: the candles and a marker set for each trade. Times are
+// Unix seconds
+type Chart struct {
+	Symbol  string   `json:"symbol"`
+	Candles []Candle `json:"candles"`
+	Trades  []Trade  `json:"trades"`
+}
+
+// Candle is one kline of the chart
+type Candle struct {
+	Time  int64   `json:"time"`
+	Open  float64 `json:"open"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+	Close float64 `json:"close"`
+}
+
+// Trade holds the entry and exit markers of a trade and the path of its protective levels
+type Trade struct {
+	Side        string      `json:"side"`
+	EntryTime   int64       `json:"entryTime"`
+	EntryPrice  float64     `json:"entryPrice"`
+	ExitTime    int64       `json:"exitTime"`
+	ExitPrice   float64     `json:"exitPrice"`
+	Quantity    float64     `json:"quantity"`
+	PNL         float64     `json:"pnl"`
+	CloseReason string      `json:"closeReason"`
+	EntryReason string      `json:"entryReason,omitempty"`
+	Levels      []StopLevel `json:"levels"`
+}
+
+// StopLevel is a trade's stop-loss, take-profit and trailing stop (0 while inactive) at a bar
+type StopLevel struct {
+	Time         int64   `json:"time"`
+	StopLoss     float64 `json:"stopLoss"`
+	TakeProfit   float64 `json:"takeProfit"`
+	TrailingStop float64 `json:"trailingStop,omitempty"`
+}
+
+// NewChart builds the chart of a backtest run over klines. The stop levels come from
+// result.StopPaths (see BacktestConfig.RecordStopPaths); without them only the entry and exit
+// markers are drawn
+func NewChart(symbol string, klines []*domain.Kline, result *backtesting.BacktestResult) *Chart {
+	chart := &Chart{
+		Symbol:  symbol,
+		Candles: make([]Candle, len(klines)),
+		Trades:  make([]Trade, len(result.Trades)),
+	}
+	for i, kline := range klines {
+		chart.Candles[i] = Candle{Time: kline.OpenTime.Unix(), Open: kline.Open, High: kline.High, Low: kline.Low, Close: kline.Close}
+	}
+	for i, trade := range result.Trades {
+		side := trade.Side
+		if side == "" {
+			side = domain.PositionSideLong
+		}
+		chart.Trades[i] = Trade{
+			Side:        string(side),
+			EntryTime:   trade.EntryTime.Unix(),
+			EntryPrice:  trade.EntryPrice,
+			ExitTime:    trade.ExitTime.Unix(),
+			ExitPrice:   trade.ExitPrice,
+			Quantity:    trade.Quantity,
+			PNL:         trade.PNL,
+			CloseReason: string(trade.CloseReason),
+			EntryReason: trade.EntryReason,
+		}
+		if i < len(result.StopPaths) {
+			levels := make([]StopLevel, len(result.StopPaths[i]))
+			for j, level := range result.StopPaths[i] {
+				levels[j] = StopLevel{Time: level.Time.Unix(), StopLoss: level.StopLoss, TakeProfit: level.TakeProfit, TrailingStop: level.TrailingStop}
+			}
+			chart.Trades[i].Levels = levels
+		}
+	}
+	return chart
+}
+
+// WriteJSON writes the chart data as JSON
+func (c *Chart) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(c)
+}
+
+// WriteHTML writes a self-contained chart page with the data inlined
+func (c *Chart) WriteHTML(w io.Writer) error {
+	return chartTemplate.Execute(w, c)
+}
+
+// Export writes the chart to basePath.json and basePath.html
+func (c *Chart) Export(basePath string) error {
+	if err := writeFile(basePath+".json", c.WriteJSON); err != nil {
+		return err
+	}
+	return writeFile(basePath+".html", c.WriteHTML)
+}
+
+// writeFile creates filename and fills it with write
+func writeFile(filename string, write func(io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filename, err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return file.Close()
+}
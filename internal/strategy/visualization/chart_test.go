@@ -0,0 +1,127 @@
+package visualization
+
+import (
+	"bytes"
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/backtesting"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// trailingStrategy enters on every bar, raises the stop to the previous close and exits once the
+// price reaches 104
+type trailingStrategy struct{}
+
+func (s *trailingStrategy) RequiredDataPoints() int { return 2 }
+func (s *trailingStrategy) Name() string            { return "trailing" }
+func (s *trailingStrategy) ShouldEnterTrade(ctx context.Context, klines []*domain.Kline, currentPrice float64) bool {
+	return true
+}
+func (s *trailingStrategy) ShouldClosePosition(ctx context.Context, position *domain.Position, klines []*domain.Kline, currentPrice float64) (bool, domain.CloseReason) {
+	position.TrailingStopPrice = klines[len(klines)-2].Close
+	return currentPrice >= 104, domain.CloseReasonTakeProfit
+}
+func (s *trailingStrategy) GetPositionSize(ctx context.Context, klines []*domain.Kline, availableFunds float64) float64 {
+	return 1
+}
+func (s *trailingStrategy) GetATR(ctx context.Context, klines []*domain.Kline) (float64, error) {
+	return 1, nil
+}
+
+func runBacktest(t *testing.T) ([]*domain.Kline, *backtesting.BacktestResult) {
+	t.Helper()
+	start := time.Date(2025, 6, 11, 10, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 5)
+	for i := range klines {
+		price := 100 + float64(i)
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Hour), Open: price, High: price + 0.5, Low: price - 0.5, Close: price}
+	}
+	config := backtesting.BacktestConfig{
+		InitialFunds: 1000, PositionSize: 1, StopLoss: 0.1, TakeProfit: 0.1, Symbol: "ETHUSDT", Leverage: 1,
+		RecordStopPaths: true,
+		Timing:          backtesting.ExecutionSignalClose,
+	}
+	result, err := backtesting.Backtest(context.Background(), &trailingStrategy{}, klines, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return klines, result
+}
+
+func TestNewChart(t *testing.T) {
+	klines, result := runBacktest(t)
+	chart := NewChart("ETHUSDT", klines, result)
+
+	if len(chart.Candles) != len(klines) || chart.Candles[2].Close != 102 || chart.Candles[2].Time != klines[2].OpenTime.Unix() {
+		t.Fatalf("Unexpected candles: %+v", chart.Candles)
+	}
+	// Entered at the close of bar 2 and closed at bar 4
+	if len(chart.Trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(chart.Trades))
+	}
+	trade := chart.Trades[0]
+	if trade.Side != "LONG" || trade.EntryPrice != 102 || trade.ExitPrice != 104 || trade.CloseReason != string(domain.CloseReasonTakeProfit) {
+		t.Errorf("Unexpected trade: %+v", trade)
+	}
+	expected := []StopLevel{
+		{Time: klines[2].OpenTime.Unix(), StopLoss: 102 * 0.9, TakeProfit: 102 * 1.1},
+		{Time: klines[3].OpenTime.Unix(), StopLoss: 102 * 0.9, TakeProfit: 102 * 1.1, TrailingStop: 102},
+		{Time: klines[4].OpenTime.Unix(), StopLoss: 102 * 0.9, TakeProfit: 102 * 1.1, TrailingStop: 103},
+	}
+	if len(trade.Levels) != len(expected) {
+		t.Fatalf("Expected %d stop levels, got %+v", len(expected), trade.Levels)
+	}
+	for i, level := range trade.Levels {
+		if level != expected[i] {
+			t.Errorf("Level %d: expected %+v, got %+v", i, expected[i], level)
+		}
+	}
+
+	// Without recorded paths the trades have markers only
+	result.StopPaths = nil
+	if chart := NewChart("ETHUSDT", klines, result); chart.Trades[0].Levels != nil {
+		t.Errorf("Expected no levels, got %+v", chart.Trades[0].Levels)
+	}
+}
+
+func TestChartExport(t *testing.T) {
+	klines, result := runBacktest(t)
+	chart := NewChart("ETH<USDT>", klines, result)
+	base := filepath.Join(t.TempDir(), "chart")
+	if err := chart.Export(base); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	data, err := os.ReadFile(base + ".json")
+	if err != nil {
+		t.Fatalf("Failed to read JSON: %v", err)
+	}
+	var decoded Chart
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if decoded.Symbol != "ETH<USDT>" || len(decoded.Candles) != len(chart.Candles) || len(decoded.Trades) != 1 || len(decoded.Trades[0].Levels) != 3 {
+		t.Errorf("JSON doesn't round-trip: %+v", decoded)
+	}
+
+	page, err := os.ReadFile(base + ".html")
+	if err != nil {
+		t.Fatalf("Failed to read HTML: %v", err)
+	}
+	var want bytes.Buffer
+	if err := chart.WriteHTML(&want); err != nil {
+		t.Fatalf("WriteHTML failed: %v", err)
+	}
+	if !bytes.Equal(page, want.Bytes()) {
+		t.Error("Exported HTML differs from WriteHTML")
+	}
+	// The data is inlined as an escaped JS object
+	if !strings.Contains(string(page), `"entryPrice":102`) || strings.Contains(string(page), "ETH<USDT>") {
+		t.Error("Expected the chart data inlined and escaped in the page")
+	}
+}
@@ -0,0 +1,768 @@
+// Package testharness provides a deterministic fake exchange that replays recorded klines at
+// accelerated speed with injected faults, for soak-testing the trading service.
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// Faults configures the errors injected by the fake exchange. Rates are probabilities in [0, 1].
+type Faults struct {
+	DisconnectRate   float64 // Chance per streamed kline that the stream disconnects
+	DisconnectKlines int     // Klines lost per disconnect; they are still available from GetKlines (default 3)
+	RejectRate       float64 // Chance an order is rejected
+	PartialFillRate  float64 // Chance a market order fills only partially
+	PartialFillRatio float64 // Share of the quantity a partial fill executes (default 0.5)
+}
+
+// Config holds configuration for the fake exchange.
+type Config struct {
+	Symbol  string
+	Klines  []*domain.Kline // Recorded klines of one interval, oldest first (at least 2)
+	History int             // Klines available from GetKlines before the replay starts (default 500)
+	Loops   int             // Passes over the recording; negative repeats until stopped (0 means 1)
+	Speed   time.Duration   // Wall time between replayed klines (0 replays as fast as they are handled)
+	Seed    int64           // Seed of the injected faults; the same seed injects the same faults
+	Balance float64         // Starting USDT balance (default 10000)
+	Faults  Faults
+
+	// AfterKline is called after the stream handler returned for a replayed kline, while no
+	// other kline is in flight, e.g. to check invariants (optional).
+	AfterKline func(kline *domain.Kline)
+}
+
+// Stats counts what happened during a replay.
+type Stats struct {
+	KlinesReplayed  int // Klines the exchange closed, including those lost to disconnects
+	KlinesDelivered int // Klines handed to the stream handler
+	Disconnects     int
+	OrdersPlaced    int
+	OrdersRejected  int
+	PartialFills    int
+	StopsTriggered  int // Stop and take-profit orders the exchange filled
+	OrdersExpired   int // Close-position orders that triggered without a position to close
+}
+
+// Position is the exchange's view of the position on one position side.
+type Position struct {
+	Amount     float64 // Positive for long, negative for short
+	EntryPrice float64
+}
+
+// FakeExchange implements ports.ExchangeClient on top of a recorded kline stream. Market orders
+// fill at the close of the last replayed kline; stop and take-profit orders fill when a later
+// kline's range reaches their price, and so do resting limit orders. Like Binance, it rejects
+// reduce-only orders that would open or grow a position and post-only orders that would cross
+// the book.
+type FakeExchange struct {
+	cfg      Config
+	interval string
+	step     time.Duration
+	total    int // Klines in the whole replay including the history; negative if unbounded
+
+	mu          sync.Mutex
+	rng         *rand.Rand
+	cursor      int // Klines [0, cursor) have closed
+	streaming   bool
+	nextOrderID int64
+	balance     float64
+	leverage    int
+	hedgeMode   bool
+	marginType  domain.MarginType
+	positions   map[domain.PositionSide]*Position
+	openOrders  map[int64]*ports.OrderResponse // Untriggered stop and take-profit orders and resting limit orders
+	orderSides  map[int64]domain.PositionSide  // Position side each open order closes
+	byClientID  map[string]*ports.OrderResponse
+	stats       Stats
+	done        chan struct{} // Closed when the replay ran out of klines
+}
+
+var (
+	_ ports.ExchangeClient        = (*FakeExchange)(nil)
+	_ ports.LimitOrderPlacer      = (*FakeExchange)(nil)
+	_ ports.PostOnlyOrderPlacer   = (*FakeExchange)(nil)
+	_ ports.ReduceOnlyOrderPlacer = (*FakeExchange)(nil)
+)
+
+// New creates a fake exchange replaying cfg.Klines.
+func New(cfg Config) (*FakeExchange, error) {
+	if cfg.Symbol == "" {
+		return nil, fmt.Errorf("symbol is required")
+	}
+	if len(cfg.Klines) < 2 {
+		return nil, fmt.Errorf("at least 2 klines are required, got %d", len(cfg.Klines))
+	}
+	step := cfg.Klines[1].OpenTime.Sub(cfg.Klines[0].OpenTime)
+	if step <= 0 {
+		return nil, fmt.Errorf("klines must be in ascending order")
+	}
+	if cfg.History <= 0 {
+		cfg.History = 500
+	}
+	if cfg.History >= len(cfg.Klines) {
+		return nil, fmt.Errorf("history (%d) must be shorter than the recording (%d klines)", cfg.History, len(cfg.Klines))
+	}
+	if cfg.Loops == 0 {
+		cfg.Loops = 1
+	}
+	if cfg.Balance <= 0 {
+		cfg.Balance = 10000
+	}
+	if cfg.Faults.DisconnectKlines <= 0 {
+		cfg.Faults.DisconnectKlines = 3
+	}
+	if cfg.Faults.PartialFillRatio <= 0 || cfg.Faults.PartialFillRatio >= 1 {
+		cfg.Faults.PartialFillRatio = 0.5
+	}
+	interval := cfg.Klines[0].Interval
+	if interval == "" {
+		interval = intervalName(step)
+	}
+	total := -1
+	if cfg.Loops > 0 {
+		total = len(cfg.Klines) * cfg.Loops
+	}
+	return &FakeExchange{
+		cfg:         cfg,
+		interval:    interval,
+		step:        step,
+		total:       total,
+		rng:         rand.New(rand.NewSource(cfg.Seed)),
+		cursor:      cfg.History,
+		nextOrderID: 1,
+		balance:     cfg.Balance,
+		leverage:    1,
+		marginType:  domain.MarginTypeCrossed,
+		positions:   make(map[domain.PositionSide]*Position),
+		openOrders:  make(map[int64]*ports.OrderResponse),
+		orderSides:  make(map[int64]domain.PositionSide),
+		byClientID:  make(map[string]*ports.OrderResponse),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// intervalName formats a kline duration the way Binance names intervals (e.g., "1m", "4h").
+func intervalName(step time.Duration) string {
+	switch {
+	case step%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", step/(24*time.Hour))
+	case step%time.Hour == 0:
+		return fmt.Sprintf("%dh", step/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", step/time.Minute)
+	}
+}
+
+// Done is closed when the replay ran out of klines.
+func (f *FakeExchange) Done() <-chan struct{} {
+	return f.done
+}
+
+// Stats returns what happened so far.
+func (f *FakeExchange) Stats() Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.stats
+}
+
+// Position returns the exchange's position on positionSide (PositionSideBoth in one-way mode).
+func (f *FakeExchange) Position(positionSide domain.PositionSide) Position {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if pos := f.positions[positionSide]; pos != nil {
+		return *pos
+	}
+	return Position{}
+}
+
+// OpenOrders returns the untriggered stop and take-profit orders and resting limit orders by ID.
+func (f *FakeExchange) OpenOrders() map[int64]ports.OrderResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	orders := make(map[int64]ports.OrderResponse, len(f.openOrders))
+	for id, order := range f.openOrders {
+		orders[id] = *order
+	}
+	return orders
+}
+
+// klineAt returns the n-th kline of the replay. Each pass continues the previous one in time;
+// odd passes replay the recording backwards with each bar's open and close swapped, so the
+// price stays continuous from pass to pass without drifting.
+func (f *FakeExchange) klineAt(n int) *domain.Kline {
+	recorded := len(f.cfg.Klines)
+	pass, i := n/recorded, n%recorded
+	src := *f.cfg.Klines[i]
+	if pass%2 == 1 {
+		src = *f.cfg.Klines[recorded-1-i]
+		src.Open, src.Close = src.Close, src.Open
+	}
+	openTime := f.cfg.Klines[0].OpenTime.Add(time.Duration(n) * f.step)
+	return &domain.Kline{
+		OpenTime:  openTime,
+		CloseTime: openTime.Add(f.step - time.Millisecond),
+		Symbol:    f.cfg.Symbol,
+		Interval:  f.interval,
+		Open:      src.Open,
+		High:      src.High,
+		Low:       src.Low,
+		Close:     src.Close,
+		Volume:    src.Volume,
+		IsFinal:   true,
+	}
+}
+
+// lastPrice returns the close of the last closed kline. Assumes the caller holds the lock.
+func (f *FakeExchange) lastPrice() float64 {
+	return f.klineAt(f.cursor - 1).Close
+}
+
+// tick returns the spacing of the order book's levels around the last close. Assumes the caller
+// holds the lock.
+func (f *FakeExchange) tick() float64 {
+	return f.lastPrice() * 0.0001
+}
+
+// chance draws from the fault generator. Assumes the caller holds the lock.
+func (f *FakeExchange) chance(rate float64) bool {
+	return rate > 0 && f.rng.Float64() < rate
+}
+
+// --- Replay ---
+
+// StreamKlines replays the recording on the recorded interval. Disconnects report
+// ports.ErrConnectionFailed to errHandler and lose the next klines, like a reconnect would.
+// Once the replay ran out of klines the stream idles until stopped.
+func (f *FakeExchange) StreamKlines(ctx context.Context, symbol, interval string, handler func(kline *domain.Kline), errHandler func(err error)) (chan struct{}, chan struct{}, error) {
+	if symbol != f.cfg.Symbol || interval != f.interval {
+		return nil, nil, fmt.Errorf("%w: fake exchange only streams %s %s klines", ports.ErrInvalidRequest, f.cfg.Symbol, f.interval)
+	}
+	f.mu.Lock()
+	if f.streaming {
+		f.mu.Unlock()
+		return nil, nil, fmt.Errorf("%w: %s stream already running", ports.ErrInvalidRequest, interval)
+	}
+	f.streaming = true
+	f.mu.Unlock()
+
+	doneCh, stopCh := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(doneCh)
+		defer func() {
+			f.mu.Lock()
+			f.streaming = false
+			f.mu.Unlock()
+		}()
+		lost := 0
+		for {
+			if f.cfg.Speed > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-stopCh:
+					return
+				case <-time.After(f.cfg.Speed):
+				}
+			} else {
+				select {
+				case <-ctx.Done():
+					return
+				case <-stopCh:
+					return
+				default:
+				}
+			}
+
+			kline, ok := f.advance()
+			if !ok {
+				// Like a live stream, it stays open until stopped
+				select {
+				case <-ctx.Done():
+				case <-stopCh:
+				}
+				return
+			}
+			if lost > 0 {
+				lost--
+				continue
+			}
+			f.mu.Lock()
+			disconnect := f.chance(f.cfg.Faults.DisconnectRate)
+			if disconnect {
+				f.stats.Disconnects++
+			} else {
+				f.stats.KlinesDelivered++
+			}
+			f.mu.Unlock()
+			if disconnect {
+				lost = f.cfg.Faults.DisconnectKlines - 1
+				errHandler(fmt.Errorf("%w: injected disconnect before %s", ports.ErrConnectionFailed, kline.OpenTime.UTC().Format(time.RFC3339)))
+				continue
+			}
+			handler(kline)
+			if f.cfg.AfterKline != nil {
+				f.cfg.AfterKline(kline)
+			}
+		}
+	}()
+	return doneCh, stopCh, nil
+}
+
+// advance closes the next kline and fills the orders it triggers. Reports false, and closes
+// Done, when the replay ran out of klines.
+func (f *FakeExchange) advance() (*domain.Kline, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.total >= 0 && f.cursor >= f.total {
+		select {
+		case <-f.done:
+		default:
+			close(f.done)
+		}
+		return nil, false
+	}
+	kline := f.klineAt(f.cursor)
+	f.cursor++
+	f.stats.KlinesReplayed++
+	f.triggerOrders(kline)
+	return kline, true
+}
+
+// triggerOrders fills the stop, take-profit and limit orders whose price the kline reached, at
+// the open when it gapped through the price. Orders reached by the same kline trigger in the order
+// they were placed, so a stop placed before its take profit fills first. Assumes the caller
+// holds the lock.
+func (f *FakeExchange) triggerOrders(kline *domain.Kline) {
+	ids := make([]int64, 0, len(f.openOrders))
+	for id := range f.openOrders {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		order, ok := f.openOrders[id]
+		if !ok {
+			continue
+		}
+		if order.Type == "LIMIT" {
+			f.fillLimit(id, order, kline)
+			continue
+		}
+		sellStop := order.Type == "STOP_MARKET" && order.Side == string(domain.Sell)
+		buyTarget := order.Type == "TAKE_PROFIT_MARKET" && order.Side == string(domain.Buy)
+		var price float64
+		if sellStop || buyTarget { // Triggers on the way down
+			if kline.Low > order.StopPrice {
+				continue
+			}
+			price = math.Min(order.StopPrice, kline.Open)
+		} else { // Sell take profit and buy stop trigger on the way up
+			if kline.High < order.StopPrice {
+				continue
+			}
+			price = math.Max(order.StopPrice, kline.Open)
+		}
+		positionSide := f.orderSides[id]
+		delete(f.openOrders, id)
+		delete(f.orderSides, id)
+
+		pos := f.positions[positionSide]
+		closes := pos != nil && ((order.Side == string(domain.Sell) && pos.Amount > 0) || (order.Side == string(domain.Buy) && pos.Amount < 0))
+		if !closes {
+			order.Status = "EXPIRED"
+			f.stats.OrdersExpired++
+			continue
+		}
+		order.Status = "FILLED"
+		order.AvgPrice = price
+		order.ExecutedQty = math.Abs(pos.Amount)
+		f.fill(positionSide, -pos.Amount, price)
+		f.stats.StopsTriggered++
+	}
+}
+
+// fillLimit fills a resting limit order once the kline traded through its price, at the open
+// when it gapped through the price. Assumes the caller holds the lock.
+func (f *FakeExchange) fillLimit(id int64, order *ports.OrderResponse, kline *domain.Kline) {
+	var price float64
+	if order.Side == string(domain.Buy) {
+		if kline.Low > order.Price {
+			return
+		}
+		price = math.Min(order.Price, kline.Open)
+	} else {
+		if kline.High < order.Price {
+			return
+		}
+		price = math.Max(order.Price, kline.Open)
+	}
+	positionSide := f.orderSides[id]
+	delete(f.openOrders, id)
+	delete(f.orderSides, id)
+	f.fillOrder(order, positionSide, order.OrigQuantity, price)
+}
+
+// fillOrder executes quantity of order at price, marking it filled. Assumes the caller holds the lock.
+func (f *FakeExchange) fillOrder(order *ports.OrderResponse, positionSide domain.PositionSide, quantity, price float64) {
+	order.Status = "FILLED"
+	order.AvgPrice = price
+	order.ExecutedQty = quantity
+	if order.Side == string(domain.Sell) {
+		quantity = -quantity
+	}
+	f.fill(positionSide, quantity, price)
+}
+
+// fill applies an execution of signed quantity at price to the position on positionSide and
+// books the realized PNL. Assumes the caller holds the lock.
+func (f *FakeExchange) fill(positionSide domain.PositionSide, quantity, price float64) {
+	pos := f.positions[positionSide]
+	if pos == nil {
+		pos = &Position{}
+		f.positions[positionSide] = pos
+	}
+	switch {
+	case pos.Amount == 0 || (pos.Amount > 0) == (quantity > 0):
+		amount := pos.Amount + quantity
+		pos.EntryPrice = (pos.EntryPrice*math.Abs(pos.Amount) + price*math.Abs(quantity)) / math.Abs(amount)
+		pos.Amount = amount
+	default:
+		closed := math.Min(math.Abs(quantity), math.Abs(pos.Amount))
+		f.balance += (price - pos.EntryPrice) * closed * math.Copysign(1, pos.Amount)
+		pos.Amount += quantity
+		switch {
+		case math.Abs(pos.Amount) < 1e-12:
+			pos.Amount, pos.EntryPrice = 0, 0
+		case (pos.Amount > 0) == (quantity > 0): // Flipped to the other side
+			pos.EntryPrice = price
+		}
+	}
+}
+
+// --- Orders ---
+
+// placeOrder assigns an ID to an order, or rejects it if the fault generator says so.
+// Assumes the caller holds the lock.
+func (f *FakeExchange) placeOrder(symbol string, orderType string, side domain.OrderSide, quantity string) (*ports.OrderResponse, float64, error) {
+	if symbol != f.cfg.Symbol {
+		return nil, 0, fmt.Errorf("%w: unknown symbol %s", ports.ErrInvalidRequest, symbol)
+	}
+	qty, err := strconv.ParseFloat(quantity, 64)
+	if err != nil || qty <= 0 {
+		return nil, 0, fmt.Errorf("%w: invalid quantity %q", ports.ErrInvalidRequest, quantity)
+	}
+	if f.chance(f.cfg.Faults.RejectRate) {
+		f.stats.OrdersRejected++
+		return nil, 0, fmt.Errorf("%w: injected rejection of %s %s order", ports.ErrOrderPlacementFailed, orderType, side)
+	}
+	f.stats.OrdersPlaced++
+	order := &ports.OrderResponse{
+		OrderID:      f.nextOrderID,
+		Symbol:       symbol,
+		OrigQuantity: qty,
+		Type:         orderType,
+		Side:         string(side),
+		Timestamp:    f.klineAt(f.cursor - 1).CloseTime,
+	}
+	f.nextOrderID++
+	return order, qty, nil
+}
+
+// PlaceMarketOrder fills at the last close, partially if the fault generator says so.
+func (f *FakeExchange) PlaceMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, clientOrderID string) (*ports.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, qty, err := f.placeOrder(symbol, "MARKET", side, quantity)
+	if err != nil {
+		return nil, err
+	}
+	partial := f.chance(f.cfg.Faults.PartialFillRate)
+	if partial {
+		qty *= f.cfg.Faults.PartialFillRatio
+		f.stats.PartialFills++
+	}
+	f.fillOrder(order, positionSide, qty, f.lastPrice())
+	if partial {
+		order.Status = "EXPIRED" // The unfilled rest of a market order expires
+	}
+	order.ClientOrderID = clientOrderID
+	if clientOrderID != "" {
+		f.byClientID[clientOrderID] = order
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// PlaceReduceOnlyMarketOrder fills at the last close like PlaceMarketOrder, at most the size of
+// the position on positionSide. It fails with ports.ErrReduceOnlyRejected when there is no
+// position the order would reduce.
+func (f *FakeExchange) PlaceReduceOnlyMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string) (*ports.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pos := f.positions[positionSide]
+	if pos == nil || pos.Amount == 0 || (side == domain.Sell) != (pos.Amount > 0) {
+		f.stats.OrdersRejected++
+		return nil, fmt.Errorf("%w: %s %s order on %s", ports.ErrReduceOnlyRejected, side, quantity, positionSide)
+	}
+	order, qty, err := f.placeOrder(symbol, "MARKET", side, quantity)
+	if err != nil {
+		return nil, err
+	}
+	f.fillOrder(order, positionSide, math.Min(qty, math.Abs(pos.Amount)), f.lastPrice())
+	copied := *order
+	return &copied, nil
+}
+
+// PlaceLimitOrder rests a limit order until a kline trades through its price. A limit that
+// crosses the book fills right away at the last close, like a market order.
+func (f *FakeExchange) PlaceLimitOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, price string, clientOrderID string) (*ports.OrderResponse, error) {
+	return f.placeLimitOrder(symbol, side, positionSide, quantity, price, clientOrderID, false)
+}
+
+// PlacePostOnlyLimitOrder rests a limit order like PlaceLimitOrder, but fails with
+// ports.ErrPostOnlyRejected when it would cross the book.
+func (f *FakeExchange) PlacePostOnlyLimitOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, price string, clientOrderID string) (*ports.OrderResponse, error) {
+	return f.placeLimitOrder(symbol, side, positionSide, quantity, price, clientOrderID, true)
+}
+
+// placeLimitOrder rests a limit order, or fills or rejects one that crosses the book (the best
+// level of GetOrderBookDepth) depending on postOnly.
+func (f *FakeExchange) placeLimitOrder(symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity, price, clientOrderID string, postOnly bool) (*ports.OrderResponse, error) {
+	limit, err := strconv.ParseFloat(price, 64)
+	if err != nil || limit <= 0 {
+		return nil, fmt.Errorf("%w: invalid limit price %q", ports.ErrInvalidRequest, price)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	last, tick := f.lastPrice(), f.tick()
+	crosses := (side == domain.Buy && limit >= last+tick) || (side == domain.Sell && limit <= last-tick)
+	if crosses && postOnly {
+		f.stats.OrdersRejected++
+		return nil, fmt.Errorf("%w: %s limit at %s crosses the book at %g", ports.ErrPostOnlyRejected, side, price, last)
+	}
+	order, qty, err := f.placeOrder(symbol, "LIMIT", side, quantity)
+	if err != nil {
+		return nil, err
+	}
+	order.ClientOrderID = clientOrderID
+	order.Price = limit
+	if clientOrderID != "" {
+		f.byClientID[clientOrderID] = order
+	}
+	if crosses {
+		f.fillOrder(order, positionSide, qty, last)
+	} else {
+		order.Status = "NEW"
+		f.openOrders[order.OrderID] = order
+		f.orderSides[order.OrderID] = positionSide
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// placeTriggerOrder rests a close-position stop or take-profit order until a kline reaches stopPrice.
+func (f *FakeExchange) placeTriggerOrder(symbol, orderType string, side domain.OrderSide, positionSide domain.PositionSide, quantity, stopPrice string) (*ports.OrderResponse, error) {
+	price, err := strconv.ParseFloat(stopPrice, 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("%w: invalid stop price %q", ports.ErrInvalidRequest, stopPrice)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, _, err := f.placeOrder(symbol, orderType, side, quantity)
+	if err != nil {
+		return nil, err
+	}
+	order.Status = "NEW"
+	order.StopPrice = price
+	order.ClosePosition = true
+	f.openOrders[order.OrderID] = order
+	f.orderSides[order.OrderID] = positionSide
+	copied := *order
+	return &copied, nil
+}
+
+// PlaceStopMarketOrder rests a close-position stop order.
+func (f *FakeExchange) PlaceStopMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
+	return f.placeTriggerOrder(symbol, "STOP_MARKET", side, positionSide, quantity, stopPrice)
+}
+
+// PlaceTakeProfitMarketOrder rests a close-position take-profit order.
+func (f *FakeExchange) PlaceTakeProfitMarketOrder(ctx context.Context, symbol string, side domain.OrderSide, positionSide domain.PositionSide, quantity string, stopPrice string) (*ports.OrderResponse, error) {
+	return f.placeTriggerOrder(symbol, "TAKE_PROFIT_MARKET", side, positionSide, quantity, stopPrice)
+}
+
+// CancelOrder cancels an untriggered stop or take-profit order or a resting limit order.
+func (f *FakeExchange) CancelOrder(ctx context.Context, symbol string, orderID int64) (*ports.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.openOrders[orderID]
+	if !ok || symbol != f.cfg.Symbol {
+		return nil, fmt.Errorf("%w: order %d", ports.ErrOrderNotFound, orderID)
+	}
+	delete(f.openOrders, orderID)
+	delete(f.orderSides, orderID)
+	order.Status = "CANCELED"
+	copied := *order
+	return &copied, nil
+}
+
+// GetOrderByClientID looks up a market or limit order by its client order ID.
+func (f *FakeExchange) GetOrderByClientID(ctx context.Context, symbol string, clientOrderID string) (*ports.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.byClientID[clientOrderID]
+	if !ok || symbol != f.cfg.Symbol {
+		return nil, fmt.Errorf("%w: client order %s", ports.ErrOrderNotFound, clientOrderID)
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// ListOpenOrders returns the untriggered stop and take-profit orders and resting limit orders.
+func (f *FakeExchange) ListOpenOrders(ctx context.Context, symbol string) ([]*ports.OrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var orders []*ports.OrderResponse
+	if symbol != f.cfg.Symbol {
+		return orders, nil
+	}
+	for _, order := range f.openOrders {
+		copied := *order
+		orders = append(orders, &copied)
+	}
+	return orders, nil
+}
+
+// --- Market data and account ---
+
+// GetKlines returns up to limit klines closed so far.
+func (f *FakeExchange) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]*domain.Kline, error) {
+	if symbol != f.cfg.Symbol || interval != f.interval {
+		return nil, fmt.Errorf("%w: fake exchange only has %s %s klines", ports.ErrInvalidRequest, f.cfg.Symbol, f.interval)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	from := f.cursor - limit
+	if from < 0 {
+		from = 0
+	}
+	klines := make([]*domain.Kline, 0, f.cursor-from)
+	for n := from; n < f.cursor; n++ {
+		klines = append(klines, f.klineAt(n))
+	}
+	return klines, nil
+}
+
+// GetMarkPrice returns the last close.
+func (f *FakeExchange) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastPrice(), nil
+}
+
+// GetTickerPrice returns the last close.
+func (f *FakeExchange) GetTickerPrice(ctx context.Context, symbol string) (float64, error) {
+	return f.GetMarkPrice(ctx, symbol)
+}
+
+// GetOrderBookDepth returns a book of limit levels one tick apart on each side of the last close.
+func (f *FakeExchange) GetOrderBookDepth(ctx context.Context, symbol string, limit int) (*ports.OrderBookDepth, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	price, tick := f.lastPrice(), f.tick()
+	depth := &ports.OrderBookDepth{Symbol: symbol, Timestamp: f.klineAt(f.cursor - 1).CloseTime}
+	for i := 1; i <= limit; i++ {
+		depth.Bids = append(depth.Bids, ports.OrderBookLevel{Price: price - float64(i)*tick, Quantity: 10})
+		depth.Asks = append(depth.Asks, ports.OrderBookLevel{Price: price + float64(i)*tick, Quantity: 10})
+	}
+	return depth, nil
+}
+
+// GetAccountBalance returns the starting balance plus the realized PNL.
+func (f *FakeExchange) GetAccountBalance(ctx context.Context, asset string) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.balance, nil
+}
+
+// GetPositionRisks returns every open position side.
+func (f *FakeExchange) GetPositionRisks(ctx context.Context, symbol string) ([]*ports.PositionRisk, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if symbol != f.cfg.Symbol {
+		return nil, nil
+	}
+	var risks []*ports.PositionRisk
+	for _, side := range []domain.PositionSide{domain.PositionSideBoth, domain.PositionSideLong, domain.PositionSideShort} {
+		pos := f.positions[side]
+		if pos == nil || pos.Amount == 0 {
+			continue
+		}
+		price := f.lastPrice()
+		risks = append(risks, &ports.PositionRisk{
+			Symbol:           symbol,
+			PositionAmt:      pos.Amount,
+			EntryPrice:       pos.EntryPrice,
+			MarkPrice:        price,
+			UnRealizedProfit: (price - pos.EntryPrice) * pos.Amount,
+			Leverage:         f.leverage,
+			MarginType:       f.marginType,
+			PositionSide:     side,
+		})
+	}
+	return risks, nil
+}
+
+// SetLeverage records the leverage.
+func (f *FakeExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.leverage = leverage
+	return nil
+}
+
+// SetPositionMode switches between hedge and one-way mode.
+func (f *FakeExchange) SetPositionMode(ctx context.Context, hedgeMode bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.hedgeMode == hedgeMode {
+		return ports.ErrNoChangeNeeded
+	}
+	f.hedgeMode = hedgeMode
+	return nil
+}
+
+// ChangeMarginType switches the margin mode.
+func (f *FakeExchange) ChangeMarginType(ctx context.Context, symbol string, marginType domain.MarginType) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.marginType == marginType {
+		return ports.ErrNoChangeNeeded
+	}
+	f.marginType = marginType
+	return nil
+}
+
+// SetServerTime does nothing; the fake exchange runs on the local clock.
+func (f *FakeExchange) SetServerTime(ctx context.Context) error {
+	return nil
+}
+
+// GetServerTime returns the local time.
+func (f *FakeExchange) GetServerTime(ctx context.Context) (time.Time, error) {
+	return time.Now(), nil
+}
+
+// Ping always succeeds.
+func (f *FakeExchange) Ping(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,188 @@
+package testharness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/ports"
+)
+
+// recording returns n 1m klines rising by 1 per bar from 100, with a 2 point range around the close
+func recording(n int) []*domain.Kline {
+	start := time.Date(2025, 6, 11, 0, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, n)
+	for i := range klines {
+		price := 100 + float64(i)
+		klines[i] = &domain.Kline{OpenTime: start.Add(time.Duration(i) * time.Minute), Open: price - 1, High: price + 1, Low: price - 2, Close: price}
+	}
+	return klines
+}
+
+// replay streams the whole replay and returns the delivered klines and stream errors
+func replay(t *testing.T, exchange *FakeExchange) ([]*domain.Kline, []error) {
+	t.Helper()
+	var delivered []*domain.Kline
+	var errs []error
+	doneCh, stopCh, err := exchange.StreamKlines(context.Background(), "ETHUSDT", "1m",
+		func(kline *domain.Kline) { delivered = append(delivered, kline) },
+		func(err error) { errs = append(errs, err) })
+	require.NoError(t, err)
+	select {
+	case <-exchange.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay did not finish")
+	}
+	close(stopCh)
+	<-doneCh
+	return delivered, errs
+}
+
+func TestFakeExchange_Replay(t *testing.T) {
+	exchange, err := New(Config{Symbol: "ETHUSDT", Klines: recording(10), History: 4, Loops: 2})
+	require.NoError(t, err)
+
+	history, err := exchange.GetKlines(context.Background(), "ETHUSDT", "1m", 3)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, 103.0, history[2].Close)
+
+	delivered, errs := replay(t, exchange)
+	assert.Empty(t, errs)
+	require.Len(t, delivered, 16) // 6 klines after the history, then 10 backwards
+	for i := 1; i < len(delivered); i++ {
+		assert.Equal(t, time.Minute, delivered[i].OpenTime.Sub(delivered[i-1].OpenTime), "kline %d", i)
+	}
+	assert.Equal(t, 109.0, delivered[5].Close)
+	assert.Equal(t, 109.0, delivered[6].Open, "the backwards pass continues the price")
+	assert.Equal(t, 99.0, delivered[15].Close, "ends at the open of the first recorded kline")
+	assert.True(t, delivered[15].IsFinal)
+
+	_, _, err = exchange.StreamKlines(context.Background(), "ETHUSDT", "15m", func(*domain.Kline) {}, func(error) {})
+	assert.ErrorIs(t, err, ports.ErrInvalidRequest)
+}
+
+func TestFakeExchange_StopOrders(t *testing.T) {
+	klines := recording(10)
+	klines[6].Low = 90 // Wicks through the stop
+	exchange, err := New(Config{Symbol: "ETHUSDT", Klines: klines, History: 4, Balance: 1000})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	entry, err := exchange.PlaceMarketOrder(ctx, "ETHUSDT", domain.Buy, domain.PositionSideBoth, "2", "entry-1")
+	require.NoError(t, err)
+	assert.Equal(t, 103.0, entry.AvgPrice)
+	assert.Equal(t, 2.0, entry.ExecutedQty)
+	stop, err := exchange.PlaceStopMarketOrder(ctx, "ETHUSDT", domain.Sell, domain.PositionSideBoth, "2", "95")
+	require.NoError(t, err)
+	tp, err := exchange.PlaceTakeProfitMarketOrder(ctx, "ETHUSDT", domain.Sell, domain.PositionSideBoth, "2", "150")
+	require.NoError(t, err)
+	byClient, err := exchange.GetOrderByClientID(ctx, "ETHUSDT", "entry-1")
+	require.NoError(t, err)
+	assert.Equal(t, entry.OrderID, byClient.OrderID)
+
+	replay(t, exchange)
+	assert.Equal(t, Position{}, exchange.Position(domain.PositionSideBoth))
+	open := exchange.OpenOrders()
+	assert.NotContains(t, open, stop.OrderID)
+	assert.Contains(t, open, tp.OrderID, "the take profit stays behind, like on Binance")
+	balance, err := exchange.GetAccountBalance(ctx, "USDT")
+	require.NoError(t, err)
+	assert.InDelta(t, 1000-2*8, balance, 1e-9)
+	stats := exchange.Stats()
+	assert.Equal(t, 1, stats.StopsTriggered)
+	assert.Equal(t, 3, stats.OrdersPlaced)
+
+	_, err = exchange.CancelOrder(ctx, "ETHUSDT", stop.OrderID)
+	assert.ErrorIs(t, err, ports.ErrOrderNotFound)
+	_, err = exchange.CancelOrder(ctx, "ETHUSDT", tp.OrderID)
+	assert.NoError(t, err)
+}
+
+func TestFakeExchange_Faults(t *testing.T) {
+	run := func(seed int64) ([]*domain.Kline, []error, Stats) {
+		exchange, err := New(Config{Symbol: "ETHUSDT", Klines: recording(200), History: 10, Seed: seed,
+			Faults: Faults{DisconnectRate: 0.05, DisconnectKlines: 2}})
+		require.NoError(t, err)
+		delivered, errs := replay(t, exchange)
+		return delivered, errs, exchange.Stats()
+	}
+
+	delivered, errs, stats := run(7)
+	require.NotEmpty(t, errs)
+	assert.ErrorIs(t, errs[0], ports.ErrConnectionFailed)
+	assert.Equal(t, stats.Disconnects, len(errs))
+	assert.Equal(t, 190, stats.KlinesReplayed)
+	assert.Equal(t, len(delivered), stats.KlinesDelivered)
+	assert.Less(t, len(delivered), 190)
+
+	again, _, _ := run(7)
+	assert.Equal(t, delivered, again, "the same seed injects the same faults")
+
+	exchange, err := New(Config{Symbol: "ETHUSDT", Klines: recording(10), History: 4,
+		Faults: Faults{RejectRate: 1}})
+	require.NoError(t, err)
+	_, err = exchange.PlaceMarketOrder(context.Background(), "ETHUSDT", domain.Buy, domain.PositionSideBoth, "1", "")
+	assert.ErrorIs(t, err, ports.ErrOrderPlacementFailed)
+
+	exchange, err = New(Config{Symbol: "ETHUSDT", Klines: recording(10), History: 4,
+		Faults: Faults{PartialFillRate: 1, PartialFillRatio: 0.25}})
+	require.NoError(t, err)
+	order, err := exchange.PlaceMarketOrder(context.Background(), "ETHUSDT", domain.Sell, domain.PositionSideBoth, "2", "")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, order.ExecutedQty)
+	assert.Equal(t, -0.5, exchange.Position(domain.PositionSideBoth).Amount)
+}
+
+func TestFakeExchange_OrderConstraints(t *testing.T) {
+	klines := recording(10)
+	klines[6].Low = 95 // Trades down through the resting limit
+	exchange, err := New(Config{Symbol: "ETHUSDT", Klines: klines, History: 4})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Reduce-only orders can't open or grow a position, and close at most all of it
+	_, err = exchange.PlaceReduceOnlyMarketOrder(ctx, "ETHUSDT", domain.Sell, domain.PositionSideBoth, "1")
+	assert.ErrorIs(t, err, ports.ErrReduceOnlyRejected, "no position to reduce")
+	_, err = exchange.PlaceMarketOrder(ctx, "ETHUSDT", domain.Buy, domain.PositionSideBoth, "2", "")
+	require.NoError(t, err)
+	_, err = exchange.PlaceReduceOnlyMarketOrder(ctx, "ETHUSDT", domain.Buy, domain.PositionSideBoth, "1")
+	assert.ErrorIs(t, err, ports.ErrReduceOnlyRejected, "would grow the long")
+	closed, err := exchange.PlaceReduceOnlyMarketOrder(ctx, "ETHUSDT", domain.Sell, domain.PositionSideBoth, "5")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, closed.ExecutedQty)
+	assert.Equal(t, Position{}, exchange.Position(domain.PositionSideBoth))
+
+	// Post-only limits that would cross the book at 103 are rejected; plain ones fill right away
+	_, err = exchange.PlacePostOnlyLimitOrder(ctx, "ETHUSDT", domain.Buy, domain.PositionSideBoth, "1", "104", "post-buy")
+	assert.ErrorIs(t, err, ports.ErrPostOnlyRejected)
+	_, err = exchange.PlacePostOnlyLimitOrder(ctx, "ETHUSDT", domain.Sell, domain.PositionSideBoth, "1", "102", "post-sell")
+	assert.ErrorIs(t, err, ports.ErrPostOnlyRejected)
+	_, err = exchange.GetOrderByClientID(ctx, "ETHUSDT", "post-buy")
+	assert.ErrorIs(t, err, ports.ErrOrderNotFound, "a rejected order never reaches the book")
+	taker, err := exchange.PlaceLimitOrder(ctx, "ETHUSDT", domain.Buy, domain.PositionSideBoth, "1", "104", "")
+	require.NoError(t, err)
+	assert.Equal(t, "FILLED", taker.Status)
+	assert.Equal(t, 103.0, taker.AvgPrice)
+	_, err = exchange.PlaceReduceOnlyMarketOrder(ctx, "ETHUSDT", domain.Sell, domain.PositionSideBoth, "1")
+	require.NoError(t, err)
+
+	// A post-only limit below the book rests until price trades through it
+	maker, err := exchange.PlacePostOnlyLimitOrder(ctx, "ETHUSDT", domain.Buy, domain.PositionSideBoth, "1", "101", "maker")
+	require.NoError(t, err)
+	assert.Equal(t, "NEW", maker.Status)
+	assert.Contains(t, exchange.OpenOrders(), maker.OrderID)
+
+	replay(t, exchange)
+	filled, err := exchange.GetOrderByClientID(ctx, "ETHUSDT", "maker")
+	require.NoError(t, err)
+	assert.Equal(t, "FILLED", filled.Status)
+	assert.Equal(t, 101.0, filled.AvgPrice)
+	assert.Equal(t, Position{Amount: 1, EntryPrice: 101}, exchange.Position(domain.PositionSideBoth))
+	assert.Empty(t, exchange.OpenOrders())
+	assert.Equal(t, 4, exchange.Stats().OrdersRejected)
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"fmt"
+	"time"
+)
+
+// KlineAggregator resamples final klines of a base interval (e.g., 1m) into klines of a higher
+// interval (e.g., 15m) as they arrive. Higher bars are aligned to UTC like the exchange's own
+// (15m bars open at :00, :15, ...; 1w bars on Mondays)
+type KlineAggregator struct {
+	base     time.Duration
+	interval string
+	duration time.Duration
+	current  *domain.Kline // Higher bar being built; nil until a base kline opens one
+	complete bool          // Whether the current bar started at its period's open
+	lastOpen time.Time     // Open time of the last base kline added
+}
+
+// NewKlineAggregator creates an aggregator of baseInterval klines into interval klines. The
+// interval must be a multiple of the base interval
+func NewKlineAggregator(baseInterval, interval string) (*KlineAggregator, error) {
+	base, err := ParseInterval(baseInterval)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := ParseInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+	if duration <= base || duration%base != 0 {
+		return nil, fmt.Errorf("interval %s is not a multiple of the base interval %s", interval, baseInterval)
+	}
+	return &KlineAggregator{base: base, interval: interval, duration: duration}, nil
+}
+
+// Interval returns the interval of the aggregated klines
+func (a *KlineAggregator) Interval() string {
+	return a.interval
+}
+
+// Add adds a base kline and returns the higher kline it completes, or nil. A bar completes with
+// its last base kline, or when a kline of a later period arrives after base klines went missing.
+// Bars whose first base klines weren't seen (e.g., when the aggregator starts mid-period) are
+// dropped rather than returned with a wrong open. Klines that aren't final, or don't follow the
+// previous one, are ignored
+func (a *KlineAggregator) Add(kline *domain.Kline) *domain.Kline {
+	if kline == nil || !kline.IsFinal || (!a.lastOpen.IsZero() && !kline.OpenTime.After(a.lastOpen)) {
+		return nil
+	}
+	a.lastOpen = kline.OpenTime
+
+	var completed *domain.Kline
+	start := kline.OpenTime.UTC().Truncate(a.duration)
+	if a.current != nil && !a.current.OpenTime.Equal(start) {
+		// A kline of a later period: the previous bar ended without its last base klines. Only one
+		// of it and the new bar can be returned complete, as a bar needs its first and last klines
+		completed = a.finish()
+	}
+	if a.current == nil {
+		a.current = &domain.Kline{
+			OpenTime:  start,
+			CloseTime: start.Add(a.duration - time.Millisecond),
+			Symbol:    kline.Symbol,
+			Interval:  a.interval,
+			Open:      kline.Open,
+			High:      kline.High,
+			Low:       kline.Low,
+		}
+		a.complete = kline.OpenTime.Equal(start)
+	}
+	a.current.High = max(a.current.High, kline.High)
+	a.current.Low = min(a.current.Low, kline.Low)
+	a.current.Close = kline.Close
+	a.current.Volume += kline.Volume
+
+	if !kline.OpenTime.Add(a.base).Before(start.Add(a.duration)) {
+		if bar := a.finish(); bar != nil {
+			completed = bar
+		}
+	}
+	return completed
+}
+
+// finish ends the current bar, returning it if it saw the start of its period
+func (a *KlineAggregator) finish() *domain.Kline {
+	bar := a.current
+	a.current = nil
+	if !a.complete {
+		return nil
+	}
+	bar.IsFinal = true
+	return bar
+}
+
+// Current returns a copy of the bar being built (not final), or nil if there is none
+func (a *KlineAggregator) Current() *domain.Kline {
+	if a.current == nil {
+		return nil
+	}
+	bar := *a.current
+	return &bar
+}
+
+// AggregateKlines resamples final baseInterval klines (oldest first) into interval klines,
+// returning the completed bars only
+func AggregateKlines(klines []*domain.Kline, baseInterval, interval string) ([]*domain.Kline, error) {
+	aggregator, err := NewKlineAggregator(baseInterval, interval)
+	if err != nil {
+		return nil, err
+	}
+	var bars []*domain.Kline
+	for _, k := range klines {
+		if bar := aggregator.Add(k); bar != nil {
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"testing"
+	"time"
+)
+
+// ohlcvKlines returns consecutive final 1m klines starting at start, one per close, each ranging
+// 1 around its close with a volume of 1
+func ohlcvKlines(start time.Time, closes ...float64) []*domain.Kline {
+	klines := make([]*domain.Kline, len(closes))
+	for i, c := range closes {
+		open := start.Add(time.Duration(i) * time.Minute)
+		klines[i] = &domain.Kline{OpenTime: open, CloseTime: open.Add(time.Minute - time.Millisecond), Symbol: "ETHUSDT", Interval: "1m",
+			Open: c - 0.5, High: c + 1, Low: c - 1, Close: c, Volume: 1, IsFinal: true}
+	}
+	return klines
+}
+
+func TestKlineAggregator(t *testing.T) {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	aggregator, err := NewKlineAggregator("1m", "5m")
+	if err != nil {
+		t.Fatalf("NewKlineAggregator failed: %v", err)
+	}
+
+	klines := ohlcvKlines(start, 100, 104, 98, 101, 103, 110)
+	for i, k := range klines[:4] {
+		if bar := aggregator.Add(k); bar != nil {
+			t.Fatalf("Unexpected bar after kline %d: %+v", i, bar)
+		}
+	}
+	if current := aggregator.Current(); current == nil || current.Close != 101 || current.IsFinal {
+		t.Errorf("Expected the bar in progress to close at 101, got %+v", current)
+	}
+	bar := aggregator.Add(klines[4])
+	want := domain.Kline{OpenTime: start, CloseTime: start.Add(5*time.Minute - time.Millisecond), Symbol: "ETHUSDT", Interval: "5m",
+		Open: 99.5, High: 105, Low: 97, Close: 103, Volume: 5, IsFinal: true}
+	if bar == nil || *bar != want {
+		t.Fatalf("Expected %+v, got %+v", want, bar)
+	}
+
+	// Replayed and unfinished klines are ignored
+	if aggregator.Add(klines[4]) != nil || aggregator.Current() != nil {
+		t.Error("Expected a replayed kline to be ignored")
+	}
+	unfinished := *klines[5]
+	unfinished.IsFinal = false
+	aggregator.Add(&unfinished)
+	if aggregator.Current() != nil {
+		t.Error("Expected an unfinished kline to be ignored")
+	}
+}
+
+func TestKlineAggregatorGaps(t *testing.T) {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	klines := ohlcvKlines(start, 100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110, 111, 112, 113, 114)
+
+	// Starting mid-period drops the first bar; a gap ends a bar early with what it has
+	bars, err := AggregateKlines(append(klines[2:8:8], klines[10:]...), "1m", "5m")
+	if err != nil {
+		t.Fatalf("AggregateKlines failed: %v", err)
+	}
+	if len(bars) != 2 {
+		t.Fatalf("Expected 2 bars, got %d", len(bars))
+	}
+	if !bars[0].OpenTime.Equal(start.Add(5*time.Minute)) || bars[0].Close != 107 || bars[0].Volume != 3 {
+		t.Errorf("Unexpected bar ended by the gap: %+v", bars[0])
+	}
+	if !bars[1].OpenTime.Equal(start.Add(10*time.Minute)) || bars[1].Close != 114 || bars[1].Volume != 5 {
+		t.Errorf("Unexpected bar after the gap: %+v", bars[1])
+	}
+
+	// Higher bars are aligned like the exchange's: 1w bars open on Mondays
+	weekly, err := NewKlineAggregator("1d", "1w")
+	if err != nil {
+		t.Fatalf("NewKlineAggregator failed: %v", err)
+	}
+	weekly.Add(&domain.Kline{OpenTime: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), Close: 1, IsFinal: true})
+	if open := weekly.Current().OpenTime; open.Weekday() != time.Monday {
+		t.Errorf("Expected the weekly bar to open on a Monday, got %s", open)
+	}
+
+	for _, intervals := range [][2]string{{"5m", "7m"}, {"5m", "5m"}, {"1h", "15m"}, {"1m", "x"}} {
+		if _, err := NewKlineAggregator(intervals[0], intervals[1]); err == nil {
+			t.Errorf("Expected aggregating %s into %s to fail", intervals[0], intervals[1])
+		}
+	}
+}
@@ -1,25 +1,232 @@
 package utils
 
 import (
+	"compress/gzip"
+	"context"
 	"cryptoMegaBot/internal/domain"
 	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
-func WriteKlinesToCSV(klines []*domain.Kline, filename string) error {
+// KlineCSVHeader is the expected header of kline CSV files
+var KlineCSVHeader = []string{"open_time", "close_time", "symbol", "interval", "open", "high", "low", "close", "volume"}
+
+// TradeCSVHeader is the expected header of trade CSV files
+var TradeCSVHeader = []string{"position_id", "symbol", "entry_price", "exit_price", "quantity", "leverage", "pnl", "entry_time", "exit_time", "close_reason",
+	"entry_reason", "signal_source", "confirmation_count", "entry_atr", "mae", "mfe", "regime_trend", "regime_volatility", "regime_htf"}
+
+// FundingRateCSVHeader is the expected header of funding rate CSV files
+var FundingRateCSVHeader = []string{"funding_time", "symbol", "funding_rate", "mark_price"}
+
+// OpenInterestCSVHeader is the expected header of open interest CSV files
+var OpenInterestCSVHeader = []string{"time", "symbol", "period", "open_interest", "open_interest_value"}
+
+// legacyTradeColumns is the number of columns in trade files written before entry tags were added
+const legacyTradeColumns = 10
+
+// taggedTradeColumns is the number of columns in trade files written before MAE/MFE were added
+const taggedTradeColumns = 14
+
+// excursionTradeColumns is the number of columns in trade files written before regimes were added
+const excursionTradeColumns = 16
+
+// ErrInvalidHeader is returned when a CSV file's header doesn't match the expected schema
+var ErrInvalidHeader = errors.New("invalid CSV header")
+
+// CSVRowError reports a malformed row together with its location in the file
+type CSVRowError struct {
+	File   string
+	Line   int
+	Column string // Empty if the error isn't specific to a column
+	Err    error
+}
+
+func (e *CSVRowError) Error() string {
+	if e.Column != "" {
+		return fmt.Sprintf("%s:%d: column %s: %v", e.File, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+// openCSV opens a CSV file for reading, transparently decompressing .gz files
+func openCSV(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filename, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open gzip stream in %s: %w", filename, err)
+	}
+	return &gzipReadCloser{Reader: gz, file: file}, nil
+}
+
+// createCSV creates a CSV file for writing, compressing the output when the name ends in .gz
+func createCSV(filename string) (io.WriteCloser, error) {
 	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filename, ".gz") {
+		return file, nil
+	}
+	return &gzipWriteCloser{Writer: gzip.NewWriter(file), file: file}, nil
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipReadCloser) Close() error {
+	gzErr := g.Reader.Close()
+	if err := g.file.Close(); err != nil {
+		return err
+	}
+	return gzErr
+}
+
+type gzipWriteCloser struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g *gzipWriteCloser) Close() error {
+	gzErr := g.Writer.Close()
+	if err := g.file.Close(); err != nil {
+		return err
+	}
+	return gzErr
+}
+
+// iterateCSV validates the header and calls fn for every data row with its line number.
+// Files may omit trailing columns of header as long as they have at least minColumns.
+func iterateCSV(filename string, header []string, minColumns int, fn func(rec []string, line int) error) error {
+	r, err := openCSV(filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 0 // Every row must have as many fields as the header
+	reader.ReuseRecord = true
+
+	got, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%s: %w: file is empty", filename, ErrInvalidHeader)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w: %v", filename, ErrInvalidHeader, err)
+	}
+	if len(got) < minColumns || len(got) > len(header) {
+		return fmt.Errorf("%s: %w: got %d columns, expected %d", filename, ErrInvalidHeader, len(got), len(header))
+	}
+	if err := validateHeader(got, header[:len(got)]); err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return &CSVRowError{File: filename, Line: parseErr.Line, Err: parseErr.Err}
+			}
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		if err := fn(rec, line); err != nil {
+			return err
+		}
+	}
+}
+
+// validateHeader checks that got matches the expected column names exactly
+func validateHeader(got, want []string) error {
+	for i, col := range want {
+		if strings.TrimSpace(got[i]) != col {
+			return fmt.Errorf("%w: column %d is %q, expected %q", ErrInvalidHeader, i+1, got[i], col)
+		}
+	}
+	return nil
+}
+
+// rowParser parses the fields of a single CSV row, remembering the first error
+type rowParser struct {
+	file   string
+	line   int
+	header []string
+	rec    []string
+	err    error
+}
+
+func (p *rowParser) fail(col int, err error) {
+	if p.err == nil {
+		p.err = &CSVRowError{File: p.file, Line: p.line, Column: p.header[col], Err: err}
+	}
+}
+
+func (p *rowParser) float(col int) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(p.rec[col]), 64)
+	if err != nil {
+		p.fail(col, err)
+	}
+	return v
+}
+
+func (p *rowParser) int(col int) int64 {
+	v, err := strconv.ParseInt(strings.TrimSpace(p.rec[col]), 10, 64)
+	if err != nil {
+		p.fail(col, err)
+	}
+	return v
+}
+
+func (p *rowParser) time(col int) time.Time {
+	v, err := time.Parse(time.RFC3339, strings.TrimSpace(p.rec[col]))
+	if err != nil {
+		p.fail(col, err)
+	}
+	return v
+}
+
+func (p *rowParser) required(col int) string {
+	v := strings.TrimSpace(p.rec[col])
+	if v == "" {
+		p.fail(col, errors.New("value is required"))
+	}
+	return v
+}
+
+func WriteKlinesToCSV(klines []*domain.Kline, filename string) error {
+	file, err := createCSV(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
 
 	// Write header
-	writer.Write([]string{"open_time", "close_time", "symbol", "interval", "open", "high", "low", "close", "volume"})
+	writer.Write(KlineCSVHeader)
 
 	for _, k := range klines {
 		writer.Write([]string{
@@ -34,53 +241,88 @@ func WriteKlinesToCSV(klines []*domain.Kline, filename string) error {
 			strconv.FormatFloat(k.Volume, 'f', -1, 64),
 		})
 	}
-	return writer.Error()
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
 
-func ReadKlinesFromCSV(filename string) ([]*domain.Kline, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+// IterateKlinesCSV streams klines from a (optionally gzipped) CSV file without loading it into memory.
+// The header is validated and malformed rows are reported as *CSVRowError with their line number.
+// Returning an error from fn stops iteration and returns that error.
+func IterateKlinesCSV(filename string, fn func(*domain.Kline) error) error {
+	return iterateCSV(filename, KlineCSVHeader, len(KlineCSVHeader), func(rec []string, line int) error {
+		p := &rowParser{file: filename, line: line, header: KlineCSVHeader, rec: rec}
+		k := &domain.Kline{
+			OpenTime:  p.time(0),
+			CloseTime: p.time(1),
+			Symbol:    p.required(2),
+			Interval:  p.required(3),
+			Open:      p.float(4),
+			High:      p.float(5),
+			Low:       p.float(6),
+			Close:     p.float(7),
+			Volume:    p.float(8),
+			IsFinal:   true,
+		}
+		if p.err != nil {
+			return p.err
+		}
+		if k.High < k.Low {
+			return &CSVRowError{File: filename, Line: line, Err: fmt.Errorf("high %v is below low %v", k.High, k.Low)}
+		}
+		return fn(k)
+	})
+}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, err
-	}
+// StreamKlinesCSV streams klines over a channel. The error channel receives at most one error
+// and both channels are closed when reading finishes or ctx is canceled.
+func StreamKlinesCSV(ctx context.Context, filename string) (<-chan *domain.Kline, <-chan error) {
+	klineCh := make(chan *domain.Kline)
+	errCh := make(chan error, 1)
 
-	var klines []*domain.Kline
-	for i, rec := range records {
-		if i == 0 {
-			continue
-		} // skip header
-		openTime, _ := time.Parse(time.RFC3339, rec[0])
-		closeTime, _ := time.Parse(time.RFC3339, rec[1])
-		open, _ := strconv.ParseFloat(rec[4], 64)
-		high, _ := strconv.ParseFloat(rec[5], 64)
-		low, _ := strconv.ParseFloat(rec[6], 64)
-		close, _ := strconv.ParseFloat(rec[7], 64)
-		volume, _ := strconv.ParseFloat(rec[8], 64)
-		klines = append(klines, &domain.Kline{
-			OpenTime: openTime, CloseTime: closeTime, Symbol: rec[2], Interval: rec[3],
-			Open: open, High: high, Low: low, Close: close, Volume: volume, IsFinal: true,
+	go func() {
+		defer close(klineCh)
+		defer close(errCh)
+
+		err := IterateKlinesCSV(filename, func(k *domain.Kline) error {
+			select {
+			case klineCh <- k:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 		})
+		if err != nil {
+			errCh <- err
+		}
+	}()
+	return klineCh, errCh
+}
+
+func ReadKlinesFromCSV(filename string) ([]*domain.Kline, error) {
+	var klines []*domain.Kline
+	err := IterateKlinesCSV(filename, func(k *domain.Kline) error {
+		klines = append(klines, k)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return klines, nil
 }
 
 func WriteTradesToCSV(trades []*domain.Trade, filename string) error {
-	file, err := os.Create(filename)
+	file, err := createCSV(filename)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
 
-	writer.Write([]string{"position_id", "symbol", "entry_price", "exit_price", "quantity", "leverage", "pnl", "entry_time", "exit_time", "close_reason"})
+	writer.Write(TradeCSVHeader)
 	for _, t := range trades {
 		writer.Write([]string{
 			strconv.FormatInt(t.PositionID, 10),
@@ -93,50 +335,188 @@ func WriteTradesToCSV(trades []*domain.Trade, filename string) error {
 			t.EntryTime.Format(time.RFC3339),
 			t.ExitTime.Format(time.RFC3339),
 			string(t.CloseReason),
+			t.EntryReason,
+			string(t.SignalSource),
+			strconv.Itoa(t.ConfirmationCount),
+			strconv.FormatFloat(t.EntryATR, 'f', -1, 64),
+			strconv.FormatFloat(t.MAE, 'f', -1, 64),
+			strconv.FormatFloat(t.MFE, 'f', -1, 64),
+			string(t.Regime.Trend),
+			string(t.Regime.Volatility),
+			string(t.Regime.HigherTF),
 		})
 	}
-	return writer.Error()
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// IterateTradesCSV streams trades from a (optionally gzipped) CSV file without loading it into memory.
+// The header is validated and malformed rows are reported as *CSVRowError with their line number.
+// Files written before entry tags, MAE/MFE or regimes were added are still accepted; the missing fields are left zero.
+// Returning an error from fn stops iteration and returns that error.
+func IterateTradesCSV(filename string, fn func(*domain.Trade) error) error {
+	return iterateCSV(filename, TradeCSVHeader, legacyTradeColumns, func(rec []string, line int) error {
+		p := &rowParser{file: filename, line: line, header: TradeCSVHeader, rec: rec}
+		t := &domain.Trade{
+			PositionID:  p.int(0),
+			Symbol:      p.required(1),
+			EntryPrice:  p.float(2),
+			ExitPrice:   p.float(3),
+			Quantity:    p.float(4),
+			Leverage:    int(p.int(5)),
+			PNL:         p.float(6),
+			EntryTime:   p.time(7),
+			ExitTime:    p.time(8),
+			CloseReason: domain.CloseReason(strings.TrimSpace(rec[9])),
+		}
+		if len(rec) >= taggedTradeColumns {
+			t.EntryReason = strings.TrimSpace(rec[10])
+			t.SignalSource = domain.SignalSource(strings.TrimSpace(rec[11]))
+			t.ConfirmationCount = int(p.int(12))
+			t.EntryATR = p.float(13)
+		}
+		if len(rec) >= excursionTradeColumns {
+			t.MAE = p.float(14)
+			t.MFE = p.float(15)
+		}
+		if len(rec) == len(TradeCSVHeader) {
+			t.Regime = domain.MarketRegime{
+				Trend:      domain.TrendRegime(strings.TrimSpace(rec[16])),
+				Volatility: domain.VolatilityRegime(strings.TrimSpace(rec[17])),
+				HigherTF:   domain.TrendDirection(strings.TrimSpace(rec[18])),
+			}
+		}
+		if p.err != nil {
+			return p.err
+		}
+		return fn(t)
+	})
 }
 
 func ReadTradesFromCSV(filename string) ([]*domain.Trade, error) {
-	file, err := os.Open(filename)
+	var trades []*domain.Trade
+	err := IterateTradesCSV(filename, func(t *domain.Trade) error {
+		trades = append(trades, t)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	return trades, nil
+}
+
+func WriteFundingRatesToCSV(rates []*domain.FundingRate, filename string) error {
+	file, err := createCSV(filename)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(file)
+
+	writer.Write(FundingRateCSVHeader)
+	for _, r := range rates {
+		writer.Write([]string{
+			r.FundingTime.Format(time.RFC3339),
+			r.Symbol,
+			strconv.FormatFloat(r.Rate, 'f', -1, 64),
+			strconv.FormatFloat(r.MarkPrice, 'f', -1, 64),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// IterateFundingRatesCSV streams funding rates from a (optionally gzipped) CSV file.
+// Returning an error from fn stops iteration and returns that error.
+func IterateFundingRatesCSV(filename string, fn func(*domain.FundingRate) error) error {
+	return iterateCSV(filename, FundingRateCSVHeader, len(FundingRateCSVHeader), func(rec []string, line int) error {
+		p := &rowParser{file: filename, line: line, header: FundingRateCSVHeader, rec: rec}
+		r := &domain.FundingRate{
+			FundingTime: p.time(0),
+			Symbol:      p.required(1),
+			Rate:        p.float(2),
+			MarkPrice:   p.float(3),
+		}
+		if p.err != nil {
+			return p.err
+		}
+		return fn(r)
+	})
+}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+func ReadFundingRatesFromCSV(filename string) ([]*domain.FundingRate, error) {
+	var rates []*domain.FundingRate
+	err := IterateFundingRatesCSV(filename, func(r *domain.FundingRate) error {
+		rates = append(rates, r)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return rates, nil
+}
+
+func WriteOpenInterestToCSV(snapshots []*domain.OpenInterest, filename string) error {
+	file, err := createCSV(filename)
+	if err != nil {
+		return err
+	}
 
-	var trades []*domain.Trade
-	for i, rec := range records {
-		if i == 0 {
-			continue
-		} // skip header
-		positionID, _ := strconv.ParseInt(rec[0], 10, 64)
-		entryPrice, _ := strconv.ParseFloat(rec[2], 64)
-		exitPrice, _ := strconv.ParseFloat(rec[3], 64)
-		quantity, _ := strconv.ParseFloat(rec[4], 64)
-		leverage, _ := strconv.Atoi(rec[5])
-		pnl, _ := strconv.ParseFloat(rec[6], 64)
-		entryTime, _ := time.Parse(time.RFC3339, rec[7])
-		exitTime, _ := time.Parse(time.RFC3339, rec[8])
-		closeReason := rec[9]
-		trades = append(trades, &domain.Trade{
-			PositionID:  positionID,
-			Symbol:      rec[1],
-			EntryPrice:  entryPrice,
-			ExitPrice:   exitPrice,
-			Quantity:    quantity,
-			Leverage:    leverage,
-			PNL:         pnl,
-			EntryTime:   entryTime,
-			ExitTime:    exitTime,
-			CloseReason: domain.CloseReason(closeReason),
+	writer := csv.NewWriter(file)
+
+	writer.Write(OpenInterestCSVHeader)
+	for _, oi := range snapshots {
+		writer.Write([]string{
+			oi.Time.Format(time.RFC3339),
+			oi.Symbol,
+			oi.Period,
+			strconv.FormatFloat(oi.Contracts, 'f', -1, 64),
+			strconv.FormatFloat(oi.Value, 'f', -1, 64),
 		})
 	}
-	return trades, nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// IterateOpenInterestCSV streams open interest snapshots from a (optionally gzipped) CSV file.
+// Returning an error from fn stops iteration and returns that error.
+func IterateOpenInterestCSV(filename string, fn func(*domain.OpenInterest) error) error {
+	return iterateCSV(filename, OpenInterestCSVHeader, len(OpenInterestCSVHeader), func(rec []string, line int) error {
+		p := &rowParser{file: filename, line: line, header: OpenInterestCSVHeader, rec: rec}
+		oi := &domain.OpenInterest{
+			Time:      p.time(0),
+			Symbol:    p.required(1),
+			Period:    p.required(2),
+			Contracts: p.float(3),
+			Value:     p.float(4),
+		}
+		if p.err != nil {
+			return p.err
+		}
+		return fn(oi)
+	})
+}
+
+func ReadOpenInterestFromCSV(filename string) ([]*domain.OpenInterest, error) {
+	var snapshots []*domain.OpenInterest
+	err := IterateOpenInterestCSV(filename, func(oi *domain.OpenInterest) error {
+		snapshots = append(snapshots, oi)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
 }
@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKlines() []*domain.Kline {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	klines := make([]*domain.Kline, 3)
+	for i := range klines {
+		openTime := start.Add(time.Duration(i) * time.Minute)
+		klines[i] = &domain.Kline{
+			OpenTime:  openTime,
+			CloseTime: openTime.Add(time.Minute - time.Second),
+			Symbol:    "ETHUSDT",
+			Interval:  "1m",
+			Open:      100 + float64(i),
+			High:      102 + float64(i),
+			Low:       99 + float64(i),
+			Close:     101 + float64(i),
+			Volume:    10,
+			IsFinal:   true,
+		}
+	}
+	return klines
+}
+
+func TestKlinesCSVRoundTrip(t *testing.T) {
+	for _, name := range []string{"klines.csv", "klines.csv.gz"} {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), name)
+			want := testKlines()
+			if err := WriteKlinesToCSV(want, filename); err != nil {
+				t.Fatalf("WriteKlinesToCSV failed: %v", err)
+			}
+
+			got, err := ReadKlinesFromCSV(filename)
+			if err != nil {
+				t.Fatalf("ReadKlinesFromCSV failed: %v", err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("Expected %d klines, got %d", len(want), len(got))
+			}
+			for i := range want {
+				if !got[i].OpenTime.Equal(want[i].OpenTime) || got[i].Close != want[i].Close || got[i].Symbol != want[i].Symbol {
+					t.Errorf("Kline %d mismatch: got %+v, want %+v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIterateKlinesCSVValidation(t *testing.T) {
+	header := strings.Join(KlineCSVHeader, ",")
+	validRow := "2025-05-01T00:00:00Z,2025-05-01T00:00:59Z,ETHUSDT,1m,100,102,99,101,10"
+
+	tests := []struct {
+		name       string
+		content    string
+		wantHeader bool
+		wantLine   int
+		wantColumn string
+	}{
+		{
+			name:       "wrong header",
+			content:    "time,open,high,low,close,volume,a,b,c\n" + validRow + "\n",
+			wantHeader: true,
+		},
+		{
+			name:       "empty file",
+			content:    "",
+			wantHeader: true,
+		},
+		{
+			name:       "unparseable price",
+			content:    header + "\n" + validRow + "\n" + "2025-05-01T00:01:00Z,2025-05-01T00:01:59Z,ETHUSDT,1m,abc,102,99,101,10\n",
+			wantLine:   3,
+			wantColumn: "open",
+		},
+		{
+			name:     "missing column",
+			content:  header + "\n" + validRow + "\n" + validRow + "\n" + "2025-05-01T00:01:00Z,ETHUSDT,1m,100,102,99,101,10\n",
+			wantLine: 4,
+		},
+		{
+			name:     "high below low",
+			content:  header + "\n" + "2025-05-01T00:00:00Z,2025-05-01T00:00:59Z,ETHUSDT,1m,100,98,99,101,10\n",
+			wantLine: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "klines.csv")
+			if err := os.WriteFile(filename, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := IterateKlinesCSV(filename, func(*domain.Kline) error { return nil })
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if tt.wantHeader {
+				if !errors.Is(err, ErrInvalidHeader) {
+					t.Errorf("Expected ErrInvalidHeader, got %v", err)
+				}
+				return
+			}
+
+			var rowErr *CSVRowError
+			if !errors.As(err, &rowErr) {
+				t.Fatalf("Expected *CSVRowError, got %T: %v", err, err)
+			}
+			if rowErr.Line != tt.wantLine {
+				t.Errorf("Expected error on line %d, got %d", tt.wantLine, rowErr.Line)
+			}
+			if rowErr.Column != tt.wantColumn {
+				t.Errorf("Expected error in column %q, got %q", tt.wantColumn, rowErr.Column)
+			}
+		})
+	}
+}
+
+func TestStreamKlinesCSV(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "klines.csv.gz")
+	if err := WriteKlinesToCSV(testKlines(), filename); err != nil {
+		t.Fatalf("WriteKlinesToCSV failed: %v", err)
+	}
+
+	klineCh, errCh := StreamKlinesCSV(context.Background(), filename)
+	count := 0
+	for range klineCh {
+		count++
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Unexpected stream error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 klines, got %d", count)
+	}
+
+	// Canceling the context stops the stream early
+	ctx, cancel := context.WithCancel(context.Background())
+	klineCh, errCh = StreamKlinesCSV(ctx, filename)
+	<-klineCh
+	cancel()
+	for range klineCh {
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestTradesCSVRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "trades.csv.gz")
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	want := []*domain.Trade{
+		{PositionID: 1, Symbol: "ETHUSDT", EntryPrice: 2000, ExitPrice: 2050, Quantity: 0.5, Leverage: 3, PNL: 25,
+			EntryTime: now, ExitTime: now.Add(time.Hour), CloseReason: domain.CloseReasonTakeProfit, MAE: 0.004, MFE: 0.031,
+			Regime:   domain.MarketRegime{Trend: domain.TrendRegimeTrending, Volatility: domain.VolatilityRegimeHigh, HigherTF: domain.TrendDirectionUp},
+			EntryTag: domain.EntryTag{EntryReason: "MA crossover", SignalSource: domain.SignalSourceCrossover, ConfirmationCount: 4, EntryATR: 12.5}},
+	}
+	if err := WriteTradesToCSV(want, filename); err != nil {
+		t.Fatalf("WriteTradesToCSV failed: %v", err)
+	}
+
+	got, err := ReadTradesFromCSV(filename)
+	if err != nil {
+		t.Fatalf("ReadTradesFromCSV failed: %v", err)
+	}
+	if len(got) != 1 || got[0].PNL != 25 || got[0].Leverage != 3 || got[0].CloseReason != domain.CloseReasonTakeProfit {
+		t.Errorf("Unexpected trades: %+v", got)
+	}
+	if len(got) == 1 && got[0].EntryTag != want[0].EntryTag {
+		t.Errorf("Expected entry tag %+v, got %+v", want[0].EntryTag, got[0].EntryTag)
+	}
+	if len(got) == 1 && (got[0].MAE != 0.004 || got[0].MFE != 0.031) {
+		t.Errorf("Expected MAE 0.004 and MFE 0.031, got %f and %f", got[0].MAE, got[0].MFE)
+	}
+	if len(got) == 1 && got[0].Regime != want[0].Regime {
+		t.Errorf("Expected regime %v, got %v", want[0].Regime, got[0].Regime)
+	}
+}
+
+func TestReadLegacyTradesCSV(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "trades.csv")
+	content := strings.Join(TradeCSVHeader[:10], ",") + "\n" +
+		"1,ETHUSDT,2000,2050,0.5,3,25,2025-05-01T12:00:00Z,2025-05-01T13:00:00Z,TAKE_PROFIT\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadTradesFromCSV(filename)
+	if err != nil {
+		t.Fatalf("ReadTradesFromCSV failed: %v", err)
+	}
+	if len(got) != 1 || got[0].PNL != 25 || got[0].SignalSource != domain.SignalSourceUnknown {
+		t.Errorf("Unexpected trades: %+v", got)
+	}
+
+	// Tagged files written before MAE/MFE were added
+	tagged := strings.Join(TradeCSVHeader[:14], ",") + "\n" +
+		"1,ETHUSDT,2000,2050,0.5,3,25,2025-05-01T12:00:00Z,2025-05-01T13:00:00Z,TAKE_PROFIT,MA crossover,crossover,4,12.5\n"
+	if err := os.WriteFile(filename, []byte(tagged), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ReadTradesFromCSV(filename)
+	if err != nil {
+		t.Fatalf("ReadTradesFromCSV failed: %v", err)
+	}
+	if len(got) != 1 || got[0].ConfirmationCount != 4 || got[0].MAE != 0 {
+		t.Errorf("Unexpected trades: %+v", got)
+	}
+
+	// Files written before regimes were added
+	excursions := strings.Join(TradeCSVHeader[:16], ",") + "\n" +
+		"1,ETHUSDT,2000,2050,0.5,3,25,2025-05-01T12:00:00Z,2025-05-01T13:00:00Z,TAKE_PROFIT,MA crossover,crossover,4,12.5,0.004,0.031\n"
+	if err := os.WriteFile(filename, []byte(excursions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ReadTradesFromCSV(filename)
+	if err != nil {
+		t.Fatalf("ReadTradesFromCSV failed: %v", err)
+	}
+	if len(got) != 1 || got[0].MFE != 0.031 || !got[0].Regime.IsZero() {
+		t.Errorf("Unexpected trades: %+v", got)
+	}
+
+	// Fewer columns than the legacy format are still rejected
+	short := strings.Join(TradeCSVHeader[:9], ",") + "\n"
+	if err := os.WriteFile(filename, []byte(short), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTradesFromCSV(filename); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("Expected ErrInvalidHeader, got %v", err)
+	}
+}
+
+func TestMarketDataCSVRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	rates := []*domain.FundingRate{
+		{Symbol: "ETHUSDT", FundingTime: now, Rate: 0.0001, MarkPrice: 2000.5},
+		{Symbol: "ETHUSDT", FundingTime: now.Add(8 * time.Hour), Rate: -0.00005},
+	}
+	ratesFile := filepath.Join(dir, "funding.csv.gz")
+	if err := WriteFundingRatesToCSV(rates, ratesFile); err != nil {
+		t.Fatalf("WriteFundingRatesToCSV failed: %v", err)
+	}
+	gotRates, err := ReadFundingRatesFromCSV(ratesFile)
+	if err != nil {
+		t.Fatalf("ReadFundingRatesFromCSV failed: %v", err)
+	}
+	if len(gotRates) != 2 || *gotRates[0] != *rates[0] || gotRates[1].Rate != -0.00005 || !gotRates[1].FundingTime.Equal(rates[1].FundingTime) {
+		t.Errorf("Unexpected funding rates: %+v", gotRates)
+	}
+
+	snapshots := []*domain.OpenInterest{{Symbol: "ETHUSDT", Time: now, Period: "1h", Contracts: 1500.25, Value: 3000500}}
+	oiFile := filepath.Join(dir, "oi.csv")
+	if err := WriteOpenInterestToCSV(snapshots, oiFile); err != nil {
+		t.Fatalf("WriteOpenInterestToCSV failed: %v", err)
+	}
+	gotOI, err := ReadOpenInterestFromCSV(oiFile)
+	if err != nil {
+		t.Fatalf("ReadOpenInterestFromCSV failed: %v", err)
+	}
+	if len(gotOI) != 1 || *gotOI[0] != *snapshots[0] {
+		t.Errorf("Unexpected open interest: %+v", gotOI)
+	}
+
+	// An open interest file isn't mistaken for funding rates
+	if _, err := ReadFundingRatesFromCSV(oiFile); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("Expected ErrInvalidHeader, got %v", err)
+	}
+}
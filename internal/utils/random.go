@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"math/rand"
+	"time"
+)
+
+// NewSeed returns a fresh seed derived from the current time, for runs that don't specify one
+func NewSeed() int64 {
+	seed := time.Now().UnixNano()
+	if seed == 0 {
+		seed = 1
+	}
+	return seed
+}
+
+// ResolveSeed returns seed unchanged when set, otherwise a fresh seed from NewSeed.
+// The resolved seed should be recorded with the run's results so it can be reproduced.
+func ResolveSeed(seed int64) int64 {
+	if seed != 0 {
+		return seed
+	}
+	return NewSeed()
+}
+
+// NewRand creates a deterministic random source for the given seed.
+// The returned *rand.Rand is not safe for concurrent use; derive one per goroutine with DeriveSeed.
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// DeriveSeed deterministically derives an independent sub-seed (e.g., per optimization run or
+// Monte Carlo path) from a parent seed, so results don't depend on goroutine scheduling
+func DeriveSeed(seed int64, index int) int64 {
+	// SplitMix64 finalizer to decorrelate neighbouring indexes
+	z := uint64(seed) + uint64(index+1)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	z ^= z >> 31
+	derived := int64(z)
+	if derived == 0 {
+		derived = 1
+	}
+	return derived
+}
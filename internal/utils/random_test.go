@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestNewRandIsDeterministic(t *testing.T) {
+	a, b := NewRand(42), NewRand(42)
+	for i := 0; i < 10; i++ {
+		if x, y := a.Float64(), b.Float64(); x != y {
+			t.Fatalf("Draw %d differs for the same seed: %f vs %f", i, x, y)
+		}
+	}
+}
+
+func TestResolveSeed(t *testing.T) {
+	if got := ResolveSeed(7); got != 7 {
+		t.Errorf("Expected explicit seed to be kept, got %d", got)
+	}
+	if got := ResolveSeed(0); got == 0 {
+		t.Error("Expected a non-zero seed to be generated")
+	}
+}
+
+func TestDeriveSeed(t *testing.T) {
+	if DeriveSeed(42, 3) != DeriveSeed(42, 3) {
+		t.Error("Expected derived seeds to be deterministic")
+	}
+	seen := make(map[int64]bool)
+	for i := 0; i < 100; i++ {
+		s := DeriveSeed(42, i)
+		if s == 0 || seen[s] {
+			t.Fatalf("Derived seed %d for index %d is zero or repeated", s, i)
+		}
+		seen[s] = true
+	}
+}
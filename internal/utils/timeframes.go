@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ParseInterval converts a Binance kline interval name (e.g., "1m", "4h", "1d", "1w") to its duration
+func ParseInterval(interval string) (time.Duration, error) {
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+	var unit time.Duration
+	switch interval[len(interval)-1] {
+	case 'm':
+		unit = time.Minute
+	case 'h':
+		unit = time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid interval %q: unit must be m, h, d or w", interval)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// TimeframeAlignment maps the bars of a base timeframe to the bars of higher timeframes, as
+// returned by AlignTimeframes
+type TimeframeAlignment struct {
+	Base      string                   // Interval of the base (shortest) timeframe
+	Intervals map[string]time.Duration // Duration of every interval, including the base
+	// Containing maps each higher interval to, for every base bar, the index of the higher bar
+	// whose period contains the base bar's open time
+	Containing map[string][]int
+	// Closed maps each higher interval to, for every base bar, the index of the last higher bar
+	// that has closed when the base bar closes (-1 if none has), i.e. the latest bar a strategy
+	// may look at without lookahead
+	Closed map[string][]int
+}
+
+// AlignTimeframes checks that klines in several intervals cover the same period consistently and
+// maps each bar of the shortest interval to the corresponding bars of the others. Every series
+// must be non-empty and ordered by open time without duplicates, every interval must be a
+// multiple of the base interval, and every base bar must fall within a bar of each higher interval
+func AlignTimeframes(klines map[string][]*domain.Kline) (*TimeframeAlignment, error) {
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no timeframes to align")
+	}
+
+	alignment := &TimeframeAlignment{
+		Intervals:  make(map[string]time.Duration, len(klines)),
+		Containing: make(map[string][]int, len(klines)-1),
+		Closed:     make(map[string][]int, len(klines)-1),
+	}
+	intervals := make([]string, 0, len(klines))
+	for interval, series := range klines {
+		duration, err := ParseInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+		if len(series) == 0 {
+			return nil, fmt.Errorf("no %s klines", interval)
+		}
+		for i := 1; i < len(series); i++ {
+			if !series[i].OpenTime.After(series[i-1].OpenTime) {
+				return nil, fmt.Errorf("%s klines are not ordered by open time: %s at index %d follows %s",
+					interval, series[i].OpenTime.Format(time.RFC3339), i, series[i-1].OpenTime.Format(time.RFC3339))
+			}
+		}
+		alignment.Intervals[interval] = duration
+		intervals = append(intervals, interval)
+	}
+	// Shortest first; names break ties so the base is deterministic
+	sort.Slice(intervals, func(i, j int) bool {
+		di, dj := alignment.Intervals[intervals[i]], alignment.Intervals[intervals[j]]
+		if di != dj {
+			return di < dj
+		}
+		return intervals[i] < intervals[j]
+	})
+	alignment.Base = intervals[0]
+	baseDuration := alignment.Intervals[alignment.Base]
+	base := klines[alignment.Base]
+
+	for _, interval := range intervals[1:] {
+		duration := alignment.Intervals[interval]
+		if duration == baseDuration || duration%baseDuration != 0 {
+			return nil, fmt.Errorf("interval %s is not a multiple of the base interval %s", interval, alignment.Base)
+		}
+		higher := klines[interval]
+		containing := make([]int, len(base))
+		closed := make([]int, len(base))
+		j := 0
+		for i, k := range base {
+			for j < len(higher) && !k.OpenTime.Before(higher[j].OpenTime.Add(duration)) {
+				j++
+			}
+			if j == len(higher) || k.OpenTime.Before(higher[j].OpenTime) {
+				return nil, fmt.Errorf("%s kline at %s is not covered by the %s klines",
+					alignment.Base, k.OpenTime.Format(time.RFC3339), interval)
+			}
+			containing[i] = j
+			closed[i] = j - 1
+			if !k.OpenTime.Add(baseDuration).Before(higher[j].OpenTime.Add(duration)) {
+				closed[i] = j // The base bar closes together with the higher bar
+			}
+		}
+		alignment.Containing[interval] = containing
+		alignment.Closed[interval] = closed
+	}
+	return alignment, nil
+}
+
+// ClosedKlines returns, for the base bar at index i, the klines of every interval that have
+// closed by the time it closes: base klines up to and including i and higher klines up to their
+// Closed index. The result is the data a multi-timeframe strategy may see at that bar
+func (a *TimeframeAlignment) ClosedKlines(klines map[string][]*domain.Kline, i int) map[string][]*domain.Kline {
+	data := make(map[string][]*domain.Kline, len(klines))
+	data[a.Base] = klines[a.Base][:i+1]
+	for interval, closed := range a.Closed {
+		data[interval] = klines[interval][:closed[i]+1]
+	}
+	return data
+}
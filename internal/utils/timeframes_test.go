@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"cryptoMegaBot/internal/domain"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// intervalKlines returns n consecutive klines of the given interval starting at start
+func intervalKlines(start time.Time, interval string, n int) []*domain.Kline {
+	duration, _ := ParseInterval(interval)
+	klines := make([]*domain.Kline, n)
+	for i := range klines {
+		open := start.Add(time.Duration(i) * duration)
+		klines[i] = &domain.Kline{OpenTime: open, CloseTime: open.Add(duration - time.Millisecond), Interval: interval, Close: float64(i)}
+	}
+	return klines
+}
+
+func TestParseInterval(t *testing.T) {
+	want := map[string]time.Duration{"1m": time.Minute, "15m": 15 * time.Minute, "4h": 4 * time.Hour, "1d": 24 * time.Hour, "1w": 7 * 24 * time.Hour}
+	for name, duration := range want {
+		if got, err := ParseInterval(name); err != nil || got != duration {
+			t.Errorf("ParseInterval(%q) = %v, %v, want %v", name, got, err, duration)
+		}
+	}
+	for _, name := range []string{"", "m", "0h", "-1m", "5s", "1M5"} {
+		if _, err := ParseInterval(name); err == nil {
+			t.Errorf("ParseInterval(%q) should fail", name)
+		}
+	}
+}
+
+func TestAlignTimeframes(t *testing.T) {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	klines := map[string][]*domain.Kline{
+		"5m":  intervalKlines(start, "5m", 24),
+		"15m": intervalKlines(start, "15m", 8),
+		"1h":  intervalKlines(start, "1h", 2),
+	}
+	alignment, err := AlignTimeframes(klines)
+	if err != nil {
+		t.Fatalf("AlignTimeframes failed: %v", err)
+	}
+	if alignment.Base != "5m" {
+		t.Errorf("Base = %s, want 5m", alignment.Base)
+	}
+
+	wantContaining15m := []int{0, 0, 0, 1, 1, 1, 2, 2, 2, 3, 3, 3, 4, 4, 4, 5, 5, 5, 6, 6, 6, 7, 7, 7}
+	wantClosed15m := []int{-1, -1, 0, 0, 0, 1, 1, 1, 2, 2, 2, 3, 3, 3, 4, 4, 4, 5, 5, 5, 6, 6, 6, 7}
+	if !reflect.DeepEqual(alignment.Containing["15m"], wantContaining15m) {
+		t.Errorf("Containing[15m] = %v, want %v", alignment.Containing["15m"], wantContaining15m)
+	}
+	if !reflect.DeepEqual(alignment.Closed["15m"], wantClosed15m) {
+		t.Errorf("Closed[15m] = %v, want %v", alignment.Closed["15m"], wantClosed15m)
+	}
+	if got := alignment.Closed["1h"]; got[10] != -1 || got[11] != 0 || got[12] != 0 || got[23] != 1 {
+		t.Errorf("Closed[1h] = %v, want the first hour closed from bar 11 and the second at bar 23", got)
+	}
+	if _, ok := alignment.Containing["5m"]; ok {
+		t.Error("the base interval should not be mapped to itself")
+	}
+
+	data := alignment.ClosedKlines(klines, 12)
+	if len(data["5m"]) != 13 || len(data["15m"]) != 4 || len(data["1h"]) != 1 {
+		t.Errorf("ClosedKlines(12) returned %d/%d/%d klines, want 13/4/1", len(data["5m"]), len(data["15m"]), len(data["1h"]))
+	}
+}
+
+func TestAlignTimeframesErrors(t *testing.T) {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	gapped := intervalKlines(start, "15m", 8)
+	gapped = append(gapped[:3], gapped[4:]...)
+	unordered := intervalKlines(start, "15m", 8)
+	unordered[2], unordered[3] = unordered[3], unordered[2]
+
+	tests := []struct {
+		name    string
+		klines  map[string][]*domain.Kline
+		wantErr string
+	}{
+		{"no timeframes", map[string][]*domain.Kline{}, "no timeframes"},
+		{"invalid interval", map[string][]*domain.Kline{"5x": intervalKlines(start, "5m", 3)}, "invalid interval"},
+		{"empty series", map[string][]*domain.Kline{"5m": intervalKlines(start, "5m", 3), "15m": nil}, "no 15m klines"},
+		{"not a multiple", map[string][]*domain.Kline{"10m": intervalKlines(start, "10m", 3), "15m": intervalKlines(start, "15m", 2)}, "not a multiple"},
+		{"gap in the higher timeframe", map[string][]*domain.Kline{"5m": intervalKlines(start, "5m", 24), "15m": gapped}, "not covered by the 15m klines"},
+		{"base starts earlier", map[string][]*domain.Kline{"5m": intervalKlines(start, "5m", 24), "1h": intervalKlines(start.Add(time.Hour), "1h", 1)}, "not covered by the 1h klines"},
+		{"base ends later", map[string][]*domain.Kline{"5m": intervalKlines(start, "5m", 24), "1h": intervalKlines(start, "1h", 1)}, "not covered by the 1h klines"},
+		{"unordered", map[string][]*domain.Kline{"5m": intervalKlines(start, "5m", 24), "15m": unordered}, "not ordered"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := AlignTimeframes(tt.klines)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AlignTimeframes() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,15 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log" // Use standard log only for initial fatal errors before logger is set up
+	"math"
 
 	"cryptoMegaBot/config"
 	"cryptoMegaBot/internal/adapters/binanceclient"
+	"cryptoMegaBot/internal/adapters/controlapi"
+	"cryptoMegaBot/internal/adapters/email"
+	"cryptoMegaBot/internal/adapters/extstrategy"
+	"cryptoMegaBot/internal/adapters/grpcapi"
 	"cryptoMegaBot/internal/adapters/logger"
 	"cryptoMegaBot/internal/adapters/sqlite"
+	"cryptoMegaBot/internal/adapters/telegram"
 	"cryptoMegaBot/internal/app"
+	"cryptoMegaBot/internal/dataquality"
 	"cryptoMegaBot/internal/ports"
+	"cryptoMegaBot/internal/risk"
 	"cryptoMegaBot/internal/strategy"
+	"cryptoMegaBot/internal/strategy/strategies"
 )
 
 func main() {
@@ -22,7 +33,23 @@ func main() {
 
 	// 2. Initialize Logger
 	appLogger := logger.NewStdLogger(cfg.LogLevel)
+	var logHistory *logger.History
+	if cfg.ControlAPIAddr != "" {
+		// Recent log lines for the control API's web dashboard
+		logHistory = logger.NewHistory(dashboardLogHistory)
+		appLogger.SetHistory(logHistory)
+	}
 	appLogger.Info(context.Background(), "Logger initialized", map[string]interface{}{"level": cfg.LogLevel.String()})
+	if cfg.SymbolOverridesFile != "" {
+		appLogger.Info(context.Background(), "Per-symbol overrides applied", map[string]interface{}{
+			"file":           cfg.SymbolOverridesFile,
+			"symbol":         cfg.Symbol,
+			"leverage":       cfg.Leverage,
+			"quantity":       cfg.Quantity,
+			"quantityMode":   cfg.QuantityMode,
+			"strategyParams": cfg.StrategyParams,
+		})
+	}
 
 	// 3. Initialize Repository (Database Adapter)
 	repo, err := sqlite.NewRepository(sqlite.Config{
@@ -48,6 +75,7 @@ func main() {
 		Logger:               appLogger,
 		ReconnectDelay:       cfg.ReconnectDelay,
 		MaxReconnectAttempts: cfg.MaxReconnectAttempts,
+		ContractTypes:        cfg.ContractTypes(),
 	})
 	if err != nil {
 		appLogger.Error(context.Background(), err, "FATAL: Failed to initialize Binance client")
@@ -56,15 +84,17 @@ func main() {
 	appLogger.Info(context.Background(), "Binance client initialized")
 
 	// 5. Initialize Strategy
-	var strat ports.Strategy
-	strat, err = strategy.New(strategy.Config{
-		ShortTermMAPeriod: cfg.StrategyShortMAPeriod,
-		LongTermMAPeriod:  cfg.StrategyLongMAPeriod,
-		EMAPeriod:         cfg.StrategyEMAPeriod,
-		RSIPeriod:         cfg.StrategyRSIPeriod,
-		RSIOverbought:     cfg.StrategyRSIOverbought,
-		RSIOversold:       cfg.StrategyRSIOversold,
-	}, appLogger)
+	// The registry lets the control API switch strategies at runtime; the configured strategy starts first
+	registry, err := strategyRegistry(cfg, appLogger)
+	if err != nil {
+		appLogger.Error(context.Background(), err, "FATAL: Failed to register trading strategies")
+		log.Fatalf("FATAL: Failed to register trading strategies: %v", err)
+	}
+	startStrategy := defaultStrategy
+	if len(cfg.ExternalStrategyCommand) > 0 {
+		startStrategy = externalStrategy
+	}
+	strat, err := registry.New(startStrategy, nil)
 	if err != nil {
 		appLogger.Error(context.Background(), err, "FATAL: Failed to initialize trading strategy")
 		log.Fatalf("FATAL: Failed to initialize trading strategy: %v", err)
@@ -72,6 +102,337 @@ func main() {
 	appLogger.Info(context.Background(), "Trading strategy initialized")
 
 	// 6. Initialize Application Service
+	serviceOpts := []app.Option{
+		app.WithStateRepository(repo),       // Restores strategy risk state across restarts (if supported)
+		app.WithEntryIntentRepository(repo), // Prevents double entries after a crash mid-entry
+		app.WithOrderLog(repo),              // Audit trail of the orders sent to the exchange
+		app.WithStrategyRegistry(registry, startStrategy),
+	}
+	if cfg.ControlAPIAddr != "" {
+		serviceOpts = append(serviceOpts, app.WithEquityHistory()) // Equity curve for the dashboard
+	}
+	if cfg.KillSwitchMaxDrawdown > 0 || cfg.KillSwitchMaxLosingDays > 0 {
+		serviceOpts = append(serviceOpts, app.WithKillSwitch(risk.NewKillSwitch(risk.KillSwitchConfig{
+			MaxDrawdown:   cfg.KillSwitchMaxDrawdown,
+			MaxLosingDays: cfg.KillSwitchMaxLosingDays,
+			CoolDown:      cfg.KillSwitchCoolDown,
+		})))
+		appLogger.Info(context.Background(), "Equity kill switch configured", map[string]interface{}{
+			"maxDrawdown":   cfg.KillSwitchMaxDrawdown,
+			"maxLosingDays": cfg.KillSwitchMaxLosingDays,
+			"coolDown":      cfg.KillSwitchCoolDown.String(),
+		})
+	}
+	if cfg.EquityTrailTarget > 0 {
+		trail, err := risk.NewEquityTrail(risk.EquityTrailConfig{Target: cfg.EquityTrailTarget, Giveback: cfg.EquityTrailGiveback})
+		if err != nil {
+			log.Fatalf("FATAL: Invalid equity trail configuration: %v", err)
+		}
+		serviceOpts = append(serviceOpts, app.WithEquityTrail(trail))
+		appLogger.Info(context.Background(), "Equity trail configured", map[string]interface{}{
+			"target":   cfg.EquityTrailTarget,
+			"giveback": cfg.EquityTrailGiveback,
+		})
+	}
+	if cfg.BreakerMaxFailures > 0 {
+		breaker, err := risk.NewCircuitBreaker(risk.CircuitBreakerConfig{
+			MaxFailures: cfg.BreakerMaxFailures,
+			Window:      cfg.BreakerWindow,
+			OpenFor:     cfg.BreakerOpenFor,
+		})
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to initialize order circuit breaker")
+			log.Fatalf("FATAL: Failed to initialize order circuit breaker: %v", err)
+		}
+		serviceOpts = append(serviceOpts, app.WithCircuitBreaker(breaker))
+		appLogger.Info(context.Background(), "Order circuit breaker configured", map[string]interface{}{
+			"maxFailures": cfg.BreakerMaxFailures,
+			"window":      cfg.BreakerWindow.String(),
+			"openFor":     cfg.BreakerOpenFor.String(),
+		})
+	}
+	if cfg.MaxDailyNotional > 0 || cfg.MaxDailyVolume > 0 {
+		serviceOpts = append(serviceOpts, app.WithDailyVolumeCap(risk.NewDailyVolumeCap(risk.DailyVolumeConfig{
+			MaxNotional: cfg.MaxDailyNotional,
+			MaxVolume:   cfg.MaxDailyVolume,
+		}), repo))
+		appLogger.Info(context.Background(), "Daily volume caps configured", map[string]interface{}{
+			"maxNotional": cfg.MaxDailyNotional,
+			"maxVolume":   cfg.MaxDailyVolume,
+		})
+	}
+	if len(cfg.DrawdownThrottle) > 0 || cfg.MaxVolumeShare > 0 || cfg.MaxExposurePct > 0 {
+		serviceOpts = append(serviceOpts, app.WithRiskManager(risk.NewRiskManager(risk.RiskConfig{
+			DrawdownThrottle: cfg.DrawdownThrottle,
+			MaxVolumeShare:   cfg.MaxVolumeShare,
+			MaxExposurePct:   cfg.MaxExposurePct,
+		})))
+		appLogger.Info(context.Background(), "Risk manager position sizing configured", map[string]interface{}{
+			"drawdownThrottle": cfg.DrawdownThrottle,
+			"maxVolumeShare":   cfg.MaxVolumeShare,
+			"maxExposurePct":   cfg.MaxExposurePct,
+		})
+	}
+	if len(cfg.StreakLadder) > 0 {
+		serviceOpts = append(serviceOpts, app.WithStreakSizing(risk.NewStreakSizer(cfg.StreakLadder)))
+		appLogger.Info(context.Background(), "Win/loss streak sizing configured", map[string]interface{}{
+			"ladder": cfg.StreakLadder.String(),
+		})
+	}
+	if cfg.TradingCalendar != nil {
+		serviceOpts = append(serviceOpts, app.WithTradingCalendar(cfg.TradingCalendar))
+		appLogger.Info(context.Background(), "Trading calendar configured", map[string]interface{}{
+			"calendar": cfg.TradingCalendar.String(),
+		})
+	}
+	if cfg.LiquidityMaxSpreadPct > 0 || cfg.LiquidityMinDepth > 0 {
+		serviceOpts = append(serviceOpts, app.WithLiquidityFilter(strategies.NewLiquidityFilter(strategies.LiquidityFilterConfig{
+			MaxSpreadPct: cfg.LiquidityMaxSpreadPct,
+			MinDepth:     cfg.LiquidityMinDepth,
+			DepthLevels:  cfg.LiquidityDepthLevels,
+		})))
+		appLogger.Info(context.Background(), "Order book liquidity filter configured", map[string]interface{}{
+			"maxSpreadPct": cfg.LiquidityMaxSpreadPct,
+			"minDepth":     cfg.LiquidityMinDepth,
+			"depthLevels":  cfg.LiquidityDepthLevels,
+		})
+	}
+	if cfg.MaxSlippageBps > 0 || cfg.MaxEntrySpreadBps > 0 {
+		serviceOpts = append(serviceOpts, app.WithSlippageGuard(app.SlippageGuardConfig{
+			MaxSlippageBps: cfg.MaxSlippageBps,
+			ExitOnSlippage: cfg.SlippageExit,
+			MaxSpreadBps:   cfg.MaxEntrySpreadBps,
+		}))
+		appLogger.Info(context.Background(), "Slippage guard configured", map[string]interface{}{
+			"maxSlippageBps": cfg.MaxSlippageBps,
+			"exit":           cfg.SlippageExit,
+			"maxSpreadBps":   cfg.MaxEntrySpreadBps,
+		})
+	}
+	if cfg.ClockCheckInterval > 0 {
+		clock, err := app.NewClockMonitor(app.ClockMonitorConfig{
+			CheckInterval: cfg.ClockCheckInterval,
+			MaxDrift:      cfg.ClockMaxDrift,
+		}, appLogger, binanceClient)
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to initialize clock drift monitor")
+			log.Fatalf("FATAL: Failed to initialize clock drift monitor: %v", err)
+		}
+		serviceOpts = append(serviceOpts, app.WithClockMonitor(clock))
+		appLogger.Info(context.Background(), "Clock drift monitor configured", map[string]interface{}{
+			"checkInterval": cfg.ClockCheckInterval.String(),
+			"maxDrift":      cfg.ClockMaxDrift.String(),
+		})
+	}
+	if cfg.StreamWatchdog {
+		serviceOpts = append(serviceOpts, app.WithStreamWatchdog(cfg.StreamGapPauseEntries))
+		appLogger.Info(context.Background(), "Kline stream watchdog configured", map[string]interface{}{
+			"pauseEntries": cfg.StreamGapPauseEntries,
+		})
+	}
+	if cfg.KlineAnomalyAction != "off" {
+		detector, err := dataquality.NewDetector(dataquality.Config{
+			Action:        dataquality.Action(cfg.KlineAnomalyAction),
+			JumpSigma:     cfg.KlineAnomalyJumpSigma,
+			ClusterCount:  cfg.KlineAnomalyClusterCount,
+			ClusterWindow: cfg.KlineAnomalyClusterWindow,
+		})
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to initialize kline anomaly detection")
+			log.Fatalf("FATAL: Failed to initialize kline anomaly detection: %v", err)
+		}
+		serviceOpts = append(serviceOpts, app.WithKlineAnomalyDetection(detector))
+		appLogger.Info(context.Background(), "Kline anomaly detection configured", map[string]interface{}{
+			"action":        cfg.KlineAnomalyAction,
+			"jumpSigma":     cfg.KlineAnomalyJumpSigma,
+			"clusterCount":  cfg.KlineAnomalyClusterCount,
+			"clusterWindow": cfg.KlineAnomalyClusterWindow.String(),
+		})
+	}
+	if cfg.LeverageBrackets {
+		serviceOpts = append(serviceOpts, app.WithLeverageBrackets())
+	}
+	if cfg.KlineAggregation {
+		serviceOpts = append(serviceOpts, app.WithKlineAggregation()) // Higher timeframes from the 1m stream
+	}
+	if cfg.ReconnectAlertThreshold > 0 {
+		serviceOpts = append(serviceOpts, app.WithReconnectAlerts(app.ReconnectAlertConfig{
+			Threshold: cfg.ReconnectAlertThreshold,
+			Window:    cfg.ReconnectAlertWindow,
+		}))
+		appLogger.Info(context.Background(), "WebSocket reconnect alerts configured", map[string]interface{}{
+			"threshold": cfg.ReconnectAlertThreshold,
+			"window":    cfg.ReconnectAlertWindow.String(),
+		})
+	}
+	if cfg.LatencyAlertP95 > 0 {
+		serviceOpts = append(serviceOpts, app.WithLatencyAlerts(app.LatencyAlertConfig{P95Threshold: cfg.LatencyAlertP95}))
+		appLogger.Info(context.Background(), "Exchange latency alerts configured", map[string]interface{}{
+			"p95Threshold": cfg.LatencyAlertP95.String(),
+		})
+	}
+	if cfg.KlineCacheSaveInterval > 0 {
+		serviceOpts = append(serviceOpts, app.WithKlineCachePersistence(repo, cfg.KlineCacheSaveInterval)) // Warm start after restarts
+	}
+	if cfg.RecordOrderFills {
+		serviceOpts = append(serviceOpts, app.WithOrderFills(repo))
+	}
+	if cfg.DBMaintenanceInterval > 0 {
+		serviceOpts = append(serviceOpts, app.WithDBMaintenance(repo, app.DBMaintenanceConfig{
+			Interval:       cfg.DBMaintenanceInterval,
+			Retention:      cfg.DBRetention,
+			Vacuum:         cfg.DBMaintenanceVacuum,
+			IntegrityCheck: cfg.DBMaintenanceIntegrityCheck,
+		}))
+	}
+	if cfg.IncomeAccrualInterval > 0 {
+		serviceOpts = append(serviceOpts, app.WithIncomeAccrual(app.IncomeAccrualConfig{Interval: cfg.IncomeAccrualInterval}))
+	}
+	if len(cfg.ReEntry) > 0 {
+		serviceOpts = append(serviceOpts, app.WithReEntryPolicy(cfg.ReEntry))
+		appLogger.Info(context.Background(), "Re-entry rules configured", map[string]interface{}{
+			"closeReasons": len(cfg.ReEntry),
+		})
+	}
+	if cfg.BalanceCheck {
+		serviceOpts = append(serviceOpts, app.WithBalanceCheck(app.BalanceCheckConfig{
+			Asset:        cfg.MarginAsset(),
+			SafetyBuffer: cfg.BalanceSafetyBuffer,
+			MinBalance:   cfg.MinAvailableBalance,
+			Shrink:       cfg.BalanceShrinkEntries,
+		}))
+		appLogger.Info(context.Background(), "Pre-entry balance check configured", map[string]interface{}{
+			"safetyBuffer": cfg.BalanceSafetyBuffer,
+			"minBalance":   cfg.MinAvailableBalance,
+			"shrink":       cfg.BalanceShrinkEntries,
+		})
+	}
+	if cfg.SafeModeFailures > 0 {
+		serviceOpts = append(serviceOpts, app.WithSafeMode(app.SafeModeConfig{
+			CheckInterval:    cfg.SafeModeCheckInterval,
+			FailureThreshold: cfg.SafeModeFailures,
+			RecoveryChecks:   cfg.SafeModeRecovery,
+			Action:           cfg.SafeModeAction,
+			TightenStop:      cfg.SafeModeTightenStop,
+		}, repo))
+		appLogger.Info(context.Background(), "Exchange safe mode configured", map[string]interface{}{
+			"failures":       cfg.SafeModeFailures,
+			"checkInterval":  cfg.SafeModeCheckInterval.String(),
+			"recoveryChecks": cfg.SafeModeRecovery,
+			"action":         cfg.SafeModeAction,
+		})
+	}
+	if cfg.SessionEndEnabled {
+		serviceOpts = append(serviceOpts, app.WithSessionEnd(cfg.SessionEndTime))
+		appLogger.Info(context.Background(), "Session end configured", map[string]interface{}{"atUTC": cfg.SessionEndTime.String()})
+	}
+	if cfg.PositionMaxHoldingTime > 0 {
+		serviceOpts = append(serviceOpts, app.WithMaxHoldingTime(cfg.PositionMaxHoldingTime))
+		appLogger.Info(context.Background(), "Maximum position holding time configured", map[string]interface{}{"maxHolding": cfg.PositionMaxHoldingTime.String()})
+	}
+	if cfg.LimitEntries {
+		serviceOpts = append(serviceOpts, app.WithLimitEntries(app.LimitEntryConfig{
+			Timeout:          cfg.LimitEntryTimeout,
+			ExpiryBars:       cfg.LimitEntryExpiryBars,
+			FallbackToMarket: cfg.LimitEntryFallback,
+			PostOnly:         cfg.LimitEntryPostOnly,
+		}))
+		appLogger.Info(context.Background(), "Limit entries configured", map[string]interface{}{
+			"offset":           cfg.LimitEntryOffset,
+			"expiryBars":       cfg.LimitEntryExpiryBars,
+			"timeout":          cfg.LimitEntryTimeout.String(),
+			"fallbackToMarket": cfg.LimitEntryFallback,
+			"postOnly":         cfg.LimitEntryPostOnly,
+		})
+	}
+	if cfg.Blackout != nil {
+		serviceOpts = append(serviceOpts, app.WithBlackoutSchedule(cfg.Blackout))
+		appLogger.Info(context.Background(), "Blackout windows configured", map[string]interface{}{
+			"file":        cfg.BlackoutFile,
+			"events":      len(cfg.Blackout.Events),
+			"recurring":   len(cfg.Blackout.Recurring),
+			"tightenStop": cfg.Blackout.TightenStop,
+		})
+	}
+	if cfg.ScaleIn.Enabled() {
+		serviceOpts = append(serviceOpts, app.WithScaleIn(cfg.ScaleIn))
+		appLogger.Info(context.Background(), "Scale-in entries configured", map[string]interface{}{
+			"initialFraction": cfg.ScaleIn.InitialFraction,
+			"steps":           cfg.ScaleIn.Steps,
+		})
+	}
+	var notifiers app.MultiNotifier
+	if cfg.TelegramBotToken != "" {
+		telegramNotifier, err := telegram.New(telegram.Config{
+			BotToken: cfg.TelegramBotToken,
+			ChatID:   cfg.TelegramChatID,
+			Logger:   appLogger,
+		})
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to initialize Telegram notifier")
+			log.Fatalf("FATAL: Failed to initialize Telegram notifier: %v", err)
+		}
+		notifiers = append(notifiers, telegramNotifier)
+		appLogger.Info(context.Background(), "Telegram notifier configured")
+	}
+	if cfg.SMTPHost != "" {
+		emailNotifier, err := email.New(email.Config{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			TLSMode:  email.TLSMode(cfg.SMTPTLS),
+			From:     cfg.SMTPFrom,
+			To:       cfg.SMTPTo,
+			Logger:   appLogger,
+		})
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to initialize email notifier")
+			log.Fatalf("FATAL: Failed to initialize email notifier: %v", err)
+		}
+		notifiers = append(notifiers, emailNotifier)
+		appLogger.Info(context.Background(), "Email notifier configured", map[string]interface{}{"host": cfg.SMTPHost, "recipients": len(cfg.SMTPTo)})
+	}
+	var notifier ports.Notifier
+	switch len(notifiers) {
+	case 0:
+	case 1:
+		notifier = notifiers[0]
+	default:
+		notifier = notifiers
+	}
+	if notifier != nil {
+		serviceOpts = append(serviceOpts, app.WithNotifier(notifier))
+	}
+	var converter *app.CurrencyConverter
+	if cfg.ReportCurrency != "" {
+		// Amounts are in the margin asset; rates come from the exchange's tickers
+		converter, err = app.NewCurrencyConverter(binanceClient, cfg.MarginAsset(), cfg.ReportCurrency)
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to initialize currency converter")
+			log.Fatalf("FATAL: Failed to initialize currency converter: %v", err)
+		}
+		serviceOpts = append(serviceOpts, app.WithCurrencyConverter(converter))
+		appLogger.Info(context.Background(), "Reporting currency configured", map[string]interface{}{"currency": cfg.ReportCurrency})
+	}
+	if cfg.DailyReportEnabled {
+		reporter, err := app.NewDailyReporter(app.DailyReportConfig{
+			Symbol:       cfg.Symbol,
+			At:           cfg.DailyReportTime,
+			FeeRate:      cfg.ReportFeeRate,
+			BalanceAsset: cfg.MarginAsset(),
+			Converter:    converter,
+		}, appLogger, binanceClient, repo, repo, notifier)
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to initialize daily reporter")
+			log.Fatalf("FATAL: Failed to initialize daily reporter: %v", err)
+		}
+		serviceOpts = append(serviceOpts, app.WithDailyReporter(reporter))
+		appLogger.Info(context.Background(), "Daily report configured", map[string]interface{}{
+			"atUTC":    cfg.DailyReportTime.String(),
+			"notifier": notifier != nil,
+		})
+	}
 	tradingService, err := app.NewTradingService(
 		cfg,
 		appLogger,
@@ -79,14 +440,69 @@ func main() {
 		repo,          // Pass the concrete implementation, service expects the interface
 		repo,          // Pass the concrete implementation, service expects the interface
 		strat,
+		serviceOpts...,
 	)
 	if err != nil {
 		appLogger.Error(context.Background(), err, "FATAL: Failed to initialize trading service")
 		log.Fatalf("FATAL: Failed to initialize trading service: %v", err)
 	}
 	appLogger.Info(context.Background(), "Trading service initialized")
+	if cfg.EventAuditLog {
+		tradingService.Events().Subscribe(app.LogEvents(appLogger))
+		appLogger.Info(context.Background(), "Trading event audit log enabled")
+	}
+
+	// 7. Start the Control API (optional)
+	if cfg.ControlAPIAddr != "" {
+		controlServer, err := controlapi.New(controlapi.Config{
+			Addr:       cfg.ControlAPIAddr,
+			Token:      cfg.ControlAPIToken,
+			Controller: tradingService,
+			Logger:     appLogger,
+			Orders:     repo,
+			Dashboard:  tradingService,
+			Logs:       logHistory,
+		})
+		if err == nil {
+			err = controlServer.Start()
+		}
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to start control API")
+			log.Fatalf("FATAL: Failed to start control API: %v", err)
+		}
+		defer func() {
+			if err := controlServer.Shutdown(context.Background()); err != nil {
+				appLogger.Error(context.Background(), err, "Error shutting down control API")
+			}
+		}()
+	}
 
-	// 7. Start the Service
+	// 7.1 Start the gRPC API (optional)
+	if cfg.GRPCAPIAddr != "" {
+		grpcServer, err := grpcapi.New(grpcapi.Config{
+			Addr:       cfg.GRPCAPIAddr,
+			Token:      cfg.ControlAPIToken,
+			Controller: tradingService,
+			Trades:     repo,
+			Events:     tradingService.Events(),
+			Symbol:     cfg.Symbol,
+			Logger:     appLogger,
+		})
+		if err == nil {
+			err = grpcServer.Start()
+		}
+		if err != nil {
+			appLogger.Error(context.Background(), err, "FATAL: Failed to start gRPC API")
+			log.Fatalf("FATAL: Failed to start gRPC API: %v", err)
+		}
+		defer func() {
+			if err := grpcServer.Shutdown(context.Background()); err != nil {
+				appLogger.Error(context.Background(), err, "Error shutting down gRPC API")
+			}
+		}()
+	}
+
+	// 8. Start the Service
 	// Use context.Background() as the base context for the application run
 	if err := tradingService.Start(context.Background()); err != nil {
 		appLogger.Error(context.Background(), err, "Trading service exited with error")
@@ -95,3 +511,187 @@ func main() {
 
 	appLogger.Info(context.Background(), "Application finished gracefully.")
 }
+
+// defaultStrategy is the registered strategy the bot starts with
+const defaultStrategy = "ma_crossover"
+
+// externalStrategy is the registered name of the external strategy process, which the bot starts
+// with instead when EXTERNAL_STRATEGY_COMMAND is set
+const externalStrategy = "external"
+
+// metaStrategy is the registered name of the voting meta strategy built from META_STRATEGY_*
+const metaStrategy = "meta"
+
+// dashboardLogHistory is the number of recent log lines kept for the dashboard
+const dashboardLogHistory = 200
+
+// strategyRegistry registers the strategies the control API can switch between. Switch
+// parameters override the configured values; unknown parameters are rejected
+func strategyRegistry(cfg *config.Config, appLogger ports.Logger) (*app.StrategyRegistry, error) {
+	registry := app.NewStrategyRegistry()
+	err := registry.Register("ma_crossover", func(params map[string]float64) (ports.Strategy, error) {
+		params = mergeStrategyParams(cfg.StrategyParams["ma_crossover"], params)
+		strategyCfg := strategy.Config{
+			ShortTermMAPeriod: cfg.StrategyShortMAPeriod,
+			LongTermMAPeriod:  cfg.StrategyLongMAPeriod,
+			EMAPeriod:         cfg.StrategyEMAPeriod,
+			RSIPeriod:         cfg.StrategyRSIPeriod,
+			RSIOverbought:     cfg.StrategyRSIOverbought,
+			RSIOversold:       cfg.StrategyRSIOversold,
+
+			BreakEvenActivation: cfg.BreakEvenActivation,
+			Fees:                cfg.FeeModel(),
+		}
+		err := applyStrategyParams(params, map[string]*int{
+			"shortMAPeriod": &strategyCfg.ShortTermMAPeriod,
+			"longMAPeriod":  &strategyCfg.LongTermMAPeriod,
+			"emaPeriod":     &strategyCfg.EMAPeriod,
+			"rsiPeriod":     &strategyCfg.RSIPeriod,
+		}, map[string]*float64{
+			"rsiOverbought":       &strategyCfg.RSIOverbought,
+			"rsiOversold":         &strategyCfg.RSIOversold,
+			"breakEvenActivation": &strategyCfg.BreakEvenActivation,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return strategy.New(strategyCfg, appLogger)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = registry.Register("improved_ma_crossover", func(params map[string]float64) (ports.Strategy, error) {
+		params = mergeStrategyParams(cfg.StrategyParams["improved_ma_crossover"], params)
+		strategyCfg := strategies.MACrossoverConfig{
+			FastMAPeriod:  8,
+			SlowMAPeriod:  21,
+			SignalPeriod:  9,
+			ATRPeriod:     14,
+			ATRMultiplier: 2.5,
+
+			BreakEvenActivation: cfg.BreakEvenActivation,
+			Fees:                cfg.FeeModel(),
+
+			// Entry confirmation weights and thresholds (ENTRY_CONFIRMATIONS / ENTRY_MIN_CONFIRMATION_SCORE)
+			Confirmation: cfg.EntryConfirmation,
+
+			// Volume profile support/resistance zones (VOLUME_PROFILE_*)
+			UseVolumeProfile:     cfg.VolumeProfilePeriod > 0,
+			VolumeProfilePeriod:  cfg.VolumeProfilePeriod,
+			VolumeProfileBuckets: cfg.VolumeProfileBuckets,
+			VolumeProfileZonePct: cfg.VolumeProfileZonePct,
+
+			// Rising open interest required alongside price (OPEN_INTEREST_*)
+			UseOpenInterest:      cfg.OpenInterestConfirmation,
+			OpenInterestPeriod:   cfg.OpenInterestPeriod,
+			OpenInterestLookback: cfg.OpenInterestLookback,
+
+			// Cooldown / fresh crossover required after each close reason (REENTRY_RULES)
+			ReEntry: cfg.ReEntry,
+
+			// Time-based exit after the maximum holding time (TIME_LIMIT_EXIT)
+			DisableTimeLimit: !cfg.TimeLimitExit,
+
+			// Limit entries below the signal price (LIMIT_ENTRIES / LIMIT_ENTRY_*)
+			UseLimitEntries:      cfg.LimitEntries,
+			LimitEntryOffset:     cfg.LimitEntryOffset,
+			LimitEntryExpiryBars: cfg.LimitEntryExpiryBars,
+		}
+		err := applyStrategyParams(params, map[string]*int{
+			"fastMAPeriod": &strategyCfg.FastMAPeriod,
+			"slowMAPeriod": &strategyCfg.SlowMAPeriod,
+			"signalPeriod": &strategyCfg.SignalPeriod,
+			"atrPeriod":    &strategyCfg.ATRPeriod,
+		}, map[string]*float64{
+			"atrMultiplier":       &strategyCfg.ATRMultiplier,
+			"breakEvenActivation": &strategyCfg.BreakEvenActivation,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return strategies.NewImprovedMACrossover(strategyCfg, appLogger)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.ExternalStrategyCommand) > 0 {
+		// Parameters are passed through to the process, which validates them itself
+		err = registry.Register(externalStrategy, func(params map[string]float64) (ports.Strategy, error) {
+			return extstrategy.New(extstrategy.Config{
+				Command: cfg.ExternalStrategyCommand[0],
+				Args:    cfg.ExternalStrategyCommand[1:],
+				Params:  mergeStrategyParams(cfg.StrategyParams[externalStrategy], params),
+				Timeout: cfg.ExternalStrategyTimeout,
+				Logger:  appLogger,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Registered last, so its children can be any of the strategies above
+	err = registry.Register(metaStrategy, func(params map[string]float64) (ports.Strategy, error) {
+		params = mergeStrategyParams(cfg.StrategyParams[metaStrategy], params)
+		metaCfg := strategies.MetaStrategyConfig{
+			Quorum:     cfg.MetaStrategyQuorum,
+			ExitPolicy: cfg.MetaStrategyExitPolicy,
+		}
+		if err := applyStrategyParams(params, nil, map[string]*float64{"quorum": &metaCfg.Quorum}); err != nil {
+			return nil, err
+		}
+
+		meta, err := strategies.NewMetaStrategyFromSpecs(metaCfg, cfg.MetaStrategyChildren, func(name string) (ports.Strategy, error) {
+			if name == metaStrategy {
+				return nil, errors.New("meta strategy cannot include itself")
+			}
+			return registry.New(name, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return meta, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// mergeStrategyParams returns the symbol's configured strategy parameters (SYMBOL_OVERRIDES_FILE)
+// with params, e.g. from a runtime strategy switch, taking precedence
+func mergeStrategyParams(symbolParams, params map[string]float64) map[string]float64 {
+	if len(symbolParams) == 0 {
+		return params
+	}
+	merged := make(map[string]float64, len(symbolParams)+len(params))
+	for name, value := range symbolParams {
+		merged[name] = value
+	}
+	for name, value := range params {
+		merged[name] = value
+	}
+	return merged
+}
+
+// applyStrategyParams overrides the configuration fields named in params. Integer fields only
+// accept whole numbers
+func applyStrategyParams(params map[string]float64, ints map[string]*int, floats map[string]*float64) error {
+	for name, value := range params {
+		if field, ok := ints[name]; ok {
+			if value != math.Trunc(value) {
+				return fmt.Errorf("parameter %s must be a whole number, got %v", name, value)
+			}
+			*field = int(value)
+			continue
+		}
+		if field, ok := floats[name]; ok {
+			*field = value
+			continue
+		}
+		return fmt.Errorf("unknown parameter %s", name)
+	}
+	return nil
+}
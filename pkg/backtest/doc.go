@@ -0,0 +1,11 @@
+// Package backtest is the trade simulation API for use outside this module: it replays klines
+// from any DataFeed through a Strategy and reports the trades and statistics.
+//
+// The Engine fills orders according to an ExecutionModel (fees, funding, slippage and how stops
+// inside a bar are detected), so the same strategy can be run under idealized and realistic
+// frictions. Klines come from a DataFeed rather than files, so callers can load them from any
+// source: a slice (SliceFeed), a channel or a FeedFunc reading a database or a CSV of their own.
+//
+// The types are aliases of the bot's own domain types, so strategies written against this package
+// also run in the live bot.
+package backtest
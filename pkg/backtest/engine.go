@@ -0,0 +1,94 @@
+package backtest
+
+import (
+	"context"
+	"cryptoMegaBot/internal/domain"
+	"cryptoMegaBot/internal/strategy/backtesting"
+	"cryptoMegaBot/internal/strategy/strategies"
+	"fmt"
+)
+
+// Domain types shared with the live bot
+type (
+	Kline       = domain.Kline
+	Position    = domain.Position
+	Trade       = domain.Trade
+	CloseReason = domain.CloseReason
+	FeeModel    = domain.FeeModel
+)
+
+// DefaultFundingInterval is the time between funding payments on Binance perpetual futures
+const DefaultFundingInterval = domain.DefaultFundingInterval
+
+// Strategy decides when the simulated account enters and exits positions. Strategies can also
+// implement EntryOrderProvider to enter with limit orders
+type Strategy = strategies.Strategy
+
+// EntryOrderProvider is implemented by strategies that want to control how entries are executed
+type EntryOrderProvider = strategies.EntryOrderProvider
+
+// EntryOrder describes the order a strategy wants to use for an entry
+type EntryOrder = strategies.EntryOrder
+
+// Config holds the account and position settings of a run. Its fees, slippage and intrabar
+// fills are taken from the engine's ExecutionModel; StartTime and EndTime default to the
+// feed's first and last kline
+type Config = backtesting.BacktestConfig
+
+// Result holds the trades and statistics of a run
+type Result = backtesting.BacktestResult
+
+// Engine runs strategies over kline feeds
+type Engine interface {
+	// Run replays the feed through the strategy. If ctx is canceled mid-run the partial result
+	// is returned together with ctx's error
+	Run(ctx context.Context, strategy Strategy, feed DataFeed) (*Result, error)
+}
+
+// engine is the default Engine
+type engine struct {
+	config Config
+}
+
+// NewEngine creates an engine simulating config's account, filling orders with execution
+// (nil keeps config's own fees, slippage and intrabar settings)
+func NewEngine(config Config, execution ExecutionModel) (Engine, error) {
+	if config.InitialFunds <= 0 {
+		return nil, fmt.Errorf("initial funds must be positive")
+	}
+	if config.Leverage <= 0 {
+		return nil, fmt.Errorf("leverage must be positive")
+	}
+	if execution != nil {
+		config.Fees = execution.Fees()
+		config.Slippage = execution.Slippage()
+		config.Intrabar = execution.Intrabar()
+	}
+	if config.Slippage < 0 {
+		return nil, fmt.Errorf("slippage cannot be negative")
+	}
+	return &engine{config: config}, nil
+}
+
+// Run replays the feed through the strategy
+func (e *engine) Run(ctx context.Context, strategy Strategy, feed DataFeed) (*Result, error) {
+	if strategy == nil || feed == nil {
+		return nil, fmt.Errorf("a strategy and a data feed are required")
+	}
+	klines, err := readFeed(ctx, feed)
+	if err != nil {
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("data feed is empty")
+	}
+
+	config := e.config
+	if config.StartTime.IsZero() {
+		config.StartTime = klines[0].OpenTime
+	}
+	if config.EndTime.IsZero() {
+		config.EndTime = klines[len(klines)-1].CloseTime
+	}
+	return backtesting.Backtest(ctx, strategy, klines, config)
+}
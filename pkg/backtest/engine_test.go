@@ -0,0 +1,57 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// idleStrategy never trades
+type idleStrategy struct{}
+
+func (idleStrategy) Name() string            { return "idle" }
+func (idleStrategy) RequiredDataPoints() int { return 1 }
+func (idleStrategy) ShouldEnterTrade(ctx context.Context, klines []*Kline, currentPrice float64) bool {
+	return false
+}
+func (idleStrategy) ShouldClosePosition(ctx context.Context, position *Position, klines []*Kline, currentPrice float64) (bool, CloseReason) {
+	return false, ""
+}
+func (idleStrategy) GetPositionSize(ctx context.Context, klines []*Kline, availableFunds float64) float64 {
+	return 0
+}
+func (idleStrategy) GetATR(ctx context.Context, klines []*Kline) (float64, error) { return 0, nil }
+
+func TestEngineRejectsBadFeeds(t *testing.T) {
+	engine, err := NewEngine(Config{InitialFunds: 1000, PositionSize: 1, Leverage: 1}, Ideal())
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	unordered := []*Kline{{OpenTime: start.Add(time.Hour)}, {OpenTime: start}}
+	broken := FeedFunc(func(ctx context.Context) (*Kline, error) { return nil, errors.New("connection lost") })
+
+	tests := []struct {
+		name string
+		feed DataFeed
+		want string
+	}{
+		{name: "empty", feed: NewSliceFeed(nil), want: "data feed is empty"},
+		{name: "out of order", feed: NewSliceFeed(unordered), want: "is not after the previous one"},
+		{name: "failing", feed: broken, want: "connection lost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := engine.Run(context.Background(), idleStrategy{}, tt.feed)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := NewEngine(Config{InitialFunds: 1000, Leverage: 1}, Execution{SlippageRate: -0.1}); err == nil || !strings.Contains(err.Error(), "slippage") {
+		t.Errorf("Expected negative slippage to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package backtest_test
+
+import (
+	"context"
+	"cryptoMegaBot/pkg/backtest"
+	"fmt"
+	"math"
+	"time"
+)
+
+// breakoutStrategy buys when the close makes a new 3-bar high and sells when it falls below the
+// previous bar's low
+type breakoutStrategy struct{}
+
+func (breakoutStrategy) Name() string            { return "breakout" }
+func (breakoutStrategy) RequiredDataPoints() int { return 3 }
+
+func (breakoutStrategy) ShouldEnterTrade(ctx context.Context, klines []*backtest.Kline, currentPrice float64) bool {
+	n := len(klines)
+	return currentPrice > klines[n-2].High && currentPrice > klines[n-3].High
+}
+
+func (breakoutStrategy) ShouldClosePosition(ctx context.Context, position *backtest.Position, klines []*backtest.Kline, currentPrice float64) (bool, backtest.CloseReason) {
+	return currentPrice < klines[len(klines)-2].Low, "SIGNAL"
+}
+
+func (breakoutStrategy) GetPositionSize(ctx context.Context, klines []*backtest.Kline, availableFunds float64) float64 {
+	return 1
+}
+
+func (breakoutStrategy) GetATR(ctx context.Context, klines []*backtest.Kline) (float64, error) {
+	return 0, nil
+}
+
+// hourlyKlines builds hourly klines closing at the given prices, each opening at the previous close
+func hourlyKlines(closes ...float64) []*backtest.Kline {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	klines := make([]*backtest.Kline, len(closes))
+	for i, price := range closes {
+		openPrice := price
+		if i > 0 {
+			openPrice = closes[i-1]
+		}
+		open := start.Add(time.Duration(i) * time.Hour)
+		klines[i] = &backtest.Kline{
+			Symbol: "ETHUSDT", Interval: "1h", OpenTime: open, CloseTime: open.Add(time.Hour - time.Millisecond),
+			Open: openPrice, High: math.Max(openPrice, price) + 1, Low: math.Min(openPrice, price) - 1, Close: price, Volume: 10, IsFinal: true,
+		}
+	}
+	return klines
+}
+
+func Example() {
+	config := backtest.Config{InitialFunds: 1000, PositionSize: 1, StopLoss: 0.05, TakeProfit: 0.1, Symbol: "ETHUSDT", Leverage: 1}
+	engine, err := backtest.NewEngine(config, backtest.Ideal())
+	if err != nil {
+		panic(err)
+	}
+
+	feed := backtest.NewSliceFeed(hourlyKlines(100, 100, 100, 103, 106, 108, 104, 104))
+	result, err := engine.Run(context.Background(), breakoutStrategy{}, feed)
+	if err != nil {
+		panic(err)
+	}
+	for _, trade := range result.Trades {
+		fmt.Printf("bought at %.2f, sold at %.2f: %+.2f\n", trade.EntryPrice, trade.ExitPrice, trade.PNL)
+	}
+	fmt.Printf("final balance %.2f\n", result.FinalBalance)
+	// Output:
+	// bought at 103.00, sold at 104.00: +1.00
+	// final balance 1001.00
+}
+
+func ExampleRealistic() {
+	config := backtest.Config{InitialFunds: 1000, PositionSize: 1, StopLoss: 0.05, TakeProfit: 0.1, Symbol: "ETHUSDT", Leverage: 1}
+	klines := hourlyKlines(100, 100, 100, 103, 106, 108, 104, 104)
+
+	// The same run with 0.04% taker fees and 0.05% slippage on each market fill
+	for _, execution := range []backtest.Execution{backtest.Ideal(), backtest.Realistic(0.0004, 0, 0.0005)} {
+		engine, err := backtest.NewEngine(config, execution)
+		if err != nil {
+			panic(err)
+		}
+		result, err := engine.Run(context.Background(), breakoutStrategy{}, backtest.NewSliceFeed(klines))
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%.4f\n", result.TotalProfit)
+	}
+	// Output:
+	// 1.0000
+	// 0.8137
+}
+
+func ExampleChannelFeed() {
+	klines := make(chan *backtest.Kline)
+	go func() {
+		defer close(klines)
+		for _, k := range hourlyKlines(100, 100, 100, 103, 106, 108, 104, 104) {
+			klines <- k // e.g., read from a database cursor
+		}
+	}()
+
+	config := backtest.Config{InitialFunds: 1000, PositionSize: 1, StopLoss: 0.05, TakeProfit: 0.1, Symbol: "ETHUSDT", Leverage: 1}
+	engine, err := backtest.NewEngine(config, nil)
+	if err != nil {
+		panic(err)
+	}
+	result, err := engine.Run(context.Background(), breakoutStrategy{}, backtest.ChannelFeed(klines))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(result.TotalTrades, "trade")
+	// Output:
+	// 1 trade
+}
@@ -0,0 +1,78 @@
+package backtest
+
+import "cryptoMegaBot/internal/strategy/backtesting"
+
+// IntrabarFill selects whether exits are checked against each bar's high and low, and which
+// level fills first when a bar reaches both the stop and the take profit
+type IntrabarFill = backtesting.IntrabarFill
+
+const (
+	// IntrabarOff leaves exits to the strategy at each bar's close
+	IntrabarOff = backtesting.IntrabarOff
+	// IntrabarPessimistic fills the stop first when a bar reaches both levels
+	IntrabarPessimistic = backtesting.IntrabarPessimistic
+	// IntrabarOptimistic fills the take profit first when a bar reaches both levels
+	IntrabarOptimistic = backtesting.IntrabarOptimistic
+)
+
+// ExecutionTiming selects when market entries and the strategy's exits fill (Config.Timing)
+type ExecutionTiming = backtesting.ExecutionTiming
+
+const (
+	// ExecutionNextOpen fills them at the open of the bar after the signal (the default)
+	ExecutionNextOpen = backtesting.ExecutionNextOpen
+	// ExecutionSignalClose fills them at the signal bar's close, which assumes a fill at the price
+	// that produced the signal
+	ExecutionSignalClose = backtesting.ExecutionSignalClose
+)
+
+// ExecutionModel decides how the engine fills orders
+type ExecutionModel interface {
+	// Fees returns the trading fees and funding charged on each trade
+	Fees() FeeModel
+
+	// Slippage returns the adverse price move (fraction) of market fills
+	Slippage() float64
+
+	// Intrabar returns how stops and take profits inside a bar are detected
+	Intrabar() IntrabarFill
+}
+
+// Execution is an ExecutionModel with fixed costs
+type Execution struct {
+	FeeModel     FeeModel
+	SlippageRate float64
+	IntrabarMode IntrabarFill
+}
+
+// Fees returns the fee model
+func (e Execution) Fees() FeeModel {
+	return e.FeeModel
+}
+
+// Slippage returns the slippage rate
+func (e Execution) Slippage() float64 {
+	return e.SlippageRate
+}
+
+// Intrabar returns the intrabar fill mode
+func (e Execution) Intrabar() IntrabarFill {
+	return e.IntrabarMode
+}
+
+// Ideal returns an execution without fees, funding or slippage that only checks exits at each
+// bar's close, showing a strategy's edge before frictions
+func Ideal() Execution {
+	// A zero fee model means "use the defaults" to the engine, so the interval is set to mark it
+	return Execution{FeeModel: FeeModel{FundingInterval: DefaultFundingInterval}}
+}
+
+// Realistic returns an execution charging the taker fee rate on each fill and funding every
+// 8 hours, with slippage on market fills and stops filled pessimistically inside bars
+func Realistic(takerRate, fundingRate, slippage float64) Execution {
+	return Execution{
+		FeeModel:     FeeModel{TakerRate: takerRate, FundingRate: fundingRate, FundingInterval: DefaultFundingInterval},
+		SlippageRate: slippage,
+		IntrabarMode: IntrabarPessimistic,
+	}
+}
@@ -0,0 +1,80 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DataFeed supplies the klines of a run in time order
+type DataFeed interface {
+	// Next returns the next kline, or io.EOF once the feed is exhausted
+	Next(ctx context.Context) (*Kline, error)
+}
+
+// FeedFunc adapts a function to the DataFeed interface
+type FeedFunc func(ctx context.Context) (*Kline, error)
+
+// Next calls f
+func (f FeedFunc) Next(ctx context.Context) (*Kline, error) {
+	return f(ctx)
+}
+
+// SliceFeed feeds klines from memory
+type SliceFeed struct {
+	klines []*Kline
+	next   int
+}
+
+// NewSliceFeed creates a feed of klines, which must be sorted by open time
+func NewSliceFeed(klines []*Kline) *SliceFeed {
+	return &SliceFeed{klines: klines}
+}
+
+// Next returns the next kline, or io.EOF after the last one
+func (f *SliceFeed) Next(ctx context.Context) (*Kline, error) {
+	if f.next >= len(f.klines) {
+		return nil, io.EOF
+	}
+	k := f.klines[f.next]
+	f.next++
+	return k, nil
+}
+
+// ChannelFeed feeds klines received on a channel until it's closed
+func ChannelFeed(klines <-chan *Kline) DataFeed {
+	return FeedFunc(func(ctx context.Context) (*Kline, error) {
+		select {
+		case k, ok := <-klines:
+			if !ok {
+				return nil, io.EOF
+			}
+			return k, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
+
+// readFeed drains feed, checking that the klines are in time order
+func readFeed(ctx context.Context, feed DataFeed) ([]*Kline, error) {
+	var klines []*Kline
+	for {
+		k, err := feed.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return klines, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kline %d: %w", len(klines)+1, err)
+		}
+		if k == nil {
+			return nil, fmt.Errorf("kline %d is nil", len(klines)+1)
+		}
+		if n := len(klines); n > 0 && !k.OpenTime.After(klines[n-1].OpenTime) {
+			return nil, fmt.Errorf("kline %d at %s is not after the previous one at %s",
+				n+1, k.OpenTime.Format("2006-01-02 15:04:05"), klines[n-1].OpenTime.Format("2006-01-02 15:04:05"))
+		}
+		klines = append(klines, k)
+	}
+}
@@ -0,0 +1,388 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: control.proto
+
+package controlpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	TradingControl_GetStatus_FullMethodName       = "/cryptomegabot.control.v1.TradingControl/GetStatus"
+	TradingControl_GetOpenPosition_FullMethodName = "/cryptomegabot.control.v1.TradingControl/GetOpenPosition"
+	TradingControl_ListTrades_FullMethodName      = "/cryptomegabot.control.v1.TradingControl/ListTrades"
+	TradingControl_PauseTrading_FullMethodName    = "/cryptomegabot.control.v1.TradingControl/PauseTrading"
+	TradingControl_ResumeTrading_FullMethodName   = "/cryptomegabot.control.v1.TradingControl/ResumeTrading"
+	TradingControl_ForceClose_FullMethodName      = "/cryptomegabot.control.v1.TradingControl/ForceClose"
+	TradingControl_TradeEvents_FullMethodName     = "/cryptomegabot.control.v1.TradingControl/TradeEvents"
+)
+
+// TradingControlClient is the client API for TradingControl service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TradingControl is the gRPC control API of the bot, for external risk systems and UIs. It
+// offers the operator controls of the HTTP control API, the trade history and a stream of
+// trading events.
+type TradingControlClient interface {
+	// GetStatus returns a snapshot of the trading state.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// GetOpenPosition returns the open positions (at most one per side).
+	GetOpenPosition(ctx context.Context, in *GetOpenPositionRequest, opts ...grpc.CallOption) (*GetOpenPositionResponse, error)
+	// ListTrades returns closed positions, most recent first.
+	ListTrades(ctx context.Context, in *ListTradesRequest, opts ...grpc.CallOption) (*ListTradesResponse, error)
+	// PauseTrading stops new entries until ResumeTrading. Open positions are still managed.
+	PauseTrading(ctx context.Context, in *PauseTradingRequest, opts ...grpc.CallOption) (*Status, error)
+	// ResumeTrading lifts an operator pause and clears a tripped kill switch.
+	ResumeTrading(ctx context.Context, in *ResumeTradingRequest, opts ...grpc.CallOption) (*Status, error)
+	// ForceClose closes open positions at market.
+	ForceClose(ctx context.Context, in *ForceCloseRequest, opts ...grpc.CallOption) (*ForceCloseResponse, error)
+	// TradeEvents streams trading events as they happen, until the client cancels.
+	TradeEvents(ctx context.Context, in *TradeEventsRequest, opts ...grpc.CallOption) (TradingControl_TradeEventsClient, error)
+}
+
+type tradingControlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTradingControlClient(cc grpc.ClientConnInterface) TradingControlClient {
+	return &tradingControlClient{cc}
+}
+
+func (c *tradingControlClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, TradingControl_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingControlClient) GetOpenPosition(ctx context.Context, in *GetOpenPositionRequest, opts ...grpc.CallOption) (*GetOpenPositionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOpenPositionResponse)
+	err := c.cc.Invoke(ctx, TradingControl_GetOpenPosition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingControlClient) ListTrades(ctx context.Context, in *ListTradesRequest, opts ...grpc.CallOption) (*ListTradesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTradesResponse)
+	err := c.cc.Invoke(ctx, TradingControl_ListTrades_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingControlClient) PauseTrading(ctx context.Context, in *PauseTradingRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, TradingControl_PauseTrading_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingControlClient) ResumeTrading(ctx context.Context, in *ResumeTradingRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, TradingControl_ResumeTrading_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingControlClient) ForceClose(ctx context.Context, in *ForceCloseRequest, opts ...grpc.CallOption) (*ForceCloseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ForceCloseResponse)
+	err := c.cc.Invoke(ctx, TradingControl_ForceClose_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradingControlClient) TradeEvents(ctx context.Context, in *TradeEventsRequest, opts ...grpc.CallOption) (TradingControl_TradeEventsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TradingControl_ServiceDesc.Streams[0], TradingControl_TradeEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tradingControlTradeEventsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TradingControl_TradeEventsClient interface {
+	Recv() (*TradeEvent, error)
+	grpc.ClientStream
+}
+
+type tradingControlTradeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *tradingControlTradeEventsClient) Recv() (*TradeEvent, error) {
+	m := new(TradeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TradingControlServer is the server API for TradingControl service.
+// All implementations must embed UnimplementedTradingControlServer
+// for forward compatibility
+//
+// TradingControl is the gRPC control API of the bot, for external risk systems and UIs. It
+// offers the operator controls of the HTTP control API, the trade history and a stream of
+// trading events.
+type TradingControlServer interface {
+	// GetStatus returns a snapshot of the trading state.
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// GetOpenPosition returns the open positions (at most one per side).
+	GetOpenPosition(context.Context, *GetOpenPositionRequest) (*GetOpenPositionResponse, error)
+	// ListTrades returns closed positions, most recent first.
+	ListTrades(context.Context, *ListTradesRequest) (*ListTradesResponse, error)
+	// PauseTrading stops new entries until ResumeTrading. Open positions are still managed.
+	PauseTrading(context.Context, *PauseTradingRequest) (*Status, error)
+	// ResumeTrading lifts an operator pause and clears a tripped kill switch.
+	ResumeTrading(context.Context, *ResumeTradingRequest) (*Status, error)
+	// ForceClose closes open positions at market.
+	ForceClose(context.Context, *ForceCloseRequest) (*ForceCloseResponse, error)
+	// TradeEvents streams trading events as they happen, until the client cancels.
+	TradeEvents(*TradeEventsRequest, TradingControl_TradeEventsServer) error
+	mustEmbedUnimplementedTradingControlServer()
+}
+
+// UnimplementedTradingControlServer must be embedded to have forward compatible implementations.
+type UnimplementedTradingControlServer struct {
+}
+
+func (UnimplementedTradingControlServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedTradingControlServer) GetOpenPosition(context.Context, *GetOpenPositionRequest) (*GetOpenPositionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOpenPosition not implemented")
+}
+func (UnimplementedTradingControlServer) ListTrades(context.Context, *ListTradesRequest) (*ListTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrades not implemented")
+}
+func (UnimplementedTradingControlServer) PauseTrading(context.Context, *PauseTradingRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseTrading not implemented")
+}
+func (UnimplementedTradingControlServer) ResumeTrading(context.Context, *ResumeTradingRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeTrading not implemented")
+}
+func (UnimplementedTradingControlServer) ForceClose(context.Context, *ForceCloseRequest) (*ForceCloseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceClose not implemented")
+}
+func (UnimplementedTradingControlServer) TradeEvents(*TradeEventsRequest, TradingControl_TradeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method TradeEvents not implemented")
+}
+func (UnimplementedTradingControlServer) mustEmbedUnimplementedTradingControlServer() {}
+
+// UnsafeTradingControlServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TradingControlServer will
+// result in compilation errors.
+type UnsafeTradingControlServer interface {
+	mustEmbedUnimplementedTradingControlServer()
+}
+
+func RegisterTradingControlServer(s grpc.ServiceRegistrar, srv TradingControlServer) {
+	s.RegisterService(&TradingControl_ServiceDesc, srv)
+}
+
+func _TradingControl_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingControlServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingControl_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingControlServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingControl_GetOpenPosition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOpenPositionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingControlServer).GetOpenPosition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingControl_GetOpenPosition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingControlServer).GetOpenPosition(ctx, req.(*GetOpenPositionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingControl_ListTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingControlServer).ListTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingControl_ListTrades_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingControlServer).ListTrades(ctx, req.(*ListTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingControl_PauseTrading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseTradingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingControlServer).PauseTrading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingControl_PauseTrading_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingControlServer).PauseTrading(ctx, req.(*PauseTradingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingControl_ResumeTrading_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeTradingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingControlServer).ResumeTrading(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingControl_ResumeTrading_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingControlServer).ResumeTrading(ctx, req.(*ResumeTradingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingControl_ForceClose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceCloseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradingControlServer).ForceClose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradingControl_ForceClose_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradingControlServer).ForceClose(ctx, req.(*ForceCloseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradingControl_TradeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TradeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TradingControlServer).TradeEvents(m, &tradingControlTradeEventsServer{ServerStream: stream})
+}
+
+type TradingControl_TradeEventsServer interface {
+	Send(*TradeEvent) error
+	grpc.ServerStream
+}
+
+type tradingControlTradeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *tradingControlTradeEventsServer) Send(m *TradeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// TradingControl_ServiceDesc is the grpc.ServiceDesc for TradingControl service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TradingControl_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cryptomegabot.control.v1.TradingControl",
+	HandlerType: (*TradingControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _TradingControl_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetOpenPosition",
+			Handler:    _TradingControl_GetOpenPosition_Handler,
+		},
+		{
+			MethodName: "ListTrades",
+			Handler:    _TradingControl_ListTrades_Handler,
+		},
+		{
+			MethodName: "PauseTrading",
+			Handler:    _TradingControl_PauseTrading_Handler,
+		},
+		{
+			MethodName: "ResumeTrading",
+			Handler:    _TradingControl_ResumeTrading_Handler,
+		},
+		{
+			MethodName: "ForceClose",
+			Handler:    _TradingControl_ForceClose_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TradeEvents",
+			Handler:       _TradingControl_TradeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "control.proto",
+}
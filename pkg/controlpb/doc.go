@@ -0,0 +1,9 @@
+// Package controlpb is the generated gRPC API of the bot's TradingControl service (see
+// control.proto), for clients that monitor and control a running bot. The server is started
+// with GRPC_API_ADDR.
+//
+// After changing control.proto, regenerate the code with protoc, protoc-gen-go and
+// protoc-gen-go-grpc:
+//
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative control.proto
+package controlpb